@@ -343,6 +343,11 @@ const expectedGraphqlConfig = `{
     },
     "introspection": {
         "disabled": false
+    },
+    "persisted_queries": {
+        "enabled": false,
+        "strict": false,
+        "queries": null
     }
 }`
 
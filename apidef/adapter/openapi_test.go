@@ -324,6 +324,11 @@ const expectedOpenAPIGraphQLConfig = `{
     },
     "introspection": {
         "disabled": false
+    },
+    "persisted_queries": {
+        "enabled": false,
+        "strict": false,
+        "queries": null
     }
 }`
 
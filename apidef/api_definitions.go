@@ -171,6 +171,8 @@ type MockResponseMeta struct {
 	Code       int               `bson:"code" json:"code"`
 	Body       string            `bson:"body" json:"body"`
 	Headers    map[string]string `bson:"headers" json:"headers"`
+	// Latency is the delay in milliseconds before the mock response is returned.
+	Latency int64 `bson:"latency" json:"latency"`
 }
 
 type EndPointMeta struct {
@@ -198,13 +200,24 @@ type TemplateData struct {
 	Mode           SourceMode       `bson:"template_mode" json:"template_mode"`
 	EnableSession  bool             `bson:"enable_session" json:"enable_session"`
 	TemplateSource string           `bson:"template_source" json:"template_source"`
+	// SOAP sets the SOAP headers of the request transformed into a SOAP envelope.
+	SOAP *TemplateSOAP `bson:"soap,omitempty" json:"soap,omitempty"`
 }
 
+// TransformFormat is the engine a body transform runs with.
+type TransformFormat string
+
+// TransformFormatJQ transforms JSON bodies with the JQ expression given as template source
+// instead of a template.
+const TransformFormatJQ TransformFormat = "jq"
+
 type TemplateMeta struct {
 	Disabled     bool         `bson:"disabled" json:"disabled"`
 	TemplateData TemplateData `bson:"template_data" json:"template_data"`
 	Path         string       `bson:"path" json:"path"`
 	Method       string       `bson:"method" json:"method"`
+	// Format selects the transform engine. Empty uses the template in TemplateData.
+	Format TransformFormat `bson:"format,omitempty" json:"format,omitempty"`
 }
 
 type TransformJQMeta struct {
@@ -214,16 +227,25 @@ type TransformJQMeta struct {
 }
 
 type HeaderInjectionMeta struct {
-	Disabled      bool              `bson:"disabled" json:"disabled"`
-	DeleteHeaders []string          `bson:"delete_headers" json:"delete_headers"`
-	AddHeaders    map[string]string `bson:"add_headers" json:"add_headers"`
-	Path          string            `bson:"path" json:"path"`
-	Method        string            `bson:"method" json:"method"`
-	ActOnResponse bool              `bson:"act_on" json:"act_on"`
+	Disabled       bool              `bson:"disabled" json:"disabled"`
+	DeleteHeaders  []string          `bson:"delete_headers" json:"delete_headers"`
+	AddHeaders     map[string]string `bson:"add_headers" json:"add_headers"`
+	RewriteHeaders []HeaderRewrite   `bson:"rewrite_headers" json:"rewrite_headers,omitempty"`
+	Path           string            `bson:"path" json:"path"`
+	Method         string            `bson:"method" json:"method"`
+	ActOnResponse  bool              `bson:"act_on" json:"act_on"`
 }
 
 func (h *HeaderInjectionMeta) Enabled() bool {
-	return !h.Disabled && (len(h.AddHeaders) > 0 || len(h.DeleteHeaders) > 0)
+	return !h.Disabled && (len(h.AddHeaders) > 0 || len(h.DeleteHeaders) > 0 || len(h.RewriteHeaders) > 0)
+}
+
+// HeaderRewrite replaces the parts of a header value matching MatchRegex with Replace.
+// The header name is matched case-insensitively and Replace may reference capture groups, e.g. `$1`.
+type HeaderRewrite struct {
+	Header     string `bson:"header" json:"header"`
+	MatchRegex string `bson:"match_regex" json:"match_regex"`
+	Replace    string `bson:"replace" json:"replace"`
 }
 
 type HardTimeoutMeta struct {
@@ -235,6 +257,49 @@ type HardTimeoutMeta struct {
 	TimeoutDuration tyktime.ReadableDuration `bson:"duration,omitempty" json:"duration,omitempty"`
 }
 
+// UpstreamHostMeta configures the Host header sent to the upstream for an endpoint.
+type UpstreamHostMeta struct {
+	Disabled bool   `bson:"disabled" json:"disabled"`
+	Path     string `bson:"path" json:"path"`
+	Method   string `bson:"method" json:"method"`
+	// Host is the Host header sent to the upstream, which may contain context variables.
+	Host string `bson:"host" json:"host"`
+}
+
+// CORSMeta overrides the API-level CORS configuration for the requests matching the path.
+// The path is matched as a prefix and the longest matching path wins. Empty lists, a zero
+// max age and an unset allow credentials are taken from the API-level configuration.
+type CORSMeta struct {
+	Disabled         bool     `bson:"disabled" json:"disabled"`
+	Path             string   `bson:"path" json:"path"`
+	AllowedOrigins   []string `bson:"allowed_origins" json:"allowed_origins"`
+	AllowedMethods   []string `bson:"allowed_methods" json:"allowed_methods"`
+	AllowedHeaders   []string `bson:"allowed_headers" json:"allowed_headers"`
+	ExposedHeaders   []string `bson:"exposed_headers" json:"exposed_headers"`
+	AllowCredentials *bool    `bson:"allow_credentials,omitempty" json:"allow_credentials,omitempty"`
+	MaxAge           int      `bson:"max_age" json:"max_age"`
+}
+
+// OAuthClientRegistration configures the dynamic registration of OAuth clients (RFC 7591) on the
+// /oauth/register endpoint of the API. Registration is refused while InitialAccessToken is empty.
+type OAuthClientRegistration struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// InitialAccessToken is the bearer token required to register a client.
+	InitialAccessToken string `bson:"initial_access_token" json:"initial_access_token"`
+	// PolicyID is the policy of the registered clients.
+	PolicyID string `bson:"policy_id" json:"policy_id"`
+}
+
+// RateCostMeta weights the requests matching the path in the rate limit of the key, so a request
+// costs Cost requests of the allowance instead of one. With ApplyToQuota the quota is weighted too.
+type RateCostMeta struct {
+	Disabled     bool   `bson:"disabled" json:"disabled"`
+	Path         string `bson:"path" json:"path"`
+	Method       string `bson:"method" json:"method"`
+	Cost         int    `bson:"rate_cost" json:"rate_cost"`
+	ApplyToQuota bool   `bson:"apply_to_quota" json:"apply_to_quota"`
+}
+
 type TrackEndpointMeta struct {
 	Disabled bool   `bson:"disabled" json:"disabled"`
 	Path     string `bson:"path" json:"path"`
@@ -315,6 +380,10 @@ type RoutingTriggerOptions struct {
 	SessionMetaMatches    map[string]StringRegexMap `bson:"session_meta_matches" json:"session_meta_matches"`
 	RequestContextMatches map[string]StringRegexMap `bson:"request_context_matches" json:"request_context_matches"`
 	PayloadMatches        StringRegexMap            `bson:"payload_matches" json:"payload_matches"`
+	// PayloadJSONMatches are matched against the values found at their JSON paths, in gjson syntax, in the request body.
+	PayloadJSONMatches map[string]StringRegexMap `bson:"payload_json_matches" json:"payload_json_matches,omitempty"`
+	// PayloadMaxSize is the size in bytes of the largest request body parsed for PayloadJSONMatches, 1MiB if 0.
+	PayloadMaxSize int64 `bson:"payload_max_size" json:"payload_max_size,omitempty"`
 }
 
 // NewRoutingTriggerOptions allocates the maps inside RoutingTriggerOptions.
@@ -370,8 +439,12 @@ type ValidatePathMeta struct {
 	Schema      map[string]interface{}  `bson:"-" json:"schema"`
 	SchemaB64   string                  `bson:"schema_b64" json:"schema_b64,omitempty"`
 	SchemaCache gojsonschema.JSONLoader `bson:"-" json:"-"`
+	// SchemaCompiled is the schema compiled when the API is loaded.
+	SchemaCompiled *gojsonschema.Schema `bson:"-" json:"-"`
 	// Allows override of default 422 Unprocessible Entity response code for validation errors.
 	ErrorResponseCode int `bson:"error_response_code" json:"error_response_code"`
+	// Strict rejects requests with a content type other than JSON, which are otherwise passed through unvalidated.
+	Strict bool `bson:"strict" json:"strict,omitempty"`
 }
 
 type ValidateRequestMeta struct {
@@ -424,6 +497,9 @@ type ExtendedPathsSet struct {
 	GoPlugin                []GoPluginMeta        `bson:"go_plugin" json:"go_plugin,omitempty"`
 	PersistGraphQL          []PersistGraphQLMeta  `bson:"persist_graphql" json:"persist_graphql"`
 	RateLimit               []RateLimitMeta       `bson:"rate_limit" json:"rate_limit"`
+	UpstreamHostHeader      []UpstreamHostMeta    `bson:"upstream_host_header" json:"upstream_host_header,omitempty"`
+	CORS                    []CORSMeta            `bson:"cors" json:"cors,omitempty"`
+	RateCost                []RateCostMeta        `bson:"rate_cost" json:"rate_cost,omitempty"`
 }
 
 // Clear omits values that have OAS API definition conversions in place.
@@ -469,10 +545,55 @@ type VersionDefinition struct {
 	// Versions is a map of version names to version ApiIDs.
 	Versions map[string]string `bson:"versions" json:"versions"`
 
+	// Sources is the ordered list of request locations the version is read from, the first one holding
+	// a version is used. When empty, the version is read from Location and Key.
+	Sources []VersionSource `bson:"sources" json:"sources,omitempty"`
+
+	// Sunset holds the deprecated and retired versions by name, requests to a retired version get a 410 Gone response.
+	Sunset map[string]VersionSunset `bson:"sunset" json:"sunset,omitempty"`
+
 	// BaseID is a hidden field used internally that represents the ApiID of the base API.
 	BaseID string `bson:"base_id" json:"-"` // json tag is `-` because we want this to be hidden to user
 }
 
+// VersionSource is a request location the version is read from.
+type VersionSource struct {
+	// Location is one of header, url-param or url.
+	Location string `bson:"location" json:"location"`
+
+	// Key is the name of the header or query parameter holding the version, unused for the url location.
+	Key string `bson:"key" json:"key,omitempty"`
+}
+
+// VersionSunset deprecates and retires a version.
+type VersionSunset struct {
+	// Deprecation is the RFC3339 time the version is deprecated at, returned in the Deprecation response header.
+	// The responses of a deprecated version carry a Warning header.
+	Deprecation string `bson:"deprecation" json:"deprecation,omitempty"`
+
+	// Date is the RFC3339 time the version is retired at, returned in the Sunset response header.
+	// The version is served until then. An empty date retires the version immediately, unless it's only deprecated.
+	Date string `bson:"date" json:"date,omitempty"`
+
+	// Message is the body of the 410 Gone response returned for the retired version.
+	Message string `bson:"message" json:"message,omitempty"`
+
+	// Warning is the text of the Warning response header of the deprecated version.
+	Warning string `bson:"warning" json:"warning,omitempty"`
+
+	// Link is the URL documenting the deprecation, returned in a Link response header.
+	Link string `bson:"link" json:"link,omitempty"`
+}
+
+// VersionSources returns the request locations the version is read from, in order.
+func (v *VersionDefinition) VersionSources() []VersionSource {
+	if len(v.Sources) > 0 {
+		return v.Sources
+	}
+
+	return []VersionSource{{Location: v.Location, Key: v.Key}}
+}
+
 func (v *VersionDefinition) ResolvedDefault() string {
 	if v.Default == Self {
 		return v.Name
@@ -503,6 +624,8 @@ type VersionInfo struct {
 	GlobalResponseHeaders         map[string]string        `bson:"global_response_headers" json:"global_response_headers"`
 	GlobalResponseHeadersRemove   []string                 `bson:"global_response_headers_remove" json:"global_response_headers_remove"`
 	GlobalResponseHeadersDisabled bool                     `bson:"global_response_headers_disabled" json:"global_response_headers_disabled"`
+	GlobalHeadersRewrite          []HeaderRewrite          `bson:"global_headers_rewrite" json:"global_headers_rewrite,omitempty"`
+	GlobalResponseHeadersRewrite  []HeaderRewrite          `bson:"global_response_headers_rewrite" json:"global_response_headers_rewrite,omitempty"`
 	IgnoreEndpointCase            bool                     `bson:"ignore_endpoint_case" json:"ignore_endpoint_case"`
 	GlobalSizeLimit               int64                    `bson:"global_size_limit" json:"global_size_limit"`
 	GlobalSizeLimitDisabled       bool                     `bson:"global_size_limit_disabled" json:"global_size_limit_disabled"`
@@ -512,11 +635,12 @@ type VersionInfo struct {
 }
 
 func (v *VersionInfo) GlobalHeadersEnabled() bool {
-	return !v.GlobalHeadersDisabled && (len(v.GlobalHeaders) > 0 || len(v.GlobalHeadersRemove) > 0)
+	return !v.GlobalHeadersDisabled && (len(v.GlobalHeaders) > 0 || len(v.GlobalHeadersRemove) > 0 || len(v.GlobalHeadersRewrite) > 0)
 }
 
 func (v *VersionInfo) GlobalResponseHeadersEnabled() bool {
-	return !v.GlobalResponseHeadersDisabled && (len(v.GlobalResponseHeaders) > 0 || len(v.GlobalResponseHeadersRemove) > 0)
+	return !v.GlobalResponseHeadersDisabled && (len(v.GlobalResponseHeaders) > 0 || len(v.GlobalResponseHeadersRemove) > 0 ||
+		len(v.GlobalResponseHeadersRewrite) > 0)
 }
 
 func (v *VersionInfo) HasEndpointReqHeader() bool {
@@ -575,6 +699,9 @@ type MiddlewareDefinition struct {
 	Code           string `bson:"code,omitempty" json:"code,omitempty"`
 	RequireSession bool   `bson:"require_session" json:"require_session"`
 	RawBodyOnly    bool   `bson:"raw_body_only" json:"raw_body_only"`
+	// StreamBody streams the raw request and response bodies to gRPC plugins in chunks, so they aren't
+	// limited by the gRPC max message sizes.
+	StreamBody bool `bson:"stream_body,omitempty" json:"stream_body,omitempty"`
 
 	// RuntimeHandlerName is set by the gateway at API-load time when the JS
 	// driver rebrands handler globals to per-(file, name) unique aliases.
@@ -614,6 +741,9 @@ type MiddlewareSection struct {
 	Response    []MiddlewareDefinition `bson:"response" json:"response"`
 	Driver      MiddlewareDriver       `bson:"driver" json:"driver"`
 	IdExtractor MiddlewareIdExtractor  `bson:"id_extractor" json:"id_extractor"`
+	// FailOpenOnDisconnect skips the plugin hooks while the gateway is disconnected from the gRPC plugin server,
+	// instead of rejecting the requests. Authentication hooks always reject the requests.
+	FailOpenOnDisconnect bool `bson:"fail_open_on_disconnect" json:"fail_open_on_disconnect"`
 }
 
 type CacheOptions struct {
@@ -624,6 +754,12 @@ type CacheOptions struct {
 	EnableUpstreamCacheControl bool     `bson:"enable_upstream_cache_control" json:"enable_upstream_cache_control"`
 	CacheControlTTLHeader      string   `bson:"cache_control_ttl_header" json:"cache_control_ttl_header"`
 	CacheByHeaders             []string `bson:"cache_by_headers" json:"cache_by_headers"`
+	// CacheVaryHeaders is the allowlist of request headers honoured when listed in the response Vary header.
+	// Responses varying on other headers are cached regardless of their values.
+	CacheVaryHeaders []string `bson:"cache_vary_headers" json:"cache_vary_headers"`
+	// StaleIfError is the grace window in seconds during which expired entries are kept
+	// and served instead of an upstream error or an open circuit breaker.
+	StaleIfError int64 `bson:"stale_if_error" json:"stale_if_error"`
 }
 
 type ResponseProcessor struct {
@@ -643,6 +779,21 @@ type ServiceDiscoveryConfiguration struct {
 	CacheDisabled       bool   `bson:"cache_disabled" json:"cache_disabled"`
 	CacheTimeout        int64  `bson:"cache_timeout" json:"cache_timeout"`
 	EndpointReturnsList bool   `bson:"endpoint_returns_list" json:"endpoint_returns_list"`
+
+	// Kubernetes configures discovering the targets from the endpoint slices of a Kubernetes service
+	// instead of querying QueryEndpoint.
+	Kubernetes KubernetesServiceDiscovery `bson:"kubernetes" json:"kubernetes"`
+}
+
+// KubernetesServiceDiscovery configures discovering the ready addresses of a Kubernetes service.
+type KubernetesServiceDiscovery struct {
+	Enabled   bool   `bson:"enabled" json:"enabled"`
+	Namespace string `bson:"namespace" json:"namespace"`
+	Service   string `bson:"service" json:"service"`
+	// PortName is the name of the service port to target, the first port is used when it's empty.
+	PortName string `bson:"port_name" json:"port_name"`
+	// KubeconfigPath is the path of the kubeconfig file to connect with, in-cluster credentials are used when it's empty.
+	KubeconfigPath string `bson:"kubeconfig_path" json:"kubeconfig_path"`
 }
 
 // CacheOptions returns the timeout value in effect, and a bool if cache is enabled.
@@ -698,6 +849,8 @@ type APIDefinition struct {
 		AllowedAccessTypes     []osin.AccessRequestType    `bson:"allowed_access_types" json:"allowed_access_types"`
 		AllowedAuthorizeTypes  []osin.AuthorizeRequestType `bson:"allowed_authorize_types" json:"allowed_authorize_types"`
 		AuthorizeLoginRedirect string                      `bson:"auth_login_redirect" json:"auth_login_redirect"`
+		RotateRefreshTokens    bool                        `bson:"rotate_refresh_tokens" json:"rotate_refresh_tokens"`
+		ClientRegistration     OAuthClientRegistration     `bson:"client_registration" json:"client_registration"`
 	} `bson:"oauth_meta" json:"oauth_meta"`
 	Auth         AuthConfig            `bson:"auth" json:"auth"` // Deprecated: Use AuthConfigs instead.
 	AuthConfigs  map[string]AuthConfig `bson:"auth_configs" json:"auth_configs"`
@@ -774,11 +927,13 @@ type APIDefinition struct {
 	SessionProvider                      SessionProviderMeta    `bson:"session_provider" json:"session_provider"`
 	EventHandlers                        EventHandlerMetaConfig `bson:"event_handlers" json:"event_handlers"`
 	EnableBatchRequestSupport            bool                   `bson:"enable_batch_request_support" json:"enable_batch_request_support"`
+	BatchRequestMaxParallelism           int                    `bson:"batch_request_max_parallelism" json:"batch_request_max_parallelism,omitempty"`
 	EnableIpWhiteListing                 bool                   `mapstructure:"enable_ip_whitelisting" bson:"enable_ip_whitelisting" json:"enable_ip_whitelisting"`
 	AllowedIPs                           []string               `mapstructure:"allowed_ips" bson:"allowed_ips" json:"allowed_ips"`
 	EnableIpBlacklisting                 bool                   `mapstructure:"enable_ip_blacklisting" bson:"enable_ip_blacklisting" json:"enable_ip_blacklisting"`
 	BlacklistedIPs                       []string               `mapstructure:"blacklisted_ips" bson:"blacklisted_ips" json:"blacklisted_ips"`
 	IPAccessControlDisabled              bool                   `mapstructure:"ip_access_control_disabled" bson:"ip_access_control_disabled" json:"ip_access_control_disabled"`
+	GeoRestriction                       GeoRestriction         `bson:"geo_restriction" json:"geo_restriction"`
 	DontSetQuotasOnCreate                bool                   `mapstructure:"dont_set_quota_on_create" bson:"dont_set_quota_on_create" json:"dont_set_quota_on_create"`
 	ExpireAnalyticsAfter                 int64                  `mapstructure:"expire_analytics_after" bson:"expire_analytics_after" json:"expire_analytics_after"` // must have an expireAt TTL index set (http://docs.mongodb.org/manual/tutorial/expire-data/)
 	ResponseProcessors                   []ResponseProcessor    `bson:"response_processors" json:"response_processors"`
@@ -797,6 +952,13 @@ type APIDefinition struct {
 	GraphQL                              GraphQLConfig          `bson:"graphql" json:"graphql"`
 	AnalyticsPlugin                      AnalyticsPluginConfig  `bson:"analytics_plugin" json:"analytics_plugin,omitempty"`
 
+	// DetailedRecordingConfig limits the data stored when detailed recording is enabled.
+	DetailedRecordingConfig DetailedRecordingConfig `bson:"detailed_recording_config" json:"detailed_recording_config,omitempty"`
+
+	// NormaliseURLPatterns replace the matching parts of the paths recorded in analytics,
+	// before the gateway normalisation patterns are applied.
+	NormaliseURLPatterns []URLNormalisePattern `bson:"normalise_url_patterns" json:"normalise_url_patterns,omitempty"`
+
 	// Gateway segment tags
 	TagsDisabled bool     `bson:"tags_disabled" json:"tags_disabled,omitempty"`
 	Tags         []string `bson:"tags" json:"tags"`
@@ -835,6 +997,37 @@ type APIDefinition struct {
 	// ErrorOverrides contains the configurations for error response customization.
 	ErrorOverrides         ErrorOverridesMap `bson:"error_overrides" json:"error_overrides"`
 	ErrorOverridesDisabled bool              `bson:"error_overrides_disabled" json:"error_overrides_disabled" `
+
+	// ErrorTemplates contains the templates of the error bodies of the API, keyed by status code or `default`.
+	// The gateway error templates are used for the status codes and formats without an API template.
+	ErrorTemplates map[string]ErrorTemplate `bson:"error_templates" json:"error_templates,omitempty"`
+
+	// MaintenanceMode contains the configuration for responding with a static response while the upstream is under maintenance.
+	MaintenanceMode MaintenanceMode `bson:"maintenance_mode" json:"maintenance_mode"`
+
+	// ConcurrencyLimit contains the configuration for limiting the number of requests in flight to the API.
+	ConcurrencyLimit ConcurrencyLimit `bson:"concurrency_limit" json:"concurrency_limit"`
+
+	// RequestID contains the configuration for the correlation ID of the requests to the API.
+	RequestID RequestID `bson:"request_id" json:"request_id"`
+
+	// Idempotency contains the configuration for replaying the responses of retried POST requests.
+	Idempotency Idempotency `bson:"idempotency" json:"idempotency"`
+
+	// ResponseCompression contains the configuration for compressing the responses of upstreams which don't.
+	ResponseCompression ResponseCompression `bson:"response_compression" json:"response_compression"`
+
+	// LatencyBreakdown contains the configuration for recording the time spent in each stage of the requests.
+	LatencyBreakdown LatencyBreakdown `bson:"latency_breakdown" json:"latency_breakdown"`
+
+	// PayloadSizeAlerts contains the configuration for firing an event on unusually large requests or responses.
+	PayloadSizeAlerts PayloadSizeAlerts `bson:"payload_size_alerts" json:"payload_size_alerts"`
+
+	// AuthFailureLimit contains the configuration for locking out the clients failing to authenticate too often.
+	AuthFailureLimit AuthFailureLimit `bson:"auth_failure_limit" json:"auth_failure_limit"`
+
+	// ScopeAccess contains the configuration restricting the endpoints to the tokens granted the scopes mapped to them.
+	ScopeAccess ScopeAccess `bson:"scope_access" json:"scope_access"`
 }
 
 type JWK struct {
@@ -852,6 +1045,145 @@ func (jwk *JWK) GetCacheTimeoutSeconds(defaultExpiration int64) int64 {
 	return defaultExpiration
 }
 
+// MaintenanceMode holds the configuration for short-circuiting requests with a static response
+// while the upstream is under maintenance.
+type MaintenanceMode struct {
+	// Enabled enables maintenance mode.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// StatusCode is the response status code, defaults to 503.
+	StatusCode int `bson:"status_code" json:"status_code"`
+	// ContentType is the response content type, defaults to application/json.
+	ContentType string `bson:"content_type" json:"content_type"`
+	// Body is a Go template for the response body.
+	Body string `bson:"body" json:"body"`
+	// RetryAfter is the number of seconds sent in the Retry-After header, the header is omitted when it's 0.
+	RetryAfter int `bson:"retry_after" json:"retry_after"`
+	// ExcludePaths is a list of paths which are still proxied to the upstream.
+	ExcludePaths []string `bson:"exclude_paths" json:"exclude_paths"`
+}
+
+// ConcurrencyLimit holds the configuration for limiting the number of requests in flight,
+// for the API and for each key with a max_concurrent_requests limit.
+type ConcurrencyLimit struct {
+	// Enabled enables concurrency limiting, including the limits set on keys and policies.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// MaxConcurrentRequests is the maximum number of requests to the API in flight at once, 0 disables the API limit.
+	MaxConcurrentRequests int `bson:"max_concurrent_requests" json:"max_concurrent_requests"`
+	// Distributed counts the requests in flight across the gateways in redis instead of per gateway.
+	Distributed bool `bson:"distributed" json:"distributed"`
+	// StatusCode is the response status code when the limit is reached, 429 or 503, defaults to 429.
+	StatusCode int `bson:"status_code" json:"status_code"`
+	// RetryAfter is the number of seconds sent in the Retry-After header when the limit is reached, defaults to 1.
+	RetryAfter int `bson:"retry_after" json:"retry_after"`
+}
+
+// RequestID holds the configuration for the correlation ID of the requests. The ID of the incoming
+// request is reused, otherwise a UUID is generated. It's sent to the upstream and returned to the client.
+type RequestID struct {
+	// Enabled enables the request ID. The gateway `request_id.enabled` option enables it for every API.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// HeaderName is the header carrying the request ID, defaults to the gateway `request_id.header_name` or X-Request-ID.
+	HeaderName string `bson:"header_name" json:"header_name"`
+}
+
+// Idempotency holds the configuration for replaying the response of a POST request retried with the
+// same idempotency key, instead of proxying it again. Responses are stored in Redis per API and key.
+type Idempotency struct {
+	// Enabled enables the idempotency keys.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// HeaderName is the header carrying the idempotency key, defaults to Idempotency-Key.
+	HeaderName string `bson:"header_name" json:"header_name"`
+	// TTL is the number of seconds a response is replayed for, defaults to 86400.
+	TTL int64 `bson:"ttl" json:"ttl"`
+	// MaxResponseSize is the largest response body stored in bytes, defaults to 1MB. Larger responses aren't stored.
+	MaxResponseSize int64 `bson:"max_response_size" json:"max_response_size"`
+	// WaitForInFlight makes the duplicates of a request in flight wait for its response, instead of a 409 Conflict.
+	WaitForInFlight bool `bson:"wait_for_in_flight" json:"wait_for_in_flight"`
+	// WaitTimeout is the number of seconds a duplicate waits before the 409 Conflict, defaults to 10.
+	WaitTimeout int64 `bson:"wait_timeout" json:"wait_timeout"`
+}
+
+// ResponseCompression holds the configuration for compressing the upstream responses the client accepts
+// compressed, with the encoding listed first in Algorithms and in the Accept-Encoding header of the request.
+// Responses already encoded by the upstream, server-sent events and upgraded connections are left untouched.
+type ResponseCompression struct {
+	// Enabled enables the response compression.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// MinSize is the smallest response body compressed in bytes, defaults to 1024.
+	MinSize int64 `bson:"min_size" json:"min_size"`
+	// ContentTypes is the list of media types compressed, a `type/*` entry matches every subtype.
+	// Defaults to JSON, XML, JavaScript and text responses.
+	ContentTypes []string `bson:"content_types" json:"content_types"`
+	// Algorithms is the list of encodings used by order of preference, `gzip` and `br`. Defaults to both, brotli first.
+	Algorithms []string `bson:"algorithms" json:"algorithms"`
+}
+
+// LatencyBreakdown holds the configuration for recording the time spent in each stage of the requests:
+// authentication, rate limiting, transforms, plugins and the upstream proxy. The breakdown is recorded in
+// analytics as a `latency-breakdown-` tag, e.g. `latency-breakdown-auth=2,plugin=120,proxy=35`.
+type LatencyBreakdown struct {
+	// Enabled enables the latency breakdown.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Threshold is the time in milliseconds a stage has to exceed to be part of the breakdown.
+	Threshold int64 `bson:"threshold" json:"threshold"`
+	// DebugHeaders returns the breakdown of the proxied requests in the X-Tyk-Latency-Breakdown response header.
+	DebugHeaders bool `bson:"debug_headers" json:"debug_headers"`
+}
+
+// PayloadSizeAlerts holds the configuration for firing the PayloadSizeThreshold event when the body
+// of a single proxied request or response is larger than the configured size.
+type PayloadSizeAlerts struct {
+	// Enabled enables the payload size alerts.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// RequestThreshold is the request body size in bytes above which the event fires, zero disables it.
+	RequestThreshold int64 `bson:"request_threshold" json:"request_threshold"`
+	// ResponseThreshold is the response body size in bytes above which the event fires, zero disables it.
+	ResponseThreshold int64 `bson:"response_threshold" json:"response_threshold"`
+}
+
+// AuthFailureLimit holds the configuration for locking out the client IPs and the token prefixes failing to
+// authenticate too often. Once locked out, the requests of a client IP are answered with 429 before their token
+// is looked up, while a token prefix only locks out the tokens not found.
+type AuthFailureLimit struct {
+	// Enabled enables the lockout of the clients failing to authenticate.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// MaxFailures is the number of authentication failures allowed within Window, defaults to 10.
+	MaxFailures int `bson:"max_failures" json:"max_failures"`
+	// Window is the period in seconds the authentication failures are counted over, defaults to 60.
+	Window int `bson:"window" json:"window"`
+	// LockoutDuration is the number of seconds a client IP or token prefix is locked out for, defaults to 300.
+	LockoutDuration int `bson:"lockout_duration" json:"lockout_duration"`
+	// TokenPrefixLength is the number of leading characters of the key IDs the failures are counted by, defaults to 8.
+	// The key ID of a generated token is the token without its org ID, or the ID decoded from it.
+	TokenPrefixLength int `bson:"token_prefix_length" json:"token_prefix_length"`
+	// Shared counts the failures in Redis, so the lockouts apply across the gateways.
+	// It costs a Redis round trip per failing request to check the lockouts of the other gateways.
+	Shared bool `bson:"shared" json:"shared"`
+}
+
+// ScopeAccess holds the configuration restricting the endpoints of the API to the tokens granted the scopes
+// mapped to them. The scopes are read from a claim of the JWTs, or from the `scope` metadata of the other
+// sessions, e.g. the scope granted to an OAuth access token. A request has to be allowed by the allowed URLs
+// of the access rights too.
+type ScopeAccess struct {
+	// Enabled enables the scope checks.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// ClaimName is the JWT claim holding the scopes, as a space delimited string or an array. The nested claims
+	// are separated by dots. It defaults to the scope claim name of the JWT scopes, then to `scope`.
+	ClaimName string `bson:"claim_name" json:"claim_name"`
+	// ScopeToEndpoints maps the scopes to the endpoints they grant. The endpoints mapped to no scope aren't
+	// restricted, the others are allowed to the tokens granted one of the scopes mapped to them.
+	ScopeToEndpoints map[string][]ScopeEndpoint `bson:"scope_to_endpoints" json:"scope_to_endpoints"`
+}
+
+// ScopeEndpoint is an endpoint granted by a scope.
+type ScopeEndpoint struct {
+	// Path is the path of the endpoint, a regular expression matched like the allowed URLs of the access rights.
+	Path string `bson:"path" json:"path"`
+	// Methods are the HTTP methods of the endpoint, all the methods when empty.
+	Methods []string `bson:"methods" json:"methods"`
+}
+
 // UpstreamAuth holds the configurations related to upstream API authentication.
 type UpstreamAuth struct {
 	// Enabled enables upstream API authentication.
@@ -860,11 +1192,15 @@ type UpstreamAuth struct {
 	BasicAuth UpstreamBasicAuth `bson:"basic_auth" json:"basic_auth"`
 	// OAuth holds the OAuth2 configuration for the upstream client credentials API authentication.
 	OAuth UpstreamOAuth `bson:"oauth" json:"oauth"`
+	// AWSSigV4 holds the AWS Signature Version 4 configuration for signing the upstream requests.
+	AWSSigV4 UpstreamAWSSigV4 `bson:"aws_sigv4" json:"aws_sigv4"`
+	// HMACSigning holds the HMAC configuration for signing the upstream requests.
+	HMACSigning UpstreamHMACSigning `bson:"hmac_signing" json:"hmac_signing"`
 }
 
 // IsEnabled checks if UpstreamAuthentication is enabled for the API.
 func (u *UpstreamAuth) IsEnabled() bool {
-	return u.Enabled && (u.BasicAuth.Enabled || u.OAuth.Enabled)
+	return u.Enabled && (u.BasicAuth.Enabled || u.OAuth.Enabled || u.AWSSigV4.Enabled || u.HMACSigning.Enabled)
 }
 
 // IsEnabled checks if UpstreamOAuth is enabled for the API.
@@ -885,6 +1221,43 @@ type UpstreamBasicAuth struct {
 	Header AuthSource `bson:"header" json:"header"`
 }
 
+// UpstreamAWSSigV4 holds the configuration for signing upstream requests with AWS Signature Version 4.
+// The credentials can be references to the KV stores, such as `secrets://aws-secret`.
+type UpstreamAWSSigV4 struct {
+	// Enabled enables AWS Signature Version 4 signing of the upstream requests.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Region is the AWS region of the upstream, e.g. `eu-west-1`.
+	Region string `bson:"region" json:"region"`
+	// Service is the AWS service name of the upstream, e.g. `execute-api`.
+	Service string `bson:"service" json:"service"`
+	// AccessKeyID is the AWS access key ID.
+	AccessKeyID string `bson:"access_key_id" json:"access_key_id"`
+	// SecretAccessKey is the AWS secret access key.
+	SecretAccessKey string `bson:"secret_access_key" json:"secret_access_key"`
+	// SessionToken is the AWS session token of temporary credentials.
+	SessionToken string `bson:"session_token" json:"session_token,omitempty"`
+}
+
+// UpstreamHMACSigning holds the configuration for signing upstream requests with an HMAC.
+// The signature is sent as `Signature keyId="...",algorithm="...",headers="...",signature="..."`
+// and the request body is covered by the `digest` header.
+type UpstreamHMACSigning struct {
+	// Enabled enables HMAC signing of the upstream requests.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// KeyID is the key identifier sent along with the signature.
+	KeyID string `bson:"key_id" json:"key_id"`
+	// Secret is the HMAC key. It can be a reference to the KV stores, such as `secrets://hmac-key`.
+	Secret string `bson:"secret" json:"secret"`
+	// Algorithm is one of `hmac-sha1`, `hmac-sha256`, `hmac-sha384` and `hmac-sha512`. Defaults to `hmac-sha256`.
+	Algorithm string `bson:"algorithm" json:"algorithm"`
+	// Headers is the list of signed headers, `(request-target)` stands for the method and path.
+	// Defaults to `(request-target)`, `host`, `date` and `digest`.
+	Headers []string `bson:"headers" json:"headers,omitempty"`
+	// Header holds the configuration for custom header name to be used for the signature.
+	// Defaults to `Authorization`.
+	Header AuthSource `bson:"header" json:"header"`
+}
+
 // UpstreamOAuth holds upstream OAuth2 authentication configuration.
 type UpstreamOAuth struct {
 	// Enabled enables upstream OAuth2 authentication.
@@ -965,6 +1338,24 @@ func (a AuthSource) AuthKeyName() string {
 	return a.Name
 }
 
+// DetailedRecordingConfig limits the request and response data stored by detailed recording.
+type DetailedRecordingConfig struct {
+	// MaxBodySize truncates recorded request and response bodies to this many bytes. 0 means no limit.
+	MaxBodySize int64 `bson:"max_body_size" json:"max_body_size,omitempty"`
+	// RedactHeaders are the names of the headers whose values are replaced with "***".
+	RedactHeaders []string `bson:"redact_headers" json:"redact_headers,omitempty"`
+	// RedactFields are the names of the JSON body fields whose values are replaced with "***".
+	RedactFields []string `bson:"redact_fields" json:"redact_fields,omitempty"`
+}
+
+// URLNormalisePattern replaces the parts of analytics paths matching Regex with Replacement.
+type URLNormalisePattern struct {
+	// Regex is the regular expression matched against the path.
+	Regex string `bson:"regex" json:"regex"`
+	// Replacement replaces the matches, it can refer to capture groups as in `$1`.
+	Replacement string `bson:"replacement" json:"replacement"`
+}
+
 // AnalyticsPluginConfig holds the configuration for the analytics custom function plugins
 type AnalyticsPluginConfig struct {
 	// Enabled activates the custom plugin
@@ -1001,6 +1392,10 @@ type HostCheckObject struct {
 	Method              string            `bson:"method" json:"method"`
 	Headers             map[string]string `bson:"headers" json:"headers"`
 	Body                string            `bson:"body" json:"body"`
+	// ExpectedStatusCodes lists the response codes treated as healthy. Defaults to 200 when empty.
+	ExpectedStatusCodes []int `bson:"expected_status_codes" json:"expected_status_codes,omitempty"`
+	// ExpectedBodyRegex is a regular expression the response body must match for the host to be healthy.
+	ExpectedBodyRegex string `bson:"expected_body_regex" json:"expected_body_regex,omitempty"`
 }
 
 // AddCommand will append a new command to the test.
@@ -1068,7 +1463,9 @@ type RequestSigningMeta struct {
 
 type ProxyConfig struct {
 	PreserveHostHeader          bool                          `bson:"preserve_host_header" json:"preserve_host_header"`
+	UpstreamHostHeader          string                        `bson:"upstream_host_header" json:"upstream_host_header,omitempty"`
 	ListenPath                  string                        `bson:"listen_path" json:"listen_path"`
+	ListenPathPriority          int                           `bson:"listen_path_priority" json:"listen_path_priority,omitempty"`
 	TargetURL                   string                        `bson:"target_url" json:"target_url"`
 	DisableStripSlash           bool                          `bson:"disable_strip_slash" json:"disable_strip_slash"`
 	StripListenPath             bool                          `bson:"strip_listen_path" json:"strip_listen_path"`
@@ -1083,8 +1480,108 @@ type ProxyConfig struct {
 		SSLMinVersion           uint16   `bson:"ssl_min_version" json:"ssl_min_version"`
 		SSLMaxVersion           uint16   `bson:"ssl_max_version" json:"ssl_max_version"`
 		SSLForceCommonNameCheck bool     `json:"ssl_force_common_name_check"`
-		ProxyURL                string   `bson:"proxy_url" json:"proxy_url"`
+		// SSLCACertificates are the IDs of the CA certificates verifying the upstream certificate,
+		// instead of the system certificate pool.
+		SSLCACertificates []string `bson:"ssl_ca_certificates" json:"ssl_ca_certificates,omitempty"`
+		// SSLClientCertificate is the ID of the client certificate presented to the upstream,
+		// when upstream_certificates has none for its host.
+		SSLClientCertificate string `bson:"ssl_client_certificate" json:"ssl_client_certificate,omitempty"`
+		// SSLServerName overrides the server name sent in the TLS handshake (SNI) and verified in the upstream certificate.
+		SSLServerName string `bson:"ssl_server_name" json:"ssl_server_name,omitempty"`
+		ProxyURL      string `bson:"proxy_url" json:"proxy_url"`
 	} `bson:"transport" json:"transport"`
+	Mirror           ProxyMirror           `bson:"mirror" json:"mirror"`
+	Canary           ProxyCanary           `bson:"canary" json:"canary"`
+	GRPCWeb          ProxyGRPCWeb          `bson:"grpc_web" json:"grpc_web"`
+	WebSocket        ProxyWebSocket        `bson:"websocket" json:"websocket"`
+	OutlierDetection ProxyOutlierDetection `bson:"outlier_detection" json:"outlier_detection"`
+}
+
+// ProxyMirror configures shadowing a sample of the proxied traffic to a secondary upstream.
+// Mirrored requests are sent asynchronously and their responses are discarded.
+type ProxyMirror struct {
+	Enabled    bool    `bson:"enabled" json:"enabled"`
+	TargetURL  string  `bson:"target_url" json:"target_url"`
+	Percentage float64 `bson:"percentage" json:"percentage"`
+}
+
+// ProxyCanary configures routing requests to a canary upstream, instead of the target URL or load balanced targets.
+// A request is routed to the canary when any of the rules matches it, or else for a percentage of the keys.
+type ProxyCanary struct {
+	Enabled   bool         `bson:"enabled" json:"enabled"`
+	TargetURL string       `bson:"target_url" json:"target_url"`
+	Rules     []CanaryRule `bson:"rules" json:"rules"`
+	// Percentage is the share, between 0 and 100, of the requests no rule matched routed to the canary.
+	// The choice is consistent per key, or per client IP for keyless requests.
+	Percentage float64 `bson:"percentage" json:"percentage"`
+}
+
+// Sources of the values matched by the canary rules.
+const (
+	CanaryRuleHeader          = "header"
+	CanaryRuleQuery           = "query"
+	CanaryRuleSessionMetadata = "session_metadata"
+)
+
+// CanaryRule matches a request header, query parameter or session metadata entry.
+type CanaryRule struct {
+	// Source is where the value is read from, one of header, query or session_metadata.
+	Source string `bson:"source" json:"source"`
+	Name   string `bson:"name" json:"name"`
+	// Value is the value matched, case insensitively. Any non-empty value matches when it's empty.
+	Value string `bson:"value" json:"value"`
+}
+
+// ProxyGRPCWeb configures translating gRPC-Web requests from browser clients into
+// native gRPC towards the upstream, which has to be reachable over HTTP/2 (h2c or TLS).
+type ProxyGRPCWeb struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+}
+
+// ProxyWebSocket configures the limits enforced on websocket connections proxied to the upstream.
+// A limit set to 0 isn't enforced.
+type ProxyWebSocket struct {
+	// Enabled activates the limits and the subprotocol restrictions.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// MaxMessageSize is the maximum size of a message in bytes, in either direction.
+	MaxMessageSize int64 `bson:"max_message_size" json:"max_message_size"`
+	// IdleTimeout is the number of seconds after which a connection without any frame is closed.
+	IdleTimeout int64 `bson:"idle_timeout" json:"idle_timeout"`
+	// MaxConnectionDuration is the number of seconds after which a connection is closed.
+	MaxConnectionDuration int64 `bson:"max_connection_duration" json:"max_connection_duration"`
+	// AllowedSubprotocols restricts the subprotocols clients may request. The others are removed
+	// from the handshake, which is rejected when none of the requested subprotocols is allowed.
+	AllowedSubprotocols []string `bson:"allowed_subprotocols" json:"allowed_subprotocols"`
+	// StripSubprotocols removes the subprotocols from the handshake, in both directions.
+	StripSubprotocols bool `bson:"strip_subprotocols" json:"strip_subprotocols"`
+}
+
+// ProxyOutlierDetection configures ejecting load balanced targets from rotation after consecutive
+// 5xx responses or connection errors. An ejected target is re-admitted once its ejection time
+// elapsed or an uptime test against it succeeded.
+type ProxyOutlierDetection struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// ConsecutiveFailures is the number of consecutive failures ejecting a target, defaults to 5.
+	ConsecutiveFailures int `bson:"consecutive_failures" json:"consecutive_failures"`
+	// EjectionTime is the number of seconds a target is ejected for, defaults to 30. It doubles each time
+	// a target is ejected again without a successful request in between.
+	EjectionTime int64 `bson:"ejection_time" json:"ejection_time"`
+	// MaxEjectionTime is the maximum number of seconds a target is ejected for, defaults to 300.
+	MaxEjectionTime int64 `bson:"max_ejection_time" json:"max_ejection_time"`
+}
+
+// GeoRestriction configures allowing or blocking requests by the country of the client IP, looked up in
+// the MaxMind database configured with `geo_restriction.db_path` in the Gateway configuration.
+type GeoRestriction struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// AllowedCountries are the ISO 3166-1 alpha-2 codes of the countries allowed to access the API. When set,
+	// requests from the other countries and from IPs without a known country are blocked.
+	AllowedCountries []string `bson:"allowed_countries" json:"allowed_countries"`
+	// BlockedCountries are the ISO 3166-1 alpha-2 codes of the countries blocked from the API.
+	// They take precedence over AllowedCountries.
+	BlockedCountries []string `bson:"blocked_countries" json:"blocked_countries"`
+	// FailOpen lets the requests through when the database is missing or expired, they are blocked otherwise.
+	FailOpen bool `bson:"fail_open" json:"fail_open"`
 }
 
 type CORSConfig struct {
@@ -1125,6 +1622,8 @@ type GraphQLConfig struct {
 	Supergraph GraphQLSupergraphConfig `bson:"supergraph" json:"supergraph"`
 	// Introspection holds the configuration for GraphQL Introspection
 	Introspection GraphQLIntrospectionConfig `bson:"introspection" json:"introspection"`
+	// PersistedQueries holds the configuration for GraphQL persisted queries.
+	PersistedQueries GraphQLPersistedQueriesConfig `bson:"persisted_queries" json:"persisted_queries"`
 }
 
 type GraphQLConfigVersion string
@@ -1140,6 +1639,19 @@ type GraphQLIntrospectionConfig struct {
 	Disabled bool `bson:"disabled" json:"disabled"`
 }
 
+// GraphQLPersistedQueriesConfig configures resolving GraphQL operations from their SHA-256 hash,
+// sent with the automatic persisted queries protocol or as documentId.
+type GraphQLPersistedQueriesConfig struct {
+	// Enabled turns on persisted queries.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Strict rejects operations which aren't persisted, and registering new ones from requests.
+	// Introspection queries are still subject to the introspection settings of the policies.
+	Strict bool `bson:"strict" json:"strict"`
+	// Queries maps the hex encoded SHA-256 hashes of query documents to the documents.
+	// Documents uploaded with the control API or registered by clients are kept in Redis.
+	Queries map[string]string `bson:"queries" json:"queries"`
+}
+
 type GraphQLResponseExtensions struct {
 	OnErrorForwarding bool `bson:"on_error_forwarding" json:"on_error_forwarding"`
 }
@@ -1751,7 +2263,7 @@ var Template = template.New("").Funcs(map[string]interface{}{
 
 		return string(xmlValue), err
 	},
-})
+}).Funcs(transformXMLFuncs)
 
 // ExternalOAuth support will be deprecated starting from 5.7.0.
 // To avoid any disruptions, we recommend that you use JSON Web Token (JWT) instead,
@@ -1821,6 +2333,43 @@ func (w *WebHookHandlerConf) Scan(in any) error {
 	return nil
 }
 
+// Chat webhook payload formats.
+const (
+	// ChatWebhookFormatSlack formats events as Slack Block Kit messages.
+	ChatWebhookFormatSlack = "slack"
+	// ChatWebhookFormatTeams formats events as Microsoft Teams message cards.
+	ChatWebhookFormatTeams = "teams"
+)
+
+// ChatWebhookHandlerConf holds configuration related to the chat webhook event handler,
+// which posts events to a Slack or Microsoft Teams incoming webhook.
+type ChatWebhookHandlerConf struct {
+	// Disabled enables/disables this handler.
+	Disabled bool `bson:"disabled" json:"disabled"`
+	// ID optional ID of the handler, to be used in pro mode.
+	ID string `bson:"id" json:"id"`
+	// Name is the name of the handler.
+	Name string `bson:"name" json:"name"`
+	// Format is the payload format, `slack` (default) or `teams`.
+	Format string `bson:"format" json:"format"`
+	// TargetPath is the incoming webhook URL of the channel.
+	TargetPath string `bson:"target_path" json:"target_path"`
+	// EventTimeout is the cool-down (in seconds) during which the same event of an API isn't posted again.
+	// Defaults to 60 seconds.
+	EventTimeout int64 `bson:"event_timeout" json:"event_timeout"`
+}
+
+// Scan scans ChatWebhookHandlerConf from `any` in.
+func (c *ChatWebhookHandlerConf) Scan(in any) error {
+	conf, err := reflect.Cast[ChatWebhookHandlerConf](in)
+	if err != nil {
+		return err
+	}
+
+	*c = *conf
+	return nil
+}
+
 // JSVMEventHandlerConf represents the configuration for a JavaScript VM event handler in the API definition.
 type JSVMEventHandlerConf struct {
 	// Disabled indicates whether the event handler is inactive.
@@ -96,3 +96,17 @@ type ErrorResponse struct {
 	// Headers are HTTP headers to include in the response.
 	Headers map[string]string `bson:"headers,omitempty" json:"headers,omitempty"`
 }
+
+// ErrorTemplateDefault is the key of the error template used for the status codes without their own template.
+const ErrorTemplateDefault = "default"
+
+// ErrorTemplate holds the Go templates of an error body, one per format. The format is picked
+// from the Accept header of the request. Templates get the Message, StatusCode, RequestID,
+// APIID and APIName of the error.
+type ErrorTemplate struct {
+	// JSON is the template of the error body of requests accepting JSON.
+	JSON string `bson:"json,omitempty" json:"json,omitempty"`
+
+	// XML is the template of the error body of requests accepting XML.
+	XML string `bson:"xml,omitempty" json:"xml,omitempty"`
+}
@@ -29,5 +29,10 @@ type HealthCheckItem struct {
 	Output        string            `json:"output,omitempty"`
 	ComponentType string            `json:"componentType,omitempty"`
 	ComponentID   string            `json:"componentId,omitempty"`
+	ObservedValue interface{}       `json:"observedValue,omitempty"`
 	Time          string            `json:"time"`
+	// LatencyMs is the time the check took, in milliseconds.
+	LatencyMs float64 `json:"latencyMs,omitempty"`
+	// LastError is the error of the last failed check, kept once the component recovered.
+	LastError string `json:"lastError,omitempty"`
 }
@@ -28,6 +28,8 @@ func GetImporterForSource(source APIImporterSource) (APIImporter, error) {
 		return &SwaggerAST{}, nil
 	case WSDLSource:
 		return &WSDLDef{}, nil
+	case PostmanSource:
+		return &PostmanCollection{}, nil
 	default:
 		return nil, errors.New("source not matched, failing")
 	}
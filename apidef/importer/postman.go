@@ -0,0 +1,442 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/apidef"
+
+	"github.com/TykTechnologies/tyk/internal/uuid"
+)
+
+const PostmanSource APIImporterSource = "postman"
+
+// postmanVersionName is the name of the single version of the imported API definitions.
+const postmanVersionName = "Default"
+
+// postmanVariable matches the `{{name}}` variables of a Postman collection.
+var postmanVariable = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
+// PostmanCollection is a v2.1 Postman collection. Its requests are imported as the
+// allowed paths and methods of the API definition and its example responses as mocks.
+type PostmanCollection struct {
+	Info struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Item     []PostmanItem     `json:"item"`
+	Variable []PostmanVariable `json:"variable"`
+	Auth     json.RawMessage   `json:"auth"`
+	Event    []json.RawMessage `json:"event"`
+
+	// Warnings lists the features of the collection which weren't imported.
+	Warnings []string `json:"-"`
+}
+
+// PostmanItem is either a folder of items or a request with its example responses.
+type PostmanItem struct {
+	Name     string            `json:"name"`
+	Item     []PostmanItem     `json:"item"`
+	Request  *PostmanRequest   `json:"request"`
+	Response []PostmanResponse `json:"response"`
+	Auth     json.RawMessage   `json:"auth"`
+	Event    []json.RawMessage `json:"event"`
+}
+
+// PostmanRequest is the request of an item, it can be given as a bare URL.
+type PostmanRequest struct {
+	Method string          `json:"method"`
+	URL    PostmanURL      `json:"url"`
+	Auth   json.RawMessage `json:"auth"`
+}
+
+// UnmarshalJSON decodes a request given as an object or as a URL string.
+func (p *PostmanRequest) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		p.URL.Raw = raw
+		return nil
+	}
+
+	type request PostmanRequest
+	return json.Unmarshal(data, (*request)(p))
+}
+
+// PostmanURL is the URL of a request, its host and path segments may contain variables.
+type PostmanURL struct {
+	Raw      string          `json:"raw"`
+	Protocol string          `json:"protocol"`
+	Host     postmanSegments `json:"host"`
+	Port     string          `json:"port"`
+	Path     postmanSegments `json:"path"`
+}
+
+// UnmarshalJSON decodes a URL given as an object or as a string.
+func (p *PostmanURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		p.Raw = raw
+		return nil
+	}
+
+	type url PostmanURL
+	return json.Unmarshal(data, (*url)(p))
+}
+
+// parts returns the base URL and the path segments of the URL, parsing the raw URL when they aren't given.
+func (p PostmanURL) parts() (base string, path []string) {
+	if len(p.Host) == 0 && len(p.Path) == 0 {
+		raw := p.Raw
+		if i := strings.IndexAny(raw, "?#"); i >= 0 {
+			raw = raw[:i]
+		}
+
+		protocol, rest, ok := strings.Cut(raw, "://")
+		if !ok {
+			protocol, rest = "", raw
+		}
+
+		host, path, _ := strings.Cut(rest, "/")
+		p = PostmanURL{Protocol: protocol, Host: postmanSegments{host}, Path: strings.Split(path, "/")}
+	}
+
+	if len(p.Host) > 0 {
+		base = strings.Join(p.Host, ".")
+		if p.Port != "" {
+			base += ":" + p.Port
+		}
+		if p.Protocol != "" {
+			base = p.Protocol + "://" + base
+		}
+	}
+
+	for _, segment := range p.Path {
+		if segment != "" {
+			path = append(path, segment)
+		}
+	}
+
+	return base, path
+}
+
+// postmanSegments are the host or path segments of a URL, given as a string or a list.
+type postmanSegments []string
+
+// UnmarshalJSON decodes the segments, the path segments given as objects are read from their value.
+func (s *postmanSegments) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		*s = strings.Split(raw, "/")
+		return nil
+	}
+
+	var segments []json.RawMessage
+	if err := json.Unmarshal(data, &segments); err != nil {
+		return err
+	}
+
+	*s = make(postmanSegments, 0, len(segments))
+	for _, segment := range segments {
+		var value string
+		if err := json.Unmarshal(segment, &value); err != nil {
+			var object struct {
+				Value string `json:"value"`
+			}
+			if err := json.Unmarshal(segment, &object); err != nil {
+				return err
+			}
+			value = object.Value
+		}
+		*s = append(*s, value)
+	}
+
+	return nil
+}
+
+// PostmanResponse is an example response of a request.
+type PostmanResponse struct {
+	Name   string            `json:"name"`
+	Code   int               `json:"code"`
+	Header []PostmanKeyValue `json:"header"`
+	Body   string            `json:"body"`
+}
+
+// PostmanKeyValue is a header of a Postman collection.
+type PostmanKeyValue struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+}
+
+// PostmanVariable is a variable of a Postman collection.
+type PostmanVariable struct {
+	Key      string `json:"key"`
+	Value    any    `json:"value"`
+	Disabled bool   `json:"disabled"`
+}
+
+// postmanEndpoint is a request of the collection, with the folders leading to it.
+type postmanEndpoint struct {
+	name   string
+	method string
+	base   string
+	path   string
+	item   PostmanItem
+}
+
+func (p *PostmanCollection) LoadFrom(r io.Reader) error {
+	return json.NewDecoder(r).Decode(p)
+}
+
+// warn records a feature of the collection which isn't imported.
+func (p *PostmanCollection) warn(format string, args ...interface{}) {
+	warning := fmt.Sprintf(format, args...)
+	for _, w := range p.Warnings {
+		if w == warning {
+			return
+		}
+	}
+	p.Warnings = append(p.Warnings, warning)
+}
+
+// variable returns the value of the collection variable.
+func (p *PostmanCollection) variable(name string) (string, bool) {
+	for _, v := range p.Variable {
+		if v.Key == name && !v.Disabled {
+			return fmt.Sprint(v.Value), true
+		}
+	}
+	return "", false
+}
+
+// resolve replaces the collection variables in value, warning about the unknown ones.
+func (p *PostmanCollection) resolve(value string) string {
+	return postmanVariable.ReplaceAllStringFunc(value, func(match string) string {
+		name := strings.TrimSpace(match[2 : len(match)-2])
+		if resolved, ok := p.variable(name); ok {
+			return resolved
+		}
+
+		p.warn("variable %q isn't defined in the collection", name)
+		return match
+	})
+}
+
+// endpoints returns the requests of the collection, in order, with their folders flattened.
+func (p *PostmanCollection) endpoints() []postmanEndpoint {
+	var endpoints []postmanEndpoint
+
+	var walk func(items []PostmanItem, folder string)
+	walk = func(items []PostmanItem, folder string) {
+		for _, item := range items {
+			name := strings.TrimPrefix(folder+"/"+item.Name, "/")
+
+			if postmanDefined(item.Auth) {
+				p.warn("authentication of %q isn't imported", name)
+			}
+			if len(item.Event) > 0 {
+				p.warn("scripts of %q aren't imported", name)
+			}
+
+			if item.Request == nil {
+				walk(item.Item, name)
+				continue
+			}
+
+			if postmanDefined(item.Request.Auth) {
+				p.warn("authentication of %q isn't imported", name)
+			}
+
+			method := strings.ToUpper(item.Request.Method)
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			base, segments := item.Request.URL.parts()
+			for i, segment := range segments {
+				segments[i] = postmanPathSegment(segment)
+			}
+
+			endpoints = append(endpoints, postmanEndpoint{
+				name:   name,
+				method: method,
+				base:   p.resolve(base),
+				path:   "/" + strings.Join(segments, "/"),
+				item:   item,
+			})
+		}
+	}
+
+	walk(p.Item, "")
+	return endpoints
+}
+
+// postmanDefined reports whether the optional field is set in the collection.
+func postmanDefined(raw json.RawMessage) bool {
+	return len(raw) > 0 && string(raw) != "null"
+}
+
+// postmanPathSegment turns the `:name` and `{{name}}` path variables into `{name}` path parameters.
+func postmanPathSegment(segment string) string {
+	if strings.HasPrefix(segment, ":") && len(segment) > 1 {
+		return "{" + segment[1:] + "}"
+	}
+
+	return postmanVariable.ReplaceAllString(segment, "{$1}")
+}
+
+func (p *PostmanCollection) ConvertIntoApiVersion(asMock bool) (apidef.VersionInfo, error) {
+	versionInfo := apidef.VersionInfo{
+		Name:             postmanVersionName,
+		UseExtendedPaths: true,
+	}
+
+	if !strings.Contains(p.Info.Schema, "v2.1") {
+		p.warn("only v2.1 collections are supported, the collection schema is %q", p.Info.Schema)
+	}
+	if postmanDefined(p.Auth) {
+		p.warn("authentication of the collection isn't imported")
+	}
+	if len(p.Event) > 0 {
+		p.warn("scripts of the collection aren't imported")
+	}
+
+	endpoints := p.endpoints()
+	if len(endpoints) == 0 {
+		return versionInfo, errors.New("no requests defined in the Postman collection")
+	}
+
+	allowed := map[string]int{}
+	for _, endpoint := range endpoints {
+		i, ok := allowed[endpoint.path]
+		if !ok {
+			i = len(versionInfo.ExtendedPaths.WhiteList)
+			allowed[endpoint.path] = i
+			versionInfo.ExtendedPaths.WhiteList = append(versionInfo.ExtendedPaths.WhiteList, apidef.EndPointMeta{
+				Path:          endpoint.path,
+				MethodActions: map[string]apidef.EndpointMethodMeta{},
+			})
+		}
+
+		methodActions := versionInfo.ExtendedPaths.WhiteList[i].MethodActions
+		if _, ok := methodActions[endpoint.method]; ok {
+			p.warn("%q duplicates %s %s, only the first request is imported", endpoint.name, endpoint.method, endpoint.path)
+			continue
+		}
+
+		methodActions[endpoint.method] = apidef.EndpointMethodMeta{
+			Action: apidef.NoAction,
+			Code:   http.StatusOK,
+		}
+
+		if !asMock || len(endpoint.item.Response) == 0 {
+			continue
+		}
+
+		if len(endpoint.item.Response) > 1 {
+			p.warn("only the first example response of %q is mocked", endpoint.name)
+		}
+
+		versionInfo.ExtendedPaths.MockResponse = append(versionInfo.ExtendedPaths.MockResponse,
+			postmanMockResponse(endpoint, endpoint.item.Response[0]))
+	}
+
+	return versionInfo, nil
+}
+
+// postmanMockResponse returns the mock response of the endpoint built from the example response.
+func postmanMockResponse(endpoint postmanEndpoint, response PostmanResponse) apidef.MockResponseMeta {
+	mock := apidef.MockResponseMeta{
+		Path:   endpoint.path,
+		Method: endpoint.method,
+		Code:   response.Code,
+		Body:   response.Body,
+	}
+
+	if mock.Code == 0 {
+		mock.Code = http.StatusOK
+	}
+
+	for _, h := range response.Header {
+		if h.Disabled {
+			continue
+		}
+		if mock.Headers == nil {
+			mock.Headers = map[string]string{}
+		}
+		mock.Headers[h.Key] = h.Value
+	}
+
+	return mock
+}
+
+// upstreamURL returns the base URL of the requests, warning when they target several upstreams.
+func (p *PostmanCollection) upstreamURL() string {
+	var upstream string
+	for _, endpoint := range p.endpoints() {
+		// The hosts with undefined variables were reported when resolved.
+		if endpoint.base == "" || postmanVariable.MatchString(endpoint.base) {
+			continue
+		}
+
+		if upstream == "" {
+			upstream = endpoint.base
+			continue
+		}
+
+		if endpoint.base != upstream {
+			p.warn("requests target several upstreams, %s is used", upstream)
+		}
+	}
+
+	if upstream != "" && !strings.Contains(upstream, "://") {
+		upstream = "http://" + upstream
+	}
+
+	return upstream
+}
+
+func (p *PostmanCollection) InsertIntoAPIDefinitionAsVersion(version apidef.VersionInfo, def *apidef.APIDefinition, versionName string) error {
+	def.VersionData.NotVersioned = true
+	def.VersionData.DefaultVersion = versionName
+	def.VersionData.Versions[versionName] = version
+	return nil
+}
+
+// ToAPIDefinition converts the collection into a keyless API definition. The upstream is
+// taken from the requests, with the collection variables resolved, unless upstreamURL is given.
+func (p *PostmanCollection) ToAPIDefinition(orgID, upstreamURL string, asMock bool) (*apidef.APIDefinition, error) {
+	ad := apidef.APIDefinition{
+		Name:             p.Info.Name,
+		Active:           true,
+		UseKeylessAccess: true,
+		APIID:            uuid.NewHex(),
+		OrgID:            orgID,
+	}
+	ad.VersionDefinition.Key = "version"
+	ad.VersionDefinition.Location = "header"
+	ad.VersionData.Versions = make(map[string]apidef.VersionInfo)
+	ad.Proxy.ListenPath = "/" + ad.APIID + "/"
+	ad.Proxy.StripListenPath = true
+
+	versionData, err := p.ConvertIntoApiVersion(asMock)
+	if err != nil {
+		return nil, err
+	}
+
+	if upstreamURL == "" {
+		upstreamURL = p.upstreamURL()
+	}
+	if upstreamURL == "" {
+		return nil, errors.New("no upstream URL found in the Postman collection, it must be supplied")
+	}
+	ad.Proxy.TargetURL = upstreamURL
+
+	err = p.InsertIntoAPIDefinitionAsVersion(versionData, &ad, postmanVersionName)
+	return &ad, err
+}
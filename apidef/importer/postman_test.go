@@ -0,0 +1,227 @@
+package importer
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+func loadPostmanCollection(t *testing.T, collection string) *PostmanCollection {
+	t.Helper()
+
+	imp, err := GetImporterForSource(PostmanSource)
+	require.NoError(t, err)
+	require.NoError(t, imp.LoadFrom(bytes.NewBufferString(collection)))
+
+	return imp.(*PostmanCollection)
+}
+
+func TestToAPIDefinition_Postman(t *testing.T) {
+	t.Run("allowed paths", func(t *testing.T) {
+		collection := loadPostmanCollection(t, postmanCollectionJSON)
+
+		def, err := collection.ToAPIDefinition("testOrg", "", false)
+		require.NoError(t, err)
+
+		assert.Equal(t, "Petstore", def.Name)
+		assert.Equal(t, "https://petstore.example.com:8443/v1", def.Proxy.TargetURL, "the upstream is resolved from the collection variables")
+		assert.True(t, def.VersionData.NotVersioned)
+		assert.True(t, def.UseKeylessAccess)
+
+		v, ok := def.VersionData.Versions[postmanVersionName]
+		require.True(t, ok)
+
+		allowed := map[string][]string{}
+		for _, meta := range v.ExtendedPaths.WhiteList {
+			for method, action := range meta.MethodActions {
+				assert.Equal(t, apidef.EndpointMethodMeta{Action: apidef.NoAction, Code: http.StatusOK}, action)
+				allowed[meta.Path] = append(allowed[meta.Path], method)
+			}
+		}
+
+		for _, methods := range allowed {
+			sort.Strings(methods)
+		}
+
+		assert.Equal(t, "/pets", v.ExtendedPaths.WhiteList[0].Path, "the paths are kept in the collection order")
+		assert.Equal(t, map[string][]string{
+			"/pets":                {http.MethodGet, http.MethodPost},
+			"/pets/{petId}":        {http.MethodGet},
+			"/pets/{petId}/photos": {http.MethodPut},
+			"/health":              {http.MethodGet},
+		}, allowed)
+		assert.Empty(t, v.ExtendedPaths.MockResponse)
+
+		assert.ElementsMatch(t, []string{
+			`authentication of the collection isn't imported`,
+			`scripts of "Pets/Create pet" aren't imported`,
+			`"Pets/List pets again" duplicates GET /pets, only the first request is imported`,
+			`requests target several upstreams, https://petstore.example.com:8443/v1 is used`,
+		}, collection.Warnings)
+	})
+
+	t.Run("mock responses", func(t *testing.T) {
+		collection := loadPostmanCollection(t, postmanCollectionJSON)
+
+		def, err := collection.ToAPIDefinition("testOrg", "http://upstream.example.com", true)
+		require.NoError(t, err)
+		assert.Equal(t, "http://upstream.example.com", def.Proxy.TargetURL)
+
+		v := def.VersionData.Versions[postmanVersionName]
+		assert.Equal(t, []apidef.MockResponseMeta{
+			{
+				Path:    "/pets",
+				Method:  http.MethodGet,
+				Code:    http.StatusOK,
+				Body:    `[{"id":1,"name":"Rex"}]`,
+				Headers: map[string]string{"Content-Type": "application/json"},
+			},
+			{
+				Path:   "/pets/{petId}",
+				Method: http.MethodGet,
+				Code:   http.StatusNotFound,
+				Body:   `{"error":"not found"}`,
+			},
+		}, v.ExtendedPaths.MockResponse)
+
+		assert.Contains(t, collection.Warnings, `only the first example response of "Pets/Get pet" is mocked`)
+	})
+
+	t.Run("no upstream", func(t *testing.T) {
+		collection := loadPostmanCollection(t, `{
+  "info": {"name": "No upstream", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+  "item": [{"name": "Ping", "request": {"method": "GET", "url": "{{baseUrl}}/ping"}}]
+}`)
+
+		_, err := collection.ToAPIDefinition("testOrg", "", false)
+		assert.Error(t, err)
+		assert.Contains(t, collection.Warnings, `variable "baseUrl" isn't defined in the collection`)
+
+		def, err := collection.ToAPIDefinition("testOrg", "http://upstream.example.com", false)
+		require.NoError(t, err)
+		assert.Equal(t, "/ping", def.VersionData.Versions[postmanVersionName].ExtendedPaths.WhiteList[0].Path)
+	})
+
+	t.Run("no requests", func(t *testing.T) {
+		collection := loadPostmanCollection(t, `{"info": {"name": "Empty"}, "item": [{"name": "Folder", "item": []}]}`)
+
+		_, err := collection.ToAPIDefinition("testOrg", "http://upstream.example.com", false)
+		assert.Error(t, err)
+	})
+}
+
+var postmanCollectionJSON = `{
+  "info": {
+    "_postman_id": "4d7a1b1e-1c9b-4c1e-9a43-3f0a5b8d2c11",
+    "name": "Petstore",
+    "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+  },
+  "auth": {
+    "type": "bearer",
+    "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]
+  },
+  "variable": [
+    {"key": "baseUrl", "value": "https://petstore.example.com:8443/v1"},
+    {"key": "token", "value": "secret"}
+  ],
+  "item": [
+    {
+      "name": "Pets",
+      "item": [
+        {
+          "name": "List pets",
+          "request": {
+            "method": "GET",
+            "header": [],
+            "url": {
+              "raw": "{{baseUrl}}/pets?limit=10",
+              "host": ["{{baseUrl}}"],
+              "path": ["pets"],
+              "query": [{"key": "limit", "value": "10"}]
+            }
+          },
+          "response": [
+            {
+              "name": "Pets",
+              "status": "OK",
+              "code": 200,
+              "header": [
+                {"key": "Content-Type", "value": "application/json"},
+                {"key": "X-Debug", "value": "1", "disabled": true}
+              ],
+              "body": "[{\"id\":1,\"name\":\"Rex\"}]"
+            }
+          ]
+        },
+        {
+          "name": "Create pet",
+          "event": [
+            {"listen": "test", "script": {"exec": ["pm.test(\"created\", () => {})"], "type": "text/javascript"}}
+          ],
+          "request": {
+            "method": "POST",
+            "body": {"mode": "raw", "raw": "{\"name\":\"Rex\"}"},
+            "url": {
+              "raw": "{{baseUrl}}/pets",
+              "host": ["{{baseUrl}}"],
+              "path": ["pets"]
+            }
+          },
+          "response": []
+        },
+        {
+          "name": "Get pet",
+          "request": {
+            "method": "GET",
+            "url": {
+              "raw": "{{baseUrl}}/pets/:petId",
+              "host": ["{{baseUrl}}"],
+              "path": ["pets", ":petId"],
+              "variable": [{"key": "petId", "value": "1"}]
+            }
+          },
+          "response": [
+            {
+              "name": "Not found",
+              "code": 404,
+              "body": "{\"error\":\"not found\"}"
+            },
+            {
+              "name": "Found",
+              "code": 200,
+              "body": "{\"id\":1,\"name\":\"Rex\"}"
+            }
+          ]
+        },
+        {
+          "name": "Upload photo",
+          "request": {
+            "method": "put",
+            "url": "{{baseUrl}}/pets/{{petId}}/photos"
+          }
+        },
+        {
+          "name": "List pets again",
+          "request": {
+            "method": "GET",
+            "url": {
+              "raw": "{{baseUrl}}/pets",
+              "host": ["{{baseUrl}}"],
+              "path": ["pets"]
+            }
+          }
+        }
+      ]
+    },
+    {
+      "name": "Health",
+      "request": "http://status.example.com/health"
+    }
+  ]
+}`
@@ -85,6 +85,16 @@ type Authentication struct {
 	// CustomKeyLifetime contains configuration for the maximum retention period for access tokens.
 	CustomKeyLifetime *CustomKeyLifetime `bson:"customKeyLifetime,omitempty" json:"customKeyLifetime,omitempty"`
 
+	// AuthFailureLimit contains the configuration for locking out the clients failing to authenticate too often.
+	//
+	// Tyk classic API definition: `auth_failure_limit`.
+	AuthFailureLimit *AuthFailureLimit `bson:"authFailureLimit,omitempty" json:"authFailureLimit,omitempty"`
+
+	// ScopeAccess contains the configuration restricting the endpoints to the tokens granted the scopes mapped to them.
+	//
+	// Tyk classic API definition: `scope_access`.
+	ScopeAccess *ScopeAccess `bson:"scopeAccess,omitempty" json:"scopeAccess,omitempty"`
+
 	// SecurityProcessingMode controls how Tyk will process the OpenAPI `security` field if multiple security requirement objects are declared.
 	// - "legacy" (default): Only the first security requirement object will be processed; uses BaseIdentityProvider to create the session object.
 	// - "compliant": All security requirement objects will be processed, request will be authorized if any of these are validated; the origin for the session object will be determined dynamically based on the validated security requirement.
@@ -272,6 +282,127 @@ func (k *CustomKeyLifetime) ExtractTo(api *apidef.APIDefinition) {
 	}
 }
 
+// AuthFailureLimit holds the configuration for locking out the client IPs and the token prefixes failing to
+// authenticate too often. Once locked out, the requests of a client IP are answered with `429 Too Many Requests`
+// before their token is looked up, while a token prefix only locks out the tokens not found. The
+// `AuthFailureLockout` event is fired on lockout.
+type AuthFailureLimit struct {
+	// Enabled enables the lockout of the clients failing to authenticate.
+	//
+	// Tyk classic API definition: `auth_failure_limit.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// MaxFailures is the number of authentication failures allowed within Window, defaults to 10.
+	//
+	// Tyk classic API definition: `auth_failure_limit.max_failures`.
+	MaxFailures int `bson:"maxFailures,omitempty" json:"maxFailures,omitempty"`
+	// Window is the period in seconds the authentication failures are counted over, defaults to 60.
+	//
+	// Tyk classic API definition: `auth_failure_limit.window`.
+	Window int `bson:"window,omitempty" json:"window,omitempty"`
+	// LockoutDuration is the number of seconds a client IP or token prefix is locked out for, defaults to 300.
+	//
+	// Tyk classic API definition: `auth_failure_limit.lockout_duration`.
+	LockoutDuration int `bson:"lockoutDuration,omitempty" json:"lockoutDuration,omitempty"`
+	// TokenPrefixLength is the number of leading characters of the key IDs the failures are counted by, defaults to 8.
+	// The key ID of a generated token is the token without its org ID, or the ID decoded from it.
+	//
+	// Tyk classic API definition: `auth_failure_limit.token_prefix_length`.
+	TokenPrefixLength int `bson:"tokenPrefixLength,omitempty" json:"tokenPrefixLength,omitempty"`
+	// Shared counts the failures in Redis, so the lockouts apply across the gateways.
+	// It costs a Redis round trip per failing request to check the lockouts of the other gateways.
+	//
+	// Tyk classic API definition: `auth_failure_limit.shared`.
+	Shared bool `bson:"shared,omitempty" json:"shared,omitempty"`
+}
+
+// Fill fills *AuthFailureLimit from apidef.AuthFailureLimit.
+func (l *AuthFailureLimit) Fill(limit apidef.AuthFailureLimit) {
+	l.Enabled = limit.Enabled
+	l.MaxFailures = limit.MaxFailures
+	l.Window = limit.Window
+	l.LockoutDuration = limit.LockoutDuration
+	l.TokenPrefixLength = limit.TokenPrefixLength
+	l.Shared = limit.Shared
+}
+
+// ExtractTo extracts *AuthFailureLimit into *apidef.AuthFailureLimit.
+func (l *AuthFailureLimit) ExtractTo(limit *apidef.AuthFailureLimit) {
+	limit.Enabled = l.Enabled
+	limit.MaxFailures = l.MaxFailures
+	limit.Window = l.Window
+	limit.LockoutDuration = l.LockoutDuration
+	limit.TokenPrefixLength = l.TokenPrefixLength
+	limit.Shared = l.Shared
+}
+
+// ScopeAccess holds the configuration restricting the endpoints of the API to the tokens granted the scopes
+// mapped to them. The scopes are read from a claim of the JWTs, or from the `scope` metadata of the other
+// sessions, e.g. the scope granted to an OAuth access token. The requests to an endpoint not granted by the
+// scopes of the token are answered with `403 Forbidden`, naming the scopes granting the endpoint.
+// A request has to be allowed by the allowed URLs of the access rights too.
+type ScopeAccess struct {
+	// Enabled enables the scope checks.
+	//
+	// Tyk classic API definition: `scope_access.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// ClaimName is the JWT claim holding the scopes, as a space delimited string or an array. The nested claims
+	// are separated by dots. It defaults to the scope claim name of the JWT scopes, then to `scope`.
+	//
+	// Tyk classic API definition: `scope_access.claim_name`.
+	ClaimName string `bson:"claimName,omitempty" json:"claimName,omitempty"`
+	// ScopeToEndpoints maps the scopes to the endpoints they grant. The endpoints mapped to no scope aren't
+	// restricted, the others are allowed to the tokens granted one of the scopes mapped to them.
+	//
+	// Tyk classic API definition: `scope_access.scope_to_endpoints`.
+	ScopeToEndpoints map[string][]ScopeEndpoint `bson:"scopeToEndpoints,omitempty" json:"scopeToEndpoints,omitempty"`
+}
+
+// ScopeEndpoint is an endpoint granted by a scope.
+type ScopeEndpoint struct {
+	// Path is the path of the endpoint, a regular expression matched like the allowed URLs of the access rights.
+	//
+	// Tyk classic API definition: `scope_access.scope_to_endpoints[].path`.
+	Path string `bson:"path" json:"path"`
+	// Methods are the HTTP methods of the endpoint, all the methods when empty.
+	//
+	// Tyk classic API definition: `scope_access.scope_to_endpoints[].methods`.
+	Methods []string `bson:"methods,omitempty" json:"methods,omitempty"`
+}
+
+// Fill fills *ScopeAccess from apidef.ScopeAccess.
+func (s *ScopeAccess) Fill(access apidef.ScopeAccess) {
+	s.Enabled = access.Enabled
+	s.ClaimName = access.ClaimName
+
+	s.ScopeToEndpoints = nil
+	if len(access.ScopeToEndpoints) > 0 {
+		s.ScopeToEndpoints = make(map[string][]ScopeEndpoint, len(access.ScopeToEndpoints))
+	}
+
+	for scope, endpoints := range access.ScopeToEndpoints {
+		for _, endpoint := range endpoints {
+			s.ScopeToEndpoints[scope] = append(s.ScopeToEndpoints[scope], ScopeEndpoint{Path: endpoint.Path, Methods: endpoint.Methods})
+		}
+	}
+}
+
+// ExtractTo extracts *ScopeAccess into *apidef.ScopeAccess.
+func (s *ScopeAccess) ExtractTo(access *apidef.ScopeAccess) {
+	access.Enabled = s.Enabled
+	access.ClaimName = s.ClaimName
+
+	access.ScopeToEndpoints = nil
+	if len(s.ScopeToEndpoints) > 0 {
+		access.ScopeToEndpoints = make(map[string][]apidef.ScopeEndpoint, len(s.ScopeToEndpoints))
+	}
+
+	for scope, endpoints := range s.ScopeToEndpoints {
+		for _, endpoint := range endpoints {
+			access.ScopeToEndpoints[scope] = append(access.ScopeToEndpoints[scope], apidef.ScopeEndpoint{Path: endpoint.Path, Methods: endpoint.Methods})
+		}
+	}
+}
+
 // Fill fills *Authentication from apidef.APIDefinition.
 func (a *Authentication) Fill(api apidef.APIDefinition) {
 	a.Enabled = !api.UseKeylessAccess
@@ -298,6 +429,26 @@ func (a *Authentication) Fill(api apidef.APIDefinition) {
 		a.CustomKeyLifetime = nil
 	}
 
+	if a.AuthFailureLimit == nil {
+		a.AuthFailureLimit = &AuthFailureLimit{}
+	}
+
+	a.AuthFailureLimit.Fill(api.AuthFailureLimit)
+
+	if ShouldOmit(a.AuthFailureLimit) {
+		a.AuthFailureLimit = nil
+	}
+
+	if a.ScopeAccess == nil {
+		a.ScopeAccess = &ScopeAccess{}
+	}
+
+	a.ScopeAccess.Fill(api.ScopeAccess)
+
+	if ShouldOmit(a.ScopeAccess) {
+		a.ScopeAccess = nil
+	}
+
 	if api.AuthConfigs == nil || len(api.AuthConfigs) == 0 {
 		return
 	}
@@ -368,6 +519,24 @@ func (a *Authentication) ExtractTo(api *apidef.APIDefinition) {
 	}
 
 	a.CustomKeyLifetime.ExtractTo(api)
+
+	if a.AuthFailureLimit == nil {
+		a.AuthFailureLimit = &AuthFailureLimit{}
+		defer func() {
+			a.AuthFailureLimit = nil
+		}()
+	}
+
+	a.AuthFailureLimit.ExtractTo(&api.AuthFailureLimit)
+
+	if a.ScopeAccess == nil {
+		a.ScopeAccess = &ScopeAccess{}
+		defer func() {
+			a.ScopeAccess = nil
+		}()
+	}
+
+	a.ScopeAccess.ExtractTo(&api.ScopeAccess)
 }
 
 // SecuritySchemes holds security scheme values keyed by the scheme name declared in `components.securitySchemes`. Each value can be an `oauth2` scheme — see [OAuth2](#oauth2) for the full configuration contract.
@@ -980,6 +1149,11 @@ type AuthenticationPlugin struct {
 	//
 	// Tyk classic API definition: `custom_middleware.auth_check.raw_body_only`.
 	RawBodyOnly bool `bson:"rawBodyOnly,omitempty" json:"rawBodyOnly,omitempty"`
+	// StreamBody if set to true, streams the raw request body to a gRPC plugin in chunks, so it isn't
+	// limited by the gRPC max message sizes.
+	//
+	// Tyk classic API definition: `custom_middleware.auth_check.stream_body`.
+	StreamBody bool `bson:"streamBody,omitempty" json:"streamBody,omitempty"`
 	// RequireSession passes down the session information for plugins after authentication.
 	//
 	// Tyk classic API definition: `custom_middleware.auth_check.require_session`.
@@ -994,6 +1168,7 @@ func (ap *AuthenticationPlugin) Fill(api apidef.APIDefinition) {
 	ap.FunctionName = api.CustomMiddleware.AuthCheck.Name
 	ap.Path = api.CustomMiddleware.AuthCheck.Path
 	ap.RawBodyOnly = api.CustomMiddleware.AuthCheck.RawBodyOnly
+	ap.StreamBody = api.CustomMiddleware.AuthCheck.StreamBody
 	ap.RequireSession = api.CustomMiddleware.AuthCheck.RequireSession
 	ap.Enabled = !api.CustomMiddleware.AuthCheck.Disabled
 	if ap.IDExtractor == nil {
@@ -1011,6 +1186,7 @@ func (ap *AuthenticationPlugin) ExtractTo(api *apidef.APIDefinition) {
 	api.CustomMiddleware.AuthCheck.Name = ap.FunctionName
 	api.CustomMiddleware.AuthCheck.Path = ap.Path
 	api.CustomMiddleware.AuthCheck.RawBodyOnly = ap.RawBodyOnly
+	api.CustomMiddleware.AuthCheck.StreamBody = ap.StreamBody
 	api.CustomMiddleware.AuthCheck.RequireSession = ap.RequireSession
 
 	if ap.IDExtractor == nil {
@@ -24,6 +24,82 @@ func TestAuthentication(t *testing.T) {
 	assert.Equal(t, emptyAuthentication, resultAuthentication)
 }
 
+func TestAuthFailureLimit(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		var auth Authentication
+		auth.Fill(apidef.APIDefinition{})
+		assert.Nil(t, auth.AuthFailureLimit)
+
+		var api apidef.APIDefinition
+		auth.ExtractTo(&api)
+		assert.Equal(t, apidef.AuthFailureLimit{}, api.AuthFailureLimit)
+	})
+
+	t.Run("fill and extract", func(t *testing.T) {
+		limit := apidef.AuthFailureLimit{
+			Enabled:           true,
+			MaxFailures:       5,
+			Window:            30,
+			LockoutDuration:   600,
+			TokenPrefixLength: 12,
+			Shared:            true,
+		}
+
+		var auth Authentication
+		auth.Fill(apidef.APIDefinition{AuthFailureLimit: limit})
+		assert.Equal(t, &AuthFailureLimit{
+			Enabled:           true,
+			MaxFailures:       5,
+			Window:            30,
+			LockoutDuration:   600,
+			TokenPrefixLength: 12,
+			Shared:            true,
+		}, auth.AuthFailureLimit)
+
+		var api apidef.APIDefinition
+		auth.ExtractTo(&api)
+		assert.Equal(t, limit, api.AuthFailureLimit)
+	})
+}
+
+func TestScopeAccess(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		var auth Authentication
+		auth.Fill(apidef.APIDefinition{})
+		assert.Nil(t, auth.ScopeAccess)
+
+		var api apidef.APIDefinition
+		auth.ExtractTo(&api)
+		assert.Equal(t, apidef.ScopeAccess{}, api.ScopeAccess)
+	})
+
+	t.Run("fill and extract", func(t *testing.T) {
+		access := apidef.ScopeAccess{
+			Enabled:   true,
+			ClaimName: "permissions.scopes",
+			ScopeToEndpoints: map[string][]apidef.ScopeEndpoint{
+				"orders:read":  {{Path: "/orders", Methods: []string{"GET"}}},
+				"orders:write": {{Path: "/orders", Methods: []string{"POST", "PUT"}}, {Path: "/orders/{id}"}},
+			},
+		}
+
+		var auth Authentication
+		auth.Fill(apidef.APIDefinition{ScopeAccess: access})
+		assert.Equal(t, &ScopeAccess{
+			Enabled:   true,
+			ClaimName: "permissions.scopes",
+			ScopeToEndpoints: map[string][]ScopeEndpoint{
+				"orders:read":  {{Path: "/orders", Methods: []string{"GET"}}},
+				"orders:write": {{Path: "/orders", Methods: []string{"POST", "PUT"}}, {Path: "/orders/{id}"}},
+			},
+		}, auth.ScopeAccess)
+
+		var api apidef.APIDefinition
+		auth.ExtractTo(&api)
+		assert.Equal(t, access, api.ScopeAccess)
+	})
+}
+
 func TestScopes(t *testing.T) {
 	t.Run("default", func(t *testing.T) {
 		var emptyScopes Scopes
@@ -0,0 +1,282 @@
+package oas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	tykreflect "github.com/TykTechnologies/tyk/internal/reflect"
+)
+
+// ExtensionTykWarnings is the OAS schema key listing the parts of a classic API definition which a document
+// generated by NewDocumentationFromClassicAPIDefinition doesn't describe.
+const ExtensionTykWarnings = "x-tyk-warnings"
+
+// NewDocumentationFromClassicAPIDefinition synthesizes an OAS document describing a classic API to its consumers.
+// The API and its versions are converted with MigrateAndFillOAS and merged into a single document served from
+// serverURL, without the Tyk extension. Mock responses are documented as examples and blocked endpoints as forbidden,
+// internal endpoints are left out. The lossy parts of the conversion are listed in the x-tyk-warnings extension.
+func NewDocumentationFromClassicAPIDefinition(api *apidef.APIDefinition, serverURL string) (*OAS, error) {
+	// the migration modifies the definition
+	api = tykreflect.Clone(api)
+	warnings := classicDocumentationWarnings(api)
+
+	base, versions, err := MigrateAndFillOAS(api)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &OAS{T: openapi3.T{
+		OpenAPI:    DefaultOpenAPI,
+		Info:       base.OAS.Info,
+		Paths:      openapi3.NewPaths(),
+		Components: base.OAS.Components,
+		Security:   base.OAS.Security,
+	}}
+
+	if serverURL != "" {
+		doc.Servers = openapi3.Servers{{URL: serverURL}}
+	}
+
+	versioning := api.VersionDefinition
+	for _, def := range append([]APIDef{base}, versions...) {
+		name := def.Classic.VersionName
+		if !documentOperations(def.OAS) {
+			warnings = append(warnings, versionWarning(versioning.Enabled, name,
+				"requests to the paths without extended path configuration are proxied to the upstream and aren't described"))
+		}
+
+		for _, path := range def.OAS.Paths.InMatchingOrder() {
+			item := def.OAS.Paths.Value(path)
+
+			switch {
+			case !versioning.Enabled:
+				doc.Paths.Set(path, item)
+			case versioning.Location == apidef.URLLocation:
+				for _, op := range item.Operations() {
+					op.OperationID = name + "/" + op.OperationID
+				}
+				doc.Paths.Set("/"+name+path, item)
+			default:
+				warnings = append(warnings, mergeVersionOperations(doc, versioning, name, path, item)...)
+			}
+		}
+	}
+
+	if len(warnings) > 0 {
+		doc.Extensions = map[string]interface{}{ExtensionTykWarnings: warnings}
+	}
+
+	if err := doc.T.Validate(context.Background(), openapi3.DisableExamplesValidation()); err != nil {
+		return nil, fmt.Errorf("generated documentation isn't valid: %w", err)
+	}
+
+	return doc, nil
+}
+
+// classicDocumentationWarnings lists the features of the classic API definition which can't be described.
+func classicDocumentationWarnings(api *apidef.APIDefinition) []string {
+	var warnings []string
+
+	for name, enabled := range map[string]bool{
+		"HMAC signature": api.EnableSignatureChecking,
+		"OpenID Connect": api.UseOpenID,
+		"mutual TLS":     api.UseMutualTLSAuth,
+		"custom plugin":  api.CustomPluginAuthEnabled || api.EnableCoProcessAuth || api.UseGoPluginAuth,
+	} {
+		if enabled {
+			warnings = append(warnings, name+" authentication can't be described by a security scheme")
+		}
+	}
+
+	if api.GraphQL.Enabled {
+		warnings = append(warnings, "the GraphQL schema isn't described, GraphQL requests are described as plain HTTP requests")
+	}
+
+	versioned := !api.VersionData.NotVersioned
+	for name, vInfo := range api.VersionData.Versions {
+		if !vInfo.UseExtendedPaths && (len(vInfo.Paths.Ignored) > 0 || len(vInfo.Paths.WhiteList) > 0 || len(vInfo.Paths.BlackList) > 0) {
+			warnings = append(warnings, versionWarning(versioned, name, "the legacy paths aren't described, only the extended paths are"))
+		}
+
+		// Clear keeps the extended paths without an OAS conversion.
+		unconverted := vInfo.ExtendedPaths
+		unconverted.Clear()
+		for _, field := range nonEmptyJSONFields(unconverted) {
+			warnings = append(warnings, versionWarning(versioned, name, "the "+field+" extended paths aren't described"))
+		}
+	}
+
+	sort.Strings(warnings)
+	return warnings
+}
+
+func versionWarning(versioned bool, name, warning string) string {
+	if !versioned {
+		return warning
+	}
+
+	return fmt.Sprintf("version %s: %s", name, warning)
+}
+
+// nonEmptyJSONFields returns the names of the fields of v which aren't empty once encoded.
+func nonEmptyJSONFields(v interface{}) []string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil
+	}
+
+	var names []string
+	for name, value := range fields {
+		switch string(value) {
+		case "null", "[]", "{}", `""`, "false", "0":
+			continue
+		}
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// documentOperations describes the endpoint configuration of the Tyk extension in the operations of s, then removes
+// the extension. It reports whether the API has an allow list, so only the described paths can be requested.
+func documentOperations(s *OAS) (allowList bool) {
+	tykOperations := s.getTykOperations()
+	s.RemoveTykExtension()
+
+	for _, path := range s.Paths.InMatchingOrder() {
+		item := s.Paths.Value(path)
+
+		for method, op := range item.Operations() {
+			tykOp := tykOperations[op.OperationID]
+			if tykOp == nil {
+				continue
+			}
+
+			if tykOp.Allow != nil && tykOp.Allow.Enabled {
+				allowList = true
+			}
+
+			if tykOp.Internal != nil && tykOp.Internal.Enabled {
+				item.SetOperation(method, nil)
+				continue
+			}
+
+			switch {
+			case tykOp.Block != nil && tykOp.Block.Enabled:
+				op.Responses = openapi3.NewResponses(openapi3.WithStatus(http.StatusForbidden, &openapi3.ResponseRef{
+					Value: openapi3.NewResponse().WithDescription("The endpoint is blocked."),
+				}))
+			case tykOp.MockResponse != nil && tykOp.MockResponse.Enabled:
+				documentMockResponse(op, tykOp.MockResponse)
+			}
+
+			if tykOp.IgnoreAuthentication != nil && tykOp.IgnoreAuthentication.Enabled {
+				op.Security = openapi3.NewSecurityRequirements()
+			}
+		}
+
+		if len(item.Operations()) == 0 {
+			s.Paths.Delete(path)
+		}
+	}
+
+	return allowList
+}
+
+// documentMockResponse sets the mock response as the example of the response of op.
+func documentMockResponse(op *openapi3.Operation, mock *MockResponse) {
+	code := mock.Code
+	if code == 0 {
+		code = http.StatusOK
+	}
+
+	response := openapi3.NewResponse().WithDescription(http.StatusText(code))
+
+	contentType := "text/plain"
+	if json.Valid([]byte(mock.Body)) {
+		contentType = contentTypeJSON
+	}
+
+	for _, header := range mock.Headers {
+		if strings.EqualFold(header.Name, "Content-Type") {
+			contentType = header.Value
+			continue
+		}
+
+		if response.Headers == nil {
+			response.Headers = openapi3.Headers{}
+		}
+
+		response.Headers[header.Name] = &openapi3.HeaderRef{Value: &openapi3.Header{Parameter: openapi3.Parameter{
+			Schema:  openapi3.NewStringSchema().NewRef(),
+			Example: header.Value,
+		}}}
+	}
+
+	if mock.Body != "" {
+		var example interface{} = mock.Body
+		if strings.Contains(contentType, "json") {
+			_ = json.Unmarshal([]byte(mock.Body), &example) // nolint:errcheck
+		}
+
+		response.Content = openapi3.Content{contentType: &openapi3.MediaType{Example: example}}
+	}
+
+	op.Responses = openapi3.NewResponses(openapi3.WithStatus(code, &openapi3.ResponseRef{Value: response}))
+}
+
+// mergeVersionOperations adds the operations of a version selected by a header or query parameter to doc. The operations
+// shared by several versions are described once, with the versions serving them listed by the version parameter.
+func mergeVersionOperations(doc *OAS, versioning apidef.VersionDefinition, name, path string, item *openapi3.PathItem) (warnings []string) {
+	existing := doc.Paths.Value(path)
+	if existing == nil {
+		existing = &openapi3.PathItem{Parameters: item.Parameters}
+		doc.Paths.Set(path, existing)
+	}
+
+	in := openapi3.ParameterInHeader
+	if versioning.Location == apidef.URLParamLocation {
+		in = openapi3.ParameterInQuery
+	}
+
+	for method, op := range item.Operations() {
+		existingOp := existing.GetOperation(method)
+		if existingOp == nil {
+			op.Parameters = append(op.Parameters, &openapi3.ParameterRef{Value: &openapi3.Parameter{
+				Name:     versioning.Key,
+				In:       in,
+				Required: versioning.Default == "",
+				Schema:   openapi3.NewStringSchema().WithEnum(name).NewRef(),
+			}})
+			existing.SetOperation(method, op)
+			continue
+		}
+
+		for _, param := range existingOp.Parameters {
+			if param.Value != nil && param.Value.Name == versioning.Key && param.Value.In == in {
+				param.Value.Schema.Value.Enum = append(param.Value.Schema.Value.Enum, name)
+			}
+		}
+
+		if !reflect.DeepEqual(existingOp.Responses, op.Responses) {
+			warnings = append(warnings, versionWarning(true, name, fmt.Sprintf(
+				"the responses of %s %s differ from another version, only the responses of the first version are described", method, path)))
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,178 @@
+package oas
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+func TestNewDocumentationFromClassicAPIDefinition(t *testing.T) {
+	endpoint := func(path, method string) apidef.EndPointMeta {
+		return apidef.EndPointMeta{Path: path, MethodActions: map[string]apidef.EndpointMethodMeta{
+			method: {Action: apidef.NoAction},
+		}}
+	}
+
+	newAPI := func() *apidef.APIDefinition {
+		var api apidef.APIDefinition
+		api.SetDisabledFlags()
+		api.Name = "Petstore"
+		api.Proxy.ListenPath = "/petstore/"
+		api.UseStandardAuth = true
+		api.AuthConfigs = map[string]apidef.AuthConfig{
+			apidef.AuthTokenType: {Name: "authToken", AuthHeaderName: "Authorization"},
+		}
+		api.EnableSignatureChecking = true
+		api.VersionDefinition.Key = "x-api-version"
+		api.VersionDefinition.Location = apidef.HeaderLocation
+		api.VersionData.DefaultVersion = "v1"
+		api.VersionData.Versions = map[string]apidef.VersionInfo{
+			"v1": {
+				UseExtendedPaths: true,
+				ExtendedPaths: apidef.ExtendedPathsSet{
+					WhiteList: []apidef.EndPointMeta{
+						endpoint("/pets", http.MethodGet),
+						{Path: "/pets/{id}", MethodActions: map[string]apidef.EndpointMethodMeta{
+							http.MethodGet: {Action: apidef.Reply, Code: http.StatusOK, Data: `{"name":"rex"}`,
+								Headers: map[string]string{"X-Mock": "true"}},
+						}},
+					},
+					BlackList: []apidef.EndPointMeta{endpoint("/admin", http.MethodDelete)},
+					Ignored:   []apidef.EndPointMeta{endpoint("/health", http.MethodGet)},
+					MockResponse: []apidef.MockResponseMeta{
+						{Path: "/version", Method: http.MethodGet, Code: http.StatusAccepted, Body: "v1",
+							Headers: map[string]string{"Content-Type": "text/plain"}},
+					},
+					Internal:    []apidef.InternalMeta{{Path: "/internal", Method: http.MethodPost}},
+					TransformJQ: []apidef.TransformJQMeta{{Path: "/pets", Method: http.MethodPost, Filter: "."}},
+				},
+			},
+			"v2": {
+				UseExtendedPaths: true,
+				ExtendedPaths: apidef.ExtendedPathsSet{
+					WhiteList: []apidef.EndPointMeta{
+						endpoint("/pets", http.MethodGet),
+						endpoint("/owners/[0-9]+", http.MethodGet),
+					},
+					MockResponse: []apidef.MockResponseMeta{
+						{Path: "/version", Method: http.MethodGet, Code: http.StatusAccepted, Body: "v2"},
+					},
+				},
+			},
+		}
+		return &api
+	}
+
+	// load validates the generated document with the openapi3 loader, as consumers would.
+	load := func(t *testing.T, doc *OAS) *openapi3.T {
+		t.Helper()
+
+		b, err := json.Marshal(doc)
+		require.NoError(t, err)
+
+		loaded, err := openapi3.NewLoader().LoadFromData(b)
+		require.NoError(t, err)
+		require.NoError(t, loaded.Validate(context.Background()))
+		assert.NotContains(t, loaded.Extensions, ExtensionTykAPIGateway)
+
+		return loaded
+	}
+
+	t.Run("header versioning", func(t *testing.T) {
+		api := newAPI()
+		doc, err := NewDocumentationFromClassicAPIDefinition(api, "http://gateway.local/petstore/")
+		require.NoError(t, err)
+		assert.False(t, api.IsOAS, "the definition isn't modified")
+
+		loaded := load(t, doc)
+		assert.Equal(t, "Petstore", loaded.Info.Title)
+		assert.Equal(t, "v1", loaded.Info.Version)
+		assert.Equal(t, "http://gateway.local/petstore/", loaded.Servers[0].URL)
+		assert.Contains(t, loaded.Components.SecuritySchemes, "authToken")
+
+		pets := loaded.Paths.Value("/pets").Get
+		require.NotNil(t, pets)
+		require.Len(t, pets.Parameters, 1)
+		assert.Equal(t, "x-api-version", pets.Parameters[0].Value.Name)
+		assert.Equal(t, openapi3.ParameterInHeader, pets.Parameters[0].Value.In)
+		assert.False(t, pets.Parameters[0].Value.Required, "the default version is served without the header")
+		assert.Equal(t, []interface{}{"v1", "v2"}, pets.Parameters[0].Value.Schema.Value.Enum)
+
+		pet := loaded.Paths.Value("/pets/{id}").Get
+		require.NotNil(t, pet)
+		response := pet.Responses.Status(http.StatusOK).Value
+		assert.Equal(t, map[string]interface{}{"name": "rex"}, response.Content.Get(contentTypeJSON).Example)
+		assert.Equal(t, "true", response.Headers["X-Mock"].Value.Example)
+		assert.Empty(t, *pet.Security, "mock responses don't require authentication")
+
+		version := loaded.Paths.Value("/version").Get.Responses.Status(http.StatusAccepted).Value
+		assert.Equal(t, "v1", version.Content.Get("text/plain").Example)
+
+		blocked := loaded.Paths.Value("/admin").Delete
+		require.NotNil(t, blocked)
+		assert.NotNil(t, blocked.Responses.Status(http.StatusForbidden))
+
+		assert.Empty(t, *loaded.Paths.Value("/health").Get.Security)
+		assert.Nil(t, loaded.Paths.Value("/internal"), "internal endpoints aren't described")
+
+		owner := loaded.Paths.Value("/owners/{customRegex1}")
+		require.NotNil(t, owner)
+		assert.Equal(t, []interface{}{"v2"}, owner.Get.Parameters[0].Value.Schema.Value.Enum)
+
+		assert.ElementsMatch(t, []interface{}{
+			"HMAC signature authentication can't be described by a security scheme",
+			"version v1: the transform_jq extended paths aren't described",
+			"version v2: the responses of GET /version differ from another version, only the responses of the first version are described",
+		}, loaded.Extensions[ExtensionTykWarnings])
+	})
+
+	t.Run("url versioning", func(t *testing.T) {
+		api := newAPI()
+		api.VersionDefinition.Location = apidef.URLLocation
+		api.EnableSignatureChecking = false
+
+		doc, err := NewDocumentationFromClassicAPIDefinition(api, "")
+		require.NoError(t, err)
+
+		loaded := load(t, doc)
+		assert.Empty(t, loaded.Servers)
+		assert.NotNil(t, loaded.Paths.Value("/v1/pets").Get)
+		assert.NotNil(t, loaded.Paths.Value("/v2/pets").Get)
+		assert.Nil(t, loaded.Paths.Value("/v1/owners/{customRegex1}"))
+		assert.NotEqual(t, loaded.Paths.Value("/v1/pets").Get.OperationID, loaded.Paths.Value("/v2/pets").Get.OperationID)
+	})
+
+	t.Run("not versioned without allow list", func(t *testing.T) {
+		var api apidef.APIDefinition
+		api.SetDisabledFlags()
+		api.Name = "Open"
+		api.Proxy.ListenPath = "/open/"
+		api.UseKeylessAccess = true
+		api.VersionData.NotVersioned = true
+		api.VersionData.Versions = map[string]apidef.VersionInfo{
+			"Default": {
+				UseExtendedPaths: true,
+				ExtendedPaths: apidef.ExtendedPathsSet{
+					HardTimeouts: []apidef.HardTimeoutMeta{{Path: "/slow", Method: http.MethodGet, TimeOut: 10}},
+				},
+			},
+		}
+
+		doc, err := NewDocumentationFromClassicAPIDefinition(&api, "")
+		require.NoError(t, err)
+
+		loaded := load(t, doc)
+		assert.NotNil(t, loaded.Paths.Value("/slow").Get)
+		assert.Nil(t, loaded.Security)
+		assert.Equal(t, []interface{}{
+			"requests to the paths without extended path configuration are proxied to the upstream and aren't described",
+		}, loaded.Extensions[ExtensionTykWarnings])
+	})
+}
@@ -171,3 +171,43 @@ func (er ErrorResponse) ExtractTo(api *apidef.ErrorResponse) {
 	api.Template = er.Template
 	api.Headers = er.Headers
 }
+
+// ErrorTemplates maps status codes, or `default`, to the templates of their error bodies.
+type ErrorTemplates map[string]ErrorTemplate
+
+// ErrorTemplate holds the Go templates of an error body, one per format. The format is picked
+// from the Accept header of the request.
+type ErrorTemplate struct {
+	// JSON is the template of the error body of requests accepting JSON.
+	// Tyk classic API definition: `error_templates[code].json`
+	JSON string `bson:"json,omitempty" json:"json,omitempty"`
+
+	// XML is the template of the error body of requests accepting XML.
+	// Tyk classic API definition: `error_templates[code].xml`
+	XML string `bson:"xml,omitempty" json:"xml,omitempty"`
+}
+
+// Fill fills *ErrorTemplates from apidef.APIDefinition.
+func (e *ErrorTemplates) Fill(api apidef.APIDefinition) {
+	if len(api.ErrorTemplates) == 0 {
+		return
+	}
+
+	*e = make(ErrorTemplates, len(api.ErrorTemplates))
+	for code, tmpl := range api.ErrorTemplates {
+		(*e)[code] = ErrorTemplate{JSON: tmpl.JSON, XML: tmpl.XML}
+	}
+}
+
+// ExtractTo extracts *ErrorTemplates into *apidef.APIDefinition.
+func (e *ErrorTemplates) ExtractTo(api *apidef.APIDefinition) {
+	if len(*e) == 0 {
+		api.ErrorTemplates = nil
+		return
+	}
+
+	api.ErrorTemplates = make(map[string]apidef.ErrorTemplate, len(*e))
+	for code, tmpl := range *e {
+		api.ErrorTemplates[code] = apidef.ErrorTemplate{JSON: tmpl.JSON, XML: tmpl.XML}
+	}
+}
@@ -311,3 +311,33 @@ func TestErrorResponse_ExtractTo(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorTemplates_FillAndExtract(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		var x XTykAPIGateway
+		x.Fill(apidef.APIDefinition{})
+		assert.Nil(t, x.ErrorTemplates)
+
+		var api apidef.APIDefinition
+		x.ExtractTo(&api)
+		assert.Nil(t, api.ErrorTemplates)
+	})
+
+	t.Run("fill and extract", func(t *testing.T) {
+		errorTemplates := map[string]apidef.ErrorTemplate{
+			"404":                       {JSON: `{"error":"{{.Message}}"}`, XML: `<error>{{.Message}}</error>`},
+			apidef.ErrorTemplateDefault: {JSON: `{"error":"{{.Message}}","api":"{{.APIName}}"}`},
+		}
+
+		var x XTykAPIGateway
+		x.Fill(apidef.APIDefinition{ErrorTemplates: errorTemplates})
+		assert.Equal(t, ErrorTemplates{
+			"404":     {JSON: `{"error":"{{.Message}}"}`, XML: `<error>{{.Message}}</error>`},
+			"default": {JSON: `{"error":"{{.Message}}","api":"{{.APIName}}"}`},
+		}, x.ErrorTemplates)
+
+		var api apidef.APIDefinition
+		x.ExtractTo(&api)
+		assert.Equal(t, errorTemplates, api.ErrorTemplates)
+	})
+}
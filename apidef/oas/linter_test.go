@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/internal/event"
 	"github.com/TykTechnologies/tyk/internal/service/gojsonschema"
 	"github.com/TykTechnologies/tyk/internal/time"
@@ -20,9 +21,15 @@ import (
 func fixSingleOperation(op *Operation) {
 	if op.TransformRequestBody != nil {
 		op.TransformRequestBody.Format = "json"
+		if op.TransformRequestBody.SOAP != nil {
+			op.TransformRequestBody.SOAP.Version = "1.1"
+		}
 	}
 	if op.TransformResponseBody != nil {
 		op.TransformResponseBody.Format = "json"
+		if op.TransformResponseBody.SOAP != nil {
+			op.TransformResponseBody.SOAP.Version = "1.1"
+		}
 	}
 	if op.RateLimit != nil {
 		op.RateLimit.Per = ReadableDuration(time.Minute)
@@ -102,6 +109,7 @@ func TestXTykGateway_Lint(t *testing.T) {
 		settings.Middleware.Global.TrafficLogs.CustomRetentionPeriod = ReadableDuration(10 * time.Second)
 		for i := range settings.Middleware.Global.TrafficLogs.Plugins {
 			settings.Middleware.Global.TrafficLogs.Plugins[i].RawBodyOnly = false
+			settings.Middleware.Global.TrafficLogs.Plugins[i].StreamBody = false
 			settings.Middleware.Global.TrafficLogs.Plugins[i].RequireSession = false
 		}
 
@@ -110,10 +118,20 @@ func TestXTykGateway_Lint(t *testing.T) {
 			operation.EnforceTimeout.Duration = ReadableDuration(2 * time.Second)
 		}
 
-		settings.Upstream.Authentication = &UpstreamAuth{
-			Enabled:   false,
-			BasicAuth: nil,
-			OAuth:     nil,
+		// Fill populates the upstream authentication with values outside of the schema constraints.
+		if oauth := settings.Upstream.Authentication.OAuth; oauth != nil {
+			oauth.AllowedAuthorizeTypes = []string{apidef.OAuthAuthorizationTypeClientCredentials, apidef.OAuthAuthorizationTypePassword}
+			if oauth.ClientCredentials != nil {
+				oauth.ClientCredentials.ClientID = "client-id"
+				oauth.ClientCredentials.ClientSecret = "client-secret"
+			}
+			if oauth.PasswordAuthentication != nil {
+				oauth.PasswordAuthentication.ClientID = "client-id"
+			}
+		}
+
+		if hmacSigning := settings.Upstream.Authentication.HMACSigning; hmacSigning != nil {
+			hmacSigning.Algorithm = "hmac-sha256"
 		}
 
 		settings.Upstream.UptimeTests = &UptimeTests{
@@ -145,6 +163,42 @@ func TestXTykGateway_Lint(t *testing.T) {
 		if settings.Upstream.EnforceTimeout != nil {
 			settings.Upstream.EnforceTimeout.Duration = ReadableDuration(5 * time.Second)
 		}
+		if settings.Upstream.Canary != nil {
+			settings.Upstream.Canary.URL = "http://canary.example.com"
+			settings.Upstream.Canary.Percentage = 50
+			for i := range settings.Upstream.Canary.Rules {
+				settings.Upstream.Canary.Rules[i].Source = "header"
+			}
+		}
+
+		if settings.Upstream.Mirror != nil {
+			settings.Upstream.Mirror.URL = "http://mirror.example.com"
+			settings.Upstream.Mirror.Percentage = 50
+		}
+		if settings.Upstream.WebSocket != nil {
+			settings.Upstream.WebSocket.MaxMessageSize = 1024
+			settings.Upstream.WebSocket.IdleTimeout = ReadableDuration(30 * time.Second)
+			settings.Upstream.WebSocket.MaxConnectionDuration = ReadableDuration(time.Hour)
+		}
+		if settings.Upstream.ConcurrencyLimit != nil {
+			settings.Upstream.ConcurrencyLimit.StatusCode = http.StatusServiceUnavailable
+			settings.Upstream.ConcurrencyLimit.RetryAfter = ReadableDuration(time.Second)
+		}
+		if settings.Middleware.Global.MaintenanceMode != nil {
+			settings.Middleware.Global.MaintenanceMode.StatusCode = http.StatusServiceUnavailable
+			settings.Middleware.Global.MaintenanceMode.RetryAfter = ReadableDuration(30 * time.Second)
+		}
+		if settings.Middleware.Global.CORS != nil {
+			for i := range settings.Middleware.Global.CORS.Paths {
+				settings.Middleware.Global.CORS.Paths[i].Path = "/widgets"
+			}
+		}
+		if settings.Middleware.Global.ResponseCompression != nil {
+			settings.Middleware.Global.ResponseCompression.Algorithms = []string{"br", "gzip"}
+		}
+		if len(settings.ErrorTemplates) > 0 {
+			settings.ErrorTemplates = ErrorTemplates{"404": {JSON: `{"error":"{{.Message}}"}`}}
+		}
 
 		if settings.Info.Versioning != nil {
 			switch settings.Info.Versioning.Location {
@@ -152,6 +206,14 @@ func TestXTykGateway_Lint(t *testing.T) {
 			default:
 				settings.Info.Versioning.Location = "header"
 			}
+			for i := range settings.Info.Versioning.Sources {
+				settings.Info.Versioning.Sources[i].Location = "url-param"
+			}
+			for i := range settings.Info.Versioning.Sunset {
+				settings.Info.Versioning.Sunset[i].Date = "2030-01-01T00:00:00Z"
+				settings.Info.Versioning.Sunset[i].Deprecation = "2029-01-01T00:00:00Z"
+				settings.Info.Versioning.Sunset[i].Link = "https://example.com/deprecation"
+			}
 		}
 	}
 
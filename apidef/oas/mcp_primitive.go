@@ -96,6 +96,7 @@ func (m *MCPPrimitive) ExtractToExtendedPaths(ep *apidef.ExtendedPathsSet, path
 	m.extractDoNotTrackEndpointTo(ep, path, method)
 	m.extractRequestSizeLimitTo(ep, path, method)
 	m.extractRateLimitEndpointTo(ep, path, method)
+	m.extractRateCostTo(ep, path, method)
 }
 
 // MCPPrimitives maps primitive names to their middleware configurations.
@@ -160,9 +160,33 @@ type Global struct {
 	// RequestSizeLimit contains the configuration related to limiting the global request size.
 	RequestSizeLimit *GlobalRequestSizeLimit `bson:"requestSizeLimit,omitempty" json:"requestSizeLimit,omitempty"`
 
+	// MaintenanceMode contains the configuration for responding with a static response while the upstream is under maintenance.
+	// Tyk classic API definition: `maintenance_mode`.
+	MaintenanceMode *MaintenanceMode `bson:"maintenanceMode,omitempty" json:"maintenanceMode,omitempty"`
+
 	// IgnoreCase contains the configuration to treat routes as case-insensitive.
 	IgnoreCase *IgnoreCase `bson:"ignoreCase,omitempty" json:"ignoreCase,omitempty"`
 
+	// RequestID contains the configuration for the correlation ID of the requests.
+	// Tyk classic API definition: `request_id`.
+	RequestID *RequestID `bson:"requestId,omitempty" json:"requestId,omitempty"`
+
+	// Idempotency contains the configuration for replaying the responses of retried POST requests.
+	// Tyk classic API definition: `idempotency`.
+	Idempotency *Idempotency `bson:"idempotency,omitempty" json:"idempotency,omitempty"`
+
+	// ResponseCompression contains the configuration for compressing the responses of upstreams which don't.
+	// Tyk classic API definition: `response_compression`.
+	ResponseCompression *ResponseCompression `bson:"responseCompression,omitempty" json:"responseCompression,omitempty"`
+
+	// LatencyBreakdown contains the configuration for recording the time spent in each stage of the requests.
+	// Tyk classic API definition: `latency_breakdown`.
+	LatencyBreakdown *LatencyBreakdown `bson:"latencyBreakdown,omitempty" json:"latencyBreakdown,omitempty"`
+
+	// PayloadSizeAlerts contains the configuration for firing an event on unusually large requests or responses.
+	// Tyk classic API definition: `payload_size_alerts`.
+	PayloadSizeAlerts *PayloadSizeAlerts `bson:"payloadSizeAlerts,omitempty" json:"payloadSizeAlerts,omitempty"`
+
 	// SkipRateLimit determines whether the rate-limiting middleware logic should be skipped.
 	// Tyk classic API definition: `disable_rate_limit`.
 	SkipRateLimit bool `bson:"skipRateLimit,omitempty" json:"skipRateLimit,omitempty"`
@@ -229,6 +253,7 @@ func (g *Global) Fill(api apidef.APIDefinition) {
 	}
 
 	g.CORS.Fill(api.CORS)
+	g.CORS.fillPaths(api.VersionData.Versions[Main].ExtendedPaths.CORS)
 	if ShouldOmit(g.CORS) {
 		g.CORS = nil
 	}
@@ -276,9 +301,10 @@ func (g *Global) Fill(api apidef.APIDefinition) {
 
 	vInfo := api.VersionData.Versions[Main]
 	g.TransformRequestHeaders.Fill(apidef.HeaderInjectionMeta{
-		Disabled:      vInfo.GlobalHeadersDisabled,
-		AddHeaders:    vInfo.GlobalHeaders,
-		DeleteHeaders: vInfo.GlobalHeadersRemove,
+		Disabled:       vInfo.GlobalHeadersDisabled,
+		AddHeaders:     vInfo.GlobalHeaders,
+		DeleteHeaders:  vInfo.GlobalHeadersRemove,
+		RewriteHeaders: vInfo.GlobalHeadersRewrite,
 	})
 	if ShouldOmit(g.TransformRequestHeaders) {
 		g.TransformRequestHeaders = nil
@@ -289,9 +315,10 @@ func (g *Global) Fill(api apidef.APIDefinition) {
 	}
 
 	g.TransformResponseHeaders.Fill(apidef.HeaderInjectionMeta{
-		Disabled:      vInfo.GlobalResponseHeadersDisabled,
-		AddHeaders:    vInfo.GlobalResponseHeaders,
-		DeleteHeaders: vInfo.GlobalResponseHeadersRemove,
+		Disabled:       vInfo.GlobalResponseHeadersDisabled,
+		AddHeaders:     vInfo.GlobalResponseHeaders,
+		DeleteHeaders:  vInfo.GlobalResponseHeadersRemove,
+		RewriteHeaders: vInfo.GlobalResponseHeadersRewrite,
 	})
 	if ShouldOmit(g.TransformResponseHeaders) {
 		g.TransformResponseHeaders = nil
@@ -305,6 +332,18 @@ func (g *Global) Fill(api apidef.APIDefinition) {
 
 	g.fillRequestSizeLimit(api)
 
+	g.fillMaintenanceMode(api)
+
+	g.fillRequestID(api)
+
+	g.fillIdempotency(api)
+
+	g.fillResponseCompression(api)
+
+	g.fillLatencyBreakdown(api)
+
+	g.fillPayloadSizeAlerts(api)
+
 	g.fillSkips(api)
 }
 
@@ -330,6 +369,72 @@ func (g *Global) fillRequestSizeLimit(api apidef.APIDefinition) {
 	}
 }
 
+func (g *Global) fillRequestID(api apidef.APIDefinition) {
+	if g.RequestID == nil {
+		g.RequestID = &RequestID{}
+	}
+
+	g.RequestID.Fill(api.RequestID)
+	if ShouldOmit(g.RequestID) {
+		g.RequestID = nil
+	}
+}
+
+func (g *Global) fillIdempotency(api apidef.APIDefinition) {
+	if g.Idempotency == nil {
+		g.Idempotency = &Idempotency{}
+	}
+
+	g.Idempotency.Fill(api.Idempotency)
+	if ShouldOmit(g.Idempotency) {
+		g.Idempotency = nil
+	}
+}
+
+func (g *Global) fillResponseCompression(api apidef.APIDefinition) {
+	if g.ResponseCompression == nil {
+		g.ResponseCompression = &ResponseCompression{}
+	}
+
+	g.ResponseCompression.Fill(api.ResponseCompression)
+	if ShouldOmit(g.ResponseCompression) {
+		g.ResponseCompression = nil
+	}
+}
+
+func (g *Global) fillLatencyBreakdown(api apidef.APIDefinition) {
+	if g.LatencyBreakdown == nil {
+		g.LatencyBreakdown = &LatencyBreakdown{}
+	}
+
+	g.LatencyBreakdown.Fill(api.LatencyBreakdown)
+	if ShouldOmit(g.LatencyBreakdown) {
+		g.LatencyBreakdown = nil
+	}
+}
+
+func (g *Global) fillPayloadSizeAlerts(api apidef.APIDefinition) {
+	if g.PayloadSizeAlerts == nil {
+		g.PayloadSizeAlerts = &PayloadSizeAlerts{}
+	}
+
+	g.PayloadSizeAlerts.Fill(api.PayloadSizeAlerts)
+	if ShouldOmit(g.PayloadSizeAlerts) {
+		g.PayloadSizeAlerts = nil
+	}
+}
+
+func (g *Global) fillMaintenanceMode(api apidef.APIDefinition) {
+	if g.MaintenanceMode == nil {
+		g.MaintenanceMode = &MaintenanceMode{}
+	}
+
+	g.MaintenanceMode.Fill(api.MaintenanceMode)
+	if ShouldOmit(g.MaintenanceMode) {
+		g.MaintenanceMode = nil
+	}
+}
+
 func (g *Global) fillContextVariables(api apidef.APIDefinition) {
 	if g.ContextVariables == nil {
 		g.ContextVariables = &ContextVariables{}
@@ -415,14 +520,28 @@ func (g *Global) ExtractTo(api *apidef.APIDefinition) {
 	vInfo.GlobalHeadersDisabled = headerMeta.Disabled
 	vInfo.GlobalHeaders = headerMeta.AddHeaders
 	vInfo.GlobalHeadersRemove = headerMeta.DeleteHeaders
+	vInfo.GlobalHeadersRewrite = headerMeta.RewriteHeaders
 
 	vInfo.GlobalResponseHeadersDisabled = resHeaderMeta.Disabled
 	vInfo.GlobalResponseHeaders = resHeaderMeta.AddHeaders
 	vInfo.GlobalResponseHeadersRemove = resHeaderMeta.DeleteHeaders
+	vInfo.GlobalResponseHeadersRewrite = resHeaderMeta.RewriteHeaders
 	updateMainVersion(api, vInfo)
 
 	g.extractRequestSizeLimitTo(api)
 
+	g.extractMaintenanceModeTo(api)
+
+	g.extractRequestIDTo(api)
+
+	g.extractIdempotencyTo(api)
+
+	g.extractResponseCompressionTo(api)
+
+	g.extractLatencyBreakdownTo(api)
+
+	g.extractPayloadSizeAlertsTo(api)
+
 	g.extractSkipsTo(api)
 }
 
@@ -448,6 +567,72 @@ func (g *Global) extractRequestSizeLimitTo(api *apidef.APIDefinition) {
 	g.RequestSizeLimit.ExtractTo(api)
 }
 
+func (g *Global) extractMaintenanceModeTo(api *apidef.APIDefinition) {
+	if g.MaintenanceMode == nil {
+		g.MaintenanceMode = &MaintenanceMode{}
+		defer func() {
+			g.MaintenanceMode = nil
+		}()
+	}
+
+	g.MaintenanceMode.ExtractTo(&api.MaintenanceMode)
+}
+
+func (g *Global) extractRequestIDTo(api *apidef.APIDefinition) {
+	if g.RequestID == nil {
+		g.RequestID = &RequestID{}
+		defer func() {
+			g.RequestID = nil
+		}()
+	}
+
+	g.RequestID.ExtractTo(&api.RequestID)
+}
+
+func (g *Global) extractIdempotencyTo(api *apidef.APIDefinition) {
+	if g.Idempotency == nil {
+		g.Idempotency = &Idempotency{}
+		defer func() {
+			g.Idempotency = nil
+		}()
+	}
+
+	g.Idempotency.ExtractTo(&api.Idempotency)
+}
+
+func (g *Global) extractResponseCompressionTo(api *apidef.APIDefinition) {
+	if g.ResponseCompression == nil {
+		g.ResponseCompression = &ResponseCompression{}
+		defer func() {
+			g.ResponseCompression = nil
+		}()
+	}
+
+	g.ResponseCompression.ExtractTo(&api.ResponseCompression)
+}
+
+func (g *Global) extractLatencyBreakdownTo(api *apidef.APIDefinition) {
+	if g.LatencyBreakdown == nil {
+		g.LatencyBreakdown = &LatencyBreakdown{}
+		defer func() {
+			g.LatencyBreakdown = nil
+		}()
+	}
+
+	g.LatencyBreakdown.ExtractTo(&api.LatencyBreakdown)
+}
+
+func (g *Global) extractPayloadSizeAlertsTo(api *apidef.APIDefinition) {
+	if g.PayloadSizeAlerts == nil {
+		g.PayloadSizeAlerts = &PayloadSizeAlerts{}
+		defer func() {
+			g.PayloadSizeAlerts = nil
+		}()
+	}
+
+	g.PayloadSizeAlerts.ExtractTo(&api.PayloadSizeAlerts)
+}
+
 func (g *Global) extractContextVariablesTo(api *apidef.APIDefinition) {
 	if g.ContextVariables == nil {
 		g.ContextVariables = &ContextVariables{}
@@ -582,11 +767,18 @@ type PluginConfig struct {
 
 	// Data configures custom plugin data.
 	Data *PluginConfigData `bson:"data,omitempty" json:"data,omitempty"`
+
+	// FailOpenOnDisconnect skips the plugin hooks while the gateway is disconnected from the gRPC plugin server,
+	// instead of rejecting the requests. Authentication hooks always reject the requests.
+	//
+	// Tyk classic API definition: `custom_middleware.fail_open_on_disconnect`.
+	FailOpenOnDisconnect bool `bson:"failOpenOnDisconnect,omitempty" json:"failOpenOnDisconnect,omitempty"`
 }
 
 // Fill fills PluginConfig from apidef.
 func (p *PluginConfig) Fill(api apidef.APIDefinition) {
 	p.Driver = api.CustomMiddleware.Driver
+	p.FailOpenOnDisconnect = api.CustomMiddleware.FailOpenOnDisconnect
 
 	if p.Bundle == nil {
 		p.Bundle = &PluginBundle{}
@@ -610,6 +802,7 @@ func (p *PluginConfig) Fill(api apidef.APIDefinition) {
 // ExtractTo extracts *PluginConfig into *apidef.
 func (p *PluginConfig) ExtractTo(api *apidef.APIDefinition) {
 	api.CustomMiddleware.Driver = p.Driver
+	api.CustomMiddleware.FailOpenOnDisconnect = p.FailOpenOnDisconnect
 
 	if p.Bundle == nil {
 		p.Bundle = &PluginBundle{}
@@ -705,6 +898,100 @@ type CORS struct {
 	//
 	// Tyk classic API definition: `CORS.allowed_methods`.
 	AllowedMethods []string `bson:"allowedMethods,omitempty" json:"allowedMethods,omitempty"`
+
+	// Paths overrides the CORS configuration for the requests matching a path.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.cors`.
+	Paths []CORSPath `bson:"paths,omitempty" json:"paths,omitempty"`
+}
+
+// CORSPath overrides the API-level CORS configuration for the requests matching a path.
+// The path is matched as a prefix and the longest matching path wins. The settings left
+// empty are taken from the API-level configuration.
+type CORSPath struct {
+	// Enabled activates the override.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.cors[].disabled` (negated).
+	Enabled bool `bson:"enabled" json:"enabled"` // required
+
+	// Path is the path prefix the override applies to, relative to the listen path.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.cors[].path`.
+	Path string `bson:"path" json:"path"` // required
+
+	// AllowedOrigins holds a list of origin domains to allow access from.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.cors[].allowed_origins`.
+	AllowedOrigins []string `bson:"allowedOrigins,omitempty" json:"allowedOrigins,omitempty"`
+
+	// AllowedMethods holds a list of methods to allow access via.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.cors[].allowed_methods`.
+	AllowedMethods []string `bson:"allowedMethods,omitempty" json:"allowedMethods,omitempty"`
+
+	// AllowedHeaders holds a list of non simple headers the client is allowed to use with cross-domain requests.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.cors[].allowed_headers`.
+	AllowedHeaders []string `bson:"allowedHeaders,omitempty" json:"allowedHeaders,omitempty"`
+
+	// ExposedHeaders indicates which headers are safe to expose to the API of a CORS API specification.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.cors[].exposed_headers`.
+	ExposedHeaders []string `bson:"exposedHeaders,omitempty" json:"exposedHeaders,omitempty"`
+
+	// AllowCredentials indicates if the request can include user credentials. The API-level value is used when unset.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.cors[].allow_credentials`.
+	AllowCredentials *bool `bson:"allowCredentials,omitempty" json:"allowCredentials,omitempty"`
+
+	// MaxAge indicates how long (in seconds) the results of a preflight request can be cached.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.cors[].max_age`.
+	MaxAge int `bson:"maxAge,omitempty" json:"maxAge,omitempty"`
+}
+
+// Fill fills *CORSPath from apidef.CORSMeta.
+func (c *CORSPath) Fill(meta apidef.CORSMeta) {
+	c.Enabled = !meta.Disabled
+	c.Path = meta.Path
+	c.AllowedOrigins = meta.AllowedOrigins
+	c.AllowedMethods = meta.AllowedMethods
+	c.AllowedHeaders = meta.AllowedHeaders
+	c.ExposedHeaders = meta.ExposedHeaders
+	c.AllowCredentials = meta.AllowCredentials
+	c.MaxAge = meta.MaxAge
+}
+
+// ExtractTo extracts *CORSPath into *apidef.CORSMeta.
+func (c *CORSPath) ExtractTo(meta *apidef.CORSMeta) {
+	meta.Disabled = !c.Enabled
+	meta.Path = c.Path
+	meta.AllowedOrigins = c.AllowedOrigins
+	meta.AllowedMethods = c.AllowedMethods
+	meta.AllowedHeaders = c.AllowedHeaders
+	meta.ExposedHeaders = c.ExposedHeaders
+	meta.AllowCredentials = c.AllowCredentials
+	meta.MaxAge = c.MaxAge
+}
+
+// fillPaths fills the path overrides of *CORS from the classic API definition.
+func (c *CORS) fillPaths(metas []apidef.CORSMeta) {
+	c.Paths = nil
+	for _, meta := range metas {
+		var path CORSPath
+		path.Fill(meta)
+		c.Paths = append(c.Paths, path)
+	}
+}
+
+// extractPathsTo extracts the path overrides of *CORS into *apidef.ExtendedPathsSet.
+func (c *CORS) extractPathsTo(ep *apidef.ExtendedPathsSet) {
+	ep.CORS = nil
+	for _, path := range c.Paths {
+		var meta apidef.CORSMeta
+		path.ExtractTo(&meta)
+		ep.CORS = append(ep.CORS, meta)
+	}
 }
 
 // Fill fills *CORS from apidef.CORSConfig.
@@ -762,6 +1049,19 @@ type Cache struct {
 	// Tyk classic API definition: `cache_options.cache_by_headers`
 	CacheByHeaders []string `bson:"cacheByHeaders,omitempty" json:"cacheByHeaders,omitempty"`
 
+	// VaryHeaders is the allowlist of request headers that are honoured when the upstream response lists them
+	// in its `Vary` header. Each combination of their values is cached as a separate entry, while headers
+	// missing from this list never fragment the cache.
+	//
+	// Tyk classic API definition: `cache_options.cache_vary_headers`
+	VaryHeaders []string `bson:"varyHeaders,omitempty" json:"varyHeaders,omitempty"`
+
+	// StaleIfError is the grace window in seconds during which expired cache entries are kept and served,
+	// flagged with a `Warning` header, when the upstream fails or the circuit breaker is open.
+	//
+	// Tyk classic API definition: `cache_options.stale_if_error`
+	StaleIfError int64 `bson:"staleIfError,omitempty" json:"staleIfError,omitempty"`
+
 	// EnableUpstreamCacheControl instructs Tyk Cache to respect upstream cache control headers.
 	//
 	// Tyk classic API definition: `cache_options.enable_upstream_cache_control`
@@ -780,6 +1080,8 @@ func (c *Cache) Fill(cache apidef.CacheOptions) {
 	c.CacheAllSafeRequests = cache.CacheAllSafeRequests
 	c.CacheResponseCodes = cache.CacheOnlyResponseCodes
 	c.CacheByHeaders = cache.CacheByHeaders
+	c.VaryHeaders = cache.CacheVaryHeaders
+	c.StaleIfError = cache.StaleIfError
 	c.EnableUpstreamCacheControl = cache.EnableUpstreamCacheControl
 	c.ControlTTLHeaderName = cache.CacheControlTTLHeader
 }
@@ -791,6 +1093,8 @@ func (c *Cache) ExtractTo(cache *apidef.CacheOptions) {
 	cache.CacheAllSafeRequests = c.CacheAllSafeRequests
 	cache.CacheOnlyResponseCodes = c.CacheResponseCodes
 	cache.CacheByHeaders = c.CacheByHeaders
+	cache.CacheVaryHeaders = c.VaryHeaders
+	cache.StaleIfError = c.StaleIfError
 	cache.EnableUpstreamCacheControl = c.EnableUpstreamCacheControl
 	cache.CacheControlTTLHeader = c.ControlTTLHeaderName
 }
@@ -1180,9 +1484,11 @@ type TransformBody struct {
 	//
 	// Tyk classic API definition: `version_data.versions..extended_paths.transform[].disabled` (negated).
 	Enabled bool `bson:"enabled" json:"enabled"`
-	// Format of the request/response body, xml or json.
+	// Format of the request/response body, xml or json. With jq, the JSON body is transformed
+	// with the JQ expression given as template instead.
 	//
-	// Tyk classic API definition: `version_data.versions..extended_paths.transform[].template_data.input_type`.
+	// Tyk classic API definition: `version_data.versions..extended_paths.transform[].template_data.input_type`,
+	// or `version_data.versions..extended_paths.transform[].format` for jq.
 	Format apidef.RequestInputType `bson:"format" json:"format"`
 	// Path file path for the template.
 	//
@@ -1192,23 +1498,57 @@ type TransformBody struct {
 	//
 	// Tyk classic API definition: `version_data.versions..extended_paths.transform[].template_data.template_source` when `template_data.template_mode` is `blob`.
 	Body string `bson:"body,omitempty" json:"body,omitempty"`
+	// SOAP sets the SOAP headers of a request transformed into a SOAP envelope. It only applies to request body transforms.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.transform[].template_data.soap`.
+	SOAP *TransformSOAP `bson:"soap,omitempty" json:"soap,omitempty"`
+}
+
+// TransformSOAP holds configuration about the SOAP headers of a transformed request.
+type TransformSOAP struct {
+	// Enabled sets the Content-Type and SOAP action headers of the transformed request.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.transform[].template_data.soap.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Version is the SOAP version of the envelope, `1.1` (default) or `1.2`.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.transform[].template_data.soap.version`.
+	Version string `bson:"version,omitempty" json:"version,omitempty"`
+	// Action is the SOAP action of the request. With SOAP 1.1 it is sent in the SOAPAction header,
+	// with SOAP 1.2 as the action parameter of the Content-Type.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.transform[].template_data.soap.action`.
+	Action string `bson:"action,omitempty" json:"action,omitempty"`
 }
 
 // Fill fills *TransformBody from apidef.TemplateMeta.
 func (tr *TransformBody) Fill(meta apidef.TemplateMeta) {
 	tr.Enabled = !meta.Disabled
 	tr.Format = meta.TemplateData.Input
+	if meta.Format == apidef.TransformFormatJQ {
+		tr.Format = apidef.RequestInputType(meta.Format)
+	}
 	if meta.TemplateData.Mode == apidef.UseBlob {
 		tr.Body = meta.TemplateData.TemplateSource
 	} else {
 		tr.Path = meta.TemplateData.TemplateSource
 	}
+
+	tr.SOAP = nil
+	if soap := meta.TemplateData.SOAP; soap != nil {
+		tr.SOAP = &TransformSOAP{Enabled: soap.Enabled, Version: soap.Version, Action: soap.Action}
+	}
 }
 
 // ExtractTo extracts data from *TransformBody into *apidef.TemplateMeta.
 func (tr *TransformBody) ExtractTo(meta *apidef.TemplateMeta) {
 	meta.Disabled = !tr.Enabled
+	meta.Format = ""
 	meta.TemplateData.Input = tr.Format
+	if tr.Format == apidef.RequestInputType(apidef.TransformFormatJQ) {
+		meta.Format = apidef.TransformFormatJQ
+		meta.TemplateData.Input = apidef.RequestJSON
+	}
 	meta.TemplateData.EnableSession = true
 	if tr.Body != "" {
 		meta.TemplateData.Mode = apidef.UseBlob
@@ -1217,6 +1557,11 @@ func (tr *TransformBody) ExtractTo(meta *apidef.TemplateMeta) {
 		meta.TemplateData.Mode = apidef.UseFile
 		meta.TemplateData.TemplateSource = tr.Path
 	}
+
+	meta.TemplateData.SOAP = nil
+	if tr.SOAP != nil {
+		meta.TemplateData.SOAP = &apidef.TemplateSOAP{Enabled: tr.SOAP.Enabled, Version: tr.SOAP.Version, Action: tr.SOAP.Action}
+	}
 }
 
 // TransformHeaders holds configuration about request/response header transformations.
@@ -1233,6 +1578,20 @@ type TransformHeaders struct {
 	//
 	// Tyk classic API definition: `version_data.versions..extended_paths.transform_headers[].add_headers`.
 	Add Headers `bson:"add,omitempty" json:"add,omitempty"`
+	// Rewrite specifies regular expression replacements applied to header values after adds and removals.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.transform_headers[].rewrite_headers`.
+	Rewrite []HeaderRewrite `bson:"rewrite,omitempty" json:"rewrite,omitempty"`
+}
+
+// HeaderRewrite holds a regular expression replacement for the values of a header.
+type HeaderRewrite struct {
+	// Name is the case-insensitive name of the header to rewrite.
+	Name string `bson:"name" json:"name"`
+	// Match is the regular expression matched against the header values.
+	Match string `bson:"match" json:"match"`
+	// Replace is the replacement for the matched parts, it can reference capture groups (e.g. `$1`).
+	Replace string `bson:"replace" json:"replace"`
 }
 
 // AppendAddOp appends add operation to TransformHeaders middleware.
@@ -1249,6 +1608,11 @@ func (th *TransformHeaders) Fill(meta apidef.HeaderInjectionMeta) {
 	if len(th.Add) == 0 {
 		th.Add = nil
 	}
+
+	th.Rewrite = nil
+	for _, rewrite := range meta.RewriteHeaders {
+		th.Rewrite = append(th.Rewrite, HeaderRewrite{Name: rewrite.Header, Match: rewrite.MatchRegex, Replace: rewrite.Replace})
+	}
 }
 
 // ExtractTo extracts *TransformHeaders into *apidef.HeaderInjectionMeta.
@@ -1260,6 +1624,11 @@ func (th *TransformHeaders) ExtractTo(meta *apidef.HeaderInjectionMeta) {
 	for _, header := range th.Add {
 		meta.AddHeaders[header.Name] = header.Value
 	}
+
+	meta.RewriteHeaders = nil
+	for _, rewrite := range th.Rewrite {
+		meta.RewriteHeaders = append(meta.RewriteHeaders, apidef.HeaderRewrite{Header: rewrite.Name, MatchRegex: rewrite.Match, Replace: rewrite.Replace})
+	}
 }
 
 // CachePlugin holds the configuration for the cache plugins.
@@ -1379,6 +1748,12 @@ type CustomPlugin struct {
 	// Tyk classic API definition: `custom_middleware.pre[].raw_body_only`, `custom_middleware.post_key_auth[].raw_body_only`,
 	// `custom_middleware.post[].raw_body_only`, `custom_middleware.response[].raw_body_only`.
 	RawBodyOnly bool `bson:"rawBodyOnly,omitempty" json:"rawBodyOnly,omitempty"`
+	// StreamBody if set to true, streams the raw request and response bodies to a gRPC plugin in chunks,
+	// so they aren't limited by the gRPC max message sizes. StreamBody is used only with gRPC plugins.
+	//
+	// Tyk classic API definition: `custom_middleware.pre[].stream_body`, `custom_middleware.post_key_auth[].stream_body`,
+	// `custom_middleware.post[].stream_body`, `custom_middleware.response[].stream_body`.
+	StreamBody bool `bson:"streamBody,omitempty" json:"streamBody,omitempty"`
 	// RequireSession if set to true passes down the session information for plugins after authentication.
 	// RequireSession is used only with JSVM custom middleware.
 	//
@@ -1404,6 +1779,7 @@ func (c *CustomPlugins) Fill(mwDefs []apidef.MiddlewareDefinition) {
 			Code:           mwDef.Code,
 			FunctionName:   mwDef.Name,
 			RawBodyOnly:    mwDef.RawBodyOnly,
+			StreamBody:     mwDef.StreamBody,
 			RequireSession: mwDef.RequireSession,
 		}
 	}
@@ -1424,6 +1800,7 @@ func (c *CustomPlugins) ExtractTo(mwDefs []apidef.MiddlewareDefinition) {
 			Path:           plugin.Path,
 			Code:           plugin.Code,
 			RawBodyOnly:    plugin.RawBodyOnly,
+			StreamBody:     plugin.StreamBody,
 			RequireSession: plugin.RequireSession,
 		}
 	}
@@ -1773,6 +2150,37 @@ func (r *RequestSizeLimit) ExtractTo(meta *apidef.RequestSizeMeta) {
 	meta.SizeLimit = r.Value
 }
 
+// RateCost weights the requests to the endpoint in the rate limit of the key, so a request
+// costs Cost requests of the allowance instead of one.
+type RateCost struct {
+	// Enabled activates the rate cost of the endpoint.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.rate_cost[].disabled` (negated).
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Cost is the number of requests of the rate limit a request to the endpoint counts as.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.rate_cost[].rate_cost`.
+	Cost int `bson:"cost" json:"cost"`
+	// ApplyToQuota weights the requests to the endpoint in the quota of the key too.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.rate_cost[].apply_to_quota`.
+	ApplyToQuota bool `bson:"applyToQuota,omitempty" json:"applyToQuota,omitempty"`
+}
+
+// Fill fills *RateCost from apidef.RateCostMeta.
+func (r *RateCost) Fill(meta apidef.RateCostMeta) {
+	r.Enabled = !meta.Disabled
+	r.Cost = meta.Cost
+	r.ApplyToQuota = meta.ApplyToQuota
+}
+
+// ExtractTo extracts *RateCost into *apidef.RateCostMeta.
+func (r *RateCost) ExtractTo(meta *apidef.RateCostMeta) {
+	meta.Disabled = !r.Enabled
+	meta.Cost = r.Cost
+	meta.ApplyToQuota = r.ApplyToQuota
+}
+
 // TrafficLogs holds configuration about API log analytics.
 type TrafficLogs struct {
 	// Enabled enables traffic log analytics for the API.
@@ -1787,6 +2195,23 @@ type TrafficLogs struct {
 	// Plugins configures custom plugins to allow for extensive modifications to analytics records
 	// The plugins would be executed in the order of configuration in the list.
 	Plugins CustomAnalyticsPlugins `bson:"plugins,omitempty" json:"plugins,omitempty"`
+	// NormalisePaths replace the matching parts of the paths recorded in analytics,
+	// before the gateway normalisation patterns are applied.
+	//
+	// Tyk classic API definition: `normalise_url_patterns`.
+	NormalisePaths []NormalisePath `bson:"normalisePaths,omitempty" json:"normalisePaths,omitempty"`
+}
+
+// NormalisePath replaces the parts of analytics paths matching a regular expression.
+type NormalisePath struct {
+	// Regex is the regular expression matched against the path.
+	//
+	// Tyk classic API definition: `normalise_url_patterns[].regex`.
+	Regex string `bson:"regex" json:"regex"`
+	// Replacement replaces the matches, it can refer to capture groups as in `$1`.
+	//
+	// Tyk classic API definition: `normalise_url_patterns[].replacement`.
+	Replacement string `bson:"replacement,omitempty" json:"replacement,omitempty"`
 }
 
 // Fill fills *TrafficLogs from apidef.APIDefinition.
@@ -1795,6 +2220,11 @@ func (t *TrafficLogs) Fill(api apidef.APIDefinition) {
 	t.TagHeaders = api.TagHeaders
 	t.CustomRetentionPeriod = ReadableDuration(time.Duration(api.ExpireAnalyticsAfter) * time.Second)
 
+	t.NormalisePaths = nil
+	for _, pattern := range api.NormaliseURLPatterns {
+		t.NormalisePaths = append(t.NormalisePaths, NormalisePath{Regex: pattern.Regex, Replacement: pattern.Replacement})
+	}
+
 	if t.Plugins == nil {
 		t.Plugins = make(CustomAnalyticsPlugins, 0)
 	}
@@ -1810,6 +2240,11 @@ func (t *TrafficLogs) ExtractTo(api *apidef.APIDefinition) {
 	api.TagHeaders = t.TagHeaders
 	api.ExpireAnalyticsAfter = int64(t.CustomRetentionPeriod.Seconds())
 
+	api.NormaliseURLPatterns = nil
+	for _, pattern := range t.NormalisePaths {
+		api.NormaliseURLPatterns = append(api.NormaliseURLPatterns, apidef.URLNormalisePattern{Regex: pattern.Regex, Replacement: pattern.Replacement})
+	}
+
 	if t.Plugins == nil {
 		t.Plugins = make(CustomAnalyticsPlugins, 0)
 		defer func() {
@@ -1892,6 +2327,59 @@ func (g *GlobalRequestSizeLimit) ExtractTo(api *apidef.APIDefinition) {
 	mainVersion.GlobalSizeLimit = g.Value
 }
 
+// MaintenanceMode holds the configuration for short-circuiting requests with a static response
+// while the upstream is under maintenance. The response is sent after authentication, so the
+// requests are still recorded in analytics against the consumer.
+type MaintenanceMode struct {
+	// Enabled enables maintenance mode.
+	//
+	// Tyk classic API definition: `maintenance_mode.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// StatusCode is the response status code, defaults to 503.
+	//
+	// Tyk classic API definition: `maintenance_mode.status_code`.
+	StatusCode int `bson:"statusCode,omitempty" json:"statusCode,omitempty"`
+	// ContentType is the response content type, defaults to `application/json`.
+	//
+	// Tyk classic API definition: `maintenance_mode.content_type`.
+	ContentType string `bson:"contentType,omitempty" json:"contentType,omitempty"`
+	// Body is a Go template for the response body. The template has access to `.APIID`, `.APIName`
+	// and `.RetryAfter`, the number of seconds sent in the `Retry-After` header.
+	//
+	// Tyk classic API definition: `maintenance_mode.body`.
+	Body string `bson:"body,omitempty" json:"body,omitempty"`
+	// RetryAfter is sent to clients in the `Retry-After` header, rounded down to seconds.
+	// The header is omitted when it isn't set.
+	//
+	// Tyk classic API definition: `maintenance_mode.retry_after`.
+	RetryAfter ReadableDuration `bson:"retryAfter,omitempty" json:"retryAfter,omitempty"`
+	// ExcludePaths is a list of paths which are still proxied to the upstream, e.g. `/health`.
+	// The paths are matched the same way as the endpoint paths, relative to the listen path.
+	//
+	// Tyk classic API definition: `maintenance_mode.exclude_paths`.
+	ExcludePaths []string `bson:"excludePaths,omitempty" json:"excludePaths,omitempty"`
+}
+
+// Fill fills *MaintenanceMode from apidef.MaintenanceMode.
+func (m *MaintenanceMode) Fill(maintenance apidef.MaintenanceMode) {
+	m.Enabled = maintenance.Enabled
+	m.StatusCode = maintenance.StatusCode
+	m.ContentType = maintenance.ContentType
+	m.Body = maintenance.Body
+	m.RetryAfter = ReadableDuration(time.Duration(maintenance.RetryAfter) * time.Second)
+	m.ExcludePaths = maintenance.ExcludePaths
+}
+
+// ExtractTo extracts *MaintenanceMode into *apidef.MaintenanceMode.
+func (m *MaintenanceMode) ExtractTo(maintenance *apidef.MaintenanceMode) {
+	maintenance.Enabled = m.Enabled
+	maintenance.StatusCode = m.StatusCode
+	maintenance.ContentType = m.ContentType
+	maintenance.Body = m.Body
+	maintenance.RetryAfter = int(m.RetryAfter.Seconds())
+	maintenance.ExcludePaths = m.ExcludePaths
+}
+
 // ContextVariables holds the configuration related to Tyk context variables.
 type ContextVariables struct {
 	// Enabled provides access to context variables from specific Tyk middleware (URL rewrite, header and body transform).
@@ -1910,6 +2398,188 @@ func (c *ContextVariables) ExtractTo(api *apidef.APIDefinition) {
 	api.EnableContextVars = c.Enabled
 }
 
+// RequestID holds the configuration for the correlation ID of the requests. The ID of the incoming
+// request is reused, otherwise a UUID is generated. The ID is sent to the upstream, returned to the client,
+// recorded in analytics and available as the `request_id` context variable and in error templates.
+type RequestID struct {
+	// Enabled enables the request ID. The gateway `request_id.enabled` option enables it for every API.
+	//
+	// Tyk classic API definition: `request_id.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// HeaderName is the header carrying the request ID, defaults to the gateway `request_id.header_name` or `X-Request-ID`.
+	//
+	// Tyk classic API definition: `request_id.header_name`.
+	HeaderName string `bson:"headerName,omitempty" json:"headerName,omitempty"`
+}
+
+// Fill fills *RequestID from apidef.RequestID.
+func (r *RequestID) Fill(requestID apidef.RequestID) {
+	r.Enabled = requestID.Enabled
+	r.HeaderName = requestID.HeaderName
+}
+
+// ExtractTo extracts *RequestID into *apidef.RequestID.
+func (r *RequestID) ExtractTo(requestID *apidef.RequestID) {
+	requestID.Enabled = r.Enabled
+	requestID.HeaderName = r.HeaderName
+}
+
+// Idempotency holds the configuration for replaying the response of a POST request retried with the same
+// idempotency key, instead of proxying it again. The first response is stored in Redis per API, key and
+// idempotency key, and replayed with the `Idempotent-Replayed: true` header.
+type Idempotency struct {
+	// Enabled enables the idempotency keys.
+	//
+	// Tyk classic API definition: `idempotency.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// HeaderName is the header carrying the idempotency key, defaults to `Idempotency-Key`.
+	//
+	// Tyk classic API definition: `idempotency.header_name`.
+	HeaderName string `bson:"headerName,omitempty" json:"headerName,omitempty"`
+	// TTL is how long a response is replayed for, rounded down to seconds. Defaults to `24h`.
+	//
+	// Tyk classic API definition: `idempotency.ttl`.
+	TTL ReadableDuration `bson:"ttl,omitempty" json:"ttl,omitempty"`
+	// MaxResponseSize is the largest response body stored in bytes, defaults to 1MB. Larger responses
+	// aren't stored and are returned with the `X-Tyk-Idempotency-Warning` header.
+	//
+	// Tyk classic API definition: `idempotency.max_response_size`.
+	MaxResponseSize int64 `bson:"maxResponseSize,omitempty" json:"maxResponseSize,omitempty"`
+	// WaitForInFlight makes the duplicates of a request in flight wait for its response.
+	// When it isn't set, the duplicates get a 409 Conflict response.
+	//
+	// Tyk classic API definition: `idempotency.wait_for_in_flight`.
+	WaitForInFlight bool `bson:"waitForInFlight,omitempty" json:"waitForInFlight,omitempty"`
+	// WaitTimeout is how long a duplicate waits before the 409 Conflict response, rounded down to seconds.
+	// Defaults to `10s`.
+	//
+	// Tyk classic API definition: `idempotency.wait_timeout`.
+	WaitTimeout ReadableDuration `bson:"waitTimeout,omitempty" json:"waitTimeout,omitempty"`
+}
+
+// Fill fills *Idempotency from apidef.Idempotency.
+func (i *Idempotency) Fill(idempotency apidef.Idempotency) {
+	i.Enabled = idempotency.Enabled
+	i.HeaderName = idempotency.HeaderName
+	i.TTL = ReadableDuration(time.Duration(idempotency.TTL) * time.Second)
+	i.MaxResponseSize = idempotency.MaxResponseSize
+	i.WaitForInFlight = idempotency.WaitForInFlight
+	i.WaitTimeout = ReadableDuration(time.Duration(idempotency.WaitTimeout) * time.Second)
+}
+
+// ExtractTo extracts *Idempotency into *apidef.Idempotency.
+func (i *Idempotency) ExtractTo(idempotency *apidef.Idempotency) {
+	idempotency.Enabled = i.Enabled
+	idempotency.HeaderName = i.HeaderName
+	idempotency.TTL = int64(i.TTL.Seconds())
+	idempotency.MaxResponseSize = i.MaxResponseSize
+	idempotency.WaitForInFlight = i.WaitForInFlight
+	idempotency.WaitTimeout = int64(i.WaitTimeout.Seconds())
+}
+
+// ResponseCompression holds the configuration for compressing the upstream responses the client accepts compressed.
+// The encoding is the first of `algorithms` listed in the `Accept-Encoding` header of the request. Responses already
+// encoded by the upstream, server-sent events and upgraded connections are left untouched.
+type ResponseCompression struct {
+	// Enabled enables the response compression.
+	//
+	// Tyk classic API definition: `response_compression.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// MinSize is the smallest response body compressed in bytes, defaults to 1024.
+	//
+	// Tyk classic API definition: `response_compression.min_size`.
+	MinSize int64 `bson:"minSize,omitempty" json:"minSize,omitempty"`
+	// ContentTypes is the list of media types compressed, a `type/*` entry matches every subtype.
+	// Defaults to JSON, XML, JavaScript and text responses.
+	//
+	// Tyk classic API definition: `response_compression.content_types`.
+	ContentTypes []string `bson:"contentTypes,omitempty" json:"contentTypes,omitempty"`
+	// Algorithms is the list of encodings used by order of preference, `gzip` and `br`.
+	// Defaults to `["br", "gzip"]`.
+	//
+	// Tyk classic API definition: `response_compression.algorithms`.
+	Algorithms []string `bson:"algorithms,omitempty" json:"algorithms,omitempty"`
+}
+
+// Fill fills *ResponseCompression from apidef.ResponseCompression.
+func (c *ResponseCompression) Fill(compression apidef.ResponseCompression) {
+	c.Enabled = compression.Enabled
+	c.MinSize = compression.MinSize
+	c.ContentTypes = compression.ContentTypes
+	c.Algorithms = compression.Algorithms
+}
+
+// ExtractTo extracts *ResponseCompression into *apidef.ResponseCompression.
+func (c *ResponseCompression) ExtractTo(compression *apidef.ResponseCompression) {
+	compression.Enabled = c.Enabled
+	compression.MinSize = c.MinSize
+	compression.ContentTypes = c.ContentTypes
+	compression.Algorithms = c.Algorithms
+}
+
+// LatencyBreakdown holds the configuration for recording the time spent in each stage of the requests:
+// authentication, rate limiting, transforms, plugins and the upstream proxy. The breakdown is recorded in
+// analytics as a `latency-breakdown-` tag, e.g. `latency-breakdown-auth=2,plugin=120,proxy=35`.
+type LatencyBreakdown struct {
+	// Enabled enables the latency breakdown.
+	//
+	// Tyk classic API definition: `latency_breakdown.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Threshold is the time a stage has to exceed to be part of the breakdown, rounded down to milliseconds.
+	//
+	// Tyk classic API definition: `latency_breakdown.threshold`.
+	Threshold ReadableDuration `bson:"threshold,omitempty" json:"threshold,omitempty"`
+	// DebugHeaders returns the breakdown of the proxied requests in the `X-Tyk-Latency-Breakdown` response header.
+	//
+	// Tyk classic API definition: `latency_breakdown.debug_headers`.
+	DebugHeaders bool `bson:"debugHeaders,omitempty" json:"debugHeaders,omitempty"`
+}
+
+// Fill fills *LatencyBreakdown from apidef.LatencyBreakdown.
+func (l *LatencyBreakdown) Fill(breakdown apidef.LatencyBreakdown) {
+	l.Enabled = breakdown.Enabled
+	l.Threshold = ReadableDuration(time.Duration(breakdown.Threshold) * time.Millisecond)
+	l.DebugHeaders = breakdown.DebugHeaders
+}
+
+// ExtractTo extracts *LatencyBreakdown into *apidef.LatencyBreakdown.
+func (l *LatencyBreakdown) ExtractTo(breakdown *apidef.LatencyBreakdown) {
+	breakdown.Enabled = l.Enabled
+	breakdown.Threshold = time.Duration(l.Threshold).Milliseconds()
+	breakdown.DebugHeaders = l.DebugHeaders
+}
+
+// PayloadSizeAlerts holds the configuration for firing the `PayloadSizeThreshold` event when the body
+// of a single proxied request or response is larger than the configured size.
+type PayloadSizeAlerts struct {
+	// Enabled enables the payload size alerts.
+	//
+	// Tyk classic API definition: `payload_size_alerts.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// RequestThreshold is the request body size in bytes above which the event fires, zero disables it.
+	//
+	// Tyk classic API definition: `payload_size_alerts.request_threshold`.
+	RequestThreshold int64 `bson:"requestThreshold,omitempty" json:"requestThreshold,omitempty"`
+	// ResponseThreshold is the response body size in bytes above which the event fires, zero disables it.
+	//
+	// Tyk classic API definition: `payload_size_alerts.response_threshold`.
+	ResponseThreshold int64 `bson:"responseThreshold,omitempty" json:"responseThreshold,omitempty"`
+}
+
+// Fill fills *PayloadSizeAlerts from apidef.PayloadSizeAlerts.
+func (p *PayloadSizeAlerts) Fill(alerts apidef.PayloadSizeAlerts) {
+	p.Enabled = alerts.Enabled
+	p.RequestThreshold = alerts.RequestThreshold
+	p.ResponseThreshold = alerts.ResponseThreshold
+}
+
+// ExtractTo extracts *PayloadSizeAlerts into *apidef.PayloadSizeAlerts.
+func (p *PayloadSizeAlerts) ExtractTo(alerts *apidef.PayloadSizeAlerts) {
+	alerts.Enabled = p.Enabled
+	alerts.RequestThreshold = p.RequestThreshold
+	alerts.ResponseThreshold = p.ResponseThreshold
+}
+
 // IgnoreCase will make route matching be case insensitive.
 // This accepts request to `/AAA` or `/aaa` if set to true.
 type IgnoreCase struct {
@@ -319,6 +319,28 @@ func TestTrafficLogs(t *testing.T) {
 		actualTrafficLogsPlugin.Fill(api)
 		assert.Equal(t, expectedTrafficLogsPlugin, actualTrafficLogsPlugin)
 	})
+
+	t.Run("with normalise paths", func(t *testing.T) {
+		trafficLogs := TrafficLogs{
+			Enabled: true,
+			NormalisePaths: []NormalisePath{
+				{Regex: `/orders/[0-9A-HJKMNP-TV-Z]{26}`, Replacement: "/orders/{order_id}"},
+			},
+		}
+
+		var convertedAPI apidef.APIDefinition
+		convertedAPI.SetDisabledFlags()
+		trafficLogs.ExtractTo(&convertedAPI)
+
+		assert.Equal(t, []apidef.URLNormalisePattern{
+			{Regex: `/orders/[0-9A-HJKMNP-TV-Z]{26}`, Replacement: "/orders/{order_id}"},
+		}, convertedAPI.NormaliseURLPatterns)
+
+		var resultTrafficLogs TrafficLogs
+		resultTrafficLogs.Fill(convertedAPI)
+
+		assert.Equal(t, trafficLogs, resultTrafficLogs)
+	})
 }
 
 func TestPluginConfig(t *testing.T) {
@@ -384,6 +406,22 @@ func TestPluginConfig(t *testing.T) {
 		newPluginConfig.Fill(api)
 		assert.Equal(t, pluginConfig, newPluginConfig)
 	})
+
+	t.Run("fail open on disconnect", func(t *testing.T) {
+		pluginConfig := PluginConfig{
+			Driver:               apidef.GrpcDriver,
+			FailOpenOnDisconnect: true,
+		}
+
+		api := apidef.APIDefinition{}
+		api.SetDisabledFlags()
+		pluginConfig.ExtractTo(&api)
+		assert.True(t, api.CustomMiddleware.FailOpenOnDisconnect)
+
+		newPluginConfig := PluginConfig{}
+		newPluginConfig.Fill(api)
+		assert.Equal(t, pluginConfig, newPluginConfig)
+	})
 }
 
 func TestPluginBundle(t *testing.T) {
@@ -431,6 +469,59 @@ func TestCORS(t *testing.T) {
 	assert.Equal(t, emptyCORS, resultCORS)
 }
 
+func TestCORSPath(t *testing.T) {
+	t.Parallel()
+
+	noCredentials := false
+	paths := []apidef.CORSMeta{
+		{
+			Path:             "/widgets",
+			AllowedOrigins:   []string{"https://widgets.example.com"},
+			AllowedMethods:   []string{"GET"},
+			AllowedHeaders:   []string{"X-Widget"},
+			ExposedHeaders:   []string{"X-Total"},
+			AllowCredentials: &noCredentials,
+			MaxAge:           60,
+		},
+		{
+			Disabled:       true,
+			Path:           "/oauth/token",
+			AllowedOrigins: []string{"*"},
+		},
+	}
+
+	var api apidef.APIDefinition
+	api.SetDisabledFlags()
+	api.CORS.Enable = true
+	api.VersionData.Versions = map[string]apidef.VersionInfo{
+		Main: {ExtendedPaths: apidef.ExtendedPathsSet{CORS: paths}},
+	}
+
+	var oas OAS
+	oas.Fill(api)
+	cors := oas.GetTykMiddleware().Global.CORS
+	assert.Equal(t, []CORSPath{
+		{
+			Enabled:          true,
+			Path:             "/widgets",
+			AllowedOrigins:   []string{"https://widgets.example.com"},
+			AllowedMethods:   []string{"GET"},
+			AllowedHeaders:   []string{"X-Widget"},
+			ExposedHeaders:   []string{"X-Total"},
+			AllowCredentials: &noCredentials,
+			MaxAge:           60,
+		},
+		{
+			Path:           "/oauth/token",
+			AllowedOrigins: []string{"*"},
+		},
+	}, cors.Paths)
+
+	var extracted apidef.APIDefinition
+	oas.ExtractTo(&extracted)
+	assert.Equal(t, paths, extracted.VersionData.Versions[Main].ExtendedPaths.CORS)
+}
+
 func TestCache(t *testing.T) {
 	var emptyCache Cache
 
@@ -520,6 +611,30 @@ func TestTransformBody(t *testing.T) {
 		assert.Equal(t, transformReqBody, newTransformReqBody)
 	})
 
+	t.Run("jq", func(t *testing.T) {
+		transformReqBody := TransformBody{
+			Body:    "test expression",
+			Format:  "jq",
+			Enabled: true,
+		}
+
+		meta := apidef.TemplateMeta{}
+		transformReqBody.ExtractTo(&meta)
+		assert.Equal(t, apidef.TemplateMeta{
+			Format: apidef.TransformFormatJQ,
+			TemplateData: apidef.TemplateData{
+				EnableSession:  true,
+				Mode:           apidef.UseBlob,
+				TemplateSource: "test expression",
+				Input:          apidef.RequestJSON,
+			},
+		}, meta)
+
+		newTransformReqBody := TransformBody{}
+		newTransformReqBody.Fill(meta)
+		assert.Equal(t, transformReqBody, newTransformReqBody)
+	})
+
 	t.Run("path", func(t *testing.T) {
 		transformReqBody := TransformBody{
 			Path:    "/opt/tyk-gateway/template.tmpl",
@@ -570,6 +685,23 @@ func TestTransformBody(t *testing.T) {
 		expectedTransformReqBody.Path = ""
 		assert.Equal(t, expectedTransformReqBody, newTransformReqBody)
 	})
+
+	t.Run("soap", func(t *testing.T) {
+		transformReqBody := TransformBody{
+			Body:    "test body",
+			Format:  apidef.RequestJSON,
+			Enabled: true,
+			SOAP:    &TransformSOAP{Enabled: true, Version: apidef.SOAPVersion12, Action: "http://tempuri.org/Add"},
+		}
+
+		meta := apidef.TemplateMeta{}
+		transformReqBody.ExtractTo(&meta)
+		assert.Equal(t, &apidef.TemplateSOAP{Enabled: true, Version: apidef.SOAPVersion12, Action: "http://tempuri.org/Add"}, meta.TemplateData.SOAP)
+
+		newTransformReqBody := TransformBody{}
+		newTransformReqBody.Fill(meta)
+		assert.Equal(t, transformReqBody, newTransformReqBody)
+	})
 }
 
 func TestAuthenticationPlugin(t *testing.T) {
@@ -1471,6 +1603,246 @@ func TestGlobalRequestSizeLimit(t *testing.T) {
 	})
 }
 
+func TestMaintenanceMode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		g := new(Global)
+		g.Fill(apidef.APIDefinition{})
+		assert.Nil(t, g.MaintenanceMode)
+
+		var apiDef apidef.APIDefinition
+		g.ExtractTo(&apiDef)
+		assert.Equal(t, apidef.MaintenanceMode{}, apiDef.MaintenanceMode)
+	})
+
+	t.Run("fill and extract", func(t *testing.T) {
+		t.Parallel()
+
+		maintenance := apidef.MaintenanceMode{
+			Enabled:      true,
+			StatusCode:   503,
+			ContentType:  "text/plain",
+			Body:         "{{.APIName}} is under maintenance",
+			RetryAfter:   90,
+			ExcludePaths: []string{"/health"},
+		}
+
+		g := new(Global)
+		g.Fill(apidef.APIDefinition{MaintenanceMode: maintenance})
+		assert.Equal(t, &MaintenanceMode{
+			Enabled:      true,
+			StatusCode:   503,
+			ContentType:  "text/plain",
+			Body:         "{{.APIName}} is under maintenance",
+			RetryAfter:   ReadableDuration(90 * time.Second),
+			ExcludePaths: []string{"/health"},
+		}, g.MaintenanceMode)
+
+		var apiDef apidef.APIDefinition
+		g.ExtractTo(&apiDef)
+		assert.Equal(t, maintenance, apiDef.MaintenanceMode)
+	})
+}
+
+func TestRequestID(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		g := new(Global)
+		g.Fill(apidef.APIDefinition{})
+		assert.Nil(t, g.RequestID)
+
+		var apiDef apidef.APIDefinition
+		g.ExtractTo(&apiDef)
+		assert.Equal(t, apidef.RequestID{}, apiDef.RequestID)
+	})
+
+	t.Run("fill and extract", func(t *testing.T) {
+		t.Parallel()
+
+		requestID := apidef.RequestID{
+			Enabled:    true,
+			HeaderName: "X-Correlation-ID",
+		}
+
+		g := new(Global)
+		g.Fill(apidef.APIDefinition{RequestID: requestID})
+		assert.Equal(t, &RequestID{
+			Enabled:    true,
+			HeaderName: "X-Correlation-ID",
+		}, g.RequestID)
+
+		var apiDef apidef.APIDefinition
+		g.ExtractTo(&apiDef)
+		assert.Equal(t, requestID, apiDef.RequestID)
+	})
+}
+
+func TestIdempotency(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		g := new(Global)
+		g.Fill(apidef.APIDefinition{})
+		assert.Nil(t, g.Idempotency)
+
+		var apiDef apidef.APIDefinition
+		g.ExtractTo(&apiDef)
+		assert.Equal(t, apidef.Idempotency{}, apiDef.Idempotency)
+	})
+
+	t.Run("fill and extract", func(t *testing.T) {
+		t.Parallel()
+
+		idempotency := apidef.Idempotency{
+			Enabled:         true,
+			HeaderName:      "X-Idempotency-Key",
+			TTL:             3600,
+			MaxResponseSize: 2048,
+			WaitForInFlight: true,
+			WaitTimeout:     5,
+		}
+
+		g := new(Global)
+		g.Fill(apidef.APIDefinition{Idempotency: idempotency})
+		assert.Equal(t, &Idempotency{
+			Enabled:         true,
+			HeaderName:      "X-Idempotency-Key",
+			TTL:             ReadableDuration(time.Hour),
+			MaxResponseSize: 2048,
+			WaitForInFlight: true,
+			WaitTimeout:     ReadableDuration(5 * time.Second),
+		}, g.Idempotency)
+
+		var apiDef apidef.APIDefinition
+		g.ExtractTo(&apiDef)
+		assert.Equal(t, idempotency, apiDef.Idempotency)
+	})
+}
+
+func TestResponseCompression(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		g := new(Global)
+		g.Fill(apidef.APIDefinition{})
+		assert.Nil(t, g.ResponseCompression)
+
+		var apiDef apidef.APIDefinition
+		g.ExtractTo(&apiDef)
+		assert.Equal(t, apidef.ResponseCompression{}, apiDef.ResponseCompression)
+	})
+
+	t.Run("fill and extract", func(t *testing.T) {
+		t.Parallel()
+
+		compression := apidef.ResponseCompression{
+			Enabled:      true,
+			MinSize:      2048,
+			ContentTypes: []string{"application/json", "text/*"},
+			Algorithms:   []string{"gzip"},
+		}
+
+		g := new(Global)
+		g.Fill(apidef.APIDefinition{ResponseCompression: compression})
+		assert.Equal(t, &ResponseCompression{
+			Enabled:      true,
+			MinSize:      2048,
+			ContentTypes: []string{"application/json", "text/*"},
+			Algorithms:   []string{"gzip"},
+		}, g.ResponseCompression)
+
+		var apiDef apidef.APIDefinition
+		g.ExtractTo(&apiDef)
+		assert.Equal(t, compression, apiDef.ResponseCompression)
+	})
+}
+
+func TestLatencyBreakdown(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		g := new(Global)
+		g.Fill(apidef.APIDefinition{})
+		assert.Nil(t, g.LatencyBreakdown)
+
+		var apiDef apidef.APIDefinition
+		g.ExtractTo(&apiDef)
+		assert.Equal(t, apidef.LatencyBreakdown{}, apiDef.LatencyBreakdown)
+	})
+
+	t.Run("fill and extract", func(t *testing.T) {
+		t.Parallel()
+
+		breakdown := apidef.LatencyBreakdown{
+			Enabled:      true,
+			Threshold:    1500,
+			DebugHeaders: true,
+		}
+
+		g := new(Global)
+		g.Fill(apidef.APIDefinition{LatencyBreakdown: breakdown})
+		assert.Equal(t, &LatencyBreakdown{
+			Enabled:      true,
+			Threshold:    ReadableDuration(1500 * time.Millisecond),
+			DebugHeaders: true,
+		}, g.LatencyBreakdown)
+
+		var apiDef apidef.APIDefinition
+		g.ExtractTo(&apiDef)
+		assert.Equal(t, breakdown, apiDef.LatencyBreakdown)
+	})
+}
+
+func TestPayloadSizeAlerts(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		g := new(Global)
+		g.Fill(apidef.APIDefinition{})
+		assert.Nil(t, g.PayloadSizeAlerts)
+
+		var apiDef apidef.APIDefinition
+		g.ExtractTo(&apiDef)
+		assert.Equal(t, apidef.PayloadSizeAlerts{}, apiDef.PayloadSizeAlerts)
+	})
+
+	t.Run("fill and extract", func(t *testing.T) {
+		t.Parallel()
+
+		alerts := apidef.PayloadSizeAlerts{
+			Enabled:           true,
+			RequestThreshold:  1 << 20,
+			ResponseThreshold: 10 << 20,
+		}
+
+		g := new(Global)
+		g.Fill(apidef.APIDefinition{PayloadSizeAlerts: alerts})
+		assert.Equal(t, &PayloadSizeAlerts{
+			Enabled:           true,
+			RequestThreshold:  1 << 20,
+			ResponseThreshold: 10 << 20,
+		}, g.PayloadSizeAlerts)
+
+		var apiDef apidef.APIDefinition
+		g.ExtractTo(&apiDef)
+		assert.Equal(t, alerts, apiDef.PayloadSizeAlerts)
+	})
+}
+
 func TestCachePlugin_Fill(t *testing.T) {
 	t.Run("should fill cache plugin with provided values", func(t *testing.T) {
 		cacheMeta := apidef.CacheMeta{
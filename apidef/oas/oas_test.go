@@ -349,6 +349,7 @@ func TestOAS_ExtractTo_ResetAPIDefinition(t *testing.T) {
 		"APIDefinition.CustomMiddleware.TrafficLogs.Code",
 		"APIDefinition.CustomMiddleware.TrafficLogs.RequireSession",
 		"APIDefinition.CustomMiddleware.TrafficLogs.RawBodyOnly",
+		"APIDefinition.CustomMiddleware.TrafficLogs.StreamBody",
 		"APIDefinition.AuthProvider.Name",
 		"APIDefinition.AuthProvider.StorageEngine",
 		"APIDefinition.AuthProvider.Meta[0]",
@@ -403,6 +404,9 @@ func TestOAS_ExtractTo_ResetAPIDefinition(t *testing.T) {
 		"APIDefinition.GraphQL.Supergraph.GlobalHeaders[0]",
 		"APIDefinition.GraphQL.Supergraph.DisableQueryBatching",
 		"APIDefinition.GraphQL.Introspection.Disabled",
+		"APIDefinition.GraphQL.PersistedQueries.Enabled",
+		"APIDefinition.GraphQL.PersistedQueries.Strict",
+		"APIDefinition.GraphQL.PersistedQueries.Queries[0]",
 		"APIDefinition.AnalyticsPlugin.Enabled",
 		"APIDefinition.AnalyticsPlugin.PluginPath",
 		"APIDefinition.AnalyticsPlugin.FuncName",
@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 
@@ -87,6 +88,13 @@ type Operation struct {
 	// RateLimit contains endpoint level rate limit configuration.
 	RateLimit *RateLimitEndpoint `bson:"rateLimit,omitempty" json:"rateLimit,omitempty"`
 
+	// RateCost weights the requests to the endpoint in the rate limit of the key.
+	RateCost *RateCost `bson:"rateCost,omitempty" json:"rateCost,omitempty"`
+
+	// UpstreamHostHeader contains the configuration for the Host header sent to the upstream for the endpoint.
+	// It takes precedence over the API level configuration.
+	UpstreamHostHeader *UpstreamHostHeaderEndpoint `bson:"upstreamHostHeader,omitempty" json:"upstreamHostHeader,omitempty"`
+
 	// ScopeCheck toggles the operation-level OAuth 2.0 scope check.
 	ScopeCheck *ScopeCheck `bson:"scopeCheck,omitempty" json:"scopeCheck,omitempty"`
 
@@ -127,6 +135,8 @@ func (o *Operation) ExtractToExtendedPaths(ep *apidef.ExtendedPathsSet, path str
 	o.extractDoNotTrackEndpointTo(ep, path, method)
 	o.extractRequestSizeLimitTo(ep, path, method)
 	o.extractRateLimitEndpointTo(ep, path, method)
+	o.extractRateCostTo(ep, path, method)
+	o.extractUpstreamHostHeaderTo(ep, path, method)
 }
 
 // AllowanceType holds the valid allowance types values.
@@ -209,6 +219,8 @@ func (s *OAS) fillPathsAndOperations(ep apidef.ExtendedPathsSet) {
 	s.fillDoNotTrackEndpoint(ep.DoNotTrackEndpoints)
 	s.fillRequestSizeLimit(ep.SizeLimit)
 	s.fillRateLimitEndpoints(ep.RateLimit)
+	s.fillRateCost(ep.RateCost)
+	s.fillUpstreamHostHeader(ep.UpstreamHostHeader)
 	s.fillMockResponsePaths(s.Paths, ep)
 }
 
@@ -282,6 +294,10 @@ func (s *OAS) fillMockResponsePaths(paths *openapi3.Paths, ep apidef.ExtendedPat
 func (s *OAS) extractPathsAndOperations(ep *apidef.ExtendedPathsSet) {
 	ep.Clear()
 
+	if middleware := s.GetTykMiddleware(); middleware != nil && middleware.Global != nil && middleware.Global.CORS != nil {
+		middleware.Global.CORS.extractPathsTo(ep)
+	}
+
 	tykOperations := s.getTykOperations()
 	if len(tykOperations) == 0 {
 		return
@@ -311,6 +327,8 @@ func (s *OAS) extractPathsAndOperations(ep *apidef.ExtendedPathsSet) {
 					tykOp.extractDoNotTrackEndpointTo(ep, path, method)
 					tykOp.extractRequestSizeLimitTo(ep, path, method)
 					tykOp.extractRateLimitEndpointTo(ep, path, method)
+					tykOp.extractRateCostTo(ep, path, method)
+					tykOp.extractUpstreamHostHeaderTo(ep, path, method)
 					break
 				}
 			}
@@ -888,6 +906,11 @@ type MockResponse struct {
 	Headers Headers `bson:"headers,omitempty" json:"headers,omitempty"`
 	// FromOASExamples is the configuration to extract a mock response from OAS documentation.
 	FromOASExamples *FromOASExamples `bson:"fromOASExamples,omitempty" json:"fromOASExamples,omitempty"`
+	// Latency is the delay before the mock response is returned, used to simulate a slow upstream.
+	// The value is a duration string, e.g. `250ms`.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.mock_response[].latency` (in milliseconds).
+	Latency ReadableDuration `bson:"latency,omitempty" json:"latency,omitempty"`
 }
 
 // Fill populates the MockResponse fields from a classic API MockResponseMeta.
@@ -909,12 +932,14 @@ func (m *MockResponse) Fill(op apidef.MockResponseMeta) {
 	m.Code = op.Code
 	m.Body = op.Body
 	m.Headers = headers
+	m.Latency = ReadableDuration(time.Duration(op.Latency) * time.Millisecond)
 }
 
 func (m *MockResponse) ExtractTo(meta *apidef.MockResponseMeta) {
 	meta.Disabled = !m.Enabled
 	meta.Code = m.Code
 	meta.Body = m.Body
+	meta.Latency = m.Latency.Milliseconds()
 
 	// Initialize headers map even when empty
 	meta.Headers = make(map[string]string)
@@ -1016,6 +1041,56 @@ func (o *Operation) extractRateLimitEndpointTo(ep *apidef.ExtendedPathsSet, path
 	ep.RateLimit = append(ep.RateLimit, meta)
 }
 
+func (s *OAS) fillRateCost(metas []apidef.RateCostMeta) {
+	for _, meta := range metas {
+		operationID := s.getOperationID(meta.Path, meta.Method)
+		operation := s.GetTykExtension().getOperation(operationID)
+		if operation.RateCost == nil {
+			operation.RateCost = &RateCost{}
+		}
+
+		operation.RateCost.Fill(meta)
+		if ShouldOmit(operation.RateCost) {
+			operation.RateCost = nil
+		}
+	}
+}
+
+func (o *Operation) extractRateCostTo(ep *apidef.ExtendedPathsSet, path string, method string) {
+	if o.RateCost == nil {
+		return
+	}
+
+	meta := apidef.RateCostMeta{Path: path, Method: method}
+	o.RateCost.ExtractTo(&meta)
+	ep.RateCost = append(ep.RateCost, meta)
+}
+
+func (s *OAS) fillUpstreamHostHeader(metas []apidef.UpstreamHostMeta) {
+	for _, meta := range metas {
+		operationID := s.getOperationID(meta.Path, meta.Method)
+		operation := s.GetTykExtension().getOperation(operationID)
+		if operation.UpstreamHostHeader == nil {
+			operation.UpstreamHostHeader = &UpstreamHostHeaderEndpoint{}
+		}
+
+		operation.UpstreamHostHeader.Fill(meta)
+		if ShouldOmit(operation.UpstreamHostHeader) {
+			operation.UpstreamHostHeader = nil
+		}
+	}
+}
+
+func (o *Operation) extractUpstreamHostHeaderTo(ep *apidef.ExtendedPathsSet, path string, method string) {
+	if o.UpstreamHostHeader == nil {
+		return
+	}
+
+	meta := apidef.UpstreamHostMeta{Path: path, Method: method}
+	o.UpstreamHostHeader.ExtractTo(&meta)
+	ep.UpstreamHostHeader = append(ep.UpstreamHostHeader, meta)
+}
+
 func (s *OAS) fillEndpointPostPlugins(endpointMetas []apidef.GoPluginMeta) {
 	for _, em := range endpointMetas {
 		operationID := s.getOperationID(em.Path, em.Method)
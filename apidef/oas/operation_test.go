@@ -536,6 +536,16 @@ var methodSetters = map[string]func(*openapi3.PathItem, *openapi3.Operation){
 	"OPTIONS": func(p *openapi3.PathItem, op *openapi3.Operation) { p.SetOperation("OPTIONS", op) },
 }
 
+func TestMockResponse_Latency(t *testing.T) {
+	var mockResponse MockResponse
+	mockResponse.Fill(apidef.MockResponseMeta{Code: http.StatusOK, Latency: 250})
+	assert.Equal(t, ReadableDuration(250*time.Millisecond), mockResponse.Latency)
+
+	var meta apidef.MockResponseMeta
+	mockResponse.ExtractTo(&meta)
+	assert.Equal(t, int64(250), meta.Latency)
+}
+
 func TestOAS_MockResponse_fillMockResponsePaths(t *testing.T) {
 	t.Parallel()
 
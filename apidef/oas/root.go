@@ -22,6 +22,9 @@ type XTykAPIGateway struct {
 	Middleware *Middleware `bson:"middleware,omitempty" json:"middleware,omitempty"`
 	// ErrorOverrides contains the configurations for error response customization.
 	ErrorOverrides *ErrorOverrides `bson:"errorOverrides,omitempty" json:"errorOverrides,omitempty"`
+	// ErrorTemplates contains the templates of the error bodies of the API, keyed by status code or `default`.
+	// Tyk classic API definition: `error_templates`
+	ErrorTemplates ErrorTemplates `bson:"errorTemplates,omitempty" json:"errorTemplates,omitempty"`
 }
 
 // Fill fills *XTykAPIGateway from apidef.APIDefinition.
@@ -44,6 +47,9 @@ func (x *XTykAPIGateway) Fill(api apidef.APIDefinition) {
 		x.ErrorOverrides = &ErrorOverrides{}
 		x.ErrorOverrides.Fill(api)
 	}
+
+	x.ErrorTemplates = nil
+	x.ErrorTemplates.Fill(api)
 }
 
 // ExtractTo extracts *XTykAPIGateway into *apidef.APIDefinition.
@@ -70,6 +76,8 @@ func (x *XTykAPIGateway) ExtractTo(api *apidef.APIDefinition) {
 		api.ErrorOverridesDisabled = true
 		api.ErrorOverrides = nil
 	}
+
+	x.ErrorTemplates.ExtractTo(api)
 }
 
 // Info contains the main metadata for the API definition.
@@ -195,6 +203,45 @@ type Versioning struct {
 	// If set to `true` then the default API version will be invoked; if set to `false` Tyk will return an HTTP 404
 	// `This API version does not seem to exist` error in this scenario.
 	FallbackToDefault bool `bson:"fallbackToDefault,omitempty" json:"fallbackToDefault,omitempty"`
+	// Sources is the ordered list of request locations the version is read from, the first one holding a version
+	// is used. When empty, the version is read from Location and Key.
+	//
+	// Tyk classic API definition: `version_definition.sources`.
+	Sources []VersionSource `bson:"sources,omitempty" json:"sources,omitempty"`
+	// Sunset contains the deprecated and retired versions. Requests to a retired version get a 410 Gone response.
+	//
+	// Tyk classic API definition: `version_definition.sunset`.
+	Sunset []VersionSunset `bson:"sunset,omitempty" json:"sunset,omitempty"`
+}
+
+// VersionSource is a request location the version is read from.
+//
+// Tyk classic API definition: Entry in `version_definition.sources` list.
+type VersionSource struct {
+	// Location is one of `header`, `url-param` or `url`.
+	Location string `bson:"location" json:"location"`
+	// Key is the name of the header or query parameter holding the version, unused for the `url` location.
+	Key string `bson:"key,omitempty" json:"key,omitempty"`
+}
+
+// VersionSunset deprecates and retires a version.
+//
+// Tyk classic API definition: Entry in `version_definition.sunset` map.
+type VersionSunset struct {
+	// Name is the name of the deprecated or retired version.
+	Name string `bson:"name" json:"name"`
+	// Deprecation is the RFC3339 time the version is deprecated at, returned in the `Deprecation` response header.
+	// The responses of a deprecated version carry a `Warning` header.
+	Deprecation string `bson:"deprecation,omitempty" json:"deprecation,omitempty"`
+	// Date is the RFC3339 time the version is retired at, returned in the `Sunset` response header.
+	// The version is served until then. An empty date retires the version immediately, unless it's only deprecated.
+	Date string `bson:"date,omitempty" json:"date,omitempty"`
+	// Message is the body of the 410 Gone response returned for the retired version.
+	Message string `bson:"message,omitempty" json:"message,omitempty"`
+	// Warning is the text of the `Warning` response header of the deprecated version.
+	Warning string `bson:"warning,omitempty" json:"warning,omitempty"`
+	// Link is the URL documenting the deprecation, returned in a `Link` response header.
+	Link string `bson:"link,omitempty" json:"link,omitempty"`
 }
 
 // Fill fills *Versioning from apidef.APIDefinition.
@@ -216,6 +263,27 @@ func (v *Versioning) Fill(api apidef.APIDefinition) {
 	v.StripVersioningData = api.VersionDefinition.StripVersioningData
 	v.FallbackToDefault = api.VersionDefinition.FallbackToDefault
 	v.UrlVersioningPattern = api.VersionDefinition.UrlVersioningPattern
+
+	v.Sources = nil
+	for _, source := range api.VersionDefinition.Sources {
+		v.Sources = append(v.Sources, VersionSource{Location: source.Location, Key: source.Key})
+	}
+
+	v.Sunset = nil
+	for vName, sunset := range api.VersionDefinition.Sunset {
+		v.Sunset = append(v.Sunset, VersionSunset{
+			Name:        vName,
+			Deprecation: sunset.Deprecation,
+			Date:        sunset.Date,
+			Message:     sunset.Message,
+			Warning:     sunset.Warning,
+			Link:        sunset.Link,
+		})
+	}
+
+	sort.Slice(v.Sunset, func(i, j int) bool {
+		return v.Sunset[i].Name < v.Sunset[j].Name
+	})
 }
 
 // ExtractTo extracts *Versioning into *apidef.APIDefinition.
@@ -238,6 +306,25 @@ func (v *Versioning) ExtractTo(api *apidef.APIDefinition) {
 	api.VersionDefinition.StripVersioningData = v.StripVersioningData
 	api.VersionDefinition.UrlVersioningPattern = v.UrlVersioningPattern
 	api.VersionDefinition.FallbackToDefault = v.FallbackToDefault
+
+	api.VersionDefinition.Sources = nil
+	for _, source := range v.Sources {
+		api.VersionDefinition.Sources = append(api.VersionDefinition.Sources, apidef.VersionSource{Location: source.Location, Key: source.Key})
+	}
+
+	api.VersionDefinition.Sunset = nil
+	if len(v.Sunset) > 0 {
+		api.VersionDefinition.Sunset = make(map[string]apidef.VersionSunset, len(v.Sunset))
+		for _, sunset := range v.Sunset {
+			api.VersionDefinition.Sunset[sunset.Name] = apidef.VersionSunset{
+				Deprecation: sunset.Deprecation,
+				Date:        sunset.Date,
+				Message:     sunset.Message,
+				Warning:     sunset.Warning,
+				Link:        sunset.Link,
+			}
+		}
+	}
 }
 
 // VersionToID contains a single mapping from a version name into an API ID.
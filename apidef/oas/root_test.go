@@ -433,6 +433,46 @@ func TestVersioning(t *testing.T) {
 	resultVersioning.Fill(convertedAPI)
 
 	assert.Equal(t, emptyVersioning, resultVersioning)
+
+	t.Run("sources and sunset", func(t *testing.T) {
+		versioning := Versioning{
+			Enabled:  true,
+			Name:     "v1",
+			Default:  "v1",
+			Location: apidef.HeaderLocation,
+			Key:      "X-API-Version",
+			Versions: []VersionToID{{Name: "v2", ID: "v2-api-id"}},
+			Sources: []VersionSource{
+				{Location: apidef.HeaderLocation, Key: "X-API-Version"},
+				{Location: apidef.URLParamLocation, Key: "version"},
+				{Location: apidef.URLLocation},
+			},
+			Sunset: []VersionSunset{
+				{Name: "v1", Date: "2030-01-01T00:00:00Z"},
+				{Name: "v2", Message: "v2 is retired"},
+				{Name: "v3", Deprecation: "2029-01-01T00:00:00Z", Warning: "v3 is deprecated", Link: "https://example.com/v3"},
+			},
+		}
+
+		var api apidef.APIDefinition
+		versioning.ExtractTo(&api)
+
+		assert.Equal(t, []apidef.VersionSource{
+			{Location: apidef.HeaderLocation, Key: "X-API-Version"},
+			{Location: apidef.URLParamLocation, Key: "version"},
+			{Location: apidef.URLLocation},
+		}, api.VersionDefinition.Sources)
+		assert.Equal(t, map[string]apidef.VersionSunset{
+			"v1": {Date: "2030-01-01T00:00:00Z"},
+			"v2": {Message: "v2 is retired"},
+			"v3": {Deprecation: "2029-01-01T00:00:00Z", Warning: "v3 is deprecated", Link: "https://example.com/v3"},
+		}, api.VersionDefinition.Sunset)
+
+		var result Versioning
+		result.Fill(api)
+
+		assert.Equal(t, versioning, result)
+	})
 }
 
 func TestXTykAPIGateway_enableTrafficLogsIfEmpty(t *testing.T) {
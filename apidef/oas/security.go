@@ -622,6 +622,12 @@ type OAuth struct {
 	// Tyk classic API definition: `oauth_meta.allowed_access_types` (contains REFRESH_TOKEN).
 	RefreshToken bool `bson:"refreshToken,omitempty" json:"refreshToken,omitempty"`
 
+	// RotateRefreshTokens invalidates a refresh token once it has been exchanged and issues a new one.
+	// Presenting an already exchanged refresh token revokes all tokens issued to the client.
+	//
+	// Tyk classic API definition: `oauth_meta.rotate_refresh_tokens`.
+	RotateRefreshTokens bool `bson:"rotateRefreshTokens,omitempty" json:"rotateRefreshTokens,omitempty"`
+
 	// AuthLoginRedirect configures a URL to redirect to after a successful login.
 	//
 	// Tyk classic API definition: `oauth_meta.auth_login_redirect`.
@@ -631,6 +637,44 @@ type OAuth struct {
 	//
 	// Tyk classic API definition: `notifications`.
 	Notifications *Notifications `bson:"notifications,omitempty" json:"notifications,omitempty"`
+
+	// ClientRegistration configures the dynamic registration of OAuth clients.
+	//
+	// Tyk classic API definition: `oauth_meta.client_registration`.
+	ClientRegistration *OAuthClientRegistration `bson:"clientRegistration,omitempty" json:"clientRegistration,omitempty"`
+}
+
+// OAuthClientRegistration configures the dynamic registration of OAuth clients (RFC 7591) on the
+// `/oauth/register` endpoint of the API.
+type OAuthClientRegistration struct {
+	// Enabled activates the client registration endpoints.
+	//
+	// Tyk classic API definition: `oauth_meta.client_registration.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+
+	// InitialAccessToken is the bearer token required to register a client. Registration is refused while it's empty.
+	//
+	// Tyk classic API definition: `oauth_meta.client_registration.initial_access_token`.
+	InitialAccessToken string `bson:"initialAccessToken,omitempty" json:"initialAccessToken,omitempty"`
+
+	// PolicyID is the policy of the registered clients.
+	//
+	// Tyk classic API definition: `oauth_meta.client_registration.policy_id`.
+	PolicyID string `bson:"policyId,omitempty" json:"policyId,omitempty"`
+}
+
+// Fill fills *OAuthClientRegistration from apidef.OAuthClientRegistration.
+func (c *OAuthClientRegistration) Fill(registration apidef.OAuthClientRegistration) {
+	c.Enabled = registration.Enabled
+	c.InitialAccessToken = registration.InitialAccessToken
+	c.PolicyID = registration.PolicyID
+}
+
+// ExtractTo extracts *OAuthClientRegistration into *apidef.OAuthClientRegistration.
+func (c *OAuthClientRegistration) ExtractTo(registration *apidef.OAuthClientRegistration) {
+	registration.Enabled = c.Enabled
+	registration.InitialAccessToken = c.InitialAccessToken
+	registration.PolicyID = c.PolicyID
 }
 
 // Import populates *OAuth from it's arguments.
@@ -656,6 +700,7 @@ func (s *OAS) fillOAuth(api apidef.APIDefinition) {
 
 	oauth.AllowedAuthorizeTypes = api.Oauth2Meta.AllowedAuthorizeTypes
 	oauth.AuthLoginRedirect = api.Oauth2Meta.AuthorizeLoginRedirect
+	oauth.RotateRefreshTokens = api.Oauth2Meta.RotateRefreshTokens
 
 	for _, accessType := range api.Oauth2Meta.AllowedAccessTypes {
 		if accessType == osin.REFRESH_TOKEN {
@@ -673,6 +718,15 @@ func (s *OAS) fillOAuth(api apidef.APIDefinition) {
 		oauth.Notifications = nil
 	}
 
+	if oauth.ClientRegistration == nil {
+		oauth.ClientRegistration = &OAuthClientRegistration{}
+	}
+
+	oauth.ClientRegistration.Fill(api.Oauth2Meta.ClientRegistration)
+	if ShouldOmit(oauth.ClientRegistration) {
+		oauth.ClientRegistration = nil
+	}
+
 	if ShouldOmit(oauth) {
 		oauth = nil
 	}
@@ -688,6 +742,7 @@ func (s *OAS) extractOAuthTo(api *apidef.APIDefinition, name string) {
 		oauth.AuthSources.ExtractTo(&authConfig)
 		api.Oauth2Meta.AllowedAuthorizeTypes = oauth.AllowedAuthorizeTypes
 		api.Oauth2Meta.AuthorizeLoginRedirect = oauth.AuthLoginRedirect
+		api.Oauth2Meta.RotateRefreshTokens = oauth.RotateRefreshTokens
 		api.Oauth2Meta.AllowedAccessTypes = []osin.AccessRequestType{}
 		if oauth.RefreshToken {
 			api.Oauth2Meta.AllowedAccessTypes = append(api.Oauth2Meta.AllowedAccessTypes, osin.REFRESH_TOKEN)
@@ -696,6 +751,10 @@ func (s *OAS) extractOAuthTo(api *apidef.APIDefinition, name string) {
 		if oauth.Notifications != nil {
 			oauth.Notifications.ExtractTo(&api.NotificationsDetails)
 		}
+
+		if oauth.ClientRegistration != nil {
+			oauth.ClientRegistration.ExtractTo(&api.Oauth2Meta.ClientRegistration)
+		}
 	}
 
 	s.extractOAuthSchemeTo(api, name)
@@ -1512,6 +1571,8 @@ func resetSecuritySchemes(api *apidef.APIDefinition) {
 	api.Oauth2Meta.AllowedAccessTypes = nil
 	api.Oauth2Meta.AllowedAuthorizeTypes = nil
 	api.Oauth2Meta.AuthorizeLoginRedirect = ""
+	api.Oauth2Meta.RotateRefreshTokens = false
+	api.Oauth2Meta.ClientRegistration = apidef.OAuthClientRegistration{}
 	api.NotificationsDetails = apidef.NotificationsManager{}
 
 	// External OAuth
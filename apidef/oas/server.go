@@ -44,6 +44,11 @@ type Server struct {
 	// Tyk classic API definition: `allowed_ips` and `blacklisted_ips`.
 	IPAccessControl *IPAccessControl `bson:"ipAccessControl,omitempty" json:"ipAccessControl,omitempty"`
 
+	// GeoRestriction configures allowing or blocking requests by the country of the client IP.
+	//
+	// Tyk classic API definition: `geo_restriction`.
+	GeoRestriction *GeoRestriction `bson:"geoRestriction,omitempty" json:"geoRestriction,omitempty"`
+
 	// BatchProcessing contains configuration settings to enable or disable batch request support for the API.
 	//
 	// Tyk classic API definition: `enable_batch_request_support`.
@@ -122,6 +127,7 @@ func (s *Server) Fill(api apidef.APIDefinition) {
 	}
 
 	s.fillIPAccessControl(api)
+	s.fillGeoRestriction(api)
 	s.fillBatchProcessing(api)
 }
 
@@ -186,6 +192,7 @@ func (s *Server) ExtractTo(api *apidef.APIDefinition) {
 	s.EventHandlers.ExtractTo(api)
 
 	s.extractIPAccessControlTo(api)
+	s.extractGeoRestrictionTo(api)
 	s.extractBatchProcessingTo(api)
 }
 
@@ -207,18 +214,26 @@ type ListenPath struct {
 	//
 	// Tyk classic API definition: `proxy.strip_listen_path`
 	Strip bool `bson:"strip,omitempty" json:"strip,omitempty"`
+
+	// Priority orders the matching of overlapping listen paths. The listen paths of a higher priority
+	// are matched first, the longest listen path is matched first between equal priorities.
+	//
+	// Tyk classic API definition: `proxy.listen_path_priority`
+	Priority int `bson:"priority,omitempty" json:"priority,omitempty"`
 }
 
 // Fill fills *ListenPath from apidef.APIDefinition.
 func (lp *ListenPath) Fill(api apidef.APIDefinition) {
 	lp.Value = api.Proxy.ListenPath
 	lp.Strip = api.Proxy.StripListenPath
+	lp.Priority = api.Proxy.ListenPathPriority
 }
 
 // ExtractTo extracts *ListenPath into *apidef.APIDefinition.
 func (lp *ListenPath) ExtractTo(api *apidef.APIDefinition) {
 	api.Proxy.ListenPath = lp.Value
 	api.Proxy.StripListenPath = lp.Strip
+	api.Proxy.ListenPathPriority = lp.Priority
 }
 
 // ClientCertificates contains the configurations related to establishing static mutual TLS between the client and Tyk.
@@ -306,16 +321,37 @@ type DetailedActivityLogs struct {
 	//
 	// Tyk classic API definition: `enable_detailed_recording`
 	Enabled bool `bson:"enabled" json:"enabled"`
+	// MaxBodySize truncates the recorded request and response bodies to this many bytes.
+	// The default of 0 applies the gateway limit, if any.
+	//
+	// Tyk classic API definition: `detailed_recording_config.max_body_size`
+	MaxBodySize int64 `bson:"maxBodySize,omitempty" json:"maxBodySize,omitempty"`
+	// RedactHeaders are the names of the headers whose recorded values are replaced with `***`.
+	//
+	// Tyk classic API definition: `detailed_recording_config.redact_headers`
+	RedactHeaders []string `bson:"redactHeaders,omitempty" json:"redactHeaders,omitempty"`
+	// RedactFields are the names of the JSON body fields whose recorded values are replaced with `***`.
+	//
+	// Tyk classic API definition: `detailed_recording_config.redact_fields`
+	RedactFields []string `bson:"redactFields,omitempty" json:"redactFields,omitempty"`
 }
 
 // ExtractTo extracts *DetailedActivityLogs into *apidef.APIDefinition.
 func (d *DetailedActivityLogs) ExtractTo(api *apidef.APIDefinition) {
 	api.EnableDetailedRecording = d.Enabled
+	api.DetailedRecordingConfig = apidef.DetailedRecordingConfig{
+		MaxBodySize:   d.MaxBodySize,
+		RedactHeaders: d.RedactHeaders,
+		RedactFields:  d.RedactFields,
+	}
 }
 
 // Fill fills *DetailedActivityLogs from apidef.APIDefinition.
 func (d *DetailedActivityLogs) Fill(api apidef.APIDefinition) {
 	d.Enabled = api.EnableDetailedRecording
+	d.MaxBodySize = api.DetailedRecordingConfig.MaxBodySize
+	d.RedactHeaders = api.DetailedRecordingConfig.RedactHeaders
+	d.RedactFields = api.DetailedRecordingConfig.RedactFields
 }
 
 // DetailedTracing holds the configuration of the detailed tracing.
@@ -392,22 +428,94 @@ func (s *Server) extractIPAccessControlTo(api *apidef.APIDefinition) {
 	s.IPAccessControl.ExtractTo(api)
 }
 
+// GeoRestriction represents the configuration for allowing or blocking requests by the country of the client IP,
+// looked up in the MaxMind database configured in the Gateway.
+type GeoRestriction struct {
+	// Enabled activates the country checks.
+	//
+	// Tyk classic API definition: `geo_restriction.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"` // required
+
+	// Allow is a list of ISO 3166-1 alpha-2 country codes (e.g. "GB") allowed to access the API.
+	// When set, requests from the other countries and from IPs without a known country are blocked.
+	//
+	// Tyk classic API definition: `geo_restriction.allowed_countries`.
+	Allow []string `bson:"allow,omitempty" json:"allow,omitempty"`
+
+	// Block is a list of ISO 3166-1 alpha-2 country codes blocked from the API.
+	// If a country is present in both Allow and Block, the Block rule will take precedence.
+	//
+	// Tyk classic API definition: `geo_restriction.blocked_countries`.
+	Block []string `bson:"block,omitempty" json:"block,omitempty"`
+
+	// FailOpen lets the requests through when the database is missing or expired, they are blocked otherwise.
+	//
+	// Tyk classic API definition: `geo_restriction.fail_open`.
+	FailOpen bool `bson:"failOpen,omitempty" json:"failOpen,omitempty"`
+}
+
+// Fill fills *GeoRestriction from apidef.APIDefinition.
+func (g *GeoRestriction) Fill(api apidef.APIDefinition) {
+	g.Enabled = api.GeoRestriction.Enabled
+	g.Allow = api.GeoRestriction.AllowedCountries
+	g.Block = api.GeoRestriction.BlockedCountries
+	g.FailOpen = api.GeoRestriction.FailOpen
+}
+
+// ExtractTo extracts *GeoRestriction into *apidef.APIDefinition.
+func (g *GeoRestriction) ExtractTo(api *apidef.APIDefinition) {
+	api.GeoRestriction.Enabled = g.Enabled
+	api.GeoRestriction.AllowedCountries = g.Allow
+	api.GeoRestriction.BlockedCountries = g.Block
+	api.GeoRestriction.FailOpen = g.FailOpen
+}
+
+func (s *Server) fillGeoRestriction(api apidef.APIDefinition) {
+	if s.GeoRestriction == nil {
+		s.GeoRestriction = &GeoRestriction{}
+	}
+
+	s.GeoRestriction.Fill(api)
+	if ShouldOmit(s.GeoRestriction) {
+		s.GeoRestriction = nil
+	}
+}
+
+func (s *Server) extractGeoRestrictionTo(api *apidef.APIDefinition) {
+	if s.GeoRestriction == nil {
+		s.GeoRestriction = &GeoRestriction{}
+		defer func() {
+			s.GeoRestriction = nil
+		}()
+	}
+
+	s.GeoRestriction.ExtractTo(api)
+}
+
 // BatchProcessing represents the configuration for enabling or disabling batch request support for an API.
 type BatchProcessing struct {
 	// Enabled determines whether batch request support is enabled or disabled for the API.
 	//
 	// Tyk classic API definition: `enable_batch_request_support`.
 	Enabled bool `bson:"enabled" json:"enabled"` // required
+
+	// MaxParallelism limits how many requests of a batch are executed concurrently.
+	// When unset, the gateway level `batch_request_max_parallelism` setting is used.
+	//
+	// Tyk classic API definition: `batch_request_max_parallelism`.
+	MaxParallelism int `bson:"maxParallelism,omitempty" json:"maxParallelism,omitempty"`
 }
 
 // Fill updates the BatchProcessing configuration based on the EnableBatchRequestSupport value from the given APIDefinition.
 func (b *BatchProcessing) Fill(api apidef.APIDefinition) {
 	b.Enabled = api.EnableBatchRequestSupport
+	b.MaxParallelism = api.BatchRequestMaxParallelism
 }
 
 // ExtractTo copies the Enabled state of BatchProcessing into the EnableBatchRequestSupport field of the provided APIDefinition.
 func (b *BatchProcessing) ExtractTo(api *apidef.APIDefinition) {
 	api.EnableBatchRequestSupport = b.Enabled
+	api.BatchRequestMaxParallelism = b.MaxParallelism
 }
 
 func (s *Server) fillBatchProcessing(api apidef.APIDefinition) {
@@ -344,6 +344,48 @@ func TestExportDetailedTracing(t *testing.T) {
 	}
 }
 
+func TestDetailedActivityLogs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fill and extract", func(t *testing.T) {
+		t.Parallel()
+
+		api := apidef.APIDefinition{
+			EnableDetailedRecording: true,
+			DetailedRecordingConfig: apidef.DetailedRecordingConfig{
+				MaxBodySize:   1024,
+				RedactHeaders: []string{"Authorization"},
+				RedactFields:  []string{"password"},
+			},
+		}
+
+		server := new(Server)
+		server.Fill(api)
+
+		assert.Equal(t, &DetailedActivityLogs{
+			Enabled:       true,
+			MaxBodySize:   1024,
+			RedactHeaders: []string{"Authorization"},
+			RedactFields:  []string{"password"},
+		}, server.DetailedActivityLogs)
+
+		var extracted apidef.APIDefinition
+		server.ExtractTo(&extracted)
+
+		assert.Equal(t, api.EnableDetailedRecording, extracted.EnableDetailedRecording)
+		assert.Equal(t, api.DetailedRecordingConfig, extracted.DetailedRecordingConfig)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		server := new(Server)
+		server.Fill(apidef.APIDefinition{})
+
+		assert.Nil(t, server.DetailedActivityLogs)
+	})
+}
+
 func TestIPAccessControl(t *testing.T) {
 	t.Run("empty", func(t *testing.T) {
 		var emptyIPAccessControl IPAccessControl
@@ -378,6 +420,40 @@ func TestIPAccessControl(t *testing.T) {
 	})
 }
 
+func TestGeoRestriction(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		var s Server
+		s.Fill(apidef.APIDefinition{})
+		assert.Nil(t, s.GeoRestriction)
+
+		var api apidef.APIDefinition
+		s.ExtractTo(&api)
+		assert.Equal(t, apidef.GeoRestriction{}, api.GeoRestriction)
+	})
+
+	t.Run("fill and extract", func(t *testing.T) {
+		geoRestriction := apidef.GeoRestriction{
+			Enabled:          true,
+			AllowedCountries: []string{"GB", "US"},
+			BlockedCountries: []string{"SE"},
+			FailOpen:         true,
+		}
+
+		var s Server
+		s.Fill(apidef.APIDefinition{GeoRestriction: geoRestriction})
+		assert.Equal(t, &GeoRestriction{
+			Enabled:  true,
+			Allow:    []string{"GB", "US"},
+			Block:    []string{"SE"},
+			FailOpen: true,
+		}, s.GeoRestriction)
+
+		var api apidef.APIDefinition
+		s.ExtractTo(&api)
+		assert.Equal(t, geoRestriction, api.GeoRestriction)
+	})
+}
+
 func TestBatchProcessing(t *testing.T) {
 	t.Run("fill", func(t *testing.T) {
 		type testCase struct {
@@ -48,6 +48,10 @@ type Upstream struct {
 	// Tyk classic API definition: `proxy.preserve_host_header`.
 	PreserveHostHeader *PreserveHostHeader `bson:"preserveHostHeader,omitempty" json:"preserveHostHeader,omitempty"`
 
+	// HostHeader contains the configuration for the Host header sent to the upstream.
+	// Tyk classic API definition: `proxy.upstream_host_header`.
+	HostHeader *UpstreamHostHeader `bson:"hostHeader,omitempty" json:"hostHeader,omitempty"`
+
 	// PreserveTrailingSlash controls whether Tyk preserves trailing slashes in URLs when proxying
 	// requests to upstream services. When enabled, URLs like "/users/" will retain the trailing slash.
 	// Tyk classic API definition: `proxy.disable_strip_slash`.
@@ -64,6 +68,29 @@ type Upstream struct {
 	// EnforceTimeout contains the configuration related to API level timeout duration.
 	// Tyk classic API definition: `version_data.versions.<version_name>.global_enforce_timeout`.
 	EnforceTimeout *GlobalEnforceTimeout `bson:"enforceTimeout,omitempty" json:"enforceTimeout,omitempty"`
+
+	// Mirror contains the configuration for shadowing a sample of the traffic to a secondary upstream.
+	// Tyk classic API definition: `proxy.mirror`.
+	Mirror *Mirror `bson:"mirror,omitempty" json:"mirror,omitempty"`
+
+	// Canary contains the configuration for routing requests to a canary upstream.
+	// Tyk classic API definition: `proxy.canary`.
+	Canary *Canary `bson:"canary,omitempty" json:"canary,omitempty"`
+
+	// GRPCWeb contains the configuration for translating gRPC-Web requests to native gRPC.
+	// Tyk classic API definition: `proxy.grpc_web`.
+	GRPCWeb *GRPCWeb `bson:"grpcWeb,omitempty" json:"grpcWeb,omitempty"`
+
+	// WebSocket contains the configuration for limiting websocket connections proxied to the upstream.
+	WebSocket *WebSocket `bson:"websocket,omitempty" json:"websocket,omitempty"`
+
+	// OutlierDetection contains the configuration for ejecting failing load balanced targets from rotation.
+	// Tyk classic API definition: `proxy.outlier_detection`.
+	OutlierDetection *OutlierDetection `bson:"outlierDetection,omitempty" json:"outlierDetection,omitempty"`
+
+	// ConcurrencyLimit contains the configuration for limiting the number of requests in flight to the upstream.
+	// Tyk classic API definition: `concurrency_limit`.
+	ConcurrencyLimit *ConcurrencyLimit `bson:"concurrencyLimit,omitempty" json:"concurrencyLimit,omitempty"`
 }
 
 // Fill fills *Upstream from apidef.APIDefinition.
@@ -149,8 +176,63 @@ func (u *Upstream) Fill(api apidef.APIDefinition) {
 		u.EnforceTimeout = nil
 	}
 
+	if u.Mirror == nil {
+		u.Mirror = &Mirror{}
+	}
+
+	u.Mirror.Fill(api)
+	if ShouldOmit(u.Mirror) {
+		u.Mirror = nil
+	}
+
+	if u.Canary == nil {
+		u.Canary = &Canary{}
+	}
+
+	u.Canary.Fill(api)
+	if ShouldOmit(u.Canary) {
+		u.Canary = nil
+	}
+
+	if u.GRPCWeb == nil {
+		u.GRPCWeb = &GRPCWeb{}
+	}
+
+	u.GRPCWeb.Fill(api)
+	if ShouldOmit(u.GRPCWeb) {
+		u.GRPCWeb = nil
+	}
+
+	if u.WebSocket == nil {
+		u.WebSocket = &WebSocket{}
+	}
+
+	u.WebSocket.Fill(api)
+	if ShouldOmit(u.WebSocket) {
+		u.WebSocket = nil
+	}
+
+	if u.OutlierDetection == nil {
+		u.OutlierDetection = &OutlierDetection{}
+	}
+
+	u.OutlierDetection.Fill(api)
+	if ShouldOmit(u.OutlierDetection) {
+		u.OutlierDetection = nil
+	}
+
+	if u.ConcurrencyLimit == nil {
+		u.ConcurrencyLimit = &ConcurrencyLimit{}
+	}
+
+	u.ConcurrencyLimit.Fill(api)
+	if ShouldOmit(u.ConcurrencyLimit) {
+		u.ConcurrencyLimit = nil
+	}
+
 	u.fillLoadBalancing(api)
 	u.fillPreserveHostHeader(api)
+	u.fillHostHeader(api)
 	u.fillPreserveTrailingSlash(api)
 }
 
@@ -165,6 +247,18 @@ func (u *Upstream) fillPreserveTrailingSlash(api apidef.APIDefinition) {
 	}
 }
 
+func (u *Upstream) fillHostHeader(api apidef.APIDefinition) {
+	if u.HostHeader == nil {
+		u.HostHeader = &UpstreamHostHeader{}
+	}
+
+	u.HostHeader.Fill(api)
+
+	if !u.HostHeader.Enabled {
+		u.HostHeader = nil
+	}
+}
+
 func (u *Upstream) fillPreserveHostHeader(api apidef.APIDefinition) {
 	if u.PreserveHostHeader == nil {
 		u.PreserveHostHeader = &PreserveHostHeader{}
@@ -261,7 +355,56 @@ func (u *Upstream) ExtractTo(api *apidef.APIDefinition) {
 	}
 	u.EnforceTimeout.ExtractTo(api)
 
+	if u.Mirror == nil {
+		u.Mirror = &Mirror{}
+		defer func() {
+			u.Mirror = nil
+		}()
+	}
+	u.Mirror.ExtractTo(api)
+
+	if u.Canary == nil {
+		u.Canary = &Canary{}
+		defer func() {
+			u.Canary = nil
+		}()
+	}
+	u.Canary.ExtractTo(api)
+
+	if u.GRPCWeb == nil {
+		u.GRPCWeb = &GRPCWeb{}
+		defer func() {
+			u.GRPCWeb = nil
+		}()
+	}
+	u.GRPCWeb.ExtractTo(api)
+
+	if u.WebSocket == nil {
+		u.WebSocket = &WebSocket{}
+		defer func() {
+			u.WebSocket = nil
+		}()
+	}
+	u.WebSocket.ExtractTo(api)
+
+	if u.OutlierDetection == nil {
+		u.OutlierDetection = &OutlierDetection{}
+		defer func() {
+			u.OutlierDetection = nil
+		}()
+	}
+	u.OutlierDetection.ExtractTo(api)
+
+	if u.ConcurrencyLimit == nil {
+		u.ConcurrencyLimit = &ConcurrencyLimit{}
+		defer func() {
+			u.ConcurrencyLimit = nil
+		}()
+	}
+	u.ConcurrencyLimit.ExtractTo(api)
+
 	u.preserveHostHeaderExtractTo(api)
+	u.hostHeaderExtractTo(api)
 	u.preserveTrailingSlashExtractTo(api)
 }
 
@@ -276,6 +419,17 @@ func (u *Upstream) preserveHostHeaderExtractTo(api *apidef.APIDefinition) {
 	u.PreserveHostHeader.ExtractTo(api)
 }
 
+func (u *Upstream) hostHeaderExtractTo(api *apidef.APIDefinition) {
+	if u.HostHeader == nil {
+		u.HostHeader = &UpstreamHostHeader{}
+		defer func() {
+			u.HostHeader = nil
+		}()
+	}
+
+	u.HostHeader.ExtractTo(api)
+}
+
 func (u *Upstream) preserveTrailingSlashExtractTo(api *apidef.APIDefinition) {
 	if u.PreserveTrailingSlash == nil {
 		u.PreserveTrailingSlash = &PreserveTrailingSlash{}
@@ -354,6 +508,24 @@ type TLSTransport struct {
 	//
 	// Tyk classic API definition: `proxy.transport.ssl_force_common_name_check`
 	ForceCommonNameCheck bool `bson:"forceCommonNameCheck,omitempty" json:"forceCommonNameCheck,omitempty"`
+
+	// CACertificates are the IDs of the CA certificates verifying the upstream certificate.
+	// When set, they're used instead of the system certificate pool.
+	//
+	// Tyk classic API definition: `proxy.transport.ssl_ca_certificates`
+	CACertificates []string `bson:"caCertificates,omitempty" json:"caCertificates,omitempty"`
+
+	// ClientCertificate is the ID of the client certificate presented to the upstream.
+	// The upstream certificates configured for the upstream host take precedence.
+	//
+	// Tyk classic API definition: `proxy.transport.ssl_client_certificate`
+	ClientCertificate string `bson:"clientCertificate,omitempty" json:"clientCertificate,omitempty"`
+
+	// ServerName overrides the server name sent to the upstream in the TLS handshake (SNI),
+	// which is also the name verified in the upstream certificate.
+	//
+	// Tyk classic API definition: `proxy.transport.ssl_server_name`
+	ServerName string `bson:"serverName,omitempty" json:"serverName,omitempty"`
 }
 
 // Fill fills *TLSTransport from apidef.ServiceDiscoveryConfiguration.
@@ -363,6 +535,9 @@ func (t *TLSTransport) Fill(api apidef.APIDefinition) {
 	t.MaxVersion = t.tlsVersionToString(api.Proxy.Transport.SSLMaxVersion)
 	t.MinVersion = t.tlsVersionToString(api.Proxy.Transport.SSLMinVersion)
 	t.InsecureSkipVerify = api.Proxy.Transport.SSLInsecureSkipVerify
+	t.CACertificates = api.Proxy.Transport.SSLCACertificates
+	t.ClientCertificate = api.Proxy.Transport.SSLClientCertificate
+	t.ServerName = api.Proxy.Transport.SSLServerName
 }
 
 // ExtractTo extracts *TLSTransport into *apidef.ServiceDiscoveryConfiguration.
@@ -372,6 +547,9 @@ func (t *TLSTransport) ExtractTo(api *apidef.APIDefinition) {
 	api.Proxy.Transport.SSLMaxVersion = t.tlsVersionFromString(t.MaxVersion)
 	api.Proxy.Transport.SSLMinVersion = t.tlsVersionFromString(t.MinVersion)
 	api.Proxy.Transport.SSLInsecureSkipVerify = t.InsecureSkipVerify
+	api.Proxy.Transport.SSLCACertificates = t.CACertificates
+	api.Proxy.Transport.SSLClientCertificate = t.ClientCertificate
+	api.Proxy.Transport.SSLServerName = t.ServerName
 }
 
 // tlsVersionFromString converts v in the form of 1.2/1.3 to the version int
@@ -529,6 +707,59 @@ type ServiceDiscovery struct {
 	//
 	// Tyk classic API definition: `service_discovery.endpoint_returns_list`
 	EndpointReturnsList bool `bson:"endpointReturnsList,omitempty" json:"endpointReturnsList,omitempty"`
+
+	// Kubernetes configures discovering the targets from the endpoint slices of a Kubernetes service
+	// instead of querying `queryEndpoint`.
+	//
+	// Tyk classic API definition: `service_discovery.kubernetes`
+	Kubernetes *KubernetesServiceDiscovery `bson:"kubernetes,omitempty" json:"kubernetes,omitempty"`
+}
+
+// KubernetesServiceDiscovery holds the configuration for discovering the ready addresses of a Kubernetes service.
+// Addresses of terminating or not ready endpoints are left out of the target list.
+type KubernetesServiceDiscovery struct {
+	// Enabled activates discovering the targets from Kubernetes.
+	//
+	// Tyk classic API definition: `service_discovery.kubernetes.enabled`
+	Enabled bool `bson:"enabled" json:"enabled"` // required
+
+	// Namespace is the namespace of the service, defaults to the namespace of the gateway pod.
+	//
+	// Tyk classic API definition: `service_discovery.kubernetes.namespace`
+	Namespace string `bson:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// Service is the name of the service.
+	//
+	// Tyk classic API definition: `service_discovery.kubernetes.service`
+	Service string `bson:"service" json:"service"`
+
+	// PortName is the name of the service port to target, the first port is used when it's empty.
+	//
+	// Tyk classic API definition: `service_discovery.kubernetes.port_name`
+	PortName string `bson:"portName,omitempty" json:"portName,omitempty"`
+
+	// KubeconfigPath is the path of the kubeconfig file to connect with, in-cluster credentials are used when it's empty.
+	//
+	// Tyk classic API definition: `service_discovery.kubernetes.kubeconfig_path`
+	KubeconfigPath string `bson:"kubeconfigPath,omitempty" json:"kubeconfigPath,omitempty"`
+}
+
+// Fill fills *KubernetesServiceDiscovery from apidef.KubernetesServiceDiscovery.
+func (k *KubernetesServiceDiscovery) Fill(kubernetes apidef.KubernetesServiceDiscovery) {
+	k.Enabled = kubernetes.Enabled
+	k.Namespace = kubernetes.Namespace
+	k.Service = kubernetes.Service
+	k.PortName = kubernetes.PortName
+	k.KubeconfigPath = kubernetes.KubeconfigPath
+}
+
+// ExtractTo extracts *KubernetesServiceDiscovery into *apidef.KubernetesServiceDiscovery.
+func (k *KubernetesServiceDiscovery) ExtractTo(kubernetes *apidef.KubernetesServiceDiscovery) {
+	kubernetes.Enabled = k.Enabled
+	kubernetes.Namespace = k.Namespace
+	kubernetes.Service = k.Service
+	kubernetes.PortName = k.PortName
+	kubernetes.KubeconfigPath = k.KubeconfigPath
 }
 
 // ServiceDiscoveryCache holds configuration for caching ServiceDiscovery data.
@@ -565,6 +796,15 @@ func (sd *ServiceDiscovery) Fill(serviceDiscovery apidef.ServiceDiscoveryConfigu
 	sd.DataPath = serviceDiscovery.DataPath
 	sd.PortDataPath = serviceDiscovery.PortDataPath
 
+	if sd.Kubernetes == nil {
+		sd.Kubernetes = &KubernetesServiceDiscovery{}
+	}
+
+	sd.Kubernetes.Fill(serviceDiscovery.Kubernetes)
+	if ShouldOmit(sd.Kubernetes) {
+		sd.Kubernetes = nil
+	}
+
 	timeout, enabled := serviceDiscovery.CacheOptions()
 	sd.Cache = &ServiceDiscoveryCache{
 		Enabled: enabled && sd.Enabled,
@@ -590,6 +830,14 @@ func (sd *ServiceDiscovery) ExtractTo(serviceDiscovery *apidef.ServiceDiscoveryC
 	serviceDiscovery.DataPath = sd.DataPath
 	serviceDiscovery.PortDataPath = sd.PortDataPath
 
+	if sd.Kubernetes == nil {
+		sd.Kubernetes = &KubernetesServiceDiscovery{}
+		defer func() {
+			sd.Kubernetes = nil
+		}()
+	}
+	sd.Kubernetes.ExtractTo(&serviceDiscovery.Kubernetes)
+
 	timeout, enabled := sd.CacheOptions()
 	serviceDiscovery.CacheDisabled = !enabled
 	serviceDiscovery.CacheTimeout = timeout
@@ -646,6 +894,18 @@ type UptimeTest struct {
 	// Body is the body of the test request.
 	Body string `bson:"body" json:"body,omitempty"`
 
+	// ExpectedStatusCodes lists the response status codes considered healthy.
+	// When empty, only `200` is considered healthy.
+	//
+	// Tyk classic API definition: `uptime_tests.check_list[].expected_status_codes`.
+	ExpectedStatusCodes []int `bson:"expectedStatusCodes" json:"expectedStatusCodes,omitempty"`
+
+	// ExpectedBodyRegex is a regular expression which the response body must match
+	// for the host to be considered healthy.
+	//
+	// Tyk classic API definition: `uptime_tests.check_list[].expected_body_regex`.
+	ExpectedBodyRegex string `bson:"expectedBodyRegex" json:"expectedBodyRegex,omitempty"`
+
 	// Commands are used for TCP checks.
 	Commands []UptimeTestCommand `bson:"commands" json:"commands,omitempty"`
 
@@ -697,6 +957,8 @@ func (u *UptimeTests) Fill(uptimeTests apidef.UptimeTests) {
 			Method:              v.Method,
 			Headers:             v.Headers,
 			Body:                v.Body,
+			ExpectedStatusCodes: v.ExpectedStatusCodes,
+			ExpectedBodyRegex:   v.ExpectedBodyRegex,
 			EnableProxyProtocol: v.EnableProxyProtocol,
 		}
 		for _, command := range v.Commands {
@@ -736,6 +998,8 @@ func (u *UptimeTests) ExtractTo(uptimeTests *apidef.UptimeTests) {
 			Method:              v.Method,
 			Headers:             v.Headers,
 			Body:                v.Body,
+			ExpectedStatusCodes: v.ExpectedStatusCodes,
+			ExpectedBodyRegex:   v.ExpectedBodyRegex,
 			EnableProxyProtocol: v.EnableProxyProtocol,
 		}
 		for _, command := range v.Commands {
@@ -1002,6 +1266,12 @@ type UpstreamAuth struct {
 	OAuth *UpstreamOAuth `bson:"oauth,omitempty" json:"oauth,omitempty"`
 	// RequestSigning holds the configuration for generating signed requests to an upstream API.
 	RequestSigning *UpstreamRequestSigning `bson:"requestSigning,omitempty" json:"requestSigning,omitempty"`
+	// AWSSigV4 holds the configuration for signing the upstream requests with AWS Signature Version 4.
+	// The signature covers the request as sent to the upstream, after all the transformations.
+	AWSSigV4 *UpstreamAWSSigV4 `bson:"awsSigV4,omitempty" json:"awsSigV4,omitempty"`
+	// HMACSigning holds the configuration for signing the upstream requests with an HMAC.
+	// The signature covers the request as sent to the upstream, after all the transformations.
+	HMACSigning *UpstreamHMACSigning `bson:"hmacSigning,omitempty" json:"hmacSigning,omitempty"`
 }
 
 // Fill fills *UpstreamAuth from apidef.APIDefinition.
@@ -1025,6 +1295,22 @@ func (u *UpstreamAuth) Fill(api apidef.APIDefinition) {
 	}
 
 	u.fillRequestSigning(api)
+
+	if u.AWSSigV4 == nil {
+		u.AWSSigV4 = &UpstreamAWSSigV4{}
+	}
+	u.AWSSigV4.Fill(api.UpstreamAuth.AWSSigV4)
+	if ShouldOmit(u.AWSSigV4) {
+		u.AWSSigV4 = nil
+	}
+
+	if u.HMACSigning == nil {
+		u.HMACSigning = &UpstreamHMACSigning{}
+	}
+	u.HMACSigning.Fill(api.UpstreamAuth.HMACSigning)
+	if ShouldOmit(u.HMACSigning) {
+		u.HMACSigning = nil
+	}
 }
 
 // ExtractTo extracts *UpstreamAuth into *apidef.APIDefinition.
@@ -1048,6 +1334,22 @@ func (u *UpstreamAuth) ExtractTo(api *apidef.APIDefinition) {
 	u.OAuth.ExtractTo(&api.UpstreamAuth.OAuth)
 
 	u.requestSigningExtractTo(api)
+
+	if u.AWSSigV4 == nil {
+		u.AWSSigV4 = &UpstreamAWSSigV4{}
+		defer func() {
+			u.AWSSigV4 = nil
+		}()
+	}
+	u.AWSSigV4.ExtractTo(&api.UpstreamAuth.AWSSigV4)
+
+	if u.HMACSigning == nil {
+		u.HMACSigning = &UpstreamHMACSigning{}
+		defer func() {
+			u.HMACSigning = nil
+		}()
+	}
+	u.HMACSigning.ExtractTo(&api.UpstreamAuth.HMACSigning)
 }
 
 func (u *UpstreamAuth) fillRequestSigning(api apidef.APIDefinition) {
@@ -1113,6 +1415,93 @@ func (u *UpstreamBasicAuth) ExtractTo(api *apidef.UpstreamBasicAuth) {
 	u.Header.ExtractTo(&api.Header.Enabled, &api.Header.Name)
 }
 
+// UpstreamAWSSigV4 holds the configuration for signing upstream requests with AWS Signature Version 4.
+type UpstreamAWSSigV4 struct {
+	// Enabled activates AWS Signature Version 4 signing of the upstream requests.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Region is the AWS region of the upstream, e.g. `eu-west-1`.
+	Region string `bson:"region" json:"region"`
+	// Service is the AWS service name of the upstream, e.g. `execute-api`.
+	Service string `bson:"service" json:"service"`
+	// AccessKeyID is the AWS access key ID, it can be a reference to the KV stores.
+	AccessKeyID string `bson:"accessKeyId" json:"accessKeyId"`
+	// SecretAccessKey is the AWS secret access key, it can be a reference to the KV stores.
+	SecretAccessKey string `bson:"secretAccessKey" json:"secretAccessKey"`
+	// SessionToken is the AWS session token of temporary credentials, it can be a reference to the KV stores.
+	SessionToken string `bson:"sessionToken,omitempty" json:"sessionToken,omitempty"`
+}
+
+// Fill fills *UpstreamAWSSigV4 from apidef.UpstreamAWSSigV4.
+func (u *UpstreamAWSSigV4) Fill(api apidef.UpstreamAWSSigV4) {
+	u.Enabled = api.Enabled
+	u.Region = api.Region
+	u.Service = api.Service
+	u.AccessKeyID = api.AccessKeyID
+	u.SecretAccessKey = api.SecretAccessKey
+	u.SessionToken = api.SessionToken
+}
+
+// ExtractTo extracts *UpstreamAWSSigV4 into *apidef.UpstreamAWSSigV4.
+func (u *UpstreamAWSSigV4) ExtractTo(api *apidef.UpstreamAWSSigV4) {
+	api.Enabled = u.Enabled
+	api.Region = u.Region
+	api.Service = u.Service
+	api.AccessKeyID = u.AccessKeyID
+	api.SecretAccessKey = u.SecretAccessKey
+	api.SessionToken = u.SessionToken
+}
+
+// UpstreamHMACSigning holds the configuration for signing upstream requests with an HMAC.
+type UpstreamHMACSigning struct {
+	// Enabled activates HMAC signing of the upstream requests.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Header contains configurations for the header carrying the signature, defaults to `Authorization`.
+	Header *AuthSource `bson:"header,omitempty" json:"header,omitempty"`
+	// KeyID is the key identifier sent along with the signature.
+	KeyID string `bson:"keyId" json:"keyId"`
+	// Secret is the HMAC key, it can be a reference to the KV stores.
+	Secret string `bson:"secret" json:"secret"`
+	// Algorithm is one of `hmac-sha1`, `hmac-sha256`, `hmac-sha384` and `hmac-sha512`, defaults to `hmac-sha256`.
+	Algorithm string `bson:"algorithm,omitempty" json:"algorithm,omitempty"`
+	// Headers is the list of signed headers, `(request-target)` stands for the method and path.
+	// Defaults to `(request-target)`, `host`, `date` and `digest`, the latter covering the request body.
+	Headers []string `bson:"headers,omitempty" json:"headers,omitempty"`
+}
+
+// Fill fills *UpstreamHMACSigning from apidef.UpstreamHMACSigning.
+func (u *UpstreamHMACSigning) Fill(api apidef.UpstreamHMACSigning) {
+	u.Enabled = api.Enabled
+	u.KeyID = api.KeyID
+	u.Secret = api.Secret
+	u.Algorithm = api.Algorithm
+	u.Headers = api.Headers
+
+	if u.Header == nil {
+		u.Header = &AuthSource{}
+	}
+	u.Header.Fill(api.Header.Enabled, api.Header.Name)
+	if ShouldOmit(u.Header) {
+		u.Header = nil
+	}
+}
+
+// ExtractTo extracts *UpstreamHMACSigning into *apidef.UpstreamHMACSigning.
+func (u *UpstreamHMACSigning) ExtractTo(api *apidef.UpstreamHMACSigning) {
+	api.Enabled = u.Enabled
+	api.KeyID = u.KeyID
+	api.Secret = u.Secret
+	api.Algorithm = u.Algorithm
+	api.Headers = u.Headers
+
+	if u.Header == nil {
+		u.Header = &AuthSource{}
+		defer func() {
+			u.Header = nil
+		}()
+	}
+	u.Header.ExtractTo(&api.Header.Enabled, &api.Header.Name)
+}
+
 // UpstreamOAuth holds the configuration for OAuth2 Client Credentials flow.
 type UpstreamOAuth struct {
 	// Enabled activates upstream OAuth2 authentication.
@@ -1423,6 +1812,51 @@ func (p *PreserveHostHeader) ExtractTo(api *apidef.APIDefinition) {
 	api.Proxy.PreserveHostHeader = p.Enabled
 }
 
+// UpstreamHostHeader holds the configuration for the Host header sent to the upstream.
+//
+// By default Tyk sends the host of the upstream target, or the host of the inbound
+// request when preserveHostHeader is enabled. A configured Host header takes precedence
+// over both, and over the host of a URL rewrite, so a virtual-hosted upstream can be
+// reached through a target like an IP address.
+type UpstreamHostHeader struct {
+	// Enabled activates sending the configured Host header to the upstream.
+	Enabled bool `json:"enabled" bson:"enabled"` // required
+
+	// Value is the Host header sent to the upstream. It supports context variables.
+	//
+	// Tyk classic API definition: `proxy.upstream_host_header`.
+	Value string `json:"value" bson:"value"`
+}
+
+// Fill fills *UpstreamHostHeader from apidef.APIDefinition.
+func (h *UpstreamHostHeader) Fill(api apidef.APIDefinition) {
+	h.Enabled = api.Proxy.UpstreamHostHeader != ""
+	h.Value = api.Proxy.UpstreamHostHeader
+}
+
+// ExtractTo extracts *UpstreamHostHeader into *apidef.APIDefinition.
+func (h *UpstreamHostHeader) ExtractTo(api *apidef.APIDefinition) {
+	api.Proxy.UpstreamHostHeader = ""
+	if h.Enabled {
+		api.Proxy.UpstreamHostHeader = h.Value
+	}
+}
+
+// UpstreamHostHeaderEndpoint carries same settings as UpstreamHostHeader but for endpoints.
+type UpstreamHostHeaderEndpoint UpstreamHostHeader
+
+// Fill fills *UpstreamHostHeaderEndpoint from apidef.UpstreamHostMeta.
+func (h *UpstreamHostHeaderEndpoint) Fill(meta apidef.UpstreamHostMeta) {
+	h.Enabled = !meta.Disabled
+	h.Value = meta.Host
+}
+
+// ExtractTo extracts *UpstreamHostHeaderEndpoint into *apidef.UpstreamHostMeta.
+func (h *UpstreamHostHeaderEndpoint) ExtractTo(meta *apidef.UpstreamHostMeta) {
+	meta.Disabled = !h.Enabled
+	meta.Host = h.Value
+}
+
 // PreserveTrailingSlash holds the configuration for preserving the
 // trailing slash when routed to upstream services.
 //
@@ -1477,3 +1911,255 @@ func (g *GlobalEnforceTimeout) ExtractTo(api *apidef.APIDefinition) {
 	mainVersion.GlobalEnforceTimeout = g.Duration
 	api.VersionData.Versions[Main] = mainVersion
 }
+
+// Mirror holds the configuration for shadowing traffic to a secondary upstream.
+// A sample of the requests is cloned and sent to the mirror asynchronously,
+// the mirror responses are discarded and never affect the primary request.
+type Mirror struct {
+	// Enabled activates traffic mirroring.
+	//
+	// Tyk classic API definition: `proxy.mirror.enabled`.
+	Enabled bool `json:"enabled" bson:"enabled"` // required
+
+	// URL is the address of the upstream receiving the mirrored requests.
+	//
+	// Tyk classic API definition: `proxy.mirror.target_url`.
+	URL string `json:"url" bson:"url"` // required
+
+	// Percentage is the share of requests, between 0 and 100, that are mirrored.
+	//
+	// Tyk classic API definition: `proxy.mirror.percentage`.
+	Percentage float64 `json:"percentage,omitempty" bson:"percentage,omitempty"`
+}
+
+// Fill fills *Mirror from apidef.APIDefinition.
+func (m *Mirror) Fill(api apidef.APIDefinition) {
+	m.Enabled = api.Proxy.Mirror.Enabled
+	m.URL = api.Proxy.Mirror.TargetURL
+	m.Percentage = api.Proxy.Mirror.Percentage
+}
+
+// ExtractTo extracts *Mirror into *apidef.APIDefinition.
+func (m *Mirror) ExtractTo(api *apidef.APIDefinition) {
+	api.Proxy.Mirror.Enabled = m.Enabled
+	api.Proxy.Mirror.TargetURL = m.URL
+	api.Proxy.Mirror.Percentage = m.Percentage
+}
+
+// Canary holds the configuration for routing requests to a canary upstream, instead of the upstream URL or load balanced targets.
+// A request is routed to the canary when any of the rules matches it, or else for a percentage of the keys.
+type Canary struct {
+	// Enabled activates canary routing.
+	//
+	// Tyk classic API definition: `proxy.canary.enabled`.
+	Enabled bool `json:"enabled" bson:"enabled"` // required
+
+	// URL is the address of the canary upstream.
+	//
+	// Tyk classic API definition: `proxy.canary.target_url`.
+	URL string `json:"url" bson:"url"` // required
+
+	// Rules route a request to the canary when any of them matches it.
+	//
+	// Tyk classic API definition: `proxy.canary.rules`.
+	Rules []CanaryRule `json:"rules,omitempty" bson:"rules,omitempty"`
+
+	// Percentage is the share, between 0 and 100, of the requests no rule matched routed to the canary.
+	// The choice is consistent per key, or per client IP for keyless requests.
+	//
+	// Tyk classic API definition: `proxy.canary.percentage`.
+	Percentage float64 `json:"percentage,omitempty" bson:"percentage,omitempty"`
+}
+
+// CanaryRule matches a request header, query parameter or session metadata entry.
+type CanaryRule struct {
+	// Source is where the value is read from, one of `header`, `query` or `session_metadata`.
+	Source string `json:"source" bson:"source"`
+
+	// Name is the name of the header, query parameter or session metadata entry.
+	Name string `json:"name" bson:"name"`
+
+	// Value is the value matched, case insensitively. Any non-empty value matches when it's empty.
+	Value string `json:"value,omitempty" bson:"value,omitempty"`
+}
+
+// Fill fills *Canary from apidef.APIDefinition.
+func (c *Canary) Fill(api apidef.APIDefinition) {
+	c.Enabled = api.Proxy.Canary.Enabled
+	c.URL = api.Proxy.Canary.TargetURL
+	c.Percentage = api.Proxy.Canary.Percentage
+
+	c.Rules = nil
+	for _, rule := range api.Proxy.Canary.Rules {
+		c.Rules = append(c.Rules, CanaryRule{Source: rule.Source, Name: rule.Name, Value: rule.Value})
+	}
+}
+
+// ExtractTo extracts *Canary into *apidef.APIDefinition.
+func (c *Canary) ExtractTo(api *apidef.APIDefinition) {
+	api.Proxy.Canary.Enabled = c.Enabled
+	api.Proxy.Canary.TargetURL = c.URL
+	api.Proxy.Canary.Percentage = c.Percentage
+
+	api.Proxy.Canary.Rules = nil
+	for _, rule := range c.Rules {
+		api.Proxy.Canary.Rules = append(api.Proxy.Canary.Rules, apidef.CanaryRule{Source: rule.Source, Name: rule.Name, Value: rule.Value})
+	}
+}
+
+// GRPCWeb holds the configuration for serving gRPC-Web clients from a native gRPC upstream.
+// Unary and server streaming calls using the binary gRPC-Web format are translated,
+// the upstream must be reachable over HTTP/2, e.g. with an `h2c://` URL.
+type GRPCWeb struct {
+	// Enabled activates the gRPC-Web translation.
+	//
+	// Tyk classic API definition: `proxy.grpc_web.enabled`.
+	Enabled bool `json:"enabled" bson:"enabled"` // required
+}
+
+// Fill fills *GRPCWeb from apidef.APIDefinition.
+func (g *GRPCWeb) Fill(api apidef.APIDefinition) {
+	g.Enabled = api.Proxy.GRPCWeb.Enabled
+}
+
+// ExtractTo extracts *GRPCWeb into *apidef.APIDefinition.
+func (g *GRPCWeb) ExtractTo(api *apidef.APIDefinition) {
+	api.Proxy.GRPCWeb.Enabled = g.Enabled
+}
+
+// WebSocket holds the limits and the subprotocol restrictions enforced on websocket connections proxied to the upstream.
+// Connections going over a limit are closed with a close frame sent to both the client and the upstream.
+type WebSocket struct {
+	// Enabled activates the websocket limits and the subprotocol restrictions.
+	//
+	// Tyk classic API definition: `proxy.websocket.enabled`.
+	Enabled bool `json:"enabled" bson:"enabled"` // required
+	// MaxMessageSize is the maximum size of a message in bytes, in either direction.
+	// Connections sending bigger messages are closed with the policy violation (1008) close code.
+	//
+	// Tyk classic API definition: `proxy.websocket.max_message_size`.
+	MaxMessageSize int64 `json:"maxMessageSize,omitempty" bson:"maxMessageSize,omitempty"`
+	// IdleTimeout is the time after which a connection without any frame in either direction is closed.
+	//
+	// Tyk classic API definition: `proxy.websocket.idle_timeout`.
+	IdleTimeout ReadableDuration `json:"idleTimeout,omitempty" bson:"idleTimeout,omitempty"`
+	// MaxConnectionDuration is the time after which a connection is closed, whether it is idle or not.
+	//
+	// Tyk classic API definition: `proxy.websocket.max_connection_duration`.
+	MaxConnectionDuration ReadableDuration `json:"maxConnectionDuration,omitempty" bson:"maxConnectionDuration,omitempty"`
+	// AllowedSubprotocols restricts the subprotocols clients may request in the Sec-WebSocket-Protocol header.
+	// The others are removed from the handshake, which is rejected with 400 when none of the requested subprotocols is allowed.
+	//
+	// Tyk classic API definition: `proxy.websocket.allowed_subprotocols`.
+	AllowedSubprotocols []string `json:"allowedSubprotocols,omitempty" bson:"allowedSubprotocols,omitempty"`
+	// StripSubprotocols removes the Sec-WebSocket-Protocol header from the handshake, in both directions.
+	//
+	// Tyk classic API definition: `proxy.websocket.strip_subprotocols`.
+	StripSubprotocols bool `json:"stripSubprotocols,omitempty" bson:"stripSubprotocols,omitempty"`
+}
+
+// Fill fills *WebSocket from apidef.APIDefinition.
+func (w *WebSocket) Fill(api apidef.APIDefinition) {
+	w.Enabled = api.Proxy.WebSocket.Enabled
+	w.MaxMessageSize = api.Proxy.WebSocket.MaxMessageSize
+	w.IdleTimeout = ReadableDuration(time.Duration(api.Proxy.WebSocket.IdleTimeout) * time.Second)
+	w.MaxConnectionDuration = ReadableDuration(time.Duration(api.Proxy.WebSocket.MaxConnectionDuration) * time.Second)
+	w.AllowedSubprotocols = api.Proxy.WebSocket.AllowedSubprotocols
+	w.StripSubprotocols = api.Proxy.WebSocket.StripSubprotocols
+}
+
+// ExtractTo extracts *WebSocket into *apidef.APIDefinition.
+func (w *WebSocket) ExtractTo(api *apidef.APIDefinition) {
+	api.Proxy.WebSocket.Enabled = w.Enabled
+	api.Proxy.WebSocket.MaxMessageSize = w.MaxMessageSize
+	api.Proxy.WebSocket.IdleTimeout = int64(w.IdleTimeout.Seconds())
+	api.Proxy.WebSocket.MaxConnectionDuration = int64(w.MaxConnectionDuration.Seconds())
+	api.Proxy.WebSocket.AllowedSubprotocols = w.AllowedSubprotocols
+	api.Proxy.WebSocket.StripSubprotocols = w.StripSubprotocols
+}
+
+// OutlierDetection holds the configuration for ejecting load balanced targets from rotation after
+// consecutive 5xx responses or connection errors, with load balancing or service discovery.
+// An ejected target is re-admitted once its ejection time elapsed or an uptime test against it succeeded.
+type OutlierDetection struct {
+	// Enabled activates outlier detection.
+	//
+	// Tyk classic API definition: `proxy.outlier_detection.enabled`.
+	Enabled bool `json:"enabled" bson:"enabled"` // required
+	// ConsecutiveFailures is the number of consecutive failures ejecting a target, defaults to 5.
+	//
+	// Tyk classic API definition: `proxy.outlier_detection.consecutive_failures`.
+	ConsecutiveFailures int `json:"consecutiveFailures,omitempty" bson:"consecutiveFailures,omitempty"`
+	// EjectionTime is the time a target is ejected for, defaults to 30s. It doubles each time
+	// a target is ejected again without a successful request in between.
+	//
+	// Tyk classic API definition: `proxy.outlier_detection.ejection_time`.
+	EjectionTime ReadableDuration `json:"ejectionTime,omitempty" bson:"ejectionTime,omitempty"`
+	// MaxEjectionTime is the maximum time a target is ejected for, defaults to 5m.
+	//
+	// Tyk classic API definition: `proxy.outlier_detection.max_ejection_time`.
+	MaxEjectionTime ReadableDuration `json:"maxEjectionTime,omitempty" bson:"maxEjectionTime,omitempty"`
+}
+
+// Fill fills *OutlierDetection from apidef.APIDefinition.
+func (o *OutlierDetection) Fill(api apidef.APIDefinition) {
+	o.Enabled = api.Proxy.OutlierDetection.Enabled
+	o.ConsecutiveFailures = api.Proxy.OutlierDetection.ConsecutiveFailures
+	o.EjectionTime = ReadableDuration(time.Duration(api.Proxy.OutlierDetection.EjectionTime) * time.Second)
+	o.MaxEjectionTime = ReadableDuration(time.Duration(api.Proxy.OutlierDetection.MaxEjectionTime) * time.Second)
+}
+
+// ExtractTo extracts *OutlierDetection into *apidef.APIDefinition.
+func (o *OutlierDetection) ExtractTo(api *apidef.APIDefinition) {
+	api.Proxy.OutlierDetection.Enabled = o.Enabled
+	api.Proxy.OutlierDetection.ConsecutiveFailures = o.ConsecutiveFailures
+	api.Proxy.OutlierDetection.EjectionTime = int64(o.EjectionTime.Seconds())
+	api.Proxy.OutlierDetection.MaxEjectionTime = int64(o.MaxEjectionTime.Seconds())
+}
+
+// ConcurrencyLimit holds the configuration for limiting the number of requests in flight, for the API
+// and for each key or policy with a `max_concurrent_requests` limit. Requests over the limit are
+// rejected straight away, instead of queueing for a slow upstream.
+type ConcurrencyLimit struct {
+	// Enabled activates concurrency limiting, including the limits set on keys and policies.
+	//
+	// Tyk classic API definition: `concurrency_limit.enabled`.
+	Enabled bool `json:"enabled" bson:"enabled"` // required
+	// MaxConcurrentRequests is the maximum number of requests to the API in flight at once.
+	// When it isn't set, only the limits of keys and policies are applied.
+	//
+	// Tyk classic API definition: `concurrency_limit.max_concurrent_requests`.
+	MaxConcurrentRequests int `json:"maxConcurrentRequests,omitempty" bson:"maxConcurrentRequests,omitempty"`
+	// Distributed counts the requests in flight across all gateways in redis. When it isn't set,
+	// each gateway enforces the limits on its own requests.
+	//
+	// Tyk classic API definition: `concurrency_limit.distributed`.
+	Distributed bool `json:"distributed,omitempty" bson:"distributed,omitempty"`
+	// StatusCode is the response status code when the limit is reached, `429` or `503`, defaults to `429`.
+	//
+	// Tyk classic API definition: `concurrency_limit.status_code`.
+	StatusCode int `json:"statusCode,omitempty" bson:"statusCode,omitempty"`
+	// RetryAfter is sent to clients in the `Retry-After` header when the limit is reached,
+	// rounded down to seconds. Defaults to `1s`.
+	//
+	// Tyk classic API definition: `concurrency_limit.retry_after`.
+	RetryAfter ReadableDuration `json:"retryAfter,omitempty" bson:"retryAfter,omitempty"`
+}
+
+// Fill fills *ConcurrencyLimit from apidef.APIDefinition.
+func (c *ConcurrencyLimit) Fill(api apidef.APIDefinition) {
+	c.Enabled = api.ConcurrencyLimit.Enabled
+	c.MaxConcurrentRequests = api.ConcurrencyLimit.MaxConcurrentRequests
+	c.Distributed = api.ConcurrencyLimit.Distributed
+	c.StatusCode = api.ConcurrencyLimit.StatusCode
+	c.RetryAfter = ReadableDuration(time.Duration(api.ConcurrencyLimit.RetryAfter) * time.Second)
+}
+
+// ExtractTo extracts *ConcurrencyLimit into *apidef.APIDefinition.
+func (c *ConcurrencyLimit) ExtractTo(api *apidef.APIDefinition) {
+	api.ConcurrencyLimit.Enabled = c.Enabled
+	api.ConcurrencyLimit.MaxConcurrentRequests = c.MaxConcurrentRequests
+	api.ConcurrencyLimit.Distributed = c.Distributed
+	api.ConcurrencyLimit.StatusCode = c.StatusCode
+	api.ConcurrencyLimit.RetryAfter = int(c.RetryAfter.Seconds())
+}
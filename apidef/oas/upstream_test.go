@@ -240,6 +240,30 @@ func TestServiceDiscovery(t *testing.T) {
 	resultServiceDiscovery.Fill(convertedServiceDiscovery)
 
 	assert.Equal(t, emptyServiceDiscovery, resultServiceDiscovery)
+
+	t.Run("kubernetes", func(t *testing.T) {
+		kubernetes := apidef.KubernetesServiceDiscovery{
+			Enabled:        true,
+			Namespace:      "payments",
+			Service:        "ledger",
+			PortName:       "http",
+			KubeconfigPath: "/etc/tyk/kubeconfig",
+		}
+
+		var serviceDiscovery ServiceDiscovery
+		serviceDiscovery.Fill(apidef.ServiceDiscoveryConfiguration{UseDiscoveryService: true, Kubernetes: kubernetes})
+		assert.Equal(t, &KubernetesServiceDiscovery{
+			Enabled:        true,
+			Namespace:      "payments",
+			Service:        "ledger",
+			PortName:       "http",
+			KubeconfigPath: "/etc/tyk/kubeconfig",
+		}, serviceDiscovery.Kubernetes)
+
+		var converted apidef.ServiceDiscoveryConfiguration
+		serviceDiscovery.ExtractTo(&converted)
+		assert.Equal(t, kubernetes, converted.Kubernetes)
+	})
 }
 
 func TestUptimeTests(t *testing.T) {
@@ -828,6 +852,9 @@ func TestTLSTransportProxy(t *testing.T) {
 			MinVersion:           "1.2",
 			MaxVersion:           "1.3",
 			ForceCommonNameCheck: true,
+			CACertificates:       []string{"ca-cert-id"},
+			ClientCertificate:    "client-cert-id",
+			ServerName:           "upstream.internal",
 		}
 
 		var convertedAPI apidef.APIDefinition
@@ -840,6 +867,9 @@ func TestTLSTransportProxy(t *testing.T) {
 		assert.Equal(t, uint16(tls.VersionTLS12), convertedAPI.Proxy.Transport.SSLMinVersion)
 		assert.Equal(t, uint16(tls.VersionTLS13), convertedAPI.Proxy.Transport.SSLMaxVersion)
 		assert.Equal(t, transport.ForceCommonNameCheck, convertedAPI.Proxy.Transport.SSLForceCommonNameCheck)
+		assert.Equal(t, []string{"ca-cert-id"}, convertedAPI.Proxy.Transport.SSLCACertificates)
+		assert.Equal(t, "client-cert-id", convertedAPI.Proxy.Transport.SSLClientCertificate)
+		assert.Equal(t, "upstream.internal", convertedAPI.Proxy.Transport.SSLServerName)
 
 		resultTransport.Fill(convertedAPI)
 
@@ -1485,3 +1515,293 @@ func TestPreserveHostHeader(t *testing.T) {
 		}
 	})
 }
+
+func TestUpstreamHostHeader(t *testing.T) {
+	t.Run("fill", func(t *testing.T) {
+		type testCase struct {
+			title    string
+			input    apidef.APIDefinition
+			expected *UpstreamHostHeader
+		}
+		testCases := []testCase{
+			{
+				title:    "upstream host header not set",
+				input:    apidef.APIDefinition{},
+				expected: nil,
+			},
+			{
+				title: "upstream host header set",
+				input: apidef.APIDefinition{
+					Proxy: apidef.ProxyConfig{
+						UpstreamHostHeader: "$tyk_context.headers_X_Upstream_Host",
+					},
+				},
+				expected: &UpstreamHostHeader{
+					Enabled: true,
+					Value:   "$tyk_context.headers_X_Upstream_Host",
+				},
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.title, func(t *testing.T) {
+				t.Parallel()
+
+				g := new(Upstream)
+				g.Fill(tc.input)
+
+				assert.Equal(t, tc.expected, g.HostHeader)
+			})
+		}
+	})
+
+	t.Run("extractTo", func(t *testing.T) {
+		type testCase struct {
+			title    string
+			input    *UpstreamHostHeader
+			expected string
+		}
+		testcases := []testCase{
+			{
+				title:    "upstream host header not configured",
+				input:    nil,
+				expected: "",
+			},
+			{
+				title: "upstream host header disabled",
+				input: &UpstreamHostHeader{
+					Enabled: false,
+					Value:   "internal.example.com",
+				},
+				expected: "",
+			},
+			{
+				title: "upstream host header enabled",
+				input: &UpstreamHostHeader{
+					Enabled: true,
+					Value:   "internal.example.com",
+				},
+				expected: "internal.example.com",
+			},
+		}
+
+		for _, tc := range testcases {
+			tc := tc
+			t.Run(tc.title, func(t *testing.T) {
+				g := new(Upstream)
+				g.HostHeader = tc.input
+
+				var apiDef apidef.APIDefinition
+				apiDef.Proxy.UpstreamHostHeader = "previous.example.com"
+				g.ExtractTo(&apiDef)
+
+				assert.Equal(t, tc.expected, apiDef.Proxy.UpstreamHostHeader)
+			})
+		}
+	})
+}
+
+func TestWebSocket(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		var u Upstream
+		u.Fill(apidef.APIDefinition{})
+		assert.Nil(t, u.WebSocket)
+
+		var api apidef.APIDefinition
+		u.ExtractTo(&api)
+		assert.Equal(t, apidef.ProxyWebSocket{}, api.Proxy.WebSocket)
+	})
+
+	t.Run("fill and extract", func(t *testing.T) {
+		webSocket := apidef.ProxyWebSocket{
+			Enabled:               true,
+			MaxMessageSize:        1024,
+			IdleTimeout:           30,
+			MaxConnectionDuration: 3600,
+			AllowedSubprotocols:   []string{"chat.v1", "chat.v2"},
+			StripSubprotocols:     true,
+		}
+
+		var u Upstream
+		u.Fill(apidef.APIDefinition{Proxy: apidef.ProxyConfig{WebSocket: webSocket}})
+		assert.Equal(t, &WebSocket{
+			Enabled:               true,
+			MaxMessageSize:        1024,
+			IdleTimeout:           ReadableDuration(30 * time.Second),
+			MaxConnectionDuration: ReadableDuration(time.Hour),
+			AllowedSubprotocols:   []string{"chat.v1", "chat.v2"},
+			StripSubprotocols:     true,
+		}, u.WebSocket)
+
+		var api apidef.APIDefinition
+		u.ExtractTo(&api)
+		assert.Equal(t, webSocket, api.Proxy.WebSocket)
+	})
+}
+
+func TestOutlierDetection(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		var u Upstream
+		u.Fill(apidef.APIDefinition{})
+		assert.Nil(t, u.OutlierDetection)
+
+		var api apidef.APIDefinition
+		u.ExtractTo(&api)
+		assert.Equal(t, apidef.ProxyOutlierDetection{}, api.Proxy.OutlierDetection)
+	})
+
+	t.Run("fill and extract", func(t *testing.T) {
+		outlierDetection := apidef.ProxyOutlierDetection{
+			Enabled:             true,
+			ConsecutiveFailures: 3,
+			EjectionTime:        30,
+			MaxEjectionTime:     300,
+		}
+
+		var u Upstream
+		u.Fill(apidef.APIDefinition{Proxy: apidef.ProxyConfig{OutlierDetection: outlierDetection}})
+		assert.Equal(t, &OutlierDetection{
+			Enabled:             true,
+			ConsecutiveFailures: 3,
+			EjectionTime:        ReadableDuration(30 * time.Second),
+			MaxEjectionTime:     ReadableDuration(5 * time.Minute),
+		}, u.OutlierDetection)
+
+		var api apidef.APIDefinition
+		u.ExtractTo(&api)
+		assert.Equal(t, outlierDetection, api.Proxy.OutlierDetection)
+	})
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		var u Upstream
+		u.Fill(apidef.APIDefinition{})
+		assert.Nil(t, u.ConcurrencyLimit)
+
+		var api apidef.APIDefinition
+		u.ExtractTo(&api)
+		assert.Equal(t, apidef.ConcurrencyLimit{}, api.ConcurrencyLimit)
+	})
+
+	t.Run("fill and extract", func(t *testing.T) {
+		concurrencyLimit := apidef.ConcurrencyLimit{
+			Enabled:               true,
+			MaxConcurrentRequests: 100,
+			Distributed:           true,
+			StatusCode:            http.StatusServiceUnavailable,
+			RetryAfter:            5,
+		}
+
+		var u Upstream
+		u.Fill(apidef.APIDefinition{ConcurrencyLimit: concurrencyLimit})
+		assert.Equal(t, &ConcurrencyLimit{
+			Enabled:               true,
+			MaxConcurrentRequests: 100,
+			Distributed:           true,
+			StatusCode:            http.StatusServiceUnavailable,
+			RetryAfter:            ReadableDuration(5 * time.Second),
+		}, u.ConcurrencyLimit)
+
+		var api apidef.APIDefinition
+		u.ExtractTo(&api)
+		assert.Equal(t, concurrencyLimit, api.ConcurrencyLimit)
+	})
+}
+
+func TestCanary(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		var u Upstream
+		u.Fill(apidef.APIDefinition{})
+		assert.Nil(t, u.Canary)
+
+		var api apidef.APIDefinition
+		u.ExtractTo(&api)
+		assert.Equal(t, apidef.ProxyCanary{}, api.Proxy.Canary)
+	})
+
+	t.Run("fill and extract", func(t *testing.T) {
+		canary := apidef.ProxyCanary{
+			Enabled:   true,
+			TargetURL: "http://canary.example.com",
+			Rules: []apidef.CanaryRule{
+				{Source: apidef.CanaryRuleHeader, Name: "X-Canary", Value: "true"},
+				{Source: apidef.CanaryRuleSessionMetadata, Name: "beta"},
+			},
+			Percentage: 5,
+		}
+
+		var u Upstream
+		u.Fill(apidef.APIDefinition{Proxy: apidef.ProxyConfig{Canary: canary}})
+		assert.Equal(t, &Canary{
+			Enabled: true,
+			URL:     "http://canary.example.com",
+			Rules: []CanaryRule{
+				{Source: "header", Name: "X-Canary", Value: "true"},
+				{Source: "session_metadata", Name: "beta"},
+			},
+			Percentage: 5,
+		}, u.Canary)
+
+		var api apidef.APIDefinition
+		u.ExtractTo(&api)
+		assert.Equal(t, canary, api.Proxy.Canary)
+	})
+}
+
+func TestUpstreamAuthSigning(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		var u UpstreamAuth
+		u.Fill(apidef.APIDefinition{})
+		assert.Nil(t, u.AWSSigV4)
+		assert.Nil(t, u.HMACSigning)
+
+		var api apidef.APIDefinition
+		u.ExtractTo(&api)
+		assert.Equal(t, apidef.UpstreamAWSSigV4{}, api.UpstreamAuth.AWSSigV4)
+		assert.Equal(t, apidef.UpstreamHMACSigning{}, api.UpstreamAuth.HMACSigning)
+	})
+
+	t.Run("fill and extract", func(t *testing.T) {
+		upstreamAuth := apidef.UpstreamAuth{
+			Enabled: true,
+			AWSSigV4: apidef.UpstreamAWSSigV4{
+				Enabled:         true,
+				Region:          "eu-west-1",
+				Service:         "execute-api",
+				AccessKeyID:     "AKID",
+				SecretAccessKey: "secrets://aws-secret",
+			},
+			HMACSigning: apidef.UpstreamHMACSigning{
+				Enabled:   true,
+				KeyID:     "key",
+				Secret:    "env://hmac",
+				Algorithm: "hmac-sha512",
+				Headers:   []string{"(request-target)", "digest"},
+				Header:    apidef.AuthSource{Enabled: true, Name: "Signature"},
+			},
+		}
+
+		var u UpstreamAuth
+		u.Fill(apidef.APIDefinition{UpstreamAuth: upstreamAuth})
+		assert.Equal(t, &UpstreamAWSSigV4{
+			Enabled:         true,
+			Region:          "eu-west-1",
+			Service:         "execute-api",
+			AccessKeyID:     "AKID",
+			SecretAccessKey: "secrets://aws-secret",
+		}, u.AWSSigV4)
+		assert.Equal(t, &UpstreamHMACSigning{
+			Enabled:   true,
+			Header:    &AuthSource{Enabled: true, Name: "Signature"},
+			KeyID:     "key",
+			Secret:    "env://hmac",
+			Algorithm: "hmac-sha512",
+			Headers:   []string{"(request-target)", "digest"},
+		}, u.HMACSigning)
+
+		var api apidef.APIDefinition
+		u.ExtractTo(&api)
+		assert.Equal(t, upstreamAuth, api.UpstreamAuth)
+	})
+}
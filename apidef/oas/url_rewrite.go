@@ -37,6 +37,7 @@ type URLRewrite struct {
 // - `sessionMetadata`, match pattern against session metadata
 // - `requestBody`, match pattern against request body
 // - `requestContext`, match pattern against request context
+// - `requestBodyJSON`, match pattern against the value at the named JSON path of a JSON request body
 //
 // The default `url` is used as the input source.
 type URLRewriteInput string
@@ -56,6 +57,7 @@ const (
 	InputSessionMetadata URLRewriteInput = "sessionMetadata"
 	InputRequestBody     URLRewriteInput = "requestBody"
 	InputRequestContext  URLRewriteInput = "requestContext"
+	InputRequestBodyJSON URLRewriteInput = "requestBodyJSON"
 
 	ConditionAll URLRewriteCondition = "all"
 	ConditionAny URLRewriteCondition = "any"
@@ -76,6 +78,7 @@ var (
 		InputSessionMetadata,
 		InputRequestBody,
 		InputRequestContext,
+		InputRequestBodyJSON,
 	}
 )
 
@@ -93,6 +96,12 @@ type URLRewriteTrigger struct {
 	// RewriteTo specifies the URL to which the request shall be rewritten
 	// if indicated by the combination of `condition` and `rules`.
 	RewriteTo string `bson:"rewriteTo" json:"rewriteTo"`
+
+	// MaxBodySize is the size in bytes of the largest request body parsed for the `requestBodyJSON` rules,
+	// larger bodies don't match. Defaults to 1MiB.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.url_rewrite[].triggers[].options.payload_max_size`.
+	MaxBodySize int64 `bson:"maxBodySize,omitempty" json:"maxBodySize,omitempty"`
 }
 
 // URLRewriteRule represents a rewrite matching rules.
@@ -108,6 +117,8 @@ type URLRewriteRule struct {
 	//
 	// The value of name is unused when `in` is set to `requestBody`,
 	// as the request body is a single value and not a set of values.
+	// For `in=requestBodyJSON`, name is the JSON path of the value in the
+	// request body, e.g. `refund.type`.
 	Name string `bson:"name,omitempty" json:"name,omitempty"`
 
 	// Pattern is the regular expression against which the `in` values are compared for this rule check.
@@ -143,9 +154,10 @@ func (v *URLRewrite) fillTriggers(from []apidef.RoutingTrigger) []*URLRewriteTri
 		}
 
 		trigger := &URLRewriteTrigger{
-			Condition: URLRewriteCondition(t.On),
-			Rules:     rules,
-			RewriteTo: t.RewriteTo,
+			Condition:   URLRewriteCondition(t.On),
+			Rules:       rules,
+			RewriteTo:   t.RewriteTo,
+			MaxBodySize: t.Options.PayloadMaxSize,
 		}
 		result = append(result, trigger)
 	}
@@ -177,6 +189,7 @@ func (v *URLRewrite) fillRules(from apidef.RoutingTriggerOptions) []*URLRewriteR
 	v.appendRules(&result, from.PathPartMatches, InputPath)
 	v.appendRules(&result, from.SessionMetaMatches, InputSessionMetadata)
 	v.appendRules(&result, from.RequestContextMatches, InputRequestContext)
+	v.appendRules(&result, from.PayloadJSONMatches, InputRequestBodyJSON)
 
 	v.appendRules(&result, map[string]apidef.StringRegexMap{
 		"": from.PayloadMatches,
@@ -220,6 +233,7 @@ func (v *URLRewrite) extractTriggers() []apidef.RoutingTrigger {
 			RewriteTo: trigger.RewriteTo,
 			Options:   v.extractTriggerOptions(trigger.Rules),
 		}
+		routingTrigger.Options.PayloadMaxSize = trigger.MaxBodySize
 		triggers[i] = routingTrigger
 	}
 	return triggers
@@ -247,6 +261,11 @@ func (*URLRewrite) extractTriggerOptions(rules []*URLRewriteRule) apidef.Routing
 			result.QueryValMatches[rule.Name] = item
 		case InputSessionMetadata:
 			result.SessionMetaMatches[rule.Name] = item
+		case InputRequestBodyJSON:
+			if result.PayloadJSONMatches == nil {
+				result.PayloadJSONMatches = make(map[string]apidef.StringRegexMap)
+			}
+			result.PayloadJSONMatches[rule.Name] = item
 		}
 	}
 
@@ -256,7 +275,7 @@ func (*URLRewrite) extractTriggerOptions(rules []*URLRewriteRule) apidef.Routing
 // Valid returns true if the type value matches valid values, false otherwise.
 func (i URLRewriteInput) Valid() bool {
 	switch i {
-	case InputQuery, InputPath, InputHeader, InputSessionMetadata, InputRequestBody, InputRequestContext:
+	case InputQuery, InputPath, InputHeader, InputSessionMetadata, InputRequestBody, InputRequestContext, InputRequestBodyJSON:
 		return true
 	}
 	return false
@@ -0,0 +1,22 @@
+package apidef
+
+import (
+	"github.com/itchyny/gojq"
+)
+
+// TransformJQVariables are the variables available to the JQ expressions of body transforms.
+var TransformJQVariables = []string{"$tyk_context", "$tyk_meta"}
+
+// CompileTransformJQ compiles the JQ expression of a body transform. The process environment
+// isn't exposed to the expression.
+func CompileTransformJQ(expression string) (*gojq.Code, error) {
+	query, err := gojq.Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	return gojq.Compile(query,
+		gojq.WithVariables(TransformJQVariables),
+		gojq.WithEnvironLoader(func() []string { return nil }),
+	)
+}
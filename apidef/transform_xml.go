@@ -0,0 +1,494 @@
+package apidef
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/clbanning/mxj"
+	"golang.org/x/net/html/charset"
+)
+
+// Values of the SOAP version of body transforms.
+const (
+	SOAPVersion11 = "1.1"
+	SOAPVersion12 = "1.2"
+)
+
+// SOAP envelope namespaces by SOAP version.
+const (
+	SOAPEnvelopeNamespace11 = "http://schemas.xmlsoap.org/soap/envelope/"
+	SOAPEnvelopeNamespace12 = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// ErrMalformedXML is returned by the XML helpers of transform templates when the XML can't be parsed.
+var ErrMalformedXML = errors.New("malformed XML")
+
+// TemplateSOAP configures the SOAP headers of a request transformed into a SOAP envelope.
+type TemplateSOAP struct {
+	// Enabled sets the Content-Type and SOAP action headers of the transformed request.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Version is the SOAP version of the envelope, 1.1 (default) or 1.2.
+	Version string `bson:"version" json:"version,omitempty"`
+	// Action is the SOAP action of the request. With SOAP 1.1 it is sent in the SOAPAction header,
+	// with SOAP 1.2 as the action parameter of the Content-Type.
+	Action string `bson:"action" json:"action,omitempty"`
+}
+
+// Options of the XML helpers of transform templates.
+const (
+	xmlOptionCast              = "cast"
+	xmlOptionNamespacePrefixes = "namespacePrefixes"
+	xmlOptionStripNamespaces   = "stripNamespaces"
+	xmlOptionDropAttributes    = "dropAttributes"
+	xmlOptionAttributePrefix   = "attributePrefix"
+	xmlOptionRoot              = "root"
+	xmlOptionXMLNS             = "xmlns"
+	xmlOptionIndent            = "indent"
+	xmlOptionVersion           = "version"
+)
+
+// mxjAttributePrefix is the prefix of the attribute keys of maps parsed and rendered by mxj.
+const mxjAttributePrefix = "-"
+
+type xmlOptions struct {
+	cast              bool
+	namespacePrefixes bool
+	stripNamespaces   bool
+	dropAttributes    bool
+	indent            bool
+	attributePrefix   string
+	root              string
+	xmlns             string
+	version           string
+}
+
+// parseXMLOptions parses the options given to an XML helper as `name` or `name=value`, failing on
+// options that the helper doesn't support.
+func parseXMLOptions(options []string, supported ...string) (xmlOptions, error) {
+	opts := xmlOptions{attributePrefix: mxjAttributePrefix, version: SOAPVersion11}
+
+	for _, option := range options {
+		name, value, _ := strings.Cut(option, "=")
+
+		supportedOption := false
+		for _, s := range supported {
+			supportedOption = supportedOption || s == name
+		}
+		if !supportedOption {
+			return opts, fmt.Errorf("unsupported option %q", option)
+		}
+
+		switch name {
+		case xmlOptionCast:
+			opts.cast = true
+		case xmlOptionNamespacePrefixes:
+			opts.namespacePrefixes = true
+		case xmlOptionStripNamespaces:
+			opts.stripNamespaces = true
+		case xmlOptionDropAttributes:
+			opts.dropAttributes = true
+		case xmlOptionIndent:
+			opts.indent = true
+		case xmlOptionAttributePrefix:
+			if value == "" {
+				return opts, fmt.Errorf("option %q requires a value", name)
+			}
+			opts.attributePrefix = value
+		case xmlOptionRoot:
+			opts.root = value
+		case xmlOptionXMLNS:
+			opts.xmlns = value
+		case xmlOptionVersion:
+			if value != SOAPVersion11 && value != SOAPVersion12 {
+				return opts, fmt.Errorf("unsupported SOAP version %q", value)
+			}
+			opts.version = value
+		}
+	}
+
+	return opts, nil
+}
+
+// xmlParseOptions are the options of the helpers parsing XML.
+var xmlParseOptions = []string{
+	xmlOptionCast, xmlOptionNamespacePrefixes, xmlOptionStripNamespaces, xmlOptionDropAttributes, xmlOptionAttributePrefix,
+}
+
+// transformXMLFuncs are the XML and SOAP helpers of body transform templates.
+var transformXMLFuncs = template.FuncMap{
+	"xmlToMap":     xmlToMap,
+	"mapToXml":     mapToXML,
+	"soapEnvelope": soapEnvelope,
+	"soapBody":     soapBody,
+}
+
+// xmlToMap parses an XML document into a map. Elements are keyed with their local names, attributes
+// with the `-` prefix and the text of elements with attributes with `#text`. Repeated elements are
+// parsed into a list. Namespace declarations are kept as `-xmlns` attributes. Options:
+//   - cast: converts numeric and boolean values,
+//   - namespacePrefixes: keeps the namespace prefixes of element and attribute names, e.g. `soap:Envelope`,
+//   - stripNamespaces: drops the namespace declarations,
+//   - dropAttributes: drops all attributes,
+//   - attributePrefix=<prefix>: keys attributes with the given prefix instead of `-`.
+func xmlToMap(doc string, options ...string) (map[string]interface{}, error) {
+	opts, err := parseXMLOptions(options, xmlParseOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := parseXML([]byte(doc), opts.cast)
+	if err != nil {
+		return nil, err
+	}
+
+	return normaliseXMLMap(m, opts).(map[string]interface{}), nil
+}
+
+// mapToXML renders a map as XML. Keys with the attribute prefix are rendered as attributes and `#text`
+// as the text of the element, child elements are rendered in the order of their names. Options:
+//   - root=<name>: wraps the map into the given root element, required for maps with several keys,
+//   - xmlns=<namespace>: declares the default namespace on the root element,
+//   - attributePrefix=<prefix>: renders keys with the given prefix instead of `-` as attributes,
+//   - indent: indents the XML.
+func mapToXML(v interface{}, options ...string) (string, error) {
+	opts, err := parseXMLOptions(options, xmlOptionRoot, xmlOptionXMLNS, xmlOptionAttributePrefix, xmlOptionIndent)
+	if err != nil {
+		return "", err
+	}
+
+	return renderXML(v, opts)
+}
+
+// soapEnvelope wraps the body into a SOAP envelope. The body is either a map, rendered as with mapToXml,
+// or XML. Besides the options of mapToXml, version=1.2 renders a SOAP 1.2 envelope instead of 1.1.
+func soapEnvelope(body interface{}, options ...string) (string, error) {
+	opts, err := parseXMLOptions(options, xmlOptionRoot, xmlOptionXMLNS, xmlOptionAttributePrefix, xmlOptionIndent, xmlOptionVersion)
+	if err != nil {
+		return "", err
+	}
+
+	content, ok := body.(string)
+	if !ok {
+		if content, err = renderXML(body, opts); err != nil {
+			return "", err
+		}
+	}
+
+	namespace := SOAPEnvelopeNamespace11
+	if opts.version == SOAPVersion12 {
+		namespace = SOAPEnvelopeNamespace12
+	}
+
+	return `<soap:Envelope xmlns:soap="` + namespace + `"><soap:Body>` + content + `</soap:Body></soap:Envelope>`, nil
+}
+
+// soapBody returns the content of the body of a SOAP envelope, given as XML or as a map parsed from it,
+// whatever the namespace prefix of the envelope. Faults are returned as the Fault key of the body.
+// It accepts the options of xmlToMap, cast only applies to XML.
+func soapBody(envelope interface{}, options ...string) (map[string]interface{}, error) {
+	opts, err := parseXMLOptions(options, xmlParseOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]interface{}
+	switch v := envelope.(type) {
+	case string:
+		if m, err = parseXML([]byte(v), opts.cast); err != nil {
+			return nil, err
+		}
+	case mxj.Map:
+		m = v
+	case map[string]interface{}:
+		m = v
+	default:
+		return nil, fmt.Errorf("unsupported SOAP envelope type %T", envelope)
+	}
+
+	env, ok := localNameValue(m, "Envelope").(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: missing SOAP envelope", ErrMalformedXML)
+	}
+
+	body, ok := localNameValue(env, "Body").(map[string]interface{})
+	if !ok {
+		// An empty body is parsed as an empty string.
+		if _, found := findLocalName(env, "Body"); !found {
+			return nil, fmt.Errorf("%w: missing SOAP body", ErrMalformedXML)
+		}
+		body = map[string]interface{}{}
+	}
+
+	return normaliseXMLMap(body, opts).(map[string]interface{}), nil
+}
+
+func renderXML(v interface{}, opts xmlOptions) (string, error) {
+	var m map[string]interface{}
+	switch mv := v.(type) {
+	case mxj.Map:
+		m = mv
+	case map[string]interface{}:
+		m = mv
+	default:
+		return "", fmt.Errorf("unsupported XML value type %T", v)
+	}
+
+	m = renameAttributes(m, opts.attributePrefix, mxjAttributePrefix).(map[string]interface{})
+
+	if opts.xmlns != "" {
+		root := opts.root
+		if root == "" {
+			if len(m) != 1 {
+				return "", errors.New("xmlns requires a single root element")
+			}
+			for key := range m {
+				root = key
+			}
+		}
+
+		content := m
+		if opts.root == "" {
+			content, _ = m[root].(map[string]interface{})
+			if content == nil {
+				content = map[string]interface{}{"#text": m[root]}
+			}
+		}
+
+		withNamespace := make(map[string]interface{}, len(content)+1)
+		for key, value := range content {
+			withNamespace[key] = value
+		}
+		withNamespace[mxjAttributePrefix+"xmlns"] = opts.xmlns
+
+		m, opts.root = withNamespace, root
+	}
+
+	var rootTag []string
+	if opts.root != "" {
+		rootTag = append(rootTag, opts.root)
+	}
+
+	mxj.XMLEscapeChars(true)
+
+	var (
+		out []byte
+		err error
+	)
+	if opts.indent {
+		out, err = mxj.Map(m).XmlIndent("", "  ", rootTag...)
+	} else {
+		out, err = mxj.Map(m).Xml(rootTag...)
+	}
+
+	return string(out), err
+}
+
+// parseXML parses an XML document into a map of qualified names.
+func parseXML(doc []byte, cast bool) (map[string]interface{}, error) {
+	d := xml.NewDecoder(bytes.NewReader(doc))
+	d.CharsetReader = charset.NewReaderLabel
+
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: missing root element", ErrMalformedXML)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformedXML, err)
+		}
+
+		if start, ok := tok.(xml.StartElement); ok {
+			value, err := parseXMLElement(d, start, cast)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrMalformedXML, err)
+			}
+
+			return map[string]interface{}{qualifiedXMLName(start.Name): value}, nil
+		}
+	}
+}
+
+// parseXMLElement parses the element up to its end. Elements with neither attributes nor children
+// are parsed into their text.
+func parseXMLElement(d *xml.Decoder, start xml.StartElement, cast bool) (interface{}, error) {
+	m := make(map[string]interface{})
+	for _, attr := range start.Attr {
+		m[mxjAttributePrefix+qualifiedXMLName(attr.Name)] = castXMLValue(attr.Value, cast)
+	}
+
+	var text strings.Builder
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			child, err := parseXMLElement(d, t, cast)
+			if err != nil {
+				return nil, err
+			}
+
+			name := qualifiedXMLName(t.Name)
+			switch existing := m[name].(type) {
+			case nil:
+				m[name] = child
+			case []interface{}:
+				m[name] = append(existing, child)
+			default:
+				m[name] = []interface{}{existing, child}
+			}
+		case xml.EndElement:
+			if t.Name != start.Name {
+				line, _ := d.InputPos()
+				return nil, fmt.Errorf("line %d: element <%s> closed by </%s>", line, qualifiedXMLName(start.Name), qualifiedXMLName(t.Name))
+			}
+
+			value := strings.TrimSpace(text.String())
+			if len(m) == 0 {
+				return castXMLValue(value, cast), nil
+			}
+			if value != "" {
+				m["#text"] = castXMLValue(value, cast)
+			}
+			return m, nil
+		case xml.CharData:
+			text.Write(t)
+		}
+	}
+}
+
+func qualifiedXMLName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+
+	return name.Space + ":" + name.Local
+}
+
+func castXMLValue(value string, cast bool) interface{} {
+	if !cast {
+		return value
+	}
+
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	return value
+}
+
+// normaliseXMLMap applies the parsing options to a map of qualified names.
+func normaliseXMLMap(v interface{}, opts xmlOptions) interface{} {
+	switch value := v.(type) {
+	case mxj.Map:
+		return normaliseXMLMap(map[string]interface{}(value), opts)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(value))
+		for key, child := range value {
+			isAttribute := strings.HasPrefix(key, mxjAttributePrefix)
+			name := strings.TrimPrefix(key, mxjAttributePrefix)
+			isDeclaration := isAttribute && (name == "xmlns" || strings.HasPrefix(name, "xmlns:"))
+
+			if isAttribute && opts.dropAttributes || isDeclaration && opts.stripNamespaces {
+				continue
+			}
+
+			if !opts.namespacePrefixes && !isDeclaration {
+				if _, local, ok := strings.Cut(name, ":"); ok {
+					name = local
+				}
+			}
+
+			if isAttribute {
+				name = opts.attributePrefix + name
+			}
+
+			child = normaliseXMLMap(child, opts)
+			if existing, ok := out[name]; ok {
+				// Elements of different namespaces with the same local name are merged into a list.
+				list, isList := existing.([]interface{})
+				if !isList {
+					list = []interface{}{existing}
+				}
+				child = append(list, child)
+			}
+			out[name] = child
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(value))
+		for i, child := range value {
+			out[i] = normaliseXMLMap(child, opts)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// renameAttributes replaces the prefix of attribute keys.
+func renameAttributes(v interface{}, from, to string) interface{} {
+	if from == to {
+		return v
+	}
+
+	switch value := v.(type) {
+	case mxj.Map:
+		return renameAttributes(map[string]interface{}(value), from, to)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(value))
+		for key, child := range value {
+			if strings.HasPrefix(key, from) {
+				key = to + strings.TrimPrefix(key, from)
+			}
+			out[key] = renameAttributes(child, from, to)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(value))
+		for i, child := range value {
+			out[i] = renameAttributes(child, from, to)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// findLocalName returns the value of the key of the map with the given local name, whatever its namespace prefix.
+func findLocalName(m map[string]interface{}, localName string) (interface{}, bool) {
+	for key, value := range m {
+		if strings.HasPrefix(key, mxjAttributePrefix) {
+			continue
+		}
+
+		if key == localName || strings.HasSuffix(key, ":"+localName) {
+			return value, true
+		}
+	}
+
+	return nil, false
+}
+
+func localNameValue(m map[string]interface{}, localName string) interface{} {
+	value, _ := findLocalName(m, localName)
+	return value
+}
@@ -0,0 +1,113 @@
+package apidef
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSOAPResponse = `<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+	<soap:Body>
+		<m:AddResponse xmlns:m="http://tempuri.org/" m:id="42">
+			<m:AddResult>5</m:AddResult>
+		</m:AddResponse>
+	</soap:Body>
+</soap:Envelope>`
+
+func TestTransformXMLFuncs(t *testing.T) {
+	t.Run("xmlToMap", func(t *testing.T) {
+		m, err := xmlToMap(testSOAPResponse)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"Envelope": map[string]interface{}{
+				"-xmlns:soap": SOAPEnvelopeNamespace11,
+				"Body": map[string]interface{}{
+					"AddResponse": map[string]interface{}{
+						"-xmlns:m":  "http://tempuri.org/",
+						"-id":       "42",
+						"AddResult": "5",
+					},
+				},
+			},
+		}, m)
+
+		m, err = xmlToMap(testSOAPResponse, "stripNamespaces", "cast", "attributePrefix=@")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"Envelope": map[string]interface{}{
+				"Body": map[string]interface{}{
+					"AddResponse": map[string]interface{}{
+						"@id":       float64(42),
+						"AddResult": float64(5),
+					},
+				},
+			},
+		}, m)
+
+		m, err = xmlToMap(`<a id="1"><b>x</b></a>`, "dropAttributes")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"a": map[string]interface{}{"b": "x"}}, m)
+
+		m, err = xmlToMap(`<a><b>1</b><b>2</b><c id="x">3</c></a>`, "cast")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"a": map[string]interface{}{
+			"b": []interface{}{float64(1), float64(2)},
+			"c": map[string]interface{}{"-id": "x", "#text": float64(3)},
+		}}, m)
+
+		for _, malformed := range []string{`<a><b></a>`, `<a>`, `not xml`} {
+			_, err = xmlToMap(malformed)
+			assert.ErrorIs(t, err, ErrMalformedXML, malformed)
+		}
+
+		_, err = xmlToMap(`<a/>`, "unknown")
+		assert.EqualError(t, err, `unsupported option "unknown"`)
+	})
+
+	t.Run("mapToXml", func(t *testing.T) {
+		out, err := mapToXML(map[string]interface{}{"Add": map[string]interface{}{"@id": "1", "intA": 2, "intB": "<3>"}}, "attributePrefix=@")
+		require.NoError(t, err)
+		assert.Equal(t, `<Add id="1"><intA>2</intA><intB>&lt;3&gt;</intB></Add>`, out)
+
+		out, err = mapToXML(map[string]interface{}{"intA": 2, "intB": 3}, "root=Add", "xmlns=http://tempuri.org/")
+		require.NoError(t, err)
+		assert.Equal(t, `<Add xmlns="http://tempuri.org/"><intA>2</intA><intB>3</intB></Add>`, out)
+
+		parsed, err := xmlToMap(testSOAPResponse, "namespacePrefixes")
+		require.NoError(t, err)
+		out, err = mapToXML(parsed)
+		require.NoError(t, err)
+		reparsed, err := xmlToMap(out, "namespacePrefixes")
+		require.NoError(t, err)
+		assert.Equal(t, parsed, reparsed)
+		assert.Contains(t, out, `<soap:Envelope xmlns:soap="`+SOAPEnvelopeNamespace11+`">`)
+
+		_, err = mapToXML("not a map")
+		assert.Error(t, err)
+	})
+
+	t.Run("soapEnvelope", func(t *testing.T) {
+		out, err := soapEnvelope(map[string]interface{}{"Add": map[string]interface{}{"intA": 2}}, "xmlns=http://tempuri.org/", "version=1.2")
+		require.NoError(t, err)
+		assert.Equal(t, `<soap:Envelope xmlns:soap="`+SOAPEnvelopeNamespace12+`"><soap:Body><Add xmlns="http://tempuri.org/"><intA>2</intA></Add></soap:Body></soap:Envelope>`, out)
+
+		_, err = soapEnvelope("<Add/>", "version=2.0")
+		assert.EqualError(t, err, `unsupported SOAP version "2.0"`)
+	})
+
+	t.Run("soapBody", func(t *testing.T) {
+		parsed, err := xmlToMap(testSOAPResponse, "cast")
+		require.NoError(t, err)
+
+		for _, envelope := range []interface{}{testSOAPResponse, parsed} {
+			body, err := soapBody(envelope, "stripNamespaces", "dropAttributes", "cast")
+			require.NoError(t, err)
+			assert.Equal(t, map[string]interface{}{"AddResponse": map[string]interface{}{"AddResult": float64(5)}}, body)
+		}
+
+		_, err = soapBody(`<a/>`)
+		assert.ErrorIs(t, err, ErrMalformedXML)
+	})
+}
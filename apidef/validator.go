@@ -1,11 +1,18 @@
 package apidef
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net"
 	"sort"
+	"strconv"
 	"strings"
+	"text/template"
+
+	"github.com/TykTechnologies/tyk/internal/httputil"
+	"github.com/TykTechnologies/tyk/internal/service/gojsonschema"
+	"github.com/TykTechnologies/tyk/regexp"
 )
 
 type ValidationResult struct {
@@ -60,6 +67,14 @@ var DefaultValidationRuleSet = ValidationRuleSet{
 	&RuleValidateEnforceTimeout{},
 	&RuleUpstreamAuth{},
 	&RuleLoadBalancingTargets{},
+	&RuleValidateHeaderRewrites{},
+	&RuleValidateTransformJQ{},
+	&RuleValidateTransformSOAP{},
+	&RuleValidateNormaliseURLPatterns{},
+	&RuleValidateErrorTemplates{},
+	&RuleValidateCORSPaths{},
+	&RuleValidateJSONSchemas{},
+	&RuleValidateUptimeTests{},
 }
 
 func Validate(definition *APIDefinition, ruleSet ValidationRuleSet) ValidationResult {
@@ -285,3 +300,209 @@ func (r *RuleLoadBalancingTargets) Validate(apiDef *APIDefinition, validationRes
 		validationResult.AppendError(ErrAllLoadBalancingTargetsZeroWeight)
 	}
 }
+
+var ErrInvalidHeaderRewriteRegex = "invalid header rewrite regex %q for header %q: %v"
+
+// RuleValidateHeaderRewrites implements validations for header rewrite regular expressions.
+type RuleValidateHeaderRewrites struct{}
+
+// Validate validates that the global and endpoint level header rewrites compile.
+func (r *RuleValidateHeaderRewrites) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	for _, vInfo := range apiDef.VersionData.Versions {
+		r.validateRewrites(vInfo.GlobalHeadersRewrite, validationResult)
+		r.validateRewrites(vInfo.GlobalResponseHeadersRewrite, validationResult)
+
+		for _, meta := range vInfo.ExtendedPaths.TransformHeader {
+			r.validateRewrites(meta.RewriteHeaders, validationResult)
+		}
+
+		for _, meta := range vInfo.ExtendedPaths.TransformResponseHeader {
+			r.validateRewrites(meta.RewriteHeaders, validationResult)
+		}
+	}
+}
+
+func (r *RuleValidateHeaderRewrites) validateRewrites(rewrites []HeaderRewrite, validationResult *ValidationResult) {
+	for _, rewrite := range rewrites {
+		if _, err := regexp.Compile(rewrite.MatchRegex); err != nil {
+			validationResult.IsValid = false
+			validationResult.AppendError(fmt.Errorf(ErrInvalidHeaderRewriteRegex, rewrite.MatchRegex, rewrite.Header, err))
+		}
+	}
+}
+
+var ErrInvalidTransformJQ = "invalid JQ expression for body transform of %s %s: %v"
+
+// RuleValidateTransformJQ implements validations for the JQ expressions of body transforms.
+type RuleValidateTransformJQ struct{}
+
+// Validate validates that the JQ expressions of request and response body transforms compile.
+// Expressions loaded from a file are only checked when the API is loaded.
+func (r *RuleValidateTransformJQ) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	for _, vInfo := range apiDef.VersionData.Versions {
+		r.validateTransforms(vInfo.ExtendedPaths.Transform, validationResult)
+		r.validateTransforms(vInfo.ExtendedPaths.TransformResponse, validationResult)
+	}
+}
+
+func (r *RuleValidateTransformJQ) validateTransforms(transforms []TemplateMeta, validationResult *ValidationResult) {
+	for _, meta := range transforms {
+		if meta.Disabled || meta.Format != TransformFormatJQ || meta.TemplateData.Mode != UseBlob {
+			continue
+		}
+
+		expression, err := base64.StdEncoding.DecodeString(meta.TemplateData.TemplateSource)
+		if err == nil {
+			_, err = CompileTransformJQ(string(expression))
+		}
+
+		if err != nil {
+			validationResult.IsValid = false
+			validationResult.AppendError(fmt.Errorf(ErrInvalidTransformJQ, meta.Method, meta.Path, err))
+		}
+	}
+}
+
+var ErrInvalidTransformSOAPVersion = "invalid SOAP version %q for body transform of %s %s"
+
+// RuleValidateTransformSOAP implements validations for the SOAP settings of request body transforms.
+type RuleValidateTransformSOAP struct{}
+
+// Validate validates the SOAP versions of request body transforms.
+func (r *RuleValidateTransformSOAP) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	for _, vInfo := range apiDef.VersionData.Versions {
+		for _, meta := range vInfo.ExtendedPaths.Transform {
+			soap := meta.TemplateData.SOAP
+			if meta.Disabled || soap == nil || !soap.Enabled {
+				continue
+			}
+
+			switch soap.Version {
+			case "", SOAPVersion11, SOAPVersion12:
+			default:
+				validationResult.IsValid = false
+				validationResult.AppendError(fmt.Errorf(ErrInvalidTransformSOAPVersion, soap.Version, meta.Method, meta.Path))
+			}
+		}
+	}
+}
+
+var ErrInvalidNormaliseURLPattern = "invalid analytics URL normalisation pattern %q: %v"
+
+// RuleValidateNormaliseURLPatterns implements validations for the analytics URL normalisation patterns.
+type RuleValidateNormaliseURLPatterns struct{}
+
+// Validate validates that the analytics URL normalisation patterns compile.
+func (r *RuleValidateNormaliseURLPatterns) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	for _, pattern := range apiDef.NormaliseURLPatterns {
+		if _, err := regexp.Compile(pattern.Regex); err != nil {
+			validationResult.IsValid = false
+			validationResult.AppendError(fmt.Errorf(ErrInvalidNormaliseURLPattern, pattern.Regex, err))
+		}
+	}
+}
+
+var ErrInvalidErrorTemplate = "invalid error template %q: %v"
+
+// RuleValidateErrorTemplates implements validations for the error templates of an API.
+type RuleValidateErrorTemplates struct{}
+
+// Validate validates that the error templates are keyed by a status code or default and parse.
+func (r *RuleValidateErrorTemplates) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	for key, tmpl := range apiDef.ErrorTemplates {
+		if key != ErrorTemplateDefault {
+			if code, err := strconv.Atoi(key); err != nil || code < 100 || code > 599 {
+				validationResult.IsValid = false
+				validationResult.AppendError(fmt.Errorf(ErrInvalidErrorTemplate, key, "key must be a status code or default"))
+				continue
+			}
+		}
+
+		for _, source := range []string{tmpl.JSON, tmpl.XML} {
+			if _, err := template.New(key).Parse(source); err != nil {
+				validationResult.IsValid = false
+				validationResult.AppendError(fmt.Errorf(ErrInvalidErrorTemplate, key, err))
+			}
+		}
+	}
+}
+
+var (
+	ErrInvalidCORSPath   = "invalid CORS override path %q: %v"
+	ErrDuplicateCORSPath = "conflicting CORS overrides for path %q in version %q"
+)
+
+// RuleValidateCORSPaths implements validations for the per-path CORS overrides.
+type RuleValidateCORSPaths struct{}
+
+// Validate validates that the CORS override paths compile and that a path is overridden only once per version.
+func (r *RuleValidateCORSPaths) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	for name, vInfo := range apiDef.VersionData.Versions {
+		paths := map[string]struct{}{}
+
+		for _, meta := range vInfo.ExtendedPaths.CORS {
+			if meta.Disabled {
+				continue
+			}
+
+			if !strings.HasPrefix(meta.Path, "/") {
+				validationResult.IsValid = false
+				validationResult.AppendError(fmt.Errorf(ErrInvalidCORSPath, meta.Path, "path must start with /"))
+				continue
+			}
+
+			if _, err := regexp.Compile(httputil.PreparePathRegexp(meta.Path, true, false)); err != nil {
+				validationResult.IsValid = false
+				validationResult.AppendError(fmt.Errorf(ErrInvalidCORSPath, meta.Path, err))
+				continue
+			}
+
+			if _, ok := paths[meta.Path]; ok {
+				validationResult.IsValid = false
+				validationResult.AppendError(fmt.Errorf(ErrDuplicateCORSPath, meta.Path, name))
+				continue
+			}
+			paths[meta.Path] = struct{}{}
+		}
+	}
+}
+
+var ErrInvalidJSONSchema = "invalid JSON schema for validation of %s %s: %v"
+
+// RuleValidateJSONSchemas implements validations for the schemas of the JSON validation paths.
+type RuleValidateJSONSchemas struct{}
+
+// Validate validates that the schemas of the JSON validation paths compile.
+func (r *RuleValidateJSONSchemas) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	for _, vInfo := range apiDef.VersionData.Versions {
+		for _, meta := range vInfo.ExtendedPaths.ValidateJSON {
+			if meta.Disabled || meta.Schema == nil {
+				continue
+			}
+
+			if _, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(meta.Schema)); err != nil {
+				validationResult.IsValid = false
+				validationResult.AppendError(fmt.Errorf(ErrInvalidJSONSchema, meta.Method, meta.Path, err))
+			}
+		}
+	}
+}
+
+var ErrInvalidUptimeTestBodyRegex = "invalid expected body regex %q for uptime test of %s: %v"
+
+// RuleValidateUptimeTests implements validations for the uptime tests of an API.
+type RuleValidateUptimeTests struct{}
+
+// Validate validates that the expected body regular expressions of the uptime tests compile.
+func (r *RuleValidateUptimeTests) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	for _, check := range apiDef.UptimeTests.CheckList {
+		if check.ExpectedBodyRegex == "" {
+			continue
+		}
+
+		if _, err := regexp.Compile(check.ExpectedBodyRegex); err != nil {
+			validationResult.IsValid = false
+			validationResult.AppendError(fmt.Errorf(ErrInvalidUptimeTestBodyRegex, check.ExpectedBodyRegex, check.CheckURL, err))
+		}
+	}
+}
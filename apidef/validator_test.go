@@ -1,10 +1,12 @@
 package apidef
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net/http"
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/stretchr/testify/assert"
@@ -650,3 +652,422 @@ func TestRuleLoadBalancingTargets_Validate(t *testing.T) {
 		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
 	}
 }
+
+func TestRuleValidateHeaderRewrites_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleValidateHeaderRewrites{},
+	}
+
+	getAPIDef := func(global, endpoint []HeaderRewrite) *APIDefinition {
+		return &APIDefinition{
+			VersionData: VersionData{
+				Versions: map[string]VersionInfo{
+					"Default": {
+						Name:                         "Default",
+						GlobalResponseHeadersRewrite: global,
+						ExtendedPaths: ExtendedPathsSet{
+							TransformResponseHeader: []HeaderInjectionMeta{
+								{Path: "/get", Method: http.MethodGet, RewriteHeaders: endpoint},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	valid := HeaderRewrite{Header: "Location", MatchRegex: `^https?://internal(:\d+)?`, Replace: "https://api.example.com"}
+	invalid := HeaderRewrite{Header: "X-Correlation-ID", MatchRegex: `([a-z`, Replace: "$1"}
+	invalidErr := fmt.Errorf(ErrInvalidHeaderRewriteRegex, invalid.MatchRegex, invalid.Header,
+		"error parsing regexp: missing closing ]: `[a-z`")
+
+	testCases := []struct {
+		name   string
+		apiDef *APIDefinition
+		result ValidationResult
+	}{
+		{
+			name:   "valid rewrites",
+			apiDef: getAPIDef([]HeaderRewrite{valid}, []HeaderRewrite{valid}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "invalid global rewrite",
+			apiDef: getAPIDef([]HeaderRewrite{invalid}, []HeaderRewrite{valid}),
+			result: ValidationResult{IsValid: false, Errors: []error{invalidErr}},
+		},
+		{
+			name:   "invalid endpoint rewrite",
+			apiDef: getAPIDef(nil, []HeaderRewrite{valid, invalid}),
+			result: ValidationResult{IsValid: false, Errors: []error{invalidErr}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleValidateTransformJQ_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleValidateTransformJQ{},
+	}
+
+	getAPIDef := func(request, response string) *APIDefinition {
+		meta := func(expression string) []TemplateMeta {
+			return []TemplateMeta{
+				{
+					Path:   "/orders",
+					Method: http.MethodPost,
+					Format: TransformFormatJQ,
+					TemplateData: TemplateData{
+						Input:          RequestJSON,
+						Mode:           UseBlob,
+						TemplateSource: base64.StdEncoding.EncodeToString([]byte(expression)),
+					},
+				},
+			}
+		}
+
+		return &APIDefinition{
+			VersionData: VersionData{
+				Versions: map[string]VersionInfo{
+					"Default": {
+						Name: "Default",
+						ExtendedPaths: ExtendedPathsSet{
+							Transform:         meta(request),
+							TransformResponse: meta(response),
+						},
+					},
+				},
+			},
+		}
+	}
+
+	valid := `{items: [.items[] | select(.qty > 0)], tenant: $tyk_context.headers_X_Tenant}`
+	invalid := `.items[] | select(.qty >`
+	_, compileErr := CompileTransformJQ(invalid)
+	invalidErr := fmt.Errorf(ErrInvalidTransformJQ, http.MethodPost, "/orders", compileErr)
+
+	_, undefinedErr := CompileTransformJQ(`$undefined`)
+
+	testCases := []struct {
+		name   string
+		apiDef *APIDefinition
+		result ValidationResult
+	}{
+		{
+			name:   "valid expressions",
+			apiDef: getAPIDef(valid, valid),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "invalid request expression",
+			apiDef: getAPIDef(invalid, valid),
+			result: ValidationResult{IsValid: false, Errors: []error{invalidErr}},
+		},
+		{
+			name:   "invalid response expression",
+			apiDef: getAPIDef(valid, invalid),
+			result: ValidationResult{IsValid: false, Errors: []error{invalidErr}},
+		},
+		{
+			name:   "undefined variable",
+			apiDef: getAPIDef(`$undefined`, valid),
+			result: ValidationResult{IsValid: false, Errors: []error{
+				fmt.Errorf(ErrInvalidTransformJQ, http.MethodPost, "/orders", undefinedErr),
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
+	}
+
+	t.Run("template transforms are ignored", func(t *testing.T) {
+		apiDef := getAPIDef(invalid, invalid)
+		version := apiDef.VersionData.Versions["Default"]
+		version.ExtendedPaths.Transform[0].Format = ""
+		version.ExtendedPaths.TransformResponse[0].Disabled = true
+
+		runValidationTest(apiDef, ruleSet, ValidationResult{IsValid: true})(t)
+	})
+}
+
+func TestRuleValidateTransformSOAP_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleValidateTransformSOAP{},
+	}
+
+	getAPIDef := func(soap *TemplateSOAP) *APIDefinition {
+		return &APIDefinition{
+			VersionData: VersionData{
+				Versions: map[string]VersionInfo{
+					"Default": {
+						Name: "Default",
+						ExtendedPaths: ExtendedPathsSet{
+							Transform: []TemplateMeta{
+								{
+									Path:         "/add",
+									Method:       http.MethodPost,
+									TemplateData: TemplateData{Input: RequestJSON, Mode: UseBlob, SOAP: soap},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		apiDef *APIDefinition
+		result ValidationResult
+	}{
+		{
+			name:   "no SOAP",
+			apiDef: getAPIDef(nil),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "default version",
+			apiDef: getAPIDef(&TemplateSOAP{Enabled: true, Action: "Add"}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "SOAP 1.2",
+			apiDef: getAPIDef(&TemplateSOAP{Enabled: true, Version: SOAPVersion12}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "disabled with invalid version",
+			apiDef: getAPIDef(&TemplateSOAP{Version: "2.0"}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "invalid version",
+			apiDef: getAPIDef(&TemplateSOAP{Enabled: true, Version: "2.0"}),
+			result: ValidationResult{IsValid: false, Errors: []error{
+				fmt.Errorf(ErrInvalidTransformSOAPVersion, "2.0", http.MethodPost, "/add"),
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleValidateNormaliseURLPatterns_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleValidateNormaliseURLPatterns{},
+	}
+
+	valid := URLNormalisePattern{Regex: `/orders/[0-9A-HJKMNP-TV-Z]{26}`, Replacement: "/orders/{order_id}"}
+	invalid := URLNormalisePattern{Regex: `/orders/([0-9`, Replacement: "/orders/{order_id}"}
+	invalidErr := fmt.Errorf(ErrInvalidNormaliseURLPattern, invalid.Regex,
+		"error parsing regexp: missing closing ]: `[0-9`")
+
+	testCases := []struct {
+		name   string
+		apiDef *APIDefinition
+		result ValidationResult
+	}{
+		{
+			name:   "no patterns",
+			apiDef: &APIDefinition{},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "valid patterns",
+			apiDef: &APIDefinition{NormaliseURLPatterns: []URLNormalisePattern{valid}},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "invalid pattern",
+			apiDef: &APIDefinition{NormaliseURLPatterns: []URLNormalisePattern{valid, invalid}},
+			result: ValidationResult{IsValid: false, Errors: []error{invalidErr}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleValidateUptimeTests_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleValidateUptimeTests{},
+	}
+
+	valid := HostCheckObject{CheckURL: "http://upstream/health", ExpectedBodyRegex: `"status":\s*"ok"`}
+	invalid := HostCheckObject{CheckURL: "http://upstream/ready", ExpectedBodyRegex: `"status":([a-z`}
+	invalidErr := fmt.Errorf(ErrInvalidUptimeTestBodyRegex, invalid.ExpectedBodyRegex, invalid.CheckURL,
+		"error parsing regexp: missing closing ]: `[a-z`")
+
+	testCases := []struct {
+		name   string
+		apiDef *APIDefinition
+		result ValidationResult
+	}{
+		{
+			name:   "no uptime tests",
+			apiDef: &APIDefinition{},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "valid body regex",
+			apiDef: &APIDefinition{UptimeTests: UptimeTests{CheckList: []HostCheckObject{valid, {CheckURL: "http://upstream"}}}},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "invalid body regex",
+			apiDef: &APIDefinition{UptimeTests: UptimeTests{CheckList: []HostCheckObject{valid, invalid}}},
+			result: ValidationResult{IsValid: false, Errors: []error{invalidErr}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleValidateErrorTemplates_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleValidateErrorTemplates{},
+	}
+
+	valid := ErrorTemplate{JSON: `{"error":"{{.Message}}"}`, XML: `<error>{{.Message}}</error>`}
+	invalid := ErrorTemplate{JSON: `{"error":"{{.Message"}`}
+	_, parseErr := template.New("404").Parse(invalid.JSON)
+
+	testCases := []struct {
+		name   string
+		apiDef *APIDefinition
+		result ValidationResult
+	}{
+		{
+			name:   "no templates",
+			apiDef: &APIDefinition{},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "valid templates",
+			apiDef: &APIDefinition{ErrorTemplates: map[string]ErrorTemplate{"404": valid, ErrorTemplateDefault: valid}},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "invalid key",
+			apiDef: &APIDefinition{ErrorTemplates: map[string]ErrorTemplate{"not_found": valid}},
+			result: ValidationResult{IsValid: false, Errors: []error{
+				fmt.Errorf(ErrInvalidErrorTemplate, "not_found", "key must be a status code or default"),
+			}},
+		},
+		{
+			name:   "invalid template",
+			apiDef: &APIDefinition{ErrorTemplates: map[string]ErrorTemplate{"404": invalid}},
+			result: ValidationResult{IsValid: false, Errors: []error{
+				fmt.Errorf(ErrInvalidErrorTemplate, "404", parseErr),
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleValidateCORSPaths_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleValidateCORSPaths{},
+	}
+
+	apiDef := func(paths ...CORSMeta) *APIDefinition {
+		return &APIDefinition{VersionData: VersionData{Versions: map[string]VersionInfo{
+			"v1": {ExtendedPaths: ExtendedPathsSet{CORS: paths}},
+		}}}
+	}
+
+	testCases := []struct {
+		name   string
+		apiDef *APIDefinition
+		result ValidationResult
+	}{
+		{
+			name:   "no overrides",
+			apiDef: &APIDefinition{},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "nested paths",
+			apiDef: apiDef(CORSMeta{Path: "/widget"}, CORSMeta{Path: "/widget/{id}"}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "duplicate disabled path",
+			apiDef: apiDef(CORSMeta{Path: "/widget"}, CORSMeta{Path: "/widget", Disabled: true}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "duplicate path",
+			apiDef: apiDef(CORSMeta{Path: "/widget"}, CORSMeta{Path: "/widget"}),
+			result: ValidationResult{IsValid: false, Errors: []error{
+				fmt.Errorf(ErrDuplicateCORSPath, "/widget", "v1"),
+			}},
+		},
+		{
+			name:   "relative path",
+			apiDef: apiDef(CORSMeta{Path: "widget"}),
+			result: ValidationResult{IsValid: false, Errors: []error{
+				fmt.Errorf(ErrInvalidCORSPath, "widget", "path must start with /"),
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleValidateJSONSchemas_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleValidateJSONSchemas{},
+	}
+
+	apiDef := func(paths ...ValidatePathMeta) *APIDefinition {
+		return &APIDefinition{VersionData: VersionData{Versions: map[string]VersionInfo{
+			"v1": {ExtendedPaths: ExtendedPathsSet{ValidateJSON: paths}},
+		}}}
+	}
+
+	invalidSchema := map[string]interface{}{"type": 5}
+
+	testCases := []struct {
+		name   string
+		apiDef *APIDefinition
+		result ValidationResult
+	}{
+		{
+			name:   "valid schema",
+			apiDef: apiDef(ValidatePathMeta{Path: "/widget", Method: "POST", Schema: map[string]interface{}{"type": "object"}}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "disabled invalid schema",
+			apiDef: apiDef(ValidatePathMeta{Path: "/widget", Method: "POST", Schema: invalidSchema, Disabled: true}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "invalid schema",
+			apiDef: apiDef(ValidatePathMeta{Path: "/widget", Method: "POST", Schema: invalidSchema}),
+			result: ValidationResult{IsValid: false, Errors: []error{
+				fmt.Errorf(ErrInvalidJSONSchema, "POST", "/widget", "Invalid type. Expected: string/array of strings, given: type"),
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
+	}
+}
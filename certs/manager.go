@@ -16,6 +16,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -102,6 +103,7 @@ type certificateManager struct {
 	storage                  StorageHandler
 	logger                   *logrus.Entry
 	cache                    cache.Repository
+	secretMu                 sync.RWMutex
 	secret                   string
 	migrateCertList          bool
 	certFetchMaxElapsedTime  time.Duration
@@ -636,14 +638,14 @@ func (c *certificateManager) appendEmbeddedPEM(id string, mode CertificateType,
 		return
 	}
 
-	parsedCert, parseErr := ParsePEMCertificate(rawCert, c.secret)
+	parsedCert, parseErr := ParsePEMCertificate(rawCert, c.getSecret())
 	if parseErr != nil {
 		// Fallback: the input may be a PEM whose line breaks were collapsed
 		// into spaces by a copy-paste into a single-line field (a common
 		// Dashboard/secret-store mishap). Repair the formatting and retry
 		// once before giving up.
 		if repaired, ok := normalizeCollapsedPEM(rawCert); ok {
-			if cert, retryErr := ParsePEMCertificate(repaired, c.secret); retryErr == nil {
+			if cert, retryErr := ParsePEMCertificate(repaired, c.getSecret()); retryErr == nil {
 				c.logger.Warn("Embedded PEM had non-canonical line formatting; normalized successfully. Please supply PEM with real line breaks (\\n).")
 				parsedCert, parseErr = cert, nil
 			}
@@ -761,7 +763,7 @@ func (c *certificateManager) List(certIDs []string, mode CertificateType) (out [
 			rawCert = []byte(val)
 		}
 
-		cert, err = ParsePEMCertificate(rawCert, c.secret)
+		cert, err = ParsePEMCertificate(rawCert, c.getSecret())
 		if err != nil {
 			c.logger.Error("Error while parsing certificate: ", id, " ", err)
 			c.logger.Debug("Failed certificate: ", string(rawCert))
@@ -943,7 +945,7 @@ func (c *certificateManager) GetRaw(certID string) (string, error) {
 
 func (c *certificateManager) Add(certData []byte, orgID string) (string, error) {
 
-	certID, certChainPEM, err := GetCertIDAndChainPEM(certData, c.secret)
+	certID, certChainPEM, err := GetCertIDAndChainPEM(certData, c.getSecret())
 	if err != nil {
 		c.logger.Error(err)
 		return "", err
@@ -992,6 +994,22 @@ func (c *certificateManager) FlushCache() {
 	c.cache.Flush()
 }
 
+// SetSecret replaces the secret encrypting the private keys, e.g. when it's rotated in a KV store.
+// The cached certificates are flushed, so they're decrypted again with the new secret.
+func (c *certificateManager) SetSecret(secret string) {
+	c.secretMu.Lock()
+	c.secret = secret
+	c.secretMu.Unlock()
+
+	c.FlushCache()
+}
+
+func (c *certificateManager) getSecret() string {
+	c.secretMu.RLock()
+	defer c.secretMu.RUnlock()
+	return c.secret
+}
+
 func (c *certificateManager) flushStorage() {
 	c.storage.DeleteScanMatch("*")
 }
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	tykcrypto "github.com/TykTechnologies/tyk/internal/crypto"
 	"github.com/TykTechnologies/tyk/storage"
@@ -192,6 +193,32 @@ func TestCertificateStorage(t *testing.T) {
 	})
 }
 
+func TestSetSecret(t *testing.T) {
+	m := newManager()
+
+	certPEM, keyPEM := genCertificateFromCommonName("private", false)
+	certID, err := m.Add(append(certPEM, keyPEM...), "")
+	require.NoError(t, err)
+
+	privateCerts := func() []*tls.Certificate {
+		var out []*tls.Certificate
+		for _, cert := range m.List([]string{certID}, CertificatePrivate) {
+			if cert != nil && !isPrivateKeyEmpty(cert) {
+				out = append(out, cert)
+			}
+		}
+		return out
+	}
+
+	require.Len(t, privateCerts(), 1)
+
+	m.SetSecret("rotated")
+	assert.Empty(t, privateCerts(), "the cached certificate is flushed, the key can't be decrypted with the new secret")
+
+	m.SetSecret("test")
+	assert.Len(t, privateCerts(), 1)
+}
+
 func TestStorageIndex(t *testing.T) {
 	m := newManager()
 	storageCert, _ := genCertificateFromCommonName("dummy", false)
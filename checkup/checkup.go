@@ -25,38 +25,17 @@ const (
 	minRecordsBufferSize = 1000
 )
 
-func Run(c *config.Config) {
-	allowInsecureConfigs(c)
-	healthCheck(c)
-	sessionLifetimeCheck(c)
-
+// Run checks the host the gateway runs on and lints its configuration and state, logging the issues found.
+// It defaults the unset analytics settings and returns the report of the configuration.
+func Run(c *config.Config, s State) Report {
 	fileDescriptors()
 	cpus()
-	defaultSecrets(c)
 	defaultAnalytics(c)
-}
 
-func allowInsecureConfigs(c *config.Config) {
-	if c.AllowInsecureConfigs {
-		log.WithField("config.allow_insecure_configs", true).
-			Warning("Insecure configuration allowed")
-	}
-}
+	report := Lint(c, s)
+	report.Log()
 
-func healthCheck(c *config.Config) {
-	if c.HealthCheck.EnableHealthChecks {
-		log.Warn("Health Checker is deprecated and not recommended")
-	}
-}
-
-func sessionLifetimeCheck(c *config.Config) {
-	if c.GlobalSessionLifetime <= 0 {
-		log.Warn("Tyk has not detected any setting for session lifetime (`global_session_lifetime` defaults to 0 seconds). \n" +
-			"\tThis means that in case there's also no `session_lifetime` defined in the api, Tyk will not set expiration on keys\n" +
-			"\tcreated in Redis, i.e. tokens will not get deleted from Redis and it eventually become overgrown.\n" +
-			"\tPlease refer to the following link for further guidance:\n" +
-			"\thttps://tyk.io/docs/basic-config-and-security/security/authentication-authorization/physical-token-expiry/")
-	}
+	return report
 }
 
 func fileDescriptors() {
@@ -83,18 +62,6 @@ func cpus() {
 	}
 }
 
-func defaultSecrets(c *config.Config) {
-	if c.Secret == defaultConfigs.Secret {
-		log.WithField("config.secret", defaultConfigs.Secret).
-			Warning("Default secret should be changed for production.")
-	}
-
-	if c.NodeSecret == defaultConfigs.NodeSecret {
-		log.WithField("config.node_secret", defaultConfigs.NodeSecret).
-			Warning("Default node_secret should be changed for production.")
-	}
-}
-
 func defaultAnalytics(c *config.Config) {
 	if !c.EnableAnalytics {
 		return
@@ -0,0 +1,152 @@
+package checkup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+func TestLint(t *testing.T) {
+	// valid is a configuration no rule finds issues in.
+	valid := func() config.Config {
+		return config.Config{
+			ListenPort:            8080,
+			Secret:                "secret",
+			NodeSecret:            "node-secret",
+			GlobalSessionLifetime: 3600,
+			Storage:               config.StorageOptionsConf{Type: "redis", Host: "localhost"},
+		}
+	}
+
+	hashed, notHashed := true, false
+
+	testCases := []struct {
+		name     string
+		conf     func(c *config.Config)
+		state    State
+		errors   []string
+		warnings []string
+	}{
+		{
+			name: "valid",
+			conf: func(*config.Config) {},
+		},
+		{
+			name: "default secrets",
+			conf: func(c *config.Config) {
+				c.Secret = defaultConfigs.Secret
+				c.NodeSecret = defaultConfigs.NodeSecret
+			},
+			warnings: []string{"default-secret", "default-node-secret"},
+		},
+		{
+			name: "analytics without analytics storage",
+			conf: func(c *config.Config) {
+				c.EnableAnalytics = true
+				c.EnableSeperateAnalyticsStore = true
+			},
+			errors: []string{"analytics-storage-unset"},
+		},
+		{
+			name: "analytics with analytics storage",
+			conf: func(c *config.Config) {
+				c.EnableAnalytics = true
+				c.EnableSeperateAnalyticsStore = true
+				c.AnalyticsStorage.Addrs = []string{"analytics:6379"}
+			},
+		},
+		{
+			name: "control port is listen port",
+			conf: func(c *config.Config) {
+				c.ControlAPIPort = c.ListenPort
+			},
+			warnings: []string{"control-port-is-listen-port"},
+		},
+		{
+			name: "OpenTelemetry without endpoint",
+			conf: func(c *config.Config) {
+				c.OpenTelemetry.Enabled = true
+			},
+			warnings: []string{"opentelemetry-endpoint-unset"},
+		},
+		{
+			name: "hashed keys listing without hashing",
+			conf: func(c *config.Config) {
+				c.EnableHashedKeysListing = true
+			},
+			warnings: []string{"hashed-keys-listing-without-hashing"},
+		},
+		{
+			name:     "hash keys enabled after keys exist",
+			conf:     func(c *config.Config) { c.HashKeys = true },
+			state:    State{LastHashKeys: &notHashed},
+			warnings: []string{"hash-keys-toggled"},
+		},
+		{
+			name:     "hash keys disabled after keys exist",
+			conf:     func(*config.Config) {},
+			state:    State{LastHashKeys: &hashed},
+			warnings: []string{"hash-keys-toggled"},
+		},
+		{
+			name:  "hash keys unchanged",
+			conf:  func(c *config.Config) { c.HashKeys = true },
+			state: State{LastHashKeys: &hashed},
+		},
+		{
+			name: "unsupported storage and insecure configs",
+			conf: func(c *config.Config) {
+				c.Storage.Type = "mongo"
+				c.AllowInsecureConfigs = true
+				c.HealthCheck.EnableHealthChecks = true
+				c.GlobalSessionLifetime = 0
+			},
+			errors:   []string{"storage-not-redis"},
+			warnings: []string{"insecure-configs-allowed", "health-check-deprecated", "session-lifetime-unset"},
+		},
+		{
+			name: "suppressed rules",
+			conf: func(c *config.Config) {
+				c.Storage.Type = "mongo"
+				c.Secret = defaultConfigs.Secret
+				c.OpenTelemetry.Enabled = true
+				c.SuppressedCheckupRules = []string{"storage-not-redis", "default-secret"}
+			},
+			warnings: []string{"opentelemetry-endpoint-unset"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := valid()
+			tc.conf(&conf)
+
+			report := Lint(&conf, tc.state)
+
+			assert.Equal(t, len(tc.errors) > 0, report.HasErrors())
+			assert.ElementsMatch(t, append(tc.errors, tc.warnings...), report.RuleIDs())
+			for _, issue := range report.Errors {
+				assert.Contains(t, tc.errors, issue.RuleID)
+				assert.Equal(t, SeverityError, issue.Severity)
+				assert.NotEmpty(t, issue.Message)
+			}
+			for _, issue := range report.Warnings {
+				assert.Contains(t, tc.warnings, issue.RuleID)
+				assert.Equal(t, SeverityWarning, issue.Severity)
+				assert.NotEmpty(t, issue.Message)
+			}
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	ids := map[string]bool{}
+	for _, rule := range Rules {
+		assert.NotEmpty(t, rule.ID)
+		assert.False(t, ids[rule.ID], "rule %s is declared twice", rule.ID)
+		assert.Contains(t, []Severity{SeverityWarning, SeverityError}, rule.Severity)
+		ids[rule.ID] = true
+	}
+}
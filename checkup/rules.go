@@ -0,0 +1,215 @@
+package checkup
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+// Severity is the severity of the issues found by a rule.
+type Severity string
+
+const (
+	// SeverityWarning is a configuration the gateway runs with, but likely not as intended.
+	SeverityWarning Severity = "warning"
+	// SeverityError is a configuration the gateway can't run correctly with.
+	SeverityError Severity = "error"
+)
+
+// Rule checks the configuration for a misconfiguration.
+type Rule struct {
+	// ID identifies the rule, it's listed in `suppressed_checkup_rules` to suppress the rule.
+	ID       string
+	Severity Severity
+	// Check returns the issue found in the configuration, or an empty string if there's none.
+	Check func(c *config.Config, s State) string
+}
+
+// State holds what the configuration is checked against beside itself, found in the storage of the gateway.
+type State struct {
+	// LastHashKeys is the `hash_keys` the gateway last ran with against the same Redis, nil if unknown.
+	LastHashKeys *bool
+}
+
+// Rules is the catalogue of the rules the configuration is linted with.
+var Rules = []Rule{
+	{ID: "insecure-configs-allowed", Severity: SeverityWarning, Check: insecureConfigsAllowed},
+	{ID: "health-check-deprecated", Severity: SeverityWarning, Check: healthCheckDeprecated},
+	{ID: "session-lifetime-unset", Severity: SeverityWarning, Check: sessionLifetimeUnset},
+	{ID: "default-secret", Severity: SeverityWarning, Check: defaultSecret},
+	{ID: "default-node-secret", Severity: SeverityWarning, Check: defaultNodeSecret},
+	{ID: "storage-not-redis", Severity: SeverityError, Check: storageNotRedis},
+	{ID: "analytics-storage-unset", Severity: SeverityError, Check: analyticsStorageUnset},
+	{ID: "hashed-keys-listing-without-hashing", Severity: SeverityWarning, Check: hashedKeysListingWithoutHashing},
+	{ID: "hash-keys-toggled", Severity: SeverityWarning, Check: hashKeysToggled},
+	{ID: "control-port-is-listen-port", Severity: SeverityWarning, Check: controlPortIsListenPort},
+	{ID: "opentelemetry-endpoint-unset", Severity: SeverityWarning, Check: openTelemetryEndpointUnset},
+}
+
+// Issue is an issue found in the configuration by a rule.
+type Issue struct {
+	RuleID   string   `json:"rule_id"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// Report is the result of linting the configuration, the issues grouped by severity.
+type Report struct {
+	Errors   []Issue `json:"errors"`
+	Warnings []Issue `json:"warnings"`
+}
+
+// HasErrors reports whether the configuration has errors.
+func (r Report) HasErrors() bool {
+	return len(r.Errors) > 0
+}
+
+// RuleIDs returns the IDs of the rules which found issues.
+func (r Report) RuleIDs() []string {
+	ids := make([]string, 0, len(r.Errors)+len(r.Warnings))
+	for _, issue := range append(slices.Clone(r.Errors), r.Warnings...) {
+		ids = append(ids, issue.RuleID)
+	}
+
+	return ids
+}
+
+// Log logs the issues of the report, the errors first.
+func (r Report) Log() {
+	for _, issue := range r.Errors {
+		log.WithField("rule", issue.RuleID).Error(issue.Message)
+	}
+
+	for _, issue := range r.Warnings {
+		log.WithField("rule", issue.RuleID).Warning(issue.Message)
+	}
+
+	if len(r.Errors) > 0 || len(r.Warnings) > 0 {
+		log.Infof("Configuration checkup found %d errors and %d warnings, "+
+			"the rules can be suppressed by listing their IDs in `suppressed_checkup_rules`", len(r.Errors), len(r.Warnings))
+	}
+}
+
+// Lint checks the configuration and the state with the rules not suppressed by `suppressed_checkup_rules`.
+func Lint(c *config.Config, s State) Report {
+	report := Report{Errors: []Issue{}, Warnings: []Issue{}}
+
+	for _, rule := range Rules {
+		if slices.Contains(c.SuppressedCheckupRules, rule.ID) {
+			continue
+		}
+
+		message := rule.Check(c, s)
+		if message == "" {
+			continue
+		}
+
+		issue := Issue{RuleID: rule.ID, Severity: rule.Severity, Message: message}
+		if rule.Severity == SeverityError {
+			report.Errors = append(report.Errors, issue)
+		} else {
+			report.Warnings = append(report.Warnings, issue)
+		}
+	}
+
+	return report
+}
+
+func insecureConfigsAllowed(c *config.Config, _ State) string {
+	if !c.AllowInsecureConfigs {
+		return ""
+	}
+
+	return "Insecure configuration allowed by `allow_insecure_configs`, the Dashboard messages aren't verified."
+}
+
+func healthCheckDeprecated(c *config.Config, _ State) string {
+	if !c.HealthCheck.EnableHealthChecks {
+		return ""
+	}
+
+	return "Health Checker is deprecated and not recommended, disable `health_check.enable_health_checks`."
+}
+
+func sessionLifetimeUnset(c *config.Config, _ State) string {
+	if c.GlobalSessionLifetime > 0 {
+		return ""
+	}
+
+	return "`global_session_lifetime` isn't set: unless the APIs set a `session_lifetime`, the keys never expire in Redis, " +
+		"which eventually becomes overgrown. See " +
+		"https://tyk.io/docs/basic-config-and-security/security/authentication-authorization/physical-token-expiry/"
+}
+
+func defaultSecret(c *config.Config, _ State) string {
+	if c.Secret != defaultConfigs.Secret {
+		return ""
+	}
+
+	return "The default `secret` should be changed for production."
+}
+
+func defaultNodeSecret(c *config.Config, _ State) string {
+	if c.NodeSecret != defaultConfigs.NodeSecret {
+		return ""
+	}
+
+	return "The default `node_secret` should be changed for production."
+}
+
+func storageNotRedis(c *config.Config, _ State) string {
+	if c.Storage.Type == "redis" {
+		return ""
+	}
+
+	return fmt.Sprintf("`storage.type` is %q, the gateway only supports \"redis\".", c.Storage.Type)
+}
+
+func analyticsStorageUnset(c *config.Config, _ State) string {
+	if !c.EnableAnalytics || !c.EnableSeperateAnalyticsStore || storageConfigured(c.AnalyticsStorage) {
+		return ""
+	}
+
+	return "`enable_separate_analytics_store` is set but `analytics_storage` has no Redis host, " +
+		"the analytics records can't be stored. Set `analytics_storage` or disable `enable_separate_analytics_store`."
+}
+
+func hashedKeysListingWithoutHashing(c *config.Config, _ State) string {
+	if !c.EnableHashedKeysListing || c.HashKeys {
+		return ""
+	}
+
+	return "`enable_hashed_keys_listing` has no effect without `hash_keys`."
+}
+
+func hashKeysToggled(c *config.Config, s State) string {
+	if s.LastHashKeys == nil || *s.LastHashKeys == c.HashKeys {
+		return ""
+	}
+
+	return fmt.Sprintf("`hash_keys` is %t but the gateway last ran with %t against this Redis: the keys stored before "+
+		"are looked up under the wrong name and aren't found. Revert `hash_keys`, or recreate the keys.", c.HashKeys, *s.LastHashKeys)
+}
+
+func controlPortIsListenPort(c *config.Config, _ State) string {
+	if c.ControlAPIPort == 0 || c.ControlAPIPort != c.ListenPort {
+		return ""
+	}
+
+	return fmt.Sprintf("`control_api_port` is the `listen_port` %d, the control API is served along the APIs as if it was unset. "+
+		"Set a separate `control_api_port` to isolate the control API.", c.ListenPort)
+}
+
+func openTelemetryEndpointUnset(c *config.Config, _ State) string {
+	if !c.OpenTelemetry.Enabled || c.OpenTelemetry.Endpoint != "" {
+		return ""
+	}
+
+	return "OpenTelemetry is enabled without `opentelemetry.endpoint`, the traces are exported to localhost:4317."
+}
+
+// storageConfigured reports whether a Redis address is set in the storage configuration.
+func storageConfigured(conf config.StorageOptionsConf) bool {
+	return conf.Host != "" || len(conf.Addrs) > 0 || len(conf.Hosts) > 0
+}
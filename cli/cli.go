@@ -43,6 +43,8 @@ var (
 	DebugMode *bool
 	// LogInstrumentation outputs instrumentation data to stdout.
 	LogInstrumentation *bool
+	// CheckConfig lints the configuration and exits, without starting the gateway.
+	CheckConfig *bool
 
 	// DefaultMode is set when default command is used.
 	DefaultMode bool
@@ -79,6 +81,7 @@ func setup(confPaths []string) {
 	HTTPProfile = startCmd.Flag("httpprofile", "expose runtime profiling data via HTTP").Bool()
 	DebugMode = startCmd.Flag("debug", "enable debug mode").Bool()
 	LogInstrumentation = startCmd.Flag("log-instrumentation", "output instrumentation output to stdout").Bool()
+	CheckConfig = startCmd.Flag("check-config", "lint the configuration and exit, with a non-zero status on errors").Bool()
 
 	startCmd.Action(func(ctx *kingpin.ParseContext) error {
 		DefaultMode = true
@@ -7,6 +7,8 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,6 +22,9 @@ import (
 
 type IPsHandleStrategy string
 
+// DnsResolverType is the kind of DNS resolver used to resolve upstream host names.
+type DnsResolverType string
+
 const GracefulShutdownDefaultDuration = 30
 
 var (
@@ -92,12 +97,29 @@ const (
 	RandomStrategy    IPsHandleStrategy = "random"
 	NoCacheStrategy   IPsHandleStrategy = "no_cache"
 
+	SystemResolver DnsResolverType = "system"
+	UDPResolver    DnsResolverType = "udp"
+	DoHResolver    DnsResolverType = "doh"
+	DoTResolver    DnsResolverType = "dot"
+
 	DefaultDashPolicySource     = "service"
 	DefaultDashPolicyRecordName = "tyk_policies"
 
 	DefaultOTelResourceName = "tyk-gateway"
 )
 
+// Values of the hash_key_migration option.
+const (
+	HashKeyMigrationDualRead = "dual_read"
+	HashKeyMigrationComplete = "complete"
+)
+
+// Values of the analytics_config.overflow_policy option.
+const (
+	AnalyticsOverflowBlock      = "block"
+	AnalyticsOverflowDropOldest = "drop_oldest"
+)
+
 type PolicySource string
 
 const (
@@ -133,6 +155,13 @@ type PoliciesConfig struct {
 	// from all the JSON files under the directory specified by the `policies.policy_path` option.
 	// In this configuration, Tyk Gateway will allow policy management through the Gateway API.
 	PolicyPath string `json:"policy_path"`
+
+	// PartitionConflictResolution is the rule resolving the conflicts between the policies applied to a key, when they
+	// set different limits in the same partition of an API, e.g. two policies with the rate limit partition and different
+	// rates. Each limit is resolved on its own. Set it to `most_permissive` to keep the highest limit, or to
+	// `most_restrictive` to keep the lowest one, -1 being unlimited. The conflicts are logged and recorded in the
+	// `tyk_policy_partition_conflicts` metadata of the key. Default: `most_permissive`.
+	PartitionConflictResolution string `json:"partition_conflict_resolution"`
 }
 
 type DBAppConfOptionsConfig struct {
@@ -148,6 +177,25 @@ type DBAppConfOptionsConfig struct {
 	// The tags to use when filtering (sharding) Tyk Gateway nodes. Tags are processed as `OR` operations.
 	// If you include a non-filter tag (e.g. an identifier such as `node-id-1`, this will become available to your Dashboard analytics).
 	Tags []string `json:"tags"`
+
+	// HeartBeatInterval is the interval in seconds between two heartbeats sent to the Dashboard. Default value is 2.
+	HeartBeatInterval float64 `json:"heartbeat_interval"`
+
+	// RegistrationRetryDelay is the delay in seconds before retrying a failed registration with the Dashboard.
+	// The delay doubles with each failed attempt, up to `registration_retry_max_delay`. Default value is 5.
+	RegistrationRetryDelay float64 `json:"registration_retry_delay"`
+
+	// RegistrationRetryMaxDelay is the maximum delay in seconds between two registration attempts. Default value is 60.
+	RegistrationRetryMaxDelay float64 `json:"registration_retry_max_delay"`
+
+	// Jitter randomly spreads the heartbeat interval and the registration retry delays by up to this fraction of their value,
+	// so the Gateways losing the Dashboard at the same time don't all retry at once. For example, 0.2 spreads a 5 seconds
+	// delay between 4 and 6 seconds. Set it between 0 and 1, the default value 0 disables it.
+	Jitter float64 `json:"jitter"`
+
+	// HeartBeatFailureThreshold is the number of seconds the heartbeats can fail before an error is logged and the
+	// `heartbeat_failing_seconds` gauge of the `DashboardHeartBeat` instrumentation job is reported. Default value is 30.
+	HeartBeatFailureThreshold float64 `json:"heartbeat_failure_threshold"`
 }
 
 type StorageOptionsConf struct {
@@ -224,6 +272,26 @@ type StorageOptionsConf struct {
 	// This limit prevents memory exhaustion during decompression.
 	// Defaults to 104857600 (100MB).
 	MaxDecompressedSize int64 `json:"max_decompressed_size"`
+
+	// ReadReplicas configures the Redis replicas the session lookups are read from, e.g. replicas in the region of
+	// the Gateway when the primary is in another one. Only applies to the `storage` section.
+	ReadReplicas ReadReplicasConf `json:"read_replicas"`
+}
+
+// ReadReplicasConf configures the Redis replicas the session lookups are read from. The sessions are read from the
+// healthy replica with the lowest latency and from the primary when no replica is healthy, or when a session isn't
+// found on the replica as it may not be replicated yet. Sessions read from a replica can be stale by the replication
+// lag. All the writes, the rate limit and the quota counters stay on the primary.
+type ReadReplicasConf struct {
+	// Enabled turns on the reads from the replicas. Default: false.
+	Enabled bool `json:"enabled"`
+
+	// Addrs lists the addresses of the replicas, for example: ["replica1:6379", "replica2:6379"]. Each replica is
+	// connected to as a standalone Redis node, with the credentials and the TLS settings of the storage.
+	Addrs []string `json:"addrs"`
+
+	// HealthCheckInterval is how often in seconds the availability and the latency of the replicas are checked. Default: 5.
+	HealthCheckInterval int `json:"health_check_interval"`
 }
 
 type NormalisedURLConfig struct {
@@ -278,6 +346,11 @@ type AnalyticsConfigConfig struct {
 	// This setting can be overridden with an organization flag, enabed at an API level, or on individual Key level.
 	EnableDetailedRecording bool `json:"enable_detailed_recording"`
 
+	// DetailedRecording limits the data stored by detailed recording. Bodies larger than `max_body_size` bytes are truncated,
+	// the values of the headers listed in `redact_headers` and of the JSON body fields listed in `redact_fields` are replaced with `***`.
+	// The body limit of an API takes precedence over this one, the redaction lists of an API extend these.
+	DetailedRecording apidef.DetailedRecordingConfig `json:"detailed_recording"`
+
 	// Tyk can store GeoIP information based on MaxMind DB’s to enable GeoIP tracking on inbound request analytics. Set this value to `true` and assign a DB using the `geo_ip_db_path` setting.
 	EnableGeoIP bool `json:"enable_geo_ip"`
 
@@ -294,6 +367,18 @@ type AnalyticsConfigConfig struct {
 	// Number of records in analytics queue, per worker. Default: 1000.
 	RecordsBufferSize uint64 `json:"records_buffer_size"`
 
+	// Number of records a worker writes to Redis in a single pipelined batch.
+	// Defaults to `records_buffer_size` divided by `pool_size`.
+	RecordsBatchSize uint64 `json:"records_batch_size"`
+
+	// Interval in milliseconds after which a worker writes its buffered records to Redis, even if the batch isn't full. Default: 200.
+	RecordsFlushInterval int `json:"records_flush_interval"`
+
+	// Policy applied when the analytics queue is full. Available options:
+	// - `block`, the default, waits for the workers to free a slot in the queue, holding up the request.
+	// - `drop_oldest` drops the oldest queued record to make room for the new one. Dropped records are counted.
+	OverflowPolicy string `json:"overflow_policy"`
+
 	// You can set a time (in seconds) to configure how long analytics are kept if they are not processed. The default is 60 seconds.
 	// This is used to prevent the potential infinite growth of Redis analytics storage.
 	StorageExpirationTime int `json:"storage_expiration_time"`
@@ -357,6 +442,43 @@ type AccessLogsConfig struct {
 	Template []string `json:"template"`
 }
 
+// ControlAPIAuditLogConfig configures the audit log of the calls made to the Control API.
+type ControlAPIAuditLogConfig struct {
+	// Enabled turns on the audit log of the Control API. Default: false.
+	Enabled bool `json:"enabled"`
+
+	// Path of the file the audit entries are written to, one JSON object per line.
+	// If not set, the entries are written to stdout.
+	Path string `json:"path"`
+
+	// MaxSize is the size in megabytes the audit log file can reach before it gets rotated. Default: 100.
+	MaxSize int `json:"max_size"`
+
+	// MaxBackups is the number of rotated audit log files to keep. If not set, all of them are kept.
+	MaxBackups int `json:"max_backups"`
+
+	// IncludeReadOnly adds the read-only calls (GET, HEAD and OPTIONS) to the audit log.
+	// By default only the calls which can change the state of the Gateway are recorded.
+	IncludeReadOnly bool `json:"include_read_only"`
+
+	// EnableRedis also pushes the audit entries to the `tyk-control-api-audit` Redis list,
+	// so they can be collected centrally, e.g. in MDCB setups.
+	EnableRedis bool `json:"enable_redis"`
+
+	// RedisMaxEntries is the number of entries kept in the Redis list, the oldest ones are
+	// removed first. Default: 10000.
+	RedisMaxEntries int `json:"redis_max_entries"`
+}
+
+// RequestIDConfig configures the correlation ID of the requests.
+type RequestIDConfig struct {
+	// Enabled turns on the request ID for every API. Default: false.
+	Enabled bool `json:"enabled"`
+
+	// HeaderName is the header carrying the request ID. Default: X-Request-ID.
+	HeaderName string `json:"header_name"`
+}
+
 type HealthCheckConfig struct {
 	// Setting this value to `true` will enable the health-check endpoint on /Tyk/health.
 	EnableHealthChecks bool `json:"enable_health_checks"`
@@ -372,6 +494,14 @@ type LivenessCheckConfig struct {
 	// Expressed in Nanoseconds. For example: 1000000000 -> 1s.
 	// Default: 10 seconds.
 	CheckDuration time.Duration `json:"check_duration"`
+
+	// Maximum time a single check of Redis, Dashboard, or RPC layer may take before the component is reported as failed.
+	// Expressed in Nanoseconds. Defaults to, and is capped by, `check_duration`.
+	Timeout time.Duration `json:"timeout"`
+
+	// Number of failed components from which the `/hello` endpoint responds with a 503 instead of a 200.
+	// Default: 0, the `/hello` endpoint always responds with a 200.
+	FailureThreshold int `json:"failure_threshold"`
 }
 
 type DnsCacheConfig struct {
@@ -394,12 +524,39 @@ type DnsCacheConfig struct {
 	MultipleIPsHandleStrategy IPsHandleStrategy `json:"multiple_ips_handle_strategy"`
 }
 
+type DnsResolverConfig struct {
+	// The resolver used for the upstream host names:
+	//
+	// * `system` resolves with the host's configuration, e.g. `/etc/resolv.conf`. This is the default.
+	// * `udp` queries the DNS servers listed in `udp_servers`.
+	// * `doh` queries the DNS-over-HTTPS endpoint set in `doh_url`.
+	// * `dot` queries the DNS-over-TLS server set in `dot_host`.
+	Type DnsResolverType `json:"type"`
+
+	// The resolvers tried in order when the resolver set in `type` fails, e.g. `["udp", "system"]`.
+	Fallback []DnsResolverType `json:"fallback"`
+
+	// The DNS servers queried by the `udp` resolver, as `host:port`. The port defaults to 53.
+	UDPServers []string `json:"udp_servers"`
+
+	// The URL of the DNS-over-HTTPS endpoint queried by the `doh` resolver, e.g. `https://resolver.internal/dns-query`.
+	DoHURL string `json:"doh_url"`
+
+	// The DNS-over-TLS server queried by the `dot` resolver, as `host:port`. The port defaults to 853.
+	DoTHost string `json:"dot_host"`
+
+	// The timeout in seconds of a single resolver, before falling back to the next one. Defaults to 5 seconds.
+	Timeout float64 `json:"timeout"`
+}
+
 type MonitorConfig struct {
 	// Set this to `true` to have monitors enabled in your configuration for the node.
 	EnableTriggerMonitors bool               `json:"enable_trigger_monitors"`
 	Config                WebHookHandlerConf `json:"configuration"`
 	// The trigger limit, as a percentage of the quota that must be reached in order to trigger the event, any time the quota percentage is increased the event will trigger.
 	GlobalTriggerLimit float64 `json:"global_trigger_limit"`
+	// A list of trigger limits, as percentages of the quota. Each of them fires the event once per key and quota period, in addition to `global_trigger_limit`.
+	TriggerLimits []float64 `json:"trigger_limits"`
 	// Apply the monitoring subsystem to user keys.
 	MonitorUserKeys bool `json:"monitor_user_keys"`
 	// Apply the monitoring subsystem to organization keys.
@@ -509,6 +666,15 @@ type SlaveOptionsConfig struct {
 	// Note: Certificates accumulate over time as they are used; they are not removed when APIs are deleted.
 	// Reduces memory usage and log noise in segmented deployments.
 	SyncUsedCertsOnly bool `json:"sync_used_certs_only"`
+
+	// EnableKeyBackup stores an encrypted copy of every key fetched from MDCB in the local Redis.
+	// While the gateway is in emergency mode, keys are served from this backup instead of being rejected,
+	// quota updates are buffered and replayed, and the served keys are re-validated once MDCB is back.
+	EnableKeyBackup bool `json:"enable_key_backup"`
+
+	// KeyBackupMaxAge is the maximum age, in seconds, of a key backup that can still be served
+	// during an outage. Defaults to 3600.
+	KeyBackupMaxAge int64 `json:"key_backup_max_age"`
 }
 
 type LocalSessionCacheConf struct {
@@ -549,6 +715,10 @@ type HttpServerOptionsConfig struct {
 	// Enable HTTP2 protocol handling
 	EnableHttp2 bool `json:"enable_http2"`
 
+	// EnableHTTP3 is an experimental setting serving HTTP/3 over QUIC on the UDP port matching each HTTPS listener.
+	// It requires `use_ssl`. The HTTP/3 listener is advertised to clients with the `Alt-Svc` header of HTTP/1.1 and HTTP/2 responses.
+	EnableHTTP3 bool `json:"enable_http3"`
+
 	// EnableStrictRoutes changes the routing to avoid nearest-neighbour requests on overlapping routes
 	//
 	// - if disabled, `/apple` will route to `/app`, the current default behavior,
@@ -631,6 +801,12 @@ type HttpServerOptionsConfig struct {
 	// This option needed be set for streaming protocols like Server Side Events, or gRPC streaming.
 	FlushInterval int `json:"flush_interval"`
 
+	// SSEKeepaliveInterval sets the number of seconds after which Tyk writes an SSE comment (`: ping`) to a Server Sent Events
+	// stream when the upstream hasn't sent anything. This keeps idle streams from being dropped by load balancers sitting between
+	// the client and the Gateway. The keepalive is only written between events and stops as soon as the stream ends.
+	// A value of zero (default) disables keepalives.
+	SSEKeepaliveInterval int `json:"sse_keepalive_interval"`
+
 	// Allow the use of a double slash in a URL path. This can be useful if you need to pass raw URLs to your API endpoints.
 	// For example: `http://myapi.com/get/http://example.com`.
 	SkipURLCleaning bool `json:"skip_url_cleaning"`
@@ -641,6 +817,11 @@ type HttpServerOptionsConfig struct {
 	// Custom SSL ciphers applicable when using TLS version 1.2. See the list of ciphers here https://tyk.io/docs/api-management/certificates#supported-tls-cipher-suites
 	Ciphers []string `json:"ssl_ciphers"`
 
+	// PortTLS overrides the TLS settings above for the HTTPS and TLS listeners on some ports, for example to serve
+	// legacy TLS 1.0 clients of a single API on its own port while the other ports require TLS 1.2.
+	// A port can only be selected by entries with the same settings, the Gateway doesn't start otherwise.
+	PortTLS PortTLSConfigs `json:"port_tls"`
+
 	// MaxRequestBodySize configures a maximum size limit for request body size (in bytes) for all APIs on the Gateway.
 	//
 	// Tyk Gateway will evaluate all API requests against this size limit and will respond with HTTP 413 status code if the body of the request is larger.
@@ -663,12 +844,37 @@ type HttpServerOptionsConfig struct {
 	// A value of 1 means using the last IP, 2 means second to last, and so on.
 	XFFDepth int `json:"xff_depth"`
 
+	// TrustedProxies is a list of CIDR ranges or IP addresses of the proxies in front of the Gateway.
+	// When set, the X-Real-IP and X-Forwarded-For headers are only honoured for requests coming from a trusted proxy,
+	// and the client IP is the first address of the X-Forwarded-For chain, read from the right, that isn't a trusted proxy.
+	// This takes precedence over XFFDepth. Requests from other addresses use their connection address as client IP.
+	// When empty (default) the forwarded headers are always honoured.
+	TrustedProxies []string `json:"trusted_proxies"`
+
 	// MaxResponseBodySize sets an upper limit on the response body (payload) size in bytes. It defaults to 0, which means there is no restriction on the response body size.
 	//
 	// The Gateway will return `HTTP 500 Response Body Too Large` if the response payload exceeds MaxResponseBodySize+1 bytes.
 	//
 	// **Note:** The limit is applied only when the [Response Body Transform middleware](/api-management/traffic-transformation/response-body) is enabled.
 	MaxResponseBodySize int64 `json:"max_response_body_size"`
+
+	// TransformJQTimeout sets the maximum time in milliseconds a JQ body transform can run for a request or response.
+	// It defaults to 0, which means the default of 1000 milliseconds is used.
+	//
+	// The Gateway will return `HTTP 500` if a transform runs out of time.
+	TransformJQTimeout int64 `json:"transform_jq_timeout"`
+
+	// TransformJQMaxOutputSize sets an upper limit in bytes on the body produced by a JQ body transform.
+	// It defaults to 0, which means the default of 10MB is used.
+	//
+	// The Gateway will return `HTTP 500` if the transformed body exceeds the limit.
+	TransformJQMaxOutputSize int64 `json:"transform_jq_max_output_size"`
+
+	// TransformJQMaxInputSize sets an upper limit in bytes on the body a JQ body transform reads.
+	// It defaults to 0, which means the default of 10MB is used.
+	//
+	// The Gateway will return `HTTP 400` for requests and `HTTP 500` for responses with a larger body.
+	TransformJQMaxInputSize int64 `json:"transform_jq_max_input_size"`
 }
 
 type AuthOverrideConf struct {
@@ -701,6 +907,35 @@ type ServiceDiscoveryConf struct {
 	DefaultCacheTimeout int `json:"default_cache_timeout"`
 }
 
+// JSVMHTTPConfig controls the `TykHttpRequest` JSVM function. Unlike `TykMakeHttpRequest`, calls are
+// only made to allowed hosts, are bounded in time and size, and are counted against a budget per plugin run.
+type JSVMHTTPConfig struct {
+	// AllowedHosts lists the hosts plugins may call, as `host` or `host:port`. A leading `*.` matches any subdomain.
+	// No calls are made when the list is empty.
+	AllowedHosts []string `json:"allowed_hosts"`
+
+	// Timeout is the maximum duration of a call in seconds. A plugin may ask for a shorter timeout per call.
+	// Defaults to 5 seconds.
+	Timeout float64 `json:"timeout"`
+
+	// MaxResponseSize is the maximum size of a response body in bytes. Larger responses fail the call.
+	// Defaults to 1MB.
+	MaxResponseSize int64 `json:"max_response_size"`
+
+	// MaxRequests is the number of calls a single plugin run may make. Defaults to 5.
+	MaxRequests int `json:"max_requests"`
+}
+
+// CacheInvalidationConfig limits the keys checked when invalidating cached responses by path pattern.
+type CacheInvalidationConfig struct {
+	// ScanCount is the number of keys Redis checks per SCAN iteration. Defaults to 100.
+	ScanCount int64 `json:"scan_count"`
+
+	// ScanLimit is the number of keys checked before an invalidation stops, leaving the remaining
+	// cached responses in place. Defaults to 10000.
+	ScanLimit int64 `json:"scan_limit"`
+}
+
 type CoProcessConfig struct {
 	// Enable gRPC and Python plugins
 	EnableCoProcess bool `json:"enable_coprocess"`
@@ -714,12 +949,29 @@ type CoProcessConfig struct {
 	// Maximum message which can be sent to gRPC server
 	GRPCSendMaxSize int `json:"grpc_send_max_size"`
 
+	// GRPCStreamChunkSize is the size in bytes of the body chunks exchanged with the gRPC server by the plugins
+	// streaming their bodies, see `stream_body`. Streamed bodies aren't limited by the max message sizes. Defaults to 1MB.
+	GRPCStreamChunkSize int `json:"grpc_stream_chunk_size"`
+
 	// Authority used in GRPC connection
 	GRPCAuthority string `json:"grpc_authority"`
 
 	// GRPCRoundRobinLoadBalancing enables round robin load balancing for gRPC services; you must provide the address of the load balanced service using `dns:///` protocol in `coprocess_grpc_server`.
 	GRPCRoundRobinLoadBalancing bool `json:"grpc_round_robin_load_balancing"`
 
+	// GRPCReconnectBaseDelay is the delay in seconds before reconnecting to the gRPC server once the connection is lost.
+	// The delay grows exponentially with each failed attempt, up to `grpc_reconnect_max_delay`. Defaults to 1 second.
+	GRPCReconnectBaseDelay float64 `json:"grpc_reconnect_base_delay"`
+
+	// GRPCReconnectMaxDelay is the maximum delay in seconds between attempts to reconnect to the gRPC server.
+	// Defaults to 120 seconds.
+	GRPCReconnectMaxDelay float64 `json:"grpc_reconnect_max_delay"`
+
+	// GRPCHealthCheckInterval is the interval in seconds of the keepalive pings checking the connection to the gRPC server.
+	// A connection not answering a ping within the interval is considered lost and reconnected. The gRPC server must
+	// permit pings at this interval. Health checks are disabled by default.
+	GRPCHealthCheckInterval float64 `json:"grpc_health_check_interval"`
+
 	// Sets the path to built-in Tyk modules. This will be part of the Python module lookup path. The value used here is the default one for most installations.
 	PythonPathPrefix string `json:"python_path_prefix"`
 
@@ -776,6 +1028,37 @@ type SecurityConfig struct {
 
 	// CertificateExpiryMonitor configures the certificate expiry monitoring and notification feature
 	CertificateExpiryMonitor CertificateExpiryMonitorConfig `json:"certificate_expiry_monitor"`
+
+	// ControlAPIAuthLimit configures the lockout of the source IPs failing to authenticate to the Control API,
+	// to protect the secret against brute-force attacks.
+	ControlAPIAuthLimit ControlAPIAuthLimitConfig `json:"control_api_auth_limit"`
+
+	// EnableOrgScopedAdminTokens allows the tenant admins to manage the keys of their organisation through
+	// the `/tyk/keys` endpoints without the gateway secret. The caller sends the token configured as
+	// `org_admin_token_hash` (its SHA-256 hex digest) on the org session in the `X-Tyk-Authorization` header
+	// and the organisation in the `X-Tyk-Org-Id` header. Scoped tokens can only list, read, create, update
+	// and delete the keys of their organisation. Default: false.
+	EnableOrgScopedAdminTokens bool `json:"enable_org_scoped_admin_tokens"`
+}
+
+// ControlAPIAuthLimitConfig configures the lockout of the source IPs failing to authenticate to the Control API.
+// A source IP reaching MaxFailures failures within Window is answered with `429 Too Many Requests` for
+// LockoutDuration, and the ControlAPIAuthLockout event is fired.
+//
+// Requests with the right secret are rejected too while the source IP is locked out, as letting them through
+// would reveal which guess of the secret is right. Changing these settings on reload clears the lockouts.
+type ControlAPIAuthLimitConfig struct {
+	// Enabled turns on the lockout of the source IPs failing to authenticate. Default: false.
+	Enabled bool `json:"enabled"`
+
+	// MaxFailures is the number of authentication failures allowed from a source IP within Window. Default: 10.
+	MaxFailures int `json:"max_failures"`
+
+	// Window is the period in seconds the authentication failures are counted over. Default: 60.
+	Window int `json:"window"`
+
+	// LockoutDuration is the number of seconds a source IP is locked out for. Default: 300.
+	LockoutDuration int `json:"lockout_duration"`
 }
 
 type JWKSConfig struct {
@@ -836,6 +1119,31 @@ func (p PortWhiteList) Match(port int) bool {
 	return false
 }
 
+// Overlap returns a port selected by both p and other, if any.
+func (p PortWhiteList) Overlap(other PortWhiteList) (int, bool) {
+	for _, port := range p.Ports {
+		if other.Match(port) {
+			return port, true
+		}
+	}
+
+	for _, port := range other.Ports {
+		if p.Match(port) {
+			return port, true
+		}
+	}
+
+	for _, r := range p.Ranges {
+		for _, o := range other.Ranges {
+			if from := max(r.From, o.From); from <= min(r.To, o.To) {
+				return from, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
 // PortRange defines a range of ports inclusively.
 type PortRange struct {
 	From int `json:"from"`
@@ -859,6 +1167,154 @@ func (pwl *PortsWhiteList) Decode(value string) error {
 	return nil
 }
 
+// Client certificate policies of a PortTLSConfig.
+const (
+	// PortClientAuthNone doesn't request a client certificate.
+	PortClientAuthNone = "none"
+	// PortClientAuthRequest requests a client certificate without requiring it.
+	PortClientAuthRequest = "request"
+	// PortClientAuthRequire requires a client certificate signed by one of the client certificates of the port.
+	PortClientAuthRequire = "require"
+)
+
+// PortTLSConfig holds the TLS settings of the listeners on the ports it selects.
+// The settings which aren't set are inherited from HttpServerOptions.
+type PortTLSConfig struct {
+	// Ports and Ranges select the ports the settings apply to.
+	PortWhiteList
+
+	// SSLCertificates replaces the `ssl_certificates` served on the ports, with certificate IDs or paths.
+	// API specific certificates are still served.
+	SSLCertificates []string `json:"ssl_certificates"`
+
+	// MinVersion is the minimum TLS version accepted on the ports.
+	MinVersion uint16 `json:"min_version"`
+
+	// MaxVersion is the maximum TLS version accepted on the ports.
+	MaxVersion uint16 `json:"max_version"`
+
+	// Ciphers is the list of TLS 1.2 and lower cipher suites accepted on the ports.
+	Ciphers []string `json:"ssl_ciphers"`
+
+	// ClientAuth sets the client certificate policy of the ports: "none", "request" or "require".
+	// When empty (default) the policy depends on the mutual TLS settings of the APIs listening on the port.
+	ClientAuth string `json:"client_auth"`
+
+	// ClientCertificates is the list of certificate IDs of the CAs client certificates are verified with
+	// when ClientAuth is "require".
+	ClientCertificates []string `json:"client_certificates"`
+}
+
+// PortTLSConfigs is a list of per port TLS settings.
+type PortTLSConfigs []PortTLSConfig
+
+func (p *PortTLSConfigs) Decode(value string) error {
+	err := json.Unmarshal([]byte(value), p)
+	if err != nil {
+		log.Error("Error unmarshalling TYK_GW_HTTPSERVEROPTIONS_PORTTLS: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// For returns the TLS settings of port, if any entry selects it.
+func (p PortTLSConfigs) For(port int) (PortTLSConfig, bool) {
+	for _, conf := range p {
+		if conf.Match(port) {
+			return conf, true
+		}
+	}
+
+	return PortTLSConfig{}, false
+}
+
+// Validate returns an error if a client certificate policy is unknown, or if a port is selected
+// by entries with different settings.
+func (p PortTLSConfigs) Validate() error {
+	for i, conf := range p {
+		switch conf.ClientAuth {
+		case "", PortClientAuthNone, PortClientAuthRequest:
+		case PortClientAuthRequire:
+			if len(conf.ClientCertificates) == 0 {
+				return fmt.Errorf("port_tls[%d]: client_auth %q requires client_certificates", i, conf.ClientAuth)
+			}
+		default:
+			return fmt.Errorf("port_tls[%d]: unknown client_auth %q", i, conf.ClientAuth)
+		}
+
+		for j := 0; j < i; j++ {
+			port, overlap := p[j].PortWhiteList.Overlap(conf.PortWhiteList)
+			if overlap && !conf.sameSettings(p[j]) {
+				return fmt.Errorf("port_tls[%d] and port_tls[%d] set different TLS settings for port %d", j, i, port)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c PortTLSConfig) sameSettings(other PortTLSConfig) bool {
+	c.PortWhiteList, other.PortWhiteList = PortWhiteList{}, PortWhiteList{}
+	return reflect.DeepEqual(c, other)
+}
+
+// TCPProxyConfig configures the proxies serving TCP and TLS passthrough APIs.
+type TCPProxyConfig struct {
+	// MaxConnections limits the number of concurrent connections accepted on every TCP proxy port.
+	// New connections above the limit are rejected. Defaults to 0, which means no limit.
+	MaxConnections int `json:"max_connections"`
+
+	// PortMaxConnections overrides MaxConnections for specific ports, keyed by port number, e.g. `{"6000": 100}`.
+	PortMaxConnections map[string]int `json:"port_max_connections"`
+}
+
+// MaxConnectionsForPort returns the connection limit of the TCP proxy listening on port.
+func (c TCPProxyConfig) MaxConnectionsForPort(port int) int {
+	if limit, ok := c.PortMaxConnections[strconv.Itoa(port)]; ok {
+		return limit
+	}
+	return c.MaxConnections
+}
+
+// AdmissionControlConfig configures the Gateway wide admission controller. It bounds the number of requests
+// proxied at once, so requests queue up to a limit instead of piling up when the upstreams slow down.
+// WebSocket and other streaming requests aren't counted.
+type AdmissionControlConfig struct {
+	// Enabled enables the admission controller.
+	Enabled bool `json:"enabled"`
+
+	// MaxConcurrentRequests is the number of requests proxied at once, the others wait in the queue for a slot.
+	MaxConcurrentRequests int `json:"max_concurrent_requests"`
+
+	// QueueSize is the number of requests waiting for a slot. The requests above it are rejected
+	// with a 503 and a `Retry-After` header. Default: 0, the requests are rejected as soon as all the slots are taken.
+	QueueSize int `json:"queue_size"`
+
+	// MaxWait is the time a request waits in the queue before it's rejected with a 503.
+	// Expressed in Nanoseconds. Default: 1 second.
+	MaxWait time.Duration `json:"max_wait"`
+
+	// MaxAPIShare is the percentage of the slots and of the queue a single API can take, so a slow API
+	// can't hold up the requests of the others. Default: 100, a single API can take the whole capacity.
+	MaxAPIShare int `json:"max_api_share"`
+
+	// RetryAfter is the number of seconds sent in the `Retry-After` header of the rejected requests. Default: 1.
+	RetryAfter int `json:"retry_after"`
+}
+
+// GeoRestrictionConfig configures the MaxMind database used by the APIs allowing or blocking requests by country.
+type GeoRestrictionConfig struct {
+	// DBPath is the path to a MaxMind database holding the country of the IPs, such as GeoLite2-Country or GeoIP2-City.
+	// Default: the `analytics_config.geo_ip_db_path` database. The file is reopened on reload when it changed on disk.
+	DBPath string `json:"db_path"`
+
+	// MaxDBAge is the number of days after the build date of the database it's considered expired.
+	// An expired database is handled like a missing one, following the `fail_open` setting of the API.
+	// Default: 0, the age of the database isn't checked.
+	MaxDBAge int `json:"max_db_age"`
+}
+
 // StreamingConfig holds the configuration for Tyk Streaming functionalities
 type StreamingConfig struct {
 	// This flag enables the Tyk Streaming feature.
@@ -901,6 +1357,10 @@ type Config struct {
 	// Can be set to disable Dashboard message signature verification. When set to `true`, `public_key_path` can be ignored.
 	AllowInsecureConfigs bool `json:"allow_insecure_configs"`
 
+	// The IDs of the configuration checkup rules to suppress. The checkup lints the configuration at startup,
+	// logging the issues found with the ID of the rule which found them. Its report is exposed at `/tyk/debug/checkup`.
+	SuppressedCheckupRules []string `json:"suppressed_checkup_rules"`
+
 	// While communicating with the Dashboard. By default, all messages are signed by a private/public key pair. Set path to public key.
 	PublicKeyPath string `json:"public_key_path"`
 
@@ -949,6 +1409,16 @@ type Config struct {
 	// Specify your previous key hashing algorithm if you migrated from one algorithm to another.
 	HashKeyFunctionFallback []string `json:"hash_key_function_fallback"`
 
+	// Migrates the stored key hashes to `hash_key_function`, including the hashes of the keys issued
+	// with another algorithm and of the legacy keys, which are otherwise hashed with murmur32. Possible values:
+	//   - "" (default): a key is hashed with the algorithm it was issued with.
+	//   - "dual_read": keys are stored under their `hash_key_function` hash. Lookups try it first and fall back
+	//     to the hash of the algorithm the key was issued with, so the keys stored before the migration keep working.
+	//     A key is moved to its new hash the next time it's saved.
+	//   - "complete": keys are only stored and looked up under their `hash_key_function` hash.
+	// Requires `hash_keys` and `hash_key_function` to be set.
+	HashKeyMigration string `json:"hash_key_migration"`
+
 	// Allows the listing of hashed API keys
 	EnableHashedKeysListing bool `json:"enable_hashed_keys_listing"`
 
@@ -973,6 +1443,15 @@ type Config struct {
 	// Disable port whilisting, essentially allowing you to use any port for your API.
 	DisablePortWhiteList bool `json:"disable_ports_whitelist"`
 
+	// Configures connection limits of TCP and TLS passthrough APIs.
+	TCPProxy TCPProxyConfig `json:"tcp_proxy"`
+
+	// Configures the admission controller bounding the requests proxied at once by the Gateway.
+	AdmissionControl AdmissionControlConfig `json:"admission_control"`
+
+	// Configures the MaxMind database of the APIs restricting access by country.
+	GeoRestriction GeoRestrictionConfig `json:"geo_restriction"`
+
 	// If Tyk is being used in its standard configuration (Open Source installations), then API definitions are stored in the apps folder (by default in /opt/tyk-gateway/apps).
 	// This location is scanned for .json files and re-scanned at startup or reload.
 	// See the API section of the Tyk Gateway API for more details.
@@ -1065,6 +1544,11 @@ type Config struct {
 	// Allows you to use custom domains
 	EnableCustomDomains bool `json:"enable_custom_domains"`
 
+	// If RejectDuplicateListenPaths is set to true, APIs declaring the same listen path on the same domain
+	// aren't loaded, except the one with the highest `proxy.listen_path_priority`, then the lowest API ID.
+	// By default the listen path of the duplicates is suffixed with their API ID so they can still be loaded.
+	RejectDuplicateListenPaths bool `json:"reject_duplicate_listen_paths"`
+
 	// If AllowMasterKeys is set to true, session objects (key definitions) that do not have explicit access rights set
 	// will be allowed by Tyk. This means that keys that are created have access to ALL APIs, which in many cases is
 	// unwanted behavior unless you are sure about what you are doing.
@@ -1078,6 +1562,11 @@ type Config struct {
 	// Enable HTTP2 support between Tyk and your upstream service. Required for gRPC.
 	ProxyEnableHttp2 bool `json:"proxy_enable_http2"`
 
+	// Number of HTTP/2 connections opened to each h2c upstream target, e.g. a gRPC service without TLS.
+	// The requests are spread across the connections of a target instead of being multiplexed on a single one.
+	// Defaults to 1.
+	ProxyH2CConnectionsPerTarget int `json:"proxy_h2c_connections_per_target"`
+
 	// Minimum TLS version for connection between Tyk and your upstream service.
 	ProxySSLMinVersion uint16 `json:"proxy_ssl_min_version"`
 
@@ -1087,6 +1576,10 @@ type Config struct {
 	// Allow list of ciphers for connection between Tyk and your upstream service.
 	ProxySSLCipherSuites []string `json:"proxy_ssl_ciphers"`
 
+	// Maximum number of batch request items executed concurrently for APIs with batch request support enabled.
+	// It can be overridden per API with `batch_request_max_parallelism`. Defaults to 10.
+	BatchRequestMaxParallelism int `json:"batch_request_max_parallelism"`
+
 	// This can specify a default timeout in seconds for upstream API requests.
 	// Default: 30 seconds
 	//
@@ -1102,6 +1595,17 @@ type Config struct {
 	// This can cause a file-handler limit to be exceeded. Setting to false can have performance benefits as the connection can be reused.
 	ProxyCloseConnections bool `json:"proxy_close_connections"`
 
+	// Maximum number of internal hops a single request can make through `tyk://` URL rewrites, internal API targets
+	// and internal data sources before it's rejected with `508 Loop Detected`. The response and the analytics record list the visited APIs.
+	// It can be overridden per request with the `loop_limit` query parameter of a looping URL rewrite. Defaults to 5.
+	MaxLoopDepth int `json:"max_loop_depth"`
+
+	// RequestID configures the correlation ID of the requests. The ID of the incoming request is reused,
+	// otherwise a UUID is generated. It's sent to the upstream, returned to the client, recorded in analytics
+	// and available as the `request_id` context variable and in error templates.
+	// APIs can enable it and override the header name with their own `request_id` settings.
+	RequestID RequestIDConfig `json:"request_id"`
+
 	// Tyk nodes can provide uptime awareness, uptime testing and analytics for your underlying APIs uptime and availability.
 	// Tyk can also notify you when a service goes down.
 	UptimeTests UptimeTestsConfig `json:"uptime_tests"`
@@ -1173,6 +1677,19 @@ type Config struct {
 	// ```
 	DnsCache DnsCacheConfig `json:"dns_cache"`
 
+	// This section configures the DNS resolver used for the API upstream host names, by the DNS cache and by the
+	// proxy when the cache is disabled. Name resolution can go over DNS-over-HTTPS or DNS-over-TLS to an internal resolver.
+	//
+	// ```
+	// "dns_resolver": {
+	//   "type": "doh",
+	//   "doh_url": "https://resolver.internal/dns-query",
+	//   "fallback": ["system"],
+	//   "timeout": 2
+	// }
+	// ```
+	DnsResolver DnsResolverConfig `json:"dns_resolver"`
+
 	// If set to `true` this allows you to disable the regular expression cache. The default setting is `false`.
 	DisableRegexpCache bool `json:"disable_regexp_cache"`
 
@@ -1205,6 +1722,9 @@ type Config struct {
 	EnableSeperateCacheStore bool               `json:"enable_separate_cache_store"`
 	CacheStorage             StorageOptionsConf `json:"cache_storage"`
 
+	// Limits the Redis work of invalidating cached responses by path pattern with `DELETE /tyk/cache/{apiID}?pattern=`.
+	CacheInvalidation CacheInvalidationConfig `json:"cache_invalidation"`
+
 	// Enable downloading Plugin bundles
 	// Example:
 	// ```
@@ -1233,6 +1753,9 @@ type Config struct {
 	// Set the execution timeout for JSVM plugins and virtal endpoints
 	JSVMTimeout int `json:"jsvm_timeout"`
 
+	// Controls the outbound HTTP calls JSVM plugins and virtual endpoints make with `TykHttpRequest`.
+	JSVMHTTP JSVMHTTPConfig `json:"jsvm_http"`
+
 	// Disable virtual endpoints and the code will not be loaded into the VM when the API definition initialises.
 	// This is useful for systems where you want to avoid having third-party code run.
 	DisableVirtualPathBlobs bool `json:"disable_virtual_path_blobs"`
@@ -1272,6 +1795,11 @@ type Config struct {
 	// If not configured, the access log is disabled.
 	AccessLogs AccessLogsConfig `json:"access_logs"`
 
+	// ControlAPIAuditLog configures the audit log of the Control API. Every entry records who made the call
+	// (a hash of the secret or the fingerprint of the client certificate), the method, path, a SHA256 hash of
+	// the request body and the response status. Entries are chained by hash, so a removed or altered entry can be detected.
+	ControlAPIAuditLog ControlAPIAuditLogConfig `json:"control_api_audit_log"`
+
 	// Section for configuring OpenTracing support
 	// Deprecated: use OpenTelemetry instead.
 	Tracer Tracer `json:"tracing"`
@@ -1343,6 +1871,10 @@ type Config struct {
 	SessionLifetimeRespectsKeyExpiration bool `bson:"session_lifetime_respects_key_expiration" json:"session_lifetime_respects_key_expiration"`
 	// global session lifetime, in seconds.
 	GlobalSessionLifetime int64 `bson:"global_session_lifetime" json:"global_session_lifetime"`
+	// SessionLastSeenInterval is the minimum number of seconds between two updates of the last seen time of the keys
+	// with an idle timeout, limiting the Redis writes of busy keys. It's capped to half of the idle timeout of a key.
+	// Default: 60 seconds.
+	SessionLastSeenInterval int64 `bson:"session_last_seen_interval" json:"session_last_seen_interval"`
 
 	// This section enables the use of the KV capabilities to substitute configuration values.
 	// See more details https://tyk.io/docs/tyk-self-managed/#store-configuration-with-key-value-store
@@ -1350,6 +1882,8 @@ type Config struct {
 		Consul ConsulConfig `json:"consul"`
 		Vault  VaultConfig  `json:"vault"`
 		File   FileConfig   `json:"file"`
+		// Watch refreshes the Consul and Vault secrets of the Gateway configuration without a restart.
+		Watch KVWatchConfig `json:"watch"`
 	} `json:"kv"`
 
 	// Secrets configures a list of key/value pairs for the gateway.
@@ -1478,6 +2012,17 @@ type TykError struct {
 	Code    int    `json:"code"`
 }
 
+// KVWatchConfig configures the polling of the Gateway configuration secrets stored in Consul or Vault.
+// When a value changes, the components using it are updated in place: Redis reconnects with the new
+// password, the dashboard client uses the new node secret and the certificate manager the new encoding secret.
+type KVWatchConfig struct {
+	// Enabled turns on the polling of the `consul://` and `vault://` values of `secret`, `node_secret`,
+	// `storage.password`, `cache_storage.password` and `security.private_certificate_encoding_secret`.
+	Enabled bool `json:"enabled"`
+	// Interval is the number of seconds between two polls, defaults to 30.
+	Interval int `json:"interval"`
+}
+
 // FileConfig configures the file-based KV provider.
 type FileConfig struct {
 	// BasePath is the directory that file:// and $secret_file.<key> references are
@@ -1578,6 +2123,17 @@ func (c *Config) SetEventTriggers(eventTriggers map[apidef.TykEvent][]TykEventHa
 	c.EventTriggersDefunct = eventTriggers
 }
 
+// KeyHashAlgorithm returns the algorithm of the stored key hashes, empty when each key is hashed
+// with the algorithm it was issued with.
+func (c *Config) KeyHashAlgorithm() string {
+	switch c.HashKeyMigration {
+	case HashKeyMigrationDualRead, HashKeyMigrationComplete:
+		return c.HashKeyFunction
+	default:
+		return ""
+	}
+}
+
 type CertData struct {
 	// Domain name
 	Name string `json:"domain_name"`
@@ -385,6 +385,82 @@ func TestPortsWhiteListDecoder(t *testing.T) {
 	assert.Contains(t, tlsWhiteList.Ports, 6015, "tls should have 6015 port")
 }
 
+func TestPortTLSConfigs(t *testing.T) {
+	t.Run("decode", func(t *testing.T) {
+		var c Config
+		t.Setenv("TYK_GW_HTTPSERVEROPTIONS_PORTTLS", `[{"ports":[8444],"min_version":769,"ssl_ciphers":["TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA"]}]`)
+
+		err := envconfig.Process("TYK_GW", &c)
+		assert.NoError(t, err)
+
+		conf, ok := c.HttpServerOptions.PortTLS.For(8444)
+		assert.True(t, ok)
+		assert.Equal(t, uint16(769), conf.MinVersion)
+		assert.Equal(t, []string{"TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA"}, conf.Ciphers)
+
+		_, ok = c.HttpServerOptions.PortTLS.For(8443)
+		assert.False(t, ok)
+	})
+
+	tests := []struct {
+		name    string
+		confs   PortTLSConfigs
+		wantErr string
+	}{
+		{
+			name: "distinct ports",
+			confs: PortTLSConfigs{
+				{PortWhiteList: PortWhiteList{Ports: []int{8444}}, MinVersion: 769},
+				{PortWhiteList: PortWhiteList{Ranges: []PortRange{{From: 9000, To: 9010}}}, MinVersion: 771},
+			},
+		},
+		{
+			name: "same settings",
+			confs: PortTLSConfigs{
+				{PortWhiteList: PortWhiteList{Ports: []int{8444}}, MinVersion: 769},
+				{PortWhiteList: PortWhiteList{Ranges: []PortRange{{From: 8440, To: 8450}}}, MinVersion: 769},
+			},
+		},
+		{
+			name: "conflicting port",
+			confs: PortTLSConfigs{
+				{PortWhiteList: PortWhiteList{Ports: []int{8444}}, MinVersion: 769},
+				{PortWhiteList: PortWhiteList{Ports: []int{8444}}, MinVersion: 771},
+			},
+			wantErr: "port_tls[0] and port_tls[1] set different TLS settings for port 8444",
+		},
+		{
+			name: "conflicting ranges",
+			confs: PortTLSConfigs{
+				{PortWhiteList: PortWhiteList{Ranges: []PortRange{{From: 9000, To: 9010}}}, MaxVersion: 771},
+				{PortWhiteList: PortWhiteList{Ranges: []PortRange{{From: 9005, To: 9020}}}},
+			},
+			wantErr: "set different TLS settings for port 9005",
+		},
+		{
+			name:    "unknown client auth",
+			confs:   PortTLSConfigs{{PortWhiteList: PortWhiteList{Ports: []int{8444}}, ClientAuth: "optional"}},
+			wantErr: `unknown client_auth "optional"`,
+		},
+		{
+			name:    "require without client certificates",
+			confs:   PortTLSConfigs{{PortWhiteList: PortWhiteList{Ports: []int{8444}}, ClientAuth: PortClientAuthRequire}},
+			wantErr: "requires client_certificates",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.confs.Validate()
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
 func TestCertificateExpiryMonitorConfig(t *testing.T) {
 	dir, err := ioutil.TempDir("", "tyk")
 	if err != nil {
@@ -14,9 +14,6 @@ type DevelopmentConfig struct {
 	// blocking the requests when they go over the defined rate limits.
 	EnableLeakyBucketRateLimiter bool `json:"enable_leaky_bucket_rate_limiter"`
 
-	// EnableTokenBucket enables token bucket rate limiting.
-	EnableTokenBucketRateLimiter bool `json:"enable_token_bucket_rate_limiter"`
-
 	// EnableSlidingWindow enables sliding window rate limiting.
 	EnableSlidingWindowRateLimiter bool `json:"enable_sliding_window_rate_limiter"`
 
@@ -10,6 +10,12 @@ type RateLimit struct {
 	// EnableFixedWindow enables fixed window rate limiting.
 	EnableFixedWindowRateLimiter bool `json:"enable_fixed_window_rate_limiter"`
 
+	// EnableTokenBucketRateLimiter enables the Redis based token bucket rate limiter for all keys. The bucket
+	// holds up to `burst` requests, as set on the key or policy, and refills at the configured rate. When burst
+	// isn't set it defaults to the rate. Limits with a burst set use the token bucket rate limiter regardless
+	// of this setting.
+	EnableTokenBucketRateLimiter bool `json:"enable_token_bucket_rate_limiter"`
+
 	// Redis based rate limiter with sliding log. Provides 100% rate limiting accuracy, but require two additional Redis roundtrips for each request.
 	EnableRedisRollingLimiter bool `json:"enable_redis_rolling_limiter"`
 
@@ -42,6 +48,21 @@ type RateLimit struct {
 	// This controls whether rate limit headers (X-RateLimit-Limit, X-RateLimit-Remaining, etc.)
 	// are populated from quota data or rate limit data. Valid values: "quotas", "rate_limits".
 	RateLimitResponseHeaders RateLimitSource `json:"rate_limit_response_headers"`
+
+	// RateLimitDebug configures the log of the rate limit decisions made for keys in debug mode.
+	// The debug mode is turned on with the `rate_limit_debug_until` field of a key, or for a limited
+	// duration with the `/tyk/debug/rate-limits/{keyHash}` endpoint of the Control API.
+	RateLimitDebug RateLimitDebugConfig `json:"rate_limit_debug"`
+}
+
+// RateLimitDebugConfig configures the log of the rate limit decisions made for keys in debug mode.
+type RateLimitDebugConfig struct {
+	// EnableRedis also pushes the decisions to a capped Redis list per key, so they can be retrieved
+	// with `GET /tyk/debug/rate-limits/{keyHash}`. The decisions are always written to the gateway log.
+	EnableRedis bool `json:"enable_redis"`
+
+	// MaxEntries is the number of decisions kept in the Redis list of a key. Default: 100.
+	MaxEntries int `json:"max_entries"`
 }
 
 type RateLimitSource string
@@ -58,6 +79,10 @@ func (r *RateLimit) String() string {
 		info = "using pipeline"
 	}
 
+	if r.EnableTokenBucketRateLimiter {
+		return "Token Bucket Rate Limiter enabled"
+	}
+
 	if r.EnableFixedWindowRateLimiter {
 		return "Fixed Window Rate Limiter enabled"
 	}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const (
+	// RedactedValue replaces the values of the configuration fields holding secrets.
+	RedactedValue = "*REDACTED*"
+
+	// redactTag is the `structviewer` tag value marking the configuration fields holding secrets.
+	redactTag = "obfuscate"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+	// secretPaths holds the JSON paths of the fields tagged `structviewer:"obfuscate"`.
+	// The `*` segment matches any element of an array or any value of an object.
+	secretPaths = sync.OnceValue(func() [][]string {
+		return taggedPaths(reflect.TypeOf(Config{}), nil, map[reflect.Type]bool{})
+	})
+)
+
+// Redact returns conf as a JSON object, with the values of the fields tagged `structviewer:"obfuscate"`
+// replaced by RedactedValue. The fields are found from the struct tags, so a new secret field is
+// redacted as soon as it's tagged. Fields with an empty value are kept, to show they are unset.
+func Redact(conf Config) (map[string]interface{}, error) {
+	raw, err := json.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var out map[string]interface{}
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+
+	for _, path := range secretPaths() {
+		redactPath(out, path)
+	}
+
+	return out, nil
+}
+
+// taggedPaths returns the JSON paths of the fields of t tagged as secrets.
+func taggedPaths(t reflect.Type, prefix []string, visiting map[reflect.Type]bool) [][]string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return taggedPaths(t.Elem(), appendPath(prefix, "*"), visiting)
+	case reflect.Struct:
+	default:
+		return nil
+	}
+
+	// Types with their own JSON encoding and recursive types aren't inspected.
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) || visiting[t] {
+		return nil
+	}
+
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	var paths [][]string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// Embedded structs without a JSON name have their fields promoted.
+		if field.Anonymous && name == "" {
+			paths = append(paths, taggedPaths(field.Type, prefix, visiting)...)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if strings.EqualFold(field.Tag.Get("structviewer"), redactTag) {
+			paths = append(paths, appendPath(prefix, name))
+			continue
+		}
+
+		paths = append(paths, taggedPaths(field.Type, appendPath(prefix, name), visiting)...)
+	}
+
+	return paths
+}
+
+func appendPath(prefix []string, segment string) []string {
+	path := make([]string, len(prefix), len(prefix)+1)
+	copy(path, prefix)
+	return append(path, segment)
+}
+
+// redactPath replaces the non-empty values at path in v by RedactedValue.
+func redactPath(v interface{}, path []string) {
+	if len(path) == 0 {
+		return
+	}
+
+	switch node := v.(type) {
+	case map[string]interface{}:
+		if path[0] == "*" {
+			for key := range node {
+				redactKey(node, key, path[1:])
+			}
+			return
+		}
+
+		if _, ok := node[path[0]]; ok {
+			redactKey(node, path[0], path[1:])
+		}
+	case []interface{}:
+		if path[0] != "*" {
+			return
+		}
+
+		for i := range node {
+			if len(path) == 1 {
+				if !isEmptyJSON(node[i]) {
+					node[i] = RedactedValue
+				}
+				continue
+			}
+			redactPath(node[i], path[1:])
+		}
+	}
+}
+
+func redactKey(node map[string]interface{}, key string, rest []string) {
+	if len(rest) > 0 {
+		redactPath(node[key], rest)
+		return
+	}
+
+	if !isEmptyJSON(node[key]) {
+		node[key] = RedactedValue
+	}
+}
+
+func isEmptyJSON(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case map[string]interface{}:
+		return len(val) == 0
+	case []interface{}:
+		return len(val) == 0
+	}
+
+	return false
+}
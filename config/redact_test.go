@@ -0,0 +1,84 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	var conf Config
+	conf.ListenPort = 8080
+	conf.HashKeys = true
+	conf.Secret = "control-api-secret"
+	conf.NodeSecret = "node-secret"
+	conf.Storage.Host = "redis"
+	conf.Storage.Password = "redis-password"
+	conf.Secrets = map[string]string{"key": "secrets-value"}
+	conf.KV.Vault.Token = "vault-token"
+
+	out, err := Redact(conf)
+	require.NoError(t, err)
+
+	raw, err := json.Marshal(out)
+	require.NoError(t, err)
+
+	for _, secret := range []string{"control-api-secret", "node-secret", "redis-password", "secrets-value", "vault-token"} {
+		assert.NotContains(t, string(raw), secret)
+	}
+
+	assert.Equal(t, RedactedValue, out["secret"])
+	assert.Equal(t, RedactedValue, out["secrets"])
+	assert.Equal(t, RedactedValue, out["storage"].(map[string]interface{})["password"])
+	assert.Equal(t, "", out["sentry_code"], "empty secrets should be kept")
+
+	assert.Equal(t, json.Number("8080"), out["listen_port"])
+	assert.Equal(t, true, out["hash_keys"])
+	assert.Equal(t, "redis", out["storage"].(map[string]interface{})["host"])
+	assert.Equal(t, "redis-password", conf.Storage.Password, "the given config shouldn't be modified")
+}
+
+// TestRedact_SecretFieldsTagged guards against secret fields added to the configuration
+// without the `structviewer:"obfuscate"` tag, which would expose them unredacted.
+func TestRedact_SecretFieldsTagged(t *testing.T) {
+	secretName := regexp.MustCompile(`(?i)(secret|password|token|apikey|connectionstring|licensekey|headerlist|sentrycode)$`)
+
+	// Fields matching secretName which don't hold a secret.
+	allowed := map[string]bool{
+		"StatsdConnectionString": true,
+	}
+
+	var check func(typ reflect.Type, visiting map[reflect.Type]bool)
+	check = func(typ reflect.Type, visiting map[reflect.Type]bool) {
+		for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map || typ.Kind() == reflect.Array {
+			typ = typ.Elem()
+		}
+
+		if typ.Kind() != reflect.Struct || visiting[typ] {
+			return
+		}
+
+		visiting[typ] = true
+		defer delete(visiting, typ)
+
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() || field.Tag.Get("json") == "-" {
+				continue
+			}
+
+			kind := field.Type.Kind()
+			if secretName.MatchString(field.Name) && !allowed[field.Name] && (kind == reflect.String || kind == reflect.Map) {
+				assert.Equal(t, redactTag, field.Tag.Get("structviewer"), "%s.%s should be tagged `structviewer:\"obfuscate\"`", typ.Name(), field.Name)
+			}
+
+			check(field.Type, visiting)
+		}
+	}
+
+	check(reflect.TypeOf(Config{}), map[reflect.Type]bool{})
+}
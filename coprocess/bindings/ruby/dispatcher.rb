@@ -20,6 +20,7 @@ module Coprocess
 
       rpc :Dispatch, Coprocess::Object, Coprocess::Object
       rpc :DispatchEvent, Coprocess::Event, Coprocess::EventReply
+      rpc :DispatchStream, stream(Coprocess::ObjectChunk), stream(Coprocess::ObjectChunk)
     end
 
     Stub = Service.rpc_stub_class
@@ -215,6 +215,105 @@ func (*EventReply) Descriptor() ([]byte, []int) {
 	return file_coprocess_object_proto_rawDescGZIP(), []int{2}
 }
 
+// ObjectChunk is a part of an Object streamed by DispatchStream. The first chunk holds the object without its raw
+// request and response bodies, the following chunks hold the parts of the bodies, in order.
+type ObjectChunk struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Payload:
+	//	*ObjectChunk_Object
+	//	*ObjectChunk_RequestBody
+	//	*ObjectChunk_ResponseBody
+	Payload isObjectChunk_Payload `protobuf_oneof:"payload"`
+}
+
+func (x *ObjectChunk) Reset() {
+	*x = ObjectChunk{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_coprocess_object_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ObjectChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ObjectChunk) ProtoMessage() {}
+
+func (x *ObjectChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_coprocess_object_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ObjectChunk.ProtoReflect.Descriptor instead.
+func (*ObjectChunk) Descriptor() ([]byte, []int) {
+	return file_coprocess_object_proto_rawDescGZIP(), []int{3}
+}
+
+func (m *ObjectChunk) GetPayload() isObjectChunk_Payload {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (x *ObjectChunk) GetObject() *Object {
+	if x, ok := x.GetPayload().(*ObjectChunk_Object); ok {
+		return x.Object
+	}
+	return nil
+}
+
+func (x *ObjectChunk) GetRequestBody() []byte {
+	if x, ok := x.GetPayload().(*ObjectChunk_RequestBody); ok {
+		return x.RequestBody
+	}
+	return nil
+}
+
+func (x *ObjectChunk) GetResponseBody() []byte {
+	if x, ok := x.GetPayload().(*ObjectChunk_ResponseBody); ok {
+		return x.ResponseBody
+	}
+	return nil
+}
+
+type isObjectChunk_Payload interface {
+	isObjectChunk_Payload()
+}
+
+type ObjectChunk_Object struct {
+	// Object is the object without its raw bodies, it's always the first chunk.
+	Object *Object `protobuf:"bytes,1,opt,name=object,proto3,oneof"`
+}
+
+type ObjectChunk_RequestBody struct {
+	// RequestBody is a part of the raw request body.
+	RequestBody []byte `protobuf:"bytes,2,opt,name=request_body,json=requestBody,proto3,oneof"`
+}
+
+type ObjectChunk_ResponseBody struct {
+	// ResponseBody is a part of the raw response body.
+	ResponseBody []byte `protobuf:"bytes,3,opt,name=response_body,json=responseBody,proto3,oneof"`
+}
+
+func (*ObjectChunk_Object) isObjectChunk_Payload() {}
+
+func (*ObjectChunk_RequestBody) isObjectChunk_Payload() {}
+
+func (*ObjectChunk_ResponseBody) isObjectChunk_Payload() {}
+
 var File_coprocess_object_proto protoreflect.FileDescriptor
 
 var file_coprocess_object_proto_rawDesc = []byte{
@@ -261,16 +360,30 @@ var file_coprocess_object_proto_rawDesc = []byte{
 	0x22, 0x21, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79,
 	0x6c, 0x6f, 0x61, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c,
 	0x6f, 0x61, 0x64, 0x22, 0x0c, 0x0a, 0x0a, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6c,
-	0x79, 0x32, 0x7c, 0x0a, 0x0a, 0x44, 0x69, 0x73, 0x70, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x12,
-	0x32, 0x0a, 0x08, 0x44, 0x69, 0x73, 0x70, 0x61, 0x74, 0x63, 0x68, 0x12, 0x11, 0x2e, 0x63, 0x6f,
-	0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x2e, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x1a, 0x11,
+	0x79, 0x22, 0x91, 0x01, 0x0a, 0x0b, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x43, 0x68, 0x75, 0x6e,
+	0x6b, 0x12, 0x2b, 0x0a, 0x06, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x11, 0x2e, 0x63, 0x6f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x2e, 0x4f, 0x62,
+	0x6a, 0x65, 0x63, 0x74, 0x48, 0x00, 0x52, 0x06, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x12, 0x23,
+	0x0a, 0x0c, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x0b, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x42,
+	0x6f, 0x64, 0x79, 0x12, 0x25, 0x0a, 0x0d, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x5f,
+	0x62, 0x6f, 0x64, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x0c, 0x72, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x6f, 0x64, 0x79, 0x42, 0x09, 0x0a, 0x07, 0x70, 0x61,
+	0x79, 0x6c, 0x6f, 0x61, 0x64, 0x32, 0xc4, 0x01, 0x0a, 0x0a, 0x44, 0x69, 0x73, 0x70, 0x61, 0x74,
+	0x63, 0x68, 0x65, 0x72, 0x12, 0x32, 0x0a, 0x08, 0x44, 0x69, 0x73, 0x70, 0x61, 0x74, 0x63, 0x68,
+	0x12, 0x11, 0x2e, 0x63, 0x6f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x2e, 0x4f, 0x62, 0x6a,
+	0x65, 0x63, 0x74, 0x1a, 0x11, 0x2e, 0x63, 0x6f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x2e,
+	0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x22, 0x00, 0x12, 0x3a, 0x0a, 0x0d, 0x44, 0x69, 0x73, 0x70,
+	0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x10, 0x2e, 0x63, 0x6f, 0x70, 0x72,
+	0x6f, 0x63, 0x65, 0x73, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x1a, 0x15, 0x2e, 0x63, 0x6f,
+	0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x22, 0x00, 0x12, 0x46, 0x0a, 0x0e, 0x44, 0x69, 0x73, 0x70, 0x61, 0x74, 0x63, 0x68,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x16, 0x2e, 0x63, 0x6f, 0x70, 0x72, 0x6f, 0x63, 0x65,
+	0x73, 0x73, 0x2e, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x1a, 0x16,
 	0x2e, 0x63, 0x6f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x2e, 0x4f, 0x62, 0x6a, 0x65, 0x63,
-	0x74, 0x22, 0x00, 0x12, 0x3a, 0x0a, 0x0d, 0x44, 0x69, 0x73, 0x70, 0x61, 0x74, 0x63, 0x68, 0x45,
-	0x76, 0x65, 0x6e, 0x74, 0x12, 0x10, 0x2e, 0x63, 0x6f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73,
-	0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x1a, 0x15, 0x2e, 0x63, 0x6f, 0x70, 0x72, 0x6f, 0x63, 0x65,
-	0x73, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42,
-	0x0c, 0x5a, 0x0a, 0x2f, 0x63, 0x6f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x62, 0x06, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x74, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x42, 0x0c, 0x5a, 0x0a,
+	0x2f, 0x63, 0x6f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
 }
 
 var (
@@ -285,34 +398,38 @@ func file_coprocess_object_proto_rawDescGZIP() []byte {
 	return file_coprocess_object_proto_rawDescData
 }
 
-var file_coprocess_object_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_coprocess_object_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_coprocess_object_proto_goTypes = []interface{}{
 	(*Object)(nil),            // 0: coprocess.Object
 	(*Event)(nil),             // 1: coprocess.Event
 	(*EventReply)(nil),        // 2: coprocess.EventReply
-	nil,                       // 3: coprocess.Object.MetadataEntry
-	nil,                       // 4: coprocess.Object.SpecEntry
-	(HookType)(0),             // 5: coprocess.HookType
-	(*MiniRequestObject)(nil), // 6: coprocess.MiniRequestObject
-	(*SessionState)(nil),      // 7: coprocess.SessionState
-	(*ResponseObject)(nil),    // 8: coprocess.ResponseObject
+	(*ObjectChunk)(nil),       // 3: coprocess.ObjectChunk
+	nil,                       // 4: coprocess.Object.MetadataEntry
+	nil,                       // 5: coprocess.Object.SpecEntry
+	(HookType)(0),             // 6: coprocess.HookType
+	(*MiniRequestObject)(nil), // 7: coprocess.MiniRequestObject
+	(*SessionState)(nil),      // 8: coprocess.SessionState
+	(*ResponseObject)(nil),    // 9: coprocess.ResponseObject
 }
 var file_coprocess_object_proto_depIdxs = []int32{
-	5, // 0: coprocess.Object.hook_type:type_name -> coprocess.HookType
-	6, // 1: coprocess.Object.request:type_name -> coprocess.MiniRequestObject
-	7, // 2: coprocess.Object.session:type_name -> coprocess.SessionState
-	3, // 3: coprocess.Object.metadata:type_name -> coprocess.Object.MetadataEntry
-	4, // 4: coprocess.Object.spec:type_name -> coprocess.Object.SpecEntry
-	8, // 5: coprocess.Object.response:type_name -> coprocess.ResponseObject
-	0, // 6: coprocess.Dispatcher.Dispatch:input_type -> coprocess.Object
-	1, // 7: coprocess.Dispatcher.DispatchEvent:input_type -> coprocess.Event
-	0, // 8: coprocess.Dispatcher.Dispatch:output_type -> coprocess.Object
-	2, // 9: coprocess.Dispatcher.DispatchEvent:output_type -> coprocess.EventReply
-	8, // [8:10] is the sub-list for method output_type
-	6, // [6:8] is the sub-list for method input_type
-	6, // [6:6] is the sub-list for extension type_name
-	6, // [6:6] is the sub-list for extension extendee
-	0, // [0:6] is the sub-list for field type_name
+	6,  // 0: coprocess.Object.hook_type:type_name -> coprocess.HookType
+	7,  // 1: coprocess.Object.request:type_name -> coprocess.MiniRequestObject
+	8,  // 2: coprocess.Object.session:type_name -> coprocess.SessionState
+	4,  // 3: coprocess.Object.metadata:type_name -> coprocess.Object.MetadataEntry
+	5,  // 4: coprocess.Object.spec:type_name -> coprocess.Object.SpecEntry
+	9,  // 5: coprocess.Object.response:type_name -> coprocess.ResponseObject
+	0,  // 6: coprocess.ObjectChunk.object:type_name -> coprocess.Object
+	0,  // 7: coprocess.Dispatcher.Dispatch:input_type -> coprocess.Object
+	1,  // 8: coprocess.Dispatcher.DispatchEvent:input_type -> coprocess.Event
+	3,  // 9: coprocess.Dispatcher.DispatchStream:input_type -> coprocess.ObjectChunk
+	0,  // 10: coprocess.Dispatcher.Dispatch:output_type -> coprocess.Object
+	2,  // 11: coprocess.Dispatcher.DispatchEvent:output_type -> coprocess.EventReply
+	3,  // 12: coprocess.Dispatcher.DispatchStream:output_type -> coprocess.ObjectChunk
+	10, // [10:13] is the sub-list for method output_type
+	7,  // [7:10] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_coprocess_object_proto_init() }
@@ -361,6 +478,23 @@ func file_coprocess_object_proto_init() {
 				return nil
 			}
 		}
+		file_coprocess_object_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ObjectChunk); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_coprocess_object_proto_msgTypes[3].OneofWrappers = []interface{}{
+		(*ObjectChunk_Object)(nil),
+		(*ObjectChunk_RequestBody)(nil),
+		(*ObjectChunk_ResponseBody)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -368,7 +502,7 @@ func file_coprocess_object_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_coprocess_object_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
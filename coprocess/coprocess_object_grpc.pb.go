@@ -20,8 +20,9 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	Dispatcher_Dispatch_FullMethodName      = "/coprocess.Dispatcher/Dispatch"
-	Dispatcher_DispatchEvent_FullMethodName = "/coprocess.Dispatcher/DispatchEvent"
+	Dispatcher_Dispatch_FullMethodName       = "/coprocess.Dispatcher/Dispatch"
+	Dispatcher_DispatchEvent_FullMethodName  = "/coprocess.Dispatcher/DispatchEvent"
+	Dispatcher_DispatchStream_FullMethodName = "/coprocess.Dispatcher/DispatchStream"
 )
 
 // DispatcherClient is the client API for Dispatcher service.
@@ -32,6 +33,10 @@ type DispatcherClient interface {
 	Dispatch(ctx context.Context, in *Object, opts ...grpc.CallOption) (*Object, error)
 	// DispatchEvent dispatches an event to the target language.
 	DispatchEvent(ctx context.Context, in *Event, opts ...grpc.CallOption) (*EventReply, error)
+	// DispatchStream is the streaming variant of Dispatch used by the hooks with stream_body enabled. The object is
+	// sent and returned as ObjectChunk messages, so its bodies aren't limited by the gRPC maximum message size.
+	// The hooks are dispatched with Dispatch to the servers not implementing it.
+	DispatchStream(ctx context.Context, opts ...grpc.CallOption) (Dispatcher_DispatchStreamClient, error)
 }
 
 type dispatcherClient struct {
@@ -60,6 +65,37 @@ func (c *dispatcherClient) DispatchEvent(ctx context.Context, in *Event, opts ..
 	return out, nil
 }
 
+func (c *dispatcherClient) DispatchStream(ctx context.Context, opts ...grpc.CallOption) (Dispatcher_DispatchStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Dispatcher_ServiceDesc.Streams[0], Dispatcher_DispatchStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dispatcherDispatchStreamClient{stream}
+	return x, nil
+}
+
+type Dispatcher_DispatchStreamClient interface {
+	Send(*ObjectChunk) error
+	Recv() (*ObjectChunk, error)
+	grpc.ClientStream
+}
+
+type dispatcherDispatchStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *dispatcherDispatchStreamClient) Send(m *ObjectChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *dispatcherDispatchStreamClient) Recv() (*ObjectChunk, error) {
+	m := new(ObjectChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // DispatcherServer is the server API for Dispatcher service.
 // All implementations should embed UnimplementedDispatcherServer
 // for forward compatibility.
@@ -68,6 +104,10 @@ type DispatcherServer interface {
 	Dispatch(context.Context, *Object) (*Object, error)
 	// DispatchEvent dispatches an event to the target language.
 	DispatchEvent(context.Context, *Event) (*EventReply, error)
+	// DispatchStream is the streaming variant of Dispatch used by the hooks with stream_body enabled. The object is
+	// sent and returned as ObjectChunk messages, so its bodies aren't limited by the gRPC maximum message size.
+	// The hooks are dispatched with Dispatch to the servers not implementing it.
+	DispatchStream(Dispatcher_DispatchStreamServer) error
 }
 
 // UnimplementedDispatcherServer should be embedded to have forward compatible implementations.
@@ -80,6 +120,9 @@ func (UnimplementedDispatcherServer) Dispatch(context.Context, *Object) (*Object
 func (UnimplementedDispatcherServer) DispatchEvent(context.Context, *Event) (*EventReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DispatchEvent not implemented")
 }
+func (UnimplementedDispatcherServer) DispatchStream(Dispatcher_DispatchStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method DispatchStream not implemented")
+}
 
 // UnsafeDispatcherServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to DispatcherServer will
@@ -128,6 +171,32 @@ func _Dispatcher_DispatchEvent_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Dispatcher_DispatchStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DispatcherServer).DispatchStream(&dispatcherDispatchStreamServer{stream})
+}
+
+type Dispatcher_DispatchStreamServer interface {
+	Send(*ObjectChunk) error
+	Recv() (*ObjectChunk, error)
+	grpc.ServerStream
+}
+
+type dispatcherDispatchStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *dispatcherDispatchStreamServer) Send(m *ObjectChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *dispatcherDispatchStreamServer) Recv() (*ObjectChunk, error) {
+	m := new(ObjectChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Dispatcher_ServiceDesc is the grpc.ServiceDesc for Dispatcher service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -144,6 +213,13 @@ var Dispatcher_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _Dispatcher_DispatchEvent_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DispatchStream",
+			Handler:       _Dispatcher_DispatchStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "coprocess_object.proto",
 }
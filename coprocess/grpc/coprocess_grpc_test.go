@@ -8,17 +8,24 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/TykTechnologies/tyk/header"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 
 	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/coprocess"
 	"github.com/TykTechnologies/tyk/gateway"
 	"github.com/TykTechnologies/tyk/test"
 	"github.com/TykTechnologies/tyk/user"
@@ -638,3 +645,207 @@ func TestGRPCConfigData(t *testing.T) {
 	})
 
 }
+
+func TestGRPCReconnect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	grpcServer := newTestGRPCServer()
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+
+	ts := gateway.StartTest(nil, gateway.TestConfig{
+		CoprocessConfig: config.CoProcessConfig{
+			EnableCoProcess:        true,
+			CoProcessGRPCServer:    grpcServerAddress(listener),
+			GRPCReconnectBaseDelay: 0.05,
+			GRPCReconnectMaxDelay:  0.2,
+		},
+	})
+	t.Cleanup(func() {
+		ts.Close()
+		grpcServer.Stop()
+	})
+
+	buildAPI := func(failOpen bool) func(spec *gateway.APISpec) {
+		return func(spec *gateway.APISpec) {
+			spec.APIID = fmt.Sprintf("grpc-reconnect-fail-open-%t", failOpen)
+			spec.Proxy.ListenPath = fmt.Sprintf("/grpc-reconnect-fail-open-%t/", failOpen)
+			spec.UseKeylessAccess = true
+			spec.CustomMiddleware = apidef.MiddlewareSection{
+				Driver:               apidef.GrpcDriver,
+				Pre:                  []apidef.MiddlewareDefinition{{Name: "testPreHook1"}},
+				FailOpenOnDisconnect: failOpen,
+			}
+		}
+	}
+	ts.Gw.BuildAndLoadAPI(buildAPI(false), buildAPI(true))
+
+	// get returns the status code of a request to the API and whether the pre hook ran.
+	get := func(failOpen bool) (int, bool) {
+		resp, err := http.Get(fmt.Sprintf("%s/grpc-reconnect-fail-open-%t/", ts.URL, failOpen))
+		if err != nil {
+			return 0, false
+		}
+		defer resp.Body.Close()
+
+		var testResponse gateway.TestHttpResponse
+		_ = json.NewDecoder(resp.Body).Decode(&testResponse)
+		return resp.StatusCode, testResponse.Headers[testHeaderName] == testHeaderValue
+	}
+
+	connected := func() bool {
+		code, hookRan := get(false)
+		return code == http.StatusOK && hookRan
+	}
+
+	require.Eventually(t, connected, 5*time.Second, 50*time.Millisecond)
+
+	grpcServer.Stop()
+
+	t.Run("fail closed while disconnected", func(t *testing.T) {
+		require.Eventually(t, func() bool {
+			code, _ := get(false)
+			return code == http.StatusServiceUnavailable
+		}, 5*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("fail open while disconnected", func(t *testing.T) {
+		code, hookRan := get(true)
+		assert.Equal(t, http.StatusOK, code)
+		assert.False(t, hookRan)
+	})
+
+	t.Run("recovers without a reload", func(t *testing.T) {
+		listener, err := net.Listen("tcp", listener.Addr().String())
+		require.NoError(t, err)
+
+		grpcServer = newTestGRPCServer()
+		go func() {
+			_ = grpcServer.Serve(listener)
+		}()
+
+		require.Eventually(t, connected, 5*time.Second, 50*time.Millisecond)
+
+		code, hookRan := get(true)
+		assert.Equal(t, http.StatusOK, code)
+		assert.True(t, hookRan)
+	})
+}
+
+// legacyDispatcher is a gRPC server predating DispatchStream.
+type legacyDispatcher struct {
+	coprocess.UnimplementedDispatcherServer
+	dispatcher dispatcher
+
+	// requestBody and responseBody are the bodies of the last dispatched object.
+	requestBody, responseBody atomic.Value
+}
+
+// Dispatch records the bodies, cleared for the hooks expecting the raw bodies only.
+func (d *legacyDispatcher) Dispatch(ctx context.Context, object *coprocess.Object) (*coprocess.Object, error) {
+	if object.Response != nil {
+		d.responseBody.Store(object.Response.Body)
+		object.Response.Body = ""
+	} else {
+		d.requestBody.Store(object.Request.Body)
+		object.Request.Body = ""
+	}
+	return d.dispatcher.Dispatch(ctx, object)
+}
+
+func TestGRPCDispatchStream(t *testing.T) {
+	// the bodies exceed the default max message size of 4MB, used by the server and the gateway
+	const bodySize = 5 << 20
+
+	start := func(t *testing.T, server coprocess.DispatcherServer) *gateway.Test {
+		t.Helper()
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		grpcServer := grpc.NewServer()
+		coprocess.RegisterDispatcherServer(grpcServer, server)
+		go func() {
+			_ = grpcServer.Serve(listener)
+		}()
+
+		ts := gateway.StartTest(nil, gateway.TestConfig{
+			CoprocessConfig: config.CoProcessConfig{
+				EnableCoProcess:     true,
+				CoProcessGRPCServer: grpcServerAddress(listener),
+				GRPCStreamChunkSize: 512 << 10,
+			},
+		})
+		t.Cleanup(func() {
+			ts.Close()
+			grpcServer.Stop()
+		})
+
+		ts.Gw.BuildAndLoadAPI(func(spec *gateway.APISpec) {
+			spec.APIID = "grpc-stream"
+			spec.Proxy.ListenPath = "/grpc-stream/"
+			spec.UseKeylessAccess = true
+			spec.CustomMiddleware = apidef.MiddlewareSection{
+				Driver:   apidef.GrpcDriver,
+				Pre:      []apidef.MiddlewareDefinition{{Name: "testStreamPreHook", StreamBody: true}},
+				Response: []apidef.MiddlewareDefinition{{Name: "testStreamResponseHook", StreamBody: true}},
+			}
+		}, func(spec *gateway.APISpec) {
+			spec.APIID = "grpc-no-stream"
+			spec.Proxy.ListenPath = "/grpc-no-stream/"
+			spec.UseKeylessAccess = true
+			spec.CustomMiddleware = apidef.MiddlewareSection{
+				Driver: apidef.GrpcDriver,
+				Pre:    []apidef.MiddlewareDefinition{{Name: "testStreamPreHook", RawBodyOnly: true}},
+			}
+		})
+
+		return ts
+	}
+
+	post := func(t *testing.T, ts *gateway.Test, path string, size int) (*http.Response, gateway.TestHttpResponse) {
+		t.Helper()
+
+		resp, err := http.Post(ts.URL+path, "text/plain", strings.NewReader(strings.Repeat("a", size)))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var testResponse gateway.TestHttpResponse
+		if resp.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&testResponse))
+		}
+		return resp, testResponse
+	}
+
+	t.Run("bodies exceeding the max message size are streamed", func(t *testing.T) {
+		ts := start(t, &dispatcher{})
+
+		resp, testResponse := post(t, ts, "/grpc-stream/", bodySize)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, strings.Repeat("A", bodySize), testResponse.Body, "the body returned by the pre hook is proxied")
+		assert.Equal(t, strconv.Itoa(bodySize), testResponse.Headers[testHeaderName])
+
+		responseSize, err := strconv.Atoi(resp.Header.Get(testHeaderName))
+		require.NoError(t, err)
+		assert.Greater(t, responseSize, bodySize, "the response hook received the whole upstream response")
+
+		resp, _ = post(t, ts, "/grpc-no-stream/", bodySize)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+
+	t.Run("servers without DispatchStream are dispatched the object", func(t *testing.T) {
+		server := &legacyDispatcher{}
+		ts := start(t, server)
+
+		resp, testResponse := post(t, ts, "/grpc-stream/", 1024)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, strings.Repeat("A", 1024), testResponse.Body)
+		assert.Equal(t, "1024", testResponse.Headers[testHeaderName])
+		assert.NotEqual(t, "0", resp.Header.Get(testHeaderName))
+
+		assert.Equal(t, strings.Repeat("a", 1024), server.requestBody.Load(), "the request body is set")
+		assert.Contains(t, server.responseBody.Load(), strings.Repeat("A", 1024), "the response body is set")
+	})
+}
@@ -1,8 +1,11 @@
 package grpc
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"strconv"
 	"strings"
 	"time"
 
@@ -82,6 +85,19 @@ func (d *dispatcher) Dispatch(_ context.Context, object *coprocess.Object) (*cop
 				return d.grpcError(object, k+" doesn't match value in object.Session.Metadata")
 			}
 		}
+	case "testStreamPreHook":
+		if object.Request.Body != "" {
+			return d.grpcError(object, "Body field isn't empty")
+		}
+		object.Request.SetHeaders = map[string]string{
+			testHeaderName: strconv.Itoa(len(object.Request.RawBody)),
+		}
+		object.Request.RawBody = bytes.ToUpper(object.Request.RawBody)
+	case "testStreamResponseHook":
+		if object.Response.Body != "" {
+			return object, errors.New("body field isn't empty")
+		}
+		object.Response.Headers[testHeaderName] = strconv.Itoa(len(object.Response.RawBody))
 	case "testResponseHook":
 		object.Response.RawBody = []byte("newbody")
 	case "testConfigDataResponseHook":
@@ -122,6 +138,20 @@ func (d *dispatcher) Dispatch(_ context.Context, object *coprocess.Object) (*cop
 	return object, nil
 }
 
+func (d *dispatcher) DispatchStream(stream coprocess.Dispatcher_DispatchStreamServer) error {
+	object, err := coprocess.RecvObject(stream)
+	if err != nil {
+		return err
+	}
+
+	object, err = d.Dispatch(stream.Context(), object)
+	if err != nil {
+		return err
+	}
+
+	return coprocess.SendObject(stream, object, coprocess.DefaultStreamChunkSize)
+}
+
 func (d *dispatcher) DispatchEvent(_ context.Context, _ *coprocess.Event) (*coprocess.EventReply, error) {
 	return &coprocess.EventReply{}, nil
 }
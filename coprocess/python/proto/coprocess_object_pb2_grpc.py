@@ -50,6 +50,11 @@ class DispatcherStub(object):
                 request_serializer=coprocess__object__pb2.Event.SerializeToString,
                 response_deserializer=coprocess__object__pb2.EventReply.FromString,
                 _registered_method=True)
+        self.DispatchStream = channel.stream_stream(
+                '/coprocess.Dispatcher/DispatchStream',
+                request_serializer=coprocess__object__pb2.ObjectChunk.SerializeToString,
+                response_deserializer=coprocess__object__pb2.ObjectChunk.FromString,
+                _registered_method=True)
 
 
 class DispatcherServicer(object):
@@ -70,6 +75,15 @@ class DispatcherServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def DispatchStream(self, request_iterator, context):
+        """DispatchStream is the streaming variant of Dispatch used by the hooks with stream_body enabled. The object is
+        sent and returned as ObjectChunk messages, so its bodies aren't limited by the gRPC maximum message size.
+        The hooks are dispatched with Dispatch to the servers not implementing it.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
 
 def add_DispatcherServicer_to_server(servicer, server):
     rpc_method_handlers = {
@@ -83,6 +97,11 @@ def add_DispatcherServicer_to_server(servicer, server):
                     request_deserializer=coprocess__object__pb2.Event.FromString,
                     response_serializer=coprocess__object__pb2.EventReply.SerializeToString,
             ),
+            'DispatchStream': grpc.stream_stream_rpc_method_handler(
+                    servicer.DispatchStream,
+                    request_deserializer=coprocess__object__pb2.ObjectChunk.FromString,
+                    response_serializer=coprocess__object__pb2.ObjectChunk.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'coprocess.Dispatcher', rpc_method_handlers)
@@ -148,3 +167,30 @@ class Dispatcher(object):
             timeout,
             metadata,
             _registered_method=True)
+
+    @staticmethod
+    def DispatchStream(request_iterator,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.stream_stream(
+            request_iterator,
+            target,
+            '/coprocess.Dispatcher/DispatchStream',
+            coprocess__object__pb2.ObjectChunk.SerializeToString,
+            coprocess__object__pb2.ObjectChunk.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
@@ -0,0 +1,112 @@
+package coprocess
+
+import (
+	"errors"
+	"io"
+)
+
+// DefaultStreamChunkSize is the size of the body chunks sent by SendObject when no size is given.
+const DefaultStreamChunkSize = 1 << 20
+
+// ErrMissingObject is returned by RecvObject when the stream doesn't start with the object.
+var ErrMissingObject = errors.New("the stream doesn't start with an object")
+
+// ObjectChunkSender is implemented by the client and server streams of DispatchStream.
+type ObjectChunkSender interface {
+	Send(*ObjectChunk) error
+}
+
+// ObjectChunkReceiver is implemented by the client and server streams of DispatchStream.
+type ObjectChunkReceiver interface {
+	Recv() (*ObjectChunk, error)
+}
+
+// SendObject streams object as ObjectChunk messages: the object without its raw bodies, followed by the
+// raw request and response bodies in chunks of chunkSize bytes. The bodies are detached from object while
+// it's sent, so they aren't copied, and restored before SendObject returns.
+func SendObject(stream ObjectChunkSender, object *Object, chunkSize int) error {
+	if chunkSize <= 0 {
+		chunkSize = DefaultStreamChunkSize
+	}
+
+	var requestBody, responseBody []byte
+	if req := object.Request; req != nil {
+		body := req.Body
+		requestBody = req.RawBody
+		req.RawBody, req.Body = nil, ""
+		defer func() { req.RawBody, req.Body = requestBody, body }()
+	}
+	if res := object.Response; res != nil {
+		body := res.Body
+		responseBody = res.RawBody
+		res.RawBody, res.Body = nil, ""
+		defer func() { res.RawBody, res.Body = responseBody, body }()
+	}
+
+	if err := stream.Send(&ObjectChunk{Payload: &ObjectChunk_Object{Object: object}}); err != nil {
+		return err
+	}
+
+	for rest := requestBody; len(rest) > 0; {
+		n := min(chunkSize, len(rest))
+		if err := stream.Send(&ObjectChunk{Payload: &ObjectChunk_RequestBody{RequestBody: rest[:n]}}); err != nil {
+			return err
+		}
+		rest = rest[n:]
+	}
+
+	for rest := responseBody; len(rest) > 0; {
+		n := min(chunkSize, len(rest))
+		if err := stream.Send(&ObjectChunk{Payload: &ObjectChunk_ResponseBody{ResponseBody: rest[:n]}}); err != nil {
+			return err
+		}
+		rest = rest[n:]
+	}
+
+	return nil
+}
+
+// RecvObject reads an object streamed by SendObject, until the end of the stream. The bodies are
+// returned in the raw_body fields of the request and response only.
+func RecvObject(stream ObjectChunkReceiver) (*Object, error) {
+	var object *Object
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch payload := chunk.Payload.(type) {
+		case *ObjectChunk_Object:
+			if object != nil {
+				return nil, errors.New("the stream holds more than one object")
+			}
+			object = payload.Object
+		case *ObjectChunk_RequestBody:
+			if object == nil {
+				return nil, ErrMissingObject
+			}
+			if object.Request == nil {
+				object.Request = &MiniRequestObject{}
+			}
+			object.Request.RawBody = append(object.Request.RawBody, payload.RequestBody...)
+		case *ObjectChunk_ResponseBody:
+			if object == nil {
+				return nil, ErrMissingObject
+			}
+			if object.Response == nil {
+				object.Response = &ResponseObject{}
+			}
+			object.Response.RawBody = append(object.Response.RawBody, payload.ResponseBody...)
+		}
+	}
+
+	if object == nil {
+		return nil, ErrMissingObject
+	}
+
+	return object, nil
+}
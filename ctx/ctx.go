@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/sirupsen/logrus"
+
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/apidef/oas"
 	"github.com/TykTechnologies/tyk/config"
@@ -81,6 +83,32 @@ const (
 	// in the JWT middleware. The value (a *gateway.Binding) is type-asserted on
 	// the gateway side; only the key lives here to avoid an import cycle.
 	MatchedIdPBinding
+	// LoopTrace holds the IDs of the APIs a request passed through on internal (tyk://) hops.
+	LoopTrace
+	// PrefetchedQuotaTTL holds the TTL of the session's quota key, read from Redis together with the session.
+	PrefetchedQuotaTTL
+	// ConcurrencySlots holds the concurrency limit slots taken by the request, released once it completed.
+	ConcurrencySlots
+	// RequestID holds the correlation ID of the request, see RequestIDData.
+	RequestID
+	// ResolvedVersion holds the name of the API version serving the request, once the default and fallback were applied.
+	ResolvedVersion
+	// IdempotencyState holds the idempotency key lock taken by the request, released once it completed.
+	IdempotencyState
+	// ChainTrace holds the middleware chain trace recorded for a request sent to the debug endpoint.
+	ChainTrace
+	// CanaryRoute holds the route, canary or stable, chosen for a request to an API with canary routing.
+	CanaryRoute
+	// LogEntry holds the log entry correlating the log lines of the request with its API, key and request ID.
+	LogEntry
+	// ControlAPIOrgScope holds the organisation a Control API call authenticated with an org-scoped admin token is limited to.
+	ControlAPIOrgScope
+	// LatencyBreakdown holds the time spent in each stage of a request to an API with the latency breakdown enabled.
+	LatencyBreakdown
+	// PayloadSizes holds the size of the request and response bodies of a proxied request.
+	PayloadSizes
+	// TokenScopes holds the scopes of the JWT authenticating the request, checked by the scope access of the API.
+	TokenScopes
 )
 
 func ctxSetSession(r *http.Request, s *user.SessionState, scheduleUpdate bool, hashKey bool) {
@@ -198,6 +226,45 @@ func GetErrorClassification(r *http.Request) *errors.ErrorClassification {
 	return nil
 }
 
+// RequestIDData holds the correlation ID of a request and the header carrying it.
+type RequestIDData struct {
+	// Header is the name of the header carrying the ID.
+	Header string
+	// ID is the correlation ID.
+	ID string
+}
+
+// SetRequestID sets the correlation ID of the request.
+func SetRequestID(r *http.Request, data *RequestIDData) {
+	ctx := r.Context()
+	ctx = context.WithValue(ctx, RequestID, data)
+	core.SetContext(r, ctx)
+}
+
+// GetRequestID returns the correlation ID of the request, or nil if the request ID isn't enabled.
+func GetRequestID(r *http.Request) *RequestIDData {
+	if v, ok := r.Context().Value(RequestID).(*RequestIDData); ok {
+		return v
+	}
+	return nil
+}
+
+// SetLogEntry sets the log entry correlating the log lines of the request.
+func SetLogEntry(r *http.Request, entry *logrus.Entry) {
+	ctx := r.Context()
+	ctx = context.WithValue(ctx, LogEntry, entry)
+	core.SetContext(r, ctx)
+}
+
+// GetLogEntry returns the log entry correlating the log lines of the request, with the API ID, API name,
+// org ID, key hash and request ID fields known so far. It returns nil before the request enters an API.
+func GetLogEntry(r *http.Request) *logrus.Entry {
+	if v, ok := r.Context().Value(LogEntry).(*logrus.Entry); ok {
+		return v
+	}
+	return nil
+}
+
 // GetMCPMethod returns the JSON-RPC method name stored in the request context.
 func GetMCPMethod(r *http.Request) string {
 	if v, ok := r.Context().Value(MCPMethod).(string); ok {
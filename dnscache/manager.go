@@ -26,6 +26,8 @@ type IDnsCacheManager interface {
 	WrapDialer(dialer *net.Dialer) DialContextFunc
 	SetCacheStorage(cache IDnsCacheStorage)
 	CacheStorage() IDnsCacheStorage
+	SetResolver(resolver Resolver)
+	Resolver() Resolver
 	IsCacheEnabled() bool
 	DisposeCache()
 }
@@ -46,14 +48,26 @@ type DnsCacheManager struct {
 	cacheStorage IDnsCacheStorage
 	strategy     config.IPsHandleStrategy
 	rand         *rand.Rand
+	resolver     Resolver
 }
 
 // NewDnsCacheManager returns new empty/non-initialized DnsCacheManager
 func NewDnsCacheManager(multipleIPsHandleStrategy config.IPsHandleStrategy) *DnsCacheManager {
-	manager := &DnsCacheManager{nil, multipleIPsHandleStrategy, nil}
+	manager := &DnsCacheManager{cacheStorage: nil, strategy: multipleIPsHandleStrategy}
 	return manager
 }
 
+// SetResolver sets the resolver used instead of the system one, by the cache storage initialized
+// afterwards and by the wrapped dialers when caching is disabled.
+func (m *DnsCacheManager) SetResolver(resolver Resolver) {
+	m.resolver = resolver
+}
+
+// Resolver returns the resolver used instead of the system one, or nil if none is set.
+func (m *DnsCacheManager) Resolver() Resolver {
+	return m.resolver
+}
+
 func (m *DnsCacheManager) SetCacheStorage(cache IDnsCacheStorage) {
 	m.cacheStorage = cache
 }
@@ -67,6 +81,7 @@ func (m *DnsCacheManager) IsCacheEnabled() bool {
 }
 
 // WrapDialer returns wrapped version of net.Dialer#DialContext func with hooked up caching of dns queries.
+// When caching is disabled the host names are resolved with the resolver set, if any.
 //
 // Actual dns server call occures in net.Resolver#LookupIPAddr method,
 // linked to net.Dialer instance by net.Dialer#Resolver field
@@ -85,7 +100,7 @@ func (m *DnsCacheManager) doCachedDial(d *net.Dialer, ctx context.Context, netwo
 		return conn, err
 	}
 
-	if !m.IsCacheEnabled() {
+	if !m.IsCacheEnabled() && m.resolver == nil {
 		return safeDial(address, "")
 	}
 
@@ -98,6 +113,15 @@ func (m *DnsCacheManager) doCachedDial(d *net.Dialer, ctx context.Context, netwo
 		return safeDial(address, "")
 	}
 
+	if !m.IsCacheEnabled() {
+		ips, err := m.resolver.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		return safeDial(net.JoinHostPort(ips[0], port), "")
+	}
+
 	ips, err := m.cacheStorage.FetchItem(host)
 	if err != nil {
 		logger.WithError(err).WithFields(logrus.Fields{
@@ -151,6 +175,9 @@ func (m *DnsCacheManager) InitDNSCaching(ttl, checkInterval time.Duration) {
 	if !m.IsCacheEnabled() {
 		logger.Infof("Initializing dns cache with ttl=%s, duration=%s", ttl, checkInterval)
 		storage := NewDnsCacheStorage(ttl, checkInterval)
+		if m.resolver != nil {
+			storage.SetResolver(m.resolver)
+		}
 		m.SetCacheStorage(IDnsCacheStorage(storage))
 	}
 }
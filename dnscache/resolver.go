@@ -0,0 +1,347 @@
+package dnscache
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+const (
+	defaultResolverTimeout = 5 * time.Second
+
+	dnsMessageContentType = "application/dns-message"
+)
+
+// Resolver resolves host names to IP addresses. Implemented by the system, udp, DoH and DoT resolvers.
+type Resolver interface {
+	// LookupHost returns the addresses of the host.
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	// Name identifies the resolver, it's recorded with the cached entries it produced.
+	Name() string
+}
+
+// NewResolver returns the resolver configured for the upstream host names, trying the fallback
+// resolvers in order when it fails. It returns nil when the system resolver alone is configured.
+func NewResolver(conf config.DnsResolverConfig) (Resolver, error) {
+	types := append([]config.DnsResolverType{conf.Type}, conf.Fallback...)
+	if types[0] == "" {
+		types[0] = config.SystemResolver
+	}
+
+	if len(types) == 1 && types[0] == config.SystemResolver {
+		return nil, nil
+	}
+
+	timeout := defaultResolverTimeout
+	if conf.Timeout > 0 {
+		timeout = time.Duration(conf.Timeout * float64(time.Second))
+	}
+
+	resolvers := make(FallbackResolver, 0, len(types))
+	for _, resolverType := range types {
+		var resolver Resolver
+
+		switch resolverType {
+		case config.SystemResolver:
+			resolver = SystemResolver{}
+		case config.UDPResolver:
+			if len(conf.UDPServers) == 0 {
+				return nil, errors.New("the udp resolver requires udp_servers")
+			}
+			resolver = NewUDPResolver(conf.UDPServers, timeout)
+		case config.DoHResolver:
+			if conf.DoHURL == "" {
+				return nil, errors.New("the doh resolver requires doh_url")
+			}
+			resolver = NewDoHResolver(conf.DoHURL, &http.Client{Timeout: timeout})
+		case config.DoTResolver:
+			if conf.DoTHost == "" {
+				return nil, errors.New("the dot resolver requires dot_host")
+			}
+			resolver = NewDoTResolver(conf.DoTHost, nil, timeout)
+		default:
+			return nil, fmt.Errorf("unknown resolver type %q", resolverType)
+		}
+
+		resolvers = append(resolvers, withTimeout{Resolver: resolver, timeout: timeout})
+	}
+
+	if len(resolvers) == 1 {
+		return resolvers[0], nil
+	}
+
+	return resolvers, nil
+}
+
+// SystemResolver resolves with the host's configuration. The default resolver is looked up
+// on every call, so the DNS mock replacing it in tests keeps working.
+type SystemResolver struct{}
+
+func (SystemResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return net.DefaultResolver.LookupHost(ctx, host)
+}
+
+func (SystemResolver) Name() string {
+	return string(config.SystemResolver)
+}
+
+// FallbackResolver tries its resolvers in order, returning the addresses of the first one succeeding.
+type FallbackResolver []Resolver
+
+func (f FallbackResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	addrs, _, err := f.lookupHost(ctx, host)
+	return addrs, err
+}
+
+// lookupHost also returns the name of the resolver that produced the addresses.
+func (f FallbackResolver) lookupHost(ctx context.Context, host string) ([]string, string, error) {
+	var errs []error
+	for _, resolver := range f {
+		addrs, err := resolver.LookupHost(ctx, host)
+		if err == nil {
+			return addrs, resolver.Name(), nil
+		}
+
+		logger.WithError(err).Debugf("Resolver %s failed to resolve %q, falling back", resolver.Name(), host)
+		errs = append(errs, fmt.Errorf("%s: %w", resolver.Name(), err))
+	}
+
+	return nil, "", errors.Join(errs...)
+}
+
+func (f FallbackResolver) Name() string {
+	names := make([]string, 0, len(f))
+	for _, resolver := range f {
+		names = append(names, resolver.Name())
+	}
+
+	return strings.Join(names, ",")
+}
+
+// lookupHost resolves host, returning the name of the resolver that produced the addresses.
+func lookupHost(ctx context.Context, resolver Resolver, host string) ([]string, string, error) {
+	if fallback, ok := resolver.(FallbackResolver); ok {
+		return fallback.lookupHost(ctx, host)
+	}
+
+	addrs, err := resolver.LookupHost(ctx, host)
+	return addrs, resolver.Name(), err
+}
+
+// withTimeout limits the time a resolver may take, so the next one is tried in time.
+type withTimeout struct {
+	Resolver
+	timeout time.Duration
+}
+
+func (w withTimeout) LookupHost(ctx context.Context, host string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	return w.Resolver.LookupHost(ctx, host)
+}
+
+// exchangeFunc sends a DNS query and returns the response.
+type exchangeFunc func(ctx context.Context, query *dns.Msg) (*dns.Msg, error)
+
+// exchangeLookup resolves the A and AAAA records of host with exchange.
+func exchangeLookup(ctx context.Context, exchange exchangeFunc, host string) ([]string, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []string{host}, nil
+	}
+
+	var addrs []string
+	var errs []error
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		query := new(dns.Msg)
+		query.SetQuestion(dns.Fqdn(host), qtype)
+
+		resp, err := exchange(ctx, query)
+		if err == nil && resp.Rcode != dns.RcodeSuccess {
+			err = fmt.Errorf("server responded with %s", dns.RcodeToString[resp.Rcode])
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, answer := range resp.Answer {
+			switch record := answer.(type) {
+			case *dns.A:
+				addrs = append(addrs, record.A.String())
+			case *dns.AAAA:
+				addrs = append(addrs, record.AAAA.String())
+			}
+		}
+	}
+
+	if len(addrs) > 0 {
+		return addrs, nil
+	}
+
+	err := errors.Join(errs...)
+	if err == nil {
+		err = errors.New("no such host")
+	}
+
+	return nil, &net.DNSError{Err: err.Error(), Name: host, IsNotFound: len(errs) == 0}
+}
+
+// UDPResolver queries the DNS servers in order, over UDP, until one of them responds.
+type UDPResolver struct {
+	servers []string
+	client  *dns.Client
+}
+
+// NewUDPResolver returns a resolver querying servers, defaulting to port 53.
+func NewUDPResolver(servers []string, timeout time.Duration) *UDPResolver {
+	return &UDPResolver{
+		servers: withDefaultPort(servers, "53"),
+		client:  &dns.Client{Net: "udp", Timeout: timeout},
+	}
+}
+
+func (u *UDPResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return exchangeLookup(ctx, u.exchange, host)
+}
+
+func (u *UDPResolver) exchange(ctx context.Context, query *dns.Msg) (*dns.Msg, error) {
+	var errs []error
+	for _, server := range u.servers {
+		resp, _, err := u.client.ExchangeContext(ctx, query, server)
+		if err == nil {
+			return resp, nil
+		}
+		errs = append(errs, err)
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+func (u *UDPResolver) Name() string {
+	return string(config.UDPResolver)
+}
+
+// DoHResolver queries a DNS-over-HTTPS endpoint, posting application/dns-message queries as defined by RFC 8484.
+type DoHResolver struct {
+	url    string
+	client *http.Client
+}
+
+// NewDoHResolver returns a resolver querying the DNS-over-HTTPS endpoint at url with client.
+func NewDoHResolver(url string, client *http.Client) *DoHResolver {
+	return &DoHResolver{
+		url:    url,
+		client: client,
+	}
+}
+
+func (d *DoHResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return exchangeLookup(ctx, d.exchange, host)
+}
+
+func (d *DoHResolver) exchange(ctx context.Context, query *dns.Msg) (*dns.Msg, error) {
+	// RFC 8484 recommends a zero ID, so the responses are cache friendly
+	query.Id = 0
+
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(packed))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", dnsMessageContentType)
+	req.Header.Set("Accept", dnsMessageContentType)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DoH server responded with status %d", resp.StatusCode)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != dnsMessageContentType {
+		return nil, fmt.Errorf("DoH server responded with content type %q", contentType)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, dns.MaxMsgSize))
+	if err != nil {
+		return nil, err
+	}
+
+	msg := new(dns.Msg)
+	if err := msg.Unpack(body); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+func (d *DoHResolver) Name() string {
+	return string(config.DoHResolver)
+}
+
+// DoTResolver queries a DNS-over-TLS server, as defined by RFC 7858.
+type DoTResolver struct {
+	host   string
+	client *dns.Client
+}
+
+// NewDoTResolver returns a resolver querying the DNS-over-TLS server at host, defaulting to port 853.
+// The server certificate is verified against the host name, unless tlsConfig says otherwise.
+func NewDoTResolver(host string, tlsConfig *tls.Config, timeout time.Duration) *DoTResolver {
+	host = withDefaultPort([]string{host}, "853")[0]
+
+	if tlsConfig == nil {
+		serverName, _, _ := net.SplitHostPort(host)
+		tlsConfig = &tls.Config{ServerName: serverName, MinVersion: tls.VersionTLS12}
+	}
+
+	return &DoTResolver{
+		host:   host,
+		client: &dns.Client{Net: "tcp-tls", TLSConfig: tlsConfig, Timeout: timeout},
+	}
+}
+
+func (d *DoTResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return exchangeLookup(ctx, d.exchange, host)
+}
+
+func (d *DoTResolver) exchange(ctx context.Context, query *dns.Msg) (*dns.Msg, error) {
+	resp, _, err := d.client.ExchangeContext(ctx, query, d.host)
+	return resp, err
+}
+
+func (d *DoTResolver) Name() string {
+	return string(config.DoTResolver)
+}
+
+// withDefaultPort adds port to the addresses without one.
+func withDefaultPort(addrs []string, port string) []string {
+	res := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort(strings.Trim(addr, "[]"), port)
+		}
+		res = append(res, addr)
+	}
+
+	return res
+}
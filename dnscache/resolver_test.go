@@ -0,0 +1,237 @@
+package dnscache
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+// testZone answers the A queries of the test resolvers.
+var testZone = map[string][]string{
+	"upstream.internal.": {"10.0.0.1", "10.0.0.2"},
+}
+
+func testZoneReply(query *dns.Msg) *dns.Msg {
+	reply := new(dns.Msg)
+	reply.SetReply(query)
+
+	question := query.Question[0]
+	addrs, ok := testZone[question.Name]
+	if !ok {
+		reply.SetRcode(query, dns.RcodeNameError)
+		return reply
+	}
+
+	if question.Qtype == dns.TypeA {
+		for _, addr := range addrs {
+			reply.Answer = append(reply.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: question.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   net.ParseIP(addr),
+			})
+		}
+	}
+
+	return reply
+}
+
+func newDoHServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != dnsMessageContentType {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		query := new(dns.Msg)
+		require.NoError(t, query.Unpack(body))
+
+		packed, err := testZoneReply(query).Pack()
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", dnsMessageContentType)
+		_, _ = w.Write(packed)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestDoHResolver(t *testing.T) {
+	srv := newDoHServer(t)
+	resolver := NewDoHResolver(srv.URL+"/dns-query", srv.Client())
+
+	addrs, err := resolver.LookupHost(context.Background(), "upstream.internal")
+	require.NoError(t, err)
+	assert.Equal(t, testZone["upstream.internal."], addrs)
+
+	_, err = resolver.LookupHost(context.Background(), "missing.internal")
+	var dnsErr *net.DNSError
+	require.ErrorAs(t, err, &dnsErr)
+	assert.Equal(t, "missing.internal", dnsErr.Name)
+
+	t.Run("ip addresses aren't resolved", func(t *testing.T) {
+		addrs, err := NewDoHResolver("http://invalid.", http.DefaultClient).LookupHost(context.Background(), "10.0.0.3")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.3"}, addrs)
+	})
+
+	t.Run("invalid responses", func(t *testing.T) {
+		plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("not a dns message"))
+		}))
+		defer plain.Close()
+
+		_, err := NewDoHResolver(plain.URL, plain.Client()).LookupHost(context.Background(), "upstream.internal")
+		assert.ErrorContains(t, err, "content type")
+
+		_, err = NewDoHResolver(srv.URL, http.DefaultClient).LookupHost(context.Background(), "upstream.internal")
+		assert.Error(t, err, "the certificate of the test server isn't trusted")
+	})
+}
+
+func TestUDPResolver(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &dns.Server{PacketConn: conn, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, query *dns.Msg) {
+		_ = w.WriteMsg(testZoneReply(query))
+	})}
+	go func() { _ = srv.ActivateAndServe() }()
+	t.Cleanup(func() { _ = srv.Shutdown() })
+
+	// the first server doesn't respond, the resolver moves on to the next one
+	unreachable, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = unreachable.Close() })
+
+	resolver := NewUDPResolver([]string{unreachable.LocalAddr().String(), conn.LocalAddr().String()}, 200*time.Millisecond)
+
+	addrs, err := resolver.LookupHost(context.Background(), "upstream.internal")
+	require.NoError(t, err)
+	assert.Equal(t, testZone["upstream.internal."], addrs)
+}
+
+type stubResolver struct {
+	name  string
+	addrs []string
+	err   error
+}
+
+func (s stubResolver) LookupHost(context.Context, string) ([]string, error) {
+	return s.addrs, s.err
+}
+
+func (s stubResolver) Name() string {
+	return s.name
+}
+
+func TestFallbackResolver(t *testing.T) {
+	resolver := FallbackResolver{
+		stubResolver{name: "doh", err: &net.DNSError{Err: "timeout", IsTimeout: true}},
+		stubResolver{name: "system", addrs: []string{"10.0.0.1"}},
+	}
+
+	addrs, name, err := lookupHost(context.Background(), resolver, "upstream.internal")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1"}, addrs)
+	assert.Equal(t, "system", name)
+	assert.Equal(t, "doh,system", resolver.Name())
+
+	_, _, err = lookupHost(context.Background(), resolver[:1], "upstream.internal")
+	assert.ErrorContains(t, err, "doh: ")
+}
+
+func TestNewResolver(t *testing.T) {
+	resolver, err := NewResolver(config.DnsResolverConfig{})
+	assert.NoError(t, err)
+	assert.Nil(t, resolver)
+
+	resolver, err = NewResolver(config.DnsResolverConfig{Type: config.SystemResolver})
+	assert.NoError(t, err)
+	assert.Nil(t, resolver)
+
+	resolver, err = NewResolver(config.DnsResolverConfig{
+		Type:     config.DoHResolver,
+		DoHURL:   "https://resolver.internal/dns-query",
+		Fallback: []config.DnsResolverType{config.DoTResolver, config.SystemResolver},
+		DoTHost:  "resolver.internal",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "doh,dot,system", resolver.Name())
+
+	resolver, err = NewResolver(config.DnsResolverConfig{Type: config.UDPResolver, UDPServers: []string{"10.0.0.53"}})
+	require.NoError(t, err)
+	assert.Equal(t, "udp", resolver.Name())
+	assert.Equal(t, []string{"10.0.0.53:53"}, resolver.(withTimeout).Resolver.(*UDPResolver).servers)
+
+	for _, conf := range []config.DnsResolverConfig{
+		{Type: config.DoHResolver},
+		{Type: config.DoTResolver},
+		{Type: config.UDPResolver},
+		{Type: "ldap"},
+		{Fallback: []config.DnsResolverType{config.DoHResolver}},
+	} {
+		_, err := NewResolver(conf)
+		assert.Error(t, err, conf)
+	}
+}
+
+func TestStorageRecordsResolver(t *testing.T) {
+	srv := newDoHServer(t)
+
+	dnsCache := NewDnsCacheStorage(time.Minute, time.Minute)
+	defer dnsCache.Clear()
+	dnsCache.SetResolver(FallbackResolver{
+		stubResolver{name: "udp", err: &net.DNSError{Err: "timeout", IsTimeout: true}},
+		NewDoHResolver(srv.URL, srv.Client()),
+	})
+
+	addrs, err := dnsCache.FetchItem("upstream.internal")
+	require.NoError(t, err)
+	assert.Equal(t, testZone["upstream.internal."], addrs)
+
+	item, ok := dnsCache.Get("upstream.internal")
+	require.True(t, ok)
+	assert.Equal(t, DnsCacheItem{Addrs: testZone["upstream.internal."], Resolver: "doh"}, item)
+}
+
+func TestWrapDialerWithResolver(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			_ = conn.Close()
+		}
+	}()
+
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	dnsManager := NewDnsCacheManager(config.NoCacheStrategy)
+	dnsManager.SetResolver(stubResolver{name: "doh", addrs: []string{"127.0.0.1"}})
+
+	conn, err := dnsManager.WrapDialer(&net.Dialer{Timeout: time.Second})(context.Background(), "tcp", "upstream.internal:"+port)
+	require.NoError(t, err)
+	assert.Equal(t, listener.Addr().String(), conn.RemoteAddr().String())
+	assert.NoError(t, conn.Close())
+
+	dnsManager.SetResolver(stubResolver{name: "doh", err: &net.DNSError{Err: "no such host", IsNotFound: true}})
+	_, err = dnsManager.WrapDialer(&net.Dialer{Timeout: time.Second})(context.Background(), "tcp", "upstream.internal:"+port)
+	assert.ErrorContains(t, err, "no such host")
+}
@@ -1,7 +1,7 @@
 package dnscache
 
 import (
-	"net"
+	"context"
 	"time"
 
 	"fmt"
@@ -14,20 +14,29 @@ import (
 // DnsCacheItem represents single record in cache
 type DnsCacheItem struct {
 	Addrs []string
+	// Resolver is the name of the resolver the addresses were resolved with, for debugging.
+	Resolver string
 }
 
 // DnsCacheStorage is an in-memory cache of auto-purged dns query ip responses
 type DnsCacheStorage struct {
-	cache *cache.Cache
+	cache    *cache.Cache
+	resolver Resolver
 }
 
 func NewDnsCacheStorage(expiration, checkInterval time.Duration) *DnsCacheStorage {
 	storage := &DnsCacheStorage{
-		cache: cache.NewCache(expiration, checkInterval),
+		cache:    cache.NewCache(expiration, checkInterval),
+		resolver: SystemResolver{},
 	}
 	return storage
 }
 
+// SetResolver sets the resolver the host names missing from the cache are resolved with.
+func (dc *DnsCacheStorage) SetResolver(resolver Resolver) {
+	dc.resolver = resolver
+}
+
 // Items returns map of non expired dns cache items
 func (dc *DnsCacheStorage) Items(includeExpired bool) map[string]DnsCacheItem {
 	var allItems = dc.cache.Items()
@@ -68,22 +77,27 @@ func (dc *DnsCacheStorage) FetchItem(hostName string) ([]string, error) {
 		logger.WithFields(logrus.Fields{
 			"hostName": hostName,
 			"addrs":    item.Addrs,
+			"resolver": item.Resolver,
 		}).Debug("Dns record was populated from cache")
 		return item.Addrs, nil
 	}
 
-	addrs, err := dc.resolveDNSRecord(hostName)
+	addrs, resolver, err := dc.resolveDNSRecord(hostName)
 	if err != nil {
 		return nil, err
 	}
 
-	dc.Set(hostName, addrs)
+	dc.setItem(hostName, DnsCacheItem{Addrs: addrs, Resolver: resolver})
 	return addrs, nil
 }
 
 func (dc *DnsCacheStorage) Set(key string, addrs []string) {
-	logger.Debugf("Adding dns record to cache: key=%q, addrs=%q", key, addrs)
-	dc.cache.Set(key, DnsCacheItem{addrs}, cache.DefaultExpiration)
+	dc.setItem(key, DnsCacheItem{Addrs: addrs})
+}
+
+func (dc *DnsCacheStorage) setItem(key string, item DnsCacheItem) {
+	logger.Debugf("Adding dns record to cache: key=%q, addrs=%q, resolver=%q", key, item.Addrs, item.Resolver)
+	dc.cache.Set(key, item, cache.DefaultExpiration)
 }
 
 // Clear deletes all records from cache
@@ -91,6 +105,6 @@ func (dc *DnsCacheStorage) Clear() {
 	dc.cache.Flush()
 }
 
-func (dc *DnsCacheStorage) resolveDNSRecord(host string) ([]string, error) {
-	return net.LookupHost(host)
+func (dc *DnsCacheStorage) resolveDNSRecord(host string) ([]string, string, error) {
+	return lookupHost(context.Background(), dc.resolver, host)
 }
@@ -0,0 +1,92 @@
+package streams
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gobwas/ws"
+)
+
+// consumerCloseTimeout bounds writing the close frame to a websocket consumer.
+const consumerCloseTimeout = time.Second
+
+// eventSourceCloseEvent is the last event sent to event source consumers of a removed stream.
+const eventSourceCloseEvent = "event: close\ndata: stream removed\n\n"
+
+// consumerKind tells apart the requests consuming the output of a stream.
+type consumerKind int
+
+const (
+	noConsumer consumerKind = iota
+	webSocketConsumer
+	streamConsumer
+	eventSourceConsumer
+)
+
+// webSocketConsumerWriter hands the websocket handler of a stream a connection which sends a
+// close frame to the consumer if the stream manager was removed.
+type webSocketConsumerWriter struct {
+	http.ResponseWriter
+	manager *Manager
+}
+
+func (w *webSocketConsumerWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, ErrResponseWriterNotHijackable
+	}
+
+	conn, brw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &webSocketConsumerConn{Conn: conn, manager: w.manager}, brw, nil
+}
+
+func (w *webSocketConsumerWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// webSocketConsumerConn is closed by the websocket handler once it stopped writing to the consumer,
+// which makes it safe to write the close frame from Close.
+type webSocketConsumerConn struct {
+	net.Conn
+	manager   *Manager
+	closeOnce sync.Once
+}
+
+func (c *webSocketConsumerConn) Close() error {
+	c.closeOnce.Do(func() {
+		if !c.manager.removed.Load() {
+			return
+		}
+
+		_ = c.SetWriteDeadline(time.Now().Add(consumerCloseTimeout))
+		_ = ws.WriteFrame(c.Conn, ws.NewCloseFrame(ws.NewCloseFrameBody(ws.StatusGoingAway, "stream removed")))
+	})
+
+	return c.Conn.Close()
+}
+
+// closeEventSourceConsumer ends the event stream of a consumer with a close event if the stream manager
+// was removed, so that clients can tell it apart from a dropped connection.
+func (sm *Manager) closeEventSourceConsumer(w http.ResponseWriter) {
+	if !sm.removed.Load() {
+		return
+	}
+
+	if _, err := io.WriteString(w, eventSourceCloseEvent); err != nil {
+		return
+	}
+
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
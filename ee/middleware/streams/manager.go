@@ -20,6 +20,10 @@ type Manager struct {
 	listenPaths      []string
 	activityCounter  atomic.Int32 // Counts active subscriptions, requests.
 	analyticsFactory StreamAnalyticsFactory
+
+	// removed is set once the stream manager is removed on API reload or removal,
+	// its consumers are then sent a close frame or a close event.
+	removed atomic.Bool
 }
 
 func (sm *Manager) initStreams(r *http.Request, config *StreamsConfig) {
@@ -100,6 +104,7 @@ func (sm *Manager) createStream(streamID string, config map[string]interface{})
 		StreamID:         streamFullID,
 		Muxer:            sm.muxer,
 		StreamManager:    sm,
+		Metrics:          stream.Metrics(),
 		// child logger is necessary to prevent race condition
 		Logger: sm.mw.Logger().WithField("stream", streamFullID),
 	})
@@ -114,6 +119,23 @@ func (sm *Manager) createStream(streamID string, config map[string]interface{})
 	return nil
 }
 
+// isIdle returns true if the stream manager neither handles requests nor has consumers connected.
+func (sm *Manager) isIdle() bool {
+	if sm.activityCounter.Load() > 0 {
+		return false
+	}
+
+	idle := true
+	sm.streams.Range(func(_, value interface{}) bool {
+		if stream, ok := value.(*Stream); ok && stream.Metrics().consumers() > 0 {
+			idle = false
+		}
+		return idle
+	})
+
+	return idle
+}
+
 func (sm *Manager) hasPath(path string) bool {
 	for _, p := range sm.listenPaths {
 		if strings.TrimPrefix(path, "/") == strings.TrimPrefix(p, "/") {
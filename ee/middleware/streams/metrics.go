@@ -0,0 +1,242 @@
+package streams
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	// MetricsPath is the path, relative to the listen path, serving the runtime metrics of the streams.
+	// It is only served when no stream listens on it and requires the gateway secret.
+	MetricsPath = "/metrics"
+
+	// metricsExporterName is the Bento metrics exporter feeding StreamMetrics.
+	metricsExporterName = "tyk_stream_metrics"
+)
+
+// StreamMetrics holds the runtime counters of a stream. Message and error counts are fed by a
+// metrics exporter plugged into the stream, consumer counts by the HTTP handlers of the stream.
+type StreamMetrics struct {
+	inputMessages  atomic.Int64
+	outputMessages atomic.Int64
+	errors         atomic.Int64
+
+	webSocketConsumers atomic.Int64
+	sseConsumers       atomic.Int64
+	lastActivity       atomic.Int64
+
+	// webSocketPaths and ssePaths are the output paths consumers connect to,
+	// eventSourcePaths the subset of ssePaths streaming server-sent events.
+	webSocketPaths   map[string]struct{}
+	ssePaths         map[string]struct{}
+	eventSourcePaths map[string]struct{}
+}
+
+// StreamMetricsSnapshot is the state of the runtime counters of a stream.
+type StreamMetricsSnapshot struct {
+	InputMessages      int64      `json:"input_messages"`
+	OutputMessages     int64      `json:"output_messages"`
+	Errors             int64      `json:"errors"`
+	WebSocketConsumers int64      `json:"websocket_consumers"`
+	SSEConsumers       int64      `json:"sse_consumers"`
+	LastActivity       *time.Time `json:"last_activity,omitempty"`
+}
+
+// StreamsMetrics is the response of the metrics endpoint.
+type StreamsMetrics struct {
+	APIID   string                           `json:"api_id"`
+	Streams map[string]StreamMetricsSnapshot `json:"streams"`
+}
+
+// Snapshot returns the current state of the counters.
+func (m *StreamMetrics) Snapshot() StreamMetricsSnapshot {
+	snapshot := StreamMetricsSnapshot{
+		InputMessages:      m.inputMessages.Load(),
+		OutputMessages:     m.outputMessages.Load(),
+		Errors:             m.errors.Load(),
+		WebSocketConsumers: m.webSocketConsumers.Load(),
+		SSEConsumers:       m.sseConsumers.Load(),
+	}
+
+	if lastActivity := m.lastActivity.Load(); lastActivity > 0 {
+		t := time.Unix(0, lastActivity).UTC()
+		snapshot.LastActivity = &t
+	}
+
+	return snapshot
+}
+
+// add merges the counters of the same stream running in another stream manager.
+func (s StreamMetricsSnapshot) add(other StreamMetricsSnapshot) StreamMetricsSnapshot {
+	s.InputMessages += other.InputMessages
+	s.OutputMessages += other.OutputMessages
+	s.Errors += other.Errors
+	s.WebSocketConsumers += other.WebSocketConsumers
+	s.SSEConsumers += other.SSEConsumers
+
+	if other.LastActivity != nil && (s.LastActivity == nil || other.LastActivity.After(*s.LastActivity)) {
+		s.LastActivity = other.LastActivity
+	}
+
+	return s
+}
+
+func (m *StreamMetrics) touch() {
+	m.lastActivity.Store(time.Now().UnixNano())
+}
+
+// setConsumerPaths records the output paths of the stream configuration that consumers connect to.
+func (m *StreamMetrics) setConsumerPaths(streamConfig map[string]interface{}) {
+	m.webSocketPaths = map[string]struct{}{}
+	m.ssePaths = map[string]struct{}{}
+	m.eventSourcePaths = map[string]struct{}{}
+
+	output, ok := streamConfig["output"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	outputs := []interface{}{output}
+	if brokerConfig, ok := output["broker"].(map[string]interface{}); ok {
+		if brokerOutputs, ok := brokerConfig["outputs"].([]interface{}); ok {
+			outputs = append(outputs, brokerOutputs...)
+		}
+	}
+
+	for _, item := range outputs {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		httpServerConfig, ok := itemMap["http_server"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		streamPath := normalizePath(stringOrDefault(httpServerConfig, "stream_path", "/get/stream"))
+		m.webSocketPaths[normalizePath(stringOrDefault(httpServerConfig, "ws_path", "/get/ws"))] = struct{}{}
+		m.ssePaths[streamPath] = struct{}{}
+		if stringOrDefault(httpServerConfig, "stream_format", "") == "event_source" {
+			m.eventSourcePaths[streamPath] = struct{}{}
+		}
+	}
+}
+
+// consumerKind returns which kind of consumer, if any, the request to the path is.
+func (m *StreamMetrics) consumerKind(path string, r *http.Request) consumerKind {
+	path = normalizePath(path)
+
+	if _, ok := m.webSocketPaths[path]; ok && strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return webSocketConsumer
+	}
+
+	if _, ok := m.eventSourcePaths[path]; ok {
+		return eventSourceConsumer
+	}
+
+	if _, ok := m.ssePaths[path]; ok {
+		return streamConsumer
+	}
+
+	return noConsumer
+}
+
+// consumers returns the number of consumers connected to the outputs of the stream.
+func (m *StreamMetrics) consumers() int64 {
+	return m.webSocketConsumers.Load() + m.sseConsumers.Load()
+}
+
+// trackRequest records the activity of a request handled by the stream. Consumers connected to the
+// output are counted until the returned function is called.
+func (m *StreamMetrics) trackRequest(kind consumerKind) func() {
+	m.touch()
+
+	var gauge *atomic.Int64
+	switch kind {
+	case webSocketConsumer:
+		gauge = &m.webSocketConsumers
+	case streamConsumer, eventSourceConsumer:
+		gauge = &m.sseConsumers
+	default:
+		return func() {}
+	}
+
+	gauge.Add(1)
+	return func() {
+		gauge.Add(-1)
+		m.touch()
+	}
+}
+
+func normalizePath(path string) string {
+	return "/" + strings.TrimPrefix(path, "/")
+}
+
+func stringOrDefault(config map[string]interface{}, key, defaultValue string) string {
+	if val, ok := config[key].(string); ok {
+		return val
+	}
+	return defaultValue
+}
+
+// metricsExporter is a Bento metrics exporter counting the messages and errors of a stream.
+type metricsExporter struct {
+	metrics *StreamMetrics
+}
+
+func (e *metricsExporter) NewCounterCtor(name string, _ ...string) service.MetricsExporterCounterCtor {
+	var counter *atomic.Int64
+	switch name {
+	case "input_received":
+		counter = &e.metrics.inputMessages
+	case "output_sent":
+		counter = &e.metrics.outputMessages
+	case "output_error", "processor_error":
+		counter = &e.metrics.errors
+	}
+
+	return func(_ ...string) service.MetricsExporterCounter {
+		if counter == nil {
+			return noopMetric{}
+		}
+		return &streamCounter{metrics: e.metrics, counter: counter}
+	}
+}
+
+func (e *metricsExporter) NewTimerCtor(_ string, _ ...string) service.MetricsExporterTimerCtor {
+	return func(_ ...string) service.MetricsExporterTimer {
+		return noopMetric{}
+	}
+}
+
+func (e *metricsExporter) NewGaugeCtor(_ string, _ ...string) service.MetricsExporterGaugeCtor {
+	return func(_ ...string) service.MetricsExporterGauge {
+		return noopMetric{}
+	}
+}
+
+func (e *metricsExporter) Close(_ context.Context) error {
+	return nil
+}
+
+type streamCounter struct {
+	metrics *StreamMetrics
+	counter *atomic.Int64
+}
+
+func (c *streamCounter) Incr(count int64) {
+	c.counter.Add(count)
+	c.metrics.touch()
+}
+
+type noopMetric struct{}
+
+func (noopMetric) Incr(int64)   {}
+func (noopMetric) Timing(int64) {}
+func (noopMetric) Set(int64)    {}
@@ -3,11 +3,13 @@ package streams
 import (
 	"context"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -15,6 +17,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 
+	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/internal/middleware"
 	"github.com/TykTechnologies/tyk/internal/model"
 )
@@ -115,13 +118,36 @@ func (s *Middleware) Init() {
 
 // CreateStreamManager creates or retrieves a stream manager based on the request.
 func (s *Middleware) CreateStreamManager(r *http.Request) *Manager {
-	streamsConfig := s.getStreamsConfig(r)
-	configJSON, _ := json.Marshal(streamsConfig)
-	cacheKey := fmt.Sprintf("%x", sha256.Sum256(configJSON))
+	streamsConfig, cacheKey := s.streamManagerConfig(r)
 
 	s.createStreamManagerLock.Lock()
 	defer s.createStreamManagerLock.Unlock()
 
+	return s.loadOrCreateStreamManager(r, streamsConfig, cacheKey)
+}
+
+// acquireStreamManager creates or retrieves the stream manager for the request with its activity counter
+// already bumped, so that GC can't remove it before the request is handled. The caller must decrement it.
+func (s *Middleware) acquireStreamManager(r *http.Request) *Manager {
+	streamsConfig, cacheKey := s.streamManagerConfig(r)
+
+	s.createStreamManagerLock.Lock()
+	defer s.createStreamManagerLock.Unlock()
+
+	manager := s.loadOrCreateStreamManager(r, streamsConfig, cacheKey)
+	manager.activityCounter.Add(1)
+
+	return manager
+}
+
+func (s *Middleware) streamManagerConfig(r *http.Request) (*StreamsConfig, string) {
+	streamsConfig := s.getStreamsConfig(r)
+	configJSON, _ := json.Marshal(streamsConfig)
+	return streamsConfig, fmt.Sprintf("%x", sha256.Sum256(configJSON))
+}
+
+// loadOrCreateStreamManager must be called with createStreamManagerLock held.
+func (s *Middleware) loadOrCreateStreamManager(r *http.Request, streamsConfig *StreamsConfig, cacheKey string) *Manager {
 	s.Logger().Debug("Attempting to load stream manager from cache")
 	s.Logger().Debugf("Cache key: %s", cacheKey)
 	if cachedManager, found := s.StreamManagerCache.Load(cacheKey); found {
@@ -144,7 +170,8 @@ func (s *Middleware) CreateStreamManager(r *http.Request) *Manager {
 	return newManager
 }
 
-// GC removes inactive stream managers.
+// GC removes inactive stream managers. A stream manager is checked and dropped from the cache
+// while holding createStreamManagerLock, so that it can't be handed to a new request meanwhile.
 func (s *Middleware) GC() {
 	s.Logger().Debug("Starting garbage collection for inactive stream managers")
 
@@ -154,18 +181,26 @@ func (s *Middleware) GC() {
 			return true
 		}
 
-		if manager.activityCounter.Load() <= 0 {
-			s.Logger().Infof("Removing inactive stream manager: %v", key)
-			manager.streams.Range(func(streamKey, streamValue interface{}) bool {
-				streamID := streamKey.(string)
-				err := manager.removeStream(streamID)
-				if err != nil {
-					s.Logger().WithError(err).Errorf("Error removing stream %s", streamID)
-				}
-				return true
-			})
+		s.createStreamManagerLock.Lock()
+		idle := manager.isIdle()
+		if idle {
 			s.StreamManagerCache.Delete(key)
 		}
+		s.createStreamManagerLock.Unlock()
+
+		if !idle {
+			return true
+		}
+
+		s.Logger().Infof("Removing inactive stream manager: %v", key)
+		manager.streams.Range(func(streamKey, streamValue interface{}) bool {
+			streamID := streamKey.(string)
+			err := manager.removeStream(streamID)
+			if err != nil {
+				s.Logger().WithError(err).Errorf("Error removing stream %s", streamID)
+			}
+			return true
+		})
 
 		return true
 	})
@@ -198,29 +233,57 @@ func (s *Middleware) processStreamsConfig(r *http.Request, streams map[string]an
 				s.Logger().Errorf("Failed to marshal stream config: %v", err)
 				continue
 			}
-			replacedStream := s.Gw.ReplaceTykVariables(r, string(marshaledStream), true)
-
-			if replacedStream != string(marshaledStream) {
-				s.Logger().Debugf("Stream config changed for %s: %s", streamID, replacedStream)
-			} else {
-				s.Logger().Debugf("Stream config has not changed for %s: %s", streamID, replacedStream)
-			}
 
 			var unmarshaledStream map[string]interface{}
-			err = json.Unmarshal([]byte(replacedStream), &unmarshaledStream)
+			err = json.Unmarshal(marshaledStream, &unmarshaledStream)
 			if err != nil {
-				s.Logger().Errorf("Failed to unmarshal replaced stream config: %v", err)
+				s.Logger().Errorf("Failed to unmarshal stream config: %v", err)
 				continue
 			}
-			stream = unmarshaledStream
+			stream = s.replaceVariables(r, unmarshaledStream, false)
+
+			s.Logger().Debugf("Stream config with variables replaced for %s: %v", streamID, stream)
 		}
 		config.Streams[streamID] = stream
 	}
 }
 
+// replaceVariables replaces the variables in the values of the stream configuration. Variables resolved
+// from the request are only replaced in the fields listed in interpolatedFields, interpolated tells
+// whether the value belongs to one of them.
+func (s *Middleware) replaceVariables(r *http.Request, value any, interpolated bool) any {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		replaced := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			_, ok := interpolatedFields[key]
+			replaced[key] = s.replaceVariables(r, item, ok)
+		}
+		return replaced
+	case []interface{}:
+		replaced := make([]interface{}, len(v))
+		for i, item := range v {
+			replaced[i] = s.replaceVariables(r, item, interpolated)
+		}
+		return replaced
+	case string:
+		if !interpolated && hasRequestVariable(v) {
+			s.Logger().Debugf("Not replacing request variables outside of the interpolated fields: %s", v)
+			return v
+		}
+		return s.Gw.ReplaceTykVariables(r, v, false)
+	default:
+		return v
+	}
+}
+
 // ProcessRequest will handle the streaming functionality.
 func (s *Middleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
 	strippedPath := s.Spec.StripListenPath(r.URL.Path)
+	if normalizePath(strippedPath) == MetricsPath && !s.defaultManager.hasPath(strippedPath) {
+		return s.serveMetrics(w, r)
+	}
+
 	if !s.defaultManager.hasPath(strippedPath) {
 		s.Logger().Debugf("Path not found: %s", strippedPath)
 		return errors.New("not found"), http.StatusNotFound
@@ -238,7 +301,9 @@ func (s *Middleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ in
 	}
 
 	var match mux.RouteMatch
-	streamManager := s.CreateStreamManager(r)
+	streamManager := s.acquireStreamManager(r)
+	defer streamManager.activityCounter.Add(-1)
+
 	streamManager.SetAnalyticsFactory(s.analyticsFactory)
 	streamManager.routeLock.Lock()
 	streamManager.muxer.Match(newRequest, &match)
@@ -250,14 +315,67 @@ func (s *Middleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ in
 		return errors.New("invalid route handler"), http.StatusInternalServerError
 	}
 
-	streamManager.activityCounter.Add(1)
-	defer streamManager.activityCounter.Add(-1)
-
 	handler.ServeHTTP(w, r)
 
 	return nil, middleware.StatusRespond
 }
 
+// serveMetrics writes the runtime metrics of the streams, for callers holding the gateway secret.
+func (s *Middleware) serveMetrics(w http.ResponseWriter, r *http.Request) (error, int) {
+	if r.Method != http.MethodGet {
+		return errors.New("method not allowed"), http.StatusMethodNotAllowed
+	}
+
+	secret := s.Gw.GetConfig().Secret
+	if secret == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get(header.XTykAuthorization)), []byte(secret)) != 1 {
+		s.Logger().Warning("Attempted access to stream metrics with invalid or missing key!")
+		return errors.New("attempted access to stream metrics with invalid or missing key"), http.StatusForbidden
+	}
+
+	w.Header().Set(header.ContentType, header.ApplicationJSON)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(s.Metrics()); err != nil {
+		s.Logger().WithError(err).Error("Failed to write stream metrics")
+	}
+
+	return nil, middleware.StatusRespond
+}
+
+// Metrics returns the runtime metrics of the running streams. The counters of a stream running
+// in several stream managers are added up.
+func (s *Middleware) Metrics() StreamsMetrics {
+	metrics := StreamsMetrics{
+		APIID:   s.Spec.APIID,
+		Streams: map[string]StreamMetricsSnapshot{},
+	}
+
+	collect := func(manager *Manager) {
+		manager.streams.Range(func(key, value interface{}) bool {
+			stream, ok := value.(*Stream)
+			if !ok {
+				return true
+			}
+
+			streamID := strings.TrimPrefix(key.(string), s.Spec.APIID+"_")
+			metrics.Streams[streamID] = metrics.Streams[streamID].add(stream.Metrics().Snapshot())
+			return true
+		})
+	}
+
+	s.StreamManagerCache.Range(func(_, value interface{}) bool {
+		if manager, ok := value.(*Manager); ok {
+			collect(manager)
+		}
+		return true
+	})
+
+	if s.defaultManager != nil {
+		collect(s.defaultManager)
+	}
+
+	return metrics
+}
+
 func (s *Middleware) resetStream(streamValue any) {
 	if stream, ok := streamValue.(*Stream); ok {
 		if err := stream.Reset(); err != nil {
@@ -279,6 +397,7 @@ func (s *Middleware) Unload() {
 		if !ok {
 			return true
 		}
+		manager.removed.Store(true)
 		manager.streams.Range(func(_, streamValue interface{}) bool {
 			totalStreams++
 			s.resetStream(streamValue)
@@ -288,6 +407,7 @@ func (s *Middleware) Unload() {
 	})
 
 	// Finally, reset the default manager and stop the underlying Bento instance
+	s.defaultManager.removed.Store(true)
 	s.defaultManager.streams.Range(func(_, streamValue interface{}) bool {
 		totalStreams++
 		s.resetStream(streamValue)
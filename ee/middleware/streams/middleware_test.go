@@ -0,0 +1,95 @@
+package streams
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+type testGateway struct {
+	vars map[string]string
+}
+
+func (g *testGateway) GetConfig() config.Config {
+	return config.Config{}
+}
+
+func (g *testGateway) ReplaceTykVariables(_ *http.Request, in string, _ bool) string {
+	for name, value := range g.vars {
+		in = strings.ReplaceAll(in, name, value)
+	}
+	return in
+}
+
+type testBase struct{}
+
+func (testBase) Logger() *logrus.Entry {
+	return testLogger()
+}
+
+func TestMiddleware_ReplaceVariables(t *testing.T) {
+	gw := &testGateway{vars: map[string]string{
+		"$tyk_context.headers_X_Tenant": "acme",
+		"$secret_env.NATS_PASSWORD":     "s3cret",
+	}}
+	mw := NewMiddleware(gw, testBase{}, &APISpec{}, nil)
+
+	streamConfig := map[string]interface{}{
+		"input": map[string]interface{}{
+			"nats": map[string]interface{}{
+				"urls":    []interface{}{"nats://localhost:4222"},
+				"subject": "tenant.$tyk_context.headers_X_Tenant",
+				"auth": map[string]interface{}{
+					"user_password": "$tyk_context.headers_X_Tenant",
+					"user_jwt":      "$secret_env.NATS_PASSWORD",
+				},
+			},
+		},
+		"output": map[string]interface{}{
+			"broker": map[string]interface{}{
+				"outputs": []interface{}{
+					map[string]interface{}{
+						"kafka": map[string]interface{}{
+							"topic":          "events-$tyk_context.headers_X_Tenant",
+							"consumer_group": "$tyk_context.headers_X_Tenant",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/subscribe", nil)
+	replaced := mw.replaceVariables(r, streamConfig, false)
+
+	assert.Equal(t, map[string]interface{}{
+		"input": map[string]interface{}{
+			"nats": map[string]interface{}{
+				"urls":    []interface{}{"nats://localhost:4222"},
+				"subject": "tenant.acme",
+				"auth": map[string]interface{}{
+					"user_password": "$tyk_context.headers_X_Tenant",
+					"user_jwt":      "s3cret",
+				},
+			},
+		},
+		"output": map[string]interface{}{
+			"broker": map[string]interface{}{
+				"outputs": []interface{}{
+					map[string]interface{}{
+						"kafka": map[string]interface{}{
+							"topic":          "events-acme",
+							"consumer_group": "acme",
+						},
+					},
+				},
+			},
+		},
+	}, replaced)
+}
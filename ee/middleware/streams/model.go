@@ -14,6 +14,26 @@ const (
 	StreamGCInterval      = 1 * time.Minute
 )
 
+// requestVariablePrefixes are the prefixes of the variables resolved from the request, which can carry values
+// controlled by the client.
+var requestVariablePrefixes = []string{"$tyk_context.", "$tyk_meta."}
+
+// interpolatedFields are the stream configuration fields in which variables resolved from the request are
+// replaced, so that a stream can be scoped to a tenant. Elsewhere, and in credentials in particular, these
+// variables are left as is so that clients can't inject values into them.
+var interpolatedFields = map[string]struct{}{
+	"channel":        {},
+	"channels":       {},
+	"client_id":      {},
+	"consumer_group": {},
+	"queue":          {},
+	"subject":        {},
+	"subjects":       {},
+	"topic":          {},
+	"topics":         {},
+	"urls":           {},
+}
+
 // BaseMiddleware is the subset of BaseMiddleware APIs that the middleware uses.
 type BaseMiddleware interface {
 	model.LoggerProvider
@@ -22,6 +22,7 @@ type Stream struct {
 	streamConfig  string
 	stream        *service.Stream
 	logger        *logrus.Entry
+	metrics       *StreamMetrics
 }
 
 // NewStream creates a new stream without initializing it
@@ -33,9 +34,15 @@ func NewStream(allowUnsafe []string, logger *logrus.Entry) *Stream {
 	return &Stream{
 		logger:        logger,
 		allowedUnsafe: allowUnsafe,
+		metrics:       &StreamMetrics{},
 	}
 }
 
+// Metrics returns the runtime counters of the stream.
+func (s *Stream) Metrics() *StreamMetrics {
+	return s.metrics
+}
+
 // Start loads up the configuration and starts the stream. Non-blocking
 func (s *Stream) Start(config map[string]interface{}, mux service.HTTPMultiplexer) error {
 	s.logger.Debugf("Starting stream")
@@ -48,8 +55,21 @@ func (s *Stream) Start(config map[string]interface{}, mux service.HTTPMultiplexe
 
 	configPayload = s.removeUnsafe(configPayload)
 
+	s.metrics.setConsumerPaths(config)
+
+	// the stream gets its own environment, so the metrics exporter counts for this stream only
+	env := service.GlobalEnvironment().Clone()
+	err = env.RegisterMetricsExporter(metricsExporterName, service.NewConfigSpec(),
+		func(_ *service.ParsedConfig, _ *service.Logger) (service.MetricsExporter, error) {
+			return &metricsExporter{metrics: s.metrics}, nil
+		})
+	if err != nil {
+		s.logger.Errorf("Failed to register metrics exporter: %v", err)
+		return err
+	}
+
 	s.logger.Debugf("Building new stream")
-	builder := service.NewStreamBuilder()
+	builder := env.NewStreamBuilder()
 	handler := slog.NewJSONHandler(newBentoLogAdapter(s.logger), nil)
 	builder.SetLogger(slog.New(handler))
 
@@ -59,6 +79,14 @@ func (s *Stream) Start(config map[string]interface{}, mux service.HTTPMultiplexe
 		return err
 	}
 
+	// metrics configured on the stream take precedence over the runtime counters
+	if _, ok := config["metrics"]; !ok {
+		if err := builder.SetMetricsYAML(metricsExporterName + ": {}"); err != nil {
+			s.logger.Errorf("Failed to set metrics: %v", err)
+			return err
+		}
+	}
+
 	if mux != nil {
 		builder.SetHTTPMux(mux)
 	}
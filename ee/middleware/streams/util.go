@@ -2,6 +2,7 @@ package streams
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -13,6 +14,7 @@ type HandleFuncAdapter struct {
 	StreamMiddleware *Middleware
 	Muxer            *mux.Router
 	Logger           *logrus.Entry
+	Metrics          *StreamMetrics
 }
 
 func (h *HandleFuncAdapter) HandleFunc(path string, f func(http.ResponseWriter, *http.Request)) {
@@ -30,12 +32,36 @@ func (h *HandleFuncAdapter) HandleFunc(path string, f func(http.ResponseWriter,
 
 		h.StreamManager.activityCounter.Add(1)
 		defer h.StreamManager.activityCounter.Add(-1)
+
+		kind := noConsumer
+		if h.Metrics != nil {
+			kind = h.Metrics.consumerKind(path, r)
+			defer h.Metrics.trackRequest(kind)()
+		}
+
+		switch kind {
+		case webSocketConsumer:
+			analyticsResponseWriter = &webSocketConsumerWriter{ResponseWriter: analyticsResponseWriter, manager: h.StreamManager}
+		case eventSourceConsumer:
+			defer h.StreamManager.closeEventSourceConsumer(analyticsResponseWriter)
+		}
+
 		f(analyticsResponseWriter, r)
 	})
 	h.StreamManager.routeLock.Unlock()
 	h.Logger.Debugf("Registered handler for path: %s", path)
 }
 
+// hasRequestVariable returns true if the value references a variable resolved from the request.
+func hasRequestVariable(value string) bool {
+	for _, prefix := range requestVariablePrefixes {
+		if strings.Contains(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // Helper function to extract paths from an http_server configuration
 func extractPaths(httpConfig map[string]interface{}) []string {
 	var paths []string
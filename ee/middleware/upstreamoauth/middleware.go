@@ -1,6 +1,7 @@
 package upstreamoauth
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -12,6 +13,9 @@ import (
 	"github.com/TykTechnologies/tyk/internal/service/core"
 )
 
+// ErrTokenUnavailable is returned when the token to authenticate against the upstream can't be obtained.
+var ErrTokenUnavailable = errors.New("upstream OAuth token unavailable")
+
 // Middleware implements upstream OAuth middleware.
 type Middleware struct {
 	Spec model.MergedAPI
@@ -19,6 +23,9 @@ type Middleware struct {
 
 	Base BaseMiddleware
 
+	// LocalCache caches the tokens in memory when set, in front of the storage handlers.
+	LocalCache LocalCache
+
 	clientCredentialsStorageHandler Storage
 	passwordStorageHandler          Storage
 }
@@ -78,13 +85,16 @@ func (m *Middleware) ProcessRequest(_ http.ResponseWriter, r *http.Request, _ in
 
 	payload, err := provider.getOAuthToken(r, m)
 	if err != nil {
-		return fmt.Errorf("failed to get OAuth token: %w", err), http.StatusInternalServerError
+		return fmt.Errorf("%w: %w", ErrTokenUnavailable, err), http.StatusServiceUnavailable
 	}
 
 	upstreamOAuthProvider := Provider{
 		HeaderName: header.Authorization,
 		AuthValue:  payload,
 		Logger:     m.Logger(),
+		refresh: func(r *http.Request) (string, error) {
+			return provider.refreshOAuthToken(r, m)
+		},
 	}
 
 	headerName := provider.getHeaderName(m)
@@ -14,6 +14,7 @@ import (
 
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/event"
 	"github.com/TykTechnologies/tyk/internal/httpclient"
 	"github.com/TykTechnologies/tyk/internal/model"
 )
@@ -316,6 +317,50 @@ func TestMiddleware_ProcessRequest_InvalidConfig(t *testing.T) {
 	assert.Contains(t, err.Error(), "no OAuth configuration selected")
 }
 
+func TestMiddleware_ProcessRequest_TokenUnavailable(t *testing.T) {
+	oauthServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer oauthServer.Close()
+
+	spec := model.MergedAPI{
+		APIDefinition: &apidef.APIDefinition{
+			APIID: "test-api",
+			UpstreamAuth: apidef.UpstreamAuth{
+				Enabled: true,
+				OAuth: apidef.UpstreamOAuth{
+					Enabled: true,
+					ClientCredentials: apidef.ClientCredentials{
+						ClientAuthData: apidef.ClientAuthData{
+							ClientID:     "test-client-id",
+							ClientSecret: "test-client-secret",
+						},
+						TokenURL: oauthServer.URL + "/token",
+					},
+					AllowedAuthorizeTypes: []string{ClientCredentialsAuthorizeType},
+				},
+			},
+		},
+	}
+
+	mw := &mockBaseMiddleware{}
+	mw.On("FireEvent", event.UpstreamOAuthError, mock.AnythingOfType("upstreamoauth.EventUpstreamOAuthMeta"))
+
+	storage := &mockStorage{data: make(map[string]string)}
+	storage.On("GetKey", mock.AnythingOfType("string")).Return("", fmt.Errorf("not found"))
+	storage.On("Lock", mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(true, nil)
+
+	middleware := NewMiddleware(&mockGateway{}, mw, spec, storage, storage)
+
+	req := httptest.NewRequest("GET", "http://example.com", nil)
+	err, statusCode := middleware.ProcessRequest(httptest.NewRecorder(), req, nil)
+
+	assert.ErrorIs(t, err, ErrTokenUnavailable)
+	assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+	mw.AssertCalled(t, "FireEvent", event.UpstreamOAuthError, mock.AnythingOfType("upstreamoauth.EventUpstreamOAuthMeta"))
+	storage.AssertNotCalled(t, "SetKey", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestNewOAuthHeaderProvider(t *testing.T) {
 	tests := []struct {
 		name          string
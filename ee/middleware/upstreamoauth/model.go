@@ -36,6 +36,12 @@ type Storage interface {
 	Lock(key string, timeout time.Duration) (bool, error)
 }
 
+// LocalCache is a subset of cache.Repository, caching the tokens in memory.
+type LocalCache interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{}, ttl int64)
+}
+
 type ClientCredentialsOAuthProvider struct{}
 
 type PerAPIClientCredentialsOAuthProvider struct{}
@@ -45,6 +51,8 @@ type PasswordOAuthProvider struct{}
 type TokenData struct {
 	Token         string                 `json:"token"`
 	ExtraMetadata map[string]interface{} `json:"extra_metadata"`
+	// ExpiresAt is the expiry of the token as a unix timestamp, zero when the token doesn't expire.
+	ExpiresAt int64 `json:"expires_at,omitempty"`
 }
 
 var (
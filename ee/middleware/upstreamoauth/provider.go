@@ -25,6 +25,9 @@ type Provider struct {
 	HeaderName string
 	// AuthValue is the value of auth header.
 	AuthValue string
+
+	// refresh obtains a new auth value, replacing the one rejected by the upstream.
+	refresh func(r *http.Request) (string, error)
 }
 
 // Fill sets the request's HeaderName with AuthValue
@@ -37,12 +40,31 @@ func (u Provider) Fill(r *http.Request) {
 	r.Header.Set(u.HeaderName, u.AuthValue)
 }
 
+// Refresh sets the request's HeaderName with a new auth value after the upstream rejected AuthValue.
+// It reports whether the request can be sent again.
+func (u Provider) Refresh(r *http.Request) bool {
+	if u.refresh == nil {
+		return false
+	}
+
+	authValue, err := u.refresh(r)
+	if err != nil {
+		u.Logger.WithError(err).Error("Failed to refresh the upstream OAuth token.")
+		return false
+	}
+
+	r.Header.Set(u.HeaderName, authValue)
+	return true
+}
+
 type OAuthHeaderProvider interface {
 	// getOAuthToken returns the OAuth token for the request.
 	getOAuthToken(r *http.Request, mw *Middleware) (string, error)
+	// refreshOAuthToken returns a new OAuth token for the request, replacing the cached one.
+	refreshOAuthToken(r *http.Request, mw *Middleware) (string, error)
 	// getHeaderName returns the header name for the OAuth token.
 	getHeaderName(mw *Middleware) string
-	//
+	// headerEnabled reports whether a custom header name is configured.
 	headerEnabled(mw *Middleware) bool
 }
 
@@ -75,6 +97,16 @@ func (p *ClientCredentialsOAuthProvider) getOAuthToken(r *http.Request, mw *Midd
 	return fmt.Sprintf("Bearer %s", token), nil
 }
 
+func (p *ClientCredentialsOAuthProvider) refreshOAuthToken(r *http.Request, mw *Middleware) (string, error) {
+	client := ClientCredentialsClient{mw}
+	token, err := client.RefreshToken(r)
+	if err != nil {
+		return handleOAuthError(r, mw, err)
+	}
+
+	return fmt.Sprintf("Bearer %s", token), nil
+}
+
 func handleOAuthError(r *http.Request, mw *Middleware, err error) (string, error) {
 	mw.FireEvent(r, event.UpstreamOAuthError, err.Error(), mw.Spec.APIID)
 	return "", err
@@ -183,6 +215,16 @@ func (p *PasswordOAuthProvider) getOAuthToken(r *http.Request, mw *Middleware) (
 	return fmt.Sprintf("Bearer %s", token), nil
 }
 
+func (p *PasswordOAuthProvider) refreshOAuthToken(r *http.Request, mw *Middleware) (string, error) {
+	client := PasswordClient{mw}
+	token, err := client.RefreshToken(r)
+	if err != nil {
+		return handleOAuthError(r, mw, err)
+	}
+
+	return fmt.Sprintf("Bearer %s", token), nil
+}
+
 func (p *PasswordOAuthProvider) getHeaderName(OAuthSpec *Middleware) string {
 	return OAuthSpec.Spec.UpstreamAuth.OAuth.PasswordAuthentication.Header.Name
 }
@@ -20,6 +20,15 @@ func (client *ClientCredentialsClient) ObtainToken(ctx context.Context) (*oauth2
 }
 
 func (client *ClientCredentialsClient) GetToken(r *http.Request) (string, error) {
+	return client.token(r, getToken)
+}
+
+// RefreshToken obtains a new token, replacing the cached one.
+func (client *ClientCredentialsClient) RefreshToken(r *http.Request) (string, error) {
+	return client.token(r, refreshToken)
+}
+
+func (client *ClientCredentialsClient) token(r *http.Request, fetch tokenFunc) (string, error) {
 	cacheKey := generateClientCredentialsCacheKey(client.mw.Spec.UpstreamAuth.OAuth, client.mw.Spec.APIID)
 	secret := client.mw.Gw.GetConfig().Secret
 	extraMetadata := client.mw.Spec.UpstreamAuth.OAuth.ClientCredentials.ExtraMetadata
@@ -28,7 +37,7 @@ func (client *ClientCredentialsClient) GetToken(r *http.Request) (string, error)
 		return client.ObtainToken(ctx)
 	}
 
-	return getToken(r, cacheKey, obtainTokenFunc, secret, extraMetadata, client.mw.clientCredentialsStorageHandler)
+	return fetch(r, cacheKey, obtainTokenFunc, secret, extraMetadata, client.mw.clientCredentialsStorageHandler, client.mw.LocalCache)
 }
 
 // getHTTPClient creates an HTTP client with external services configuration if available
@@ -19,6 +19,15 @@ func (client *PasswordClient) ObtainToken(ctx context.Context) (*oauth2.Token, e
 }
 
 func (client *PasswordClient) GetToken(r *http.Request) (string, error) {
+	return client.token(r, getToken)
+}
+
+// RefreshToken obtains a new token, replacing the cached one.
+func (client *PasswordClient) RefreshToken(r *http.Request) (string, error) {
+	return client.token(r, refreshToken)
+}
+
+func (client *PasswordClient) token(r *http.Request, fetch tokenFunc) (string, error) {
 	cacheKey := generatePasswordOAuthCacheKey(client.mw.Spec.UpstreamAuth.OAuth, client.mw.Spec.APIID)
 	secret := client.mw.Gw.GetConfig().Secret
 	extraMetadata := client.mw.Spec.UpstreamAuth.OAuth.PasswordAuthentication.ExtraMetadata
@@ -27,7 +36,7 @@ func (client *PasswordClient) GetToken(r *http.Request) (string, error) {
 		return client.ObtainToken(ctx)
 	}
 
-	return getToken(r, cacheKey, obtainTokenFunc, secret, extraMetadata, client.mw.passwordStorageHandler)
+	return fetch(r, cacheKey, obtainTokenFunc, secret, extraMetadata, client.mw.passwordStorageHandler, client.mw.LocalCache)
 }
 
 // getHTTPClient creates an HTTP client with external services configuration if available
@@ -2,9 +2,12 @@ package upstreamoauth_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -16,6 +19,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/test"
 )
@@ -129,8 +133,129 @@ func TestProvider_ClientCredentialsAuthorizeType(t *testing.T) {
 
 }
 
+func TestProvider_ClientCredentialsTokenLifecycle(t *testing.T) {
+	tst := StartTest(func(globalConf *config.Config) {
+		globalConf.Secrets = map[string]string{"upstream-client-secret": "CLIENT_SECRET"}
+	})
+	t.Cleanup(tst.Close)
+
+	var tokenRequests atomic.Int64
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/token" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		clientID, clientSecret, _ := r.BasicAuth()
+		if clientID != "CLIENT_ID" || clientSecret != "CLIENT_SECRET" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		n := tokenRequests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		// The token is cached for its lifetime minus the 30s expiry skew, past the 5s token lock.
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":36}`, n)
+	}))
+	t.Cleanup(tokenServer.Close)
+
+	var (
+		mu       sync.Mutex
+		rejected = map[string]bool{}
+	)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		auth := r.Header.Get(header.Authorization)
+		if rejected[auth] || rejected["*"] {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		_, _ = fmt.Fprintf(w, "%s|%s", auth, body)
+	}))
+	t.Cleanup(upstream.Close)
+
+	reject := func(auth string) {
+		mu.Lock()
+		defer mu.Unlock()
+		rejected = map[string]bool{auth: true}
+	}
+
+	loadAPI := func(listenPath, tokenURL string) {
+		tst.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.APIID = listenPath
+			spec.Proxy.ListenPath = "/" + listenPath + "/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.UseKeylessAccess = true
+			spec.UpstreamAuth = apidef.UpstreamAuth{
+				Enabled: true,
+				OAuth: apidef.UpstreamOAuth{
+					Enabled: true,
+					ClientCredentials: apidef.ClientCredentials{
+						ClientAuthData: apidef.ClientAuthData{
+							ClientID:     "CLIENT_ID",
+							ClientSecret: "secrets://upstream-client-secret",
+						},
+						TokenURL: tokenURL,
+					},
+					AllowedAuthorizeTypes: []string{apidef.OAuthAuthorizationTypeClientCredentials},
+				},
+			}
+		})
+	}
+
+	loadAPI("upstream-oauth-lifecycle", tokenServer.URL+"/token")
+
+	t.Run("token is cached", func(t *testing.T) {
+		_, _ = tst.Run(t, test.TestCases{
+			{Path: "/upstream-oauth-lifecycle/", Code: http.StatusOK, BodyMatch: "^Bearer token-1"},
+			{Path: "/upstream-oauth-lifecycle/", Code: http.StatusOK, BodyMatch: "^Bearer token-1"},
+		}...)
+
+		assert.Equal(t, int64(1), tokenRequests.Load())
+	})
+
+	t.Run("token is refreshed once when rejected", func(t *testing.T) {
+		reject("Bearer token-1")
+		_, _ = tst.Run(t, test.TestCase{Method: http.MethodPost, Path: "/upstream-oauth-lifecycle/", Data: "payload",
+			Code: http.StatusOK, BodyMatch: `^Bearer token-2\|payload$`})
+		assert.Equal(t, int64(2), tokenRequests.Load())
+
+		reject("*")
+		_, _ = tst.Run(t, test.TestCase{Path: "/upstream-oauth-lifecycle/", Code: http.StatusUnauthorized})
+		assert.Equal(t, int64(3), tokenRequests.Load())
+
+		reject("")
+		_, _ = tst.Run(t, test.TestCase{Path: "/upstream-oauth-lifecycle/", Code: http.StatusOK, BodyMatch: "^Bearer token-3"})
+		assert.Equal(t, int64(3), tokenRequests.Load())
+	})
+
+	t.Run("token is refreshed on expiry", func(t *testing.T) {
+		time.Sleep(6500 * time.Millisecond)
+
+		_, _ = tst.Run(t, test.TestCases{
+			{Path: "/upstream-oauth-lifecycle/", Code: http.StatusOK, BodyMatch: "^Bearer token-4"},
+			{Path: "/upstream-oauth-lifecycle/", Code: http.StatusOK, BodyMatch: "^Bearer token-4"},
+		}...)
+
+		assert.Equal(t, int64(4), tokenRequests.Load())
+	})
+
+	t.Run("token unavailable", func(t *testing.T) {
+		loadAPI("upstream-oauth-unavailable", tokenServer.URL+"/unavailable")
+
+		_, _ = tst.Run(t, test.TestCase{Path: "/upstream-oauth-unavailable/", Code: http.StatusServiceUnavailable,
+			BodyMatch: upstreamoauth.ErrTokenUnavailable.Error()})
+	})
+}
+
 func TestProvider_PasswordAuthorizeType(t *testing.T) {
-	tst := StartTest(nil)
+	tst := StartTest(func(globalConf *config.Config) {
+		globalConf.Secrets = map[string]string{"upstream-password": "password1"}
+	})
 	t.Cleanup(tst.Close)
 
 	var requestCount int
@@ -173,7 +298,7 @@ func TestProvider_PasswordAuthorizeType(t *testing.T) {
 			ClientSecret: "CLIENT_SECRET",
 		},
 		Username:      "user1",
-		Password:      "password1",
+		Password:      "secrets://upstream-password",
 		TokenURL:      ts.URL + "/token",
 		Scopes:        []string{"scope1", "scope2"},
 		Header:        apidef.AuthSource{Enabled: true, Name: "Authorization"},
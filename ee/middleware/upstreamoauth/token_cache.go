@@ -19,7 +19,32 @@ type Cache interface {
 	ObtainToken(ctx context.Context) (*oauth2.Token, error)
 }
 
-func getToken(r *http.Request, cacheKey string, obtainTokenFunc func(context.Context) (*oauth2.Token, error), secret string, extraMetadata []string, cache Storage) (string, error) {
+// tokenExpirySkew is subtracted from the lifetime of a token when it's cached, so it's
+// renewed before it expires rather than being rejected by the upstream.
+const tokenExpirySkew = 30 * time.Second
+
+// localTokenPrefix prefixes the keys of the tokens cached in memory.
+const localTokenPrefix = "upstreamOAuth-"
+
+// localToken is the token cached in memory, saving a Redis lookup and decryption per request.
+type localToken struct {
+	AccessToken   string
+	ExtraMetadata map[string]interface{}
+}
+
+// tokenFunc returns the token identified by cacheKey, using obtainTokenFunc to request it from the OAuth provider.
+type tokenFunc func(r *http.Request, cacheKey string, obtainTokenFunc func(context.Context) (*oauth2.Token, error), secret string, extraMetadata []string, cache Storage, local LocalCache) (string, error)
+
+func getToken(r *http.Request, cacheKey string, obtainTokenFunc func(context.Context) (*oauth2.Token, error), secret string, extraMetadata []string, cache Storage, local LocalCache) (string, error) {
+	if local != nil {
+		if cached, found := local.Get(localTokenPrefix + cacheKey); found {
+			if token, ok := cached.(localToken); ok {
+				SetExtraMetadata(r, extraMetadata, token.ExtraMetadata)
+				return token.AccessToken, nil
+			}
+		}
+	}
+
 	tokenData, err := retryGetKeyAndLock(cacheKey, cache)
 	if err != nil {
 		return "", err
@@ -32,9 +57,21 @@ func getToken(r *http.Request, cacheKey string, obtainTokenFunc func(context.Con
 		}
 		decryptedToken := crypto.Decrypt(crypto.GetPaddedString(secret), tokenContents.Token)
 		SetExtraMetadata(r, extraMetadata, tokenContents.ExtraMetadata)
+
+		var expiry time.Time
+		if tokenContents.ExpiresAt > 0 {
+			expiry = time.Unix(tokenContents.ExpiresAt, 0)
+		}
+		setLocalToken(local, cacheKey, localToken{decryptedToken, tokenContents.ExtraMetadata}, expiry)
+
 		return decryptedToken, nil
 	}
 
+	return refreshToken(r, cacheKey, obtainTokenFunc, secret, extraMetadata, cache, local)
+}
+
+// refreshToken obtains a new token from the OAuth provider and caches it, replacing the cached one.
+func refreshToken(r *http.Request, cacheKey string, obtainTokenFunc func(context.Context) (*oauth2.Token, error), secret string, extraMetadata []string, cache Storage, local LocalCache) (string, error) {
 	token, err := obtainTokenFunc(r.Context())
 	if err != nil {
 		return "", err
@@ -48,10 +85,20 @@ func getToken(r *http.Request, cacheKey string, obtainTokenFunc func(context.Con
 	metadataMap := BuildMetadataMap(token, extraMetadata)
 	SetExtraMetadata(r, extraMetadata, metadataMap)
 
-	ttl := time.Until(token.Expiry)
+	// A token without expiry is cached without a TTL.
+	var ttl time.Duration
+	if !token.Expiry.IsZero() {
+		ttl = time.Until(token.Expiry) - tokenExpirySkew
+		if ttl < time.Second {
+			// Expiring too soon to be reused.
+			return token.AccessToken, nil
+		}
+	}
+
 	if err := setTokenInCache(cache, cacheKey, string(tokenDataBytes), ttl); err != nil {
 		return "", err
 	}
+	setLocalToken(local, cacheKey, localToken{token.AccessToken, metadataMap}, token.Expiry)
 
 	return token.AccessToken, nil
 }
@@ -61,11 +108,32 @@ func setTokenInCache(cache Storage, cacheKey string, token string, ttl time.Dura
 	return cache.SetKey(cacheKey, token, int64(time.Until(oauthTokenExpiry).Seconds()))
 }
 
+// setLocalToken caches the token in memory until its expiry minus tokenExpirySkew, a token without
+// expiry is cached for the default expiration of the cache.
+func setLocalToken(local LocalCache, cacheKey string, token localToken, expiry time.Time) {
+	if local == nil {
+		return
+	}
+
+	var ttl int64
+	if !expiry.IsZero() {
+		ttl = int64((time.Until(expiry) - tokenExpirySkew).Seconds())
+		if ttl < 1 {
+			return
+		}
+	}
+
+	local.Set(localTokenPrefix+cacheKey, token, ttl)
+}
+
 func CreateTokenDataBytes(encryptedToken string, token *oauth2.Token, extraMetadataKeys []string) ([]byte, error) {
 	td := TokenData{
 		Token:         encryptedToken,
 		ExtraMetadata: BuildMetadataMap(token, extraMetadataKeys),
 	}
+	if !token.Expiry.IsZero() {
+		td.ExpiresAt = token.Expiry.Unix()
+	}
 	return json.Marshal(td)
 }
 
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2"
 
+	"github.com/TykTechnologies/tyk/internal/cache"
 	"github.com/TykTechnologies/tyk/internal/crypto"
 )
 
@@ -50,7 +51,7 @@ func TestGetToken_CacheHit(t *testing.T) {
 	}
 
 	// Execute
-	token, err := getToken(req, cacheKey, obtainTokenFunc, secret, extraMetadata, storage)
+	token, err := getToken(req, cacheKey, obtainTokenFunc, secret, extraMetadata, storage, nil)
 
 	// Assertions
 	assert.NoError(t, err)
@@ -90,7 +91,7 @@ func TestGetToken_CacheMiss(t *testing.T) {
 	}
 
 	// Execute
-	token, err := getToken(req, cacheKey, obtainTokenFunc, secret, extraMetadata, storage)
+	token, err := getToken(req, cacheKey, obtainTokenFunc, secret, extraMetadata, storage, nil)
 
 	// Assertions
 	assert.NoError(t, err)
@@ -121,7 +122,7 @@ func TestGetToken_ObtainTokenError(t *testing.T) {
 	}
 
 	// Execute
-	token, err := getToken(req, cacheKey, obtainTokenFunc, secret, []string{}, storage)
+	token, err := getToken(req, cacheKey, obtainTokenFunc, secret, []string{}, storage, nil)
 
 	// Assertions
 	assert.Error(t, err)
@@ -129,6 +130,131 @@ func TestGetToken_ObtainTokenError(t *testing.T) {
 	assert.Empty(t, token)
 }
 
+func TestGetToken_LocalCache(t *testing.T) {
+	secret := "test-secret"
+	cacheKey := "test-cache-key"
+	extraMetadata := []string{"scope"}
+
+	storage := &mockStorage{data: make(map[string]string)}
+	storage.On("GetKey", cacheKey).Return("", fmt.Errorf("not found"))
+	storage.On("Lock", cacheKey+":lock", mock.AnythingOfType("time.Duration")).Return(true, nil)
+	storage.On("SetKey", cacheKey, mock.AnythingOfType("string"), mock.AnythingOfType("int64")).Return(nil)
+
+	local := cache.New(60, 60)
+	defer local.Close()
+
+	var obtained int
+	obtainTokenFunc := func(_ context.Context) (*oauth2.Token, error) {
+		obtained++
+		token := &oauth2.Token{AccessToken: "local-access-token", Expiry: time.Now().Add(time.Hour)}
+		return token.WithExtra(map[string]interface{}{"scope": "read"}), nil
+	}
+
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	token, err := getToken(req, cacheKey, obtainTokenFunc, secret, extraMetadata, storage, local)
+	require.NoError(t, err)
+	assert.Equal(t, "local-access-token", token)
+
+	// The second request is served from memory, without hitting the storage.
+	req, _ = http.NewRequest("GET", "http://example.com", nil)
+	token, err = getToken(req, cacheKey, obtainTokenFunc, secret, extraMetadata, storage, local)
+	require.NoError(t, err)
+	assert.Equal(t, "local-access-token", token)
+	assert.Equal(t, "read", CtxGetData(req)["scope"])
+
+	assert.Equal(t, 1, obtained)
+	storage.AssertNumberOfCalls(t, "GetKey", 1)
+}
+
+func TestGetToken_LocalCacheFromStorage(t *testing.T) {
+	secret := "test-secret"
+	cacheKey := "test-cache-key"
+
+	tokenData := TokenData{
+		Token:     crypto.Encrypt(crypto.GetPaddedString(secret), "stored-access-token"),
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}
+	tokenDataBytes, err := json.Marshal(tokenData)
+	require.NoError(t, err)
+
+	storage := &mockStorage{data: map[string]string{cacheKey: string(tokenDataBytes)}}
+	storage.On("GetKey", cacheKey).Return(string(tokenDataBytes), nil)
+
+	local := cache.New(60, 60)
+	defer local.Close()
+
+	obtainTokenFunc := func(_ context.Context) (*oauth2.Token, error) {
+		t.Fatal("obtainTokenFunc should not be called for cache hit")
+		return nil, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+		token, err := getToken(req, cacheKey, obtainTokenFunc, secret, nil, storage, local)
+		require.NoError(t, err)
+		assert.Equal(t, "stored-access-token", token)
+	}
+
+	storage.AssertNumberOfCalls(t, "GetKey", 1)
+}
+
+func TestRefreshToken(t *testing.T) {
+	secret := "test-secret"
+	cacheKey := "test-cache-key"
+
+	newStorage := func() *mockStorage {
+		storage := &mockStorage{data: make(map[string]string)}
+		storage.On("SetKey", cacheKey, mock.AnythingOfType("string"), mock.AnythingOfType("int64")).Return(nil)
+		return storage
+	}
+
+	obtain := func(expiry time.Time) func(context.Context) (*oauth2.Token, error) {
+		return func(_ context.Context) (*oauth2.Token, error) {
+			return &oauth2.Token{AccessToken: "refreshed-access-token", Expiry: expiry}, nil
+		}
+	}
+
+	t.Run("ttl is shortened by the expiry skew", func(t *testing.T) {
+		storage := newStorage()
+		local := cache.New(60, 60)
+		defer local.Close()
+		local.Set(localTokenPrefix+cacheKey, localToken{AccessToken: "rejected-access-token"}, 0)
+
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+		token, err := refreshToken(req, cacheKey, obtain(time.Now().Add(time.Hour)), secret, nil, storage, local)
+		require.NoError(t, err)
+		assert.Equal(t, "refreshed-access-token", token)
+
+		ttl := storage.Calls[0].Arguments.Get(2).(int64)
+		assert.InDelta(t, (time.Hour - tokenExpirySkew).Seconds(), ttl, 1)
+
+		cached, found := local.Get(localTokenPrefix + cacheKey)
+		require.True(t, found)
+		assert.Equal(t, "refreshed-access-token", cached.(localToken).AccessToken)
+	})
+
+	t.Run("token expiring within the skew isn't cached", func(t *testing.T) {
+		storage := newStorage()
+
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+		token, err := refreshToken(req, cacheKey, obtain(time.Now().Add(tokenExpirySkew/2)), secret, nil, storage, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "refreshed-access-token", token)
+
+		storage.AssertNotCalled(t, "SetKey", cacheKey, mock.AnythingOfType("string"), mock.AnythingOfType("int64"))
+	})
+
+	t.Run("token without expiry is cached without ttl", func(t *testing.T) {
+		storage := newStorage()
+
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+		_, err := refreshToken(req, cacheKey, obtain(time.Time{}), secret, nil, storage, nil)
+		require.NoError(t, err)
+
+		storage.AssertCalled(t, "SetKey", cacheKey, mock.AnythingOfType("string"), int64(0))
+	})
+}
+
 func TestSetTokenInCache(t *testing.T) {
 	storage := &mockStorage{data: make(map[string]string)}
 	storage.On("SetKey", "test-key", "test-token", mock.AnythingOfType("int64")).Return(nil)
@@ -0,0 +1,241 @@
+package gateway
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/httputil"
+)
+
+const defaultAdmissionMaxWait = time.Second
+
+var (
+	errAdmissionQueueFull = errors.New("Gateway is overloaded, the request queue is full")
+	errAdmissionTimeout   = errors.New("Gateway is overloaded, the request timed out in the queue")
+)
+
+// AdmissionControlStats are the counters of the admission controller, reported by the health check.
+type AdmissionControlStats struct {
+	// Active is the number of requests being proxied.
+	Active int `json:"active"`
+	// Queued is the number of requests waiting for a slot.
+	Queued int `json:"queued"`
+	// Shed is the number of requests rejected since the Gateway started.
+	Shed int64 `json:"shed"`
+}
+
+// admissionWaiter is a request waiting in the queue for a slot.
+type admissionWaiter struct {
+	apiID string
+	ready chan struct{}
+}
+
+// admissionController bounds the number of requests proxied at once by the Gateway. The requests above
+// the limit wait in a bounded FIFO queue, skipping the waiters of the APIs which already take their share.
+type admissionController struct {
+	maxActive    int
+	maxQueued    int
+	maxAPIActive int
+	maxAPIQueued int
+	maxWait      time.Duration
+	retryAfter   int
+
+	mu        sync.Mutex
+	active    int
+	apiActive map[string]int
+	apiQueued map[string]int
+	queue     *list.List
+	shed      int64
+}
+
+func newAdmissionController(conf config.AdmissionControlConfig) *admissionController {
+	share := conf.MaxAPIShare
+	if share <= 0 || share > 100 {
+		share = 100
+	}
+
+	c := &admissionController{
+		maxActive:    conf.MaxConcurrentRequests,
+		maxQueued:    conf.QueueSize,
+		maxAPIActive: max(1, conf.MaxConcurrentRequests*share/100),
+		maxAPIQueued: max(1, conf.QueueSize*share/100),
+		maxWait:      conf.MaxWait,
+		retryAfter:   conf.RetryAfter,
+		apiActive:    make(map[string]int),
+		apiQueued:    make(map[string]int),
+		queue:        list.New(),
+	}
+
+	if c.maxWait <= 0 {
+		c.maxWait = defaultAdmissionMaxWait
+	}
+	if c.retryAfter <= 0 {
+		c.retryAfter = 1
+	}
+
+	return c
+}
+
+// getAdmissionController returns the admission controller, or nil if it's disabled.
+// It's created only once, so the requests in flight are counted across reloads.
+func (gw *Gateway) getAdmissionController() *admissionController {
+	gw.admissionControllerOnce.Do(func() {
+		conf := gw.GetConfig().AdmissionControl
+		if !conf.Enabled || conf.MaxConcurrentRequests <= 0 {
+			return
+		}
+
+		gw.admissionController = newAdmissionController(conf)
+	})
+
+	return gw.admissionController
+}
+
+// acquire takes a slot for a request of the API, waiting in the queue until one is free.
+// The returned function releases the slot.
+func (c *admissionController) acquire(ctx context.Context, apiID string) (func(), error) {
+	c.mu.Lock()
+
+	if c.active < c.maxActive && c.apiActive[apiID] < c.maxAPIActive {
+		c.take(apiID)
+		c.mu.Unlock()
+		return c.releaseFunc(apiID), nil
+	}
+
+	if c.queue.Len() >= c.maxQueued || c.apiQueued[apiID] >= c.maxAPIQueued {
+		c.shed++
+		c.mu.Unlock()
+		return nil, errAdmissionQueueFull
+	}
+
+	waiter := &admissionWaiter{apiID: apiID, ready: make(chan struct{})}
+	elem := c.queue.PushBack(waiter)
+	c.apiQueued[apiID]++
+	c.mu.Unlock()
+
+	timer := time.NewTimer(c.maxWait)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-waiter.ready:
+		return c.releaseFunc(apiID), nil
+	case <-timer.C:
+		err = errAdmissionTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-waiter.ready:
+		// The slot was granted while giving up, hand it over to the next waiter.
+		c.releaseLocked(apiID)
+	default:
+		c.queue.Remove(elem)
+		c.decrement(c.apiQueued, apiID)
+	}
+
+	if errors.Is(err, errAdmissionTimeout) {
+		c.shed++
+	}
+
+	return nil, err
+}
+
+func (c *admissionController) take(apiID string) {
+	c.active++
+	c.apiActive[apiID]++
+}
+
+func (c *admissionController) releaseFunc(apiID string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.releaseLocked(apiID)
+		})
+	}
+}
+
+// releaseLocked frees the slot of the API and grants the free slots to the first waiters
+// of the APIs below their share.
+func (c *admissionController) releaseLocked(apiID string) {
+	c.active--
+	c.decrement(c.apiActive, apiID)
+
+	for elem := c.queue.Front(); elem != nil && c.active < c.maxActive; {
+		next := elem.Next()
+
+		waiter := elem.Value.(*admissionWaiter)
+		if c.apiActive[waiter.apiID] < c.maxAPIActive {
+			c.queue.Remove(elem)
+			c.decrement(c.apiQueued, waiter.apiID)
+			c.take(waiter.apiID)
+			close(waiter.ready)
+		}
+
+		elem = next
+	}
+}
+
+func (c *admissionController) decrement(counts map[string]int, apiID string) {
+	counts[apiID]--
+	if counts[apiID] <= 0 {
+		delete(counts, apiID)
+	}
+}
+
+// stats returns the current counters of the admission controller.
+func (c *admissionController) stats() AdmissionControlStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return AdmissionControlStats{
+		Active: c.active,
+		Queued: c.queue.Len(),
+		Shed:   c.shed,
+	}
+}
+
+// full reports whether the queue can't take any more requests.
+func (c *admissionController) full() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.active >= c.maxActive && c.queue.Len() >= c.maxQueued
+}
+
+// handler wraps the proxy handler of the API, so the requests are proxied once admitted.
+// The rejected requests are answered with a 503 through the error handler of the API.
+func (c *admissionController) handler(spec *APISpec, errorHandler ErrorHandler, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Looping requests already hold the slot of the original request.
+		if ctxLoopLevel(r) > 0 || httputil.IsStreamingRequest(r) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		release, err := c.acquire(r.Context(), spec.APIID)
+		if err != nil {
+			errorHandler.Logger().WithError(err).Warning("Request rejected by the admission controller")
+
+			w.Header().Set(header.RetryAfter, strconv.Itoa(c.retryAfter))
+			errorHandler.HandleError(w, r, err.Error(), http.StatusServiceUnavailable, true)
+			return
+		}
+		defer release()
+
+		h.ServeHTTP(w, r)
+	})
+}
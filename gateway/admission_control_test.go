@@ -0,0 +1,152 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestAdmissionController(t *testing.T) {
+	c := newAdmissionController(config.AdmissionControlConfig{
+		MaxConcurrentRequests: 2,
+		QueueSize:             2,
+		MaxAPIShare:           50,
+		MaxWait:               time.Second,
+	})
+
+	releaseA, err := c.acquire(context.Background(), "a")
+	require.NoError(t, err)
+
+	granted := make(chan func(), 1)
+	go func() {
+		release, err := c.acquire(context.Background(), "a")
+		assert.NoError(t, err)
+		granted <- release
+	}()
+
+	require.Eventually(t, func() bool {
+		return c.stats().Queued == 1
+	}, time.Second, time.Millisecond, "the API takes its share of the slots, the next request waits")
+
+	_, err = c.acquire(context.Background(), "a")
+	assert.ErrorIs(t, err, errAdmissionQueueFull, "the API takes its share of the queue")
+
+	releaseB, err := c.acquire(context.Background(), "b")
+	require.NoError(t, err, "another API still gets a slot")
+
+	releaseA()
+	select {
+	case release := <-granted:
+		release()
+	case <-time.After(time.Second):
+		t.Fatal("the waiting request wasn't granted the freed slot")
+	}
+	releaseB()
+
+	t.Run("max wait", func(t *testing.T) {
+		c := newAdmissionController(config.AdmissionControlConfig{
+			MaxConcurrentRequests: 1,
+			QueueSize:             1,
+			MaxWait:               10 * time.Millisecond,
+		})
+
+		release, err := c.acquire(context.Background(), "a")
+		require.NoError(t, err)
+		defer release()
+
+		_, err = c.acquire(context.Background(), "b")
+		assert.ErrorIs(t, err, errAdmissionTimeout)
+		assert.Equal(t, AdmissionControlStats{Active: 1, Shed: 1}, c.stats())
+	})
+}
+
+func TestAdmissionControl(t *testing.T) {
+	unblock := make(chan struct{})
+	slowUpstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-unblock:
+		case <-r.Context().Done():
+		}
+	}))
+	defer slowUpstream.Close()
+
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.AdmissionControl = config.AdmissionControlConfig{
+			Enabled:               true,
+			MaxConcurrentRequests: 4,
+			QueueSize:             4,
+			MaxWait:               10 * time.Second,
+			MaxAPIShare:           50,
+			RetryAfter:            5,
+		}
+	})
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "slow"
+		spec.Proxy.ListenPath = "/slow/"
+		spec.Proxy.TargetURL = slowUpstream.URL
+	}, func(spec *APISpec) {
+		spec.APIID = "fast"
+		spec.Proxy.ListenPath = "/fast/"
+	})
+
+	admission := ts.Gw.getAdmissionController()
+	require.NotNil(t, admission)
+
+	const slowRequests = 8
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		codes = map[int]int{}
+	)
+
+	for i := 0; i < slowRequests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			resp, err := http.Get(ts.URL + "/slow/")
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode == http.StatusServiceUnavailable {
+				assert.Equal(t, "5", resp.Header.Get(header.RetryAfter))
+			}
+
+			mu.Lock()
+			codes[resp.StatusCode]++
+			mu.Unlock()
+		}()
+	}
+
+	require.Eventually(t, func() bool {
+		stats := admission.stats()
+		return stats.Active == 2 && stats.Queued == 2 && stats.Shed == slowRequests-4
+	}, 5*time.Second, 10*time.Millisecond, "the slow API takes half of the slots and of the queue")
+
+	_, _ = ts.Run(t, test.TestCase{Path: "/fast/", Code: http.StatusOK})
+
+	item, ok := ts.Gw.admissionControlHealthCheck()
+	require.True(t, ok)
+	assert.Equal(t, AdmissionControlStats{Active: 2, Queued: 2, Shed: slowRequests - 4}, item.ObservedValue)
+
+	close(unblock)
+	wg.Wait()
+
+	assert.Equal(t, map[int]int{http.StatusOK: 4, http.StatusServiceUnavailable: slowRequests - 4}, codes)
+	assert.Equal(t, AdmissionControlStats{Shed: slowRequests - 4}, admission.stats())
+}
@@ -13,6 +13,7 @@ import (
 
 	maxminddb "github.com/oschwald/maxminddb-golang"
 
+	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/regexp"
 	"github.com/TykTechnologies/tyk/storage"
@@ -48,12 +49,15 @@ type RedisAnalyticsHandler struct {
 	globalConf                  config.Config
 	recordsChan                 chan *analytics.AnalyticsRecord
 	workerBufferSize            uint64
+	flushInterval               time.Duration
+	dropOldest                  bool
+	droppedRecords              atomic.Uint64
 	shouldStop                  uint32
 	poolWg                      sync.WaitGroup
 	enableMultipleAnalyticsKeys bool
 	Clean                       Purger
 	Gw                          *Gateway `json:"-"`
-	mu                          sync.Mutex
+	mu                          sync.RWMutex
 	analyticsSerializer         serializer.AnalyticsSerializer
 
 	// testing purposes
@@ -72,11 +76,29 @@ func (r *RedisAnalyticsHandler) Init() {
 	}
 
 	r.Store.Connect()
-	ps := r.Gw.GetConfig().AnalyticsConfig.PoolSize
+	ps := r.globalConf.AnalyticsConfig.PoolSize
 	recordsBufferSize := r.globalConf.AnalyticsConfig.RecordsBufferSize
 
 	r.workerBufferSize = recordsBufferSize / uint64(ps)
+	if r.globalConf.AnalyticsConfig.RecordsBatchSize > 0 {
+		r.workerBufferSize = r.globalConf.AnalyticsConfig.RecordsBatchSize
+	}
 	log.WithField("workerBufferSize", r.workerBufferSize).Debug("Analytics pool worker buffer size")
+
+	r.flushInterval = recordsBufferFlushInterval
+	if r.globalConf.AnalyticsConfig.RecordsFlushInterval > 0 {
+		r.flushInterval = time.Duration(r.globalConf.AnalyticsConfig.RecordsFlushInterval) * time.Millisecond
+	}
+
+	switch r.globalConf.AnalyticsConfig.OverflowPolicy {
+	case "", config.AnalyticsOverflowBlock:
+	case config.AnalyticsOverflowDropOldest:
+		r.dropOldest = true
+	default:
+		log.WithField("overflowPolicy", r.globalConf.AnalyticsConfig.OverflowPolicy).
+			Warning("Unknown analytics overflow policy, defaulting to block")
+	}
+
 	r.enableMultipleAnalyticsKeys = r.globalConf.AnalyticsConfig.EnableMultipleAnalyticsKeys
 	r.analyticsSerializer = serializer.NewAnalyticsSerializer(r.globalConf.AnalyticsConfig.SerializerType)
 
@@ -87,7 +109,7 @@ func (r *RedisAnalyticsHandler) Init() {
 func (r *RedisAnalyticsHandler) Start() {
 	r.recordsChan = make(chan *analytics.AnalyticsRecord, r.globalConf.AnalyticsConfig.RecordsBufferSize)
 	atomic.SwapUint32(&r.shouldStop, 0)
-	for i := 0; i < r.Gw.GetConfig().AnalyticsConfig.PoolSize; i++ {
+	for i := 0; i < r.globalConf.AnalyticsConfig.PoolSize; i++ {
 		r.poolWg.Add(1)
 		go r.recordWorker()
 	}
@@ -116,6 +138,11 @@ func (r *RedisAnalyticsHandler) Flush() {
 	r.Start()
 }
 
+// DroppedRecords returns the number of records dropped by the drop_oldest overflow policy.
+func (r *RedisAnalyticsHandler) DroppedRecords() uint64 {
+	return r.droppedRecords.Load()
+}
+
 // RecordHit will store an analytics.Record in Redis
 func (r *RedisAnalyticsHandler) RecordHit(record *analytics.AnalyticsRecord) error {
 	if r.mockEnabled {
@@ -123,6 +150,10 @@ func (r *RedisAnalyticsHandler) RecordHit(record *analytics.AnalyticsRecord) err
 		return nil
 	}
 
+	// the read lock lets records be sent concurrently, while keeping Stop from closing the channel meanwhile
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	// check if we should stop sending records 1st
 	if atomic.LoadUint32(&r.shouldStop) > 0 {
 		return nil
@@ -130,11 +161,25 @@ func (r *RedisAnalyticsHandler) RecordHit(record *analytics.AnalyticsRecord) err
 
 	// just send record to channel consumed by pool of workers
 	// leave all data crunching and Redis I/O work for pool workers
-	r.mu.Lock()
-	r.recordsChan <- record
-	r.mu.Unlock()
+	if !r.dropOldest {
+		r.recordsChan <- record
+		return nil
+	}
+
+	for {
+		select {
+		case r.recordsChan <- record:
+			return nil
+		default:
+		}
 
-	return nil
+		// the queue is full, make room by dropping the oldest record
+		select {
+		case <-r.recordsChan:
+			r.droppedRecords.Add(1)
+		default:
+		}
+	}
 }
 
 func (r *RedisAnalyticsHandler) recordWorker() {
@@ -158,7 +203,7 @@ func (r *RedisAnalyticsHandler) recordWorker() {
 
 		readyToSend := false
 
-		flushTimer := time.NewTimer(recordsBufferFlushInterval)
+		flushTimer := time.NewTimer(r.flushInterval)
 
 		select {
 		case record, ok := <-r.recordsChan:
@@ -176,8 +221,13 @@ func (r *RedisAnalyticsHandler) recordWorker() {
 
 			// we have new record - prepare it and add to buffer
 
+			// Truncate and redact the request and response stored by detailed recording
+			r.sanitiseDetailedRecord(record)
+
 			// If we are obfuscating API Keys, store the hashed representation (config check handled in hashing function)
-			record.APIKey = storage.HashKey(record.APIKey, r.globalConf.HashKeys)
+			if r.globalConf.HashKeys {
+				record.APIKey = storage.HashStr(record.APIKey, r.globalConf.KeyHashAlgorithm())
+			}
 
 			if r.globalConf.SlaveOptions.UseRPC {
 				// Extend tag list to include this data so wecan segment by node if necessary
@@ -238,6 +288,36 @@ func DurationToMillisecond(d time.Duration) float64 {
 	return float64(d) / 1e6
 }
 
+// urlNormalisePattern is a compiled apidef.URLNormalisePattern.
+type urlNormalisePattern struct {
+	regex       *regexp.Regexp
+	replacement string
+}
+
+func compileNormaliseURLPatterns(patterns []apidef.URLNormalisePattern) ([]urlNormalisePattern, error) {
+	compiled := make([]urlNormalisePattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern.Regex)
+		if err != nil {
+			return nil, fmt.Errorf(apidef.ErrInvalidNormaliseURLPattern, pattern.Regex, err)
+		}
+		compiled = append(compiled, urlNormalisePattern{regex: re, replacement: pattern.Replacement})
+	}
+	return compiled, nil
+}
+
+// normaliseAnalyticsPath applies the URL normalisation patterns of the API to the path of the record,
+// followed by the gateway ones if normalisation is enabled.
+func (s *APISpec) normaliseAnalyticsPath(a *analytics.AnalyticsRecord) {
+	for _, pattern := range s.normaliseURLPatterns {
+		a.Path = pattern.regex.ReplaceAllString(a.Path, pattern.replacement)
+	}
+
+	if s.GlobalConfig.AnalyticsConfig.NormaliseUrls.Enabled {
+		NormalisePath(a, &s.GlobalConfig)
+	}
+}
+
 func NormalisePath(a *analytics.AnalyticsRecord, globalConfig *config.Config) {
 
 	if globalConfig.AnalyticsConfig.NormaliseUrls.NormaliseUUIDs {
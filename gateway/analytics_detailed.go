@@ -0,0 +1,280 @@
+package gateway
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+)
+
+const (
+	// redactedValue replaces the values of redacted headers and JSON fields in detailed records.
+	redactedValue = "***"
+
+	// truncatedBodyIndicator is appended to the bodies truncated in detailed records.
+	truncatedBodyIndicator = "...[truncated %d bytes]"
+
+	// maxDecodedDetailedBodySize caps the size of a compressed body decoded for redaction.
+	maxDecodedDetailedBodySize = 10 << 20
+)
+
+var (
+	wireFormatHeaderEnd = []byte("\r\n\r\n")
+	wireFormatLineEnd   = []byte("\r\n")
+
+	errDetailedBodyTooLarge = errors.New("decoded body is too large")
+)
+
+// detailedRecordingConfig returns the limits of detailed recording for an API. The body size limit of the API
+// takes precedence over the gateway one, its redaction lists extend the gateway ones.
+func (r *RedisAnalyticsHandler) detailedRecordingConfig(apiID string) apidef.DetailedRecordingConfig {
+	conf := r.globalConf.AnalyticsConfig.DetailedRecording
+
+	spec := r.Gw.getApiSpec(apiID)
+	if spec == nil {
+		return conf
+	}
+
+	apiConf := spec.DetailedRecordingConfig
+	if apiConf.MaxBodySize > 0 {
+		conf.MaxBodySize = apiConf.MaxBodySize
+	}
+
+	conf.RedactHeaders = append(append([]string{}, conf.RedactHeaders...), apiConf.RedactHeaders...)
+	conf.RedactFields = append(append([]string{}, conf.RedactFields...), apiConf.RedactFields...)
+
+	return conf
+}
+
+// sanitiseDetailedRecord truncates the bodies and redacts the configured headers and JSON fields
+// of the request and response stored by detailed recording.
+func (r *RedisAnalyticsHandler) sanitiseDetailedRecord(record *analytics.AnalyticsRecord) {
+	if record.RawRequest == "" && record.RawResponse == "" {
+		return
+	}
+
+	conf := r.detailedRecordingConfig(record.APIID)
+	if conf.MaxBodySize <= 0 && len(conf.RedactHeaders) == 0 && len(conf.RedactFields) == 0 {
+		return
+	}
+
+	record.RawRequest = sanitiseWireFormat(record.RawRequest, conf)
+	record.RawResponse = sanitiseWireFormat(record.RawResponse, conf)
+}
+
+// sanitiseWireFormat applies the detailed recording limits to a base64 encoded HTTP message in wire format.
+// Headers are redacted line by line, so they are redacted even if the body isn't valid JSON.
+func sanitiseWireFormat(raw string, conf apidef.DetailedRecordingConfig) string {
+	if raw == "" {
+		return raw
+	}
+
+	message, err := base64.StdEncoding.DecodeString(raw)
+	if err != nil {
+		log.WithError(err).Debug("Couldn't decode detailed record")
+		return raw
+	}
+
+	head, body := message, []byte(nil)
+	if i := bytes.Index(message, wireFormatHeaderEnd); i >= 0 {
+		head, body = message[:i], message[i+len(wireFormatHeaderEnd):]
+	}
+
+	lines := bytes.Split(head, wireFormatLineEnd)
+	redactWireFormatHeaders(lines, conf.RedactHeaders)
+
+	if body != nil {
+		sanitised := body
+		if len(conf.RedactFields) > 0 {
+			lines, sanitised = decodeWireFormatBody(lines, sanitised)
+			sanitised = redactJSONBody(sanitised, conf.RedactFields)
+		}
+		sanitised = truncateBody(sanitised, conf.MaxBodySize)
+
+		// the stored body no longer matches the declared length
+		if i, _ := wireFormatHeader(lines, header.ContentLength); i >= 0 && !bytes.Equal(sanitised, body) {
+			lines[i] = []byte(header.ContentLength + ": " + strconv.Itoa(len(sanitised)))
+		}
+		body = sanitised
+	}
+
+	var out bytes.Buffer
+	out.Write(bytes.Join(lines, wireFormatLineEnd))
+	if body != nil {
+		out.Write(wireFormatHeaderEnd)
+		out.Write(body)
+	}
+
+	return base64.StdEncoding.EncodeToString(out.Bytes())
+}
+
+func redactWireFormatHeaders(lines [][]byte, names []string) {
+	if len(names) == 0 {
+		return
+	}
+
+	// the first line is the request or status line
+	for i := 1; i < len(lines); i++ {
+		name, _, found := bytes.Cut(lines[i], []byte(":"))
+		if !found || !containsFold(names, string(bytes.TrimSpace(name))) {
+			continue
+		}
+
+		lines[i] = []byte(string(name) + ": " + redactedValue)
+	}
+}
+
+// wireFormatHeader returns the index and the value of the named header, or -1 if it isn't set.
+func wireFormatHeader(lines [][]byte, name string) (int, string) {
+	for i := 1; i < len(lines); i++ {
+		key, value, found := bytes.Cut(lines[i], []byte(":"))
+		if found && strings.EqualFold(string(bytes.TrimSpace(key)), name) {
+			return i, string(bytes.TrimSpace(value))
+		}
+	}
+	return -1, ""
+}
+
+func deleteWireFormatHeader(lines [][]byte, name string) [][]byte {
+	if i, _ := wireFormatHeader(lines, name); i >= 0 {
+		return append(lines[:i], lines[i+1:]...)
+	}
+	return lines
+}
+
+// decodeWireFormatBody removes the chunked transfer encoding and the content encoding of a body so its
+// JSON fields can be redacted, along with the headers declaring them. A body which can't be decoded
+// is replaced by redactedValue, so that compression can't be used to bypass redaction.
+func decodeWireFormatBody(lines [][]byte, body []byte) ([][]byte, []byte) {
+	if _, encoding := wireFormatHeader(lines, header.TransferEncoding); strings.EqualFold(encoding, "chunked") {
+		decoded, err := readDetailedBody(httputil.NewChunkedReader(bytes.NewReader(body)))
+		if err != nil {
+			log.WithError(err).Debug("Couldn't decode chunked body of detailed record")
+			return lines, []byte(redactedValue)
+		}
+
+		// the decoded body has a known length
+		i, _ := wireFormatHeader(lines, header.TransferEncoding)
+		lines[i] = []byte(header.ContentLength + ": " + strconv.Itoa(len(decoded)))
+		body = decoded
+	}
+
+	_, encoding := wireFormatHeader(lines, header.ContentEncoding)
+
+	var reader io.Reader
+	switch strings.ToLower(encoding) {
+	case "", "identity":
+		return lines, body
+	case "gzip":
+		gzipReader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			log.WithError(err).Debug("Couldn't decode gzip body of detailed record")
+			return lines, []byte(redactedValue)
+		}
+		reader = gzipReader
+	case "deflate":
+		reader = flate.NewReader(bytes.NewReader(body))
+	case "br":
+		reader = brotli.NewReader(bytes.NewReader(body))
+	default:
+		log.WithField("encoding", encoding).Debug("Unsupported content encoding of detailed record")
+		return lines, []byte(redactedValue)
+	}
+
+	decoded, err := readDetailedBody(reader)
+	if err != nil {
+		log.WithError(err).Debug("Couldn't decode body of detailed record")
+		return lines, []byte(redactedValue)
+	}
+
+	return deleteWireFormatHeader(lines, header.ContentEncoding), decoded
+}
+
+func readDetailedBody(reader io.Reader) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(reader, maxDecodedDetailedBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) > maxDecodedDetailedBodySize {
+		return nil, errDetailedBodyTooLarge
+	}
+
+	return body, nil
+}
+
+// redactJSONBody replaces the values of the named fields at any depth of a JSON body.
+// Bodies which aren't valid JSON are returned as is.
+func redactJSONBody(body []byte, fields []string) []byte {
+	if len(fields) == 0 || !json.Valid(body) {
+		return body
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return body
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(redactJSONValue(value, fields)); err != nil {
+		return body
+	}
+
+	return bytes.TrimSuffix(out.Bytes(), []byte("\n"))
+}
+
+func redactJSONValue(value interface{}, fields []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if containsFold(fields, key) {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactJSONValue(item, fields)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSONValue(item, fields)
+		}
+	}
+
+	return value
+}
+
+func truncateBody(body []byte, maxSize int64) []byte {
+	if maxSize <= 0 || int64(len(body)) <= maxSize {
+		return body
+	}
+
+	truncated := append([]byte{}, body[:maxSize]...)
+	return append(truncated, fmt.Sprintf(truncatedBodyIndicator, int64(len(body))-maxSize)...)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package gateway
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func gzipString(s string) string {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(s))
+	_ = zw.Close()
+	return buf.String()
+}
+
+func TestSanitiseWireFormat(t *testing.T) {
+	encode := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+
+	testCases := []struct {
+		name     string
+		message  string
+		conf     apidef.DetailedRecordingConfig
+		expected string
+	}{
+		{
+			name:     "redacts headers and nested JSON fields",
+			message:  "POST /login HTTP/1.1\r\nAuthorization: Bearer secret\r\nX-Id: 1\r\n\r\n" + `{"user":{"password":"hunter2","tokens":[{"token":"t1"}]},"ok":true}`,
+			conf:     apidef.DetailedRecordingConfig{RedactHeaders: []string{"authorization"}, RedactFields: []string{"password", "token"}},
+			expected: "POST /login HTTP/1.1\r\nAuthorization: ***\r\nX-Id: 1\r\n\r\n" + `{"ok":true,"user":{"password":"***","tokens":[{"token":"***"}]}}`,
+		},
+		{
+			name:     "redacts headers of non JSON bodies",
+			message:  "HTTP/1.1 200 OK\r\nSet-Cookie: session=abc\r\n\r\npassword=hunter2",
+			conf:     apidef.DetailedRecordingConfig{RedactHeaders: []string{"Set-Cookie"}, RedactFields: []string{"password"}},
+			expected: "HTTP/1.1 200 OK\r\nSet-Cookie: ***\r\n\r\npassword=hunter2",
+		},
+		{
+			name:     "truncates bodies after redaction",
+			message:  "HTTP/1.1 200 OK\r\nContent-Length: 29\r\n\r\n" + `{"token":"abc","data":"xxxx"}`,
+			conf:     apidef.DetailedRecordingConfig{MaxBodySize: 16, RedactFields: []string{"token"}},
+			expected: "HTTP/1.1 200 OK\r\nContent-Length: 39\r\n\r\n" + `{"data":"xxxx","` + fmt.Sprintf(truncatedBodyIndicator, 13),
+		},
+		{
+			name:     "decodes compressed bodies",
+			message:  "HTTP/1.1 200 OK\r\nContent-Encoding: gzip\r\nContent-Length: 1\r\n\r\n" + gzipString(`{"token":"abc"}`),
+			conf:     apidef.DetailedRecordingConfig{RedactFields: []string{"token"}},
+			expected: "HTTP/1.1 200 OK\r\nContent-Length: 15\r\n\r\n" + `{"token":"***"}`,
+		},
+		{
+			name:     "decodes chunked bodies",
+			message:  "HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n" + "7\r\n{\"token\r\n8\r\n\":\"abc\"}\r\n0\r\n\r\n",
+			conf:     apidef.DetailedRecordingConfig{RedactFields: []string{"token"}},
+			expected: "HTTP/1.1 200 OK\r\nContent-Length: 15\r\n\r\n" + `{"token":"***"}`,
+		},
+		{
+			name:     "drops bodies which can't be decoded",
+			message:  "HTTP/1.1 200 OK\r\nContent-Encoding: zstd\r\n\r\n" + `{"token":"abc"}`,
+			conf:     apidef.DetailedRecordingConfig{RedactFields: []string{"token"}},
+			expected: "HTTP/1.1 200 OK\r\nContent-Encoding: zstd\r\n\r\n" + redactedValue,
+		},
+		{
+			name:     "keeps bodies within the limit",
+			message:  "HTTP/1.1 204 No Content\r\n\r\n",
+			conf:     apidef.DetailedRecordingConfig{MaxBodySize: 16},
+			expected: "HTTP/1.1 204 No Content\r\n\r\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := base64.StdEncoding.DecodeString(sanitiseWireFormat(encode(tc.message), tc.conf))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+		})
+	}
+}
+
+func TestAnalytics_DetailedRecordingLimits(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.AnalyticsConfig.EnableDetailedRecording = true
+		globalConf.AnalyticsConfig.DetailedRecording = apidef.DetailedRecordingConfig{
+			MaxBodySize:   64,
+			RedactHeaders: []string{"Authorization"},
+		}
+	})
+	defer ts.Close()
+
+	upstreamBody := `{"token":"upstream-secret","data":"` + strings.Repeat("x", 1024) + `"}`
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(upstreamBody))
+	}))
+	defer upstream.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.DetailedRecordingConfig.RedactFields = []string{"password", "token"}
+	})
+
+	redisAnalyticsKeyName := analyticsKeyName + ts.Gw.Analytics.analyticsSerializer.GetSuffix()
+	ts.Gw.Analytics.Store.GetAndDeleteSet(redisAnalyticsKeyName)
+
+	_, _ = ts.Run(t, test.TestCase{
+		Method:  http.MethodPost,
+		Path:    "/login",
+		Data:    `{"user":"bob","password":"hunter2"}`,
+		Headers: map[string]string{"Authorization": "Bearer client-secret"},
+		Code:    http.StatusOK,
+	})
+
+	ts.Gw.Analytics.Flush()
+
+	results := ts.Gw.Analytics.Store.GetAndDeleteSet(redisAnalyticsKeyName)
+	require.Len(t, results, 1)
+
+	var record analytics.AnalyticsRecord
+	require.NoError(t, ts.Gw.Analytics.analyticsSerializer.Decode([]byte(results[0].(string)), &record))
+
+	rawRequest, err := base64.StdEncoding.DecodeString(record.RawRequest)
+	require.NoError(t, err)
+	assert.Contains(t, string(rawRequest), "Authorization: ***\r\n")
+	assert.Contains(t, string(rawRequest), `"password":"***"`)
+	assert.NotContains(t, string(rawRequest), "client-secret")
+	assert.NotContains(t, string(rawRequest), "hunter2")
+
+	rawResponse, err := base64.StdEncoding.DecodeString(record.RawResponse)
+	require.NoError(t, err)
+	_, body, found := strings.Cut(string(rawResponse), "\r\n\r\n")
+	require.True(t, found)
+	assert.NotContains(t, body, "upstream-secret")
+
+	redactedBody := `{"data":"` + strings.Repeat("x", 1024) + `","token":"***"}`
+	assert.Equal(t, redactedBody[:64]+fmt.Sprintf(truncatedBodyIndicator, len(redactedBody)-64), body)
+}
@@ -2,13 +2,17 @@ package gateway
 
 import (
 	"encoding/base64"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/TykTechnologies/tyk-pump/analytics"
 	"github.com/TykTechnologies/tyk/apidef"
@@ -658,6 +662,54 @@ func BenchmarkURLReplacer(b *testing.B) {
 	}
 }
 
+func TestURLReplacer_APIPatterns(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	const orderID = "01J8ME3QK6Z9N2V4T5R7W8X0YB"
+
+	ts.Gw.BuildAndLoadAPI(
+		func(spec *APISpec) {
+			spec.APIID = "orders"
+			spec.Proxy.ListenPath = "/shop/"
+			spec.NormaliseURLPatterns = []apidef.URLNormalisePattern{
+				{Regex: `/orders/[0-9A-HJKMNP-TV-Z]{26}`, Replacement: "/orders/{order_id}"},
+				{Regex: `/items/(sku)-\d+`, Replacement: "/items/$1-{n}"},
+			}
+		},
+		func(spec *APISpec) {
+			spec.APIID = "other"
+			spec.Proxy.ListenPath = "/other/"
+		},
+	)
+
+	paths := map[string]string{}
+	ts.Gw.Analytics.mockEnabled = true
+	ts.Gw.Analytics.mockRecordHit = func(record *analytics.AnalyticsRecord) {
+		paths[record.APIID] = record.Path
+	}
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Path: "/shop/orders/" + orderID + "/items/sku-42", Code: http.StatusOK},
+		{Path: "/other/orders/" + orderID, Code: http.StatusOK},
+	}...)
+
+	assert.Equal(t, map[string]string{
+		"orders": "/shop/orders/{order_id}/items/sku-{n}",
+		"other":  "/other/orders/" + orderID,
+	}, paths)
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		specs := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.APIID = "invalid"
+			spec.Proxy.ListenPath = "/invalid/"
+			spec.NormaliseURLPatterns = []apidef.URLNormalisePattern{{Regex: `/orders/([0-9`}}
+		})
+
+		assert.Nil(t, specs[0], "API with an invalid normalisation pattern should not be loaded")
+	})
+}
+
 func TestTagHeaders(t *testing.T) {
 	req := TestReq(t, "GET", "/tagmeplease", nil)
 	req.Header.Set("Content-Type", "application/json")
@@ -728,3 +780,116 @@ func BenchmarkTagHeaders(b *testing.B) {
 		}
 	}
 }
+
+// countingAnalyticsStore records the pipelined writes of analytics records, optionally blocking them.
+type countingAnalyticsStore struct {
+	mu      sync.Mutex
+	writes  int
+	records [][]byte
+
+	// writing is signalled and release awaited on each write, when set.
+	writing chan struct{}
+	release chan struct{}
+}
+
+func (s *countingAnalyticsStore) Connect() bool { return true }
+
+func (s *countingAnalyticsStore) AppendToSetPipelined(_ string, values [][]byte) {
+	if len(values) == 0 {
+		return
+	}
+
+	if s.writing != nil {
+		s.writing <- struct{}{}
+		<-s.release
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes++
+	s.records = append(s.records, values...)
+}
+
+func (s *countingAnalyticsStore) GetAndDeleteSet(string) []interface{} { return nil }
+func (s *countingAnalyticsStore) SetExp(string, int64) error           { return nil }
+func (s *countingAnalyticsStore) GetExp(string) (int64, error)         { return 0, nil }
+
+// newAnalyticsHandler starts an analytics handler writing to store. The analytics configuration is
+// set after the gateway is started, as it enforces a minimum queue size.
+func (ts *Test) newAnalyticsHandler(store *countingAnalyticsStore, configure func(*config.AnalyticsConfigConfig)) *RedisAnalyticsHandler {
+	conf := ts.Gw.GetConfig()
+	configure(&conf.AnalyticsConfig)
+	ts.Gw.SetConfig(conf)
+
+	handler := &RedisAnalyticsHandler{Store: store, Gw: ts.Gw}
+	handler.Init()
+	return handler
+}
+
+func TestRedisAnalyticsHandler_DropOldest(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	store := &countingAnalyticsStore{writing: make(chan struct{}), release: make(chan struct{})}
+	handler := ts.newAnalyticsHandler(store, func(conf *config.AnalyticsConfigConfig) {
+		conf.PoolSize = 1
+		conf.RecordsBufferSize = 2
+		conf.RecordsBatchSize = 1
+		conf.OverflowPolicy = config.AnalyticsOverflowDropOldest
+	})
+
+	// the worker holds the first record in a write blocked on Redis
+	require.NoError(t, handler.RecordHit(&analytics.AnalyticsRecord{APIID: "0"}))
+	<-store.writing
+
+	for i := 1; i < 10; i++ {
+		require.NoError(t, handler.RecordHit(&analytics.AnalyticsRecord{APIID: strconv.Itoa(i)}))
+	}
+	assert.Equal(t, uint64(7), handler.DroppedRecords(), "records beyond the queue size should be dropped")
+
+	go func() {
+		for range store.writing {
+			store.release <- struct{}{}
+		}
+	}()
+	store.release <- struct{}{}
+	handler.Flush()
+	handler.Stop()
+	close(store.writing)
+
+	var apiIDs []string
+	for _, encoded := range store.records {
+		var record analytics.AnalyticsRecord
+		require.NoError(t, handler.analyticsSerializer.Decode(encoded, &record))
+		apiIDs = append(apiIDs, record.APIID)
+	}
+	assert.Equal(t, []string{"0", "8", "9"}, apiIDs, "the oldest queued records should be dropped")
+}
+
+func BenchmarkRedisAnalyticsHandler_RecordHit(b *testing.B) {
+	for _, batchSize := range []uint64{1, 100} {
+		b.Run(fmt.Sprintf("batch size %d", batchSize), func(b *testing.B) {
+			ts := StartTest(nil)
+			defer ts.Close()
+
+			store := &countingAnalyticsStore{}
+			handler := ts.newAnalyticsHandler(store, func(conf *config.AnalyticsConfigConfig) {
+				conf.PoolSize = 2
+				conf.RecordsBufferSize = 1000
+				conf.RecordsBatchSize = batchSize
+			})
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_ = handler.RecordHit(&analytics.AnalyticsRecord{APIID: "api", Path: "/path"})
+				}
+			})
+
+			handler.Stop()
+			b.ReportMetric(float64(store.writes)/float64(b.N), "redis-ops/record")
+		})
+	}
+}
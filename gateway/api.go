@@ -55,6 +55,7 @@ import (
 	gql "github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
 	gqlv2 "github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
 	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/apidef/importer"
 	"github.com/TykTechnologies/tyk/apidef/oas"
 	"github.com/TykTechnologies/tyk/certs"
 	"github.com/TykTechnologies/tyk/ctx"
@@ -63,6 +64,7 @@ import (
 	"github.com/TykTechnologies/tyk/internal/model"
 	"github.com/TykTechnologies/tyk/internal/osutil"
 	"github.com/TykTechnologies/tyk/internal/otel"
+	"github.com/TykTechnologies/tyk/internal/policy"
 	"github.com/TykTechnologies/tyk/internal/redis"
 	"github.com/TykTechnologies/tyk/internal/sanitize"
 	"github.com/TykTechnologies/tyk/internal/uuid"
@@ -347,6 +349,9 @@ func (gw *Gateway) applyPoliciesAndSave(keyName string, session *user.SessionSta
 		return err
 	}
 
+	// the key is idle from now on if it's never used
+	session.SeedLastSeen(time.Now())
+
 	// calculate lifetime considering access rights
 	lifetime := gw.ApplyLifetime(session, spec)
 
@@ -697,15 +702,24 @@ func (gw *Gateway) handleAddOrUpdate(keyName string, r *http.Request, isHashed b
 		if isHashed {
 			response.KeyHash = keyName
 		} else {
-			response.KeyHash = storage.HashKey(keyName, gw.GetConfig().HashKeys)
+			response.KeyHash = gw.keyHash(keyName)
 		}
 	}
 
 	return response, http.StatusOK
 }
 
+// keyDetail is a key with its policies applied, as returned by the key detail endpoint.
+type keyDetail struct {
+	user.SessionState
+
+	// PolicyLimits records the policies which set the effective limits of the key.
+	PolicyLimits *policy.LimitSources `json:"policy_limits,omitempty"`
+}
+
 func (gw *Gateway) handleGetDetail(sessionKey, apiID, orgID string, byHash bool) (interface{}, int) {
-	if byHash && !gw.GetConfig().HashKeys {
+	conf := gw.GetConfig()
+	if byHash && !conf.HashKeys {
 		return apiError("Key requested by hash but key hashing is not enabled"), http.StatusBadRequest
 	}
 
@@ -721,12 +735,13 @@ func (gw *Gateway) handleGetDetail(sessionKey, apiID, orgID string, byHash bool)
 	}
 
 	mw := &BaseMiddleware{Spec: spec, Gw: gw}
-	mw.ApplyPolicies(&session)
+	policies := mw.policyService()
+	policies.Apply(&session)
 
 	if session.QuotaMax != -1 {
-		quotaKey := QuotaKeyPrefix + storage.HashKey(sessionKey, gw.GetConfig().HashKeys)
+		quotaKey := quotaKeyName(&conf, "", storage.HashKey(sessionKey, conf.HashKeys))
 		if byHash {
-			quotaKey = QuotaKeyPrefix + sessionKey
+			quotaKey = quotaKeyName(&conf, "", sessionKey)
 		}
 
 		if usedQuota, err := gw.GlobalSessionManager.Store().GetRawKey(quotaKey); err == nil || errors.Is(err, redis.Nil) {
@@ -753,14 +768,9 @@ func (gw *Gateway) handleGetDetail(sessionKey, apiID, orgID string, byHash bool)
 			continue
 		}
 
-		quotaScope := ""
-		if access.AllowanceScope != "" {
-			quotaScope = access.AllowanceScope + "-"
-		}
-
-		limQuotaKey := QuotaKeyPrefix + quotaScope + storage.HashKey(sessionKey, gw.GetConfig().HashKeys)
+		limQuotaKey := quotaKeyName(&conf, access.AllowanceScope, storage.HashKey(sessionKey, conf.HashKeys))
 		if byHash {
-			limQuotaKey = QuotaKeyPrefix + quotaScope + sessionKey
+			limQuotaKey = quotaKeyName(&conf, access.AllowanceScope, sessionKey)
 		}
 
 		if usedQuota, err := gw.GlobalSessionManager.Store().GetRawKey(limQuotaKey); err == nil {
@@ -800,7 +810,13 @@ func (gw *Gateway) handleGetDetail(sessionKey, apiID, orgID string, byHash bool)
 		"status": "ok",
 	}).Info("Retrieved key detail.")
 
-	return session.Clone(), http.StatusOK
+	detail := keyDetail{SessionState: session.Clone()}
+	if len(session.PolicyIDs()) > 0 {
+		limitSources := policies.LimitSources()
+		detail.PolicyLimits = &limitSources
+	}
+
+	return detail, http.StatusOK
 }
 
 // apiAllKeys represents a list of keys in the memory store
@@ -842,6 +858,13 @@ func (gw *Gateway) handleGetAllKeys(c context.Context, filter string, apiID stri
 
 // filterKeysByAPIID handles the concurrent processing of key permissions
 func (gw *Gateway) filterKeysByAPIID(c context.Context, keys []string, filter, apiID string, hashed bool) ([]string, error) {
+	return gw.filterKeys(c, keys, func(keyName string) bool {
+		return gw.keyHasAccess(filter, keyName, apiID, hashed)
+	})
+}
+
+// filterKeys concurrently keeps the keys the match function accepts, using a Worker Pool.
+func (gw *Gateway) filterKeys(c context.Context, keys []string, match func(keyName string) bool) ([]string, error) {
 	numKeys := len(keys)
 	// the values for keyListingWorkerCount and keyListingBufferSize were averaged through profiling and benchmarking
 	keyListingWorkerCount := min(int(4+math.Sqrt(float64(numKeys))), KeyListingWorkerCountCap)
@@ -872,7 +895,7 @@ func (gw *Gateway) filterKeysByAPIID(c context.Context, keys []string, filter, a
 						return
 					}
 
-					if gw.keyHasAccess(filter, keyName, apiID, hashed) {
+					if match(keyName) {
 						localMatches = append(localMatches, keyName)
 					}
 				}
@@ -911,15 +934,16 @@ func (gw *Gateway) keyHasAccess(filter, keyName, apiID string, hashed bool) bool
 		return false
 	}
 
-	if _, ok := session.AccessRights[apiID]; ok {
-		return true
-	}
+	return gw.sessionHasAPIAccess(&session, apiID)
+}
 
-	if len(session.AccessRights) == 0 && gw.GetConfig().AllowMasterKeys {
+// sessionHasAPIAccess checks if the session grants access to the requested API ID.
+func (gw *Gateway) sessionHasAPIAccess(session *user.SessionState, apiID string) bool {
+	if _, ok := session.AccessRights[apiID]; ok {
 		return true
 	}
 
-	return false
+	return len(session.AccessRights) == 0 && gw.GetConfig().AllowMasterKeys
 }
 
 // feedKeyListingWorkers pushes keys into the job channel while filtering out internal system keys.
@@ -1822,6 +1846,33 @@ func (gw *Gateway) apiOASExportHandler(w http.ResponseWriter, r *http.Request) {
 	doJSONExport(w, code, obj, fmt.Sprintf("%s.%s", fileName, fileTypeJSON))
 }
 
+// apiOpenAPIHandler serves the OAS document describing an API to its consumers. OAS APIs are served their public
+// document, classic APIs a document synthesized from their definition.
+func (gw *Gateway) apiOpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	apiID := mux.Vars(r)["apiID"]
+
+	spec := gw.getApiSpec(apiID)
+	if spec == nil {
+		doJSONWrite(w, http.StatusNotFound, apiError(apidef.ErrAPINotFound.Error()))
+		return
+	}
+
+	if spec.IsOAS {
+		obj, code := gw.handleGetAPIOAS(apiID, true)
+		doJSONWrite(w, code, obj)
+		return
+	}
+
+	doc, err := oas.NewDocumentationFromClassicAPIDefinition(spec.APIDefinition, getAPIURL(*spec.APIDefinition, gw.GetConfig()))
+	if err != nil {
+		log.WithError(err).WithField("apiID", apiID).Error("Couldn't generate the OpenAPI document of the API")
+		doJSONWrite(w, http.StatusUnprocessableEntity, apiError(err.Error()))
+		return
+	}
+
+	doJSONWrite(w, http.StatusOK, doc)
+}
+
 func (gw *Gateway) keyHandler(w http.ResponseWriter, r *http.Request) {
 	keyName := mux.Vars(r)["keyName"]
 	apiID := r.URL.Query().Get("api_id")
@@ -1829,6 +1880,12 @@ func (gw *Gateway) keyHandler(w http.ResponseWriter, r *http.Request) {
 	isUserName := r.URL.Query().Get("username") == "true"
 	orgID := r.URL.Query().Get("org_id")
 
+	// org-scoped admin tokens can only act within their own organisation
+	scopedOrgID := ctxGetControlAPIOrgScope(r)
+	if scopedOrgID != "" {
+		orgID = scopedOrgID
+	}
+
 	// check if passed key is user name and convert it to real key with respect to current hashing algorithm
 	origKeyName := keyName
 	gwConfig := gw.GetConfig()
@@ -1837,6 +1894,13 @@ func (gw *Gateway) keyHandler(w http.ResponseWriter, r *http.Request) {
 		keyName = gw.generateToken(orgID, keyName)
 	}
 
+	if scopedOrgID != "" {
+		if code, err := gw.checkOrgScopedKeyAccess(r, orgID, isHashed, keyName, origKeyName); err != nil {
+			doJSONWrite(w, code, apiError(err.Error()))
+			return
+		}
+	}
+
 	var obj interface{}
 	var code int
 	hashKeyFunction := gwConfig.HashKeyFunction
@@ -1866,8 +1930,14 @@ func (gw *Gateway) keyHandler(w http.ResponseWriter, r *http.Request) {
 					doJSONWrite(w, http.StatusNotFound, apiError("Hashed key listing is disabled in config (enable_hashed_keys_listing)"))
 					return
 				}
+			}
+
+			switch {
+			case scopedOrgID != "":
+				obj, code = gw.handleGetOrgScopedKeys(r.Context(), scopedOrgID, apiID, gwConfig.HashKeys)
+			case gwConfig.HashKeys:
 				obj, code = gw.handleGetAllKeys(r.Context(), "", apiID, true)
-			} else {
+			default:
 				filter := r.URL.Query().Get("filter")
 				if apiID != "" && filter == "" {
 					doJSONWrite(w, http.StatusBadRequest, apiError("The 'filter' parameter (Org ID) is required when filtering by 'api_id' in legacy mode"))
@@ -2025,7 +2095,8 @@ func (gw *Gateway) handleOrgAddOrUpdate(orgID string, r *http.Request) (interfac
 	if r.URL.Query().Get("reset_quota") == "1" {
 		sessionManager.ResetQuota(orgID, newSession, false)
 		newSession.QuotaRenews = time.Now().Unix() + newSession.QuotaRenewalRate
-		rawKey := QuotaKeyPrefix + storage.HashKey(orgID, gw.GetConfig().HashKeys)
+		conf := gw.GetConfig()
+		rawKey := quotaKeyName(&conf, "", storage.HashKey(orgID, conf.HashKeys))
 
 		// manage quotas separately
 		gw.DefaultQuotaStore.RemoveSession(orgID, rawKey, false)
@@ -2299,7 +2370,7 @@ func (gw *Gateway) createKeyHandler(w http.ResponseWriter, r *http.Request) {
 
 	// add key hash to reply
 	if gw.GetConfig().HashKeys {
-		obj.KeyHash = storage.HashKey(newKey, gw.GetConfig().HashKeys)
+		obj.KeyHash = gw.keyHash(newKey)
 	}
 
 	gw.FireSystemEvent(EventTokenCreated, EventTokenMeta{
@@ -2549,6 +2620,7 @@ func (gw *Gateway) rotateOauthClient(keyName, apiID string) (interface{}, int) {
 		PolicyID:          client.GetPolicyID(),
 		MetaData:          client.GetUserData(),
 		Description:       client.GetDescription(),
+		Registration:      oauthClientRegistration(client),
 	}
 
 	err = apiSpec.OAuthManager.Storage().SetClient(storageID, apiSpec.OrgID, &updatedClient, true)
@@ -2624,6 +2696,7 @@ func (gw *Gateway) updateOauthClient(keyName, apiID string, r *http.Request) (in
 		PolicyID:          updateClientData.PolicyID,          // update
 		MetaData:          updateClientData.MetaData,          // update
 		Description:       updateClientData.Description,       // update
+		Registration:      oauthClientRegistration(client),
 	}
 
 	err = apiSpec.OAuthManager.Storage().SetClient(storageID, apiSpec.OrgID, &updatedClient, true)
@@ -3086,6 +3159,11 @@ func userRatesCheck(w http.ResponseWriter, r *http.Request) {
 func (gw *Gateway) invalidateCacheHandler(w http.ResponseWriter, r *http.Request) {
 	apiID := mux.Vars(r)["apiID"]
 
+	if pattern := r.URL.Query().Get("pattern"); pattern != "" {
+		gw.invalidateCacheByPathHandler(w, r, apiID, pattern)
+		return
+	}
+
 	if ok := gw.invalidateAPICache(apiID); !ok {
 		err := errors.New("scan/delete failed")
 		var orgid string
@@ -3111,6 +3189,103 @@ func (gw *Gateway) invalidateCacheHandler(w http.ResponseWriter, r *http.Request
 	doJSONWrite(w, http.StatusOK, apiOk("cache invalidated"))
 }
 
+// apiCacheInvalidationMessage is the response of invalidating the cached responses of an API by path pattern.
+type apiCacheInvalidationMessage struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	// Removed is the number of removed cache entries.
+	Removed int64 `json:"removed"`
+	// Truncated is set when the invalidation stopped at the scan limit, matching entries may remain.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// invalidateCacheByPathHandler removes the cached responses of an API for the paths, relative to the
+// listen path, matching the glob or the regular expression of the pattern query parameter.
+func (gw *Gateway) invalidateCacheByPathHandler(w http.ResponseWriter, r *http.Request, apiID, pattern string) {
+	match, err := cachePathMatcher(pattern, r.URL.Query().Get("pattern_type"))
+	if err != nil {
+		doJSONWrite(w, http.StatusBadRequest, apiError("Invalid pattern: "+err.Error()))
+		return
+	}
+
+	removed, truncated, err := gw.invalidateAPICacheByPath(apiID, match)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix":  "api",
+			"api_id":  apiID,
+			"pattern": pattern,
+			"status":  "fail",
+			"user_ip": requestIPHops(r),
+		}).WithError(err).Error("Failed to delete cache by pattern")
+
+		doJSONWrite(w, http.StatusInternalServerError, apiError("Cache invalidation failed"))
+		return
+	}
+
+	message := apiCacheInvalidationMessage{Status: "ok", Message: "cache invalidated", Removed: removed, Truncated: truncated}
+	if truncated {
+		message.Message = "cache partially invalidated, scan limit reached"
+	}
+	doJSONWrite(w, http.StatusOK, message)
+}
+
+// apiPersistedQueriesMessage is the response of uploading GraphQL persisted queries.
+type apiPersistedQueriesMessage struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	// Hashes are the hex encoded SHA-256 hashes of the uploaded queries, in upload order.
+	Hashes []string `json:"hashes"`
+}
+
+// graphqlPersistedQueriesUploadHandler stores query documents as persisted queries of a GraphQL API.
+func (gw *Gateway) graphqlPersistedQueriesUploadHandler(w http.ResponseWriter, r *http.Request) {
+	apiID := mux.Vars(r)["apiID"]
+
+	spec := gw.getApiSpec(apiID)
+	if spec == nil {
+		doJSONWrite(w, http.StatusNotFound, apiError(apidef.ErrAPINotFound.Error()))
+		return
+	}
+
+	if !spec.GraphQL.Enabled || !spec.GraphQL.PersistedQueries.Enabled {
+		doJSONWrite(w, http.StatusBadRequest, apiError("Persisted queries are not enabled for this API"))
+		return
+	}
+
+	var upload struct {
+		Queries []string `json:"queries"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&upload); err != nil || len(upload.Queries) == 0 {
+		doJSONWrite(w, http.StatusBadRequest, apiError("Request body must contain a non empty list of queries"))
+		return
+	}
+
+	store := gw.graphqlPersistedQueryStore(apiID)
+	hashes := make([]string, 0, len(upload.Queries))
+	for _, query := range upload.Queries {
+		if strings.TrimSpace(query) == "" {
+			doJSONWrite(w, http.StatusBadRequest, apiError("Queries must not be empty"))
+			return
+		}
+
+		hash := persistedQueryHashOf(query)
+		if err := store.SetKey(hash, query, 0); err != nil {
+			log.WithFields(logrus.Fields{
+				"prefix":  "api",
+				"api_id":  apiID,
+				"status":  "fail",
+				"user_ip": requestIPHops(r),
+			}).WithError(err).Error("Failed to store persisted query")
+
+			doJSONWrite(w, http.StatusInternalServerError, apiError("Persisted queries upload failed"))
+			return
+		}
+		hashes = append(hashes, hash)
+	}
+
+	doJSONWrite(w, http.StatusOK, apiPersistedQueriesMessage{Status: "ok", Message: "persisted queries stored", Hashes: hashes})
+}
+
 func (gw *Gateway) RevokeTokenHandler(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 
@@ -3301,6 +3476,72 @@ func (gw *Gateway) makeImportedOASTykAPI(next http.HandlerFunc) http.HandlerFunc
 	}
 }
 
+// apiImportSuccess is the response of an import, listing the features of the source which weren't imported.
+type apiImportSuccess struct {
+	apiModifyKeySuccess
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// apiImportPostmanHandler creates a keyless API from a v2.1 Postman collection. The listen path, the upstream URL,
+// the API ID and the mock responses are configured with the same query parameters as the OAS import.
+func (gw *Gateway) apiImportPostmanHandler(w http.ResponseWriter, r *http.Request) {
+	collection := &importer.PostmanCollection{}
+	if err := collection.LoadFrom(r.Body); err != nil {
+		log.WithError(err).Error("Couldn't decode Postman collection")
+		doJSONWrite(w, http.StatusBadRequest, apiError("Couldn't decode Postman collection"))
+		return
+	}
+
+	params := oas.GetTykExtensionConfigParams(r)
+	if params == nil {
+		params = &oas.TykExtensionConfigParams{}
+	}
+
+	if params.UpstreamURL != "" {
+		if u, err := url.Parse(params.UpstreamURL); err != nil || u.Scheme == "" || u.Host == "" {
+			doJSONWrite(w, http.StatusBadRequest, apiError("The upstream URL must be absolute"))
+			return
+		}
+	}
+
+	asMock := params.MockResponse != nil && *params.MockResponse
+
+	newDef, err := collection.ToAPIDefinition("", params.UpstreamURL, asMock)
+	if err != nil {
+		doJSONWrite(w, http.StatusBadRequest, apiError(err.Error()))
+		return
+	}
+
+	if params.ApiID != "" {
+		newDef.APIID = params.ApiID
+		newDef.Proxy.ListenPath = "/" + params.ApiID + "/"
+	}
+	if params.ListenPath != "" {
+		newDef.Proxy.ListenPath = params.ListenPath
+	}
+
+	if err := sanitize.ValidatePathComponent(newDef.APIID); err != nil {
+		log.Errorf(errInvalidAPIIDFmt, newDef.APIID, err)
+		doJSONWrite(w, http.StatusBadRequest, apiError(errInvalidAPIID))
+		return
+	}
+
+	if validationErr := validateAPIDef(newDef); validationErr != nil {
+		doJSONWrite(w, http.StatusBadRequest, *validationErr)
+		return
+	}
+
+	if err, errCode := gw.writeToFile(afero.NewOsFs(), newDef, newDef.APIID); err != nil {
+		doJSONWrite(w, errCode, apiError(err.Error()))
+		return
+	}
+
+	doJSONWrite(w, http.StatusOK, apiImportSuccess{
+		apiModifyKeySuccess: apiModifyKeySuccess{Key: newDef.APIID, Status: "ok", Action: "added"},
+		Warnings:            collection.Warnings,
+	})
+}
+
 // ctxSetCacheOptions sets a cache key to use for the http request
 func ctxSetCacheOptions(r *http.Request, options *cacheOptions) {
 	setCtxValue(r, ctx.CacheOptions, options)
@@ -3329,6 +3570,18 @@ func ctxGetMatchedBinding(r *http.Request) *Binding {
 	return b
 }
 
+// ctxSetTokenScopes stores the scopes of the JWT authenticating the request.
+func ctxSetTokenScopes(r *http.Request, scopes []string) {
+	setCtxValue(r, ctx.TokenScopes, scopes)
+}
+
+// ctxGetTokenScopes returns the scopes of the JWT authenticating the request,
+// ok is false when the request wasn't authenticated with a JWT.
+func ctxGetTokenScopes(r *http.Request) (scopes []string, ok bool) {
+	scopes, ok = r.Context().Value(ctx.TokenScopes).([]string)
+	return scopes, ok
+}
+
 func ctxGetSession(r *http.Request) *user.SessionState {
 	return ctx.GetSession(r)
 }
@@ -3423,6 +3676,18 @@ func ctxSetVersionName(r *http.Request, vName *string) {
 	setCtxValue(r, ctx.VersionName, vName)
 }
 
+func ctxGetResolvedVersion(r *http.Request) string {
+	if v, ok := r.Context().Value(ctx.ResolvedVersion).(string); ok {
+		return v
+	}
+
+	return ""
+}
+
+func ctxSetResolvedVersion(r *http.Request, vName string) {
+	setCtxValue(r, ctx.ResolvedVersion, vName)
+}
+
 func ctxSetOrigRequestURL(r *http.Request, url *url.URL) {
 	setCtxValue(r, ctx.OrigRequestURL, url)
 }
@@ -3606,6 +3871,51 @@ func ctxIncLoopLevel(r *http.Request, loopLimit int) {
 	ctxSetLoopLevel(r, ctxLoopLevel(r)+1)
 }
 
+// ctxLoopTrace returns the IDs of the APIs the request left on internal hops, in order.
+func ctxLoopTrace(r *http.Request) []string {
+	if v := r.Context().Value(ctx.LoopTrace); v != nil {
+		if trace, ok := v.([]string); ok {
+			return trace
+		}
+	}
+
+	return nil
+}
+
+func ctxAppendLoopTrace(r *http.Request, apiID string) {
+	// Copy the trace, the parent context may still be in use by the caller of an in memory loop.
+	current := ctxLoopTrace(r)
+	trace := make([]string, 0, len(current)+1)
+	trace = append(trace, current...)
+	setCtxValue(r, ctx.LoopTrace, append(trace, apiID))
+}
+
+// prefetchedTTL is the TTL of a key read ahead of its use.
+type prefetchedTTL struct {
+	key string
+	ttl time.Duration
+}
+
+func ctxSetPrefetchedQuotaTTL(r *http.Request, key string, ttl time.Duration) {
+	setCtxValue(r, ctx.PrefetchedQuotaTTL, &prefetchedTTL{key: key, ttl: ttl})
+}
+
+// ctxTakePrefetchedQuotaTTL returns the prefetched TTL of the quota key and removes it from the context,
+// the TTL is only valid until the quota counter gets updated.
+func ctxTakePrefetchedQuotaTTL(r *http.Request, key string) (time.Duration, bool) {
+	if r == nil {
+		return 0, false
+	}
+
+	prefetched, ok := r.Context().Value(ctx.PrefetchedQuotaTTL).(*prefetchedTTL)
+	if !ok || prefetched == nil || prefetched.key != key {
+		return 0, false
+	}
+
+	setCtxValue(r, ctx.PrefetchedQuotaTTL, (*prefetchedTTL)(nil))
+	return prefetched.ttl, true
+}
+
 func ctxLoopLevelLimit(r *http.Request) int {
 	if v := r.Context().Value(ctx.LoopLevelLimit); v != nil {
 		if intVal, ok := v.(int); ok {
@@ -3718,6 +4028,14 @@ func ctxGetJSONRPCErrorCode(r *http.Request) int {
 	return ctx.GetJSONRPCErrorCode(r)
 }
 
+// oauthClientRegistration returns the dynamic registration state of a client, kept when it's updated or rotated.
+func oauthClientRegistration(client ExtendedOsinClientInterface) *OAuthClientRegistration {
+	if oauthClient, ok := client.(*OAuthClient); ok {
+		return oauthClient.Registration
+	}
+	return nil
+}
+
 var createOauthClientSecret = func() string {
 	secret := uuid.New()
 	return base64.StdEncoding.EncodeToString([]byte(secret))
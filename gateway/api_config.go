@@ -1,13 +1,24 @@
 package gateway
 
 import (
+	"encoding/json"
 	"net/http"
+	"strings"
 	"sync"
 
 	"github.com/TykTechnologies/structviewer"
+
+	"github.com/TykTechnologies/tyk/checkup"
+	"github.com/TykTechnologies/tyk/config"
+)
+
+const (
+	errMsgConfigViewerInit = "Failed to initialize config viewer"
+	errMsgDebugConfig      = "Failed to render the gateway configuration"
 )
 
-const errMsgConfigViewerInit = "Failed to initialize config viewer"
+// kvPrefixes are the prefixes of the configuration values resolved from a KV store.
+var kvPrefixes = []string{prefixEnv, prefixSecrets, prefixConsul, prefixVault, prefixFile}
 
 // configViewerFactory is used to create config viewers.
 // It can be overridden in tests to simulate errors.
@@ -99,3 +110,73 @@ func (gw *Gateway) envHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	viewer.EnvsHandler(w, r)
 }
+
+// debugConfigHandler handles GET /debug/config requests.
+// Returns the effective gateway configuration, after the defaults were applied and the KV store
+// references were resolved. Sensitive fields are redacted based on structviewer:"obfuscate" tags,
+// and the values resolved from a KV store are replaced by a placeholder naming their source.
+func (gw *Gateway) debugConfigHandler(w http.ResponseWriter, _ *http.Request) {
+	out, err := config.Redact(gw.GetConfig())
+	if err != nil {
+		mainLog.WithError(err).Error(errMsgDebugConfig)
+		doJSONWrite(w, http.StatusInternalServerError, apiError(errMsgDebugConfig))
+		return
+	}
+
+	if unresolved := gw.unresolvedConfig.Load(); unresolved != nil {
+		raw, err := json.Marshal(unresolved)
+		if err != nil {
+			mainLog.WithError(err).Error(errMsgDebugConfig)
+			doJSONWrite(w, http.StatusInternalServerError, apiError(errMsgDebugConfig))
+			return
+		}
+
+		var original map[string]interface{}
+		if err := json.Unmarshal(raw, &original); err != nil {
+			mainLog.WithError(err).Error(errMsgDebugConfig)
+			doJSONWrite(w, http.StatusInternalServerError, apiError(errMsgDebugConfig))
+			return
+		}
+
+		markKVSources(original, out)
+	}
+
+	doJSONWrite(w, http.StatusOK, out)
+}
+
+// debugCheckupHandler handles GET /debug/checkup requests.
+// Returns the issues found by linting the current gateway configuration, grouped by severity.
+func (gw *Gateway) debugCheckupHandler(w http.ResponseWriter, _ *http.Request) {
+	conf := gw.GetConfig()
+	doJSONWrite(w, http.StatusOK, checkup.Lint(&conf, gw.checkupState))
+}
+
+// markKVSources replaces the values of effective that were KV store references in original
+// by a placeholder such as "(from vault)", so the resolved values are never exposed.
+func markKVSources(original, effective map[string]interface{}) {
+	for key, value := range original {
+		switch val := value.(type) {
+		case map[string]interface{}:
+			if nested, ok := effective[key].(map[string]interface{}); ok {
+				markKVSources(val, nested)
+			}
+		case string:
+			if source := kvSource(val); source != "" {
+				if _, ok := effective[key]; ok {
+					effective[key] = "(from " + source + ")"
+				}
+			}
+		}
+	}
+}
+
+// kvSource returns the name of the KV store value refers to, or an empty string.
+func kvSource(value string) string {
+	for _, prefix := range kvPrefixes {
+		if strings.HasPrefix(value, prefix) {
+			return strings.TrimSuffix(prefix, "://")
+		}
+	}
+
+	return ""
+}
@@ -3,13 +3,16 @@ package gateway
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/TykTechnologies/structviewer"
 
+	"github.com/TykTechnologies/tyk/checkup"
 	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/test"
 )
@@ -644,3 +647,83 @@ func TestEnvHandler_ViewerInitError(t *testing.T) {
 		},
 	})
 }
+
+func TestDebugConfigEndpoint(t *testing.T) {
+	ts := StartTest(func(cnf *config.Config) {
+		cnf.Secret = "control-api-secret"
+		cnf.HashKeys = true
+		cnf.Storage.Password = "redis-password"
+		cnf.NodeSecret = "secrets://node"
+		cnf.Secrets = map[string]string{"node": "node-secret-value"}
+	})
+	defer ts.Close()
+
+	require.NoError(t, ts.Gw.afterConfSetup())
+	require.Equal(t, "node-secret-value", ts.Gw.GetConfig().NodeSecret)
+
+	t.Run("auth required", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{
+			Method: http.MethodGet,
+			Path:   "/tyk/debug/config",
+			Code:   http.StatusForbidden,
+		})
+	})
+
+	resp, _ := ts.Run(t, test.TestCase{
+		Method:    http.MethodGet,
+		Path:      "/tyk/debug/config",
+		AdminAuth: true,
+		Code:      http.StatusOK,
+	})
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	for _, secret := range []string{"control-api-secret", "redis-password", "node-secret-value"} {
+		assert.NotContains(t, string(body), secret)
+	}
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &got))
+
+	assert.Equal(t, config.RedactedValue, got["secret"])
+	assert.Equal(t, config.RedactedValue, got["secrets"])
+	assert.Equal(t, "(from secrets)", got["node_secret"])
+
+	conf := ts.Gw.GetConfig()
+	assert.Equal(t, float64(conf.ListenPort), got["listen_port"])
+	assert.Equal(t, conf.HashKeys, got["hash_keys"])
+	assert.Equal(t, conf.HealthCheckEndpointName, got["health_check_endpoint_name"])
+	assert.Equal(t, conf.Storage.Host, got["storage"].(map[string]interface{})["host"])
+}
+
+func TestDebugCheckupEndpoint(t *testing.T) {
+	ts := StartTest(func(cnf *config.Config) {
+		cnf.HashKeys = false
+		cnf.EnableHashedKeysListing = true
+		cnf.SuppressedCheckupRules = []string{"session-lifetime-unset"}
+	})
+	defer ts.Close()
+
+	t.Run("auth required", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{
+			Method: http.MethodGet,
+			Path:   "/tyk/debug/checkup",
+			Code:   http.StatusForbidden,
+		})
+	})
+
+	resp, _ := ts.Run(t, test.TestCase{
+		Method:    http.MethodGet,
+		Path:      "/tyk/debug/checkup",
+		AdminAuth: true,
+		Code:      http.StatusOK,
+	})
+
+	var report checkup.Report
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+
+	assert.False(t, report.HasErrors())
+	assert.Contains(t, report.RuleIDs(), "hashed-keys-listing-without-hashing")
+	assert.NotContains(t, report.RuleIDs(), "session-lifetime-unset")
+}
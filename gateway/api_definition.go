@@ -22,6 +22,7 @@ import (
 	"github.com/cenk/backoff"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/itchyny/gojq"
 	"github.com/sirupsen/logrus"
 
 	circuit "github.com/TykTechnologies/circuitbreaker"
@@ -85,6 +86,8 @@ const (
 	PersistGraphQL
 	RateLimit
 	OASMockResponse
+	UpstreamHostHeader
+	RateCost
 )
 
 // RequestStatus is a custom type to avoid collisions
@@ -96,6 +99,8 @@ const (
 	VersionDoesNotExist                   RequestStatus = "This API version does not seem to exist"
 	VersionWhiteListStatusNotFound        RequestStatus = "WhiteListStatus for path not found"
 	VersionExpired                        RequestStatus = "Api Version has expired, please check documentation or contact administrator"
+	VersionSunset                         RequestStatus = "This API version has been retired"
+	VersionDeprecated                     RequestStatus = "This API version is deprecated"
 	VersionDefaultForNotVersionedNotFound RequestStatus = "No default API version for this non-versioned API found"
 	VersionAmbiguousDefault               RequestStatus = "Ambiguous default API version for this non-versioned API"
 	APIExpired                            RequestStatus = "API has expired, please check documentation or contact administrator"
@@ -126,6 +131,8 @@ const (
 	StatusGoPlugin                        RequestStatus = "Go plugin"
 	StatusPersistGraphQL                  RequestStatus = "Persist GraphQL"
 	StatusRateLimit                       RequestStatus = "Rate Limited"
+	StatusUpstreamHostHeader              RequestStatus = "Upstream Host header set"
+	StatusRateCost                        RequestStatus = "Rate cost"
 	// MCPPrimitiveNotFound is returned when a primitive VEM is accessed directly (not via JSON-RPC routing).
 	// It intentionally maps to HTTP 404 to avoid exposing internal-only endpoints.
 	MCPPrimitiveNotFound RequestStatus = "MCP Primitive Not Found"
@@ -141,6 +148,8 @@ type EndPointCacheMeta struct {
 type TransformSpec struct {
 	apidef.TemplateMeta
 	Template *texttemplate.Template
+	// JQ is the compiled expression of transforms using the JQ engine.
+	JQ *gojq.Code
 }
 
 type ExtendedCircuitBreakerMeta struct {
@@ -162,6 +171,48 @@ func (a *APISpec) GetSessionLifetimeRespectsKeyExpiration() bool {
 	return a.SessionLifetimeRespectsKeyExpiration
 }
 
+// trackInFlight wraps h so requests are counted as in-flight until they complete.
+func (s *APISpec) trackInFlight(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&s.inFlight, 1)
+		defer atomic.AddInt64(&s.inFlight, -1)
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// InFlight returns the number of requests currently served by the API spec.
+func (s *APISpec) InFlight() int {
+	return int(atomic.LoadInt64(&s.inFlight))
+}
+
+// UnloadWhenIdle releases the API spec resources once its in-flight requests completed.
+// If requests are still in flight after timeout, the spec is unloaded regardless.
+func (s *APISpec) UnloadWhenIdle(timeout time.Duration) {
+	if s.InFlight() == 0 {
+		s.Unload()
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+
+		deadline := time.After(timeout)
+		for s.InFlight() > 0 {
+			select {
+			case <-ticker.C:
+			case <-deadline:
+				log.WithField("api_id", s.APIID).Warningf("Unloading API with %d requests still in flight", s.InFlight())
+				s.Unload()
+				return
+			}
+		}
+
+		s.Unload()
+	}()
+}
+
 // AddUnloadHook adds a function to be called when the API spec is unloaded
 func (s *APISpec) AddUnloadHook(hook func()) {
 	s.unloadHooks = append(s.unloadHooks, hook)
@@ -250,7 +301,18 @@ func (s *APISpec) validateTCP() error {
 }
 
 func (s *APISpec) validateHTTP() error {
-	// NOOP
+	result := apidef.Validate(s.APIDefinition, apidef.ValidationRuleSet{
+		&apidef.RuleValidateHeaderRewrites{},
+		&apidef.RuleValidateTransformJQ{},
+		&apidef.RuleValidateTransformSOAP{},
+		&apidef.RuleValidateNormaliseURLPatterns{},
+		&apidef.RuleValidateErrorTemplates{},
+		&apidef.RuleValidateCORSPaths{},
+		&apidef.RuleValidateJSONSchemas{},
+	})
+	if !result.IsValid {
+		return result.FirstError()
+	}
 	return nil
 }
 
@@ -297,6 +359,8 @@ func (a APIDefinitionLoader) MakeSpec(def *model.MergedAPI, logger *logrus.Entry
 		return currSpec, nil
 	}
 
+	spec.loadedAt = time.Now()
+
 	// new expiration feature
 	if def.Expiration != "" {
 		if t, err := time.Parse(apidef.ExpirationTimeFormat, def.Expiration); err != nil {
@@ -336,6 +400,18 @@ func (a APIDefinitionLoader) MakeSpec(def *model.MergedAPI, logger *logrus.Entry
 
 	spec.GlobalConfig = a.Gw.GetConfig()
 
+	if spec.normaliseURLPatterns, err = compileNormaliseURLPatterns(def.NormaliseURLPatterns); err != nil {
+		logger.WithError(err).Error("Couldn't compile analytics URL normalisation patterns")
+		return nil, err
+	}
+
+	if spec.errorTemplates, err = compileErrorTemplates(def.ErrorTemplates); err != nil {
+		logger.WithError(err).Error("Couldn't compile error templates")
+		return nil, err
+	}
+
+	spec.outlierDetector = newUpstreamOutlierDetector(def.Proxy.OutlierDetection)
+
 	if err = a.Gw.loadBundle(spec); err != nil {
 		logger.WithError(err).Error("Couldn't load bundle")
 		return nil, err
@@ -506,10 +582,7 @@ func (a APIDefinitionLoader) FromDashboardService(endpoint string) ([]*APISpec,
 	// Process
 	specs := a.prepareSpecs(apiDefs, gwConfig, false)
 
-	// Set the nonce
-	a.Gw.ServiceNonceMutex.Lock()
-	a.Gw.ServiceNonce = list.Nonce
-	a.Gw.ServiceNonceMutex.Unlock()
+	// The nonce was set by executeDashboardRequestWithRecovery
 	log.Debug("Loading APIS Finished: Nonce Set: ", list.Nonce)
 
 	return specs, nil
@@ -1023,6 +1096,24 @@ func (a APIDefinitionLoader) loadBlobTemplate(blob string) (*texttemplate.Templa
 	return apidef.Template.New("").Funcs(a.filterSprigFuncs()).Parse(string(uDec))
 }
 
+func (a APIDefinitionLoader) loadFileJQ(path string) (*gojq.Code, error) {
+	log.Debug("-- Loading JQ expression: ", path)
+	expression, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return apidef.CompileTransformJQ(string(expression))
+}
+
+func (a APIDefinitionLoader) loadBlobJQ(blob string) (*gojq.Code, error) {
+	log.Debug("-- Loading JQ blob")
+	uDec, err := base64.StdEncoding.DecodeString(blob)
+	if err != nil {
+		return nil, err
+	}
+	return apidef.CompileTransformJQ(string(uDec))
+}
+
 func (a APIDefinitionLoader) compileTransformPathSpec(paths []apidef.TemplateMeta, stat URLStatus, conf config.Config) []URLSpec {
 	// transform an extended configuration URL into an array of URLSpecs
 	// This way we can iterate the whole array once, on match we break with status
@@ -1044,13 +1135,23 @@ func (a APIDefinitionLoader) compileTransformPathSpec(paths []apidef.TemplateMet
 		// Load the templates
 		var err error
 
+		isJQ := stringSpec.Format == apidef.TransformFormatJQ
+
 		switch stringSpec.TemplateData.Mode {
 		case apidef.UseFile:
 			log.Debug("-- Using File mode")
-			newTransformSpec.Template, err = a.loadFileTemplate(stringSpec.TemplateData.TemplateSource)
+			if isJQ {
+				newTransformSpec.JQ, err = a.loadFileJQ(stringSpec.TemplateData.TemplateSource)
+			} else {
+				newTransformSpec.Template, err = a.loadFileTemplate(stringSpec.TemplateData.TemplateSource)
+			}
 		case apidef.UseBlob:
 			log.Debug("-- Blob mode")
-			newTransformSpec.Template, err = a.loadBlobTemplate(stringSpec.TemplateData.TemplateSource)
+			if isJQ {
+				newTransformSpec.JQ, err = a.loadBlobJQ(stringSpec.TemplateData.TemplateSource)
+			} else {
+				newTransformSpec.Template, err = a.loadBlobTemplate(stringSpec.TemplateData.TemplateSource)
+			}
 		default:
 			log.Warning("[Transform Templates] No template mode defined! Found: ", stringSpec.TemplateData.Mode)
 			err = errors.New("No valid template mode defined, must be either 'file' or 'blob'")
@@ -1140,6 +1241,42 @@ func (a APIDefinitionLoader) compileTimeoutPathSpec(paths []apidef.HardTimeoutMe
 	return urlSpec
 }
 
+func (a APIDefinitionLoader) compileUpstreamHostPathSpec(paths []apidef.UpstreamHostMeta, stat URLStatus, conf config.Config) []URLSpec {
+	urlSpec := []URLSpec{}
+
+	for _, stringSpec := range paths {
+		if stringSpec.Disabled {
+			continue
+		}
+
+		newSpec := URLSpec{}
+		a.generateRegex(stringSpec.Path, &newSpec, stat, conf)
+		newSpec.UpstreamHost = stringSpec
+
+		urlSpec = append(urlSpec, newSpec)
+	}
+
+	return urlSpec
+}
+
+func (a APIDefinitionLoader) compileRateCostPathSpec(paths []apidef.RateCostMeta, stat URLStatus, conf config.Config) []URLSpec {
+	urlSpec := []URLSpec{}
+
+	for _, stringSpec := range paths {
+		if stringSpec.Disabled || stringSpec.Cost <= 0 {
+			continue
+		}
+
+		newSpec := URLSpec{}
+		a.generateRegex(stringSpec.Path, &newSpec, stat, conf)
+		newSpec.RateCost = stringSpec
+
+		urlSpec = append(urlSpec, newSpec)
+	}
+
+	return urlSpec
+}
+
 func (a APIDefinitionLoader) compileRequestSizePathSpec(paths []apidef.RequestSizeMeta, stat URLStatus, conf config.Config) []URLSpec {
 	// transform an extended configuration URL into an array of URLSpecs
 	// This way we can iterate the whole array once, on match we break with status
@@ -1203,6 +1340,8 @@ func (a APIDefinitionLoader) compileCircuitBreakerPathSpec(paths []apidef.Circui
 						a.Gw.ServiceCache.Delete(spec.APIID)
 					}
 
+					a.Gw.MetricInstruments.RecordCircuitBreakerTrip(context.Background(), spec.APIID, spec.Proxy.ListenPath)
+
 					spec.FireEvent(EventBreakerTriggered, EventCurcuitBreakerMeta{
 						EventMetaDefault: EventMetaDefault{Message: "Breaker Tripped"},
 						CircuitEvent:     e,
@@ -1383,6 +1522,13 @@ func (a APIDefinitionLoader) compileValidateJSONPathsSpec(paths []apidef.Validat
 		// Extend with method actions
 
 		stringSpec.SchemaCache = gojsonschema.NewGoLoader(stringSpec.Schema)
+		if stringSpec.Schema != nil {
+			schema, err := gojsonschema.NewSchema(stringSpec.SchemaCache)
+			if err != nil {
+				log.WithError(err).Errorf("Failed to compile the JSON schema of %s %s", stringSpec.Method, stringSpec.Path)
+			}
+			stringSpec.SchemaCompiled = schema
+		}
 		newSpec.ValidatePathMeta = stringSpec
 		urlSpec = append(urlSpec, newSpec)
 	}
@@ -1946,6 +2092,8 @@ func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionIn
 	goPlugins := a.compileGopluginPathsSpec(apiVersionDef.ExtendedPaths.GoPlugin, GoPlugin, apiSpec, conf)
 	persistGraphQL := a.compilePersistGraphQLPathSpec(apiVersionDef.ExtendedPaths.PersistGraphQL, PersistGraphQL, apiSpec, conf)
 	rateLimitPaths := a.compileRateLimitPathsSpec(apiVersionDef.ExtendedPaths.RateLimit, RateLimit, conf)
+	upstreamHostPaths := a.compileUpstreamHostPathSpec(apiVersionDef.ExtendedPaths.UpstreamHostHeader, UpstreamHostHeader, conf)
+	rateCostPaths := a.compileRateCostPathSpec(apiVersionDef.ExtendedPaths.RateCost, RateCost, conf)
 
 	// OAS-specific middleware paths - compiled alongside Classic middleware
 	// The compile functions handle nil/empty OAS gracefully by returning empty slices
@@ -1977,6 +2125,8 @@ func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionIn
 	combinedPath = append(combinedPath, validateJSON...)
 	combinedPath = append(combinedPath, internalPaths...)
 	combinedPath = append(combinedPath, rateLimitPaths...)
+	combinedPath = append(combinedPath, upstreamHostPaths...)
+	combinedPath = append(combinedPath, rateCostPaths...)
 	combinedPath = append(combinedPath, oasValidateRequestPaths...)
 	combinedPath = append(combinedPath, oasMockResponsePaths...)
 
@@ -2076,12 +2226,40 @@ func (a *APISpec) getURLStatus(stat URLStatus) RequestStatus {
 		return StatusPersistGraphQL
 	case RateLimit:
 		return StatusRateLimit
+	case UpstreamHostHeader:
+		return StatusUpstreamHostHeader
+	case RateCost:
+		return StatusRateCost
 	default:
 		log.Error("URL Status was not one of Ignored, Blacklist or WhiteList! Blocking.")
 		return EndPointNotAllowed
 	}
 }
 
+// isIgnoredEndpoint reports whether the request matches an ignored endpoint, for requests whose
+// status was already decided by an earlier match such as a mock response.
+func (a *APISpec) isIgnoredEndpoint(r *http.Request, rxPaths []URLSpec) bool {
+	for i := range rxPaths {
+		if rxPaths[i].Status != Ignored || !rxPaths[i].matchesPath(r.URL.Path, a) {
+			continue
+		}
+
+		if rxPaths[i].MethodActions != nil { // Deprecated
+			if _, ok := rxPaths[i].MethodActions[r.Method]; ok {
+				return true
+			}
+
+			continue
+		}
+
+		if rxPaths[i].Ignored.Method == "" || rxPaths[i].Ignored.Method == r.Method {
+			return true
+		}
+	}
+
+	return false
+}
+
 // URLAllowedAndIgnored checks if a url is allowed and ignored.
 func (a *APISpec) URLAllowedAndIgnored(r *http.Request, rxPaths []URLSpec, whiteListStatus bool) (RequestStatus, interface{}) {
 	// Skip Whitelist and Blocklist for CORS preflight requests when CORS is enabled and passthrough is disabled.
@@ -2236,21 +2414,36 @@ func (a *APISpec) getVersionFromRequest(r *http.Request) string {
 	var vName string
 	defer ctxSetVersionName(r, &vName)
 
-	switch a.VersionDefinition.Location {
+	// The sources are tried in order, the first one holding a version wins.
+	sources := a.VersionDefinition.VersionSources()
+	for _, source := range sources {
+		if vName = a.versionFromSource(r, source, len(sources) > 1); vName != "" {
+			return vName
+		}
+	}
+
+	return ""
+}
+
+// versionFromSource reads the version from a single request location, stripping it when configured.
+// When the version is read from a list of sources, a URL segment only holds a version when it matches
+// the URL versioning pattern, so the sources following it are tried for the other requests.
+func (a *APISpec) versionFromSource(r *http.Request, source apidef.VersionSource, inList bool) string {
+	switch source.Location {
 	case apidef.HeaderLocation:
-		vName = r.Header.Get(a.VersionDefinition.Key)
-		if a.VersionDefinition.StripVersioningData {
+		vName := r.Header.Get(source.Key)
+		if vName != "" && a.VersionDefinition.StripVersioningData {
 			log.Debug("Stripping version from header: ", vName)
-			defer r.Header.Del(a.VersionDefinition.Key)
+			r.Header.Del(source.Key)
 		}
 
 		return vName
 	case apidef.URLParamLocation:
-		vName = r.URL.Query().Get(a.VersionDefinition.Key)
-		if a.VersionDefinition.StripVersioningData {
+		vName := r.URL.Query().Get(source.Key)
+		if vName != "" && a.VersionDefinition.StripVersioningData {
 			log.Debug("Stripping version from query: ", vName)
 			q := r.URL.Query()
-			q.Del(a.VersionDefinition.Key)
+			q.Del(source.Key)
 			r.URL.RawQuery = q.Encode()
 		}
 
@@ -2272,6 +2465,10 @@ func (a *APISpec) getVersionFromRequest(r *http.Request) string {
 					}
 				}
 
+				if inList && !matchesUrlVersioningPattern {
+					return ""
+				}
+
 				if (a.VersionDefinition.StripVersioningData || a.VersionDefinition.StripPath) && matchesUrlVersioningPattern {
 					log.Debug("Stripping version from url: ", part)
 
@@ -2279,9 +2476,6 @@ func (a *APISpec) getVersionFromRequest(r *http.Request) string {
 					r.URL.RawPath = strings.Replace(r.URL.RawPath, part+"/", "", 1)
 				}
 
-				//never delete this line as there's an easy to miss defer statement above
-				vName = part
-
 				return part
 			}
 		}
@@ -2519,6 +2713,14 @@ func (a *APISpec) getRedirectTargetUrl(inputUrl *url.URL) (*url.URL, error) {
 // hasActiveMock checks if specification has at least one active mock.
 func (a *APISpec) hasActiveMock() bool {
 	if !a.IsOAS {
+		for _, version := range a.VersionData.Versions {
+			for _, mock := range version.ExtendedPaths.MockResponse {
+				if !mock.Disabled {
+					return true
+				}
+			}
+		}
+
 		return false
 	}
 
@@ -30,6 +30,7 @@ import (
 	"github.com/TykTechnologies/tyk/apidef/oas"
 	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/ee/middleware/streams"
+	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/internal/model"
 	"github.com/TykTechnologies/tyk/internal/policy"
 	"github.com/TykTechnologies/tyk/regexp"
@@ -650,6 +651,8 @@ func TestOldMockResponse(t *testing.T) {
 			},
 		},
 	}
+	authHeaders := map[string]string{}
+
 	blackEndpointMeta := whiteEndpointMeta
 	blackEndpointMeta.Path = blackMockPath
 	ignoreEndpointMeta := whiteEndpointMeta
@@ -674,7 +677,9 @@ func TestOldMockResponse(t *testing.T) {
 		})[0]
 	}
 
-	check := func(t *testing.T, api *APISpec, tc []test.TestCase) {
+	// migratedTC overrides the expectations once the method actions are migrated to mock responses,
+	// which are only returned to authenticated requests.
+	check := func(t *testing.T, api *APISpec, tc []test.TestCase, migratedTC ...test.TestCase) {
 		t.Helper()
 		ts.Gw.LoadAPI(api)
 		_, _ = ts.Run(t, tc...)
@@ -683,7 +688,17 @@ func TestOldMockResponse(t *testing.T) {
 			_, err := api.Migrate()
 			assert.NoError(t, err)
 
+			if len(migratedTC) > 0 {
+				tc = migratedTC
+			}
+
 			ts.Gw.LoadAPI(api)
+
+			_, key := ts.CreateSession(func(s *user.SessionState) {
+				s.AccessRights = map[string]user.AccessDefinition{api.APIID: {APIID: api.APIID}}
+			})
+			authHeaders[header.Authorization] = key
+
 			_, _ = ts.Run(t, tc...)
 		})
 	}
@@ -704,7 +719,13 @@ func TestOldMockResponse(t *testing.T) {
 				{Method: http.MethodPost, Path: whiteMockPath, Code: http.StatusUnauthorized},
 				{Method: http.MethodPut, Path: whiteMockPath, Code: http.StatusForbidden},
 				{Method: http.MethodGet, Path: "/something", Code: http.StatusForbidden},
-			})
+			}, []test.TestCase{
+				{Method: http.MethodGet, Path: whiteMockPath, Code: http.StatusUnauthorized},
+				{Method: http.MethodGet, Path: whiteMockPath, Headers: authHeaders, BodyMatch: mockResponse, HeadersMatch: headers, Code: http.StatusTeapot},
+				{Method: http.MethodPost, Path: whiteMockPath, Code: http.StatusUnauthorized},
+				{Method: http.MethodPut, Path: whiteMockPath, Code: http.StatusForbidden},
+				{Method: http.MethodGet, Path: "/something", Code: http.StatusForbidden},
+			}...)
 		})
 	})
 
@@ -724,7 +745,13 @@ func TestOldMockResponse(t *testing.T) {
 				{Method: http.MethodPost, Path: blackMockPath, Code: http.StatusForbidden},
 				{Method: http.MethodPut, Path: blackMockPath, Code: http.StatusUnauthorized},
 				{Method: http.MethodGet, Path: "/something", Code: http.StatusUnauthorized},
-			})
+			}, []test.TestCase{
+				{Method: http.MethodGet, Path: blackMockPath, Code: http.StatusUnauthorized},
+				{Method: http.MethodGet, Path: blackMockPath, Headers: authHeaders, BodyMatch: mockResponse, HeadersMatch: headers, Code: http.StatusTeapot},
+				{Method: http.MethodPost, Path: blackMockPath, Code: http.StatusForbidden},
+				{Method: http.MethodPut, Path: blackMockPath, Code: http.StatusUnauthorized},
+				{Method: http.MethodGet, Path: "/something", Code: http.StatusUnauthorized},
+			}...)
 		})
 	})
 
@@ -790,8 +817,15 @@ func TestNewMockResponse(t *testing.T) {
 	})[0]
 
 	t.Run("protected", func(t *testing.T) {
+		_, key := ts.CreateSession(func(s *user.SessionState) {
+			s.AccessRights = map[string]user.AccessDefinition{api.APIID: {APIID: api.APIID}}
+		})
+		authHeaders := map[string]string{header.Authorization: key}
+
 		_, _ = ts.Run(t, []test.TestCase{
-			{Method: http.MethodGet, Path: mockPath, BodyMatch: mockResponse, Code: http.StatusTeapot},
+			{Method: http.MethodGet, Path: mockPath, BodyNotMatch: mockResponse, Code: http.StatusUnauthorized},
+			{Method: http.MethodGet, Path: mockPath, Headers: authHeaders, BodyMatch: mockResponse, HeadersMatch: headers, Code: http.StatusTeapot},
+			{Method: http.MethodPut, Path: mockPath, Headers: authHeaders, Code: http.StatusOK},
 		}...)
 	})
 
@@ -0,0 +1,163 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/internal/model"
+)
+
+var (
+	errLoadMissingAPIID   = errors.New("API ID is missing")
+	errLoadDuplicateAPIID = errors.New("API ID is declared by another definition")
+	errLoadNotRouted      = errors.New("API wasn't routed, its listen path or target URL is invalid")
+	errLoadFailed         = errors.New("API failed to load, check the gateway logs")
+)
+
+// LoadResult is the outcome of loading an API definition with LoadAPIDefinitions.
+type LoadResult struct {
+	APIID string
+	Name  string
+	// Loaded is true when the API is served by the gateway.
+	Loaded bool
+	// Errors holds the reasons the API wasn't loaded.
+	Errors []error
+	// Warnings holds issues which didn't prevent loading the API, e.g. a listen path shared with another API.
+	Warnings []string
+}
+
+// LoadAPIDefinitions replaces the loaded APIs with the given definitions, without going through
+// the dashboard, RPC or the app path. The definitions are validated, their middleware chains
+// compiled and their routes registered as by a reload, and the result of each is returned in
+// the same order. An invalid definition is skipped without failing the others.
+//
+// The APIs are replaced until the next reload, which loads the APIs from the configured source.
+// An error is only returned when the context is done before the APIs are loaded.
+func (gw *Gateway) LoadAPIDefinitions(ctx context.Context, defs []*apidef.APIDefinition) ([]LoadResult, error) {
+	gw.reloadMu.Lock()
+	defer gw.reloadMu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	startedAt := time.Now()
+	loader := APIDefinitionLoader{Gw: gw}
+
+	results := make([]LoadResult, len(defs))
+	specs := make([]*APISpec, 0, len(defs))
+	seen := make(map[string]struct{}, len(defs))
+
+	for i, def := range defs {
+		result := &results[i]
+		result.APIID, result.Name = def.APIID, def.Name
+
+		if def.APIID == "" {
+			result.Errors = append(result.Errors, errLoadMissingAPIID)
+			continue
+		}
+
+		if _, ok := seen[def.APIID]; ok {
+			result.Errors = append(result.Errors, errLoadDuplicateAPIID)
+			continue
+		}
+		seen[def.APIID] = struct{}{}
+
+		spec, err := loader.MakeSpec(&model.MergedAPI{APIDefinition: def}, nil)
+		if err != nil {
+			result.Errors = append(result.Errors, err)
+			continue
+		}
+
+		specs = append(specs, spec)
+	}
+
+	_, skipped := gw.setAPISpecs(specs, startedAt)
+	gw.loadSyncedAPIs()
+
+	skippedByID := make(map[string]error, len(skipped))
+	for _, s := range skipped {
+		skippedByID[s.APIID] = s.err
+	}
+
+	var conflicts []ListenPathConflict
+	if status := gw.lastReloadStatus.Load(); status != nil {
+		conflicts = status.ListenPathConflicts
+	}
+
+	for i := range results {
+		result := &results[i]
+		if len(result.Errors) > 0 {
+			continue
+		}
+
+		if err, ok := skippedByID[result.APIID]; ok {
+			result.Errors = append(result.Errors, err)
+			continue
+		}
+
+		addLoadConflicts(result, conflicts)
+		if len(result.Errors) > 0 {
+			continue
+		}
+
+		if err := gw.loadedAPIError(result.APIID); err != nil {
+			result.Errors = append(result.Errors, err)
+			continue
+		}
+
+		result.Loaded = true
+	}
+
+	return results, nil
+}
+
+// addLoadConflicts reports the listen path conflicts of an API, as an error when it was rejected.
+func addLoadConflicts(result *LoadResult, conflicts []ListenPathConflict) {
+	for _, conflict := range conflicts {
+		if !slices.Contains(conflict.APIIDs, result.APIID) {
+			continue
+		}
+
+		msg := fmt.Sprintf("listen path %q on domain %q is shared with the APIs %v", conflict.ListenPath, conflict.Domain, conflict.APIIDs)
+		if slices.Contains(conflict.Rejected, result.APIID) {
+			result.Errors = append(result.Errors, errors.New(msg))
+			continue
+		}
+
+		result.Warnings = append(result.Warnings, msg)
+	}
+}
+
+// loadedAPIError checks the API is registered and, for HTTP APIs, routed.
+func (gw *Gateway) loadedAPIError(apiID string) error {
+	gw.apisMu.RLock()
+	spec := gw.apisByID[apiID]
+	handles := gw.apisHandlesByID
+	gw.apisMu.RUnlock()
+
+	if spec == nil {
+		return errLoadFailed
+	}
+
+	switch spec.Protocol {
+	case "", "http", "https", "h2c":
+	default:
+		return nil
+	}
+
+	handle, ok := handles.Load(apiID)
+	if !ok {
+		return errLoadFailed
+	}
+
+	if chain, ok := handle.(*ChainObject); ok && chain.Skip && !spec.Internal {
+		return errLoadNotRouted
+	}
+
+	return nil
+}
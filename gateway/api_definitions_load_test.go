@@ -0,0 +1,89 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestLoadAPIDefinitions(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "previous"
+		spec.Proxy.ListenPath = "/previous/"
+	})
+
+	buildDef := func(apiID, listenPath string) *apidef.APIDefinition {
+		return BuildAPI(func(spec *APISpec) {
+			spec.APIID = apiID
+			spec.Name = apiID
+			spec.Proxy.ListenPath = listenPath
+		})[0].APIDefinition
+	}
+
+	bad := buildDef("bad", "/bad/")
+	bad.Protocol = "tcp"
+
+	results, err := ts.Gw.LoadAPIDefinitions(context.Background(), []*apidef.APIDefinition{
+		buildDef("good", "/good/"),
+		bad,
+		buildDef("", "/no-id/"),
+		buildDef("shared-a", "/shared/"),
+		buildDef("shared-b", "/shared/"),
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+
+	t.Run("per spec results", func(t *testing.T) {
+		assert.Equal(t, LoadResult{APIID: "good", Name: "good", Loaded: true}, results[0])
+
+		assert.Equal(t, "bad", results[1].APIID)
+		assert.False(t, results[1].Loaded)
+		require.Len(t, results[1].Errors, 1)
+		assert.EqualError(t, results[1].Errors[0], "missing listening port")
+
+		assert.False(t, results[2].Loaded)
+		assert.Equal(t, []error{errLoadMissingAPIID}, results[2].Errors)
+
+		for _, result := range results[3:] {
+			assert.True(t, result.Loaded, result.APIID)
+			assert.Empty(t, result.Errors, result.APIID)
+			assert.Len(t, result.Warnings, 1, result.APIID)
+		}
+	})
+
+	t.Run("routing state", func(t *testing.T) {
+		assert.NotNil(t, ts.Gw.getApiSpec("good"))
+		assert.Nil(t, ts.Gw.getApiSpec("bad"))
+		assert.Nil(t, ts.Gw.getApiSpec("previous"), "loaded APIs should be replaced")
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/good/", Code: http.StatusOK},
+			{Path: "/previous/", Code: http.StatusNotFound},
+		}...)
+
+		status := ts.Gw.lastReloadStatus.Load()
+		require.NotNil(t, status)
+		assert.Equal(t, []string{"good", "shared-a", "shared-b"}, status.Added)
+		assert.Equal(t, []string{"previous"}, status.Removed)
+		require.Len(t, status.Skipped, 1)
+		assert.Equal(t, "bad", status.Skipped[0].APIID)
+	})
+
+	t.Run("done context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := ts.Gw.LoadAPIDefinitions(ctx, []*apidef.APIDefinition{buildDef("other", "/other/")})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NotNil(t, ts.Gw.getApiSpec("good"))
+	})
+}
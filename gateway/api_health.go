@@ -0,0 +1,199 @@
+package gateway
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+const (
+	// apiHealthWindow is the number of seconds covered by the traffic stats of an API health snapshot.
+	apiHealthWindow = 60
+	// apiHealthLatencySamples is the size of the latency ring buffer kept per API.
+	apiHealthLatencySamples = 1024
+)
+
+// apiHealthBucket counts the requests served during a single second.
+type apiHealthBucket struct {
+	second   int64
+	requests int64
+	errors   int64
+}
+
+// apiHealthStats collects lock-free traffic stats for an API. Buckets are reused
+// once their second falls out of the window, so the counts are approximate under
+// heavy concurrency around a second boundary.
+type apiHealthStats struct {
+	buckets [apiHealthWindow]apiHealthBucket
+
+	// latencies holds samples packed as unix second (high 32 bits) and latency in ms (low 32 bits).
+	latencies [apiHealthLatencySamples]uint64
+	cursor    uint64
+
+	requestSizes  payloadSizeStats
+	responseSizes payloadSizeStats
+}
+
+func (s *apiHealthStats) record(now time.Time, code int, latencyMs int64) {
+	sec := now.Unix()
+	b := &s.buckets[sec%apiHealthWindow]
+	prev := atomic.LoadInt64(&b.second)
+	if prev < sec && atomic.CompareAndSwapInt64(&b.second, prev, sec) {
+		atomic.StoreInt64(&b.requests, 0)
+		atomic.StoreInt64(&b.errors, 0)
+	}
+
+	// A bucket already reused for a later second must not count an older request.
+	if prev <= sec {
+		atomic.AddInt64(&b.requests, 1)
+		if code >= http.StatusInternalServerError {
+			atomic.AddInt64(&b.errors, 1)
+		}
+	}
+
+	if latencyMs < 0 {
+		latencyMs = 0
+	} else if latencyMs > 0xFFFFFFFF {
+		latencyMs = 0xFFFFFFFF
+	}
+
+	i := atomic.AddUint64(&s.cursor, 1) - 1
+	atomic.StoreUint64(&s.latencies[i%apiHealthLatencySamples], uint64(sec)<<32|uint64(latencyMs))
+}
+
+func (s *apiHealthStats) snapshot(now time.Time) APIHealthTraffic {
+	traffic := APIHealthTraffic{WindowSeconds: apiHealthWindow}
+	since := now.Unix() - apiHealthWindow
+
+	for i := range s.buckets {
+		b := &s.buckets[i]
+		if atomic.LoadInt64(&b.second) <= since {
+			continue
+		}
+		traffic.Requests += atomic.LoadInt64(&b.requests)
+		traffic.Errors5xx += atomic.LoadInt64(&b.errors)
+	}
+
+	if traffic.Requests > 0 {
+		traffic.ErrorRate = float64(traffic.Errors5xx) / float64(traffic.Requests)
+	}
+
+	latencies := make([]int64, 0, apiHealthLatencySamples)
+	for i := range s.latencies {
+		v := atomic.LoadUint64(&s.latencies[i])
+		if v == 0 || int64(v>>32) <= since {
+			continue
+		}
+		latencies = append(latencies, int64(v&0xFFFFFFFF))
+	}
+
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		traffic.LatencyP95 = latencies[(len(latencies)*95+99)/100-1]
+	}
+
+	traffic.RequestSize = s.requestSizes.snapshot(now)
+	traffic.ResponseSize = s.responseSizes.snapshot(now)
+
+	return traffic
+}
+
+// APIHealthSnapshot describes the current health of a loaded API.
+type APIHealthSnapshot struct {
+	APIID               string             `json:"api_id"`
+	LastReload          time.Time          `json:"last_reload"`
+	Hosts               []APIHealthHost    `json:"hosts"`
+	OpenCircuitBreakers []APIHealthBreaker `json:"open_circuit_breakers"`
+	Traffic             APIHealthTraffic   `json:"traffic"`
+}
+
+// APIHealthHost is the uptime checker status of an upstream host.
+type APIHealthHost struct {
+	URL string `json:"url"`
+	Up  bool   `json:"up"`
+}
+
+// APIHealthBreaker identifies an endpoint whose circuit breaker is open.
+type APIHealthBreaker struct {
+	Version string `json:"version"`
+	Path    string `json:"path"`
+	Method  string `json:"method"`
+}
+
+// APIHealthTraffic holds the traffic stats of an API over the last WindowSeconds.
+type APIHealthTraffic struct {
+	WindowSeconds int     `json:"window_seconds"`
+	Requests      int64   `json:"requests"`
+	Errors5xx     int64   `json:"errors_5xx"`
+	ErrorRate     float64 `json:"error_rate"`
+	LatencyP95    int64   `json:"latency_p95_ms"`
+
+	RequestSize  APIHealthPayloadSize `json:"request_size"`
+	ResponseSize APIHealthPayloadSize `json:"response_size"`
+}
+
+// APIHealthPayloadSize holds the size in bytes of the request or response bodies over the traffic window.
+type APIHealthPayloadSize struct {
+	Avg int64 `json:"avg_bytes"`
+	P95 int64 `json:"p95_bytes"`
+	Max int64 `json:"max_bytes"`
+}
+
+func (gw *Gateway) apiHealthSnapshot(spec *APISpec) *APIHealthSnapshot {
+	snapshot := &APIHealthSnapshot{
+		APIID:               spec.APIID,
+		LastReload:          spec.loadedAt,
+		Hosts:               []APIHealthHost{},
+		OpenCircuitBreakers: []APIHealthBreaker{},
+		Traffic:             spec.health.snapshot(time.Now()),
+	}
+
+	if !spec.UptimeTests.Disabled && gw.GlobalHostChecker != nil {
+		for _, check := range spec.UptimeTests.CheckList {
+			if _, err := url.Parse(check.CheckURL); err != nil {
+				continue
+			}
+			snapshot.Hosts = append(snapshot.Hosts, APIHealthHost{
+				URL: check.CheckURL,
+				Up:  !gw.GlobalHostChecker.HostDown(check.CheckURL),
+			})
+		}
+	}
+
+	versions := make([]string, 0, len(spec.RxPaths))
+	for version := range spec.RxPaths {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+
+	for _, version := range versions {
+		for _, urlSpec := range spec.RxPaths[version] {
+			if urlSpec.Status != CircuitBreaker || urlSpec.CircuitBreaker.CB == nil || !urlSpec.CircuitBreaker.CB.Tripped() {
+				continue
+			}
+			snapshot.OpenCircuitBreakers = append(snapshot.OpenCircuitBreakers, APIHealthBreaker{
+				Version: version,
+				Path:    urlSpec.CircuitBreaker.Path,
+				Method:  urlSpec.CircuitBreaker.Method,
+			})
+		}
+	}
+
+	return snapshot
+}
+
+func (gw *Gateway) apiHealthHandler(w http.ResponseWriter, r *http.Request) {
+	spec := gw.getApiSpec(mux.Vars(r)["apiID"])
+	if spec == nil {
+		doJSONWrite(w, http.StatusNotFound, apiError(apidef.ErrAPINotFound.Error()))
+		return
+	}
+
+	doJSONWrite(w, http.StatusOK, gw.apiHealthSnapshot(spec))
+}
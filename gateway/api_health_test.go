@@ -0,0 +1,116 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestAPIHealthStats(t *testing.T) {
+	var stats apiHealthStats
+	now := time.Now()
+
+	for i := int64(1); i <= 100; i++ {
+		code := http.StatusOK
+		if i%4 == 0 {
+			code = http.StatusBadGateway
+		}
+		stats.record(now, code, i)
+	}
+
+	// Samples older than the window are ignored.
+	stats.record(now.Add(-2*apiHealthWindow*time.Second), http.StatusInternalServerError, 1000)
+
+	traffic := stats.snapshot(now)
+	assert.Equal(t, apiHealthWindow, traffic.WindowSeconds)
+	assert.Equal(t, int64(100), traffic.Requests)
+	assert.Equal(t, int64(25), traffic.Errors5xx)
+	assert.InDelta(t, 0.25, traffic.ErrorRate, 0.001)
+	assert.Equal(t, int64(95), traffic.LatencyP95)
+
+	traffic = stats.snapshot(now.Add(2 * apiHealthWindow * time.Second))
+	assert.Zero(t, traffic.Requests)
+	assert.Zero(t, traffic.LatencyP95)
+}
+
+func TestAPIHealthHandler(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/errors" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}))
+	defer upstream.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "health-api"
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.CircuitBreakerEnabled = true
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.CircuitBreaker = []apidef.CircuitBreakerMeta{{
+				Path:                 "/errors",
+				Method:               http.MethodGet,
+				ThresholdPercent:     0.5,
+				Samples:              3,
+				ReturnToServiceAfter: 60,
+			}}
+		})
+	})
+
+	getHealth := func(t *testing.T) APIHealthSnapshot {
+		t.Helper()
+		resp, err := ts.Run(t, test.TestCase{
+			Path:      "/tyk/apis/health-api/health",
+			AdminAuth: true,
+			Code:      http.StatusOK,
+		})
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var snapshot APIHealthSnapshot
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&snapshot))
+		return snapshot
+	}
+
+	snapshot := getHealth(t)
+	assert.Equal(t, "health-api", snapshot.APIID)
+	assert.False(t, snapshot.LastReload.IsZero())
+	assert.Empty(t, snapshot.OpenCircuitBreakers)
+	assert.Zero(t, snapshot.Traffic.Requests)
+
+	for i := 0; i < 10; i++ {
+		_, _ = ts.Run(t, test.TestCase{Path: "/ok", Code: http.StatusOK})
+	}
+	for i := 0; i < 5; i++ {
+		_, _ = ts.Run(t, test.TestCase{Path: "/errors"})
+	}
+
+	snapshot = getHealth(t)
+	assert.Equal(t, int64(15), snapshot.Traffic.Requests)
+	assert.Equal(t, int64(5), snapshot.Traffic.Errors5xx)
+	assert.InDelta(t, 1.0/3, snapshot.Traffic.ErrorRate, 0.01)
+	assert.GreaterOrEqual(t, snapshot.Traffic.LatencyP95, int64(5))
+	assert.Equal(t, []APIHealthBreaker{{Version: "v1", Path: "/errors", Method: http.MethodGet}}, snapshot.OpenCircuitBreakers)
+
+	t.Run("unknown API", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{
+			Path:      "/tyk/apis/missing/health",
+			AdminAuth: true,
+			Code:      http.StatusNotFound,
+			BodyMatch: apidef.ErrAPINotFound.Error(),
+		})
+	})
+}
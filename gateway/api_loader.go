@@ -12,11 +12,13 @@ import (
 	"path/filepath"
 	"runtime"
 	"runtime/debug"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	texttemplate "text/template"
+	"time"
 
 	"github.com/TykTechnologies/tyk/common/option"
 
@@ -26,6 +28,7 @@ import (
 
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/apidef/oas"
+	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/coprocess"
 	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/rpc"
@@ -63,8 +66,11 @@ type ProcessSpecOptions struct {
 func (gw *Gateway) prepareStorage() generalStores {
 	var gs generalStores
 
-	gs.redisStore = &storage.RedisCluster{KeyPrefix: "apikey-", HashKeys: gw.GetConfig().HashKeys, ConnectionHandler: gw.StorageConnectionHandler}
-	gs.redisStore.Connect()
+	// the APIs look up the sessions on the nearest read replica, the key management reads from the primary
+	keyStore := gw.newKeyStore()
+	keyStore.ReadFromReplica = true
+	keyStore.Connect()
+	gs.redisStore = keyStore
 
 	gs.redisOrgStore = &storage.RedisCluster{KeyPrefix: "orgkey.", ConnectionHandler: gw.StorageConnectionHandler}
 	gs.redisOrgStore.Connect()
@@ -75,7 +81,9 @@ func (gw *Gateway) prepareStorage() generalStores {
 	gs.rpcAuthStore = &RPCStorageHandler{KeyPrefix: "apikey-", HashKeys: gw.GetConfig().HashKeys, Gw: gw}
 	gs.rpcOrgStore = gw.getGlobalMDCBStorageHandler("orgkey.", false)
 
-	gw.GlobalSessionManager.Init(gs.redisStore)
+	globalKeyStore := gw.newKeyStore()
+	globalKeyStore.Connect()
+	gw.GlobalSessionManager.Init(globalKeyStore)
 	return gs
 }
 
@@ -400,6 +408,9 @@ func (gw *Gateway) processSpec(
 		logger.Info("Checking security policy: Open")
 	}
 
+	// RequestIDMiddleware runs first, so the requests rejected by any other middleware carry the request ID.
+	gw.mwAppendEnabled(&chainArray, &RequestIDMiddleware{BaseMiddleware: baseMid.Copy()})
+
 	// For MCP/JSON-RPC APIs, add RequestSizeLimitMiddleware early to prevent DoS attacks.
 	// JSONRPCMiddleware reads the entire request body, so size must be validated first.
 	if spec.IsMCP() {
@@ -426,7 +437,7 @@ func (gw *Gateway) processSpec(
 			)
 		} else if !isJSDriver(mwDriver) {
 			coprocessLog.Debug("Registering coprocess middleware, hook name: ", obj.Name, "hook type: Pre", ", driver: ", mwDriver)
-			gw.mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid.Copy(), coprocess.HookType_Pre, obj.Name, mwDriver, obj.RawBodyOnly, nil})
+			gw.mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid.Copy(), coprocess.HookType_Pre, obj.Name, mwDriver, obj.RawBodyOnly, obj.StreamBody, nil})
 		} else {
 			chainArray = append(chainArray, gw.createDynamicMiddleware(pickMiddlewareClassName(obj), true, obj.RequireSession, baseMid.Copy()))
 		}
@@ -435,6 +446,7 @@ func (gw *Gateway) processSpec(
 	gw.mwAppendEnabled(&chainArray, &RateCheckMW{BaseMiddleware: baseMid.Copy()})
 	gw.mwAppendEnabled(&chainArray, &IPWhiteListMiddleware{BaseMiddleware: baseMid.Copy()})
 	gw.mwAppendEnabled(&chainArray, &IPBlackListMiddleware{BaseMiddleware: baseMid.Copy()})
+	gw.mwAppendEnabled(&chainArray, &GeoRestrictionMiddleware{BaseMiddleware: baseMid.Copy()})
 	gw.mwAppendEnabled(&chainArray, &CertificateCheckMW{BaseMiddleware: baseMid.Copy()})
 	gw.mwAppendEnabled(&chainArray, &OrganizationMonitor{BaseMiddleware: baseMid.Copy(), mon: Monitor{Gw: gw}})
 
@@ -535,7 +547,7 @@ func (gw *Gateway) processSpec(
 				coprocessLog.Debug("Registering coprocess middleware, hook name: ", mwAuthCheckFunc.Name, "hook type: CustomKeyCheck", ", driver: ", mwDriver)
 
 				newExtractor(spec, baseMid.Copy())
-				coProcessMW := &CoProcessMiddleware{baseMid.Copy(), coprocess.HookType_CustomKeyCheck, mwAuthCheckFunc.Name, mwDriver, mwAuthCheckFunc.RawBodyOnly, nil}
+				coProcessMW := &CoProcessMiddleware{baseMid.Copy(), coprocess.HookType_CustomKeyCheck, mwAuthCheckFunc.Name, mwDriver, mwAuthCheckFunc.RawBodyOnly, mwAuthCheckFunc.StreamBody, nil}
 				if gw.mwAppendEnabled(&authArray, coProcessMW) {
 					authMiddlewares = append(authMiddlewares, coProcessMW)
 				}
@@ -600,15 +612,20 @@ func (gw *Gateway) processSpec(
 				chainArray = append(chainArray, gw.createDynamicMiddleware(pickMiddlewareClassName(obj), false, obj.RequireSession, baseMid.Copy()))
 			} else {
 				coprocessLog.Debug("Registering coprocess middleware, hook name: ", obj.Name, "hook type: Pre", ", driver: ", mwDriver)
-				gw.mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid.Copy(), coprocess.HookType_PostKeyAuth, obj.Name, mwDriver, obj.RawBodyOnly, nil})
+				gw.mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid.Copy(), coprocess.HookType_PostKeyAuth, obj.Name, mwDriver, obj.RawBodyOnly, obj.StreamBody, nil})
 			}
 		}
 
 		gw.mwAppendEnabled(&chainArray, &StripAuth{baseMid.Copy()})
 		gw.mwAppendEnabled(&chainArray, &KeyExpired{baseMid.Copy()})
+		gw.mwAppendEnabled(&chainArray, &KeyIPAllowListMiddleware{baseMid.Copy()})
 		gw.mwAppendEnabled(&chainArray, &AccessRightsCheck{baseMid.Copy()})
 		gw.mwAppendEnabled(&chainArray, &GranularAccessMiddleware{baseMid.Copy()})
+		gw.mwAppendEnabled(&chainArray, &ScopeAccessMiddleware{baseMid.Copy()})
+		gw.mwAppendEnabled(&chainArray, newMaintenanceModeMiddleware(baseMid.Copy()))
 		gw.mwAppendEnabled(&chainArray, &RateLimitAndQuotaCheck{baseMid.Copy()})
+	} else {
+		gw.mwAppendEnabled(&chainArray, newMaintenanceModeMiddleware(baseMid.Copy()))
 	}
 
 	if spec.IsMCP() {
@@ -620,6 +637,8 @@ func (gw *Gateway) processSpec(
 	gw.mwAppendEnabled(&chainArray, getOAuth2ExchangeMw(baseMid.Copy()))
 
 	gw.mwAppendEnabled(&chainArray, &RateLimitForAPI{BaseMiddleware: baseMid.Copy(), quotaKey: options.quotaKey})
+	gw.mwAppendEnabled(&chainArray, &ConcurrencyLimit{BaseMiddleware: baseMid.Copy()})
+	gw.mwAppendEnabled(&chainArray, &GraphQLPersistedQueryMiddleware{BaseMiddleware: baseMid.Copy()})
 	gw.mwAppendEnabled(&chainArray, &GraphQLMiddleware{BaseMiddleware: baseMid.Copy()})
 
 	if streamMw := getStreamingMiddleware(baseMid); streamMw != nil {
@@ -639,6 +658,8 @@ func (gw *Gateway) processSpec(
 		gw.mwAppendEnabled(&chainArray, upstreamOAuthMw)
 	}
 
+	gw.mwAppendEnabled(&chainArray, &UpstreamSigning{BaseMiddleware: baseMid.Copy()})
+
 	gw.mwAppendEnabled(&chainArray, &ValidateJSON{BaseMiddleware: baseMid.Copy()})
 	gw.mwAppendEnabled(&chainArray, &ValidateRequest{BaseMiddleware: baseMid.Copy()})
 	gw.mwAppendEnabled(&chainArray, &PersistGraphQLOperationMiddleware{BaseMiddleware: baseMid.Copy()})
@@ -650,6 +671,7 @@ func (gw *Gateway) processSpec(
 
 	// Earliest we can respond with cache get 200 ok
 	gw.mwAppendEnabled(&chainArray, newMockResponseMiddleware(baseMid.Copy()))
+	gw.mwAppendEnabled(&chainArray, &Idempotency{BaseMiddleware: baseMid.Copy()})
 	gw.mwAppendEnabled(&chainArray, &RedisCacheMiddleware{BaseMiddleware: baseMid.Copy(), store: &cacheStore})
 	gw.mwAppendEnabled(&chainArray, &VirtualEndpoint{BaseMiddleware: baseMid.Copy()})
 	gw.mwAppendEnabled(&chainArray, &RequestSigning{BaseMiddleware: baseMid.Copy()})
@@ -668,7 +690,7 @@ func (gw *Gateway) processSpec(
 			)
 		} else if !isJSDriver(mwDriver) {
 			coprocessLog.Debug("Registering coprocess middleware, hook name: ", obj.Name, "hook type: Post", ", driver: ", mwDriver)
-			gw.mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid.Copy(), coprocess.HookType_Post, obj.Name, mwDriver, obj.RawBodyOnly, nil})
+			gw.mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid.Copy(), coprocess.HookType_Post, obj.Name, mwDriver, obj.RawBodyOnly, obj.StreamBody, nil})
 		} else {
 			chainArray = append(chainArray, gw.createDynamicMiddleware(pickMiddlewareClassName(obj), false, obj.RequireSession, baseMid.Copy()))
 		}
@@ -679,16 +701,23 @@ func (gw *Gateway) processSpec(
 	// and either continues to the next VEM or allows the request to proceed to upstream.
 	gw.mwAppendEnabled(&chainArray, &MCPVEMContinuationMiddleware{BaseMiddleware: baseMid.Copy()})
 
-	chain = alice.New(chainArray...).Then(&DummyProxyHandler{SH: SuccessHandler{baseMid.Copy()}, Gw: gw})
+	var proxyHandler http.Handler = &DummyProxyHandler{SH: SuccessHandler{baseMid.Copy()}, Gw: gw}
+	if admission := gw.getAdmissionController(); admission != nil {
+		proxyHandler = admission.handler(spec, ErrorHandler{baseMid.Copy()}, proxyHandler)
+	}
+
+	chain = alice.New(chainArray...).Then(proxyHandler)
 
 	if !spec.UseKeylessAccess {
 		var simpleArray []alice.Constructor
 		gw.mwAppendEnabled(&simpleArray, &IPWhiteListMiddleware{baseMid.Copy()})
 		gw.mwAppendEnabled(&simpleArray, &IPBlackListMiddleware{BaseMiddleware: baseMid.Copy()})
+		gw.mwAppendEnabled(&simpleArray, &GeoRestrictionMiddleware{BaseMiddleware: baseMid.Copy()})
 		gw.mwAppendEnabled(&simpleArray, &OrganizationMonitor{BaseMiddleware: baseMid.Copy(), mon: Monitor{Gw: gw}})
 		gw.mwAppendEnabled(&simpleArray, &VersionCheck{BaseMiddleware: baseMid.Copy()})
 		simpleArray = append(simpleArray, authArray...)
 		gw.mwAppendEnabled(&simpleArray, &KeyExpired{baseMid.Copy()})
+		gw.mwAppendEnabled(&simpleArray, &KeyIPAllowListMiddleware{baseMid.Copy()})
 		gw.mwAppendEnabled(&simpleArray, &AccessRightsCheck{baseMid.Copy()})
 
 		rateLimitPath := path.Join(spec.Proxy.ListenPath, rateLimitEndpoint)
@@ -700,6 +729,16 @@ func (gw *Gateway) processSpec(
 
 	logger.Debug("Setting Listen Path: ", spec.Proxy.ListenPath)
 
+	if spec.ConcurrencyLimit.Enabled {
+		chain = releaseConcurrencySlots(chain)
+	}
+
+	if spec.Idempotency.Enabled {
+		chain = releaseIdempotencyLock(chain)
+	}
+
+	chain = spec.trackInFlight(chain)
+
 	if trace.IsEnabled() { // trace.IsEnabled = check if opentracing is enabled
 		chainDef.ThisHandler = trace.Handle(spec.Name, chain)
 	} else if gw.GetConfig().OpenTelemetry.TracesEnabled() { // check if opentelemetry is enabled
@@ -760,21 +799,49 @@ func (gw *Gateway) configureAuthAndOrgStores(gs *generalStores, spec *APISpec) (
 // Check for recursion
 const defaultLoopLevelLimit = 5
 
-func isLoop(r *http.Request) (bool, error) {
-	if r.URL.Scheme != "tyk" {
-		return false, nil
-	}
+// loopDetectedError is returned when a request makes more internal hops than allowed.
+type loopDetectedError struct {
+	limit int
+	trace []string
+}
 
+func (e *loopDetectedError) Error() string {
+	return fmt.Sprintf("Loop level too deep. Found more than %d loops in single request, visited APIs: %s", e.limit, strings.Join(e.trace, ", "))
+}
+
+// checkLoopLimit returns a *loopDetectedError if the request, currently handled by the API
+// with apiID, made more internal hops than allowed. Every hop is counted, whether it's a
+// URL rewrite, a tyk:// target or an in memory loop, so APIs pointing at each other can't
+// recurse forever. The limit set with the `loop_limit` query parameter takes precedence
+// over the gateway's max_loop_depth.
+func (gw *Gateway) checkLoopLimit(r *http.Request, apiID string) error {
 	limit := ctxLoopLevelLimit(r)
 	if limit == 0 {
+		limit = gw.GetConfig().MaxLoopDepth
+	}
+	if limit <= 0 {
 		limit = defaultLoopLevelLimit
 	}
 
-	if ctxLoopLevel(r) > limit {
-		return true, fmt.Errorf("Loop level too deep. Found more than %d loops in single request", limit)
+	if trace := ctxLoopTrace(r); len(trace) > limit {
+		return &loopDetectedError{limit: limit, trace: append(slices.Clone(trace), apiID)}
+	}
+
+	return nil
+}
+
+// loopTraceTag returns the analytics tag holding the APIs a looping request passed through.
+func loopTraceTag(r *http.Request, apiID string) (string, bool) {
+	trace := ctxLoopTrace(r)
+	if len(trace) == 0 {
+		return "", false
 	}
 
-	return true, nil
+	return "loop-trace-" + strings.Join(append(slices.Clone(trace), apiID), ","), true
+}
+
+func isLoop(r *http.Request) bool {
+	return r.URL.Scheme == "tyk"
 }
 
 type DummyProxyHandler struct {
@@ -791,13 +858,17 @@ func (d *DummyProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		r.Method = newMethod
 		ctxSetTransformRequestMethod(r, "")
 	}
-	if found, err := isLoop(r); found {
-		if err != nil {
+	if isLoop(r) {
+		if err := d.Gw.checkLoopLimit(r, d.SH.Spec.APIID); err != nil {
 			handler := ErrorHandler{d.SH.Base()}
-			handler.HandleError(w, r, err.Error(), http.StatusInternalServerError, true)
+			handler.HandleError(w, r, err.Error(), http.StatusLoopDetected, true)
 			return
 		}
 
+		if host, ok := d.Gw.upstreamHostHeader(d.SH.Spec, r); ok {
+			r.Host = host
+		}
+
 		r.URL.Scheme = "http"
 		if methodOverride := r.URL.Query().Get("method"); methodOverride != "" {
 			r.Method = methodOverride
@@ -848,12 +919,19 @@ func (d *DummyProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			ctxSetOrigRequestURL(r, nil)
 		}
 
+		ctxAppendLoopTrace(r, d.SH.Spec.APIID)
 		ctxIncLoopLevel(r, loopLevelLimit)
 		handler.ServeHTTP(w, r)
 		return
 	}
 
 	if d.SH.Spec.target.Scheme == "tyk" {
+		if err := d.Gw.checkLoopLimit(r, d.SH.Spec.APIID); err != nil {
+			handler := ErrorHandler{d.SH.Base()}
+			handler.HandleError(w, r, err.Error(), http.StatusLoopDetected, true)
+			return
+		}
+
 		handler, _, found := d.Gw.findInternalHttpHandlerByNameOrID(d.SH.Spec.target.Host)
 
 		if !found {
@@ -871,9 +949,14 @@ func (d *DummyProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if host, ok := d.Gw.upstreamHostHeader(d.SH.Spec, r); ok {
+			r.Host = host
+		}
+
 		d.SH.Spec.SanitizeProxyPaths(r)
 		ctxSetInternalRedirectTarget(r, targetUrl)
 		ctxSetVersionInfo(r, nil)
+		ctxAppendLoopTrace(r, d.SH.Spec.APIID)
 		handler.ServeHTTP(w, r)
 		return
 	}
@@ -902,7 +985,12 @@ func (gw *Gateway) loadGlobalApps() {
 	specs := make([]*APISpec, len(gw.apiSpecs))
 	copy(specs, gw.apiSpecs)
 	gw.apisMu.RUnlock()
+
+	specs, conflicts := gw.checkListenPathConflicts(specs)
 	gw.loadApps(specs)
+
+	// loadApps sorted the specs in the order they're matched
+	gw.setReloadStatusRouting(conflicts, specs)
 }
 
 func trimCategories(name string) string {
@@ -1261,17 +1349,112 @@ func sortSpecsByListenPath(specs []*APISpec, enabledCustomDomain bool) {
 	// doesn't break /foo-bar
 	sort.Slice(specs, func(i, j int) bool {
 		// when custom domains are disabled in config we sort by the following rules:
+		// - decreasing order of listen path priority
 		// - decreasing order of listen path length
 
 		// when custom domains are enabled in config we sort by the following rules:
-		// - decreasing order of listen path length
 		// - if a domain is empty it should be at the end
+		// - decreasing order of listen path priority
+		// - decreasing order of listen path length
+
+		// the ties are broken by listen path, domain and API ID, so the order
+		// doesn't depend on the order the specs were loaded in.
 		if enabledCustomDomain && (specs[i].Domain == "") != (specs[j].Domain == "") {
 			return specs[i].Domain != ""
 		}
 
-		return listenPathLength(specs[i].Proxy.ListenPath) > listenPathLength(specs[j].Proxy.ListenPath)
+		if pi, pj := specs[i].Proxy.ListenPathPriority, specs[j].Proxy.ListenPathPriority; pi != pj {
+			return pi > pj
+		}
+
+		if li, lj := listenPathLength(specs[i].Proxy.ListenPath), listenPathLength(specs[j].Proxy.ListenPath); li != lj {
+			return li > lj
+		}
+
+		if specs[i].Proxy.ListenPath != specs[j].Proxy.ListenPath {
+			return specs[i].Proxy.ListenPath > specs[j].Proxy.ListenPath
+		}
+
+		if specs[i].Domain != specs[j].Domain {
+			return specs[i].Domain < specs[j].Domain
+		}
+
+		return specs[i].APIID < specs[j].APIID
+	})
+}
+
+// checkListenPathConflicts logs the APIs declaring the same listen path on the same domain. When
+// reject_duplicate_listen_paths is set, only the one with the highest listen path priority, then the
+// lowest API ID, is kept.
+func (gw *Gateway) checkListenPathConflicts(specs []*APISpec) ([]*APISpec, []ListenPathConflict) {
+	byListenHash := make(map[string][]*APISpec, len(specs))
+	for _, spec := range specs {
+		hash := generateDomainPath(spec.GetAPIDomain(), spec.Proxy.ListenPath)
+		byListenHash[hash] = append(byListenHash[hash], spec)
+	}
+
+	reject := gw.GetConfig().RejectDuplicateListenPaths
+	rejected := make(map[*APISpec]struct{})
+	conflicts := []ListenPathConflict{}
+
+	for _, duplicates := range byListenHash {
+		if len(duplicates) < 2 {
+			continue
+		}
+
+		sort.Slice(duplicates, func(i, j int) bool {
+			if pi, pj := duplicates[i].Proxy.ListenPathPriority, duplicates[j].Proxy.ListenPathPriority; pi != pj {
+				return pi > pj
+			}
+			return duplicates[i].APIID < duplicates[j].APIID
+		})
+
+		conflict := ListenPathConflict{
+			Domain:     duplicates[0].GetAPIDomain(),
+			ListenPath: duplicates[0].Proxy.ListenPath,
+			Rejected:   []string{},
+		}
+		for i, spec := range duplicates {
+			conflict.APIIDs = append(conflict.APIIDs, spec.APIID)
+			if reject && i > 0 {
+				rejected[spec] = struct{}{}
+				conflict.Rejected = append(conflict.Rejected, spec.APIID)
+			}
+		}
+
+		logger := mainLog.WithFields(logrus.Fields{
+			"domain":      conflict.Domain,
+			"listen_path": conflict.ListenPath,
+			"api_ids":     conflict.APIIDs,
+		})
+		if reject {
+			logger.WithField("rejected", conflict.Rejected).Error("Listen path conflict, only loading API ", conflict.APIIDs[0])
+		} else {
+			logger.Warning("Listen path conflict")
+		}
+
+		conflicts = append(conflicts, conflict)
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Domain != conflicts[j].Domain {
+			return conflicts[i].Domain < conflicts[j].Domain
+		}
+		return conflicts[i].ListenPath < conflicts[j].ListenPath
 	})
+
+	if len(rejected) == 0 {
+		return specs, conflicts
+	}
+
+	kept := make([]*APISpec, 0, len(specs)-len(rejected))
+	for _, spec := range specs {
+		if _, ok := rejected[spec]; !ok {
+			kept = append(kept, spec)
+		}
+	}
+
+	return kept, conflicts
 }
 
 func listenPathLength(listenPath string) int {
@@ -1298,6 +1481,8 @@ func listenPathLength(listenPath string) int {
 func (gw *Gateway) loadApps(specs []*APISpec) {
 	mainLog.Info("Loading API configurations.")
 
+	gw.reloadGeoIPDB(specs)
+
 	// Only build usage map in RPC mode (when tracker exists)
 	if gw.certUsageTracker != nil {
 		// Build the complete usage map offline (no locks held during construction)
@@ -1454,9 +1639,16 @@ func (gw *Gateway) loadApps(specs []*APISpec) {
 
 	gw.apisMu.Unlock()
 
+	unloadTimeout := time.Duration(gw.GetConfig().GracefulShutdownTimeoutDuration) * time.Second
+	if unloadTimeout == 0 {
+		unloadTimeout = config.GracefulShutdownDefaultDuration * time.Second
+	}
+
+	// Requests already routed to removed or changed specs keep using them, so
+	// their resources are only released once these requests completed.
 	for _, spec := range specsToUnload {
 		mainLog.Debugf("Unloading spec %s", spec.APIID)
-		spec.Unload()
+		spec.UnloadWhenIdle(unloadTimeout)
 	}
 
 	mainLog.Debug("Checker host list")
@@ -9402,10 +9402,10 @@ func TestSortAPISpecs(t *testing.T) {
 				{APIDefinition: &apidef.APIDefinition{Domain: "tyk.io", Proxy: apidef.ProxyConfig{ListenPath: "/path"}}},
 				{APIDefinition: &apidef.APIDefinition{Domain: "{domains:tyk.io|abc.def.ghi}", Proxy: apidef.ProxyConfig{ListenPath: "/path"}}},
 				{APIDefinition: &apidef.APIDefinition{Domain: "{domains:tyk.io}", Proxy: apidef.ProxyConfig{ListenPath: "/path"}}},
-				{APIDefinition: &apidef.APIDefinition{Domain: "samelength1.com", Proxy: apidef.ProxyConfig{ListenPath: "/a"}}},
+				{APIDefinition: &apidef.APIDefinition{Domain: "abc.def.ghi", Proxy: apidef.ProxyConfig{ListenPath: "/b"}}},
 				{APIDefinition: &apidef.APIDefinition{Domain: "samelength2.com", Proxy: apidef.ProxyConfig{ListenPath: "/b"}}},
 				{APIDefinition: &apidef.APIDefinition{Domain: "tyk.io", Proxy: apidef.ProxyConfig{ListenPath: "/b"}}},
-				{APIDefinition: &apidef.APIDefinition{Domain: "abc.def.ghi", Proxy: apidef.ProxyConfig{ListenPath: "/b"}}},
+				{APIDefinition: &apidef.APIDefinition{Domain: "samelength1.com", Proxy: apidef.ProxyConfig{ListenPath: "/a"}}},
 				{APIDefinition: &apidef.APIDefinition{Domain: "tyk.io", Proxy: apidef.ProxyConfig{ListenPath: "/a"}}},
 				{APIDefinition: &apidef.APIDefinition{Domain: "", Proxy: apidef.ProxyConfig{ListenPath: "/aaaaaaaaaaaaaaaaaaaa"}}},
 			},
@@ -9964,3 +9964,150 @@ func TestProcessSpec_ErrorOverrideDisabled(t *testing.T) {
 		assert.Nil(t, specs[0].GetCompiledErrorOverrides())
 	})
 }
+
+func TestReload_inFlightRequestToRemovedAPI(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	removed := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "removed"
+		spec.Proxy.ListenPath = "/removed/"
+		spec.Proxy.TargetURL = upstream.URL
+	})[0]
+
+	result := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(ts.URL + "/removed/slow")
+		if err != nil {
+			result <- 0
+			return
+		}
+		resp.Body.Close()
+		result <- resp.StatusCode
+	}()
+
+	require.Eventually(t, func() bool {
+		return removed.InFlight() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "remaining"
+		spec.Proxy.ListenPath = "/remaining/"
+	})
+
+	_, _ = ts.Run(t, test.TestCase{Path: "/removed/slow", Code: http.StatusNotFound})
+	removed.RLock()
+	assert.NotNil(t, removed.HTTPTransport, "spec with in-flight requests must not be unloaded")
+	removed.RUnlock()
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-result)
+
+	assert.Eventually(t, func() bool {
+		removed.RLock()
+		defer removed.RUnlock()
+		return removed.HTTPTransport == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestListenPathMatchingOrder(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	buildSpec := func(apiID, listenPath, upstreamPath string, priority int) *APISpec {
+		return BuildAPI(func(spec *APISpec) {
+			spec.APIID = apiID
+			spec.Proxy.ListenPath = listenPath
+			spec.Proxy.TargetURL = TestHttpAny + upstreamPath
+			spec.Proxy.ListenPathPriority = priority
+		})[0]
+	}
+
+	// The specs are loaded in the order of their API IDs, swapping them swaps the load order of the listen paths.
+	for _, ids := range [][2]string{{"api-a", "api-b"}, {"api-b", "api-a"}} {
+		t.Run("longest listen path wins, "+ids[0]+" on /foo/", func(t *testing.T) {
+			ts.Gw.LoadAPI(
+				buildSpec(ids[0], "/foo/", "/foo-api", 0),
+				buildSpec(ids[1], "/foo/bar/", "/foo-bar-api", 0),
+			)
+
+			_, _ = ts.Run(t, []test.TestCase{
+				{Path: "/foo/bar/baz", Code: http.StatusOK, BodyMatch: `"Url":"/foo-bar-api/`},
+				{Path: "/foo/baz", Code: http.StatusOK, BodyMatch: `"Url":"/foo-api/`},
+			}...)
+
+			status := ts.Gw.lastReloadStatus.Load()
+			require.NotNil(t, status)
+			assert.Equal(t, []MatchedListenPath{
+				{APIID: ids[1], ListenPath: "/foo/bar/"},
+				{APIID: ids[0], ListenPath: "/foo/"},
+			}, status.MatchingOrder)
+			assert.Empty(t, status.ListenPathConflicts)
+		})
+
+		t.Run("priority wins, "+ids[0]+" on /foo/", func(t *testing.T) {
+			ts.Gw.LoadAPI(
+				buildSpec(ids[0], "/foo/", "/foo-api", 1),
+				buildSpec(ids[1], "/foo/bar/", "/foo-bar-api", 0),
+			)
+
+			_, _ = ts.Run(t, test.TestCase{Path: "/foo/bar/baz", Code: http.StatusOK, BodyMatch: `"Url":"/foo-api/`})
+
+			status := ts.Gw.lastReloadStatus.Load()
+			require.NotNil(t, status)
+			assert.Equal(t, []MatchedListenPath{
+				{APIID: ids[0], ListenPath: "/foo/", Priority: 1},
+				{APIID: ids[1], ListenPath: "/foo/bar/"},
+			}, status.MatchingOrder)
+		})
+	}
+}
+
+func TestListenPathConflicts(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.RejectDuplicateListenPaths = true
+	})
+	defer ts.Close()
+
+	buildSpec := func(apiID, upstreamPath string, priority int) *APISpec {
+		return BuildAPI(func(spec *APISpec) {
+			spec.APIID = apiID
+			spec.Proxy.ListenPath = "/foo/"
+			spec.Proxy.TargetURL = TestHttpAny + upstreamPath
+			spec.Proxy.ListenPathPriority = priority
+		})[0]
+	}
+
+	t.Run("lowest API ID is kept", func(t *testing.T) {
+		for _, specs := range [][]*APISpec{
+			{buildSpec("api-a", "/a", 0), buildSpec("api-b", "/b", 0)},
+			{buildSpec("api-b", "/b", 0), buildSpec("api-a", "/a", 0)},
+		} {
+			ts.Gw.LoadAPI(specs...)
+
+			_, _ = ts.Run(t, test.TestCase{Path: "/foo/bar", Code: http.StatusOK, BodyMatch: `"Url":"/a/`})
+			assert.Nil(t, ts.Gw.getApiSpec("api-b"))
+
+			status := ts.Gw.lastReloadStatus.Load()
+			require.NotNil(t, status)
+			assert.Equal(t, []ListenPathConflict{
+				{ListenPath: "/foo/", APIIDs: []string{"api-a", "api-b"}, Rejected: []string{"api-b"}},
+			}, status.ListenPathConflicts)
+			assert.Equal(t, []MatchedListenPath{{APIID: "api-a", ListenPath: "/foo/"}}, status.MatchingOrder)
+		}
+	})
+
+	t.Run("highest priority is kept", func(t *testing.T) {
+		ts.Gw.LoadAPI(buildSpec("api-a", "/a", 0), buildSpec("api-b", "/b", 5))
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/foo/bar", Code: http.StatusOK, BodyMatch: `"Url":"/b/`})
+		assert.Nil(t, ts.Gw.getApiSpec("api-a"))
+	})
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/TykTechnologies/tyk/certs"
 	"github.com/TykTechnologies/tyk/config"
 	internalmodel "github.com/TykTechnologies/tyk/internal/model"
+	"github.com/TykTechnologies/tyk/internal/policy"
 	"github.com/TykTechnologies/tyk/internal/uuid"
 	"github.com/TykTechnologies/tyk/pkg/identifier"
 	"github.com/TykTechnologies/tyk/storage"
@@ -806,6 +807,59 @@ func BenchmarkKeyHandler_CreateKeyHandler(b *testing.B) {
 	}
 }
 
+func TestKeyHandler_GetKeyPolicyLimits(t *testing.T) {
+	const testAPIID = "testAPIID"
+
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = testAPIID
+		spec.UseKeylessAccess = false
+		spec.OrgID = "default"
+	})
+
+	accessRights := map[string]user.AccessDefinition{testAPIID: {APIID: testAPIID, Versions: []string{"v1"}}}
+
+	aclID := ts.CreatePolicy(func(p *user.Policy) {
+		p.ID = "acl"
+		p.Partitions.Acl = true
+		p.AccessRights = accessRights
+	})
+
+	highID := ts.CreatePolicy(func(p *user.Policy) {
+		p.ID = "rate-high"
+		p.Partitions.RateLimit = true
+		p.Rate, p.Per = 100, 60
+		p.AccessRights = accessRights
+	})
+
+	lowID := ts.CreatePolicy(func(p *user.Policy) {
+		p.ID = "rate-low"
+		p.Partitions.RateLimit = true
+		p.Rate, p.Per = 10, 60
+		p.AccessRights = accessRights
+	})
+
+	_, key := ts.CreateSession(func(s *user.SessionState) {
+		s.ApplyPolicies = []string{aclID, lowID, highID}
+	})
+
+	resp, _ := ts.Run(t, test.TestCase{
+		Method: http.MethodGet, Path: "/tyk/keys/" + key + "?api_id=" + testAPIID, AdminAuth: true, Code: http.StatusOK,
+	})
+
+	var detail struct {
+		user.SessionState
+		PolicyLimits policy.LimitSources `json:"policy_limits"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&detail))
+
+	assert.Equal(t, float64(100), detail.AccessRights[testAPIID].Limit.Rate)
+	assert.Equal(t, highID, detail.PolicyLimits.APIs[testAPIID]["rate"])
+	assert.Contains(t, detail.MetaData, policy.PartitionConflictsMetaKey)
+}
+
 func TestKeyHandler_DeleteKeyWithQuota(t *testing.T) {
 	const testAPIID = "testAPIID"
 	const orgId = "default"
@@ -1811,6 +1865,78 @@ func TestInvalidateCache(t *testing.T) {
 	}...)
 }
 
+func TestInvalidateCacheByPattern(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	api := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "pattern-cache"
+		spec.Proxy.ListenPath = "/pattern-cache/"
+		spec.CacheOptions = apidef.CacheOptions{
+			CacheTimeout:         60,
+			EnableCache:          true,
+			CacheAllSafeRequests: true,
+		}
+	})[0]
+
+	headerCache := map[string]string{cachedResponseHeader: "1"}
+	paths := []string{"/products/123", "/products/1234", "/products/456", "/orders/1"}
+
+	cacheAll := func(t *testing.T) {
+		t.Helper()
+		for _, path := range paths {
+			_, _ = ts.Run(t, []test.TestCase{
+				{Path: "/pattern-cache" + path, Delay: 50 * time.Millisecond},
+				{Path: "/pattern-cache" + path, HeadersMatch: headerCache},
+			}...)
+		}
+	}
+	assertCached := func(t *testing.T, cached ...string) {
+		t.Helper()
+		for _, path := range paths {
+			tc := test.TestCase{Path: "/pattern-cache" + path, HeadersNotMatch: headerCache}
+			for _, c := range cached {
+				if c == path {
+					tc = test.TestCase{Path: "/pattern-cache" + path, HeadersMatch: headerCache}
+				}
+			}
+			_, _ = ts.Run(t, tc)
+		}
+	}
+	invalidate := func(query string) string {
+		return "/tyk/cache/" + api.APIID + "?" + query
+	}
+
+	t.Run("glob", func(t *testing.T) {
+		cacheAll(t)
+
+		_, _ = ts.Run(t, test.TestCase{
+			Method: http.MethodDelete, Path: invalidate("pattern=/products/123*"), AdminAuth: true,
+			Code: http.StatusOK, BodyMatch: `"removed":2`,
+		})
+
+		assertCached(t, "/products/456", "/orders/1")
+	})
+
+	t.Run("regex", func(t *testing.T) {
+		cacheAll(t)
+
+		_, _ = ts.Run(t, test.TestCase{
+			Method: http.MethodDelete, Path: invalidate("pattern_type=regex&pattern=^/(orders|products/4)"), AdminAuth: true,
+			Code: http.StatusOK, BodyMatch: `"removed":2`,
+		})
+
+		assertCached(t, "/products/123", "/products/1234")
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Method: http.MethodDelete, Path: invalidate("pattern_type=regex&pattern=("), AdminAuth: true, Code: http.StatusBadRequest},
+			{Method: http.MethodDelete, Path: invalidate("pattern_type=prefix&pattern=/products"), AdminAuth: true, Code: http.StatusBadRequest},
+		}...)
+	})
+}
+
 func TestGetOAuthClients(t *testing.T) {
 	ts := StartTest(nil)
 	defer ts.Close()
@@ -4028,6 +4154,70 @@ func testImportOAS(t *testing.T, ts *Test, testCase test.TestCase) string {
 	return importResp.Key
 }
 
+func TestImportPostman(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	collection := `{
+  "info": {"name": "Petstore", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+  "variable": [{"key": "baseUrl", "value": "` + TestHttpAny + `"}],
+  "item": [{
+    "name": "Pets",
+    "item": [
+      {
+        "name": "Get pet",
+        "event": [{"listen": "test", "script": {"exec": [""]}}],
+        "request": {"method": "GET", "url": {"host": ["{{baseUrl}}"], "path": ["pets", ":petId"]}},
+        "response": [{"name": "Pet", "code": 201, "body": "{\"name\":\"Rex\"}"}]
+      },
+      {"name": "Create pet", "request": {"method": "POST", "url": "{{baseUrl}}/pets"}}
+    ]
+  }]
+}`
+
+	importPostman := func(t *testing.T, params map[string]string, code int) apiImportSuccess {
+		t.Helper()
+
+		resp, _ := ts.Run(t, test.TestCase{AdminAuth: true, Method: http.MethodPost, Path: "/tyk/apis/import/postman",
+			QueryParams: params, Data: collection, Code: code})
+
+		var importResp apiImportSuccess
+		_ = json.NewDecoder(resp.Body).Decode(&importResp)
+		ts.Gw.DoReload()
+
+		return importResp
+	}
+
+	t.Run("proxied", func(t *testing.T) {
+		importResp := importPostman(t, map[string]string{"listenPath": "/petstore/"}, http.StatusOK)
+		assert.Equal(t, "added", importResp.Action)
+		assert.Equal(t, []string{`scripts of "Pets/Get pet" aren't imported`}, importResp.Warnings)
+
+		spec := ts.Gw.getApiSpec(importResp.Key)
+		require.NotNil(t, spec)
+		assert.Equal(t, TestHttpAny, spec.Proxy.TargetURL)
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Method: http.MethodGet, Path: "/petstore/pets/1", Code: http.StatusOK, BodyMatch: `"Url":"/pets/1"`},
+			{Method: http.MethodPost, Path: "/petstore/pets", Code: http.StatusOK},
+			{Method: http.MethodDelete, Path: "/petstore/pets/1", Code: http.StatusForbidden},
+			{Method: http.MethodGet, Path: "/petstore/owners", Code: http.StatusForbidden},
+		}...)
+	})
+
+	t.Run("mocked", func(t *testing.T) {
+		importResp := importPostman(t, map[string]string{"apiID": "mocked-petstore", "mockResponse": "true"}, http.StatusOK)
+		assert.Equal(t, "mocked-petstore", importResp.Key)
+
+		_, _ = ts.Run(t, test.TestCase{Method: http.MethodGet, Path: "/mocked-petstore/pets/1",
+			Code: http.StatusCreated, BodyMatch: `{"name":"Rex"}`})
+	})
+
+	t.Run("invalid upstream URL", func(t *testing.T) {
+		_ = importPostman(t, map[string]string{"upstreamURL": "upstream.example.com"}, http.StatusBadRequest)
+	})
+}
+
 func TestGetAPI_WithVersionBaseID(t *testing.T) {
 	ts := StartTest(nil)
 	defer ts.Close()
@@ -4092,6 +4282,55 @@ func TestGetAPI_WithVersionBaseID(t *testing.T) {
 	}...)
 }
 
+func TestAPIOpenAPIHandler(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "classic-api"
+		spec.Name = "Classic API"
+		spec.Proxy.ListenPath = "/classic/"
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.UseExtendedPaths = true
+			v.ExtendedPaths.WhiteList = []apidef.EndPointMeta{{Path: "/pets", MethodActions: map[string]apidef.EndpointMethodMeta{
+				http.MethodGet: {Action: apidef.NoAction},
+			}}}
+			v.ExtendedPaths.MockResponse = []apidef.MockResponseMeta{{Path: "/status", Method: http.MethodGet, Code: http.StatusOK, Body: `{"ok":true}`}}
+			v.ExtendedPaths.TransformJQ = []apidef.TransformJQMeta{{Path: "/pets", Method: http.MethodPost, Filter: "."}}
+		})
+	})
+
+	resp, err := ts.Run(t, test.TestCase{
+		Path:      "/tyk/apis/classic-api/openapi",
+		AdminAuth: true,
+		Code:      http.StatusOK,
+	})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	doc, err := openapi3.NewLoader().LoadFromData(body)
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+
+	assert.Equal(t, "Classic API", doc.Info.Title)
+	require.Len(t, doc.Servers, 1)
+	assert.True(t, strings.HasSuffix(doc.Servers[0].URL, "/classic/"))
+	assert.NotNil(t, doc.Paths.Value("/pets").Get)
+	assert.NotNil(t, doc.Paths.Value("/status").Get.Responses.Status(http.StatusOK))
+	assert.Contains(t, doc.Extensions[oas.ExtensionTykWarnings], "the transform_jq extended paths aren't described")
+	assert.NotContains(t, doc.Extensions, oas.ExtensionTykAPIGateway)
+
+	_, _ = ts.Run(t, test.TestCase{
+		Path:      "/tyk/apis/missing/openapi",
+		AdminAuth: true,
+		Code:      http.StatusNotFound,
+		BodyMatch: apidef.ErrAPINotFound.Error(),
+	})
+}
+
 func TestGetOASAPI_WithVersionBaseID(t *testing.T) {
 	ts := StartTest(nil)
 	defer ts.Close()
@@ -0,0 +1,343 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/apidef/oas"
+	"github.com/TykTechnologies/tyk/goplugin"
+	"github.com/TykTechnologies/tyk/internal/httputil"
+	"github.com/TykTechnologies/tyk/regexp"
+)
+
+// APIValidationResult is the outcome of validating an API definition without loading it.
+// Errors describe a definition that would fail to load or references something broken,
+// warnings describe features this gateway has disabled, so they would not run.
+type APIValidationResult struct {
+	Valid    bool     `json:"valid"`
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
+}
+
+func newAPIValidationResult() *APIValidationResult {
+	return &APIValidationResult{
+		Valid:    true,
+		Errors:   []string{},
+		Warnings: []string{},
+	}
+}
+
+func (r *APIValidationResult) addError(format string, args ...interface{}) {
+	r.Valid = false
+	r.Errors = appendUnique(r.Errors, fmt.Sprintf(format, args...))
+}
+
+func (r *APIValidationResult) addWarning(format string, args ...interface{}) {
+	r.Warnings = appendUnique(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+func appendUnique(list []string, msg string) []string {
+	for _, m := range list {
+		if m == msg {
+			return list
+		}
+	}
+	return append(list, msg)
+}
+
+// validateAPISpec runs the checks applied when creating and loading an API along with
+// middleware sanity checks. It only reads the gateway configuration, so it's safe to
+// call concurrently with reloads.
+func (gw *Gateway) validateAPISpec(spec *APISpec) *APIValidationResult {
+	result := newAPIValidationResult()
+
+	for _, err := range apidef.Validate(spec.APIDefinition, apidef.DefaultValidationRuleSet).Errors {
+		result.addError("%s", err)
+	}
+
+	if err := spec.Validate(gw.GetConfig().OAS); err != nil {
+		result.addError("%s", err)
+	}
+
+	gw.validateAPIRegexps(spec, result)
+	gw.validateAPIPlugins(spec, result)
+
+	return result
+}
+
+func (gw *Gateway) validateAPIRegexps(spec *APISpec, result *APIValidationResult) {
+	conf := gw.GetConfig()
+	isPrefixMatch := conf.HttpServerOptions.EnablePathPrefixMatching
+	isSuffixMatch := conf.HttpServerOptions.EnablePathSuffixMatching
+
+	versions := make([]string, 0, len(spec.VersionData.Versions))
+	for name := range spec.VersionData.Versions {
+		versions = append(versions, name)
+	}
+	sort.Strings(versions)
+
+	for _, name := range versions {
+		paths := spec.VersionData.Versions[name].ExtendedPaths
+
+		for _, path := range extendedPathsList(paths) {
+			if _, err := regexp.Compile(httputil.PreparePathRegexp(path, isPrefixMatch, isSuffixMatch)); err != nil {
+				result.addError("version %q: invalid endpoint path %q: %v", name, path, err)
+			}
+		}
+
+		for _, rewrite := range paths.URLRewrite {
+			if _, err := regexp.Compile(rewrite.MatchPattern); err != nil {
+				result.addError("version %q: invalid URL rewrite pattern %q: %v", name, rewrite.MatchPattern, err)
+			}
+
+			for _, trigger := range rewrite.Triggers {
+				for _, pattern := range triggerPatterns(trigger.Options) {
+					if _, err := regexp.Compile(pattern); err != nil {
+						result.addError("version %q: invalid URL rewrite trigger pattern %q: %v", name, pattern, err)
+					}
+				}
+			}
+		}
+	}
+}
+
+// extendedPathsList returns the endpoint paths configured in the extended paths.
+func extendedPathsList(e apidef.ExtendedPathsSet) []string {
+	paths := append([]string{}, e.Cached...)
+	for _, m := range e.Ignored {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range e.WhiteList {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range e.BlackList {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range e.MockResponse {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range e.AdvanceCacheConfig {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range append(e.Transform, e.TransformResponse...) {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range append(e.TransformJQ, e.TransformJQResponse...) {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range append(e.TransformHeader, e.TransformResponseHeader...) {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range e.HardTimeouts {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range e.CircuitBreaker {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range e.URLRewrite {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range e.Virtual {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range e.SizeLimit {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range e.MethodTransforms {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range append(e.TrackEndpoints, e.DoNotTrackEndpoints...) {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range e.ValidateJSON {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range e.ValidateRequest {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range e.Internal {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range e.GoPlugin {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range e.PersistGraphQL {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range e.RateLimit {
+		paths = append(paths, m.Path)
+	}
+	return paths
+}
+
+// triggerPatterns returns the non-empty match patterns of URL rewrite trigger options.
+func triggerPatterns(o apidef.RoutingTriggerOptions) []string {
+	var patterns []string
+	for _, matches := range []map[string]apidef.StringRegexMap{
+		o.HeaderMatches,
+		o.QueryValMatches,
+		o.PathPartMatches,
+		o.SessionMetaMatches,
+		o.RequestContextMatches,
+		o.PayloadJSONMatches,
+	} {
+		for _, m := range matches {
+			if m.MatchPattern != "" {
+				patterns = append(patterns, m.MatchPattern)
+			}
+		}
+	}
+	if o.PayloadMatches.MatchPattern != "" {
+		patterns = append(patterns, o.PayloadMatches.MatchPattern)
+	}
+	sort.Strings(patterns)
+	return patterns
+}
+
+func (gw *Gateway) validateAPIPlugins(spec *APISpec, result *APIValidationResult) {
+	conf := gw.GetConfig()
+	mw := spec.CustomMiddleware
+
+	var defs []apidef.MiddlewareDefinition
+	if !mw.AuthCheck.Disabled && mw.AuthCheck.Name != "" {
+		defs = append(defs, mw.AuthCheck)
+	}
+	for _, list := range [][]apidef.MiddlewareDefinition{mw.Pre, mw.Post, mw.PostKeyAuth, mw.Response} {
+		for _, def := range list {
+			if !def.Disabled {
+				defs = append(defs, def)
+			}
+		}
+	}
+
+	// Plugin files are shipped in the bundle, they can't be checked before it's fetched
+	fromBundle := !spec.CustomMiddlewareBundleDisabled && spec.CustomMiddlewareBundle != ""
+	if fromBundle && !conf.EnableBundleDownloader {
+		result.addWarning("bundle downloader is disabled, bundle %q will not be loaded", spec.CustomMiddlewareBundle)
+	}
+
+	if len(defs) > 0 {
+		switch driver := mw.Driver; {
+		case driver == apidef.GoPluginDriver:
+			for _, def := range defs {
+				if !fromBundle {
+					checkGoPluginFile(def.Path, result)
+				}
+			}
+			checkGoPluginSupport(result)
+		case isJSDriver(driver):
+			if !conf.EnableJSVM {
+				result.addWarning("JSVM is disabled, %s middleware will not run", driver)
+				break
+			}
+			for _, def := range defs {
+				if !fromBundle && def.Code == "" && def.Path != "" {
+					checkMiddlewareFile(def.Path, result)
+				}
+			}
+		case driver == apidef.PythonDriver, driver == apidef.LuaDriver, driver == apidef.GrpcDriver:
+			if !conf.CoProcessOptions.EnableCoProcess {
+				result.addWarning("coprocess is disabled, %s middleware will not run", driver)
+			}
+		}
+	}
+
+	for _, v := range spec.VersionData.Versions {
+		for _, meta := range v.ExtendedPaths.GoPlugin {
+			if meta.Disabled {
+				continue
+			}
+			checkGoPluginFile(meta.PluginPath, result)
+			checkGoPluginSupport(result)
+		}
+
+		for _, meta := range v.ExtendedPaths.Virtual {
+			if meta.Disabled {
+				continue
+			}
+			if !conf.EnableJSVM {
+				result.addWarning("JSVM is disabled, virtual endpoints will not run")
+			}
+			switch meta.FunctionSourceType {
+			case apidef.UseFile:
+				checkMiddlewareFile(meta.FunctionSourceURI, result)
+			case apidef.UseBlob:
+				if conf.DisableVirtualPathBlobs {
+					result.addWarning("virtual endpoint blobs are disabled, virtual endpoint %q will not run", meta.Path)
+				}
+			}
+		}
+	}
+
+	if spec.AnalyticsPlugin.Enabled {
+		checkGoPluginFile(spec.AnalyticsPlugin.PluginPath, result)
+		checkGoPluginSupport(result)
+	}
+}
+
+func checkGoPluginFile(path string, result *APIValidationResult) {
+	if _, err := goplugin.GetPluginFileNameToLoad(goplugin.FileSystemStorage{}, path); err != nil {
+		result.addError("go plugin %q: %v", path, err)
+	}
+}
+
+func checkGoPluginSupport(result *APIValidationResult) {
+	if !goplugin.Enabled {
+		result.addWarning("go plugins are not supported by this gateway build")
+	}
+}
+
+func checkMiddlewareFile(path string, result *APIValidationResult) {
+	if _, err := os.Stat(path); err != nil {
+		result.addError("middleware file %q: %v", path, err)
+	}
+}
+
+func writeAPIValidationResult(w http.ResponseWriter, result *APIValidationResult) {
+	code := http.StatusOK
+	if !result.Valid {
+		code = http.StatusBadRequest
+	}
+	doJSONWrite(w, code, result)
+}
+
+func (gw *Gateway) validateAPIHandler(w http.ResponseWriter, r *http.Request) {
+	var def apidef.APIDefinition
+	if err := json.NewDecoder(r.Body).Decode(&def); err != nil {
+		doJSONWrite(w, http.StatusBadRequest, apiError("Request malformed"))
+		return
+	}
+
+	writeAPIValidationResult(w, gw.validateAPISpec(&APISpec{APIDefinition: &def}))
+}
+
+func (gw *Gateway) validateAPIOASHandler(w http.ResponseWriter, r *http.Request) {
+	reqBodyInBytes, oasObj, err := extractOASObjFromReq(r.Body)
+	if err != nil {
+		doJSONWrite(w, http.StatusBadRequest, apiError(err.Error()))
+		return
+	}
+
+	if err := oas.ValidateOASObject(reqBodyInBytes, oasObj.OpenAPI); err != nil {
+		result := newAPIValidationResult()
+		result.addError("%s", err)
+		writeAPIValidationResult(w, result)
+		return
+	}
+
+	if oasObj.GetTykExtension() == nil {
+		result := newAPIValidationResult()
+		result.addError("%s", apidef.ErrPayloadWithoutTykExtension)
+		writeAPIValidationResult(w, result)
+		return
+	}
+
+	var def apidef.APIDefinition
+	oasObj.ExtractTo(&def)
+
+	writeAPIValidationResult(w, gw.validateAPISpec(&APISpec{APIDefinition: &def, OAS: *oasObj}))
+}
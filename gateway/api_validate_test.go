@@ -0,0 +1,129 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/apidef/oas"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestValidateAPIHandler(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	validate := func(t *testing.T, path string, data interface{}, code int) APIValidationResult {
+		t.Helper()
+		resp, err := ts.Run(t, test.TestCase{
+			Method:    http.MethodPost,
+			Path:      path,
+			Data:      data,
+			AdminAuth: true,
+			Code:      code,
+		})
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var result APIValidationResult
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		return result
+	}
+
+	t.Run("valid API", func(t *testing.T) {
+		spec := BuildAPI(func(spec *APISpec) {
+			spec.APIID = "validate-api"
+		})[0]
+
+		result := validate(t, "/tyk/apis/validate", spec.APIDefinition, http.StatusOK)
+		assert.True(t, result.Valid)
+		assert.Empty(t, result.Errors)
+
+		// Validation doesn't load the API.
+		assert.Nil(t, ts.Gw.getApiSpec("validate-api"))
+	})
+
+	t.Run("invalid regex in extended paths", func(t *testing.T) {
+		spec := BuildAPI(func(spec *APISpec) {
+			UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+				v.ExtendedPaths.Ignored = []apidef.EndPointMeta{{Path: "/ignored/[a-", Method: http.MethodGet}}
+			})
+		})[0]
+
+		result := validate(t, "/tyk/apis/validate", spec.APIDefinition, http.StatusBadRequest)
+		assert.False(t, result.Valid)
+		require.Len(t, result.Errors, 1)
+		assert.Contains(t, result.Errors[0], `version "v1": invalid endpoint path "/ignored/[a-"`)
+	})
+
+	t.Run("missing go plugin", func(t *testing.T) {
+		spec := BuildAPI(func(spec *APISpec) {
+			spec.CustomMiddleware = apidef.MiddlewareSection{
+				Driver: apidef.GoPluginDriver,
+				Pre: []apidef.MiddlewareDefinition{
+					{Name: "MyPreHook", Path: "/nonexistent/plugin.so"},
+				},
+			}
+		})[0]
+
+		result := validate(t, "/tyk/apis/validate", spec.APIDefinition, http.StatusBadRequest)
+		assert.False(t, result.Valid)
+		assert.Equal(t, []string{`go plugin "/nonexistent/plugin.so": plugin file not found`}, result.Errors)
+	})
+
+	t.Run("JSVM disabled", func(t *testing.T) {
+		conf := ts.Gw.GetConfig()
+		conf.EnableJSVM = false
+		ts.Gw.SetConfig(conf)
+		defer func() {
+			conf.EnableJSVM = true
+			ts.Gw.SetConfig(conf)
+		}()
+
+		spec := BuildAPI(func(spec *APISpec) {
+			spec.CustomMiddleware = apidef.MiddlewareSection{
+				Driver: apidef.OttoDriver,
+				Pre: []apidef.MiddlewareDefinition{
+					{Name: "pre", Path: "/nonexistent/pre.js"},
+				},
+			}
+		})[0]
+
+		result := validate(t, "/tyk/apis/validate", spec.APIDefinition, http.StatusOK)
+		assert.True(t, result.Valid)
+		assert.Equal(t, []string{"JSVM is disabled, otto middleware will not run"}, result.Warnings)
+	})
+
+	t.Run("malformed request", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{
+			Method:    http.MethodPost,
+			Path:      "/tyk/apis/validate",
+			Data:      "{",
+			AdminAuth: true,
+			Code:      http.StatusBadRequest,
+			BodyMatch: "Request malformed",
+		})
+	})
+
+	t.Run("OAS API", func(t *testing.T) {
+		spec := BuildOASAPI(func(oasDef *oas.OAS) {})[0]
+
+		result := validate(t, "/tyk/apis/oas/validate", &spec.OAS, http.StatusOK)
+		assert.True(t, result.Valid)
+		assert.Empty(t, result.Errors)
+		assert.Nil(t, ts.Gw.getApiSpec(spec.APIID))
+	})
+
+	t.Run("OAS API without Tyk extension", func(t *testing.T) {
+		spec := BuildOASAPI(func(oasDef *oas.OAS) {
+			delete(oasDef.Extensions, oas.ExtensionTykAPIGateway)
+		})[0]
+
+		result := validate(t, "/tyk/apis/oas/validate", &spec.OAS, http.StatusBadRequest)
+		assert.Equal(t, []string{apidef.ErrPayloadWithoutTykExtension.Error()}, result.Errors)
+	})
+}
@@ -0,0 +1,376 @@
+package gateway
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/request"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+const (
+	ErrAuthTooManyFailures = "auth.too_many_failures"
+
+	MsgAuthTooManyFailures = "Too many failed authentication attempts, try again later"
+)
+
+const (
+	defaultAuthFailureMaxFailures       = 10
+	defaultAuthFailureWindow            = time.Minute
+	defaultAuthFailureLockoutDuration   = 5 * time.Minute
+	defaultAuthFailureTokenPrefixLength = 8
+
+	// authFailureTrackerSize is the number of client IPs and token prefixes kept in memory,
+	// the least recently failing ones are evicted first.
+	authFailureTrackerSize = 10000
+
+	// authFailureKeyPrefix prefixes the Redis keys holding the shared failure counters and lockouts.
+	authFailureKeyPrefix = "auth-failures-"
+
+	authFailureSubjectIP          = "ip"
+	authFailureSubjectTokenPrefix = "token_prefix"
+)
+
+func initAuthFailureLimitErrors() {
+	TykErrors[ErrAuthTooManyFailures] = config.TykError{
+		Message: MsgAuthTooManyFailures,
+		Code:    http.StatusTooManyRequests,
+	}
+}
+
+// authFailureLimit is the auth failure limit of an API with the defaults applied.
+type authFailureLimit struct {
+	maxFailures  int
+	window       time.Duration
+	lockout      time.Duration
+	prefixLength int
+	shared       bool
+}
+
+func newAuthFailureLimit(conf apidef.AuthFailureLimit) authFailureLimit {
+	limit := authFailureLimit{
+		maxFailures:  conf.MaxFailures,
+		window:       time.Duration(conf.Window) * time.Second,
+		lockout:      time.Duration(conf.LockoutDuration) * time.Second,
+		prefixLength: conf.TokenPrefixLength,
+		shared:       conf.Shared,
+	}
+
+	if limit.maxFailures <= 0 {
+		limit.maxFailures = defaultAuthFailureMaxFailures
+	}
+	if limit.window <= 0 {
+		limit.window = defaultAuthFailureWindow
+	}
+	if limit.lockout <= 0 {
+		limit.lockout = defaultAuthFailureLockoutDuration
+	}
+	if limit.prefixLength <= 0 {
+		limit.prefixLength = defaultAuthFailureTokenPrefixLength
+	}
+
+	return limit
+}
+
+// authFailureSubject is a client IP or a token prefix the authentication failures of an API are counted by.
+type authFailureSubject struct {
+	kind  string
+	value string
+}
+
+func (s authFailureSubject) key(apiID string) string {
+	return apiID + "-" + s.kind + "-" + s.value
+}
+
+// authFailureSubjects returns the client IP of the request and the prefix of the key ID of its token, if any.
+func authFailureSubjects(r *http.Request, token, orgID string, prefixLength int) []authFailureSubject {
+	subjects := []authFailureSubject{{kind: authFailureSubjectIP, value: request.RealIP(r)}}
+
+	if keyID := authFailureKeyID(token, orgID); keyID != "" {
+		if len(keyID) > prefixLength {
+			keyID = keyID[:prefixLength]
+		}
+		subjects = append(subjects, authFailureSubject{kind: authFailureSubjectTokenPrefix, value: keyID})
+	}
+
+	return subjects
+}
+
+// authFailureKeyID returns the key ID of the token. Generated tokens start with the org ID, or are base64 JSON
+// starting with it, so the prefixes of the tokens of an org are the same, unlike the prefixes of their key IDs.
+func authFailureKeyID(token, orgID string) string {
+	if keyID, err := storage.TokenID(token); err == nil {
+		return keyID
+	}
+
+	if orgID != "" && strings.HasPrefix(token, orgID) {
+		return token[len(orgID):]
+	}
+
+	if org := storage.TokenOrg(token); org != "" {
+		return token[len(org):]
+	}
+
+	return token
+}
+
+// authFailureEntry holds the authentication failures of a client IP or a token prefix.
+type authFailureEntry struct {
+	failures    int
+	windowEnd   time.Time
+	lockedUntil time.Time
+}
+
+// authFailureTracker locks out the client IPs and token prefixes failing to authenticate to an API too often.
+// The failures are counted in an LRU cache, so the memory used is bounded under a credential stuffing attack.
+// APIs sharing the failures also count them in Redis. The lockouts read from Redis are kept in memory,
+// so the requests of a locked out client stop costing a Redis round trip.
+type authFailureTracker struct {
+	now   func() time.Time
+	store *storage.RedisCluster
+
+	mu      sync.Mutex
+	entries *lru.Cache[string, *authFailureEntry]
+}
+
+func newAuthFailureTracker(store *storage.RedisCluster) *authFailureTracker {
+	// the size is positive, New doesn't fail
+	entries, _ := lru.New[string, *authFailureEntry](authFailureTrackerSize) // nolint:errcheck
+
+	return &authFailureTracker{
+		now:     time.Now,
+		store:   store,
+		entries: entries,
+	}
+}
+
+// getAuthFailureTracker returns the tracker of the authentication failures of the APIs.
+// It's created only once, so the failures are counted across reloads.
+func (gw *Gateway) getAuthFailureTracker() *authFailureTracker {
+	gw.authFailureTrackerOnce.Do(func() {
+		gw.authFailureTracker = newAuthFailureTracker(&storage.RedisCluster{ConnectionHandler: gw.StorageConnectionHandler})
+	})
+
+	return gw.authFailureTracker
+}
+
+func authFailureCountKey(key string) string {
+	return authFailureKeyPrefix + key
+}
+
+func authFailureLockKey(key string) string {
+	return authFailureKeyPrefix + "lock-" + key
+}
+
+// lockedOut returns how long the most restricted of the subjects is still locked out of the API for, 0 if none is.
+func (t *authFailureTracker) lockedOut(apiID string, limit authFailureLimit, subjects []authFailureSubject) time.Duration {
+	now := t.now()
+
+	var remaining time.Duration
+	t.mu.Lock()
+	for _, subject := range subjects {
+		if entry, ok := t.entries.Peek(subject.key(apiID)); ok && entry.lockedUntil.After(now) {
+			remaining = max(remaining, entry.lockedUntil.Sub(now))
+		}
+	}
+	t.mu.Unlock()
+
+	if remaining > 0 || !limit.shared {
+		return remaining
+	}
+
+	lockKeys := make([]string, len(subjects))
+	for i, subject := range subjects {
+		lockKeys[i] = authFailureLockKey(subject.key(apiID))
+	}
+
+	// When Redis fails, the requests are authenticated rather than blocking all traffic.
+	_, ttls, err := t.store.GetMultiKeyAndTTL(nil, lockKeys)
+	if err != nil {
+		return 0
+	}
+
+	for i, ttl := range ttls {
+		if ttl <= 0 {
+			continue
+		}
+
+		t.lock(subjects[i].key(apiID), now.Add(ttl))
+		remaining = max(remaining, ttl)
+	}
+
+	return remaining
+}
+
+// fail records an authentication failure of the subjects. It returns the subjects which got locked out.
+func (t *authFailureTracker) fail(apiID string, limit authFailureLimit, subjects []authFailureSubject) (locked []authFailureSubject) {
+	now := t.now()
+
+	for _, subject := range subjects {
+		key := subject.key(apiID)
+
+		t.mu.Lock()
+		entry, ok := t.entries.Get(key)
+		if !ok {
+			entry = &authFailureEntry{}
+			t.entries.Add(key, entry)
+		}
+
+		if entry.lockedUntil.After(now) {
+			t.mu.Unlock()
+			continue
+		}
+
+		if !now.Before(entry.windowEnd) {
+			entry.failures = 0
+			entry.windowEnd = now.Add(limit.window)
+		}
+
+		entry.failures++
+		failures := entry.failures
+		t.mu.Unlock()
+
+		// the local count is only used when Redis fails
+		if limit.shared {
+			if shared := t.store.IncrememntWithExpire(authFailureCountKey(key), int64(limit.window/time.Second)); shared > 0 {
+				failures = int(shared)
+			}
+		}
+
+		if failures < limit.maxFailures {
+			continue
+		}
+
+		t.lock(key, now.Add(limit.lockout))
+		if limit.shared {
+			if err := t.store.SetRawKey(authFailureLockKey(key), "1", int64(limit.lockout/time.Second)); err != nil {
+				log.WithError(err).Warning("Couldn't share the authentication failures lockout")
+			}
+			t.store.DeleteRawKey(authFailureCountKey(key))
+		}
+
+		locked = append(locked, subject)
+	}
+
+	return locked
+}
+
+// lock locks the subject with the given key out until the given time and forgets its failures.
+func (t *authFailureTracker) lock(key string, until time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.entries.Add(key, &authFailureEntry{lockedUntil: until})
+}
+
+// reset forgets the failures of the subjects after a successful authentication, the lockouts are kept.
+// The shared failures are only reset for the subjects which failed on this gateway, so successful requests
+// don't write to Redis.
+func (t *authFailureTracker) reset(apiID string, limit authFailureLimit, subjects []authFailureSubject) {
+	now := t.now()
+	var countKeys []string
+
+	t.mu.Lock()
+	for _, subject := range subjects {
+		key := subject.key(apiID)
+		if entry, ok := t.entries.Peek(key); ok && !entry.lockedUntil.After(now) {
+			t.entries.Remove(key)
+			countKeys = append(countKeys, authFailureCountKey(key))
+		}
+	}
+	t.mu.Unlock()
+
+	if limit.shared && len(countKeys) > 0 {
+		t.store.DeleteRawKeys(countKeys)
+	}
+}
+
+// rejectAuthFailureLockout responds with 429 to a request when its subject of the given kind is locked out of
+// the API. It returns a nil error when the request isn't locked out.
+// The client IP is checked before the token is looked up, so a locked out client doesn't reach the key store.
+// The token prefix is only checked for the tokens which weren't found, so a flood of invalid tokens doesn't
+// lock the valid ones out.
+func (t *BaseMiddleware) rejectAuthFailureLockout(w http.ResponseWriter, r *http.Request, token, kind string) (error, int) {
+	conf := t.Spec.AuthFailureLimit
+	if !conf.Enabled {
+		return nil, http.StatusOK
+	}
+
+	limit := newAuthFailureLimit(conf)
+	var subjects []authFailureSubject
+	for _, subject := range authFailureSubjects(r, token, t.Spec.OrgID, limit.prefixLength) {
+		if subject.kind == kind {
+			subjects = append(subjects, subject)
+		}
+	}
+	if len(subjects) == 0 {
+		return nil, http.StatusOK
+	}
+
+	remaining := t.Gw.getAuthFailureTracker().lockedOut(t.Spec.APIID, limit, subjects)
+	if remaining <= 0 {
+		return nil, http.StatusOK
+	}
+
+	retryAfter := int((remaining + time.Second - 1) / time.Second)
+	w.Header().Set(header.RetryAfter, strconv.Itoa(retryAfter))
+
+	return errorAndStatusCode(ErrAuthTooManyFailures)
+}
+
+// authFailed records an authentication failure of the request. Once its client IP or token prefix is locked out,
+// a security log entry is written and the AuthFailureLockout event is fired.
+func (t *BaseMiddleware) authFailed(r *http.Request, token string) {
+	conf := t.Spec.AuthFailureLimit
+	if !conf.Enabled {
+		return
+	}
+
+	limit := newAuthFailureLimit(conf)
+	subjects := authFailureSubjects(r, token, t.Spec.OrgID, limit.prefixLength)
+	lockout := int(limit.lockout / time.Second)
+
+	for _, subject := range t.Gw.getAuthFailureTracker().fail(t.Spec.APIID, limit, subjects) {
+		meta := EventAuthFailureLockoutMeta{
+			EventMetaDefault: EventMetaDefault{Message: "Locked out after too many authentication failures"},
+			APIID:            t.Spec.APIID,
+			Path:             r.URL.Path,
+			Origin:           subjects[0].value,
+			Failures:         limit.maxFailures,
+			LockoutDuration:  lockout,
+		}
+		if subject.kind == authFailureSubjectTokenPrefix {
+			meta.TokenPrefix = subject.value
+		}
+
+		t.Logger().WithFields(logrus.Fields{
+			"security":         "auth_failure_lockout",
+			"origin":           meta.Origin,
+			"locked_out":       subject.kind,
+			"failures":         limit.maxFailures,
+			"lockout_duration": lockout,
+		}).Warning(meta.Message)
+
+		t.FireEvent(EventAuthFailureLockout, meta)
+	}
+}
+
+// authSucceeded resets the authentication failures of the client IP and the token prefix of the request.
+func (t *BaseMiddleware) authSucceeded(r *http.Request, token string) {
+	conf := t.Spec.AuthFailureLimit
+	if !conf.Enabled {
+		return
+	}
+
+	limit := newAuthFailureLimit(conf)
+	t.Gw.getAuthFailureTracker().reset(t.Spec.APIID, limit, authFailureSubjects(r, token, t.Spec.OrgID, limit.prefixLength))
+}
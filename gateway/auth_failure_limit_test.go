@@ -0,0 +1,228 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/storage"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestAuthFailureLimit(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	spec := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "auth-failure-limit"
+		spec.UseKeylessAccess = false
+		spec.Proxy.ListenPath = "/"
+		spec.AuthFailureLimit = apidef.AuthFailureLimit{
+			Enabled:           true,
+			MaxFailures:       3,
+			Window:            60,
+			LockoutDuration:   120,
+			TokenPrefixLength: 6,
+		}
+	})[0]
+
+	lockouts := make(chan EventAuthFailureLockoutMeta, 10)
+	var authFailures atomic.Int64
+	spec.EventPaths = map[apidef.TykEvent][]config.TykEventHandler{
+		EventAuthFailureLockout: {&testEventHandler{func(em config.EventMessage) {
+			meta, ok := em.Meta.(EventAuthFailureLockoutMeta)
+			assert.True(t, ok)
+			lockouts <- meta
+		}}},
+		EventAuthFailure: {&testEventHandler{func(config.EventMessage) {
+			authFailures.Add(1)
+		}}},
+	}
+
+	// The clock is moved forward to check the recovery from the lockout.
+	tracker := ts.Gw.getAuthFailureTracker()
+	var offset atomic.Int64
+	tracker.now = func() time.Time {
+		return time.Now().Add(time.Duration(offset.Load()))
+	}
+
+	key := CreateSession(ts.Gw)
+
+	var attempt int
+	request := func(ip, token string, code int) test.TestCase {
+		return test.TestCase{
+			Path:    "/",
+			Headers: map[string]string{header.Authorization: token, header.XRealIP: ip},
+			Code:    code,
+		}
+	}
+	// invalid returns a failing request with a new token prefix on every call, so only the client IP is locked out.
+	invalid := func(ip string) test.TestCase {
+		attempt++
+		return request(ip, fmt.Sprintf("%06d-invalid", attempt), http.StatusForbidden)
+	}
+	lockedOut := func(ip, token string) test.TestCase {
+		tc := request(ip, token, http.StatusTooManyRequests)
+		tc.BodyMatch = MsgAuthTooManyFailures
+		tc.HeadersMatch = map[string]string{header.RetryAfter: "120"}
+		return tc
+	}
+
+	receiveLockout := func(t *testing.T) EventAuthFailureLockoutMeta {
+		t.Helper()
+		select {
+		case meta := <-lockouts:
+			return meta
+		case <-time.After(time.Second):
+			t.Fatal("expected a lockout event")
+			return EventAuthFailureLockoutMeta{}
+		}
+	}
+
+	t.Run("success resets the failures", func(t *testing.T) {
+		_, _ = ts.Run(t, invalid("10.0.0.1"), invalid("10.0.0.1"), request("10.0.0.1", key, http.StatusOK),
+			invalid("10.0.0.1"), invalid("10.0.0.1"), request("10.0.0.1", key, http.StatusOK))
+		assert.Empty(t, lockouts)
+	})
+
+	t.Run("failure burst locks out the client IP", func(t *testing.T) {
+		_, _ = ts.Run(t, invalid("10.0.0.2"), invalid("10.0.0.2"), invalid("10.0.0.2"))
+
+		meta := receiveLockout(t)
+		assert.Equal(t, "10.0.0.2", meta.Origin)
+		assert.Empty(t, meta.TokenPrefix)
+		assert.Equal(t, "auth-failure-limit", meta.APIID)
+		assert.Equal(t, 120, meta.LockoutDuration)
+
+		failures := authFailures.Load()
+
+		// The client is locked out before its token is looked up, the valid tokens included.
+		sessionManager := &sessionDetailCounter{SessionHandler: ts.Gw.GlobalSessionManager}
+		ts.Gw.GlobalSessionManager = sessionManager
+		_, _ = ts.Run(t, lockedOut("10.0.0.2", key), lockedOut("10.0.0.2", "unknown-token"))
+		ts.Gw.GlobalSessionManager = sessionManager.SessionHandler
+		assert.Zero(t, sessionManager.calls.Load(), "the key store isn't read for locked out clients")
+		assert.Equal(t, failures, authFailures.Load(), "the failures of locked out requests aren't reported")
+
+		// Other clients aren't affected.
+		_, _ = ts.Run(t, request("10.0.0.3", key, http.StatusOK))
+	})
+
+	t.Run("failure burst locks out the token prefix", func(t *testing.T) {
+		_, _ = ts.Run(t,
+			request("10.0.1.1", "stuff-1", http.StatusForbidden),
+			request("10.0.1.2", "stuff-2", http.StatusForbidden),
+			request("10.0.1.3", "stuff-3", http.StatusForbidden),
+		)
+
+		meta := receiveLockout(t)
+		assert.Equal(t, "10.0.1.3", meta.Origin)
+		assert.Equal(t, "stuff-", meta.TokenPrefix)
+
+		_, _ = ts.Run(t, lockedOut("10.0.1.4", "stuff-4"), request("10.0.1.4", key, http.StatusOK))
+	})
+
+	t.Run("generated tokens are counted by their key ID", func(t *testing.T) {
+		var cases []test.TestCase
+		for i := 0; i < 3; i++ {
+			b64Token, err := storage.GenerateToken(MockOrgID, fmt.Sprintf("%d-b64", i), storage.HashMurmur64)
+			require.NoError(t, err)
+
+			cases = append(cases,
+				request(fmt.Sprintf("10.0.2.%d", i), MockOrgID+fmt.Sprintf("%d-legacy", i), http.StatusForbidden),
+				request(fmt.Sprintf("10.0.3.%d", i), b64Token, http.StatusForbidden),
+			)
+		}
+		_, _ = ts.Run(t, cases...)
+
+		assert.Empty(t, lockouts, "the tokens of an org don't share their prefix")
+	})
+
+	t.Run("recovery after the lockout", func(t *testing.T) {
+		offset.Store(int64(121 * time.Second))
+
+		_, _ = ts.Run(t, request("10.0.0.2", key, http.StatusOK), request("10.0.1.4", "stuff-4", http.StatusForbidden))
+
+		// A new window starts after the lockout.
+		_, _ = ts.Run(t, invalid("10.0.0.2"), invalid("10.0.0.2"), request("10.0.0.2", key, http.StatusOK))
+	})
+}
+
+// sessionDetailCounter counts the session lookups of the key store.
+type sessionDetailCounter struct {
+	SessionHandler
+	calls atomic.Int64
+}
+
+func (s *sessionDetailCounter) SessionDetail(orgID string, keyName string, hashed bool) (user.SessionState, bool) {
+	s.calls.Add(1)
+	return s.SessionHandler.SessionDetail(orgID, keyName, hashed)
+}
+
+func TestAuthFailureKeyID(t *testing.T) {
+	b64Token, err := storage.GenerateToken(MockOrgID, "key-id", storage.HashMurmur64)
+	require.NoError(t, err)
+
+	assert.Equal(t, "key-id", authFailureKeyID(b64Token, MockOrgID))
+	assert.Equal(t, "key-id", authFailureKeyID(MockOrgID+"key-id", MockOrgID))
+	assert.Equal(t, "key-id", authFailureKeyID(MockOrgID+"key-id", "other-org"), "the org ID is recognized")
+	assert.Equal(t, "key-id", authFailureKeyID("default"+"key-id", "default"))
+	assert.Equal(t, "custom-key", authFailureKeyID("custom-key", MockOrgID))
+	assert.Empty(t, authFailureKeyID("", MockOrgID))
+}
+
+func TestAuthFailureLimit_Disabled(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.UseKeylessAccess = false
+		spec.Proxy.ListenPath = "/"
+	})
+
+	for i := 0; i < 2*defaultAuthFailureMaxFailures; i++ {
+		_, _ = ts.Run(t, test.TestCase{Path: "/", Headers: map[string]string{header.Authorization: "invalid"}, Code: http.StatusForbidden})
+	}
+
+	assert.Nil(t, ts.Gw.authFailureTracker, "the tracker isn't created for APIs without a limit")
+}
+
+func TestAuthFailureTracker_Shared(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	// Both trackers share the failures in Redis, as two gateways would.
+	first := newAuthFailureTracker(ts.Gw.getAuthFailureTracker().store)
+	second := newAuthFailureTracker(ts.Gw.getAuthFailureTracker().store)
+
+	apiID := "shared-" + ts.Gw.GetNodeID()
+	limit := newAuthFailureLimit(apidef.AuthFailureLimit{Enabled: true, MaxFailures: 3, Shared: true})
+	ip := []authFailureSubject{{kind: authFailureSubjectIP, value: "10.0.0.1"}}
+	t.Cleanup(func() {
+		first.store.DeleteRawKeys([]string{
+			authFailureCountKey(ip[0].key(apiID)),
+			authFailureLockKey(ip[0].key(apiID)),
+		})
+	})
+
+	assert.Empty(t, first.fail(apiID, limit, ip))
+	assert.Empty(t, second.fail(apiID, limit, ip))
+
+	// A success on the first gateway resets the shared failures.
+	first.reset(apiID, limit, ip)
+	assert.Empty(t, second.fail(apiID, limit, ip))
+	assert.Empty(t, first.fail(apiID, limit, ip))
+	assert.Zero(t, second.lockedOut(apiID, limit, ip))
+
+	require.Equal(t, ip, second.fail(apiID, limit, ip))
+	assert.Greater(t, first.lockedOut(apiID, limit, ip), 4*time.Minute, "the lockout is shared")
+}
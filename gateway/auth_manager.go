@@ -3,11 +3,13 @@ package gateway
 import (
 	"encoding/base64"
 	"encoding/json"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/internal/uuid"
 	"github.com/TykTechnologies/tyk/storage"
 	"github.com/TykTechnologies/tyk/user"
@@ -64,28 +66,40 @@ func (b *DefaultSessionManager) ResetQuota(keyName string, session *user.Session
 		keyName = storage.HashKey(keyName, b.Gw.GetConfig().HashKeys)
 	}
 
-	rawKey := QuotaKeyPrefix + keyName
+	conf := b.Gw.GetConfig()
+	rawKey := quotaKeyName(&conf, "", keyName)
 	log.WithFields(logrus.Fields{
 		"prefix":      "auth-mgr",
 		"inbound-key": b.Gw.obfuscateKey(origKeyName),
 		"key":         b.ResetQuotaObfuscateKey(keyName),
 	}).Info("Reset quota for key.")
 
-	rateLimiterSentinelKey := RateLimitKeyPrefix + keyName + ".BLOCKED"
+	rateLimiterSentinelKey := rateLimitSentinelKeyName(&conf, keyName)
 
 	// Clear the rate limiter and
 	// Fix the raw key
 	defaultKeys := []string{rateLimiterSentinelKey, rawKey}
-	keys := rawKeysWithAllowanceScope(defaultKeys, keyName, session)
+	keys := rawKeysWithAllowanceScope(defaultKeys, keyName, session, func(scope, keyName string) string {
+		return quotaKeyName(&conf, scope, keyName)
+	})
 	b.store.DeleteRawKeys(keys)
+
+	if clusterLimiterKeys(&conf) {
+		// The counters not migrated yet would be migrated back after the reset. Their keys
+		// are in different hash slots, so they're removed one by one.
+		legacyKeys := rawKeysWithAllowanceScope([]string{legacyQuotaKeyName("", keyName)}, keyName, session, legacyQuotaKeyName)
+		for _, key := range legacyKeys {
+			b.store.DeleteRawKey(key)
+		}
+	}
 }
 
-func rawKeysWithAllowanceScope(keys []string, keyName string, session *user.SessionState) []string {
+func rawKeysWithAllowanceScope(keys []string, keyName string, session *user.SessionState, quotaKey func(scope, keyName string) string) []string {
 	for _, acl := range session.AccessRights {
 		if acl.AllowanceScope == "" {
 			continue
 		}
-		keys = append(keys, QuotaKeyPrefix+acl.AllowanceScope+"-"+keyName)
+		keys = append(keys, quotaKey(acl.AllowanceScope, keyName))
 	}
 	return keys
 }
@@ -211,6 +225,45 @@ func (b *DefaultSessionManager) SessionDetail(orgID string, keyName string, hash
 		}
 	}
 
+	return b.decodeSessionDetail(keyName, keyId, jsonKeyVal, err)
+}
+
+// sessionDetailForRequest returns the session like SessionDetail does for an unhashed key. If the store can batch
+// reads, the TTL of the session's quota key is fetched in the same round trip and kept in the request context,
+// saving the quota check a call to Redis. Keys needing a lookup of their legacy formats fall back to SessionDetail.
+func (b *DefaultSessionManager) sessionDetailForRequest(r *http.Request, orgID string, keyName string) (user.SessionState, bool) {
+	store, ok := b.store.(storage.GetMultiKeyAndTTLHandler)
+	if !ok || storage.TokenOrg(keyName) != orgID {
+		return b.SessionDetail(orgID, keyName, false)
+	}
+
+	// the quota TTL is read from the primary, the session is read from the replica
+	if replicaStore, ok := b.store.(storage.ReadReplicaHandler); ok && replicaStore.ReadsFromReplica() {
+		return b.SessionDetail(orgID, keyName, false)
+	}
+
+	// the quota key used by the limiter when the session has no allowance scope, see RedisQuotaExceeded
+	conf := b.Gw.GetConfig()
+	quotaKey := quotaKeyName(&conf, "", storage.HashKey(keyName, conf.HashKeys))
+
+	var jsonKeyVal string
+	values, ttls, err := store.GetMultiKeyAndTTL([]string{keyName}, []string{quotaKey})
+	if err == nil {
+		jsonKeyVal = values[0]
+		if jsonKeyVal == "" {
+			err = storage.ErrKeyNotFound
+		}
+	}
+
+	session, found := b.decodeSessionDetail(keyName, keyName, jsonKeyVal, err)
+	if found {
+		ctxSetPrefetchedQuotaTTL(r, quotaKey, ttls[0])
+	}
+
+	return session, found
+}
+
+func (b *DefaultSessionManager) decodeSessionDetail(keyName, keyId, jsonKeyVal string, err error) (user.SessionState, bool) {
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"prefix":      "auth-mgr",
@@ -236,6 +289,30 @@ func (b *DefaultSessionManager) Sessions(filter string) []string {
 	return b.store.GetKeys(filter)
 }
 
+// newKeyStore returns the Redis store of the keys, hashing them as set by the
+// hash_keys, hash_key_function and hash_key_migration options.
+func (gw *Gateway) newKeyStore() *storage.RedisCluster {
+	conf := gw.GetConfig()
+
+	return &storage.RedisCluster{
+		KeyPrefix:         "apikey-",
+		HashKeys:          conf.HashKeys,
+		HashKeyFunction:   conf.KeyHashAlgorithm(),
+		HashKeyFallback:   conf.HashKeyMigration == config.HashKeyMigrationDualRead,
+		ConnectionHandler: gw.StorageConnectionHandler,
+	}
+}
+
+// keyHash returns the hash the key is stored under, or the key when keys aren't hashed.
+func (gw *Gateway) keyHash(key string) string {
+	conf := gw.GetConfig()
+	if !conf.HashKeys {
+		return key
+	}
+
+	return storage.HashStr(key, conf.KeyHashAlgorithm())
+}
+
 type DefaultKeyGenerator struct {
 	Gw *Gateway `json:"-"`
 }
@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"context"
 	"crypto/x509"
 	"encoding/json"
 	"errors"
@@ -8,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -17,6 +19,7 @@ import (
 	"github.com/TykTechnologies/tyk/config"
 
 	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/redis"
 
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/storage"
@@ -648,3 +651,149 @@ func (c *countingStorageHandler) SetKeyEx(string, string, int64) error {
 func (c *countingStorageHandler) SetRawKeyEx(string, string, int64) error {
 	return errors.New("not implemented")
 }
+
+// pttlCounter counts the PTTL commands sent to Redis.
+type pttlCounter struct {
+	count atomic.Int64
+}
+
+func (c *pttlCounter) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (c *pttlCounter) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() == "pttl" {
+			c.count.Add(1)
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (c *pttlCounter) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestSessionDetailForRequest_PrefetchesQuotaTTL(t *testing.T) {
+	for _, hashKeys := range []bool{false, true} {
+		t.Run(fmt.Sprintf("hash keys %v", hashKeys), func(t *testing.T) {
+			ts := StartTest(func(globalConf *config.Config) {
+				globalConf.HashKeys = hashKeys
+				globalConf.LocalSessionCache.DisableCacheSessionState = true
+			})
+			defer ts.Close()
+
+			pttls := &pttlCounter{}
+			ts.Gw.SessionLimiter.limiterStorage.AddHook(pttls)
+
+			const apiID = "prefetch-quota"
+			ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+				spec.APIID = apiID
+				spec.UseKeylessAccess = false
+				spec.Proxy.ListenPath = "/"
+			})
+
+			key := CreateSession(ts.Gw, func(s *user.SessionState) {
+				s.QuotaMax = 2
+				s.QuotaRenewalRate = 60
+				s.AccessRights = map[string]user.AccessDefinition{apiID: {APIID: apiID, Versions: []string{"v1"}}}
+			})
+			authHeaders := map[string]string{header.Authorization: key}
+
+			_, _ = ts.Run(t, []test.TestCase{
+				{Headers: authHeaders, Code: http.StatusOK, HeadersMatch: map[string]string{header.XRateLimitRemaining: "1"}},
+				{Headers: authHeaders, Code: http.StatusOK, HeadersMatch: map[string]string{header.XRateLimitRemaining: "0"}},
+				{Headers: authHeaders, Code: http.StatusForbidden},
+			}...)
+
+			assert.Zero(t, pttls.count.Load(), "the quota TTL should be read together with the session")
+
+			_, _ = ts.Run(t, test.TestCase{Headers: map[string]string{header.Authorization: "unknown-key"}, Code: http.StatusForbidden})
+		})
+	}
+}
+
+func TestHashKeyMigration(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.HashKeys = true
+		globalConf.HashKeyFunction = storage.HashMurmur64
+		globalConf.LocalSessionCache.DisableCacheSessionState = true
+	})
+	defer ts.Close()
+
+	api := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "test"
+		spec.Proxy.ListenPath = "/"
+		spec.UseKeylessAccess = false
+	})[0]
+
+	session := CreateStandardSession()
+	session.AccessRights = map[string]user.AccessDefinition{"test": {
+		APIID: "test", Versions: []string{"v1"},
+	}}
+
+	createKey := func(t *testing.T) apiModifyKeySuccess {
+		t.Helper()
+
+		resp, _ := ts.Run(t, test.TestCase{AdminAuth: true, Method: http.MethodPost, Path: "/tyk/keys/create",
+			Data: session, Code: http.StatusOK})
+
+		var created apiModifyKeySuccess
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		return created
+	}
+
+	// keys issued before the migration: a murmur64 token and a legacy one hashed with murmur32
+	issued := createKey(t)
+	assert.Equal(t, storage.HashStr(issued.Key, storage.HashMurmur64), issued.KeyHash)
+
+	globalConf := ts.Gw.GetConfig()
+	globalConf.HashKeyFunction = ""
+	ts.Gw.SetConfig(globalConf)
+	legacy := createKey(t)
+	assert.Equal(t, storage.HashStr(legacy.Key, storage.HashMurmur32), legacy.KeyHash)
+
+	globalConf.HashKeyFunction = storage.HashSha256
+	globalConf.HashKeyMigration = config.HashKeyMigrationDualRead
+	ts.Gw.SetConfig(globalConf)
+	ts.Gw.LoadAPI(api)
+
+	for name, key := range map[string]apiModifyKeySuccess{"issued key": issued, "legacy key": legacy} {
+		t.Run(name, func(t *testing.T) {
+			authHeader := map[string]string{header.Authorization: key.Key}
+			newHash := storage.HashStr(key.Key, storage.HashSha256)
+
+			_, _ = ts.Run(t, []test.TestCase{
+				{Headers: authHeader, Code: http.StatusOK},
+				{AdminAuth: true, Method: http.MethodGet, Path: "/tyk/keys/" + key.Key, Code: http.StatusOK},
+				{AdminAuth: true, Method: http.MethodGet, Path: "/tyk/keys/" + key.KeyHash + "?hashed=true", Code: http.StatusOK},
+				{AdminAuth: true, Method: http.MethodPut, Path: "/tyk/keys/" + key.Key, Data: session, Code: http.StatusOK},
+				{AdminAuth: true, Method: http.MethodGet, Path: "/tyk/keys/" + newHash + "?hashed=true", Code: http.StatusOK},
+				{AdminAuth: true, Method: http.MethodGet, Path: "/tyk/keys/" + key.KeyHash + "?hashed=true", Code: http.StatusNotFound},
+				{Headers: authHeader, Code: http.StatusOK},
+				{AdminAuth: true, Method: http.MethodDelete, Path: "/tyk/keys/" + key.Key, Code: http.StatusOK},
+				{Headers: authHeader, Code: http.StatusForbidden},
+			}...)
+		})
+	}
+
+	t.Run("new key", func(t *testing.T) {
+		key := createKey(t)
+		assert.Equal(t, storage.HashStr(key.Key, storage.HashSha256), key.KeyHash)
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Headers: map[string]string{header.Authorization: key.Key}, Code: http.StatusOK},
+			{AdminAuth: true, Method: http.MethodGet, Path: "/tyk/keys/" + key.KeyHash + "?hashed=true", Code: http.StatusOK},
+		}...)
+	})
+
+	t.Run("migration complete", func(t *testing.T) {
+		key := createKey(t)
+
+		globalConf.HashKeyMigration = config.HashKeyMigrationComplete
+		ts.Gw.SetConfig(globalConf)
+		ts.Gw.LoadAPI(api)
+
+		_, _ = ts.Run(t, test.TestCase{Headers: map[string]string{header.Authorization: key.Key}, Code: http.StatusOK})
+	})
+}
@@ -8,8 +8,14 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+// defaultBatchRequestMaxParallelism is the number of batch items executed concurrently
+// when neither the API nor the gateway configuration set a limit.
+const defaultBatchRequestMaxParallelism = 10
+
 // RequestDefinition defines a batch request
 type RequestDefinition struct {
 	Method      string            `json:"method"`
@@ -61,18 +67,21 @@ func (b *BatchRequestHandler) doRequest(req *http.Request, relURL string) BatchR
 	tr.Proxy = proxyFromAPI(b.API)
 
 	client := &http.Client{Transport: tr}
+	if timeout := b.Gw.GetConfig().ProxyDefaultTimeout; timeout > 0 {
+		client.Timeout = time.Duration(timeout * float64(time.Second))
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
 		log.Error("Webhook request failed: ", err)
-		return BatchReplyUnit{}
+		return BatchReplyUnit{RelativeURL: relURL}
 	}
 
 	defer resp.Body.Close()
 	content, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		log.Warning("Body read failure! ", err)
-		return BatchReplyUnit{}
+		return BatchReplyUnit{RelativeURL: relURL}
 	}
 
 	return BatchReplyUnit{
@@ -120,31 +129,50 @@ func (b *BatchRequestHandler) ConstructRequests(batchRequest BatchRequestStructu
 	return requestSet, nil
 }
 
-func (b *BatchRequestHandler) MakeRequests(batchRequest BatchRequestStructure, requestSet []*http.Request) []BatchReplyUnit {
-	replySet := []BatchReplyUnit{}
+// maxParallelism returns how many batch items may be executed concurrently.
+func (b *BatchRequestHandler) maxParallelism() int {
+	if b.API != nil && b.API.BatchRequestMaxParallelism > 0 {
+		return b.API.BatchRequestMaxParallelism
+	}
+
+	if limit := b.Gw.GetConfig().BatchRequestMaxParallelism; limit > 0 {
+		return limit
+	}
+
+	return defaultBatchRequestMaxParallelism
+}
 
+// MakeRequests executes the batch and returns the replies in the order of the requests.
+// Unless parallel execution is suppressed, up to maxParallelism requests run concurrently.
+func (b *BatchRequestHandler) MakeRequests(batchRequest BatchRequestStructure, requestSet []*http.Request) []BatchReplyUnit {
 	if len(batchRequest.Requests) != len(requestSet) {
 		log.Error("Something went wrong creating requests, they are of mismatched lengths!", len(batchRequest.Requests), len(requestSet))
+		return []BatchReplyUnit{}
 	}
 
-	if !batchRequest.SuppressParallelExecution {
-		replies := make(chan BatchReplyUnit)
-		for i, req := range requestSet {
-			go func(i int, req *http.Request) {
-				reply := b.doRequest(req, batchRequest.Requests[i].RelativeURL)
-				replies <- reply
-			}(i, req)
-		}
+	replySet := make([]BatchReplyUnit, len(requestSet))
 
-		for range batchRequest.Requests {
-			replySet = append(replySet, <-replies)
-		}
-	} else {
+	if batchRequest.SuppressParallelExecution {
 		for i, req := range requestSet {
-			reply := b.doRequest(req, batchRequest.Requests[i].RelativeURL)
-			replySet = append(replySet, reply)
+			replySet[i] = b.doRequest(req, batchRequest.Requests[i].RelativeURL)
 		}
+		return replySet
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, b.maxParallelism())
+	for i, req := range requestSet {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, req *http.Request) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			replySet[i] = b.doRequest(req, batchRequest.Requests[i].RelativeURL)
+		}(i, req)
 	}
+	wg.Wait()
 
 	return replySet
 }
@@ -5,6 +5,7 @@ import (
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -12,6 +13,10 @@ import (
 	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/TykTechnologies/tyk/certs"
 
@@ -246,3 +251,96 @@ func TestBatchIgnoreCanonicalHeaderKey(t *testing.T) {
 		t.Errorf("expected %q got %q", NonCanonicalHeaderKey, got)
 	}
 }
+
+func TestBatchParallelExecution(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	const (
+		items = 6
+		delay = 200 * time.Millisecond
+	)
+
+	var inFlight, maxInFlight int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+				break
+			}
+		}
+
+		time.Sleep(delay)
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer upstream.Close()
+
+	loadAPI := func(maxParallelism int) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/batch/"
+			spec.Proxy.StripListenPath = true
+			spec.Proxy.TargetURL = upstream.URL
+			spec.EnableBatchRequestSupport = true
+			spec.BatchRequestMaxParallelism = maxParallelism
+		})
+	}
+
+	batch := func(suppressParallel bool) BatchRequestStructure {
+		batchRequest := BatchRequestStructure{SuppressParallelExecution: suppressParallel}
+		for i := 0; i < items; i++ {
+			batchRequest.Requests = append(batchRequest.Requests, RequestDefinition{
+				Method:      http.MethodGet,
+				RelativeURL: fmt.Sprintf("item/%d", i),
+			})
+		}
+		return batchRequest
+	}
+
+	run := func(t *testing.T, batchRequest BatchRequestStructure) ([]BatchReplyUnit, time.Duration) {
+		t.Helper()
+		atomic.StoreInt32(&maxInFlight, 0)
+
+		data, err := json.Marshal(batchRequest)
+		require.NoError(t, err)
+
+		start := time.Now()
+		resp, err := ts.Run(t, test.TestCase{Method: http.MethodPost, Path: "/batch/tyk/batch/", Data: data, Code: http.StatusOK})
+		elapsed := time.Since(start)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var replies []BatchReplyUnit
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&replies))
+		require.Len(t, replies, items)
+		for i, reply := range replies {
+			assert.Equal(t, fmt.Sprintf("item/%d", i), reply.RelativeURL)
+			assert.Equal(t, http.StatusOK, reply.Code)
+			assert.Equal(t, fmt.Sprintf("/item/%d", i), reply.Body)
+		}
+
+		return replies, elapsed
+	}
+
+	t.Run("parallel", func(t *testing.T) {
+		loadAPI(0)
+		_, elapsed := run(t, batch(false))
+		assert.Less(t, elapsed, items*delay/2)
+		assert.Equal(t, int32(items), atomic.LoadInt32(&maxInFlight))
+	})
+
+	t.Run("parallelism limit", func(t *testing.T) {
+		loadAPI(2)
+		_, elapsed := run(t, batch(false))
+		assert.GreaterOrEqual(t, elapsed, items/2*delay)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&maxInFlight))
+	})
+
+	t.Run("suppressed", func(t *testing.T) {
+		loadAPI(0)
+		_, elapsed := run(t, batch(true))
+		assert.GreaterOrEqual(t, elapsed, items*delay)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&maxInFlight))
+	})
+}
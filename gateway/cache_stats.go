@@ -0,0 +1,86 @@
+package gateway
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gocraft/health"
+
+	"github.com/TykTechnologies/tyk/internal/cache"
+)
+
+// cacheStatsReporter is implemented by the caches counting their accesses, see cache.MemRepository.
+type cacheStatsReporter interface {
+	EnableStats()
+	Stats() (cache.StatsSnapshot, bool)
+}
+
+// cacheStats is the snapshot of the counters of an internal cache.
+type cacheStats struct {
+	Name         string `json:"name"`
+	StatsEnabled bool   `json:"stats_enabled"`
+	cache.StatsSnapshot
+}
+
+// namedCaches returns the internal caches of the gateway by name.
+func (gw *Gateway) namedCaches() map[string]cache.Repository {
+	return map[string]cache.Repository{
+		"session":    gw.SessionCache,
+		"expiry":     gw.ExpiryCache,
+		"util":       gw.UtilCache,
+		"service":    gw.ServiceCache,
+		"rpc_global": gw.RPCGlobalCache,
+		"rpc_cert":   gw.RPCCertCache,
+	}
+}
+
+// enableCacheStats starts counting the accesses of the internal caches. The counters
+// are only enabled with instrumentation, the caches don't count anything otherwise.
+func (gw *Gateway) enableCacheStats() {
+	for _, c := range gw.namedCaches() {
+		if reporter, ok := c.(cacheStatsReporter); ok {
+			reporter.EnableStats()
+		}
+	}
+}
+
+// cacheStats returns the snapshots of the internal caches, sorted by name.
+func (gw *Gateway) cacheStats() []cacheStats {
+	var snapshots []cacheStats
+	for name, c := range gw.namedCaches() {
+		reporter, ok := c.(cacheStatsReporter)
+		if !ok {
+			continue
+		}
+
+		snapshot, enabled := reporter.Stats()
+		snapshots = append(snapshots, cacheStats{Name: name, StatsEnabled: enabled, StatsSnapshot: snapshot})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Name < snapshots[j].Name
+	})
+
+	return snapshots
+}
+
+// publishCacheStats sends the counters of the internal caches to the instrumentation sink, labelled by cache name.
+func (gw *Gateway) publishCacheStats(job *health.Job) {
+	for _, stats := range gw.cacheStats() {
+		if !stats.StatsEnabled {
+			continue
+		}
+
+		metadata := health.Kvs{"host": gw.hostDetails.Hostname, "cache": stats.Name}
+		job.GaugeKv(stats.Name+".hits", float64(stats.Hits), metadata)
+		job.GaugeKv(stats.Name+".misses", float64(stats.Misses), metadata)
+		job.GaugeKv(stats.Name+".evictions", float64(stats.Evictions), metadata)
+		job.GaugeKv(stats.Name+".size", float64(stats.Size), metadata)
+		job.GaugeKv(stats.Name+".hit_ratio", stats.HitRatio, metadata)
+	}
+}
+
+// debugCachesHandler returns a snapshot of the counters of the internal caches.
+func (gw *Gateway) debugCachesHandler(w http.ResponseWriter, _ *http.Request) {
+	doJSONWrite(w, http.StatusOK, gw.cacheStats())
+}
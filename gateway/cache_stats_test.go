@@ -0,0 +1,95 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gocraft/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/test"
+)
+
+// gaugeSink records the gauges emitted to the instrumentation stream.
+type gaugeSink struct {
+	gauges map[string]float64
+	cache  map[string]string
+}
+
+func (s *gaugeSink) EmitGauge(job string, event string, value float64, kvs map[string]string) {
+	s.gauges[job+"."+event] = value
+	s.cache[job+"."+event] = kvs["cache"]
+}
+
+func (s *gaugeSink) EmitEvent(string, string, map[string]string)                            {}
+func (s *gaugeSink) EmitEventErr(string, string, error, map[string]string)                  {}
+func (s *gaugeSink) EmitTiming(string, string, int64, map[string]string)                    {}
+func (s *gaugeSink) EmitComplete(string, health.CompletionStatus, int64, map[string]string) {}
+
+func TestCacheStats(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	utilStats := func(t *testing.T) cacheStats {
+		t.Helper()
+
+		resp, _ := ts.Run(t, test.TestCase{AdminAuth: true, Method: http.MethodGet, Path: "/tyk/debug/caches", Code: http.StatusOK})
+
+		var snapshots []cacheStats
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&snapshots))
+		require.Len(t, snapshots, len(ts.Gw.namedCaches()))
+
+		for _, snapshot := range snapshots {
+			if snapshot.Name == "util" {
+				return snapshot
+			}
+		}
+
+		t.Fatal("the util cache isn't reported")
+		return cacheStats{}
+	}
+
+	ts.Gw.UtilCache.Set("key", "value", 0)
+
+	t.Run("disabled", func(t *testing.T) {
+		ts.Gw.UtilCache.Get("key")
+
+		stats := utilStats(t)
+		assert.False(t, stats.StatsEnabled)
+		assert.Zero(t, stats.Hits, "accesses aren't counted without instrumentation")
+		assert.Equal(t, 1, stats.Size)
+	})
+
+	ts.Gw.enableCacheStats()
+
+	// 3 hits and a miss
+	for _, key := range []string{"key", "key", "missing", "key"} {
+		ts.Gw.UtilCache.Get(key)
+	}
+
+	t.Run("debug endpoint", func(t *testing.T) {
+		stats := utilStats(t)
+		assert.True(t, stats.StatsEnabled)
+		assert.Equal(t, int64(3), stats.Hits)
+		assert.Equal(t, int64(1), stats.Misses)
+		assert.Equal(t, 1, stats.Size)
+		assert.Equal(t, 0.75, stats.HitRatio)
+	})
+
+	t.Run("instrumentation", func(t *testing.T) {
+		sink := &gaugeSink{gauges: map[string]float64{}, cache: map[string]string{}}
+		stream := health.NewStream()
+		stream.AddSink(sink)
+
+		ts.Gw.publishCacheStats(stream.NewJob("Cache"))
+
+		assert.Equal(t, 3.0, sink.gauges["Cache.util.hits"])
+		assert.Equal(t, 1.0, sink.gauges["Cache.util.misses"])
+		assert.Equal(t, 1.0, sink.gauges["Cache.util.size"])
+		assert.Equal(t, 0.75, sink.gauges["Cache.util.hit_ratio"])
+		assert.Equal(t, "util", sink.cache["Cache.util.hit_ratio"])
+		assert.Contains(t, sink.gauges, "Cache.session.hit_ratio")
+	})
+}
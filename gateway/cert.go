@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"errors"
 	"io/ioutil"
 	"net"
@@ -138,14 +139,23 @@ func getCertificateIDForHost(host string, certMaps []map[string]string) string {
 	return certID
 }
 
+// getUpstreamCertificate returns the client certificate presented to the upstream host. The certificates
+// of the API for the host take precedence over its transport client certificate, then over the gateway ones.
 func (gw *Gateway) getUpstreamCertificate(host string, spec *APISpec) (cert *tls.Certificate) {
-	certMaps := []map[string]string{gw.GetConfig().Security.Certificates.Upstream}
+	var certID string
 
 	if spec != nil && !spec.UpstreamCertificatesDisabled && spec.UpstreamCertificates != nil {
-		certMaps = append(certMaps, spec.UpstreamCertificates)
+		certID = getCertificateIDForHost(host, []map[string]string{spec.UpstreamCertificates})
+	}
+
+	if certID == "" && spec != nil {
+		certID = spec.Proxy.Transport.SSLClientCertificate
+	}
+
+	if certID == "" {
+		certID = getCertificateIDForHost(host, []map[string]string{gw.GetConfig().Security.Certificates.Upstream})
 	}
 
-	certID := getCertificateIDForHost(host, certMaps)
 	if certID == "" {
 		return nil
 	}
@@ -158,6 +168,37 @@ func (gw *Gateway) getUpstreamCertificate(host string, spec *APISpec) (cert *tls
 	return certs[0]
 }
 
+// upstreamCACertificates returns the pool of the CA certificates verifying the upstream certificate for spec,
+// and a fingerprint of the certificates which changes when they're rotated. The pool is nil when the API
+// doesn't configure CA certificates, so the system pool is used.
+func (gw *Gateway) upstreamCACertificates(spec *APISpec) (*x509.CertPool, string) {
+	certIDs := spec.Proxy.Transport.SSLCACertificates
+	if len(certIDs) == 0 {
+		return nil, ""
+	}
+
+	pool := x509.NewCertPool()
+	fingerprint := sha256.New()
+
+	for _, cert := range gw.CertificateManager.List(certIDs, certs.CertificatePublic) {
+		if cert == nil || crypto.IsPublicKey(cert) {
+			continue
+		}
+
+		crypto.AddCACertificatesFromChainToPool(pool, cert)
+		for _, der := range cert.Certificate {
+			fingerprint.Write(der)
+		}
+	}
+
+	return pool, hex.EncodeToString(fingerprint.Sum(nil))
+}
+
+// invalidateUpstreamCACertificates makes the proxies re-check their upstream CA certificates on the next request.
+func (gw *Gateway) invalidateUpstreamCACertificates() {
+	gw.upstreamCAGeneration.Add(1)
+}
+
 func (gw *Gateway) verifyPeerCertificatePinnedCheck(spec *APISpec, tlsConfig *tls.Config) func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
 	if (spec == nil || spec.CertificatePinningDisabled || len(spec.PinnedPublicKeys) == 0) &&
 		len(gw.GetConfig().Security.PinnedPublicKeys) == 0 {
@@ -391,7 +432,14 @@ func (gw *Gateway) getTLSConfigForClient(baseConfig *tls.Config, listenPort int)
 	serverCerts := []tls.Certificate{}
 	certNameMap := map[string]*tls.Certificate{}
 
-	for _, certData := range gwConfig.HttpServerOptions.Certificates {
+	legacyCertificates := gwConfig.HttpServerOptions.Certificates
+	sslCertificateIDs := gwConfig.HttpServerOptions.SSLCertificates
+	portTLS, hasPortTLS := gwConfig.HttpServerOptions.PortTLS.For(listenPort)
+	if hasPortTLS && len(portTLS.SSLCertificates) > 0 {
+		legacyCertificates, sslCertificateIDs = nil, portTLS.SSLCertificates
+	}
+
+	for _, certData := range legacyCertificates {
 		cert, err := tls.LoadX509KeyPair(certData.CertFile, certData.KeyFile)
 		if err != nil {
 			log.Errorf("Server error: loadkeys: %s", err)
@@ -407,7 +455,7 @@ func (gw *Gateway) getTLSConfigForClient(baseConfig *tls.Config, listenPort int)
 		logServerCertificateExpiry(&serverCerts[i], threshold, "file")
 	}
 
-	if len(gwConfig.HttpServerOptions.SSLCertificates) > 0 {
+	if len(sslCertificateIDs) > 0 {
 		var waitingRedisLog sync.Once
 		// ensure that we are connected to redis
 		for {
@@ -421,7 +469,7 @@ func (gw *Gateway) getTLSConfigForClient(baseConfig *tls.Config, listenPort int)
 			time.Sleep(10 * time.Millisecond)
 		}
 	}
-	sslCertificates := gw.CertificateManager.List(gwConfig.HttpServerOptions.SSLCertificates, certs.CertificatePrivate)
+	sslCertificates := gw.CertificateManager.List(sslCertificateIDs, certs.CertificatePrivate)
 	for _, cert := range sslCertificates {
 		if cert != nil {
 			serverCerts = append(serverCerts, *cert)
@@ -594,6 +642,10 @@ func (gw *Gateway) getTLSConfigForClient(baseConfig *tls.Config, listenPort int)
 			newConfig.ClientAuth = tls.RequestClientCert
 		}
 
+		if hasPortTLS {
+			gw.applyPortClientAuth(newConfig, portTLS)
+		}
+
 		// Cache the config
 		tlsConfigCache.Set(hello.ServerName+listenPortStr, newConfig, cache.DefaultExpiration)
 
@@ -601,6 +653,23 @@ func (gw *Gateway) getTLSConfigForClient(baseConfig *tls.Config, listenPort int)
 	}
 }
 
+// applyPortClientAuth overrides the client certificate policy derived from the APIs with the one of the port, if set.
+func (gw *Gateway) applyPortClientAuth(tlsConfig *tls.Config, portTLS config.PortTLSConfig) {
+	switch portTLS.ClientAuth {
+	case config.PortClientAuthNone:
+		tlsConfig.ClientAuth = tls.NoClientCert
+		tlsConfig.ClientCAs = nil
+		tlsConfig.VerifyPeerCertificate = nil
+	case config.PortClientAuthRequest:
+		tlsConfig.ClientAuth = tls.RequestClientCert
+		tlsConfig.VerifyPeerCertificate = nil
+	case config.PortClientAuthRequire:
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		tlsConfig.ClientCAs = gw.CertificateManager.CertPool(portTLS.ClientCertificates)
+		tlsConfig.VerifyPeerCertificate = nil
+	}
+}
+
 func (gw *Gateway) certHandler(w http.ResponseWriter, r *http.Request) {
 	certID := mux.Vars(r)["certID"]
 
@@ -619,6 +688,7 @@ func (gw *Gateway) certHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		gw.invalidateUpstreamCACertificates()
 		doJSONWrite(w, http.StatusOK, &APICertificateStatusMessage{certID, "ok", "Certificate added"})
 	case "GET":
 		if certID == "" {
@@ -670,6 +740,7 @@ func (gw *Gateway) certHandler(w http.ResponseWriter, r *http.Request) {
 			orgID = certID[:len(certID)-sha256.Size*2]
 		}
 		gw.CertificateManager.Delete(certID, orgID)
+		gw.invalidateUpstreamCACertificates()
 		doJSONWrite(w, http.StatusOK, &apiStatusMessage{"ok", "removed"})
 	}
 }
@@ -19,6 +19,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -911,6 +912,115 @@ func testAPIMutualTLSHelper(t *testing.T, skipCAAnnounce bool) {
 	})
 }
 
+func TestAPIMutualTLS_ClientCertContextVars(t *testing.T) {
+	serverCertPem, _, combinedPEM, _ := crypto.GenServerCertificate()
+	certID, _, _ := certs.GetCertIDAndChainPEM(combinedPEM, "")
+
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.HttpServerOptions.UseSSL = true
+		globalConf.HttpServerOptions.SSLCertificates = []string{certID}
+	})
+	defer ts.Close()
+
+	certID, err := ts.Gw.CertificateManager.Add(combinedPEM, "")
+	require.NoError(t, err)
+	defer ts.Gw.CertificateManager.Delete(certID, "")
+	ts.ReloadGatewayProxy()
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	clientCertPem, _, _, clientCert := crypto.GenCertificate(&x509.Certificate{
+		Subject:        pkix.Name{CommonName: "client.example.com"},
+		DNSNames:       []string{"client.example.com"},
+		EmailAddresses: []string{"ops@example.com"},
+		NotAfter:       expiresAt,
+	}, false)
+
+	clientCertID, err := ts.Gw.CertificateManager.Add(clientCertPem, "")
+	require.NoError(t, err)
+	defer ts.Gw.CertificateManager.Delete(clientCertID, "")
+
+	block, _ := pem.Decode(clientCertPem)
+	fingerprint := crypto.HexSHA256(block.Bytes)
+
+	injectHeaders := func(v *apidef.VersionInfo) {
+		v.GlobalHeaders = map[string]string{
+			"X-Client-Cert-CN":          "$tyk_context.client_cert_cn",
+			"X-Client-Cert-Fingerprint": "$tyk_context.client_cert_fingerprint",
+			"X-Client-Cert-SAN":         "$tyk_context.client_cert_san",
+			"X-Client-Cert-Expires-At":  "$tyk_context.client_cert_expires_at",
+		}
+	}
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/mtls/"
+		spec.UseMutualTLSAuth = true
+		spec.ClientCertificates = []string{clientCertID}
+		spec.EnableContextVars = true
+		UpdateAPIVersion(spec, "v1", injectHeaders)
+	}, func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/plain/"
+		spec.EnableContextVars = true
+		UpdateAPIVersion(spec, "v1", injectHeaders)
+	})
+
+	upstreamHeaders := func(t *testing.T, resp *http.Response) map[string]string {
+		t.Helper()
+
+		var got TestHttpResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+
+		return got.Headers
+	}
+
+	t.Run("client certificate", func(t *testing.T) {
+		resp, _ := ts.Run(t, test.TestCase{Path: "/mtls/", Code: http.StatusOK, Client: GetTLSClient(&clientCert, serverCertPem)})
+
+		headers := upstreamHeaders(t, resp)
+		assert.Equal(t, "client.example.com", headers["X-Client-Cert-Cn"])
+		assert.Equal(t, fingerprint, headers["X-Client-Cert-Fingerprint"])
+		assert.Equal(t, "client.example.com,ops@example.com", headers["X-Client-Cert-San"])
+		assert.Equal(t, expiresAt.UTC().Format(time.RFC3339), headers["X-Client-Cert-Expires-At"])
+	})
+
+	t.Run("without client certificate", func(t *testing.T) {
+		resp, _ := ts.Run(t, test.TestCase{Path: "/plain/", Code: http.StatusOK, Client: GetTLSClient(nil, serverCertPem)})
+
+		headers := upstreamHeaders(t, resp)
+		assert.Empty(t, headers["X-Client-Cert-Cn"])
+		assert.Empty(t, headers["X-Client-Cert-Fingerprint"])
+	})
+
+	t.Run("API without mutual TLS", func(t *testing.T) {
+		resp, _ := ts.Run(t, test.TestCase{Path: "/plain/", Code: http.StatusOK, Client: GetTLSClient(&clientCert, serverCertPem)})
+
+		assert.Empty(t, upstreamHeaders(t, resp)["X-Client-Cert-Cn"], "certificates aren't validated without mutual TLS")
+	})
+}
+
+func TestClientCertTags(t *testing.T) {
+	_, _, _, clientCert := crypto.GenCertificate(&x509.Certificate{
+		Subject: pkix.Name{CommonName: "client.example.com"},
+	}, false)
+
+	leaf, err := x509.ParseCertificate(clientCert.Certificate[0])
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}
+
+	spec := &APISpec{APIDefinition: &apidef.APIDefinition{UseMutualTLSAuth: true}}
+	assert.Equal(t, []string{
+		"client-cert-fingerprint-" + crypto.HexSHA256(leaf.Raw),
+		"client-cert-cn-client.example.com",
+	}, clientCertTags(spec, r))
+
+	spec.UseMutualTLSAuth = false
+	assert.Empty(t, clientCertTags(spec, r))
+
+	spec.UseMutualTLSAuth = true
+	assert.Empty(t, clientCertTags(spec, httptest.NewRequest(http.MethodGet, "/", nil)))
+}
+
 func TestUpstreamMutualTLS(t *testing.T) {
 
 	ts := StartTest(nil)
@@ -1010,6 +1120,115 @@ func TestUpstreamMutualTLS(t *testing.T) {
 	})
 }
 
+func TestUpstreamTransportTLS(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	set := newCertSet(t)
+	rotated := newCertSet(t)
+
+	serverCert, err := tls.X509KeyPair(set.servCert, set.servKey)
+	require.NoError(t, err)
+	rotatedServerCert, err := tls.X509KeyPair(rotated.servCert, rotated.servKey)
+	require.NoError(t, err)
+
+	var current atomic.Pointer[tls.Certificate]
+	current.Store(&serverCert)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(set.ca)
+
+	// The upstream certificate is issued for localhost by the private CA, and clients need a certificate from it.
+	upstream := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "server name is %s", r.TLS.ServerName)
+	}))
+	upstream.TLS = &tls.Config{
+		GetCertificate: func(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return current.Load(), nil
+		},
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	upstream.StartTLS()
+	defer upstream.Close()
+
+	caID, err := ts.Gw.CertificateManager.Add(set.ca, "")
+	require.NoError(t, err)
+	defer ts.Gw.CertificateManager.Delete(caID, "")
+
+	clientCertID, err := ts.Gw.CertificateManager.Add(append(set.clientCert, set.clientKey...), "")
+	require.NoError(t, err)
+	defer ts.Gw.CertificateManager.Delete(clientCertID, "")
+
+	// The rotated CA certificate is referenced before it's added, which is how it's rotated in.
+	rotatedCABlock, _ := pem.Decode(rotated.ca)
+	rotatedCAID := crypto.HexSHA256(rotatedCABlock.Bytes)
+
+	ts.Gw.BuildAndLoadAPI(
+		func(spec *APISpec) {
+			spec.APIID = "private-ca"
+			spec.Proxy.ListenPath = "/private-ca/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.Proxy.Transport.SSLCACertificates = []string{caID}
+			spec.Proxy.Transport.SSLClientCertificate = clientCertID
+			spec.Proxy.Transport.SSLServerName = "localhost"
+		},
+		func(spec *APISpec) {
+			spec.APIID = "system-ca"
+			spec.Proxy.ListenPath = "/system-ca/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.Proxy.Transport.SSLClientCertificate = clientCertID
+			spec.Proxy.Transport.SSLServerName = "localhost"
+		},
+		func(spec *APISpec) {
+			spec.APIID = "no-server-name"
+			spec.Proxy.ListenPath = "/no-server-name/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.Proxy.Transport.SSLCACertificates = []string{caID}
+			spec.Proxy.Transport.SSLClientCertificate = clientCertID
+		},
+		func(spec *APISpec) {
+			spec.APIID = "no-client-cert"
+			spec.Proxy.ListenPath = "/no-client-cert/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.Proxy.Transport.SSLCACertificates = []string{caID}
+			spec.Proxy.Transport.SSLServerName = "localhost"
+		},
+		func(spec *APISpec) {
+			spec.APIID = "rotated-ca"
+			spec.Proxy.ListenPath = "/rotated-ca/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.Proxy.Transport.SSLCACertificates = []string{caID, rotatedCAID}
+			spec.Proxy.Transport.SSLClientCertificate = clientCertID
+			spec.Proxy.Transport.SSLServerName = "localhost"
+		},
+	)
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Path: "/private-ca/", Code: http.StatusOK, BodyMatch: "server name is localhost"},
+		{Path: "/system-ca/", Code: http.StatusInternalServerError},
+		{Path: "/no-server-name/", Code: http.StatusInternalServerError},
+		{Path: "/no-client-cert/", Code: http.StatusInternalServerError},
+		{Path: "/rotated-ca/", Code: http.StatusOK},
+	}...)
+
+	t.Run("rotated CA certificate", func(t *testing.T) {
+		current.Store(&rotatedServerCert)
+		clientCAs.AppendCertsFromPEM(rotated.ca)
+		// Force a new handshake so the upstream presents the rotated certificate.
+		upstream.CloseClientConnections()
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/rotated-ca/", Code: http.StatusInternalServerError})
+
+		// Adding the certificate through the API makes the proxy re-check its CA certificates.
+		_, _ = ts.Run(t, []test.TestCase{
+			{Method: http.MethodPost, Path: "/tyk/certs", Data: string(rotated.ca), AdminAuth: true, Code: http.StatusOK},
+			{Path: "/rotated-ca/", Code: http.StatusOK},
+		}...)
+		defer ts.Gw.CertificateManager.Delete(rotatedCAID, "")
+	})
+}
+
 func TestUpstreamCertificateWithPort(t *testing.T) {
 	ts := StartTest(nil)
 	defer ts.Close()
@@ -1903,6 +2122,81 @@ func TestCipherSuites(t *testing.T) {
 	})
 }
 
+func TestPortTLS(t *testing.T) {
+	_, _, combinedPEM, _ := crypto.GenServerCertificate()
+	serverCertID, _, _ := certs.GetCertIDAndChainPEM(combinedPEM, "")
+
+	ls, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	legacyPort := ls.Addr().(*net.TCPAddr).Port
+	require.NoError(t, ls.Close())
+
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.HttpServerOptions.UseSSL = true
+		globalConf.HttpServerOptions.SSLCertificates = []string{serverCertID}
+		globalConf.HttpServerOptions.MinVersion = tls.VersionTLS12
+		globalConf.HttpServerOptions.PortTLS = config.PortTLSConfigs{{
+			PortWhiteList: config.PortWhiteList{Ports: []int{legacyPort}},
+			MinVersion:    tls.VersionTLS10,
+			Ciphers:       []string{"TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA"},
+		}}
+	})
+	defer ts.Close()
+
+	_, _ = ts.Gw.CertificateManager.Add(combinedPEM, "")
+	defer ts.Gw.CertificateManager.Delete(serverCertID, "")
+	ts.ReloadGatewayProxy()
+
+	ts.EnablePort(legacyPort, "https")
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+	}, func(spec *APISpec) {
+		spec.APIID = "legacy"
+		spec.Proxy.ListenPath = "/legacy/"
+		spec.Protocol = "https"
+		spec.ListenPort = legacyPort
+	})
+
+	mainAddr := ts.mainProxy().listener.Addr().String()
+	legacyAddr := fmt.Sprintf("127.0.0.1:%d", legacyPort)
+
+	handshake := func(addr string, version uint16) error {
+		conn, err := tls.Dial("tcp", addr, &tls.Config{
+			InsecureSkipVerify: true,
+			MinVersion:         version,
+			MaxVersion:         version,
+		})
+		if err == nil {
+			_ = conn.Close()
+		}
+		return err
+	}
+
+	t.Run("main port requires TLS 1.2", func(t *testing.T) {
+		assert.ErrorContains(t, handshake(mainAddr, tls.VersionTLS10), "protocol version")
+		assert.NoError(t, handshake(mainAddr, tls.VersionTLS12))
+	})
+
+	t.Run("legacy port accepts TLS 1.0", func(t *testing.T) {
+		assert.NoError(t, handshake(legacyAddr, tls.VersionTLS10))
+		assert.NoError(t, handshake(legacyAddr, tls.VersionTLS12))
+	})
+
+	t.Run("legacy API is served over TLS 1.0", func(t *testing.T) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+			MinVersion:         tls.VersionTLS10,
+			MaxVersion:         tls.VersionTLS10,
+		}}}
+
+		res, err := client.Get("https://" + legacyAddr + "/legacy/")
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, uint16(tls.VersionTLS10), res.TLS.Version)
+	})
+}
+
 func TestUpstreamCertificates_WithProtocolTCP(t *testing.T) {
 	ts := StartTest(nil)
 	defer ts.Close()
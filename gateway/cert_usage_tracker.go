@@ -75,6 +75,10 @@ func extractCertificatesFromSpec(spec *APISpec) map[string]struct{} {
 	for _, certID := range spec.PinnedPublicKeys {
 		addID(certID)
 	}
+	for _, certID := range spec.Proxy.Transport.SSLCACertificates {
+		addID(certID)
+	}
+	addID(spec.Proxy.Transport.SSLClientCertificate)
 
 	return certSet
 }
@@ -178,6 +178,17 @@ func TestCollectCertUsageMap(t *testing.T) {
 		assert.Contains(t, usageMap["shared-cert"], "__server__")
 		assert.Contains(t, usageMap["shared-cert"], "api1")
 	})
+
+	t.Run("upstream transport certs", func(t *testing.T) {
+		spec := createTestAPISpec("api1", nil, nil, nil, nil)
+		spec.Proxy.Transport.SSLCACertificates = []string{"ca-cert"}
+		spec.Proxy.Transport.SSLClientCertificate = "client-cert"
+		usageMap := CollectCertUsageMap([]*APISpec{spec}, nil)
+
+		assert.Len(t, usageMap, 2)
+		assert.Contains(t, usageMap["ca-cert"], "api1")
+		assert.Contains(t, usageMap["client-cert"], "api1")
+	})
 }
 
 func TestUsageTracker_ReplaceAll(t *testing.T) {
@@ -0,0 +1,179 @@
+package gateway
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/crypto"
+	"github.com/TykTechnologies/tyk/request"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+const (
+	// controlAPIAuditRedisKey is the Redis list the audit entries are pushed to when enabled.
+	controlAPIAuditRedisKey = "tyk-control-api-audit"
+
+	defaultControlAPIAuditRedisMaxEntries = 10000
+)
+
+// controlAPIAuditEntry is a single record of the control API audit log.
+type controlAPIAuditEntry struct {
+	Time       time.Time `json:"time"`
+	NodeID     string    `json:"node_id"`
+	Actor      string    `json:"actor"`
+	RemoteAddr string    `json:"remote_addr"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	BodyHash   string    `json:"body_hash,omitempty"`
+	Status     int       `json:"status"`
+	// PrevHash is the hash of the previous entry written by this gateway process.
+	PrevHash string `json:"prev_hash"`
+	// Hash is the SHA256 of the entry encoded without this field.
+	Hash string `json:"hash,omitempty"`
+}
+
+// controlAPIAuditLog writes the audit entries of the calls made to the control API.
+type controlAPIAuditLog struct {
+	gw    *Gateway
+	out   io.Writer
+	store *storage.RedisCluster
+	// maxEntries caps the length of the Redis list.
+	maxEntries int64
+
+	mu       sync.Mutex
+	prevHash string
+}
+
+// getControlAPIAuditLog returns the audit log of the control API, or nil if it's disabled.
+// The control API endpoints are loaded on every reload, the audit log is created only once so
+// the hash chain isn't broken and the log file isn't reopened.
+func (gw *Gateway) getControlAPIAuditLog() *controlAPIAuditLog {
+	gw.controlAPIAuditOnce.Do(func() {
+		conf := gw.GetConfig().ControlAPIAuditLog
+		if !conf.Enabled {
+			return
+		}
+
+		audit := &controlAPIAuditLog{gw: gw, out: os.Stdout}
+		if conf.Path != "" {
+			audit.out = &lumberjack.Logger{
+				Filename:   conf.Path,
+				MaxSize:    conf.MaxSize,
+				MaxBackups: conf.MaxBackups,
+			}
+		}
+
+		if conf.EnableRedis {
+			audit.store = &storage.RedisCluster{ConnectionHandler: gw.StorageConnectionHandler}
+			audit.maxEntries = int64(conf.RedisMaxEntries)
+			if audit.maxEntries <= 0 {
+				audit.maxEntries = defaultControlAPIAuditRedisMaxEntries
+			}
+		}
+
+		gw.controlAPIAudit = audit
+	})
+
+	return gw.controlAPIAudit
+}
+
+// Wrap records the calls passing through the handler. Read-only calls are skipped unless configured otherwise.
+func (a *controlAPIAuditLog) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.gw.GetConfig().ControlAPIAuditLog.IncludeReadOnly && isSafeMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		entry := controlAPIAuditEntry{
+			Time:       time.Now().UTC(),
+			NodeID:     a.gw.GetNodeID(),
+			Actor:      controlAPIActor(r),
+			RemoteAddr: request.RealIP(r),
+			Method:     r.Method,
+			Path:       r.URL.Path,
+		}
+
+		if r.Body != nil {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				log.WithError(err).Error("Failed to read control API request body for the audit log")
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			if len(body) > 0 {
+				sum := sha256.Sum256(body)
+				entry.BodyHash = hex.EncodeToString(sum[:])
+			}
+		}
+
+		rw := &customResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(rw, r)
+
+		entry.Status = rw.statusCodeSent
+		if entry.Status == 0 {
+			entry.Status = http.StatusOK
+		}
+
+		a.write(entry)
+	})
+}
+
+// write chains the entry to the previous one and writes it to the configured outputs.
+func (a *controlAPIAuditLog) write(entry controlAPIAuditEntry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entry.PrevHash = a.prevHash
+	unsigned, err := json.Marshal(entry)
+	if err != nil {
+		log.WithError(err).Error("Failed to encode control API audit entry")
+		return
+	}
+
+	sum := sha256.Sum256(unsigned)
+	entry.Hash = hex.EncodeToString(sum[:])
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.WithError(err).Error("Failed to encode control API audit entry")
+		return
+	}
+
+	a.prevHash = entry.Hash
+
+	if _, err := a.out.Write(append(line, '\n')); err != nil {
+		log.WithError(err).Error("Failed to write control API audit entry")
+	}
+
+	if a.store != nil {
+		// The error is logged by the store.
+		_ = a.store.AppendToCappedList(controlAPIAuditRedisKey, string(line), a.maxEntries)
+	}
+}
+
+// controlAPIActor identifies the caller by the fingerprint of its client certificate, or by the hash of
+// the secret it sent. The secret itself is never recorded.
+func controlAPIActor(r *http.Request) string {
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		return "cert:" + crypto.HexSHA256(r.TLS.PeerCertificates[0].Raw)
+	}
+
+	secret := r.Header.Get(header.XTykAuthorization)
+	if secret == "" {
+		return "anonymous"
+	}
+
+	sum := sha256.Sum256([]byte(secret))
+	return "secret:" + hex.EncodeToString(sum[:8])
+}
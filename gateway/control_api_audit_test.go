@@ -0,0 +1,188 @@
+package gateway
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/storage"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func readControlAPIAuditLog(t *testing.T, path string) []controlAPIAuditEntry {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var entries []controlAPIAuditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry controlAPIAuditEntry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	require.NoError(t, scanner.Err())
+
+	return entries
+}
+
+func TestControlAPIAuditLog(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.Policies.PolicyPath = t.TempDir()
+		globalConf.Policies.PolicySource = "file"
+		globalConf.AllowUnsafePolicyIds = true
+		globalConf.ControlAPIAuditLog = config.ControlAPIAuditLogConfig{
+			Enabled:     true,
+			Path:        auditPath,
+			EnableRedis: true,
+		}
+	})
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "audited"
+		spec.UseKeylessAccess = false
+	})
+
+	redisStore := &storage.RedisCluster{ConnectionHandler: ts.Gw.StorageConnectionHandler}
+	redisStore.GetAndDeleteSet(controlAPIAuditRedisKey)
+
+	session := CreateStandardSession()
+	session.AccessRights = map[string]user.AccessDefinition{"audited": {APIID: "audited"}}
+	keyBody, err := json.Marshal(session)
+	require.NoError(t, err)
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Method: http.MethodPost, Path: "/tyk/keys/create", Data: keyBody, AdminAuth: true, Code: http.StatusOK},
+		{Method: http.MethodPost, Path: "/tyk/policies/default-test", Data: defaultTestPol, AdminAuth: true, Code: http.StatusOK},
+	}...)
+	ts.Gw.DoReload()
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Method: http.MethodPut, Path: "/tyk/policies/default-test", Data: defaultTestPol, AdminAuth: true, Code: http.StatusOK},
+		{Method: http.MethodGet, Path: "/tyk/policies/default-test", AdminAuth: true, Code: http.StatusOK},
+		{Method: http.MethodDelete, Path: "/tyk/keys/unknown", Code: http.StatusForbidden},
+	}...)
+
+	entries := readControlAPIAuditLog(t, auditPath)
+	require.Len(t, entries, 4, "read-only calls should not be recorded")
+
+	secretSum := sha256.Sum256([]byte(ts.Gw.GetConfig().Secret))
+	secretActor := "secret:" + hex.EncodeToString(secretSum[:8])
+	policySum := sha256.Sum256([]byte(defaultTestPol))
+
+	assert.Equal(t, http.MethodPost, entries[0].Method)
+	assert.Equal(t, "/tyk/keys/create", entries[0].Path)
+	assert.Equal(t, http.StatusOK, entries[0].Status)
+	assert.Equal(t, secretActor, entries[0].Actor)
+	keySum := sha256.Sum256(keyBody)
+	assert.Equal(t, hex.EncodeToString(keySum[:]), entries[0].BodyHash)
+
+	assert.Equal(t, http.MethodPost, entries[1].Method)
+	assert.Equal(t, "/tyk/policies/default-test", entries[1].Path)
+
+	assert.Equal(t, http.MethodPut, entries[2].Method)
+	assert.Equal(t, "/tyk/policies/default-test", entries[2].Path)
+	assert.Equal(t, http.StatusOK, entries[2].Status)
+	assert.Equal(t, secretActor, entries[2].Actor)
+	assert.Equal(t, hex.EncodeToString(policySum[:]), entries[2].BodyHash)
+
+	assert.Equal(t, http.MethodDelete, entries[3].Method)
+	assert.Equal(t, http.StatusForbidden, entries[3].Status)
+	assert.Equal(t, "anonymous", entries[3].Actor)
+	assert.Empty(t, entries[3].BodyHash)
+
+	t.Run("entries are chained", func(t *testing.T) {
+		prevHash := ""
+		for _, entry := range entries {
+			assert.Equal(t, prevHash, entry.PrevHash)
+
+			hash := entry.Hash
+			entry.Hash = ""
+			unsigned, err := json.Marshal(entry)
+			require.NoError(t, err)
+
+			sum := sha256.Sum256(unsigned)
+			assert.Equal(t, hex.EncodeToString(sum[:]), hash)
+			prevHash = hash
+		}
+	})
+
+	t.Run("secrets are not stored", func(t *testing.T) {
+		raw, err := os.ReadFile(auditPath)
+		require.NoError(t, err)
+		assert.NotContains(t, string(raw), ts.Gw.GetConfig().Secret)
+		assert.NotContains(t, string(raw), "quota_max")
+	})
+
+	t.Run("entries are pushed to Redis", func(t *testing.T) {
+		stored := redisStore.GetAndDeleteSet(controlAPIAuditRedisKey)
+		require.Len(t, stored, len(entries))
+
+		var entry controlAPIAuditEntry
+		require.NoError(t, json.Unmarshal([]byte(stored[2].(string)), &entry))
+		assert.Equal(t, entries[2], entry)
+	})
+}
+
+func TestControlAPIAuditLog_RedisMaxEntries(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.ControlAPIAuditLog = config.ControlAPIAuditLogConfig{
+			Enabled:         true,
+			Path:            filepath.Join(t.TempDir(), "audit.log"),
+			EnableRedis:     true,
+			RedisMaxEntries: 2,
+		}
+	})
+	defer ts.Close()
+
+	redisStore := &storage.RedisCluster{ConnectionHandler: ts.Gw.StorageConnectionHandler}
+	redisStore.GetAndDeleteSet(controlAPIAuditRedisKey)
+
+	for i := 0; i < 3; i++ {
+		_, _ = ts.Run(t, test.TestCase{Method: http.MethodDelete, Path: fmt.Sprintf("/tyk/keys/unknown-%d", i), Code: http.StatusForbidden})
+	}
+
+	stored := redisStore.GetAndDeleteSet(controlAPIAuditRedisKey)
+	require.Len(t, stored, 2, "the oldest entries should be removed")
+
+	var entry controlAPIAuditEntry
+	require.NoError(t, json.Unmarshal([]byte(stored[0].(string)), &entry))
+	assert.Equal(t, "/tyk/keys/unknown-1", entry.Path)
+}
+
+func TestControlAPIAuditLog_IncludeReadOnly(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.ControlAPIAuditLog = config.ControlAPIAuditLogConfig{
+			Enabled:         true,
+			Path:            auditPath,
+			IncludeReadOnly: true,
+		}
+	})
+	defer ts.Close()
+
+	_, _ = ts.Run(t, test.TestCase{Method: http.MethodGet, Path: "/tyk/apis", AdminAuth: true, Code: http.StatusOK})
+
+	entries := readControlAPIAuditLog(t, auditPath)
+	require.Len(t, entries, 1)
+	assert.Equal(t, http.MethodGet, entries[0].Method)
+	assert.True(t, strings.HasPrefix(entries[0].Actor, "secret:"))
+}
@@ -0,0 +1,220 @@
+package gateway
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/cache"
+)
+
+const (
+	defaultControlAPIAuthMaxFailures     = 10
+	defaultControlAPIAuthWindow          = time.Minute
+	defaultControlAPIAuthLockoutDuration = 5 * time.Minute
+
+	// controlAPIAuthCleanupInterval is how often the expired source IPs are removed from memory.
+	controlAPIAuthCleanupInterval = time.Minute
+
+	msgControlAPIAuthLockout = "Too many failed administrative access attempts, try again later"
+)
+
+// controlAPIAuthSource holds the authentication failures of a source IP.
+type controlAPIAuthSource struct {
+	failures    int
+	windowEnd   time.Time
+	lockedUntil time.Time
+}
+
+// controlAPIAuthLimiter locks out the source IPs failing to authenticate to the control API too often.
+// The source IPs are kept in a TTL map, so the memory used is bounded by the failures in the last window.
+type controlAPIAuthLimiter struct {
+	maxFailures int
+	window      time.Duration
+	lockout     time.Duration
+	now         func() time.Time
+
+	mu      sync.Mutex
+	sources *cache.Cache
+}
+
+func newControlAPIAuthLimiter(maxFailures int, window, lockout time.Duration) *controlAPIAuthLimiter {
+	maxFailures, window, lockout = controlAPIAuthLimits(maxFailures, window, lockout)
+
+	return &controlAPIAuthLimiter{
+		maxFailures: maxFailures,
+		window:      window,
+		lockout:     lockout,
+		now:         time.Now,
+		sources:     cache.NewCache(0, controlAPIAuthCleanupInterval),
+	}
+}
+
+// controlAPIAuthLimits applies the defaults to the unset limits.
+func controlAPIAuthLimits(maxFailures int, window, lockout time.Duration) (int, time.Duration, time.Duration) {
+	if maxFailures <= 0 {
+		maxFailures = defaultControlAPIAuthMaxFailures
+	}
+	if window <= 0 {
+		window = defaultControlAPIAuthWindow
+	}
+	if lockout <= 0 {
+		lockout = defaultControlAPIAuthLockoutDuration
+	}
+
+	return maxFailures, window, lockout
+}
+
+// getControlAPIAuthLimiter returns the limiter of the control API authentication failures, or nil if it's disabled.
+// The limiter is kept while its configuration is unchanged, so the failures are counted across reloads,
+// and rebuilt once a reload changes it.
+func (gw *Gateway) getControlAPIAuthLimiter() *controlAPIAuthLimiter {
+	gw.controlAPIAuthLimiterMu.Lock()
+	defer gw.controlAPIAuthLimiterMu.Unlock()
+
+	conf := gw.GetConfig().Security.ControlAPIAuthLimit
+	current := gw.controlAPIAuthLimiter
+
+	if !conf.Enabled {
+		if current != nil {
+			current.close()
+			gw.controlAPIAuthLimiter = nil
+		}
+		return nil
+	}
+
+	maxFailures, window, lockout := controlAPIAuthLimits(
+		conf.MaxFailures,
+		time.Duration(conf.Window)*time.Second,
+		time.Duration(conf.LockoutDuration)*time.Second,
+	)
+
+	if current != nil {
+		if current.maxFailures == maxFailures && current.window == window && current.lockout == lockout {
+			return current
+		}
+		current.close()
+	}
+
+	gw.controlAPIAuthLimiter = newControlAPIAuthLimiter(maxFailures, window, lockout)
+
+	return gw.controlAPIAuthLimiter
+}
+
+// lockedOut returns how long the source IP is still locked out for, 0 if it isn't.
+func (l *controlAPIAuthLimiter) lockedOut(ip string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	v, ok := l.sources.Get(ip)
+	if !ok {
+		return 0
+	}
+
+	if remaining := v.(*controlAPIAuthSource).lockedUntil.Sub(l.now()); remaining > 0 {
+		return remaining
+	}
+
+	return 0
+}
+
+// fail records an authentication failure of the source IP. It returns true when the source IP gets locked out.
+func (l *controlAPIAuthLimiter) fail(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	source := &controlAPIAuthSource{}
+	if v, ok := l.sources.Get(ip); ok {
+		source = v.(*controlAPIAuthSource)
+	}
+
+	if !now.Before(source.windowEnd) {
+		source.failures = 0
+		source.windowEnd = now.Add(l.window)
+	}
+
+	source.failures++
+	if source.failures < l.maxFailures {
+		l.sources.Set(ip, source, l.window)
+		return false
+	}
+
+	source.failures = 0
+	source.windowEnd = time.Time{}
+	source.lockedUntil = now.Add(l.lockout)
+	l.sources.Set(ip, source, l.lockout)
+
+	return true
+}
+
+// reset forgets the failures of the source IP after a successful authentication.
+func (l *controlAPIAuthLimiter) reset(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sources.Delete(ip)
+}
+
+// close stops the cleanup of the expired source IPs.
+func (l *controlAPIAuthLimiter) close() {
+	l.sources.Close()
+}
+
+// rejectLockedOut responds with 429 when the source IP of the request is locked out of the control API.
+// It runs before the secret is checked, so requests with the right secret are rejected too: letting them
+// through would tell a client guessing the secret which of its guesses is right, defeating the lockout.
+func (l *controlAPIAuthLimiter) rejectLockedOut(w http.ResponseWriter, ip string) bool {
+	remaining := l.lockedOut(ip)
+	if remaining <= 0 {
+		return false
+	}
+
+	retryAfter := int((remaining + time.Second - 1) / time.Second)
+	w.Header().Set(header.RetryAfter, strconv.Itoa(retryAfter))
+	doJSONWrite(w, http.StatusTooManyRequests, apiError(msgControlAPIAuthLockout))
+
+	return true
+}
+
+// controlAPIAuthFailed records a failed authentication to the control API. Once the source IP is locked out,
+// a security log entry is written and the ControlAPIAuthLockout event is fired.
+func (gw *Gateway) controlAPIAuthFailed(limiter *controlAPIAuthLimiter, r *http.Request, ip string) {
+	if !limiter.fail(ip) {
+		return
+	}
+
+	lockout := int(limiter.lockout / time.Second)
+
+	mainLog.WithFields(logrus.Fields{
+		"security":         "control_api_auth_lockout",
+		"origin":           ip,
+		"path":             r.URL.Path,
+		"failures":         limiter.maxFailures,
+		"lockout_duration": lockout,
+	}).Warning("Locked out administrative access after too many authentication failures")
+
+	gw.FireSystemEvent(EventControlAPIAuthLockout, EventControlAPIAuthLockoutMeta{
+		EventMetaDefault: EventMetaDefault{Message: "Locked out administrative access after too many authentication failures"},
+		Origin:           ip,
+		Failures:         limiter.maxFailures,
+		LockoutDuration:  lockout,
+	})
+}
+
+// controlAPIAuthSourceIP returns the IP of the peer of the connection. The forwarding headers are ignored,
+// clients could otherwise evade the lockout by changing them on every attempt.
+func controlAPIAuthSourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
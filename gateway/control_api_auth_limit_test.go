@@ -0,0 +1,188 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestControlAPIAuthLimit(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.Security.ControlAPIAuthLimit = config.ControlAPIAuthLimitConfig{
+			Enabled:         true,
+			MaxFailures:     3,
+			Window:          60,
+			LockoutDuration: 120,
+		}
+	})
+	defer ts.Close()
+
+	limiter := ts.Gw.getControlAPIAuthLimiter()
+	require.NotNil(t, limiter)
+
+	// The clock is moved forward to check the recovery from the lockout.
+	var offset atomic.Int64
+	limiter.now = func() time.Time {
+		return time.Now().Add(time.Duration(offset.Load()))
+	}
+
+	badSecret := map[string]string{header.XTykAuthorization: "guess"}
+	forbidden := test.TestCase{Path: "/tyk/apis", Headers: badSecret, Code: http.StatusForbidden}
+	allowed := test.TestCase{Path: "/tyk/apis", AdminAuth: true, Code: http.StatusOK}
+	lockedOut := test.TestCase{
+		Path:         "/tyk/apis",
+		AdminAuth:    true,
+		Code:         http.StatusTooManyRequests,
+		BodyMatch:    msgControlAPIAuthLockout,
+		HeadersMatch: map[string]string{header.RetryAfter: "120"},
+	}
+
+	t.Run("successful requests aren't throttled", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			_, _ = ts.Run(t, allowed)
+		}
+	})
+
+	t.Run("success resets the failures", func(t *testing.T) {
+		_, _ = ts.Run(t, forbidden, forbidden, allowed, forbidden, forbidden, allowed)
+	})
+
+	t.Run("lockout", func(t *testing.T) {
+		_, _ = ts.Run(t, forbidden, forbidden, forbidden)
+
+		// The right secret is rejected too, so a lockout can't be used to confirm a guess.
+		_, _ = ts.Run(t, lockedOut)
+
+		lockedOutBadSecret := lockedOut
+		lockedOutBadSecret.AdminAuth = false
+		lockedOutBadSecret.Headers = badSecret
+		_, _ = ts.Run(t, lockedOutBadSecret)
+	})
+
+	t.Run("recovery", func(t *testing.T) {
+		offset.Store(int64(121 * time.Second))
+		_, _ = ts.Run(t, allowed)
+
+		// A new window starts after the lockout.
+		_, _ = ts.Run(t, forbidden, forbidden, allowed)
+	})
+}
+
+func TestControlAPIAuthLimit_Reload(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.Security.ControlAPIAuthLimit = config.ControlAPIAuthLimitConfig{
+			Enabled:     true,
+			MaxFailures: 2,
+		}
+	})
+	defer ts.Close()
+
+	badSecret := map[string]string{header.XTykAuthorization: "guess"}
+	forbidden := test.TestCase{Path: "/tyk/apis", Headers: badSecret, Code: http.StatusForbidden}
+	allowed := test.TestCase{Path: "/tyk/apis", AdminAuth: true, Code: http.StatusOK}
+
+	_, _ = ts.Run(t, forbidden)
+
+	t.Run("unchanged configuration keeps the failures", func(t *testing.T) {
+		limiter := ts.Gw.getControlAPIAuthLimiter()
+		ts.Gw.DoReload()
+		assert.Same(t, limiter, ts.Gw.getControlAPIAuthLimiter())
+
+		_, _ = ts.Run(t, forbidden, test.TestCase{Path: "/tyk/apis", AdminAuth: true, Code: http.StatusTooManyRequests})
+	})
+
+	t.Run("changed configuration rebuilds the limiter", func(t *testing.T) {
+		conf := ts.Gw.GetConfig()
+		conf.Security.ControlAPIAuthLimit.MaxFailures = 3
+		ts.Gw.SetConfig(conf)
+		ts.Gw.DoReload()
+
+		limiter := ts.Gw.getControlAPIAuthLimiter()
+		require.NotNil(t, limiter)
+		assert.Equal(t, 3, limiter.maxFailures)
+
+		_, _ = ts.Run(t, allowed, forbidden, forbidden, allowed)
+	})
+
+	t.Run("disabling removes the limiter", func(t *testing.T) {
+		conf := ts.Gw.GetConfig()
+		conf.Security.ControlAPIAuthLimit.Enabled = false
+		ts.Gw.SetConfig(conf)
+		ts.Gw.DoReload()
+
+		assert.Nil(t, ts.Gw.getControlAPIAuthLimiter())
+		_, _ = ts.Run(t, forbidden, forbidden, forbidden, allowed)
+	})
+}
+
+func TestControlAPIAuthLimit_Disabled(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	assert.Nil(t, ts.Gw.getControlAPIAuthLimiter())
+
+	badSecret := map[string]string{header.XTykAuthorization: "guess"}
+	for i := 0; i < 20; i++ {
+		_, _ = ts.Run(t, test.TestCase{Path: "/tyk/apis", Headers: badSecret, Code: http.StatusForbidden})
+	}
+
+	_, _ = ts.Run(t, test.TestCase{Path: "/tyk/apis", AdminAuth: true, Code: http.StatusOK})
+}
+
+func TestControlAPIAuthLimiter(t *testing.T) {
+	t.Run("per source IP", func(t *testing.T) {
+		limiter := newControlAPIAuthLimiter(2, time.Minute, time.Minute)
+		defer limiter.close()
+
+		assert.False(t, limiter.fail("10.0.0.1"))
+		assert.True(t, limiter.fail("10.0.0.1"))
+
+		assert.Equal(t, time.Minute, limiter.lockedOut("10.0.0.1").Round(time.Second))
+		assert.Zero(t, limiter.lockedOut("10.0.0.2"))
+	})
+
+	t.Run("failures outside the window", func(t *testing.T) {
+		now := time.Now()
+		limiter := newControlAPIAuthLimiter(2, time.Minute, time.Minute)
+		limiter.now = func() time.Time { return now }
+		defer limiter.close()
+
+		assert.False(t, limiter.fail("10.0.0.1"))
+
+		now = now.Add(2 * time.Minute)
+		assert.False(t, limiter.fail("10.0.0.1"))
+		assert.Zero(t, limiter.lockedOut("10.0.0.1"))
+	})
+
+	t.Run("expired sources are removed", func(t *testing.T) {
+		limiter := newControlAPIAuthLimiter(2, 10*time.Millisecond, 10*time.Millisecond)
+		defer limiter.close()
+
+		for i := 0; i < 100; i++ {
+			limiter.fail(fmt.Sprintf("10.0.0.%d", i))
+		}
+		assert.Equal(t, 100, limiter.sources.Count())
+
+		time.Sleep(20 * time.Millisecond)
+		limiter.sources.Cleanup()
+		assert.Zero(t, limiter.sources.Count())
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		limiter := newControlAPIAuthLimiter(0, 0, 0)
+		defer limiter.close()
+
+		assert.Equal(t, defaultControlAPIAuthMaxFailures, limiter.maxFailures)
+		assert.Equal(t, defaultControlAPIAuthWindow, limiter.window)
+		assert.Equal(t, defaultControlAPIAuthLockoutDuration, limiter.lockout)
+	})
+}
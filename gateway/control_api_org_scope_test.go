@@ -0,0 +1,210 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/crypto"
+	"github.com/TykTechnologies/tyk/internal/uuid"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestIsOrgScopedKeyPath(t *testing.T) {
+	for path, expected := range map[string]bool{
+		"/keys":              true,
+		"/keys/abc":          true,
+		"/keys/preview":      false,
+		"/keys/create":       false,
+		"/keys/policy/abc":   false,
+		"/keysx":             false,
+		"/apis":              false,
+		"/org/keys/org-a":    false,
+		"/oauth/clients/abc": false,
+	} {
+		assert.Equal(t, expected, isOrgScopedKeyPath(path), path)
+	}
+}
+
+func TestOrgScopedAdminTokens(t *testing.T) {
+	for _, hashKeys := range []bool{false, true} {
+		name := "hashing disabled"
+		if hashKeys {
+			name = "hashing enabled"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			testOrgScopedAdminTokens(t, hashKeys)
+		})
+	}
+}
+
+type orgScopedTestOrg struct {
+	id      string
+	apiID   string
+	token   string
+	headers map[string]string
+}
+
+func testOrgScopedAdminTokens(t *testing.T, hashKeys bool) {
+	t.Helper()
+
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.HashKeys = hashKeys
+		globalConf.EnableHashedKeysListing = true
+		globalConf.Security.EnableOrgScopedAdminTokens = true
+	})
+	defer ts.Close()
+
+	newOrg := func() orgScopedTestOrg {
+		org := orgScopedTestOrg{id: "org-" + uuid.NewHex(), apiID: uuid.NewHex(), token: uuid.NewHex()}
+		org.headers = map[string]string{header.XTykAuthorization: org.token, header.XTykOrgID: org.id}
+		return org
+	}
+	orgA, orgB := newOrg(), newOrg()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = orgA.apiID
+		spec.OrgID = orgA.id
+		spec.Proxy.ListenPath = "/org-a/"
+		spec.UseKeylessAccess = false
+	}, func(spec *APISpec) {
+		spec.APIID = orgB.apiID
+		spec.OrgID = orgB.id
+		spec.Proxy.ListenPath = "/org-b/"
+		spec.UseKeylessAccess = false
+	})
+
+	for _, org := range []orgScopedTestOrg{orgA, orgB} {
+		orgSession := CreateStandardSession()
+		orgSession.OrgID = org.id
+		orgSession.OrgAdminTokenHash = crypto.HashStr(org.token, crypto.HashSha256)
+		_, _ = ts.Run(t, test.TestCase{
+			Method: http.MethodPost, Path: "/tyk/org/keys/" + org.id, Data: orgSession, AdminAuth: true, Code: http.StatusOK,
+		})
+	}
+
+	keySession := func(org orgScopedTestOrg, apiID string) *user.SessionState {
+		session := CreateStandardSession()
+		session.OrgID = org.id
+		session.AccessRights = map[string]user.AccessDefinition{apiID: {APIID: apiID}}
+		return session
+	}
+
+	createKey := func(org orgScopedTestOrg) apiModifyKeySuccess {
+		t.Helper()
+
+		resp, err := ts.Run(t, test.TestCase{
+			Method: http.MethodPost, Path: "/tyk/keys", Data: keySession(org, org.apiID), Headers: org.headers, Code: http.StatusOK,
+		})
+		require.NoError(t, err)
+
+		var created apiModifyKeySuccess
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		require.NotEmpty(t, created.Key)
+		return created
+	}
+
+	listKeys := func(org orgScopedTestOrg, query string) []string {
+		t.Helper()
+
+		resp, err := ts.Run(t, test.TestCase{Path: "/tyk/keys" + query, Headers: org.headers, Code: http.StatusOK})
+		require.NoError(t, err)
+
+		var list apiAllKeys
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+		return list.APIKeys
+	}
+
+	listed := func(created apiModifyKeySuccess) string {
+		if hashKeys {
+			return created.KeyHash
+		}
+		return created.Key
+	}
+
+	keyA, keyB := createKey(orgA), createKey(orgB)
+
+	t.Run("same org", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/tyk/keys/" + keyA.Key, Headers: orgA.headers, Code: http.StatusOK, BodyMatch: `"org_id":"` + orgA.id + `"`},
+			{Method: http.MethodPut, Path: "/tyk/keys/" + keyA.Key, Data: keySession(orgA, orgA.apiID), Headers: orgA.headers, Code: http.StatusOK},
+		}...)
+
+		keys := listKeys(orgA, "")
+		assert.Contains(t, keys, listed(keyA))
+		assert.NotContains(t, keys, listed(keyB))
+
+		keys = listKeys(orgA, "?api_id="+orgA.apiID)
+		assert.Equal(t, []string{listed(keyA)}, keys)
+	})
+
+	t.Run("cross org", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/tyk/keys/" + keyB.Key, Headers: orgA.headers, Code: http.StatusNotFound},
+			{Path: "/tyk/keys/" + keyB.Key + "?org_id=" + orgB.id + "&api_id=" + orgB.apiID, Headers: orgA.headers, Code: http.StatusNotFound},
+			{Method: http.MethodPut, Path: "/tyk/keys/" + keyB.Key, Data: keySession(orgB, orgB.apiID), Headers: orgA.headers, Code: http.StatusNotFound},
+			{Method: http.MethodPut, Path: "/tyk/keys/" + keyB.Key, Data: keySession(orgA, orgA.apiID), Headers: orgA.headers, Code: http.StatusNotFound},
+			{Method: http.MethodDelete, Path: "/tyk/keys/" + keyB.Key, Headers: orgA.headers, Code: http.StatusNotFound},
+			{Method: http.MethodPost, Path: "/tyk/keys", Data: keySession(orgB, orgB.apiID), Headers: orgA.headers, Code: http.StatusForbidden},
+			{Method: http.MethodPost, Path: "/tyk/keys", Data: keySession(orgA, orgB.apiID), Headers: orgA.headers, Code: http.StatusForbidden},
+			// the key of the other organisation is left untouched
+			{Path: "/tyk/keys/" + keyB.Key, Headers: orgB.headers, Code: http.StatusOK},
+		}...)
+
+		if hashKeys {
+			_, _ = ts.Run(t, []test.TestCase{
+				{Path: "/tyk/keys/" + keyB.KeyHash + "?hashed=1", Headers: orgA.headers, Code: http.StatusNotFound},
+				{Method: http.MethodDelete, Path: "/tyk/keys/" + keyB.KeyHash + "?hashed=1", Headers: orgA.headers, Code: http.StatusNotFound},
+			}...)
+		}
+
+		assert.NotContains(t, listKeys(orgA, "?api_id="+orgB.apiID), listed(keyB))
+		assert.NotContains(t, listKeys(orgB, ""), listed(keyA))
+	})
+
+	t.Run("scoped tokens can't reach other endpoints", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/tyk/apis", Headers: orgA.headers, Code: http.StatusForbidden},
+			{Path: "/tyk/org/keys/" + orgA.id, Headers: orgA.headers, Code: http.StatusForbidden},
+			{Method: http.MethodPost, Path: "/tyk/keys/create", Data: keySession(orgA, orgA.apiID), Headers: orgA.headers, Code: http.StatusForbidden},
+			{Path: "/tyk/keys/" + keyA.Key, Headers: map[string]string{header.XTykAuthorization: orgA.token, header.XTykOrgID: orgB.id}, Code: http.StatusForbidden},
+			{Path: "/tyk/keys/" + keyA.Key, Headers: map[string]string{header.XTykAuthorization: orgA.token}, Code: http.StatusForbidden},
+		}...)
+	})
+
+	t.Run("global secret keeps full access", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{Path: "/tyk/keys/" + keyB.Key, AdminAuth: true, Code: http.StatusOK})
+
+		resp, err := ts.Run(t, test.TestCase{Path: "/tyk/keys", AdminAuth: true, Code: http.StatusOK})
+		require.NoError(t, err)
+
+		var list apiAllKeys
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+		if hashKeys {
+			assert.Contains(t, list.APIKeys, listed(keyA))
+			assert.Contains(t, list.APIKeys, listed(keyB))
+		}
+	})
+
+	t.Run("same org delete", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Method: http.MethodDelete, Path: "/tyk/keys/" + keyA.Key, Headers: orgA.headers, Code: http.StatusOK},
+			{Path: "/tyk/keys/" + keyA.Key, Headers: orgA.headers, Code: http.StatusNotFound},
+		}...)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		conf := ts.Gw.GetConfig()
+		conf.Security.EnableOrgScopedAdminTokens = false
+		ts.Gw.SetConfig(conf)
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/tyk/keys/" + keyB.Key, Headers: orgB.headers, Code: http.StatusForbidden})
+	})
+}
@@ -28,6 +28,22 @@ var (
 	loadedDrivers    = map[apidef.MiddlewareDriver]coprocess.Dispatcher{}
 )
 
+// ErrCoProcessUnavailable is returned when dispatching to a driver that is disconnected from its plugin server.
+var ErrCoProcessUnavailable = errors.New("coprocess driver is disconnected")
+
+// connectionChecker is implemented by dispatchers that keep a connection to a plugin server.
+type connectionChecker interface {
+	Connected() bool
+}
+
+// errDispatchStreamUnimplemented is returned by DispatchStream when the plugin server doesn't implement streaming.
+var errDispatchStreamUnimplemented = errors.New("coprocess server doesn't implement DispatchStream")
+
+// streamDispatcher is implemented by the dispatchers able to stream the request and response bodies.
+type streamDispatcher interface {
+	DispatchStream(ctx context.Context, object *coprocess.Object, chunkSize int) (*coprocess.Object, error)
+}
+
 // CoProcessMiddleware is the basic CP middleware struct.
 type CoProcessMiddleware struct {
 	*BaseMiddleware
@@ -36,6 +52,7 @@ type CoProcessMiddleware struct {
 	HookName         string
 	MiddlewareDriver apidef.MiddlewareDriver
 	RawBodyOnly      bool
+	StreamBody       bool
 
 	successHandler *SuccessHandler
 }
@@ -110,9 +127,6 @@ func (c *CoProcessor) BuildObject(req *http.Request, res *http.Response, spec *A
 		if err != nil {
 			return nil, err
 		}
-		if utf8.Valid(miniRequestObject.RawBody) && !c.Middleware.RawBodyOnly {
-			miniRequestObject.Body = string(miniRequestObject.RawBody)
-		}
 	}
 
 	object := &coprocess.Object{
@@ -182,15 +196,31 @@ func (c *CoProcessor) BuildObject(req *http.Request, res *http.Response, spec *A
 		}
 		resObj.RawBody = rawBody
 		res.Body = ioutil.NopCloser(bytes.NewReader(rawBody))
-		if utf8.Valid(rawBody) && !c.Middleware.RawBodyOnly {
-			resObj.Body = string(rawBody)
-		}
 		object.Response = resObj
 	}
 
+	// streamed bodies are sent raw only, the bodies are set if the object isn't streamed
+	if !c.Middleware.StreamBody {
+		c.setBodies(object)
+	}
+
 	return object, nil
 }
 
+// setBodies sets the request and response bodies of object from their raw bodies, when they're valid UTF-8
+// and the hook isn't given the raw bodies only.
+func (c *CoProcessor) setBodies(object *coprocess.Object) {
+	if c.Middleware.RawBodyOnly {
+		return
+	}
+	if req := object.Request; req != nil && utf8.Valid(req.RawBody) {
+		req.Body = string(req.RawBody)
+	}
+	if res := object.Response; res != nil && utf8.Valid(res.RawBody) {
+		res.Body = string(res.RawBody)
+	}
+}
+
 // ObjectPostProcess does CoProcessObject post-processing (adding/removing headers or params, etc.).
 func (c *CoProcessor) ObjectPostProcess(object *coprocess.Object, r *http.Request, origURL string, origMethod string) (err error) {
 	r.ContentLength = int64(len(object.Request.RawBody))
@@ -362,6 +392,20 @@ func (m *CoProcessMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Requ
 	returnObject, err := coProcessor.Dispatch(r.Context(), object)
 	ms := DurationToMillisecond(time.Since(t1))
 
+	// The gRPC call carries the request context, it's cancelled along with the request.
+	if err != nil && requestCancelled(r) {
+		return abortMiddleware(r, m.Name(), logger)
+	}
+
+	if errors.Is(err, ErrCoProcessUnavailable) {
+		if m.Spec.CustomMiddleware.FailOpenOnDisconnect && m.HookType != coprocess.HookType_CustomKeyCheck {
+			logger.WithError(err).Warning("Skipping hook while disconnected")
+			return nil, http.StatusOK
+		}
+		logger.WithError(err).Error("Dispatch error")
+		return errors.New(http.StatusText(http.StatusServiceUnavailable)), http.StatusServiceUnavailable
+	}
+
 	if err != nil {
 		logger.WithError(err).Error("Dispatch error")
 		if m.HookType == coprocess.HookType_CustomKeyCheck {
@@ -371,7 +415,7 @@ func (m *CoProcessMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
-	m.logger.WithField("ms", ms).Debug("gRPC request processing took")
+	logger.WithField("ms", ms).Debug("gRPC request processing took")
 
 	err = coProcessor.ObjectPostProcess(returnObject, r, origURL, origMethod)
 	if err != nil {
@@ -560,6 +604,7 @@ func (h *CustomMiddlewareResponseHook) Init(mwDef interface{}, spec *APISpec) er
 		HookName:         mwDefinition.Name,
 		HookType:         coprocess.HookType_Response,
 		RawBodyOnly:      mwDefinition.RawBodyOnly,
+		StreamBody:       mwDefinition.StreamBody,
 		MiddlewareDriver: spec.CustomMiddleware.Driver,
 	}
 	return nil
@@ -580,8 +625,9 @@ func (h *CustomMiddlewareResponseHook) HandleError(rw http.ResponseWriter, req *
 }
 
 func (h *CustomMiddlewareResponseHook) HandleResponse(rw http.ResponseWriter, res *http.Response, req *http.Request, ses *user.SessionState) error {
+	logger := h.requestLogger(req)
 
-	h.logger().WithFields(logrus.Fields{
+	logger.WithFields(logrus.Fields{
 		"prefix": "coprocess",
 	}).Debugf("Response hook '%s' is called", h.mw.Name())
 
@@ -591,19 +637,23 @@ func (h *CustomMiddlewareResponseHook) HandleResponse(rw http.ResponseWriter, re
 
 	object, err := coProcessor.BuildObject(req, res, h.mw.Spec)
 	if err != nil {
-		h.logger().WithError(err).Debug("Couldn't build request object")
+		logger.WithError(err).Debug("Couldn't build request object")
 		return errors.New("Middleware error")
 	}
 	object.Session = ProtoSessionState(ses)
 
 	retObject, err := coProcessor.Dispatch(req.Context(), object)
+	if errors.Is(err, ErrCoProcessUnavailable) && h.mw.Spec.CustomMiddleware.FailOpenOnDisconnect {
+		logger.WithError(err).Warning("Skipping response hook while disconnected")
+		return nil
+	}
 	if err != nil {
-		h.logger().WithError(err).Debug("Couldn't dispatch request object")
+		logger.WithError(err).Debug("Couldn't dispatch request object")
 		return errors.New("Middleware error")
 	}
 
 	if retObject.Response == nil {
-		h.logger().WithError(err).Debug("No response object returned by response hook")
+		logger.WithError(err).Debug("No response object returned by response hook")
 		return errors.New("Middleware error")
 	}
 
@@ -685,6 +735,20 @@ func (c *CoProcessor) Dispatch(ctx context.Context, object *coprocess.Object) (*
 		err := fmt.Errorf("Couldn't dispatch request, driver '%s' isn't available", c.Middleware.MiddlewareDriver)
 		return nil, err
 	}
+	if checker, ok := dispatcher.(connectionChecker); ok && !checker.Connected() {
+		return nil, ErrCoProcessUnavailable
+	}
+	if c.Middleware.StreamBody {
+		if streamer, ok := dispatcher.(streamDispatcher); ok {
+			chunkSize := c.Middleware.Gw.GetConfig().CoProcessOptions.GRPCStreamChunkSize
+			newObject, err := streamer.DispatchStream(ctx, object, chunkSize)
+			if !errors.Is(err, errDispatchStreamUnimplemented) {
+				return newObject, err
+			}
+		}
+		// the object is dispatched whole, with its bodies
+		c.setBodies(object)
+	}
 	newObject, err := dispatcher.DispatchWithContext(ctx, object)
 	if err != nil {
 		return nil, err
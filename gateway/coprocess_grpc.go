@@ -3,12 +3,21 @@ package gateway
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+	"io"
 	"net/url"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/coprocess"
@@ -22,6 +31,9 @@ var (
 // GRPCDispatcher implements a coprocess.Dispatcher
 type GRPCDispatcher struct {
 	coprocess.Dispatcher
+
+	conn         *grpc.ClientConn
+	disconnected atomic.Bool
 }
 
 func (gw *Gateway) GetCoProcessGrpcServerTargetURL() (*url.URL, error) {
@@ -54,7 +66,91 @@ func (d *GRPCDispatcher) Dispatch(object *coprocess.Object) (*coprocess.Object,
 
 // DispatchWithContext takes a context and CoProcessMessage and sends it to the CP with trace propagation.
 func (d *GRPCDispatcher) DispatchWithContext(ctx context.Context, object *coprocess.Object) (*coprocess.Object, error) {
-	return grpcClient.Dispatch(ctx, object)
+	newObject, err := grpcClient.Dispatch(ctx, object)
+	if status.Code(err) == codes.Unavailable {
+		return nil, fmt.Errorf("%w: %v", ErrCoProcessUnavailable, err)
+	}
+	return newObject, err
+}
+
+// DispatchStream sends object to the CP through the streaming rpc, with the raw request and response bodies
+// in chunks of chunkSize bytes, so they aren't limited by the max message sizes. errDispatchStreamUnimplemented
+// is returned for servers not implementing the streaming rpc, the object is to be dispatched with Dispatch.
+func (d *GRPCDispatcher) DispatchStream(ctx context.Context, object *coprocess.Object, chunkSize int) (*coprocess.Object, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	newObject, err := dispatchStream(ctx, object, chunkSize)
+	switch status.Code(err) {
+	case codes.Unimplemented:
+		log.WithFields(logrus.Fields{
+			"prefix": "coprocess",
+		}).Debug("gRPC server doesn't implement DispatchStream, falling back to Dispatch")
+		return nil, fmt.Errorf("%w: %v", errDispatchStreamUnimplemented, err)
+	case codes.Unavailable:
+		return nil, fmt.Errorf("%w: %v", ErrCoProcessUnavailable, err)
+	}
+	return newObject, err
+}
+
+func dispatchStream(ctx context.Context, object *coprocess.Object, chunkSize int) (*coprocess.Object, error) {
+	stream, err := grpcClient.DispatchStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := coprocess.SendObject(stream, object, chunkSize); err != nil {
+		// The stream was ended by the server, its status is returned by Recv.
+		if err == io.EOF {
+			if _, recvErr := stream.Recv(); recvErr != nil && recvErr != io.EOF {
+				err = recvErr
+			}
+		}
+		return nil, err
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	return coprocess.RecvObject(stream)
+}
+
+// Connected reports whether the connection to the gRPC server is usable.
+func (d *GRPCDispatcher) Connected() bool {
+	return !d.disconnected.Load()
+}
+
+// watchConnection follows the state of the connection to the gRPC server until ctx is done.
+// A lost connection is reconnected with exponential backoff, without a reload of the gateway.
+func (d *GRPCDispatcher) watchConnection(ctx context.Context) {
+	logger := log.WithFields(logrus.Fields{
+		"prefix": "coprocess",
+	})
+
+	for {
+		state := d.conn.GetState()
+		switch state {
+		case connectivity.Idle:
+			// The connection goes idle once it's lost, reconnect without waiting for a request.
+			d.conn.Connect()
+		case connectivity.Ready:
+			if d.disconnected.CompareAndSwap(true, false) {
+				logger.Info("Reconnected to gRPC server")
+			}
+		case connectivity.TransientFailure:
+			if d.disconnected.CompareAndSwap(false, true) {
+				logger.Warning("Lost connection to gRPC server, reconnecting")
+			}
+		case connectivity.Shutdown:
+			d.disconnected.Store(true)
+			return
+		}
+
+		if !d.conn.WaitForStateChange(ctx, state) {
+			return
+		}
+	}
 }
 
 // DispatchEvent dispatches a Tyk event.
@@ -90,6 +186,40 @@ func (gw *Gateway) grpcCallOpts() grpc.DialOption {
 	return grpc.WithDefaultCallOptions(opts...)
 }
 
+func (gw *Gateway) grpcConnectionOpts() []grpc.DialOption {
+	conf := gw.GetConfig().CoProcessOptions
+
+	backoffConfig := backoff.DefaultConfig
+	if conf.GRPCReconnectBaseDelay > 0 {
+		backoffConfig.BaseDelay = time.Duration(conf.GRPCReconnectBaseDelay * float64(time.Second))
+	}
+	if conf.GRPCReconnectMaxDelay > 0 {
+		backoffConfig.MaxDelay = time.Duration(conf.GRPCReconnectMaxDelay * float64(time.Second))
+	}
+	if backoffConfig.MaxDelay < backoffConfig.BaseDelay {
+		backoffConfig.MaxDelay = backoffConfig.BaseDelay
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff: backoffConfig,
+			// gRPC default, ConnectParams don't fall back to it.
+			MinConnectTimeout: 20 * time.Second,
+		}),
+	}
+
+	if conf.GRPCHealthCheckInterval > 0 {
+		interval := time.Duration(conf.GRPCHealthCheckInterval * float64(time.Second))
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                interval,
+			Timeout:             interval,
+			PermitWithoutStream: true,
+		}))
+	}
+
+	return opts
+}
+
 // NewGRPCDispatcher wraps all the actions needed for this CP.
 func (gw *Gateway) NewGRPCDispatcher() (coprocess.Dispatcher, error) {
 	if gw.GetConfig().CoProcessOptions.CoProcessGRPCServer == "" {
@@ -107,6 +237,7 @@ func (gw *Gateway) NewGRPCDispatcher() (coprocess.Dispatcher, error) {
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 	}
+	dialOptions = append(dialOptions, gw.grpcConnectionOpts()...)
 	authority := gw.GetConfig().CoProcessOptions.GRPCAuthority
 	if authority != "" {
 		dialOptions = append(dialOptions, grpc.WithAuthority(authority))
@@ -129,5 +260,9 @@ func (gw *Gateway) NewGRPCDispatcher() (coprocess.Dispatcher, error) {
 	}
 
 	grpcClient = coprocess.NewDispatcherClient(grpcConnection)
-	return &GRPCDispatcher{}, nil
+
+	dispatcher := &GRPCDispatcher{conn: grpcConnection}
+	go dispatcher.watchConnection(gw.ctx)
+
+	return dispatcher, nil
 }
@@ -0,0 +1,116 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/TykTechnologies/tyk/header"
+)
+
+// The dashboard rotates the node nonce on every call and rejects a stale one, so the calls
+// consuming it, e.g. the heartbeat and a policy fetch, are serialised by doDashboardNonceRequest:
+// a call is only sent once the previous one stored the nonce it got back.
+
+func (gw *Gateway) getServiceNonce() string {
+	gw.ServiceNonceMutex.RLock()
+	defer gw.ServiceNonceMutex.RUnlock()
+	return gw.ServiceNonce
+}
+
+func (gw *Gateway) setServiceNonce(nonce string) {
+	gw.ServiceNonceMutex.Lock()
+	defer gw.ServiceNonceMutex.Unlock()
+	gw.ServiceNonce = nonce
+}
+
+// DashboardNonceMismatches returns the number of dashboard calls rejected for a stale nonce.
+func (gw *Gateway) DashboardNonceMismatches() int64 {
+	return gw.dashboardNonceMismatches.Load()
+}
+
+// doDashboardNonceRequest sends a request consuming the node nonce. The buildReq function is
+// called while no other such request is in flight and must set the current nonce. The response
+// body is read before the next request is sent, the nonce of a 200 response is stored and the
+// body is returned buffered.
+//
+// A request rejected for its nonce is retried once when the nonce was refreshed meanwhile,
+// e.g. by a re-registration, so callers only fall back to re-registering when it's still stale.
+func (gw *Gateway) doDashboardNonceRequest(buildReq func() (*http.Request, error), client *http.Client, errorContext string) (*http.Response, error) {
+	for retried := false; ; retried = true {
+		resp, sentNonce, err := gw.sendDashboardNonceRequest(buildReq, client)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusForbidden {
+			return resp, nil
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if !isNonceRelatedError(string(body)) {
+			return resp, nil
+		}
+
+		mismatches := gw.dashboardNonceMismatches.Add(1)
+		log.WithField("context", errorContext).WithField("mismatches", mismatches).
+			Warning("Dashboard rejected the node nonce")
+		instrument.NewJob("DashboardNonce").Event("mismatch")
+
+		if retried || gw.getServiceNonce() == sentNonce {
+			return resp, nil
+		}
+
+		log.WithField("context", errorContext).Info("Nonce was refreshed, retrying request...")
+	}
+}
+
+// sendDashboardNonceRequest sends a single request under the nonce lock, returning it with the nonce it was sent with.
+func (gw *Gateway) sendDashboardNonceRequest(buildReq func() (*http.Request, error), client *http.Client) (*http.Response, string, error) {
+	gw.dashboardNonceMu.Lock()
+	defer gw.dashboardNonceMu.Unlock()
+
+	req, err := buildReq()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build request: %w", err)
+	}
+	sentNonce := req.Header.Get(header.XTykNonce)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, sentNonce, err
+	}
+
+	body, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if readErr != nil {
+		// The caller gets the read error when reading the body, and can recover from it.
+		resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), errReader{readErr}))
+		return resp, sentNonce, nil
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if resp.StatusCode == http.StatusOK {
+		var envelope struct {
+			Nonce string
+		}
+		if json.Unmarshal(body, &envelope) == nil {
+			gw.setServiceNonce(envelope.Nonce)
+		}
+	}
+
+	return resp, sentNonce, nil
+}
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,162 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/header"
+)
+
+// strictNonceDashboard only accepts the nonce it issued last, rotating it on every accepted call,
+// like the dashboard does.
+type strictNonceDashboard struct {
+	t *testing.T
+	// onStale is called when a stale nonce is rejected
+	onStale func()
+
+	mu            sync.Mutex
+	counter       int
+	nonce         string
+	registrations int32
+}
+
+func newStrictNonceDashboard(t *testing.T) *strictNonceDashboard {
+	return &strictNonceDashboard{t: t, nonce: "nonce-0"}
+}
+
+func (d *strictNonceDashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	if r.URL.Path == "/register/node" {
+		atomic.AddInt32(&d.registrations, 1)
+	} else if r.Header.Get(header.XTykNonce) != d.nonce {
+		d.mu.Unlock()
+		if d.onStale != nil {
+			d.onStale()
+		}
+		w.WriteHeader(http.StatusForbidden)
+		writeBody(d.t, w, `{"Status":"Error","Message":"Nonce failed","Nonce":""}`)
+		return
+	}
+
+	// Widen the window in which an unserialised call would be sent with the same nonce.
+	time.Sleep(2 * time.Millisecond)
+	d.counter++
+	d.nonce = fmt.Sprintf("nonce-%d", d.counter)
+	nonce := d.nonce
+	d.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/system/apis", "/system/policies":
+		writeBody(d.t, w, fmt.Sprintf(`{"Message":[],"Nonce":%q}`, nonce))
+	default:
+		writeJSON(d.t, w, okResponse("node-1", nonce))
+	}
+}
+
+func (d *strictNonceDashboard) current() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.nonce
+}
+
+// TestDashboardNonce_InterleavedCalls verifies the calls consuming the nonce don't race on it
+// and never make the node re-register.
+func TestDashboardNonce_InterleavedCalls(t *testing.T) {
+	dashboard := newStrictNonceDashboard(t)
+	srv := httptest.NewServer(dashboard)
+	defer srv.Close()
+
+	h, closeFn := newTestDashboardHandler(t, srv.URL)
+	defer closeFn()
+	h.HeartBeatEndpoint = srv.URL + "/register/ping"
+	h.KeyQuotaTriggerEndpoint = srv.URL + "/system/key/quota_trigger"
+	h.Gw.SetNodeID("node-1")
+	h.Gw.setServiceNonce(dashboard.current())
+
+	const rounds = 10
+	calls := map[string]func() error{
+		"heartbeat": func() error {
+			return h.doHeartBeat(h.newRequest(http.MethodGet, h.HeartBeatEndpoint), h.Gw.initialiseClient())
+		},
+		"policies": func() error {
+			_, err := h.Gw.LoadPoliciesFromDashboard(srv.URL+"/system/policies", "test-secret")
+			return err
+		},
+		"apis": func() error {
+			_, err := APIDefinitionLoader{Gw: h.Gw}.FromDashboardService(srv.URL + "/system/apis")
+			return err
+		},
+		"quota trigger": func() error {
+			return h.NotifyDashboardOfEvent(EventTriggerExceededMeta{Key: "key", TriggerLimit: 80})
+		},
+	}
+
+	var wg sync.WaitGroup
+	for name, call := range calls {
+		for i := 0; i < rounds; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, call(), name)
+			}()
+		}
+	}
+	wg.Wait()
+
+	assert.Zero(t, atomic.LoadInt32(&dashboard.registrations), "node shouldn't re-register")
+	assert.Zero(t, h.Gw.DashboardNonceMismatches())
+	assert.Equal(t, dashboard.current(), h.Gw.getServiceNonce())
+	assert.Equal(t, "nonce-40", dashboard.current())
+}
+
+// TestDashboardNonce_RetriesWithRefreshedNonce verifies a call rejected for its nonce is retried
+// with the nonce refreshed meanwhile rather than re-registering the node.
+func TestDashboardNonce_RetriesWithRefreshedNonce(t *testing.T) {
+	dashboard := newStrictNonceDashboard(t)
+	srv := httptest.NewServer(dashboard)
+	defer srv.Close()
+
+	h, closeFn := newTestDashboardHandler(t, srv.URL)
+	defer closeFn()
+	h.Gw.SetNodeID("node-1")
+	h.Gw.setServiceNonce("stale")
+
+	// The nonce is refreshed while the stale one is rejected, e.g. by a registration.
+	dashboard.onStale = func() {
+		h.Gw.setServiceNonce(dashboard.current())
+	}
+
+	_, err := h.Gw.LoadPoliciesFromDashboard(srv.URL+"/system/policies", "test-secret")
+	require.NoError(t, err)
+
+	assert.Zero(t, atomic.LoadInt32(&dashboard.registrations), "node shouldn't re-register")
+	assert.Equal(t, int64(1), h.Gw.DashboardNonceMismatches())
+	assert.Equal(t, "nonce-1", h.Gw.getServiceNonce())
+}
+
+// TestDashboardNonce_StaleReRegisters verifies a nonce which is still stale on retry makes the node re-register.
+func TestDashboardNonce_StaleReRegisters(t *testing.T) {
+	dashboard := newStrictNonceDashboard(t)
+	srv := httptest.NewServer(dashboard)
+	defer srv.Close()
+
+	h, closeFn := newTestDashboardHandler(t, srv.URL)
+	defer closeFn()
+	h.Gw.SetNodeID("node-1")
+	h.Gw.setServiceNonce("stale")
+
+	_, err := h.Gw.LoadPoliciesFromDashboard(srv.URL+"/system/policies", "test-secret")
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&dashboard.registrations))
+	assert.Equal(t, int64(1), h.Gw.DashboardNonceMismatches())
+}
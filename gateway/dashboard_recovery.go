@@ -21,20 +21,14 @@ func (e *DashboardAuthError) Error() string {
 
 // executeDashboardRequestWithRecovery performs a dashboard request with automatic recovery for network and nonce errors.
 // It uses a 2-attempt policy (original + 1 retry) to avoid unbounded recursion.
-// The buildReq function should create a fresh request with updated headers (including nonce).
+// The buildReq function should create a fresh request with updated headers (including nonce),
+// it's sent through doDashboardNonceRequest which stores the nonce of the response.
 func (gw *Gateway) executeDashboardRequestWithRecovery(buildReq func() (*http.Request, error), errorContext string) (*http.Response, error) {
 	const maxAttempts = 2
 
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		// Build fresh request with current nonce
-		req, err := buildReq()
-		if err != nil {
-			return nil, fmt.Errorf("failed to build request: %w", err)
-		}
-
-		// Execute request
-		c := gw.initialiseClient()
-		resp, err := c.Do(req)
+		// Execute request with current nonce
+		resp, err := gw.doDashboardNonceRequest(buildReq, gw.initialiseClient(), errorContext)
 
 		// Handle network errors during request
 		if err != nil {
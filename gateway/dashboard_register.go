@@ -7,15 +7,94 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	mathrand "math/rand"
 	"net/http"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/gocraft/health"
+
 	"github.com/TykTechnologies/tyk/header"
 )
 
 var dashLog = log.WithField("prefix", "dashboard")
 
+const (
+	defaultHeartBeatInterval         = 2 * time.Second
+	defaultRegistrationRetryDelay    = 5 * time.Second
+	defaultRegistrationRetryMaxDelay = time.Minute
+	defaultHeartBeatFailureThreshold = 30 * time.Second
+)
+
+// dashboardTimings holds the heartbeat interval and registration retry delays from db_app_conf_options.
+type dashboardTimings struct {
+	heartBeatInterval         time.Duration
+	retryDelay                time.Duration
+	retryMaxDelay             time.Duration
+	jitter                    float64
+	heartBeatFailureThreshold time.Duration
+}
+
+func secondsOrDefault(seconds float64, def time.Duration) time.Duration {
+	if seconds <= 0 {
+		return def
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func (gw *Gateway) dashboardTimings() dashboardTimings {
+	conf := gw.GetConfig().DBAppConfOptions
+
+	timings := dashboardTimings{
+		heartBeatInterval:         secondsOrDefault(conf.HeartBeatInterval, defaultHeartBeatInterval),
+		retryDelay:                secondsOrDefault(conf.RegistrationRetryDelay, defaultRegistrationRetryDelay),
+		retryMaxDelay:             secondsOrDefault(conf.RegistrationRetryMaxDelay, defaultRegistrationRetryMaxDelay),
+		jitter:                    min(max(conf.Jitter, 0), 1),
+		heartBeatFailureThreshold: secondsOrDefault(conf.HeartBeatFailureThreshold, defaultHeartBeatFailureThreshold),
+	}
+
+	if timings.retryMaxDelay < timings.retryDelay {
+		timings.retryMaxDelay = timings.retryDelay
+	}
+
+	return timings
+}
+
+// registrationRetryDelay returns the delay before the next registration attempt after the given
+// number of consecutive failed ones, doubling from the retry delay up to the maximum delay.
+func (d dashboardTimings) registrationRetryDelay(failures int) time.Duration {
+	delay := d.retryDelay
+	for i := 1; i < failures && delay < d.retryMaxDelay; i++ {
+		delay *= 2
+	}
+
+	return d.withJitter(min(delay, d.retryMaxDelay))
+}
+
+// withJitter randomly spreads a delay by up to the jitter fraction of it.
+func (d dashboardTimings) withJitter(delay time.Duration) time.Duration {
+	if d.jitter <= 0 {
+		return delay
+	}
+
+	return delay + time.Duration((mathrand.Float64()*2-1)*d.jitter*float64(delay))
+}
+
+// sleepContext waits for the delay, returning false when the context is cancelled first.
+func sleepContext(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
 type NodeResponse struct {
 	Status  string
 	Message any
@@ -50,7 +129,8 @@ type HTTPDashboardHandler struct {
 	HeartBeatEndpoint       string
 	KeyQuotaTriggerEndpoint string
 
-	Secret string
+	Secret   string
+	secretMu sync.RWMutex
 
 	heartBeatStopSentinel int32
 
@@ -105,7 +185,14 @@ func (gw *Gateway) reLogin() {
 		dashLog.Error("Could not deregister: ", err)
 	}
 
-	time.Sleep(5 * time.Second)
+	ctx := gw.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if !sleepContext(ctx, gw.dashboardTimings().registrationRetryDelay(1)) {
+		return
+	}
 
 	if err := gw.DashService.Register(gw.ctx); err != nil {
 		dashLog.Error("Could not register: ", err)
@@ -149,21 +236,20 @@ func (h *HTTPDashboardHandler) NotifyDashboardOfEvent(event interface{}) error {
 		return err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, h.KeyQuotaTriggerEndpoint, &b)
-	if err != nil {
-		log.Errorf("Could not create request.. %v", err)
-		return err
-	}
-
-	req.Header.Set("authorization", h.Secret)
-	req.Header.Set(header.XTykNodeID, h.Gw.GetNodeID())
-	h.Gw.ServiceNonceMutex.RLock()
-	req.Header.Set(header.XTykNonce, h.Gw.ServiceNonce)
-	h.Gw.ServiceNonceMutex.RUnlock()
+	buildReq := func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPost, h.KeyQuotaTriggerEndpoint, bytes.NewReader(b.Bytes()))
+		if err != nil {
+			log.Errorf("Could not create request.. %v", err)
+			return nil, err
+		}
 
-	c := h.Gw.initialiseClient()
+		req.Header.Set("authorization", h.getSecret())
+		req.Header.Set(header.XTykNodeID, h.Gw.GetNodeID())
+		req.Header.Set(header.XTykNonce, h.Gw.getServiceNonce())
+		return req, nil
+	}
 
-	resp, err := c.Do(req)
+	resp, err := h.Gw.doDashboardNonceRequest(buildReq, h.Gw.initialiseClient(), "quota trigger notification")
 	if err != nil {
 		log.Errorf("Request failed with error %v", err)
 		return err
@@ -178,15 +264,7 @@ func (h *HTTPDashboardHandler) NotifyDashboardOfEvent(event interface{}) error {
 	}
 
 	val := NodeResponse{}
-	if err := json.NewDecoder(resp.Body).Decode(&val); err != nil {
-		return err
-	}
-
-	h.Gw.ServiceNonceMutex.Lock()
-	h.Gw.ServiceNonce = val.Nonce
-	h.Gw.ServiceNonceMutex.Unlock()
-
-	return nil
+	return json.NewDecoder(resp.Body).Decode(&val)
 }
 
 // parseRegistrationResponse extracts the NodeID from a successful registration
@@ -194,19 +272,19 @@ func (h *HTTPDashboardHandler) NotifyDashboardOfEvent(event interface{}) error {
 func parseRegistrationResponse(statusCode int, val NodeResponse) (nodeID string, ok bool) {
 	// 409 with Status != "OK" means lock contention or Redis failure — retry.
 	if statusCode == http.StatusConflict && val.Status != "OK" {
-		dashLog.Warning("Registration deferred (409 with status: ", val.Status, "); retrying in 5s")
+		dashLog.Warning("Registration deferred (409 with status: ", val.Status, ")")
 		return "", false
 	}
 
 	msgMap, ok := val.Message.(map[string]interface{})
 	if !ok {
-		dashLog.Error("Failed to register node, retrying in 5s")
+		dashLog.Error("Failed to register node")
 		return "", false
 	}
 
 	nodeID, ok = msgMap["NodeID"].(string)
 	if !ok || nodeID == "" {
-		dashLog.Error("Failed to register node, retrying in 5s")
+		dashLog.Error("Failed to register node")
 		return "", false
 	}
 
@@ -216,7 +294,8 @@ func parseRegistrationResponse(statusCode int, val NodeResponse) (nodeID string,
 func (h *HTTPDashboardHandler) Register(ctx context.Context) error {
 	dashLog.Info("Registering gateway node with Dashboard")
 
-	for {
+	timings := h.Gw.dashboardTimings()
+	for failures := 1; ; failures++ {
 		registered, err := h.attemptRegistration(ctx)
 		if err != nil {
 			return err
@@ -225,27 +304,42 @@ func (h *HTTPDashboardHandler) Register(ctx context.Context) error {
 			return nil
 		}
 
-		select {
-		case <-ctx.Done():
+		delay := timings.registrationRetryDelay(failures)
+		dashLog.Infof("Retrying registration in %s", delay.Round(time.Millisecond))
+
+		if !sleepContext(ctx, delay) {
 			return ctx.Err()
-		case <-time.After(5 * time.Second):
 		}
 	}
 }
 
 func (h *HTTPDashboardHandler) attemptRegistration(ctx context.Context) (registered bool, err error) {
+	registered, err = h.register(ctx)
+	if registered {
+		h.Gw.DoReloadWithRetry(ctx)
+	}
+
+	return registered, err
+}
+
+// register sends the registration request. It holds the nonce lock so no call consuming
+// the previous nonce is in flight when the nonce of the registration is stored.
+func (h *HTTPDashboardHandler) register(ctx context.Context) (registered bool, err error) {
+	h.Gw.dashboardNonceMu.Lock()
+	defer h.Gw.dashboardNonceMu.Unlock()
+
 	req := h.newRequestWithContext(ctx, http.MethodGet, h.RegistrationEndpoint)
 	req.Header.Set(header.XTykSessionID, h.Gw.SessionID)
 
 	resp, err := h.Gw.initialiseClient().Do(req)
 	if err != nil {
-		dashLog.Errorf("Request failed with error %v; retrying in 5s", err)
+		dashLog.Errorf("Request failed with error %v", err)
 		return false, nil
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusConflict {
-		dashLog.Errorf("Response failed with code %d; retrying in 5s", resp.StatusCode)
+		dashLog.Errorf("Response failed with code %d", resp.StatusCode)
 		return false, nil
 	}
 
@@ -262,11 +356,8 @@ func (h *HTTPDashboardHandler) attemptRegistration(ctx context.Context) (registe
 	h.Gw.SetNodeID(nodeID)
 	dashLog.WithField("id", h.Gw.GetNodeID()).Info("Node Registered")
 
-	h.Gw.ServiceNonceMutex.Lock()
-	h.Gw.ServiceNonce = val.Nonce
-	h.Gw.ServiceNonceMutex.Unlock()
+	h.Gw.setServiceNonce(val.Nonce)
 	dashLog.Debug("Registration Finished: Nonce Set: ", val.Nonce)
-	h.Gw.DoReloadWithRetry(ctx)
 
 	return true, nil
 }
@@ -282,6 +373,11 @@ func (h *HTTPDashboardHandler) Ping() error {
 		ctx = context.Background()
 	}
 
+	return h.PingContext(ctx)
+}
+
+// PingContext is Ping, cancelled with ctx.
+func (h *HTTPDashboardHandler) PingContext(ctx context.Context) error {
 	err := h.doHeartBeat(
 		h.newRequestWithContext(ctx, http.MethodGet, h.HeartBeatEndpoint),
 		h.Gw.initialiseClient())
@@ -298,9 +394,15 @@ func (h *HTTPDashboardHandler) isHeartBeatStopped() bool {
 func (h *HTTPDashboardHandler) StartBeating(ctx context.Context) error {
 	atomic.SwapInt32(&h.heartBeatStopSentinel, HeartBeatStarted)
 
-	req := h.newRequest(http.MethodGet, h.HeartBeatEndpoint)
+	req := h.newRequestWithContext(ctx, http.MethodGet, h.HeartBeatEndpoint)
 	client := h.Gw.initialiseClient()
+	timings := h.Gw.dashboardTimings()
 
+	failures := heartBeatFailures{
+		threshold: timings.heartBeatFailureThreshold,
+		job:       instrument.NewJob("DashboardHeartBeat"),
+		host:      h.Gw.hostDetails.Hostname,
+	}
 	for {
 		select {
 		case <-ctx.Done():
@@ -313,12 +415,66 @@ func (h *HTTPDashboardHandler) StartBeating(ctx context.Context) error {
 			}
 			if err := h.sendHeartBeat(req, client, ctx); err != nil {
 				dashLog.Warning(err)
+				failures.failed(time.Now())
+			} else {
+				failures.succeeded(time.Now())
+			}
+
+			if !sleepContext(ctx, timings.withJitter(timings.heartBeatInterval)) {
+				dashLog.Info("Heartbeat stopped due to context cancellation")
+				return nil
 			}
-			time.Sleep(time.Second * 2)
 		}
 	}
 }
 
+// heartBeatFailures tracks for how long the heartbeats have been failing. Once it's longer than
+// the threshold, an error is logged and the heartbeat_failing_seconds gauge is reported until
+// the heartbeats recover.
+type heartBeatFailures struct {
+	threshold time.Duration
+	job       *health.Job
+	host      string
+
+	since    time.Time
+	reported bool
+}
+
+func (f *heartBeatFailures) failed(now time.Time) {
+	if f.since.IsZero() {
+		f.since = now
+	}
+
+	failing := now.Sub(f.since)
+	if failing < f.threshold {
+		return
+	}
+
+	if !f.reported {
+		dashLog.Errorf("Heartbeat has been failing for %s", failing.Round(time.Second))
+		f.reported = true
+	}
+
+	f.gauge(failing)
+}
+
+func (f *heartBeatFailures) succeeded(now time.Time) {
+	if f.since.IsZero() {
+		return
+	}
+
+	dashLog.Infof("Heartbeat recovered after failing for %s", now.Sub(f.since).Round(time.Second))
+	if f.reported {
+		f.gauge(0)
+	}
+
+	f.since, f.reported = time.Time{}, false
+}
+
+func (f *heartBeatFailures) gauge(failing time.Duration) {
+	f.job.GaugeKv("heartbeat_failing_seconds", failing.Seconds(), health.Kvs{"host": f.host})
+}
+
 func (h *HTTPDashboardHandler) StopBeating() {
 	atomic.SwapInt32(&h.heartBeatStopSentinel, HeartBeatStopped)
 }
@@ -343,7 +499,7 @@ func (h *HTTPDashboardHandler) newRequestWithContext(ctx context.Context, method
 }
 
 func (h *HTTPDashboardHandler) addHeaderToRequest(req *http.Request) {
-	req.Header.Set("authorization", h.Secret)
+	req.Header.Set("authorization", h.getSecret())
 	req.Header.Set(header.XTykHostname, h.Gw.hostDetails.Hostname)
 	req.Header.Set(header.XTykSessionID, h.Gw.SessionID)
 }
@@ -359,13 +515,29 @@ func (h *HTTPDashboardHandler) sendHeartBeat(req *http.Request, client *http.Cli
 	return err
 }
 
+// SetSecret replaces the node secret sent to the dashboard, e.g. when it's rotated in a KV store.
+func (h *HTTPDashboardHandler) SetSecret(secret string) {
+	h.secretMu.Lock()
+	defer h.secretMu.Unlock()
+	h.Secret = secret
+}
+
+func (h *HTTPDashboardHandler) getSecret() string {
+	h.secretMu.RLock()
+	defer h.secretMu.RUnlock()
+	return h.Secret
+}
+
 func (h *HTTPDashboardHandler) doHeartBeat(req *http.Request, client *http.Client) error {
-	req.Header.Set(header.XTykNodeID, h.Gw.GetNodeID())
-	h.Gw.ServiceNonceMutex.RLock()
-	req.Header.Set(header.XTykNonce, h.Gw.ServiceNonce)
-	h.Gw.ServiceNonceMutex.RUnlock()
+	buildReq := func() (*http.Request, error) {
+		// The heartbeat request is reused, the node secret may have been rotated since it was built.
+		req.Header.Set("authorization", h.getSecret())
+		req.Header.Set(header.XTykNodeID, h.Gw.GetNodeID())
+		req.Header.Set(header.XTykNonce, h.Gw.getServiceNonce())
+		return req, nil
+	}
 
-	resp, err := client.Do(req)
+	resp, err := h.Gw.doDashboardNonceRequest(buildReq, client, "heartbeat")
 	if err != nil {
 		return errors.New("dashboard is down? Heartbeat is failing")
 	}
@@ -380,30 +552,18 @@ func (h *HTTPDashboardHandler) doHeartBeat(req *http.Request, client *http.Clien
 		return errors.New("dashboard is down? Heartbeat non-200 response")
 	}
 	val := NodeResponse{}
-	if err := json.NewDecoder(resp.Body).Decode(&val); err != nil {
-		return err
-	}
-
-	// Set the nonce
-	h.Gw.ServiceNonceMutex.Lock()
-	h.Gw.ServiceNonce = val.Nonce
-	h.Gw.ServiceNonceMutex.Unlock()
-	//log.Debug("Heartbeat Finished: Nonce Set: ", h.Gw.ServiceNonce)
-
-	return nil
+	return json.NewDecoder(resp.Body).Decode(&val)
 }
 
 func (h *HTTPDashboardHandler) DeRegister() error {
-	req := h.newRequest(http.MethodDelete, h.DeRegistrationEndpoint)
-
-	req.Header.Set(header.XTykNodeID, h.Gw.GetNodeID())
-	h.Gw.ServiceNonceMutex.RLock()
-	req.Header.Set(header.XTykNonce, h.Gw.ServiceNonce)
-	h.Gw.ServiceNonceMutex.RUnlock()
-
-	c := h.Gw.initialiseClient()
-	resp, err := c.Do(req)
+	buildReq := func() (*http.Request, error) {
+		req := h.newRequest(http.MethodDelete, h.DeRegistrationEndpoint)
+		req.Header.Set(header.XTykNodeID, h.Gw.GetNodeID())
+		req.Header.Set(header.XTykNonce, h.Gw.getServiceNonce())
+		return req, nil
+	}
 
+	resp, err := h.Gw.doDashboardNonceRequest(buildReq, h.Gw.initialiseClient(), "deregistration")
 	if err != nil {
 		return fmt.Errorf("deregister request failed with error %w", err)
 	}
@@ -417,10 +577,6 @@ func (h *HTTPDashboardHandler) DeRegister() error {
 		return err
 	}
 
-	// Set the nonce
-	h.Gw.ServiceNonceMutex.Lock()
-	h.Gw.ServiceNonce = val.Nonce
-	h.Gw.ServiceNonceMutex.Unlock()
 	dashLog.Info("De-registered.")
 
 	return nil
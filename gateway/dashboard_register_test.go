@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/gocraft/health"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -485,3 +487,198 @@ func Test_DashboardLifecycle(t *testing.T) {
 	handler.StopBeating()
 	assert.True(t, handler.isHeartBeatStopped())
 }
+
+func Test_dashboardTimings(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		var gw Gateway
+		gw.SetConfig(config.Config{})
+
+		timings := gw.dashboardTimings()
+		assert.Equal(t, defaultHeartBeatInterval, timings.heartBeatInterval)
+		assert.Equal(t, defaultRegistrationRetryDelay, timings.registrationRetryDelay(1))
+		assert.Equal(t, defaultRegistrationRetryMaxDelay, timings.registrationRetryDelay(100))
+		assert.Equal(t, defaultHeartBeatFailureThreshold, timings.heartBeatFailureThreshold)
+	})
+
+	t.Run("exponential backoff with cap", func(t *testing.T) {
+		timings := dashboardTimings{retryDelay: time.Second, retryMaxDelay: 5 * time.Second}
+
+		var delays []time.Duration
+		for failures := 1; failures <= 5; failures++ {
+			delays = append(delays, timings.registrationRetryDelay(failures))
+		}
+
+		assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}, delays)
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		timings := dashboardTimings{jitter: 0.2}
+
+		seen := map[time.Duration]bool{}
+		for i := 0; i < 100; i++ {
+			delay := timings.withJitter(10 * time.Second)
+			assert.GreaterOrEqual(t, delay, 8*time.Second)
+			assert.LessOrEqual(t, delay, 12*time.Second)
+			seen[delay] = true
+		}
+
+		assert.Greater(t, len(seen), 1, "delays should be spread")
+	})
+}
+
+// flakyDashboard fails the first failures calls to path with a 503 and records the time of every call.
+type flakyDashboard struct {
+	t        *testing.T
+	failures int32
+
+	mu    sync.Mutex
+	calls []time.Time
+}
+
+func (d *flakyDashboard) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	d.mu.Lock()
+	d.calls = append(d.calls, time.Now())
+	call := int32(len(d.calls))
+	d.mu.Unlock()
+
+	if call <= atomic.LoadInt32(&d.failures) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	writeJSON(d.t, w, okResponse("node-after-backoff", "nonce-after-backoff"))
+}
+
+// gaps returns the time elapsed between the recorded calls.
+func (d *flakyDashboard) gaps() []time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var gaps []time.Duration
+	for i := 1; i < len(d.calls); i++ {
+		gaps = append(gaps, d.calls[i].Sub(d.calls[i-1]))
+	}
+	return gaps
+}
+
+func setDashboardTimings(h *HTTPDashboardHandler, fn func(*config.DBAppConfOptionsConfig)) {
+	conf := h.Gw.GetConfig()
+	fn(&conf.DBAppConfOptions)
+	h.Gw.SetConfig(conf)
+}
+
+// TestRegister_Backoff verifies the delay between registration attempts doubles up to the maximum delay.
+func TestRegister_Backoff(t *testing.T) {
+	dashboard := &flakyDashboard{t: t, failures: 4}
+	srv := httptest.NewServer(dashboard)
+	defer srv.Close()
+
+	h, closeFn := newTestDashboardHandler(t, srv.URL)
+	defer closeFn()
+	setDashboardTimings(h, func(conf *config.DBAppConfOptionsConfig) {
+		conf.RegistrationRetryDelay = 0.05
+		conf.RegistrationRetryMaxDelay = 0.2
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	require.NoError(t, h.Register(ctx))
+	assert.Equal(t, "node-after-backoff", h.Gw.GetNodeID())
+
+	gaps := dashboard.gaps()
+	require.Len(t, gaps, 4)
+
+	// 50ms, 100ms, 200ms and capped to 200ms
+	for i, want := range []time.Duration{50, 100, 200, 200} {
+		assert.GreaterOrEqual(t, gaps[i], want*time.Millisecond, "gap %d", i)
+	}
+	assert.Greater(t, gaps[2], gaps[0]+100*time.Millisecond, "backoff should grow")
+	assert.Less(t, gaps[3], 400*time.Millisecond, "backoff should be capped")
+}
+
+// TestRegister_BackoffCancelled verifies the registration stops waiting once the context is cancelled.
+func TestRegister_BackoffCancelled(t *testing.T) {
+	dashboard := &flakyDashboard{t: t, failures: 100}
+	srv := httptest.NewServer(dashboard)
+	defer srv.Close()
+
+	h, closeFn := newTestDashboardHandler(t, srv.URL)
+	defer closeFn()
+	setDashboardTimings(h, func(conf *config.DBAppConfOptionsConfig) {
+		conf.RegistrationRetryDelay = 3600
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	assert.ErrorIs(t, h.Register(ctx), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+// TestStartBeating_Jitter verifies the heartbeats are spread around the interval and stop with the context.
+func TestStartBeating_Jitter(t *testing.T) {
+	dashboard := &flakyDashboard{t: t}
+	srv := httptest.NewServer(dashboard)
+	defer srv.Close()
+
+	h, closeFn := newTestDashboardHandler(t, srv.URL)
+	defer closeFn()
+	h.HeartBeatEndpoint = srv.URL + "/register/ping"
+	setDashboardTimings(h, func(conf *config.DBAppConfOptionsConfig) {
+		conf.HeartBeatInterval = 0.05
+		conf.Jitter = 0.5
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- h.StartBeating(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		return len(dashboard.gaps()) >= 10
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("heartbeat didn't stop with the context")
+	}
+
+	gaps := dashboard.gaps()
+	shortest, longest := gaps[0], gaps[0]
+	for _, gap := range gaps {
+		assert.GreaterOrEqual(t, gap, 25*time.Millisecond)
+		shortest, longest = min(shortest, gap), max(longest, gap)
+	}
+	assert.Greater(t, longest-shortest, 5*time.Millisecond, "heartbeats should be jittered")
+}
+
+func TestHeartBeatFailures(t *testing.T) {
+	sink := &gaugeSink{gauges: map[string]float64{}, cache: map[string]string{}}
+	stream := health.NewStream()
+	stream.AddSink(sink)
+
+	failures := heartBeatFailures{threshold: 30 * time.Second, job: stream.NewJob("DashboardHeartBeat")}
+	const gauge = "DashboardHeartBeat.heartbeat_failing_seconds"
+
+	start := time.Now()
+	failures.failed(start)
+	failures.failed(start.Add(20 * time.Second))
+	assert.NotContains(t, sink.gauges, gauge, "failures below the threshold aren't reported")
+
+	failures.failed(start.Add(40 * time.Second))
+	assert.Equal(t, 40.0, sink.gauges[gauge])
+
+	failures.succeeded(start.Add(42 * time.Second))
+	assert.Equal(t, 0.0, sink.gauges[gauge])
+
+	failures.failed(start.Add(50 * time.Second))
+	failures.failed(start.Add(70 * time.Second))
+	assert.Equal(t, 0.0, sink.gauges[gauge], "the failure period restarts after a success")
+}
@@ -1,14 +1,78 @@
 package gateway
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/TykTechnologies/tyk/storage"
 )
 
+const (
+	defaultCacheInvalidationScanCount = 100
+	defaultCacheInvalidationScanLimit = 10000
+)
+
+// cachePatternTypeRegex selects regular expressions for the paths of cached responses to invalidate, instead of globs.
+const cachePatternTypeRegex = "regex"
+
+var errCachePatternType = errors.New("pattern_type must be either glob or regex")
+
 func (gw *Gateway) invalidateAPICache(apiID string) bool {
 	store := storage.RedisCluster{IsCache: true, ConnectionHandler: gw.StorageConnectionHandler}
 	store.Connect()
 
 	return store.DeleteScanMatch(fmt.Sprintf("cache-%s*", apiID))
 }
+
+// invalidateAPICacheByPath removes the cached responses of the API for the paths matching match.
+// It returns the number of removed entries and whether the scan stopped at the configured limit.
+func (gw *Gateway) invalidateAPICacheByPath(apiID string, match func(path string) bool) (int64, bool, error) {
+	store := storage.RedisCluster{IsCache: true, ConnectionHandler: gw.StorageConnectionHandler}
+	store.Connect()
+
+	conf := gw.GetConfig().CacheInvalidation
+	count := conf.ScanCount
+	if count <= 0 {
+		count = defaultCacheInvalidationScanCount
+	}
+	limit := conf.ScanLimit
+	if limit <= 0 {
+		limit = defaultCacheInvalidationScanLimit
+	}
+
+	// keys are prefixed by the cache store of the API, see processSpec
+	prefix := "cache-" + apiID + apiID + cacheKeyPathSeparator
+
+	return store.DeleteScanMatchFunc(escapeScanPattern(prefix)+"*", count, limit, func(key string) bool {
+		path, _, ok := strings.Cut(strings.TrimPrefix(key, prefix), cacheKeyPathSeparator)
+		return ok && match(path)
+	})
+}
+
+// cachePathMatcher returns a matcher of cached paths for a glob, where `*` matches any characters
+// and `?` a single character, or for a regular expression when patternType is regex.
+func cachePathMatcher(pattern, patternType string) (func(path string) bool, error) {
+	switch patternType {
+	case "", "glob":
+		expr := regexp.QuoteMeta(pattern)
+		expr = strings.ReplaceAll(expr, `\*`, ".*")
+		expr = strings.ReplaceAll(expr, `\?`, ".")
+		pattern = "^" + expr + "$"
+	case cachePatternTypeRegex:
+	default:
+		return nil, errCachePatternType
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString, nil
+}
+
+// escapeScanPattern escapes the special characters of Redis SCAN patterns.
+func escapeScanPattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`).Replace(s)
+}
@@ -0,0 +1,48 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachePathMatcher(t *testing.T) {
+	t.Run("glob", func(t *testing.T) {
+		match, err := cachePathMatcher("/products/12?/*", "")
+		require.NoError(t, err)
+
+		assert.True(t, match("/products/123/reviews"))
+		assert.True(t, match("/products/124/reviews/1"))
+		assert.False(t, match("/products/1234/reviews"))
+		assert.False(t, match("/v1/products/123/reviews"))
+	})
+
+	t.Run("glob quotes regular expression characters", func(t *testing.T) {
+		match, err := cachePathMatcher("/search.json", "glob")
+		require.NoError(t, err)
+
+		assert.True(t, match("/search.json"))
+		assert.False(t, match("/search-json"))
+	})
+
+	t.Run("regex", func(t *testing.T) {
+		match, err := cachePathMatcher(`^/products/\d+$`, cachePatternTypeRegex)
+		require.NoError(t, err)
+
+		assert.True(t, match("/products/123"))
+		assert.False(t, match("/products/abc"))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := cachePathMatcher("(", cachePatternTypeRegex)
+		assert.Error(t, err)
+
+		_, err = cachePathMatcher("/products", "prefix")
+		assert.ErrorIs(t, err, errCachePatternType)
+	})
+}
+
+func TestEscapeScanPattern(t *testing.T) {
+	assert.Equal(t, `cache-a\*b\?c\[d\]\\`, escapeScanPattern(`cache-a*b?c[d]\`))
+}
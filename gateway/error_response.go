@@ -84,6 +84,25 @@ func escapeTemplateString(s string, isXML bool) htmltemplate.HTML {
 	return htmltemplate.HTML(htmltemplate.JSEscapeString(s))
 }
 
+// escapeTemplateValue escapes the strings of an error classification template value, see escapeTemplateString.
+func escapeTemplateValue(v any, isXML bool) any {
+	switch v := v.(type) {
+	case string:
+		return escapeTemplateString(v, isXML)
+	case []jsonSchemaError:
+		escaped := make([]map[string]htmltemplate.HTML, 0, len(v))
+		for _, schemaErr := range v {
+			escaped = append(escaped, map[string]htmltemplate.HTML{
+				"Pointer": escapeTemplateString(schemaErr.Pointer, isXML),
+				"Message": escapeTemplateString(schemaErr.Message, isXML),
+			})
+		}
+		return escaped
+	default:
+		return v
+	}
+}
+
 // ExecuteErrorTemplate executes a template and captures output for analytics.
 // Uses io.MultiWriter to write to both the response and a buffer for recording.
 func (e *ErrorHandler) ExecuteErrorTemplate(w http.ResponseWriter, tmpl TemplateExecutor, data any, errCode int) *http.Response {
@@ -0,0 +1,147 @@
+package gateway
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	tykctx "github.com/TykTechnologies/tyk/ctx"
+	"github.com/TykTechnologies/tyk/header"
+)
+
+// apiErrorTemplate holds the compiled error templates of an API for a status code.
+type apiErrorTemplate struct {
+	json *texttemplate.Template
+	xml  *texttemplate.Template
+}
+
+// compileErrorTemplates compiles the error templates of an API, keyed as in the API definition.
+func compileErrorTemplates(templates map[string]apidef.ErrorTemplate) (map[string]apiErrorTemplate, error) {
+	if len(templates) == 0 {
+		return nil, nil
+	}
+
+	compiled := make(map[string]apiErrorTemplate, len(templates))
+	for key, tmpl := range templates {
+		var (
+			errTmpl apiErrorTemplate
+			err     error
+		)
+
+		if tmpl.JSON != "" {
+			if errTmpl.json, err = texttemplate.New(key + ".json").Parse(tmpl.JSON); err != nil {
+				return nil, err
+			}
+		}
+
+		if tmpl.XML != "" {
+			if errTmpl.xml, err = texttemplate.New(key + ".xml").Parse(tmpl.XML); err != nil {
+				return nil, err
+			}
+		}
+
+		compiled[key] = errTmpl
+	}
+
+	return compiled, nil
+}
+
+// errorTemplate returns the error template of the API for a status code and format,
+// or nil if the gateway error templates should be used.
+func (s *APISpec) errorTemplate(code int, isXML bool) *texttemplate.Template {
+	for _, key := range []string{strconv.Itoa(code), apidef.ErrorTemplateDefault} {
+		tmpl := s.errorTemplates[key].json
+		if isXML {
+			tmpl = s.errorTemplates[key].xml
+		}
+
+		if tmpl != nil {
+			return tmpl
+		}
+	}
+
+	return nil
+}
+
+// apiErrorTemplateData returns the data API error templates are executed with.
+func (e *ErrorHandler) apiErrorTemplateData(r *http.Request, errMsg string, errCode int, isXML bool) map[string]any {
+	requestID := ctxGetRequestID(r)
+	if requestID == "" {
+		requestID, _ = ctxGetData(r)["request_id"].(string)
+	}
+	if requestID == "" {
+		requestID = r.Header.Get(header.XRequestID)
+	}
+
+	data := map[string]any{
+		"Message":    escapeTemplateString(errMsg, isXML),
+		"StatusCode": errCode,
+		"RequestID":  escapeTemplateString(requestID, isXML),
+		"APIID":      escapeTemplateString(e.Spec.APIID, isXML),
+		"APIName":    escapeTemplateString(e.Spec.Name, isXML),
+	}
+
+	// The data of the error classification, e.g. the failures of request validation, is also exposed.
+	if ec := tykctx.GetErrorClassification(r); ec != nil {
+		for k, v := range ec.TemplateData {
+			if _, ok := data[k]; !ok {
+				data[k] = escapeTemplateValue(v, isXML)
+			}
+		}
+	}
+
+	return data
+}
+
+// errorResponseContentType returns the content type of an error response. It is negotiated from the
+// Accept header of the request, falling back to the request content type when it accepts neither JSON nor XML.
+func errorResponseContentType(r *http.Request) string {
+	if contentType := negotiateErrorContentType(r.Header.Get(header.Accept)); contentType != "" {
+		return contentType
+	}
+
+	contentType := strings.Split(r.Header.Get(header.ContentType), ";")[0]
+	switch contentType {
+	case header.ApplicationSoapXML, header.ApplicationXML, header.TextXML:
+		return contentType
+	default:
+		return header.ApplicationJSON
+	}
+}
+
+// negotiateErrorContentType returns the JSON or XML content type with the highest quality in an Accept header.
+func negotiateErrorContentType(accept string) string {
+	var (
+		best        string
+		bestQuality float64
+	)
+
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+
+		switch mediaType {
+		case header.ApplicationJSON, header.ApplicationXML, header.TextXML, header.ApplicationSoapXML:
+		default:
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = mediaType, quality
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,148 @@
+package gateway
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestErrorTemplates(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	errorTemplates := map[string]apidef.ErrorTemplate{
+		"404": {
+			JSON: `{"code":{{.StatusCode}},"api":"{{.APIName}}","request":"{{.RequestID}}","error":"{{.Message}}"}`,
+			XML:  `<error code="{{.StatusCode}}" api="{{.APIName}}" request="{{.RequestID}}">{{.Message}}</error>`,
+		},
+		apidef.ErrorTemplateDefault: {
+			JSON: `{"brand":"acme","code":{{.StatusCode}},"error":"{{.Message}}"}`,
+			XML:  `<acme code="{{.StatusCode}}">{{.Message}}</acme>`,
+		},
+	}
+
+	loadAPIs := func(errorTemplates map[string]apidef.ErrorTemplate) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.APIID = "versioned"
+			spec.Name = "Payments"
+			spec.Proxy.ListenPath = "/versioned/"
+			spec.ErrorTemplates = errorTemplates
+			spec.VersionDefinition.Enabled = true
+			spec.VersionDefinition.Default = apidef.Self
+			spec.VersionDefinition.Location = apidef.URLParamLocation
+			spec.VersionDefinition.Key = "version"
+			spec.VersionDefinition.Versions = map[string]string{"v2": "missing"}
+		}, func(spec *APISpec) {
+			spec.APIID = "limited"
+			spec.Name = "Ledger"
+			spec.Proxy.ListenPath = "/limited/"
+			spec.ErrorTemplates = errorTemplates
+			spec.GlobalRateLimit = apidef.GlobalRateLimit{Rate: 1, Per: 60}
+		}, func(spec *APISpec) {
+			spec.APIID = "plain"
+			spec.Proxy.ListenPath = "/plain/"
+			spec.GlobalRateLimit = apidef.GlobalRateLimit{Rate: 1, Per: 60}
+		})
+	}
+
+	loadAPIs(errorTemplates)
+
+	acceptJSON := map[string]string{header.Accept: header.ApplicationJSON, header.XRequestID: "req-1"}
+	acceptXML := map[string]string{header.Accept: "application/json;q=0.5, application/xml", header.XRequestID: "req-2"}
+
+	t.Run("404", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{
+				Path:         "/versioned/?version=notFound",
+				Headers:      acceptJSON,
+				Code:         http.StatusNotFound,
+				BodyMatch:    `^{"code":404,"api":"Payments","request":"req-1","error":"This API version does not seem to exist"}$`,
+				HeadersMatch: map[string]string{header.ContentType: header.ApplicationJSON},
+			},
+			{
+				Path:         "/versioned/?version=notFound",
+				Headers:      acceptXML,
+				Code:         http.StatusNotFound,
+				BodyMatch:    `^<error code="404" api="Payments" request="req-2">This API version does not seem to exist</error>$`,
+				HeadersMatch: map[string]string{header.ContentType: header.ApplicationXML},
+			},
+		}...)
+	})
+
+	t.Run("429", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/limited/", Code: http.StatusOK},
+			{
+				Path:         "/limited/",
+				Headers:      acceptJSON,
+				Code:         http.StatusTooManyRequests,
+				BodyMatch:    `^{"brand":"acme","code":429,"error":"API Rate Limit Exceeded"}$`,
+				HeadersMatch: map[string]string{header.ContentType: header.ApplicationJSON},
+			},
+			{
+				Path:         "/limited/",
+				Headers:      acceptXML,
+				Code:         http.StatusTooManyRequests,
+				BodyMatch:    `^<acme code="429">API Rate Limit Exceeded</acme>$`,
+				HeadersMatch: map[string]string{header.ContentType: header.ApplicationXML},
+			},
+		}...)
+	})
+
+	t.Run("gateway templates without API templates", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/plain/", Code: http.StatusOK},
+			{
+				Path:         "/plain/",
+				Headers:      acceptXML,
+				Code:         http.StatusTooManyRequests,
+				BodyMatch:    `<error>`,
+				HeadersMatch: map[string]string{header.ContentType: header.ApplicationXML},
+			},
+		}...)
+	})
+
+	t.Run("reload picks up changed templates", func(t *testing.T) {
+		loadAPIs(map[string]apidef.ErrorTemplate{
+			"404": {JSON: `{"missing":"{{.Message}}"}`},
+		})
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/versioned/?version=notFound", Headers: acceptJSON, Code: http.StatusNotFound, BodyMatch: `^{"missing":"This API version does not seem to exist"}$`},
+			{Path: "/versioned/?version=notFound", Headers: acceptXML, Code: http.StatusNotFound, BodyMatch: `<error>`},
+		}...)
+	})
+
+	t.Run("invalid templates", func(t *testing.T) {
+		specs := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.ErrorTemplates = map[string]apidef.ErrorTemplate{"404": {JSON: `{{.Message`}}
+		})
+
+		assert.Nil(t, specs[0], "API with an invalid error template should not be loaded")
+	})
+}
+
+func TestNegotiateErrorContentType(t *testing.T) {
+	testCases := []struct {
+		accept   string
+		expected string
+	}{
+		{accept: "", expected: ""},
+		{accept: "*/*", expected: ""},
+		{accept: "text/html, application/xml", expected: header.ApplicationXML},
+		{accept: "application/xml;q=0.8, application/json", expected: header.ApplicationJSON},
+		{accept: "application/json;q=0, text/xml", expected: header.TextXML},
+		{accept: "application/soap+xml", expected: header.ApplicationSoapXML},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.accept, func(t *testing.T) {
+			assert.Equal(t, tc.expected, negotiateErrorContentType(tc.accept))
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package gateway
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/crypto"
+	"github.com/TykTechnologies/tyk/internal/httpclient"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+const (
+	// defaultChatWebhookCooldown is the cool-down in seconds used when event_timeout isn't set.
+	defaultChatWebhookCooldown = 60
+
+	chatWebhookCooldownPrefix = "chat-webhook.cooldown."
+)
+
+var (
+	// ErrChatWebhookInvalidURL is returned when the target path of a chat webhook isn't an absolute HTTP(S) URL.
+	ErrChatWebhookInvalidURL = errors.New("chat webhook target_path must be an absolute http or https URL")
+
+	// ErrChatWebhookUnknownFormat is returned when the format of a chat webhook isn't supported.
+	ErrChatWebhookUnknownFormat = errors.New("chat webhook format must be slack or teams")
+)
+
+// ChatWebhookHandler is an event handler that posts events to a Slack or Microsoft Teams channel.
+// The same event of an API is posted at most once per cool-down, so a flapping circuit breaker
+// doesn't flood the channel.
+type ChatWebhookHandler struct {
+	conf  apidef.ChatWebhookHandlerConf
+	store storage.Handler
+
+	// Spec is the API the handler is configured on, nil for gateway level handlers.
+	Spec *APISpec
+	Gw   *Gateway `json:"-"`
+}
+
+// Init enables the init of event handler instances when they are created on ApiSpec creation
+func (c *ChatWebhookHandler) Init(handlerConf any) error {
+	if err := c.conf.Scan(handlerConf); err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix": "chat_webhooks",
+		}).Error("Problem getting configuration, skipping. ", err)
+		return err
+	}
+
+	if c.conf.Disabled {
+		log.WithFields(logrus.Fields{
+			"prefix": "chat_webhooks",
+		}).Infof("skipping disabled chat webhook %s", c.conf.Name)
+		return ErrEventHandlerDisabled
+	}
+
+	switch c.conf.Format {
+	case "":
+		c.conf.Format = apidef.ChatWebhookFormatSlack
+	case apidef.ChatWebhookFormatSlack, apidef.ChatWebhookFormatTeams:
+	default:
+		return ErrChatWebhookUnknownFormat
+	}
+
+	target, err := url.ParseRequestURI(c.conf.TargetPath)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return ErrChatWebhookInvalidURL
+	}
+
+	if c.conf.EventTimeout <= 0 {
+		c.conf.EventTimeout = defaultChatWebhookCooldown
+	}
+
+	c.store = &storage.RedisCluster{ConnectionHandler: c.Gw.StorageConnectionHandler}
+	c.store.Connect()
+
+	return nil
+}
+
+// HandleEvent will be fired when the event handler instance is found in an APISpec EventPaths object during a request chain
+func (c *ChatWebhookHandler) HandleEvent(em config.EventMessage) {
+	details := c.eventDetails(em)
+
+	if c.store.IncrememntWithExpire(c.cooldownKey(em.Type, details.apiID), c.conf.EventTimeout) != 1 {
+		log.WithFields(logrus.Fields{
+			"prefix": "chat_webhooks",
+			"event":  em.Type,
+			"api_id": details.apiID,
+		}).Debug("Event is cooling down, not posting it")
+		return
+	}
+
+	var payload any
+	if c.conf.Format == apidef.ChatWebhookFormatTeams {
+		payload = details.teamsMessage()
+	} else {
+		payload = details.slackMessage()
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.WithError(err).WithFields(logrus.Fields{
+			"prefix": "chat_webhooks",
+		}).Error("Failed to encode chat webhook payload")
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.conf.TargetPath, bytes.NewReader(body))
+	if err != nil {
+		log.WithError(err).WithFields(logrus.Fields{
+			"prefix": "chat_webhooks",
+		}).Error("Failed to create request object")
+		return
+	}
+
+	req.Header.Set(header.UserAgent, header.TykHookshot)
+	req.Header.Set(header.ContentType, header.ApplicationJSON)
+
+	cli, err := NewExternalHTTPClientFactory(c.Gw).CreateWebhookClient()
+	if err != nil {
+		if c.Gw.GetConfig().ExternalServices.Webhooks.MTLS.Enabled && httpclient.IsMTLSError(err) {
+			log.WithError(err).Error("mTLS configuration failed for webhooks. Chat webhook delivery will be skipped to maintain security.")
+			return
+		}
+
+		log.WithError(err).Debug("Failed to create webhook HTTP client, falling back to default")
+		cli = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	resp, err := cli.Do(req)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix": "chat_webhooks",
+		}).Error("Chat webhook request failed: ", err)
+		return
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		log.WithFields(logrus.Fields{
+			"prefix":       "chat_webhooks",
+			"responseCode": resp.StatusCode,
+		}).Error("Request to chat webhook failed")
+	}
+}
+
+// cooldownKey is the storage key tracking the cool-down of an event for an API on the target channel.
+func (c *ChatWebhookHandler) cooldownKey(eventType apidef.TykEvent, apiID string) string {
+	target := sha256.Sum256([]byte(c.conf.TargetPath))
+	return fmt.Sprintf("%s%s.%s.%s", chatWebhookCooldownPrefix, hex.EncodeToString(target[:8]), eventType, apiID)
+}
+
+// chatEventDetails holds the event details posted to the channel.
+type chatEventDetails struct {
+	eventType apidef.TykEvent
+	apiID     string
+	apiName   string
+	keyHash   string
+	message   string
+	timestamp string
+}
+
+func (c *ChatWebhookHandler) eventDetails(em config.EventMessage) chatEventDetails {
+	details := chatEventDetails{
+		eventType: em.Type,
+		timestamp: templateFuncAsRFC3339FromString(log)(em.TimeStamp),
+	}
+
+	var key string
+	switch meta := em.Meta.(type) {
+	case EventKeyFailureMeta:
+		details.message, key = meta.Message, meta.Key
+	case EventVersionFailureMeta:
+		details.message, key = meta.Message, meta.Key
+	case EventTokenMeta:
+		details.message, key = meta.Message, meta.Key
+	case EventTriggerExceededMeta:
+		details.message, key = meta.Message, meta.Key
+	case EventCurcuitBreakerMeta:
+		details.message, details.apiID = meta.Message, meta.APIID
+	case EventRefreshTokenReusedMeta:
+		details.message, details.apiID = meta.Message, meta.APIID
+	case EventMetaDefault:
+		details.message = meta.Message
+	}
+
+	if c.Spec != nil {
+		details.apiID, details.apiName = c.Spec.APIID, c.Spec.Name
+	}
+
+	if key != "" {
+		details.keyHash = c.keyHash(key)
+	}
+
+	return details
+}
+
+// keyHash identifies the key of the event without posting the key itself.
+func (c *ChatWebhookHandler) keyHash(key string) string {
+	if c.Gw.GetConfig().HashKeys {
+		return crypto.HashStr(key)
+	}
+
+	return c.Gw.obfuscateKey(key)
+}
+
+func (d chatEventDetails) title() string {
+	return "Tyk event: " + string(d.eventType)
+}
+
+// chatFact is an event detail posted to the channel.
+type chatFact struct {
+	name  string
+	value string
+}
+
+// facts lists the event details, skipping the ones the event doesn't carry.
+func (d chatEventDetails) facts() []chatFact {
+	facts := []chatFact{}
+	for _, fact := range []chatFact{
+		{"API", d.apiName},
+		{"API ID", d.apiID},
+		{"Event", string(d.eventType)},
+		{"Key", d.keyHash},
+		{"Time", d.timestamp},
+	} {
+		if fact.value != "" {
+			facts = append(facts, fact)
+		}
+	}
+
+	return facts
+}
+
+type slackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type   string      `json:"type"`
+	Text   *slackText  `json:"text,omitempty"`
+	Fields []slackText `json:"fields,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (d chatEventDetails) slackMessage() slackMessage {
+	fields := []slackText{}
+	for _, fact := range d.facts() {
+		fields = append(fields, slackText{Type: "mrkdwn", Text: fmt.Sprintf("*%s*\n%s", fact.name, fact.value)})
+	}
+
+	msg := slackMessage{
+		Text: d.title(),
+		Blocks: []slackBlock{
+			{Type: "header", Text: &slackText{Type: "plain_text", Text: d.title()}},
+			{Type: "section", Fields: fields},
+		},
+	}
+
+	if d.message != "" {
+		msg.Blocks = append(msg.Blocks, slackBlock{Type: "section", Text: &slackText{Type: "plain_text", Text: d.message}})
+	}
+
+	return msg
+}
+
+type teamsMessageCard struct {
+	Type     string         `json:"@type"`
+	Context  string         `json:"@context"`
+	Summary  string         `json:"summary"`
+	Title    string         `json:"title"`
+	Text     string         `json:"text,omitempty"`
+	Sections []teamsSection `json:"sections"`
+}
+
+type teamsSection struct {
+	Facts []teamsFact `json:"facts"`
+}
+
+type teamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func (d chatEventDetails) teamsMessage() teamsMessageCard {
+	facts := []teamsFact{}
+	for _, fact := range d.facts() {
+		facts = append(facts, teamsFact{Name: fact.name, Value: fact.value})
+	}
+
+	return teamsMessageCard{
+		Type:     "MessageCard",
+		Context:  "https://schema.org/extensions",
+		Summary:  d.title(),
+		Title:    d.title(),
+		Text:     d.message,
+		Sections: []teamsSection{{Facts: facts}},
+	}
+}
@@ -0,0 +1,247 @@
+package gateway
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/event"
+)
+
+// chatWebhookServer captures the bodies posted to a chat webhook.
+type chatWebhookServer struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	bodies [][]byte
+}
+
+func newChatWebhookServer(t *testing.T) *chatWebhookServer {
+	t.Helper()
+
+	s := &chatWebhookServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, header.ApplicationJSON, r.Header.Get(header.ContentType))
+
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		s.mu.Lock()
+		s.bodies = append(s.bodies, body)
+		s.mu.Unlock()
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *chatWebhookServer) posted() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([][]byte{}, s.bodies...)
+}
+
+func (ts *Test) createChatWebhookHandler(t *testing.T, spec *APISpec, meta map[string]any) *ChatWebhookHandler {
+	t.Helper()
+
+	h, err := ts.Gw.EventHandlerByName(apidef.EventHandlerTriggerConfig{
+		Handler:     event.ChatWebhookHandler,
+		HandlerMeta: meta,
+	}, spec)
+	require.NoError(t, err)
+
+	return h.(*ChatWebhookHandler)
+}
+
+func TestChatWebhookHandler_Init(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	testCases := []struct {
+		name   string
+		conf   map[string]any
+		err    error
+		format string
+	}{
+		{
+			name:   "slack by default",
+			conf:   map[string]any{"target_path": "https://hooks.slack.com/services/T0/B0/secret"},
+			format: apidef.ChatWebhookFormatSlack,
+		},
+		{
+			name:   "teams",
+			conf:   map[string]any{"target_path": "https://example.webhook.office.com/webhookb2/secret", "format": "teams"},
+			format: apidef.ChatWebhookFormatTeams,
+		},
+		{
+			name: "disabled",
+			conf: map[string]any{"target_path": "https://hooks.slack.com/services/T0/B0/secret", "disabled": true},
+			err:  ErrEventHandlerDisabled,
+		},
+		{
+			name: "unknown format",
+			conf: map[string]any{"target_path": "https://hooks.slack.com/services/T0/B0/secret", "format": "irc"},
+			err:  ErrChatWebhookUnknownFormat,
+		},
+		{
+			name: "relative target",
+			conf: map[string]any{"target_path": "/services/T0/B0/secret"},
+			err:  ErrChatWebhookInvalidURL,
+		},
+		{
+			name: "missing target",
+			conf: map[string]any{},
+			err:  ErrChatWebhookInvalidURL,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &ChatWebhookHandler{Gw: ts.Gw}
+			err := h.Init(tc.conf)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.format, h.conf.Format)
+			assert.Equal(t, int64(defaultChatWebhookCooldown), h.conf.EventTimeout)
+		})
+	}
+}
+
+func TestChatWebhookHandler_HandleEvent(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	specs := ts.Gw.BuildAndLoadAPI(
+		func(spec *APISpec) {
+			spec.APIID = "chat-api"
+			spec.Name = "Chat API"
+			spec.Proxy.ListenPath = "/chat-api/"
+		},
+		func(spec *APISpec) {
+			spec.APIID = "other-api"
+			spec.Name = "Other API"
+			spec.Proxy.ListenPath = "/other-api/"
+		},
+	)
+
+	newEvent := func(eventType apidef.TykEvent, meta any) config.EventMessage {
+		return config.EventMessage{Type: eventType, Meta: meta, TimeStamp: time.Now().Local().String()}
+	}
+
+	breakerTripped := newEvent(EventBreakerTripped, EventCurcuitBreakerMeta{
+		EventMetaDefault: EventMetaDefault{Message: "Breaker tripped"},
+		Path:             "/flaky",
+		APIID:            "chat-api",
+	})
+
+	t.Run("slack payload", func(t *testing.T) {
+		server := newChatWebhookServer(t)
+		h := ts.createChatWebhookHandler(t, specs[0], map[string]any{"target_path": server.URL})
+
+		h.HandleEvent(breakerTripped)
+
+		posted := server.posted()
+		require.Len(t, posted, 1)
+
+		var msg slackMessage
+		require.NoError(t, json.Unmarshal(posted[0], &msg))
+
+		assert.Equal(t, "Tyk event: BreakerTripped", msg.Text)
+		require.Len(t, msg.Blocks, 3)
+
+		assert.Equal(t, "header", msg.Blocks[0].Type)
+		assert.Equal(t, &slackText{Type: "plain_text", Text: "Tyk event: BreakerTripped"}, msg.Blocks[0].Text)
+
+		assert.Equal(t, "section", msg.Blocks[1].Type)
+		require.Len(t, msg.Blocks[1].Fields, 4)
+		assert.Equal(t, slackText{Type: "mrkdwn", Text: "*API*\nChat API"}, msg.Blocks[1].Fields[0])
+		assert.Equal(t, slackText{Type: "mrkdwn", Text: "*API ID*\nchat-api"}, msg.Blocks[1].Fields[1])
+		assert.Equal(t, slackText{Type: "mrkdwn", Text: "*Event*\nBreakerTripped"}, msg.Blocks[1].Fields[2])
+		assert.Regexp(t, `^\*Time\*\n\d{4}-\d{2}-\d{2}T`, msg.Blocks[1].Fields[3].Text)
+
+		assert.Equal(t, &slackText{Type: "plain_text", Text: "Breaker tripped"}, msg.Blocks[2].Text)
+	})
+
+	t.Run("teams payload with key hash", func(t *testing.T) {
+		server := newChatWebhookServer(t)
+		h := ts.createChatWebhookHandler(t, specs[0], map[string]any{"target_path": server.URL, "format": "teams"})
+
+		h.HandleEvent(newEvent(EventQuotaExceeded, EventKeyFailureMeta{
+			EventMetaDefault: EventMetaDefault{Message: "Key Quota Limit Exceeded"},
+			Path:             "/chat-api/",
+			Key:              "secret-key-1234",
+		}))
+
+		posted := server.posted()
+		require.Len(t, posted, 1)
+		assert.NotContains(t, string(posted[0]), "secret-key")
+
+		var card teamsMessageCard
+		require.NoError(t, json.Unmarshal(posted[0], &card))
+
+		assert.Equal(t, "MessageCard", card.Type)
+		assert.Equal(t, "https://schema.org/extensions", card.Context)
+		assert.Equal(t, "Tyk event: QuotaExceeded", card.Title)
+		assert.Equal(t, "Tyk event: QuotaExceeded", card.Summary)
+		assert.Equal(t, "Key Quota Limit Exceeded", card.Text)
+
+		require.Len(t, card.Sections, 1)
+		facts := card.Sections[0].Facts
+		require.Len(t, facts, 5)
+		assert.Equal(t, teamsFact{Name: "API", Value: "Chat API"}, facts[0])
+		assert.Equal(t, teamsFact{Name: "API ID", Value: "chat-api"}, facts[1])
+		assert.Equal(t, teamsFact{Name: "Event", Value: "QuotaExceeded"}, facts[2])
+		assert.Equal(t, teamsFact{Name: "Key", Value: ts.Gw.obfuscateKey("secret-key-1234")}, facts[3])
+		assert.Equal(t, "Time", facts[4].Name)
+	})
+
+	t.Run("cooldown per event type and API", func(t *testing.T) {
+		server := newChatWebhookServer(t)
+		h := ts.createChatWebhookHandler(t, specs[0], map[string]any{"target_path": server.URL})
+		other := ts.createChatWebhookHandler(t, specs[1], map[string]any{"target_path": server.URL})
+
+		for i := 0; i < 5; i++ {
+			h.HandleEvent(breakerTripped)
+		}
+		assert.Len(t, server.posted(), 1, "a flapping breaker should be posted once per cool-down")
+
+		h.HandleEvent(newEvent(EventBreakerReset, EventCurcuitBreakerMeta{APIID: "chat-api"}))
+		assert.Len(t, server.posted(), 2, "another event type of the API should be posted")
+
+		other.HandleEvent(breakerTripped)
+		assert.Len(t, server.posted(), 3, "the same event of another API should be posted")
+
+		other.HandleEvent(breakerTripped)
+		assert.Len(t, server.posted(), 3)
+	})
+
+	t.Run("posted again after cooldown", func(t *testing.T) {
+		server := newChatWebhookServer(t)
+		h := ts.createChatWebhookHandler(t, specs[0], map[string]any{"target_path": server.URL, "event_timeout": 1})
+
+		h.HandleEvent(breakerTripped)
+		h.HandleEvent(breakerTripped)
+		assert.Len(t, server.posted(), 1)
+
+		time.Sleep(1100 * time.Millisecond)
+
+		h.HandleEvent(breakerTripped)
+		assert.Len(t, server.posted(), 2)
+	})
+}
@@ -53,6 +53,10 @@ const (
 	EventHOSTDOWN = event.HostDown
 	// EventHOSTUP is an alias maintained for backwards compatibility.
 	EventHOSTUP = event.HostUp
+	// EventHostEjected is an alias maintained for backwards compatibility.
+	EventHostEjected = event.HostEjected
+	// EventHostReadmitted is an alias maintained for backwards compatibility.
+	EventHostReadmitted = event.HostReadmitted
 	// EventTokenCreated is an alias maintained for backwards compatibility.
 	EventTokenCreated = event.TokenCreated
 	// EventTokenUpdated is an alias maintained for backwards compatibility.
@@ -63,6 +67,24 @@ const (
 	EventCertificateExpiringSoon = event.CertificateExpiringSoon
 	// EventCertificateExpired is an alias maintained for backwards compatibility.
 	EventCertificateExpired = event.CertificateExpired
+	// EventRefreshTokenReused is an alias maintained for backwards compatibility.
+	EventRefreshTokenReused = event.RefreshTokenReused
+	// EventKeyIPNotAllowed is an alias maintained for backwards compatibility.
+	EventKeyIPNotAllowed = event.KeyIPNotAllowed
+	// EventControlAPIAuthLockout is an alias maintained for backwards compatibility.
+	EventControlAPIAuthLockout = event.ControlAPIAuthLockout
+	// EventKVSecretsRotated is an alias maintained for backwards compatibility.
+	EventKVSecretsRotated = event.KVSecretsRotated
+	// EventGeoRestrictionBlocked is an alias maintained for backwards compatibility.
+	EventGeoRestrictionBlocked = event.GeoRestrictionBlocked
+	// EventPayloadSizeThreshold is an alias maintained for backwards compatibility.
+	EventPayloadSizeThreshold = event.PayloadSizeThreshold
+	// EventVersionDeprecated is an alias maintained for backwards compatibility.
+	EventVersionDeprecated = event.VersionDeprecated
+	// EventVersionSunset is an alias maintained for backwards compatibility.
+	EventVersionSunset = event.VersionSunset
+	// EventAuthFailureLockout is an alias maintained for backwards compatibility.
+	EventAuthFailureLockout = event.AuthFailureLockout
 )
 
 type EventHostStatusMeta struct {
@@ -110,6 +132,10 @@ type EventTriggerExceededMeta struct {
 	Key             string `json:"key"`
 	TriggerLimit    int64  `json:"trigger_limit"`
 	UsagePercentage int64  `json:"usage_percentage"`
+	// Threshold is the exact trigger limit that fired, as a percentage of the quota.
+	Threshold float64 `json:"threshold"`
+	// PolicyID is the policy overriding the webhook the event is sent to, if any.
+	PolicyID string `json:"policy_id,omitempty"`
 }
 
 type EventTokenMeta struct {
@@ -118,6 +144,70 @@ type EventTokenMeta struct {
 	Key string
 }
 
+// EventRefreshTokenReusedMeta is the metadata structure for a detected OAuth refresh token reuse
+type EventRefreshTokenReusedMeta struct {
+	EventMetaDefault
+	APIID    string `json:"api_id"`
+	OrgID    string `json:"org_id"`
+	ClientID string `json:"client_id"`
+	Revoked  int    `json:"revoked"`
+}
+
+// EventControlAPIAuthLockoutMeta is the metadata structure for a source IP locked out of the Control API
+type EventControlAPIAuthLockoutMeta struct {
+	EventMetaDefault
+	Origin          string `json:"origin"`
+	Failures        int    `json:"failures"`
+	LockoutDuration int    `json:"lockout_duration"`
+}
+
+// EventKVSecretsRotatedMeta is the metadata structure for the Gateway secrets rotated in a KV store
+type EventKVSecretsRotatedMeta struct {
+	EventMetaDefault
+	Fields []string `json:"fields"`
+}
+
+// EventGeoRestrictionBlockedMeta is the metadata structure for a request blocked by the country restrictions of an API
+type EventGeoRestrictionBlockedMeta struct {
+	EventMetaDefault
+	Path    string `json:"path"`
+	Origin  string `json:"origin"`
+	Country string `json:"country"`
+	Reason  string `json:"reason"`
+}
+
+// EventPayloadSizeThresholdMeta is the metadata structure for a request or response body larger than the threshold of an API
+type EventPayloadSizeThresholdMeta struct {
+	EventMetaDefault
+	APIID string `json:"api_id"`
+	Path  string `json:"path"`
+	// Direction is `request` or `response`.
+	Direction string `json:"direction"`
+	Size      int64  `json:"size"`
+	Threshold int64  `json:"threshold"`
+}
+
+// EventVersionPhaseMeta is the metadata structure for an API version entering its deprecation or sunset phase
+type EventVersionPhaseMeta struct {
+	EventMetaDefault
+	APIID   string `json:"api_id"`
+	Version string `json:"version"`
+	// Date is the RFC3339 time the phase started at, empty for a version retired without a date.
+	Date string `json:"date"`
+}
+
+// EventAuthFailureLockoutMeta is the metadata structure for a client IP or a token prefix locked out of an API
+type EventAuthFailureLockoutMeta struct {
+	EventMetaDefault
+	APIID  string `json:"api_id"`
+	Path   string `json:"path"`
+	Origin string `json:"origin"`
+	// TokenPrefix is set when the token prefix is locked out rather than the client IP.
+	TokenPrefix     string `json:"token_prefix,omitempty"`
+	Failures        int    `json:"failures"`
+	LockoutDuration int    `json:"lockout_duration"`
+}
+
 // EventHandlerByName is a convenience function to get event handler instances from an API Definition
 func (gw *Gateway) EventHandlerByName(handlerConf apidef.EventHandlerTriggerConfig, spec *APISpec) (config.TykEventHandler, error) {
 
@@ -131,6 +221,10 @@ func (gw *Gateway) EventHandlerByName(handlerConf apidef.EventHandlerTriggerConf
 		h := &WebHookHandler{Gw: gw}
 		err := h.Init(conf)
 		return h, err
+	case event.ChatWebhookHandler:
+		h := &ChatWebhookHandler{Spec: spec, Gw: gw}
+		err := h.Init(conf)
+		return h, err
 	case EH_JSVMHandler:
 		// Load the globals and file here
 		if spec != nil {
@@ -71,3 +71,9 @@ func getJWKWithClient(jwlUrl string, client *http.Client) (*jose.JSONWebKeySet,
 	log.Debug("Pulling JWK with configured client")
 	return httpclient.GetJWKWithClient(jwlUrl, client, parseJWK)
 }
+
+// getJWKWithClientAndHeaders fetches JWK like getJWKWithClient and also returns the response headers.
+func getJWKWithClientAndHeaders(jwlUrl string, client *http.Client) (*jose.JSONWebKeySet, http.Header, error) {
+	log.Debug("Pulling JWK with configured client")
+	return httpclient.GetJWKWithClientAndHeaders(jwlUrl, client, parseJWK)
+}
@@ -13,9 +13,13 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/TykTechnologies/tyk/certs"
 
 	"github.com/TykTechnologies/tyk/config"
@@ -28,6 +32,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	pbexample "google.golang.org/grpc/examples/helloworld/helloworld"
+	"google.golang.org/grpc/peer"
 
 	"github.com/TykTechnologies/tyk/test"
 	"github.com/TykTechnologies/tyk/user"
@@ -138,6 +143,116 @@ func TestGRPC_H2C(t *testing.T) {
 	}
 }
 
+// peerRecordingGreeter records the connections the calls are received on.
+type peerRecordingGreeter struct {
+	pbexample.UnimplementedGreeterServer
+
+	mu    sync.Mutex
+	peers map[string]int
+}
+
+func (s *peerRecordingGreeter) SayHello(ctx context.Context, in *pbexample.HelloRequest) (*pbexample.HelloReply, error) {
+	p, _ := peer.FromContext(ctx)
+
+	s.mu.Lock()
+	s.peers[p.Addr.String()]++
+	s.mu.Unlock()
+
+	return &pbexample.HelloReply{Message: "Hello " + in.Name}, nil
+}
+
+func (s *peerRecordingGreeter) reset() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	peers := s.peers
+	s.peers = map[string]int{}
+	return peers
+}
+
+func TestGRPC_H2C_LoadBalancing(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.ProxyH2CConnectionsPerTarget = 2
+	})
+	defer ts.Close()
+
+	var port = 6666
+	ts.EnablePort(port, "h2c")
+
+	greeters := []*peerRecordingGreeter{{peers: map[string]int{}}, {peers: map[string]int{}}}
+	var targets []string
+	for _, greeter := range greeters {
+		greeter := greeter
+		target, s := startGRPCServerH2C(t, func(t *testing.T, s *grpc.Server) {
+			pbexample.RegisterGreeterServer(s, greeter)
+		})
+		defer target.Close()
+		defer s.Stop()
+
+		targets = append(targets, toTarget(t, "h2c", target))
+	}
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Name = "h2c_lb_api"
+		spec.Proxy.ListenPath = "/"
+		spec.UseKeylessAccess = true
+		spec.Proxy.TargetURL = targets[0]
+		spec.Proxy.EnableLoadBalancing = true
+		spec.Proxy.Targets = targets
+		spec.Proxy.CheckHostAgainstUptimeTests = true
+		spec.ListenPort = port
+		spec.Protocol = "h2c"
+	})
+
+	// A single client connection, the calls are balanced by the gateway.
+	conn, err := grpc.Dial("localhost:6666", grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pbexample.NewGreeterClient(conn)
+
+	sayHello := func(calls int) {
+		for i := 0; i < calls; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			_, err := client.SayHello(ctx, &pbexample.HelloRequest{Name: "Josh"})
+			cancel()
+			require.NoError(t, err)
+		}
+	}
+
+	t.Run("calls are spread across the targets and their connections", func(t *testing.T) {
+		sayHello(8)
+
+		for i, greeter := range greeters {
+			peers := greeter.reset()
+			assert.Len(t, peers, 2, "target %d is reached on 2 connections", i)
+			for addr, calls := range peers {
+				assert.Equal(t, 2, calls, "calls on %s", addr)
+			}
+		}
+	})
+
+	t.Run("targets which are down are skipped", func(t *testing.T) {
+		u, err := url.Parse(targets[1])
+		require.NoError(t, err)
+
+		key := PoolerHostSentinelKeyPrefix + u.Host
+		ts.Gw.GlobalHostChecker.unhealthyHostList.Store(key, 1)
+		require.NoError(t, ts.Gw.GlobalHostChecker.store.SetKey(key, "1", 0))
+		defer ts.Gw.GlobalHostChecker.store.DeleteKey(key)
+		require.True(t, ts.Gw.GlobalHostChecker.HostDown(targets[1]))
+
+		sayHello(4)
+
+		assert.Empty(t, greeters[1].reset())
+
+		calls := 0
+		for _, n := range greeters[0].reset() {
+			calls += n
+		}
+		assert.Equal(t, 4, calls)
+	})
+}
+
 // For gRPC, we should be sure that HTTP/2 works with Tyk.
 func TestHTTP2_TLS(t *testing.T) {
 
@@ -9,7 +9,6 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/TykTechnologies/tyk-pump/analytics"
@@ -51,6 +50,9 @@ func defaultTykErrors() {
 
 	initAuthKeyErrors()
 	initOauth2KeyExistsErrors()
+	initKeyIPAllowListErrors()
+	initGeoRestrictionErrors()
+	initAuthFailureLimitErrors()
 }
 
 func overrideTykErrors(gw *Gateway) {
@@ -98,6 +100,11 @@ type TemplateExecutor interface {
 // HandleError is the actual error handler and will store the error details in analytics if analytics processing is enabled.
 func (e *ErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, errMsg string, errCode int, writeResponse bool) {
 	defer e.Base().UpdateRequestSession(r)
+
+	if writeResponse && errCode >= http.StatusInternalServerError && e.serveStaleCache(w, r) {
+		return
+	}
+
 	response := &http.Response{}
 
 	if writeResponse {
@@ -137,7 +144,10 @@ func (e *ErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, errMs
 
 		addVersionHeader(w, r, e.Spec.GlobalConfig)
 
-		version := e.Spec.getVersionFromRequest(r)
+		version := ctxGetResolvedVersion(r)
+		if version == "" {
+			version = e.Spec.getVersionFromRequest(r)
+		}
 
 		if version == "" {
 			version = "Non Versioned"
@@ -164,6 +174,25 @@ func (e *ErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, errMs
 		if len(e.Spec.Tags) > 0 {
 			tags = append(tags, e.Spec.Tags...)
 		}
+
+		if tag, ok := loopTraceTag(r, e.Spec.APIID); ok {
+			tags = append(tags, tag)
+		}
+
+		if tag, ok := requestIDTag(r); ok {
+			tags = append(tags, tag)
+		}
+
+		if tag, ok := canaryRouteTag(r); ok {
+			tags = append(tags, tag)
+		}
+
+		if tag, ok := latencyBreakdownTag(r, e.Spec); ok {
+			tags = append(tags, tag)
+		}
+
+		tags = append(tags, clientCertTags(e.Spec, r)...)
+
 		trackEP := false
 		trackedPath := r.URL.Path
 
@@ -260,9 +289,7 @@ func (e *ErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, errMs
 
 		record.SetExpiry(expiresAfter)
 
-		if e.Spec.GlobalConfig.AnalyticsConfig.NormaliseUrls.Enabled {
-			NormalisePath(&record, &e.Spec.GlobalConfig)
-		}
+		e.Spec.normaliseAnalyticsPath(&record)
 
 		if e.Spec.AnalyticsPlugin.Enabled {
 			_ = e.Spec.AnalyticsPluginConfig.processRecord(&record)
@@ -282,28 +309,52 @@ func (e *ErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, errMs
 	reportHealthValue(e.Spec, BlockedRequestLog, "-1")
 }
 
+// serveStaleCache writes the expired cached response kept for the request in place of the error.
+// Analytics record the status actually served.
+func (e *ErrorHandler) serveStaleCache(w http.ResponseWriter, r *http.Request) bool {
+	res := staleCachedResponse(r)
+	if res == nil {
+		return false
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	e.Logger().Debug("Serving stale cached response instead of error")
+
+	compressCachedResponse(e.Spec, res, r)
+	copyHeader(w.Header(), res.Header, e.Gw.GetConfig().IgnoreCanonicalMIMEHeaderKey)
+	w.WriteHeader(res.StatusCode)
+	_, _ = io.Copy(w, res.Body)
+
+	if e.Spec.DoNotTrack || ctxGetDoNotTrack(r) {
+		return true
+	}
+
+	var latency analytics.Latency
+	if requestStartTime := ctxGetRequestStartTime(r); !requestStartTime.IsZero() {
+		totalMs := int64(DurationToMillisecond(time.Since(requestStartTime)))
+		latency = analytics.Latency{Total: totalMs, Gateway: totalMs}
+	}
+
+	sh := SuccessHandler{e.BaseMiddleware}
+	sh.RecordHit(r, latency, res.StatusCode, res, true)
+	sh.RecordAccessLog(r, res, latency)
+	e.Base().RecordMetrics(w, r, res.StatusCode, latency, res)
+
+	return true
+}
+
 // writeTemplateErrorResponse writes an error response using the configured error templates
 // and returns the corresponding http.Response for analytics recording.
 func (e *ErrorHandler) writeTemplateErrorResponse(w http.ResponseWriter, r *http.Request, errMsg string, errCode int) *http.Response {
 	response := &http.Response{}
 
-	var templateExtension string
-	contentType := r.Header.Get(header.ContentType)
-	contentType = strings.Split(contentType, ";")[0]
-
-	switch contentType {
-	case header.ApplicationSoapXML:
-		templateExtension = "xml"
-		contentType = header.ApplicationSoapXML
-	case header.ApplicationXML:
+	templateExtension := "json"
+	contentType := errorResponseContentType(r)
+	isXML := contentType != header.ApplicationJSON
+	if isXML {
 		templateExtension = "xml"
-		contentType = header.ApplicationXML
-	case header.TextXML:
-		templateExtension = "xml"
-		contentType = header.TextXML
-	default:
-		templateExtension = "json"
-		contentType = header.ApplicationJSON
 	}
 
 	w.Header().Set(header.ContentType, contentType)
@@ -311,6 +362,9 @@ func (e *ErrorHandler) writeTemplateErrorResponse(w http.ResponseWriter, r *http
 	response.Header.Set(header.ContentType, contentType)
 	templateName := "error_" + strconv.Itoa(errCode) + "." + templateExtension
 
+	// Templates of the API take precedence over the gateway ones.
+	apiTmpl := e.Spec.errorTemplate(errCode, isXML)
+
 	// Try to use an error template that matches the HTTP error code and the content type: 500.json, 400.xml, etc.
 	tmpl := e.Gw.templates.Lookup(templateName)
 
@@ -321,7 +375,7 @@ func (e *ErrorHandler) writeTemplateErrorResponse(w http.ResponseWriter, r *http
 	}
 
 	// If no template is available for this content type, fallback to "error.json".
-	if tmpl == nil {
+	if tmpl == nil && apiTmpl == nil {
 		templateName = defaultTemplateName + "." + defaultTemplateFormat
 		tmpl = e.Gw.templates.Lookup(templateName)
 		w.Header().Set(header.ContentType, defaultContentType)
@@ -345,14 +399,17 @@ func (e *ErrorHandler) writeTemplateErrorResponse(w http.ResponseWriter, r *http
 	response.StatusCode = errCode
 
 	// If error is not customized write error in default way
-	if errMsg != errCustomBodyResponse.Error() {
+	if errMsg != errCustomBodyResponse.Error() && apiTmpl != nil {
+		apiResponse := e.ExecuteErrorTemplate(w, apiTmpl, e.apiErrorTemplateData(r, errMsg, errCode, isXML), errCode)
+		response.Body = apiResponse.Body
+	} else if errMsg != errCustomBodyResponse.Error() {
 		w.WriteHeader(errCode)
 		var tmplExecutor TemplateExecutor
 		tmplExecutor = tmpl
 
 		apiError := APIError{htmltemplate.HTML(htmltemplate.JSEscapeString(errMsg))}
 
-		if contentType == header.ApplicationXML || contentType == header.TextXML || contentType == header.ApplicationSoapXML {
+		if isXML {
 			apiError.Message = htmltemplate.HTML(errMsg)
 
 			//we look up in the last defined templateName to obtain the template.
@@ -444,11 +501,7 @@ func (e *ErrorHandler) writeOverrideResponse(w http.ResponseWriter, r *http.Requ
 
 		if ec := tykctx.GetErrorClassification(r); ec != nil && ec.TemplateData != nil {
 			for k, v := range ec.TemplateData {
-				if s, ok := v.(string); ok {
-					v = escapeTemplateString(s, ctx.IsXML)
-				}
-
-				data[k] = v
+				data[k] = escapeTemplateValue(v, ctx.IsXML)
 			}
 		}
 
@@ -203,7 +203,10 @@ func (s *SuccessHandler) RecordHit(r *http.Request, timing analytics.Latency, co
 		token := ctxGetAuthToken(r)
 
 		// Track version data
-		version := s.Spec.getVersionFromRequest(r)
+		version := ctxGetResolvedVersion(r)
+		if version == "" {
+			version = s.Spec.getVersionFromRequest(r)
+		}
 		if version == "" {
 			version = "Non Versioned"
 		}
@@ -231,6 +234,24 @@ func (s *SuccessHandler) RecordHit(r *http.Request, timing analytics.Latency, co
 			tags = append(tags, "cached-response")
 		}
 
+		if tag, ok := loopTraceTag(r, s.Spec.APIID); ok {
+			tags = append(tags, tag)
+		}
+
+		if tag, ok := requestIDTag(r); ok {
+			tags = append(tags, tag)
+		}
+
+		if tag, ok := canaryRouteTag(r); ok {
+			tags = append(tags, tag)
+		}
+
+		if tag, ok := latencyBreakdownTag(r, s.Spec); ok {
+			tags = append(tags, tag)
+		}
+
+		tags = append(tags, clientCertTags(s.Spec, r)...)
+
 		tags = s.addTraceIDTag(r.Context(), tags)
 
 		rawRequest := ""
@@ -340,9 +361,7 @@ func (s *SuccessHandler) RecordHit(r *http.Request, timing analytics.Latency, co
 
 		record.SetExpiry(expiresAfter)
 
-		if s.Spec.GlobalConfig.AnalyticsConfig.NormaliseUrls.Enabled {
-			NormalisePath(&record, &s.Spec.GlobalConfig)
-		}
+		s.Spec.normaliseAnalyticsPath(&record)
 
 		if s.Spec.AnalyticsPlugin.Enabled {
 
@@ -459,6 +478,7 @@ func (s *SuccessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) *http
 		s.RecordAccessLog(r, resp.Response, latency)
 
 		s.Base().RecordMetrics(w, r, resp.Response.StatusCode, latency, resp.Response)
+		s.recordUpstreamLatency(r, resp.UpstreamLatency)
 	}
 	log.Debug("Done proxy")
 
@@ -507,7 +527,17 @@ func (s *SuccessHandler) ServeHTTPWithCache(w http.ResponseWriter, r *http.Reque
 		s.RecordAccessLog(r, inRes.Response, latency)
 
 		s.Base().RecordMetrics(w, r, inRes.Response.StatusCode, latency, inRes.Response)
+		s.recordUpstreamLatency(r, inRes.UpstreamLatency)
 	}
 
 	return inRes
 }
+
+// recordUpstreamLatency records the upstream response time of a proxied request.
+func (s *SuccessHandler) recordUpstreamLatency(r *http.Request, latency time.Duration) {
+	if s.Spec.DoNotTrack || ctxGetDoNotTrack(r) {
+		return
+	}
+
+	s.Gw.MetricInstruments.RecordUpstreamLatency(r.Context(), s.Spec.APIID, s.Spec.Proxy.ListenPath, latency)
+}
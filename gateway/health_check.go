@@ -6,9 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/gocraft/health"
 	"github.com/sirupsen/logrus"
 
 	"github.com/TykTechnologies/tyk/header"
@@ -80,6 +83,68 @@ type SafeHealthCheck struct {
 	mux  sync.Mutex
 }
 
+// redisHealthCheck writes the liveness probe key to Redis, it's replaced in tests to simulate a slow Redis.
+var redisHealthCheck = func(ctx context.Context, store *storage.RedisCluster) error {
+	const key = "tyk-liveness-probe"
+
+	client, err := store.Client()
+	if err != nil {
+		return err
+	}
+	return client.Set(ctx, key, key, 10*time.Second).Err()
+}
+
+// contextPinger is implemented by the dashboard services whose liveness probe can be cancelled.
+type contextPinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// healthCheckTimeout is the time a single check may take, capped by the check interval.
+func (gw *Gateway) healthCheckTimeout() time.Duration {
+	interval := gw.healthCheckInterval()
+	if n := gw.GetConfig().LivenessCheck.Timeout; n > 0 && n < interval {
+		return n
+	}
+	return interval
+}
+
+// runHealthCheck runs the check until ctx is done, and reports its outcome and latency.
+// The context passed to the check is cancelled once it timed out, so it doesn't keep running.
+func runHealthCheck(ctx context.Context, componentType string, check func(context.Context) error) HealthCheckItem {
+	startTime := time.Now()
+	item := HealthCheckItem{
+		Status:        Pass,
+		ComponentType: componentType,
+		Time:          startTime.Format(time.RFC3339),
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = errHealthCheckTimedOut
+	}
+
+	item.LatencyMs = float64(time.Since(startTime)) / float64(time.Millisecond)
+	if err != nil {
+		item.Status = Fail
+		item.Output = err.Error()
+		item.LastError = err.Error()
+	}
+
+	return item
+}
+
+var errHealthCheckTimedOut = errors.New("health check timed out")
+
 func (gw *Gateway) gatherHealthChecks() {
 	allInfos := SafeHealthCheck{info: make(map[string]HealthCheckItem, 3)}
 
@@ -88,7 +153,8 @@ func (gw *Gateway) gatherHealthChecks() {
 	redisStore := storage.RedisCluster{KeyPrefix: "livenesscheck-", ConnectionHandler: gw.StorageConnectionHandler}
 	redisStore.Connect()
 
-	key := "tyk-liveness-probe"
+	ctx, cancel := context.WithTimeout(context.Background(), gw.healthCheckTimeout())
+	defer cancel()
 
 	var wg sync.WaitGroup
 
@@ -96,17 +162,11 @@ func (gw *Gateway) gatherHealthChecks() {
 	go func() {
 		defer wg.Done()
 
-		var checkItem = HealthCheckItem{
-			Status:        Pass,
-			ComponentType: Datastore,
-			Time:          time.Now().Format(time.RFC3339),
-		}
-
-		err := redisStore.SetRawKey(key, key, 10)
-		if err != nil {
-			mainLog.WithField("liveness-check", true).WithError(err).Error("Redis health check failed")
-			checkItem.Output = err.Error()
-			checkItem.Status = Fail
+		checkItem := runHealthCheck(ctx, Datastore, func(ctx context.Context) error {
+			return redisHealthCheck(ctx, &redisStore)
+		})
+		if checkItem.Status == Fail {
+			mainLog.WithField("liveness-check", true).WithField("error", checkItem.Output).Error("Redis health check failed")
 		}
 
 		allInfos.mux.Lock()
@@ -121,25 +181,19 @@ func (gw *Gateway) gatherHealthChecks() {
 		go func() {
 			defer wg.Done()
 
-			var checkItem = HealthCheckItem{
-				Status:        Pass,
-				ComponentType: Datastore,
-				Time:          time.Now().Format(time.RFC3339),
-			}
-
-			if gw.DashService == nil {
-				err := errors.New("Dashboard service not initialized")
-				mainLog.WithField("liveness-check", true).Error(err)
-				checkItem.Output = err.Error()
-				checkItem.Status = Fail
-			} else if err := gw.DashService.Ping(); err != nil {
-				mainLog.WithField("liveness-check", true).Error(err)
-				checkItem.Output = err.Error()
-				checkItem.Status = Fail
+			checkItem := runHealthCheck(ctx, System, func(ctx context.Context) error {
+				if gw.DashService == nil {
+					return errors.New("Dashboard service not initialized")
+				}
+				if pinger, ok := gw.DashService.(contextPinger); ok {
+					return pinger.PingContext(ctx)
+				}
+				return gw.DashService.Ping()
+			})
+			if checkItem.Status == Fail {
+				mainLog.WithField("liveness-check", true).Error(checkItem.Output)
 			}
 
-			checkItem.ComponentType = System
-
 			allInfos.mux.Lock()
 			allInfos.info["dashboard"] = checkItem
 			allInfos.mux.Unlock()
@@ -153,18 +207,12 @@ func (gw *Gateway) gatherHealthChecks() {
 		go func() {
 			defer wg.Done()
 
-			var checkItem = HealthCheckItem{
-				Status:        Pass,
-				ComponentType: Datastore,
-				Time:          time.Now().Format(time.RFC3339),
-			}
-
-			if !rpc.Login() {
-				checkItem.Output = "Could not connect to RPC"
-				checkItem.Status = Fail
-			}
-
-			checkItem.ComponentType = System
+			checkItem := runHealthCheck(ctx, System, func(context.Context) error {
+				if !rpc.Login() {
+					return errors.New("Could not connect to RPC")
+				}
+				return nil
+			})
 
 			allInfos.mux.Lock()
 			allInfos.info["rpc"] = checkItem
@@ -199,23 +247,112 @@ func (gw *Gateway) gatherHealthChecks() {
 		if _, ok := info[component]; !ok {
 			info[component] = HealthCheckItem{
 				Status:        Fail,
-				Output:        "health check timed out",
+				Output:        errHealthCheckTimedOut.Error(),
 				ComponentType: componentType,
 				Time:          time.Now().Format(time.RFC3339),
+				LastError:     errHealthCheckTimedOut.Error(),
 			}
 		}
 	}
 
+	previous := gw.getHealthCheckInfo()
+	for component, item := range info {
+		if item.LastError == "" {
+			item.LastError = previous[component].LastError
+			info[component] = item
+		}
+	}
+
+	for component, item := range gw.tcpProxyHealthChecks() {
+		info[component] = item
+	}
+
+	if item, ok := gw.admissionControlHealthCheck(); ok {
+		info["admission_control"] = item
+	}
+
 	gw.setCurrentHealthCheckInfo(info)
 }
 
+// tcpProxyHealthChecks reports the connection counters of every TCP proxy port as
+// health check items and pushes them to the instrumentation sink. A port which
+// reached its connection limit is reported with a warning.
+func (gw *Gateway) tcpProxyHealthChecks() map[string]HealthCheckItem {
+	if gw.DefaultProxyMux == nil {
+		return nil
+	}
+
+	conf := gw.GetConfig()
+	items := make(map[string]HealthCheckItem)
+	job := instrument.NewJob("TCPProxy")
+	for port, stats := range gw.DefaultProxyMux.tcpProxyStats() {
+		portS := strconv.Itoa(port)
+		item := HealthCheckItem{
+			Status:        Pass,
+			ComponentType: string(model.Component),
+			ComponentID:   portS,
+			ObservedValue: stats,
+			Time:          time.Now().Format(time.RFC3339),
+		}
+
+		if limit := conf.TCPProxy.MaxConnectionsForPort(port); limit > 0 && stats.Active >= int64(limit) {
+			item.Status = Warn
+			item.Output = fmt.Sprintf("connection limit of %d reached", limit)
+		}
+
+		items["tcp_proxy_"+portS] = item
+
+		metadata := health.Kvs{"port": portS}
+		job.GaugeKv("active_connections", float64(stats.Active), metadata)
+		job.GaugeKv("accepted_connections", float64(stats.Accepted), metadata)
+		job.GaugeKv("rejected_connections", float64(stats.Rejected), metadata)
+		job.GaugeKv("bytes_in", float64(stats.BytesIn), metadata)
+		job.GaugeKv("bytes_out", float64(stats.BytesOut), metadata)
+	}
+
+	return items
+}
+
+// admissionControlHealthCheck reports the queue depth and shed count of the admission controller
+// as a health check item and pushes them to the instrumentation sink. A full queue is reported with a warning.
+func (gw *Gateway) admissionControlHealthCheck() (HealthCheckItem, bool) {
+	admission := gw.getAdmissionController()
+	if admission == nil {
+		return HealthCheckItem{}, false
+	}
+
+	stats := admission.stats()
+	item := HealthCheckItem{
+		Status:        Pass,
+		ComponentType: string(model.Component),
+		ObservedValue: stats,
+		Time:          time.Now().Format(time.RFC3339),
+	}
+
+	if admission.full() {
+		item.Status = Warn
+		item.Output = "request queue is full"
+	}
+
+	job := instrument.NewJob("AdmissionControl")
+	job.Gauge("active_requests", float64(stats.Active))
+	job.Gauge("queue_depth", float64(stats.Queued))
+	job.Gauge("shed_requests", float64(stats.Shed))
+
+	return item, true
+}
+
 func (gw *Gateway) liveCheckHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		doJSONWrite(w, http.StatusMethodNotAllowed, apiError(http.StatusText(http.StatusMethodNotAllowed)))
 		return
 	}
 
-	checks := gw.getHealthCheckInfo()
+	checks, err := filterHealthChecks(gw.getHealthCheckInfo(), r.URL.Query()["component"])
+	if err != nil {
+		doJSONWrite(w, http.StatusNotFound, apiError(err.Error()))
+		return
+	}
 
 	res := HealthCheckResponse{
 		Status:      Pass,
@@ -254,13 +391,44 @@ func (gw *Gateway) liveCheckHandler(w http.ResponseWriter, r *http.Request) {
 		addMascotHeaders(w)
 	}
 
-	w.WriteHeader(http.StatusOK)
-	err := json.NewEncoder(w).Encode(res)
+	code := http.StatusOK
+	if threshold := gw.GetConfig().LivenessCheck.FailureThreshold; threshold > 0 && failCount >= threshold {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.WriteHeader(code)
+	err = json.NewEncoder(w).Encode(res)
 	if err != nil {
 		mainLog.Warning(fmt.Sprintf("[Liveness] Could not encode response, error: %s", err.Error()))
 	}
 }
 
+// filterHealthChecks returns the checks of the given components, comma separated or repeated.
+// All the checks are returned when no component is given.
+func filterHealthChecks(checks map[string]HealthCheckItem, components []string) (map[string]HealthCheckItem, error) {
+	if len(components) == 0 {
+		return checks, nil
+	}
+
+	filtered := make(map[string]HealthCheckItem, len(components))
+	for _, value := range components {
+		for _, component := range strings.Split(value, ",") {
+			component = strings.TrimSpace(component)
+			if component == "" {
+				continue
+			}
+
+			item, ok := checks[component]
+			if !ok {
+				return nil, fmt.Errorf("unknown health check component: %s", component)
+			}
+			filtered[component] = item
+		}
+	}
+
+	return filtered, nil
+}
+
 // readinessHandler is a dedicated endpoint for readiness probes
 // It checks if the gateway is ready to serve requests by verifying:
 // - Redis connection status
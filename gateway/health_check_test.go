@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -70,6 +71,135 @@ func TestGatherHealthChecks_HungProbeDoesNotWedgeTheLoop(t *testing.T) {
 	assert.Equal(t, "health check timed out", checks["dashboard"].Output)
 }
 
+// TestGatherHealthChecks_SlowRedis verifies a Redis check slower than the timeout is reported
+// as failed with its latency, and flips /hello to a 503 once the failure threshold is reached.
+func TestGatherHealthChecks_SlowRedis(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	cfg := ts.Gw.GetConfig()
+	cfg.LivenessCheck.Timeout = 50 * time.Millisecond
+	cfg.LivenessCheck.FailureThreshold = 1
+	ts.Gw.SetConfig(cfg)
+
+	var slow atomic.Bool
+	slow.Store(true)
+	cancelled := make(chan struct{}, 1)
+	defer func(check func(context.Context, *storage.RedisCluster) error) {
+		redisHealthCheck = check
+	}(redisHealthCheck)
+	redisHealthCheck = func(ctx context.Context, _ *storage.RedisCluster) error {
+		if !slow.Load() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			cancelled <- struct{}{}
+			return ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+			return nil
+		}
+	}
+
+	hello := func(t *testing.T, target string) (int, HealthCheckResponse) {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		ts.Gw.liveCheckHandler(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+		var res HealthCheckResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		return w.Code, res
+	}
+
+	ts.Gw.gatherHealthChecks()
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("the timed out Redis check wasn't cancelled")
+	}
+
+	redis := ts.Gw.getHealthCheckInfo()["redis"]
+	assert.Equal(t, HealthCheckStatus(Fail), redis.Status)
+	assert.Equal(t, "health check timed out", redis.Output)
+	assert.Equal(t, "health check timed out", redis.LastError)
+	assert.Greater(t, redis.LatencyMs, float64(0))
+	assert.Less(t, redis.LatencyMs, float64(200))
+
+	code, res := hello(t, "/hello?component=redis")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, HealthCheckStatus(Fail), res.Status)
+	assert.Equal(t, redis, res.Details["redis"])
+
+	slow.Store(false)
+	ts.Gw.gatherHealthChecks()
+
+	redis = ts.Gw.getHealthCheckInfo()["redis"]
+	assert.Equal(t, Pass, redis.Status)
+	assert.Empty(t, redis.Output)
+	assert.Equal(t, "health check timed out", redis.LastError, "the last error is kept once recovered")
+
+	code, res = hello(t, "/hello?component=redis")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, Pass, res.Status)
+}
+
+func TestGateway_liveCheckHandler_ComponentFilter(t *testing.T) {
+	gw := NewGateway(config.Config{}, nil)
+	gw.setCurrentHealthCheckInfo(map[string]HealthCheckItem{
+		"redis":     {Status: Pass, ComponentType: Datastore, LatencyMs: 1.5},
+		"dashboard": {Status: Fail, ComponentType: System, Output: "Dashboard connection failed"},
+		"rpc":       {Status: Pass, ComponentType: System},
+	})
+
+	tests := []struct {
+		name               string
+		target             string
+		failureThreshold   int
+		expectedCode       int
+		expectedStatus     HealthCheckStatus
+		expectedComponents []string
+	}{
+		{"all components", "/hello", 0, http.StatusOK, Warn, []string{"redis", "dashboard", "rpc"}},
+		{"single component", "/hello?component=redis", 0, http.StatusOK, Pass, []string{"redis"}},
+		{"comma separated", "/hello?component=redis,dashboard", 0, http.StatusOK, Warn, []string{"redis", "dashboard"}},
+		{"repeated", "/hello?component=rpc&component=dashboard", 0, http.StatusOK, Warn, []string{"rpc", "dashboard"}},
+		{"failure under threshold", "/hello", 2, http.StatusOK, Warn, []string{"redis", "dashboard", "rpc"}},
+		{"failure reaching threshold", "/hello", 1, http.StatusServiceUnavailable, Warn, []string{"redis", "dashboard", "rpc"}},
+		{"threshold on filtered components", "/hello?component=redis", 1, http.StatusOK, Pass, []string{"redis"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := gw.GetConfig()
+			conf.LivenessCheck.FailureThreshold = tt.failureThreshold
+			gw.SetConfig(conf)
+
+			w := httptest.NewRecorder()
+			gw.liveCheckHandler(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			var res HealthCheckResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			assert.Equal(t, tt.expectedStatus, res.Status)
+
+			components := make([]string, 0, len(res.Details))
+			for component := range res.Details {
+				components = append(components, component)
+			}
+			assert.ElementsMatch(t, tt.expectedComponents, components)
+		})
+	}
+
+	t.Run("unknown component", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		gw.liveCheckHandler(w, httptest.NewRequest(http.MethodGet, "/hello?component=mongo", nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
 func TestGateway_readinessHandler(t *testing.T) {
 	tests := []struct {
 		name                   string
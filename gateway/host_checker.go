@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"io"
 	mathrand "math/rand"
 	"net"
 	"net/http"
@@ -19,11 +20,15 @@ import (
 
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/internal/httpclient"
+	"github.com/TykTechnologies/tyk/regexp"
 )
 
 const (
 	defaultTimeout             = 10
 	defaultSampletTriggerLimit = 3
+
+	// maxHostCheckBodySize limits how much of the response body is read when matching it.
+	maxHostCheckBodySize = 1 << 20
 )
 
 const (
@@ -45,14 +50,32 @@ type HostData struct {
 	Method              string
 	Headers             map[string]string
 	Body                string
+	ExpectedStatusCodes []int
+	ExpectedBody        *regexp.Regexp
 	MetaData            map[string]string
 }
 
+// isExpectedStatus reports whether code is considered healthy for the host.
+func (h HostData) isExpectedStatus(code int) bool {
+	if len(h.ExpectedStatusCodes) == 0 {
+		return code == http.StatusOK
+	}
+
+	for _, expected := range h.ExpectedStatusCodes {
+		if code == expected {
+			return true
+		}
+	}
+
+	return false
+}
+
 type HostHealthReport struct {
 	HostData
-	ResponseCode int
-	Latency      float64
-	IsTCPError   bool
+	ResponseCode   int
+	Latency        float64
+	IsTCPError     bool
+	IsBodyMismatch bool
 }
 
 type HostSample struct {
@@ -174,6 +197,9 @@ func (h *HostUptimeChecker) HostReporter(ctx context.Context) {
 					}
 				}
 			}
+			if h.cb.Ok != nil {
+				go h.cb.Ok(ctx, okHost)
+			}
 			if h.cb.Ping != nil {
 				go h.cb.Ping(ctx, okHost)
 			}
@@ -337,18 +363,26 @@ func (h *HostUptimeChecker) CheckHost(toCheck HostData) {
 			report.IsTCPError = true
 			break
 		}
-		response.Body.Close()
 		report.ResponseCode = response.StatusCode
+
+		if toCheck.ExpectedBody != nil {
+			body, err := io.ReadAll(io.LimitReader(response.Body, maxHostCheckBodySize))
+			if err != nil || !toCheck.ExpectedBody.Match(body) {
+				log.Warning("[HOST CHECKER] Response body did not match expectation: ", toCheck.CheckURL)
+				report.IsBodyMismatch = true
+			}
+		}
+		response.Body.Close()
 	}
 
 	millisec := DurationToMillisecond(time.Since(t1))
 	report.Latency = millisec
-	if report.IsTCPError {
+	if report.IsTCPError || report.IsBodyMismatch {
 		h.errorChan <- report
 		return
 	}
 
-	if report.ResponseCode != http.StatusOK {
+	if !toCheck.isExpectedStatus(report.ResponseCode) {
 		h.errorChan <- report
 		return
 	}
@@ -369,6 +403,10 @@ type HostCheckCallBacks struct {
 
 	// Fail is invoked when the host checker decides a host is not healthy.
 	Fail func(context.Context, HostHealthReport)
+
+	// Ok when provided this callback will be invoked on every successful check
+	// of a remote host.
+	Ok func(context.Context, HostHealthReport)
 }
 
 func (h *HostUptimeChecker) Init(workers, triggerLimit, timeout int, hostList map[string]HostData, cb HostCheckCallBacks) {
@@ -5,7 +5,6 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
-	"net/http"
 	"net/url"
 	"sync"
 	"time"
@@ -16,6 +15,7 @@ import (
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/internal/uuid"
 	"github.com/TykTechnologies/tyk/pkg/errpack"
+	"github.com/TykTechnologies/tyk/regexp"
 	"github.com/TykTechnologies/tyk/storage"
 )
 
@@ -200,6 +200,7 @@ func (hc *HostCheckerManager) StartPoller(ctx context.Context) {
 			Up:   hc.OnHostBackUp,
 			Fail: hc.OnHostDown,
 			Ping: hc.OnHostReport,
+			Ok:   hc.OnHostOk,
 		},
 	)
 
@@ -241,6 +242,16 @@ func (hc *HostCheckerManager) OnHostReport(ctx context.Context, report HostHealt
 	}
 }
 
+// OnHostOk readmits a host ejected by the outlier detection of its API once an uptime test against it succeeded.
+func (hc *HostCheckerManager) OnHostOk(ctx context.Context, report HostHealthReport) {
+	spec := hc.Gw.getApiSpec(report.MetaData[UnHealthyHostMetaDataAPIKey])
+	if spec == nil {
+		return
+	}
+
+	spec.readmitUpstreamHost(report.CheckURL)
+}
+
 func (hc *HostCheckerManager) OnHostDown(ctx context.Context, report HostHealthReport) {
 	key := hc.getHostKey(report)
 	log.WithFields(logrus.Fields{
@@ -360,6 +371,17 @@ func (hc *HostCheckerManager) PrepareTrackingHost(checkObject apidef.HostCheckOb
 		bodyData = string(bodyByteArr)
 	}
 
+	var expectedBody *regexp.Regexp
+	if checkObject.ExpectedBodyRegex != "" {
+		expectedBody, err = regexp.Compile(checkObject.ExpectedBodyRegex)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"prefix": "host-check-mgr",
+			}).Error("Failed to compile expected body regex: ", err)
+			return hostData, err
+		}
+	}
+
 	hostData = HostData{
 		CheckURL: checkObject.CheckURL,
 		MetaData: map[string]string{
@@ -374,6 +396,8 @@ func (hc *HostCheckerManager) PrepareTrackingHost(checkObject apidef.HostCheckOb
 		Commands:            checkObject.Commands,
 		Headers:             checkObject.Headers,
 		Body:                bodyData,
+		ExpectedStatusCodes: checkObject.ExpectedStatusCodes,
+		ExpectedBody:        expectedBody,
 	}
 
 	return hostData, nil
@@ -504,7 +528,7 @@ func (hc *HostCheckerManager) RecordUptimeAnalytics(report HostHealthReport) err
 	t := time.Now()
 
 	var serverError bool
-	if report.ResponseCode > http.StatusOK {
+	if report.ResponseCode != 0 && !report.isExpectedStatus(report.ResponseCode) || report.IsBodyMismatch {
 		serverError = true
 	}
 
@@ -3,6 +3,8 @@ package gateway
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -15,6 +17,7 @@ import (
 
 	proxyproto "github.com/pires/go-proxyproto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/TykTechnologies/tyk/internal/uuid"
 
@@ -456,6 +459,80 @@ func TestTestCheckerTCPHosts_correct_wrong_answers(t *testing.T) {
 	}
 }
 
+func TestCheckHost_expectedResponse(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	var status atomic.Value
+	status.Store("healthy")
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || string(body) != `{"check":"deep"}` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"status":"` + status.Load().(string) + `"}`))
+	}))
+	defer upstream.Close()
+
+	hostData, err := ts.Gw.GlobalHostChecker.PrepareTrackingHost(apidef.HostCheckObject{
+		CheckURL:            upstream.URL,
+		Method:              http.MethodPost,
+		Body:                base64.StdEncoding.EncodeToString([]byte(`{"check":"deep"}`)),
+		ExpectedStatusCodes: []int{http.StatusOK, http.StatusAccepted},
+		ExpectedBodyRegex:   `"status":\s*"healthy"`,
+	}, "test")
+	require.NoError(t, err)
+
+	checkHost := func(hostData HostData) *HostUptimeChecker {
+		hs := &HostUptimeChecker{
+			Gw:        ts.Gw,
+			okChan:    make(chan HostHealthReport, 1),
+			errorChan: make(chan HostHealthReport, 1),
+		}
+		hs.CheckHost(hostData)
+		return hs
+	}
+
+	t.Run("healthy body", func(t *testing.T) {
+		status.Store("healthy")
+		hs := checkHost(hostData)
+
+		require.Len(t, hs.okChan, 1)
+		report := <-hs.okChan
+		assert.Equal(t, http.StatusAccepted, report.ResponseCode)
+		assert.False(t, report.IsBodyMismatch)
+	})
+
+	t.Run("degraded body", func(t *testing.T) {
+		status.Store("degraded")
+		hs := checkHost(hostData)
+
+		require.Len(t, hs.errorChan, 1)
+		report := <-hs.errorChan
+		assert.Equal(t, http.StatusAccepted, report.ResponseCode)
+		assert.True(t, report.IsBodyMismatch)
+	})
+
+	t.Run("unexpected status code", func(t *testing.T) {
+		status.Store("healthy")
+		unexpected := hostData
+		unexpected.ExpectedStatusCodes = []int{http.StatusOK}
+		hs := checkHost(unexpected)
+
+		require.Len(t, hs.errorChan, 1)
+	})
+
+	t.Run("invalid regex", func(t *testing.T) {
+		_, err := ts.Gw.GlobalHostChecker.PrepareTrackingHost(apidef.HostCheckObject{
+			CheckURL:          upstream.URL,
+			ExpectedBodyRegex: "(",
+		}, "test")
+		assert.Error(t, err)
+	})
+}
+
 func TestProxyWhenHostIsDown(t *testing.T) {
 	conf := func(conf *config.Config) {
 		conf.UptimeTests.Config.FailureTriggerSampleSize = 1
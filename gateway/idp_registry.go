@@ -198,8 +198,9 @@ func (r *IdPRegistry) fetch() ([]IdP, error) {
 // nonce-failure re-registration and client config as FromDashboardService — the
 // registry is a first-class /system/* citizen, not a fragile passive reader.
 // The response is a NodeResponseOK envelope {"Status","Message":[...],"Nonce"};
-// the Nonce is captured into gw.ServiceNonce so the node stays in sync — the
-// dashboard rotates the node's stored nonce on every /system/* call.
+// the Nonce is captured into gw.ServiceNonce by the request path so the node
+// stays in sync — the dashboard rotates the node's stored nonce on every
+// /system/* call.
 func (r *IdPRegistry) fetchFromDashboard() ([]IdP, error) {
 	endpoint := r.gw.buildDashboardConnStr("/system/clientidps")
 
@@ -233,20 +234,13 @@ func (r *IdPRegistry) fetchFromDashboard() ([]IdP, error) {
 		return nil, fmt.Errorf("client-idps dashboard error: status %d", resp.StatusCode)
 	}
 
-	// Stream-decode straight off the response body to avoid buffering the whole
-	// payload in memory (the registry can be large in big deployments). This path
-	// can't reuse unmarshalIdPs: it must also capture the rotating Nonce, which
-	// that helper deliberately drops, and it decodes from the stream rather than
-	// a []byte.
+	// The body is buffered by the request path, which reads the rotating Nonce
+	// off it before the next nonce-consuming call may be sent.
 	var feed idpFeedEnvelope
 	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
 		return nil, fmt.Errorf("unmarshal client-idps payload: %w", err)
 	}
 
-	r.gw.ServiceNonceMutex.Lock()
-	r.gw.ServiceNonce = feed.Nonce
-	r.gw.ServiceNonceMutex.Unlock()
-
 	return feed.Message, nil
 }
 
@@ -45,6 +45,7 @@ func (gw *Gateway) setupInstrumentation() {
 	log.Info("StatsD instrumentation sink started")
 	instrument.AddSink(statsdSink)
 
+	gw.enableCacheStats()
 	gw.MonitorApplicationInstrumentation()
 }
 
@@ -70,6 +71,8 @@ func (gw *Gateway) MonitorApplicationInstrumentation() {
 	go func() {
 		job := instrument.NewJob("GCActivity")
 		job_rl := instrument.NewJob("Load")
+		jobCache := instrument.NewJob("Cache")
+		jobPayloadSize := instrument.NewJob("PayloadSize")
 		metadata := health.Kvs{"host": gw.hostDetails.Hostname}
 		applicationGCStats.PauseQuantiles = make([]time.Duration, 5)
 
@@ -82,6 +85,8 @@ func (gw *Gateway) MonitorApplicationInstrumentation() {
 			job.GaugeKv("pauses_quantile_max", float64(applicationGCStats.PauseQuantiles[4].Nanoseconds()), metadata)
 
 			job_rl.GaugeKv("rps", float64(GlobalRate.Rate()), metadata)
+			gw.publishCacheStats(jobCache)
+			gw.publishPayloadSizeStats(jobPayloadSize)
 			time.Sleep(5 * time.Second)
 		}
 	}()
@@ -1,5 +1,7 @@
 package gateway
 
+import "context"
+
 // JSRunner abstracts JS expression execution across otto and goja VM engines.
 // Callers build a JS expression string, call Run(), and get back the
 // stringified result. Implementations handle timeout, VM lifecycle, and
@@ -8,6 +10,10 @@ type JSRunner interface {
 	// Run executes a JS expression and returns its string result.
 	Run(expr string) (string, error)
 
+	// RunContext executes a JS expression like Run, the execution is interrupted
+	// as soon as ctx is done.
+	RunContext(ctx context.Context, expr string) (string, error)
+
 	// Ready reports whether the VM has been initialized and is usable.
 	Ready() bool
 }
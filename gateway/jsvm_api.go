@@ -30,6 +30,9 @@ type JSVMAPIHelper struct {
 	Log    *logrus.Entry
 	RawLog *logrus.Logger
 	Store  *storage.RedisCluster
+
+	// httpRequests counts the TykHttpRequest calls of the plugin run.
+	httpRequests int
 }
 
 // JSVM storage binding limits. All keys live under jsvmStoreKeyPrefix so
@@ -129,31 +132,7 @@ func (h *JSVMAPIHelper) MakeHTTPRequest(jsonHRO string) (string, error) {
 	}
 	r.Close = true
 
-	maxSSLVersion := h.Gw.GetConfig().ProxySSLMaxVersion
-	if h.Spec.Proxy.Transport.SSLMaxVersion > 0 {
-		maxSSLVersion = h.Spec.Proxy.Transport.SSLMaxVersion
-	}
-
-	tr := &http.Transport{TLSClientConfig: &tls.Config{
-		MaxVersion: maxSSLVersion,
-	}}
-
-	if cert := h.Gw.getUpstreamCertificate(r.Host, h.Spec); cert != nil {
-		tr.TLSClientConfig.Certificates = []tls.Certificate{*cert}
-	}
-
-	if h.Gw.GetConfig().ProxySSLInsecureSkipVerify {
-		tr.TLSClientConfig.InsecureSkipVerify = true
-	}
-
-	if h.Spec.Proxy.Transport.SSLInsecureSkipVerify {
-		tr.TLSClientConfig.InsecureSkipVerify = true
-	}
-
-	tr.DialTLS = h.Gw.customDialTLSCheck(h.Spec, tr.TLSClientConfig)
-	tr.Proxy = proxyFromAPI(h.Spec)
-
-	client := &http.Client{Transport: tr}
+	client := &http.Client{Transport: h.httpTransport(r.Host)}
 	resp, err := client.Do(r)
 	if err != nil {
 		h.Log.WithError(err).Error("Request failed")
@@ -184,6 +163,35 @@ func (h *JSVMAPIHelper) MakeHTTPRequest(jsonHRO string) (string, error) {
 	return string(retAsStr), nil
 }
 
+// httpTransport is the transport of the HTTP calls plugins make, using the upstream TLS settings of the API.
+func (h *JSVMAPIHelper) httpTransport(host string) *http.Transport {
+	maxSSLVersion := h.Gw.GetConfig().ProxySSLMaxVersion
+	if h.Spec.Proxy.Transport.SSLMaxVersion > 0 {
+		maxSSLVersion = h.Spec.Proxy.Transport.SSLMaxVersion
+	}
+
+	tr := &http.Transport{TLSClientConfig: &tls.Config{
+		MaxVersion: maxSSLVersion,
+	}}
+
+	if cert := h.Gw.getUpstreamCertificate(host, h.Spec); cert != nil {
+		tr.TLSClientConfig.Certificates = []tls.Certificate{*cert}
+	}
+
+	if h.Gw.GetConfig().ProxySSLInsecureSkipVerify {
+		tr.TLSClientConfig.InsecureSkipVerify = true
+	}
+
+	if h.Spec.Proxy.Transport.SSLInsecureSkipVerify {
+		tr.TLSClientConfig.InsecureSkipVerify = true
+	}
+
+	tr.DialTLS = h.Gw.customDialTLSCheck(h.Spec, tr.TLSClientConfig)
+	tr.Proxy = proxyFromAPI(h.Spec)
+
+	return tr
+}
+
 func (h *JSVMAPIHelper) GetKeyData(apiKey, apiID string) string {
 	obj, _ := h.Gw.handleGetDetail(apiKey, apiID, "", false)
 	bs, err := json.Marshal(obj)
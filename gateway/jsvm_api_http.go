@@ -0,0 +1,213 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocraft/health"
+	"github.com/sirupsen/logrus"
+)
+
+// TykHttpRequest defaults, used when the jsvm_http gateway settings are unset.
+const (
+	defaultJSVMHTTPTimeout         = 5 * time.Second
+	defaultJSVMHTTPMaxResponseSize = 1 << 20
+	defaultJSVMHTTPMaxRequests     = 5
+)
+
+var (
+	errJSVMHTTPHostNotAllowed   = errors.New("host is not allowed")
+	errJSVMHTTPBudgetExceeded   = errors.New("request budget exceeded")
+	errJSVMHTTPResponseTooLarge = errors.New("response body too large")
+)
+
+// TykJSControlledHttpRequest is the request object of TykHttpRequest.
+type TykJSControlledHttpRequest struct {
+	TykJSHttpRequest
+
+	// Timeout of the call in seconds, capped at the jsvm_http timeout.
+	Timeout float64
+}
+
+// TykJSControlledHttpResponse is the response object of TykHttpRequest.
+// Error is set when the call was refused or failed.
+type TykJSControlledHttpResponse struct {
+	TykJSHttpResponse
+
+	Error string `json:"Error,omitempty"`
+}
+
+// ControlledHTTPRequest makes the HTTP call described by jsonHRO to an allowed host,
+// bounded by the jsvm_http gateway settings. It always returns a response object,
+// so the plugin can tell a refused or failed call from a response.
+func (h *JSVMAPIHelper) ControlledHTTPRequest(jsonHRO string) string {
+	startTime := time.Now()
+	resp, host, err := h.controlledHTTPRequest(jsonHRO)
+	latency := time.Since(startTime)
+
+	if instrumentationEnabled {
+		job := instrument.NewJob("JSVMHttpRequest")
+		meta := health.Kvs{
+			"host": host,
+			"code": strconv.Itoa(resp.Code),
+		}
+		if err != nil {
+			meta["error"] = err.Error()
+			job.EventKv("failed", meta)
+		}
+		job.TimingKv("exec_time", latency.Nanoseconds(), meta)
+	}
+
+	logger := h.Log.WithFields(logrus.Fields{
+		"host": host,
+		"code": resp.Code,
+		"ns":   latency.Nanoseconds(),
+	})
+
+	out := TykJSControlledHttpResponse{TykJSHttpResponse: resp}
+	if err != nil {
+		logger.WithError(err).Warning("JSVM: HTTP request failed")
+		out.Error = err.Error()
+	} else {
+		logger.Debug("JSVM: HTTP request finished")
+	}
+
+	retAsStr, err := json.Marshal(out)
+	if err != nil {
+		h.Log.WithError(err).Error("JSVM: Failed to encode response")
+		return `{"Code":0,"code":0,"Error":"failed to encode response"}`
+	}
+	return string(retAsStr)
+}
+
+func (h *JSVMAPIHelper) controlledHTTPRequest(jsonHRO string) (TykJSHttpResponse, string, error) {
+	conf := h.Gw.GetConfig().JSVMHTTP
+
+	maxRequests := conf.MaxRequests
+	if maxRequests <= 0 {
+		maxRequests = defaultJSVMHTTPMaxRequests
+	}
+	if h.httpRequests >= maxRequests {
+		return TykJSHttpResponse{}, "", fmt.Errorf("%w: %d requests per run", errJSVMHTTPBudgetExceeded, maxRequests)
+	}
+	h.httpRequests++
+
+	hro := TykJSControlledHttpRequest{}
+	if err := json.Unmarshal([]byte(jsonHRO), &hro); err != nil {
+		return TykJSHttpResponse{}, "", fmt.Errorf("invalid request object: %w", err)
+	}
+
+	u, err := url.ParseRequestURI(hro.Domain + hro.Resource)
+	if err != nil {
+		return TykJSHttpResponse{}, "", err
+	}
+	if err := jsvmHTTPCheckHost(conf.AllowedHosts, u); err != nil {
+		return TykJSHttpResponse{}, u.Host, err
+	}
+
+	timeout := defaultJSVMHTTPTimeout
+	if conf.Timeout > 0 {
+		timeout = time.Duration(conf.Timeout * float64(time.Second))
+	}
+	if requested := time.Duration(hro.Timeout * float64(time.Second)); requested > 0 && requested < timeout {
+		timeout = requested
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	body := hro.Body
+	if body == "" && len(hro.FormData) > 0 {
+		data := url.Values{}
+		for k, v := range hro.FormData {
+			data.Set(k, v)
+		}
+		body = data.Encode()
+	}
+
+	var bodyReader io.Reader
+	if body != "" {
+		bodyReader = strings.NewReader(body)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, hro.Method, u.String(), bodyReader)
+	if err != nil {
+		return TykJSHttpResponse{}, u.Host, err
+	}
+
+	ignoreCanonical := h.Gw.GetConfig().IgnoreCanonicalMIMEHeaderKey
+	for k, v := range hro.Headers {
+		setCustomHeader(r.Header, k, v, ignoreCanonical)
+	}
+	r.Close = true
+
+	client := &http.Client{
+		Transport: h.httpTransport(r.Host),
+		// A redirect must not take the call to a host that isn't allowed.
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return jsvmHTTPCheckHost(conf.AllowedHosts, req.URL)
+		},
+	}
+
+	resp, err := client.Do(r)
+	if err != nil {
+		return TykJSHttpResponse{}, u.Host, err
+	}
+	defer resp.Body.Close()
+
+	maxResponseSize := conf.MaxResponseSize
+	if maxResponseSize <= 0 {
+		maxResponseSize = defaultJSVMHTTPMaxResponseSize
+	}
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return TykJSHttpResponse{}, u.Host, err
+	}
+	if int64(len(respBody)) > maxResponseSize {
+		return TykJSHttpResponse{}, u.Host, fmt.Errorf("%w: limit is %d bytes", errJSVMHTTPResponseTooLarge, maxResponseSize)
+	}
+
+	bodyStr := string(respBody)
+	return TykJSHttpResponse{
+		Code:        resp.StatusCode,
+		Body:        bodyStr,
+		Headers:     resp.Header,
+		CodeComp:    resp.StatusCode,
+		BodyComp:    bodyStr,
+		HeadersComp: resp.Header,
+	}, u.Host, nil
+}
+
+// jsvmHTTPCheckHost checks the URL is an http(s) URL to one of the allowed hosts.
+// An allowed host matches any port unless it has one, and `*.example.com` matches
+// the subdomains of example.com.
+func jsvmHTTPCheckHost(allowed []string, u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	hostname, hostport := strings.ToLower(u.Hostname()), strings.ToLower(u.Host)
+	for _, a := range allowed {
+		a = strings.ToLower(a)
+		if a == hostname || a == hostport {
+			return nil
+		}
+		if domain, ok := strings.CutPrefix(a, "*."); ok && strings.HasSuffix(hostname, "."+domain) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s", errJSVMHTTPHostNotAllowed, u.Host)
+}
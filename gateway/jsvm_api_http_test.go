@@ -0,0 +1,199 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+// tykHttpRequestJS makes the calls listed in the config data of the API and
+// reports the result of each one in the X-Results header.
+const tykHttpRequestJS = `
+var httpMid = new TykJS.TykMiddleware.NewMiddleware({});
+httpMid.NewProcessRequest(function(request, session, spec) {
+	var results = [];
+	var calls = spec.config_data.calls;
+	for (var i = 0; i < calls.length; i++) {
+		var resp = JSON.parse(TykHttpRequest(JSON.stringify(calls[i])));
+		if (resp.Error) {
+			results.push("error: " + resp.Error);
+			continue;
+		}
+		var enriched = resp.Headers["X-Enriched"] ? resp.Headers["X-Enriched"][0] : "";
+		results.push(resp.Code + " " + resp.Body + " " + enriched);
+	}
+	request.SetHeaders["X-Results"] = results.join(" | ");
+	return httpMid.ReturnData(request, {});
+});`
+
+func TestTykHttpRequest(t *testing.T) {
+	var hits atomic.Int64
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(500 * time.Millisecond)
+		case "/big":
+			_, _ = w.Write([]byte(strings.Repeat("a", 2048)))
+			return
+		case "/redirect":
+			http.Redirect(w, r, "http://not-allowed.example/", http.StatusFound)
+			return
+		}
+		w.Header().Set("X-Enriched", "yes")
+		_, _ = w.Write([]byte("pong"))
+	}))
+	defer upstream.Close()
+
+	upstreamURL, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+
+	call := func(domain, resource string, timeout float64) map[string]any {
+		return map[string]any{"Method": "GET", "Domain": domain, "Resource": resource, "Timeout": timeout}
+	}
+
+	testCases := []struct {
+		name      string
+		calls     []any
+		results   []string
+		wantCalls int64
+	}{
+		{
+			name:      "allowed host",
+			calls:     []any{call(upstream.URL, "/enrich", 0)},
+			results:   []string{"200 pong yes"},
+			wantCalls: 1,
+		},
+		{
+			name:      "host not allowed",
+			calls:     []any{call("http://not-allowed.example", "/enrich", 0)},
+			results:   []string{"error: host is not allowed: not-allowed.example"},
+			wantCalls: 0,
+		},
+		{
+			name: "over budget",
+			calls: []any{
+				call(upstream.URL, "/enrich", 0),
+				call(upstream.URL, "/enrich", 0),
+				call(upstream.URL, "/enrich", 0),
+			},
+			results:   []string{"200 pong yes", "200 pong yes", "error: request budget exceeded: 2 requests per run"},
+			wantCalls: 2,
+		},
+		{
+			name:      "timeout",
+			calls:     []any{call(upstream.URL, "/slow", 0.1)},
+			results:   []string{"error: Get \"" + upstream.URL + "/slow\": context deadline exceeded"},
+			wantCalls: 1,
+		},
+		{
+			name:      "response too large",
+			calls:     []any{call(upstream.URL, "/big", 0)},
+			results:   []string{"error: response body too large: limit is 1024 bytes"},
+			wantCalls: 1,
+		},
+		{
+			name:      "redirect to a host not allowed",
+			calls:     []any{call(upstream.URL, "/redirect", 0)},
+			results:   []string{"error: Get \"http://not-allowed.example/\": host is not allowed: not-allowed.example"},
+			wantCalls: 1,
+		},
+	}
+
+	for _, driver := range drivers {
+		t.Run(string(driver), func(t *testing.T) {
+			ts := StartTest(func(globalConf *config.Config) {
+				globalConf.JSVMHTTP = config.JSVMHTTPConfig{
+					AllowedHosts:    []string{upstreamURL.Host},
+					MaxRequests:     2,
+					MaxResponseSize: 1024,
+				}
+			})
+			defer ts.Close()
+
+			apiID := "tyk-http-request-" + string(driver)
+			ts.RegisterJSFileMiddleware(apiID, map[string]string{"http.js": tykHttpRequestJS})
+
+			for _, tc := range testCases {
+				t.Run(tc.name, func(t *testing.T) {
+					ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+						spec.APIID = apiID
+						spec.Proxy.ListenPath = "/" + apiID + "/"
+						spec.ConfigData = map[string]any{"calls": tc.calls}
+						spec.CustomMiddleware = apidef.MiddlewareSection{
+							Driver: driver,
+							Pre: []apidef.MiddlewareDefinition{{
+								Name: "httpMid",
+								Path: ts.Gw.GetConfig().MiddlewarePath + "/" + apiID + "/http.js",
+							}},
+						}
+					})
+
+					// The budget is per run, so a second request gets a fresh one.
+					for i := 1; i <= 2; i++ {
+						hits.Store(0)
+
+						resp, err := ts.Run(t, test.TestCase{Path: "/" + apiID + "/get", Code: http.StatusOK})
+						require.NoError(t, err)
+
+						var body struct {
+							Headers map[string]string
+						}
+						require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+						assert.Equal(t, strings.Join(tc.results, " | "), body.Headers["X-Results"], "request %d", i)
+						assert.Equal(t, tc.wantCalls, hits.Load(), "request %d", i)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestJSVMHTTPCheckHost(t *testing.T) {
+	allowed := []string{"enrich.internal", "api.example.com:8443", "*.svc.cluster.local"}
+
+	testCases := []struct {
+		url     string
+		allowed bool
+	}{
+		{"http://enrich.internal/path", true},
+		{"https://ENRICH.internal:9000/path", true},
+		{"https://api.example.com:8443/", true},
+		{"https://api.example.com/", false},
+		{"http://users.svc.cluster.local/", true},
+		{"http://svc.cluster.local/", false},
+		{"http://enrich.internal.evil.com/", false},
+		{"ftp://enrich.internal/", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.url, func(t *testing.T) {
+			u, err := url.Parse(tc.url)
+			require.NoError(t, err)
+
+			err = jsvmHTTPCheckHost(allowed, u)
+			if tc.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	u, err := url.Parse("http://enrich.internal/")
+	require.NoError(t, err)
+	assert.ErrorIs(t, jsvmHTTPCheckHost(nil, u), errJSVMHTTPHostNotAllowed)
+}
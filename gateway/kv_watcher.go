@@ -0,0 +1,156 @@
+package gateway
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/scheduler"
+)
+
+const defaultKVWatchInterval = 30 * time.Second
+
+// kvWatchedValue is a secret of the Gateway configuration which can be stored in Consul or Vault.
+type kvWatchedValue struct {
+	field string
+	get   func(*config.Config) string
+	set   func(*config.Config, string)
+}
+
+// kvWatchedValues are the secrets refreshed by the KV watcher, the components using them are updated in place.
+var kvWatchedValues = []kvWatchedValue{
+	{
+		field: "secret",
+		get:   func(c *config.Config) string { return c.Secret },
+		set:   func(c *config.Config, v string) { c.Secret = v },
+	},
+	{
+		field: "node_secret",
+		get:   func(c *config.Config) string { return c.NodeSecret },
+		set:   func(c *config.Config, v string) { c.NodeSecret = v },
+	},
+	{
+		field: "storage.password",
+		get:   func(c *config.Config) string { return c.Storage.Password },
+		set:   func(c *config.Config, v string) { c.Storage.Password = v },
+	},
+	{
+		field: "cache_storage.password",
+		get:   func(c *config.Config) string { return c.CacheStorage.Password },
+		set:   func(c *config.Config, v string) { c.CacheStorage.Password = v },
+	},
+	{
+		field: "security.private_certificate_encoding_secret",
+		get:   func(c *config.Config) string { return c.Security.PrivateCertificateEncodingSecret },
+		set:   func(c *config.Config, v string) { c.Security.PrivateCertificateEncodingSecret = v },
+	},
+}
+
+// secretSetter is implemented by the components holding a copy of a rotated secret.
+type secretSetter interface {
+	SetSecret(secret string)
+}
+
+// isWatchedKVReference reports whether the configuration value is resolved from Consul or Vault.
+func isWatchedKVReference(value string) bool {
+	return strings.HasPrefix(value, "consul://") || strings.HasPrefix(value, "vault://")
+}
+
+// startKVWatcher polls the Consul and Vault secrets of the configuration when the KV watch is enabled.
+func (gw *Gateway) startKVWatcher() {
+	conf := gw.GetConfig().KV.Watch
+	if !conf.Enabled {
+		return
+	}
+
+	interval := defaultKVWatchInterval
+	if conf.Interval > 0 {
+		interval = time.Duration(conf.Interval) * time.Second
+	}
+
+	job := scheduler.NewJob("kv-watcher", gw.refreshKVSecrets, interval)
+	go scheduler.NewScheduler(log).Start(gw.ctx, job)
+}
+
+// refreshKVSecrets resolves the watched secrets again and applies the changed ones. Either all the changes
+// are applied, or none if a value can't be resolved or Redis can't be reconnected with the new password.
+func (gw *Gateway) refreshKVSecrets() error {
+	unresolved := gw.unresolvedConfig.Load()
+	if unresolved == nil {
+		return nil
+	}
+
+	gw.kvWatchMu.Lock()
+	defer gw.kvWatchMu.Unlock()
+
+	previous := gw.GetConfig()
+	conf := previous
+
+	var changed []string
+	for _, value := range kvWatchedValues {
+		reference := value.get(unresolved)
+		if !isWatchedKVReference(reference) {
+			continue
+		}
+
+		resolved, err := gw.kvStore(reference)
+		if err != nil {
+			return fmt.Errorf("could not refresh %s from the KV store: %w", value.field, err)
+		}
+		// kvStore returns the reference as is when the KV store can't be set up.
+		if resolved == reference {
+			return fmt.Errorf("could not refresh %s, the KV store is not available", value.field)
+		}
+
+		if resolved != value.get(&conf) {
+			value.set(&conf, resolved)
+			changed = append(changed, value.field)
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	if conf.Storage.Password != previous.Storage.Password || conf.CacheStorage.Password != previous.CacheStorage.Password {
+		if err := gw.StorageConnectionHandler.Reconnect(conf); err != nil {
+			return fmt.Errorf("could not reconnect to Redis with the rotated password: %w", err)
+		}
+	}
+
+	gw.SetConfig(conf)
+
+	if conf.NodeSecret != previous.NodeSecret {
+		if dashboard, ok := gw.DashService.(secretSetter); ok {
+			dashboard.SetSecret(conf.NodeSecret)
+		}
+	}
+
+	if certificateSecret(conf) != certificateSecret(previous) {
+		if certificateManager, ok := gw.CertificateManager.(secretSetter); ok {
+			certificateManager.SetSecret(certificateSecret(conf))
+		}
+	}
+
+	mainLog.WithFields(logrus.Fields{
+		"fields": changed,
+	}).Info("Applied the secrets rotated in the KV store")
+
+	gw.FireSystemEvent(EventKVSecretsRotated, EventKVSecretsRotatedMeta{
+		EventMetaDefault: EventMetaDefault{Message: "Applied the secrets rotated in the KV store"},
+		Fields:           changed,
+	})
+
+	return nil
+}
+
+// certificateSecret returns the secret encrypting the private keys of the certificates.
+func certificateSecret(conf config.Config) string {
+	if conf.Security.PrivateCertificateEncodingSecret != "" {
+		return conf.Security.PrivateCertificateEncodingSecret
+	}
+	return conf.Secret
+}
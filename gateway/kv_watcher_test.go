@@ -0,0 +1,169 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/certs"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/redis"
+	"github.com/TykTechnologies/tyk/storage"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+// secretRecordingCertManager records the secrets set on the certificate manager.
+type secretRecordingCertManager struct {
+	certs.CertificateManager
+	secret string
+}
+
+func (m *secretRecordingCertManager) SetSecret(secret string) {
+	m.secret = secret
+}
+
+// redisPassword returns the password of the current Redis connection of the store.
+func redisPassword(t *testing.T, store *storage.RedisCluster) string {
+	t.Helper()
+
+	client, err := store.Client()
+	require.NoError(t, err)
+
+	redisClient, ok := client.(*redis.Client)
+	require.True(t, ok)
+
+	return redisClient.Options().Password
+}
+
+func TestRefreshKVSecrets(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.NodeSecret = "node-secret"
+		globalConf.Security.PrivateCertificateEncodingSecret = "cert-secret"
+	})
+	defer ts.Close()
+
+	store := &mockKVStore{store: map[string]string{
+		"tyk/secret":         ts.Gw.GetConfig().Secret,
+		"tyk/node_secret":    "node-secret",
+		"tyk/cert_secret":    "cert-secret",
+		"tyk/redis_password": "",
+	}}
+	ts.Gw.consulKVStore = store
+	ts.Gw.vaultKVStore = store
+
+	unresolved := ts.Gw.GetConfig()
+	unresolved.Secret = "consul://tyk/secret"
+	unresolved.NodeSecret = "consul://tyk/node_secret"
+	unresolved.Security.PrivateCertificateEncodingSecret = "vault://tyk/cert_secret"
+	unresolved.Storage.Password = "consul://tyk/redis_password"
+	ts.Gw.unresolvedConfig.Store(&unresolved)
+
+	certManager := &secretRecordingCertManager{CertificateManager: ts.Gw.CertificateManager}
+	ts.Gw.CertificateManager = certManager
+
+	var (
+		heartbeatMu   sync.Mutex
+		heartbeatAuth string
+	)
+	dashboard := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		heartbeatMu.Lock()
+		heartbeatAuth = r.Header.Get("authorization")
+		heartbeatMu.Unlock()
+		_, _ = w.Write([]byte(`{"nonce":"nonce"}`))
+	}))
+	defer dashboard.Close()
+
+	dashService := &HTTPDashboardHandler{Gw: ts.Gw, Secret: "node-secret", HeartBeatEndpoint: dashboard.URL}
+	ts.Gw.DashService = dashService
+	heartbeat := dashService.newRequest(http.MethodGet, dashService.HeartBeatEndpoint)
+
+	rotated := make(chan EventKVSecretsRotatedMeta, 4)
+	conf := ts.Gw.GetConfig()
+	conf.SetEventTriggers(map[apidef.TykEvent][]config.TykEventHandler{
+		EventKVSecretsRotated: {&testEventHandler{cb: func(em config.EventMessage) {
+			rotated <- em.Meta.(EventKVSecretsRotatedMeta)
+		}}},
+	})
+	ts.Gw.SetConfig(conf)
+
+	redisStore := &storage.RedisCluster{KeyPrefix: "kv-watcher-", ConnectionHandler: ts.Gw.StorageConnectionHandler}
+
+	t.Run("unchanged", func(t *testing.T) {
+		require.NoError(t, ts.Gw.refreshKVSecrets())
+		assert.Empty(t, certManager.secret)
+	})
+
+	t.Run("rotated", func(t *testing.T) {
+		previousSecret := ts.Gw.GetConfig().Secret
+		store.store["tyk/secret"] = "rotated-secret"
+		store.store["tyk/node_secret"] = "rotated-node-secret"
+		store.store["tyk/cert_secret"] = "rotated-cert-secret"
+		store.store["tyk/redis_password"] = "rotated-password"
+
+		require.NoError(t, ts.Gw.refreshKVSecrets())
+
+		conf := ts.Gw.GetConfig()
+		assert.Equal(t, "rotated-node-secret", conf.NodeSecret)
+		assert.Equal(t, "rotated-cert-secret", conf.Security.PrivateCertificateEncodingSecret)
+		assert.Equal(t, "rotated-password", conf.Storage.Password)
+
+		assert.Equal(t, "rotated-cert-secret", certManager.secret)
+
+		require.NoError(t, dashService.doHeartBeat(heartbeat, http.DefaultClient))
+		heartbeatMu.Lock()
+		assert.Equal(t, "rotated-node-secret", heartbeatAuth, "the heartbeat sends the rotated node secret")
+		heartbeatMu.Unlock()
+
+		assert.Equal(t, "rotated-password", redisPassword(t, redisStore), "Redis is reconnected with the rotated password")
+		assert.NoError(t, redisStore.SetKey("key", "value", 0))
+
+		_, password := ts.Gw.rateLimiterCredentials()
+		assert.Equal(t, "rotated-password", password, "the rate limiter connects with the rotated password")
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/tyk/apis", Headers: map[string]string{"x-tyk-authorization": "rotated-secret"}, Code: http.StatusOK},
+			{Path: "/tyk/apis", Headers: map[string]string{"x-tyk-authorization": previousSecret}, Code: http.StatusForbidden},
+		}...)
+
+		select {
+		case meta := <-rotated:
+			assert.Equal(t, []string{"secret", "node_secret", "storage.password", "security.private_certificate_encoding_secret"}, meta.Fields)
+		case <-time.After(time.Second):
+			t.Fatal("the rotation event wasn't fired")
+		}
+		assert.Empty(t, rotated, "the rotation is applied at once")
+	})
+
+	t.Run("unresolved value", func(t *testing.T) {
+		store.store["tyk/node_secret"] = "ignored-node-secret"
+		delete(store.store, "tyk/cert_secret")
+
+		assert.Error(t, ts.Gw.refreshKVSecrets())
+		assert.Equal(t, "rotated-node-secret", ts.Gw.GetConfig().NodeSecret, "no change is applied")
+	})
+
+	t.Run("rotated back", func(t *testing.T) {
+		store.store["tyk/node_secret"] = "node-secret"
+		store.store["tyk/cert_secret"] = "cert-secret"
+		store.store["tyk/redis_password"] = ""
+
+		require.NoError(t, ts.Gw.refreshKVSecrets())
+
+		assert.Equal(t, "cert-secret", certManager.secret)
+		assert.Empty(t, redisPassword(t, redisStore))
+		assert.NoError(t, redisStore.SetKey("key", "value", 0))
+
+		select {
+		case meta := <-rotated:
+			assert.Equal(t, []string{"node_secret", "storage.password", "security.private_certificate_encoding_secret"}, meta.Fields)
+		case <-time.After(time.Second):
+			t.Fatal("the rotation event wasn't fired")
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package gateway
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TykTechnologies/tyk/ctx"
+)
+
+// latencyStage is a stage of the request the latency breakdown attributes time to.
+type latencyStage int
+
+// Stages of the latency breakdown, in the order they are reported.
+const (
+	latencyStageAuth latencyStage = iota
+	latencyStageRateLimit
+	latencyStageTransform
+	latencyStagePlugin
+	latencyStageProxy
+	latencyStageOther
+
+	latencyStageCount
+)
+
+const latencyBreakdownTagPrefix = "latency-breakdown-"
+
+var latencyStageNames = [latencyStageCount]string{
+	latencyStageAuth:      "auth",
+	latencyStageRateLimit: "rate_limit",
+	latencyStageTransform: "transform",
+	latencyStagePlugin:    "plugin",
+	latencyStageProxy:     "proxy",
+	latencyStageOther:     "other",
+}
+
+// latencyBreakdown is the time spent in each stage of a request. A nil breakdown records nothing,
+// so requests to APIs without the latency breakdown don't pay for it.
+type latencyBreakdown struct {
+	stages [latencyStageCount]time.Duration
+}
+
+// requestLatencyStage returns the stage the time spent in a request middleware is attributed to.
+func requestLatencyStage(mw TykMiddleware) latencyStage {
+	switch mw.(type) {
+	case *AuthKey, *BasicAuthKeyIsValid, *JWTMiddleware, *Oauth2KeyExists, *OpenIDMW, *ExternalOAuthMiddleware,
+		*HTTPSignatureValidationMiddleware, *CertificateCheckMW, *AuthORWrapper, *KeyExpired, *AccessRightsCheck:
+		return latencyStageAuth
+	case *RateLimitAndQuotaCheck, *RateLimitForAPI, *RateCheckMW, *ConcurrencyLimit:
+		return latencyStageRateLimit
+	case *TransformMiddleware, *TransformJQMiddleware, *TransformHeaders, *TransformMethod, *URLRewriteMiddleware:
+		return latencyStageTransform
+	case *DynamicMiddleware, *CoProcessMiddleware, *GoPluginMiddleware, *VirtualEndpoint:
+		return latencyStagePlugin
+	default:
+		return latencyStageOther
+	}
+}
+
+// responseLatencyStage returns the stage the time spent in a response middleware is attributed to.
+func responseLatencyStage(rh TykResponseHandler) latencyStage {
+	switch rh.(type) {
+	case *ResponseTransformMiddleware, *ResponseTransformJQMiddleware, *HeaderTransform:
+		return latencyStageTransform
+	case *CustomMiddlewareResponseHook, *JSResponseMiddleware, *ResponseGoPluginMiddleware:
+		return latencyStagePlugin
+	default:
+		return latencyStageOther
+	}
+}
+
+// ctxGetOrCreateLatencyBreakdown returns the latency breakdown of the request, starting it on the first call.
+func ctxGetOrCreateLatencyBreakdown(r *http.Request) *latencyBreakdown {
+	if b := ctxGetLatencyBreakdown(r); b != nil {
+		return b
+	}
+
+	b := &latencyBreakdown{}
+	setCtxValue(r, ctx.LatencyBreakdown, b)
+	return b
+}
+
+func ctxGetLatencyBreakdown(r *http.Request) *latencyBreakdown {
+	b, _ := r.Context().Value(ctx.LatencyBreakdown).(*latencyBreakdown)
+	return b
+}
+
+// add attributes d to the stage. It's a no-op when the breakdown isn't enabled.
+func (b *latencyBreakdown) add(stage latencyStage, d time.Duration) {
+	if b == nil {
+		return
+	}
+
+	b.stages[stage] += d
+}
+
+// since attributes the time elapsed since start to the stage. It's a no-op when the breakdown isn't enabled.
+func (b *latencyBreakdown) since(stage latencyStage, start time.Time) {
+	if b == nil {
+		return
+	}
+
+	b.add(stage, time.Since(start))
+}
+
+// format renders the stages above threshold as `stage=ms` pairs, e.g. `auth=2,plugin=120,proxy=35`.
+func (b *latencyBreakdown) format(threshold int64) string {
+	if b == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	for stage, d := range b.stages {
+		ms := d.Milliseconds()
+		if ms <= threshold {
+			continue
+		}
+
+		if sb.Len() > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(latencyStageNames[stage])
+		sb.WriteByte('=')
+		sb.WriteString(strconv.FormatInt(ms, 10))
+	}
+
+	return sb.String()
+}
+
+// latencyBreakdownTag returns the analytics tag holding the latency breakdown of the request.
+func latencyBreakdownTag(r *http.Request, spec *APISpec) (string, bool) {
+	if !spec.LatencyBreakdown.Enabled {
+		return "", false
+	}
+
+	breakdown := ctxGetLatencyBreakdown(r).format(spec.LatencyBreakdown.Threshold)
+	if breakdown == "" {
+		return "", false
+	}
+
+	return latencyBreakdownTagPrefix + breakdown, true
+}
@@ -0,0 +1,144 @@
+package gateway
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestLatencyBreakdown_Format(t *testing.T) {
+	var disabled *latencyBreakdown
+	disabled.add(latencyStageAuth, time.Second)
+	disabled.since(latencyStageProxy, time.Now())
+	assert.Empty(t, disabled.format(0))
+
+	b := &latencyBreakdown{}
+	b.add(latencyStageProxy, 35*time.Millisecond)
+	b.add(latencyStageAuth, 2*time.Millisecond)
+	b.add(latencyStagePlugin, 100*time.Millisecond)
+	b.add(latencyStagePlugin, 20*time.Millisecond)
+	b.add(latencyStageTransform, 500*time.Microsecond)
+
+	assert.Equal(t, "auth=2,plugin=120,proxy=35", b.format(0))
+	assert.Equal(t, "plugin=120", b.format(35))
+	assert.Empty(t, b.format(120))
+}
+
+func TestLatencyBreakdown(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.RegisterJSFileMiddleware("latency_breakdown", map[string]string{
+		"slow.js": `var slow = new TykJS.TykMiddleware.NewMiddleware({});
+
+slow.NewProcessRequest(function(request, session) {
+    var start = new Date().getTime();
+    while (new Date().getTime() - start < 200) {}
+
+    return slow.ReturnData(request, {});
+});`,
+	})
+
+	loadAPI := func(breakdown apidef.LatencyBreakdown) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.APIID = "latency-breakdown"
+			spec.Proxy.ListenPath = "/slow/"
+			spec.LatencyBreakdown = breakdown
+			spec.CustomMiddleware = apidef.MiddlewareSection{
+				Driver: apidef.OttoDriver,
+				Pre: []apidef.MiddlewareDefinition{{
+					Name: "slow",
+					Path: ts.Gw.GetConfig().MiddlewarePath + "/latency_breakdown/slow.js",
+				}},
+			}
+		})
+	}
+
+	redisAnalyticsKeyName := analyticsKeyName + ts.Gw.Analytics.analyticsSerializer.GetSuffix()
+	recordedTags := func() []string {
+		t.Helper()
+
+		ts.Gw.Analytics.Flush()
+		results := ts.Gw.Analytics.Store.GetAndDeleteSet(redisAnalyticsKeyName)
+		require.Len(t, results, 1)
+
+		var record analytics.AnalyticsRecord
+		require.NoError(t, ts.Gw.Analytics.analyticsSerializer.Decode([]byte(results[0].(string)), &record))
+		return record.Tags
+	}
+
+	ts.Gw.Analytics.Flush()
+	ts.Gw.Analytics.Store.GetAndDeleteSet(redisAnalyticsKeyName)
+
+	t.Run("enabled", func(t *testing.T) {
+		loadAPI(apidef.LatencyBreakdown{Enabled: true, Threshold: 50, DebugHeaders: true})
+
+		resp, err := ts.Run(t, test.TestCase{Path: "/slow/", Code: http.StatusOK})
+		require.NoError(t, err)
+
+		stages := parseLatencyBreakdown(t, resp.Header.Get(header.XTykLatencyBreakdown))
+		assert.GreaterOrEqual(t, stages["plugin"], int64(200))
+		assert.Len(t, stages, 1, "only the slow plugin is above the threshold")
+
+		var tag string
+		for _, recorded := range recordedTags() {
+			if strings.HasPrefix(recorded, latencyBreakdownTagPrefix) {
+				tag = recorded
+			}
+		}
+		assert.Equal(t, stages, parseLatencyBreakdown(t, strings.TrimPrefix(tag, latencyBreakdownTagPrefix)))
+	})
+
+	t.Run("debug headers disabled", func(t *testing.T) {
+		loadAPI(apidef.LatencyBreakdown{Enabled: true, Threshold: 50})
+
+		resp, err := ts.Run(t, test.TestCase{Path: "/slow/", Code: http.StatusOK})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Header.Get(header.XTykLatencyBreakdown))
+
+		var found bool
+		for _, recorded := range recordedTags() {
+			found = found || strings.HasPrefix(recorded, latencyBreakdownTagPrefix+"plugin=")
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		loadAPI(apidef.LatencyBreakdown{DebugHeaders: true})
+
+		resp, err := ts.Run(t, test.TestCase{Path: "/slow/", Code: http.StatusOK})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Header.Get(header.XTykLatencyBreakdown))
+
+		for _, recorded := range recordedTags() {
+			assert.NotContains(t, recorded, latencyBreakdownTagPrefix)
+		}
+	})
+}
+
+func parseLatencyBreakdown(t *testing.T, breakdown string) map[string]int64 {
+	t.Helper()
+
+	stages := map[string]int64{}
+	for _, pair := range strings.Split(breakdown, ",") {
+		stage, ms, ok := strings.Cut(pair, "=")
+		require.True(t, ok, breakdown)
+
+		value, err := strconv.ParseInt(ms, 10, 64)
+		require.NoError(t, err)
+		stages[stage] = value
+	}
+
+	return stages
+}
@@ -0,0 +1,42 @@
+package gateway
+
+import (
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/rate"
+)
+
+// clusterLimiterKeys tells whether the rate limit and quota keys hold the session identifier
+// in a hash tag. On Redis Cluster this keeps all the counters of a session in the same hash
+// slot, so they can be updated together by scripts and transactions.
+func clusterLimiterKeys(conf *config.Config) bool {
+	return conf.GetRateLimiterStorage().EnableCluster
+}
+
+// quotaKeyName returns the redis key of the quota counter for the allowance scope, keyName
+// being the key ID, its hash, or the custom quota key.
+func quotaKeyName(conf *config.Config, scope, keyName string) string {
+	if clusterLimiterKeys(conf) {
+		keyName = rate.HashTag(keyName)
+	}
+
+	return legacyQuotaKeyName(scope, keyName)
+}
+
+// legacyQuotaKeyName returns the quota key name used before the keys were hash-tagged. The
+// counters stored under it are migrated to the new key within their quota period.
+func legacyQuotaKeyName(scope, keyName string) string {
+	if scope != "" {
+		scope += "-"
+	}
+
+	return QuotaKeyPrefix + scope + keyName
+}
+
+// rateLimitSentinelKeyName returns the sentinel key of the session rate limiter.
+func rateLimitSentinelKeyName(conf *config.Config, keyName string) string {
+	if clusterLimiterKeys(conf) {
+		keyName = rate.HashTag(keyName)
+	}
+
+	return RateLimitKeyPrefix + keyName + SentinelRateLimitKeyPostfix
+}
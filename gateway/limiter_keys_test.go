@@ -0,0 +1,118 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/rate/mock"
+	"github.com/TykTechnologies/tyk/storage"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestQuotaKeyName(t *testing.T) {
+	conf := &config.Config{}
+	assert.Equal(t, "quota-abc", quotaKeyName(conf, "", "abc"))
+	assert.Equal(t, "quota-api-abc", quotaKeyName(conf, "api", "abc"))
+	assert.Equal(t, "rate-limit-abc.BLOCKED", rateLimitSentinelKeyName(conf, "abc"))
+
+	conf.Storage.EnableCluster = true
+	assert.Equal(t, "quota-{abc}", quotaKeyName(conf, "", "abc"))
+	assert.Equal(t, "quota-api-{abc}", quotaKeyName(conf, "api", "abc"))
+	assert.Equal(t, "rate-limit-{abc}.BLOCKED", rateLimitSentinelKeyName(conf, "abc"))
+	assert.Equal(t, "quota-api-abc", legacyQuotaKeyName("api", "abc"))
+}
+
+func TestClusterLimiterKeys(t *testing.T) {
+	for _, hashKeys := range []bool{false, true} {
+		t.Run(fmt.Sprintf("hash keys %v", hashKeys), func(t *testing.T) {
+			testClusterLimiterKeys(t, hashKeys)
+		})
+	}
+}
+
+func testClusterLimiterKeys(t *testing.T, hashKeys bool) {
+	t.Helper()
+
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.HashKeys = hashKeys
+		globalConf.EnableRedisRollingLimiter = true
+	})
+	defer ts.Close()
+
+	// The limiter storage is a single redis node, rejecting the operations
+	// Redis Cluster would reject as the keys are in different hash slots.
+	conf := ts.Gw.GetConfig()
+	conf.Storage.EnableCluster = true
+	ts.Gw.SetConfig(conf)
+	ts.Gw.SessionLimiter.config.Storage.EnableCluster = true
+
+	cluster := &mock.Cluster{}
+	conn := ts.Gw.SessionLimiter.limiterStorage
+	conn.AddHook(cluster)
+
+	const apiID = "cluster-limiter-keys"
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = apiID
+		spec.UseKeylessAccess = false
+		spec.Proxy.ListenPath = "/"
+	})
+
+	session := CreateStandardSession()
+	session.Rate = 100
+	session.Per = 60
+	session.QuotaMax = 10
+	session.QuotaRenewalRate = 3600
+	session.AccessRights = map[string]user.AccessDefinition{apiID: {APIID: apiID, Versions: []string{"v1"}}}
+
+	key := CreateSession(ts.Gw, func(s *user.SessionState) { *s = session.Clone() })
+	authHeaders := map[string]string{header.Authorization: key}
+
+	keyHash := storage.HashKey(key, hashKeys)
+	quotaKey := "quota-{" + keyHash + "}"
+	legacyQuotaKey := "quota-" + keyHash
+
+	ctx := context.Background()
+	require.NoError(t, conn.Set(ctx, legacyQuotaKey, 3, time.Hour).Err())
+
+	t.Run("legacy counter is migrated", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/", Headers: authHeaders, Code: http.StatusOK},
+			{Path: "/", Headers: authHeaders, Code: http.StatusOK},
+		}...)
+
+		assert.Equal(t, "5", conn.Get(ctx, quotaKey).Val())
+		assert.InDelta(t, time.Hour, conn.PTTL(ctx, quotaKey).Val(), float64(time.Minute))
+		assert.Equal(t, "3", conn.Get(ctx, legacyQuotaKey).Val(), "the legacy counter is left to expire")
+		assert.EqualValues(t, 1, conn.Exists(ctx, "rate-limit-{"+keyHash+"}").Val())
+	})
+
+	t.Run("quota is enforced", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			_, _ = ts.Run(t, test.TestCase{Path: "/", Headers: authHeaders, Code: http.StatusOK})
+		}
+		_, _ = ts.Run(t, test.TestCase{Path: "/", Headers: authHeaders, Code: http.StatusForbidden})
+
+		assert.Equal(t, "11", conn.Get(ctx, quotaKey).Val())
+	})
+
+	t.Run("reset quota", func(t *testing.T) {
+		ts.Gw.GlobalSessionManager.ResetQuota(key, session, false)
+
+		assert.Zero(t, conn.Exists(ctx, quotaKey).Val())
+		assert.Zero(t, conn.Exists(ctx, legacyQuotaKey).Val())
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/", Headers: authHeaders, Code: http.StatusOK})
+		assert.Equal(t, "1", conn.Get(ctx, quotaKey).Val())
+	})
+
+	assert.Empty(t, cluster.CrossSlot())
+}
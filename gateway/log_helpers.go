@@ -13,8 +13,10 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/TykTechnologies/tyk/ctx"
 	"github.com/TykTechnologies/tyk/internal/otel"
 	"github.com/TykTechnologies/tyk/request"
+	"github.com/TykTechnologies/tyk/storage"
 )
 
 // identifies that field value was hidden before output to the log
@@ -65,6 +67,40 @@ func (gw *Gateway) getLogEntryForRequest(logger *logrus.Entry, r *http.Request,
 	return logger.WithFields(fields)
 }
 
+// requestLogEntry returns the entry correlating the log lines of r with the API serving it, its key and its request ID.
+// It's created when r enters the middleware chain of spec and kept in the request context,
+// the key hash and request ID fields are added once known.
+func (gw *Gateway) requestLogEntry(spec *APISpec, r *http.Request) *logrus.Entry {
+	current := ctx.GetLogEntry(r)
+
+	entry := current
+	if entry == nil || entry.Data["api_id"] != spec.APIID {
+		entry = logrus.NewEntry(log).WithFields(logrus.Fields{
+			"api_id":   spec.APIID,
+			"api_name": spec.Name,
+			"org_id":   spec.OrgID,
+		})
+	}
+
+	if _, ok := entry.Data["request_id"]; !ok {
+		if requestID := ctx.GetRequestID(r); requestID != nil && requestID.ID != "" {
+			entry = entry.WithField("request_id", requestID.ID)
+		}
+	}
+
+	if _, ok := entry.Data["key_hash"]; !ok {
+		if token := ctxGetAuthToken(r); token != "" {
+			entry = entry.WithField("key_hash", storage.HashStr(token))
+		}
+	}
+
+	if entry != current {
+		ctx.SetLogEntry(r, entry)
+	}
+
+	return entry
+}
+
 func (gw *Gateway) getExplicitLogEntryForRequest(logger *logrus.Entry, path string, IP string, key string, data map[string]interface{}) *logrus.Entry {
 	// populate http request fields
 	fields := logrus.Fields{
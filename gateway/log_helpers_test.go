@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io"
 	"net"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"reflect"
@@ -15,8 +16,12 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/TykTechnologies/tyk/ctx"
 	"github.com/TykTechnologies/tyk/internal/otel"
+	"github.com/TykTechnologies/tyk/storage"
+	"github.com/TykTechnologies/tyk/user"
 )
 
 func TestGetLogEntryForRequest(t *testing.T) {
@@ -365,3 +370,90 @@ func TestGetLogEntryForRequest_TraceAndSpanIDs(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateMiddleware_RequestLogFields(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	spec := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "log-api"
+		spec.Name = "Log API"
+		spec.OrgID = "log-org"
+		spec.Proxy.ListenPath = "/"
+		spec.ConfigData = map[string]interface{}{"custom_data": "error"}
+	})[0]
+
+	logger, hook := test.NewNullLogger()
+	logger.SetLevel(logrus.DebugLevel)
+
+	base := NewBaseMiddleware(ts.Gw, spec, nil, logrus.NewEntry(logger))
+	handler := ts.Gw.createMiddleware(&modifiedMiddleware{BaseMiddleware: base})(http.NotFoundHandler())
+
+	newRequest := func() *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		ctxSetSession(r, &user.SessionState{KeyID: "log-key"}, false, false)
+		ctx.SetRequestID(r, &ctx.RequestIDData{Header: "X-Request-Id", ID: "log-request"})
+		return r
+	}
+
+	expected := logrus.Fields{
+		"api_id":     "log-api",
+		"api_name":   "Log API",
+		"org_id":     "log-org",
+		"key_hash":   storage.HashStr("log-key"),
+		"request_id": "log-request",
+		"mw":         "modifiedMiddleware",
+	}
+
+	t.Run("middleware error log", func(t *testing.T) {
+		hook.Reset()
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest())
+
+		var finished *logrus.Entry
+		for _, entry := range hook.AllEntries() {
+			if entry.Message == "Finished" && entry.Data[logrus.ErrorKey] != nil {
+				finished = entry
+			}
+		}
+		require.NotNil(t, finished)
+
+		for field, value := range expected {
+			assert.Equal(t, value, finished.Data[field], field)
+		}
+	})
+
+	t.Run("middleware logger", func(t *testing.T) {
+		r := newRequest()
+		requestLogger := base.SetRequestLogger(r)
+
+		for field, value := range expected {
+			assert.Equal(t, value, requestLogger.Data[field], field)
+		}
+		assert.Equal(t, "log-api", ctx.GetLogEntry(r).Data["api_id"])
+
+		// The middleware is shared by concurrent requests, the request fields are kept off it.
+		assert.NotContains(t, base.Logger().Data, "request_id")
+		assert.NotContains(t, base.Logger().Data, "key_hash")
+	})
+}
+
+func BenchmarkSetRequestLogger(b *testing.B) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	spec := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+	})[0]
+
+	logger, _ := test.NewNullLogger()
+	base := NewBaseMiddleware(ts.Gw, spec, nil, logrus.NewEntry(logger))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctxSetSession(r, &user.SessionState{KeyID: "bench-key"}, false, false)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		base.SetRequestLogger(r)
+	}
+}
@@ -7,14 +7,21 @@ package gateway
 import (
 	"encoding/json"
 	"net/http"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
 
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/apidef/oas"
+	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/test"
 	"github.com/TykTechnologies/tyk/user"
 )
@@ -240,7 +247,7 @@ func TestLooping(t *testing.T) {
 		})
 
 		ts.Run(t, []test.TestCase{
-			{Method: "GET", Path: "/recursion", Code: 500, BodyMatch: "Loop level too deep. Found more than 2 loops in single request"},
+			{Method: "GET", Path: "/recursion", Code: http.StatusLoopDetected, BodyMatch: "Loop level too deep. Found more than 2 loops in single request"},
 		}...)
 	})
 
@@ -506,6 +513,109 @@ func TestLooping_AnotherAPIWithAuthTokens(t *testing.T) {
 	}...)
 }
 
+func TestLooping_MaxDepth(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.MaxLoopDepth = 3
+	}, TestConfig{Delay: 20 * time.Millisecond})
+	defer ts.Close()
+
+	rewriteTo := func(target string) func(*apidef.VersionInfo) {
+		return func(v *apidef.VersionInfo) {
+			v.UseExtendedPaths = true
+			v.ExtendedPaths.URLRewrite = []apidef.URLRewriteMeta{{
+				Path:         "/",
+				Method:       http.MethodGet,
+				MatchPattern: ".*",
+				RewriteTo:    target,
+			}}
+		}
+	}
+
+	internalHeader := func(v *apidef.VersionInfo) {
+		v.GlobalHeaders = map[string]string{apidef.TykInternalApiHeader: "true"}
+	}
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "rewrite-a"
+		spec.Proxy.ListenPath = "/rewrite-a/"
+		UpdateAPIVersion(spec, "v1", rewriteTo("tyk://rewrite-b/"))
+	}, func(spec *APISpec) {
+		spec.APIID = "rewrite-b"
+		spec.Proxy.ListenPath = "/rewrite-b/"
+		UpdateAPIVersion(spec, "v1", rewriteTo("tyk://rewrite-a/"))
+	}, func(spec *APISpec) {
+		spec.APIID = "target-a"
+		spec.Proxy.ListenPath = "/target-a/"
+		spec.Proxy.TargetURL = "tyk://target-b"
+	}, func(spec *APISpec) {
+		spec.APIID = "target-b"
+		spec.Proxy.ListenPath = "/target-b/"
+		spec.Proxy.TargetURL = "tyk://target-a"
+	}, func(spec *APISpec) {
+		spec.APIID = "memconn-a"
+		spec.Proxy.ListenPath = "/memconn-a/"
+		spec.Proxy.TargetURL = "http://memconn-b"
+		UpdateAPIVersion(spec, "v1", internalHeader)
+	}, func(spec *APISpec) {
+		spec.APIID = "memconn-b"
+		spec.Proxy.ListenPath = "/memconn-b/"
+		spec.Proxy.TargetURL = "http://memconn-a"
+		UpdateAPIVersion(spec, "v1", internalHeader)
+	})
+
+	redisAnalyticsKeyName := analyticsKeyName + ts.Gw.Analytics.analyticsSerializer.GetSuffix()
+
+	testCases := []struct {
+		name  string
+		path  string
+		trace []string
+	}{
+		{
+			name:  "URL rewrites",
+			path:  "/rewrite-a/",
+			trace: []string{"rewrite-a", "rewrite-b", "rewrite-a", "rewrite-b", "rewrite-a"},
+		},
+		{
+			name:  "internal targets",
+			path:  "/target-a/",
+			trace: []string{"target-a", "target-b", "target-a", "target-b", "target-a"},
+		},
+		{
+			name:  "in memory loops",
+			path:  "/memconn-a/",
+			trace: []string{"memconn-a", "memconn-b", "memconn-a", "memconn-b", "memconn-a"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts.Gw.Analytics.Flush()
+			ts.Gw.Analytics.Store.GetAndDeleteSet(redisAnalyticsKeyName)
+
+			_, _ = ts.Run(t, test.TestCase{
+				Path:      tc.path,
+				Code:      http.StatusLoopDetected,
+				BodyMatch: "Found more than 3 loops in single request, visited APIs: " + strings.Join(tc.trace, ", "),
+			})
+
+			ts.Gw.Analytics.Flush()
+			results := ts.Gw.Analytics.Store.GetAndDeleteSet(redisAnalyticsKeyName)
+			require.NotEmpty(t, results)
+
+			var loopDetected bool
+			for _, result := range results {
+				var record analytics.AnalyticsRecord
+				require.NoError(t, ts.Gw.Analytics.analyticsSerializer.Decode([]byte(result.(string)), &record))
+
+				if record.ResponseCode == http.StatusLoopDetected && slices.Contains(record.Tags, "loop-trace-"+strings.Join(tc.trace, ",")) {
+					loopDetected = true
+				}
+			}
+			assert.True(t, loopDetected, "analytics record with the loop trace not found")
+		})
+	}
+}
+
 func TestLoopingControlParams(t *testing.T) {
 	ts := StartTest(nil)
 	defer ts.Close()
@@ -617,7 +727,7 @@ func TestLoopingControlParams(t *testing.T) {
 
 			ts.Run(t, test.TestCase{
 				Method: "GET", Path: "/recurse",
-				Code:      500,
+				Code:      http.StatusLoopDetected,
 				BodyMatch: "Loop level too deep. Found more than 2 loops in single request",
 			})
 		})
@@ -643,7 +753,7 @@ func TestLoopingControlParams(t *testing.T) {
 
 			ts.Run(t, test.TestCase{
 				Method: "GET", Path: "/recurse",
-				Code:      500,
+				Code:      http.StatusLoopDetected,
 				BodyMatch: "Found more than 3 loops",
 			})
 		})
@@ -669,7 +779,7 @@ func TestLoopingControlParams(t *testing.T) {
 
 			ts.Run(t, test.TestCase{
 				Method: "GET", Path: "/recurse",
-				Code:      500,
+				Code:      http.StatusLoopDetected,
 				BodyMatch: "Found more than 5 loops",
 			})
 		})
@@ -128,6 +128,9 @@ func (gw *Gateway) createMiddleware(actualMW TykMiddleware) func(http.Handler) h
 		mw.Logger().Fatal("[Middleware] Configuration load failed")
 	}
 
+	latencyBreakdownEnabled := spec.LatencyBreakdown.Enabled
+	stage := requestLatencyStage(actualMW)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Create span early if OpenTelemetry is enabled
@@ -177,7 +180,14 @@ func (gw *Gateway) createMiddleware(actualMW TykMiddleware) func(http.Handler) h
 				return
 			}
 
+			var breakdown *latencyBreakdown
+			if latencyBreakdownEnabled {
+				breakdown = ctxGetOrCreateLatencyBreakdown(r)
+			}
+
+			traced := ctxGetChainTrace(r).begin(mw.Name(), r)
 			err, errCode := mw.ProcessRequest(w, r, mwConf)
+			breakdown.since(stage, startTime)
 
 			// Workaround
 			// ProcessRequest signature is too narrow it has to be extended to handle cases like this
@@ -185,11 +195,12 @@ func (gw *Gateway) createMiddleware(actualMW TykMiddleware) func(http.Handler) h
 			if errors.Is(err, ErrResponseSucceed) {
 				err = nil
 			}
+			traced.end(r, err, errCode)
 
 			if err != nil {
 				// Prevent double error write
 				writeResponse := true
-				if goPlugin, isGoPlugin := actualMW.(*GoPluginMiddleware); isGoPlugin && goPlugin.handler != nil || errors.Is(err, ErrResponseErrorSent) || errors.Is(err, middleware.ErrResponseRendered) {
+				if goPlugin, isGoPlugin := actualMW.(*GoPluginMiddleware); isGoPlugin && goPlugin.handler != nil || errors.Is(err, ErrResponseErrorSent) || errors.Is(err, middleware.ErrResponseRendered) || errors.Is(err, ErrMiddlewareCancelled) {
 					writeResponse = false
 				}
 
@@ -312,6 +323,9 @@ func NewBaseMiddleware(gw *Gateway, spec *APISpec, proxy ReturningHttpHandler, l
 		if len(v.ExtendedPaths.HardTimeouts) > 0 {
 			baseMid.Spec.EnforcedTimeoutEnabled = true
 		}
+		if len(v.ExtendedPaths.UpstreamHostHeader) > 0 {
+			baseMid.Spec.UpstreamHostEnabled = true
+		}
 		if !v.GlobalEnforceTimeoutDisabled && v.GlobalEnforceTimeout != 0 {
 			baseMid.Spec.EnforcedTimeoutEnabled = true
 		}
@@ -362,15 +376,16 @@ func (t *BaseMiddleware) Logger() (logger *logrus.Entry) {
 	return t.logger
 }
 
+// SetRequestLogger returns the middleware logger with the fields of r, including the API ID, API name,
+// org ID, key hash and request ID correlating the log lines of r. The correlation fields are set in the
+// request context, the middleware is shared by concurrent requests.
 func (t *BaseMiddleware) SetRequestLogger(r *http.Request) *logrus.Entry {
-	t.loggerMu.Lock()
-	defer t.loggerMu.Unlock()
-
-	if t.logger == nil {
-		t.logger = logrus.NewEntry(log)
+	var correlation logrus.Fields
+	if t.Spec != nil {
+		correlation = t.Gw.requestLogEntry(t.Spec, r).Data
 	}
-	t.logger = t.Gw.getLogEntryForRequest(t.logger, r, ctxGetAuthToken(r), nil)
-	return t.logger
+
+	return t.Gw.getLogEntryForRequest(t.Logger(), r, ctxGetAuthToken(r), correlation)
 }
 
 func (t *BaseMiddleware) Init() {}
@@ -481,12 +496,18 @@ func (t *BaseMiddleware) UpdateRequestSession(r *http.Request) bool {
 // ApplyPolicies will check if any policies are loaded. If any are, it
 // will overwrite the session state to use the policy values.
 func (t *BaseMiddleware) ApplyPolicies(session *user.SessionState) error {
+	return t.policyService().Apply(session)
+}
+
+// policyService returns the service applying the policies to the sessions of the API.
+func (t *BaseMiddleware) policyService() *policy.Service {
 	var orgID *string
 	if t.Spec != nil {
 		orgID = &t.Spec.OrgID
 	}
-	store := policy.New(orgID, t.Gw.policies, log)
-	return store.Apply(session)
+
+	resolution := policy.ConflictResolution(t.Gw.GetConfig().Policies.PartitionConflictResolution)
+	return policy.New(orgID, t.Gw.policies, log).WithConflictResolution(resolution)
 }
 
 // RecordAccessLog is used for Success/Error handler logging.
@@ -537,6 +558,10 @@ func (t *BaseMiddleware) RecordAccessLog(req *http.Request, resp *http.Response,
 // is used as a fallback source for response headers when the proxy response
 // object does not carry them (e.g. when enable_detailed_recording is false).
 func (t *BaseMiddleware) RecordMetrics(w http.ResponseWriter, r *http.Request, statusCode int, latency analytics.Latency, response *http.Response) {
+	now := time.Now()
+	t.Spec.health.record(now, statusCode, latency.Total)
+	t.recordPayloadSizes(r, now)
+
 	if t.Spec.DoNotTrack || ctxGetDoNotTrack(r) {
 		return
 	}
@@ -592,6 +617,7 @@ func (t *BaseMiddleware) RecordMetrics(w http.ResponseWriter, r *http.Request, s
 	}
 	t.Gw.MetricInstruments.RecordRequest(r.Context())
 	t.Gw.MetricInstruments.RecordAPIMetrics(r.Context(), rc)
+	t.Gw.MetricInstruments.RecordProxyRequest(r.Context(), rc.APIID, rc.ListenPath, statusCode)
 }
 
 func copyAllowedURLs(input []user.AccessSpec) []user.AccessSpec {
@@ -665,7 +691,13 @@ func (t *BaseMiddleware) CheckSessionAndIdentityForValidKey(originalKey string,
 
 	// Check session store
 	t.Logger().Debug("Querying keystore")
-	session, found := t.Gw.GlobalSessionManager.SessionDetail(t.Spec.OrgID, key, false)
+	var session user.SessionState
+	var found bool
+	if sessionManager, ok := t.Gw.GlobalSessionManager.(*DefaultSessionManager); ok {
+		session, found = sessionManager.sessionDetailForRequest(r, t.Spec.OrgID, key)
+	} else {
+		session, found = t.Gw.GlobalSessionManager.SessionDetail(t.Spec.OrgID, key, false)
+	}
 
 	if found {
 		if t.Spec.GlobalConfig.HashKeys {
@@ -689,12 +721,12 @@ func (t *BaseMiddleware) CheckSessionAndIdentityForValidKey(originalKey string,
 		return session, true
 	}
 
-	if _, ok := t.Spec.AuthManager.Store().(*RPCStorageHandler); ok && rpc.IsEmergencyMode() {
+	if _, ok := t.Spec.AuthManager.Store().(*RPCStorageHandler); ok && rpc.IsEmergencyMode() && !t.Gw.keyBackupEnabled() {
 		session.KeyID = key
 		return session.Clone(), false
 	}
 
-	// Only search in RPC if it's not in emergency mode
+	// Only search in RPC if it's not in emergency mode, or if keys can be served from the key backup
 	t.Logger().Debug("Querying authstore")
 	// 2. If not there, get it from the AuthorizationHandler
 	session, found = t.Spec.AuthManager.SessionDetail(t.Spec.OrgID, key, false)
@@ -786,6 +818,8 @@ func (t *BaseMiddleware) handleRateLimitFailure(r *http.Request, e event.Event,
 	// Report in health check
 	reportHealthValue(t.Spec, Throttle, "-1")
 
+	t.Gw.MetricInstruments.RecordRateLimitRejection(r.Context(), t.Spec.APIID, t.Spec.Proxy.ListenPath)
+
 	return errors.New(message), http.StatusTooManyRequests
 }
 
@@ -903,8 +937,17 @@ func handleResponseChain(chain []TykResponseHandler, rw http.ResponseWriter, res
 	}
 
 	traceIsEnabled := trace.IsEnabled()
+	breakdown := ctxGetLatencyBreakdown(req)
 	for _, rh := range chain {
-		if err := handleResponse(rh, rw, res, req, ses, traceIsEnabled); err != nil {
+		var start time.Time
+		if breakdown != nil {
+			start = time.Now()
+		}
+
+		err := handleResponse(rh, rw, res, req, ses, traceIsEnabled)
+		breakdown.since(responseLatencyStage(rh), start)
+
+		if err != nil {
 			// Abort the request if this handler is a response middleware hook:
 			if rh.Name() == "CustomMiddlewareResponseHook" || rh.Name() == "JSResponseMiddleware" {
 				rh.HandleError(rw, req)
@@ -1026,3 +1069,14 @@ func (b *BaseTykResponseHandler) logger() *logrus.Entry {
 	}
 	return b.log
 }
+
+// requestLogger returns the logger of the handler with the fields correlating the log lines of r,
+// see requestLogEntry.
+func (b *BaseTykResponseHandler) requestLogger(r *http.Request) *logrus.Entry {
+	logger := b.logger()
+	if b.Gw == nil || b.Spec == nil {
+		return logger
+	}
+
+	return logger.WithFields(b.Gw.requestLogEntry(b.Spec, r).Data)
+}
@@ -0,0 +1,44 @@
+package gateway
+
+import (
+	"net/http"
+
+	"github.com/gocraft/health"
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/pkg/errpack"
+)
+
+// StatusClientClosedRequest is the non-standard status recorded for the requests
+// abandoned by the client before a response was written.
+const StatusClientClosedRequest = 499
+
+// ErrMiddlewareCancelled is returned by the custom middleware (JSVM, coprocess and Go plugins)
+// aborted because the request context is done, the client disconnected or a deadline expired.
+// No response is written, the client isn't there to read it.
+var ErrMiddlewareCancelled = errpack.New(
+	"middleware cancelled",
+	errpack.WithType(errpack.TypeDomain),
+	errpack.WithLogLevel(logrus.DebugLevel),
+)
+
+// requestCancelled reports whether the context of the request is done.
+func requestCancelled(r *http.Request) bool {
+	return r.Context().Err() != nil
+}
+
+// abortMiddleware records a custom middleware run aborted by the cancellation of the request,
+// and returns the error ending the middleware chain. The aborted runs are counted by the
+// "aborted" event of the MiddlewareCall instrumentation job.
+func abortMiddleware(r *http.Request, mwName string, logger *logrus.Entry) (error, int) {
+	logger.WithError(r.Context().Err()).Debug("Middleware aborted, the request was cancelled")
+
+	if instrumentationEnabled {
+		job := instrument.NewJob("MiddlewareCall")
+		meta := health.Kvs{"mw_name": mwName}
+		job.EventKv("aborted", meta)
+		job.EventKv(mwName+".aborted", meta)
+	}
+
+	return ErrMiddlewareCancelled, StatusClientClosedRequest
+}
@@ -50,15 +50,17 @@ func (d *logDecorator) HandleResponse(
 	state *user.SessionState,
 ) error {
 
+	logger := d.Base().requestLogger(request)
+
 	start := time.Now()
-	d.logger().WithField("ts", start.UnixNano()).Debug("Started")
+	logger.WithField("ts", start.UnixNano()).Debug("Started")
 
 	if err := d.TykResponseHandler.HandleResponse(writer, response, request, state); err != nil {
-		d.logger().WithField("ns", time.Since(start).Nanoseconds()).WithError(err).Error("Failed to process response")
+		logger.WithField("ns", time.Since(start).Nanoseconds()).WithError(err).Error("Failed to process response")
 		return err
 	}
 
-	d.logger().WithField("ns", time.Since(start).Nanoseconds()).Debug("Finished")
+	logger.WithField("ns", time.Since(start).Nanoseconds()).Debug("Finished")
 
 	return nil
 }
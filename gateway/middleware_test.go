@@ -16,6 +16,7 @@ import (
 	logrustest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/mock/gomock"
 
 	"github.com/TykTechnologies/opentelemetry/metric/metrictest"
@@ -1135,6 +1136,37 @@ func TestBaseMiddleware_RecordMetrics(t *testing.T) {
 	})
 }
 
+func TestProxyOutcomeMetrics(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	inst, tp := testMetricInstruments(t, nil)
+	ts.Gw.MetricInstruments = inst
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "metrics-api"
+		spec.Proxy.ListenPath = "/metrics-api/"
+		spec.GlobalRateLimit = apidef.GlobalRateLimit{Rate: 1, Per: 60}
+	})
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Path: "/metrics-api/get", Code: http.StatusOK},
+		{Path: "/metrics-api/get", Code: http.StatusTooManyRequests},
+	}...)
+
+	apiAttrs := []attribute.KeyValue{
+		attribute.String("tyk.api.id", "metrics-api"),
+		attribute.String("tyk.api.listen_path", "/metrics-api/"),
+	}
+
+	requests := tp.FindMetric(t, "tyk.proxy.requests")
+	metrictest.AssertSumWithAttrs(t, requests, int64(1), append(apiAttrs, attribute.String("http.response.status_class", "2xx"))...)
+	metrictest.AssertSumWithAttrs(t, requests, int64(1), append(apiAttrs, attribute.String("http.response.status_class", "4xx"))...)
+
+	metrictest.AssertHistogramCount(t, tp.FindMetric(t, "tyk.proxy.upstream.latency"), 1)
+	metrictest.AssertSumWithAttrs(t, tp.FindMetric(t, "tyk.proxy.rate_limit.rejections"), int64(1), apiAttrs...)
+}
+
 func TestRecordAccessLog_APIType(t *testing.T) {
 	ts := StartTest(nil)
 	defer ts.Close()
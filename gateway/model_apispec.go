@@ -53,11 +53,14 @@ type APISpec struct {
 	URLRewriteEnabled        bool
 	CircuitBreakerEnabled    bool
 	EnforcedTimeoutEnabled   bool
+	UpstreamHostEnabled      bool
 	LastGoodHostList         *apidef.HostList
 	HasRun                   bool
 	ServiceRefreshInProgress bool
 	HTTPTransport            *TykRoundTripper
 	HTTPTransportCreated     time.Time
+	HTTPTransportCAs         string
+	HTTPTransportCAsGen      uint64
 	WSTransport              http.RoundTripper
 	WSTransportCreated       time.Time
 	GlobalConfig             config.Config
@@ -66,8 +69,25 @@ type APISpec struct {
 
 	unloadHooks []func()
 
+	// inFlight counts the requests currently served by the spec's handler chain.
+	inFlight int64
+
+	// health collects the traffic stats reported by the API health endpoint.
+	health apiHealthStats
+	// loadedAt is the time the spec was last (re)built.
+	loadedAt time.Time
+
 	network analytics.NetworkStats
 
+	// normaliseURLPatterns are the compiled analytics URL normalisation patterns of the API.
+	normaliseURLPatterns []urlNormalisePattern
+
+	// errorTemplates are the compiled error templates of the API, keyed by status code or default.
+	errorTemplates map[string]apiErrorTemplate
+
+	// outlierDetector ejects failing load balanced targets from rotation, nil unless outlier detection is enabled.
+	outlierDetector *upstreamOutlierDetector
+
 	GraphEngine graphengine.Engine
 
 	oasRouter routers.Router
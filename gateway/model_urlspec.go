@@ -38,6 +38,8 @@ type URLSpec struct {
 	GoPluginMeta              GoPluginMiddleware
 	PersistGraphQL            apidef.PersistGraphQLMeta
 	RateLimit                 apidef.RateLimitMeta
+	UpstreamHost              apidef.UpstreamHostMeta
+	RateCost                  apidef.RateCostMeta
 	OASValidateRequestMeta    *oas.ValidateRequest
 	OASMockResponseMeta       *oas.MockResponse
 
@@ -83,6 +85,8 @@ func (u *URLSpec) modeSpecificSpec(mode URLStatus) (interface{}, bool) {
 	switch mode {
 	case Ignored, BlackList, WhiteList:
 		return nil, true
+	case MockResponse:
+		return &u.MockResponse, true
 	case Cached:
 		return &u.CacheConfig, true
 	case Transformed:
@@ -125,6 +129,10 @@ func (u *URLSpec) modeSpecificSpec(mode URLStatus) (interface{}, bool) {
 		return u.OASValidateRequestMeta, true
 	case OASMockResponse:
 		return u.OASMockResponseMeta, true
+	case UpstreamHostHeader:
+		return &u.UpstreamHost, true
+	case RateCost:
+		return &u.RateCost, true
 	default:
 		return nil, false
 	}
@@ -135,6 +143,8 @@ func (u *URLSpec) matchesMethod(method string) bool {
 	switch u.Status {
 	case Ignored, BlackList, WhiteList:
 		return true
+	case MockResponse:
+		return method == u.MockResponse.Method
 	case Cached:
 		return method == u.CacheConfig.Method || (u.CacheConfig.Method == SAFE_METHODS && isSafeMethod(method))
 	case Transformed:
@@ -175,6 +185,10 @@ func (u *URLSpec) matchesMethod(method string) bool {
 		return method == u.PersistGraphQL.Method
 	case RateLimit:
 		return method == u.RateLimit.Method
+	case UpstreamHostHeader:
+		return method == u.UpstreamHost.Method
+	case RateCost:
+		return method == u.RateCost.Method
 	case OASValidateRequest, OASMockResponse:
 		// OAS middleware is method-specific, check against stored method
 		return method == u.OASMethod
@@ -1,21 +1,43 @@
 package gateway
 
 import (
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/crypto"
+	"github.com/TykTechnologies/tyk/internal/model"
+	"github.com/TykTechnologies/tyk/storage"
 	"github.com/TykTechnologies/tyk/user"
 )
 
+// monitorFiredKeyPrefix prefixes the keys recording the trigger limits already fired in a quota period.
+const monitorFiredKeyPrefix = "monitor.fired."
+
 type Monitor struct {
 	Gw *Gateway `json:"-"`
 }
 
+// monitorTarget is the event handler notified of the trigger limits crossed by a key.
+type monitorTarget struct {
+	handler  config.TykEventHandler
+	policyID string
+}
+
 func (m Monitor) Enabled() bool {
 	return m.Gw.GetConfig().Monitor.EnableTriggerMonitors
 }
 
 func (m Monitor) Fire(sessionData *user.SessionState, key string, triggerLimit, usagePercentage float64) {
+	m.fire(monitorTarget{handler: m.Gw.MonitoringHandler}, sessionData, key, triggerLimit, usagePercentage)
+}
+
+func (m Monitor) fire(target monitorTarget, sessionData *user.SessionState, key string, triggerLimit, usagePercentage float64) {
+	if target.handler == nil {
+		return
+	}
+
 	em := config.EventMessage{
 		Type: EventTriggerExceeded,
 		Meta: EventTriggerExceededMeta{
@@ -24,11 +46,13 @@ func (m Monitor) Fire(sessionData *user.SessionState, key string, triggerLimit,
 			Key:              key,
 			TriggerLimit:     int64(triggerLimit),
 			UsagePercentage:  int64(usagePercentage),
+			Threshold:        triggerLimit,
+			PolicyID:         target.policyID,
 		},
 		TimeStamp: time.Now().String(),
 	}
 
-	go m.Gw.MonitoringHandler.HandleEvent(em)
+	go target.handler.HandleEvent(em)
 }
 
 func (m Monitor) Check(sessionData *user.SessionState, key string) {
@@ -36,22 +60,24 @@ func (m Monitor) Check(sessionData *user.SessionState, key string) {
 		return
 	}
 
-	if m.checkLimit(sessionData, key, sessionData.QuotaMax, sessionData.QuotaRemaining, sessionData.QuotaRenews) {
+	if m.checkLimit(sessionData, key, "", sessionData.QuotaMax, sessionData.QuotaRemaining, sessionData.QuotaRenews) {
 		return
 	}
 
-	for _, ac := range sessionData.AccessRights {
+	for apiID, ac := range sessionData.AccessRights {
 		if ac.Limit.IsEmpty() {
 			continue
 		}
 
-		if m.checkLimit(sessionData, key, ac.Limit.QuotaMax, ac.Limit.QuotaRemaining, ac.Limit.QuotaRenews) {
+		if m.checkLimit(sessionData, key, apiID, ac.Limit.QuotaMax, ac.Limit.QuotaRemaining, ac.Limit.QuotaRenews) {
 			return
 		}
 	}
 }
 
-func (m Monitor) checkLimit(sessionData *user.SessionState, key string, quotaMax, quotaRemaining, quotaRenews int64) bool {
+// checkLimit fires the trigger limits crossed by the quota usage, once per quota period.
+// It reports whether any trigger limit is crossed.
+func (m Monitor) checkLimit(sessionData *user.SessionState, key, scope string, quotaMax, quotaRemaining, quotaRenews int64) bool {
 	if quotaMax <= 0 {
 		return false
 	}
@@ -71,19 +97,128 @@ func (m Monitor) checkLimit(sessionData *user.SessionState, key string, quotaMax
 		return false
 	}
 
-	if m.Gw.GetConfig().Monitor.GlobalTriggerLimit > 0.0 && usagePerc >= m.Gw.GetConfig().Monitor.GlobalTriggerLimit {
+	target := m.target(sessionData)
+
+	crossed := false
+	for _, triggerLimit := range m.triggerLimits(sessionData) {
+		if usagePerc < triggerLimit {
+			continue
+		}
+		crossed = true
+
+		if m.wasFired(sessionData, key, scope, triggerLimit, renewalDate) {
+			continue
+		}
+
 		log.Info("Firing...")
-		m.Fire(sessionData, key, m.Gw.GetConfig().Monitor.GlobalTriggerLimit, usagePerc)
-		return true
+		m.fire(target, sessionData, key, triggerLimit, usagePerc)
+	}
+
+	return crossed
+}
+
+// triggerLimits returns the distinct trigger limits of the session, from the global config,
+// the session itself and its policies.
+func (m Monitor) triggerLimits(sessionData *user.SessionState) []float64 {
+	conf := m.Gw.GetConfig().Monitor
+
+	var limits []float64
+	seen := map[float64]struct{}{}
+	add := func(triggerLimits ...float64) {
+		for _, limit := range triggerLimits {
+			if limit <= 0 {
+				continue
+			}
+			if _, ok := seen[limit]; ok {
+				continue
+			}
+			seen[limit] = struct{}{}
+			limits = append(limits, limit)
+		}
+	}
+
+	add(conf.GlobalTriggerLimit)
+	add(conf.TriggerLimits...)
+	add(sessionData.Monitor.TriggerLimits...)
+	for _, policy := range m.policies(sessionData) {
+		add(policy.Monitor.TriggerLimits...)
+	}
+
+	sort.Float64s(limits)
+	return limits
+}
+
+// target returns the event handler of the first policy of the session overriding the webhook,
+// or the global monitoring handler.
+func (m Monitor) target(sessionData *user.SessionState) monitorTarget {
+	for _, policy := range m.policies(sessionData) {
+		if policy.Monitor.TargetPath == "" && policy.Monitor.TemplatePath == "" {
+			continue
+		}
+
+		handler, err := m.policyHandler(policy.Monitor)
+		if err != nil {
+			log.WithError(err).Errorf("Failed to initialise the monitor of policy %s", policy.ID)
+			continue
+		}
+
+		return monitorTarget{handler: handler, policyID: policy.ID}
 	}
 
-	for _, triggerLimit := range sessionData.Monitor.TriggerLimits {
-		if usagePerc >= triggerLimit && triggerLimit != m.Gw.GetConfig().Monitor.GlobalTriggerLimit {
-			log.Info("Firing...")
-			m.Fire(sessionData, key, triggerLimit, usagePerc)
-			return true
+	return monitorTarget{handler: m.Gw.MonitoringHandler}
+}
+
+// policyHandler returns the webhook handler of the global monitor configuration overridden by the policy.
+func (m Monitor) policyHandler(policyMonitor user.PolicyMonitor) (config.TykEventHandler, error) {
+	conf := m.Gw.GetConfig().Monitor.Config
+	if policyMonitor.TargetPath != "" {
+		conf.TargetPath = policyMonitor.TargetPath
+	}
+	if policyMonitor.TemplatePath != "" {
+		conf.TemplatePath = policyMonitor.TemplatePath
+	}
+
+	cacheKey := conf.TargetPath + "|" + conf.TemplatePath
+	if handler, ok := m.Gw.monitorHandlers.Load(cacheKey); ok {
+		return handler.(config.TykEventHandler), nil
+	}
+
+	h := &WebHookHandler{Gw: m.Gw}
+	if err := h.Init(conf); err != nil {
+		return nil, err
+	}
+
+	handler, _ := m.Gw.monitorHandlers.LoadOrStore(cacheKey, h)
+	return handler.(config.TykEventHandler), nil
+}
+
+func (m Monitor) policies(sessionData *user.SessionState) []user.Policy {
+	var policies []user.Policy
+	for _, polID := range sessionData.PolicyIDs() {
+		if policy, ok := m.Gw.policies.PolicyByID(model.NewScopedCustomPolicyId(sessionData.OrgID, polID)); ok {
+			policies = append(policies, policy)
 		}
 	}
+	return policies
+}
+
+// wasFired tells whether the trigger limit was already fired for the key in the quota period ending at renewalDate.
+// Otherwise, it records the trigger limit as fired until the quota renews.
+func (m Monitor) wasFired(sessionData *user.SessionState, key, scope string, triggerLimit float64, renewalDate time.Time) bool {
+	identity := key
+	if identity == "" {
+		identity = sessionData.OrgID
+	}
+
+	firedKey := monitorFiredKeyPrefix + crypto.HashStr(fmt.Sprintf("%s.%s.%g.%d", identity, scope, triggerLimit, renewalDate.Unix()))
+
+	store := &storage.RedisCluster{ConnectionHandler: m.Gw.StorageConnectionHandler}
+	set, err := store.Lock(firedKey, time.Until(renewalDate))
+	if err != nil {
+		// Better to notify twice than not at all.
+		log.WithError(err).Warning("Could not record the fired trigger limit")
+		return false
+	}
 
-	return false
+	return !set
 }
@@ -0,0 +1,184 @@
+package gateway
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// firedThresholds collects the thresholds of the trigger events sent to it.
+type firedThresholds struct {
+	mu         sync.Mutex
+	thresholds []float64
+}
+
+func (f *firedThresholds) add(em config.EventMessage) {
+	meta, ok := em.Meta.(EventTriggerExceededMeta)
+	if !ok {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.thresholds = append(f.thresholds, meta.Threshold)
+}
+
+func (f *firedThresholds) get() []float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]float64{}, f.thresholds...)
+}
+
+func TestMonitor_triggerLimits(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.Monitor.EnableTriggerMonitors = true
+		globalConf.Monitor.MonitorUserKeys = true
+		globalConf.Monitor.GlobalTriggerLimit = 80
+		globalConf.Monitor.TriggerLimits = []float64{50, 80}
+	})
+	defer ts.Close()
+
+	fired := &firedThresholds{}
+	ts.Gw.MonitoringHandler = &testEventHandler{cb: fired.add}
+
+	api := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.UseKeylessAccess = false
+		spec.Proxy.ListenPath = "/"
+	})[0]
+
+	_, key := ts.CreateSession(func(s *user.SessionState) {
+		s.AccessRights = map[string]user.AccessDefinition{
+			api.APIID: {APIName: api.Name, APIID: api.APIID},
+		}
+		s.QuotaMax = 10
+		s.QuotaRemaining = 10
+		s.QuotaRenewalRate = 3600
+		s.Monitor.TriggerLimits = []float64{90}
+	})
+
+	authHeaders := map[string]string{header.Authorization: key}
+
+	steps := []struct {
+		requests int
+		expected []float64
+	}{
+		{requests: 4, expected: nil},
+		{requests: 1, expected: []float64{50}},
+		{requests: 2, expected: []float64{50}},
+		{requests: 1, expected: []float64{50, 80}},
+		{requests: 2, expected: []float64{50, 80, 90}},
+	}
+
+	for _, step := range steps {
+		for i := 0; i < step.requests; i++ {
+			_, _ = ts.Run(t, test.TestCase{Path: "/", Headers: authHeaders, Code: http.StatusOK})
+		}
+
+		if step.expected == nil {
+			time.Sleep(50 * time.Millisecond)
+			assert.Empty(t, fired.get())
+			continue
+		}
+
+		assert.Eventually(t, func() bool {
+			return len(fired.get()) == len(step.expected)
+		}, time.Second, 10*time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+		assert.ElementsMatch(t, step.expected, fired.get())
+	}
+
+	t.Run("new quota period", func(t *testing.T) {
+		fired := &firedThresholds{}
+		ts.Gw.MonitoringHandler = &testEventHandler{cb: fired.add}
+
+		session := CreateStandardSession()
+		session.QuotaMax = 10
+		session.QuotaRemaining = 4
+		session.QuotaRenews = time.Now().Add(time.Hour).Unix()
+
+		monitor := Monitor{Gw: ts.Gw}
+		monitor.Check(session, "period-key")
+		monitor.Check(session, "period-key")
+
+		session.QuotaRenews = time.Now().Add(2 * time.Hour).Unix()
+		monitor.Check(session, "period-key")
+
+		assert.Eventually(t, func() bool {
+			return len(fired.get()) == 2
+		}, time.Second, 10*time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, []float64{50, 50}, fired.get())
+	})
+}
+
+func TestMonitor_policyWebhook(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []map[string]string
+	)
+
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		payload := map[string]string{}
+		_ = json.Unmarshal(body, &payload)
+
+		mu.Lock()
+		received = append(received, payload)
+		mu.Unlock()
+	}))
+	defer webhook.Close()
+
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.Monitor.EnableTriggerMonitors = true
+		globalConf.Monitor.MonitorUserKeys = true
+		globalConf.Monitor.Config.Method = http.MethodPost
+	})
+	defer ts.Close()
+
+	fired := &firedThresholds{}
+	ts.Gw.MonitoringHandler = &testEventHandler{cb: fired.add}
+
+	policyID := ts.CreatePolicy(func(p *user.Policy) {
+		p.Monitor = user.PolicyMonitor{
+			TriggerLimits: []float64{50},
+			TargetPath:    webhook.URL,
+		}
+	})
+
+	session := CreateStandardSession()
+	session.SetPolicies(policyID)
+	session.QuotaMax = 10
+	session.QuotaRemaining = 4
+	session.QuotaRenews = time.Now().Add(time.Hour).Unix()
+
+	monitor := Monitor{Gw: ts.Gw}
+	monitor.Check(session, "policy-key")
+	monitor.Check(session, "policy-key")
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 1)
+	assert.Equal(t, "policy-key", received[0]["key"])
+	assert.Equal(t, "50", received[0]["threshold"])
+	assert.Empty(t, fired.get(), "the policy webhook overrides the global one")
+}
@@ -129,10 +129,17 @@ func (k *AuthKey) ProcessRequest(w http.ResponseWriter, r *http.Request, _ inter
 	if key != "" {
 		key = stripBearer(key)
 	}
+
+	token := key
+
 	var keyExists, updateSession bool
 	var certHash string
 	var session user.SessionState
 
+	if err, code := k.rejectAuthFailureLockout(w, r, key, authFailureSubjectIP); err != nil {
+		return k.prmError(w, r, err, code)
+	}
+
 	session, keyExists = k.CheckSessionAndIdentityForValidKey(key, r)
 	if !authConfig.UseCertificate {
 		if key == "" {
@@ -141,7 +148,7 @@ func (k *AuthKey) ProcessRequest(w http.ResponseWriter, r *http.Request, _ inter
 			return k.prmErrorAndStatusCode(w, r, ErrAuthAuthorizationFieldMissing)
 		}
 		if !keyExists {
-			err, code := k.reportInvalidKey(key, r, MsgNonExistentKey, ErrAuthKeyNotFound)
+			err, code := k.reportKeyNotFound(w, r, key)
 			return k.prmError(w, r, err, code)
 		}
 	}
@@ -175,7 +182,7 @@ func (k *AuthKey) ProcessRequest(w http.ResponseWriter, r *http.Request, _ inter
 			if key != "" {
 				session, keyExists = k.CheckSessionAndIdentityForValidKey(key, r)
 				if !keyExists {
-					err, code := k.reportInvalidKey(key, r, MsgNonExistentKey, ErrAuthKeyNotFound)
+					err, code := k.reportKeyNotFound(w, r, key)
 					return k.prmError(w, r, err, code)
 				}
 			}
@@ -185,7 +192,7 @@ func (k *AuthKey) ProcessRequest(w http.ResponseWriter, r *http.Request, _ inter
 			// fallback to search by cert
 			session, keyExists = k.CheckSessionAndIdentityForValidKey(certHash, r)
 			if !keyExists {
-				err, code := k.reportInvalidKey(key, r, MsgNonExistentKey, ErrAuthKeyNotFound)
+				err, code := k.reportKeyNotFound(w, r, key)
 				return k.prmError(w, r, err, code)
 			}
 		}
@@ -211,6 +218,8 @@ func (k *AuthKey) ProcessRequest(w http.ResponseWriter, r *http.Request, _ inter
 		}
 	}
 
+	k.authSucceeded(r, token)
+
 	// Set session state on context, we will need it later
 	switch k.Spec.BaseIdentityProvidedBy {
 	case apidef.AuthToken, apidef.UnsetAuth:
@@ -249,6 +258,19 @@ func (k *AuthKey) ProcessRequest(w http.ResponseWriter, r *http.Request, _ inter
 	return k.validateSignature(r, key)
 }
 
+// reportKeyNotFound reports a token which wasn't found and counts the failure towards the lockout of the client.
+// Once the token prefix is locked out, the request is rejected with 429 and isn't reported.
+func (k *AuthKey) reportKeyNotFound(w http.ResponseWriter, r *http.Request, key string) (error, int) {
+	if err, code := k.rejectAuthFailureLockout(w, r, key, authFailureSubjectTokenPrefix); err != nil {
+		return err, code
+	}
+
+	err, code := k.reportInvalidKey(key, r, MsgNonExistentKey, ErrAuthKeyNotFound)
+	k.authFailed(r, key)
+
+	return err, code
+}
+
 func (k *AuthKey) reportInvalidKey(key string, r *http.Request, msg string, errMsg string) (error, int) {
 	k.Logger().WithField("key", k.Gw.obfuscateKey(key)).Info(msg)
 
@@ -355,6 +355,7 @@ func (a *AuthORWrapper) initCustomPluginMiddleware() {
 		coProcessMw.HookName = spec.CustomMiddleware.AuthCheck.Name
 		coProcessMw.MiddlewareDriver = spec.CustomMiddleware.Driver
 		coProcessMw.RawBodyOnly = spec.CustomMiddleware.AuthCheck.RawBodyOnly
+		coProcessMw.StreamBody = spec.CustomMiddleware.AuthCheck.StreamBody
 		mw = coProcessMw
 	}
 
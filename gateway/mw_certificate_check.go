@@ -3,7 +3,9 @@ package gateway
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/TykTechnologies/tyk/certs"
@@ -174,3 +176,72 @@ func (m *CertificateCheckMW) extractCertInfo(cert *tls.Certificate) (certInfo ce
 		UntilExpiry: time.Until(cert.Leaf.NotAfter),
 	}, true
 }
+
+const (
+	clientCertCNTag          = "client-cert-cn-"
+	clientCertFingerprintTag = "client-cert-fingerprint-"
+)
+
+// clientCertificate returns the client certificate of a request to an API using mutual TLS authentication,
+// the certificate has been validated by CertificateCheckMW. It returns nil for other APIs or without a certificate.
+func clientCertificate(spec *APISpec, r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 || !spec.UseMutualTLSAuth {
+		return nil
+	}
+
+	return r.TLS.PeerCertificates[0]
+}
+
+// clientCertContextVars returns the context variables describing the client certificate.
+// The values are empty when the request has no client certificate.
+func clientCertContextVars(cert *x509.Certificate) map[string]interface{} {
+	vars := map[string]interface{}{
+		"client_cert_cn":          "",
+		"client_cert_fingerprint": "",
+		"client_cert_san":         "",
+		"client_cert_expires_at":  "",
+	}
+
+	if cert == nil {
+		return vars
+	}
+
+	vars["client_cert_cn"] = cert.Subject.CommonName
+	vars["client_cert_fingerprint"] = crypto.HexSHA256(cert.Raw)
+	vars["client_cert_san"] = strings.Join(clientCertSANs(cert), ",")
+	vars["client_cert_expires_at"] = cert.NotAfter.UTC().Format(time.RFC3339)
+
+	return vars
+}
+
+// clientCertSANs returns the subject alternative names of the certificate: DNS names, emails, IPs and URIs.
+func clientCertSANs(cert *x509.Certificate) []string {
+	sans := make([]string, 0, len(cert.DNSNames)+len(cert.EmailAddresses)+len(cert.IPAddresses)+len(cert.URIs))
+	sans = append(sans, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	return sans
+}
+
+// clientCertTags returns the analytics tags attributing the request to its client certificate.
+func clientCertTags(spec *APISpec, r *http.Request) []string {
+	cert := clientCertificate(spec, r)
+	if cert == nil {
+		return nil
+	}
+
+	tags := []string{clientCertFingerprintTag + crypto.HexSHA256(cert.Raw)}
+	if cert.Subject.CommonName != "" {
+		tags = append(tags, clientCertCNTag+cert.Subject.CommonName)
+	}
+
+	return tags
+}
@@ -0,0 +1,166 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TykTechnologies/tyk/ctx"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/rate"
+)
+
+const (
+	// ConcurrencyLimitKeyPrefix serves as a standard prefix for generating concurrency limiter keys.
+	ConcurrencyLimitKeyPrefix = "concurrency-"
+
+	// concurrencyLeaseTTL is the time after which a request in flight stops counting towards
+	// a distributed concurrency limit, when its gateway didn't release it.
+	concurrencyLeaseTTL = 5 * time.Minute
+)
+
+var errConcurrencyLimitExceeded = errors.New("Too many concurrent requests")
+
+// concurrencySlots holds the release functions of the concurrency limit slots taken by a request.
+type concurrencySlots struct {
+	releases []func()
+}
+
+// ConcurrencyLimit rejects requests while the API or the key already has the maximum
+// number of requests in flight. The slots are taken in the middleware chain and released
+// by the handler wrapping the chain once the request completed.
+type ConcurrencyLimit struct {
+	*BaseMiddleware
+
+	distributed *rate.ConcurrencyRedis
+}
+
+func (k *ConcurrencyLimit) Name() string {
+	return "ConcurrencyLimit"
+}
+
+func (k *ConcurrencyLimit) EnabledForSpec() bool {
+	conf := k.Spec.ConcurrencyLimit
+	if !conf.Enabled {
+		return false
+	}
+
+	return conf.MaxConcurrentRequests > 0 || !k.Spec.UseKeylessAccess
+}
+
+func (k *ConcurrencyLimit) Init() {
+	if !k.Spec.ConcurrencyLimit.Distributed {
+		return
+	}
+
+	if k.Gw.SessionLimiter.limiterStorage == nil {
+		k.Logger().Warning("Distributed concurrency limit requires redis rate limiter storage, counting requests per gateway")
+		return
+	}
+
+	k.distributed = rate.NewConcurrencyRedis(k.Gw.SessionLimiter.limiterStorage, concurrencyLeaseTTL)
+}
+
+func (k *ConcurrencyLimit) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	// Skip limits for looping
+	if !ctxCheckLimits(r) {
+		return nil, http.StatusOK
+	}
+
+	slots := ctxGetConcurrencySlots(r)
+	if slots == nil {
+		return nil, http.StatusOK
+	}
+
+	if limit := k.Spec.ConcurrencyLimit.MaxConcurrentRequests; limit > 0 {
+		if !k.acquire(r, slots, ConcurrencyLimitKeyPrefix+k.Spec.APIID, limit) {
+			return k.reject(w)
+		}
+	}
+
+	session := ctxGetSession(r)
+	if session == nil {
+		return nil, http.StatusOK
+	}
+
+	accessDef, allowanceScope, err := GetAccessDefinitionByAPIIDOrSession(session, k.Spec)
+	if err != nil {
+		return nil, http.StatusOK
+	}
+
+	if limit := accessDef.Limit.MaxConcurrentRequests; limit > 0 {
+		key := ConcurrencyLimitKeyPrefix + session.KeyHash()
+		if allowanceScope != "" {
+			key += "-" + allowanceScope
+		}
+
+		if !k.acquire(r, slots, key, limit) {
+			return k.reject(w)
+		}
+	}
+
+	return nil, http.StatusOK
+}
+
+// acquire takes a slot of key for the request and reports whether it's allowed.
+// When redis fails, the request is allowed rather than blocking all traffic.
+func (k *ConcurrencyLimit) acquire(r *http.Request, slots *concurrencySlots, key string, limit int) bool {
+	if k.distributed == nil {
+		release, ok := k.Gw.concurrencyLimiter.Acquire(key, limit)
+		if ok {
+			slots.releases = append(slots.releases, release)
+		}
+		return ok
+	}
+
+	release, ok, err := k.distributed.Acquire(r.Context(), time.Now(), key, limit)
+	if err != nil {
+		k.Logger().WithError(err).Error("Failed to acquire distributed concurrency limit slot")
+		return true
+	}
+
+	if ok {
+		slots.releases = append(slots.releases, release)
+	}
+	return ok
+}
+
+func (k *ConcurrencyLimit) reject(w http.ResponseWriter) (error, int) {
+	conf := k.Spec.ConcurrencyLimit
+
+	retryAfter := conf.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = 1
+	}
+	w.Header().Set(header.RetryAfter, strconv.Itoa(retryAfter))
+
+	code := conf.StatusCode
+	if code != http.StatusServiceUnavailable {
+		code = http.StatusTooManyRequests
+	}
+
+	return errConcurrencyLimitExceeded, code
+}
+
+// releaseConcurrencySlots wraps h so the concurrency limit slots taken while serving
+// a request are released once it completed, was cancelled or panicked.
+func releaseConcurrencySlots(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slots := &concurrencySlots{}
+		defer func() {
+			for _, release := range slots.releases {
+				release()
+			}
+		}()
+
+		r = r.WithContext(context.WithValue(r.Context(), ctx.ConcurrencySlots, slots))
+		h.ServeHTTP(w, r)
+	})
+}
+
+func ctxGetConcurrencySlots(r *http.Request) *concurrencySlots {
+	slots, _ := r.Context().Value(ctx.ConcurrencySlots).(*concurrencySlots)
+	return slots
+}
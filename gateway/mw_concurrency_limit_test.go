@@ -0,0 +1,275 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/rate"
+	"github.com/TykTechnologies/tyk/storage"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// slowUpstream holds the requests to a /slow path until it's released.
+type slowUpstream struct {
+	*httptest.Server
+
+	held    atomic.Int64
+	release chan struct{}
+	once    sync.Once
+}
+
+func newSlowUpstream(t *testing.T) *slowUpstream {
+	t.Helper()
+
+	u := &slowUpstream{release: make(chan struct{})}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/slow") {
+			u.held.Add(1)
+			defer u.held.Add(-1)
+
+			select {
+			case <-u.release:
+			case <-r.Context().Done():
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Cleanup(func() {
+		u.Release()
+		u.Close()
+	})
+
+	return u
+}
+
+// Release lets the held requests and the following ones through.
+func (u *slowUpstream) Release() {
+	u.once.Do(func() {
+		close(u.release)
+	})
+}
+
+// holdRequests sends n requests which are held by a slow upstream, and returns a channel receiving their status codes.
+func holdRequests(t *testing.T, n int, url string, headers map[string]string) <-chan int {
+	t.Helper()
+
+	codes := make(chan int, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			if err != nil {
+				codes <- 0
+				return
+			}
+			for k, v := range headers {
+				req.Header.Set(k, v)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				codes <- 0
+				return
+			}
+			resp.Body.Close()
+			codes <- resp.StatusCode
+		}()
+	}
+
+	return codes
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	inFlight := func(key string) func() int {
+		return func() int {
+			return ts.Gw.concurrencyLimiter.InFlight(key)
+		}
+	}
+
+	waitInFlight := func(t *testing.T, count func() int, n int) {
+		t.Helper()
+		require.Eventually(t, func() bool {
+			return count() == n
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	releaseAll := func(t *testing.T, upstream *slowUpstream, codes <-chan int, n int) {
+		t.Helper()
+		upstream.Release()
+		for i := 0; i < n; i++ {
+			assert.Equal(t, http.StatusOK, <-codes)
+		}
+	}
+
+	t.Run("api limit", func(t *testing.T) {
+		upstream := newSlowUpstream(t)
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.APIID = "concurrency-api"
+			spec.Proxy.ListenPath = "/api/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.ConcurrencyLimit = apidef.ConcurrencyLimit{Enabled: true, MaxConcurrentRequests: 2}
+		})
+
+		codes := holdRequests(t, 2, ts.URL+"/api/slow", nil)
+		waitInFlight(t, inFlight("concurrency-concurrency-api"), 2)
+
+		start := time.Now()
+		_, _ = ts.Run(t, test.TestCase{
+			Path:         "/api/slow",
+			Code:         http.StatusTooManyRequests,
+			HeadersMatch: map[string]string{header.RetryAfter: "1"},
+		})
+		assert.Less(t, time.Since(start), time.Second, "request over the limit should be rejected immediately")
+
+		releaseAll(t, upstream, codes, 2)
+		waitInFlight(t, inFlight("concurrency-concurrency-api"), 0)
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/api/fast", Code: http.StatusOK})
+	})
+
+	t.Run("service unavailable", func(t *testing.T) {
+		upstream := newSlowUpstream(t)
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.APIID = "concurrency-503"
+			spec.Proxy.ListenPath = "/unavailable/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.ConcurrencyLimit = apidef.ConcurrencyLimit{
+				Enabled:               true,
+				MaxConcurrentRequests: 1,
+				StatusCode:            http.StatusServiceUnavailable,
+				RetryAfter:            5,
+			}
+		})
+
+		codes := holdRequests(t, 1, ts.URL+"/unavailable/slow", nil)
+		waitInFlight(t, inFlight("concurrency-concurrency-503"), 1)
+
+		_, _ = ts.Run(t, test.TestCase{
+			Path:         "/unavailable/slow",
+			Code:         http.StatusServiceUnavailable,
+			HeadersMatch: map[string]string{header.RetryAfter: "5"},
+		})
+
+		releaseAll(t, upstream, codes, 1)
+	})
+
+	t.Run("key limit", func(t *testing.T) {
+		upstream := newSlowUpstream(t)
+		api := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.APIID = "concurrency-key"
+			spec.Proxy.ListenPath = "/key/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.UseKeylessAccess = false
+			spec.ConcurrencyLimit = apidef.ConcurrencyLimit{Enabled: true}
+		})[0]
+
+		createKey := func() string {
+			_, key := ts.CreateSession(func(s *user.SessionState) {
+				s.MaxConcurrentRequests = 1
+				s.AccessRights = map[string]user.AccessDefinition{
+					api.APIID: {APIID: api.APIID, Versions: []string{"v1"}},
+				}
+			})
+			require.NotEmpty(t, key)
+			return key
+		}
+
+		key1, key2 := createKey(), createKey()
+
+		codes := holdRequests(t, 1, ts.URL+"/key/slow", map[string]string{header.Authorization: key1})
+		waitInFlight(t, inFlight("concurrency-"+storage.HashKey(key1, ts.Gw.GetConfig().HashKeys)), 1)
+
+		_, _ = ts.Run(t, test.TestCase{
+			Path:    "/key/slow",
+			Headers: map[string]string{header.Authorization: key1},
+			Code:    http.StatusTooManyRequests,
+		})
+
+		// Other keys have their own limit.
+		codes2 := holdRequests(t, 1, ts.URL+"/key/slow", map[string]string{header.Authorization: key2})
+		waitInFlight(t, func() int { return int(upstream.held.Load()) }, 2)
+
+		releaseAll(t, upstream, codes, 1)
+		assert.Equal(t, http.StatusOK, <-codes2)
+	})
+
+	t.Run("cancelled requests release their slot", func(t *testing.T) {
+		upstream := newSlowUpstream(t)
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.APIID = "concurrency-cancel"
+			spec.Proxy.ListenPath = "/cancel/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.ConcurrencyLimit = apidef.ConcurrencyLimit{Enabled: true, MaxConcurrentRequests: 1}
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/cancel/slow", nil)
+			if err != nil {
+				return
+			}
+			if resp, err := http.DefaultClient.Do(req); err == nil {
+				resp.Body.Close()
+			}
+		}()
+
+		waitInFlight(t, inFlight("concurrency-concurrency-cancel"), 1)
+		cancel()
+		<-done
+		waitInFlight(t, inFlight("concurrency-concurrency-cancel"), 0)
+	})
+
+	t.Run("distributed", func(t *testing.T) {
+		upstream := newSlowUpstream(t)
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.APIID = "concurrency-distributed"
+			spec.Proxy.ListenPath = "/distributed/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.ConcurrencyLimit = apidef.ConcurrencyLimit{Enabled: true, MaxConcurrentRequests: 1, Distributed: true}
+		})
+
+		codes := holdRequests(t, 1, ts.URL+"/distributed/slow", nil)
+		waitInFlight(t, func() int { return int(upstream.held.Load()) }, 1)
+
+		// The request in flight is counted in redis.
+		_, _ = ts.Run(t, test.TestCase{Path: "/distributed/slow", Code: http.StatusTooManyRequests})
+		assert.Equal(t, 0, ts.Gw.concurrencyLimiter.InFlight("concurrency-concurrency-distributed"))
+
+		releaseAll(t, upstream, codes, 1)
+	})
+}
+
+func TestReleaseConcurrencySlots(t *testing.T) {
+	gw := &Gateway{concurrencyLimiter: rate.NewConcurrency()}
+
+	handler := releaseConcurrencySlots(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		release, ok := gw.concurrencyLimiter.Acquire("key", 1)
+		require.True(t, ok)
+
+		slots := ctxGetConcurrencySlots(r)
+		slots.releases = append(slots.releases, release)
+		panic("handler failed")
+	}))
+
+	assert.Panics(t, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+	assert.Equal(t, 0, gw.concurrencyLimiter.InFlight("key"))
+}
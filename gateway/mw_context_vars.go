@@ -50,6 +50,11 @@ func (m *MiddlewareContextVars) ProcessRequest(w http.ResponseWriter, r *http.Re
 
 	parseForm(r)
 
+	requestID := ctxGetRequestID(r)
+	if requestID == "" {
+		requestID = uuid.New()
+	}
+
 	contextDataObject := map[string]interface{}{
 		"request_data": r.Form, // Form params (map[string][]string)
 		"headers":      map[string][]string(r.Header),
@@ -57,11 +62,19 @@ func (m *MiddlewareContextVars) ProcessRequest(w http.ResponseWriter, r *http.Re
 		"path_parts":   strings.Split(r.URL.Path, "/"), // Path parts
 		"path":         r.URL.Path,                     // path data
 		"remote_addr":  request.RealIP(r),              // IP
-		"request_id":   uuid.New(),                     //Correlation ID
+		"request_id":   requestID,                      //Correlation ID
 	}
 
 	contextDataObject = m.addTraceIDToContextVars(r.Context(), contextDataObject)
 
+	for name, value := range clientCertContextVars(clientCertificate(m.Spec, r)) {
+		contextDataObject[name] = value
+	}
+
+	if version := ctxGetResolvedVersion(r); version != "" {
+		contextDataObject["api_version"] = version
+	}
+
 	for hname, vals := range r.Header {
 		n := "headers_" + strings.Replace(hname, "-", "_", -1)
 		contextDataObject[n] = vals[0]
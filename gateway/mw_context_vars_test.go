@@ -99,12 +99,16 @@ func testPrepareTestContextVarsMiddleware() map[string]testContextVarsData {
 					"x-header-b": {"B"},
 					"x-header-c": {"C"},
 				},
-				"headers_x_header_a": "A",
-				"headers_x_header_b": "B",
-				"headers_x_header_c": "C",
-				"headers_Host":       "abc.com",
-				"path_parts":         []string{"", "aaa", "bbb", "111", "222"},
-				"path":               "/aaa/bbb/111/222",
+				"headers_x_header_a":      "A",
+				"headers_x_header_b":      "B",
+				"headers_x_header_c":      "C",
+				"headers_Host":            "abc.com",
+				"path_parts":              []string{"", "aaa", "bbb", "111", "222"},
+				"path":                    "/aaa/bbb/111/222",
+				"client_cert_cn":          "",
+				"client_cert_fingerprint": "",
+				"client_cert_san":         "",
+				"client_cert_expires_at":  "",
 			},
 		},
 		"POST with query string and encoded form data": {
@@ -137,13 +141,17 @@ func testPrepareTestContextVarsMiddleware() map[string]testContextVarsData {
 					"x-header-c":   {"C"},
 					"Content-Type": {"application/x-www-form-urlencoded"},
 				},
-				"headers_x_header_a":   "A",
-				"headers_x_header_b":   "B",
-				"headers_x_header_c":   "C",
-				"headers_Content_Type": "application/x-www-form-urlencoded",
-				"headers_Host":         "abc.com",
-				"path_parts":           []string{"", "aaa", "bbb", "111", "222"},
-				"path":                 "/aaa/bbb/111/222",
+				"headers_x_header_a":      "A",
+				"headers_x_header_b":      "B",
+				"headers_x_header_c":      "C",
+				"headers_Content_Type":    "application/x-www-form-urlencoded",
+				"headers_Host":            "abc.com",
+				"path_parts":              []string{"", "aaa", "bbb", "111", "222"},
+				"path":                    "/aaa/bbb/111/222",
+				"client_cert_cn":          "",
+				"client_cert_fingerprint": "",
+				"client_cert_san":         "",
+				"client_cert_expires_at":  "",
 			},
 		},
 		"POST with query string and encoded form data and cookies": {
@@ -178,16 +186,20 @@ func testPrepareTestContextVarsMiddleware() map[string]testContextVarsData {
 					"Content-Type": {"application/x-www-form-urlencoded"},
 					"Cookie":       {"c-1=cookie1;c-2=cookie2"},
 				},
-				"headers_x_header_a":   "A",
-				"headers_x_header_b":   "B",
-				"headers_x_header_c":   "C",
-				"headers_Content_Type": "application/x-www-form-urlencoded",
-				"headers_Cookie":       "c-1=cookie1;c-2=cookie2",
-				"headers_Host":         "abc.com",
-				"cookies_c_1":          "cookie1",
-				"cookies_c_2":          "cookie2",
-				"path_parts":           []string{"", "aaa", "bbb", "111", "222"},
-				"path":                 "/aaa/bbb/111/222",
+				"headers_x_header_a":      "A",
+				"headers_x_header_b":      "B",
+				"headers_x_header_c":      "C",
+				"headers_Content_Type":    "application/x-www-form-urlencoded",
+				"headers_Cookie":          "c-1=cookie1;c-2=cookie2",
+				"headers_Host":            "abc.com",
+				"cookies_c_1":             "cookie1",
+				"cookies_c_2":             "cookie2",
+				"path_parts":              []string{"", "aaa", "bbb", "111", "222"},
+				"path":                    "/aaa/bbb/111/222",
+				"client_cert_cn":          "",
+				"client_cert_fingerprint": "",
+				"client_cert_san":         "",
+				"client_cert_expires_at":  "",
 			},
 		},
 	}
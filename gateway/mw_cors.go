@@ -1,14 +1,20 @@
 package gateway
 
 import (
-	"github.com/TykTechnologies/tyk/internal/middleware"
-	"github.com/rs/cors"
 	"net/http"
+	"sort"
+
+	"github.com/rs/cors"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/internal/httputil"
+	"github.com/TykTechnologies/tyk/internal/middleware"
+	"github.com/TykTechnologies/tyk/regexp"
 )
 
 type CORSMiddleware struct {
 	*BaseMiddleware
-	corsHandler *cors.Cors
+	corsHandlers *corsHandlers
 }
 
 func (c *CORSMiddleware) Name() string {
@@ -20,20 +26,11 @@ func (c *CORSMiddleware) EnabledForSpec() bool {
 }
 
 func (c *CORSMiddleware) Init() {
-	c.corsHandler = cors.New(cors.Options{
-		AllowedOrigins:     c.Spec.CORS.AllowedOrigins,
-		AllowedMethods:     c.Spec.CORS.AllowedMethods,
-		AllowedHeaders:     c.Spec.CORS.AllowedHeaders,
-		ExposedHeaders:     c.Spec.CORS.ExposedHeaders,
-		AllowCredentials:   c.Spec.CORS.AllowCredentials,
-		MaxAge:             c.Spec.CORS.MaxAge,
-		OptionsPassthrough: c.Spec.CORS.OptionsPassthrough,
-		Debug:              c.Spec.CORS.Debug,
-	})
+	c.corsHandlers = newCORSHandlers(c.Spec)
 }
 
 func (c *CORSMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
-	c.corsHandler.HandlerFunc(w, r)
+	c.corsHandlers.forRequest(r).HandlerFunc(w, r)
 
 	if r.Method == http.MethodOptions && !c.Spec.CORS.OptionsPassthrough {
 		return nil, middleware.StatusRespond
@@ -41,3 +38,119 @@ func (c *CORSMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request,
 
 	return nil, http.StatusOK
 }
+
+// corsPathHandler is the CORS handler of a path override.
+type corsPathHandler struct {
+	path    string
+	re      *regexp.Regexp
+	handler *cors.Cors
+}
+
+// corsHandlers holds the API-level CORS handler and the handlers of the path overrides of each version.
+type corsHandlers struct {
+	spec  *APISpec
+	api   *cors.Cors
+	paths map[string][]corsPathHandler
+}
+
+// newCORSHandlers builds the CORS handlers of the spec. The path overrides of each version are
+// sorted by descending path length, so the longest matching path is found first.
+func newCORSHandlers(spec *APISpec) *corsHandlers {
+	h := &corsHandlers{
+		spec:  spec,
+		api:   cors.New(corsOptions(spec.CORS)),
+		paths: map[string][]corsPathHandler{},
+	}
+
+	for _, vInfo := range spec.VersionData.Versions {
+		var handlers []corsPathHandler
+		for _, meta := range vInfo.ExtendedPaths.CORS {
+			if meta.Disabled {
+				continue
+			}
+
+			re, err := regexp.Compile(httputil.PreparePathRegexp(meta.Path, true, false))
+			if err != nil {
+				log.WithError(err).WithField("path", meta.Path).Error("Could not compile the CORS override path, skipping")
+				continue
+			}
+
+			handlers = append(handlers, corsPathHandler{
+				path:    meta.Path,
+				re:      re,
+				handler: cors.New(corsPathOptions(spec.CORS, meta)),
+			})
+		}
+
+		sort.SliceStable(handlers, func(i, j int) bool {
+			return len(handlers[i].path) > len(handlers[j].path)
+		})
+
+		if len(handlers) > 0 {
+			h.paths[vInfo.Name] = handlers
+		}
+	}
+
+	return h
+}
+
+// forRequest returns the handler of the longest path override matching the request,
+// or the API-level handler when no override matches.
+func (h *corsHandlers) forRequest(r *http.Request) *cors.Cors {
+	if len(h.paths) == 0 {
+		return h.api
+	}
+
+	vInfo, _ := h.spec.Version(r)
+	if vInfo == nil {
+		return h.api
+	}
+
+	path := h.spec.StripListenPath(r.URL.Path)
+	for _, p := range h.paths[vInfo.Name] {
+		if p.re.MatchString(path) {
+			return p.handler
+		}
+	}
+
+	return h.api
+}
+
+func corsOptions(conf apidef.CORSConfig) cors.Options {
+	return cors.Options{
+		AllowedOrigins:     conf.AllowedOrigins,
+		AllowedMethods:     conf.AllowedMethods,
+		AllowedHeaders:     conf.AllowedHeaders,
+		ExposedHeaders:     conf.ExposedHeaders,
+		AllowCredentials:   conf.AllowCredentials,
+		MaxAge:             conf.MaxAge,
+		OptionsPassthrough: conf.OptionsPassthrough,
+		Debug:              conf.Debug,
+	}
+}
+
+// corsPathOptions applies a path override on top of the API-level CORS configuration.
+func corsPathOptions(conf apidef.CORSConfig, meta apidef.CORSMeta) cors.Options {
+	opts := corsOptions(conf)
+
+	if meta.AllowCredentials != nil {
+		opts.AllowCredentials = *meta.AllowCredentials
+	}
+	if len(meta.AllowedOrigins) > 0 {
+		opts.AllowedOrigins = meta.AllowedOrigins
+	}
+	if len(meta.AllowedMethods) > 0 {
+		opts.AllowedMethods = meta.AllowedMethods
+	}
+	if len(meta.AllowedHeaders) > 0 {
+		opts.AllowedHeaders = meta.AllowedHeaders
+	}
+	if len(meta.ExposedHeaders) > 0 {
+		opts.ExposedHeaders = meta.ExposedHeaders
+	}
+	if meta.MaxAge > 0 {
+		opts.MaxAge = meta.MaxAge
+	}
+
+	return opts
+}
@@ -160,6 +160,79 @@ func TestCORSMiddleware_testApi(t *testing.T) {
 	})
 }
 
+func TestCORSMiddleware_PathOverrides(t *testing.T) {
+	g := StartTest(nil)
+	defer g.Close()
+
+	const (
+		adminOrigin  = "http://admin.example.com"
+		publicOrigin = "http://public.example.com"
+	)
+
+	noCredentials := false
+
+	g.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/cors-paths/"
+		spec.UseOauth2 = true
+		spec.CORS.Enable = true
+		spec.CORS.AllowedOrigins = []string{adminOrigin}
+		spec.CORS.AllowedMethods = []string{http.MethodGet, http.MethodPost}
+		spec.CORS.AllowCredentials = true
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.CORS = []apidef.CORSMeta{
+				{Path: "/widget", AllowedOrigins: []string{"*"}, MaxAge: 600, AllowCredentials: &noCredentials},
+				{Path: "/widget/private", AllowedOrigins: []string{adminOrigin}},
+				{Path: "/oauth", AllowedOrigins: []string{publicOrigin}},
+				{Path: "/disabled", AllowedOrigins: []string{"*"}, Disabled: true},
+			}
+		})
+	})
+
+	preflight := func(path, origin string) test.TestCase {
+		return test.TestCase{
+			Method: http.MethodOptions,
+			Path:   path,
+			Headers: map[string]string{
+				"Origin":                        origin,
+				"Access-Control-Request-Method": http.MethodGet,
+			},
+			Code: http.StatusNoContent,
+		}
+	}
+
+	withHeaders := func(tc test.TestCase, headers map[string]string) test.TestCase {
+		tc.HeadersMatch = headers
+		return tc
+	}
+
+	_, _ = g.Run(t, []test.TestCase{
+		withHeaders(preflight("/cors-paths/widget", publicOrigin), map[string]string{
+			"Access-Control-Allow-Origin":      "*",
+			"Access-Control-Max-Age":           "600",
+			"Access-Control-Allow-Credentials": "",
+		}),
+		withHeaders(preflight("/cors-paths/widget/items", publicOrigin), map[string]string{
+			"Access-Control-Allow-Origin": "*",
+		}),
+		withHeaders(preflight("/cors-paths/admin", publicOrigin), map[string]string{
+			"Access-Control-Allow-Origin": "",
+		}),
+		withHeaders(preflight("/cors-paths/admin", adminOrigin), map[string]string{
+			"Access-Control-Allow-Origin": adminOrigin,
+		}),
+		withHeaders(preflight("/cors-paths/widget/private", publicOrigin), map[string]string{
+			"Access-Control-Allow-Origin": "",
+		}),
+		withHeaders(preflight("/cors-paths/disabled", publicOrigin), map[string]string{
+			"Access-Control-Allow-Origin": "",
+		}),
+		withHeaders(preflight("/cors-paths/oauth/token", publicOrigin), map[string]string{
+			"Access-Control-Allow-Origin":      publicOrigin,
+			"Access-Control-Allow-Credentials": "true",
+		}),
+	}...)
+}
+
 func createCORSConfig() apidef.CORSConfig {
 	return apidef.CORSConfig{
 		Enable:             true,
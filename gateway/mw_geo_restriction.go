@@ -0,0 +1,249 @@
+package gateway
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/request"
+)
+
+const (
+	ErrGeoCountryBlocked      = "geo.country_blocked"
+	ErrGeoDatabaseUnavailable = "geo.database_unavailable"
+
+	MsgGeoCountryBlocked      = "Access from this country has been disallowed"
+	MsgGeoDatabaseUnavailable = "Access has been disallowed as the country of the request could not be determined"
+)
+
+const (
+	geoRestrictionReasonDenied = "country not allowed"
+	geoRestrictionReasonNoDB   = "database unavailable"
+)
+
+var errGeoIPDBExpired = errors.New("GeoIP database expired")
+
+func initGeoRestrictionErrors() {
+	TykErrors[ErrGeoCountryBlocked] = config.TykError{
+		Message: MsgGeoCountryBlocked,
+		Code:    http.StatusForbidden,
+	}
+
+	TykErrors[ErrGeoDatabaseUnavailable] = config.TykError{
+		Message: MsgGeoDatabaseUnavailable,
+		Code:    http.StatusForbidden,
+	}
+}
+
+// GeoRestrictionMiddleware allows or blocks requests by the country of the client IP,
+// looked up in the MaxMind database configured with geo_restriction.db_path.
+type GeoRestrictionMiddleware struct {
+	*BaseMiddleware
+}
+
+func (g *GeoRestrictionMiddleware) Name() string {
+	return "GeoRestrictionMiddleware"
+}
+
+func (g *GeoRestrictionMiddleware) EnabledForSpec() bool {
+	conf := g.Spec.GeoRestriction
+	return conf.Enabled && (len(conf.AllowedCountries) > 0 || len(conf.BlockedCountries) > 0)
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (g *GeoRestrictionMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	conf := g.Spec.GeoRestriction
+	realIP := request.RealIP(r)
+
+	db, err := g.Gw.geoIPDB.get(g.Gw.GetConfig())
+	if err != nil {
+		if conf.FailOpen {
+			g.Logger().WithError(err).Debug("GeoIP database unavailable, letting the request through.")
+			return nil, http.StatusOK
+		}
+
+		g.blocked(r, realIP, "", geoRestrictionReasonNoDB)
+		return errorAndStatusCode(ErrGeoDatabaseUnavailable)
+	}
+
+	country := lookupCountry(db, realIP)
+	if countryAllowed(country, conf.AllowedCountries, conf.BlockedCountries) {
+		return nil, http.StatusOK
+	}
+
+	g.blocked(r, realIP, country, geoRestrictionReasonDenied)
+	return errorAndStatusCode(ErrGeoCountryBlocked)
+}
+
+func (g *GeoRestrictionMiddleware) blocked(r *http.Request, origin, country, reason string) {
+	g.Logger().WithField("origin", origin).WithField("country", country).Info("Attempted access from a restricted country: ", reason)
+
+	g.FireEvent(EventGeoRestrictionBlocked, EventGeoRestrictionBlockedMeta{
+		EventMetaDefault: EventMetaDefault{Message: "Attempted access from a restricted country.", OriginatingRequest: EncodeRequestToEvent(r)},
+		Path:             r.URL.Path,
+		Origin:           origin,
+		Country:          country,
+		Reason:           reason,
+	})
+
+	// Report in health check
+	reportHealthValue(g.Spec, KeyFailure, "-1")
+}
+
+// countryAllowed checks the ISO code of a country against the allowed and blocked lists,
+// the blocked list taking precedence. An unknown country, the empty string, is only
+// blocked when an allowed list is set.
+func countryAllowed(country string, allowed, blocked []string) bool {
+	for _, code := range blocked {
+		if country != "" && strings.EqualFold(code, country) {
+			return false
+		}
+	}
+
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, code := range allowed {
+		if country != "" && strings.EqualFold(code, country) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lookupCountry returns the ISO code of the country of an IP, or the empty string when it isn't known.
+func lookupCountry(db *maxminddb.Reader, ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+
+	var record struct {
+		Country struct {
+			ISOCode string `maxminddb:"iso_code"`
+		} `maxminddb:"country"`
+	}
+
+	if err := db.Lookup(parsed, &record); err != nil {
+		log.WithError(err).Debug("GeoIP lookup failed for ", ip)
+		return ""
+	}
+
+	return record.Country.ISOCode
+}
+
+// geoIPDatabase holds the MaxMind database of the APIs restricting access by country.
+// It's reopened on reload when the file changed on disk.
+type geoIPDatabase struct {
+	mu      sync.RWMutex
+	loaded  bool
+	path    string
+	modTime time.Time
+	reader  *maxminddb.Reader
+	err     error
+	expired bool
+}
+
+// geoRestrictionDBPath returns the path of the database of the country restrictions,
+// falling back to the analytics GeoIP database.
+func geoRestrictionDBPath(conf config.Config) string {
+	if conf.GeoRestriction.DBPath != "" {
+		return conf.GeoRestriction.DBPath
+	}
+
+	return conf.AnalyticsConfig.GeoIPDBLocation
+}
+
+// reload opens the database again if its path or modification time changed since it was last opened.
+func (d *geoIPDatabase) reload(conf config.Config) {
+	path := geoRestrictionDBPath(conf)
+
+	var modTime time.Time
+	info, statErr := os.Stat(path)
+	if statErr == nil {
+		modTime = info.ModTime()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.loaded && d.path == path && d.modTime.Equal(modTime) && d.err == nil {
+		return
+	}
+
+	d.loaded, d.path, d.modTime, d.expired = true, path, modTime, false
+	d.reader, d.err = nil, nil
+
+	if path == "" {
+		d.err = errors.New("no GeoIP database configured")
+	} else if statErr != nil {
+		d.err = statErr
+	} else {
+		// The file is read in memory rather than mapped, so the requests still using
+		// the previous database aren't affected when it's replaced.
+		var content []byte
+		if content, d.err = os.ReadFile(path); d.err == nil {
+			d.reader, d.err = maxminddb.FromBytes(content)
+		}
+	}
+
+	if d.err != nil {
+		log.WithError(d.err).Warning("Failed to open the GeoIP database of the country restrictions, APIs will fail open or closed as configured")
+	}
+}
+
+// get returns the database, or an error when it's missing or older than geo_restriction.max_db_age.
+func (d *geoIPDatabase) get(conf config.Config) (*maxminddb.Reader, error) {
+	d.mu.RLock()
+	loaded, reader, err := d.loaded, d.reader, d.err
+	d.mu.RUnlock()
+
+	if !loaded {
+		d.reload(conf)
+		return d.get(conf)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	maxAge := time.Duration(conf.GeoRestriction.MaxDBAge) * 24 * time.Hour
+	if maxAge > 0 && time.Since(time.Unix(int64(reader.Metadata.BuildEpoch), 0)) > maxAge {
+		d.warnExpired()
+		return nil, errGeoIPDBExpired
+	}
+
+	return reader, nil
+}
+
+// warnExpired logs the database expired once until it's reopened.
+func (d *geoIPDatabase) warnExpired() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.expired {
+		return
+	}
+
+	d.expired = true
+	log.WithField("path", d.path).Warning("The GeoIP database of the country restrictions expired, APIs will fail open or closed as configured")
+}
+
+// reloadGeoIPDB reopens the database of the country restrictions when an API uses them.
+func (gw *Gateway) reloadGeoIPDB(specs []*APISpec) {
+	for _, spec := range specs {
+		if spec.GeoRestriction.Enabled {
+			gw.geoIPDB.reload(gw.GetConfig())
+			return
+		}
+	}
+}
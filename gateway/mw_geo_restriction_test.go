@@ -0,0 +1,167 @@
+package gateway
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+// testCountryDB maps 81.2.69.0/24 to GB, 216.160.83.0/24 to US and 89.160.20.0/24 to SE.
+var testCountryDB = filepath.Join("..", "testdata", "MaxMind-DB-test-country.mmdb")
+
+const (
+	testIPGB      = "81.2.69.142"
+	testIPUS      = "216.160.83.56"
+	testIPSE      = "89.160.20.112"
+	testIPUnknown = "203.0.113.7"
+)
+
+func TestCountryAllowed(t *testing.T) {
+	tests := []struct {
+		name             string
+		country          string
+		allowed, blocked []string
+		want             bool
+	}{
+		{"no lists", "GB", nil, nil, true},
+		{"allowed", "GB", []string{"GB", "US"}, nil, true},
+		{"allowed ignoring case", "GB", []string{"gb"}, nil, true},
+		{"not allowed", "SE", []string{"GB", "US"}, nil, false},
+		{"unknown with allowed list", "", []string{"GB"}, nil, false},
+		{"blocked", "SE", nil, []string{"SE"}, false},
+		{"not blocked", "GB", nil, []string{"SE"}, true},
+		{"unknown with blocked list", "", nil, []string{"SE"}, true},
+		{"blocked takes precedence", "GB", []string{"GB"}, []string{"GB"}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, countryAllowed(tc.country, tc.allowed, tc.blocked))
+		})
+	}
+}
+
+func TestGeoRestrictionMiddleware(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.GeoRestriction.DBPath = testCountryDB
+	})
+	defer ts.Close()
+
+	specs := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "geo-allow"
+		spec.Proxy.ListenPath = "/allow/"
+		spec.GeoRestriction = apidef.GeoRestriction{Enabled: true, AllowedCountries: []string{"GB", "US"}}
+	}, func(spec *APISpec) {
+		spec.APIID = "geo-block"
+		spec.Proxy.ListenPath = "/block/"
+		spec.GeoRestriction = apidef.GeoRestriction{Enabled: true, BlockedCountries: []string{"SE"}}
+	}, func(spec *APISpec) {
+		spec.APIID = "geo-disabled"
+		spec.Proxy.ListenPath = "/disabled/"
+		spec.GeoRestriction = apidef.GeoRestriction{Enabled: false, BlockedCountries: []string{"SE"}}
+	})
+
+	events := make(chan EventGeoRestrictionBlockedMeta, 10)
+	specs[1].EventPaths = map[apidef.TykEvent][]config.TykEventHandler{
+		EventGeoRestrictionBlocked: {&testEventHandler{func(em config.EventMessage) {
+			meta, ok := em.Meta.(EventGeoRestrictionBlockedMeta)
+			assert.True(t, ok)
+			events <- meta
+		}}},
+	}
+
+	withIP := func(ip string) map[string]string {
+		return map[string]string{header.XRealIP: ip}
+	}
+
+	t.Run("allowed countries", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/allow/", Headers: withIP(testIPGB), Code: http.StatusOK},
+			{Path: "/allow/", Headers: withIP(testIPUS), Code: http.StatusOK},
+			{Path: "/allow/", Headers: withIP(testIPSE), Code: http.StatusForbidden, BodyMatch: MsgGeoCountryBlocked},
+			{Path: "/allow/", Headers: withIP(testIPUnknown), Code: http.StatusForbidden, BodyMatch: MsgGeoCountryBlocked},
+		}...)
+	})
+
+	t.Run("blocked countries", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/block/", Headers: withIP(testIPGB), Code: http.StatusOK},
+			{Path: "/block/", Headers: withIP(testIPSE), Code: http.StatusForbidden, BodyMatch: MsgGeoCountryBlocked},
+			{Path: "/block/", Headers: withIP(testIPUnknown), Code: http.StatusOK},
+		}...)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{Path: "/disabled/", Headers: withIP(testIPSE), Code: http.StatusOK})
+	})
+
+	t.Run("event is fired", func(t *testing.T) {
+		for len(events) > 0 {
+			<-events
+		}
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/block/audit", Headers: withIP(testIPSE), Code: http.StatusForbidden})
+
+		select {
+		case meta := <-events:
+			assert.Equal(t, testIPSE, meta.Origin)
+			assert.Equal(t, "SE", meta.Country)
+			assert.Equal(t, "/block/audit", meta.Path)
+			assert.Equal(t, geoRestrictionReasonDenied, meta.Reason)
+		case <-time.After(time.Second):
+			t.Fatal("GeoRestrictionBlocked event wasn't fired")
+		}
+	})
+}
+
+func TestGeoRestrictionMiddleware_DatabaseUnavailable(t *testing.T) {
+	loadAPIs := func(ts *Test) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.APIID = "geo-fail-closed"
+			spec.Proxy.ListenPath = "/closed/"
+			spec.GeoRestriction = apidef.GeoRestriction{Enabled: true, AllowedCountries: []string{"GB"}}
+		}, func(spec *APISpec) {
+			spec.APIID = "geo-fail-open"
+			spec.Proxy.ListenPath = "/open/"
+			spec.GeoRestriction = apidef.GeoRestriction{Enabled: true, AllowedCountries: []string{"GB"}, FailOpen: true}
+		})
+	}
+
+	check := func(t *testing.T, ts *Test) {
+		t.Helper()
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/closed/", Headers: map[string]string{header.XRealIP: testIPGB}, Code: http.StatusForbidden, BodyMatch: MsgGeoDatabaseUnavailable},
+			{Path: "/open/", Headers: map[string]string{header.XRealIP: testIPSE}, Code: http.StatusOK},
+		}...)
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		ts := StartTest(func(globalConf *config.Config) {
+			globalConf.GeoRestriction.DBPath = filepath.Join(t.TempDir(), "missing.mmdb")
+		})
+		defer ts.Close()
+
+		loadAPIs(ts)
+		check(t, ts)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		// The test database was built on 2024-01-01.
+		ts := StartTest(func(globalConf *config.Config) {
+			globalConf.GeoRestriction.DBPath = testCountryDB
+			globalConf.GeoRestriction.MaxDBAge = 1
+		})
+		defer ts.Close()
+
+		loadAPIs(ts)
+		check(t, ts)
+	})
+}
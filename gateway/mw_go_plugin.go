@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -41,16 +42,34 @@ type customResponseWriter struct {
 	copyData       bool
 	data           []byte
 	dataLength     int64
+	// ctx is the context of the request, the writes are dropped once it's done.
+	ctx context.Context
 }
 
-func (w *customResponseWriter) Write(b []byte) (int, error) {
+// cancelled reports whether the request of the response is cancelled.
+func (w *customResponseWriter) cancelled() bool {
+	return w.ctx != nil && w.ctx.Err() != nil
+}
+
+func (w *customResponseWriter) Write(b []byte) (num int, err error) {
+	if w.cancelled() {
+		return 0, w.ctx.Err()
+	}
+
+	// the connection may be gone by the time a plugin writes, don't let it bring the gateway down
+	defer func() {
+		if e := recover(); e != nil {
+			num, err = 0, fmt.Errorf("response write failed: %v", e)
+		}
+	}()
+
 	w.responseSent = true
 	if w.statusCodeSent == 0 {
 		w.statusCodeSent = http.StatusOK // no WriteHeader was called so it will be set to StatusOK in actual ResponseWriter
 	}
 
 	// send actual data
-	num, err := w.ResponseWriter.Write(b)
+	num, err = w.ResponseWriter.Write(b)
 
 	// copy data sent
 	if w.copyData {
@@ -69,12 +88,25 @@ func (w *customResponseWriter) Write(b []byte) (int, error) {
 }
 
 func (w *customResponseWriter) WriteHeader(statusCode int) {
+	if w.cancelled() {
+		return
+	}
+
+	// the status line is already out, a superfluous call is dropped like net/http does
+	if w.statusCodeSent >= http.StatusOK {
+		return
+	}
+
 	w.responseSent = true
 	w.statusCodeSent = statusCode
 	w.ResponseWriter.WriteHeader(statusCode)
 }
 
 func (w *customResponseWriter) Flush() {
+	if w.cancelled() {
+		return
+	}
+
 	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
 		flusher.Flush()
 	}
@@ -99,7 +131,13 @@ func (w *customResponseWriter) getHttpResponse(r *http.Request) *http.Response {
 	return httpResponse
 }
 
-// GoPluginMiddleware is a generic middleware that will execute Go-plugin code before continuing
+// GoPluginMiddleware is a generic middleware that will execute Go-plugin code before continuing.
+//
+// A plugin function can't be interrupted, it runs to completion. The request it gets carries the
+// context of the client request, which is cancelled when the client disconnects: long running
+// plugins should watch r.Context() and return early. Once the context is done, the writes to the
+// http.ResponseWriter given to the plugin are dropped and return the context error, they never
+// panic, and the middleware chain ends without a response.
 type GoPluginMiddleware struct {
 	*BaseMiddleware
 
@@ -199,11 +237,17 @@ func (m *GoPluginMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Reque
 		return errors.New(http.StatusText(http.StatusInternalServerError)), http.StatusInternalServerError
 	}
 
+	logger = logger.WithFields(m.Gw.requestLogEntry(m.Spec, r).Data)
+
 	rw, ms, err := m.executePluginHandler(w, r, handler, logger)
 	if err != nil {
 		return err, http.StatusInternalServerError
 	}
 
+	if !rw.responseSent && requestCancelled(r) {
+		return abortMiddleware(r, m.Name(), logger)
+	}
+
 	return m.handlePluginResponse(r, rw, ms, logger, successHandler)
 }
 
@@ -259,6 +303,7 @@ func (m *GoPluginMiddleware) executePluginHandler(
 	rw := &customResponseWriter{
 		ResponseWriter: w,
 		copyData:       recordDetail(r, m.Spec),
+		ctx:            r.Context(),
 	}
 
 	// call Go-plugin function
@@ -1,8 +1,13 @@
 package gateway
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/TykTechnologies/tyk/apidef"
@@ -53,3 +58,73 @@ func TestGoPluginMiddleware_EnabledForSpec(t *testing.T) {
 		})
 	})
 }
+
+func TestCustomResponseWriter_WriteHeader(t *testing.T) {
+	t.Run("superfluous calls are dropped", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		w := &customResponseWriter{ResponseWriter: recorder}
+
+		w.WriteHeader(http.StatusTeapot)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("body"))
+		assert.NoError(t, err)
+		w.WriteHeader(http.StatusBadGateway)
+
+		assert.Equal(t, http.StatusTeapot, w.statusCodeSent)
+		assert.Equal(t, http.StatusTeapot, recorder.Code)
+	})
+
+	t.Run("invalid status code panics", func(t *testing.T) {
+		w := &customResponseWriter{ResponseWriter: httptest.NewRecorder()}
+
+		assert.Panics(t, func() {
+			w.WriteHeader(0)
+		})
+	})
+}
+
+func TestGoPluginMiddleware_Cancellation(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	aborted := recordAbortedMiddleware(t)
+
+	reqCtx, cancel := context.WithCancel(context.Background())
+
+	gpm := &GoPluginMiddleware{
+		BaseMiddleware: &BaseMiddleware{Spec: &APISpec{APIDefinition: &apidef.APIDefinition{}}, Gw: ts.Gw},
+		APILevel:       true,
+		logger:         logrus.NewEntry(log),
+		handler: func(w http.ResponseWriter, r *http.Request) {
+			cancel()
+			<-r.Context().Done()
+
+			w.WriteHeader(http.StatusTeapot)
+			n, err := w.Write([]byte("too late"))
+			assert.Zero(t, n)
+			assert.ErrorIs(t, err, context.Canceled)
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/foo", nil).WithContext(reqCtx)
+	recorder := httptest.NewRecorder()
+
+	done := make(chan bool, 1)
+	go func() {
+		err, code := gpm.ProcessRequest(recorder, req, nil)
+		assert.ErrorIs(t, err, ErrMiddlewareCancelled)
+		assert.Equal(t, StatusClientClosedRequest, code)
+		done <- true
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Go plugin middleware didn't return")
+	}
+
+	assert.False(t, recorder.Flushed)
+	assert.Empty(t, recorder.Body.String(), "the writes after the cancellation are dropped")
+	assert.NotEqual(t, http.StatusTeapot, recorder.Code)
+	assert.Equal(t, int32(1), aborted.aborted.Load())
+}
@@ -1,12 +1,14 @@
 package gateway
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
 
+	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/internal/graphengine"
 	"github.com/TykTechnologies/tyk/user"
 )
@@ -33,6 +35,11 @@ func (m *GraphQLComplexityMiddleware) EnabledForSpec() bool {
 
 // ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
 func (m *GraphQLComplexityMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	// Persisted operations are defined in the API, so they are trusted.
+	if m.isPersistedOperation(r) {
+		return nil, http.StatusOK
+	}
+
 	accessDef, _, err := GetAccessDefinitionByAPIIDOrSession(ctxGetSession(r), m.Spec)
 	if err != nil {
 		m.Logger().Debugf("Error while calculating GraphQL complexity: '%s'", err)
@@ -42,6 +49,8 @@ func (m *GraphQLComplexityMiddleware) ProcessRequest(w http.ResponseWriter, r *h
 	graphEngineComplexityAccessDefinition := &graphengine.ComplexityAccessDefinition{
 		Limit: graphengine.ComplexityLimit{
 			MaxQueryDepth: accessDef.Limit.MaxQueryDepth,
+			MaxQueryNodes: accessDef.Limit.MaxQueryNodes,
+			MaxAliases:    accessDef.Limit.MaxAliases,
 		},
 		FieldAccessRights: []graphengine.ComplexityFieldAccessDefinition{},
 	}
@@ -56,7 +65,35 @@ func (m *GraphQLComplexityMiddleware) ProcessRequest(w http.ResponseWriter, r *h
 		})
 	}
 
-	return m.Spec.GraphEngine.ProcessGraphQLComplexity(r, graphEngineComplexityAccessDefinition)
+	err, statusCode := m.Spec.GraphEngine.ProcessGraphQLComplexity(r, graphEngineComplexityAccessDefinition)
+	if errors.Is(err, graphengine.GraphQLDepthLimitExceededErr) ||
+		errors.Is(err, graphengine.GraphQLNodeLimitExceededErr) ||
+		errors.Is(err, graphengine.GraphQLAliasLimitExceededErr) {
+		return m.writeGraphQLError(w, err, statusCode)
+	}
+
+	return err, statusCode
+}
+
+func (m *GraphQLComplexityMiddleware) isPersistedOperation(r *http.Request) bool {
+	persistMw := &PersistGraphQLOperationMiddleware{BaseMiddleware: m.BaseMiddleware}
+	if !persistMw.EnabledForSpec() {
+		return false
+	}
+
+	vInfo, _ := m.Spec.Version(r)
+	found, _ := m.Spec.CheckSpecMatchesStatus(r, m.Spec.RxPaths[vInfo.Name], PersistGraphQL)
+	return found
+}
+
+// writeGraphQLError responds with err in the GraphQL errors format, so clients can handle
+// a rejected query the same way as one rejected by the upstream.
+func (m *GraphQLComplexityMiddleware) writeGraphQLError(w http.ResponseWriter, err error, statusCode int) (error, int) {
+	w.Header().Set(header.ContentType, header.ApplicationJSON)
+	w.WriteHeader(statusCode)
+	_, _ = graphql.RequestErrorsFromError(err).WriteResponse(w)
+
+	return errCustomBodyResponse, statusCode
 }
 
 func (m *GraphQLComplexityMiddleware) handleComplexityFailReason(failReason ComplexityFailReason) (error, int) {
@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/sirupsen/logrus"
@@ -10,6 +11,9 @@ import (
 
 	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
 
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
 	"github.com/TykTechnologies/tyk/user"
 )
 
@@ -325,7 +329,7 @@ func TestGraphQLComplexityMiddleware_ProcessRequest_GraphqlLimits(t *testing.T)
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			session.AccessRights = tc.rights
-			_, failReason := m.ProcessRequest(nil, httpReq, nil)
+			_, failReason := m.ProcessRequest(httptest.NewRecorder(), httpReq, nil)
 			assert.Equal(t, tc.result, failReason)
 		})
 	}
@@ -400,3 +404,61 @@ const gqlInvalidCountriesQuery = `{
       }
     }
 }`
+
+func TestGraphQLComplexityMiddleware_QuerySizeLimits(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	const persistedOperation = `query { countries { code name } }`
+
+	spec := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.UseKeylessAccess = false
+		spec.Proxy.ListenPath = "/"
+		spec.GraphQL.Enabled = true
+		spec.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeProxyOnly
+		spec.GraphQL.Schema = gqlCountriesSchema
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.UseExtendedPaths = true
+			v.ExtendedPaths.PersistGraphQL = []apidef.PersistGraphQLMeta{
+				{Path: "/trusted", Method: http.MethodPost, Operation: persistedOperation},
+			}
+		})
+	})[0]
+
+	pID := ts.CreatePolicy(func(p *user.Policy) {
+		p.MaxQueryDepth = 3
+		p.MaxQueryNodes = 4
+		p.MaxAliases = 3
+		p.AccessRights = map[string]user.AccessDefinition{
+			spec.APIID: {APIID: spec.APIID, APIName: spec.Name},
+		}
+	})
+
+	session, key := ts.CreateSession(func(s *user.SessionState) {
+		s.ApplyPolicies = []string{pID}
+	})
+	require.Equal(t, 4, session.AccessRights[spec.APIID].Limit.MaxQueryNodes)
+	require.Equal(t, 3, session.AccessRights[spec.APIID].Limit.MaxAliases)
+
+	authHeader := map[string]string{header.Authorization: key}
+	query := func(q string) graphql.Request {
+		return graphql.Request{Query: q}
+	}
+
+	const (
+		normalQuery = `{ countries { code name continent { code } } }`
+		nestedQuery = `{ countries { continent { countries { continent { code } } } } }`
+		wideQuery   = `{ countries { continent { code } languages { code } states { code } } continents { code } }`
+		aliasQuery  = `{ a: countries { code } b: countries { code } c: countries { code } d: countries { code } }`
+	)
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Headers: authHeader, Method: http.MethodPost, Data: query(normalQuery), Code: http.StatusOK},
+		{Headers: authHeader, Method: http.MethodPost, Data: query(nestedQuery), BodyMatch: `{"errors":\[{"message":"depth limit exceeded"}\]}`, Code: http.StatusForbidden},
+		{Headers: authHeader, Method: http.MethodPost, Data: query(wideQuery), BodyMatch: `{"errors":\[{"message":"query node limit exceeded"}\]}`, Code: http.StatusBadRequest},
+		{Headers: authHeader, Method: http.MethodPost, Data: query(aliasQuery), BodyMatch: `{"errors":\[{"message":"query alias limit exceeded"}\]}`, Code: http.StatusBadRequest},
+		{Headers: authHeader, Method: http.MethodPost, Data: query(gqlIntrospectionQuery), Code: http.StatusOK},
+		// The persisted operation replaces the request body, so the check is skipped.
+		{Headers: authHeader, Method: http.MethodPost, Path: "/trusted", Data: query(aliasQuery), Code: http.StatusOK},
+	}...)
+}
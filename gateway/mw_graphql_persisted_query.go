@@ -0,0 +1,227 @@
+package gateway
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/websocket"
+
+	gql "github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
+
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+const (
+	graphqlPersistedQueryKeyPrefix = "graphql-persisted-query-"
+	graphqlDocumentIDHashPrefix    = "sha256:"
+)
+
+var (
+	errPersistedQueryNotFound     = errors.New("PersistedQueryNotFound")
+	errPersistedQueryNotAllowed   = errors.New("PersistedQueryNotAllowed")
+	errPersistedQueryHashMismatch = errors.New("provided sha does not match query")
+)
+
+// persistedQueryErrorCodes are the error codes of the automatic persisted queries protocol.
+var persistedQueryErrorCodes = map[error]string{
+	errPersistedQueryNotFound:     "PERSISTED_QUERY_NOT_FOUND",
+	errPersistedQueryNotAllowed:   "PERSISTED_QUERY_NOT_ALLOWED",
+	errPersistedQueryHashMismatch: "INVALID_PERSISTED_QUERY_HASH",
+}
+
+// GraphQLPersistedQueryMiddleware replaces the hash of a persisted query sent by the client with the
+// stored query document, before the GraphQL middleware processes the request.
+type GraphQLPersistedQueryMiddleware struct {
+	*BaseMiddleware
+
+	store storage.Handler
+}
+
+func (m *GraphQLPersistedQueryMiddleware) Name() string {
+	return "GraphQLPersistedQueryMiddleware"
+}
+
+func (m *GraphQLPersistedQueryMiddleware) EnabledForSpec() bool {
+	return m.Spec.GraphQL.Enabled && m.Spec.GraphQL.PersistedQueries.Enabled
+}
+
+func (m *GraphQLPersistedQueryMiddleware) Init() {
+	m.store = m.Gw.graphqlPersistedQueryStore(m.Spec.APIID)
+}
+
+func (m *GraphQLPersistedQueryMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	if websocket.IsWebSocketUpgrade(r) || r.Body == nil {
+		return nil, http.StatusOK
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		m.Logger().WithError(err).Error("error reading request")
+		return errors.New("error reading the request"), http.StatusBadRequest
+	}
+	_ = r.Body.Close()
+	setRequestBody(r, body)
+
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(body, &payload); err != nil {
+		// the GraphQL middleware reports malformed requests
+		return nil, http.StatusOK
+	}
+
+	var query string
+	_ = json.Unmarshal(payload["query"], &query)
+	hash := persistedQueryHash(payload)
+
+	switch {
+	case hash == "" && query == "":
+		return nil, http.StatusOK
+	case hash == "":
+		if m.Spec.GraphQL.PersistedQueries.Strict && !isIntrospectionOnly(query) {
+			return m.writePersistedQueryError(w, errPersistedQueryNotAllowed)
+		}
+		return nil, http.StatusOK
+	case query == "":
+		stored, found := m.lookup(hash)
+		if !found {
+			return m.writePersistedQueryError(w, errPersistedQueryNotFound)
+		}
+		query = stored
+	default:
+		if persistedQueryHashOf(query) != hash {
+			return m.writePersistedQueryError(w, errPersistedQueryHashMismatch)
+		}
+		if _, found := m.lookup(hash); !found {
+			if m.Spec.GraphQL.PersistedQueries.Strict {
+				return m.writePersistedQueryError(w, errPersistedQueryNotAllowed)
+			}
+			if err := m.store.SetKey(hash, query, 0); err != nil {
+				m.Logger().WithError(err).Error("Could not register persisted query")
+			}
+		}
+	}
+
+	if err := rewritePersistedQueryPayload(payload, query); err != nil {
+		m.Logger().WithError(err).Error("error rewriting persisted query request")
+		return ProxyingRequestFailedErr, http.StatusInternalServerError
+	}
+
+	body, err = json.Marshal(payload)
+	if err != nil {
+		m.Logger().WithError(err).Error("error rewriting persisted query request")
+		return ProxyingRequestFailedErr, http.StatusInternalServerError
+	}
+	setRequestBody(r, body)
+
+	return nil, http.StatusOK
+}
+
+// lookup returns the query document of hash, from the API definition first and then from the store.
+func (m *GraphQLPersistedQueryMiddleware) lookup(hash string) (string, bool) {
+	if query, ok := m.Spec.GraphQL.PersistedQueries.Queries[hash]; ok {
+		return query, true
+	}
+
+	query, err := m.store.GetKey(hash)
+	if err != nil {
+		return "", false
+	}
+	return query, true
+}
+
+// writePersistedQueryError responds with err in the GraphQL errors format, with the error code
+// clients of the automatic persisted queries protocol expect.
+func (m *GraphQLPersistedQueryMiddleware) writePersistedQueryError(w http.ResponseWriter, err error) (error, int) {
+	type persistedQueryError struct {
+		Message    string            `json:"message"`
+		Extensions map[string]string `json:"extensions"`
+	}
+
+	response := struct {
+		Errors []persistedQueryError `json:"errors"`
+	}{
+		Errors: []persistedQueryError{{
+			Message:    err.Error(),
+			Extensions: map[string]string{"code": persistedQueryErrorCodes[err]},
+		}},
+	}
+
+	w.Header().Set(header.ContentType, header.ApplicationJSON)
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(response)
+
+	return errCustomBodyResponse, http.StatusBadRequest
+}
+
+// persistedQueryHash returns the lower case hash of the persisted query of the request, if any,
+// from the persistedQuery extension or from documentId.
+func persistedQueryHash(payload map[string]json.RawMessage) string {
+	var extensions struct {
+		PersistedQuery struct {
+			Sha256Hash string `json:"sha256Hash"`
+		} `json:"persistedQuery"`
+	}
+	if err := json.Unmarshal(payload["extensions"], &extensions); err == nil && extensions.PersistedQuery.Sha256Hash != "" {
+		return strings.ToLower(extensions.PersistedQuery.Sha256Hash)
+	}
+
+	var documentID string
+	_ = json.Unmarshal(payload["documentId"], &documentID)
+	return strings.ToLower(strings.TrimPrefix(documentID, graphqlDocumentIDHashPrefix))
+}
+
+// rewritePersistedQueryPayload sets the query of the request and removes the hash of the persisted query,
+// so the upstream of proxy-only APIs gets a regular GraphQL request.
+func rewritePersistedQueryPayload(payload map[string]json.RawMessage, query string) error {
+	rawQuery, err := json.Marshal(query)
+	if err != nil {
+		return err
+	}
+	payload["query"] = rawQuery
+	delete(payload, "documentId")
+
+	var extensions map[string]json.RawMessage
+	if err := json.Unmarshal(payload["extensions"], &extensions); err != nil {
+		return nil
+	}
+	delete(extensions, "persistedQuery")
+	if len(extensions) == 0 {
+		delete(payload, "extensions")
+		return nil
+	}
+
+	payload["extensions"], err = json.Marshal(extensions)
+	return err
+}
+
+func persistedQueryHashOf(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// isIntrospectionOnly tells whether the query only selects introspection fields, which are
+// left to the introspection settings of the policies.
+func isIntrospectionOnly(query string) bool {
+	gqlRequest := gql.Request{Query: query}
+	isIntrospection, err := gqlRequest.IsIntrospectionQueryStrict()
+	return err == nil && isIntrospection
+}
+
+func setRequestBody(r *http.Request, body []byte) {
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+}
+
+// graphqlPersistedQueryStore returns the store of the persisted queries of an API
+// uploaded with the control API or registered by clients.
+func (gw *Gateway) graphqlPersistedQueryStore(apiID string) storage.Handler {
+	store := &storage.RedisCluster{KeyPrefix: graphqlPersistedQueryKeyPrefix + apiID + "-", ConnectionHandler: gw.StorageConnectionHandler}
+	store.Connect()
+	return store
+}
@@ -0,0 +1,131 @@
+package gateway
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func persistedQueryRequest(hash string, variables map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"variables": variables,
+		"extensions": map[string]interface{}{
+			"persistedQuery": map[string]interface{}{"version": 1, "sha256Hash": hash},
+		},
+	}
+}
+
+func TestGraphQLPersistedQueryMiddleware(t *testing.T) {
+	g := StartTest(nil)
+	defer g.Close()
+
+	const (
+		helloQuery   = `query Hello($a: String!) { hello(name: $a) }`
+		methodQuery  = `query Method($a: String!) { hello(name: $a) httpMethod }`
+		unknownQuery = `query Unknown($a: String!) { httpMethod }`
+	)
+	helloHash, methodHash := persistedQueryHashOf(helloQuery), persistedQueryHashOf(methodQuery)
+	variables := map[string]interface{}{"a": "World"}
+
+	loadAPI := func(strict bool) *APISpec {
+		return g.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.UseKeylessAccess = true
+			spec.Proxy.ListenPath = "/"
+			spec.Proxy.TargetURL = testGraphQLProxyUpstream
+			spec.GraphQL.Enabled = true
+			spec.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeProxyOnly
+			spec.GraphQL.Version = apidef.GraphQLConfigVersion2
+			spec.GraphQL.Schema = gqlProxyUpstreamSchema
+			spec.GraphQL.PersistedQueries = apidef.GraphQLPersistedQueriesConfig{
+				Enabled: true,
+				Strict:  strict,
+				Queries: map[string]string{helloHash: helloQuery},
+			}
+		})[0]
+	}
+
+	t.Run("proxy only", func(t *testing.T) {
+		loadAPI(false)
+
+		_, err := g.Run(t, []test.TestCase{
+			{Method: http.MethodPost, Data: persistedQueryRequest(helloHash, variables), Code: http.StatusOK, BodyMatch: `"hello":"World"`},
+			{Method: http.MethodPost, Data: map[string]interface{}{"documentId": "sha256:" + helloHash, "variables": variables}, Code: http.StatusOK, BodyMatch: `"hello":"World"`},
+			// hash miss, the client registers the query by sending it along with its hash
+			{Method: http.MethodPost, Data: persistedQueryRequest(methodHash, variables), Code: http.StatusBadRequest, BodyMatch: `"message":"PersistedQueryNotFound"`},
+			{Method: http.MethodPost, Data: map[string]interface{}{
+				"query":      methodQuery,
+				"variables":  variables,
+				"extensions": map[string]interface{}{"persistedQuery": map[string]interface{}{"version": 1, "sha256Hash": methodHash}},
+			}, Code: http.StatusOK, BodyMatch: `"httpMethod":"POST"`},
+			{Method: http.MethodPost, Data: persistedQueryRequest(methodHash, variables), Code: http.StatusOK, BodyMatch: `"httpMethod":"POST"`},
+			{Method: http.MethodPost, Data: map[string]interface{}{
+				"query":      unknownQuery,
+				"variables":  variables,
+				"extensions": map[string]interface{}{"persistedQuery": map[string]interface{}{"version": 1, "sha256Hash": helloHash}},
+			}, Code: http.StatusBadRequest, BodyMatch: `"code":"INVALID_PERSISTED_QUERY_HASH"`},
+			// regular queries are allowed when not strict
+			{Method: http.MethodPost, Data: map[string]interface{}{"query": helloQuery, "variables": variables}, Code: http.StatusOK, BodyMatch: `"hello":"World"`},
+		}...)
+		assert.NoError(t, err)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		spec := loadAPI(true)
+		uploadedQuery := `query Uploaded($a: String!) { hello(name: $a) }`
+
+		_, err := g.Run(t, []test.TestCase{
+			{Method: http.MethodPost, Data: persistedQueryRequest(helloHash, variables), Code: http.StatusOK, BodyMatch: `"hello":"World"`},
+			{Method: http.MethodPost, Data: map[string]interface{}{"query": unknownQuery, "variables": variables}, Code: http.StatusBadRequest, BodyMatch: `"message":"PersistedQueryNotAllowed"`},
+			{Method: http.MethodPost, Data: map[string]interface{}{
+				"query":      unknownQuery,
+				"variables":  variables,
+				"extensions": map[string]interface{}{"persistedQuery": map[string]interface{}{"version": 1, "sha256Hash": persistedQueryHashOf(unknownQuery)}},
+			}, Code: http.StatusBadRequest, BodyMatch: `"code":"PERSISTED_QUERY_NOT_ALLOWED"`},
+			// introspection is left to the introspection interception of the GraphQL middleware
+			{Method: http.MethodPost, Data: map[string]interface{}{"query": gqlIntrospectionQuery}, Code: http.StatusOK, BodyMatch: `"__schema"`},
+			{Method: http.MethodPost, Path: "/tyk/apis/" + spec.APIID + "/graphql/persisted-queries", AdminAuth: true,
+				Data: map[string]interface{}{"queries": []string{uploadedQuery}}, Code: http.StatusOK, BodyMatch: persistedQueryHashOf(uploadedQuery)},
+			{Method: http.MethodPost, Data: persistedQueryRequest(persistedQueryHashOf(uploadedQuery), variables), Code: http.StatusOK, BodyMatch: `"hello":"World"`},
+		}...)
+		assert.NoError(t, err)
+	})
+
+	t.Run("execution engine", func(t *testing.T) {
+		const typenameQuery = `query { __typename }`
+
+		g.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.UseKeylessAccess = true
+			spec.Proxy.ListenPath = "/"
+			spec.GraphQL.Enabled = true
+			spec.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeExecutionEngine
+			spec.GraphQL.Version = apidef.GraphQLConfigVersion2
+			spec.GraphQL.PersistedQueries = apidef.GraphQLPersistedQueriesConfig{
+				Enabled: true,
+				Strict:  true,
+				Queries: map[string]string{persistedQueryHashOf(typenameQuery): typenameQuery},
+			}
+		})
+
+		_, err := g.Run(t, []test.TestCase{
+			{Method: http.MethodPost, Data: persistedQueryRequest(persistedQueryHashOf(typenameQuery), nil), Code: http.StatusOK, BodyMatch: `{"data":{"__typename":"Query"}}`},
+			{Method: http.MethodPost, Data: map[string]interface{}{"query": `query { countries { name } }`}, Code: http.StatusBadRequest, BodyMatch: `PersistedQueryNotAllowed`},
+		}...)
+		assert.NoError(t, err)
+	})
+
+	t.Run("upload", func(t *testing.T) {
+		spec := loadAPI(false)
+
+		_, err := g.Run(t, []test.TestCase{
+			{Method: http.MethodPost, Path: "/tyk/apis/unknown/graphql/persisted-queries", AdminAuth: true,
+				Data: map[string]interface{}{"queries": []string{helloQuery}}, Code: http.StatusNotFound},
+			{Method: http.MethodPost, Path: "/tyk/apis/" + spec.APIID + "/graphql/persisted-queries", AdminAuth: true,
+				Data: map[string]interface{}{"queries": []string{}}, Code: http.StatusBadRequest},
+		}...)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,262 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+	"github.com/TykTechnologies/tyk/ctx"
+	"github.com/TykTechnologies/tyk/internal/crypto"
+	"github.com/TykTechnologies/tyk/internal/middleware"
+	"github.com/TykTechnologies/tyk/request"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+const (
+	// idempotencyKeyPrefix prefixes the Redis keys holding the stored responses and the in-flight locks.
+	idempotencyKeyPrefix = "idempotency-"
+
+	// idempotencyLockTTL is the time after which a request in flight stops blocking its duplicates,
+	// when its gateway didn't release the lock.
+	idempotencyLockTTL = 5 * time.Minute
+
+	// idempotencyPollInterval is how often a waiting duplicate checks for the response of the request in flight.
+	idempotencyPollInterval = 50 * time.Millisecond
+
+	defaultIdempotencyHeaderName      = "Idempotency-Key"
+	defaultIdempotencyTTL             = 24 * 60 * 60
+	defaultIdempotencyMaxResponseSize = 1 << 20
+	defaultIdempotencyWaitTimeout     = 10
+
+	// idempotentReplayedHeader is set on the responses replayed for a duplicate request.
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	// idempotencyWarningHeader is set on the responses which couldn't be stored for the duplicates.
+	idempotencyWarningHeader = "X-Tyk-Idempotency-Warning"
+)
+
+var errIdempotencyInFlight = errors.New("A request with the same idempotency key is in progress")
+
+// idempotentResponse is the response stored for the duplicates of a request.
+type idempotentResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+// idempotencyState holds the idempotency key of the request holding the in-flight lock. The lock
+// is taken in the middleware chain and released by the handler wrapping the chain once the request completed.
+type idempotencyState struct {
+	key     string
+	release func()
+}
+
+// Idempotency replays the stored response of a POST request retried with the same idempotency key,
+// instead of proxying it again. The first request takes an in-flight lock, its duplicates get
+// a 409 Conflict or wait for its response until it's stored by IdempotencyResponseMiddleware.
+type Idempotency struct {
+	*BaseMiddleware
+
+	store *storage.RedisCluster
+	sh    SuccessHandler
+}
+
+func (m *Idempotency) Name() string {
+	return "Idempotency"
+}
+
+func (m *Idempotency) EnabledForSpec() bool {
+	return m.Spec.Idempotency.Enabled
+}
+
+func (m *Idempotency) Init() {
+	m.sh = SuccessHandler{m.BaseMiddleware}
+	m.store = newIdempotencyStore(m.Gw)
+}
+
+func (m *Idempotency) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	if r.Method != http.MethodPost {
+		return nil, http.StatusOK
+	}
+
+	state := ctxGetIdempotencyState(r)
+	if state == nil {
+		return nil, http.StatusOK
+	}
+
+	idempotencyKey := r.Header.Get(idempotencyHeaderName(m.Spec))
+	if idempotencyKey == "" {
+		return nil, http.StatusOK
+	}
+
+	key := m.storageKey(r, idempotencyKey)
+	if m.replay(w, r, key) {
+		return nil, middleware.StatusRespond
+	}
+
+	if locked, err := m.lock(state, key); err != nil || locked {
+		// When redis fails, the request is proxied rather than blocking all traffic.
+		return nil, http.StatusOK
+	}
+
+	if !m.Spec.Idempotency.WaitForInFlight {
+		return errIdempotencyInFlight, http.StatusConflict
+	}
+
+	return m.waitForInFlight(w, r, state, key)
+}
+
+// storageKey returns the key of the stored response, unique per API, key or client IP, and idempotency key.
+func (m *Idempotency) storageKey(r *http.Request, idempotencyKey string) string {
+	token := ctxGetAuthToken(r)
+
+	// No authentication data? use the IP.
+	if token == "" {
+		token = request.RealIP(r)
+	}
+
+	return crypto.HexSHA256([]byte(m.Spec.APIID + "|" + token + "|" + idempotencyKey))
+}
+
+// lock takes the in-flight lock of key for the request and reports whether it's the first one.
+func (m *Idempotency) lock(state *idempotencyState, key string) (bool, error) {
+	lockKey := idempotencyLockKey(key)
+
+	locked, err := m.store.Lock(lockKey, idempotencyLockTTL)
+	if err != nil {
+		m.Logger().WithError(err).Error("Failed to take the idempotency key lock")
+		return false, err
+	}
+
+	if locked {
+		state.key = key
+		state.release = func() {
+			m.store.DeleteRawKey(lockKey)
+		}
+	}
+
+	return locked, nil
+}
+
+// waitForInFlight waits for the response of the request in flight with the same idempotency key. When
+// that request completes without storing a response, the waiting request takes the lock and is proxied.
+func (m *Idempotency) waitForInFlight(w http.ResponseWriter, r *http.Request, state *idempotencyState, key string) (error, int) {
+	timeout := m.Spec.Idempotency.WaitTimeout
+	if timeout <= 0 {
+		timeout = defaultIdempotencyWaitTimeout
+	}
+
+	deadline := time.NewTimer(time.Duration(timeout) * time.Second)
+	defer deadline.Stop()
+
+	ticker := time.NewTicker(idempotencyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return errIdempotencyInFlight, http.StatusConflict
+		case <-deadline.C:
+			return errIdempotencyInFlight, http.StatusConflict
+		case <-ticker.C:
+			if m.replay(w, r, key) {
+				return nil, middleware.StatusRespond
+			}
+
+			if locked, err := m.lock(state, key); err != nil || locked {
+				return nil, http.StatusOK
+			}
+		}
+	}
+}
+
+// replay writes the response stored for key and reports whether there was one.
+func (m *Idempotency) replay(w http.ResponseWriter, r *http.Request, key string) bool {
+	t1 := time.Now()
+
+	stored, err := m.store.GetKey(key)
+	if err != nil {
+		return false
+	}
+
+	var res idempotentResponse
+	if err := json.Unmarshal([]byte(stored), &res); err != nil {
+		m.Logger().WithError(err).Error("Could not decode the stored idempotent response")
+		m.store.DeleteKey(key)
+		return false
+	}
+
+	if res.Header == nil {
+		res.Header = http.Header{}
+	}
+
+	m.Gw.limitHeaderFactory(res.Header).SendQuotas(ctxGetSession(r), m.Spec.APIID)
+	res.Header.Set(idempotentReplayedHeader, "true")
+	removeUpstreamRequestID(r, res.Header)
+
+	copyHeader(w.Header(), res.Header, m.Gw.GetConfig().IgnoreCanonicalMIMEHeaderKey)
+	w.WriteHeader(res.StatusCode)
+	_, _ = w.Write(res.Body)
+
+	if !m.Spec.DoNotTrack {
+		newRes := &http.Response{
+			StatusCode: res.StatusCode,
+			Header:     res.Header,
+			Body:       io.NopCloser(bytes.NewReader(res.Body)),
+		}
+
+		ms := DurationToMillisecond(time.Since(t1))
+		latency := analytics.Latency{Total: int64(ms), Upstream: 0, Gateway: int64(ms)}
+		m.sh.RecordHit(r, latency, newRes.StatusCode, newRes, true)
+		m.sh.RecordAccessLog(r, newRes, latency)
+		m.sh.Base().RecordMetrics(w, r, newRes.StatusCode, latency, newRes)
+	}
+
+	return true
+}
+
+func newIdempotencyStore(gw *Gateway) *storage.RedisCluster {
+	return &storage.RedisCluster{KeyPrefix: idempotencyKeyPrefix, ConnectionHandler: gw.StorageConnectionHandler}
+}
+
+func idempotencyLockKey(key string) string {
+	return idempotencyKeyPrefix + "lock-" + key
+}
+
+func idempotencyHeaderName(spec *APISpec) string {
+	if spec.Idempotency.HeaderName != "" {
+		return spec.Idempotency.HeaderName
+	}
+
+	return defaultIdempotencyHeaderName
+}
+
+// releaseIdempotencyLock wraps h so the idempotency key lock taken while serving
+// a request is released once it completed, was cancelled or panicked.
+func releaseIdempotencyLock(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		state := &idempotencyState{}
+		defer func() {
+			if state.release != nil {
+				state.release()
+			}
+		}()
+
+		r = r.WithContext(context.WithValue(r.Context(), ctx.IdempotencyState, state))
+		h.ServeHTTP(w, r)
+	})
+}
+
+func ctxGetIdempotencyState(r *http.Request) *idempotencyState {
+	state, _ := r.Context().Value(ctx.IdempotencyState).(*idempotencyState)
+	return state
+}
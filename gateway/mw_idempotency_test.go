@@ -0,0 +1,230 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+// idempotencyUpstream counts the requests it serves, a request blocks while the gate is held.
+type idempotencyUpstream struct {
+	*httptest.Server
+
+	hits    atomic.Int64
+	gate    sync.RWMutex
+	started chan struct{}
+}
+
+func newIdempotencyUpstream(bodySize int) *idempotencyUpstream {
+	u := &idempotencyUpstream{started: make(chan struct{}, 10)}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hit := u.hits.Add(1)
+		select {
+		case u.started <- struct{}{}:
+		default:
+		}
+
+		u.gate.RLock()
+		defer u.gate.RUnlock()
+
+		w.Header().Set("X-Upstream-Hit", strconv.FormatInt(hit, 10))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hit " + strconv.FormatInt(hit, 10) + strings.Repeat(".", bodySize)))
+	}))
+
+	return u
+}
+
+func (ts *Test) loadIdempotencyAPI(upstream *idempotencyUpstream, conf apidef.Idempotency) {
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/payments/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.Idempotency = conf
+	})
+}
+
+func TestIdempotency(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream := newIdempotencyUpstream(0)
+	defer upstream.Close()
+
+	ts.loadIdempotencyAPI(upstream, apidef.Idempotency{Enabled: true})
+
+	withKey := func(key string) map[string]string {
+		return map[string]string{"Idempotency-Key": key}
+	}
+
+	t.Run("duplicates replay the stored response", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Method: http.MethodPost, Path: "/payments/", Headers: withKey("replay"), Code: http.StatusCreated, BodyMatch: "hit 1$",
+				HeadersNotMatch: map[string]string{idempotentReplayedHeader: "true"}},
+			{Method: http.MethodPost, Path: "/payments/", Headers: withKey("replay"), Code: http.StatusCreated, BodyMatch: "hit 1$",
+				HeadersMatch: map[string]string{idempotentReplayedHeader: "true", "X-Upstream-Hit": "1"}},
+		}...)
+
+		assert.Equal(t, int64(1), upstream.hits.Load())
+	})
+
+	t.Run("other requests are proxied", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Method: http.MethodPost, Path: "/payments/", Headers: withKey("other"), Code: http.StatusCreated, BodyMatch: "hit 2$"},
+			{Method: http.MethodPost, Path: "/payments/", Code: http.StatusCreated, BodyMatch: "hit 3$"},
+			{Method: http.MethodPut, Path: "/payments/", Headers: withKey("replay"), Code: http.StatusCreated, BodyMatch: "hit 4$"},
+		}...)
+	})
+
+	t.Run("the response is stored per client", func(t *testing.T) {
+		otherClient := withKey("replay")
+		otherClient[header.XRealIP] = "10.0.0.2"
+
+		_, _ = ts.Run(t, test.TestCase{Method: http.MethodPost, Path: "/payments/", Headers: otherClient, Code: http.StatusCreated, BodyMatch: "hit 5$"})
+	})
+}
+
+func TestIdempotency_Concurrent(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream := newIdempotencyUpstream(0)
+	defer upstream.Close()
+
+	duplicate := test.TestCase{Method: http.MethodPost, Path: "/payments/", Headers: map[string]string{"Idempotency-Key": "race"}}
+
+	// inFlight sends the first request while the upstream is blocked, and returns a function
+	// unblocking the upstream and waiting for the first request to complete.
+	inFlight := func(t *testing.T) func() {
+		t.Helper()
+
+		upstream.gate.Lock()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+
+			first := duplicate
+			first.Code = http.StatusCreated
+			_, _ = ts.Run(t, first)
+		}()
+
+		<-upstream.started
+
+		return func() {
+			upstream.gate.Unlock()
+			<-done
+		}
+	}
+
+	t.Run("conflict", func(t *testing.T) {
+		ts.loadIdempotencyAPI(upstream, apidef.Idempotency{Enabled: true})
+
+		complete := inFlight(t)
+
+		conflict := duplicate
+		conflict.Code = http.StatusConflict
+		conflict.BodyMatch = errIdempotencyInFlight.Error()
+		_, _ = ts.Run(t, conflict)
+
+		complete()
+
+		replayed := duplicate
+		replayed.Code = http.StatusCreated
+		replayed.BodyMatch = "hit 1$"
+		_, _ = ts.Run(t, replayed)
+
+		assert.Equal(t, int64(1), upstream.hits.Load())
+	})
+
+	t.Run("wait", func(t *testing.T) {
+		duplicate.Headers = map[string]string{"Idempotency-Key": "race-wait"}
+		ts.loadIdempotencyAPI(upstream, apidef.Idempotency{Enabled: true, WaitForInFlight: true})
+
+		complete := inFlight(t)
+
+		waited := make(chan struct{})
+		go func() {
+			defer close(waited)
+
+			replayed := duplicate
+			replayed.Code = http.StatusCreated
+			replayed.BodyMatch = "hit 2$"
+			replayed.HeadersMatch = map[string]string{idempotentReplayedHeader: "true"}
+			_, _ = ts.Run(t, replayed)
+		}()
+
+		time.Sleep(3 * idempotencyPollInterval)
+		complete()
+		<-waited
+
+		assert.Equal(t, int64(2), upstream.hits.Load())
+	})
+
+	t.Run("wait timeout", func(t *testing.T) {
+		duplicate.Headers = map[string]string{"Idempotency-Key": "race-timeout"}
+		ts.loadIdempotencyAPI(upstream, apidef.Idempotency{Enabled: true, WaitForInFlight: true, WaitTimeout: 1})
+
+		complete := inFlight(t)
+		defer complete()
+
+		conflict := duplicate
+		conflict.Code = http.StatusConflict
+		_, _ = ts.Run(t, conflict)
+	})
+}
+
+func TestIdempotency_TTL(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream := newIdempotencyUpstream(0)
+	defer upstream.Close()
+
+	ts.loadIdempotencyAPI(upstream, apidef.Idempotency{Enabled: true, HeaderName: "X-Idempotency-Key", TTL: 1})
+
+	duplicate := test.TestCase{Method: http.MethodPost, Path: "/payments/", Headers: map[string]string{"X-Idempotency-Key": "ttl"}, Code: http.StatusCreated}
+
+	first, replayed := duplicate, duplicate
+	first.BodyMatch = "hit 1$"
+	replayed.BodyMatch = "hit 1$"
+	replayed.HeadersMatch = map[string]string{idempotentReplayedHeader: "true"}
+	_, _ = ts.Run(t, first, replayed)
+
+	time.Sleep(1500 * time.Millisecond)
+
+	expired := duplicate
+	expired.BodyMatch = "hit 2$"
+	expired.HeadersNotMatch = map[string]string{idempotentReplayedHeader: "true"}
+	_, _ = ts.Run(t, expired)
+}
+
+func TestIdempotency_MaxResponseSize(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream := newIdempotencyUpstream(100)
+	defer upstream.Close()
+
+	ts.loadIdempotencyAPI(upstream, apidef.Idempotency{Enabled: true, MaxResponseSize: 50})
+
+	duplicate := test.TestCase{Method: http.MethodPost, Path: "/payments/", Headers: map[string]string{"Idempotency-Key": "large"}, Code: http.StatusCreated}
+
+	first := duplicate
+	first.BodyMatch = "hit 1" + strings.Repeat(`\.`, 100)
+	first.HeadersMatch = map[string]string{idempotencyWarningHeader: "response too large to be stored"}
+
+	retried := duplicate
+	retried.BodyMatch = "hit 2"
+	_, _ = ts.Run(t, first, retried)
+}
@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -199,7 +200,10 @@ func (d *DynamicMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Reques
 		logger.Error("JSVM isn't initialized, check your gateway settings")
 		return errors.New("middleware error"), http.StatusInternalServerError
 	}
-	returnDataStr, err := runner.Run(expr)
+	returnDataStr, err := runner.RunContext(r.Context(), expr)
+	if err != nil && requestCancelled(r) {
+		return abortMiddleware(r, d.Name(), logger)
+	}
 	if err != nil {
 		logger.WithError(err).Error("Failed to run JS middleware")
 		return errors.New(http.StatusText(http.StatusInternalServerError)), http.StatusInternalServerError
@@ -376,11 +380,18 @@ func (j *JSVM) Ready() bool {
 // in a goroutine with timeout handling and panic recovery, and returns the
 // stringified result.
 func (j *JSVM) Run(expr string) (string, error) {
+	return j.RunContext(context.Background(), expr)
+}
+
+// RunContext implements JSRunner. The VM is interrupted when the timeout
+// fires or ctx is done, whichever comes first.
+func (j *JSVM) RunContext(ctx context.Context, expr string) (string, error) {
 	if j.VM == nil {
 		return "", errors.New("JSVM isn't enabled, check your gateway settings")
 	}
 	vm := j.VM.Copy()
 	vm.Interrupt = make(chan func(), 1)
+	j.loadTykHttpRequest(vm)
 	ret := make(chan otto.Value, 1)
 	errRet := make(chan error, 1)
 	go func() {
@@ -409,6 +420,10 @@ func (j *JSVM) Run(expr string) (string, error) {
 		t.Stop()
 		vm.Interrupt <- func() { panic("stop") }
 		return "", fmt.Errorf("JS middleware timed out after %v", j.Timeout)
+	case <-ctx.Done():
+		t.Stop()
+		vm.Interrupt <- func() { panic("stop") }
+		return "", fmt.Errorf("JS middleware cancelled: %w", ctx.Err())
 	}
 }
 
@@ -525,6 +540,21 @@ func (j *JSVM) LoadTykJSApi() {
 	}`)
 }
 
+// loadTykHttpRequest binds TykHttpRequest to the VM copy of a run, so each
+// run gets its own request budget.
+func (j *JSVM) loadTykHttpRequest(vm *otto.Otto) {
+	h := &JSVMAPIHelper{Spec: j.Spec, Gw: j.Gw, Log: j.Log, RawLog: j.RawLog}
+
+	vm.Set("TykHttpRequest", func(call otto.FunctionCall) otto.Value {
+		val, err := vm.ToValue(h.ControlledHTTPRequest(call.Argument(0).String()))
+		if err != nil {
+			h.Log.WithError(err).Error("Failed to convert value for JS")
+			return otto.Value{}
+		}
+		return val
+	})
+}
+
 const coreJS = `
 var TykJS = {
 	TykMiddleware: {
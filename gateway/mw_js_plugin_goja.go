@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"context"
 	"crypto/sha256"
 	"errors"
 	"fmt"
@@ -116,6 +117,12 @@ func (j *GojaJSVM) newRuntime() *goja.Runtime {
 // Run executes a JS expression on a fresh runtime with timeout handling.
 // Each call gets an isolated runtime so concurrent requests don't interfere.
 func (j *GojaJSVM) Run(expr string) (string, error) {
+	return j.RunContext(context.Background(), expr)
+}
+
+// RunContext executes a JS expression like Run, the runtime is interrupted
+// when the timeout fires or ctx is done, whichever comes first.
+func (j *GojaJSVM) RunContext(ctx context.Context, expr string) (string, error) {
 	if !j.initialized {
 		return "", errors.New("JSVM isn't enabled, check your gateway settings")
 	}
@@ -127,10 +134,18 @@ func (j *GojaJSVM) Run(expr string) (string, error) {
 	})
 	defer timer.Stop()
 
+	stop := context.AfterFunc(ctx, func() {
+		vm.Interrupt("cancelled")
+	})
+	defer stop()
+
 	returnRaw, err := vm.RunString(expr)
 	if err != nil {
 		var interrupted *goja.InterruptedError
 		if errors.As(err, &interrupted) {
+			if interrupted.Value() == "cancelled" {
+				return "", fmt.Errorf("JS middleware cancelled: %w", ctx.Err())
+			}
 			return "", fmt.Errorf("JS middleware timed out after %v", j.Timeout)
 		}
 		return "", err
@@ -395,6 +410,11 @@ func (j *GojaJSVM) registerAPI(vm *goja.Runtime) {
 		}
 		return vm.ToValue(result)
 	})
+	// The runtime, and so the helper, is created per run, which scopes the
+	// request budget of TykHttpRequest to the run.
+	set("TykHttpRequest", func(call goja.FunctionCall) goja.Value {
+		return vm.ToValue(h.ControlledHTTPRequest(call.Argument(0).String()))
+	})
 	set("TykGetKeyData", func(call goja.FunctionCall) goja.Value {
 		return vm.ToValue(h.GetKeyData(call.Argument(0).String(), call.Argument(1).String()))
 	})
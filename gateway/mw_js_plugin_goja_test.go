@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gocraft/health"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -228,6 +230,94 @@ timeoutMid.NewProcessRequest(function(request, session) {
 	}
 }
 
+// abortedEventSink records the middleware runs aborted by cancellation.
+type abortedEventSink struct {
+	aborted atomic.Int32
+}
+
+func (s *abortedEventSink) EmitEvent(job string, event string, _ map[string]string) {
+	if job == "MiddlewareCall" && event == "aborted" {
+		s.aborted.Add(1)
+	}
+}
+
+func (s *abortedEventSink) EmitEventErr(string, string, error, map[string]string)                  {}
+func (s *abortedEventSink) EmitTiming(string, string, int64, map[string]string)                    {}
+func (s *abortedEventSink) EmitComplete(string, health.CompletionStatus, int64, map[string]string) {}
+func (s *abortedEventSink) EmitGauge(string, string, float64, map[string]string)                   {}
+
+// recordAbortedMiddleware enables the instrumentation for the test and returns the sink counting the aborted runs.
+func recordAbortedMiddleware(t *testing.T) *abortedEventSink {
+	t.Helper()
+
+	sink := &abortedEventSink{}
+	sinks, enabled := instrument.Sinks, instrumentationEnabled
+	instrument.AddSink(sink)
+	instrumentationEnabled = true
+
+	t.Cleanup(func() {
+		instrument.Sinks, instrumentationEnabled = sinks, enabled
+	})
+
+	return sink
+}
+
+func TestGoja_Cancellation(t *testing.T) {
+	const js = `
+var slowMid = new TykJS.TykMiddleware.NewMiddleware({});
+
+slowMid.NewProcessRequest(function(request, session) {
+	while (true) {}
+	return slowMid.ReturnData(request, {});
+});`
+
+	for _, driver := range drivers {
+		t.Run(string(driver), func(t *testing.T) {
+			ts := StartTest(nil)
+			defer ts.Close()
+
+			aborted := recordAbortedMiddleware(t)
+
+			spec := &APISpec{APIDefinition: &apidef.APIDefinition{}}
+			dynMid := &DynamicMiddleware{
+				BaseMiddleware:      &BaseMiddleware{Spec: spec, Gw: ts.Gw},
+				MiddlewareClassName: "slowMid",
+				Pre:                 true,
+			}
+
+			initJSVM(t, spec, ts.Gw, driver, js)
+
+			// The timeout doesn't fire during the test, the cancellation of the request interrupts the VM.
+			if driver == apidef.JavaScriptDriver {
+				spec.GojaJSVM.Timeout = time.Minute
+			} else {
+				spec.JSVM.Timeout = time.Minute
+			}
+
+			reqCtx, cancel := context.WithCancel(context.Background())
+			req := httptest.NewRequest("GET", "/foo", strings.NewReader("body")).WithContext(reqCtx)
+
+			done := make(chan bool, 1)
+			go func() {
+				err, code := dynMid.ProcessRequest(nil, req, nil)
+				assert.ErrorIs(t, err, ErrMiddlewareCancelled)
+				assert.Equal(t, StatusClientClosedRequest, code)
+				done <- true
+			}()
+
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+
+			select {
+			case <-done:
+				assert.Equal(t, int32(1), aborted.aborted.Load())
+			case <-time.After(3 * time.Second):
+				t.Fatal("JS middleware wasn't interrupted after the request was cancelled")
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // 5. Config data access from JS
 // ---------------------------------------------------------------------------
@@ -75,7 +75,7 @@ func (h *JSResponseMiddleware) HandleError(rw http.ResponseWriter, req *http.Req
 }
 
 func (h *JSResponseMiddleware) HandleResponse(_ http.ResponseWriter, res *http.Response, req *http.Request, ses *user.SessionState) error {
-	logger := h.logger().WithFields(logrus.Fields{
+	logger := h.requestLogger(req).WithFields(logrus.Fields{
 		"prefix": "jsvm-response",
 	})
 	logger.Debugf("Response hook '%s' is called", h.hookName)
@@ -164,7 +164,7 @@ func (h *JSResponseMiddleware) HandleResponse(_ http.ResponseWriter, res *http.R
 		logger.Error("JSVM isn't initialized")
 		return errors.New("middleware error")
 	}
-	returnDataStr, runErr := runner.Run(expr)
+	returnDataStr, runErr := runner.RunContext(req.Context(), expr)
 	if runErr != nil {
 		logger.WithError(runErr).Error("Failed to run JS response middleware")
 		return errors.New("middleware error")
@@ -11,6 +11,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -54,6 +56,20 @@ const (
 const UnexpectedSigningMethod = "Unexpected signing method"
 const JWKsAPIDef = "jwks_api_def_"
 
+const (
+	// jwksLastKnownGoodTimeout is how long, in seconds, a last known good JWKS is
+	// cached for after a failed fetch before the fetch is retried.
+	jwksLastKnownGoodTimeout = 30
+	// jwksLastKnownGoodRetention is how long, in seconds, a last known good JWKS
+	// is kept after its cache timeout. A JWKS not fetched nor used as a fallback
+	// for that long, e.g. the JWKS of a deleted API, is dropped.
+	jwksLastKnownGoodRetention = 3600
+	// jwksKidMissRefetchCooldown is the minimum time, in seconds, between two
+	// refetches of a JWKS triggered by tokens with an unknown kid.
+	jwksKidMissRefetchCooldown = 10
+	jwksRefetchCooldownPrefix  = "kid-miss-refetch-"
+)
+
 var (
 	// List of common OAuth Client ID claims used by IDPs:
 	oauthClientIDClaims = []string{
@@ -82,28 +98,13 @@ func (k *JWTMiddleware) Init() {
 			k.Gw.deleteJWKCacheByAPIID(k.Spec.APIID)
 			jwkCache := k.Gw.loadOrCreateJWKCacheByApiID(k.Spec.APIID)
 
-			// Create client factory for JWK fetching
-			clientFactory := NewExternalHTTPClientFactory(k.Gw)
-			client, clientErr := clientFactory.CreateJWKClient()
-
 			for _, jwk := range config.JWTJwksURIs {
-				var jwkSet *jose.JSONWebKeySet
-				var err error
-
-				if clientErr == nil {
-					jwkSet, err = getJWKWithClient(jwk.URL, client)
-				}
-
-				// Fallback to original method if factory fails
-				if clientErr != nil || err != nil {
-					jwkSet, err = GetJWK(jwk.URL, k.Gw.GetConfig().JWTSSLInsecureSkipVerify)
-				}
-
+				jwkSet, timeout, err := k.fetchJWKSet(jwk.URL)
 				if err != nil {
 					continue
 				}
 
-				jwkCache.Set(jwk.URL, jwkSet, jwk.GetCacheTimeoutSeconds(cache.DefaultExpiration))
+				jwkCache.Set(jwk.URL, jwkSet, timeout)
 			}
 		}()
 	}
@@ -216,9 +217,10 @@ func (k *JWTMiddleware) getSecretFromURL(url string, kidVal interface{}, keyType
 	}
 
 	var (
-		jwkSet *jose.JSONWebKeySet
-		err    error
-		found  bool
+		jwkSet  *jose.JSONWebKeySet
+		err     error
+		found   bool
+		timeout int64
 	)
 
 	jwkCache := k.loadOrCreateJWKCache()
@@ -257,41 +259,35 @@ func (k *JWTMiddleware) getSecretFromURL(url string, kidVal interface{}, keyType
 	}
 
 	if !found || cacheOutdated {
-		// Try to use HTTP client factory first
-		clientFactory := NewExternalHTTPClientFactory(k.Gw)
-		client, clientErr := clientFactory.CreateJWKClient()
-		if clientErr == nil {
-			if jwkSet, err = getJWKWithClient(url, client); err != nil {
-				k.Gw.logJWKError(k.Logger(), url, err)
-				k.Logger().WithError(err).Info("Failed to decode JWKs body with factory client. Trying x5c PEM fallback.")
-			}
-		}
+		if jwkSet, timeout, err = k.fetchJWKSet(url); err != nil {
+			k.Logger().Info("Failed to decode JWKs body. Trying x5c PEM fallback.")
 
-		// Fallback to original method if factory fails or JWK fetch fails
-		if clientErr != nil || err != nil {
-			if jwkSet, err = GetJWK(url, k.Gw.GetConfig().JWTSSLInsecureSkipVerify); err != nil {
-				k.Gw.logJWKError(k.Logger(), url, err)
-				k.Logger().Info("Failed to decode JWKs body. Trying x5c PEM fallback.")
-
-				key, legacyError := k.legacyGetSecretFromURL(url, kid, keyType)
-				if legacyError == nil {
-					return key, nil
-				}
-
-				return nil, err
+			key, legacyError := k.legacyGetSecretFromURL(url, kid, keyType)
+			if legacyError == nil {
+				return key, nil
 			}
+
+			return nil, err
 		}
 
 		// Cache it
 		k.Logger().Debug("Caching JWK")
-		jwkCache.Set(url, jwkSet, k.findCacheTimeoutByURL(url))
+		jwkCache.Set(url, jwkSet, timeout)
 		jwkCache.Set(cacheAPIDef, k.Spec.APIDefinition, cache.DefaultExpiration)
 	}
 
 	k.Logger().Debug("Checking JWKs...")
-	if keys := jwkSet.Key(kid); len(keys) > 0 {
-		return keys[0].Key, nil
+	if key := keyFromJWKSet(jwkSet, kid); key != nil {
+		return key, nil
 	}
+
+	// The cached set may predate a key rotation
+	if found && !cacheOutdated {
+		if key := keyFromJWKSet(k.refetchJWKSetOnKidMiss(url, jwkCache), kid); key != nil {
+			return key, nil
+		}
+	}
+
 	return nil, errors.New("No matching KID could be found")
 }
 
@@ -451,9 +447,14 @@ func (k *JWTMiddleware) fetchJWKSKey(url, kid string) interface{} {
 	}
 	jwkCache := k.loadOrCreateJWKCache() // per-API cache; URL-keyed inside
 
-	if key := keyFromJWKSet(cachedJWKSet(jwkCache, url), kid); key != nil {
+	cached := cachedJWKSet(jwkCache, url)
+	if key := keyFromJWKSet(cached, kid); key != nil {
 		return key
 	}
+	if cached != nil {
+		// The cached set may predate a key rotation
+		return keyFromJWKSet(k.refetchJWKSetOnKidMiss(url, jwkCache), kid)
+	}
 
 	// Coalesce concurrent fetches for the same (API, URL) so a burst of requests
 	// on a cold/expired cache triggers a single network fetch instead of a
@@ -514,22 +515,161 @@ func (k *JWTMiddleware) fetchAndCacheJWKS(url string, jwkCache cache.Repository)
 		return set, nil
 	}
 
+	jwkSet, timeout, err := k.fetchJWKSet(url)
+	if err != nil {
+		return nil, err
+	}
+	jwkCache.Set(url, jwkSet, timeout)
+	return jwkSet, nil
+}
+
+// fetchJWKSet fetches the JWKS at url (factory client, then plain-client
+// fallback) and returns it with the timeout to cache it for. When the fetch
+// fails, the last key set successfully fetched from url is returned instead so
+// an IdP outage doesn't reject every token.
+func (k *JWTMiddleware) fetchJWKSet(url string) (*jose.JSONWebKeySet, int64, error) {
 	var (
 		jwkSet *jose.JSONWebKeySet
+		header http.Header
 		err    error
 	)
 	client, clientErr := NewExternalHTTPClientFactory(k.Gw).CreateJWKClient()
 	if clientErr == nil {
-		jwkSet, err = getJWKWithClient(url, client)
+		jwkSet, header, err = getJWKWithClientAndHeaders(url, client)
 	}
 	if clientErr != nil || err != nil {
 		jwkSet, err = GetJWK(url, k.Gw.GetConfig().JWTSSLInsecureSkipVerify)
 	}
 	if err != nil {
-		return nil, err
+		k.Gw.logJWKError(k.Logger(), url, err)
+
+		if lastGood := k.Gw.loadLastKnownGoodJWKS(url); lastGood != nil {
+			k.Logger().Warnf("Using last known good JWKS for %s", url)
+			k.Gw.storeLastKnownGoodJWKS(url, lastGood, jwksLastKnownGoodTimeout)
+			return lastGood, jwksLastKnownGoodTimeout, nil
+		}
+		return nil, 0, err
 	}
-	jwkCache.Set(url, jwkSet, cache.DefaultExpiration)
-	return jwkSet, nil
+
+	timeout := k.jwksCacheTimeout(url, header)
+	if timeout == cache.DefaultExpiration {
+		k.Gw.storeLastKnownGoodJWKS(url, jwkSet, jwksCacheExpiration(k.Gw.GetConfig()))
+	} else {
+		k.Gw.storeLastKnownGoodJWKS(url, jwkSet, timeout)
+	}
+	return jwkSet, timeout, nil
+}
+
+// jwksLastKnownGoodEntry is a JWKS successfully fetched, used as a fallback
+// when fetching it again fails until it expires.
+type jwksLastKnownGoodEntry struct {
+	set     *jose.JSONWebKeySet
+	expires time.Time
+}
+
+// storeLastKnownGoodJWKS keeps set as the last known good JWKS of url for the
+// timeout it's cached for and jwksLastKnownGoodRetention. The expired entries
+// are dropped on the way.
+func (gw *Gateway) storeLastKnownGoodJWKS(url string, set *jose.JSONWebKeySet, timeout int64) {
+	now := time.Now()
+	gw.jwksLastKnownGood.Range(func(key, value any) bool {
+		if entry, ok := value.(jwksLastKnownGoodEntry); !ok || now.After(entry.expires) {
+			gw.jwksLastKnownGood.Delete(key)
+		}
+		return true
+	})
+
+	gw.jwksLastKnownGood.Store(url, jwksLastKnownGoodEntry{
+		set:     set,
+		expires: now.Add(time.Duration(timeout+jwksLastKnownGoodRetention) * time.Second),
+	})
+}
+
+// loadLastKnownGoodJWKS returns the last known good JWKS of url, or nil if
+// there's none or it has expired.
+func (gw *Gateway) loadLastKnownGoodJWKS(url string) *jose.JSONWebKeySet {
+	value, ok := gw.jwksLastKnownGood.Load(url)
+	if !ok {
+		return nil
+	}
+
+	entry, ok := value.(jwksLastKnownGoodEntry)
+	if !ok || time.Now().After(entry.expires) {
+		gw.jwksLastKnownGood.CompareAndDelete(url, value)
+		return nil
+	}
+
+	return entry.set
+}
+
+// jwksCacheTimeout returns how long the JWKS fetched from url is cached. A
+// timeout configured on the JWKS URI or in the gateway config wins over the
+// lifetime advertised by the JWKS endpoint.
+func (k *JWTMiddleware) jwksCacheTimeout(url string, header http.Header) int64 {
+	if timeout := k.findCacheTimeoutByURL(url); timeout != cache.DefaultExpiration {
+		return timeout
+	}
+	if k.Gw.GetConfig().JWKS.Cache.Timeout > 0 {
+		return cache.DefaultExpiration
+	}
+	if timeout := jwksMaxAge(header, time.Now()); timeout > 0 {
+		return timeout
+	}
+	return cache.DefaultExpiration
+}
+
+// jwksMaxAge returns the lifetime in seconds advertised by the Cache-Control
+// max-age or Expires response headers, or 0 if there is none. no-store and
+// no-cache also return 0: a key set is needed for every request, so those
+// fall back to the default timeout.
+func jwksMaxAge(header http.Header, now time.Time) int64 {
+	maxAge := int64(-1)
+	for _, directive := range strings.Split(strings.Join(header.Values("Cache-Control"), ","), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store", directive == "no-cache":
+			return 0
+		case strings.HasPrefix(directive, "max-age="):
+			if v, err := strconv.ParseInt(strings.TrimPrefix(directive, "max-age="), 10, 64); err == nil {
+				maxAge = v
+			}
+		}
+	}
+	if maxAge >= 0 {
+		return maxAge
+	}
+
+	if expires, err := http.ParseTime(header.Get("Expires")); err == nil && expires.After(now) {
+		return int64(expires.Sub(now).Seconds())
+	}
+	return 0
+}
+
+// refetchJWKSetOnKidMiss refetches the JWKS at url after a token carried a kid
+// missing from the cached set, as happens right after the IdP rotates its keys.
+// Refetches are coalesced and limited to one per jwksKidMissRefetchCooldown per
+// URL, so tokens with unknown kids can't flood the IdP. While cooling down the
+// cached set is returned, which may already hold keys refetched by another request.
+func (k *JWTMiddleware) refetchJWKSetOnKidMiss(url string, jwkCache cache.Repository) *jose.JSONWebKeySet {
+	cooldownKey := jwksRefetchCooldownPrefix + url
+
+	res, _, _ := jwksFetchGroup.Do(k.Spec.APIID+"|refetch|"+url, func() (interface{}, error) {
+		if _, coolingDown := jwkCache.Get(cooldownKey); coolingDown {
+			return cachedJWKSet(jwkCache, url), nil
+		}
+		jwkCache.Set(cooldownKey, true, jwksKidMissRefetchCooldown)
+
+		k.Logger().Debugf("Unknown kid, refetching JWKS from %s", url)
+		jwkSet, timeout, err := k.fetchJWKSet(url)
+		if err != nil {
+			return nil, err
+		}
+		jwkCache.Set(url, jwkSet, timeout)
+		return jwkSet, nil
+	})
+
+	set, _ := res.(*jose.JSONWebKeySet)
+	return set
 }
 
 var GetJWK = getJWK
@@ -590,36 +730,21 @@ func (k *JWTMiddleware) getSecretFromMultipleJWKURIs(jwkURIs []apidef.JWK, kidVa
 		}
 	}
 
+	fromCache := len(jwkSets) > 0
 	if !foundDef || cacheOutdated || len(jwkSets) == 0 {
+		fromCache = false
 		jwkSets = nil
 		jwkCache := k.loadOrCreateJWKCache()
 		jwkCache.Flush()
 
-		// Create client factory for JWK fetching
-		clientFactory := NewExternalHTTPClientFactory(k.Gw)
-		client, clientErr := clientFactory.CreateJWKClient()
-
 		for _, jwk := range jwkURIs {
-			var jwkSet *jose.JSONWebKeySet
-			var err error
-
-			// Try with factory client first
-			if clientErr == nil {
-				jwkSet, err = getJWKWithClient(jwk.URL, client)
-			}
-
-			// Fallback to original method if factory fails
-			if clientErr != nil || err != nil {
-				jwkSet, err = GetJWK(jwk.URL, k.Gw.GetConfig().JWTSSLInsecureSkipVerify)
-			}
-
+			jwkSet, timeout, err := k.fetchJWKSet(jwk.URL)
 			if err != nil {
-				k.Gw.logJWKError(k.Logger(), jwk.URL, err)
 				fallbackJWKURIs = append(fallbackJWKURIs, jwk)
 				continue
 			}
 
-			jwkCache.Set(jwk.URL, jwkSet, jwk.GetCacheTimeoutSeconds(cache.DefaultExpiration))
+			jwkCache.Set(jwk.URL, jwkSet, timeout)
 			jwkSets = append(jwkSets, jwkSet)
 		}
 
@@ -630,8 +755,18 @@ func (k *JWTMiddleware) getSecretFromMultipleJWKURIs(jwkURIs []apidef.JWK, kidVa
 	}
 
 	for _, jwkSet := range jwkSets {
-		if keys := jwkSet.Key(kid); len(keys) > 0 {
-			return keys[0].Key, nil
+		if key := keyFromJWKSet(jwkSet, kid); key != nil {
+			return key, nil
+		}
+	}
+
+	// The cached sets may predate a key rotation
+	if fromCache {
+		jwkCache := k.loadOrCreateJWKCache()
+		for _, jwk := range jwkURIs {
+			if key := keyFromJWKSet(k.refetchJWKSetOnKidMiss(jwk.URL, jwkCache), kid); key != nil {
+				return key, nil
+			}
 		}
 	}
 
@@ -1186,6 +1321,7 @@ func (k *JWTMiddleware) processCentralisedJWT(r *http.Request, token *jwt.Token)
 		ctxSetSpanAttributes(r, k.Name(), otel.APIKeyAliasAttribute(session.Alias))
 	}
 	ctxSetJWTContextVars(k.Spec, r, token)
+	k.setTokenScopes(r, claims)
 
 	return nil, http.StatusOK
 }
@@ -1209,6 +1345,21 @@ func (k *JWTMiddleware) scopeClaimNameForRequest(claims jwt.MapClaims, binding *
 	return name
 }
 
+// setTokenScopes stores the scopes of the token for the scope access checks, when the API enables them.
+func (k *JWTMiddleware) setTokenScopes(r *http.Request, claims jwt.MapClaims) {
+	if !k.Spec.ScopeAccess.Enabled {
+		return
+	}
+
+	claimName := k.Spec.ScopeAccess.ClaimName
+	if claimName == "" {
+		claimName = k.scopeClaimNameForRequest(claims, ctxGetMatchedBinding(r))
+	}
+
+	scopes := getScopeFromClaim(claims, claimName)
+	ctxSetTokenScopes(r, slices.DeleteFunc(scopes, func(scope string) bool { return scope == "" }))
+}
+
 func (k *JWTMiddleware) getScopeClaimNameOAS(claims jwt.MapClaims) string {
 	// A registry-resolved OAS API has no JWT configuration in its OAS def, so
 	// GetJWTConfiguration() (and its Scopes) can be nil — the scope claim name
@@ -1261,6 +1412,7 @@ func (k *JWTMiddleware) processOneToOneTokenMap(r *http.Request, token *jwt.Toke
 	ctxSetSession(r, &session, false, k.Gw.GetConfig().HashKeys)
 	ctxSetSpanAttributes(r, k.Name(), otel.APIKeyAliasAttribute(session.Alias))
 	ctxSetJWTContextVars(k.Spec, r, token)
+	k.setTokenScopes(r, token.Claims.(jwt.MapClaims))
 	return nil, http.StatusOK
 }
 
@@ -1669,6 +1821,8 @@ func (gw *Gateway) generateSessionFromPolicy(policyID, orgID string, enforceOrg
 	session.ThrottleInterval = policy.ThrottleInterval
 	session.ThrottleRetryLimit = policy.ThrottleRetryLimit
 	session.MaxQueryDepth = policy.MaxQueryDepth
+	session.MaxQueryNodes = policy.MaxQueryNodes
+	session.MaxAliases = policy.MaxAliases
 	session.QuotaMax = policy.QuotaMax
 	session.QuotaRenewalRate = policy.QuotaRenewalRate
 	session.AccessRights = make(map[string]user.AccessDefinition)
@@ -1686,6 +1840,7 @@ func (gw *Gateway) generateSessionFromPolicy(policyID, orgID string, enforceOrg
 
 	session.PostExpiryAction = policy.PostExpiryAction
 	session.PostExpiryGracePeriod = policy.PostExpiryGracePeriod
+	session.IdleTimeout = policy.IdleTimeout
 
 	return session.Clone(), nil
 }
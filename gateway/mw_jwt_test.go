@@ -2,6 +2,8 @@ package gateway
 
 import (
 	"crypto/md5"
+	"crypto/rand"
+	"crypto/rsa"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -11,6 +13,7 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -3981,6 +3984,8 @@ func TestGetSecretFromMultipleJWKURIs(t *testing.T) {
 			m.logger = logger.WithField("mw", "JWTMiddleware")
 
 			m.loadOrCreateJWKCache().Flush()
+			// Each case starts without a previously fetched key set to fall back to
+			gw.jwksLastKnownGood.Clear()
 
 			if tt.setup != nil {
 				tt.setup(tt.isOas)
@@ -5445,3 +5450,184 @@ func Test_mapScopeToPolicies_TT5893(t *testing.T) {
 		})
 	})
 }
+
+func TestJWTMiddleware_JWKSKeyRotation(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	publicJWK := func(kid string, key *rsa.PrivateKey) jose.JSONWebKey {
+		return jose.JSONWebKey{KeyID: kid, Key: &key.PublicKey, Algorithm: "RS256", Use: "sig"}
+	}
+
+	var (
+		keys    atomic.Value
+		failing atomic.Bool
+		hits    atomic.Int32
+	)
+	keys.Store([]jose.JSONWebKey{publicJWK("old", oldKey)})
+
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: keys.Load().([]jose.JSONWebKey)})
+	}))
+	defer jwks.Close()
+
+	const apiID = "jwks-rotation"
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = apiID
+		spec.UseKeylessAccess = false
+		spec.EnableJWT = true
+		spec.JWTSigningMethod = RSASign
+		spec.JWTSource = base64.StdEncoding.EncodeToString([]byte(jwks.URL))
+		spec.JWTIdentityBaseField = "user_id"
+		spec.JWTPolicyFieldName = "policy_id"
+		spec.Proxy.ListenPath = "/"
+	})
+
+	pID := ts.CreatePolicy(func(p *user.Policy) {
+		p.AccessRights = map[string]user.AccessDefinition{
+			apiID: {APIName: "jwks-rotation"},
+		}
+	})
+
+	sign := func(kid string, key *rsa.PrivateKey) map[string]string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"user_id":   "user",
+			"policy_id": pID,
+			"exp":       time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return map[string]string{"Authorization": signed}
+	}
+
+	oldToken := sign("old", oldKey)
+	newToken := sign("new", newKey)
+
+	_, _ = ts.Run(t, test.TestCase{Headers: oldToken, Code: http.StatusOK})
+	assert.Equal(t, int32(1), hits.Load())
+
+	// The IdP starts signing with the new key, both keys are published during the overlap.
+	keys.Store([]jose.JSONWebKey{publicJWK("old", oldKey), publicJWK("new", newKey)})
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Headers: newToken, Code: http.StatusOK},
+		{Headers: oldToken, Code: http.StatusOK},
+		{Headers: newToken, Code: http.StatusOK},
+	}...)
+	assert.Equal(t, int32(2), hits.Load(), "the unknown kid should trigger a single refetch")
+
+	t.Run("unknown kids don't flood the JWKS endpoint", func(t *testing.T) {
+		unknown := sign("unknown", newKey)
+		_, _ = ts.Run(t, []test.TestCase{
+			{Headers: unknown, Code: http.StatusForbidden},
+			{Headers: unknown, Code: http.StatusForbidden},
+		}...)
+		assert.Equal(t, int32(2), hits.Load())
+	})
+
+	t.Run("last known good key set is used when the JWKS endpoint fails", func(t *testing.T) {
+		failing.Store(true)
+		ts.Gw.invalidateJWKSCacheByAPIID(apiID)
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Headers: oldToken, Code: http.StatusOK},
+			{Headers: newToken, Code: http.StatusOK},
+		}...)
+		assert.Greater(t, hits.Load(), int32(2))
+	})
+}
+
+func TestGateway_lastKnownGoodJWKS(t *testing.T) {
+	gw := &Gateway{}
+	set := &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "kid"}}}
+
+	gw.storeLastKnownGoodJWKS("https://idp.example.com/jwks", set, 60)
+	assert.Same(t, set, gw.loadLastKnownGoodJWKS("https://idp.example.com/jwks"))
+	assert.Nil(t, gw.loadLastKnownGoodJWKS("https://other.example.com/jwks"))
+
+	// the JWKS of a deleted API isn't fetched nor used anymore and expires
+	gw.storeLastKnownGoodJWKS("https://deleted.example.com/jwks", set, -jwksLastKnownGoodRetention-1)
+	assert.Nil(t, gw.loadLastKnownGoodJWKS("https://deleted.example.com/jwks"))
+
+	gw.storeLastKnownGoodJWKS("https://deleted.example.com/jwks", set, -jwksLastKnownGoodRetention-1)
+	gw.storeLastKnownGoodJWKS("https://idp.example.com/jwks", set, 60)
+	_, found := gw.jwksLastKnownGood.Load("https://deleted.example.com/jwks")
+	assert.False(t, found, "expired entries should be dropped when a JWKS is stored")
+}
+
+func TestJWKSMaxAge(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   int64
+	}{
+		{"no headers", http.Header{}, 0},
+		{"max-age", http.Header{"Cache-Control": {"public, max-age=3600"}}, 3600},
+		{"max-age wins over expires", http.Header{
+			"Cache-Control": {"max-age=60"},
+			"Expires":       {now.Add(time.Hour).UTC().Format(http.TimeFormat)},
+		}, 60},
+		{"no-store", http.Header{"Cache-Control": {"no-store, max-age=60"}}, 0},
+		{"no-cache", http.Header{"Cache-Control": {"no-cache"}}, 0},
+		{"expires", http.Header{"Expires": {now.Add(2 * time.Minute).UTC().Format(http.TimeFormat)}}, 119},
+		{"expired", http.Header{"Expires": {now.Add(-time.Minute).UTC().Format(http.TimeFormat)}}, 0},
+		{"invalid max-age", http.Header{"Cache-Control": {"max-age=abc"}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := jwksMaxAge(tt.header, now)
+			if tt.name == "expires" {
+				assert.InDelta(t, tt.want, got, 1)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestJWTMiddleware_jwksCacheTimeout(t *testing.T) {
+	const url = "https://idp.example.com/jwks"
+	maxAge := http.Header{"Cache-Control": {"max-age=600"}}
+
+	newMiddleware := func(conf config.Config, uris ...apidef.JWK) *JWTMiddleware {
+		gw := &Gateway{}
+		gw.SetConfig(conf)
+		return &JWTMiddleware{BaseMiddleware: &BaseMiddleware{
+			Gw:   gw,
+			Spec: &APISpec{APIDefinition: &apidef.APIDefinition{JWTJwksURIs: uris}},
+		}}
+	}
+
+	t.Run("response headers", func(t *testing.T) {
+		assert.Equal(t, int64(600), newMiddleware(config.Config{}).jwksCacheTimeout(url, maxAge))
+	})
+
+	t.Run("no cache headers", func(t *testing.T) {
+		assert.Equal(t, int64(cache.DefaultExpiration), newMiddleware(config.Config{}).jwksCacheTimeout(url, http.Header{}))
+	})
+
+	t.Run("gateway timeout wins over response headers", func(t *testing.T) {
+		conf := config.Config{}
+		conf.JWKS.Cache.Timeout = 30
+		assert.Equal(t, int64(cache.DefaultExpiration), newMiddleware(conf).jwksCacheTimeout(url, maxAge))
+	})
+
+	t.Run("JWKS URI timeout wins over response headers", func(t *testing.T) {
+		m := newMiddleware(config.Config{}, apidef.JWK{URL: url, CacheTimeout: tyktime.ReadableDuration(time.Minute)})
+		assert.Equal(t, int64(60), m.jwksCacheTimeout(url, maxAge))
+	})
+}
@@ -3,10 +3,20 @@ package gateway
 import (
 	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/TykTechnologies/tyk/request"
+	"github.com/TykTechnologies/tyk/storage"
+	"github.com/TykTechnologies/tyk/user"
 )
 
+// defaultSessionLastSeenInterval is the default number of seconds between two updates of the last seen time of a key.
+const defaultSessionLastSeenInterval = 60
+
+// lastSeenKeyPrefix prefixes the keys holding the last seen time of the keys used on an MDCB data plane.
+const lastSeenKeyPrefix = "key-last-seen-"
+
 // KeyExpired middleware will check if the requesting key is expired or not. It makes use of the authManager to do so.
 type KeyExpired struct {
 	*BaseMiddleware
@@ -46,7 +56,7 @@ func (k *KeyExpired) ProcessRequest(w http.ResponseWriter, r *http.Request, _ in
 	}
 
 	if !k.Spec.AuthManager.KeyExpired(session) {
-		return nil, http.StatusOK
+		return k.checkIdleTimeout(r, session, token)
 	}
 	logger.Info("Attempted access from expired key.")
 
@@ -61,3 +71,93 @@ func (k *KeyExpired) ProcessRequest(w http.ResponseWriter, r *http.Request, _ in
 
 	return errors.New("Key has expired, please renew"), http.StatusUnauthorized
 }
+
+// checkIdleTimeout rejects and removes the keys which weren't used for longer than their idle timeout,
+// and updates the last seen time of the others, at most every session_last_seen_interval seconds.
+// In MDCB slave mode the copy of the key pulled from the control plane carries a stale last seen time,
+// so the data plane records it in its own Redis, see lastSeenStore, and the idle keys are only rejected.
+func (k *KeyExpired) checkIdleTimeout(r *http.Request, session *user.SessionState, token string) (error, int) {
+	if session.IdleTimeout <= 0 {
+		return nil, http.StatusOK
+	}
+
+	useRPC := k.Gw.GetConfig().SlaveOptions.UseRPC
+	if useRPC {
+		k.loadLastSeen(session, token)
+	}
+
+	now := time.Now()
+	if !session.IdleExpired(now) {
+		if now.Unix()-session.LastSeen >= k.lastSeenInterval(session) {
+			session.LastSeen = now.Unix()
+			if useRPC {
+				k.saveLastSeen(session, token)
+			} else {
+				session.Touch()
+			}
+		}
+
+		return nil, http.StatusOK
+	}
+
+	k.Logger().Info("Attempted access from idle key.")
+
+	k.FireEvent(EventKeyExpired, EventKeyFailureMeta{
+		EventMetaDefault: EventMetaDefault{Message: "Attempted access from idle key.", OriginatingRequest: EncodeRequestToEvent(r)},
+		Path:             r.URL.Path,
+		Origin:           request.RealIP(r),
+		Key:              token,
+	})
+	// Report in health check
+	reportHealthValue(k.Spec, KeyFailure, "-1")
+
+	// In MDCB slave mode the keys are owned by the control plane, removing the local copy would
+	// only have it pulled again.
+	if !useRPC {
+		k.Gw.GlobalSessionManager.RemoveSession(session.OrgID, token, false)
+	}
+
+	return errors.New("Key has expired due to inactivity, please renew"), http.StatusUnauthorized
+}
+
+// lastSeenStore returns the local Redis store of the last seen times of the keys used on an MDCB data plane.
+func (k *KeyExpired) lastSeenStore() *storage.RedisCluster {
+	return &storage.RedisCluster{KeyPrefix: lastSeenKeyPrefix, ConnectionHandler: k.Gw.StorageConnectionHandler}
+}
+
+// loadLastSeen sets the last seen time of the session to the one recorded by the data plane when it's later.
+// The record expires some time after the idle timeout, the key is idle from the time of the session then.
+func (k *KeyExpired) loadLastSeen(session *user.SessionState, token string) {
+	value, err := k.lastSeenStore().GetKey(k.Gw.keyHash(token))
+	if err != nil {
+		return
+	}
+
+	lastSeen, err := strconv.ParseInt(value, 10, 64)
+	if err == nil && lastSeen > session.LastSeen {
+		session.LastSeen = lastSeen
+	}
+}
+
+// saveLastSeen records the last seen time of the session on the data plane.
+func (k *KeyExpired) saveLastSeen(session *user.SessionState, token string) {
+	value := strconv.FormatInt(session.LastSeen, 10)
+	if err := k.lastSeenStore().SetKey(k.Gw.keyHash(token), value, 2*session.IdleTimeout); err != nil {
+		k.Logger().WithError(err).Error("Couldn't record the last seen time of the key")
+	}
+}
+
+// lastSeenInterval returns the number of seconds between two updates of the last seen time of a key,
+// capped to half of its idle timeout so it can't expire while it's being used.
+func (k *KeyExpired) lastSeenInterval(session *user.SessionState) int64 {
+	interval := k.Gw.GetConfig().SessionLastSeenInterval
+	if interval <= 0 {
+		interval = defaultSessionLastSeenInterval
+	}
+
+	if half := session.IdleTimeout / 2; interval > half {
+		interval = half
+	}
+
+	return interval
+}
@@ -1,10 +1,20 @@
 package gateway
 
 import (
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/uuid"
 	"github.com/TykTechnologies/tyk/storage"
 	"github.com/TykTechnologies/tyk/test"
 	"github.com/TykTechnologies/tyk/user"
@@ -130,3 +140,151 @@ func TestKeyInactiveWithoutPolicyWithCache(t *testing.T) {
 		BodyMatch: "Key is inactive",
 	})
 }
+
+func TestKeyExpired_IdleTimeout(t *testing.T) {
+	for _, hashKeys := range []bool{false, true} {
+		t.Run(map[bool]string{false: "plain keys", true: "hashed keys"}[hashKeys], func(t *testing.T) {
+			ts := StartTest(func(globalConf *config.Config) {
+				globalConf.HashKeys = hashKeys
+			})
+			defer ts.Close()
+
+			spec := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+				spec.UseKeylessAccess = false
+				spec.Proxy.ListenPath = "/"
+			})[0]
+
+			events := make(chan EventKeyFailureMeta, 10)
+			spec.EventPaths = map[apidef.TykEvent][]config.TykEventHandler{
+				EventKeyExpired: {&testEventHandler{func(em config.EventMessage) {
+					meta, ok := em.Meta.(EventKeyFailureMeta)
+					assert.True(t, ok)
+					events <- meta
+				}}},
+			}
+
+			key := CreateSession(ts.Gw, func(s *user.SessionState) {
+				s.IdleTimeout = 3600
+			})
+			keyHash := storage.HashKey(key, hashKeys)
+			authHeader := map[string]string{header.Authorization: key}
+
+			// setLastSeen fast-forwards the time the key was last used
+			setLastSeen := func(lastSeen int64) {
+				session, found := ts.Gw.GlobalSessionManager.SessionDetail("default", keyHash, true)
+				require.True(t, found)
+				session.LastSeen = lastSeen
+				require.NoError(t, ts.Gw.GlobalSessionManager.UpdateSession(keyHash, &session, 0, true))
+			}
+
+			lastSeen := func() int64 {
+				session, found := ts.Gw.GlobalSessionManager.SessionDetail("default", keyHash, true)
+				require.True(t, found)
+				return session.LastSeen
+			}
+
+			_, _ = ts.Run(t, test.TestCase{Headers: authHeader, Code: http.StatusOK})
+			assert.InDelta(t, time.Now().Unix(), lastSeen(), 5)
+
+			// The last seen time isn't updated more than once per session_last_seen_interval
+			recently := time.Now().Unix() - 10
+			setLastSeen(recently)
+			_, _ = ts.Run(t, test.TestCase{Headers: authHeader, Code: http.StatusOK})
+			assert.Equal(t, recently, lastSeen())
+
+			setLastSeen(time.Now().Unix() - 7200)
+			_, _ = ts.Run(t, test.TestCase{Headers: authHeader, Code: http.StatusUnauthorized, BodyMatch: "inactivity"})
+
+			select {
+			case meta := <-events:
+				assert.Equal(t, key, meta.Key)
+			case <-time.After(time.Second):
+				t.Fatal("KeyExpired event wasn't fired")
+			}
+
+			_, found := ts.Gw.GlobalSessionManager.SessionDetail("default", keyHash, true)
+			assert.False(t, found, "idle key should be removed")
+
+			_, _ = ts.Run(t, test.TestCase{Headers: authHeader, Code: http.StatusForbidden})
+
+			// A key created before its idle timeout was set is idle from its next use, not its creation
+			oldKey := CreateSession(ts.Gw, func(s *user.SessionState) {
+				s.IdleTimeout = 3600
+				s.DateCreated = time.Now().Add(-48 * time.Hour)
+			})
+			keyHash = storage.HashKey(oldKey, hashKeys)
+
+			_, _ = ts.Run(t, test.TestCase{Headers: map[string]string{header.Authorization: oldKey}, Code: http.StatusOK})
+			assert.InDelta(t, time.Now().Unix(), lastSeen(), 5)
+		})
+	}
+}
+
+func TestKeyExpired_IdleTimeout_NeverUsed(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "idle"
+		spec.UseKeylessAccess = false
+		spec.Proxy.ListenPath = "/"
+	})
+
+	session := CreateStandardSession()
+	session.IdleTimeout = 3600
+	session.AccessRights = map[string]user.AccessDefinition{"idle": {APIID: "idle", Versions: []string{"v1"}}}
+
+	resp, _ := ts.Run(t, test.TestCase{AdminAuth: true, Method: http.MethodPost, Path: "/tyk/keys/create", Data: session, Code: http.StatusOK})
+	var created apiModifyKeySuccess
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+
+	stored, found := ts.Gw.GlobalSessionManager.SessionDetail(session.OrgID, created.Key, false)
+	require.True(t, found)
+	assert.InDelta(t, time.Now().Unix(), stored.LastSeen, 5, "the last seen time is set with the idle timeout")
+
+	stored.LastSeen = time.Now().Unix() - 7200
+	require.NoError(t, ts.Gw.GlobalSessionManager.UpdateSession(created.Key, &stored, 0, false))
+
+	_, _ = ts.Run(t, test.TestCase{Headers: map[string]string{header.Authorization: created.Key}, Code: http.StatusUnauthorized, BodyMatch: "inactivity"})
+}
+
+func TestKeyExpired_IdleTimeout_RPC(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	conf := ts.Gw.GetConfig()
+	conf.SlaveOptions.UseRPC = true
+	ts.Gw.SetConfig(conf)
+
+	k := &KeyExpired{&BaseMiddleware{Spec: &APISpec{APIDefinition: &apidef.APIDefinition{}}, Gw: ts.Gw}}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	token := "rpc-idle-key-" + uuid.New()
+	defer k.lastSeenStore().DeleteKey(ts.Gw.keyHash(token))
+
+	// the copy of the key pulled from the control plane has a stale last seen time
+	pulled := func() *user.SessionState {
+		return &user.SessionState{OrgID: "default", IdleTimeout: 3600, LastSeen: time.Now().Unix() - 3000}
+	}
+
+	err, code := k.checkIdleTimeout(r, pulled(), token)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+
+	recorded, err := k.lastSeenStore().GetKey(ts.Gw.keyHash(token))
+	require.NoError(t, err)
+	assert.Equal(t, strconv.FormatInt(time.Now().Unix(), 10), recorded)
+
+	// the last seen time recorded by the data plane is used when the key is pulled again
+	session := pulled()
+	session.LastSeen = time.Now().Unix() - 7200
+	err, code = k.checkIdleTimeout(r, session, token)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+
+	// the key is rejected once it's idle on the data plane
+	require.NoError(t, k.lastSeenStore().SetKey(ts.Gw.keyHash(token), strconv.FormatInt(time.Now().Unix()-7200, 10), 0))
+	session.LastSeen = time.Now().Unix() - 7200
+	err, code = k.checkIdleTimeout(r, session, token)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
@@ -0,0 +1,88 @@
+package gateway
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/request"
+)
+
+const (
+	ErrAuthKeyIPNotAllowed = "auth.key_ip_not_allowed"
+
+	MsgKeyIPNotAllowed = "Access from this IP has been disallowed for this key"
+)
+
+func initKeyIPAllowListErrors() {
+	TykErrors[ErrAuthKeyIPNotAllowed] = config.TykError{
+		Message: MsgKeyIPNotAllowed,
+		Code:    http.StatusForbidden,
+	}
+}
+
+// KeyIPAllowListMiddleware restricts a key to the IPs in its allowed_ips list,
+// set on the key or inherited from its policies. Keys without allowed IPs can
+// be used from anywhere.
+type KeyIPAllowListMiddleware struct {
+	*BaseMiddleware
+}
+
+func (k *KeyIPAllowListMiddleware) Name() string {
+	return "KeyIPAllowListMiddleware"
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (k *KeyIPAllowListMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	if ctxGetRequestStatus(r) == StatusOkAndIgnore {
+		return nil, http.StatusOK
+	}
+
+	session := ctxGetSession(r)
+	if session == nil || len(session.AllowedIPs) == 0 {
+		return nil, http.StatusOK
+	}
+
+	realIP := request.RealIP(r)
+	if ipInList(net.ParseIP(realIP), session.AllowedIPs) {
+		return nil, http.StatusOK
+	}
+
+	k.Logger().WithField("origin", realIP).Info("Attempted access from an IP not allowed for the key.")
+
+	k.FireEvent(EventKeyIPNotAllowed, EventKeyFailureMeta{
+		EventMetaDefault: EventMetaDefault{Message: "Attempted access from an IP not allowed for the key.", OriginatingRequest: EncodeRequestToEvent(r)},
+		Path:             r.URL.Path,
+		Origin:           realIP,
+		Key:              ctxGetAuthToken(r),
+	})
+
+	// Report in health check
+	reportHealthValue(k.Spec, KeyFailure, "-1")
+
+	return errorAndStatusCode(ErrAuthKeyIPNotAllowed)
+}
+
+// ipInList reports whether ip matches any of the IPs or CIDR ranges in list.
+func ipInList(ip net.IP, list []string) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, entry := range list {
+		// Might be CIDR, try this one first then fallback to IP parsing later
+		if _, allowedNet, err := net.ParseCIDR(entry); err == nil {
+			if allowedNet.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		// We parse the IP to manage IPv4 and IPv6 easily
+		if net.ParseIP(entry).Equal(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,169 @@
+package gateway
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestIPInList(t *testing.T) {
+	list := []string{"192.168.1.10", "10.0.0.0/8", "2001:db8::/32", "fe80::1", "bob"}
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"192.168.1.10", true},
+		{"192.168.1.11", false},
+		{"10.20.30.40", true},
+		{"11.0.0.1", false},
+		{"2001:db8::1", true},
+		{"2001:db9::1", false},
+		{"fe80::1", true},
+		{"fe80::2", false},
+		{"::ffff:10.0.0.1", true},
+		{"", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.ip, func(t *testing.T) {
+			assert.Equal(t, tc.want, ipInList(net.ParseIP(tc.ip), list))
+		})
+	}
+}
+
+func TestKeyIPAllowListMiddleware(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	const apiID = "key-ip-allowlist"
+	spec := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = apiID
+		spec.UseKeylessAccess = false
+		spec.Proxy.ListenPath = "/"
+	})[0]
+
+	events := make(chan EventKeyFailureMeta, 10)
+	spec.EventPaths = map[apidef.TykEvent][]config.TykEventHandler{
+		EventKeyIPNotAllowed: {&testEventHandler{func(em config.EventMessage) {
+			meta, ok := em.Meta.(EventKeyFailureMeta)
+			assert.True(t, ok)
+			events <- meta
+		}}},
+	}
+
+	createKey := func(allowedIPs ...string) map[string]string {
+		key := CreateSession(ts.Gw, func(s *user.SessionState) {
+			s.AccessRights = map[string]user.AccessDefinition{apiID: {APIID: apiID}}
+			s.AllowedIPs = allowedIPs
+		})
+		return map[string]string{header.Authorization: key}
+	}
+
+	withIP := func(auth map[string]string, ip string) map[string]string {
+		return map[string]string{header.Authorization: auth[header.Authorization], header.XRealIP: ip}
+	}
+
+	t.Run("key without allowed IPs", func(t *testing.T) {
+		key := createKey()
+		_, _ = ts.Run(t, []test.TestCase{
+			{Headers: key, Code: http.StatusOK},
+			{Headers: withIP(key, "203.0.113.7"), Code: http.StatusOK},
+		}...)
+	})
+
+	t.Run("IPv4", func(t *testing.T) {
+		key := createKey("192.168.1.10", "10.0.0.0/8")
+		_, _ = ts.Run(t, []test.TestCase{
+			{Headers: withIP(key, "192.168.1.10"), Code: http.StatusOK},
+			{Headers: withIP(key, "10.20.30.40"), Code: http.StatusOK},
+			{Headers: withIP(key, "192.168.1.11"), Code: http.StatusForbidden, BodyMatch: MsgKeyIPNotAllowed},
+			{Headers: key, Code: http.StatusForbidden, BodyMatch: MsgKeyIPNotAllowed},
+		}...)
+	})
+
+	t.Run("IPv6", func(t *testing.T) {
+		key := createKey("2001:db8::/32", "fe80::1")
+		_, _ = ts.Run(t, []test.TestCase{
+			{Headers: withIP(key, "2001:db8::1"), Code: http.StatusOK},
+			{Headers: withIP(key, "fe80::1"), Code: http.StatusOK},
+			{Headers: withIP(key, "2001:db9::1"), Code: http.StatusForbidden, BodyMatch: MsgKeyIPNotAllowed},
+		}...)
+	})
+
+	t.Run("allowed IPs from policy", func(t *testing.T) {
+		pID := ts.CreatePolicy(func(p *user.Policy) {
+			p.AccessRights = map[string]user.AccessDefinition{apiID: {APIID: apiID}}
+			p.AllowedIPs = []string{"172.16.0.0/12"}
+		})
+		key := CreateSession(ts.Gw, func(s *user.SessionState) {
+			s.ApplyPolicies = []string{pID}
+		})
+		auth := map[string]string{header.Authorization: key}
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Headers: withIP(auth, "172.16.5.4"), Code: http.StatusOK},
+			{Headers: withIP(auth, "10.0.0.1"), Code: http.StatusForbidden, BodyMatch: MsgKeyIPNotAllowed},
+		}...)
+	})
+
+	t.Run("event is fired", func(t *testing.T) {
+		for len(events) > 0 {
+			<-events
+		}
+
+		key := createKey("10.0.0.0/8")
+		_, _ = ts.Run(t, test.TestCase{Path: "/audit", Headers: withIP(key, "203.0.113.7"), Code: http.StatusForbidden})
+
+		select {
+		case meta := <-events:
+			assert.Equal(t, "203.0.113.7", meta.Origin)
+			assert.Equal(t, "/audit", meta.Path)
+			assert.Equal(t, key[header.Authorization], meta.Key)
+		case <-time.After(time.Second):
+			t.Fatal("KeyIPNotAllowed event wasn't fired")
+		}
+	})
+}
+
+func TestKeyIPAllowListMiddleware_TrustedProxy(t *testing.T) {
+	// The gateway sits behind one trusted proxy which appends the client IP
+	// to X-Forwarded-For, anything to the left of it is set by the client.
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.HttpServerOptions.XFFDepth = 1
+	})
+	defer ts.Close()
+
+	const apiID = "key-ip-allowlist-proxy"
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = apiID
+		spec.UseKeylessAccess = false
+		spec.Proxy.ListenPath = "/"
+	})
+
+	key := CreateSession(ts.Gw, func(s *user.SessionState) {
+		s.AccessRights = map[string]user.AccessDefinition{apiID: {APIID: apiID}}
+		s.AllowedIPs = []string{"10.0.0.0/8"}
+	})
+
+	withXFF := func(xff string) map[string]string {
+		return map[string]string{header.Authorization: key, header.XForwardFor: xff}
+	}
+
+	_, _ = ts.Run(t, []test.TestCase{
+		// Client connecting from an allowed IP
+		{Headers: withXFF("10.1.2.3"), Code: http.StatusOK},
+		{Headers: withXFF("203.0.113.7, 10.1.2.3"), Code: http.StatusOK},
+		// Client spoofing an allowed IP
+		{Headers: withXFF("10.1.2.3, 203.0.113.7"), Code: http.StatusForbidden, BodyMatch: MsgKeyIPNotAllowed},
+	}...)
+}
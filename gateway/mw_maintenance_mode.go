@@ -0,0 +1,153 @@
+package gateway
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/httputil"
+	"github.com/TykTechnologies/tyk/internal/middleware"
+)
+
+const defaultMaintenanceModeBody = `{"error": "API is under maintenance"}`
+
+// MaintenanceModeMiddleware responds with the configured static response instead of
+// proxying the request while the API is in maintenance mode.
+type MaintenanceModeMiddleware struct {
+	*BaseMiddleware
+
+	body        []byte
+	hitRecorder hitRecorder
+}
+
+// maintenanceModeTemplateData is the data available to the maintenance mode body template.
+type maintenanceModeTemplateData struct {
+	APIID      string
+	APIName    string
+	RetryAfter int
+}
+
+func newMaintenanceModeMiddleware(base *BaseMiddleware) *MaintenanceModeMiddleware {
+	return &MaintenanceModeMiddleware{
+		BaseMiddleware: base,
+		hitRecorder:    &realHitRecorder{successHandler: &SuccessHandler{base.Copy()}},
+	}
+}
+
+func (m *MaintenanceModeMiddleware) Name() string {
+	return "MaintenanceModeMiddleware"
+}
+
+func (m *MaintenanceModeMiddleware) EnabledForSpec() bool {
+	return m.Spec.MaintenanceMode.Enabled
+}
+
+// Init renders the body template, the response doesn't depend on the request so it's done once per API load.
+func (m *MaintenanceModeMiddleware) Init() {
+	conf := m.Spec.MaintenanceMode
+	if conf.Body == "" {
+		m.body = []byte(defaultMaintenanceModeBody)
+		return
+	}
+
+	m.body = []byte(conf.Body)
+
+	tmpl, err := template.New("maintenance").Parse(conf.Body)
+	if err != nil {
+		m.Logger().WithError(err).Error("Failed to parse maintenance mode body template, using it as is")
+		return
+	}
+
+	var body bytes.Buffer
+	err = tmpl.Execute(&body, maintenanceModeTemplateData{
+		APIID:      m.Spec.APIID,
+		APIName:    m.Spec.Name,
+		RetryAfter: conf.RetryAfter,
+	})
+	if err != nil {
+		m.Logger().WithError(err).Error("Failed to execute maintenance mode body template, using it as is")
+		return
+	}
+
+	m.body = body.Bytes()
+}
+
+func (m *MaintenanceModeMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	start := time.Now()
+
+	if m.excluded(r) {
+		return nil, http.StatusOK
+	}
+
+	conf := m.Spec.MaintenanceMode
+
+	code := conf.StatusCode
+	if code == 0 {
+		code = http.StatusServiceUnavailable
+	}
+
+	contentType := conf.ContentType
+	if contentType == "" {
+		contentType = header.ApplicationJSON
+	}
+
+	res := &http.Response{
+		StatusCode:    code,
+		Header:        http.Header{},
+		Body:          io.NopCloser(bytes.NewReader(m.body)),
+		ContentLength: int64(len(m.body)),
+		Request:       r,
+	}
+	res.Header.Set(header.ContentType, contentType)
+	if conf.RetryAfter > 0 {
+		res.Header.Set(header.RetryAfter, strconv.Itoa(conf.RetryAfter))
+	}
+
+	for key, values := range res.Header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(code)
+	if _, err := w.Write(m.body); err != nil {
+		m.Logger().WithError(err).Debug("Failed to write maintenance mode response")
+	}
+
+	m.hitRecorder.hit(w, r, res, start)
+
+	return nil, middleware.StatusRespond
+}
+
+// excluded reports whether the request path is excluded from maintenance mode.
+func (m *MaintenanceModeMiddleware) excluded(r *http.Request) bool {
+	if len(m.Spec.MaintenanceMode.ExcludePaths) == 0 {
+		return false
+	}
+
+	gwConfig := m.Gw.GetConfig()
+	isPrefixMatch := gwConfig.HttpServerOptions.EnablePathPrefixMatching
+	isSuffixMatch := gwConfig.HttpServerOptions.EnablePathSuffixMatching
+
+	urlPaths := []string{
+		m.Spec.StripListenPath(r.URL.Path),
+		r.URL.Path,
+	}
+
+	for _, path := range m.Spec.MaintenanceMode.ExcludePaths {
+		pattern := httputil.PreparePathRegexp(path, isPrefixMatch, isSuffixMatch)
+
+		match, err := httputil.MatchPaths(pattern, urlPaths)
+		if err != nil {
+			m.Logger().WithError(err).WithField("pattern", pattern).Error("error matching maintenance mode excluded path")
+			continue
+		}
+
+		if match {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,150 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	ts := StartTest(nil, TestConfig{Delay: 20 * time.Millisecond})
+	defer ts.Close()
+
+	var upstreamHits atomic.Int64
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		upstreamHits.Add(1)
+		_, _ = w.Write([]byte("upstream"))
+	}))
+	defer upstream.Close()
+
+	const apiID = "maintenance-mode"
+	maintenance := apidef.MaintenanceMode{
+		Enabled:      true,
+		ContentType:  "text/plain",
+		Body:         "{{.APIName}} is under maintenance, retry in {{.RetryAfter}}s",
+		RetryAfter:   120,
+		ExcludePaths: []string{"/health"},
+	}
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = apiID
+		spec.Name = "Payments"
+		spec.UseKeylessAccess = false
+		spec.Proxy.ListenPath = "/payments/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.MaintenanceMode = maintenance
+	})
+
+	key := CreateSession(ts.Gw, func(s *user.SessionState) {
+		s.AccessRights = map[string]user.AccessDefinition{apiID: {APIID: apiID}}
+	})
+	authHeaders := map[string]string{header.Authorization: key}
+
+	t.Run("responds without contacting the upstream", func(t *testing.T) {
+		upstreamHits.Store(0)
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{
+				Path:         "/payments/charges",
+				Headers:      authHeaders,
+				Code:         http.StatusServiceUnavailable,
+				BodyMatch:    "^Payments is under maintenance, retry in 120s$",
+				HeadersMatch: map[string]string{header.RetryAfter: "120", header.ContentType: "text/plain"},
+			},
+			{Method: http.MethodPost, Path: "/payments/charges", Headers: authHeaders, Code: http.StatusServiceUnavailable},
+		}...)
+
+		assert.Zero(t, upstreamHits.Load())
+	})
+
+	t.Run("authentication is still enforced", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{Path: "/payments/charges", Code: http.StatusUnauthorized})
+		assert.Zero(t, upstreamHits.Load())
+	})
+
+	t.Run("excluded paths reach the upstream", func(t *testing.T) {
+		upstreamHits.Store(0)
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/payments/health", Headers: authHeaders, Code: http.StatusOK, BodyMatch: "upstream"})
+		assert.Equal(t, int64(1), upstreamHits.Load())
+	})
+
+	t.Run("analytics record the consumer", func(t *testing.T) {
+		redisAnalyticsKeyName := analyticsKeyName + ts.Gw.Analytics.analyticsSerializer.GetSuffix()
+		ts.Gw.Analytics.Flush()
+		ts.Gw.Analytics.Store.GetAndDeleteSet(redisAnalyticsKeyName)
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/payments/charges", Headers: authHeaders, Code: http.StatusServiceUnavailable})
+
+		ts.Gw.Analytics.Flush()
+		results := ts.Gw.Analytics.Store.GetAndDeleteSet(redisAnalyticsKeyName)
+		require.Len(t, results, 1)
+
+		var record analytics.AnalyticsRecord
+		require.NoError(t, ts.Gw.Analytics.analyticsSerializer.Decode([]byte(results[0].(string)), &record))
+		assert.Equal(t, apiID, record.APIID)
+		assert.Equal(t, http.StatusServiceUnavailable, record.ResponseCode)
+		assert.NotEmpty(t, record.APIKey)
+	})
+
+	t.Run("toggled through the API", func(t *testing.T) {
+		api := BuildAPI(func(spec *APISpec) {
+			spec.APIID = apiID
+			spec.Name = "Payments"
+			spec.UseKeylessAccess = false
+			spec.Proxy.ListenPath = "/payments/"
+			spec.Proxy.TargetURL = upstream.URL
+		})[0]
+
+		_, _ = ts.Run(t, test.TestCase{AdminAuth: true, Method: http.MethodPost, Path: "/tyk/apis", Data: api, Code: http.StatusOK})
+		ts.Gw.DoReload()
+
+		upstreamHits.Store(0)
+		_, _ = ts.Run(t, test.TestCase{Path: "/payments/charges", Headers: authHeaders, Code: http.StatusOK})
+		assert.Equal(t, int64(1), upstreamHits.Load())
+
+		api.MaintenanceMode = maintenance
+		testUpdateAPI(t, ts, api, apiID, false)
+
+		upstreamHits.Store(0)
+		_, _ = ts.Run(t, test.TestCase{Path: "/payments/charges", Headers: authHeaders, Code: http.StatusServiceUnavailable})
+		assert.Zero(t, upstreamHits.Load())
+
+		api.MaintenanceMode.Enabled = false
+		testUpdateAPI(t, ts, api, apiID, false)
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/payments/charges", Headers: authHeaders, Code: http.StatusOK})
+		assert.Equal(t, int64(1), upstreamHits.Load())
+	})
+}
+
+func TestMaintenanceMode_Defaults(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.MaintenanceMode.Enabled = true
+	})
+
+	res, _ := ts.Run(t, test.TestCase{
+		Path:         "/",
+		Code:         http.StatusServiceUnavailable,
+		BodyMatch:    defaultMaintenanceModeBody,
+		HeadersMatch: map[string]string{header.ContentType: header.ApplicationJSON},
+	})
+	require.NotNil(t, res)
+	assert.Empty(t, res.Header.Values(header.RetryAfter))
+}
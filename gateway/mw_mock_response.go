@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/TykTechnologies/tyk-pump/analytics"
+	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/apidef/oas"
 	"github.com/TykTechnologies/tyk/common/option"
 	"github.com/TykTechnologies/tyk/header"
@@ -113,6 +115,10 @@ func (m *mockResponseMiddleware) mockResponse(r *http.Request) (
 	versionInfo, _ := m.Spec.Version(r)
 	versionPaths := m.Spec.RxPaths[versionInfo.Name]
 
+	if !m.Spec.IsOAS {
+		return m.classicMockResponse(r, versionPaths)
+	}
+
 	urlSpec, found := m.Spec.FindSpecMatchesStatus(r, versionPaths, OASMockResponse)
 
 	if !found || urlSpec == nil {
@@ -155,6 +161,37 @@ func (m *mockResponseMiddleware) mockResponse(r *http.Request) (
 		code, body, headers = mockFromConfig(mockResponse)
 	}
 
+	if err = waitMockLatency(r.Context(), time.Duration(mockResponse.Latency)); err != nil {
+		return nil, nil, err
+	}
+
+	m.buildResponse(r, res, code, contentType, body, headers)
+
+	return res, internal, nil
+}
+
+// classicMockResponse returns the mock response configured in the extended paths of a classic API for the request,
+// or a nil response if the endpoint isn't mocked.
+func (m *mockResponseMiddleware) classicMockResponse(r *http.Request, versionPaths []URLSpec) (*http.Response, *http.Request, error) {
+	urlSpec, found := m.Spec.FindSpecMatchesStatus(r, versionPaths, MockResponse)
+	if !found || urlSpec == nil {
+		return nil, nil, nil
+	}
+
+	code, body, headers := mockFromClassicConfig(urlSpec.MockResponse)
+
+	if err := waitMockLatency(r.Context(), time.Duration(urlSpec.MockResponse.Latency)*time.Millisecond); err != nil {
+		return nil, nil, err
+	}
+
+	res := &http.Response{Header: http.Header{}}
+	m.buildResponse(r, res, code, "", body, headers)
+
+	return res, r, nil
+}
+
+// buildResponse populates the mock response with the status code, headers and body.
+func (m *mockResponseMiddleware) buildResponse(r *http.Request, res *http.Response, code int, contentType string, body []byte, headers []oas.Header) {
 	for _, h := range headers {
 		res.Header.Set(h.Name, h.Value)
 	}
@@ -171,8 +208,23 @@ func (m *mockResponseMiddleware) mockResponse(r *http.Request) (
 	}
 
 	m.Gw.limitHeaderFactory(res.Header).SendQuotas(ctxGetSession(r), m.Spec.APIID)
+}
 
-	return res, internal, nil
+// waitMockLatency delays a mock response by the configured latency, returning early if the request is cancelled.
+func waitMockLatency(ctx context.Context, latency time.Duration) error {
+	if latency <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // resolveMockCandidate returns the mock response config and OAS path to use.
@@ -212,6 +264,21 @@ func mockFromConfig(tykMockRespOp *oas.MockResponse) (int, []byte, []oas.Header)
 	return code, body, headers
 }
 
+func mockFromClassicConfig(meta apidef.MockResponseMeta) (int, []byte, []oas.Header) {
+	code := meta.Code
+
+	if code == 0 {
+		code = http.StatusOK
+	}
+
+	headers := make([]oas.Header, 0, len(meta.Headers))
+	for name, value := range meta.Headers {
+		headers = append(headers, oas.Header{Name: name, Value: value})
+	}
+
+	return code, []byte(meta.Body), headers
+}
+
 func mockFromOAS(r *http.Request, operation *openapi3.Operation, fromOASExamples *oas.FromOASExamples) (int, string, []byte, []oas.Header, error) {
 	// Extract example name from config or request header
 	exampleName := fromOASExamples.ExampleName
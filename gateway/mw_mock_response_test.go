@@ -13,10 +13,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/apidef/oas"
 	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/internal/uuid"
 	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
 )
 
 func TestMockResponse(t *testing.T) {
@@ -227,6 +229,107 @@ func TestMockResponse(t *testing.T) {
 	})
 }
 
+func TestMockResponse_Classic(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	const latency = 200 * time.Millisecond
+
+	api := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "classic-mock"
+		spec.Proxy.ListenPath = "/"
+		spec.UseKeylessAccess = false
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.UseExtendedPaths = true
+			v.ExtendedPaths.Ignored = []apidef.EndPointMeta{{Path: "/public", Method: http.MethodGet}}
+			v.ExtendedPaths.MockResponse = []apidef.MockResponseMeta{
+				{Path: "/users", Method: http.MethodGet, Code: http.StatusOK, Body: `[{"name":"mock"}]`, Headers: map[string]string{"X-Mock": "list"}},
+				{Path: "/users", Method: http.MethodPost, Code: http.StatusCreated, Body: `{"id":"1"}`},
+				{Path: "/slow", Method: http.MethodGet, Body: "slow", Latency: latency.Milliseconds()},
+				{Path: "/public", Method: http.MethodGet, Code: http.StatusTeapot, Body: "public"},
+				{Path: "/disabled", Method: http.MethodGet, Body: "disabled", Disabled: true},
+			}
+		})
+	})[0]
+
+	_, key := ts.CreateSession(func(s *user.SessionState) {
+		s.AccessRights = map[string]user.AccessDefinition{api.APIID: {APIID: api.APIID}}
+	})
+	authHeaders := map[string]string{header.Authorization: key}
+
+	t.Run("method specific mocks", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Method: http.MethodGet, Path: "/users", Headers: authHeaders, Code: http.StatusOK, BodyMatch: `^\[{"name":"mock"}\]$`, HeadersMatch: map[string]string{"X-Mock": "list"}},
+			{Method: http.MethodPost, Path: "/users", Headers: authHeaders, Code: http.StatusCreated, BodyMatch: `^{"id":"1"}$`},
+		}...)
+	})
+
+	t.Run("non mocked endpoints are proxied", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Method: http.MethodPut, Path: "/users", Headers: authHeaders, Code: http.StatusOK, BodyMatch: `"Method":"PUT"`},
+			{Method: http.MethodGet, Path: "/orders", Headers: authHeaders, Code: http.StatusOK, BodyMatch: `"Url":"/orders"`},
+			{Method: http.MethodGet, Path: "/disabled", Headers: authHeaders, Code: http.StatusOK, BodyMatch: `"Url":"/disabled"`},
+		}...)
+	})
+
+	t.Run("access control applies", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Method: http.MethodGet, Path: "/users", Code: http.StatusUnauthorized, BodyNotMatch: "mock"},
+			{Method: http.MethodGet, Path: "/public", Code: http.StatusTeapot, BodyMatch: "^public$"},
+		}...)
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		start := time.Now()
+		_, _ = ts.Run(t, test.TestCase{Method: http.MethodGet, Path: "/slow", Headers: authHeaders, Code: http.StatusOK, BodyMatch: "^slow$"})
+		assert.GreaterOrEqual(t, time.Since(start), latency)
+	})
+}
+
+func TestMockResponse_OASLatency(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	const latency = 200 * time.Millisecond
+
+	api := BuildOASAPI(func(oasDef *oas.OAS) {
+		tykExt := oasDef.GetTykExtension()
+		tykExt.Server.ListenPath.Value = "/oas-mock/"
+		tykExt.Middleware = &oas.Middleware{
+			Operations: oas.Operations{
+				"slowGET": {
+					MockResponse: &oas.MockResponse{
+						Enabled: true,
+						Code:    http.StatusAccepted,
+						Body:    "slow",
+						Latency: oas.ReadableDuration(latency),
+					},
+				},
+			},
+		}
+
+		oasDef.Paths = openapi3.NewPaths()
+		oasDef.Paths.Set("/slow", &openapi3.PathItem{
+			Get: &openapi3.Operation{OperationID: "slowGET", Responses: openapi3.NewResponses()},
+		})
+	})[0]
+
+	ts.Gw.LoadAPI(api)
+
+	start := time.Now()
+	_, _ = ts.Run(t, test.TestCase{Path: "/oas-mock/slow", Code: http.StatusAccepted, BodyMatch: "^slow$"})
+	assert.GreaterOrEqual(t, time.Since(start), latency)
+}
+
+func TestWaitMockLatency(t *testing.T) {
+	assert.NoError(t, waitMockLatency(context.Background(), 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, waitMockLatency(ctx, time.Minute), context.Canceled)
+}
+
 func Test_mockFromConfig(t *testing.T) {
 	const body = "my-mock-response-body"
 
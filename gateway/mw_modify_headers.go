@@ -2,8 +2,10 @@ package gateway
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/regexp"
 )
 
 // TransformMiddleware is a middleware that will apply a template to a request body to transform it's contents ready for an upstream API
@@ -47,6 +49,8 @@ func (t *TransformHeaders) ProcessRequest(w http.ResponseWriter, r *http.Request
 			logger.Debugf("Adding global: %s: %s", nKey, nVal)
 			setCustomHeader(r.Header, nKey, t.Gw.ReplaceTykVariables(r, nVal, false), ignoreCanonical)
 		}
+
+		rewriteHeaders(r.Header, vInfo.GlobalHeadersRewrite)
 	}
 
 	versionPaths := t.Spec.RxPaths[vInfo.Name]
@@ -61,7 +65,30 @@ func (t *TransformHeaders) ProcessRequest(w http.ResponseWriter, r *http.Request
 			setCustomHeader(r.Header, nKey, t.Gw.ReplaceTykVariables(r, nVal, false), ignoreCanonical)
 			logger.Debugf("Adding: %s: %s", nKey, nVal)
 		}
+		rewriteHeaders(r.Header, hmeta.RewriteHeaders)
 	}
 
 	return nil, http.StatusOK
 }
+
+// rewriteHeaders applies the regular expression replacements to the values of the matching headers.
+// Header names are matched case-insensitively, so non-canonical keys are rewritten too.
+func rewriteHeaders(h http.Header, rewrites []apidef.HeaderRewrite) {
+	for _, rewrite := range rewrites {
+		re, err := regexp.Compile(rewrite.MatchRegex)
+		if err != nil {
+			log.WithError(err).Errorf("Invalid header rewrite regex for header %s", rewrite.Header)
+			continue
+		}
+
+		for name, values := range h {
+			if !strings.EqualFold(name, rewrite.Header) {
+				continue
+			}
+
+			for i, value := range values {
+				values[i] = re.ReplaceAllString(value, rewrite.Replace)
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -99,4 +100,25 @@ func TestHeaderInjectionMeta_Enabled(t *testing.T) {
 
 	h.DeleteHeaders = []string{"a"}
 	assert.True(t, h.Enabled())
+
+	h.DeleteHeaders = nil
+	h.RewriteHeaders = []apidef.HeaderRewrite{{Header: "a", MatchRegex: "b", Replace: "c"}}
+	assert.True(t, h.Enabled())
+}
+
+func TestRewriteHeaders(t *testing.T) {
+	h := http.Header{
+		"X-Correlation-Id": []string{"abcd1234efgh", "wxyz9876"},
+		"location":         []string{"http://internal.local:8080/path"},
+		"X-Other":          []string{"abcd1234efgh"},
+	}
+
+	rewriteHeaders(h, []apidef.HeaderRewrite{
+		{Header: "x-correlation-id", MatchRegex: `^(\w{4})\w+`, Replace: "$1****"},
+		{Header: "Location", MatchRegex: `^http://internal\.local(:\d+)?`, Replace: "https://api.example.com"},
+	})
+
+	assert.Equal(t, []string{"abcd****", "wxyz****"}, h["X-Correlation-Id"])
+	assert.Equal(t, []string{"https://api.example.com/path"}, h["location"])
+	assert.Equal(t, []string{"abcd1234efgh"}, h["X-Other"])
 }
@@ -3,13 +3,14 @@
 package gateway
 
 import (
+	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/ee/middleware/upstreamoauth"
 	"github.com/TykTechnologies/tyk/internal/model"
 	"github.com/TykTechnologies/tyk/storage"
 )
 
 func getUpstreamOAuthMw(base *BaseMiddleware) TykMiddleware {
-	mwSpec := model.MergedAPI{APIDefinition: base.Spec.APIDefinition}
+	mwSpec := model.MergedAPI{APIDefinition: resolveUpstreamOAuthSecrets(base)}
 	upstreamOAuthMw := upstreamoauth.NewMiddleware(
 		base.Gw,
 		base,
@@ -17,10 +18,37 @@ func getUpstreamOAuthMw(base *BaseMiddleware) TykMiddleware {
 		getClientCredentialsStorageHandler(base),
 		getPasswordStorageHandler(base),
 	)
+	upstreamOAuthMw.LocalCache = base.Gw.UtilCache
 
 	return WrapMiddleware(base, upstreamOAuthMw)
 }
 
+// resolveUpstreamOAuthSecrets returns a copy of the API definition with the client and password credentials
+// resolved from the KV stores, so the resolved values aren't exposed through the API definition.
+func resolveUpstreamOAuthSecrets(base *BaseMiddleware) *apidef.APIDefinition {
+	def := *base.Spec.APIDefinition
+	clientAuth := &def.UpstreamAuth.OAuth.ClientCredentials.ClientAuthData
+	passwordAuth := &def.UpstreamAuth.OAuth.PasswordAuthentication
+
+	for _, value := range []*string{
+		&clientAuth.ClientID,
+		&clientAuth.ClientSecret,
+		&passwordAuth.ClientID,
+		&passwordAuth.ClientSecret,
+		&passwordAuth.Username,
+		&passwordAuth.Password,
+	} {
+		resolved, err := base.Gw.kvStore(*value)
+		if err != nil {
+			base.Logger().WithError(err).Error("Couldn't resolve the upstream OAuth credentials")
+			continue
+		}
+		*value = resolved
+	}
+
+	return &def
+}
+
 func getClientCredentialsStorageHandler(base *BaseMiddleware) *storage.RedisCluster {
 	handler := &storage.RedisCluster{KeyPrefix: "upstreamOAuthCC-", ConnectionHandler: base.Gw.StorageConnectionHandler}
 	handler.Connect()
@@ -8,6 +8,8 @@ import (
 	"golang.org/x/sync/singleflight"
 
 	"github.com/TykTechnologies/tyk/ctx"
+	"github.com/TykTechnologies/tyk/header"
+	tykerrors "github.com/TykTechnologies/tyk/internal/errors"
 	"github.com/TykTechnologies/tyk/request"
 	"github.com/TykTechnologies/tyk/user"
 )
@@ -21,6 +23,12 @@ var orgChanMap = orgChanMapMu{channels: map[string](chan bool){}}
 var orgActiveMap sync.Map
 var orgSessionFetchGroup singleflight.Group
 
+//nolint:staticcheck
+var (
+	errOrgQuotaExceeded     = errors.New("This organisation quota has been exceeded, please contact your API administrator")
+	errOrgRateLimitExceeded = errors.New("This organisation rate limit has been exceeded, please contact your API administrator")
+)
+
 // RateLimitAndQuotaCheck will check the incoming request and key whether it is within it's quota and
 // within it's rate limit, it makes use of the SessionLimiter object to do this
 type OrganizationMonitor struct {
@@ -99,7 +107,12 @@ func (k *OrganizationMonitor) ProcessRequest(w http.ResponseWriter, r *http.Requ
 		r2 := r.WithContext(r.Context())
 		return k.ProcessRequestOffThread(r2, &clone)
 	}
-	return k.ProcessRequestLive(r, &clone)
+
+	err, code := k.ProcessRequestLive(r, &clone)
+	if errors.Is(err, errOrgQuotaExceeded) || errors.Is(err, errOrgRateLimitExceeded) {
+		w.Header().Set(header.XTykLimitScope, limitScopeOrg)
+	}
+	return err, code
 }
 
 func (k *OrganizationMonitor) refreshOrgSession(orgID string) {
@@ -165,6 +178,9 @@ func (k *OrganizationMonitor) ProcessRequestLive(
 	case sessionFailQuota:
 		logger.Warning("Organisation quota has been exceeded.", k.Spec.OrgID)
 
+		// Set error classification for access logs
+		ctx.SetErrorClassification(r, tykerrors.ClassifyOrgQuotaExceededError(k.Name()))
+
 		// Fire a quota exceeded event
 		k.FireEvent(
 			EventOrgQuotaExceeded,
@@ -178,10 +194,13 @@ func (k *OrganizationMonitor) ProcessRequestLive(
 				Key:    k.Spec.OrgID,
 			})
 
-		return errors.New("This organisation quota has been exceeded, please contact your API administrator"), http.StatusForbidden
+		return errOrgQuotaExceeded, http.StatusForbidden
 	case sessionFailRateLimit:
 		logger.Warning("Organisation rate limit has been exceeded.", k.Spec.OrgID)
 
+		// Set error classification for access logs
+		ctx.SetErrorClassification(r, tykerrors.ClassifyRateLimitError(tykerrors.ErrTypeOrgRateLimit, k.Name()))
+
 		// Fire a rate limit exceeded event
 		k.FireEvent(
 			EventOrgRateLimitExceeded,
@@ -195,7 +214,7 @@ func (k *OrganizationMonitor) ProcessRequestLive(
 				Key:    k.Spec.OrgID,
 			},
 		)
-		return errors.New("This organisation rate limit has been exceeded, please contact your API administrator"), http.StatusForbidden
+		return errOrgRateLimitExceeded, http.StatusForbidden
 	}
 
 	if k.Spec.GlobalConfig.Monitor.MonitorOrgKeys {
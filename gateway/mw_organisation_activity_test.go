@@ -12,7 +12,9 @@ import (
 	"github.com/TykTechnologies/tyk/internal/uuid"
 
 	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
 )
 
 func (ts *Test) testPrepareProcessRequestQuotaLimit(tb testing.TB, data map[string]interface{}) {
@@ -123,6 +125,65 @@ func TestProcessRequestLiveQuotaLimit(t *testing.T) {
 	})
 }
 
+func TestProcessRequestLiveQuotaLimit_SharedAcrossKeys(t *testing.T) {
+	test.Flaky(t) // Test uses StorageConnectionHandler (singleton).
+
+	conf := func(globalConf *config.Config) {
+		globalConf.EnforceOrgQuotas = true
+		globalConf.ExperimentalProcessOrgOffThread = false
+	}
+	ts := StartTest(conf)
+	defer ts.Close()
+
+	orgID := "test-org-" + uuid.New()
+	api := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.UseKeylessAccess = false
+		spec.OrgID = orgID
+		spec.Proxy.ListenPath = "/"
+	})[0]
+
+	ts.Run(t, test.TestCase{
+		Path:      "/tyk/org/keys/" + orgID + "?reset_quota=1",
+		AdminAuth: true,
+		Method:    http.MethodPost,
+		Code:      http.StatusOK,
+		Data: map[string]interface{}{
+			"org_id":             orgID,
+			"quota_max":          4,
+			"quota_remaining":    4,
+			"quota_renewal_rate": 60,
+		},
+	})
+
+	createKey := func(quotaMax int64) string {
+		_, key := ts.CreateSession(func(s *user.SessionState) {
+			s.OrgID = orgID
+			s.QuotaMax = quotaMax
+			s.QuotaRemaining = quotaMax
+			s.QuotaRenewalRate = 60
+			s.AccessRights = map[string]user.AccessDefinition{api.APIID: {
+				APIID: api.APIID,
+			}}
+		})
+		return key
+	}
+
+	keyA, keyB := createKey(-1), createKey(1)
+	authA := map[string]string{header.Authorization: keyA}
+	authB := map[string]string{header.Authorization: keyB}
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Headers: authA, Code: http.StatusOK},
+		{Headers: authB, Code: http.StatusOK},
+		// key B is out of its own quota while the org still has some left
+		{Headers: authB, Code: http.StatusForbidden, HeadersMatch: map[string]string{header.XTykLimitScope: limitScopeKey}},
+		{Headers: authA, Code: http.StatusOK},
+		// the org quota is exhausted by both keys together
+		{Headers: authA, Code: http.StatusForbidden, HeadersMatch: map[string]string{header.XTykLimitScope: limitScopeOrg},
+			BodyMatch: "organisation quota has been exceeded"},
+	}...)
+}
+
 func BenchmarkProcessRequestLiveQuotaLimit(b *testing.B) {
 	b.ReportAllocs()
 
@@ -8,9 +8,18 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/TykTechnologies/tyk/ctx"
+	"github.com/TykTechnologies/tyk/header"
 	tykerrors "github.com/TykTechnologies/tyk/internal/errors"
 	"github.com/TykTechnologies/tyk/internal/event"
+	"github.com/TykTechnologies/tyk/internal/rate"
 	"github.com/TykTechnologies/tyk/request"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// Values of the X-Tyk-Limit-Scope header telling which limit rejected the request.
+const (
+	limitScopeKey = "key"
+	limitScopeOrg = "org"
 )
 
 // RateLimitAndQuotaCheck will check the incomming request and key whether it is within it's quota and
@@ -103,43 +112,17 @@ func (k *RateLimitAndQuotaCheck) ProcessRequest(w http.ResponseWriter, r *http.R
 	switch reason {
 	case sessionFailNone:
 	case sessionFailRateLimit:
+		if k.throttle(r, session, rateLimitKey, quotaKey, throttleInterval, throttleRetryLimit, headerSender) {
+			return k.ProcessRequest(w, r, nil)
+		}
+
+		w.Header().Set(header.XTykLimitScope, limitScopeKey)
 		// Set error classification for access logs
 		ctx.SetErrorClassification(r, tykerrors.ClassifyRateLimitError(tykerrors.ErrTypeSessionRateLimit, k.Name()))
-		err, errCode := k.handleRateLimitFailure(r, event.RateLimitExceeded, "Rate Limit Exceeded", rateLimitKey)
-		if throttleRetryLimit > 0 {
-			for {
-				ctxIncThrottleLevel(r, throttleRetryLimit)
-				time.Sleep(time.Duration(throttleInterval * float64(time.Second)))
-
-				reason = k.Gw.SessionLimiter.ForwardMessage(
-					r,
-					session,
-					rateLimitKey,
-					quotaKey,
-					!k.Spec.DisableRateLimit,
-					!k.Spec.DisableQuota,
-					k.Spec,
-					true,
-					headerSender,
-				)
-
-				log.WithFields(logrus.Fields{
-					"middleware": "RateLimitAndQuotaCheck",
-					"func":       "ProcessRequest",
-				}).Debugf("after dry-run (reason: '%s')", reason)
-
-				if ctxThrottleLevel(r) > throttleRetryLimit {
-					break
-				}
-
-				if reason == sessionFailNone {
-					return k.ProcessRequest(w, r, nil)
-				}
-			}
-		}
-		return err, errCode
+		return k.handleRateLimitFailure(r, event.RateLimitExceeded, "Rate Limit Exceeded", rateLimitKey)
 
 	case sessionFailQuota:
+		w.Header().Set(header.XTykLimitScope, limitScopeKey)
 		return k.handleQuotaFailure(r, rateLimitKey)
 	case sessionFailInternalServerError:
 		ctx.SetErrorClassification(r, tykerrors.ClassifyRateLimitError(tykerrors.ErrTypeOtherRateLimit, k.Name()))
@@ -157,3 +140,52 @@ func (k *RateLimitAndQuotaCheck) ProcessRequest(w http.ResponseWriter, r *http.R
 	// Request is valid, carry on
 	return nil, http.StatusOK
 }
+
+// throttle queues a request of a key which exceeded its rate limit when throttle_interval and
+// throttle_retry_limit are set, checking the rate limit again after each interval. It reports
+// whether the rate limit allows the request before the retry limit is reached, the request is
+// cancelled or its deadline would be exceeded.
+//
+// The checks are dry runs, the request is counted by the check of ProcessRequest once allowed.
+// No storage connection is held while waiting, and the time spent waiting is only accounted to
+// the gateway latency in analytics, not to the upstream latency.
+func (k *RateLimitAndQuotaCheck) throttle(r *http.Request, session *user.SessionState, rateLimitKey, quotaKey string, interval float64, retryLimit int, headerSender rate.HeaderSender) bool {
+	if interval <= 0 || retryLimit <= 0 {
+		return false
+	}
+
+	wait := time.Duration(interval * float64(time.Second))
+	for ctxThrottleLevel(r) < retryLimit {
+		if deadline, ok := r.Context().Deadline(); ok && time.Until(deadline) < wait {
+			return false
+		}
+
+		ctxIncThrottleLevel(r, retryLimit)
+		if !sleepContext(r.Context(), wait) {
+			return false
+		}
+
+		reason := k.Gw.SessionLimiter.ForwardMessage(
+			r,
+			session,
+			rateLimitKey,
+			quotaKey,
+			!k.Spec.DisableRateLimit,
+			!k.Spec.DisableQuota,
+			k.Spec,
+			true,
+			headerSender,
+		)
+
+		log.WithFields(logrus.Fields{
+			"middleware": "RateLimitAndQuotaCheck",
+			"func":       "throttle",
+		}).Debugf("after dry-run (reason: '%s')", reason)
+
+		if reason == sessionFailNone {
+			return true
+		}
+	}
+
+	return false
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
 
+	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/test"
@@ -68,8 +69,68 @@ func TestRateLimit_Unlimited(t *testing.T) {
 	})
 }
 
+func TestRateLimit_Throttling(t *testing.T) {
+	g := StartTest(nil)
+	defer g.Close()
+
+	api := g.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.UseKeylessAccess = false
+	})[0]
+
+	createKey := func(per, interval float64, retryLimit int) map[string]string {
+		_, key := g.CreateSession(func(s *user.SessionState) {
+			s.AccessRights = map[string]user.AccessDefinition{
+				api.APIID: {
+					APIName: api.Name,
+					APIID:   api.APIID,
+				},
+			}
+			s.Rate = 1
+			s.Per = per
+			s.ThrottleInterval = interval
+			s.ThrottleRetryLimit = retryLimit
+		})
+
+		return map[string]string{header.Authorization: key}
+	}
+
+	timeRequest := func(t *testing.T, authHeader map[string]string, code int) time.Duration {
+		t.Helper()
+		start := time.Now()
+		_, _ = g.Run(t, test.TestCase{Headers: authHeader, Code: code})
+		return time.Since(start)
+	}
+
+	t.Run("request waits for the rate limit", func(t *testing.T) {
+		authHeader := createKey(1, 0.2, 10)
+
+		timeRequest(t, authHeader, http.StatusOK)
+		elapsed := timeRequest(t, authHeader, http.StatusOK)
+		assert.GreaterOrEqual(t, elapsed, 200*time.Millisecond, "request should be throttled")
+		assert.Less(t, elapsed, 2*time.Second)
+	})
+
+	t.Run("rate limited once the retry budget is exhausted", func(t *testing.T) {
+		authHeader := createKey(60, 0.1, 3)
+
+		timeRequest(t, authHeader, http.StatusOK)
+		elapsed := timeRequest(t, authHeader, http.StatusTooManyRequests)
+		assert.GreaterOrEqual(t, elapsed, 300*time.Millisecond, "request should be retried 3 times")
+		assert.Less(t, elapsed, time.Second)
+	})
+
+	t.Run("not throttled without interval", func(t *testing.T) {
+		authHeader := createKey(60, 0, 3)
+
+		timeRequest(t, authHeader, http.StatusOK)
+		elapsed := timeRequest(t, authHeader, http.StatusTooManyRequests)
+		assert.Less(t, elapsed, 100*time.Millisecond)
+	})
+}
+
 func TestRateLimitResponseHeaders(t *testing.T) {
-	limiters := []string{"Redis", "Sentinel", "DRL", "FixedWindow"}
+	limiters := []string{"Redis", "Sentinel", "DRL", "FixedWindow", "TokenBucket"}
 
 	for _, limiter := range limiters {
 		t.Run("Rate limit headers for "+limiter, func(t *testing.T) {
@@ -85,6 +146,8 @@ func TestRateLimitResponseHeaders(t *testing.T) {
 					globalConf.DRLEnableSentinelRateLimiter = true
 				case "FixedWindow":
 					globalConf.EnableFixedWindowRateLimiter = true
+				case "TokenBucket":
+					globalConf.EnableTokenBucketRateLimiter = true
 				}
 			})
 			defer ts.Close()
@@ -131,7 +194,7 @@ func TestRateLimitResponseHeaders(t *testing.T) {
 			}
 
 			// For limiters that don't support Remaining (Sentinel, FixedWindow), it should be 0.
-			if limiter == "Redis" || limiter == "DRL" {
+			if limiter == "Redis" || limiter == "DRL" || limiter == "TokenBucket" {
 				headersMatch1[header.XRateLimitRemaining] = expectedRemaining1
 				headersMatch2[header.XRateLimitRemaining] = expectedRemaining2
 			} else {
@@ -210,12 +273,192 @@ func TestRateLimitResponseHeaders_NoDuplicated(t *testing.T) {
 	assert.Len(t, resp.Header.Values(header.XRateLimitLimit), 1)
 }
 
+func TestRateLimitBurst(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.RateLimitResponseHeaders = config.SourceRateLimits
+	})
+	defer ts.Close()
+
+	api := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/rate-limit-burst"
+		spec.UseKeylessAccess = false
+	})[0]
+
+	// One request a minute, with up to three requests in a burst.
+	limit := user.APILimit{RateLimit: user.RateLimit{Rate: 1, Per: 60, Burst: 3}}
+
+	check := func(t *testing.T, authKey string) {
+		t.Helper()
+
+		authorization := map[string]string{header.Authorization: authKey}
+		remaining := func(n string) map[string]string {
+			return map[string]string{header.XRateLimitLimit: "3", header.XRateLimitRemaining: n}
+		}
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Headers: authorization, Path: "/rate-limit-burst", Code: http.StatusOK, HeadersMatch: remaining("2")},
+			{Headers: authorization, Path: "/rate-limit-burst", Code: http.StatusOK, HeadersMatch: remaining("1")},
+			{Headers: authorization, Path: "/rate-limit-burst", Code: http.StatusOK, HeadersMatch: remaining("0")},
+			{Headers: authorization, Path: "/rate-limit-burst", Code: http.StatusTooManyRequests},
+			{Headers: authorization, Path: "/rate-limit-burst", Code: http.StatusTooManyRequests},
+		}...)
+	}
+
+	t.Run("burst on key", func(t *testing.T) {
+		_, authKey := ts.CreateSession(func(s *user.SessionState) {
+			s.AccessRights = map[string]user.AccessDefinition{
+				api.APIID: {
+					APIName: api.Name,
+					APIID:   api.APIID,
+					Limit:   limit,
+				},
+			}
+		})
+
+		check(t, authKey)
+	})
+
+	t.Run("burst on policy", func(t *testing.T) {
+		pID := ts.CreatePolicy(func(p *user.Policy) {
+			p.Rate = limit.Rate
+			p.Per = limit.Per
+			p.Burst = limit.Burst
+			p.AccessRights = map[string]user.AccessDefinition{
+				api.APIID: {APIName: api.Name, APIID: api.APIID},
+			}
+		})
+
+		_, authKey := ts.CreateSession(func(s *user.SessionState) {
+			s.ApplyPolicies = []string{pID}
+		})
+
+		check(t, authKey)
+	})
+}
+
 // TestQuotaHeadersOnErrorResponses verifies the current behavior where quota headers
 // are not populated when requests are rejected due to quota exhaustion.
 //
 // When a request exceeds the quota limit and returns 403 Forbidden,
 // the X-RateLimit-* headers are not included in the response to suppport
 // backward compatibility.
+func TestRateLimit_RateCost(t *testing.T) {
+	rateCosts := func(applyToQuota bool) func(spec *APISpec) {
+		return func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/"
+			spec.UseKeylessAccess = false
+			UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+				v.ExtendedPaths.RateCost = []apidef.RateCostMeta{
+					{Path: "/heavy", Method: http.MethodGet, Cost: 5, ApplyToQuota: applyToQuota},
+					{Path: "/cheap", Method: http.MethodGet, Cost: 1, ApplyToQuota: applyToQuota},
+				}
+			})
+		}
+	}
+
+	limiters := []string{"DRL", "Redis", "TokenBucket", "FixedWindow"}
+
+	for _, limiter := range limiters {
+		t.Run("rate limit with "+limiter, func(t *testing.T) {
+			ts := StartTest(func(globalConf *config.Config) {
+				globalConf.RateLimitResponseHeaders = config.SourceRateLimits
+
+				switch limiter {
+				case "Redis":
+					globalConf.EnableRedisRollingLimiter = true
+				case "TokenBucket":
+					globalConf.EnableTokenBucketRateLimiter = true
+				case "FixedWindow":
+					globalConf.EnableFixedWindowRateLimiter = true
+				}
+			})
+			defer ts.Close()
+
+			api := ts.Gw.BuildAndLoadAPI(rateCosts(false))[0]
+
+			_, key := ts.CreateSession(func(s *user.SessionState) {
+				s.AccessRights = map[string]user.AccessDefinition{
+					api.APIID: {
+						APIName: api.Name,
+						APIID:   api.APIID,
+						Limit:   user.APILimit{RateLimit: user.RateLimit{Rate: 10, Per: 60}},
+					},
+				}
+			})
+
+			authHeader := map[string]string{header.Authorization: key}
+			remaining := func(n string) map[string]string {
+				// the fixed window limiter doesn't report the remaining requests
+				if limiter == "FixedWindow" {
+					return nil
+				}
+				return map[string]string{header.XRateLimitLimit: "10", header.XRateLimitRemaining: n}
+			}
+
+			_, _ = ts.Run(t, []test.TestCase{
+				{Path: "/heavy", Headers: authHeader, Code: http.StatusOK, HeadersMatch: remaining("5")},
+				{Path: "/cheap", Headers: authHeader, Code: http.StatusOK, HeadersMatch: remaining("4")},
+				{Path: "/cheap", Headers: authHeader, Code: http.StatusOK, HeadersMatch: remaining("3")},
+				{Path: "/heavy", Headers: authHeader, Code: http.StatusTooManyRequests},
+			}...)
+
+			// the sliding log records the blocked requests too
+			if limiter == "Redis" {
+				return
+			}
+
+			// the blocked request doesn't use up the allowance left
+			_, _ = ts.Run(t, []test.TestCase{
+				{Path: "/cheap", Headers: authHeader, Code: http.StatusOK},
+				{Path: "/cheap", Headers: authHeader, Code: http.StatusOK},
+				{Path: "/cheap", Headers: authHeader, Code: http.StatusOK},
+				{Path: "/cheap", Headers: authHeader, Code: http.StatusTooManyRequests},
+			}...)
+		})
+	}
+
+	for _, applyToQuota := range []bool{true, false} {
+		t.Run(fmt.Sprintf("quota with apply_to_quota %v", applyToQuota), func(t *testing.T) {
+			ts := StartTest(nil)
+			defer ts.Close()
+
+			api := ts.Gw.BuildAndLoadAPI(rateCosts(applyToQuota))[0]
+
+			_, key := ts.CreateSession(func(s *user.SessionState) {
+				s.AccessRights = map[string]user.AccessDefinition{
+					api.APIID: {
+						APIName: api.Name,
+						APIID:   api.APIID,
+						Limit:   user.APILimit{QuotaMax: 10, QuotaRenewalRate: 60},
+					},
+				}
+			})
+
+			authHeader := map[string]string{header.Authorization: key}
+			remaining := func(n string) map[string]string {
+				return map[string]string{header.XRateLimitRemaining: n}
+			}
+
+			if !applyToQuota {
+				_, _ = ts.Run(t, []test.TestCase{
+					{Path: "/heavy", Headers: authHeader, Code: http.StatusOK, HeadersMatch: remaining("9")},
+					{Path: "/heavy", Headers: authHeader, Code: http.StatusOK, HeadersMatch: remaining("8")},
+					{Path: "/cheap", Headers: authHeader, Code: http.StatusOK, HeadersMatch: remaining("7")},
+				}...)
+				return
+			}
+
+			_, _ = ts.Run(t, []test.TestCase{
+				{Path: "/heavy", Headers: authHeader, Code: http.StatusOK, HeadersMatch: remaining("5")},
+				{Path: "/cheap", Headers: authHeader, Code: http.StatusOK, HeadersMatch: remaining("4")},
+				{Path: "/heavy", Headers: authHeader, Code: http.StatusForbidden},
+				// the blocked request doesn't use up the quota left
+				{Path: "/cheap", Headers: authHeader, Code: http.StatusOK, HeadersMatch: remaining("3")},
+			}...)
+		})
+	}
+}
+
 func TestQuotaHeadersOnErrorResponses(t *testing.T) {
 	ts := StartTest(func(globalConf *config.Config) {
 		globalConf.RateLimitResponseHeaders = config.SourceQuotas
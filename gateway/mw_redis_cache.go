@@ -25,6 +25,8 @@ import (
 
 const (
 	cachedResponseHeader = "x-tyk-cached-response"
+	// staleResponseHeader is set to "stale" on expired cache entries served because the upstream failed.
+	staleResponseHeader = "X-Tyk-Cache"
 )
 
 // RedisCacheMiddleware is a caching middleware that will pull data from Redis instead of the upstream proxy
@@ -66,7 +68,7 @@ func (m *RedisCacheMiddleware) CreateCheckSum(req *http.Request, keyName string,
 	}
 
 	reqChecksum := hex.EncodeToString(h.Sum(nil))
-	return m.Spec.APIID + keyName + reqChecksum, nil
+	return cacheKeyPathPrefix(m.Spec.APIID, m.Spec.StripListenPath(req.URL.EscapedPath())) + keyName + reqChecksum, nil
 }
 
 func addBodyHash(req *http.Request, regex string, h hash.Hash) (err error) {
@@ -147,6 +149,10 @@ type cacheOptions struct {
 	key                    string
 	cacheOnlyResponseCodes []int
 	timeout                int64
+	// varyValues holds the request values of the allowlisted vary headers, nil when vary support is disabled.
+	varyValues http.Header
+	// stale holds an expired cached response which can still be served if the upstream fails.
+	stale string
 }
 
 // ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
@@ -207,11 +213,21 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 		}
 	}
 
-	ctxSetCacheOptions(r, &cacheOptions{
+	varyValues := m.getVaryValues(r)
+	options := &cacheOptions{
 		key:                    key,
 		cacheOnlyResponseCodes: cacheOnlyResponseCodes,
 		timeout:                timeout,
-	})
+		varyValues:             varyValues,
+	}
+	ctxSetCacheOptions(r, options)
+
+	// The response for this key varies on request headers, look up the matching variant
+	if varyValues != nil {
+		if varied, err := m.store.GetKey(cacheVaryIndexKey(key)); err == nil && varied != "" {
+			key = cacheVariantKey(key, strings.Split(varied, ","), varyValues)
+		}
+	}
 
 	retBlob, err = m.store.GetKey(key)
 	if err != nil {
@@ -226,30 +242,36 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 		return nil, http.StatusOK
 	}
 
-	if m.isTimeStampExpired(timestamp) || len(cachedData) == 0 {
+	if len(cachedData) == 0 {
 		m.store.DeleteKey(key)
 		return nil, http.StatusOK
 	}
 
-	bufData := bufio.NewReader(strings.NewReader(cachedData))
-	newRes, err := http.ReadResponse(bufData, r)
+	if m.isTimeStampExpired(timestamp) {
+		if m.Spec.CacheOptions.StaleIfError > 0 {
+			// Keep the expired entry within its grace window, it is served only if the upstream fails
+			options.stale = cachedData
+			return nil, http.StatusOK
+		}
+
+		m.store.DeleteKey(key)
+		return nil, http.StatusOK
+	}
+
+	newRes, err := readCachedResponse(cachedData, r)
 	if err != nil {
 		m.Logger().WithError(err).Error("Could not create response object")
 		m.store.DeleteKey(key)
 		return nil, http.StatusOK
 	}
 
-	nopCloseResponseBody(newRes)
-
-	defer newRes.Body.Close()
-	for _, h := range hopHeaders {
-		newRes.Header.Del(h)
-	}
+	defer func() {
+		_ = newRes.Body.Close()
+	}()
 
 	m.Gw.limitHeaderFactory(newRes.Header).SendQuotas(ctxGetSession(r), m.Spec.APIID)
 	newRes.Header.Set(cachedResponseHeader, "1")
-
-	copyHeader(w.Header(), newRes.Header, m.Gw.GetConfig().IgnoreCanonicalMIMEHeaderKey)
+	removeUpstreamRequestID(r, newRes.Header)
 
 	if reqEtag := r.Header.Get("If-None-Match"); reqEtag != "" {
 		if respEtag := newRes.Header.Get("Etag"); respEtag != "" {
@@ -259,6 +281,9 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 		}
 	}
 
+	compressCachedResponse(m.Spec, newRes, r)
+	copyHeader(w.Header(), newRes.Header, m.Gw.GetConfig().IgnoreCanonicalMIMEHeaderKey)
+
 	w.WriteHeader(newRes.StatusCode)
 	if newRes.StatusCode != http.StatusNotModified {
 		_ = m.Proxy.CopyResponse(w, newRes.Body, 0)
@@ -288,3 +313,75 @@ func (m *RedisCacheMiddleware) getCacheKeyFromHeaders(r *http.Request) (key stri
 	}
 	return
 }
+
+// readCachedResponse decodes a cached wire format response, stripping hop-by-hop headers.
+func readCachedResponse(cachedData string, r *http.Request) (*http.Response, error) {
+	res, err := http.ReadResponse(bufio.NewReader(strings.NewReader(cachedData)), r)
+	if err != nil {
+		return nil, err
+	}
+
+	nopCloseResponseBody(res)
+	for _, h := range hopHeaders {
+		res.Header.Del(h)
+	}
+
+	return res, nil
+}
+
+// staleCachedResponse returns the expired cached response kept for the request, marked as stale.
+// It returns nil when there is no stale entry to fall back to.
+func staleCachedResponse(r *http.Request) *http.Response {
+	options := ctxGetCacheOptions(r)
+	if options == nil || options.stale == "" {
+		return nil
+	}
+
+	res, err := readCachedResponse(options.stale, r)
+	if err != nil {
+		log.WithError(err).Debug("Could not decode stale cached response")
+		return nil
+	}
+
+	res.Header.Set(cachedResponseHeader, "1")
+	res.Header.Set(staleResponseHeader, "stale")
+	res.Header.Set("Warning", `110 - "Response is Stale"`)
+	return res
+}
+
+// getVaryValues captures the request values of the headers allowed in the response Vary header.
+func (m *RedisCacheMiddleware) getVaryValues(r *http.Request) http.Header {
+	if len(m.Spec.CacheOptions.CacheVaryHeaders) == 0 {
+		return nil
+	}
+
+	values := make(http.Header, len(m.Spec.CacheOptions.CacheVaryHeaders))
+	for _, name := range m.Spec.CacheOptions.CacheVaryHeaders {
+		values[http.CanonicalHeaderKey(name)] = r.Header.Values(name)
+	}
+	return values
+}
+
+// cacheKeyPathSeparator delimits the request path in cache keys, it never appears in an escaped path.
+const cacheKeyPathSeparator = "|"
+
+// cacheKeyPathPrefix returns the start of the keys caching responses for the path, relative to the listen path.
+// Cached responses are invalidated by path pattern on it.
+func cacheKeyPathPrefix(apiID, path string) string {
+	return apiID + cacheKeyPathSeparator + path + cacheKeyPathSeparator
+}
+
+// cacheVaryIndexKey returns the key holding the headers the response cached under key varies on.
+// It shares the prefix of the cache key so that API cache invalidation removes it too.
+func cacheVaryIndexKey(key string) string {
+	return key + "-vary"
+}
+
+// cacheVariantKey returns the key of the response variant matching the request values of the varied headers.
+func cacheVariantKey(key string, varied []string, values http.Header) string {
+	h := md5.New()
+	for _, name := range varied {
+		io.WriteString(h, name+"="+strings.Join(values.Values(name), ",")+"\n")
+	}
+	return key + "-" + hex.EncodeToString(h.Sum(nil))
+}
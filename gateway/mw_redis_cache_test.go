@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"hash"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -557,3 +558,159 @@ func countAccessLogEntries(hook *logrustest.Hook) int {
 	}
 	return count
 }
+
+func TestRedisCacheMiddleware_vary(t *testing.T) {
+	var hits int64
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&hits, 1)
+		w.Header().Set("Vary", "Accept-Language, User-Agent")
+		_, _ = fmt.Fprintf(w, "lang=%s", r.Header.Get("Accept-Language"))
+	}))
+	defer upstream.Close()
+
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	api := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "vary-cache"
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.CacheOptions = apidef.CacheOptions{
+			CacheTimeout:         60,
+			EnableCache:          true,
+			CacheAllSafeRequests: true,
+			CacheVaryHeaders:     []string{"accept-language"},
+		}
+	})[0]
+
+	headerCache := map[string]string{cachedResponseHeader: "1"}
+	request := func(lang, userAgent string) test.TestCase {
+		return test.TestCase{
+			Path:      "/translated",
+			Headers:   map[string]string{"Accept-Language": lang, "User-Agent": userAgent},
+			BodyMatch: "lang=" + lang,
+			Delay:     50 * time.Millisecond,
+		}
+	}
+	cached := func(tc test.TestCase) test.TestCase {
+		tc.HeadersMatch = headerCache
+		return tc
+	}
+	notCached := func(tc test.TestCase) test.TestCase {
+		tc.HeadersNotMatch = headerCache
+		return tc
+	}
+
+	t.Run("varied header keys separate entries", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			notCached(request("en", "client-a")),
+			cached(request("en", "client-a")),
+			notCached(request("de", "client-a")),
+			cached(request("de", "client-a")),
+			cached(request("en", "client-a")),
+		}...)
+		assert.EqualValues(t, 2, atomic.LoadInt64(&hits))
+	})
+
+	t.Run("header outside the allowlist does not fragment the cache", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			cached(request("en", "client-b")),
+			cached(request("de", "client-c")),
+		}...)
+		assert.EqualValues(t, 2, atomic.LoadInt64(&hits))
+	})
+
+	t.Run("invalidation clears all variants", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Method: http.MethodDelete, Path: "/tyk/cache/" + api.APIID, AdminAuth: true, Code: http.StatusOK},
+			notCached(request("en", "client-a")),
+			notCached(request("de", "client-a")),
+		}...)
+		assert.EqualValues(t, 4, atomic.LoadInt64(&hits))
+	})
+}
+
+func TestResponseVaryHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Add("Vary", "accept-language, Accept-Encoding")
+	h.Add("Vary", "Origin, Accept-Language")
+
+	varied, cacheable := responseVaryHeaders(h, []string{"Origin", "Accept-Language"})
+	assert.True(t, cacheable)
+	assert.Equal(t, []string{"Accept-Language", "Origin"}, varied)
+
+	h.Set("Vary", "*")
+	_, cacheable = responseVaryHeaders(h, []string{"Origin"})
+	assert.False(t, cacheable)
+}
+
+func TestRedisCacheMiddleware_staleIfError(t *testing.T) {
+	var (
+		hits    int64
+		failing atomic.Bool
+	)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&hits, 1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte("fresh content"))
+	}))
+	defer upstream.Close()
+
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "stale-if-error"
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.CircuitBreakerEnabled = true
+		spec.CacheOptions = apidef.CacheOptions{
+			CacheTimeout:         2,
+			EnableCache:          true,
+			CacheAllSafeRequests: true,
+			StaleIfError:         60,
+		}
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.CircuitBreaker = []apidef.CircuitBreakerMeta{{
+				Path:                 "/resource",
+				Method:               http.MethodGet,
+				ThresholdPercent:     0.1,
+				Samples:              3,
+				ReturnToServiceAfter: 60,
+			}}
+		})
+	})
+
+	headerCache := map[string]string{cachedResponseHeader: "1"}
+	headerStale := map[string]string{staleResponseHeader: "stale"}
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Path: "/resource", Code: http.StatusOK, BodyMatch: "fresh content", HeadersNotMatch: headerCache, Delay: 50 * time.Millisecond},
+		{Path: "/resource", Code: http.StatusOK, HeadersMatch: headerCache, HeadersNotMatch: headerStale},
+	}...)
+
+	// Let the cached entry expire, expiry is tracked with second precision
+	time.Sleep(3 * time.Second)
+	failing.Store(true)
+
+	staleResponse := test.TestCase{
+		Path:         "/resource",
+		Code:         http.StatusOK,
+		BodyMatch:    "fresh content",
+		HeadersMatch: headerStale,
+	}
+
+	for i := 0; i < 5; i++ {
+		_, _ = ts.Run(t, staleResponse)
+	}
+
+	// The breaker is open by now, the stale entry is served without reaching the upstream
+	upstreamHits := atomic.LoadInt64(&hits)
+	assert.Less(t, upstreamHits, int64(6))
+
+	_, _ = ts.Run(t, staleResponse)
+	assert.Equal(t, upstreamHits, atomic.LoadInt64(&hits))
+}
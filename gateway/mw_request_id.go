@@ -0,0 +1,91 @@
+package gateway
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/ctx"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/uuid"
+)
+
+// maxRequestIDLength is the longest incoming request ID reused, longer ones are replaced by a generated ID.
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware sets the correlation ID of the request. The ID of a request looping from
+// another API or the ID sent by the client is reused, otherwise a UUID is generated. The ID is
+// sent to the upstream and returned to the client in the request ID header.
+type RequestIDMiddleware struct {
+	*BaseMiddleware
+}
+
+func (m *RequestIDMiddleware) Name() string {
+	return "RequestIDMiddleware"
+}
+
+func (m *RequestIDMiddleware) EnabledForSpec() bool {
+	return m.Spec.RequestID.Enabled || m.Gw.GetConfig().RequestID.Enabled
+}
+
+func (m *RequestIDMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	headerName := m.headerName()
+
+	var id string
+	if data := ctx.GetRequestID(r); data != nil {
+		id = data.ID
+	}
+
+	if id == "" {
+		id = strings.TrimSpace(r.Header.Get(headerName))
+	}
+
+	if id == "" || len(id) > maxRequestIDLength {
+		id = uuid.New()
+	}
+
+	r.Header.Set(headerName, id)
+	w.Header().Set(headerName, id)
+	ctx.SetRequestID(r, &ctx.RequestIDData{Header: headerName, ID: id})
+
+	return nil, http.StatusOK
+}
+
+// headerName returns the header carrying the request ID, the API setting takes precedence over the gateway one.
+func (m *RequestIDMiddleware) headerName() string {
+	if name := m.Spec.RequestID.HeaderName; name != "" {
+		return http.CanonicalHeaderKey(name)
+	}
+
+	if name := m.Gw.GetConfig().RequestID.HeaderName; name != "" {
+		return http.CanonicalHeaderKey(name)
+	}
+
+	return header.XRequestID
+}
+
+// ctxGetRequestID returns the correlation ID of the request, or an empty string if the request ID isn't enabled.
+func ctxGetRequestID(r *http.Request) string {
+	if data := ctx.GetRequestID(r); data != nil {
+		return data.ID
+	}
+
+	return ""
+}
+
+// requestIDTag returns the analytics tag holding the correlation ID of the request.
+func requestIDTag(r *http.Request) (string, bool) {
+	id := ctxGetRequestID(r)
+	if id == "" {
+		return "", false
+	}
+
+	return "request-id-" + id, true
+}
+
+// removeUpstreamRequestID removes the request ID header from an upstream or cached response,
+// so the client only gets the ID of its own request.
+func removeUpstreamRequestID(r *http.Request, h http.Header) {
+	if data := ctx.GetRequestID(r); data != nil {
+		h.Del(data.Header)
+	}
+}
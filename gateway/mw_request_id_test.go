@@ -0,0 +1,216 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/uuid"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+// upstreamRequestID returns the request ID the test upstream received in headerName, and the ID returned to the client.
+func upstreamRequestID(t *testing.T, resp *http.Response, headerName string) (upstream string, returned string) {
+	t.Helper()
+
+	var got TestHttpResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+
+	return got.Headers[http.CanonicalHeaderKey(headerName)], resp.Header.Get(headerName)
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "request-id"
+		spec.Proxy.ListenPath = "/request-id/"
+		spec.RequestID.Enabled = true
+	}, func(spec *APISpec) {
+		spec.APIID = "custom-header"
+		spec.Proxy.ListenPath = "/custom-header/"
+		spec.RequestID = apidef.RequestID{Enabled: true, HeaderName: "X-Correlation-ID"}
+	}, func(spec *APISpec) {
+		spec.APIID = "disabled"
+		spec.Proxy.ListenPath = "/disabled/"
+	})
+
+	t.Run("generated", func(t *testing.T) {
+		resp, _ := ts.Run(t, test.TestCase{Path: "/request-id/", Code: http.StatusOK})
+
+		upstream, returned := upstreamRequestID(t, resp, header.XRequestID)
+		assert.True(t, uuid.Valid(returned), "expected a generated UUID, got %q", returned)
+		assert.Equal(t, returned, upstream)
+	})
+
+	t.Run("passthrough", func(t *testing.T) {
+		resp, _ := ts.Run(t, test.TestCase{
+			Path:    "/request-id/",
+			Headers: map[string]string{header.XRequestID: "client-id"},
+			Code:    http.StatusOK,
+		})
+
+		upstream, returned := upstreamRequestID(t, resp, header.XRequestID)
+		assert.Equal(t, "client-id", returned)
+		assert.Equal(t, "client-id", upstream)
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		resp, _ := ts.Run(t, test.TestCase{
+			Path:    "/request-id/",
+			Headers: map[string]string{header.XRequestID: strings.Repeat("a", maxRequestIDLength+1)},
+			Code:    http.StatusOK,
+		})
+
+		_, returned := upstreamRequestID(t, resp, header.XRequestID)
+		assert.True(t, uuid.Valid(returned), "expected a generated UUID, got %q", returned)
+	})
+
+	t.Run("custom header", func(t *testing.T) {
+		resp, _ := ts.Run(t, test.TestCase{
+			Path:    "/custom-header/",
+			Headers: map[string]string{"X-Correlation-ID": "client-id"},
+			Code:    http.StatusOK,
+		})
+
+		upstream, returned := upstreamRequestID(t, resp, "X-Correlation-ID")
+		assert.Equal(t, "client-id", returned)
+		assert.Equal(t, "client-id", upstream)
+		assert.Empty(t, resp.Header.Get(header.XRequestID))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		resp, _ := ts.Run(t, test.TestCase{Path: "/disabled/", Code: http.StatusOK})
+
+		upstream, returned := upstreamRequestID(t, resp, header.XRequestID)
+		assert.Empty(t, returned)
+		assert.Empty(t, upstream)
+	})
+}
+
+func TestRequestIDMiddleware_GlobalDefault(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.RequestID.Enabled = true
+		globalConf.RequestID.HeaderName = "X-Trace-Ref"
+	})
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "global"
+		spec.Proxy.ListenPath = "/global/"
+	}, func(spec *APISpec) {
+		spec.APIID = "override"
+		spec.Proxy.ListenPath = "/override/"
+		spec.RequestID.HeaderName = "X-Correlation-ID"
+	})
+
+	resp, _ := ts.Run(t, test.TestCase{Path: "/global/", Code: http.StatusOK})
+	upstream, returned := upstreamRequestID(t, resp, "X-Trace-Ref")
+	assert.True(t, uuid.Valid(returned), "expected a generated UUID, got %q", returned)
+	assert.Equal(t, returned, upstream)
+
+	resp, _ = ts.Run(t, test.TestCase{
+		Path:    "/override/",
+		Headers: map[string]string{"X-Correlation-ID": "client-id"},
+		Code:    http.StatusOK,
+	})
+	upstream, returned = upstreamRequestID(t, resp, "X-Correlation-ID")
+	assert.Equal(t, "client-id", returned)
+	assert.Equal(t, "client-id", upstream)
+}
+
+func TestRequestIDMiddleware_InternalHop(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "internal"
+		spec.Name = "internal"
+		spec.Proxy.ListenPath = "/internal/"
+		spec.Internal = true
+		spec.RequestID = apidef.RequestID{Enabled: true, HeaderName: "X-Correlation-ID"}
+	}, func(spec *APISpec) {
+		spec.APIID = "public"
+		spec.Proxy.ListenPath = "/public/"
+		spec.Proxy.TargetURL = "tyk://internal"
+		spec.RequestID.Enabled = true
+	})
+
+	resp, _ := ts.Run(t, test.TestCase{Path: "/public/", Code: http.StatusOK})
+
+	var got TestHttpResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+
+	returned := resp.Header.Get(header.XRequestID)
+	assert.True(t, uuid.Valid(returned), "expected a generated UUID, got %q", returned)
+	assert.Equal(t, returned, got.Headers[http.CanonicalHeaderKey("X-Correlation-ID")], "the internal API should reuse the ID")
+	assert.Equal(t, returned, resp.Header.Get("X-Correlation-ID"))
+}
+
+func TestRequestIDMiddleware_ErrorTemplate(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "protected"
+		spec.Proxy.ListenPath = "/protected/"
+		spec.UseKeylessAccess = false
+		spec.RequestID.Enabled = true
+		spec.ErrorTemplates = map[string]apidef.ErrorTemplate{
+			apidef.ErrorTemplateDefault: {JSON: `{"request":"{{.RequestID}}","error":"{{.Message}}"}`},
+		}
+	})
+
+	headers := map[string]string{header.Accept: header.ApplicationJSON}
+
+	t.Run("generated", func(t *testing.T) {
+		resp, _ := ts.Run(t, test.TestCase{Path: "/protected/", Headers: headers, Code: http.StatusUnauthorized})
+
+		var body struct {
+			Request string `json:"request"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+		assert.True(t, uuid.Valid(body.Request), "expected a generated UUID, got %q", body.Request)
+		assert.Equal(t, body.Request, resp.Header.Get(header.XRequestID))
+	})
+
+	t.Run("passthrough", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{
+			Path:         "/protected/",
+			Headers:      map[string]string{header.Accept: header.ApplicationJSON, header.XRequestID: "client-id"},
+			Code:         http.StatusUnauthorized,
+			BodyMatch:    `^{"request":"client-id","error":"Authorization field missing"}$`,
+			HeadersMatch: map[string]string{header.XRequestID: "client-id"},
+		})
+	})
+}
+
+func TestRequestIDMiddleware_ContextVariable(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.EnableContextVars = true
+		spec.RequestID.Enabled = true
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.GlobalHeaders = map[string]string{"X-Echo-Request-Id": "$tyk_context.request_id"}
+		})
+	})
+
+	_, _ = ts.Run(t, test.TestCase{
+		Path:      "/",
+		Headers:   map[string]string{header.XRequestID: "client-id"},
+		Code:      http.StatusOK,
+		BodyMatch: `"X-Echo-Request-Id":"client-id"`,
+	})
+}
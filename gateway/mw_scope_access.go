@@ -0,0 +1,115 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/internal/httputil"
+)
+
+// sessionScopeMetaKey is the session metadata holding the scopes of the sessions not authenticated with a JWT,
+// as a space delimited string or an array. The OAuth access tokens record the scope granted to them in it.
+const sessionScopeMetaKey = "scope"
+
+// ScopeAccessMiddleware restricts the endpoints of the API to the tokens granted the scopes mapped to them.
+// It runs after the granular access checks, so a request has to be allowed by the allowed URLs of the key too.
+type ScopeAccessMiddleware struct {
+	*BaseMiddleware
+}
+
+func (m *ScopeAccessMiddleware) Name() string {
+	return "ScopeAccessMiddleware"
+}
+
+func (m *ScopeAccessMiddleware) EnabledForSpec() bool {
+	return !m.Spec.UseKeylessAccess && m.Spec.ScopeAccess.Enabled && len(m.Spec.ScopeAccess.ScopeToEndpoints) > 0
+}
+
+// ProcessRequest allows the request when the endpoint is mapped to no scope, or to one of the scopes of the token.
+func (m *ScopeAccessMiddleware) ProcessRequest(_ http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	if ctxGetRequestStatus(r) == StatusOkAndIgnore {
+		return nil, http.StatusOK
+	}
+
+	required := m.requiredScopes(r)
+	if len(required) == 0 {
+		return nil, http.StatusOK
+	}
+
+	scopes := m.tokenScopes(r)
+	for _, scope := range required {
+		if slices.Contains(scopes, scope) {
+			return nil, http.StatusOK
+		}
+	}
+
+	m.Logger().WithField("required_scopes", required).Info("Attempted access to an endpoint not granted by the token scopes.")
+
+	if len(required) == 1 {
+		return fmt.Errorf("Access to this resource requires the scope: %s", required[0]), http.StatusForbidden
+	}
+
+	return fmt.Errorf("Access to this resource requires one of the scopes: %s", strings.Join(required, ", ")), http.StatusForbidden
+}
+
+// requiredScopes returns the sorted scopes mapped to the endpoint of the request.
+func (m *ScopeAccessMiddleware) requiredScopes(r *http.Request) []string {
+	gwConfig := m.Gw.GetConfig()
+	isPrefixMatch := gwConfig.HttpServerOptions.EnablePathPrefixMatching
+	isSuffixMatch := gwConfig.HttpServerOptions.EnablePathSuffixMatching
+
+	urlPaths := []string{
+		m.Spec.StripListenPath(r.URL.Path),
+		r.URL.Path,
+	}
+
+	var required []string
+	for scope, endpoints := range m.Spec.ScopeAccess.ScopeToEndpoints {
+		for _, endpoint := range endpoints {
+			if len(endpoint.Methods) > 0 && !slices.Contains(endpoint.Methods, r.Method) {
+				continue
+			}
+
+			pattern := httputil.PreparePathRegexp(endpoint.Path, isPrefixMatch, isSuffixMatch)
+
+			match, err := httputil.MatchPaths(pattern, urlPaths)
+			if err != nil {
+				m.Logger().WithError(err).WithField("pattern", pattern).Error("error matching scope endpoint")
+				continue
+			}
+
+			if match {
+				required = append(required, scope)
+				break
+			}
+		}
+	}
+
+	slices.Sort(required)
+	return required
+}
+
+// tokenScopes returns the scopes of the JWT authenticating the request, or the scopes of its session.
+func (m *ScopeAccessMiddleware) tokenScopes(r *http.Request) []string {
+	if scopes, ok := ctxGetTokenScopes(r); ok {
+		return scopes
+	}
+
+	session := ctxGetSession(r)
+	if session == nil {
+		return nil
+	}
+
+	switch scopes := session.MetaData[sessionScopeMetaKey].(type) {
+	case string:
+		return strings.Fields(scopes)
+	case []string:
+		return scopes
+	case []interface{}:
+		return toScopeStringsSlice(scopes, nil, false)
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/internal/uuid"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestScopeAccess(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	scopeAccess := apidef.ScopeAccess{
+		Enabled: true,
+		ScopeToEndpoints: map[string][]apidef.ScopeEndpoint{
+			"orders:read":  {{Path: "/orders", Methods: []string{http.MethodGet}}},
+			"orders:write": {{Path: "/orders", Methods: []string{http.MethodPost}}, {Path: "/orders/{id}"}},
+			"orders:admin": {{Path: "/orders/{id}", Methods: []string{http.MethodDelete}}},
+		},
+	}
+
+	policyID := ts.CreatePolicy(func(p *user.Policy) {
+		p.AccessRights = map[string]user.AccessDefinition{
+			"jwt":    {APIID: "jwt", Versions: []string{"Default"}},
+			"nested": {APIID: "nested", Versions: []string{"Default"}},
+		}
+	})
+
+	jwtAPI := func(apiID, claimName string) func(spec *APISpec) {
+		return func(spec *APISpec) {
+			spec.APIID = apiID
+			spec.UseKeylessAccess = false
+			spec.EnableJWT = true
+			spec.JWTSigningMethod = RSASign
+			spec.JWTSource = base64.StdEncoding.EncodeToString([]byte(jwtRSAPubKey))
+			spec.JWTIdentityBaseField = "user_id"
+			spec.JWTDefaultPolicies = []string{policyID}
+			spec.Proxy.ListenPath = "/" + apiID + "/"
+			spec.ScopeAccess = scopeAccess
+			spec.ScopeAccess.ClaimName = claimName
+		}
+	}
+
+	ts.Gw.BuildAndLoadAPI(
+		jwtAPI("jwt", ""),
+		jwtAPI("nested", "permissions.scopes"),
+		func(spec *APISpec) {
+			spec.APIID = "key"
+			spec.UseKeylessAccess = false
+			spec.Proxy.ListenPath = "/key/"
+			spec.ScopeAccess = scopeAccess
+		},
+	)
+
+	token := func(claims jwt.MapClaims) map[string]string {
+		return map[string]string{"authorization": CreateJWKToken(func(t *jwt.Token) {
+			t.Claims.(jwt.MapClaims)["user_id"] = "user-" + uuid.New()
+			t.Claims.(jwt.MapClaims)["exp"] = time.Now().Add(time.Hour).Unix()
+			for name, value := range claims {
+				t.Claims.(jwt.MapClaims)[name] = value
+			}
+		})}
+	}
+
+	t.Run("JWT", func(t *testing.T) {
+		read := token(jwt.MapClaims{"scope": "orders:read"})
+		readWrite := token(jwt.MapClaims{"scope": "orders:read  orders:write"})
+		array := token(jwt.MapClaims{"scope": []interface{}{"orders:read", "orders:admin"}})
+		none := token(nil)
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/jwt/orders", Headers: read, Code: http.StatusOK},
+			{Path: "/jwt/orders", Method: http.MethodPost, Headers: read, Code: http.StatusForbidden,
+				BodyMatch: `requires the scope: orders:write`},
+			{Path: "/jwt/orders/1", Method: http.MethodDelete, Headers: read, Code: http.StatusForbidden,
+				BodyMatch: `requires one of the scopes: orders:admin, orders:write`},
+			{Path: "/jwt/health", Headers: read, Code: http.StatusOK},
+
+			{Path: "/jwt/orders", Headers: readWrite, Code: http.StatusOK},
+			{Path: "/jwt/orders", Method: http.MethodPost, Headers: readWrite, Code: http.StatusOK},
+			{Path: "/jwt/orders/1", Method: http.MethodPut, Headers: readWrite, Code: http.StatusOK},
+			{Path: "/jwt/orders/1", Method: http.MethodDelete, Headers: readWrite, Code: http.StatusOK},
+
+			{Path: "/jwt/orders", Headers: array, Code: http.StatusOK},
+			{Path: "/jwt/orders/1", Method: http.MethodDelete, Headers: array, Code: http.StatusOK},
+			{Path: "/jwt/orders/1", Method: http.MethodPut, Headers: array, Code: http.StatusForbidden,
+				BodyMatch: `requires the scope: orders:write`},
+
+			{Path: "/jwt/orders", Headers: none, Code: http.StatusForbidden, BodyMatch: `requires the scope: orders:read`},
+			{Path: "/jwt/health", Headers: none, Code: http.StatusOK},
+		}...)
+	})
+
+	t.Run("JWT nested claim", func(t *testing.T) {
+		nested := token(jwt.MapClaims{"permissions": map[string]interface{}{"scopes": []interface{}{"orders:write"}}})
+		scopeClaim := token(jwt.MapClaims{"scope": "orders:write"})
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/nested/orders", Method: http.MethodPost, Headers: nested, Code: http.StatusOK},
+			{Path: "/nested/orders", Headers: nested, Code: http.StatusForbidden, BodyMatch: `requires the scope: orders:read`},
+			{Path: "/nested/orders", Method: http.MethodPost, Headers: scopeClaim, Code: http.StatusForbidden,
+				BodyMatch: `requires the scope: orders:write`},
+		}...)
+	})
+
+	t.Run("session metadata", func(t *testing.T) {
+		key := func(scope interface{}, allowedURLs ...user.AccessSpec) map[string]string {
+			_, key := ts.CreateSession(func(s *user.SessionState) {
+				s.AccessRights = map[string]user.AccessDefinition{"key": {
+					APIID: "key", Versions: []string{"Default"}, AllowedURLs: allowedURLs,
+				}}
+				s.MetaData = map[string]interface{}{sessionScopeMetaKey: scope}
+			})
+
+			return map[string]string{"authorization": key}
+		}
+
+		read := key("orders:read")
+		array := key([]string{"orders:read", "orders:write"})
+		acl := key("orders:read orders:write", user.AccessSpec{URL: "/orders", Methods: []string{http.MethodGet}})
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/key/orders", Headers: read, Code: http.StatusOK},
+			{Path: "/key/orders", Method: http.MethodPost, Headers: read, Code: http.StatusForbidden,
+				BodyMatch: `requires the scope: orders:write`},
+			{Path: "/key/orders", Method: http.MethodPost, Headers: array, Code: http.StatusOK},
+
+			// the allowed URLs of the key apply too
+			{Path: "/key/orders", Headers: acl, Code: http.StatusOK},
+			{Path: "/key/orders", Method: http.MethodPost, Headers: acl, Code: http.StatusForbidden,
+				BodyMatch: `Access to this resource has been disallowed`},
+		}...)
+	})
+}
+
+func TestScopeAccess_OAuth(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	spec := ts.Gw.LoadAPI(buildTestOAuthSpec(func(spec *APISpec) {
+		spec.ScopeAccess = apidef.ScopeAccess{
+			Enabled: true,
+			ScopeToEndpoints: map[string][]apidef.ScopeEndpoint{
+				"orders:read":  {{Path: "/orders", Methods: []string{http.MethodGet}}},
+				"orders:write": {{Path: "/orders", Methods: []string{http.MethodPost}}},
+			},
+		}
+	}))[0]
+
+	policyID := ts.CreatePolicy(func(p *user.Policy) {
+		p.AccessRights = map[string]user.AccessDefinition{
+			spec.APIID: {APIID: spec.APIID, Versions: []string{"Default"}},
+		}
+	})
+
+	client := OAuthClient{
+		ClientID:          authClientID,
+		ClientSecret:      authClientSecret,
+		ClientRedirectURI: authRedirectUri,
+		PolicyID:          policyID,
+	}
+	require.NoError(t, spec.OAuthManager.Storage().SetClient(client.ClientID, spec.OrgID, &client, false))
+
+	param := make(url.Values)
+	param.Set("grant_type", "client_credentials")
+	param.Set("client_id", authClientID)
+	param.Set("client_secret", authClientSecret)
+	param.Set("scope", "orders:read")
+
+	resp, err := ts.Run(t, test.TestCase{
+		Path:   "/APIID/oauth/token/",
+		Method: http.MethodPost,
+		Data:   param.Encode(),
+		Headers: map[string]string{
+			"Content-Type":  "application/x-www-form-urlencoded",
+			"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(authClientID+":"+authClientSecret)),
+		},
+		Code: http.StatusOK,
+	})
+	require.NoError(t, err)
+
+	var tokens tokenData
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+
+	headers := map[string]string{"authorization": "Bearer " + tokens.AccessToken}
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Path: "/APIID/orders", Headers: headers, Code: http.StatusOK},
+		{Path: "/APIID/orders", Method: http.MethodPost, Headers: headers, Code: http.StatusForbidden,
+			BodyMatch: `requires the scope: orders:write`},
+	}...)
+}
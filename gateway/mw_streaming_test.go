@@ -9,8 +9,11 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"runtime"
 	"strings"
 	"sync"
 	"testing"
@@ -1023,6 +1026,164 @@ func TestStreamingAPIGarbageCollection(t *testing.T) {
 	require.Equal(t, 0, streamManagersAfterGC)
 }
 
+const bentoEventSourceTemplate = `
+streams:
+  test:
+    input:
+      http_server:
+        path: /post
+        timeout: 1s
+    output:
+      http_server:
+        stream_path: /get/stream
+        stream_format: event_source
+`
+
+// startStreamMiddleware serves the streams of an API through a middleware held by the test.
+func startStreamMiddleware(t *testing.T, ts *Test, apiName string, streamConfig string) (*streams.Middleware, string) {
+	t.Helper()
+
+	oasAPI, err := setupOASForStreamAPI(streamConfig)
+	require.NoError(t, err)
+
+	specs := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = fmt.Sprintf("/%s", apiName)
+		spec.UseKeylessAccess = true
+		spec.IsOAS = true
+		spec.OAS = oasAPI
+		spec.OAS.Fill(*spec.APIDefinition)
+	})
+
+	apiSpec := streams.NewAPISpec(specs[0].APIID, specs[0].Name, specs[0].IsOAS, specs[0].OAS, specs[0].StripListenPath)
+	s := streams.NewMiddleware(ts.Gw, &DummyBase{}, apiSpec, nil)
+	s.Init()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err, code := s.ProcessRequest(w, r, nil); err != nil {
+			http.Error(w, err.Error(), code)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return s, fmt.Sprintf("%s/%s", srv.URL, apiName)
+}
+
+func waitForStreamConsumers(t *testing.T, s *streams.Middleware, consumers int64) {
+	t.Helper()
+
+	assert.Eventually(t, func() bool {
+		stream := s.Metrics().Streams["test"]
+		return stream.WebSocketConsumers+stream.SSEConsumers == consumers
+	}, 3*time.Second, 10*time.Millisecond, "consumers not connected")
+}
+
+func TestStreamingAPIGarbageCollection_ConcurrentConsumers(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.Streaming.Enabled = true
+	})
+	t.Cleanup(ts.Close)
+
+	s, apiURL := startStreamMiddleware(t, ts, "gc-race-api", bentoHTTPServerTemplate)
+	t.Cleanup(s.Unload)
+
+	stopGC := make(chan struct{})
+	gcDone := make(chan struct{})
+	go func() {
+		defer close(gcDone)
+		for {
+			select {
+			case <-stopGC:
+				return
+			default:
+				s.GC()
+				runtime.Gosched()
+			}
+		}
+	}()
+	defer func() {
+		close(stopGC)
+		<-gcDone
+	}()
+
+	dialer := websocket.Dialer{HandshakeTimeout: 1 * time.Second}
+	wsURL := strings.Replace(apiURL, "http", "ws", 1) + "/subscribe"
+
+	// every round connects a consumer while GC may be removing the stream manager of the previous one
+	const rounds = 10
+	for i := 0; i < rounds; i++ {
+		wsConn, _, err := dialer.Dial(wsURL, nil)
+		require.NoError(t, err, "failed to connect to ws server")
+
+		message := fmt.Sprintf(`{"round": %d}`, i)
+		resp, err := http.Post(apiURL+"/post", "application/json", strings.NewReader(message))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		require.NoError(t, wsConn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		_, p, err := wsConn.ReadMessage()
+		require.NoError(t, err, "consumer was closed while connected")
+		assert.Equal(t, message, string(p))
+
+		require.NoError(t, wsConn.Close())
+	}
+}
+
+func TestStreamingAPIRemoval_ClosesConsumers(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.Streaming.Enabled = true
+	})
+	t.Cleanup(ts.Close)
+
+	t.Run("websocket consumers get a close frame", func(t *testing.T) {
+		s, apiURL := startStreamMiddleware(t, ts, "removal-ws-api", bentoHTTPServerTemplate)
+
+		dialer := websocket.Dialer{HandshakeTimeout: 1 * time.Second}
+		wsConn, _, err := dialer.Dial(strings.Replace(apiURL, "http", "ws", 1)+"/subscribe", nil)
+		require.NoError(t, err, "failed to connect to ws server")
+		t.Cleanup(func() {
+			_ = wsConn.Close()
+		})
+
+		waitForStreamConsumers(t, s, 1)
+		s.Unload()
+
+		require.NoError(t, wsConn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		_, _, err = wsConn.ReadMessage()
+
+		var closeErr *websocket.CloseError
+		require.ErrorAs(t, err, &closeErr)
+		assert.Equal(t, websocket.CloseGoingAway, closeErr.Code)
+	})
+
+	t.Run("event source consumers get a close event", func(t *testing.T) {
+		s, apiURL := startStreamMiddleware(t, ts, "removal-sse-api", bentoEventSourceTemplate)
+
+		body := make(chan string, 1)
+		go func() {
+			resp, err := http.Get(apiURL + "/get/stream")
+			if err != nil {
+				body <- err.Error()
+				return
+			}
+			defer resp.Body.Close()
+
+			data, _ := io.ReadAll(resp.Body)
+			body <- string(data)
+		}()
+
+		waitForStreamConsumers(t, s, 1)
+		s.Unload()
+
+		select {
+		case data := <-body:
+			assert.True(t, strings.HasSuffix(data, "event: close\ndata: stream removed\n\n"), "unexpected event stream: %q", data)
+		case <-time.After(10 * time.Second):
+			t.Fatal("event stream wasn't ended")
+		}
+	})
+}
+
 func TestStreaming_HttpOutputPaths(t *testing.T) {
 	ts := StartTest(func(globalConf *config.Config) {
 		globalConf.Streaming.Enabled = true
@@ -1093,6 +1254,204 @@ func TestStreaming_HttpOutputPaths(t *testing.T) {
 	})
 }
 
+func TestStreaming_Metrics(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.Streaming.Enabled = true
+	})
+	t.Cleanup(ts.Close)
+
+	apiName := "metrics-test-api"
+	require.NoError(t, setUpStreamAPI(ts, apiName, bentoHTTPServerTemplate))
+
+	const totalMessages = 3
+
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 1 * time.Second,
+		TLSClientConfig:  &tls.Config{InsecureSkipVerify: true},
+	}
+
+	wsURL := strings.Replace(ts.URL, "http", "ws", 1) + fmt.Sprintf("/%s/subscribe", apiName)
+	wsConn, _, err := dialer.Dial(wsURL, nil)
+	require.NoError(t, err, "failed to connect to ws server")
+	t.Cleanup(func() {
+		_ = wsConn.Close()
+	})
+
+	publishURL := fmt.Sprintf("%s/%s/post", ts.URL, apiName)
+	for i := 0; i < totalMessages; i++ {
+		data := []byte(fmt.Sprintf("{\"test\": \"message %d\"}", i))
+		resp, err := http.Post(publishURL, "application/json", bytes.NewReader(data))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	require.NoError(t, wsConn.SetReadDeadline(time.Now().Add(500*time.Millisecond)))
+	for i := 0; i < totalMessages; i++ {
+		_, _, err := wsConn.ReadMessage()
+		require.NoError(t, err, "error reading message")
+	}
+
+	metricsPath := fmt.Sprintf("/%s%s", apiName, streams.MetricsPath)
+	secretHeader := map[string]string{"X-Tyk-Authorization": ts.Gw.GetConfig().Secret}
+
+	t.Run("requires the gateway secret", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: metricsPath, Code: http.StatusForbidden},
+			{Path: metricsPath, Headers: map[string]string{"X-Tyk-Authorization": "wrong"}, Code: http.StatusForbidden},
+			{Path: metricsPath, Method: http.MethodPost, Headers: secretHeader, Code: http.StatusMethodNotAllowed},
+		}...)
+	})
+
+	t.Run("reports the counters of the stream", func(t *testing.T) {
+		var metrics streams.StreamsMetrics
+		assert.Eventually(t, func() bool {
+			resp, err := ts.Run(t, test.TestCase{Path: metricsPath, Headers: secretHeader, Code: http.StatusOK})
+			if err != nil {
+				return false
+			}
+			defer resp.Body.Close()
+
+			metrics = streams.StreamsMetrics{}
+			return json.NewDecoder(resp.Body).Decode(&metrics) == nil &&
+				metrics.Streams["test"].OutputMessages == totalMessages
+		}, 3*time.Second, 50*time.Millisecond, "output messages not counted")
+
+		assert.NotEmpty(t, metrics.APIID)
+
+		stream := metrics.Streams["test"]
+		assert.EqualValues(t, totalMessages, stream.InputMessages)
+		assert.EqualValues(t, totalMessages, stream.OutputMessages)
+		assert.Zero(t, stream.Errors)
+		assert.EqualValues(t, 1, stream.WebSocketConsumers)
+		assert.Zero(t, stream.SSEConsumers)
+		require.NotNil(t, stream.LastActivity)
+		assert.WithinDuration(t, time.Now(), *stream.LastActivity, time.Minute)
+	})
+}
+
+const bentoNatsTenantTemplate = `
+streams:
+  tenant:
+    input:
+      nats:
+        urls: ["%s"]
+        subject: "tenant.$tyk_context.headers_X_Tenant"
+    output:
+      http_server:
+        ws_path: /subscribe
+`
+
+func startNATSServer(t *testing.T) string {
+	t.Helper()
+	ctx := context.Background()
+
+	natsContainer, err := natscon.Run(
+		ctx,
+		"nats:2.9",
+		testcontainers.WithWaitStrategy(wait.ForAll(
+			wait.ForLog("Server is ready"),
+			wait.ForListeningPort("4222/tcp"),
+		).WithDeadline(30*time.Second)))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = natsContainer.Terminate(ctx)
+	})
+
+	connectionStr, err := natsContainer.ConnectionString(ctx)
+	require.NoError(t, err)
+
+	return connectionStr
+}
+
+func TestStreaming_ContextVariablesPerTenant(t *testing.T) {
+	natsURL := startNATSServer(t)
+
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.Streaming.Enabled = true
+	})
+	t.Cleanup(ts.Close)
+
+	oasAPI, err := setupOASForStreamAPI(fmt.Sprintf(bentoNatsTenantTemplate, natsURL))
+	require.NoError(t, err)
+
+	apiName := "tenant-streams-api"
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = fmt.Sprintf("/%s", apiName)
+		spec.UseKeylessAccess = true
+		spec.IsOAS = true
+		spec.OAS = oasAPI
+		spec.OAS.Fill(*spec.APIDefinition)
+		spec.EnableContextVars = true
+	})
+
+	nc, err := nats.Connect(natsURL)
+	require.NoError(t, err)
+	t.Cleanup(nc.Close)
+
+	dialer := websocket.Dialer{HandshakeTimeout: 1 * time.Second}
+	wsURL := strings.Replace(ts.URL, "http", "ws", 1) + fmt.Sprintf("/%s/subscribe", apiName)
+
+	subscribe := func(tenant string) <-chan string {
+		wsConn, _, err := dialer.Dial(wsURL, http.Header{"X-Tenant": []string{tenant}})
+		require.NoError(t, err, "failed to connect to ws server")
+		t.Cleanup(func() {
+			_ = wsConn.Close()
+		})
+
+		messages := make(chan string, 100)
+		go func() {
+			defer close(messages)
+			for {
+				_, p, err := wsConn.ReadMessage()
+				if err != nil {
+					return
+				}
+				messages <- string(p)
+			}
+		}()
+
+		return messages
+	}
+
+	tenants := map[string]<-chan string{
+		"acme":   subscribe("acme"),
+		"globex": subscribe("globex"),
+	}
+
+	// messages published before the stream of a tenant subscribed to its subject are lost, so publish until received
+	for tenant, messages := range tenants {
+		message := "message for " + tenant
+		assert.Eventually(t, func() bool {
+			require.NoError(t, nc.Publish("tenant."+tenant, []byte(message)))
+
+			select {
+			case received := <-messages:
+				return assert.Equal(t, message, received)
+			case <-time.After(100 * time.Millisecond):
+				return false
+			}
+		}, 5*time.Second, 10*time.Millisecond, "tenant %s didn't receive its message", tenant)
+	}
+
+	for tenant := range tenants {
+		require.NoError(t, nc.Publish("tenant."+tenant, []byte("message for "+tenant)))
+	}
+	require.NoError(t, nc.Flush())
+
+	for tenant, messages := range tenants {
+		timeout := time.After(500 * time.Millisecond)
+	drain:
+		for {
+			select {
+			case received := <-messages:
+				assert.Equal(t, "message for "+tenant, received, "tenant %s received a message of another tenant", tenant)
+			case <-timeout:
+				break drain
+			}
+		}
+	}
+}
+
 type testStreamSSEClient struct {
 	ctx    context.Context
 	url    string
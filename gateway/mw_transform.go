@@ -3,15 +3,18 @@ package gateway
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 
 	"github.com/clbanning/mxj"
 	"golang.org/x/net/html/charset"
 
 	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
 )
 
 func WrappedCharsetReader(s string, i io.Reader) (io.Reader, error) {
@@ -44,17 +47,79 @@ func (t *TransformMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Requ
 	if !found {
 		return nil, http.StatusOK
 	}
-	err := transformBody(r, meta.(*TransformSpec), t)
+	tmeta := meta.(*TransformSpec)
+
+	if tmeta.Format == apidef.TransformFormatJQ {
+		if err := transformBodyJQ(r, tmeta, t); err != nil {
+			t.Logger().WithError(err).Error("Body transform failure")
+
+			switch {
+			case errors.Is(err, errTransformJQInputTooLarge):
+				return errors.New("Request is too large"), http.StatusBadRequest
+			case errors.Is(err, errTransformJQInput):
+				return errors.New("Request body could not be transformed"), http.StatusBadRequest
+			}
+			return errors.New(http.StatusText(http.StatusInternalServerError)), http.StatusInternalServerError
+		}
+
+		t.Logger().Debugf("%s", msgBodyTransformed)
+		return nil, http.StatusOK
+	}
+
+	err := transformBody(r, tmeta, t)
 
 	if err != nil {
 		t.Logger().WithError(err).Error("Body transform failure")
+		if errors.Is(err, apidef.ErrMalformedXML) {
+			return fmt.Errorf("Request body could not be transformed: %w", err), http.StatusBadRequest
+		}
 	} else {
+		setSOAPHeaders(r, tmeta.TemplateData.SOAP)
 		t.Logger().Debugf("%s", msgBodyTransformed)
 	}
 
 	return nil, http.StatusOK
 }
 
+// setSOAPHeaders sets the Content-Type and SOAP action headers of a request transformed into a SOAP envelope.
+func setSOAPHeaders(r *http.Request, soap *apidef.TemplateSOAP) {
+	if soap == nil || !soap.Enabled {
+		return
+	}
+
+	if soap.Version == apidef.SOAPVersion12 {
+		contentType := header.ApplicationSoapXML + "; charset=utf-8"
+		if soap.Action != "" {
+			contentType += "; action=" + strconv.Quote(soap.Action)
+		}
+
+		r.Header.Set(header.ContentType, contentType)
+		r.Header.Del(header.SOAPAction)
+		return
+	}
+
+	r.Header.Set(header.ContentType, header.TextXML+"; charset=utf-8")
+	r.Header.Set(header.SOAPAction, strconv.Quote(soap.Action))
+}
+
+// transformBodyJQ replaces the request body with the output of the JQ expression of the transform.
+func transformBodyJQ(r *http.Request, tmeta *TransformSpec, t *TransformMiddleware) error {
+	body, _ := io.ReadAll(t.Gw.transformJQInputReader(r.Body))
+	defer r.Body.Close()
+
+	out, err := t.Gw.transformJQ(r, t.Spec, tmeta, body)
+	if err != nil {
+		return err
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(out))
+
+	r.ContentLength = int64(len(out))
+	nopCloseRequestBody(r)
+
+	return nil
+}
+
 func transformBody(r *http.Request, tmeta *TransformSpec, t *TransformMiddleware) error {
 	body, _ := ioutil.ReadAll(r.Body)
 	defer r.Body.Close()
@@ -71,7 +136,7 @@ func transformBody(r *http.Request, tmeta *TransformSpec, t *TransformMiddleware
 		var err error
 		bodyData, err = mxj.NewMapXml(body) // unmarshal
 		if err != nil {
-			return fmt.Errorf("error unmarshalling XML: %w", err)
+			return fmt.Errorf("error unmarshalling XML: %w: %v", apidef.ErrMalformedXML, err)
 		}
 	case apidef.RequestJSON:
 		if len(body) == 0 {
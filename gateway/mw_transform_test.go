@@ -2,12 +2,18 @@ package gateway
 
 import (
 	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
 	"io/ioutil"
 	"net/http"
 	"strings"
 	"testing"
 	texttemplate "text/template"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/test"
 
 	"github.com/TykTechnologies/tyk/apidef"
@@ -390,3 +396,102 @@ func TestTransformRequestBody(t *testing.T) {
 		_, _ = ts.Run(t, test.TestCase{Path: "/get", Data: body, BodyNotMatch: bodyMatch, Code: http.StatusOK})
 	})
 }
+
+func TestTransformRequestBody_SOAP(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	tmpl := `{{ soapEnvelope (dict "Add" (dict "intA" .a "intB" .b "-id" .id)) "xmlns=http://tempuri.org/" }}`
+
+	loadAPI := func(soap *apidef.TemplateSOAP) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/"
+			UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+				v.ExtendedPaths.Transform = []apidef.TemplateMeta{{
+					Path:   "/add",
+					Method: http.MethodPost,
+					TemplateData: apidef.TemplateData{
+						Input:          apidef.RequestJSON,
+						Mode:           apidef.UseBlob,
+						TemplateSource: base64.StdEncoding.EncodeToString([]byte(tmpl)),
+						SOAP:           soap,
+					},
+				}}
+			})
+		})
+	}
+
+	var envelope struct {
+		XMLName xml.Name `xml:"http://schemas.xmlsoap.org/soap/envelope/ Envelope"`
+		Body    struct {
+			Add struct {
+				ID   string `xml:"id,attr"`
+				IntA int    `xml:"intA"`
+				IntB int    `xml:"intB"`
+			} `xml:"http://tempuri.org/ Add"`
+		} `xml:"http://schemas.xmlsoap.org/soap/envelope/ Body"`
+	}
+
+	upstreamRequest := func(t *testing.T) TestHttpResponse {
+		t.Helper()
+		resp, err := ts.Run(t, test.TestCase{
+			Method: http.MethodPost, Path: "/add", Data: `{"a": 2, "b": 3, "id": "op<1>"}`, Code: http.StatusOK,
+		})
+		require.NoError(t, err)
+
+		var upstream TestHttpResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&upstream))
+		return upstream
+	}
+
+	t.Run("SOAP 1.1", func(t *testing.T) {
+		loadAPI(&apidef.TemplateSOAP{Enabled: true, Action: "http://tempuri.org/Add"})
+
+		upstream := upstreamRequest(t)
+		assert.Equal(t, "text/xml; charset=utf-8", upstream.Headers[header.ContentType])
+		assert.Equal(t, `"http://tempuri.org/Add"`, upstream.Headers[http.CanonicalHeaderKey(header.SOAPAction)])
+
+		require.NoError(t, xml.Unmarshal([]byte(upstream.Body), &envelope))
+		assert.Equal(t, "op<1>", envelope.Body.Add.ID)
+		assert.Equal(t, 2, envelope.Body.Add.IntA)
+		assert.Equal(t, 3, envelope.Body.Add.IntB)
+	})
+
+	t.Run("SOAP 1.2", func(t *testing.T) {
+		loadAPI(&apidef.TemplateSOAP{Enabled: true, Version: apidef.SOAPVersion12, Action: "http://tempuri.org/Add"})
+
+		upstream := upstreamRequest(t)
+		assert.Equal(t, `application/soap+xml; charset=utf-8; action="http://tempuri.org/Add"`, upstream.Headers[header.ContentType])
+		assert.Empty(t, upstream.Headers[http.CanonicalHeaderKey(header.SOAPAction)])
+	})
+
+	t.Run("headers disabled", func(t *testing.T) {
+		loadAPI(nil)
+
+		upstream := upstreamRequest(t)
+		assert.NotContains(t, upstream.Headers[header.ContentType], "xml")
+		assert.Empty(t, upstream.Headers[http.CanonicalHeaderKey(header.SOAPAction)])
+	})
+
+	t.Run("malformed XML", func(t *testing.T) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/"
+			UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+				v.ExtendedPaths.Transform = []apidef.TemplateMeta{{
+					Path:   "/add",
+					Method: http.MethodPost,
+					TemplateData: apidef.TemplateData{
+						Input:          apidef.RequestXML,
+						Mode:           apidef.UseBlob,
+						TemplateSource: base64.StdEncoding.EncodeToString([]byte(`{{ jsonMarshal . }}`)),
+					},
+				}}
+			})
+		})
+
+		_, _ = ts.Run(t, test.TestCase{
+			Method: http.MethodPost, Path: "/add", Data: `<Add><intA>2</Add>`, Code: http.StatusBadRequest,
+			BodyMatch: `Request body could not be transformed: error unmarshalling XML: malformed XML`,
+		})
+	})
+}
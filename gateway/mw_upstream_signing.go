@@ -0,0 +1,243 @@
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/httputil"
+	"github.com/TykTechnologies/tyk/internal/model"
+	"github.com/TykTechnologies/tyk/internal/service/core"
+)
+
+const (
+	// unsignedPayload replaces the SigV4 payload hash of the streamed request bodies.
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	// amzContentSha256 is the header carrying the SigV4 payload hash.
+	amzContentSha256 = "X-Amz-Content-Sha256"
+)
+
+// errUnbufferedBody is returned when a streamed request body can't be covered by the upstream signature.
+var errUnbufferedBody = errors.New("the streamed request body can't be signed for the upstream")
+
+// defaultUpstreamHMACHeaders are the headers signed when no list is configured, the digest covers the body.
+var defaultUpstreamHMACHeaders = []string{"(request-target)", "host", "date", "digest"}
+
+// UpstreamSigning signs the requests sent to the upstream with AWS Signature Version 4 or an HMAC.
+// The signature is computed by the proxy on the outbound request, after all the transformations.
+type UpstreamSigning struct {
+	*BaseMiddleware
+
+	signer upstreamSigner
+	err    error
+}
+
+// upstreamSigner signs the outbound requests, it's stored as the upstream auth provider of the request.
+type upstreamSigner interface {
+	model.UpstreamAuthProvider
+	model.UpstreamAuthSigner
+}
+
+func (s *UpstreamSigning) Name() string {
+	return "UpstreamSigning"
+}
+
+func (s *UpstreamSigning) EnabledForSpec() bool {
+	upstreamAuth := s.Spec.UpstreamAuth
+	return upstreamAuth.Enabled && (upstreamAuth.AWSSigV4.Enabled || upstreamAuth.HMACSigning.Enabled)
+}
+
+// Init resolves the signing credentials from the KV stores and validates the configuration.
+func (s *UpstreamSigning) Init() {
+	if sigV4 := s.Spec.UpstreamAuth.AWSSigV4; sigV4.Enabled {
+		s.resolveSecrets(&sigV4.AccessKeyID, &sigV4.SecretAccessKey, &sigV4.SessionToken)
+		if sigV4.Region == "" || sigV4.Service == "" || sigV4.AccessKeyID == "" || sigV4.SecretAccessKey == "" {
+			s.err = errors.New("AWS SigV4 upstream signing requires the region, service, access key ID and secret access key")
+		}
+
+		s.signer = &awsSigV4Signer{conf: sigV4, logger: s.Logger(), now: time.Now}
+		return
+	}
+
+	hmacSigning := s.Spec.UpstreamAuth.HMACSigning
+	s.resolveSecrets(&hmacSigning.Secret)
+	if hmacSigning.Algorithm == "" {
+		hmacSigning.Algorithm = "hmac-sha256"
+	}
+	if len(hmacSigning.Headers) == 0 {
+		hmacSigning.Headers = defaultUpstreamHMACHeaders
+	}
+
+	switch {
+	case hmacSigning.KeyID == "" || hmacSigning.Secret == "":
+		s.err = errors.New("HMAC upstream signing requires the key ID and secret")
+	case !strings.HasPrefix(hmacSigning.Algorithm, "hmac-") || !slices.Contains(supportedAlgorithms, hmacSigning.Algorithm):
+		s.err = errors.New("HMAC upstream signing algorithm is not supported")
+	}
+
+	s.signer = &hmacSigner{conf: hmacSigning, logger: s.Logger(), now: time.Now}
+}
+
+func (s *UpstreamSigning) resolveSecrets(values ...*string) {
+	for _, value := range values {
+		resolved, err := s.Gw.kvStore(*value)
+		if err != nil {
+			s.Logger().WithError(err).Error("Couldn't resolve the upstream signing credentials")
+			continue
+		}
+		*value = resolved
+	}
+}
+
+// ProcessRequest sets the signer as the upstream auth provider, the request is signed by the proxy.
+func (s *UpstreamSigning) ProcessRequest(_ http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	if s.err != nil {
+		s.Logger().WithError(s.err).Error("Upstream signing is misconfigured")
+		return s.err, http.StatusInternalServerError
+	}
+
+	core.SetUpstreamAuth(r, s.signer)
+	return nil, http.StatusOK
+}
+
+// awsSigV4Signer signs the requests with AWS Signature Version 4.
+type awsSigV4Signer struct {
+	conf   apidef.UpstreamAWSSigV4
+	logger *logrus.Entry
+	now    func() time.Time
+}
+
+// Fill signs the request, logging the failures.
+func (a *awsSigV4Signer) Fill(r *http.Request) {
+	if err := a.Sign(r); err != nil {
+		a.logger.WithError(err).Error("Couldn't sign the upstream request")
+	}
+}
+
+// Sign signs the request, the streamed bodies are left out of the signature with an unsigned payload.
+func (a *awsSigV4Signer) Sign(r *http.Request) error {
+	payloadHash := unsignedPayload
+
+	body, err := upstreamSignedBody(r)
+	switch {
+	case errors.Is(err, errUnbufferedBody):
+		r.Header.Set(amzContentSha256, unsignedPayload)
+	case err != nil:
+		return err
+	default:
+		hash := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(hash[:])
+	}
+
+	credentials := aws.Credentials{
+		AccessKeyID:     a.conf.AccessKeyID,
+		SecretAccessKey: a.conf.SecretAccessKey,
+		SessionToken:    a.conf.SessionToken,
+	}
+
+	return v4.NewSigner().SignHTTP(context.Background(), credentials, r, payloadHash, a.conf.Service, a.conf.Region, a.now())
+}
+
+// hmacSigner signs the requests with an HMAC of the configured headers.
+type hmacSigner struct {
+	conf   apidef.UpstreamHMACSigning
+	logger *logrus.Entry
+	now    func() time.Time
+}
+
+// Fill signs the request, logging the failures.
+func (h *hmacSigner) Fill(r *http.Request) {
+	if err := h.Sign(r); err != nil {
+		h.logger.WithError(err).Error("Couldn't sign the upstream request")
+	}
+}
+
+// Sign signs the request, the streamed bodies are rejected as the digest can't be computed.
+func (h *hmacSigner) Sign(r *http.Request) error {
+	body, err := upstreamSignedBody(r)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(body)
+	r.Header.Set(header.Digest, "SHA-256="+base64.StdEncoding.EncodeToString(digest[:]))
+
+	if r.Header.Get(header.Date) == "" {
+		r.Header.Set(header.Date, h.now().UTC().Format(http.TimeFormat))
+	}
+
+	signature, err := generateHMACEncodedSignature(h.signatureString(r), h.conf.Secret, h.conf.Algorithm)
+	if err != nil {
+		return err
+	}
+
+	headerName := header.Authorization
+	if name := h.conf.Header.AuthKeyName(); name != "" {
+		headerName = name
+	}
+
+	r.Header.Set(headerName, "Signature "+
+		`keyId="`+h.conf.KeyID+`",`+
+		`algorithm="`+h.conf.Algorithm+`",`+
+		`headers="`+strings.Join(h.conf.Headers, " ")+`",`+
+		`signature="`+signature+`"`)
+
+	return nil
+}
+
+// signatureString returns the signed lines of the configured headers, in order.
+func (h *hmacSigner) signatureString(r *http.Request) string {
+	lines := make([]string, 0, len(h.conf.Headers))
+	for _, name := range h.conf.Headers {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		var value string
+		switch name {
+		case "(request-target)":
+			value = strings.ToLower(r.Method) + " " + r.URL.RequestURI()
+		case "host":
+			value = r.Host
+			if value == "" {
+				value = r.URL.Host
+			}
+		default:
+			value = strings.TrimSpace(r.Header.Get(name))
+		}
+
+		lines = append(lines, name+": "+value)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// upstreamSignedBody returns the body of the outbound request, keeping it readable for the upstream.
+// It returns errUnbufferedBody for the streamed bodies, which can't be read ahead.
+func upstreamSignedBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if httputil.IsStreamingRequest(r) {
+		return nil, errUnbufferedBody
+	}
+
+	if err := nopCloseRequestBodyErr(r); err != nil {
+		return nil, err
+	}
+
+	// The buffered body rewinds once read to the end.
+	return io.ReadAll(r.Body)
+}
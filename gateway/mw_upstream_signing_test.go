@@ -0,0 +1,225 @@
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+var testSigV4Conf = apidef.UpstreamAWSSigV4{
+	Enabled:         true,
+	Region:          "us-east-1",
+	Service:         "service",
+	AccessKeyID:     "AKIDEXAMPLE",
+	SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+}
+
+func testSigV4Signer(signingTime time.Time) *awsSigV4Signer {
+	return &awsSigV4Signer{
+		conf:   testSigV4Conf,
+		logger: logrus.NewEntry(log),
+		now:    func() time.Time { return signingTime },
+	}
+}
+
+func TestAWSSigV4Signer(t *testing.T) {
+	signingTime := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	t.Run("known answer", func(t *testing.T) {
+		// get-vanilla from the AWS Signature Version 4 test suite.
+		r := httptest.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+		r.Body = nil
+
+		require.NoError(t, testSigV4Signer(signingTime).Sign(r))
+
+		assert.Equal(t, "20150830T123600Z", r.Header.Get("X-Amz-Date"))
+		assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+			"SignedHeaders=host;x-amz-date, "+
+			"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+			r.Header.Get(header.Authorization))
+	})
+
+	t.Run("signature covers the body", func(t *testing.T) {
+		sign := func(body string) string {
+			r := httptest.NewRequest(http.MethodPost, "https://example.amazonaws.com/", strings.NewReader(body))
+			require.NoError(t, testSigV4Signer(signingTime).Sign(r))
+
+			sent, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, body, string(sent), "the body is still sent to the upstream")
+
+			return r.Header.Get(header.Authorization)
+		}
+
+		assert.NotEqual(t, sign(`{"a":1}`), sign(`{"a":2}`))
+	})
+
+	t.Run("streamed body is unsigned", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "https://example.amazonaws.com/", strings.NewReader("stream"))
+		r.ContentLength = -1
+		r.Header.Set(header.ContentType, "application/grpc")
+
+		require.NoError(t, testSigV4Signer(signingTime).Sign(r))
+		assert.Equal(t, unsignedPayload, r.Header.Get(amzContentSha256))
+		assert.Contains(t, r.Header.Get(header.Authorization), "x-amz-content-sha256")
+	})
+}
+
+func TestHMACSigner(t *testing.T) {
+	signer := &hmacSigner{
+		conf: apidef.UpstreamHMACSigning{
+			Enabled:   true,
+			KeyID:     "key",
+			Secret:    "secret",
+			Algorithm: "hmac-sha256",
+			Headers:   defaultUpstreamHMACHeaders,
+		},
+		logger: logrus.NewEntry(log),
+		now:    time.Now,
+	}
+
+	t.Run("signs the digest of the body", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "http://upstream/path?q=1", strings.NewReader("body"))
+		require.NoError(t, signer.Sign(r))
+
+		digest := sha256.Sum256([]byte("body"))
+		assert.Equal(t, "SHA-256="+base64.StdEncoding.EncodeToString(digest[:]), r.Header.Get(header.Digest))
+
+		expected, err := generateHMACEncodedSignature(
+			"(request-target): post /path?q=1\nhost: upstream\ndate: "+r.Header.Get(header.Date)+"\ndigest: "+r.Header.Get(header.Digest),
+			"secret", "hmac-sha256")
+		require.NoError(t, err)
+
+		assert.Equal(t, `Signature keyId="key",algorithm="hmac-sha256",headers="(request-target) host date digest",signature="`+expected+`"`,
+			r.Header.Get(header.Authorization))
+	})
+
+	t.Run("streamed body is rejected", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "http://upstream/", strings.NewReader("stream"))
+		r.ContentLength = -1
+		r.Header.Set(header.ContentType, "application/grpc")
+
+		assert.ErrorIs(t, signer.Sign(r), errUnbufferedBody)
+		assert.Empty(t, r.Header.Get(header.Authorization))
+	})
+}
+
+// verifySigV4 signs a copy of the received request with the same signing time and compares the signatures.
+func verifySigV4(r *http.Request, body []byte) bool {
+	signingTime, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return false
+	}
+
+	auth := r.Header.Get(header.Authorization)
+	_, signedHeaders, ok := strings.Cut(auth, "SignedHeaders=")
+	if !ok {
+		return false
+	}
+	signedHeaders, _, _ = strings.Cut(signedHeaders, ",")
+
+	signed, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), nil)
+	signed.ContentLength = int64(len(body))
+	for _, name := range strings.Split(signedHeaders, ";") {
+		if name != "host" && name != "content-length" {
+			signed.Header.Set(name, r.Header.Get(name))
+		}
+	}
+
+	hash := sha256.Sum256(body)
+	credentials := aws.Credentials{AccessKeyID: testSigV4Conf.AccessKeyID, SecretAccessKey: testSigV4Conf.SecretAccessKey}
+	err = v4.NewSigner().SignHTTP(context.Background(), credentials, signed, hex.EncodeToString(hash[:]),
+		testSigV4Conf.Service, testSigV4Conf.Region, signingTime)
+
+	return err == nil && signed.Header.Get(header.Authorization) == auth
+}
+
+func TestUpstreamSigning(t *testing.T) {
+	type received struct {
+		body  string
+		valid bool
+	}
+
+	var (
+		mu   sync.Mutex
+		last received
+	)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		last = received{body: string(body), valid: verifySigV4(r, body)}
+		mu.Unlock()
+	}))
+	defer upstream.Close()
+
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.Secrets = map[string]string{"aws-secret": testSigV4Conf.SecretAccessKey}
+	})
+	defer ts.Close()
+
+	sigV4 := testSigV4Conf
+	sigV4.SecretAccessKey = "secrets://aws-secret"
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/signed/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.Proxy.StripListenPath = true
+		spec.UpstreamAuth = apidef.UpstreamAuth{Enabled: true, AWSSigV4: sigV4}
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.Transform = []apidef.TemplateMeta{{
+				Path:   "/transformed",
+				Method: http.MethodPost,
+				TemplateData: apidef.TemplateData{
+					Input:          apidef.RequestJSON,
+					Mode:           apidef.UseBlob,
+					TemplateSource: base64.StdEncoding.EncodeToString([]byte(`{"name":"{{.name | repeat 2}}"}`)),
+				},
+			}}
+		})
+	}, func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/misconfigured/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.UpstreamAuth = apidef.UpstreamAuth{Enabled: true, HMACSigning: apidef.UpstreamHMACSigning{Enabled: true, KeyID: "key"}}
+	})
+
+	lastReceived := func() received {
+		mu.Lock()
+		defer mu.Unlock()
+		return last
+	}
+
+	body := `{"name":"tyk"}`
+
+	_, _ = ts.Run(t, test.TestCase{Method: http.MethodPost, Path: "/signed/plain", Data: body, Code: http.StatusOK})
+	plain := lastReceived()
+	assert.True(t, plain.valid, "the signature matches the received request")
+	assert.Equal(t, body, plain.body)
+
+	_, _ = ts.Run(t, test.TestCase{Method: http.MethodPost, Path: "/signed/transformed", Data: body, Code: http.StatusOK})
+	transformed := lastReceived()
+	assert.True(t, transformed.valid, "the signature covers the transformed body")
+	assert.Equal(t, `{"name":"tyktyk"}`, transformed.body)
+
+	_, _ = ts.Run(t, test.TestCase{Method: http.MethodPost, Path: "/misconfigured/", Data: body, Code: http.StatusInternalServerError})
+}
@@ -13,6 +13,7 @@ import (
 	"strings"
 
 	"github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
 
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/ctx"
@@ -30,6 +31,9 @@ const (
 	fileLabel        = "$secret_file."
 	triggerKeyPrefix = "trigger"
 	triggerKeySep    = "-"
+
+	// defaultTriggerPayloadMaxSize is the size of the largest request body parsed by the JSON payload triggers.
+	defaultTriggerPayloadMaxSize = 1 << 20
 )
 
 var dollarMatch = regexp.MustCompile(`\$\d+`)
@@ -142,6 +146,17 @@ func (gw *Gateway) urlRewrite(meta *apidef.URLRewriteMeta, r *http.Request) (str
 				}
 			}
 
+			// Check JSON payload
+			if len(triggerOpts.Options.PayloadJSONMatches) > 0 {
+				if checkPayloadJSON(r, triggerOpts.Options.PayloadJSONMatches, triggerOpts.Options.PayloadMaxSize, checkAny, tn) {
+					setCount += 1
+					if checkAny {
+						rewriteToPath = triggerOpts.RewriteTo
+						break
+					}
+				}
+			}
+
 			if !checkAny {
 				// Set total count:
 				total := 0
@@ -163,6 +178,9 @@ func (gw *Gateway) urlRewrite(meta *apidef.URLRewriteMeta, r *http.Request) (str
 				if triggerOpts.Options.PayloadMatches.MatchPattern != "" {
 					total += 1
 				}
+				if len(triggerOpts.Options.PayloadJSONMatches) > 0 {
+					total += 1
+				}
 				if total == setCount {
 					rewriteToPath = triggerOpts.RewriteTo
 					break
@@ -453,6 +471,10 @@ func (m *URLRewriteMiddleware) InitTriggerRx() (enabled bool) {
 					h.Init()
 					tr.Options.PathPartMatches[key] = h
 				}
+				for key, h := range tr.Options.PayloadJSONMatches {
+					h.Init()
+					tr.Options.PayloadJSONMatches[key] = h
+				}
 				if tr.Options.PayloadMatches.MatchPattern != "" {
 					tr.Options.PayloadMatches.Init()
 				}
@@ -486,7 +508,12 @@ func (m *URLRewriteMiddleware) CheckHostRewrite(oldPath, newTarget string, r *ht
 		return errParseNew
 	}
 
-	if shouldRewriteHost(oldAsURL, newAsURL) {
+	var target *url.URL
+	if m.Spec != nil {
+		target = m.Spec.target
+	}
+
+	if shouldRewriteHost(oldAsURL, newAsURL, target) {
 		log.Debug("Detected a host rewrite in pattern!")
 		setCtxValue(r, ctx.RetainHost, true)
 	}
@@ -494,7 +521,9 @@ func (m *URLRewriteMiddleware) CheckHostRewrite(oldPath, newTarget string, r *ht
 	return nil
 }
 
-func shouldRewriteHost(oldURL, newURL *url.URL) bool {
+// shouldRewriteHost reports whether an absolute rewrite target points to a different upstream,
+// either another host than the request or another scheme or host than the API target.
+func shouldRewriteHost(oldURL, newURL, target *url.URL) bool {
 	if newURL.Scheme == "" {
 		return false
 	}
@@ -503,6 +532,10 @@ func shouldRewriteHost(oldURL, newURL *url.URL) bool {
 		return false
 	}
 
+	if target != nil && target.Scheme != LoopScheme && (newURL.Scheme != target.Scheme || newURL.Host != target.Host) {
+		return true
+	}
+
 	if oldURL.Host == newURL.Host {
 		return false
 	}
@@ -757,6 +790,70 @@ func checkPayload(r *http.Request, options apidef.StringRegexMap, triggernum int
 	return false
 }
 
+// checkPayloadJSON matches the values found at the JSON paths of options in the request body. Bodies larger than
+// maxSize, 1MiB if 0, and bodies which aren't JSON don't match. The body is buffered, so it's read again downstream.
+func checkPayloadJSON(r *http.Request, options map[string]apidef.StringRegexMap, maxSize int64, any bool, triggernum int) bool {
+	if maxSize <= 0 {
+		maxSize = defaultTriggerPayloadMaxSize
+	}
+
+	if r.Body == nil || r.ContentLength > maxSize {
+		return false
+	}
+
+	var (
+		bodyBytes []byte
+		err       error
+	)
+	if r.ContentLength < 0 {
+		// the size of chunked bodies isn't known before reading them, they're read up to the cap
+		// and what was read is put back in front of the rest of the body
+		body := r.Body
+		bodyBytes, err = io.ReadAll(io.LimitReader(body, maxSize+1))
+		r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(bodyBytes), body), Closer: body}
+		if err == nil && int64(len(bodyBytes)) > maxSize {
+			return false
+		}
+	} else {
+		nopCloseRequestBody(r)
+		bodyBytes, err = io.ReadAll(r.Body)
+	}
+	if err != nil {
+		log.WithError(err).Error("error reading request body")
+		return false
+	}
+
+	if !gjson.ValidBytes(bodyBytes) {
+		return false
+	}
+
+	contextData := ctxGetData(r)
+	fCount := 0
+	for path, mr := range options {
+		result := gjson.GetBytes(bodyBytes, path)
+		if !result.Exists() {
+			continue
+		}
+
+		matched, match := mr.FindStringSubmatch(result.String())
+		if matched {
+			addMatchToContextData(contextData, match, triggernum, path)
+			fCount++
+		}
+	}
+
+	if fCount > 0 {
+		ctxSetData(r, contextData)
+		if any {
+			return true
+		}
+
+		return len(options) <= fCount
+	}
+
+	return false
+}
+
 func addMatchToContextData(cd map[string]interface{}, match []string, trNum int, trName string, indices ...int) {
 	kn := buildTriggerKey(trNum, trName, indices...)
 	if len(match) == 0 {
@@ -6,8 +6,11 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -1136,6 +1139,166 @@ func TestRewriterTriggers(t *testing.T) {
 				false,
 			}
 		},
+		func() TestDef {
+			var jsonStr = []byte(`{"refund":{"type":"refund","amount":10}}`)
+			r, _ := http.NewRequest("POST", "/test/pl/rewrite", bytes.NewBuffer(jsonStr))
+
+			hOpt := apidef.StringRegexMap{MatchPattern: "^refund$"}
+			hOpt.Init()
+
+			return TestDef{
+				"Payload JSON Match",
+				"/test/pl/rewrite", "/change/to/me/ignore",
+				"/test/pl/rewrite", "/change/to/me/refund",
+				[]apidef.RoutingTrigger{
+					{
+						On: apidef.All,
+						Options: apidef.RoutingTriggerOptions{
+							PayloadJSONMatches: map[string]apidef.StringRegexMap{
+								"refund.type": hOpt,
+							},
+						},
+						RewriteTo: "/change/to/me/$tyk_context.trigger-0-refund.type",
+					},
+				},
+				r,
+				true,
+			}
+		},
+		func() TestDef {
+			var jsonStr = []byte(`{"refund":{"type":"purchase"},"note":"refund"}`)
+			r, _ := http.NewRequest("POST", "/test/pl/rewrite", bytes.NewBuffer(jsonStr))
+
+			hOpt := apidef.StringRegexMap{MatchPattern: "^refund$"}
+			hOpt.Init()
+
+			return TestDef{
+				"Payload JSON No Match",
+				"/test/pl/rewrite", "/change/to/me/ignore",
+				"/test/pl/rewrite", "/change/to/me/ignore",
+				[]apidef.RoutingTrigger{
+					{
+						On: apidef.Any,
+						Options: apidef.RoutingTriggerOptions{
+							PayloadJSONMatches: map[string]apidef.StringRegexMap{
+								"refund.type": hOpt,
+							},
+						},
+						RewriteTo: "/change/to/me/refund",
+					},
+				},
+				r,
+				true,
+			}
+		},
+		func() TestDef {
+			r, _ := http.NewRequest("POST", "/test/pl/rewrite", strings.NewReader("type=refund"))
+
+			hOpt := apidef.StringRegexMap{MatchPattern: "refund"}
+			hOpt.Init()
+
+			return TestDef{
+				"Payload JSON Not JSON",
+				"/test/pl/rewrite", "/change/to/me/ignore",
+				"/test/pl/rewrite", "/change/to/me/ignore",
+				[]apidef.RoutingTrigger{
+					{
+						On: apidef.Any,
+						Options: apidef.RoutingTriggerOptions{
+							PayloadJSONMatches: map[string]apidef.StringRegexMap{
+								"type": hOpt,
+							},
+						},
+						RewriteTo: "/change/to/me/refund",
+					},
+				},
+				r,
+				true,
+			}
+		},
+		func() TestDef {
+			var jsonStr = []byte(`{"type":"refund","items":["` + strings.Repeat("x", 64) + `"]}`)
+			r, _ := http.NewRequest("POST", "/test/pl/rewrite", bytes.NewBuffer(jsonStr))
+
+			hOpt := apidef.StringRegexMap{MatchPattern: "refund"}
+			hOpt.Init()
+
+			return TestDef{
+				"Payload JSON Oversized",
+				"/test/pl/rewrite", "/change/to/me/ignore",
+				"/test/pl/rewrite", "/change/to/me/ignore",
+				[]apidef.RoutingTrigger{
+					{
+						On: apidef.Any,
+						Options: apidef.RoutingTriggerOptions{
+							PayloadJSONMatches: map[string]apidef.StringRegexMap{
+								"type": hOpt,
+							},
+							PayloadMaxSize: 32,
+						},
+						RewriteTo: "/change/to/me/refund",
+					},
+				},
+				r,
+				true,
+			}
+		},
+		func() TestDef {
+			var jsonStr = []byte(`{"type":"refund"}`)
+			r, _ := http.NewRequest("POST", "/test/pl/rewrite", bytes.NewBuffer(jsonStr))
+			r.ContentLength = -1 // chunked
+
+			hOpt := apidef.StringRegexMap{MatchPattern: "refund"}
+			hOpt.Init()
+
+			return TestDef{
+				"Payload JSON Chunked",
+				"/test/pl/rewrite", "/change/to/me/ignore",
+				"/test/pl/rewrite", "/change/to/me/refund",
+				[]apidef.RoutingTrigger{
+					{
+						On: apidef.Any,
+						Options: apidef.RoutingTriggerOptions{
+							PayloadJSONMatches: map[string]apidef.StringRegexMap{
+								"type": hOpt,
+							},
+							PayloadMaxSize: 32,
+						},
+						RewriteTo: "/change/to/me/refund",
+					},
+				},
+				r,
+				true,
+			}
+		},
+		func() TestDef {
+			var jsonStr = []byte(`{"type":"refund","items":["` + strings.Repeat("x", 64) + `"]}`)
+			r, _ := http.NewRequest("POST", "/test/pl/rewrite", bytes.NewBuffer(jsonStr))
+			r.ContentLength = -1 // chunked
+
+			hOpt := apidef.StringRegexMap{MatchPattern: "refund"}
+			hOpt.Init()
+
+			return TestDef{
+				"Payload JSON Chunked Oversized",
+				"/test/pl/rewrite", "/change/to/me/ignore",
+				"/test/pl/rewrite", "/change/to/me/ignore",
+				[]apidef.RoutingTrigger{
+					{
+						On: apidef.Any,
+						Options: apidef.RoutingTriggerOptions{
+							PayloadJSONMatches: map[string]apidef.StringRegexMap{
+								"type": hOpt,
+							},
+							PayloadMaxSize: 32,
+						},
+						RewriteTo: "/change/to/me/refund",
+					},
+				},
+				r,
+				true,
+			}
+		},
 	}
 	for _, tf := range tests {
 		tc := tf()
@@ -1310,6 +1473,68 @@ func TestURLRewriteCaseSensitivity(t *testing.T) {
 	})
 }
 
+func TestURLRewrite_absoluteTargetOnOtherUpstream(t *testing.T) {
+	var landed atomic.Value
+	second := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		landed.Store(r.URL.RequestURI())
+		_, _ = w.Write([]byte("second upstream"))
+	}))
+	defer second.Close()
+
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	routeOpt := apidef.StringRegexMap{MatchPattern: "second"}
+	routeOpt.Init()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = TestHttpAny
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.URLRewrite = []apidef.URLRewriteMeta{
+				{
+					Path:         "/rule/{id}",
+					Method:       http.MethodGet,
+					MatchPattern: "/rule/(.*)",
+					RewriteTo:    second.URL + "/rewritten/$1",
+				},
+				{
+					Path:         "/trigger",
+					Method:       http.MethodGet,
+					MatchPattern: "/trigger",
+					RewriteTo:    "/not-triggered",
+					Triggers: []apidef.RoutingTrigger{{
+						On: apidef.Any,
+						Options: apidef.RoutingTriggerOptions{
+							HeaderMatches: map[string]apidef.StringRegexMap{"X-Route": routeOpt},
+						},
+						RewriteTo: second.URL + "/triggered",
+					}},
+				},
+			}
+		})
+	})
+
+	t.Run("rule", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{Path: "/rule/42", Code: http.StatusOK, BodyMatch: "second upstream"})
+		assert.Equal(t, "/rewritten/42", landed.Load())
+	})
+
+	t.Run("trigger", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{
+			Path:      "/trigger",
+			Headers:   map[string]string{"X-Route": "second"},
+			Code:      http.StatusOK,
+			BodyMatch: "second upstream",
+		})
+		assert.Equal(t, "/triggered", landed.Load())
+	})
+
+	t.Run("trigger not matched stays on API target", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{Path: "/trigger", Code: http.StatusOK, BodyMatch: `"Url":"/not-triggered"`})
+	})
+}
+
 func TestValToStr(t *testing.T) {
 
 	example := []interface{}{
@@ -1556,6 +1781,7 @@ func TestURLRewriteMiddleware_CheckHostRewrite(t *testing.T) {
 	type args struct {
 		oldPath   string
 		newTarget string
+		target    string
 	}
 
 	tests := []struct {
@@ -1627,10 +1853,45 @@ func TestURLRewriteMiddleware_CheckHostRewrite(t *testing.T) {
 			errExpected:   true,
 			retainHostVal: nil,
 		},
+		{
+			name: "same host as request but different from API target",
+			args: args{
+				oldPath:   "http://tyk-gateway/hello",
+				newTarget: "http://tyk-gateway/status",
+				target:    "http://upstream/",
+			},
+			errExpected:   false,
+			retainHostVal: true,
+		},
+		{
+			name: "different scheme than API target",
+			args: args{
+				oldPath:   "http://upstream/hello",
+				newTarget: "https://upstream/status",
+				target:    "http://upstream/",
+			},
+			errExpected:   false,
+			retainHostVal: true,
+		},
+		{
+			name: "same scheme and host as API target and request",
+			args: args{
+				oldPath:   "http://upstream/hello",
+				newTarget: "http://upstream/status",
+				target:    "http://upstream/",
+			},
+			errExpected:   false,
+			retainHostVal: nil,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := &URLRewriteMiddleware{BaseMiddleware: &BaseMiddleware{}}
+			if tt.args.target != "" {
+				target, err := url.Parse(tt.args.target)
+				require.NoError(t, err)
+				m.Spec = &APISpec{APIDefinition: &apidef.APIDefinition{}, target: target}
+			}
 			r := &http.Request{}
 			err := m.CheckHostRewrite(tt.args.oldPath, tt.args.newTarget, r)
 			assert.Equal(t, tt.errExpected, err != nil)
@@ -5,10 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"strings"
 
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/ctx"
+	"github.com/TykTechnologies/tyk/header"
 	tykerrors "github.com/TykTechnologies/tyk/internal/errors"
 	"github.com/TykTechnologies/tyk/internal/service/gojsonschema"
 )
@@ -41,10 +44,17 @@ func (k *ValidateJSON) ProcessRequest(_ http.ResponseWriter, r *http.Request, _
 	}
 
 	vPathMeta := meta.(*apidef.ValidatePathMeta)
-	if vPathMeta.Schema == nil {
+	if vPathMeta.SchemaCompiled == nil {
 		return errors.New("no schemas to validate against"), http.StatusInternalServerError
 	}
 
+	if !isJSONContentType(r.Header.Get(header.ContentType)) {
+		if vPathMeta.Strict {
+			return errors.New("request content type must be JSON"), http.StatusUnsupportedMediaType
+		}
+		return nil, http.StatusOK
+	}
+
 	nopCloseRequestBody(r)
 	// Load input body into gojsonschema
 	bodyBytes, err := io.ReadAll(r.Body)
@@ -56,7 +66,7 @@ func (k *ValidateJSON) ProcessRequest(_ http.ResponseWriter, r *http.Request, _
 	inputLoader := gojsonschema.NewBytesLoader(bodyBytes)
 
 	// Perform validation
-	result, err := gojsonschema.Validate(vPathMeta.SchemaCache, inputLoader)
+	result, err := vPathMeta.SchemaCompiled.Validate(inputLoader)
 	if err != nil {
 		ctx.SetErrorClassification(r, tykerrors.ClassifyJSONValidationError(tykerrors.ErrTypeJSONParseError, k.Name()).
 			WithTemplateData(map[string]any{"InvalidParams": err.Error()}))
@@ -65,13 +75,17 @@ func (k *ValidateJSON) ProcessRequest(_ http.ResponseWriter, r *http.Request, _
 
 	// Handle Failure
 	if !result.Valid() {
-		if vPathMeta.ErrorResponseCode == 0 {
-			vPathMeta.ErrorResponseCode = http.StatusUnprocessableEntity
+		errorResponseCode := vPathMeta.ErrorResponseCode
+		if errorResponseCode == 0 {
+			errorResponseCode = http.StatusUnprocessableEntity
 		}
 		formattedErr := k.formatError(result.Errors())
 		ctx.SetErrorClassification(r, tykerrors.ClassifyJSONValidationError(tykerrors.ErrTypeSchemaValidationFailed, k.Name()).
-			WithTemplateData(map[string]any{"InvalidParams": formattedErr.Error()}))
-		return formattedErr, vPathMeta.ErrorResponseCode
+			WithTemplateData(map[string]any{
+				"InvalidParams":    formattedErr.Error(),
+				"ValidationErrors": jsonSchemaErrors(result.Errors()),
+			}))
+		return formattedErr, errorResponseCode
 	}
 
 	// Handle Success
@@ -90,3 +104,51 @@ func (k *ValidateJSON) formatError(schemaErrors []gojsonschema.ResultError) erro
 
 	return errors.New(errStr)
 }
+
+// jsonSchemaError is a JSON schema validation failure, exposed to error templates as ValidationErrors.
+type jsonSchemaError struct {
+	// Pointer is the JSON pointer of the invalid value in the request body.
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+func jsonSchemaErrors(schemaErrors []gojsonschema.ResultError) []jsonSchemaError {
+	errs := make([]jsonSchemaError, 0, len(schemaErrors))
+	for _, schemaErr := range schemaErrors {
+		errs = append(errs, jsonSchemaError{
+			Pointer: jsonPointer(schemaErr.Context().String("\x00")),
+			Message: schemaErr.Description(),
+		})
+	}
+
+	return errs
+}
+
+// jsonPointer converts a gojsonschema context, with its parts separated by NUL, to a JSON pointer.
+func jsonPointer(context string) string {
+	parts := strings.Split(context, "\x00")[1:]
+	if len(parts) == 0 {
+		return ""
+	}
+
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	for i, part := range parts {
+		parts[i] = escaper.Replace(part)
+	}
+
+	return "/" + strings.Join(parts, "/")
+}
+
+// isJSONContentType reports whether a request content type is JSON. Requests without one are validated as JSON.
+func isJSONContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == header.ApplicationJSON || strings.HasSuffix(mediaType, "+json")
+}
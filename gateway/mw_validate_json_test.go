@@ -7,10 +7,12 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/test"
 )
 
@@ -209,3 +211,78 @@ func TestValidateJSONSchemaTemplateData(t *testing.T) {
 		})
 	})
 }
+
+func TestValidateJSONSchema_Compiled(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	loadAPI := func(listenPath string, schema string, configure func(*apidef.ValidatePathMeta)) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.APIID = listenPath
+			spec.Proxy.ListenPath = "/" + listenPath + "/"
+			spec.ErrorTemplates = map[string]apidef.ErrorTemplate{
+				"422": {JSON: `{"errors": [{{range $i, $e := .ValidationErrors}}{{if $i}},{{end}}{"pointer": "{{$e.Pointer}}", "message": "{{$e.Message}}"}{{end}}]}`},
+			}
+			UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+				meta := apidef.ValidatePathMeta{Path: "/v", Method: http.MethodPost}
+				require.NoError(t, json.Unmarshal([]byte(schema), &meta.Schema))
+				if configure != nil {
+					configure(&meta)
+				}
+				v.ExtendedPaths.ValidateJSON = []apidef.ValidatePathMeta{meta}
+			})
+		})
+	}
+
+	t.Run("schema compile failure", func(t *testing.T) {
+		loadAPI("invalid", `{"type": "object", "properties": {"age": {"type": 5}}}`, nil)
+
+		assert.Nil(t, ts.Gw.getApiSpec("invalid"))
+		_, _ = ts.Run(t, test.TestCase{Method: http.MethodPost, Path: "/invalid/v", Data: `{}`, Code: http.StatusNotFound})
+	})
+
+	t.Run("error list", func(t *testing.T) {
+		loadAPI("list", `{"type": "object", "required": ["name"], "properties": {"tags": {"type": "array", "items": {"type": "string"}}}}`, nil)
+
+		resp, _ := ts.Run(t, []test.TestCase{
+			{Method: http.MethodPost, Path: "/list/v", Data: `{"name": "a", "tags": ["b"]}`, Code: http.StatusOK},
+			{Method: http.MethodPost, Path: "/list/v", Data: `{"tags": ["b", 1]}`, Code: http.StatusUnprocessableEntity},
+		}...)
+
+		var body struct {
+			Errors []jsonSchemaError `json:"errors"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.ElementsMatch(t, []jsonSchemaError{
+			{Pointer: "", Message: "name is required"},
+			{Pointer: "/tags/1", Message: "Invalid type. Expected: string, given: integer"},
+		}, body.Errors)
+	})
+
+	t.Run("error response code", func(t *testing.T) {
+		loadAPI("code", `{"type": "object"}`, func(meta *apidef.ValidatePathMeta) {
+			meta.ErrorResponseCode = http.StatusBadRequest
+		})
+
+		_, _ = ts.Run(t, test.TestCase{Method: http.MethodPost, Path: "/code/v", Data: `[]`, Code: http.StatusBadRequest})
+	})
+
+	t.Run("content type", func(t *testing.T) {
+		textPlain := map[string]string{header.ContentType: "text/plain"}
+		problemJSON := map[string]string{header.ContentType: "application/problem+json; charset=utf-8"}
+
+		loadAPI("lenient", `{"type": "object"}`, nil)
+		_, _ = ts.Run(t, []test.TestCase{
+			{Method: http.MethodPost, Path: "/lenient/v", Data: `[]`, Headers: textPlain, Code: http.StatusOK},
+			{Method: http.MethodPost, Path: "/lenient/v", Data: `[]`, Headers: problemJSON, Code: http.StatusUnprocessableEntity},
+		}...)
+
+		loadAPI("strict", `{"type": "object"}`, func(meta *apidef.ValidatePathMeta) {
+			meta.Strict = true
+		})
+		_, _ = ts.Run(t, []test.TestCase{
+			{Method: http.MethodPost, Path: "/strict/v", Data: `[]`, Headers: textPlain, Code: http.StatusUnsupportedMediaType},
+			{Method: http.MethodPost, Path: "/strict/v", Data: `{}`, Code: http.StatusOK},
+		}...)
+	})
+}
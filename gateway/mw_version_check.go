@@ -2,7 +2,9 @@ package gateway
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -10,6 +12,7 @@ import (
 
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/apidef/oas"
+	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/internal/httpctx"
 	"github.com/TykTechnologies/tyk/internal/jsonrpc"
 	"github.com/TykTechnologies/tyk/internal/mcp"
@@ -69,6 +72,7 @@ func (v *VersionCheck) ProcessRequest(w http.ResponseWriter, r *http.Request, _
 			return errors.New(string(VersionNotFound)), http.StatusForbidden
 		}
 
+		resolvedVersion := targetVersion
 		subVersionID := v.Spec.VersionDefinition.Versions[targetVersion]
 		handler, _, found := v.Gw.findInternalHttpHandlerByNameOrID(subVersionID)
 		if !found {
@@ -80,6 +84,8 @@ func (v *VersionCheck) ProcessRequest(w http.ResponseWriter, r *http.Request, _
 				goto outside
 			}
 
+			resolvedVersion = v.Spec.VersionDefinition.Default
+
 			targetID, ok := v.Spec.VersionDefinition.Versions[v.Spec.VersionDefinition.Default]
 			if !ok {
 				log.Errorf("fallback to default but %s is not in the versions list", v.Spec.VersionDefinition.Default)
@@ -93,12 +99,22 @@ func (v *VersionCheck) ProcessRequest(w http.ResponseWriter, r *http.Request, _
 			}
 		}
 
+		if err, code := v.serveVersion(w, r, resolvedVersion); err != nil {
+			return err, code
+		}
+
 		v.Spec.SanitizeProxyPaths(r)
 
 		handler.ServeHTTP(w, r)
 		return nil, middleware.StatusRespond
 	}
 outside:
+	if vName := v.servedVersionName(r); vName != "" {
+		if err, code := v.serveVersion(w, r, vName); err != nil {
+			return err, code
+		}
+	}
+
 	// Check versioning, blacklist, whitelist and ignored status
 	requestValid, stat := v.Spec.RequestValid(r)
 	if !requestValid {
@@ -150,20 +166,23 @@ outside:
 
 	whiteListStatus := v.Spec.WhiteListEnabled[versionInfo.Name]
 
-	// We handle redirects before ignores in case we aren't using a whitelist
+	// We handle redirects before ignores in case we aren't using a whitelist.
+	// Mock responses are returned by the mock response middleware once the request is authenticated,
+	// only the deprecated method action replies are returned here.
 	if stat == StatusRedirectFlowByReply {
 		_, meta := v.Spec.URLAllowedAndIgnored(r, versionPaths, whiteListStatus)
-		var mockMeta apidef.MockResponseMeta
-		var ok bool
-		if mockMeta, ok = meta.(apidef.MockResponseMeta); !ok {
-			endpointMethodMeta := meta.(*apidef.EndpointMethodMeta)
-			mockMeta.Body = endpointMethodMeta.Data
-			mockMeta.Headers = endpointMethodMeta.Headers
-			mockMeta.Code = endpointMethodMeta.Code
+		if endpointMethodMeta, ok := meta.(*apidef.EndpointMethodMeta); ok {
+			v.DoMockReply(w, apidef.MockResponseMeta{
+				Body:    endpointMethodMeta.Data,
+				Headers: endpointMethodMeta.Headers,
+				Code:    endpointMethodMeta.Code,
+			})
+			return nil, middleware.StatusRespond
 		}
 
-		v.DoMockReply(w, mockMeta)
-		return nil, middleware.StatusRespond
+		if v.Spec.isIgnoredEndpoint(r, versionPaths) {
+			stat = StatusOkAndIgnore
+		}
 	}
 
 	if !v.Spec.ExpirationTs.IsZero() {
@@ -179,6 +198,125 @@ outside:
 	return nil, http.StatusOK
 }
 
+// servedVersionName returns the name of the version of this API serving the request,
+// or an empty string when the API isn't versioned.
+func (v *VersionCheck) servedVersionName(r *http.Request) string {
+	if v.Spec.VersionDefinition.Enabled {
+		return v.Spec.VersionDefinition.Name
+	}
+
+	if v.Spec.VersionData.NotVersioned {
+		return ""
+	}
+
+	versionInfo, stat := v.Spec.Version(r)
+	if stat != StatusOk {
+		return ""
+	}
+
+	return versionInfo.Name
+}
+
+// versionPhase is the stage of its deprecation a version is in.
+type versionPhase string
+
+const (
+	versionPhaseActive     versionPhase = "active"
+	versionPhaseDeprecated versionPhase = "deprecated"
+	versionPhaseSunset     versionPhase = "sunset"
+)
+
+// serveVersion records the version serving the request and applies its deprecation and sunset. A retired
+// version is rejected with 410 Gone, a version being retired is served with the Sunset header and a
+// deprecated one with the Deprecation and Warning headers. The phases follow the configured dates, so
+// a version is retired on time without updating its definition.
+func (v *VersionCheck) serveVersion(w http.ResponseWriter, r *http.Request, vName string) (error, int) {
+	ctxSetResolvedVersion(r, vName)
+
+	sunset, ok := v.Spec.VersionDefinition.Sunset[vName]
+	if !ok {
+		return nil, http.StatusOK
+	}
+
+	now := time.Now()
+	phase, phaseDate := versionPhaseActive, ""
+
+	if sunset.Deprecation != "" {
+		date, err := time.Parse(time.RFC3339, sunset.Deprecation)
+		if err != nil {
+			v.Logger().WithError(err).Warningf("Ignoring the invalid deprecation date of version %s", vName)
+		} else {
+			// RFC 9745 structured field date
+			w.Header().Set(header.Deprecation, "@"+strconv.FormatInt(date.Unix(), 10))
+			if !now.Before(date) {
+				phase, phaseDate = versionPhaseDeprecated, sunset.Deprecation
+			}
+		}
+	}
+
+	switch {
+	case sunset.Date != "":
+		date, err := time.Parse(time.RFC3339, sunset.Date)
+		if err != nil {
+			v.Logger().WithError(err).Warningf("Ignoring the invalid sunset date of version %s", vName)
+			break
+		}
+
+		w.Header().Set(header.Sunset, date.UTC().Format(http.TimeFormat))
+		if !now.Before(date) {
+			phase, phaseDate = versionPhaseSunset, sunset.Date
+		}
+	case sunset.Deprecation == "":
+		phase = versionPhaseSunset
+	}
+
+	if sunset.Link != "" {
+		w.Header().Add(header.Link, fmt.Sprintf(`<%s>; rel="deprecation"`, sunset.Link))
+	}
+
+	v.fireVersionPhaseEvent(vName, phase, phaseDate)
+
+	switch phase {
+	case versionPhaseDeprecated:
+		warning := sunset.Warning
+		if warning == "" {
+			warning = string(VersionDeprecated)
+		}
+
+		w.Header().Set(header.Warning, "299 - "+strconv.Quote(warning))
+	case versionPhaseSunset:
+		message := sunset.Message
+		if message == "" {
+			message = string(VersionSunset)
+		}
+
+		return errors.New(message), http.StatusGone
+	}
+
+	return nil, http.StatusOK
+}
+
+// fireVersionPhaseEvent fires EventVersionDeprecated or EventVersionSunset on the first request
+// seen once the version entered the phase.
+func (v *VersionCheck) fireVersionPhaseEvent(vName string, phase versionPhase, date string) {
+	prev, _ := v.Gw.versionPhases.Swap(v.Spec.APIID+"/"+vName, phase)
+	if prev == phase || phase == versionPhaseActive {
+		return
+	}
+
+	name, message := EventVersionDeprecated, "API version deprecated."
+	if phase == versionPhaseSunset {
+		name, message = EventVersionSunset, "API version retired."
+	}
+
+	v.FireEvent(name, EventVersionPhaseMeta{
+		EventMetaDefault: EventMetaDefault{Message: message},
+		APIID:            v.Spec.APIID,
+		Version:          vName,
+		Date:             date,
+	})
+}
+
 // handleMCPPrimitiveNotFound handles the MCPPrimitiveNotFound status for MCP/JSON-RPC APIs.
 // MCPPrimitiveNotFound indicates that a request targets an MCP primitive (tool/resource/prompt)
 // that is not defined in the API definition. This can occur in two scenarios:
@@ -3,13 +3,17 @@ package gateway
 import (
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/TykTechnologies/tyk-pump/analytics"
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/internal/httpctx"
 	"github.com/TykTechnologies/tyk/internal/mcp"
 	"github.com/TykTechnologies/tyk/test"
@@ -807,3 +811,298 @@ func TestVersionCheck_handleMCPPrimitiveNotFound(t *testing.T) {
 		})
 	}
 }
+
+func TestVersioning_Sources(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(name + " served " + r.Header.Get("X-Served-Version")))
+		}))
+	}
+
+	upstreamV1, upstreamV2 := upstream("upstream-v1"), upstream("upstream-v2")
+	defer upstreamV1.Close()
+	defer upstreamV2.Close()
+
+	injectVersion := func(v *apidef.VersionInfo) {
+		v.GlobalHeaders = map[string]string{"X-Served-Version": "$tyk_context.api_version"}
+	}
+
+	baseAPI := BuildAPI(func(a *APISpec) {
+		a.APIID = "base"
+		a.Proxy.ListenPath = "/versioned"
+		a.Proxy.TargetURL = upstreamV1.URL
+		a.EnableContextVars = true
+		a.VersionDefinition.Enabled = true
+		a.VersionDefinition.Name = "v1"
+		a.VersionDefinition.Default = apidef.Self
+		a.VersionDefinition.FallbackToDefault = true
+		a.VersionDefinition.Sources = []apidef.VersionSource{
+			{Location: apidef.HeaderLocation, Key: "X-API-Version"},
+			{Location: apidef.URLParamLocation, Key: "version"},
+		}
+		a.VersionDefinition.Versions = map[string]string{"v2": "v2-api-id"}
+		UpdateAPIVersion(a, "Default", injectVersion)
+	})[0]
+
+	v2 := BuildAPI(func(a *APISpec) {
+		a.APIID = "v2-api-id"
+		a.Proxy.ListenPath = "/versioned-v2"
+		a.Proxy.TargetURL = upstreamV2.URL
+		a.Internal = true
+		a.EnableContextVars = true
+		UpdateAPIVersion(a, "Default", injectVersion)
+	})[0]
+
+	ts.Gw.LoadAPI(baseAPI, v2)
+
+	servedByV1 := "upstream-v1 served v1"
+	servedByV2 := "upstream-v2 served v2"
+
+	t.Run("precedence", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/versioned?version=v1", Headers: map[string]string{"X-API-Version": "v2"}, BodyMatch: servedByV2},
+			{Path: "/versioned?version=v2", Headers: map[string]string{"X-API-Version": "v1"}, BodyMatch: servedByV1},
+			{Path: "/versioned?version=v2", BodyMatch: servedByV2},
+		}...)
+
+		baseAPI.VersionDefinition.Sources = []apidef.VersionSource{
+			{Location: apidef.URLParamLocation, Key: "version"},
+			{Location: apidef.HeaderLocation, Key: "X-API-Version"},
+		}
+		ts.Gw.LoadAPI(baseAPI, v2)
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/versioned?version=v1", Headers: map[string]string{"X-API-Version": "v2"}, BodyMatch: servedByV1})
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/versioned", BodyMatch: servedByV1},
+			{Path: "/versioned?version=v9", BodyMatch: servedByV1},
+		}...)
+
+		baseAPI.VersionDefinition.Default = "v2"
+		ts.Gw.LoadAPI(baseAPI, v2)
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/versioned", BodyMatch: servedByV2},
+			{Path: "/versioned?version=v9", BodyMatch: servedByV2},
+			{Path: "/versioned?version=v1", BodyMatch: servedByV1},
+		}...)
+	})
+
+	t.Run("sunset", func(t *testing.T) {
+		retiredAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+		retiringAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+		baseAPI.VersionDefinition.Sunset = map[string]apidef.VersionSunset{
+			"v1": {Date: retiringAt.Format(time.RFC3339)},
+			"v2": {Date: retiredAt.Format(time.RFC3339), Message: "v2 is retired, use v1"},
+		}
+		ts.Gw.LoadAPI(baseAPI, v2)
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{
+				Path:         "/versioned?version=v1",
+				Code:         http.StatusOK,
+				BodyMatch:    servedByV1,
+				HeadersMatch: map[string]string{header.Sunset: retiringAt.Format(http.TimeFormat)},
+			},
+			{
+				Path:         "/versioned?version=v2",
+				Code:         http.StatusGone,
+				BodyMatch:    "v2 is retired, use v1",
+				HeadersMatch: map[string]string{header.Sunset: retiredAt.Format(http.TimeFormat)},
+			},
+			// The default version is retired too.
+			{Path: "/versioned", Code: http.StatusGone},
+		}...)
+
+		baseAPI.VersionDefinition.Sunset = map[string]apidef.VersionSunset{"v2": {}}
+		ts.Gw.LoadAPI(baseAPI, v2)
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/versioned?version=v2", Code: http.StatusGone, BodyMatch: string(VersionSunset)})
+	})
+}
+
+func TestVersioning_ResolvedVersionAnalytics(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.Analytics.Flush()
+	ts.Gw.Analytics.Store.GetAndDeleteSet(analyticsKeyName)
+
+	baseAPI := BuildAPI(func(a *APISpec) {
+		a.APIID = "base"
+		a.Proxy.ListenPath = "/versioned"
+		a.VersionDefinition.Enabled = true
+		a.VersionDefinition.Name = "v1"
+		a.VersionDefinition.Default = "v2"
+		a.VersionDefinition.Location = apidef.HeaderLocation
+		a.VersionDefinition.Key = "X-API-Version"
+		a.VersionDefinition.Versions = map[string]string{"v2": "v2-api-id"}
+	})[0]
+
+	v2 := BuildAPI(func(a *APISpec) {
+		a.APIID = "v2-api-id"
+		a.Proxy.ListenPath = "/versioned-v2"
+		a.Internal = true
+	})[0]
+
+	ts.Gw.LoadAPI(baseAPI, v2)
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Path: "/versioned", Code: http.StatusOK},
+		{Path: "/versioned", Headers: map[string]string{"X-API-Version": "v1"}, Code: http.StatusOK},
+	}...)
+
+	ts.Gw.Analytics.Flush()
+
+	results := ts.Gw.Analytics.Store.GetAndDeleteSet(analyticsKeyName)
+	require.Len(t, results, 2)
+
+	var versions []string
+	for _, result := range results {
+		var record analytics.AnalyticsRecord
+		require.NoError(t, ts.Gw.Analytics.analyticsSerializer.Decode([]byte(result.(string)), &record))
+		versions = append(versions, record.APIVersion)
+	}
+
+	assert.ElementsMatch(t, []string{"v1", "v2"}, versions)
+}
+
+func TestVersioning_Deprecation(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	baseAPI := BuildAPI(func(a *APISpec) {
+		a.APIID = "base"
+		a.Proxy.ListenPath = "/versioned"
+		a.VersionDefinition.Enabled = true
+		a.VersionDefinition.Name = "v1"
+		a.VersionDefinition.Default = apidef.Self
+		a.VersionDefinition.Location = apidef.URLParamLocation
+		a.VersionDefinition.Key = "version"
+		a.VersionDefinition.Versions = map[string]string{"v2": "v2-api-id"}
+	})[0]
+
+	v2 := BuildAPI(func(a *APISpec) {
+		a.APIID = "v2-api-id"
+		a.Proxy.ListenPath = "/versioned-v2"
+		a.Internal = true
+	})[0]
+
+	deprecatedAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	deprecatingAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	// the sunset passes during the test, without updating the definition
+	sunsetAt := time.Now().Add(2 * time.Second).UTC().Truncate(time.Second)
+
+	baseAPI.VersionDefinition.Sunset = map[string]apidef.VersionSunset{
+		"v1": {Deprecation: deprecatingAt.Format(time.RFC3339)},
+		"v2": {
+			Deprecation: deprecatedAt.Format(time.RFC3339),
+			Date:        sunsetAt.Format(time.RFC3339),
+			Message:     "v2 is retired, use v1",
+			Warning:     "v2 is deprecated",
+			Link:        "https://example.com/v2-deprecation",
+		},
+	}
+	ts.Gw.LoadAPI(baseAPI, v2)
+
+	events := make(chan EventVersionPhaseMeta, 10)
+	phases := map[apidef.TykEvent]string{}
+	handler := func(em config.EventMessage) {
+		meta, ok := em.Meta.(EventVersionPhaseMeta)
+		assert.True(t, ok)
+		phases[em.Type] = meta.Date
+		events <- meta
+	}
+	ts.Gw.getApiSpec("base").EventPaths = map[apidef.TykEvent][]config.TykEventHandler{
+		EventVersionDeprecated: {&testEventHandler{handler}},
+		EventVersionSunset:     {&testEventHandler{handler}},
+	}
+
+	waitEvent := func(t *testing.T) EventVersionPhaseMeta {
+		t.Helper()
+		select {
+		case meta := <-events:
+			return meta
+		case <-time.After(time.Second):
+			t.Fatal("expected a version phase event")
+		}
+		return EventVersionPhaseMeta{}
+	}
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{
+			Path: "/versioned?version=v1",
+			Code: http.StatusOK,
+			HeadersMatch: map[string]string{
+				header.Deprecation: "@" + strconv.FormatInt(deprecatingAt.Unix(), 10),
+				header.Warning:     "",
+				header.Sunset:      "",
+			},
+		},
+		{
+			Path: "/versioned?version=v2",
+			Code: http.StatusOK,
+			HeadersMatch: map[string]string{
+				header.Deprecation: "@" + strconv.FormatInt(deprecatedAt.Unix(), 10),
+				header.Sunset:      sunsetAt.Format(http.TimeFormat),
+				header.Warning:     `299 - "v2 is deprecated"`,
+				header.Link:        `<https://example.com/v2-deprecation>; rel="deprecation"`,
+			},
+		},
+		{Path: "/versioned?version=v2", Code: http.StatusOK},
+	}...)
+
+	meta := waitEvent(t)
+	assert.Equal(t, EventVersionPhaseMeta{
+		EventMetaDefault: EventMetaDefault{Message: "API version deprecated."},
+		APIID:            "base",
+		Version:          "v2",
+		Date:             deprecatedAt.Format(time.RFC3339),
+	}, meta)
+	assert.Empty(t, events, "the event only fires on the first request of the phase")
+
+	time.Sleep(time.Until(sunsetAt))
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{
+			Path:      "/versioned?version=v2",
+			Code:      http.StatusGone,
+			BodyMatch: "v2 is retired, use v1",
+			HeadersMatch: map[string]string{
+				header.Sunset:  sunsetAt.Format(http.TimeFormat),
+				header.Warning: "",
+			},
+		},
+		{Path: "/versioned?version=v2", Code: http.StatusGone},
+	}...)
+
+	meta = waitEvent(t)
+	assert.Equal(t, "v2", meta.Version)
+	assert.Equal(t, sunsetAt.Format(time.RFC3339), meta.Date)
+	assert.Empty(t, events)
+	assert.Equal(t, map[apidef.TykEvent]string{
+		EventVersionDeprecated: deprecatedAt.Format(time.RFC3339),
+		EventVersionSunset:     sunsetAt.Format(time.RFC3339),
+	}, phases)
+
+	t.Run("reload keeps the phase", func(t *testing.T) {
+		ts.Gw.LoadAPI(baseAPI, v2)
+		ts.Gw.getApiSpec("base").EventPaths = map[apidef.TykEvent][]config.TykEventHandler{
+			EventVersionSunset: {&testEventHandler{handler}},
+		}
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/versioned?version=v2", Code: http.StatusGone})
+
+		select {
+		case <-events:
+			t.Fatal("the phase didn't change")
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+}
@@ -207,7 +207,7 @@ func (d *VirtualEndpoint) ServeHTTPForCache(w http.ResponseWriter, r *http.Reque
 	if runner == nil {
 		return nil, errors.New("JSVM isn't initialized")
 	}
-	returnDataStr, err := runner.Run(expr)
+	returnDataStr, err := runner.RunContext(r.Context(), expr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to run JS middleware: %w", err)
 	}
@@ -57,6 +57,8 @@ type OAuthClient struct {
 	MetaData          interface{} `json:"meta_data,omitempty"`
 	PolicyID          string      `json:"policyid"`
 	Description       string      `json:"description"`
+	// Registration is set on clients registered with dynamic client registration.
+	Registration *OAuthClientRegistration `json:"registration,omitempty"`
 }
 
 func (oc *OAuthClient) GetId() string {
@@ -315,9 +317,21 @@ func RevokeAllTokens(storage ExtendedOsinStorageInterface, clientId, clientSecre
 		return http.StatusUnauthorized, resp, errors.New(oauthClientSecretWrong)
 	}
 
+	resp, err = revokeClientTokens(storage, clientId)
+	if err != nil {
+		return http.StatusBadRequest, resp, err
+	}
+
+	return http.StatusOK, resp, nil
+}
+
+// revokeClientTokens removes all access and refresh tokens issued to a client and
+// returns the revoked access tokens.
+func revokeClientTokens(storage ExtendedOsinStorageInterface, clientId string) ([]string, error) {
+	resp := []string{}
 	clientTokens, err := storage.GetClientTokens(clientId)
 	if err != nil {
-		return http.StatusBadRequest, resp, errors.New("cannot retrieve client tokens")
+		return resp, errors.New("cannot retrieve client tokens")
 	}
 
 	log.Debug("Tokens found to be revoked:", len(clientTokens))
@@ -332,7 +346,96 @@ func RevokeAllTokens(storage ExtendedOsinStorageInterface, clientId, clientSecre
 		}
 	}
 
-	return http.StatusOK, resp, nil
+	return resp, nil
+}
+
+// OAuthIntrospectionResponse is the token introspection response as described in
+// https://tools.ietf.org/html/rfc7662#section-2.2
+type OAuthIntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+}
+
+// HandleIntrospectToken implements token introspection in compliance with
+// https://tools.ietf.org/html/rfc7662. The caller must authenticate with the
+// credentials of an OAuth client registered for the API.
+func (o *OAuthHandlers) HandleIntrospectToken(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		doJSONWrite(w, http.StatusBadRequest, apiError("error parsing form. Form malformed"))
+		return
+	}
+
+	clientID, secret, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostFormValue("client_id")
+		secret = r.PostFormValue("client_secret")
+	}
+
+	if clientID == "" {
+		doJSONWrite(w, http.StatusUnauthorized, apiError(oauthClientIdEmpty))
+		return
+	}
+
+	if secret == "" {
+		doJSONWrite(w, http.StatusUnauthorized, apiError(oauthClientSecretEmpty))
+		return
+	}
+
+	client, err := o.Manager.OsinServer.Storage.GetClient(clientID)
+	if err != nil {
+		doJSONWrite(w, http.StatusUnauthorized, apiError(oAuthClientNotFound))
+		return
+	}
+
+	if client.GetSecret() != secret {
+		doJSONWrite(w, http.StatusUnauthorized, apiError(oauthClientSecretWrong))
+		return
+	}
+
+	token := r.PostFormValue("token")
+	if token == "" {
+		doJSONWrite(w, http.StatusBadRequest, apiError(oauthTokenEmpty))
+		return
+	}
+
+	doJSONWrite(w, http.StatusOK, IntrospectToken(o.Manager.OsinServer.Storage, o.Manager.Gw.GlobalSessionManager, o.Manager.API.OrgID, token))
+}
+
+// IntrospectToken looks up the stored access data and session for token. Unknown, expired
+// and revoked tokens are reported as inactive rather than as an error.
+func IntrospectToken(storage ExtendedOsinStorageInterface, sessionManager SessionHandler, orgID, token string) OAuthIntrospectionResponse {
+	inactive := OAuthIntrospectionResponse{Active: false}
+
+	access, err := storage.LoadAccess(token)
+	if err != nil || access.IsExpired() {
+		return inactive
+	}
+
+	session, found := sessionManager.SessionDetail(orgID, token, false)
+	if !found || sessionManager.KeyExpired(&session) {
+		return inactive
+	}
+
+	resp := OAuthIntrospectionResponse{
+		Active:    true,
+		Scope:     access.Scope,
+		Username:  session.Alias,
+		TokenType: "bearer",
+		Exp:       access.ExpireAt().Unix(),
+		Iat:       access.CreatedAt.Unix(),
+	}
+
+	if access.Client != nil {
+		resp.ClientID = access.Client.GetId()
+	}
+
+	return resp
 }
 
 // OAuthManager handles and wraps osin OAuth2 functions to handle authorise and access requests
@@ -350,7 +453,13 @@ func (o *OAuthManager) Storage() ExtendedOsinStorageInterface {
 func (o *OAuthManager) HandleAuthorisation(r *http.Request, complete bool, session string) *osin.Response {
 	resp := o.OsinServer.NewResponse()
 
-	if ar := o.OsinServer.HandleAuthorizeRequest(resp, r); ar != nil {
+	ar := o.OsinServer.HandleAuthorizeRequest(resp, r)
+	if ar != nil && !registeredGrantTypeAllowed(ar.Client, authorizeGrantType(ar.Type)) {
+		resp.SetErrorState(osin.E_UNAUTHORIZED_CLIENT, "", ar.State)
+		ar = nil
+	}
+
+	if ar != nil {
 		// Since this is called by the Reource provider (proxied API), we assume it has been approved
 		ar.Authorized = true
 
@@ -401,7 +510,22 @@ func (o *OAuthManager) HandleAccess(r *http.Request) *osin.Response {
 	}
 	var username string
 
-	if ar := o.OsinServer.HandleAccessRequest(resp, r); ar != nil {
+	ar := o.OsinServer.HandleAccessRequest(resp, r)
+	if ar != nil && !registeredGrantTypeAllowed(ar.Client, string(ar.Type)) {
+		resp.SetError(osin.E_UNAUTHORIZED_CLIENT, "")
+		ar = nil
+	}
+
+	rotateRefresh := o.API.Oauth2Meta.RotateRefreshTokens && r.Form.Get("grant_type") == string(osin.REFRESH_TOKEN)
+	if rotateRefresh && ar == nil {
+		o.detectRefreshTokenReuse(resp, r)
+	}
+
+	if rotateRefresh && ar != nil && !o.claimRefreshToken(resp, ar) {
+		ar = nil
+	}
+
+	if ar != nil {
 
 		var session *user.SessionState
 		if ar.Type == osin.PASSWORD {
@@ -462,6 +586,10 @@ func (o *OAuthManager) HandleAccess(r *http.Request) *osin.Response {
 
 		log.Debug("[OAuth] Finishing access request ")
 		o.OsinServer.FinishAccessRequest(resp, r, ar)
+		if rotateRefresh && resp.IsError {
+			// the refresh token hasn't been exchanged, it can be used again
+			o.OsinServer.Storage.ReleaseRefreshToken(ar.Client.GetId(), ar.Code)
+		}
 		new_token, foundNewToken := resp.Output["access_token"]
 		if username != "" && foundNewToken {
 			log.Debug("Updating token data in key")
@@ -512,6 +640,80 @@ func (o *OAuthManager) HandleAccess(r *http.Request) *osin.Response {
 	return resp
 }
 
+// claimRefreshToken makes sure a refresh token can only be exchanged once when refresh token
+// rotation is enabled. It's called once osin has authenticated the client and validated the
+// refresh token, so the token can't be claimed by a request which wouldn't exchange it.
+// It returns false if the request must be rejected.
+func (o *OAuthManager) claimRefreshToken(resp *osin.Response, ar *osin.AccessRequest) bool {
+	if o.OsinServer.Storage.ClaimRefreshToken(ar.Client.GetId(), ar.Code) {
+		return true
+	}
+
+	log.WithFields(logrus.Fields{
+		"org_id":    o.API.OrgID,
+		"client_id": ar.Client.GetId(),
+	}).Warning("[OAuth] Refresh token is already being exchanged")
+
+	resp.SetError(osin.E_INVALID_GRANT, "")
+	return false
+}
+
+// detectRefreshTokenReuse handles a refresh token rejected by osin. A refresh token which has
+// been exchanged before is treated as leaked: every token issued to the client is revoked and
+// a RefreshTokenReused event is fired. Osin validates the refresh token after authenticating the
+// client, so an invalid_grant error for a given refresh token means the client is authenticated.
+func (o *OAuthManager) detectRefreshTokenReuse(resp *osin.Response, r *http.Request) {
+	token := r.Form.Get("refresh_token")
+	if resp.Output["error"] != osin.E_INVALID_GRANT || token == "" {
+		return
+	}
+
+	// the client is authenticated as osin does it
+	var clientID string
+	if _, hasSecret := r.Form["client_secret"]; hasSecret && o.OsinServer.Config.AllowClientSecretInParams {
+		clientID = r.Form.Get("client_id")
+	}
+	if auth, err := osin.CheckBasicAuth(r); clientID == "" && err == nil && auth != nil {
+		clientID = auth.Username
+	}
+
+	if clientID != "" && o.OsinServer.Storage.RefreshTokenClaimed(clientID, token) {
+		o.handleRefreshTokenReuse(clientID, r)
+	}
+}
+
+func (o *OAuthManager) handleRefreshTokenReuse(clientID string, r *http.Request) {
+	log.WithFields(logrus.Fields{
+		"org_id":     o.API.OrgID,
+		"client_id":  clientID,
+		"RemoteAddr": request.RealIP(r),
+	}).Warning("[OAuth] Refresh token reuse detected, revoking all client tokens")
+
+	tokens, err := revokeClientTokens(o.OsinServer.Storage, clientID)
+	if err != nil {
+		log.WithError(err).Error("[OAuth] Could not revoke client tokens")
+	}
+
+	if len(tokens) > 0 {
+		o.Gw.MainNotifier.Notify(Notification{
+			Command: KeySpaceUpdateNotification,
+			Payload: strings.Join(tokens, ","),
+			Gw:      o.Gw,
+		})
+	}
+
+	o.API.FireEvent(EventRefreshTokenReused, EventRefreshTokenReusedMeta{
+		EventMetaDefault: EventMetaDefault{
+			Message:            "Refresh token reuse detected, all client tokens revoked",
+			OriginatingRequest: EncodeRequestToEvent(r),
+		},
+		APIID:    o.API.APIID,
+		OrgID:    o.API.OrgID,
+		ClientID: clientID,
+		Revoked:  len(tokens),
+	})
+}
+
 // These enums fix the prefix to use when storing various OAuth keys and data, since we
 // delegate everything to the osin framework
 const (
@@ -522,6 +724,7 @@ const (
 	prefixClientset       = "oauth-clientset."
 	prefixClientIndexList = "oauth-client-index."
 	prefixClientTokens    = "oauth-client-tokens."
+	prefixRefreshClaimed  = "oauth-refresh-claimed."
 )
 
 // swagger:model
@@ -561,6 +764,21 @@ type ExtendedOsinStorageInterface interface {
 
 	// SetUser updates a Basic Access user token type in the key store
 	SetUser(string, *user.SessionState, int64) error
+
+	// ClaimRefreshToken atomically marks a refresh token as exchanged,
+	// it returns false if the token has been claimed before
+	ClaimRefreshToken(clientID, token string) bool
+
+	// ReleaseRefreshToken releases the claim of a refresh token which couldn't be exchanged
+	ReleaseRefreshToken(clientID, token string)
+
+	// RefreshTokenClaimed reports whether a refresh token has been exchanged before
+	RefreshTokenClaimed(clientID, token string) bool
+}
+
+// sortedSetMemberRemover is implemented by the storage removing sorted set members atomically
+type sortedSetMemberRemover interface {
+	RemoveFromSortedSet(keyName string, values ...string) error
 }
 
 // TykOsinServer subclasses osin.Server so we can add the SetClient method without wrecking the lbrary
@@ -1010,6 +1228,15 @@ func (r *RedisOsinStorageInterface) SaveAccess(accessData *osin.AccessData) erro
 		}
 	}
 
+	// Record the scope granted to the token, checked by the scope access of the API
+	if accessData.Scope != "" {
+		if newSession.MetaData == nil {
+			newSession.MetaData = make(map[string]interface{})
+		}
+
+		newSession.MetaData[sessionScopeMetaKey] = accessData.Scope
+	}
+
 	sessionLifetime := r.Gw.ApplyLifetime(newSession)
 
 	// Use the default session expiry here as this is OAuth
@@ -1022,12 +1249,8 @@ func (r *RedisOsinStorageInterface) SaveAccess(accessData *osin.AccessData) erro
 			return err
 		}
 		key := prefixRefresh + accessData.RefreshToken
-		refreshExpire := int64(1209600) // 14 days
-		if oauthRefreshExpire := r.Gw.GetConfig().OauthRefreshExpire; oauthRefreshExpire != 0 {
-			refreshExpire = oauthRefreshExpire
-		}
 		log.Debug("STORING ACCESS DATA: ", string(accessDataJSON))
-		err = r.store.SetKey(key, string(accessDataJSON), refreshExpire)
+		err = r.store.SetKey(key, string(accessDataJSON), r.refreshExpire())
 		if err != nil {
 			log.WithError(err).Error("could not save access data")
 		}
@@ -1071,8 +1294,14 @@ func (r *RedisOsinStorageInterface) RemoveAccess(token string) error {
 		key := prefixClientTokens + access.Client.GetId()
 		//remove from set oauth.client-tokens
 		log.Info("removing token from oauth client tokens list")
-		limit := strconv.FormatFloat(float64(access.ExpireAt().Unix()), 'f', 0, 64)
-		r.redisStore.RemoveSortedSetRange(key, limit, limit)
+		// tokens expiring within the same second share the score, remove only this one
+		hashedToken := storage.HashKey(access.AccessToken, r.Gw.GetConfig().HashKeys)
+		if remover, ok := r.redisStore.(sortedSetMemberRemover); ok {
+			remover.RemoveFromSortedSet(key, hashedToken, access.AccessToken)
+		} else {
+			limit := strconv.FormatFloat(float64(access.ExpireAt().Unix()), 'f', 0, 64)
+			r.redisStore.RemoveSortedSetRange(key, limit, limit)
+		}
 	} else {
 		log.Warning("Cannot load access token:", token)
 	}
@@ -1114,6 +1343,34 @@ func (r *RedisOsinStorageInterface) RemoveRefresh(token string) error {
 	return nil
 }
 
+func (r *RedisOsinStorageInterface) refreshExpire() int64 {
+	if oauthRefreshExpire := r.Gw.GetConfig().OauthRefreshExpire; oauthRefreshExpire != 0 {
+		return oauthRefreshExpire
+	}
+	return 1209600 // 14 days
+}
+
+func (r *RedisOsinStorageInterface) refreshClaimKey(clientID, token string) string {
+	return r.redisStore.GetKeyPrefix() + prefixRefreshClaimed + clientID + "." + storage.HashStr(token)
+}
+
+// ClaimRefreshToken atomically marks a refresh token as exchanged. The claim is kept
+// for as long as the refresh token could have been valid, so reuse can be detected.
+func (r *RedisOsinStorageInterface) ClaimRefreshToken(clientID, token string) bool {
+	return r.redisStore.IncrememntWithExpire(r.refreshClaimKey(clientID, token), r.refreshExpire()) == 1
+}
+
+// ReleaseRefreshToken releases the claim of a refresh token which couldn't be exchanged
+func (r *RedisOsinStorageInterface) ReleaseRefreshToken(clientID, token string) {
+	r.redisStore.DeleteRawKey(r.refreshClaimKey(clientID, token))
+}
+
+// RefreshTokenClaimed reports whether a refresh token has been exchanged before
+func (r *RedisOsinStorageInterface) RefreshTokenClaimed(clientID, token string) bool {
+	_, err := r.redisStore.GetRawKey(r.refreshClaimKey(clientID, token))
+	return err == nil
+}
+
 // accessTokenGen is a modified authorization token generator that uses the same method used to generate tokens for Tyk authHandler
 type accessTokenGen struct {
 	Gw *Gateway `json:"-"`
@@ -0,0 +1,325 @@
+package gateway
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lonelycode/osin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/crypto"
+	"github.com/TykTechnologies/tyk/internal/uuid"
+)
+
+// Errors of dynamic client registration, see https://tools.ietf.org/html/rfc7591#section-3.2.2.
+const (
+	oauthRegistrationInvalidRedirectURI    = "invalid_redirect_uri"
+	oauthRegistrationInvalidClientMetadata = "invalid_client_metadata"
+	oauthRegistrationInvalidToken          = "invalid_token"
+	oauthRegistrationServerError           = "server_error"
+)
+
+// oauthImplicitGrantType is the grant type of the implicit flow, allowed by the token authorize type.
+const oauthImplicitGrantType = "implicit"
+
+// OAuthClientRegistration is the registration state of a dynamically registered OAuth client.
+type OAuthClientRegistration struct {
+	// TokenHash is the SHA-256 hash of the registration access token of the client.
+	TokenHash  string   `json:"token_hash"`
+	GrantTypes []string `json:"grant_types"`
+	IssuedAt   int64    `json:"issued_at"`
+}
+
+// OAuthClientMetadata is the metadata of a dynamically registered OAuth client.
+type OAuthClientMetadata struct {
+	RedirectURIs []string `json:"redirect_uris,omitempty"`
+	GrantTypes   []string `json:"grant_types,omitempty"`
+	ClientName   string   `json:"client_name,omitempty"`
+}
+
+// OAuthClientInformation is the response of dynamic client registration and client configuration requests.
+type OAuthClientInformation struct {
+	OAuthClientMetadata
+	ClientID                string `json:"client_id"`
+	ClientSecret            string `json:"client_secret"`
+	ClientIDIssuedAt        int64  `json:"client_id_issued_at"`
+	ClientSecretExpiresAt   int64  `json:"client_secret_expires_at"`
+	RegistrationAccessToken string `json:"registration_access_token,omitempty"`
+	RegistrationClientURI   string `json:"registration_client_uri"`
+}
+
+type oauthRegistrationError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// HandleRegisterClient implements dynamic client registration in compliance with
+// https://tools.ietf.org/html/rfc7591. The caller must present the initial access
+// token configured on the API as a bearer token.
+func (o *OAuthHandlers) HandleRegisterClient(w http.ResponseWriter, r *http.Request) {
+	initialAccessToken := o.Manager.API.Oauth2Meta.ClientRegistration.InitialAccessToken
+	if initialAccessToken == "" || !tokensEqual(bearerToken(r), initialAccessToken) {
+		writeOAuthRegistrationUnauthorized(w)
+		return
+	}
+
+	var meta OAuthClientMetadata
+	if err := json.NewDecoder(r.Body).Decode(&meta); err != nil {
+		doJSONWrite(w, http.StatusBadRequest, oauthRegistrationError{oauthRegistrationInvalidClientMetadata, "malformed client metadata"})
+		return
+	}
+
+	if regErr := o.validateClientMetadata(&meta); regErr != nil {
+		doJSONWrite(w, http.StatusBadRequest, regErr)
+		return
+	}
+
+	client := OAuthClient{
+		ClientID:     uuid.NewHex(),
+		ClientSecret: createOauthClientSecret(),
+		PolicyID:     o.Manager.API.Oauth2Meta.ClientRegistration.PolicyID,
+		Registration: &OAuthClientRegistration{IssuedAt: time.Now().Unix()},
+	}
+
+	info, ok := o.storeRegisteredClient(w, &client, meta)
+	if !ok {
+		return
+	}
+	info.RegistrationClientURI = registrationClientURI(r, strings.TrimSuffix(r.URL.Path, "/")+"/"+client.ClientID)
+
+	log.WithFields(logrus.Fields{
+		"prefix":   "api",
+		"apiID":    o.Manager.API.APIID,
+		"clientID": client.ClientID,
+		"status":   "ok",
+	}).Info("Registered OAuth client")
+
+	doJSONWrite(w, http.StatusCreated, info)
+}
+
+// HandleClientConfiguration implements the management of a dynamically registered client in compliance
+// with https://tools.ietf.org/html/rfc7592. The caller must present the registration access token of the
+// client as a bearer token. Updating the client issues a new client secret and registration access token.
+func (o *OAuthHandlers) HandleClientConfiguration(w http.ResponseWriter, r *http.Request) {
+	clientID := mux.Vars(r)["clientID"]
+	storageID := oauthClientStorageID(clientID)
+
+	client, ok := o.registeredClient(storageID, bearerToken(r))
+	if !ok {
+		writeOAuthRegistrationUnauthorized(w)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		info := o.clientInformation(client)
+		info.RegistrationClientURI = registrationClientURI(r, r.URL.Path)
+		doJSONWrite(w, http.StatusOK, info)
+	case http.MethodPut:
+		var update struct {
+			OAuthClientMetadata
+			ClientID string `json:"client_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			doJSONWrite(w, http.StatusBadRequest, oauthRegistrationError{oauthRegistrationInvalidClientMetadata, "malformed client metadata"})
+			return
+		}
+
+		if update.ClientID != client.ClientID {
+			doJSONWrite(w, http.StatusBadRequest, oauthRegistrationError{oauthRegistrationInvalidClientMetadata, "client_id doesn't match the client"})
+			return
+		}
+
+		if regErr := o.validateClientMetadata(&update.OAuthClientMetadata); regErr != nil {
+			doJSONWrite(w, http.StatusBadRequest, regErr)
+			return
+		}
+
+		client.ClientSecret = createOauthClientSecret()
+
+		info, ok := o.storeRegisteredClient(w, client, update.OAuthClientMetadata)
+		if !ok {
+			return
+		}
+		info.RegistrationClientURI = registrationClientURI(r, r.URL.Path)
+
+		doJSONWrite(w, http.StatusOK, info)
+	case http.MethodDelete:
+		if err := o.Manager.Storage().DeleteClient(storageID, o.Manager.API.OrgID, true); err != nil {
+			doJSONWrite(w, http.StatusInternalServerError, oauthRegistrationError{Error: oauthRegistrationServerError})
+			return
+		}
+
+		log.WithFields(logrus.Fields{
+			"prefix":   "api",
+			"apiID":    o.Manager.API.APIID,
+			"clientID": clientID,
+			"status":   "ok",
+		}).Info("Deleted registered OAuth client")
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// validateClientMetadata validates the requested metadata against the grants of the API, defaulting the grant types.
+func (o *OAuthHandlers) validateClientMetadata(meta *OAuthClientMetadata) *oauthRegistrationError {
+	if len(meta.GrantTypes) == 0 {
+		meta.GrantTypes = []string{string(osin.AUTHORIZATION_CODE)}
+	}
+
+	for _, grantType := range meta.GrantTypes {
+		if !o.grantTypeAllowed(grantType) {
+			return &oauthRegistrationError{oauthRegistrationInvalidClientMetadata, "grant type " + grantType + " isn't allowed"}
+		}
+	}
+
+	// Clients without a redirect URI are refused by every grant, whether it redirects or not.
+	if len(meta.RedirectURIs) == 0 {
+		return &oauthRegistrationError{oauthRegistrationInvalidRedirectURI, "redirect_uris is required"}
+	}
+
+	if len(meta.RedirectURIs) > 1 && o.Manager.Gw.GetConfig().OauthRedirectUriSeparator == "" {
+		return &oauthRegistrationError{oauthRegistrationInvalidRedirectURI, "a single redirect URI is supported"}
+	}
+
+	for _, redirectURI := range meta.RedirectURIs {
+		u, err := url.Parse(redirectURI)
+		if err != nil || u.Scheme == "" || u.Host == "" || u.Fragment != "" {
+			return &oauthRegistrationError{oauthRegistrationInvalidRedirectURI, "invalid redirect URI " + redirectURI}
+		}
+	}
+
+	return nil
+}
+
+func (o *OAuthHandlers) grantTypeAllowed(grantType string) bool {
+	if grantType == oauthImplicitGrantType {
+		return slices.Contains(o.Manager.API.Oauth2Meta.AllowedAuthorizeTypes, osin.TOKEN)
+	}
+
+	return slices.Contains(o.Manager.API.Oauth2Meta.AllowedAccessTypes, osin.AccessRequestType(grantType))
+}
+
+// registeredGrantTypeAllowed reports whether the client may use the grant type.
+// Dynamically registered clients are limited to their registered grant types, other clients to the grants of the API.
+func registeredGrantTypeAllowed(client osin.Client, grantType string) bool {
+	oauthClient, ok := client.(*OAuthClient)
+	if !ok || oauthClient.Registration == nil {
+		return true
+	}
+
+	return slices.Contains(oauthClient.Registration.GrantTypes, grantType)
+}
+
+// authorizeGrantType returns the grant type of the authorize request type.
+func authorizeGrantType(authorizeType osin.AuthorizeRequestType) string {
+	if authorizeType == osin.TOKEN {
+		return oauthImplicitGrantType
+	}
+
+	return string(osin.AUTHORIZATION_CODE)
+}
+
+// registeredClient returns the dynamically registered client of the registration access token.
+func (o *OAuthHandlers) registeredClient(storageID, token string) (*OAuthClient, bool) {
+	if token == "" {
+		return nil, false
+	}
+
+	extendedClient, err := o.Manager.Storage().GetExtendedClientNoPrefix(storageID)
+	if err != nil {
+		return nil, false
+	}
+
+	client, ok := extendedClient.(*OAuthClient)
+	if !ok || client.Registration == nil {
+		return nil, false
+	}
+
+	return client, tokensEqual(crypto.HashStr(token, crypto.HashSha256), client.Registration.TokenHash)
+}
+
+// storeRegisteredClient applies the metadata to the client with a new registration access token and stores it.
+// On failure, the error is written and false is returned.
+func (o *OAuthHandlers) storeRegisteredClient(w http.ResponseWriter, client *OAuthClient, meta OAuthClientMetadata) (OAuthClientInformation, bool) {
+	registrationAccessToken := uuid.NewHex()
+
+	client.ClientRedirectURI = strings.Join(meta.RedirectURIs, o.Manager.Gw.GetConfig().OauthRedirectUriSeparator)
+	client.Description = meta.ClientName
+	client.Registration.GrantTypes = meta.GrantTypes
+	client.Registration.TokenHash = crypto.HashStr(registrationAccessToken, crypto.HashSha256)
+
+	err := o.Manager.Storage().SetClient(oauthClientStorageID(client.ClientID), o.Manager.API.OrgID, client, true)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix": "api",
+			"apiID":  o.Manager.API.APIID,
+			"status": "fail",
+			"err":    err,
+		}).Error("Failed to store registered OAuth client")
+		doJSONWrite(w, http.StatusInternalServerError, oauthRegistrationError{Error: oauthRegistrationServerError})
+		return OAuthClientInformation{}, false
+	}
+
+	info := o.clientInformation(client)
+	info.RegistrationAccessToken = registrationAccessToken
+
+	return info, true
+}
+
+func (o *OAuthHandlers) clientInformation(client *OAuthClient) OAuthClientInformation {
+	var redirectURIs []string
+	if client.ClientRedirectURI != "" {
+		redirectURIs = []string{client.ClientRedirectURI}
+		if separator := o.Manager.Gw.GetConfig().OauthRedirectUriSeparator; separator != "" {
+			redirectURIs = strings.Split(client.ClientRedirectURI, separator)
+		}
+	}
+
+	return OAuthClientInformation{
+		OAuthClientMetadata: OAuthClientMetadata{
+			RedirectURIs: redirectURIs,
+			GrantTypes:   client.Registration.GrantTypes,
+			ClientName:   client.Description,
+		},
+		ClientID:         client.ClientID,
+		ClientSecret:     client.ClientSecret,
+		ClientIDIssuedAt: client.Registration.IssuedAt,
+	}
+}
+
+// registrationClientURI returns the absolute URL of the client configuration endpoint at path.
+func registrationClientURI(r *http.Request, path string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return (&url.URL{Scheme: scheme, Host: r.Host, Path: path}).String()
+}
+
+func writeOAuthRegistrationUnauthorized(w http.ResponseWriter) {
+	w.Header().Set(header.WWWAuthenticate, `Bearer error="`+oauthRegistrationInvalidToken+`"`)
+	doJSONWrite(w, http.StatusUnauthorized, oauthRegistrationError{Error: oauthRegistrationInvalidToken})
+}
+
+// bearerToken returns the bearer token of the Authorization header of the request.
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get(header.Authorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+
+	return strings.TrimSpace(token)
+}
+
+func tokensEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,211 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestOAuthClientRegistration(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	policyID := ts.CreatePolicy(func(p *user.Policy) {
+		p.AccessRights = map[string]user.AccessDefinition{
+			"999999": {APIID: "999999", Versions: []string{"v1"}},
+		}
+	})
+
+	ts.Gw.LoadAPI(buildTestOAuthSpec(func(spec *APISpec) {
+		spec.Oauth2Meta.ClientRegistration.Enabled = true
+		spec.Oauth2Meta.ClientRegistration.InitialAccessToken = "initial-token"
+		spec.Oauth2Meta.ClientRegistration.PolicyID = policyID
+	}))
+
+	bearer := func(token string) map[string]string {
+		return map[string]string{header.Authorization: "Bearer " + token}
+	}
+
+	decode := func(t *testing.T, resp *http.Response) OAuthClientInformation {
+		t.Helper()
+		var info OAuthClientInformation
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+		return info
+	}
+
+	t.Run("registration errors", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/APIID/oauth/register", Method: http.MethodPost, Data: `{}`, Code: http.StatusUnauthorized},
+			{Path: "/APIID/oauth/register", Method: http.MethodPost, Data: `{}`, Headers: bearer("wrong"), Code: http.StatusUnauthorized},
+			{Path: "/APIID/oauth/register", Method: http.MethodPost, Data: `{"grant_types": ["password"]}`, Headers: bearer("initial-token"),
+				Code: http.StatusBadRequest, BodyMatch: `"error":"invalid_client_metadata"`},
+			{Path: "/APIID/oauth/register", Method: http.MethodPost, Data: `{"grant_types": ["client_credentials"]}`, Headers: bearer("initial-token"),
+				Code: http.StatusBadRequest, BodyMatch: `"error":"invalid_redirect_uri"`},
+			{Path: "/APIID/oauth/register", Method: http.MethodPost, Data: `{"redirect_uris": ["/relative"]}`, Headers: bearer("initial-token"),
+				Code: http.StatusBadRequest, BodyMatch: `"error":"invalid_redirect_uri"`},
+		}...)
+	})
+
+	var registered OAuthClientInformation
+
+	t.Run("register", func(t *testing.T) {
+		resp, err := ts.Run(t, test.TestCase{
+			Path:    "/APIID/oauth/register",
+			Method:  http.MethodPost,
+			Data:    `{"client_name": "partner", "grant_types": ["client_credentials", "authorization_code"], "redirect_uris": ["http://partner.example.com/cb"]}`,
+			Headers: bearer("initial-token"),
+			Code:    http.StatusCreated,
+		})
+		require.NoError(t, err)
+
+		registered = decode(t, resp)
+		assert.NotEmpty(t, registered.ClientID)
+		assert.NotEmpty(t, registered.ClientSecret)
+		assert.NotEmpty(t, registered.RegistrationAccessToken)
+		assert.NotZero(t, registered.ClientIDIssuedAt)
+		assert.True(t, strings.HasSuffix(registered.RegistrationClientURI, "/APIID/oauth/register/"+registered.ClientID))
+		assert.Equal(t, OAuthClientMetadata{
+			ClientName:   "partner",
+			GrantTypes:   []string{"client_credentials", "authorization_code"},
+			RedirectURIs: []string{"http://partner.example.com/cb"},
+		}, registered.OAuthClientMetadata)
+	})
+
+	clientPath := func() string {
+		return "/APIID/oauth/register/" + registered.ClientID
+	}
+
+	accessToken := func(t *testing.T, secret string, code int) string {
+		t.Helper()
+		resp, err := ts.Run(t, test.TestCase{
+			Path:   "/APIID/oauth/token/",
+			Method: http.MethodPost,
+			Data:   url.Values{"grant_type": {"client_credentials"}}.Encode(),
+			Headers: map[string]string{
+				header.ContentType:   "application/x-www-form-urlencoded",
+				header.Authorization: "Basic " + base64.StdEncoding.EncodeToString([]byte(url.QueryEscape(registered.ClientID)+":"+url.QueryEscape(secret))),
+			},
+			Code: code,
+		})
+		require.NoError(t, err)
+
+		var token tokenData
+		_ = json.NewDecoder(resp.Body).Decode(&token)
+		return token.AccessToken
+	}
+
+	t.Run("use", func(t *testing.T) {
+		token := accessToken(t, registered.ClientSecret, http.StatusOK)
+		require.NotEmpty(t, token)
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/APIID/", Headers: bearer(token), Code: http.StatusOK})
+
+		resp, err := ts.Run(t, test.TestCase{Path: clientPath(), Headers: bearer(registered.RegistrationAccessToken), Code: http.StatusOK})
+		require.NoError(t, err)
+
+		info := decode(t, resp)
+		assert.Equal(t, registered.ClientSecret, info.ClientSecret)
+		assert.Empty(t, info.RegistrationAccessToken)
+		assert.Equal(t, registered.OAuthClientMetadata, info.OAuthClientMetadata)
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: clientPath(), Headers: bearer("initial-token"), Code: http.StatusUnauthorized},
+			{Path: "/APIID/oauth/register/unknown", Headers: bearer(registered.RegistrationAccessToken), Code: http.StatusUnauthorized},
+		}...)
+	})
+
+	t.Run("rotate", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{
+			Path: clientPath(), Method: http.MethodPut, Headers: bearer(registered.RegistrationAccessToken),
+			Data: `{"client_id": "other", "grant_types": ["client_credentials"]}`, Code: http.StatusBadRequest,
+		})
+
+		resp, err := ts.Run(t, test.TestCase{
+			Path:    clientPath(),
+			Method:  http.MethodPut,
+			Headers: bearer(registered.RegistrationAccessToken),
+			Data:    `{"client_id": "` + registered.ClientID + `", "client_name": "partner v2", "grant_types": ["client_credentials"], "redirect_uris": ["http://partner.example.com/v2"]}`,
+			Code:    http.StatusOK,
+		})
+		require.NoError(t, err)
+
+		rotated := decode(t, resp)
+		assert.Equal(t, registered.ClientID, rotated.ClientID)
+		assert.Equal(t, registered.ClientIDIssuedAt, rotated.ClientIDIssuedAt)
+		assert.NotEqual(t, registered.ClientSecret, rotated.ClientSecret)
+		assert.NotEqual(t, registered.RegistrationAccessToken, rotated.RegistrationAccessToken)
+		assert.Equal(t, OAuthClientMetadata{
+			ClientName:   "partner v2",
+			GrantTypes:   []string{"client_credentials"},
+			RedirectURIs: []string{"http://partner.example.com/v2"},
+		}, rotated.OAuthClientMetadata)
+
+		_, _ = ts.Run(t, test.TestCase{Path: clientPath(), Headers: bearer(registered.RegistrationAccessToken), Code: http.StatusUnauthorized})
+		accessToken(t, registered.ClientSecret, http.StatusForbidden)
+
+		registered = rotated
+		assert.NotEmpty(t, accessToken(t, registered.ClientSecret, http.StatusOK))
+	})
+
+	t.Run("unregistered grant types", func(t *testing.T) {
+		authorize := url.Values{
+			"response_type": {"code"},
+			"client_id":     {registered.ClientID},
+			"redirect_uri":  {"http://partner.example.com/v2"},
+		}
+
+		_, _ = ts.Run(t, test.TestCase{
+			Path: "/APIID/tyk/oauth/authorize-client/", AdminAuth: true, Method: http.MethodPost, Data: authorize.Encode(),
+			Headers: map[string]string{header.ContentType: "application/x-www-form-urlencoded"},
+			Code:    http.StatusForbidden, BodyMatch: `"error":"unauthorized_client"`,
+		})
+
+		resp, err := ts.Run(t, test.TestCase{
+			Path:    "/APIID/oauth/register",
+			Method:  http.MethodPost,
+			Data:    `{"grant_types": ["authorization_code"], "redirect_uris": ["http://partner.example.com/cb"]}`,
+			Headers: bearer("initial-token"),
+			Code:    http.StatusCreated,
+		})
+		require.NoError(t, err)
+
+		codeOnly := decode(t, resp)
+		_, _ = ts.Run(t, test.TestCase{
+			Path:   "/APIID/oauth/token/",
+			Method: http.MethodPost,
+			Data:   url.Values{"grant_type": {"client_credentials"}}.Encode(),
+			Headers: map[string]string{
+				header.ContentType:   "application/x-www-form-urlencoded",
+				header.Authorization: "Basic " + base64.StdEncoding.EncodeToString([]byte(url.QueryEscape(codeOnly.ClientID)+":"+url.QueryEscape(codeOnly.ClientSecret))),
+			},
+			Code: http.StatusForbidden, BodyMatch: `"error":"unauthorized_client"`,
+		})
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: clientPath(), Method: http.MethodDelete, Headers: bearer(registered.RegistrationAccessToken), Code: http.StatusNoContent},
+			{Path: clientPath(), Headers: bearer(registered.RegistrationAccessToken), Code: http.StatusUnauthorized},
+		}...)
+
+		accessToken(t, registered.ClientSecret, http.StatusForbidden)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		ts.Gw.LoadAPI(buildTestOAuthSpec())
+
+		_, _ = ts.Run(t, test.TestCase{
+			Path: "/APIID/oauth/register", Method: http.MethodPost, Data: `{}`, Headers: bearer("initial-token"), Code: http.StatusForbidden,
+		})
+	})
+}
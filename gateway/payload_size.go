@@ -0,0 +1,245 @@
+package gateway
+
+import (
+	"io"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/gocraft/health"
+
+	"github.com/TykTechnologies/tyk/ctx"
+)
+
+const (
+	payloadDirectionRequest  = "request"
+	payloadDirectionResponse = "response"
+)
+
+// payloadSizes is the size in bytes of the request and response bodies of a proxied request.
+type payloadSizes struct {
+	request  int64
+	response int64
+}
+
+// ctxGetOrCreatePayloadSizes returns the payload sizes of the request, starting them on the first call.
+func ctxGetOrCreatePayloadSizes(r *http.Request) *payloadSizes {
+	if s := ctxGetPayloadSizes(r); s != nil {
+		return s
+	}
+
+	s := &payloadSizes{}
+	setCtxValue(r, ctx.PayloadSizes, s)
+	return s
+}
+
+func ctxGetPayloadSizes(r *http.Request) *payloadSizes {
+	s, _ := r.Context().Value(ctx.PayloadSizes).(*payloadSizes)
+	return s
+}
+
+// countRequest records the size of the body sent upstream. Bodies of unknown length, e.g. chunked
+// ones, are counted as the transport reads them rather than buffered.
+func (s *payloadSizes) countRequest(outreq *http.Request) {
+	if outreq.Body == nil || outreq.Body == http.NoBody {
+		return
+	}
+
+	if outreq.ContentLength >= 0 {
+		atomic.StoreInt64(&s.request, outreq.ContentLength)
+		return
+	}
+
+	// seekable bodies are kept as they are, the retry with refreshed upstream auth rewinds them
+	if seeker, ok := outreq.Body.(io.Seeker); ok {
+		if size, err := seeker.Seek(0, io.SeekEnd); err == nil {
+			if _, err := seeker.Seek(0, io.SeekStart); err == nil {
+				atomic.StoreInt64(&s.request, size)
+				return
+			}
+		}
+	}
+
+	outreq.Body = &countingReadCloser{ReadCloser: outreq.Body, n: &s.request}
+}
+
+// countResponse counts the bytes of the response body as they are copied to the client.
+func (s *payloadSizes) countResponse(res *http.Response) {
+	if res.Body == nil || res.Body == http.NoBody {
+		return
+	}
+
+	res.Body = &countingReadCloser{ReadCloser: res.Body, n: &s.response}
+}
+
+func (s *payloadSizes) requestSize() int64 {
+	return atomic.LoadInt64(&s.request)
+}
+
+func (s *payloadSizes) responseSize() int64 {
+	return atomic.LoadInt64(&s.response)
+}
+
+// countingReadCloser adds the number of bytes read from the wrapped body to n.
+type countingReadCloser struct {
+	io.ReadCloser
+	n *int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	atomic.AddInt64(c.n, int64(n))
+	return n, err
+}
+
+// payloadSizeBucket aggregates the payload sizes seen during a single second.
+type payloadSizeBucket struct {
+	second int64
+	count  int64
+	sum    int64
+	max    int64
+}
+
+// payloadSizeStats collects lock-free payload size stats over the API health window, with the
+// same approximations as apiHealthStats.
+type payloadSizeStats struct {
+	buckets [apiHealthWindow]payloadSizeBucket
+
+	// samples are packed as unix second (high 32 bits) and size in bytes, capped at 4GiB (low 32 bits).
+	samples [apiHealthLatencySamples]uint64
+	cursor  uint64
+}
+
+func (s *payloadSizeStats) record(now time.Time, size int64) {
+	if size < 0 {
+		size = 0
+	}
+
+	sec := now.Unix()
+	b := &s.buckets[sec%apiHealthWindow]
+	prev := atomic.LoadInt64(&b.second)
+	if prev < sec && atomic.CompareAndSwapInt64(&b.second, prev, sec) {
+		atomic.StoreInt64(&b.count, 0)
+		atomic.StoreInt64(&b.sum, 0)
+		atomic.StoreInt64(&b.max, 0)
+	}
+
+	if prev <= sec {
+		atomic.AddInt64(&b.count, 1)
+		atomic.AddInt64(&b.sum, size)
+		for {
+			max := atomic.LoadInt64(&b.max)
+			if size <= max || atomic.CompareAndSwapInt64(&b.max, max, size) {
+				break
+			}
+		}
+	}
+
+	sample := size
+	if sample > 0xFFFFFFFF {
+		sample = 0xFFFFFFFF
+	}
+
+	i := atomic.AddUint64(&s.cursor, 1) - 1
+	atomic.StoreUint64(&s.samples[i%apiHealthLatencySamples], uint64(sec)<<32|uint64(sample))
+}
+
+func (s *payloadSizeStats) snapshot(now time.Time) APIHealthPayloadSize {
+	var stats APIHealthPayloadSize
+	var count, sum int64
+	since := now.Unix() - apiHealthWindow
+
+	for i := range s.buckets {
+		b := &s.buckets[i]
+		if atomic.LoadInt64(&b.second) <= since {
+			continue
+		}
+		count += atomic.LoadInt64(&b.count)
+		sum += atomic.LoadInt64(&b.sum)
+		if max := atomic.LoadInt64(&b.max); max > stats.Max {
+			stats.Max = max
+		}
+	}
+
+	if count > 0 {
+		stats.Avg = sum / count
+	}
+
+	sizes := make([]int64, 0, apiHealthLatencySamples)
+	for i := range s.samples {
+		v := atomic.LoadUint64(&s.samples[i])
+		if v == 0 || int64(v>>32) <= since {
+			continue
+		}
+		sizes = append(sizes, int64(v&0xFFFFFFFF))
+	}
+
+	if len(sizes) > 0 {
+		sort.Slice(sizes, func(i, j int) bool { return sizes[i] < sizes[j] })
+		stats.P95 = sizes[(len(sizes)*95+99)/100-1]
+	}
+
+	return stats
+}
+
+// recordPayloadSizes adds the payload sizes of a proxied request to the API stats and fires
+// EventPayloadSizeThreshold for the bodies exceeding the thresholds of the API.
+func (t *BaseMiddleware) recordPayloadSizes(r *http.Request, now time.Time) {
+	sizes := ctxGetPayloadSizes(r)
+	if sizes == nil {
+		return
+	}
+
+	requestSize, responseSize := sizes.requestSize(), sizes.responseSize()
+	t.Spec.health.requestSizes.record(now, requestSize)
+	t.Spec.health.responseSizes.record(now, responseSize)
+
+	conf := t.Spec.PayloadSizeAlerts
+	if !conf.Enabled {
+		return
+	}
+
+	if conf.RequestThreshold > 0 && requestSize > conf.RequestThreshold {
+		t.firePayloadSizeThreshold(r, payloadDirectionRequest, requestSize, conf.RequestThreshold)
+	}
+
+	if conf.ResponseThreshold > 0 && responseSize > conf.ResponseThreshold {
+		t.firePayloadSizeThreshold(r, payloadDirectionResponse, responseSize, conf.ResponseThreshold)
+	}
+}
+
+func (t *BaseMiddleware) firePayloadSizeThreshold(r *http.Request, direction string, size, threshold int64) {
+	t.Logger().WithField("direction", direction).WithField("size", size).Debug("Payload size threshold exceeded")
+
+	// the originating request isn't encoded, it would copy the oversized body into the event
+	t.FireEvent(EventPayloadSizeThreshold, EventPayloadSizeThresholdMeta{
+		EventMetaDefault: EventMetaDefault{Message: "Payload size threshold exceeded"},
+		APIID:            t.Spec.APIID,
+		Path:             r.URL.Path,
+		Direction:        direction,
+		Size:             size,
+		Threshold:        threshold,
+	})
+}
+
+// publishPayloadSizeStats reports the request and response size stats of the loaded APIs.
+func (gw *Gateway) publishPayloadSizeStats(job *health.Job) {
+	now := time.Now()
+
+	gw.apisMu.RLock()
+	defer gw.apisMu.RUnlock()
+
+	for _, spec := range gw.apisByID {
+		metadata := health.Kvs{"host": gw.hostDetails.Hostname, "api_id": spec.APIID}
+		for direction, stats := range map[string]*payloadSizeStats{
+			payloadDirectionRequest:  &spec.health.requestSizes,
+			payloadDirectionResponse: &spec.health.responseSizes,
+		} {
+			size := stats.snapshot(now)
+			job.GaugeKv(direction+"_size.avg", float64(size.Avg), metadata)
+			job.GaugeKv(direction+"_size.p95", float64(size.P95), metadata)
+			job.GaugeKv(direction+"_size.max", float64(size.Max), metadata)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package gateway
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestPayloadSizeStats(t *testing.T) {
+	var stats payloadSizeStats
+	now := time.Now()
+
+	for i := int64(1); i <= 100; i++ {
+		stats.record(now, i*10)
+	}
+
+	// Samples older than the window are ignored.
+	stats.record(now.Add(-2*apiHealthWindow*time.Second), 1<<20)
+
+	assert.Equal(t, APIHealthPayloadSize{Avg: 505, P95: 950, Max: 1000}, stats.snapshot(now))
+	assert.Zero(t, stats.snapshot(now.Add(2*apiHealthWindow*time.Second)))
+}
+
+func TestPayloadSizes(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+
+		// the response is flushed in chunks, so its length isn't known upfront
+		size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+		for size > 0 {
+			chunk := min(size, 512)
+			_, _ = w.Write([]byte(strings.Repeat("a", chunk)))
+			w.(http.Flusher).Flush()
+			size -= chunk
+		}
+	}))
+	defer upstream.Close()
+
+	spec := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "payload-api"
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.PayloadSizeAlerts = apidef.PayloadSizeAlerts{
+			Enabled:           true,
+			RequestThreshold:  1000,
+			ResponseThreshold: 2000,
+		}
+	})[0]
+
+	events := make(chan EventPayloadSizeThresholdMeta, 10)
+	spec.EventPaths = map[apidef.TykEvent][]config.TykEventHandler{
+		EventPayloadSizeThreshold: {&testEventHandler{func(em config.EventMessage) {
+			meta, ok := em.Meta.(EventPayloadSizeThresholdMeta)
+			assert.True(t, ok)
+			events <- meta
+		}}},
+	}
+
+	send := func(t *testing.T, body io.Reader, responseSize int) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/?size="+strconv.Itoa(responseSize), body)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Len(t, respBody, responseSize)
+	}
+
+	send(t, strings.NewReader(strings.Repeat("a", 100)), 500)
+	// the request body is chunked, its length is unknown to the gateway
+	send(t, io.MultiReader(strings.NewReader(strings.Repeat("a", 1500))), 3000)
+	send(t, nil, 0)
+
+	received := map[string]EventPayloadSizeThresholdMeta{}
+	for len(received) < 2 {
+		select {
+		case meta := <-events:
+			received[meta.Direction] = meta
+		case <-time.After(time.Second):
+			t.Fatalf("expected request and response events, got %v", received)
+		}
+	}
+
+	assert.Equal(t, int64(1500), received[payloadDirectionRequest].Size)
+	assert.Equal(t, int64(1000), received[payloadDirectionRequest].Threshold)
+	assert.Equal(t, int64(3000), received[payloadDirectionResponse].Size)
+	assert.Equal(t, int64(2000), received[payloadDirectionResponse].Threshold)
+	assert.Equal(t, "payload-api", received[payloadDirectionResponse].APIID)
+	assert.Empty(t, events)
+
+	resp, err := ts.Run(t, test.TestCase{
+		Path:      "/tyk/apis/payload-api/health",
+		AdminAuth: true,
+		Code:      http.StatusOK,
+	})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var snapshot APIHealthSnapshot
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&snapshot))
+	assert.Equal(t, APIHealthPayloadSize{Avg: 533, P95: 1500, Max: 1500}, snapshot.Traffic.RequestSize)
+	assert.Equal(t, APIHealthPayloadSize{Avg: 1166, P95: 3000, Max: 3000}, snapshot.Traffic.ResponseSize)
+}
@@ -252,9 +252,7 @@ func (gw *Gateway) LoadPoliciesFromDashboard(endpoint, secret string) ([]user.Po
 		return nil, err
 	}
 
-	gw.ServiceNonceMutex.Lock()
-	gw.ServiceNonce = list.Nonce
-	gw.ServiceNonceMutex.Unlock()
+	// The nonce was set by executeDashboardRequestWithRecovery
 	log.Debug("Loading Policies Finished: Nonce Set: ", list.Nonce)
 
 	return lo.Map(list.Message, func(item DBPolicy, _ int) user.Policy { return item.ToRegularPolicy() }), nil
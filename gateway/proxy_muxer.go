@@ -25,6 +25,8 @@ import (
 	"github.com/TykTechnologies/tyk/tcp"
 
 	"github.com/gorilla/mux"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/http2"
 )
@@ -35,6 +37,9 @@ type handleWrapper struct {
 
 	maxContentLength   int64
 	maxRequestBodySize int64
+
+	// http3 is the HTTP/3 server advertised on responses to HTTP/1.1 and HTTP/2 requests, if enabled
+	http3 *http3.Server
 }
 
 // h2cWrapper tracks handleWrapper for swapping w.router on reloads.
@@ -69,6 +74,10 @@ func (h *handleWrapper) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Capture original request path before any middleware modifications
 	ctxSetOriginalRequestPath(r, r.URL.Path)
 
+	if h.http3 != nil && r.ProtoMajor < 3 {
+		_ = h.http3.SetQUICHeaders(w.Header())
+	}
+
 	if r.Body != nil {
 		if !h.handleRequestLimits(w, r) {
 			return
@@ -111,6 +120,8 @@ type proxy struct {
 	useProxyProtocol bool
 	router           *mux.Router
 	httpServer       *http.Server
+	http3Server      *http3.Server
+	http3Conn        net.PacketConn
 	tcpProxy         *tcp.Proxy
 	started          bool
 }
@@ -262,6 +273,7 @@ func (m *proxyMux) addTCPService(spec *APISpec, modifier *tcp.Modifier, gw *Gate
 				DialTLS:         gw.dialWithServiceDiscovery(spec, gw.customDialTLSCheck(spec, tlsConfig)),
 				Dial:            gw.dialWithServiceDiscovery(spec, net.Dial),
 				TLSConfigTarget: tlsConfig,
+				MaxConnections:  conf.TCPProxy.MaxConnectionsForPort(spec.ListenPort),
 				// SyncStats:       recordTCPHit(spec.APIID, spec.DoNotTrack),
 			},
 		}
@@ -270,6 +282,20 @@ func (m *proxyMux) addTCPService(spec *APISpec, modifier *tcp.Modifier, gw *Gate
 	}
 }
 
+// tcpProxyStats returns the connection statistics of every TCP proxy keyed by port.
+func (m *proxyMux) tcpProxyStats() map[int]tcp.ConnectionStats {
+	m.RLock()
+	defer m.RUnlock()
+
+	stats := make(map[int]tcp.ConnectionStats)
+	for _, p := range m.proxies {
+		if p.tcpProxy != nil {
+			stats[p.port] = p.tcpProxy.Stats()
+		}
+	}
+	return stats
+}
+
 func (gw *Gateway) flushNetworkAnalytics(ctx context.Context) {
 	mainLog.Debug("Starting routine for flushing network analytics")
 	tick := time.NewTicker(time.Second)
@@ -392,6 +418,12 @@ func (m *proxyMux) swap(new *proxyMux, gw *Gateway) {
 				delete(m.instrumentedRouters, curP.router)
 			}
 
+			if curP.http3Server != nil {
+				curP.http3Server.Close()
+				// The server doesn't close the UDP socket it was given.
+				curP.http3Conn.Close()
+			}
+
 			if curP.httpServer != nil {
 				ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 				curP.httpServer.Shutdown(ctx)
@@ -493,12 +525,43 @@ func (m *proxyMux) serve(gw *Gateway) {
 			if conf.CloseConnections {
 				p.httpServer.SetKeepAlivesEnabled(false)
 			}
+
+			if p.protocol == "https" && conf.HttpServerOptions.EnableHTTP3 {
+				p.serveHTTP3(h, gw)
+			}
+
 			go p.httpServer.Serve(p.listener)
 		}
 		p.started = true
 	}
 }
 
+// serveHTTP3 starts the experimental HTTP/3 server on the UDP port matching the HTTPS listener of p.
+// It serves requests with the same handler as the HTTPS listener.
+func (p *proxy) serveHTTP3(h *handleWrapper, gw *Gateway) {
+	addr := gw.GetConfig().ListenAddress + ":" + strconv.Itoa(p.port)
+
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		mainLog.WithError(err).Error("Can't start HTTP/3 listener")
+		return
+	}
+
+	// The server configures the TLS config for HTTP/3 itself.
+	p.http3Server = &http3.Server{
+		Addr:       addr,
+		Port:       p.port,
+		TLSConfig:  gw.listenerTLSConfig(p.port),
+		QUICConfig: &quic.Config{Allow0RTT: false},
+		Handler:    h,
+	}
+	p.http3Conn = conn
+	h.http3 = p.http3Server
+
+	mainLog.Warning("Starting HTTP/3 server on:", conn.LocalAddr().String())
+	go p.http3Server.Serve(conn)
+}
+
 func target(listenAddress string, listenPort int) string {
 	return fmt.Sprintf("%s:%d", listenAddress, listenPort)
 }
@@ -526,44 +589,73 @@ func (m *proxyMux) generateListener(listenPort int, protocol string, gw *Gateway
 	}
 
 	targetPort := listenAddress + ":" + strconv.Itoa(listenPort)
-	if ls := m.again.GetListener(targetPort); ls != nil {
-		return ls, nil
+	// Listeners inherited from the parent process on hot restart are plain TCP
+	// listeners, TLS listeners are wrapped again with the settings of the port.
+	l = m.again.GetListener(targetPort)
+	if _, inherited := l.(*net.TCPListener); l != nil && (!inherited || !isTLSProtocol(protocol)) {
+		return l, nil
 	}
-	switch protocol {
-	case "https", "tls":
-		mainLog.Infof("--> Using TLS (%s)", protocol)
-		httpServerOptions := conf.HttpServerOptions
-
-		tlsConfig := tls.Config{
-			GetCertificate:     dummyGetCertificate,
-			ServerName:         httpServerOptions.ServerName,
-			MinVersion:         httpServerOptions.MinVersion,
-			MaxVersion:         httpServerOptions.MaxVersion,
-			ClientAuth:         tls.NoClientCert,
-			InsecureSkipVerify: httpServerOptions.SSLInsecureSkipVerify,
-			CipherSuites:       getCipherAliases(httpServerOptions.Ciphers),
-		}
 
-		if httpServerOptions.EnableHttp2 {
-			tlsConfig.NextProtos = append(tlsConfig.NextProtos, http2.NextProtoTLS)
+	if l == nil {
+		l, err = net.Listen("tcp", targetPort)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		tlsConfig.GetConfigForClient = gw.getTLSConfigForClient(&tlsConfig, listenPort)
-		l, err = tls.Listen("tcp", targetPort, &tlsConfig)
-
-	default:
+	if isTLSProtocol(protocol) {
+		mainLog.Infof("--> Using TLS (%s)", protocol)
+		l = tls.NewListener(l, gw.listenerTLSConfig(listenPort))
+	} else {
 		mainLog.WithField("port", targetPort).Infof("--> Standard listener (%s)", protocol)
-		l, err = net.Listen("tcp", targetPort)
-	}
-	if err != nil {
-		return nil, err
 	}
+
 	if err := (&m.again).Listen(targetPort, l); err != nil {
 		return nil, err
 	}
 	return l, nil
 }
 
+func isTLSProtocol(protocol string) bool {
+	return protocol == "https" || protocol == "tls"
+}
+
+// listenerTLSConfig returns the TLS configuration of the listener on listenPort,
+// with the port_tls settings of the port overriding the gateway wide ones.
+func (gw *Gateway) listenerTLSConfig(listenPort int) *tls.Config {
+	httpServerOptions := gw.GetConfig().HttpServerOptions
+
+	minVersion, maxVersion, ciphers := httpServerOptions.MinVersion, httpServerOptions.MaxVersion, httpServerOptions.Ciphers
+	if portTLS, ok := httpServerOptions.PortTLS.For(listenPort); ok {
+		if portTLS.MinVersion != 0 {
+			minVersion = portTLS.MinVersion
+		}
+		if portTLS.MaxVersion != 0 {
+			maxVersion = portTLS.MaxVersion
+		}
+		if len(portTLS.Ciphers) > 0 {
+			ciphers = portTLS.Ciphers
+		}
+	}
+
+	tlsConfig := &tls.Config{
+		GetCertificate:     dummyGetCertificate,
+		ServerName:         httpServerOptions.ServerName,
+		MinVersion:         minVersion,
+		MaxVersion:         maxVersion,
+		ClientAuth:         tls.NoClientCert,
+		InsecureSkipVerify: httpServerOptions.SSLInsecureSkipVerify,
+		CipherSuites:       getCipherAliases(ciphers),
+	}
+
+	if httpServerOptions.EnableHttp2 {
+		tlsConfig.NextProtos = append(tlsConfig.NextProtos, http2.NextProtoTLS)
+	}
+
+	tlsConfig.GetConfigForClient = gw.getTLSConfigForClient(tlsConfig, listenPort)
+	return tlsConfig
+}
+
 // CheckAndMarkInstrumented returns true if the router was not instrumented yet.
 // It marks it as instrumented for future calls.
 func (p *proxyMux) checkAndMarkInstrumented(r *mux.Router) bool {
@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -8,17 +9,24 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/quic-go/quic-go/http3"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/crypto"
 	tykLog "github.com/TykTechnologies/tyk/log"
+	"github.com/TykTechnologies/tyk/tcp"
 	"github.com/TykTechnologies/tyk/test"
 )
 
@@ -212,6 +220,66 @@ func TestTCP_missing_port(t *testing.T) {
 	}
 }
 
+func TestTCPProxy_connectionLimit(t *testing.T) {
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer upstream.Close()
+	go func() {
+		for {
+			conn, err := upstream.Accept()
+			if err != nil {
+				return
+			}
+			go io.Copy(conn, conn)
+		}
+	}()
+
+	p, err := getUnusedPort()
+	require.NoError(t, err)
+
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.TCPProxy.PortMaxConnections = map[string]int{strconv.Itoa(p): 1}
+	})
+	defer ts.Close()
+
+	ts.EnablePort(p, "tcp")
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Protocol = "tcp"
+		spec.ListenPort = p
+		spec.Proxy.TargetURL = upstream.Addr().String()
+	})
+
+	address := fmt.Sprintf("127.0.0.1:%d", p)
+	first, err := net.Dial("tcp", address)
+	require.NoError(t, err)
+	defer first.Close()
+
+	_, err = first.Write([]byte("ping"))
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(first, buf)
+	require.NoError(t, err)
+
+	second, err := net.Dial("tcp", address)
+	require.NoError(t, err)
+	defer second.Close()
+
+	second.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = second.Read(buf)
+	assert.Error(t, err, "connection over the limit should be closed")
+
+	item, ok := ts.Gw.tcpProxyHealthChecks()["tcp_proxy_"+strconv.Itoa(p)]
+	require.True(t, ok)
+	assert.Equal(t, HealthCheckStatus(Warn), item.Status)
+
+	stats, ok := item.ObservedValue.(tcp.ConnectionStats)
+	require.True(t, ok)
+	assert.EqualValues(t, 1, stats.Active)
+	assert.EqualValues(t, 1, stats.Accepted)
+	assert.EqualValues(t, 1, stats.Rejected)
+}
+
 // getUnusedPort returns a tcp port that is a vailable for binding.
 func getUnusedPort() (int, error) {
 	rp, err := net.Listen("tcp", "127.0.0.1:0")
@@ -470,3 +538,62 @@ func TestRequestBodyLimit(t *testing.T) {
 		{Path: "/sample/", Method: "POST", Data: strings.Repeat("a", 1025), Code: http.StatusRequestEntityTooLarge},
 	}...)
 }
+
+func TestHTTP3(t *testing.T) {
+	serverCertPem, serverPrivPem, _, _ := crypto.GenServerCertificate()
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	require.NoError(t, os.WriteFile(certFile, serverCertPem, 0600))
+	require.NoError(t, os.WriteFile(keyFile, serverPrivPem, 0600))
+
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.HttpServerOptions.UseSSL = true
+		globalConf.HttpServerOptions.EnableHTTP3 = true
+		globalConf.HttpServerOptions.Certificates = []config.CertData{{
+			Name:     "localhost",
+			CertFile: certFile,
+			KeyFile:  keyFile,
+		}}
+	})
+	defer ts.Close()
+	defer tlsConfigCache.Flush()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.UseKeylessAccess = true
+	})
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(ts.URL, "https://"))
+	require.NoError(t, err)
+
+	t.Run("Alt-Svc on the TCP listener", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{
+			Client:       GetTLSClient(nil, nil),
+			Code:         http.StatusOK,
+			HeadersMatch: map[string]string{"Alt-Svc": `h3=":` + port + `"; ma=2592000`},
+		})
+	})
+
+	t.Run("HTTP/3 listener", func(t *testing.T) {
+		transport := &http3.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+		defer transport.Close()
+
+		_, _ = ts.Run(t, test.TestCase{
+			Client: &http.Client{Transport: transport},
+			Code:   http.StatusOK,
+			Proto:  "HTTP/3.0",
+			HeadersNotMatch: map[string]string{
+				"Alt-Svc": `h3=":` + port + `"; ma=2592000`,
+			},
+		})
+	})
+
+	t.Run("UDP socket is released when the listener stops", func(t *testing.T) {
+		ts.Gw.DefaultProxyMux.swap(&proxyMux{}, ts.Gw)
+
+		conn, err := net.ListenPacket("udp", ts.Gw.GetConfig().ListenAddress+":"+port)
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+	})
+}
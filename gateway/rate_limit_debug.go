@@ -0,0 +1,290 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/internal/rate"
+	"github.com/TykTechnologies/tyk/storage"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+const (
+	// rateLimitDebugRedisPrefix prefixes the Redis lists holding the rate limit decisions of the keys in debug mode.
+	rateLimitDebugRedisPrefix = "rate-limit-debug-"
+
+	// rateLimitDebugRedisTTL is how long the decisions of a key are kept in Redis after the last one, in seconds.
+	rateLimitDebugRedisTTL = 24 * 60 * 60
+
+	defaultRateLimitDebugMaxEntries = 100
+	defaultRateLimitDebugDuration   = 5 * time.Minute
+)
+
+var rateLimitDebugLog = log.WithField("prefix", "ratelimit-debug")
+
+// rateLimitDecision explains why a request of a key in debug mode was allowed or throttled.
+type rateLimitDecision struct {
+	Time     time.Time `json:"time"`
+	KeyHash  string    `json:"key_hash"`
+	APIID    string    `json:"api_id"`
+	Limiter  string    `json:"limiter"`
+	Scope    string    `json:"scope,omitempty"`
+	Endpoint bool      `json:"endpoint,omitempty"`
+
+	// Rate, Per and Burst are the allowance of the key, Rate requests every Per seconds.
+	Rate  float64 `json:"rate"`
+	Per   float64 `json:"per"`
+	Burst int64   `json:"burst,omitempty"`
+
+	// Limit, Count and Remaining are the counter values reported by the limiter, -1 when it doesn't report them.
+	Limit     int `json:"limit"`
+	Count     int `json:"count"`
+	Remaining int `json:"remaining"`
+
+	// WindowStart is the start of the rate limit period ending with the request,
+	// ResetAt is when the allowance of the key is replenished.
+	WindowStart time.Time `json:"window_start"`
+	ResetAt     time.Time `json:"reset_at"`
+
+	Blocked bool   `json:"blocked"`
+	DryRun  bool   `json:"dry_run,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// rateLimitDecisionLog writes the rate limit decisions of the keys in debug mode
+// to the gateway log, and to a capped Redis list per key when enabled.
+type rateLimitDecisionLog struct {
+	store      *storage.RedisCluster
+	maxEntries int64
+}
+
+func (gw *Gateway) newRateLimitDecisionLog() *rateLimitDecisionLog {
+	conf := gw.GetConfig().RateLimitDebug
+
+	decisionLog := &rateLimitDecisionLog{maxEntries: int64(conf.MaxEntries)}
+	if decisionLog.maxEntries <= 0 {
+		decisionLog.maxEntries = defaultRateLimitDebugMaxEntries
+	}
+
+	if conf.EnableRedis {
+		decisionLog.store = &storage.RedisCluster{KeyPrefix: rateLimitDebugRedisPrefix, ConnectionHandler: gw.StorageConnectionHandler}
+	}
+
+	return decisionLog
+}
+
+// logRateLimitDecision records the decision made for a key in debug mode. It's only called when the
+// debug mode field of the session is set, so keys not in debug mode only pay for that check.
+func (l *SessionLimiter) logRateLimitDecision(
+	session *user.SessionState,
+	api *APISpec,
+	limiterName string,
+	apiLimit *user.APILimit,
+	allowanceScope string,
+	endpoint bool,
+	stats rate.Stats,
+	blocked bool,
+	dryRun bool,
+) {
+	now := time.Now()
+	if !session.RateLimitDebugEnabled(now) {
+		return
+	}
+
+	decision := rateLimitDecision{
+		Time:        now.UTC(),
+		KeyHash:     session.KeyHash(),
+		APIID:       api.APIID,
+		Limiter:     limiterName,
+		Scope:       allowanceScope,
+		Endpoint:    endpoint,
+		Rate:        apiLimit.Rate,
+		Per:         apiLimit.Per,
+		Burst:       apiLimit.Burst,
+		Limit:       stats.Limit,
+		Count:       stats.Count,
+		Remaining:   stats.Remaining,
+		WindowStart: now.Add(-time.Duration(apiLimit.Per * float64(time.Second))).UTC(),
+		ResetAt:     now.Add(stats.Reset).UTC(),
+		Blocked:     blocked,
+		DryRun:      dryRun,
+	}
+	decision.Reason = decision.explain(stats.Reset)
+
+	l.decisionLog.write(decision)
+}
+
+// explain returns a readable reason of the decision.
+func (d rateLimitDecision) explain(reset time.Duration) string {
+	allowance := fmt.Sprintf("%v requests per %vs", d.Rate, d.Per)
+	if d.Burst > 0 {
+		allowance += fmt.Sprintf(" with a burst of %d", d.Burst)
+	}
+
+	if !d.Blocked {
+		if d.Remaining < 0 {
+			return fmt.Sprintf("allowed by the %s limiter, within the allowance of %s", d.Limiter, allowance)
+		}
+		return fmt.Sprintf("allowed by the %s limiter, %d requests remaining of the allowance of %s", d.Limiter, d.Remaining, allowance)
+	}
+
+	if d.Count > 0 {
+		return fmt.Sprintf("blocked by the %s limiter, %d requests in the window exceed the allowance of %s, retry in %s",
+			d.Limiter, d.Count, allowance, reset.Round(time.Millisecond))
+	}
+
+	return fmt.Sprintf("blocked by the %s limiter, the allowance of %s is exhausted, retry in %s",
+		d.Limiter, allowance, reset.Round(time.Millisecond))
+}
+
+// write logs the decision and pushes it to the Redis list of the key when enabled.
+func (d *rateLimitDecisionLog) write(decision rateLimitDecision) {
+	rateLimitDebugLog.WithFields(logrus.Fields{
+		"key":          decision.KeyHash,
+		"api_id":       decision.APIID,
+		"limiter":      decision.Limiter,
+		"scope":        decision.Scope,
+		"rate":         decision.Rate,
+		"per":          decision.Per,
+		"burst":        decision.Burst,
+		"limit":        decision.Limit,
+		"count":        decision.Count,
+		"remaining":    decision.Remaining,
+		"window_start": decision.WindowStart,
+		"reset_at":     decision.ResetAt,
+		"blocked":      decision.Blocked,
+	}).Info(decision.Reason)
+
+	if d == nil || d.store == nil {
+		return
+	}
+
+	entry, err := json.Marshal(decision)
+	if err != nil {
+		rateLimitDebugLog.WithError(err).Error("Failed to encode rate limit decision")
+		return
+	}
+
+	if err := d.store.AppendToCappedList(decision.KeyHash, string(entry), d.maxEntries); err != nil {
+		return
+	}
+
+	if err := d.store.SetExp(decision.KeyHash, rateLimitDebugRedisTTL); err != nil {
+		rateLimitDebugLog.WithError(err).Error("Failed to set the expiry of the rate limit decisions")
+	}
+}
+
+// decisions returns the decisions recorded for the key, oldest first.
+func (d *rateLimitDecisionLog) decisions(keyHash string) ([]rateLimitDecision, error) {
+	entries, err := d.store.GetListRange(keyHash, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	decisions := make([]rateLimitDecision, 0, len(entries))
+	for _, entry := range entries {
+		var decision rateLimitDecision
+		if err := json.Unmarshal([]byte(entry), &decision); err != nil {
+			rateLimitDebugLog.WithError(err).Warning("Skipping invalid rate limit decision")
+			continue
+		}
+		decisions = append(decisions, decision)
+	}
+
+	return decisions, nil
+}
+
+type rateLimitDebugStatus struct {
+	KeyHash string `json:"key_hash"`
+	Status  string `json:"status"`
+	Action  string `json:"action"`
+	Until   int64  `json:"rate_limit_debug_until"`
+}
+
+type rateLimitDecisionList struct {
+	KeyHash   string              `json:"key_hash"`
+	Decisions []rateLimitDecision `json:"decisions"`
+}
+
+// rateLimitDebugHandler turns the rate limit debug mode of a key on for a limited duration (POST),
+// off (DELETE), or returns the decisions recorded in Redis (GET).
+func (gw *Gateway) rateLimitDebugHandler(w http.ResponseWriter, r *http.Request) {
+	keyHash := mux.Vars(r)["keyHash"]
+
+	var obj interface{}
+	var code int
+
+	switch r.Method {
+	case http.MethodGet:
+		obj, code = gw.handleGetRateLimitDecisions(keyHash)
+	case http.MethodPost:
+		duration := defaultRateLimitDebugDuration
+		if v := r.URL.Query().Get("duration"); v != "" {
+			seconds, err := strconv.Atoi(v)
+			if err != nil || seconds <= 0 {
+				doJSONWrite(w, http.StatusBadRequest, apiError("duration must be a positive number of seconds"))
+				return
+			}
+			duration = time.Duration(seconds) * time.Second
+		}
+
+		obj, code = gw.handleSetRateLimitDebug(keyHash, time.Now().Add(duration).Unix())
+	case http.MethodDelete:
+		obj, code = gw.handleSetRateLimitDebug(keyHash, 0)
+	}
+
+	doJSONWrite(w, code, obj)
+}
+
+func (gw *Gateway) handleSetRateLimitDebug(keyHash string, until int64) (interface{}, int) {
+	session, ok := gw.GlobalSessionManager.SessionDetail("", keyHash, true)
+	if !ok {
+		return apiError("Key not found"), http.StatusNotFound
+	}
+
+	session.RateLimitDebugUntil = until
+
+	if err := gw.GlobalSessionManager.UpdateSession(keyHash, &session, gw.ApplyLifetime(&session), true); err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix": "api",
+			"key":    gw.obfuscateKey(keyHash),
+			"status": "fail",
+			"err":    err,
+		}).Error("Failed to update the rate limit debug mode of the key.")
+
+		return apiError("Could not write key data"), http.StatusInternalServerError
+	}
+
+	action := "enabled"
+	if until == 0 {
+		action = "disabled"
+	}
+
+	log.WithFields(logrus.Fields{
+		"prefix": "api",
+		"key":    gw.obfuscateKey(keyHash),
+		"until":  until,
+	}).Infof("Rate limit debug mode %s.", action)
+
+	return rateLimitDebugStatus{KeyHash: keyHash, Status: "ok", Action: action, Until: until}, http.StatusOK
+}
+
+func (gw *Gateway) handleGetRateLimitDecisions(keyHash string) (interface{}, int) {
+	decisionLog := gw.SessionLimiter.decisionLog
+	if decisionLog == nil || decisionLog.store == nil {
+		return apiError("Rate limit decisions aren't stored in Redis, enable rate_limit_debug.enable_redis"), http.StatusBadRequest
+	}
+
+	decisions, err := decisionLog.decisions(keyHash)
+	if err != nil {
+		return apiError("Could not read the rate limit decisions"), http.StatusInternalServerError
+	}
+
+	return rateLimitDecisionList{KeyHash: keyHash, Decisions: decisions}, http.StatusOK
+}
@@ -0,0 +1,178 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/rate"
+	"github.com/TykTechnologies/tyk/storage"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestRateLimitDebug(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.RateLimitDebug.EnableRedis = true
+	})
+	defer ts.Close()
+
+	api := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/rate-limit-debug"
+		spec.UseKeylessAccess = false
+	})[0]
+
+	_, authKey := ts.CreateSession(func(s *user.SessionState) {
+		s.AccessRights = map[string]user.AccessDefinition{
+			api.APIID: {
+				APIName: api.Name,
+				APIID:   api.APIID,
+				Limit:   user.APILimit{RateLimit: user.RateLimit{Rate: 2, Per: 60}},
+			},
+		}
+	})
+	keyHash := storage.HashKey(authKey, ts.Gw.GetConfig().HashKeys)
+	debugPath := "/tyk/debug/rate-limits/" + keyHash
+
+	authorized := map[string]string{header.Authorization: authKey}
+
+	decisions := func(t *testing.T) []rateLimitDecision {
+		t.Helper()
+
+		resp, _ := ts.Run(t, test.TestCase{Path: debugPath, AdminAuth: true, Code: http.StatusOK})
+
+		var list rateLimitDecisionList
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+		assert.Equal(t, keyHash, list.KeyHash)
+
+		return list.Decisions
+	}
+
+	t.Run("off by default", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{Path: "/rate-limit-debug", Headers: authorized, Code: http.StatusOK})
+		assert.Empty(t, decisions(t))
+	})
+
+	t.Run("decisions explain the 429", func(t *testing.T) {
+		resp, _ := ts.Run(t, test.TestCase{Method: http.MethodPost, Path: debugPath + "?duration=60", AdminAuth: true, Code: http.StatusOK})
+
+		var status rateLimitDebugStatus
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+		assert.Equal(t, "enabled", status.Action)
+		assert.InDelta(t, time.Now().Add(time.Minute).Unix(), status.Until, 2)
+
+		// The first request of the key was made before the debug mode was enabled.
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/rate-limit-debug", Headers: authorized, Code: http.StatusOK},
+			{Path: "/rate-limit-debug", Headers: authorized, Code: http.StatusTooManyRequests},
+		}...)
+
+		got := decisions(t)
+		require.Len(t, got, 2)
+
+		assert.False(t, got[0].Blocked)
+		assert.Equal(t, 0, got[0].Remaining)
+
+		blocked := got[1]
+		assert.True(t, blocked.Blocked)
+		assert.Equal(t, keyHash, blocked.KeyHash)
+		assert.Equal(t, api.APIID, blocked.APIID)
+		assert.Equal(t, rate.LimitDRL, blocked.Limiter)
+		assert.Equal(t, float64(2), blocked.Rate)
+		assert.Equal(t, float64(60), blocked.Per)
+		assert.Equal(t, 2, blocked.Limit)
+		assert.WithinDuration(t, blocked.Time.Add(-time.Minute), blocked.WindowStart, time.Second)
+		assert.True(t, blocked.ResetAt.After(blocked.Time))
+		assert.Contains(t, blocked.Reason, "blocked by the drl limiter, the allowance of 2 requests per 60s is exhausted")
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{Method: http.MethodDelete, Path: debugPath, AdminAuth: true, Code: http.StatusOK, BodyMatch: `"action":"disabled"`})
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/rate-limit-debug", Headers: authorized, Code: http.StatusTooManyRequests})
+		assert.Len(t, decisions(t), 2)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Method: http.MethodPost, Path: "/tyk/debug/rate-limits/unknown", AdminAuth: true, Code: http.StatusNotFound},
+			{Method: http.MethodPost, Path: debugPath + "?duration=-5", AdminAuth: true, Code: http.StatusBadRequest},
+			{Path: debugPath, Code: http.StatusForbidden},
+		}...)
+	})
+}
+
+func TestRateLimitDebug_SessionFlag(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.RateLimitDebug.EnableRedis = true
+		globalConf.RateLimitDebug.MaxEntries = 2
+	})
+	defer ts.Close()
+
+	api := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/rate-limit-debug"
+		spec.UseKeylessAccess = false
+	})[0]
+
+	_, authKey := ts.CreateSession(func(s *user.SessionState) {
+		s.RateLimitDebugUntil = -1
+		s.AccessRights = map[string]user.AccessDefinition{
+			api.APIID: {
+				APIName: api.Name,
+				APIID:   api.APIID,
+				Limit:   user.APILimit{RateLimit: user.RateLimit{Rate: 1, Per: 60}},
+			},
+		}
+	})
+	keyHash := storage.HashKey(authKey, ts.Gw.GetConfig().HashKeys)
+
+	authorized := map[string]string{header.Authorization: authKey}
+	_, _ = ts.Run(t, []test.TestCase{
+		{Path: "/rate-limit-debug", Headers: authorized, Code: http.StatusOK},
+		{Path: "/rate-limit-debug", Headers: authorized, Code: http.StatusTooManyRequests},
+		{Path: "/rate-limit-debug", Headers: authorized, Code: http.StatusTooManyRequests},
+	}...)
+
+	got, err := ts.Gw.SessionLimiter.decisionLog.decisions(keyHash)
+	require.NoError(t, err)
+
+	// The list is capped, the oldest decision was removed.
+	require.Len(t, got, 2)
+	assert.True(t, got[0].Blocked)
+	assert.True(t, got[1].Blocked)
+}
+
+func TestRateLimitDebug_RedisDisabled(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	_, _ = ts.Run(t, test.TestCase{
+		Path:      "/tyk/debug/rate-limits/any",
+		AdminAuth: true,
+		Code:      http.StatusBadRequest,
+		BodyMatch: "enable rate_limit_debug.enable_redis",
+	})
+}
+
+func TestRateLimitDecision_Explain(t *testing.T) {
+	d := rateLimitDecision{Limiter: rate.LimitRedisRolling, Rate: 10, Per: 1, Remaining: 3}
+	assert.Equal(t, "allowed by the redis-rolling limiter, 3 requests remaining of the allowance of 10 requests per 1s", d.explain(0))
+
+	d.Remaining = -1
+	assert.Equal(t, "allowed by the redis-rolling limiter, within the allowance of 10 requests per 1s", d.explain(0))
+
+	d.Blocked = true
+	d.Count = 12
+	assert.Equal(t, "blocked by the redis-rolling limiter, 12 requests in the window exceed the allowance of 10 requests per 1s, retry in 250ms",
+		d.explain(250*time.Millisecond))
+
+	d = rateLimitDecision{Limiter: rate.LimitTokenBucket, Rate: 1, Per: 60, Burst: 3, Blocked: true}
+	assert.Equal(t, "blocked by the token-bucket limiter, the allowance of 1 requests per 60s with a burst of 3 is exhausted, retry in 20s",
+		d.explain(20*time.Second))
+}
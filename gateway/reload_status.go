@@ -0,0 +1,157 @@
+package gateway
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ReloadStatus summarises the outcome of the last API specs sync.
+type ReloadStatus struct {
+	StartedAt  time.Time          `json:"started_at"`
+	FinishedAt time.Time          `json:"finished_at"`
+	Counts     ReloadStatusCounts `json:"counts"`
+	Added      []string           `json:"added"`
+	Removed    []string           `json:"removed"`
+	Changed    []string           `json:"changed"`
+	Skipped    []SkippedAPISpec   `json:"skipped"`
+
+	// ListenPathConflicts lists the listen paths declared by several APIs on the same domain.
+	ListenPathConflicts []ListenPathConflict `json:"listen_path_conflicts"`
+	// MatchingOrder lists the loaded HTTP APIs in the order their listen paths are matched.
+	MatchingOrder []MatchedListenPath `json:"matching_order"`
+}
+
+// ReloadStatusCounts holds the number of APIs in each category of a ReloadStatus.
+type ReloadStatusCounts struct {
+	Loaded  int `json:"loaded"`
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Changed int `json:"changed"`
+	Skipped int `json:"skipped"`
+}
+
+// SkippedAPISpec describes an API which was not loaded because it failed validation.
+type SkippedAPISpec struct {
+	APIID string `json:"api_id"`
+	Name  string `json:"name"`
+	Error string `json:"error"`
+
+	err error
+}
+
+// ListenPathConflict describes a listen path declared by several APIs on the same domain.
+// Rejected holds the APIs which were not loaded when reject_duplicate_listen_paths is set.
+type ListenPathConflict struct {
+	Domain     string   `json:"domain"`
+	ListenPath string   `json:"listen_path"`
+	APIIDs     []string `json:"api_ids"`
+	Rejected   []string `json:"rejected"`
+}
+
+// MatchedListenPath is an entry of the matching order of the listen paths.
+type MatchedListenPath struct {
+	APIID      string `json:"api_id"`
+	Domain     string `json:"domain"`
+	ListenPath string `json:"listen_path"`
+	Priority   int    `json:"priority"`
+}
+
+// newReloadStatus diffs the loaded specs against the currently registered ones.
+// An API is reported as changed when its checksum differs from the registered spec.
+func newReloadStatus(previous map[string]*APISpec, loaded []*APISpec, skipped []SkippedAPISpec) *ReloadStatus {
+	status := &ReloadStatus{
+		Added:   []string{},
+		Removed: []string{},
+		Changed: []string{},
+		Skipped: skipped,
+
+		ListenPathConflicts: []ListenPathConflict{},
+		MatchingOrder:       []MatchedListenPath{},
+	}
+	if status.Skipped == nil {
+		status.Skipped = []SkippedAPISpec{}
+	}
+
+	seen := make(map[string]struct{}, len(loaded))
+	for _, spec := range loaded {
+		seen[spec.APIID] = struct{}{}
+
+		prev, ok := previous[spec.APIID]
+		switch {
+		case !ok:
+			status.Added = append(status.Added, spec.APIID)
+		case prev.Checksum != spec.Checksum:
+			status.Changed = append(status.Changed, spec.APIID)
+		}
+	}
+
+	for apiID := range previous {
+		if _, ok := seen[apiID]; !ok {
+			status.Removed = append(status.Removed, apiID)
+		}
+	}
+
+	sort.Strings(status.Added)
+	sort.Strings(status.Removed)
+	sort.Strings(status.Changed)
+
+	status.Counts = ReloadStatusCounts{
+		Loaded:  len(loaded),
+		Added:   len(status.Added),
+		Removed: len(status.Removed),
+		Changed: len(status.Changed),
+		Skipped: len(status.Skipped),
+	}
+
+	return status
+}
+
+func (s *ReloadStatus) log() {
+	mainLog.WithFields(logrus.Fields{
+		"loaded":  s.Counts.Loaded,
+		"added":   s.Added,
+		"removed": s.Removed,
+		"changed": s.Changed,
+		"skipped": s.Counts.Skipped,
+	}).Info("API reload diff computed")
+}
+
+// setReloadStatusRouting adds the listen path conflicts and the matching order of the loaded specs,
+// sorted by loadApps, to the last reload status.
+func (gw *Gateway) setReloadStatusRouting(conflicts []ListenPathConflict, specs []*APISpec) {
+	status := gw.lastReloadStatus.Load()
+	if status == nil {
+		return
+	}
+
+	order := make([]MatchedListenPath, 0, len(specs))
+	for _, spec := range specs {
+		switch spec.Protocol {
+		case "", "http", "https", "h2c":
+			order = append(order, MatchedListenPath{
+				APIID:      spec.APIID,
+				Domain:     spec.GetAPIDomain(),
+				ListenPath: spec.Proxy.ListenPath,
+				Priority:   spec.Proxy.ListenPathPriority,
+			})
+		}
+	}
+
+	updated := *status
+	updated.ListenPathConflicts = conflicts
+	updated.MatchingOrder = order
+	gw.lastReloadStatus.CompareAndSwap(status, &updated)
+}
+
+func (gw *Gateway) reloadStatusHandler(w http.ResponseWriter, _ *http.Request) {
+	status := gw.lastReloadStatus.Load()
+	if status == nil {
+		doJSONWrite(w, http.StatusNotFound, apiError("No reload has been performed yet"))
+		return
+	}
+
+	doJSONWrite(w, http.StatusOK, status)
+}
@@ -0,0 +1,69 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestReloadStatus(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	buildSpec := func(apiID, listenPath, target string) *APISpec {
+		return BuildAPI(func(spec *APISpec) {
+			spec.APIID = apiID
+			spec.Proxy.ListenPath = listenPath
+			spec.Proxy.TargetURL = target
+		})[0]
+	}
+
+	ts.Gw.LoadAPI(
+		buildSpec("api-a", "/a/", TestHttpAny),
+		buildSpec("api-b", "/b/", TestHttpAny),
+	)
+
+	getStatus := func(t *testing.T) ReloadStatus {
+		t.Helper()
+		resp, err := ts.Run(t, test.TestCase{
+			Path:      "/tyk/reload/status",
+			AdminAuth: true,
+			Code:      http.StatusOK,
+		})
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var status ReloadStatus
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+		return status
+	}
+
+	status := getStatus(t)
+	assert.Equal(t, []string{"api-a", "api-b"}, status.Added)
+	assert.Empty(t, status.Removed)
+	assert.Empty(t, status.Changed)
+
+	invalid := buildSpec("api-tcp", "/tcp/", TestHttpAny)
+	invalid.Protocol = "tcp"
+	ts.Gw.LoadAPI(
+		buildSpec("api-a", "/a/", TestHttpAny+"/changed"),
+		buildSpec("api-c", "/c/", TestHttpAny),
+		invalid,
+	)
+
+	status = getStatus(t)
+	assert.Equal(t, []string{"api-c"}, status.Added)
+	assert.Equal(t, []string{"api-b"}, status.Removed)
+	assert.Equal(t, []string{"api-a"}, status.Changed)
+	require.Len(t, status.Skipped, 1)
+	assert.Equal(t, "api-tcp", status.Skipped[0].APIID)
+	assert.Equal(t, "missing listening port", status.Skipped[0].Error)
+	assert.Equal(t, ReloadStatusCounts{Loaded: 2, Added: 1, Removed: 1, Changed: 1, Skipped: 1}, status.Counts)
+	assert.False(t, status.StartedAt.IsZero())
+	assert.False(t, status.FinishedAt.Before(status.StartedAt))
+}
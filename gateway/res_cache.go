@@ -5,7 +5,9 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/TykTechnologies/tyk/storage"
@@ -55,9 +57,11 @@ func (m *ResponseCacheMiddleware) encodePayload(payload string, timestamp int64)
 
 // HandleResponse checks if the http.Response argument can be cached and caches it for future requests.
 func (m *ResponseCacheMiddleware) HandleResponse(w http.ResponseWriter, res *http.Response, r *http.Request, ses *user.SessionState) error {
+	logger := m.requestLogger(r)
+
 	// No cache of empty responses
 	if res == nil {
-		m.logger().Warning("Upstream request must have failed, response is empty")
+		logger.Warning("Upstream request must have failed, response is empty")
 		return nil
 	}
 
@@ -69,10 +73,20 @@ func (m *ResponseCacheMiddleware) HandleResponse(w http.ResponseWriter, res *htt
 	// Has cache been enabled on the request?
 	options := ctxGetCacheOptions(r)
 	if options == nil {
-		m.logger().Debug("Request is not cacheable")
+		logger.Debug("Request is not cacheable")
 		return nil
 	}
 
+	// Fall back to the stale cached response instead of the upstream error
+	if res.StatusCode >= http.StatusInternalServerError {
+		if stale := staleCachedResponse(r); stale != nil {
+			logger.Debug("Upstream failed, serving stale cached response")
+			res.Body.Close()
+			*res = *stale
+			return nil
+		}
+	}
+
 	cacheThisRequest := true
 	cacheTTL := options.timeout
 
@@ -110,29 +124,50 @@ func (m *ResponseCacheMiddleware) HandleResponse(w http.ResponseWriter, res *htt
 		}
 	}
 
+	key := options.key
+	var varied []string
+	if options.varyValues != nil {
+		var cacheable bool
+		varied, cacheable = responseVaryHeaders(res.Header, m.Spec.CacheOptions.CacheVaryHeaders)
+		cacheThisRequest = cacheThisRequest && cacheable
+		if len(varied) > 0 {
+			key = cacheVariantKey(options.key, varied, options.varyValues)
+		}
+	}
+
 	var toStore string
 	var err error
 
 	if cacheThisRequest {
 		res.Body, err = newNopCloserBuffer(res.Body)
 		if err != nil {
-			m.logger().WithError(err).Error("error reading cache body")
+			logger.WithError(err).Error("error reading cache body")
 			return nil
 		}
 
 		var wireFormatReq bytes.Buffer
 		if err := res.Write(&wireFormatReq); err != nil {
-			m.logger().WithError(err).Error("error encoding cache")
+			logger.WithError(err).Error("error encoding cache")
 			return nil
 		}
 
 		ts := m.getTimeTTL(cacheTTL)
 		toStore = m.encodePayload(wireFormatReq.String(), ts)
 
+		// Keep the entry in the store past its expiry so it can be served stale
+		storeTTL := cacheTTL
+		if cacheTTL > 0 {
+			storeTTL += m.Spec.CacheOptions.StaleIfError
+		}
+
 		go func() {
-			err := m.store.SetKey(options.key, toStore, cacheTTL)
+			if options.varyValues != nil {
+				m.storeVaryIndex(options.key, varied, storeTTL)
+			}
+
+			err := m.store.SetKey(key, toStore, storeTTL)
 			if err != nil {
-				m.logger().WithError(err).Error("could not save key in cache store")
+				logger.WithError(err).Error("could not save key in cache store")
 			}
 		}()
 	}
@@ -148,3 +183,51 @@ func (m *ResponseCacheMiddleware) HandleResponse(w http.ResponseWriter, res *htt
 
 	return nil
 }
+
+// storeVaryIndex records the headers the response cached under key varies on,
+// or removes a stale record when the response no longer varies.
+func (m *ResponseCacheMiddleware) storeVaryIndex(key string, varied []string, cacheTTL int64) {
+	if len(varied) == 0 {
+		m.store.DeleteKey(cacheVaryIndexKey(key))
+		return
+	}
+
+	if err := m.store.SetKey(cacheVaryIndexKey(key), strings.Join(varied, ","), cacheTTL); err != nil {
+		m.logger().WithError(err).Error("could not save vary index in cache store")
+	}
+}
+
+// responseVaryHeaders returns the sorted allowlisted headers listed in the response Vary header.
+// Headers missing from the allowlist are ignored so they don't fragment the cache.
+// It reports false when the response varies on everything and must not be cached.
+func responseVaryHeaders(h http.Header, allowlist []string) ([]string, bool) {
+	allowed := make(map[string]struct{}, len(allowlist))
+	for _, name := range allowlist {
+		allowed[http.CanonicalHeaderKey(name)] = struct{}{}
+	}
+
+	seen := map[string]struct{}{}
+	varied := []string{}
+	for _, value := range h.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return nil, false
+			}
+
+			name = http.CanonicalHeaderKey(name)
+			if _, ok := allowed[name]; !ok {
+				continue
+			}
+			if _, ok := seen[name]; ok {
+				continue
+			}
+
+			seen[name] = struct{}{}
+			varied = append(varied, name)
+		}
+	}
+
+	sort.Strings(varied)
+	return varied, true
+}
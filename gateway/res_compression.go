@@ -0,0 +1,261 @@
+package gateway
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/httputil"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+const (
+	compressionGzip   = "gzip"
+	compressionBrotli = "br"
+
+	defaultCompressionMinSize = 1024
+)
+
+var (
+	defaultCompressionAlgorithms   = []string{compressionBrotli, compressionGzip}
+	defaultCompressionContentTypes = []string{
+		"application/json",
+		"application/*+json",
+		"application/xml",
+		"application/*+xml",
+		"application/javascript",
+		"text/*",
+	}
+)
+
+// ResponseCompressionMiddleware compresses the upstream responses the client accepts compressed.
+// The body is compressed as it's streamed to the client, only its first MinSize bytes are buffered.
+type ResponseCompressionMiddleware struct {
+	BaseTykResponseHandler
+}
+
+func (m *ResponseCompressionMiddleware) Base() *BaseTykResponseHandler {
+	return &m.BaseTykResponseHandler
+}
+
+func (m *ResponseCompressionMiddleware) Name() string {
+	return "ResponseCompressionMiddleware"
+}
+
+func (m *ResponseCompressionMiddleware) Enabled() bool {
+	return m.Spec.ResponseCompression.Enabled
+}
+
+func (m *ResponseCompressionMiddleware) Init(_ interface{}, spec *APISpec) error {
+	m.Spec = spec
+	return nil
+}
+
+func (m *ResponseCompressionMiddleware) HandleError(_ http.ResponseWriter, _ *http.Request) {
+}
+
+func (m *ResponseCompressionMiddleware) HandleResponse(_ http.ResponseWriter, res *http.Response, req *http.Request, _ *user.SessionState) error {
+	if res == nil || res.Body == nil || !m.compressible(res, req) {
+		return nil
+	}
+
+	conf := m.Spec.ResponseCompression
+	algorithms := conf.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = defaultCompressionAlgorithms
+	}
+
+	encoding := negotiateEncoding(req.Header.Get(header.AcceptEncoding), algorithms)
+	if encoding == "" {
+		return nil
+	}
+
+	minSize := conf.MinSize
+	if minSize <= 0 {
+		minSize = defaultCompressionMinSize
+	}
+
+	if res.ContentLength >= 0 && res.ContentLength < minSize {
+		return nil
+	}
+
+	if res.ContentLength < 0 {
+		// The size is unknown, compress only once the body reached the minimum size.
+		head, err := io.ReadAll(io.LimitReader(res.Body, minSize))
+		res.Body = readCloser{io.MultiReader(bytes.NewReader(head), res.Body), res.Body}
+		if err != nil || int64(len(head)) < minSize {
+			return nil
+		}
+	}
+
+	res.Body = newCompressedBody(res.Body, encoding)
+	res.Header.Set(header.ContentEncoding, encoding)
+	res.Header.Del(header.ContentLength)
+	res.ContentLength = -1
+	addVary(res.Header, header.AcceptEncoding)
+
+	// The compressed representation isn't byte for byte the one the strong validator was computed for.
+	if etag := res.Header.Get(header.ETag); etag != "" && !strings.HasPrefix(etag, "W/") {
+		res.Header.Set(header.ETag, "W/"+etag)
+	}
+
+	return nil
+}
+
+// compressCachedResponse compresses a response served from the cache, which doesn't go through the response chain.
+// The cache stores the plain body, so it's compressed per request like the upstream responses.
+func compressCachedResponse(spec *APISpec, res *http.Response, req *http.Request) {
+	if !spec.ResponseCompression.Enabled {
+		return
+	}
+
+	m := &ResponseCompressionMiddleware{BaseTykResponseHandler: BaseTykResponseHandler{Spec: spec}}
+	_ = m.HandleResponse(nil, res, req, nil)
+}
+
+// compressible tells whether the response can be compressed, regardless of the encodings the client accepts.
+func (m *ResponseCompressionMiddleware) compressible(res *http.Response, req *http.Request) bool {
+	if req.Method == http.MethodHead || httputil.IsStreamingRequest(req) || httputil.IsStreamingResponse(res) {
+		return false
+	}
+
+	switch res.StatusCode {
+	case http.StatusSwitchingProtocols, http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+		return false
+	}
+
+	if encoding := res.Header.Get(header.ContentEncoding); encoding != "" && !strings.EqualFold(encoding, "identity") {
+		return false
+	}
+
+	if strings.Contains(strings.ToLower(res.Header.Get(header.CacheControl)), "no-transform") {
+		return false
+	}
+
+	contentTypes := m.Spec.ResponseCompression.ContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = defaultCompressionContentTypes
+	}
+
+	return matchesMediaType(res.Header.Get(header.ContentType), contentTypes)
+}
+
+// matchesMediaType tells whether the media type of contentType is one of mediaTypes.
+// The `type/*` and `type/*+suffix` entries match every subtype, and the subtypes with the suffix.
+func matchesMediaType(contentType string, mediaTypes []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	for _, candidate := range mediaTypes {
+		candidateType, candidateSubtype, _ := strings.Cut(strings.ToLower(strings.TrimSpace(candidate)), "/")
+		if candidateType != typ {
+			continue
+		}
+
+		switch {
+		case candidateSubtype == "*", candidateSubtype == subtype:
+			return true
+		case strings.HasPrefix(candidateSubtype, "*+") && strings.HasSuffix(subtype, candidateSubtype[1:]):
+			return true
+		}
+	}
+
+	return false
+}
+
+// negotiateEncoding returns the first of algorithms accepted by the Accept-Encoding header, if any.
+func negotiateEncoding(acceptEncoding string, algorithms []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+
+		quality := 1.0
+		if name, value, found := strings.Cut(strings.TrimSpace(params), "="); found && strings.TrimSpace(name) == "q" {
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				quality = q
+			}
+		}
+
+		accepted[coding] = quality > 0
+	}
+
+	for _, algorithm := range algorithms {
+		algorithm = strings.ToLower(algorithm)
+		if algorithm != compressionGzip && algorithm != compressionBrotli {
+			continue
+		}
+
+		ok, listed := accepted[algorithm]
+		if !listed {
+			ok = accepted["*"]
+		}
+
+		if ok {
+			return algorithm
+		}
+	}
+
+	return ""
+}
+
+// addVary adds value to the Vary header unless it's already listed.
+func addVary(h http.Header, value string) {
+	for _, vary := range h.Values(header.Vary) {
+		for _, field := range strings.Split(vary, ",") {
+			field = strings.TrimSpace(field)
+			if field == "*" || strings.EqualFold(field, value) {
+				return
+			}
+		}
+	}
+
+	h.Add(header.Vary, value)
+}
+
+// compressedBody is the compressed stream of a response body. The body is compressed in
+// a goroutine as it's read, closing compressedBody stops it and closes the original body.
+type compressedBody struct {
+	*io.PipeReader
+	src io.ReadCloser
+}
+
+func newCompressedBody(src io.ReadCloser, encoding string) *compressedBody {
+	pr, pw := io.Pipe()
+
+	go func() {
+		var encoder io.WriteCloser
+		if encoding == compressionBrotli {
+			encoder = brotli.NewWriter(pw)
+		} else {
+			encoder = gzip.NewWriter(pw)
+		}
+
+		_, err := io.Copy(encoder, src)
+		if closeErr := encoder.Close(); err == nil {
+			err = closeErr
+		}
+		pw.CloseWithError(err)
+	}()
+
+	return &compressedBody{PipeReader: pr, src: src}
+}
+
+func (b *compressedBody) Close() error {
+	_ = b.PipeReader.Close()
+	return b.src.Close()
+}
@@ -0,0 +1,200 @@
+package gateway
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+)
+
+func TestResponseCompression(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	largeJSON := `{"items":["` + strings.Repeat("item", 1024) + `"]}`
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/large":
+			w.Header().Set(header.ContentType, "application/json; charset=utf-8")
+			w.Header().Set(header.ETag, `"v1"`)
+			_, _ = io.WriteString(w, largeJSON)
+		case "/small":
+			w.Header().Set(header.ContentType, header.ApplicationJSON)
+			_, _ = io.WriteString(w, `{"ok":true}`)
+		case "/image":
+			w.Header().Set(header.ContentType, "image/png")
+			_, _ = io.WriteString(w, strings.Repeat("x", 4096))
+		case "/encoded":
+			w.Header().Set(header.ContentType, header.ApplicationJSON)
+			w.Header().Set(header.ContentEncoding, "deflate")
+			_, _ = io.WriteString(w, largeJSON)
+		case "/events":
+			w.Header().Set(header.ContentType, "text/event-stream")
+			for i := 0; i < 100; i++ {
+				_, _ = io.WriteString(w, "data: "+strings.Repeat("event", 20)+"\n\n")
+			}
+		}
+	}))
+	defer upstream.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.ResponseCompression = apidef.ResponseCompression{Enabled: true}
+	}, func(spec *APISpec) {
+		spec.APIID = "cached"
+		spec.Proxy.ListenPath = "/cached/"
+		spec.Proxy.StripListenPath = true
+		spec.Proxy.TargetURL = upstream.URL
+		spec.ResponseCompression = apidef.ResponseCompression{Enabled: true}
+		spec.CacheOptions = apidef.CacheOptions{EnableCache: true, CacheAllSafeRequests: true, CacheTimeout: 60}
+	})
+
+	// The transport would decompress gzip transparently, the test checks the encoded body.
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+
+	get := func(t *testing.T, path, acceptEncoding string) (*http.Response, []byte) {
+		t.Helper()
+
+		req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		require.NoError(t, err)
+		if acceptEncoding != "" {
+			req.Header.Set(header.AcceptEncoding, acceptEncoding)
+		}
+
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res, body
+	}
+
+	t.Run("large JSON is gzipped", func(t *testing.T) {
+		res, body := get(t, "/large", "gzip, deflate")
+
+		assert.Equal(t, "gzip", res.Header.Get(header.ContentEncoding))
+		assert.Equal(t, header.AcceptEncoding, res.Header.Get(header.Vary))
+		assert.Empty(t, res.Header.Get(header.ContentLength))
+		assert.Equal(t, `W/"v1"`, res.Header.Get(header.ETag))
+		assert.Less(t, len(body), len(largeJSON))
+
+		reader, err := gzip.NewReader(strings.NewReader(string(body)))
+		require.NoError(t, err)
+		decoded, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, largeJSON, string(decoded))
+	})
+
+	t.Run("cached response is compressed", func(t *testing.T) {
+		res, _ := get(t, "/cached/large", "")
+		assert.Empty(t, res.Header.Get(header.ContentEncoding))
+		assert.Empty(t, res.Header.Get(cachedResponseHeader))
+
+		res, body := get(t, "/cached/large", "gzip")
+		assert.Equal(t, "1", res.Header.Get(cachedResponseHeader))
+		assert.Equal(t, "gzip", res.Header.Get(header.ContentEncoding))
+		assert.Equal(t, header.AcceptEncoding, res.Header.Get(header.Vary))
+
+		reader, err := gzip.NewReader(strings.NewReader(string(body)))
+		require.NoError(t, err)
+		decoded, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, largeJSON, string(decoded))
+	})
+
+	t.Run("brotli is preferred", func(t *testing.T) {
+		res, body := get(t, "/large", "gzip;q=0.8, br")
+
+		assert.Equal(t, "br", res.Header.Get(header.ContentEncoding))
+		decoded, err := io.ReadAll(brotli.NewReader(strings.NewReader(string(body))))
+		require.NoError(t, err)
+		assert.Equal(t, largeJSON, string(decoded))
+	})
+
+	t.Run("left untouched", func(t *testing.T) {
+		for name, tc := range map[string]struct{ path, acceptEncoding string }{
+			"not accepted":     {"/large", ""},
+			"refused":          {"/large", "br;q=0, gzip;q=0"},
+			"below min size":   {"/small", "gzip"},
+			"content type":     {"/image", "gzip"},
+			"already encoded":  {"/encoded", "gzip"},
+			"identity only":    {"/large", "identity"},
+			"unknown encoding": {"/large", "zstd"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				res, _ := get(t, tc.path, tc.acceptEncoding)
+				assert.NotEqual(t, "gzip", res.Header.Get(header.ContentEncoding))
+				assert.NotEqual(t, "br", res.Header.Get(header.ContentEncoding))
+			})
+		}
+	})
+
+	t.Run("SSE stream is untouched", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/events", nil)
+		require.NoError(t, err)
+		req.Header.Set(header.AcceptEncoding, "gzip, br")
+
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Empty(t, res.Header.Get(header.ContentEncoding))
+		assert.Empty(t, res.Header.Get(header.Vary))
+
+		line, err := bufio.NewReader(res.Body).ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "data: "+strings.Repeat("event", 20)+"\n", line)
+	})
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	for _, tc := range []struct {
+		acceptEncoding string
+		algorithms     []string
+		expected       string
+	}{
+		{"", defaultCompressionAlgorithms, ""},
+		{"gzip", defaultCompressionAlgorithms, "gzip"},
+		{"gzip, br", defaultCompressionAlgorithms, "br"},
+		{"gzip, br", []string{"gzip", "br"}, "gzip"},
+		{"gzip, br;q=0", defaultCompressionAlgorithms, "gzip"},
+		{"*", defaultCompressionAlgorithms, "br"},
+		{"*, br;q=0", defaultCompressionAlgorithms, "gzip"},
+		{"deflate", defaultCompressionAlgorithms, ""},
+		{"GZIP", []string{"gzip"}, "gzip"},
+		{"gzip", []string{"zstd"}, ""},
+	} {
+		assert.Equal(t, tc.expected, negotiateEncoding(tc.acceptEncoding, tc.algorithms), tc.acceptEncoding)
+	}
+}
+
+func TestMatchesMediaType(t *testing.T) {
+	for _, tc := range []struct {
+		contentType string
+		expected    bool
+	}{
+		{"application/json", true},
+		{"application/json; charset=utf-8", true},
+		{"application/problem+json", true},
+		{"application/soap+xml", true},
+		{"text/html", true},
+		{"image/png", false},
+		{"application/octet-stream", false},
+		{"", false},
+	} {
+		assert.Equal(t, tc.expected, matchesMediaType(tc.contentType, defaultCompressionContentTypes), tc.contentType)
+	}
+}
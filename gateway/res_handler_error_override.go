@@ -51,10 +51,7 @@ func (r *ResponseErrorOverrideMiddleware) HandleResponse(
 		return nil
 	}
 
-	logger := r.logger().WithFields(logrus.Fields{
-		"prefix": "error-override",
-		"api_id": r.Spec.APIID,
-	})
+	logger := r.requestLogger(req).WithField("prefix", "error-override")
 
 	bodyReader := newLazyBodyReader(res.Body, logger)
 	overrides := NewErrorOverrides(r.Spec, r.Gw)
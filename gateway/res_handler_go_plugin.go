@@ -16,7 +16,7 @@ type ResponseGoPluginMiddleware struct {
 	BaseTykResponseHandler
 	Path       string // path to .so file
 	SymbolName string // function symbol to look up
-	ResHandler func(rw http.ResponseWriter, res *http.Response, req *http.Request)
+	ResHandler goplugin.ResponseHandlerWithSession
 }
 
 func (h *ResponseGoPluginMiddleware) Base() *BaseTykResponseHandler {
@@ -55,7 +55,7 @@ func (h *ResponseGoPluginMiddleware) Init(c interface{}, spec *APISpec) error {
 	}
 
 	// try to load plugin
-	if h.ResHandler, err = goplugin.GetResponseHandler(h.Path, h.SymbolName); err != nil {
+	if h.ResHandler, err = goplugin.GetResponseHandlerWithSession(h.Path, h.SymbolName); err != nil {
 		h.logger().WithError(err).Error("Could not load Go-plugin")
 		return err
 	}
@@ -64,17 +64,24 @@ func (h *ResponseGoPluginMiddleware) Init(c interface{}, spec *APISpec) error {
 	return nil
 }
 
-func (h *ResponseGoPluginMiddleware) HandleResponse(w http.ResponseWriter, res *http.Response, req *http.Request, _ *user.SessionState) error {
-	return h.HandleGoPluginResponse(w, res, req)
+func (h *ResponseGoPluginMiddleware) HandleResponse(w http.ResponseWriter, res *http.Response, req *http.Request, ses *user.SessionState) error {
+	return h.handleGoPluginResponse(w, res, req, ses)
 }
 
+// HandleGoPluginResponse runs the plugin without a session.
 func (h *ResponseGoPluginMiddleware) HandleGoPluginResponse(w http.ResponseWriter, res *http.Response, req *http.Request) error {
+	return h.handleGoPluginResponse(w, res, req, nil)
+}
+
+func (h *ResponseGoPluginMiddleware) handleGoPluginResponse(w http.ResponseWriter, res *http.Response, req *http.Request, ses *user.SessionState) error {
+	logger := h.requestLogger(req)
+
 	// make sure tyk recover in case Go-plugin function panics
 	defer func() {
 		if e := recover(); e != nil {
 			err := fmt.Errorf("%v", e)
 			w.WriteHeader(http.StatusInternalServerError)
-			h.logger().WithError(err).Error("Recovered from panic while running Go-plugin middleware func")
+			logger.WithError(err).Error("Recovered from panic while running Go-plugin middleware func")
 		}
 	}()
 
@@ -90,11 +97,11 @@ func (h *ResponseGoPluginMiddleware) HandleGoPluginResponse(w http.ResponseWrite
 	// call Go-plugin function
 	t1 := time.Now()
 
-	h.ResHandler(rw, res, req)
+	h.ResHandler(rw, res, req, ses)
 
 	// calculate latency
 	ms := DurationToMillisecond(time.Since(t1))
-	h.logger().WithField("ms", ms).Debug("Go-plugin response processing took")
+	logger.WithField("ms", ms).Debug("Go-plugin response processing took")
 
 	// check if response was sent
 	if rw.responseSent {
@@ -104,7 +111,7 @@ func (h *ResponseGoPluginMiddleware) HandleGoPluginResponse(w http.ResponseWrite
 			// base middleware will report this error to analytics if needed
 			w.WriteHeader(rw.statusCodeSent)
 			err := fmt.Errorf("plugin function sent error response code: %d", rw.statusCodeSent)
-			h.logger().WithError(err).Error("Returned error code while processing response with Go-plugin middleware func")
+			logger.WithError(err).Error("Returned error code while processing response with Go-plugin middleware func")
 			return err
 		}
 	}
@@ -67,6 +67,7 @@ func (h *HeaderInjector) HandleResponse(rw http.ResponseWriter, res *http.Respon
 			h.logger().Debugf("Adding: %v: %v", nKey, nVal)
 			setCustomHeader(res.Header, nKey, h.Gw.ReplaceTykVariables(req, nVal, false), ignoreCanonical)
 		}
+		rewriteHeaders(res.Header, hmeta.RewriteHeaders)
 	}
 
 	// Manage global response header options with versionInfo
@@ -81,6 +82,8 @@ func (h *HeaderInjector) HandleResponse(rw http.ResponseWriter, res *http.Respon
 			setCustomHeader(res.Header, key, h.Gw.ReplaceTykVariables(req, val, false), ignoreCanonical)
 		}
 
+		rewriteHeaders(res.Header, vInfo.GlobalResponseHeadersRewrite)
+
 		// Manage global response header options with response_processors
 		for _, n := range h.config.RemoveHeaders {
 			h.logger().Debug("Removing global: ", n)
@@ -3,6 +3,8 @@ package gateway
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -302,3 +304,61 @@ func TestVersionInfo_HasEndpointResHeader(t *testing.T) {
 	v.ExtendedPaths.TransformResponseHeader[1].DeleteHeaders = []string{"a"}
 	assert.True(t, v.HasEndpointResHeader())
 }
+
+func TestResponseHeaderRewrite(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "http://internal.local:8080/resources/1")
+		w.Header().Set("X-Correlation-ID", r.Header.Get("X-Correlation-ID"))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer upstream.Close()
+
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	spec := BuildAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.UseExtendedPaths = true
+			v.ExtendedPaths.TransformHeader = []apidef.HeaderInjectionMeta{{
+				Path:   "/resources",
+				Method: http.MethodPost,
+				RewriteHeaders: []apidef.HeaderRewrite{
+					{Header: "x-correlation-id", MatchRegex: `^(\w{4})\w+`, Replace: "$1****"},
+				},
+			}}
+			v.ExtendedPaths.TransformResponseHeader = []apidef.HeaderInjectionMeta{{
+				Path:          "/resources",
+				Method:        http.MethodPost,
+				ActOnResponse: true,
+				RewriteHeaders: []apidef.HeaderRewrite{
+					{Header: "location", MatchRegex: `^https?://internal\.local(:\d+)?`, Replace: "https://api.example.com"},
+				},
+			}}
+		})
+	})[0]
+	ts.Gw.LoadAPI(spec)
+
+	_, _ = ts.Run(t, test.TestCase{
+		Method:  http.MethodPost,
+		Path:    "/resources",
+		Headers: map[string]string{"X-Correlation-ID": "abcd1234efgh"},
+		Code:    http.StatusCreated,
+		HeadersMatch: map[string]string{
+			"Location":         "https://api.example.com/resources/1",
+			"X-Correlation-ID": "abcd****",
+		},
+	})
+
+	t.Run("invalid regex fails API validation", func(t *testing.T) {
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.TransformResponseHeader[0].RewriteHeaders[0].MatchRegex = "([a-z"
+		})
+
+		assert.Error(t, spec.Validate(ts.Gw.GetConfig().OAS))
+
+		ts.Gw.LoadAPI(spec)
+		_, _ = ts.Run(t, test.TestCase{Method: http.MethodPost, Path: "/resources", Code: http.StatusNotFound})
+	})
+}
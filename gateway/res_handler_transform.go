@@ -6,9 +6,11 @@ import (
 	"compress/gzip"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"strconv"
 
 	"github.com/clbanning/mxj"
@@ -126,7 +128,12 @@ func (r *ResponseTransformMiddleware) HandleResponse(rw http.ResponseWriter, res
 	respBody := respBodyReader(req, res)
 	defer respBody.Close()
 
-	body, err := r.GetResponseBody(respBody, logger)
+	var bodyReader io.Reader = respBody
+	if tmeta.Format == apidef.TransformFormatJQ {
+		bodyReader = r.Gw.transformJQInputReader(respBody)
+	}
+
+	body, err := r.GetResponseBody(bodyReader, logger)
 	if err != nil {
 		if errors.Is(err, ErrResponseSizeLimitExceeded) {
 			handler := ErrorHandler{&BaseMiddleware{Spec: r.Spec, Gw: r.Gw}}
@@ -136,6 +143,28 @@ func (r *ResponseTransformMiddleware) HandleResponse(rw http.ResponseWriter, res
 		return err
 	}
 
+	if tmeta.Format == apidef.TransformFormatJQ {
+		out, err := r.Gw.transformJQ(req, r.Spec, tmeta, body)
+		if err != nil {
+			logger.WithError(err).Error("Failed to apply JQ transform to response")
+
+			errMsg := "Response body could not be transformed"
+			if errors.Is(err, errTransformJQInputTooLarge) {
+				errMsg = "Response body too large"
+			}
+
+			handler := ErrorHandler{&BaseMiddleware{Spec: r.Spec, Gw: r.Gw}}
+			handler.HandleError(rw, req, errMsg, http.StatusInternalServerError, true)
+
+			return err
+		}
+
+		logger.Debugf("%s", msgBodyTransformed)
+		r.setBody(res, *bytes.NewBuffer(out))
+
+		return nil
+	}
+
 	// Put into an interface:
 	bodyData := make(map[string]interface{})
 	switch tmeta.TemplateData.Input {
@@ -150,8 +179,7 @@ func (r *ResponseTransformMiddleware) HandleResponse(rw http.ResponseWriter, res
 		xmlMap, err := mxj.NewMapXml(body) // unmarshal
 		if err != nil {
 			logger.WithError(err).Error("Error unmarshalling XML")
-			//todo return error
-			break
+			return r.handleMalformedXML(res, req, fmt.Errorf("%w: %v", apidef.ErrMalformedXML, err))
 		}
 		for k, v := range xmlMap {
 			bodyData[k] = v
@@ -192,19 +220,43 @@ func (r *ResponseTransformMiddleware) HandleResponse(rw http.ResponseWriter, res
 	var bodyBuffer bytes.Buffer
 	if err := tmeta.Template.Execute(&bodyBuffer, bodyData); err != nil {
 		logger.WithError(err).Error("Failed to apply template to request")
+		if errors.Is(err, apidef.ErrMalformedXML) {
+			return r.handleMalformedXML(res, req, err)
+		}
 	} else {
 		logger.Debugf("%s", msgBodyTransformed)
 	}
 
-	// Re-compress if original upstream response was compressed
+	r.setBody(res, bodyBuffer)
+
+	return nil
+}
+
+// handleMalformedXML replaces the upstream response with a bad gateway error detailing why its XML couldn't
+// be parsed. The response is replaced in place, so the rest of the response chain and the proxy send the error
+// instead of the upstream response.
+func (r *ResponseTransformMiddleware) handleMalformedXML(res *http.Response, req *http.Request, err error) error {
+	handler := ErrorHandler{&BaseMiddleware{Spec: r.Spec, Gw: r.Gw}}
+	recorder := httptest.NewRecorder()
+	handler.writeTemplateErrorResponse(recorder, req, "Upstream response could not be transformed: "+err.Error(), http.StatusBadGateway)
+
+	res.StatusCode = http.StatusBadGateway
+	res.Status = fmt.Sprintf("%d %s", http.StatusBadGateway, http.StatusText(http.StatusBadGateway))
+	res.Header = recorder.Header().Clone()
+	r.setBody(res, *recorder.Body)
+
+	return nil
+}
+
+// setBody replaces the body of the response with the transformed one, re-compressing it
+// if the original upstream response was compressed.
+func (r *ResponseTransformMiddleware) setBody(res *http.Response, bodyBuffer bytes.Buffer) {
 	encoding := res.Header.Get("Content-Encoding")
 	bodyBuffer = compressBuffer(bodyBuffer, encoding)
 
 	res.ContentLength = int64(bodyBuffer.Len())
 	res.Header.Set("Content-Length", strconv.Itoa(bodyBuffer.Len()))
 	res.Body = ioutil.NopCloser(&bodyBuffer)
-
-	return nil
 }
 
 // GetResponseBody reads the response body with size limit enforcement
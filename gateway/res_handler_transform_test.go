@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/test"
 )
 
@@ -211,6 +213,82 @@ func TestTransformResponseBody(t *testing.T) {
 	})
 }
 
+func TestTransformResponseBody_SOAP(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(header.ContentType, header.TextXML)
+		switch r.URL.Path {
+		case "/add":
+			_, _ = io.WriteString(w, `<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+	<soap:Body>
+		<AddResponse xmlns="http://tempuri.org/">
+			<AddResult>5</AddResult>
+			<Items>
+				<Item id="1">a</Item>
+				<Item id="2">b</Item>
+			</Items>
+		</AddResponse>
+	</soap:Body>
+</soap:Envelope>`)
+		case "/malformed":
+			_, _ = io.WriteString(w, `<soap:Envelope><soap:Body>`)
+		case "/embedded":
+			w.Header().Set(header.ContentType, header.ApplicationJSON)
+			_, _ = io.WriteString(w, `{"xml": "<a><b></a>"}`)
+		}
+	}))
+	defer upstream.Close()
+
+	transform := func(path string, input apidef.RequestInputType, tmpl string) apidef.TemplateMeta {
+		return apidef.TemplateMeta{
+			Path:   path,
+			Method: http.MethodGet,
+			TemplateData: apidef.TemplateData{
+				Input:          input,
+				Mode:           apidef.UseBlob,
+				TemplateSource: base64.StdEncoding.EncodeToString([]byte(tmpl)),
+			},
+		}
+	}
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.TransformResponse = []apidef.TemplateMeta{
+				transform("/add", apidef.RequestXML, `{{ $r := (soapBody . "stripNamespaces" "attributePrefix=@").AddResponse }}`+
+					`{"result": {{ $r.AddResult }}, "items": {{ jsonMarshal $r.Items.Item }}}`),
+				transform("/malformed", apidef.RequestXML, `{{ jsonMarshal . }}`),
+				transform("/embedded", apidef.RequestJSON, `{{ jsonMarshal (xmlToMap .xml) }}`),
+			}
+		})
+	})
+
+	resp, err := ts.Run(t, test.TestCase{Path: "/add", Code: http.StatusOK})
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"result": 5, "items": [{"@id": "1", "#text": "a"}, {"@id": "2", "#text": "b"}]}`, string(body))
+
+	for path, message := range map[string]string{
+		"/malformed": `Upstream response could not be transformed: malformed XML: xml.Decoder.Token() - XML syntax error on line 1: unexpected EOF`,
+		"/embedded":  `Upstream response could not be transformed: template: :1:16: executing \"\" at \u003CxmlToMap .xml\u003E: error calling xmlToMap: malformed XML: line 1: element \u003Cb\u003E closed by \u003C/a\u003E`,
+	} {
+		resp, err := ts.Run(t, test.TestCase{Path: path, Code: http.StatusBadGateway})
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "{\n    \"error\": \""+message+"\"\n}", string(body))
+		assert.Equal(t, header.ApplicationJSON, resp.Header.Get(header.ContentType))
+		assert.Equal(t, strconv.Itoa(len(body)), resp.Header.Get(header.ContentLength))
+	}
+}
+
 func TestResponseTransformMiddleware_Enabled(t *testing.T) {
 	getTransformResponseConf := func(disabled bool, path string) apidef.TemplateMeta {
 		return apidef.TemplateMeta{
@@ -0,0 +1,100 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/TykTechnologies/tyk/storage"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// IdempotencyResponseMiddleware stores the response of a request holding an idempotency key lock,
+// so it's replayed for its duplicates. Server errors aren't stored so the request can be retried,
+// and responses larger than the size limit are returned with a warning header instead.
+type IdempotencyResponseMiddleware struct {
+	BaseTykResponseHandler
+	store *storage.RedisCluster
+}
+
+func (m *IdempotencyResponseMiddleware) Base() *BaseTykResponseHandler {
+	return &m.BaseTykResponseHandler
+}
+
+func (m *IdempotencyResponseMiddleware) Name() string {
+	return "IdempotencyResponseMiddleware"
+}
+
+func (m *IdempotencyResponseMiddleware) Enabled() bool {
+	return m.Spec.Idempotency.Enabled
+}
+
+func (m *IdempotencyResponseMiddleware) Init(_ interface{}, spec *APISpec) error {
+	m.Spec = spec
+	return nil
+}
+
+func (m *IdempotencyResponseMiddleware) HandleError(_ http.ResponseWriter, _ *http.Request) {
+}
+
+func (m *IdempotencyResponseMiddleware) HandleResponse(_ http.ResponseWriter, res *http.Response, r *http.Request, _ *user.SessionState) error {
+	state := ctxGetIdempotencyState(r)
+	if res == nil || state == nil || state.key == "" {
+		return nil
+	}
+
+	if res.StatusCode >= http.StatusInternalServerError {
+		return nil
+	}
+
+	maxSize := m.Spec.Idempotency.MaxResponseSize
+	if maxSize <= 0 {
+		maxSize = defaultIdempotencyMaxResponseSize
+	}
+
+	if res.ContentLength > maxSize {
+		res.Header.Set(idempotencyWarningHeader, "response too large to be stored")
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxSize+1))
+	if err != nil || int64(len(body)) > maxSize {
+		// Return what was read followed by the rest of the body, without storing it.
+		res.Body = readCloser{io.MultiReader(bytes.NewReader(body), res.Body), res.Body}
+		if err == nil {
+			res.Header.Set(idempotencyWarningHeader, "response too large to be stored")
+		}
+		return nil
+	}
+
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	stored, err := json.Marshal(idempotentResponse{
+		StatusCode: res.StatusCode,
+		Header:     res.Header.Clone(),
+		Body:       body,
+	})
+	if err != nil {
+		m.logger().WithError(err).Error("Could not encode the idempotent response")
+		return nil
+	}
+
+	ttl := m.Spec.Idempotency.TTL
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+
+	if err := m.store.SetKey(state.key, string(stored), ttl); err != nil {
+		m.logger().WithError(err).Error("Could not store the idempotent response")
+	}
+
+	return nil
+}
+
+// readCloser reads from Reader and closes Closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -26,6 +26,7 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/akutz/memconn"
@@ -45,9 +46,12 @@ import (
 	"github.com/TykTechnologies/tyk/internal/graphengine"
 	"github.com/TykTechnologies/tyk/internal/httputil"
 	"github.com/TykTechnologies/tyk/internal/mcp"
+	"github.com/TykTechnologies/tyk/internal/model"
 	"github.com/TykTechnologies/tyk/internal/otel"
 	"github.com/TykTechnologies/tyk/internal/service/core"
+	"github.com/TykTechnologies/tyk/internal/uuid"
 	"github.com/TykTechnologies/tyk/regexp"
+	"github.com/TykTechnologies/tyk/request"
 	"github.com/TykTechnologies/tyk/storage"
 	"github.com/TykTechnologies/tyk/trace"
 	"github.com/TykTechnologies/tyk/user"
@@ -161,6 +165,9 @@ func (gw *Gateway) nextTarget(targetData *apidef.HostList, spec *APISpec) (strin
 		// Use a HostList
 		startPos := spec.RoundRobin.WithLen(targetData.Len())
 		pos := startPos
+		// ejected is the first host which is up but ejected by outlier detection,
+		// it is only picked when all the hosts which are up are ejected.
+		var ejected string
 		for {
 			gotHost, err := targetData.GetIndex(pos)
 			if err != nil {
@@ -168,22 +175,21 @@ func (gw *Gateway) nextTarget(targetData *apidef.HostList, spec *APISpec) (strin
 			}
 
 			host := EnsureTransport(gotHost, spec.Protocol)
-			if !spec.Proxy.CheckHostAgainstUptimeTests {
-				return host, nil // we don't care if it's up
-			}
+			if !gw.upstreamHostDown(host, spec) {
+				if !spec.upstreamHostEjected(host) {
+					return host, nil
+				}
 
-			// GlobalHostCheck has not been initialized, return the host picked
-			// by round-robin algorithm.
-			if gw.GlobalHostChecker == nil {
-				return host, nil // we don't care if it's up
-			}
-			// As checked by HostCheckerManager.AmIPolling
-			if !gw.GlobalHostChecker.HostDown(host) {
-				return host, nil // we do care and it's up
+				if ejected == "" {
+					ejected = host
+				}
 			}
-			// if the host is down, keep trying all the rest
+			// if the host is down or ejected, keep trying all the rest
 			// in order from where we started.
 			if pos = (pos + 1) % targetData.Len(); pos == startPos {
+				if ejected != "" {
+					return ejected, nil
+				}
 				return "", fmt.Errorf("all hosts are down, uptime tests are failing")
 			}
 		}
@@ -199,12 +205,48 @@ func (gw *Gateway) nextTarget(targetData *apidef.HostList, spec *APISpec) (strin
 	return EnsureTransport(gotHost, spec.Protocol), nil
 }
 
+// upstreamHostDown reports whether uptime tests found a load balanced target down.
+func (gw *Gateway) upstreamHostDown(host string, spec *APISpec) bool {
+	if !spec.Proxy.CheckHostAgainstUptimeTests {
+		return false // we don't care if it's up
+	}
+
+	// GlobalHostCheck has not been initialized, use the host picked
+	// by round-robin algorithm.
+	if gw.GlobalHostChecker == nil {
+		return false // we don't care if it's up
+	}
+
+	// As checked by HostCheckerManager.AmIPolling
+	return gw.GlobalHostChecker.HostDown(host)
+}
+
 var (
 	onceStartAllHostsDown sync.Once
 
 	allHostsDownURL string
 )
 
+// upstreamHostHeader returns the Host header configured for the upstream request, with the endpoint
+// level value taking precedence over the API level one. Context variables in the value are replaced.
+func (gw *Gateway) upstreamHostHeader(spec *APISpec, r *http.Request) (string, bool) {
+	host := spec.Proxy.UpstreamHostHeader
+
+	if spec.UpstreamHostEnabled {
+		if vInfo, _ := spec.Version(r); vInfo != nil {
+			if urlSpec, found := spec.FindSpecMatchesStatus(r, spec.RxPaths[vInfo.Name], UpstreamHostHeader); found {
+				host = urlSpec.UpstreamHost.Host
+			}
+		}
+	}
+
+	if host == "" {
+		return "", false
+	}
+
+	return gw.ReplaceTykVariables(r, host, false), true
+}
+
 // TykNewSingleHostReverseProxy returns a new ReverseProxy that rewrites
 // URLs to the scheme, host, and base path provided in target. If the
 // target's path is "/base" and the incoming request was for "/dir",
@@ -243,10 +285,18 @@ func (gw *Gateway) TykNewSingleHostReverseProxy(target *url.URL, spec *APISpec,
 		logger := logger
 		spec := spec
 		target := target
+		targetQuery := targetQuery
 		gw := gw
 
+		// Resolved before the path is joined with the target path, so endpoints match the inbound request.
+		upstreamHost, setUpstreamHost := gw.upstreamHostHeader(spec, req)
+
 		hostList := spec.Proxy.StructuredTargetList
 		switch {
+		case ctxGetCanaryTarget(req) != nil:
+			// The canary upstream replaces the target URL and the load balanced targets.
+			target = ctxGetCanaryTarget(req)
+			targetQuery = target.RawQuery
 		case spec.Proxy.ServiceDiscovery.UseDiscoveryService:
 			var err error
 			hostList, err = urlFromService(spec, gw)
@@ -317,6 +367,10 @@ func (gw *Gateway) TykNewSingleHostReverseProxy(target *url.URL, spec *APISpec,
 			req.Host = targetToUse.Host
 		}
 
+		if setUpstreamHost {
+			req.Host = upstreamHost
+		}
+
 		if targetQuery == "" || req.URL.RawQuery == "" {
 			req.URL.RawQuery = targetQuery + req.URL.RawQuery
 		} else {
@@ -422,7 +476,7 @@ func (p *ReverseProxy) defaultTransport(dialerTimeout float64) *http.Transport {
 		DualStack: true,
 	}
 	dialContextFunc := dialer.DialContext
-	if p.Gw.dnsCacheManager.IsCacheEnabled() {
+	if p.Gw.dnsCacheManager.IsCacheEnabled() || p.Gw.dnsCacheManager.Resolver() != nil {
 		dialContextFunc = p.Gw.dnsCacheManager.WrapDialer(dialer)
 	}
 
@@ -767,6 +821,12 @@ func tlsClientConfig(s *APISpec, gw *Gateway) *tls.Config {
 		config.Renegotiation = tls.RenegotiateFreelyAsClient
 	}
 
+	config.ServerName = s.Proxy.Transport.SSLServerName
+
+	if gw != nil {
+		config.RootCAs, _ = gw.upstreamCACertificates(s)
+	}
+
 	return config
 }
 
@@ -825,6 +885,10 @@ func (p *ReverseProxy) httpTransport(timeOut float64, rw http.ResponseWriter, re
 		transport.TLSClientConfig.Renegotiation = tls.RenegotiateFreelyAsClient
 	}
 
+	transport.TLSClientConfig.ServerName = p.TykAPISpec.Proxy.Transport.SSLServerName
+	p.TykAPISpec.HTTPTransportCAsGen = p.Gw.upstreamCAGeneration.Load()
+	transport.TLSClientConfig.RootCAs, p.TykAPISpec.HTTPTransportCAs = p.Gw.upstreamCACertificates(p.TykAPISpec)
+
 	transport.DisableKeepAlives = p.TykAPISpec.GlobalConfig.ProxyCloseConnections
 
 	if p.Gw.GetConfig().ProxyEnableHttp2 {
@@ -833,19 +897,13 @@ func (p *ReverseProxy) httpTransport(timeOut float64, rw http.ResponseWriter, re
 
 	p.logger.Debug("Out request url: ", outReq.URL.String())
 
-	if outReq.URL.Scheme == "h2c" {
+	if h2cUpstream(p.TykAPISpec, outReq) {
 		p.logger.Info("Enabling h2c mode")
-		h2t := &http2.Transport{
-			// kind of a hack, but for plaintext/H2C requests, pretend to dial TLS
-			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
-				return net.Dial(network, addr)
-			},
-			AllowHTTP: true,
-		}
-		return &TykRoundTripper{transport, h2t, p.logger, p.Gw}
+		h2t := newH2CTransport(transport.DialContext, p.Gw.GetConfig().ProxyH2CConnectionsPerTarget)
+		return &TykRoundTripper{transport, h2t, p.logger, p.Gw, p.TykAPISpec.APIID}
 	}
 
-	return &TykRoundTripper{transport, nil, p.logger, p.Gw}
+	return &TykRoundTripper{transport, nil, p.logger, p.Gw, p.TykAPISpec.APIID}
 }
 
 func (p *ReverseProxy) setCommonNameVerifyPeerCertificate(tlsConfig *tls.Config, hostName string) {
@@ -902,6 +960,7 @@ type TykRoundTripper struct {
 	h2ctransport *http2.Transport
 	logger       *logrus.Entry
 	Gw           *Gateway `json:"-"`
+	apiID        string
 }
 
 func (rt *TykRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
@@ -913,14 +972,20 @@ func (rt *TykRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
 			r.Header.Del(apidef.TykInternalApiHeader)
 		}
 
-		handler, _, found := rt.Gw.findInternalHttpHandlerByNameOrID(r.Host)
+		// The target is looked up by the URL, as the Host header may be overridden for the upstream.
+		handler, _, found := rt.Gw.findInternalHttpHandlerByNameOrID(r.URL.Host)
 		if !found {
-			rt.logger.WithField("looping_url", "tyk://"+r.Host).Error("Couldn't detect target")
+			rt.logger.WithField("looping_url", "tyk://"+r.URL.Host).Error("Couldn't detect target")
 			return nil, errors.New("handler could")
 		}
 
-		rt.logger.WithField("looping_url", "tyk://"+r.Host).Debug("Executing request on internal route")
+		if err := rt.Gw.checkLoopLimit(r, rt.apiID); err != nil {
+			return nil, err
+		}
+
+		rt.logger.WithField("looping_url", "tyk://"+r.URL.Host).Debug("Executing request on internal route")
 
+		ctxAppendLoopTrace(r, rt.apiID)
 		return handleInMemoryLoop(handler, r)
 	}
 
@@ -951,6 +1016,9 @@ type memConnProvider struct {
 	listener net.Listener
 	provider *memconn.Provider
 	expireAt time.Time
+	// inFlight counts the requests being served through the listener, a
+	// provider with in-flight requests is never considered idle.
+	inFlight int64
 }
 
 var memConnProviders = &struct {
@@ -967,7 +1035,7 @@ func cleanIdleMemConnProvidersEagerly(pointInTime time.Time) {
 	defer memConnProviders.mtx.Unlock()
 
 	for host, mp := range memConnProviders.m {
-		if mp.expireAt.Before(pointInTime) {
+		if mp.expireAt.Before(pointInTime) && atomic.LoadInt64(&mp.inFlight) == 0 {
 			delete(memConnProviders.m, host)
 			// on listener.Close http.Serve will return with error and stop goroutine
 			_ = mp.listener.Close()
@@ -1012,10 +1080,13 @@ func getMemConnProvider(addr string) (*memconn.Provider, error) {
 // createMemConnProviderIfNeeded creates a new memconn.Provider and net.Listener
 // for the given host.
 func createMemConnProviderIfNeeded(handler http.Handler, r *http.Request) error {
+	// Providers are keyed by the host memConnClient dials, which may differ from the Host header.
+	host := r.URL.Hostname()
+
 	memConnProviders.mtx.Lock()
 	defer memConnProviders.mtx.Unlock()
 
-	p, ok := memConnProviders.m[r.Host]
+	p, ok := memConnProviders.m[host]
 	if ok {
 		// Clean the providers and close its listener, if it is idle for a while.
 		p.expireAt = time.Now().Add(maxIdleMemConnDuration)
@@ -1029,21 +1100,34 @@ func createMemConnProviderIfNeeded(handler http.Handler, r *http.Request) error
 		return err
 	}
 
+	mp := &memConnProvider{
+		listener: lis,
+		provider: provider,
+		expireAt: time.Now().Add(maxIdleMemConnDuration),
+	}
+
 	// start http server with in mem listener
 	// Note: do not try to use http.Server it is working only with mux
 	mux := http.NewServeMux()
 	mux.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, wrappingHandlerReq *http.Request) {
-		reqWithPropagatedContext := wrappingHandlerReq.WithContext(r.Context())
+		atomic.AddInt64(&mp.inFlight, 1)
+		defer atomic.AddInt64(&mp.inFlight, -1)
+
+		reqCtx := r.Context()
+		if id := wrappingHandlerReq.Header.Get(inMemoryLoopIDHeader); id != "" {
+			wrappingHandlerReq.Header.Del(inMemoryLoopIDHeader)
+			if loopCtx, ok := inMemoryLoopContexts.Load(id); ok {
+				reqCtx = loopCtx.(context.Context)
+			}
+		}
+
+		reqWithPropagatedContext := wrappingHandlerReq.WithContext(reqCtx)
 		handler.ServeHTTP(w, reqWithPropagatedContext)
 	}))
 
 	go func() { _ = http.Serve(lis, mux) }()
 
-	memConnProviders.m[r.Host] = &memConnProvider{
-		listener: lis,
-		provider: provider,
-		expireAt: time.Now().Add(maxIdleMemConnDuration),
-	}
+	memConnProviders.m[host] = mp
 	return nil
 }
 
@@ -1060,13 +1144,27 @@ var memConnClient = &http.Client{
 	},
 }
 
+// inMemoryLoopIDHeader carries the key of the calling request's context in inMemoryLoopContexts.
+const inMemoryLoopIDHeader = "X-Tyk-In-Memory-Loop-Id"
+
+// inMemoryLoopContexts holds the contexts of the requests sent to internal APIs over memconn,
+// so the internal API sees the loop trace of the request that called it.
+var inMemoryLoopContexts sync.Map
+
 func handleInMemoryLoop(handler http.Handler, r *http.Request) (resp *http.Response, err error) {
 	err = createMemConnProviderIfNeeded(handler, r)
 	if err != nil {
 		return nil, err
 	}
 
+	id := uuid.New()
+	inMemoryLoopContexts.Store(id, r.Context())
+	defer inMemoryLoopContexts.Delete(id)
+
+	r.Header.Set(inMemoryLoopIDHeader, id)
 	r.URL.Scheme = "http"
+	// proxied requests carry the RequestURI of the inbound request, which a client request can't have.
+	r.RequestURI = ""
 	return memConnClient.Do(r)
 }
 
@@ -1081,10 +1179,55 @@ func (p *ReverseProxy) handleOutboundRequest(roundTripper *TykRoundTripper, outr
 		return
 	}
 
+	if trace := ctxGetChainTrace(outreq); trace != nil {
+		trace.upstream(outreq)
+		if trace.DryRun {
+			res = dryRunResponse(outreq)
+			return
+		}
+	}
+
 	res, err = p.sendRequestToUpstream(roundTripper, outreq)
+	if err == nil && res.StatusCode == http.StatusUnauthorized {
+		res, err = p.retryWithRefreshedAuth(roundTripper, outreq, res)
+	}
 	return
 }
 
+// retryWithRefreshedAuth sends the request once more when the upstream rejected its authentication
+// details and the upstream auth provider renewed them. Otherwise the rejection is returned.
+func (p *ReverseProxy) retryWithRefreshedAuth(roundTripper *TykRoundTripper, outreq *http.Request, res *http.Response) (*http.Response, error) {
+	if !p.TykAPISpec.UpstreamAuth.IsEnabled() {
+		return res, nil
+	}
+
+	refresher, ok := core.GetUpstreamAuth(outreq).(model.UpstreamAuthRefresher)
+	if !ok {
+		return res, nil
+	}
+
+	// The body must be replayed, which is only possible when it was buffered.
+	if outreq.Body != nil {
+		body, ok := outreq.Body.(io.Seeker)
+		if !ok {
+			return res, nil
+		}
+
+		if _, err := body.Seek(0, io.SeekStart); err != nil {
+			return res, nil
+		}
+	}
+
+	if !refresher.Refresh(outreq) {
+		return res, nil
+	}
+
+	p.logger.Debug("Upstream rejected the authentication details, retrying with refreshed ones")
+	res.Body.Close()
+
+	return p.sendRequestToUpstream(roundTripper, outreq)
+}
+
 func isCORSPreflight(r *http.Request) bool {
 	return r.Method == http.MethodOptions
 }
@@ -1147,6 +1290,11 @@ func (p *ReverseProxy) sendRequestToUpstream(roundTripper *TykRoundTripper, outr
 }
 
 func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Request, withCache bool) ProxyResponse {
+	p.routeCanary(req)
+
+	// set before the context is copied to the outbound request, the response chain restores it from there
+	sizes := ctxGetOrCreatePayloadSizes(req)
+
 	if trace.IsEnabled() {
 		span, ctx := trace.Span(req.Context(), req.URL.Path)
 		defer span.Finish()
@@ -1202,6 +1350,8 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 	if req.ContentLength == 0 {
 		outreq.Body = nil // Issue 16036: nil Body for http.Transport retries
 	}
+
+	sizes.countRequest(outreq)
 	outreq = outreq.WithContext(reqCtx)
 	setContext(logreq, outreq.Context())
 
@@ -1245,6 +1395,13 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 		logreq.Header.Set("Connection", "Upgrade")
 		outreq.Header.Set("Upgrade", reqUpType)
 		logreq.Header.Set("Upgrade", reqUpType)
+
+		if reqUpType == "websocket" {
+			if err := restrictWebSocketSubprotocols(p.TykAPISpec.Proxy.WebSocket, outreq.Header); err != nil {
+				p.ErrorHandler.HandleError(rw, logreq, err.Error(), http.StatusBadRequest, true)
+				return ProxyResponse{}
+			}
+		}
 	}
 
 	addrs := requestIPHops(req)
@@ -1252,6 +1409,13 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 		outreq.Header.Set(header.XForwardFor, addrs)
 	}
 
+	grpcWeb := p.isGRPCWebRequest(req)
+	if grpcWeb {
+		grpcWebToGRPCRequest(outreq)
+	}
+
+	p.mirrorRequest(req, outreq)
+
 	// Circuit breaker
 	breakerEnforced, breakerConf := p.CheckCircuitBreakerEnforced(p.TykAPISpec, req)
 
@@ -1283,13 +1447,29 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 		createTransport = time.Since(p.TykAPISpec.HTTPTransportCreated) > time.Duration(p.Gw.GetConfig().MaxConnTime)*time.Second
 	}
 
+	// Recreate the transport when the upstream CA certificates were rotated. The fingerprint is only
+	// recomputed after a certificate was added or removed.
+	if gen := p.Gw.upstreamCAGeneration.Load(); !createTransport && gen != p.TykAPISpec.HTTPTransportCAsGen &&
+		len(p.TykAPISpec.Proxy.Transport.SSLCACertificates) > 0 {
+		_, fingerprint := p.Gw.upstreamCACertificates(p.TykAPISpec)
+		p.TykAPISpec.HTTPTransportCAsGen = gen
+		createTransport = fingerprint != p.TykAPISpec.HTTPTransportCAs
+	}
+
 	if createTransport {
-		var oldTransport *http.Transport
+		var (
+			oldTransport *http.Transport
+			oldH2CPool   *h2cConnPool
+		)
 
 		if p.TykAPISpec.HTTPTransport != nil {
 			oldTransport = p.TykAPISpec.HTTPTransport.transport
 			// Prevent new idle connections to be generated.
 			oldTransport.DisableKeepAlives = true
+
+			if h2t := p.TykAPISpec.HTTPTransport.h2ctransport; h2t != nil {
+				oldH2CPool, _ = h2t.ConnPool.(*h2cConnPool)
+			}
 		}
 
 		timeout := proxyTimeout(p.TykAPISpec)
@@ -1313,6 +1493,10 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 		if oldTransport != nil {
 			oldTransport.CloseIdleConnections()
 		}
+
+		if oldH2CPool != nil {
+			oldH2CPool.closeIdleConnections()
+		}
 	}
 
 	roundTripper = p.TykAPISpec.HTTPTransport
@@ -1345,7 +1529,16 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 
 	}
 
-	p.addAuthInfo(outreq, req)
+	if err := p.addAuthInfo(outreq, req); err != nil {
+		p.logger.WithError(err).Error("Couldn't sign the upstream request")
+
+		code := http.StatusInternalServerError
+		if errors.Is(err, errUnbufferedBody) {
+			code = http.StatusBadRequest
+		}
+		p.ErrorHandler.HandleError(rw, logreq, err.Error(), code, true)
+		return ProxyResponse{}
+	}
 
 	// do request round trip
 	var (
@@ -1375,6 +1568,12 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 		res, isHijacked, upstreamLatency, err = p.handleOutboundRequest(roundTripper, outreq, rw)
 	}
 
+	p.TykAPISpec.recordUpstreamResult(outreq.URL.Host, res, err)
+
+	if p.TykAPISpec.LatencyBreakdown.Enabled {
+		ctxGetOrCreateLatencyBreakdown(req).add(latencyStageProxy, upstreamLatency)
+	}
+
 	if err != nil {
 		// Classify the upstream error for structured access logs
 		errClass := tykerrors.ClassifyUpstreamError(err, outreq.URL.Host+outreq.URL.Path)
@@ -1421,6 +1620,13 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 			p.ErrorHandler.HandleError(rw, logreq, "Upstream host lookup failed", http.StatusInternalServerError, true)
 			return ProxyResponse{UpstreamLatency: upstreamLatency}
 		}
+
+		var loopErr *loopDetectedError
+		if errors.As(err, &loopErr) {
+			p.ErrorHandler.HandleError(rw, logreq, loopErr.Error(), http.StatusLoopDetected, true)
+			return ProxyResponse{UpstreamLatency: upstreamLatency}
+		}
+
 		p.ErrorHandler.HandleError(rw, logreq, "There was a problem proxying the request", http.StatusInternalServerError, true)
 		return ProxyResponse{UpstreamLatency: upstreamLatency}
 
@@ -1434,9 +1640,19 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 		return ProxyResponse{UpstreamLatency: upstreamLatency}
 	}
 
+	if grpcWeb {
+		grpcToGRPCWebResponse(res)
+	}
+
 	_, upgrade := p.IsUpgrade(req)
 	// Deal with 101 Switching Protocols responses: (WebSocket, h2c, etc)
 	if upgrade && res.StatusCode == 101 {
+		if err := checkWebSocketSubprotocol(p.TykAPISpec.Proxy.WebSocket, outreq.Header, res.Header); err != nil {
+			res.Body.Close()
+			p.ErrorHandler.HandleError(rw, logreq, err.Error(), http.StatusBadGateway, true)
+			return ProxyResponse{UpstreamLatency: upstreamLatency}
+		}
+
 		if err := p.handleUpgradeResponse(rw, outreq, res); err != nil {
 			p.ErrorHandler.HandleError(rw, logreq, err.Error(), http.StatusInternalServerError, true)
 			return ProxyResponse{UpstreamLatency: upstreamLatency}
@@ -1487,6 +1703,10 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 		}
 	}
 
+	if !upgrade {
+		sizes.countResponse(res)
+	}
+
 	if withCache {
 		*inres = *res // includes shallow copies of maps, but okay
 
@@ -1516,6 +1736,14 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 	// which copies res.Header into rw.Header.
 	augmentMCPWWWAuthenticate(res, logreq, p.TykAPISpec)
 
+	removeUpstreamRequestID(logreq, res.Header)
+
+	if conf := p.TykAPISpec.LatencyBreakdown; conf.Enabled && conf.DebugHeaders {
+		if breakdown := ctxGetLatencyBreakdown(req).format(conf.Threshold); breakdown != "" {
+			rw.Header().Set(header.XTykLatencyBreakdown, breakdown)
+		}
+	}
+
 	p.HandleResponse(rw, res, ses)
 	return ProxyResponse{UpstreamLatency: upstreamLatency, Response: inres}
 }
@@ -1570,13 +1798,17 @@ func (p *ReverseProxy) HandleResponse(rw http.ResponseWriter, res *http.Response
 
 	isStreaming := httputil.IsStreamingResponse(res)
 	copyDst := io.Writer(rw)
+	cleanup := func() {}
 	if isStreaming {
-		if w, cleanup := p.prepareSSEStreaming(rw, res.Body); w != nil {
+		if w, stop := p.prepareSSEStreaming(rw, res); w != nil {
 			copyDst = w
-			defer cleanup()
+			cleanup = stop
 		}
 	}
-	if err := p.CopyResponse(copyDst, res.Body, p.flushInterval(res)); err != nil {
+	err := p.CopyResponse(copyDst, res.Body, p.flushInterval(res))
+	// nothing else may be written to the stream once the upstream body is copied
+	cleanup()
+	if err != nil {
 		p.handleCopyError(rw, err, isStreaming)
 	}
 
@@ -1679,7 +1911,13 @@ func upgradeType(h http.Header) string {
 }
 
 func (p *ReverseProxy) handleUpgradeResponse(rw http.ResponseWriter, req *http.Request, res *http.Response) error {
-	copyHeader(res.Header, rw.Header(), p.Gw.GetConfig().IgnoreCanonicalMIMEHeaderKey)
+	// The headers set by the gateway are added to the handshake, except those negotiated
+	// with the upstream, which would otherwise be sent twice and fail the handshake.
+	gwHeader := rw.Header().Clone()
+	for _, h := range webSocketHandshakeHeaders {
+		gwHeader.Del(h)
+	}
+	copyHeader(res.Header, gwHeader, p.Gw.GetConfig().IgnoreCanonicalMIMEHeaderKey)
 
 	hj, ok := rw.(http.Hijacker)
 	if !ok {
@@ -1713,11 +1951,24 @@ func (p *ReverseProxy) handleUpgradeResponse(rw http.ResponseWriter, req *http.R
 	if err := brw.Flush(); err != nil {
 		return fmt.Errorf("response flush: %w", err)
 	}
-	errc := make(chan error, 1)
-	spc := switchProtocolCopier{user: conn, backend: backConn}
-	go spc.copyToBackend(errc)
-	go spc.copyFromBackend(errc)
-	<-errc
+
+	isWebSocket := upgradeType(res.Header) == "websocket"
+	if isWebSocket && p.Gw.ConnectionWatcher != nil {
+		p.Gw.ConnectionWatcher.AddWebSocket(p.TykAPISpec.APIID, 1)
+		defer p.Gw.ConnectionWatcher.AddWebSocket(p.TykAPISpec.APIID, -1)
+	}
+
+	if wsConf := p.TykAPISpec.Proxy.WebSocket; isWebSocket && wsConf.Enabled {
+		if err := newWebSocketLimiter(wsConf, conn, backConn).proxy(); errors.Is(err, errWebSocketMessageTooBig) {
+			p.logger.WithError(err).Debug("Closed websocket connection")
+		}
+	} else {
+		errc := make(chan error, 1)
+		spc := switchProtocolCopier{user: conn, backend: backConn}
+		go spc.copyToBackend(errc)
+		go spc.copyFromBackend(errc)
+		<-errc
+	}
 
 	res.Body = ioutil.NopCloser(strings.NewReader(""))
 
@@ -1801,7 +2052,8 @@ func requestIPHops(r *http.Request) string {
 	// If we aren't the first proxy retain prior
 	// X-Forwarded-For information as a comma+space
 	// separated list and fold multiple headers into one.
-	if prior, ok := r.Header["X-Forwarded-For"]; ok {
+	// The prior hops are dropped when they weren't added by a trusted proxy.
+	if prior, ok := r.Header["X-Forwarded-For"]; ok && request.FromTrustedProxy(r) {
 		clientIP = strings.Join(prior, ", ") + ", " + clientIP
 	}
 	return clientIP
@@ -2040,14 +2292,22 @@ func (p *ReverseProxy) IsUpgrade(req *http.Request) (string, bool) {
 	return httputil.IsUpgrade(req)
 }
 
-func (p *ReverseProxy) addAuthInfo(outReq, req *http.Request) {
+func (p *ReverseProxy) addAuthInfo(outReq, req *http.Request) error {
 	if !p.TykAPISpec.UpstreamAuth.IsEnabled() {
-		return
+		return nil
 	}
 
-	if authProvider := core.GetUpstreamAuth(req); authProvider != nil {
-		authProvider.Fill(outReq)
+	authProvider := core.GetUpstreamAuth(req)
+	if authProvider == nil {
+		return nil
 	}
+
+	if signer, ok := authProvider.(model.UpstreamAuthSigner); ok {
+		return signer.Sign(outReq)
+	}
+
+	authProvider.Fill(outReq)
+	return nil
 }
 
 // initUpstreamCertBatcher initializes the upstream certificate expiry batcher (called lazily via sync.Once)
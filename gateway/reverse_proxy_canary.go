@@ -0,0 +1,136 @@
+package gateway
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/ctx"
+	"github.com/TykTechnologies/tyk/request"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+const (
+	canaryRouteCanary = "canary"
+	canaryRouteStable = "stable"
+
+	// canaryRouteContextVar is the context variable holding the route chosen for the request.
+	canaryRouteContextVar = "canary_route"
+	canaryRouteTagPrefix  = "canary-route-"
+)
+
+// canaryRoute is the route chosen for a request to an API with canary routing.
+type canaryRoute struct {
+	name   string
+	target *url.URL
+}
+
+// routeCanary chooses the route of the request, before its target is selected by the director.
+// The route is exposed as the `canary_route` context variable, when context variables are enabled.
+func (p *ReverseProxy) routeCanary(req *http.Request) {
+	canary := p.TykAPISpec.Proxy.Canary
+	if !canary.Enabled || canary.TargetURL == "" {
+		return
+	}
+
+	route := &canaryRoute{name: canaryRouteStable}
+	if matchesCanary(canary, req) {
+		target, err := url.Parse(canary.TargetURL)
+		if err != nil {
+			p.logger.WithError(err).Error("Invalid canary target URL")
+		} else {
+			route = &canaryRoute{name: canaryRouteCanary, target: target}
+		}
+	}
+
+	setCtxValue(req, ctx.CanaryRoute, route)
+	if data := ctxGetData(req); data != nil {
+		data[canaryRouteContextVar] = route.name
+	}
+}
+
+// matchesCanary tells whether the request is routed to the canary, either by a rule or by its percentage.
+func matchesCanary(canary apidef.ProxyCanary, req *http.Request) bool {
+	session := ctxGetSession(req)
+	for _, rule := range canary.Rules {
+		if matchesCanaryRule(rule, req, session) {
+			return true
+		}
+	}
+
+	if canary.Percentage <= 0 {
+		return false
+	}
+
+	if canary.Percentage >= 100 {
+		return true
+	}
+
+	identity := ctxGetAuthToken(req)
+	if identity == "" {
+		identity = request.RealIP(req)
+	}
+
+	return canaryBucket(identity) < canary.Percentage
+}
+
+func matchesCanaryRule(rule apidef.CanaryRule, req *http.Request, session *user.SessionState) bool {
+	var value string
+	switch rule.Source {
+	case apidef.CanaryRuleHeader:
+		value = req.Header.Get(rule.Name)
+	case apidef.CanaryRuleQuery:
+		value = req.URL.Query().Get(rule.Name)
+	case apidef.CanaryRuleSessionMetadata:
+		if session == nil {
+			return false
+		}
+
+		v, ok := session.MetaData[rule.Name]
+		if !ok || v == nil {
+			return false
+		}
+		value = fmt.Sprint(v)
+	default:
+		return false
+	}
+
+	if rule.Value == "" {
+		return value != ""
+	}
+
+	return strings.EqualFold(value, rule.Value)
+}
+
+// canaryBucket maps the identity to a stable position between 0 and 100.
+func canaryBucket(identity string) float64 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(identity))
+	return float64(h.Sum32()%10000) / 100
+}
+
+func ctxGetCanaryRoute(r *http.Request) *canaryRoute {
+	route, _ := r.Context().Value(ctx.CanaryRoute).(*canaryRoute)
+	return route
+}
+
+// ctxGetCanaryTarget returns the canary upstream the request is routed to, if any.
+func ctxGetCanaryTarget(r *http.Request) *url.URL {
+	if route := ctxGetCanaryRoute(r); route != nil {
+		return route.target
+	}
+	return nil
+}
+
+// canaryRouteTag returns the analytics tag of the route chosen for the request.
+func canaryRouteTag(r *http.Request) (string, bool) {
+	route := ctxGetCanaryRoute(r)
+	if route == nil {
+		return "", false
+	}
+
+	return canaryRouteTagPrefix + route.name, true
+}
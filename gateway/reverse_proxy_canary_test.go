@@ -0,0 +1,130 @@
+package gateway
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestCanaryBucket(t *testing.T) {
+	for _, identity := range []string{"", "key-1", "key-2", "127.0.0.1"} {
+		bucket := canaryBucket(identity)
+		assert.GreaterOrEqual(t, bucket, float64(0))
+		assert.Less(t, bucket, float64(100))
+		assert.Equal(t, bucket, canaryBucket(identity), "the bucket is stable")
+	}
+}
+
+func TestReverseProxy_canary(t *testing.T) {
+	upstream := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, name+" "+r.URL.Path)
+		}))
+	}
+
+	stable := upstream("stable")
+	defer stable.Close()
+	canary := upstream("canary")
+	defer canary.Close()
+
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "canary-rules"
+		spec.Proxy.ListenPath = "/rules/"
+		spec.Proxy.StripListenPath = true
+		spec.Proxy.TargetURL = stable.URL
+		spec.UseKeylessAccess = false
+		spec.EnableContextVars = true
+		spec.Proxy.Canary = apidef.ProxyCanary{
+			Enabled:   true,
+			TargetURL: canary.URL + "/v2",
+			Rules: []apidef.CanaryRule{
+				{Source: apidef.CanaryRuleHeader, Name: "X-Canary", Value: "true"},
+				{Source: apidef.CanaryRuleQuery, Name: "canary"},
+				{Source: apidef.CanaryRuleSessionMetadata, Name: "beta", Value: "true"},
+			},
+		}
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.UseExtendedPaths = true
+			v.GlobalResponseHeaders = map[string]string{"X-Route": "$tyk_context.canary_route"}
+		})
+	}, func(spec *APISpec) {
+		spec.APIID = "canary-percentage"
+		spec.Proxy.ListenPath = "/percentage/"
+		spec.Proxy.StripListenPath = true
+		spec.Proxy.TargetURL = stable.URL
+		spec.UseKeylessAccess = false
+		spec.Proxy.Canary = apidef.ProxyCanary{
+			Enabled:    true,
+			TargetURL:  canary.URL,
+			Percentage: 50,
+		}
+	})
+
+	_, key := ts.CreateSession(func(s *user.SessionState) {
+		s.AccessRights = map[string]user.AccessDefinition{"canary-rules": {APIID: "canary-rules"}}
+	})
+	_, betaKey := ts.CreateSession(func(s *user.SessionState) {
+		s.AccessRights = map[string]user.AccessDefinition{"canary-rules": {APIID: "canary-rules"}}
+		s.MetaData = map[string]interface{}{"beta": true}
+	})
+
+	auth := func(key string) map[string]string {
+		return map[string]string{header.Authorization: key}
+	}
+
+	t.Run("header match", func(t *testing.T) {
+		headers := auth(key)
+		headers["X-Canary"] = "True"
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/rules/users", Headers: auth(key), BodyMatch: "^stable /users$", HeadersMatch: map[string]string{"X-Route": "stable"}},
+			{Path: "/rules/users", Headers: headers, BodyMatch: "^canary /v2/users$", HeadersMatch: map[string]string{"X-Route": "canary"}},
+		}...)
+	})
+
+	t.Run("query match", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/rules/users?canary=1", Headers: auth(key), BodyMatch: "^canary /v2/users$"},
+			{Path: "/rules/users?canary=", Headers: auth(key), BodyMatch: "^stable /users$"},
+		}...)
+	})
+
+	t.Run("session metadata match", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{
+			Path: "/rules/users", Headers: auth(betaKey), BodyMatch: "^canary /v2/users$", HeadersMatch: map[string]string{"X-Route": "canary"},
+		})
+	})
+
+	t.Run("percentage is deterministic per key", func(t *testing.T) {
+		routes := map[string]int{}
+		for i := 0; i < 20; i++ {
+			_, key := ts.CreateSession(func(s *user.SessionState) {
+				s.AccessRights = map[string]user.AccessDefinition{"canary-percentage": {APIID: "canary-percentage"}}
+			})
+
+			expected := "^stable /$"
+			if canaryBucket(key) < 50 {
+				expected = "^canary /$"
+			}
+			routes[expected]++
+
+			for j := 0; j < 3; j++ {
+				_, _ = ts.Run(t, test.TestCase{Path: "/percentage/", Headers: auth(key), BodyMatch: expected})
+			}
+		}
+
+		require.Len(t, routes, 2, "both routes are taken")
+	})
+}
@@ -0,0 +1,119 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/header"
+)
+
+const (
+	grpcContentType    = "application/grpc"
+	grpcWebContentType = "application/grpc-web"
+
+	// grpcWebTrailerFlag marks the length-prefixed frame carrying the trailers
+	// at the end of a gRPC-Web response body.
+	grpcWebTrailerFlag byte = 0x80
+)
+
+// isGRPCWebRequest reports whether the request should be translated from gRPC-Web to gRPC.
+// Only the binary format is supported, application/grpc-web-text requests are proxied as is.
+func (p *ReverseProxy) isGRPCWebRequest(r *http.Request) bool {
+	if !p.TykAPISpec.Proxy.GRPCWeb.Enabled {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get(header.ContentType))
+	if err != nil {
+		return false
+	}
+
+	return mediaType == grpcWebContentType || strings.HasPrefix(mediaType, grpcWebContentType+"+")
+}
+
+// grpcWebToGRPCRequest turns a gRPC-Web request into a gRPC one. Both protocols
+// frame the messages the same way, so only the headers need to be changed.
+func grpcWebToGRPCRequest(r *http.Request) {
+	contentType := r.Header.Get(header.ContentType)
+	r.Header.Set(header.ContentType, grpcContentType+strings.TrimPrefix(contentType, grpcWebContentType))
+	r.Header.Set("Te", "trailers")
+	r.Header.Del("X-Grpc-Web")
+}
+
+// grpcToGRPCWebResponse turns a gRPC response into a gRPC-Web one. The trailers sent
+// by the upstream are appended to the body as a trailer frame, as browsers can't read
+// HTTP trailers. A trailers-only response already carries the status in its headers,
+// which gRPC-Web clients accept, so its body is left untouched.
+func grpcToGRPCWebResponse(res *http.Response) {
+	contentType := res.Header.Get(header.ContentType)
+	if strings.HasPrefix(contentType, grpcContentType) {
+		res.Header.Set(header.ContentType, grpcWebContentType+strings.TrimPrefix(contentType, grpcContentType))
+	}
+
+	if res.Header.Get("Grpc-Status") != "" {
+		return
+	}
+
+	res.Header.Del(header.ContentLength)
+	res.ContentLength = -1
+	res.Body = &grpcWebResponseBody{ReadCloser: res.Body, res: res}
+	res.Trailer = nil
+}
+
+// grpcWebResponseBody streams the upstream body and appends the trailer frame once it's consumed.
+type grpcWebResponseBody struct {
+	io.ReadCloser
+	res     *http.Response
+	trailer *bytes.Reader
+}
+
+func (b *grpcWebResponseBody) Read(p []byte) (int, error) {
+	if b.trailer != nil {
+		return b.trailer.Read(p)
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if err != io.EOF {
+		return n, err
+	}
+
+	// The transport sets the trailers on the response once the body is read,
+	// move them into the body so they aren't sent as HTTP trailers too.
+	b.trailer = bytes.NewReader(grpcWebTrailerFrame(b.res.Trailer))
+	b.res.Trailer = nil
+
+	if n > 0 {
+		return n, nil
+	}
+	return b.trailer.Read(p)
+}
+
+// grpcWebTrailerFrame encodes trailers as a gRPC-Web trailer frame, a flag byte and
+// the length of the HTTP/1 style header block that follows.
+func grpcWebTrailerFrame(trailer http.Header) []byte {
+	keys := make([]string, 0, len(trailer))
+	for k := range trailer {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var block bytes.Buffer
+	for _, k := range keys {
+		for _, v := range trailer[k] {
+			block.WriteString(strings.ToLower(k))
+			block.WriteString(": ")
+			block.WriteString(v)
+			block.WriteString("\r\n")
+		}
+	}
+
+	frame := make([]byte, 5, 5+block.Len())
+	frame[0] = grpcWebTrailerFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(block.Len()))
+	return append(frame, block.Bytes()...)
+}
@@ -0,0 +1,186 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pbexample "google.golang.org/grpc/examples/helloworld/helloworld"
+	pb "google.golang.org/grpc/examples/route_guide/routeguide"
+
+	"github.com/TykTechnologies/tyk/header"
+)
+
+type grpcWebFrame struct {
+	flag byte
+	data []byte
+}
+
+func encodeGRPCWebMessage(t *testing.T, msg proto.Message) []byte {
+	t.Helper()
+
+	data, err := proto.Marshal(msg)
+	require.NoError(t, err)
+
+	frame := make([]byte, 5, 5+len(data))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	return append(frame, data...)
+}
+
+func decodeGRPCWebFrames(t *testing.T, body []byte) []grpcWebFrame {
+	t.Helper()
+
+	var frames []grpcWebFrame
+	for len(body) > 0 {
+		require.GreaterOrEqual(t, len(body), 5, "truncated frame header")
+		length := int(binary.BigEndian.Uint32(body[1:5]))
+		require.GreaterOrEqual(t, len(body), 5+length, "truncated frame")
+
+		frames = append(frames, grpcWebFrame{flag: body[0], data: body[5 : 5+length]})
+		body = body[5+length:]
+	}
+	return frames
+}
+
+func doGRPCWebRequest(t *testing.T, url string, body []byte) (*http.Response, []byte) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set(header.ContentType, "application/grpc-web+proto")
+	req.Header.Set("X-Grpc-Web", "1")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res, resBody
+}
+
+func TestGRPCWeb(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	helloTarget, helloSrv := startGRPCServerH2C(t, setupHelloSVC)
+	defer helloTarget.Close()
+	defer helloSrv.Stop()
+
+	streamTarget, streamSrv := startGRPCServerH2C(t, setupStreamSVC)
+	defer streamTarget.Close()
+	defer streamSrv.Stop()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "grpc-web-hello"
+		spec.Proxy.ListenPath = "/hello/"
+		spec.Proxy.StripListenPath = true
+		spec.UseKeylessAccess = true
+		spec.Proxy.TargetURL = toTarget(t, "h2c", helloTarget)
+		spec.Proxy.GRPCWeb.Enabled = true
+	}, func(spec *APISpec) {
+		spec.APIID = "grpc-web-stream"
+		spec.Proxy.ListenPath = "/stream/"
+		spec.Proxy.StripListenPath = true
+		spec.UseKeylessAccess = true
+		spec.Proxy.TargetURL = toTarget(t, "h2c", streamTarget)
+		spec.Proxy.GRPCWeb.Enabled = true
+	}, func(spec *APISpec) {
+		spec.APIID = "grpc-web-disabled"
+		spec.Proxy.ListenPath = "/disabled/"
+		spec.Proxy.StripListenPath = true
+		spec.UseKeylessAccess = true
+		spec.Proxy.TargetURL = toTarget(t, "h2c", helloTarget)
+	})
+
+	t.Run("unary", func(t *testing.T) {
+		body := encodeGRPCWebMessage(t, &pbexample.HelloRequest{Name: "Josh"})
+		res, resBody := doGRPCWebRequest(t, ts.URL+"/hello/helloworld.Greeter/SayHello", body)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "application/grpc-web+proto", res.Header.Get(header.ContentType))
+		assert.Empty(t, res.Trailer)
+
+		frames := decodeGRPCWebFrames(t, resBody)
+		require.Len(t, frames, 2)
+
+		assert.Equal(t, byte(0), frames[0].flag)
+		reply := &pbexample.HelloReply{}
+		require.NoError(t, proto.Unmarshal(frames[0].data, reply))
+		assert.Equal(t, "Hello Josh", reply.Message)
+
+		assert.Equal(t, grpcWebTrailerFlag, frames[1].flag)
+		assert.Contains(t, string(frames[1].data), "grpc-status: 0\r\n")
+	})
+
+	t.Run("server streaming", func(t *testing.T) {
+		rect := &pb.Rectangle{
+			Lo: &pb.Point{Latitude: 400000000, Longitude: -750000000},
+			Hi: &pb.Point{Latitude: 420000000, Longitude: -730000000},
+		}
+
+		var want []string
+		for _, feature := range newServer(t).savedFeatures {
+			if inRange(feature.Location, rect) {
+				want = append(want, feature.Name)
+			}
+		}
+		require.NotEmpty(t, want)
+
+		res, resBody := doGRPCWebRequest(t, ts.URL+"/stream/routeguide.RouteGuide/ListFeatures", encodeGRPCWebMessage(t, rect))
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		frames := decodeGRPCWebFrames(t, resBody)
+		require.Len(t, frames, len(want)+1)
+
+		var got []string
+		for _, frame := range frames[:len(want)] {
+			assert.Equal(t, byte(0), frame.flag)
+			feature := &pb.Feature{}
+			require.NoError(t, proto.Unmarshal(frame.data, feature))
+			got = append(got, feature.Name)
+		}
+		assert.Equal(t, want, got)
+
+		trailer := frames[len(frames)-1]
+		assert.Equal(t, grpcWebTrailerFlag, trailer.flag)
+		assert.Contains(t, string(trailer.data), "grpc-status: 0\r\n")
+	})
+
+	t.Run("trailers only response", func(t *testing.T) {
+		body := encodeGRPCWebMessage(t, &pbexample.HelloRequest{Name: "Josh"})
+		res, resBody := doGRPCWebRequest(t, ts.URL+"/hello/helloworld.Greeter/Unknown", body)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "application/grpc-web+proto", res.Header.Get(header.ContentType))
+		assert.Equal(t, "12", res.Header.Get("Grpc-Status"))
+		assert.Empty(t, resBody)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		body := encodeGRPCWebMessage(t, &pbexample.HelloRequest{Name: "Josh"})
+		res, _ := doGRPCWebRequest(t, ts.URL+"/disabled/helloworld.Greeter/SayHello", body)
+
+		assert.NotEqual(t, "application/grpc-web+proto", res.Header.Get(header.ContentType))
+	})
+}
+
+func TestGRPCWebTrailerFrame(t *testing.T) {
+	trailer := http.Header{
+		"Grpc-Status":  {"0"},
+		"Grpc-Message": {""},
+		"X-Custom":     {"a", "b"},
+	}
+
+	frame := grpcWebTrailerFrame(trailer)
+	block := "grpc-message: \r\ngrpc-status: 0\r\nx-custom: a\r\nx-custom: b\r\n"
+
+	assert.Equal(t, grpcWebTrailerFlag, frame[0])
+	assert.Equal(t, uint32(len(block)), binary.BigEndian.Uint32(frame[1:5]))
+	assert.Equal(t, block, string(frame[5:]))
+}
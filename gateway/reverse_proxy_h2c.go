@@ -0,0 +1,201 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/http2"
+)
+
+// h2cUpstream reports whether the upstream of the API is reached with HTTP/2 without TLS.
+// The scheme of the load balanced targets is normalised to http when a target is picked,
+// so the configured targets and the API protocol are checked as well.
+func h2cUpstream(spec *APISpec, outReq *http.Request) bool {
+	if outReq.URL.Scheme == "h2c" {
+		return true
+	}
+
+	if !spec.Proxy.EnableLoadBalancing {
+		return false
+	}
+
+	for _, target := range spec.Proxy.Targets {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(target)), "h2c://") {
+			return true
+		}
+	}
+
+	return spec.Protocol == "h2c"
+}
+
+// newH2CTransport returns the HTTP/2 transport of h2c upstreams. Its connections are opened
+// by a h2cConnPool holding connsPerTarget connections to each target.
+func newH2CTransport(dial func(ctx context.Context, network, addr string) (net.Conn, error), connsPerTarget int) *http2.Transport {
+	transport := &http2.Transport{AllowHTTP: true}
+
+	transport.ConnPool = newH2CConnPool(transport, dial, connsPerTarget)
+
+	return transport
+}
+
+// h2cConnPool is the connection pool of the h2c transport. It opens up to size connections
+// to each target and spreads the requests across them round robin, instead of multiplexing
+// all the streams to a target on a single connection.
+type h2cConnPool struct {
+	transport *http2.Transport
+	dial      func(ctx context.Context, network, addr string) (net.Conn, error)
+	size      int
+
+	mu    sync.Mutex
+	conns map[string][]*http2.ClientConn
+	next  map[string]int
+	// retired holds the connections taken out of the pool which still carry requests,
+	// they are closed once idle.
+	retired map[string][]*http2.ClientConn
+}
+
+func newH2CConnPool(transport *http2.Transport, dial func(ctx context.Context, network, addr string) (net.Conn, error), size int) *h2cConnPool {
+	if size < 1 {
+		size = 1
+	}
+
+	return &h2cConnPool{
+		transport: transport,
+		dial:      dial,
+		size:      size,
+		conns:     map[string][]*http2.ClientConn{},
+		next:      map[string]int{},
+		retired:   map[string][]*http2.ClientConn{},
+	}
+}
+
+// GetClientConn implements http2.ClientConnPool. A connection is opened while the target has
+// less than size connections, then the pooled connections are picked in turn. When all of them
+// reached the stream limit of the target, the request gets a connection of its own.
+func (p *h2cConnPool) GetClientConn(req *http.Request, addr string) (*http2.ClientConn, error) {
+	p.mu.Lock()
+	conns := p.usable(addr)
+	if len(conns) >= p.size {
+		for range conns {
+			cc := conns[p.next[addr]%len(conns)]
+			p.next[addr]++
+
+			if cc.ReserveNewRequest() {
+				p.mu.Unlock()
+				return cc, nil
+			}
+		}
+	}
+	p.mu.Unlock()
+
+	conn, err := p.dial(req.Context(), "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	cc, err := p.transport.NewClientConn(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	p.mu.Lock()
+	if len(p.usable(addr)) < p.size {
+		p.conns[addr] = append(p.conns[addr], cc)
+	} else {
+		// The pool filled up meanwhile, the connection is closed after the request.
+		cc.SetDoNotReuse()
+		p.retired[addr] = append(p.retired[addr], cc)
+	}
+	p.mu.Unlock()
+
+	if !cc.ReserveNewRequest() {
+		return nil, errors.New("h2c connection can't take new requests")
+	}
+
+	return cc, nil
+}
+
+// MarkDead implements http2.ClientConnPool.
+func (p *h2cConnPool) MarkDead(cc *http2.ClientConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, pool := range []map[string][]*http2.ClientConn{p.conns, p.retired} {
+		for addr, conns := range pool {
+			for i, c := range conns {
+				if c == cc {
+					pool[addr] = append(conns[:i:i], conns[i+1:]...)
+					return
+				}
+			}
+		}
+	}
+}
+
+// closeIdleConnections closes the pooled and retired connections without active streams.
+func (p *h2cConnPool) closeIdleConnections() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, pool := range []map[string][]*http2.ClientConn{p.conns, p.retired} {
+		for addr, conns := range pool {
+			pool[addr] = closeIdleH2CConns(conns)
+		}
+	}
+}
+
+// usable returns the pooled connections to addr which can take new requests. The others are
+// retired: they aren't reused, and they are closed once their requests are done.
+// It's called with the lock held.
+func (p *h2cConnPool) usable(addr string) []*http2.ClientConn {
+	retired := closeIdleH2CConns(p.retired[addr])
+
+	conns := p.conns[addr]
+	usable := conns[:0]
+	for _, cc := range conns {
+		if cc.CanTakeNewRequest() {
+			usable = append(usable, cc)
+			continue
+		}
+
+		cc.SetDoNotReuse()
+		if !closeIdleH2CConn(cc) {
+			retired = append(retired, cc)
+		}
+	}
+	p.conns[addr] = usable
+	p.retired[addr] = retired
+	return usable
+}
+
+// closeIdleH2CConns closes the idle connections of conns and returns the others.
+func closeIdleH2CConns(conns []*http2.ClientConn) []*http2.ClientConn {
+	active := conns[:0]
+	for _, cc := range conns {
+		if !closeIdleH2CConn(cc) {
+			active = append(active, cc)
+		}
+	}
+	return active
+}
+
+// closeIdleH2CConn closes cc if it has no active, reserved or pending streams, and reports
+// whether cc is closed.
+func closeIdleH2CConn(cc *http2.ClientConn) bool {
+	state := cc.State()
+	if state.Closed {
+		return true
+	}
+
+	if state.StreamsActive == 0 && state.StreamsReserved == 0 && state.StreamsPending == 0 {
+		cc.Close()
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,106 @@
+package gateway
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestH2CConnPool_RetiredConnections(t *testing.T) {
+	release := make(chan struct{})
+	upstream := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+		_, _ = w.Write([]byte("ok"))
+	}), &http2.Server{MaxConcurrentStreams: 1}))
+	defer upstream.Close()
+
+	addr := upstream.Listener.Addr().String()
+	var dialer net.Dialer
+	transport := newH2CTransport(dialer.DialContext, 1)
+	pool := transport.ConnPool.(*h2cConnPool)
+
+	newRequest := func(path string) *http.Request {
+		return httptest.NewRequest(http.MethodGet, "http://"+addr+path, nil)
+	}
+
+	roundTrip := func(cc *http2.ClientConn, path string) error {
+		res, err := cc.RoundTrip(newRequest(path))
+		if err != nil {
+			return err
+		}
+		_, _ = io.Copy(io.Discard, res.Body)
+		return res.Body.Close()
+	}
+
+	// Wait for the stream limit of the upstream to be known.
+	cc, err := pool.GetClientConn(newRequest("/"), addr)
+	require.NoError(t, err)
+	require.NoError(t, roundTrip(cc, "/"))
+
+	busy, err := pool.GetClientConn(newRequest("/slow"), addr)
+	require.NoError(t, err)
+	require.Same(t, cc, busy)
+
+	slowDone := make(chan error, 1)
+	go func() {
+		slowDone <- roundTrip(busy, "/slow")
+	}()
+	require.Eventually(t, func() bool {
+		return busy.State().StreamsActive == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	t.Run("busy connections are retired and closed after their requests", func(t *testing.T) {
+		next, err := pool.GetClientConn(newRequest("/"), addr)
+		require.NoError(t, err)
+		assert.NotSame(t, busy, next)
+		require.NoError(t, roundTrip(next, "/"))
+
+		pool.mu.Lock()
+		assert.Equal(t, []*http2.ClientConn{busy}, pool.retired[addr])
+		assert.Equal(t, []*http2.ClientConn{next}, pool.conns[addr])
+		pool.mu.Unlock()
+		assert.False(t, busy.State().Closed)
+
+		close(release)
+		require.NoError(t, <-slowDone)
+
+		assert.Eventually(t, func() bool {
+			return busy.State().Closed
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("idle connections which can't take requests are closed", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		pool.mu.Lock()
+		idle := pool.conns[addr][0]
+		pool.mu.Unlock()
+		idle.SetDoNotReuse()
+
+		next, err := pool.GetClientConn(newRequest("/").WithContext(ctx), addr)
+		require.NoError(t, err)
+		assert.NotSame(t, idle, next)
+		assert.True(t, idle.State().Closed)
+		require.NoError(t, roundTrip(next, "/"))
+
+		pool.closeIdleConnections()
+		assert.True(t, next.State().Closed)
+
+		pool.mu.Lock()
+		assert.Empty(t, pool.conns[addr])
+		assert.Empty(t, pool.retired[addr])
+		pool.mu.Unlock()
+	})
+}
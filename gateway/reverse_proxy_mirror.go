@@ -0,0 +1,148 @@
+package gateway
+
+import (
+	"context"
+	"io"
+	mathrand "math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TykTechnologies/tyk/internal/httputil"
+)
+
+const (
+	// mirrorWorkers is the number of goroutines sending mirrored requests.
+	mirrorWorkers = 16
+	// mirrorQueueSize is the number of mirrored requests waiting for a worker
+	// before new ones are dropped.
+	mirrorQueueSize = 256
+	// mirrorRequestTimeout bounds the time a worker spends on a single mirrored request.
+	mirrorRequestTimeout = 10 * time.Second
+)
+
+// trafficMirror sends shadow copies of proxied requests through a bounded worker pool.
+// Requests are dropped when the queue is full, so mirroring never back-pressures the primary path.
+type trafficMirror struct {
+	ctx     context.Context
+	client  *http.Client
+	queue   chan *http.Request
+	start   sync.Once
+	dropped int64
+}
+
+func newTrafficMirror(ctx context.Context) *trafficMirror {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return &trafficMirror{
+		ctx: ctx,
+		client: &http.Client{
+			Transport: http.DefaultTransport.(*http.Transport).Clone(),
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		queue: make(chan *http.Request, mirrorQueueSize),
+	}
+}
+
+// submit queues the request for mirroring. It never blocks and reports
+// whether the request was accepted.
+func (m *trafficMirror) submit(req *http.Request) bool {
+	m.start.Do(func() {
+		for i := 0; i < mirrorWorkers; i++ {
+			go m.worker()
+		}
+	})
+
+	select {
+	case m.queue <- req:
+		return true
+	default:
+		atomic.AddInt64(&m.dropped, 1)
+		log.Debug("Mirror queue is full, dropping mirrored request")
+		return false
+	}
+}
+
+func (m *trafficMirror) worker() {
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case req := <-m.queue:
+			m.send(req)
+		}
+	}
+}
+
+func (m *trafficMirror) send(req *http.Request) {
+	ctx, cancel := context.WithTimeout(m.ctx, mirrorRequestTimeout)
+	defer cancel()
+
+	resp, err := m.client.Do(req.WithContext(ctx))
+	if err != nil {
+		log.WithError(err).Debug("Mirrored request failed")
+		return
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+// shouldMirror samples the request against the configured mirror percentage.
+func shouldMirror(percentage float64) bool {
+	switch {
+	case percentage <= 0:
+		return false
+	case percentage >= 100:
+		return true
+	}
+
+	return mathrand.Float64()*100 < percentage
+}
+
+// mirrorRequest sends a copy of the request to the mirror upstream for a sampled share of the traffic.
+// The copy is sent directly to the mirror, bypassing rate limits, quotas and analytics.
+func (p *ReverseProxy) mirrorRequest(req, outreq *http.Request) {
+	mirror := p.TykAPISpec.Proxy.Mirror
+	if !mirror.Enabled || mirror.TargetURL == "" || httputil.IsStreamingRequest(req) {
+		return
+	}
+
+	if !shouldMirror(mirror.Percentage) {
+		return
+	}
+
+	target, err := url.Parse(mirror.TargetURL)
+	if err != nil {
+		p.logger.WithError(err).Debug("Invalid mirror target URL")
+		return
+	}
+
+	mirrorReq := req.Clone(context.Background())
+	p.TykAPISpec.SanitizeProxyPaths(mirrorReq)
+
+	mirrorReq.URL.Scheme = target.Scheme
+	mirrorReq.URL.Host = target.Host
+	mirrorReq.URL.Path = singleJoiningSlash(target.Path, mirrorReq.URL.Path, p.TykAPISpec.Proxy.DisableStripSlash)
+	mirrorReq.URL.RawPath = ""
+	mirrorReq.Host = target.Host
+	mirrorReq.RequestURI = ""
+	mirrorReq.Header = cloneHeader(outreq.Header)
+	mirrorReq.Body = nil
+	mirrorReq.GetBody = nil
+
+	if req.ContentLength != 0 {
+		if err := deepCopyBody(req, mirrorReq); err != nil {
+			p.logger.WithError(err).Debug("Unable to copy body of mirrored request")
+			return
+		}
+	}
+
+	p.Gw.trafficMirror.submit(mirrorReq)
+}
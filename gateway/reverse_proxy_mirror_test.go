@@ -0,0 +1,136 @@
+package gateway
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestShouldMirror(t *testing.T) {
+	assert.False(t, shouldMirror(0))
+	assert.False(t, shouldMirror(-10))
+	assert.True(t, shouldMirror(100))
+
+	const samples = 10000
+	mirrored := 0
+	for i := 0; i < samples; i++ {
+		if shouldMirror(25) {
+			mirrored++
+		}
+	}
+
+	assert.InDelta(t, 0.25, float64(mirrored)/samples, 0.03)
+}
+
+func TestReverseProxy_mirror(t *testing.T) {
+	type mirroredRequest struct {
+		path   string
+		body   string
+		header string
+	}
+
+	var (
+		mu       sync.Mutex
+		received []mirroredRequest
+	)
+
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, mirroredRequest{
+			path:   r.URL.RequestURI(),
+			body:   string(body),
+			header: r.Header.Get("X-Test"),
+		})
+		mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer mirror.Close()
+
+	mirroredCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received)
+	}
+
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "mirrored"
+		spec.Proxy.ListenPath = "/mirrored/"
+		spec.Proxy.StripListenPath = true
+		spec.Proxy.Mirror.Enabled = true
+		spec.Proxy.Mirror.TargetURL = mirror.URL + "/shadow"
+		spec.Proxy.Mirror.Percentage = 100
+	}, func(spec *APISpec) {
+		spec.APIID = "not-sampled"
+		spec.Proxy.ListenPath = "/not-sampled/"
+		spec.Proxy.Mirror.Enabled = true
+		spec.Proxy.Mirror.TargetURL = mirror.URL
+		spec.Proxy.Mirror.Percentage = 0
+	})
+
+	t.Run("request is mirrored and mirror response is ignored", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{
+			Method:    http.MethodPost,
+			Path:      "/mirrored/resource?q=1",
+			Data:      "payload",
+			Headers:   map[string]string{"X-Test": "value"},
+			Code:      http.StatusOK,
+			BodyMatch: `"Body":"payload"`,
+		})
+
+		require.Eventually(t, func() bool {
+			return mirroredCount() == 1
+		}, 5*time.Second, 10*time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, mirroredRequest{path: "/shadow/resource?q=1", body: "payload", header: "value"}, received[0])
+	})
+
+	t.Run("request is not mirrored when out of sample", func(t *testing.T) {
+		before := mirroredCount()
+		for i := 0; i < 5; i++ {
+			_, _ = ts.Run(t, test.TestCase{Path: "/not-sampled/", Code: http.StatusOK})
+		}
+
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, before, mirroredCount())
+	})
+}
+
+func TestReverseProxy_mirrorHangingUpstream(t *testing.T) {
+	release := make(chan struct{})
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer mirror.Close()
+	defer close(release)
+
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.Mirror.Enabled = true
+		spec.Proxy.Mirror.TargetURL = mirror.URL
+		spec.Proxy.Mirror.Percentage = 100
+	})
+
+	// Send more requests than there are mirror workers so that the queue fills up.
+	for i := 0; i < mirrorWorkers*2; i++ {
+		start := time.Now()
+		_, _ = ts.Run(t, test.TestCase{Path: "/", Code: http.StatusOK})
+		assert.Less(t, time.Since(start), time.Second)
+	}
+}
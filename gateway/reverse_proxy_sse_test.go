@@ -341,3 +341,111 @@ func TestSSE_NonStreamingUnaffected(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, `{"status":"ok"}`, string(body))
 }
+
+// TestSSE_KeepaliveWhileUpstreamQuiet verifies that the gateway writes SSE
+// comments to the client while the upstream is quiet, and that the
+// Last-Event-ID header is forwarded to the upstream.
+func TestSSE_KeepaliveWhileUpstreamQuiet(t *testing.T) {
+	lastEventID := make(chan string, 1)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastEventID <- r.Header.Get("Last-Event-ID")
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		fmt.Fprint(w, "id: 43\ndata: first\n\n")
+		flusher.Flush()
+
+		// stay quiet for longer than the keepalive interval
+		select {
+		case <-time.After(2500 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+
+		fmt.Fprint(w, "id: 44\ndata: last\n\n")
+		flusher.Flush()
+	}))
+	defer upstream.Close()
+
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.HttpServerOptions.EnableWebSockets = false
+		globalConf.HttpServerOptions.SSEKeepaliveInterval = 1
+	})
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.TargetURL = upstream.URL
+		spec.Proxy.ListenPath = "/"
+		spec.UseKeylessAccess = true
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Last-Event-ID", "42")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "42", <-lastEventID)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	bodyStr := string(body)
+	require.True(t, strings.HasPrefix(bodyStr, "id: 43\ndata: first\n\n: ping\n\n"), bodyStr)
+	require.True(t, strings.HasSuffix(bodyStr, ": ping\n\nid: 44\ndata: last\n\n"), bodyStr)
+	assert.Equal(t, 2, strings.Count(bodyStr, ": ping\n\n"), bodyStr)
+}
+
+// TestSSE_KeepaliveDisabledByDefault verifies that no keepalives are written
+// unless sse_keepalive_interval is set.
+func TestSSE_KeepaliveDisabledByDefault(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		fmt.Fprint(w, "data: first\n\n")
+		flusher.Flush()
+		time.Sleep(1500 * time.Millisecond)
+		fmt.Fprint(w, "data: last\n\n")
+	}))
+	defer upstream.Close()
+
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.HttpServerOptions.EnableWebSockets = false
+	})
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.TargetURL = upstream.URL
+		spec.Proxy.ListenPath = "/"
+		spec.UseKeylessAccess = true
+	})
+
+	resp, err := http.Get(ts.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "data: first\n\ndata: last\n\n", string(body))
+}
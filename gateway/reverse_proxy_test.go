@@ -22,6 +22,7 @@ import (
 	texttemplate "text/template"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -89,33 +90,46 @@ func TestReverseProxyRetainHost(t *testing.T) {
 		name          string
 		inURL, inPath string
 		retainHost    bool
+		upstreamHost  string
 		wantURL       string
+		wantHost      string
 	}{
 		{
 			"no-retain-same-path",
 			"http://orig-host.com/origpath", "/origpath",
-			false, "http://target-host.com/targetpath/origpath",
+			false, "", "http://target-host.com/targetpath/origpath", "target-host.com",
 		},
 		{
 			"no-retain-minus-slash",
 			"http://orig-host.com/origpath", "origpath",
-			false, "http://target-host.com/targetpath/origpath",
+			false, "", "http://target-host.com/targetpath/origpath", "target-host.com",
 		},
 		{
 			"retain-same-path",
 			"http://orig-host.com/origpath", "/origpath",
-			true, "http://orig-host.com/origpath",
+			true, "", "http://orig-host.com/origpath", "orig-host.com",
 		},
 		{
 			"retain-minus-slash",
 			"http://orig-host.com/origpath", "origpath",
-			true, "http://orig-host.com/origpath",
+			true, "", "http://orig-host.com/origpath", "orig-host.com",
+		},
+		{
+			"no-retain-upstream-host",
+			"http://orig-host.com/origpath", "/origpath",
+			false, "virtual-host.com", "http://target-host.com/targetpath/origpath", "virtual-host.com",
+		},
+		{
+			"retain-upstream-host",
+			"http://orig-host.com/origpath", "/origpath",
+			true, "virtual-host.com", "http://orig-host.com/origpath", "virtual-host.com",
 		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			spec := &APISpec{APIDefinition: &apidef.APIDefinition{}, URLRewriteEnabled: true}
 			spec.URLRewriteEnabled = true
+			spec.Proxy.UpstreamHostHeader = tc.upstreamHost
 
 			req := TestReq(t, http.MethodGet, tc.inURL, nil)
 			req.URL.Path = tc.inPath
@@ -129,10 +143,113 @@ func TestReverseProxyRetainHost(t *testing.T) {
 			if got := req.URL.String(); got != tc.wantURL {
 				t.Fatalf("wanted url %q, got %q", tc.wantURL, got)
 			}
+			if req.Host != tc.wantHost {
+				t.Fatalf("wanted host %q, got %q", tc.wantHost, req.Host)
+			}
 		})
 	}
 }
 
+func TestUpstreamHostHeader(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	globalConf := ts.Gw.GetConfig()
+	globalConf.HttpServerOptions.EnableWebSockets = true
+	ts.Gw.SetConfig(globalConf)
+
+	upgrader := websocket.Upgrader{}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if websocket.IsWebSocketUpgrade(r) {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			_ = conn.WriteMessage(websocket.TextMessage, []byte("host is "+r.Host))
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, "host is %s", r.Host)
+	}))
+	defer upstream.Close()
+
+	ts.Gw.BuildAndLoadAPI(
+		func(spec *APISpec) {
+			spec.APIID = "host-header"
+			spec.Proxy.ListenPath = "/host-header/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.EnableContextVars = true
+			spec.Proxy.UpstreamHostHeader = "$tyk_context.headers_X_Upstream_Host"
+			UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+				v.UseExtendedPaths = true
+				v.ExtendedPaths.UpstreamHostHeader = []apidef.UpstreamHostMeta{
+					{Path: "/admin", Method: http.MethodGet, Host: "admin.example.com"},
+					{Path: "/disabled", Method: http.MethodGet, Host: "disabled.example.com", Disabled: true},
+				}
+			})
+		},
+		func(spec *APISpec) {
+			spec.APIID = "preserve-host"
+			spec.Proxy.ListenPath = "/preserve-host/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.Proxy.PreserveHostHeader = true
+			spec.Proxy.UpstreamHostHeader = "virtual.example.com"
+		},
+		func(spec *APISpec) {
+			spec.APIID = "internal-hop"
+			spec.Proxy.ListenPath = "/internal-hop/"
+			spec.Proxy.TargetURL = "tyk://internal-target"
+			spec.Proxy.UpstreamHostHeader = "hop.example.com"
+		},
+		func(spec *APISpec) {
+			spec.APIID = "internal-target"
+			spec.Proxy.ListenPath = "/internal-target/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.Proxy.PreserveHostHeader = true
+		},
+	)
+
+	upstreamHost := strings.TrimPrefix(upstream.URL, "http://")
+	tenantHeader := map[string]string{"X-Upstream-Host": "tenant.example.com"}
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Path: "/host-header/", Headers: tenantHeader, Code: http.StatusOK, BodyMatch: "host is tenant.example.com"},
+		{Path: "/host-header/admin", Headers: tenantHeader, Code: http.StatusOK, BodyMatch: "host is admin.example.com"},
+		{Path: "/host-header/disabled", Headers: tenantHeader, Code: http.StatusOK, BodyMatch: "host is tenant.example.com"},
+		{Method: http.MethodPost, Path: "/host-header/admin", Headers: tenantHeader, Code: http.StatusOK, BodyMatch: "host is tenant.example.com"},
+		{Path: "/preserve-host/", Code: http.StatusOK, BodyMatch: "host is virtual.example.com"},
+		{Path: "/internal-hop/", Code: http.StatusOK, BodyMatch: "host is hop.example.com"},
+		{Path: "/internal-target/", Code: http.StatusOK, BodyMatch: "host is " + strings.TrimPrefix(ts.URL, "http://")},
+	}...)
+
+	t.Run("target host without override", func(t *testing.T) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/"
+			spec.Proxy.TargetURL = upstream.URL
+		})
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/", Code: http.StatusOK, BodyMatch: "host is " + upstreamHost})
+	})
+
+	t.Run("websocket upgrade", func(t *testing.T) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.Proxy.UpstreamHostHeader = "ws.example.com"
+		})
+
+		conn, _, err := websocket.DefaultDialer.Dial(strings.Replace(ts.URL, "http://", "ws://", 1)+"/ws", nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, msg, err := conn.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, "host is ws.example.com", string(msg))
+	})
+}
+
 // TestReverseProxyMCPHostRootDiscovery verifies that for MCP APIs whose
 // upstream URL embeds a path, OAuth/MCP discovery probes (.well-known/...)
 // are routed to the upstream HOST ROOT instead of being prefixed with the
@@ -868,6 +985,24 @@ func testRequestIPHops(t testing.TB) {
 
 func TestRequestIPHops(t *testing.T) {
 	testRequestIPHops(t)
+
+	t.Run("trusted proxies", func(t *testing.T) {
+		global := request.Global
+		defer func() { request.Global = global }()
+
+		conf := config.Config{}
+		conf.HttpServerOptions.TrustedProxies = []string{"10.0.0.0/8"}
+		request.Global = func() config.Config { return conf }
+
+		req := &http.Request{Header: http.Header{}}
+		req.Header.Set(header.XForwardFor, "1.1.1.1")
+
+		req.RemoteAddr = "10.0.0.1:80"
+		assert.Equal(t, "1.1.1.1, 10.0.0.1", requestIPHops(req))
+
+		req.RemoteAddr = "192.168.0.1:80"
+		assert.Equal(t, "192.168.0.1", requestIPHops(req), "prior hops from an untrusted address are dropped")
+	})
 }
 
 func TestNopCloseRequestBody(t *testing.T) {
@@ -1612,6 +1747,19 @@ func TestGraphQL_InternalDataSource_memConnProviders(t *testing.T) {
 	require.Contains(t, memConnProviders.m, tykSubgraphReviews.Name)
 	memConnProviders.mtx.Unlock()
 
+	// A provider serving an in-flight request is not idle, even past its expiry.
+	memConnProviders.mtx.Lock()
+	atomic.AddInt64(&memConnProviders.m[tykSubgraphAccounts.Name].inFlight, 1)
+	memConnProviders.mtx.Unlock()
+
+	cleanIdleMemConnProvidersEagerly(time.Now().Add(2 * time.Minute))
+
+	memConnProviders.mtx.Lock()
+	require.Contains(t, memConnProviders.m, tykSubgraphAccounts.Name)
+	require.NotContains(t, memConnProviders.m, tykSubgraphReviews.Name)
+	atomic.AddInt64(&memConnProviders.m[tykSubgraphAccounts.Name].inFlight, -1)
+	memConnProviders.mtx.Unlock()
+
 	// Remove memconn.Provider structs from the cache, if they are idle for a while.
 	cleanIdleMemConnProvidersEagerly(time.Now().Add(2 * time.Minute))
 
@@ -1942,6 +2090,240 @@ func TestReverseProxyWebSocketCancelation(t *testing.T) {
 	}
 }
 
+func TestReverseProxyWebSocketLimits(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.HttpServerOptions.EnableWebSockets = true
+	})
+	defer ts.Close()
+
+	upstreamCloseCodes := make(chan int, 1)
+	upgrader := websocket.Upgrader{}
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			messageType, msg, err := conn.ReadMessage()
+			if err != nil {
+				var closeErr *websocket.CloseError
+				if errors.As(err, &closeErr) {
+					upstreamCloseCodes <- closeErr.Code
+				}
+				return
+			}
+			if err := conn.WriteMessage(messageType, msg); err != nil {
+				return
+			}
+		}
+	}))
+	defer upstream.Close()
+
+	const (
+		sizeAPIID     = "websocket-size"
+		idleAPIID     = "websocket-idle"
+		durationAPIID = "websocket-duration"
+	)
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = sizeAPIID
+		spec.Proxy.ListenPath = "/size/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.Proxy.WebSocket = apidef.ProxyWebSocket{Enabled: true, MaxMessageSize: 16}
+	}, func(spec *APISpec) {
+		spec.APIID = idleAPIID
+		spec.Proxy.ListenPath = "/idle/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.Proxy.WebSocket = apidef.ProxyWebSocket{Enabled: true, IdleTimeout: 1}
+	}, func(spec *APISpec) {
+		spec.APIID = durationAPIID
+		spec.Proxy.ListenPath = "/duration/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.Proxy.WebSocket = apidef.ProxyWebSocket{Enabled: true, MaxConnectionDuration: 1}
+	})
+
+	dial := func(t *testing.T, listenPath string) *websocket.Conn {
+		t.Helper()
+
+		conn, _, err := websocket.DefaultDialer.Dial(strings.Replace(ts.URL, "http", "ws", 1)+listenPath, nil)
+		require.NoError(t, err)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+		return conn
+	}
+
+	echo := func(t *testing.T, conn *websocket.Conn, msg string) {
+		t.Helper()
+
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(msg)))
+		_, got, err := conn.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, msg, string(got))
+	}
+
+	assertClosed := func(t *testing.T, conn *websocket.Conn, code int) {
+		t.Helper()
+
+		_, _, err := conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, code), "client got %v", err)
+
+		select {
+		case upstreamCode := <-upstreamCloseCodes:
+			assert.Equal(t, code, upstreamCode)
+		case <-time.After(5 * time.Second):
+			t.Error("upstream didn't get a close frame")
+		}
+	}
+
+	t.Run("oversized messages close the connection", func(t *testing.T) {
+		conn := dial(t, "/size/")
+		defer conn.Close()
+
+		echo(t, conn, "fits in 16 bytes")
+		assert.Equal(t, 1, ts.Gw.ConnectionWatcher.WebSocketCount(sizeAPIID))
+
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("doesn't fit in 16 bytes")))
+		assertClosed(t, conn, websocket.ClosePolicyViolation)
+
+		assert.Eventually(t, func() bool {
+			return ts.Gw.ConnectionWatcher.WebSocketCount(sizeAPIID) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("idle connections are closed", func(t *testing.T) {
+		conn := dial(t, "/idle/")
+		defer conn.Close()
+
+		echo(t, conn, "hello")
+		time.Sleep(500 * time.Millisecond)
+		// activity resets the idle timeout
+		echo(t, conn, "hello again")
+		assert.Equal(t, 1, ts.Gw.ConnectionWatcher.WebSocketCount(idleAPIID))
+
+		start := time.Now()
+		assertClosed(t, conn, websocket.CloseGoingAway)
+		assert.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond)
+
+		assert.Eventually(t, func() bool {
+			return ts.Gw.ConnectionWatcher.WebSocketCount(idleAPIID) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("connections are closed after the max duration", func(t *testing.T) {
+		conn := dial(t, "/duration/")
+		defer conn.Close()
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			echo(t, conn, "hello")
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		assertClosed(t, conn, websocket.CloseGoingAway)
+		assert.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond)
+	})
+}
+
+func TestReverseProxyWebSocketSubprotocols(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.HttpServerOptions.EnableWebSockets = true
+	})
+	defer ts.Close()
+
+	offered := make(chan string, 1)
+	upgrader := websocket.Upgrader{Subprotocols: []string{"chat.v2", "chat.v1"}, EnableCompression: true}
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offered <- r.Header.Get(header.SecWebSocketProtocol)
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		messageType, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = conn.WriteMessage(messageType, msg)
+	}))
+	defer upstream.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/passthrough/"
+		spec.Proxy.TargetURL = upstream.URL
+	}, func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/restricted/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.Proxy.WebSocket = apidef.ProxyWebSocket{Enabled: true, AllowedSubprotocols: []string{"chat.v1"}}
+	}, func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/stripped/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.Proxy.WebSocket = apidef.ProxyWebSocket{Enabled: true, StripSubprotocols: true}
+	})
+
+	dial := func(t *testing.T, listenPath string, subprotocols ...string) (*websocket.Conn, *http.Response, error) {
+		t.Helper()
+
+		dialer := websocket.Dialer{Subprotocols: subprotocols, EnableCompression: true}
+		return dialer.Dial(strings.Replace(ts.URL, "http", "ws", 1)+listenPath, nil)
+	}
+
+	echo := func(t *testing.T, conn *websocket.Conn) {
+		t.Helper()
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("hello hello hello")))
+		_, got, err := conn.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, "hello hello hello", string(got))
+	}
+
+	t.Run("selected subprotocol and compression are echoed", func(t *testing.T) {
+		conn, resp, err := dial(t, "/passthrough/", "chat.v3", "chat.v2")
+		require.NoError(t, err)
+		defer conn.Close()
+
+		assert.Equal(t, "chat.v3, chat.v2", <-offered)
+		assert.Equal(t, "chat.v2", conn.Subprotocol())
+		assert.Equal(t, []string{"chat.v2"}, resp.Header.Values(header.SecWebSocketProtocol))
+		assert.Contains(t, resp.Header.Get(header.SecWebSocketExtensions), "permessage-deflate")
+		echo(t, conn)
+	})
+
+	t.Run("subprotocols which aren't allowed are removed", func(t *testing.T) {
+		conn, _, err := dial(t, "/restricted/", "chat.v1", "chat.v2")
+		require.NoError(t, err)
+		defer conn.Close()
+
+		assert.Equal(t, "chat.v1", <-offered)
+		assert.Equal(t, "chat.v1", conn.Subprotocol())
+		echo(t, conn)
+	})
+
+	t.Run("handshake without allowed subprotocol is rejected", func(t *testing.T) {
+		_, resp, err := dial(t, "/restricted/", "chat.v2")
+		require.ErrorIs(t, err, websocket.ErrBadHandshake)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Empty(t, offered, "upstream shouldn't be called")
+	})
+
+	t.Run("subprotocols are stripped", func(t *testing.T) {
+		conn, resp, err := dial(t, "/stripped/", "chat.v1")
+		require.NoError(t, err)
+		defer conn.Close()
+
+		assert.Empty(t, <-offered)
+		assert.Empty(t, conn.Subprotocol())
+		assert.Empty(t, resp.Header.Values(header.SecWebSocketProtocol))
+		echo(t, conn)
+	})
+}
+
 func TestSSE(t *testing.T) {
 	sseServer := TestHelperSSEServer(t)
 	conf := func(globalConf *config.Config) {
@@ -0,0 +1,255 @@
+package gateway
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gobwas/ws"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+)
+
+// websocketCloseWriteTimeout bounds writing the close frame to the client.
+const websocketCloseWriteTimeout = time.Second
+
+var (
+	errWebSocketMessageTooBig         = errors.New("websocket message exceeds the maximum message size")
+	errWebSocketSubprotocolNotAllowed = errors.New("none of the requested websocket subprotocols is allowed")
+	errWebSocketSubprotocolNotOffered = errors.New("upstream selected a websocket subprotocol which wasn't offered")
+)
+
+// webSocketHandshakeHeaders are negotiated between the client and the upstream, so the
+// values sent by the upstream are never merged with those set by the gateway.
+var webSocketHandshakeHeaders = []string{
+	"Connection",
+	header.Upgrade,
+	header.SecWebSocketAccept,
+	header.SecWebSocketProtocol,
+	header.SecWebSocketExtensions,
+}
+
+// webSocketSubprotocols returns the subprotocols listed in the Sec-WebSocket-Protocol headers.
+func webSocketSubprotocols(h http.Header) []string {
+	var protocols []string
+	for _, value := range h.Values(header.SecWebSocketProtocol) {
+		for _, protocol := range strings.Split(value, ",") {
+			if protocol = strings.TrimSpace(protocol); protocol != "" {
+				protocols = append(protocols, protocol)
+			}
+		}
+	}
+	return protocols
+}
+
+// restrictWebSocketSubprotocols removes the subprotocols which aren't allowed from the handshake
+// sent to the upstream. It fails when the client only requested subprotocols which aren't allowed.
+func restrictWebSocketSubprotocols(conf apidef.ProxyWebSocket, h http.Header) error {
+	if !conf.Enabled {
+		return nil
+	}
+
+	if conf.StripSubprotocols {
+		h.Del(header.SecWebSocketProtocol)
+		return nil
+	}
+
+	requested := webSocketSubprotocols(h)
+	if len(conf.AllowedSubprotocols) == 0 || len(requested) == 0 {
+		return nil
+	}
+
+	allowed := slices.DeleteFunc(requested, func(protocol string) bool {
+		return !slices.Contains(conf.AllowedSubprotocols, protocol)
+	})
+	if len(allowed) == 0 {
+		return errWebSocketSubprotocolNotAllowed
+	}
+
+	h.Set(header.SecWebSocketProtocol, strings.Join(allowed, ", "))
+	return nil
+}
+
+// checkWebSocketSubprotocol checks the subprotocol selected by the upstream was offered to it,
+// removing it from the handshake when the subprotocols are stripped.
+func checkWebSocketSubprotocol(conf apidef.ProxyWebSocket, offered, selected http.Header) error {
+	protocol := selected.Get(header.SecWebSocketProtocol)
+	if !conf.Enabled || protocol == "" {
+		return nil
+	}
+
+	if conf.StripSubprotocols {
+		selected.Del(header.SecWebSocketProtocol)
+		return nil
+	}
+
+	if len(conf.AllowedSubprotocols) > 0 && !slices.Contains(webSocketSubprotocols(offered), protocol) {
+		return errWebSocketSubprotocolNotOffered
+	}
+
+	return nil
+}
+
+// websocketLimiter proxies the frames of a websocket connection while enforcing the
+// limits configured for the API. When a limit is hit, a close frame is sent to both
+// the client and the upstream before both connections are closed.
+type websocketLimiter struct {
+	conf apidef.ProxyWebSocket
+
+	user    net.Conn
+	backend io.ReadWriteCloser
+
+	// userMu and backendMu are held while a frame is written, so that close frames
+	// are never written in the middle of a proxied frame.
+	userMu    sync.Mutex
+	backendMu sync.Mutex
+
+	lastActivity atomic.Int64
+	closeOnce    sync.Once
+	done         chan struct{}
+}
+
+func newWebSocketLimiter(conf apidef.ProxyWebSocket, user net.Conn, backend io.ReadWriteCloser) *websocketLimiter {
+	l := &websocketLimiter{
+		conf:    conf,
+		user:    user,
+		backend: backend,
+		done:    make(chan struct{}),
+	}
+	l.touch()
+
+	return l
+}
+
+// proxy copies the frames in both directions until either side goes away or a limit is hit.
+func (l *websocketLimiter) proxy() error {
+	errc := make(chan error, 2)
+	go l.copyToBackend(errc)
+	go l.copyFromBackend(errc)
+	go l.watch()
+
+	err := <-errc
+	l.close(nil)
+
+	return err
+}
+
+func (l *websocketLimiter) copyToBackend(errc chan<- error) {
+	errc <- l.copyFrames(l.backend, &l.backendMu, l.user)
+}
+
+func (l *websocketLimiter) copyFromBackend(errc chan<- error) {
+	errc <- l.copyFrames(l.user, &l.userMu, l.backend)
+}
+
+func (l *websocketLimiter) copyFrames(dst io.Writer, dstMu *sync.Mutex, src io.Reader) error {
+	var messageSize int64
+
+	for {
+		h, err := ws.ReadHeader(src)
+		if err != nil {
+			return err
+		}
+		l.touch()
+
+		if !h.OpCode.IsControl() {
+			if h.OpCode != ws.OpContinuation {
+				messageSize = 0
+			}
+
+			messageSize += h.Length
+			if l.conf.MaxMessageSize > 0 && messageSize > l.conf.MaxMessageSize {
+				l.close(ws.NewCloseFrameBody(ws.StatusPolicyViolation, "message too big"))
+				return errWebSocketMessageTooBig
+			}
+		}
+
+		dstMu.Lock()
+		err = ws.WriteHeader(dst, h)
+		if err == nil {
+			_, err = io.CopyN(dst, src, h.Length)
+		}
+		dstMu.Unlock()
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// watch closes the connection once it has been idle or open for too long.
+func (l *websocketLimiter) watch() {
+	var (
+		idleTimer *time.Timer
+		idle      <-chan time.Time
+		expired   <-chan time.Time
+	)
+
+	idleTimeout := time.Duration(l.conf.IdleTimeout) * time.Second
+	if idleTimeout > 0 {
+		idleTimer = time.NewTimer(idleTimeout)
+		defer idleTimer.Stop()
+		idle = idleTimer.C
+	}
+
+	if maxDuration := time.Duration(l.conf.MaxConnectionDuration) * time.Second; maxDuration > 0 {
+		durationTimer := time.NewTimer(maxDuration)
+		defer durationTimer.Stop()
+		expired = durationTimer.C
+	}
+
+	for {
+		select {
+		case <-idle:
+			sinceLastActivity := time.Since(time.Unix(0, l.lastActivity.Load()))
+			if sinceLastActivity < idleTimeout {
+				idleTimer.Reset(idleTimeout - sinceLastActivity)
+				continue
+			}
+			l.close(ws.NewCloseFrameBody(ws.StatusGoingAway, "idle timeout"))
+			return
+		case <-expired:
+			l.close(ws.NewCloseFrameBody(ws.StatusGoingAway, "max connection duration reached"))
+			return
+		case <-l.done:
+			return
+		}
+	}
+}
+
+func (l *websocketLimiter) touch() {
+	l.lastActivity.Store(time.Now().UnixNano())
+}
+
+// close sends the close frame to the client and the upstream, then closes both connections.
+// A side in the middle of receiving a frame doesn't get the close frame. Without a close frame,
+// the connections are closed as is, which is what happens when either side went away.
+func (l *websocketLimiter) close(closeFrameBody []byte) {
+	l.closeOnce.Do(func() {
+		close(l.done)
+
+		if closeFrameBody != nil {
+			if l.userMu.TryLock() {
+				_ = l.user.SetWriteDeadline(time.Now().Add(websocketCloseWriteTimeout))
+				_ = ws.WriteFrame(l.user, ws.NewCloseFrame(closeFrameBody))
+				l.userMu.Unlock()
+			}
+
+			if l.backendMu.TryLock() {
+				// frames sent to the upstream by a client must be masked
+				_ = ws.WriteFrame(l.backend, ws.MaskFrame(ws.NewCloseFrame(closeFrameBody)))
+				l.backendMu.Unlock()
+			}
+		}
+
+		l.user.Close()
+		l.backend.Close()
+	})
+}
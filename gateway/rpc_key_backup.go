@@ -0,0 +1,264 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/TykTechnologies/tyk/internal/crypto"
+	"github.com/TykTechnologies/tyk/internal/model"
+	"github.com/TykTechnologies/tyk/rpc"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+const BackupKeyBase = "node-key-backup:"
+
+const (
+	// keyRevalidationQueue lists the keys served from the backup during an outage.
+	keyRevalidationQueue = "node-key-revalidation"
+	// quotaReplayQueue lists the quota updates buffered during an outage.
+	quotaReplayQueue = "node-quota-replay"
+	// quotaShadowKeyBase prefixes the local counters that stand in for MDCB during an outage.
+	quotaShadowKeyBase = "node-quota-shadow:"
+
+	defaultKeyBackupMaxAge int64 = 3600
+)
+
+const (
+	quotaOpDecrement = "decrement"
+	quotaOpIncrement = "increment"
+)
+
+// rpcKeyBackup is the local copy of a key fetched from MDCB.
+type rpcKeyBackup struct {
+	Value   string `json:"value"`
+	SavedAt int64  `json:"saved_at"`
+}
+
+// rpcQuotaOp is a quota update made while MDCB was unreachable.
+type rpcQuotaOp struct {
+	Op      string `json:"op"`
+	KeyName string `json:"key_name"`
+	Expire  int64  `json:"expire,omitempty"`
+}
+
+func (gw *Gateway) keyBackupEnabled() bool {
+	return gw.GetConfig().SlaveOptions.EnableKeyBackup
+}
+
+func (gw *Gateway) keyBackupMaxAge() int64 {
+	if maxAge := gw.GetConfig().SlaveOptions.KeyBackupMaxAge; maxAge > 0 {
+		return maxAge
+	}
+
+	return defaultKeyBackupMaxAge
+}
+
+func (gw *Gateway) keyBackupStore() (*storage.RedisCluster, error) {
+	store := &storage.RedisCluster{KeyPrefix: RPCKeyPrefix, ConnectionHandler: gw.StorageConnectionHandler}
+	if !store.Connect() {
+		return nil, errors.New("[RPC] --> Key backup failed: redis connection failed")
+	}
+
+	return store, nil
+}
+
+// saveRPCKeyBackup stores an encrypted copy of a key fetched from MDCB, expiring once it's too old to be served.
+func (gw *Gateway) saveRPCKeyBackup(keyName, value string) {
+	store, err := gw.keyBackupStore()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	data, err := json.Marshal(rpcKeyBackup{Value: value, SavedAt: time.Now().Unix()})
+	if err != nil {
+		log.WithError(err).Error("[RPC] --> Failed to encode key backup")
+		return
+	}
+
+	secret := crypto.GetPaddedString(gw.GetConfig().Secret)
+	cryptoText := crypto.Encrypt(secret, string(data))
+	if err := store.SetKey(BackupKeyBase+keyName, cryptoText, gw.keyBackupMaxAge()); err != nil {
+		log.WithError(err).Error("[RPC] --> Failed to store key backup")
+	}
+}
+
+// loadRPCKeyBackup returns the backup of a key if it isn't older than the configured max age.
+// Keys served from the backup are queued to be re-validated once MDCB is reachable again.
+func (gw *Gateway) loadRPCKeyBackup(keyName string) (string, error) {
+	store, err := gw.keyBackupStore()
+	if err != nil {
+		return "", err
+	}
+
+	cryptoText, err := store.GetKey(BackupKeyBase + keyName)
+	if err != nil {
+		return "", storage.ErrKeyNotFound
+	}
+
+	secret := crypto.GetPaddedString(gw.GetConfig().Secret)
+
+	var backup rpcKeyBackup
+	if err := json.Unmarshal([]byte(crypto.Decrypt(secret, cryptoText)), &backup); err != nil {
+		log.WithError(err).Error("[RPC] --> Failed to decode key backup")
+		return "", storage.ErrKeyNotFound
+	}
+
+	if time.Since(time.Unix(backup.SavedAt, 0)) > time.Duration(gw.keyBackupMaxAge())*time.Second {
+		log.Debug("[RPC] --> Key backup is too old, ignoring it")
+		return "", storage.ErrKeyNotFound
+	}
+
+	store.AppendToSet(keyRevalidationQueue, keyName)
+
+	return backup.Value, nil
+}
+
+// bufferRPCQuotaOp queues a quota update to be replayed against MDCB once it's reachable again.
+func (gw *Gateway) bufferRPCQuotaOp(op rpcQuotaOp) {
+	store, err := gw.keyBackupStore()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	data, err := json.Marshal(op)
+	if err != nil {
+		log.WithError(err).Error("[RPC] --> Failed to encode quota update")
+		return
+	}
+
+	store.AppendToSet(quotaReplayQueue, string(data))
+}
+
+// incrementRPCQuotaShadow increments the local counter standing in for a MDCB counter during an outage.
+func (gw *Gateway) incrementRPCQuotaShadow(keyName string, expire int64) int64 {
+	store, err := gw.keyBackupStore()
+	if err != nil {
+		log.Error(err)
+		return 0
+	}
+
+	return store.IncrememntWithExpire(RPCKeyPrefix+quotaShadowKeyBase+keyName, expire)
+}
+
+// recoverFromRPCOutage replays the quota updates buffered during an outage and re-validates the keys
+// that were served from the backup.
+func (gw *Gateway) recoverFromRPCOutage() {
+	if !gw.keyBackupEnabled() {
+		return
+	}
+
+	gw.replayRPCQuotaOps()
+	gw.revalidateRPCKeys()
+}
+
+// replayRPCQuotaOps sends the buffered quota updates to MDCB in the order they were made. If MDCB
+// becomes unreachable again, the updates that weren't sent are queued back.
+func (gw *Gateway) replayRPCQuotaOps() {
+	store, err := gw.keyBackupStore()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	ops := store.GetAndDeleteSet(quotaReplayQueue)
+	log.WithField("count", len(ops)).Debug("[RPC] --> Replaying buffered quota updates")
+
+	for i, raw := range ops {
+		data, _ := raw.(string)
+
+		var op rpcQuotaOp
+		if err := json.Unmarshal([]byte(data), &op); err != nil {
+			log.WithError(err).Error("[RPC] --> Failed to decode buffered quota update")
+			continue
+		}
+
+		var callErr error
+		switch op.Op {
+		case quotaOpDecrement:
+			_, callErr = rpc.FuncClientSingleton("Decrement", op.KeyName)
+		case quotaOpIncrement:
+			_, callErr = rpc.FuncClientSingleton("IncrememntWithExpire", model.InboundData{KeyName: op.KeyName, Expire: op.Expire})
+			store.DeleteKey(quotaShadowKeyBase + op.KeyName)
+		}
+
+		if callErr != nil {
+			log.WithError(callErr).Warning("[RPC] --> Failed to replay quota updates, keeping them for the next recovery")
+			for _, pending := range ops[i:] {
+				pendingData, _ := pending.(string)
+				store.AppendToSet(quotaReplayQueue, pendingData)
+			}
+			return
+		}
+	}
+}
+
+// revalidateRPCKeys fetches the keys served from the backup again. Keys MDCB doesn't know anymore are
+// removed from the backup and from the local stores, the others get their backup refreshed.
+func (gw *Gateway) revalidateRPCKeys() {
+	store, err := gw.keyBackupStore()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	queued := store.GetAndDeleteSet(keyRevalidationQueue)
+
+	seen := make(map[string]struct{}, len(queued))
+	keyNames := make([]string, 0, len(queued))
+	for _, raw := range queued {
+		keyName, _ := raw.(string)
+		if _, ok := seen[keyName]; ok || keyName == "" {
+			continue
+		}
+		seen[keyName] = struct{}{}
+		keyNames = append(keyNames, keyName)
+	}
+
+	log.WithField("count", len(keyNames)).Debug("[RPC] --> Re-validating keys served from backup")
+
+	for i, keyName := range keyNames {
+		value, err := rpc.FuncClientSingleton("GetKey", keyName)
+		if err != nil && (rpc.IsEmergencyMode() || errors.Is(err, rpc.ErrRPCIsDown) || (RPCStorageHandler{}).IsRetriableError(err)) {
+			log.WithError(err).Warning("[RPC] --> Failed to re-validate keys, keeping them for the next recovery")
+			for _, pending := range keyNames[i:] {
+				store.AppendToSet(keyRevalidationQueue, pending)
+			}
+			return
+		}
+
+		if err != nil {
+			log.Info("[RPC] --> Key served from backup is no longer valid, removing it: ", gw.obfuscateKey(keyName))
+			gw.purgeRPCKey(store, keyName)
+			continue
+		}
+
+		if valueStr, ok := value.(string); ok {
+			gw.saveRPCKeyBackup(keyName, valueStr)
+		}
+	}
+}
+
+// purgeRPCKey removes a key from the backup and the session copies made from it during the outage, the same
+// way keys removed from MDCB are dropped by the keyspace sync.
+func (gw *Gateway) purgeRPCKey(store *storage.RedisCluster, keyName string) {
+	store.DeleteKey(BackupKeyBase + keyName)
+	gw.RPCGlobalCache.Delete(keyName)
+
+	key, isSessionKey := strings.CutPrefix(keyName, "apikey-")
+	if !isSessionKey {
+		return
+	}
+
+	orgID := gw.GetConfig().SlaveOptions.RPCKey
+	if gw.GetConfig().HashKeys {
+		gw.handleDeleteHashedKey(key, orgID, "-1", false)
+	} else {
+		gw.handleDeleteKey(key, orgID, "-1", false)
+	}
+
+	gw.SessionCache.Delete(key)
+}
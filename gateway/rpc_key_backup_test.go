@@ -0,0 +1,208 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/gorpc"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/crypto"
+	"github.com/TykTechnologies/tyk/internal/model"
+	"github.com/TykTechnologies/tyk/rpc"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestRPCKeyBackup(t *testing.T) {
+	test.Flaky(t) // Test uses emergency mode (singleton).
+
+	rpc.UseSyncLoginRPC = true
+
+	var (
+		mu          sync.Mutex
+		known       = map[string]bool{"apikey-seen-key": true, "apikey-revoked-key": true}
+		revoked     = map[string]bool{}
+		decremented []string
+		incremented []model.InboundData
+	)
+
+	dispatcher := gorpc.NewDispatcher()
+	dispatcher.AddFunc("GetApiDefinitions", func(clientAddr string, dr *model.DefRequest) (string, error) {
+		return jsonMarshalString(BuildAPI(func(spec *APISpec) {
+			spec.UseKeylessAccess = false
+		})), nil
+	})
+	dispatcher.AddFunc("GetPolicies", func(clientAddr string, orgid string) (string, error) {
+		return `[]`, nil
+	})
+	dispatcher.AddFunc("Login", func(clientAddr, userKey string) bool {
+		return true
+	})
+	dispatcher.AddFunc("GetKey", func(clientAddr, key string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !known[key] || revoked[key] {
+			return "", errors.New("key not found")
+		}
+		return jsonMarshalString(CreateStandardSession()), nil
+	})
+	dispatcher.AddFunc("Decrement", func(clientAddr, keyName string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		decremented = append(decremented, keyName)
+		return nil
+	})
+	dispatcher.AddFunc("IncrememntWithExpire", func(clientAddr string, ibd *model.InboundData) (int64, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		incremented = append(incremented, *ibd)
+		return int64(len(incremented)), nil
+	})
+
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.SlaveOptions.EnableKeyBackup = true
+		globalConf.SlaveOptions.KeyBackupMaxAge = 60
+		globalConf.AuthOverride.ForceAuthProvider = true
+		globalConf.AuthOverride.AuthProvider.StorageEngine = RPCStorageEngine
+		globalConf.HashKeys = false
+	})
+	defer ts.Close()
+	defer rpc.ResetEmergencyMode()
+
+	rpcMock, connectionString := startRPCMock(dispatcher)
+	defer stopRPCMock(rpcMock)
+
+	conf := ts.Gw.GetConfig()
+	conf.SlaveOptions.ConnectionString = connectionString
+	conf.SlaveOptions.RPCKey = "default"
+	conf.SlaveOptions.APIKey = "test"
+	ts.Gw.SetConfig(conf)
+
+	rpcListener := &RPCStorageHandler{Gw: ts.Gw, SuppressRegister: true}
+	require.True(t, rpcListener.Connect())
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.UseKeylessAccess = false
+		spec.Proxy.ListenPath = "/sample"
+	})
+
+	store, err := ts.Gw.keyBackupStore()
+	require.NoError(t, err)
+	store.DeleteKey(keyRevalidationQueue)
+	store.DeleteKey(quotaReplayQueue)
+
+	const (
+		seenKey    = "seen-key"
+		revokedKey = "revoked-key"
+		staleKey   = "stale-key"
+	)
+
+	// forgetLocalCopies drops the copies the gateway made of the keys, leaving only their backup.
+	forgetLocalCopies := func(keys ...string) {
+		for _, key := range keys {
+			ts.Gw.handleDeleteKey(key, "default", "-1", false)
+		}
+		ts.Gw.SessionCache.Flush()
+		ts.Gw.RPCGlobalCache.Flush()
+	}
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Path: "/sample", Headers: map[string]string{"Authorization": seenKey}, Code: 200},
+		{Path: "/sample", Headers: map[string]string{"Authorization": revokedKey}, Code: 200},
+	}...)
+
+	// a backup older than the max age isn't served
+	secret := crypto.GetPaddedString(ts.Gw.GetConfig().Secret)
+	staleBackup, err := json.Marshal(rpcKeyBackup{
+		Value:   jsonMarshalString(CreateStandardSession()),
+		SavedAt: time.Now().Add(-2 * time.Minute).Unix(),
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.SetKey(BackupKeyBase+"apikey-"+staleKey, crypto.Encrypt(secret, string(staleBackup)), -1))
+
+	forgetLocalCopies(seenKey, revokedKey)
+	rpc.SetEmergencyMode(t, true)
+
+	t.Run("previously seen keys authenticate while RPC is down", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/sample", Headers: map[string]string{"Authorization": seenKey}, Code: 200},
+			{Path: "/sample", Headers: map[string]string{"Authorization": revokedKey}, Code: 200},
+			{Path: "/sample", Headers: map[string]string{"Authorization": "never-seen-key"}, Code: 403},
+			{Path: "/sample", Headers: map[string]string{"Authorization": staleKey}, Code: 403},
+		}...)
+
+		queued, err := store.GetListRange(keyRevalidationQueue, 0, -1)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"apikey-" + seenKey, "apikey-" + revokedKey}, queued)
+	})
+
+	t.Run("quota updates are buffered while RPC is down", func(t *testing.T) {
+		assert.EqualValues(t, 1, rpcListener.IncrememntWithExpire("quota-outage", 60))
+		assert.EqualValues(t, 2, rpcListener.IncrememntWithExpire("quota-outage", 60))
+		rpcListener.Decrement("quota-outage")
+
+		mu.Lock()
+		assert.Empty(t, incremented)
+		assert.Empty(t, decremented)
+		mu.Unlock()
+	})
+
+	t.Run("outage is reconciled once RPC is back", func(t *testing.T) {
+		mu.Lock()
+		revoked["apikey-"+revokedKey] = true
+		mu.Unlock()
+
+		rpc.ResetEmergencyMode()
+		ts.Gw.recoverFromRPCOutage()
+
+		mu.Lock()
+		assert.Equal(t, []model.InboundData{
+			{KeyName: "quota-outage", Expire: 60},
+			{KeyName: "quota-outage", Expire: 60},
+		}, incremented)
+		assert.Equal(t, []string{"quota-outage"}, decremented)
+		mu.Unlock()
+
+		_, err := store.GetKey(BackupKeyBase + "apikey-" + seenKey)
+		assert.NoError(t, err, "valid keys keep their backup")
+
+		_, err = store.GetKey(BackupKeyBase + "apikey-" + revokedKey)
+		assert.Error(t, err, "revoked keys lose their backup")
+
+		_, found := ts.Gw.GlobalSessionManager.SessionDetail("default", revokedKey, false)
+		assert.False(t, found, "revoked keys are removed from the local store")
+
+		_, found = ts.Gw.GlobalSessionManager.SessionDetail("default", seenKey, false)
+		assert.True(t, found, "valid keys are kept in the local store")
+
+		queued, err := store.GetListRange(keyRevalidationQueue, 0, -1)
+		require.NoError(t, err)
+		assert.Empty(t, queued)
+
+		replay, err := store.GetListRange(quotaReplayQueue, 0, -1)
+		require.NoError(t, err)
+		assert.Empty(t, replay)
+	})
+}
+
+func TestRPCKeyBackup_Disabled(t *testing.T) {
+	test.Flaky(t) // Test uses emergency mode (singleton).
+
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	rpc.SetEmergencyMode(t, true)
+	defer rpc.ResetEmergencyMode()
+
+	ts.Gw.saveRPCKeyBackup("apikey-disabled", jsonMarshalString(CreateStandardSession()))
+
+	rpcListener := RPCStorageHandler{KeyPrefix: "apikey-", Gw: ts.Gw}
+	_, err := rpcListener.GetRawKey("apikey-disabled")
+	assert.Error(t, err, "backups aren't served unless enabled")
+}
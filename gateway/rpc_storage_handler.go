@@ -14,6 +14,7 @@ import (
 	temporalmodel "github.com/TykTechnologies/storage/temporal/model"
 
 	"github.com/TykTechnologies/tyk/certs"
+	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/internal/cache"
 	"github.com/TykTechnologies/tyk/internal/model"
 	"github.com/TykTechnologies/tyk/pkg/errpack"
@@ -164,6 +165,7 @@ func (r *RPCStorageHandler) Connect() bool {
 		r.getGroupLoginCallback(r.Gw.GetConfig().SlaveOptions.SynchroniserEnabled),
 		func() {
 			r.Gw.reloadURLStructure(nil)
+			go r.Gw.recoverFromRPCOutage()
 		},
 		r.DoReload,
 	)
@@ -290,7 +292,14 @@ func (r *RPCStorageHandler) GetRawKey(keyName string) (string, error) {
 		}
 	}
 
+	keyBackupEnabled := r.Gw.keyBackupEnabled() && !strings.Contains(keyName, "cert-")
+
 	if rpc.IsEmergencyMode() {
+		if keyBackupEnabled {
+			if value, err := r.Gw.loadRPCKeyBackup(keyName); err == nil {
+				return value, nil
+			}
+		}
 		return "", storage.ErrMDCBConnectionLost
 	}
 
@@ -316,6 +325,10 @@ func (r *RPCStorageHandler) GetRawKey(keyName string) (string, error) {
 		cacheStore.Set(keyName, value, cache.DefaultExpiration)
 	}
 
+	if keyBackupEnabled {
+		r.Gw.saveRPCKeyBackup(keyName, value.(string))
+	}
+
 	return value.(string), nil
 }
 
@@ -416,6 +429,11 @@ func (r *RPCStorageHandler) SetRawKeyEx(keyName, session string, timeout int64)
 // Decrement will decrement a key in redis
 func (r *RPCStorageHandler) Decrement(keyName string) {
 	log.Warning("Decrement called")
+	if rpc.IsEmergencyMode() && r.Gw.keyBackupEnabled() {
+		r.Gw.bufferRPCQuotaOp(rpcQuotaOp{Op: quotaOpDecrement, KeyName: keyName})
+		return
+	}
+
 	_, err := rpc.FuncClientSingleton("Decrement", keyName)
 	if err != nil {
 		rpc.EmitErrorEventKv(
@@ -437,6 +455,10 @@ func (r *RPCStorageHandler) Decrement(keyName string) {
 
 // IncrementWithExpire will increment a key in redis
 func (r *RPCStorageHandler) IncrememntWithExpire(keyName string, expire int64) int64 {
+	if rpc.IsEmergencyMode() && r.Gw.keyBackupEnabled() {
+		r.Gw.bufferRPCQuotaOp(rpcQuotaOp{Op: quotaOpIncrement, KeyName: keyName, Expire: expire})
+		return r.Gw.incrementRPCQuotaShadow(keyName, expire)
+	}
 
 	ibd := model.InboundData{
 		KeyName: keyName,
@@ -1026,18 +1048,29 @@ func (r *RPCStorageHandler) CheckForKeyspaceChanges(orgId string) {
 
 func (gw *Gateway) getSessionAndCreate(keyName string, r *RPCStorageHandler, isHashed bool, orgId string) {
 
-	key := keyName
+	keys := []string{keyName}
 	// avoid double hashing
 	if !isHashed {
-		key = storage.HashKey(keyName, gw.GetConfig().HashKeys)
+		keys = []string{gw.keyHash(keyName)}
+
+		// during the dual_read migration the key may still be stored under the hash of its own algorithm
+		conf := gw.GetConfig()
+		if conf.HashKeys && conf.HashKeyMigration == config.HashKeyMigrationDualRead {
+			if fallback := storage.HashKey(keyName, true); fallback != keys[0] {
+				keys = append(keys, fallback)
+			}
+		}
 	}
 
-	sessionString, err := r.GetRawKey("apikey-" + key)
-	if err != nil {
-		log.Error("Key not found in master - skipping")
-	} else {
-		gw.handleAddKey(key, sessionString, orgId)
+	for _, key := range keys {
+		sessionString, err := r.GetRawKey("apikey-" + key)
+		if err == nil {
+			gw.handleAddKey(key, sessionString, orgId)
+			return
+		}
 	}
+
+	log.Error("Key not found in master - skipping")
 }
 
 func (gw *Gateway) ProcessSingleOauthClientEvent(apiId, oauthClientId, orgID, event string) {
@@ -1275,6 +1308,10 @@ func (r *RPCStorageHandler) ProcessKeySpaceChanges(keys []string, orgId string)
 		}
 	}
 
+	if len(CertificatesToRemove) > 0 || len(CertificatesToAdd) > 0 {
+		r.Gw.invalidateUpstreamCACertificates()
+	}
+
 	synchronizerEnabled := r.Gw.GetConfig().SlaveOptions.SynchroniserEnabled
 	for _, key := range keys {
 		// Skip keys that are user keys to be reset
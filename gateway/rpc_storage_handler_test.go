@@ -840,6 +840,59 @@ func TestDeleteUsingTokenID(t *testing.T) {
 	})
 }
 
+func TestGetSessionAndCreate_HashKeyMigration(t *testing.T) {
+	const orgID = "test_org"
+
+	rpc.UseSyncLoginRPC = true
+
+	session := CreateStandardSession()
+	session.OrgID = orgID
+	sessionJSON, err := json.Marshal(session)
+	require.NoError(t, err)
+
+	key := "mdcb-migrated-key"
+	legacyHash := storage.HashStr(key)
+	newHash := storage.HashStr(key, storage.HashSha256)
+
+	var requested []string
+	dispatcher := newDispatcher(
+		withFunc("GetKey", func(_, keyName string) (string, error) {
+			requested = append(requested, keyName)
+			if keyName == "apikey-"+legacyHash {
+				return string(sessionJSON), nil
+			}
+			return "", errors.New("not found")
+		}),
+	)
+
+	g := StartTest(func(globalConf *config.Config) {
+		globalConf.HashKeys = true
+		globalConf.HashKeyFunction = storage.HashSha256
+		globalConf.HashKeyMigration = config.HashKeyMigrationDualRead
+	})
+	defer g.Close()
+	defer rpc.ResetEmergencyMode()
+
+	rpcMock, connectionString := startRPCMock(dispatcher)
+	defer stopRPCMock(rpcMock)
+
+	conf := g.Gw.GetConfig()
+	conf.SlaveOptions.ConnectionString = connectionString
+	conf.SlaveOptions.RPCKey = orgID
+	conf.SlaveOptions.APIKey = "test"
+	g.Gw.SetConfig(conf)
+
+	rpcListener := &RPCStorageHandler{Gw: g.Gw, SuppressRegister: true}
+	require.True(t, rpcListener.Connect())
+
+	g.Gw.getSessionAndCreate(key, rpcListener, false, orgID)
+
+	assert.Equal(t, []string{"apikey-" + newHash, "apikey-" + legacyHash}, requested)
+
+	_, found := g.Gw.GlobalSessionManager.SessionDetail(orgID, key, false)
+	assert.True(t, found, "the key synced from the legacy hash is found with the fallback")
+}
+
 func TestProcessKeySpaceChanges_UserKeyReset(t *testing.T) {
 	oldKey := "old-api-key"
 	newKey := "new-api-key"
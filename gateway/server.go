@@ -32,7 +32,7 @@ import (
 	grayloghook "github.com/gemnasium/logrus-graylog-hook"
 	"github.com/gorilla/mux"
 	"github.com/lonelycode/osin"
-	"github.com/rs/cors"
+	"github.com/quic-go/quic-go/http3"
 	"github.com/samber/lo"
 	"github.com/sirupsen/logrus"
 	logrussyslog "github.com/sirupsen/logrus/hooks/syslog"
@@ -116,8 +116,12 @@ type Gateway struct {
 	config            atomic.Value
 	configMu          sync.Mutex
 	configViewerCache *configViewerCache
+	// unresolvedConfig holds the configuration before the KV store references were resolved.
+	unresolvedConfig atomic.Pointer[config.Config]
 
 	kvResolvers []func() error
+	// kvWatchMu serialises the refreshes of the secrets rotated in the KV stores.
+	kvWatchMu sync.Mutex
 
 	ctx context.Context
 
@@ -150,6 +154,8 @@ type Gateway struct {
 
 	SessionLimiter SessionLimiter
 	SessionMonitor Monitor
+	// monitorHandlers holds the webhook handlers of the policies overriding the monitor webhook
+	monitorHandlers sync.Map
 
 	// RPCGlobalCache stores keys
 	RPCGlobalCache cache.Repository
@@ -163,10 +169,16 @@ type Gateway struct {
 	UtilCache cache.Repository
 	// ServiceCache is the service discovery cache
 	ServiceCache cache.Repository
+	// kubernetesClients holds the clients of Kubernetes service discovery by kubeconfig path
+	kubernetesClients sync.Map
 
 	// Nonce to use when interacting with the dashboard service
 	ServiceNonce      string
 	ServiceNonceMutex sync.RWMutex
+	// dashboardNonceMu serialises the dashboard calls consuming ServiceNonce
+	dashboardNonceMu sync.Mutex
+	// dashboardNonceMismatches counts the dashboard calls rejected for a stale nonce
+	dashboardNonceMismatches atomic.Int64
 
 	apisMu          sync.RWMutex
 	apiSpecs        []*APISpec
@@ -183,6 +195,10 @@ type Gateway struct {
 	certUsageTracker *certUsageTracker // nil in non-RPC mode
 	pendingCerts     sync.Map          // certID -> struct{}, certs skipped due to tracker miss
 
+	// upstreamCAGeneration changes whenever a certificate is added or removed, so the proxies
+	// re-check the fingerprint of their upstream CA certificates.
+	upstreamCAGeneration atomic.Uint64
+
 	dnsCacheManager dnscache.IDnsCacheManager
 
 	consulKVStore kv.Store
@@ -191,6 +207,36 @@ type Gateway struct {
 	// signatureVerifier is used to verify signatures with config.PublicKeyPath.
 	signatureVerifier atomic.Pointer[goverify.Verifier]
 
+	// lastReloadStatus holds the diff computed by the last API specs sync.
+	lastReloadStatus atomic.Pointer[ReloadStatus]
+
+	// trafficMirror sends shadow copies of proxied requests to mirror upstreams.
+	trafficMirror *trafficMirror
+	// concurrencyLimiter counts the requests in flight of the APIs and keys with a concurrency limit.
+	concurrencyLimiter *rate.Concurrency
+
+	// controlAPIAudit records the calls made to the control API. Lazily initialised, nil when disabled.
+	controlAPIAuditOnce sync.Once
+	controlAPIAudit     *controlAPIAuditLog
+
+	// controlAPIAuthLimiter locks out the source IPs failing to authenticate to the control API. Lazily initialised, nil when disabled.
+	controlAPIAuthLimiterMu sync.Mutex
+	controlAPIAuthLimiter   *controlAPIAuthLimiter
+
+	// authFailureTracker locks out the clients failing to authenticate to the APIs. Lazily initialised.
+	authFailureTrackerOnce sync.Once
+	authFailureTracker     *authFailureTracker
+
+	// admissionController bounds the requests proxied at once. Lazily initialised, nil when disabled.
+	admissionControllerOnce sync.Once
+	admissionController     *admissionController
+
+	// geoIPDB is the MaxMind database of the APIs restricting access by country, reopened on reload.
+	geoIPDB geoIPDatabase
+
+	// checkupState is the state the configuration was checked against on startup.
+	checkupState checkup.State
+
 	RedisPurgeOnce sync.Once
 	RpcPurgeOnce   sync.Once
 
@@ -246,6 +292,10 @@ type Gateway struct {
 
 	// apiJWKCaches cache per api entity
 	apiJWKCaches sync.Map
+	// jwksLastKnownGood holds the last JWKS fetched successfully per URL
+	jwksLastKnownGood sync.Map
+	// versionPhases holds the last seen deprecation phase per API version, it's kept across reloads
+	versionPhases sync.Map
 
 	// idpRegistry is the in-memory client-IdP registry, a sibling dataset of the
 	// API definitions consulted only as a last fallback in the JWT path.
@@ -283,6 +333,8 @@ func NewGateway(config config.Config, ctx context.Context) *Gateway {
 		Timeout: 500 * time.Millisecond,
 	}
 	gw.ConnectionWatcher = httputil.NewConnectionWatcher()
+	gw.trafficMirror = newTrafficMirror(ctx)
+	gw.concurrencyLimiter = rate.NewConcurrency()
 
 	gw.cacheCreate()
 
@@ -358,6 +410,12 @@ func (gw *Gateway) cacheClose() {
 	gw.UtilCache.Close()
 	gw.RPCGlobalCache.Close()
 	gw.RPCCertCache.Close()
+
+	gw.controlAPIAuthLimiterMu.Lock()
+	if gw.controlAPIAuthLimiter != nil {
+		gw.controlAPIAuthLimiter.close()
+	}
+	gw.controlAPIAuthLimiterMu.Unlock()
 }
 
 func (gw *Gateway) saveApi(spec *APISpec) {
@@ -484,6 +542,26 @@ func (gw *Gateway) getAPIDefinition(apiID string) (*apidef.APIDefinition, error)
 	return apiSpec.APIDefinition, nil
 }
 
+// recordHashKeys records the `hash_keys` the gateway runs with in Redis and returns the one recorded by the
+// last gateway to run against it, nil if none was or Redis can't be read.
+func (gw *Gateway) recordHashKeys(hashKeys bool) *bool {
+	store := &storage.RedisCluster{KeyPrefix: "checkup-", ConnectionHandler: gw.StorageConnectionHandler}
+	store.Connect()
+
+	var last *bool
+	if value, err := store.GetKey("hash-keys"); err == nil {
+		if recorded, err := strconv.ParseBool(value); err == nil {
+			last = &recorded
+		}
+	}
+
+	if err := store.SetKey("hash-keys", strconv.FormatBool(hashKeys), 0); err != nil {
+		mainLog.WithError(err).Debug("Could not record hash_keys for the checkup")
+	}
+
+	return last
+}
+
 func (gw *Gateway) apisByIDLen() int {
 	gw.apisMu.RLock()
 	defer gw.apisMu.RUnlock()
@@ -495,7 +573,8 @@ func (gw *Gateway) setupGlobals() {
 	defaultTykErrors()
 
 	gwConfig := gw.GetConfig()
-	checkup.Run(&gwConfig)
+	gw.checkupState = checkup.State{LastHashKeys: gw.recordHashKeys(gwConfig.HashKeys)}
+	checkup.Run(&gwConfig, gw.checkupState)
 
 	gw.SetConfig(gwConfig)
 
@@ -508,6 +587,13 @@ func (gw *Gateway) setupGlobals() {
 
 	gw.dnsCacheManager = dnscache.NewDnsCacheManager(gwConfig.DnsCache.MultipleIPsHandleStrategy)
 
+	if resolver, err := dnscache.NewResolver(gwConfig.DnsResolver); err != nil {
+		mainLog.WithError(err).Error("Invalid DNS resolver configuration, upstream host names are resolved by the system resolver")
+	} else if resolver != nil {
+		mainLog.Infof("Resolving upstream host names with the %s resolver", resolver.Name())
+		gw.dnsCacheManager.SetResolver(resolver)
+	}
+
 	if gwConfig.DnsCache.Enabled {
 		gw.dnsCacheManager.InitDNSCaching(
 			time.Duration(gwConfig.DnsCache.TTL)*time.Second,
@@ -532,7 +618,7 @@ func (gw *Gateway) setupGlobals() {
 
 	gw.initHealthCheck(gw.ctx)
 
-	redisStore := &storage.RedisCluster{KeyPrefix: "apikey-", HashKeys: gwConfig.HashKeys, ConnectionHandler: gw.StorageConnectionHandler}
+	redisStore := gw.newKeyStore()
 	redisStore.Connect()
 
 	gw.GlobalSessionManager.Init(redisStore)
@@ -614,10 +700,7 @@ func (gw *Gateway) setupGlobals() {
 		gw.SetConfig(conf)
 	}
 
-	certificateSecret := gw.GetConfig().Secret
-	if gw.GetConfig().Security.PrivateCertificateEncodingSecret != "" {
-		certificateSecret = gw.GetConfig().Security.PrivateCertificateEncodingSecret
-	}
+	certSecret := certificateSecret(gw.GetConfig())
 
 	storeCert := &storage.RedisCluster{KeyPrefix: "cert-", HashKeys: false, ConnectionHandler: gw.StorageConnectionHandler}
 	storeCert.Connect()
@@ -637,7 +720,7 @@ func (gw *Gateway) setupGlobals() {
 
 	gw.CertificateManager = certs.NewCertificateManager(
 		storeCert,
-		certificateSecret,
+		certSecret,
 		log,
 		!conf.Cloud,
 		certs.WithRetryEnabled(retryEnabled),
@@ -658,7 +741,7 @@ func (gw *Gateway) setupGlobals() {
 		slaveCM := certs.NewSlaveCertManager(
 			storeCert,
 			rpcStore,
-			certificateSecret,
+			certSecret,
 			log,
 			!gw.GetConfig().Cloud,
 			certs.WithRetryEnabled(retryEnabled),
@@ -696,6 +779,7 @@ func (gw *Gateway) buildDashboardConnStr(resource string) string {
 }
 
 func (gw *Gateway) syncAPISpecs() (int, error) {
+	startedAt := time.Now()
 	loader := APIDefinitionLoader{Gw: gw}
 
 	var s []*APISpec
@@ -728,6 +812,13 @@ func (gw *Gateway) syncAPISpecs() (int, error) {
 
 	mainLog.Printf("Detected %v APIs", len(s))
 
+	apiLen, _ := gw.setAPISpecs(s, startedAt)
+	return apiLen, nil
+}
+
+// setAPISpecs validates the specs and registers the valid ones to be loaded by loadGlobalApps,
+// recording the reload status. It returns the number of valid specs and the skipped ones.
+func (gw *Gateway) setAPISpecs(s []*APISpec, startedAt time.Time) (int, []SkippedAPISpec) {
 	if gw.GetConfig().AuthOverride.ForceAuthProvider {
 		for i := range s {
 			s[i].AuthProvider = gw.GetConfig().AuthOverride.AuthProvider
@@ -739,22 +830,32 @@ func (gw *Gateway) syncAPISpecs() (int, error) {
 			s[i].SessionProvider = gw.GetConfig().AuthOverride.SessionProvider
 		}
 	}
-	var filter []*APISpec
+	var (
+		filter  []*APISpec
+		skipped []SkippedAPISpec
+	)
 	for _, v := range s {
 		if err := v.Validate(gw.GetConfig().OAS); err != nil {
 			mainLog.WithError(err).WithField("spec", v.Name).Error("Skipping loading spec because it failed validation")
+			skipped = append(skipped, SkippedAPISpec{APIID: v.APIID, Name: v.Name, Error: err.Error(), err: err})
 			continue
 		}
 		filter = append(filter, v)
 	}
 
 	gw.apisMu.Lock()
+	reloadStatus := newReloadStatus(gw.apisByID, filter, skipped)
 	gw.apiSpecs = filter
 	apiLen := len(gw.apiSpecs)
 	tlsConfigCache.Flush()
 	gw.apisMu.Unlock()
 
-	return apiLen, nil
+	reloadStatus.StartedAt = startedAt
+	reloadStatus.FinishedAt = time.Now()
+	reloadStatus.log()
+	gw.lastReloadStatus.Store(reloadStatus)
+
+	return apiLen, skipped
 }
 
 func (gw *Gateway) syncPolicies() (count int, err error) {
@@ -873,9 +974,13 @@ func (gw *Gateway) loadControlAPIEndpoints(muxer *mux.Router) {
 	muxer.HandleFunc("/"+gw.GetConfig().ReadinessCheckEndpointName, gw.readinessHandler)
 
 	r := mux.NewRouter()
-	muxer.PathPrefix("/tyk/").Handler(http.StripPrefix("/tyk",
+	var controlAPIHandler http.Handler = http.StripPrefix("/tyk",
 		stripSlashes(gw.checkIsAPIOwner(gw.controlAPICheckClientCertificate("/gateway/client", InstrumentationMW(r)))),
-	))
+	)
+	if audit := gw.getControlAPIAuditLog(); audit != nil {
+		controlAPIHandler = audit.Wrap(controlAPIHandler)
+	}
+	muxer.PathPrefix("/tyk/").Handler(controlAPIHandler)
 
 	if hostname != "" {
 		muxer = muxer.Host(hostname).Subrouter()
@@ -895,7 +1000,10 @@ func (gw *Gateway) loadControlAPIEndpoints(muxer *mux.Router) {
 
 	// set up main API handlers
 	r.HandleFunc("/reload/group", gw.groupResetHandler).Methods("GET")
+	r.HandleFunc("/reload/status", gw.reloadStatusHandler).Methods("GET")
 	r.HandleFunc("/reload", gw.resetHandler(nil)).Methods("GET")
+	r.HandleFunc("/apis/validate", gw.validateAPIHandler).Methods(http.MethodPost)
+	r.HandleFunc("/apis/oas/validate", gw.validateAPIOASHandler).Methods(http.MethodPost)
 
 	if !gw.isRPCMode() {
 		versionsHandler := NewVersionHandler(gw.getAPIDefinition)
@@ -914,6 +1022,7 @@ func (gw *Gateway) loadControlAPIEndpoints(muxer *mux.Router) {
 		r.HandleFunc("/apis/{apiID}/versions", versionsHandler.ServeHTTP).Methods(http.MethodGet)
 		r.HandleFunc("/apis/oas/export", gw.apiOASExportHandler).Methods("GET")
 		r.HandleFunc("/apis/oas/import", gw.blockInDashboardMode(gw.validateOAS(gw.makeImportedOASTykAPI(gw.apiOASPostHandler)))).Methods(http.MethodPost)
+		r.HandleFunc("/apis/import/postman", gw.blockInDashboardMode(gw.apiImportPostmanHandler)).Methods(http.MethodPost)
 		r.HandleFunc("/apis/oas/{apiID}", gw.apiOASGetHandler).Methods(http.MethodGet)
 		r.HandleFunc("/apis/oas/{apiID}", gw.blockInDashboardMode(gw.validateOAS(gw.apiOASPutHandler))).Methods(http.MethodPut)
 		r.HandleFunc("/apis/oas/{apiID}", gw.blockInDashboardMode(gw.validateOAS(gw.apiOASPatchHandler))).Methods(http.MethodPatch)
@@ -943,10 +1052,17 @@ func (gw *Gateway) loadControlAPIEndpoints(muxer *mux.Router) {
 	}
 
 	r.HandleFunc("/debug", gw.traceHandler).Methods("POST")
+	r.HandleFunc("/debug/config", gw.debugConfigHandler).Methods(http.MethodGet)
+	r.HandleFunc("/debug/caches", gw.debugCachesHandler).Methods(http.MethodGet)
+	r.HandleFunc("/debug/checkup", gw.debugCheckupHandler).Methods(http.MethodGet)
+	r.HandleFunc("/debug/rate-limits/{keyHash}", gw.rateLimitDebugHandler).Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
 	r.HandleFunc("/plugins/test", gw.pluginTestHandler).Methods("POST")
 	r.HandleFunc("/cache/jwks/{apiID}", gw.invalidateJWKSCacheForAPIID).Methods("DELETE")
 	r.HandleFunc("/cache/jwks", gw.invalidateJWKSCacheForAllAPIs).Methods("DELETE")
 	r.HandleFunc("/cache/{apiID}", gw.invalidateCacheHandler).Methods("DELETE")
+	r.HandleFunc("/apis/{apiID}/health", gw.apiHealthHandler).Methods(http.MethodGet)
+	r.HandleFunc("/apis/{apiID}/openapi", gw.apiOpenAPIHandler).Methods(http.MethodGet)
+	r.HandleFunc("/apis/{apiID}/graphql/persisted-queries", gw.graphqlPersistedQueriesUploadHandler).Methods(http.MethodPost)
 	r.HandleFunc("/keys", gw.keyHandler).Methods("POST", "PUT", "GET", "DELETE")
 	r.HandleFunc("/keys/preview", gw.previewKeyHandler).Methods("POST")
 	r.HandleFunc("/keys/{keyName:[^/]*}", gw.keyHandler).Methods("POST", "PUT", "GET", "DELETE")
@@ -967,16 +1083,47 @@ func (gw *Gateway) loadControlAPIEndpoints(muxer *mux.Router) {
 // client and the owner and is set in the tyk.conf file. This should
 // never be made public!
 func (gw *Gateway) checkIsAPIOwner(next http.Handler) http.Handler {
-	secret := gw.GetConfig().Secret
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the secret is read on every request as it can be rotated in the KV store
+		secret := gw.GetConfig().Secret
+		// and the limiter as a reload can change its configuration
+		limiter := gw.getControlAPIAuthLimiter()
+
+		var ip string
+		if limiter != nil {
+			ip = controlAPIAuthSourceIP(r)
+			if limiter.rejectLockedOut(w, ip) {
+				return
+			}
+		}
+
 		tykAuthKey := r.Header.Get(header.XTykAuthorization)
 		if tykAuthKey != secret {
+			if orgID, ok := gw.orgScopedAdminOrg(r); ok {
+				if limiter != nil {
+					limiter.reset(ip)
+				}
+
+				ctxSetControlAPIOrgScope(r, orgID)
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Error
 			mainLog.Warning("Attempted administrative access with invalid or missing key!")
 
+			if limiter != nil {
+				gw.controlAPIAuthFailed(limiter, r, ip)
+			}
+
 			doJSONWrite(w, http.StatusForbidden, apiError("Attempted administrative access with invalid or missing key!"))
 			return
 		}
+
+		if limiter != nil {
+			limiter.reset(ip)
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
@@ -993,6 +1140,9 @@ func (gw *Gateway) addOAuthHandlers(spec *APISpec, muxer *mux.Router) *OAuthMana
 	clientAccessPath := "/oauth/token{_:/?}"
 	revokeToken := "/oauth/revoke"
 	revokeAllTokens := "/oauth/revoke_all"
+	introspectToken := "/oauth/introspect"
+	registerClient := "/oauth/register"
+	clientConfiguration := "/oauth/register/{clientID}"
 
 	serverConfig := osin.NewServerConfig()
 
@@ -1034,6 +1184,13 @@ func (gw *Gateway) addOAuthHandlers(spec *APISpec, muxer *mux.Router) *OAuthMana
 	muxer.HandleFunc(clientAccessPath, wrapWithCORS(addSecureAndCacheHeaders(allowMethods(oauthHandlers.HandleAccessRequest, "GET", "POST"))))
 	muxer.HandleFunc(revokeToken, wrapWithCORS(oauthHandlers.HandleRevokeToken))
 	muxer.HandleFunc(revokeAllTokens, wrapWithCORS(oauthHandlers.HandleRevokeAllTokens))
+	muxer.HandleFunc(introspectToken, wrapWithCORS(addSecureAndCacheHeaders(allowMethods(oauthHandlers.HandleIntrospectToken, "POST"))))
+
+	if spec.Oauth2Meta.ClientRegistration.Enabled {
+		muxer.HandleFunc(registerClient, addSecureAndCacheHeaders(allowMethods(oauthHandlers.HandleRegisterClient, "POST")))
+		muxer.HandleFunc(clientConfiguration, addSecureAndCacheHeaders(allowMethods(oauthHandlers.HandleClientConfiguration, "GET", "PUT", "DELETE")))
+	}
+
 	return &oauthManager
 }
 
@@ -1235,6 +1392,10 @@ func (gw *Gateway) createResponseMiddlewareChain(
 	gw.responseMWAppendEnabled(&responseMWChain,
 		decorate(&ResponseErrorOverrideMiddleware{BaseTykResponseHandler: baseHandler}))
 
+	// Store the idempotent response as it's returned to the client
+	gw.responseMWAppendEnabled(&responseMWChain,
+		decorate(&IdempotencyResponseMiddleware{BaseTykResponseHandler: baseHandler, store: newIdempotencyStore(gw)}))
+
 	keyPrefix := "cache-" + spec.APIID
 	cacheStore := &storage.RedisCluster{KeyPrefix: keyPrefix, IsCache: true, ConnectionHandler: gw.StorageConnectionHandler}
 	cacheStore.Connect()
@@ -1245,7 +1406,13 @@ func (gw *Gateway) createResponseMiddlewareChain(
 		log.WithError(err).Debug("Failed to init processor")
 	}
 
-	return append(responseMWChain, processor)
+	responseMWChain = append(responseMWChain, processor)
+
+	// Compress the response once the other handlers, cache writer included, are done with the plain body
+	gw.responseMWAppendEnabled(&responseMWChain,
+		decorate(&ResponseCompressionMiddleware{BaseTykResponseHandler: baseHandler}))
+
+	return responseMWChain
 }
 
 func (gw *Gateway) isRPCMode() bool {
@@ -1323,6 +1490,17 @@ func (gw *Gateway) DoReloadWithError() error {
 		}
 	}
 
+	gw.loadSyncedAPIs()
+
+	gw.MetricInstruments.RecordReload(gw.ctx, time.Since(start))
+
+	gw.performedSuccessfulReload = true
+	mainLog.Info("API reload complete")
+	return nil
+}
+
+// loadSyncedAPIs loads the specs registered by setAPISpecs, replacing the routes of the loaded APIs.
+func (gw *Gateway) loadSyncedAPIs() {
 	gw.loadGlobalApps()
 
 	// Refresh the client-IdP registry AFTER loadGlobalApps populates apisByID.
@@ -1336,12 +1514,6 @@ func (gw *Gateway) DoReloadWithError() error {
 			mainLog.WithError(err).Warn("IdP registry refresh failed during reload — keeping previous snapshot")
 		}
 	}
-
-	gw.MetricInstruments.RecordReload(gw.ctx, time.Since(start))
-
-	gw.performedSuccessfulReload = true
-	mainLog.Info("API reload complete")
-	return nil
 }
 
 // DoReload preserves the func() signature required by RPCStorageHandler,
@@ -1398,28 +1570,19 @@ func (gw *Gateway) DoReloadWithRetry(ctx context.Context) {
 }
 
 func createCORSWrapper(spec *APISpec) func(handler http.HandlerFunc) http.HandlerFunc {
-	var corsHandler func(http.Handler) http.Handler
+	var corsHandlers *corsHandlers
 
 	if spec.CORS.Enable {
-		corsHandler = cors.New(cors.Options{
-			AllowedOrigins:     spec.CORS.AllowedOrigins,
-			AllowedMethods:     spec.CORS.AllowedMethods,
-			AllowedHeaders:     spec.CORS.AllowedHeaders,
-			ExposedHeaders:     spec.CORS.ExposedHeaders,
-			AllowCredentials:   spec.CORS.AllowCredentials,
-			MaxAge:             spec.CORS.MaxAge,
-			OptionsPassthrough: spec.CORS.OptionsPassthrough,
-			Debug:              spec.CORS.Debug,
-		}).Handler
+		corsHandlers = newCORSHandlers(spec)
 	}
 
 	return func(handler http.HandlerFunc) http.HandlerFunc {
-		if corsHandler == nil {
+		if corsHandlers == nil {
 			return handler
 		}
 
 		return func(w http.ResponseWriter, r *http.Request) {
-			corsHandler(handler).ServeHTTP(w, r)
+			corsHandlers.forRequest(r).Handler(handler).ServeHTTP(w, r)
 		}
 	}
 }
@@ -1942,6 +2105,10 @@ func (gw *Gateway) afterConfSetup() error {
 	regexp.Configure(cacheOpts)
 	httputil.ConfigurePathRegexpCache(maxEntries, conf.DisableRegexpCacheBound, mainLog.Warnf)
 
+	if err := conf.HttpServerOptions.PortTLS.Validate(); err != nil {
+		return fmt.Errorf("invalid http_server_options.port_tls: %w", err)
+	}
+
 	if conf.HealthCheckEndpointName == "" {
 		conf.HealthCheckEndpointName = "hello"
 	}
@@ -1950,6 +2117,9 @@ func (gw *Gateway) afterConfSetup() error {
 		conf.ReadinessCheckEndpointName = "ready"
 	}
 
+	unresolved := conf
+	gw.unresolvedConfig.Store(&unresolved)
+
 	var err error
 
 	conf.Secret, err = gw.kvStore(conf.Secret)
@@ -2216,6 +2386,10 @@ func Start() {
 
 	gwConfig := config.Config{}
 	if err := config.Load(confPaths, &gwConfig); err != nil {
+		if *cli.CheckConfig {
+			mainLog.Fatalf("Error loading config: %v", err)
+		}
+
 		mainLog.Errorf("Error loading config, using defaults: %v", err)
 
 		defaultConfig, err := config.NewDefaultWithEnv()
@@ -2225,6 +2399,16 @@ func Start() {
 		gwConfig = *defaultConfig
 	}
 
+	// Lint the configuration without starting the listeners
+	if *cli.CheckConfig {
+		report := checkup.Lint(&gwConfig, checkup.State{})
+		report.Log()
+		if report.HasErrors() {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	gw := NewGateway(gwConfig, ctx)
 	gwConfig = gw.GetConfig()
 
@@ -2431,6 +2615,8 @@ func (gw *Gateway) start() {
 	// interval counts from the start of one reload to the next.
 	go gw.reloadLoop(time.Tick(reloadInterval))
 	go gw.reloadQueueLoop()
+
+	gw.startKVWatcher()
 }
 
 func dashboardServiceInit(gw *Gateway) {
@@ -2463,12 +2649,21 @@ func handleDashboardRegistration(gw *Gateway) {
 	}()
 }
 
+// rateLimiterCredentials returns the credentials of the rate limiter storage in the current configuration,
+// which changes when the Redis password is rotated in the KV store.
+func (gw *Gateway) rateLimiterCredentials() (username, password string) {
+	conf := gw.GetConfig()
+	storage := conf.GetRateLimiterStorage()
+	return storage.Username, storage.Password
+}
+
 func (gw *Gateway) startDRL() {
 	gwConfig := gw.GetConfig()
 
 	gw.drlOnce.Do(func() {
 		drlManager := &drl.DRL{}
-		gw.SessionLimiter = NewSessionLimiter(gw.ctx, &gwConfig, drlManager, &gwConfig.ExternalServices)
+		gw.SessionLimiter = NewSessionLimiter(gw.ctx, &gwConfig, drlManager, &gwConfig.ExternalServices, gw.rateLimiterCredentials)
+		gw.SessionLimiter.decisionLog = gw.newRateLimitDecisionLog()
 
 		gw.DRLManager = drlManager
 
@@ -2492,7 +2687,7 @@ func (gw *Gateway) startDRL() {
 func (gw *Gateway) isDRLDisabled() bool {
 	gwConfig := gw.GetConfig()
 
-	return gwConfig.ManagementNode || gwConfig.EnableSentinelRateLimiter || gwConfig.EnableRedisRollingLimiter || gwConfig.EnableFixedWindowRateLimiter
+	return gwConfig.ManagementNode || gwConfig.EnableSentinelRateLimiter || gwConfig.EnableRedisRollingLimiter || gwConfig.EnableFixedWindowRateLimiter || gwConfig.EnableTokenBucketRateLimiter
 }
 
 func (gw *Gateway) setupPortsWhitelist() {
@@ -2611,6 +2806,33 @@ func (gw *Gateway) shutdownHTTPServer(ctx context.Context, server *http.Server,
 	}()
 }
 
+// shutdownHTTP3Server gracefully shuts down an HTTP/3 server and closes its UDP socket
+func (gw *Gateway) shutdownHTTP3Server(ctx context.Context, server *http3.Server, conn net.PacketConn, port int, wg *sync.WaitGroup, errChan chan<- error) {
+	if server == nil {
+		return
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		mainLog.Infof("Shutting down HTTP/3 server on %s", server.Addr)
+
+		err := server.Shutdown(ctx)
+		if conn != nil {
+			_ = conn.Close()
+		}
+
+		if err != nil {
+			mainLog.Errorf("Error shutting down HTTP/3 server on port %d: %v", port, err)
+			select {
+			case errChan <- err:
+			default:
+				// Channel closed, ignore
+			}
+		}
+	}()
+}
+
 // shutdownTCPProxy gracefully shuts down a TCP proxy
 func (gw *Gateway) shutdownTCPProxy(ctx context.Context, listener net.Listener, port int, protocol string, proxy *tcp.Proxy, wg *sync.WaitGroup, errChan chan<- error) {
 	if proxy == nil || listener == nil {
@@ -2659,6 +2881,9 @@ func (gw *Gateway) gracefulShutdown(ctx context.Context) error {
 		if p.httpServer != nil {
 			gw.shutdownHTTPServer(ctx, p.httpServer, p.port, &wg, errChan)
 		}
+		if p.http3Server != nil {
+			gw.shutdownHTTP3Server(ctx, p.http3Server, p.http3Conn, p.port, &wg, errChan)
+		}
 		if p.tcpProxy != nil && p.listener != nil {
 			gw.shutdownTCPProxy(ctx, p.listener, p.port, p.protocol, p.tcpProxy, &wg, errChan)
 		}
@@ -2741,14 +2966,17 @@ func (gw *Gateway) gracefulShutdown(ctx context.Context) error {
 }
 
 func buildJWKSCache(cfg config.Config) *cache.MemRepository {
-	var jwkCacheExpiration int64 = externalOAuthJWKCacheExpiration
+	return cache.New(
+		jwksCacheExpiration(cfg),
+		externalOAuthJWKCacheCleanupInterval,
+	)
+}
 
+// jwksCacheExpiration returns the default timeout, in seconds, of the JWKS caches.
+func jwksCacheExpiration(cfg config.Config) int64 {
 	if cfg.JWKS.Cache.Timeout > 0 {
-		jwkCacheExpiration = cfg.JWKS.Cache.Timeout
+		return cfg.JWKS.Cache.Timeout
 	}
 
-	return cache.New(
-		jwkCacheExpiration,
-		externalOAuthJWKCacheCleanupInterval,
-	)
+	return externalOAuthJWKCacheExpiration
 }
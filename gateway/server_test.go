@@ -640,6 +640,24 @@ func TestGateway_apisByIDLen(t *testing.T) {
 	}
 }
 
+func TestGateway_recordHashKeys(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	// the gateway records its hash_keys on startup
+	last := ts.Gw.recordHashKeys(true)
+	require.NotNil(t, last)
+	assert.Equal(t, ts.Gw.GetConfig().HashKeys, *last)
+
+	last = ts.Gw.recordHashKeys(false)
+	require.NotNil(t, last)
+	assert.True(t, *last)
+
+	last = ts.Gw.recordHashKeys(false)
+	require.NotNil(t, last)
+	assert.False(t, *last)
+}
+
 func TestGateway_policiesByIDLen(t *testing.T) {
 	tcs := []struct {
 		name     string
@@ -298,6 +298,10 @@ func (s *ServiceDiscovery) ProcessRawData(rawData string) (*apidef.HostList, err
 }
 
 func (s *ServiceDiscovery) Target(serviceURL string) (*apidef.HostList, error) {
+	if s.spec != nil && s.spec.Kubernetes.Enabled {
+		return s.kubernetesTargets()
+	}
+
 	// Get the data
 	rawData, err := s.getServiceData(serviceURL)
 	if err != nil {
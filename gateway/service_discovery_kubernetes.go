@@ -0,0 +1,167 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+const (
+	kubernetesDiscoveryTimeout = 10 * time.Second
+
+	kubernetesDefaultNamespace = "default"
+)
+
+// kubernetesNamespaceFile holds the namespace of the pod the gateway runs in.
+var kubernetesNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+var errKubernetesNoService = errors.New("kubernetes service discovery requires a service name")
+
+// kubernetesTargets lists the ready addresses of the endpoint slices of the configured Kubernetes service.
+// Terminating and not ready endpoints are left out, so pods going away during a rollout stop receiving
+// requests once the cached target list expires.
+func (s *ServiceDiscovery) kubernetesTargets() (*apidef.HostList, error) {
+	conf := s.spec.Kubernetes
+	if conf.Service == "" {
+		return nil, errKubernetesNoService
+	}
+
+	client, err := s.gw.kubernetesClient(conf.KubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := conf.Namespace
+	if namespace == "" {
+		namespace = kubernetesPodNamespace()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), kubernetesDiscoveryTimeout)
+	defer cancel()
+
+	slices, err := client.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + conf.Service,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]struct{}{}
+	hosts := []string{}
+	for _, slice := range slices.Items {
+		port, ok := kubernetesSlicePort(slice.Ports, conf.PortName)
+		if !ok {
+			continue
+		}
+
+		for _, endpoint := range slice.Endpoints {
+			if !kubernetesEndpointReady(endpoint.Conditions) {
+				continue
+			}
+
+			for _, address := range endpoint.Addresses {
+				host := net.JoinHostPort(address, strconv.Itoa(int(port))) + s.targetPath
+				if _, ok := seen[host]; ok {
+					continue
+				}
+
+				seen[host] = struct{}{}
+				hosts = append(hosts, host)
+			}
+		}
+	}
+
+	// the order of the slices and their endpoints isn't stable, keep round robin fair across refreshes
+	sort.Strings(hosts)
+
+	if !s.isTargetList && len(hosts) > 1 {
+		hosts = hosts[:1]
+	}
+
+	log.Debugf("[PROXY][SD] Kubernetes service %s/%s has %d ready addresses", namespace, conf.Service, len(hosts))
+
+	hostList := apidef.NewHostList()
+	hostList.Set(hosts)
+	return hostList, nil
+}
+
+// kubernetesSlicePort returns the port with the given name of an endpoint slice, or its first port if the name is empty.
+func kubernetesSlicePort(ports []discoveryv1.EndpointPort, name string) (int32, bool) {
+	for _, port := range ports {
+		if port.Port == nil {
+			continue
+		}
+
+		if name == "" || (port.Name != nil && *port.Name == name) {
+			return *port.Port, true
+		}
+	}
+
+	return 0, false
+}
+
+// kubernetesEndpointReady reports whether an endpoint is ready and not terminating.
+// A nil ready condition has to be interpreted as ready.
+func kubernetesEndpointReady(conditions discoveryv1.EndpointConditions) bool {
+	if conditions.Ready != nil && !*conditions.Ready {
+		return false
+	}
+
+	return conditions.Terminating == nil || !*conditions.Terminating
+}
+
+// kubernetesPodNamespace returns the namespace the gateway pod runs in.
+func kubernetesPodNamespace() string {
+	namespace, err := os.ReadFile(kubernetesNamespaceFile)
+	if err != nil || strings.TrimSpace(string(namespace)) == "" {
+		return kubernetesDefaultNamespace
+	}
+
+	return strings.TrimSpace(string(namespace))
+}
+
+// kubernetesClient returns the Kubernetes client for a kubeconfig path, using in-cluster credentials when it's empty.
+// Clients are created once and reused across service discovery refreshes.
+func (gw *Gateway) kubernetesClient(kubeconfigPath string) (kubernetes.Interface, error) {
+	if client, ok := gw.kubernetesClients.Load(kubeconfigPath); ok {
+		return client.(kubernetes.Interface), nil
+	}
+
+	var (
+		restConfig *rest.Config
+		err        error
+	)
+
+	if kubeconfigPath == "" {
+		restConfig, err = rest.InClusterConfig()
+	} else {
+		restConfig, err = clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	restConfig.Timeout = kubernetesDiscoveryTimeout
+
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, _ := gw.kubernetesClients.LoadOrStore(kubeconfigPath, client)
+	return actual.(kubernetes.Interface), nil
+}
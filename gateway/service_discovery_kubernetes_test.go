@@ -0,0 +1,210 @@
+package gateway
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+type testKubernetesEndpoint struct {
+	address     string
+	ready       bool
+	terminating bool
+}
+
+func testEndpointSlice(name, service string, ports map[string]int32, endpoints ...testKubernetesEndpoint) *discoveryv1.EndpointSlice {
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "payments",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: service},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+	}
+
+	for portName, port := range ports {
+		portName, port := portName, port
+		slice.Ports = append(slice.Ports, discoveryv1.EndpointPort{Name: &portName, Port: &port})
+	}
+
+	for _, endpoint := range endpoints {
+		ready, terminating := endpoint.ready, endpoint.terminating
+		slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{
+			Addresses:  []string{endpoint.address},
+			Conditions: discoveryv1.EndpointConditions{Ready: &ready, Terminating: &terminating},
+		})
+	}
+
+	return slice
+}
+
+func TestServiceDiscovery_Kubernetes(t *testing.T) {
+	client := fake.NewClientset(
+		testEndpointSlice("ledger-a", "ledger", map[string]int32{"http": 8080},
+			testKubernetesEndpoint{address: "10.0.0.2", ready: true},
+			testKubernetesEndpoint{address: "10.0.0.1", ready: true},
+			testKubernetesEndpoint{address: "10.0.0.3", ready: false},
+			testKubernetesEndpoint{address: "10.0.0.4", ready: true, terminating: true},
+		),
+		testEndpointSlice("ledger-b", "ledger", map[string]int32{"http": 8080},
+			testKubernetesEndpoint{address: "10.0.0.1", ready: true},
+			testKubernetesEndpoint{address: "10.0.0.5", ready: true},
+		),
+		testEndpointSlice("ledger-c", "ledger", map[string]int32{"metrics": 9090},
+			testKubernetesEndpoint{address: "10.0.0.6", ready: true},
+		),
+		testEndpointSlice("audit", "audit", map[string]int32{"http": 8080},
+			testKubernetesEndpoint{address: "10.0.1.1", ready: true},
+		),
+	)
+
+	gw := &Gateway{}
+	gw.kubernetesClients.Store("", client)
+
+	target := func(conf apidef.ServiceDiscoveryConfiguration) []string {
+		t.Helper()
+
+		sd := ServiceDiscovery{}
+		sd.Init(&conf, gw)
+
+		hostList, err := sd.Target(conf.QueryEndpoint)
+		require.NoError(t, err)
+		return hostList.All()
+	}
+
+	conf := apidef.ServiceDiscoveryConfiguration{
+		UseDiscoveryService: true,
+		UseTargetList:       true,
+		TargetPath:          "/v1",
+		Kubernetes: apidef.KubernetesServiceDiscovery{
+			Enabled:   true,
+			Namespace: "payments",
+			Service:   "ledger",
+			PortName:  "http",
+		},
+	}
+
+	t.Run("ready addresses of the named port", func(t *testing.T) {
+		assert.Equal(t, []string{"10.0.0.1:8080/v1", "10.0.0.2:8080/v1", "10.0.0.5:8080/v1"}, target(conf))
+	})
+
+	t.Run("first port without a port name", func(t *testing.T) {
+		conf := conf
+		conf.Kubernetes.PortName = ""
+		conf.TargetPath = ""
+		assert.Equal(t, []string{"10.0.0.1:8080", "10.0.0.2:8080", "10.0.0.5:8080", "10.0.0.6:9090"}, target(conf))
+	})
+
+	t.Run("single target", func(t *testing.T) {
+		conf := conf
+		conf.UseTargetList = false
+		assert.Equal(t, []string{"10.0.0.1:8080/v1"}, target(conf))
+	})
+
+	t.Run("endpoint churn", func(t *testing.T) {
+		_, err := client.DiscoveryV1().EndpointSlices("payments").Update(context.Background(),
+			testEndpointSlice("ledger-a", "ledger", map[string]int32{"http": 8080},
+				testKubernetesEndpoint{address: "10.0.0.2", ready: false, terminating: true},
+				testKubernetesEndpoint{address: "10.0.0.7", ready: true},
+			), metav1.UpdateOptions{})
+		require.NoError(t, err)
+
+		err = client.DiscoveryV1().EndpointSlices("payments").Delete(context.Background(), "ledger-b", metav1.DeleteOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"10.0.0.7:8080/v1"}, target(conf))
+	})
+
+	t.Run("missing service name", func(t *testing.T) {
+		conf := conf
+		conf.Kubernetes.Service = ""
+
+		sd := ServiceDiscovery{}
+		sd.Init(&conf, gw)
+
+		_, err := sd.Target("")
+		assert.ErrorIs(t, err, errKubernetesNoService)
+	})
+}
+
+func TestServiceDiscovery_KubernetesProxy(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	newUpstream := func(name string) (*httptest.Server, testKubernetesEndpoint, int32) {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(name))
+		}))
+
+		u, err := url.Parse(upstream.URL)
+		require.NoError(t, err)
+
+		host, port, err := net.SplitHostPort(u.Host)
+		require.NoError(t, err)
+
+		portNumber, err := strconv.Atoi(port)
+		require.NoError(t, err)
+
+		return upstream, testKubernetesEndpoint{address: host, ready: true}, int32(portNumber)
+	}
+
+	old, oldEndpoint, oldPort := newUpstream("old")
+	defer old.Close()
+
+	updated, updatedEndpoint, updatedPort := newUpstream("new")
+	defer updated.Close()
+
+	client := fake.NewClientset(testEndpointSlice("ledger-old", "ledger", map[string]int32{"http": oldPort}, oldEndpoint))
+	ts.Gw.kubernetesClients.Store("", client)
+
+	api := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.EnableLoadBalancing = true
+		spec.Proxy.ServiceDiscovery = apidef.ServiceDiscoveryConfiguration{
+			UseDiscoveryService: true,
+			UseTargetList:       true,
+			CacheTimeout:        60,
+			Kubernetes: apidef.KubernetesServiceDiscovery{
+				Enabled:   true,
+				Namespace: "payments",
+				Service:   "ledger",
+				PortName:  "http",
+			},
+		}
+	})[0]
+
+	_, _ = ts.Run(t, test.TestCase{Path: "/", Code: http.StatusOK, BodyMatch: "old"})
+
+	// a rollout terminates the old pod and starts a new one
+	oldEndpoint.terminating = true
+	_, err := client.DiscoveryV1().EndpointSlices("payments").Update(context.Background(),
+		testEndpointSlice("ledger-old", "ledger", map[string]int32{"http": oldPort}, oldEndpoint), metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	_, err = client.DiscoveryV1().EndpointSlices("payments").Create(context.Background(),
+		testEndpointSlice("ledger-new", "ledger", map[string]int32{"http": updatedPort}, updatedEndpoint), metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	// the target list is served from the cache until it expires
+	_, _ = ts.Run(t, test.TestCase{Path: "/", Code: http.StatusOK, BodyMatch: "old"})
+
+	ts.Gw.ServiceCache.Delete(api.APIID)
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Path: "/", Code: http.StatusOK, BodyMatch: "new"},
+		{Path: "/", Code: http.StatusOK, BodyMatch: "new"},
+	}...)
+}
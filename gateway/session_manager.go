@@ -12,6 +12,7 @@ import (
 
 	"github.com/TykTechnologies/drl"
 
+	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/internal/httputil"
 	"github.com/TykTechnologies/tyk/internal/memorycache"
@@ -45,6 +46,9 @@ const (
 
 	// SentinelRateLimitKeyPostfix is appended to the rate limiting key to combine into a sentinel key.
 	SentinelRateLimitKeyPostfix = ".BLOCKED"
+
+	// TokenBucketRateLimitKeyPostfix is appended to the rate limiting key to combine into a token bucket key.
+	TokenBucketRateLimitKeyPostfix = ".BUCKET"
 )
 
 // SessionLimiter is the rate limiter for the API, use ForwardMessage() to
@@ -56,6 +60,7 @@ type SessionLimiter struct {
 	bucketStore    model.BucketStorage
 	limiterStorage redis.UniversalClient
 	smoothing      *rate.Smoothing
+	decisionLog    *rateLimitDecisionLog
 }
 
 // NewSessionLimiter initializes the session limiter.
@@ -64,12 +69,15 @@ type SessionLimiter struct {
 // It supports two storage types: `redis` and `local`. If redis storage is
 // configured, then redis will be used. If local storage is configured, then
 // in-memory counters will be used. If no storage is configured, it falls
-// back onto the default gateway storage configuration.
+// back onto the default gateway storage configuration. When credentials is
+// set, the redis connections are authenticated with the credentials it returns,
+// so a rotated password is used by the connections opened after the rotation.
 func NewSessionLimiter(
 	ctx context.Context,
 	conf *config.Config,
 	drlManager *drl.DRL,
 	externalServicesConfig *config.ExternalServiceConfig,
+	credentials func() (username, password string),
 ) SessionLimiter {
 
 	sessionLimiter := SessionLimiter{
@@ -85,7 +93,7 @@ func NewSessionLimiter(
 
 	switch storageConf.Type {
 	case "redis":
-		sessionLimiter.limiterStorage = rate.NewStorage(storageConf, externalServicesConfig)
+		sessionLimiter.limiterStorage = rate.NewStorageWithCredentials(storageConf, externalServicesConfig, credentials)
 	}
 
 	sessionLimiter.smoothing = rate.NewSmoothing(sessionLimiter.limiterStorage)
@@ -97,15 +105,15 @@ func (l *SessionLimiter) Context() context.Context {
 	return l.ctx
 }
 
-func (l *SessionLimiter) limitRedis(r *http.Request, session *user.SessionState, rateLimiterKey string, apiLimit *user.APILimit, dryRun bool) (rate.Stats, bool) {
+func (l *SessionLimiter) limitRedis(r *http.Request, session *user.SessionState, rateLimiterKey string, apiLimit *user.APILimit, cost int64, dryRun bool) (rate.Stats, bool) {
 	ctx := l.Context()
 	rateLimiterSentinelKey := rateLimiterKey + SentinelRateLimitKeyPostfix
 
-	var per, cost float64
+	var per, maxRate float64
 
 	if apiLimit != nil { // respect limit on API level
 		per = apiLimit.Per
-		cost = apiLimit.Rate
+		maxRate = apiLimit.Rate
 	}
 
 	pipeline := l.config.EnableNonTransactionalRateLimiter
@@ -147,7 +155,7 @@ func (l *SessionLimiter) limitRedis(r *http.Request, session *user.SessionState,
 	}
 
 	ratelimit := rate.NewSlidingLogRedis(l.limiterStorage, pipeline, smoothingFn)
-	stats, shouldBlock, err := ratelimit.Do(ctx, time.Now(), rateLimiterKey, int64(cost), int64(per))
+	stats, shouldBlock, err := ratelimit.DoCost(ctx, time.Now(), rateLimiterKey, int64(maxRate), int64(per), cost)
 
 	if shouldBlock && !dryRun && (l.config.EnableSentinelRateLimiter || l.config.DRLEnableSentinelRateLimiter) {
 		l.limiterStorage.SetNX(ctx, rateLimiterSentinelKey, "1", time.Second*time.Duration(per))
@@ -165,11 +173,12 @@ func (l *SessionLimiter) limitSentinel(
 	session *user.SessionState,
 	rateLimiterKey string,
 	apiLimit *user.APILimit,
+	cost int64,
 	dryRun bool,
 ) (time.Duration, bool) {
 
 	defer func() {
-		go l.limitRedis(r, session, rateLimiterKey, apiLimit, dryRun)
+		go l.limitRedis(r, session, rateLimiterKey, apiLimit, cost, dryRun)
 	}()
 
 	expires, err := l.limiterStorage.TTL(l.Context(), rateLimiterKey+SentinelRateLimitKeyPostfix).Result()
@@ -185,19 +194,28 @@ func (l *SessionLimiter) limitSentinel(
 	return expires, expires > 0
 }
 
-func (l *SessionLimiter) limitDRL(bucketKey string, apiLimit *user.APILimit, dryRun bool) (model.BucketState, bool) {
+// limitTokenBucket returns a checker for the token bucket shared by all gateways in redis.
+func (l *SessionLimiter) limitTokenBucket(rateLimiterKey string, apiLimit *user.APILimit, cost int64, dryRun bool) rate.Checker {
+	return rate.AnonChecker(func() (rate.Stats, bool, error) {
+		bucket := rate.NewTokenBucketRedis(l.limiterStorage)
+		return bucket.DoCost(l.Context(), time.Now(), rateLimiterKey+TokenBucketRateLimitKeyPostfix, apiLimit.Rate, apiLimit.Per, apiLimit.Burst, cost, dryRun)
+	})
+}
+
+// limitDRL takes cost requests from the bucket, each worth the current token value.
+func (l *SessionLimiter) limitDRL(bucketKey string, apiLimit *user.APILimit, cost int64, dryRun bool) (model.BucketState, bool) {
 	currRate := apiLimit.Rate
 	per := apiLimit.Per
 
 	tokenValue := uint(l.drlManager.CurrentTokenValue())
 
 	// DRL will always overflow with more servers on low rates
-	cost := uint(currRate * float64(l.drlManager.RequestTokenValue))
-	if cost < tokenValue {
-		cost = tokenValue
+	capacity := uint(currRate * float64(l.drlManager.RequestTokenValue))
+	if capacity < tokenValue {
+		capacity = tokenValue
 	}
 
-	userBucket, err := l.bucketStore.Create(bucketKey, cost, time.Duration(per)*time.Second)
+	userBucket, err := l.bucketStore.Create(bucketKey, capacity, time.Duration(per)*time.Second)
 	if err != nil {
 		log.Error("Failed to create bucket!")
 		return model.BucketState{}, true
@@ -205,10 +223,10 @@ func (l *SessionLimiter) limitDRL(bucketKey string, apiLimit *user.APILimit, dry
 
 	if dryRun {
 		state := userBucket.State()
-		return state, state.Remaining < tokenValue && time.Now().Before(state.Reset)
+		return state, state.Remaining < tokenValue*uint(cost) && time.Now().Before(state.Reset)
 	}
 
-	state, errF := userBucket.Add(tokenValue)
+	state, errF := userBucket.Add(tokenValue * uint(cost))
 	return state, errF != nil
 }
 
@@ -250,6 +268,7 @@ func (l *SessionLimiter) RateLimitInfo(r *http.Request, api *APISpec, endpoints
 					KeySuffix: storage.HashStr(fmt.Sprintf("%s:%s", endpointMethod.Name, endpoint.Path)),
 					Rate:      endpointMethod.Limit.Rate,
 					Per:       endpointMethod.Limit.Per,
+					Burst:     endpointMethod.Limit.Burst,
 				}, true
 			}
 		}
@@ -288,10 +307,13 @@ func (l *SessionLimiter) ForwardMessage(
 	if doEndpointRL {
 		apiLimit.Rate = endpointRLInfo.Rate
 		apiLimit.Per = endpointRLInfo.Per
+		apiLimit.Burst = endpointRLInfo.Burst
 		endpointRLKeySuffix = endpointRLInfo.KeySuffix
 	}
 
-	if rl := l.newRateLimitChecker(r, session, rateLimitKey, quotaKey, enableRL, dryRun, apiLimit, endpointRLKeySuffix, allowanceScope); rl != nil {
+	rateCost := l.requestRateCost(r, api)
+
+	if rl, limiterName := l.newRateLimitChecker(r, session, rateLimitKey, quotaKey, enableRL, dryRun, apiLimit, endpointRLKeySuffix, allowanceScope, int64(rateCost.Cost)); rl != nil {
 		stats, shouldBlock, err := rl.Check()
 
 		if err != nil {
@@ -309,6 +331,10 @@ func (l *SessionLimiter) ForwardMessage(
 
 		l.extendContextWithLimits(r, stats, api.EnableContextVars)
 
+		if session.RateLimitDebugUntil != 0 {
+			l.logRateLimitDecision(session, api, limiterName, apiLimit, allowanceScope, endpointRLKeySuffix != "", stats, shouldBlock, dryRun)
+		}
+
 		if shouldBlock {
 			return sessionFailRateLimit
 		}
@@ -317,10 +343,15 @@ func (l *SessionLimiter) ForwardMessage(
 	// If rate is -1 or 0, it means unlimited and no need for rate limiting.
 	if enableQ {
 		if l.config.LegacyEnableAllowanceCountdown {
-			session.Allowance = session.Allowance - 1
+			session.Allowance = session.Allowance - float64(rateCost.Cost)
 		}
 
-		if l.RedisQuotaExceeded(r, session, quotaKey, allowanceScope, apiLimit, l.config.HashKeys, api.EnableContextVars) {
+		quotaCost := int64(1)
+		if rateCost.ApplyToQuota {
+			quotaCost = int64(rateCost.Cost)
+		}
+
+		if l.redisQuotaExceeded(r, session, quotaKey, allowanceScope, apiLimit, l.config.HashKeys, api.EnableContextVars, quotaCost) {
 			return sessionFailQuota
 		}
 	}
@@ -338,10 +369,11 @@ func (l *SessionLimiter) newRateLimitChecker(
 	apiLimit *user.APILimit,
 	endpointRLKeySuffix string,
 	allowanceScope string,
-) rate.Checker {
+	cost int64,
+) (rate.Checker, string) {
 
 	if !enableRL || apiLimit.Rate <= 0 {
-		return nil
+		return nil, ""
 	}
 
 	// If quotaKey is not set then the default ratelimit keys should be used.
@@ -352,6 +384,9 @@ func (l *SessionLimiter) newRateLimitChecker(
 
 	// This limiter key should be used consistently here out.
 	limiterKey := rate.LimiterKey(session, allowanceScope, rateLimitKey, useCustomKey)
+	if clusterLimiterKeys(l.config) {
+		limiterKey = rate.ClusterLimiterKey(session, allowanceScope, rateLimitKey, useCustomKey)
+	}
 
 	if endpointRLKeySuffix != "" {
 		log.Debugf("[RATELIMIT] applying endpoint rate limit key suffix: %s: %s", limiterKey, endpointRLKeySuffix)
@@ -362,10 +397,13 @@ func (l *SessionLimiter) newRateLimitChecker(
 	limiterFn := rate.Limiter(l.config, l.limiterStorage)
 
 	switch {
+	case l.limiterStorage != nil && (apiLimit.Burst > 0 || l.config.EnableTokenBucketRateLimiter):
+		return l.limitTokenBucket(limiterKey, apiLimit, cost, dryRun), rate.LimitTokenBucket
+
 	case limiterFn != nil:
 
 		return rate.AnonChecker(func() (rate.Stats, bool, error) {
-			waitTime, err := limiterFn(r.Context(), limiterKey, apiLimit.Rate, apiLimit.Per)
+			waitTime, err := limiterFn(r.Context(), limiterKey, apiLimit.Rate, apiLimit.Per, cost)
 
 			switch {
 			case errors.Is(err, rate.ErrLimitExhausted):
@@ -386,26 +424,26 @@ func (l *SessionLimiter) newRateLimitChecker(
 					Remaining: -1,
 				}, waitTime > 0, nil
 			}
-		})
+		}), rate.LimiterKind(l.config)
 
 	case l.config.EnableSentinelRateLimiter:
-		ttl, shouldBlock := l.limitSentinel(r, session, limiterKey, apiLimit, dryRun)
-		return newAnonTtlChecker(apiLimit.Rate, ttl, shouldBlock)
+		ttl, shouldBlock := l.limitSentinel(r, session, limiterKey, apiLimit, cost, dryRun)
+		return newAnonTtlChecker(apiLimit.Rate, ttl, shouldBlock), rate.LimitSentinel
 	case l.config.EnableRedisRollingLimiter:
-		return newStaticTtlChecker(l.limitRedis(r, session, limiterKey, apiLimit, dryRun))
+		return newStaticTtlChecker(l.limitRedis(r, session, limiterKey, apiLimit, cost, dryRun)), rate.LimitRedisRolling
 	default:
 		var n float64
 		if l.drlManager.Servers != nil {
 			n = float64(l.drlManager.Servers.Count())
 		}
-		cost := apiLimit.Rate / apiLimit.Per
+		requestRate := apiLimit.Rate / apiLimit.Per
 		c := l.config.DRLThreshold
 		if c == 0 {
 			// defaults to 5
 			c = 5
 		}
 
-		if n <= 1 || n*c < cost {
+		if n <= 1 || n*c < requestRate {
 			// If we have 1 server, there is no need to strain redis at all the leaky
 			// bucket algorithm will suffice.
 
@@ -414,13 +452,13 @@ func (l *SessionLimiter) newRateLimitChecker(
 				bucketKey = limiterKey
 			}
 
-			state, shouldBlock := l.limitDRL(bucketKey, apiLimit, dryRun)
+			state, shouldBlock := l.limitDRL(bucketKey, apiLimit, cost, dryRun)
 			tokenValue := uint(l.drlManager.CurrentTokenValue())
 
-			return newBucketStateChecker(apiLimit.Rate, state, shouldBlock, tokenValue)
+			return newBucketStateChecker(apiLimit.Rate, state, shouldBlock, tokenValue), rate.LimitDRL
 		} else {
 			// sliding window
-			return newStaticTtlChecker(l.limitRedis(r, session, limiterKey, apiLimit, dryRun))
+			return newStaticTtlChecker(l.limitRedis(r, session, limiterKey, apiLimit, cost, dryRun)), rate.LimitRedisRolling
 		}
 	}
 }
@@ -434,6 +472,19 @@ func (l *SessionLimiter) RedisQuotaExceeded(
 	hashKeys bool,
 	enableCtxVars bool,
 ) bool {
+	return l.redisQuotaExceeded(r, session, quotaKey, scope, limit, hashKeys, enableCtxVars, 1)
+}
+
+// redisQuotaExceeded counts the request as cost requests of the quota.
+func (l *SessionLimiter) redisQuotaExceeded(
+	r *http.Request,
+	session *user.SessionState,
+	quotaKey, scope string,
+	limit *user.APILimit,
+	hashKeys bool,
+	enableCtxVars bool,
+	cost int64,
+) bool {
 
 	logger := log.WithFields(logrus.Fields{
 		"quotaMax":         limit.QuotaMax,
@@ -447,11 +498,6 @@ func (l *SessionLimiter) RedisQuotaExceeded(
 	// don't use the requests cancellation context
 	ctx := context.Background()
 
-	quotaScope := ""
-	if scope != "" {
-		quotaScope = scope + "-"
-	}
-
 	key := session.KeyID
 	if hashKeys {
 		key = storage.HashStr(session.KeyID)
@@ -463,7 +509,7 @@ func (l *SessionLimiter) RedisQuotaExceeded(
 	now := time.Now()
 
 	// rawKey is the redis key for quota
-	rawKey := QuotaKeyPrefix + quotaScope + key
+	rawKey := quotaKeyName(l.config, scope, key)
 
 	var quotaRenewalRate time.Duration
 	if limit.QuotaRenewalRate > 0 {
@@ -471,14 +517,30 @@ func (l *SessionLimiter) RedisQuotaExceeded(
 	}
 
 	conn := l.limiterStorage
+	quota := rate.NewQuotaRedis(conn)
 
 	var expired, exists bool
 	var expiredAt time.Time
 
-	dur, err := conn.PTTL(ctx, rawKey).Result()
-	if err != nil && !errors.Is(err, redis.Nil) {
-		logger.WithError(err).Error("error getting key TTL, blocking")
-		return true
+	// the TTL may have been read together with the session
+	dur, prefetched := ctxTakePrefetchedQuotaTTL(r, rawKey)
+	if !prefetched {
+		var err error
+		dur, err = conn.PTTL(ctx, rawKey).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			logger.WithError(err).Error("error getting key TTL, blocking")
+			return true
+		}
+	}
+
+	// a missing counter may still be stored under the key name used before the keys were hash-tagged
+	if dur == -2 && clusterLimiterKeys(l.config) {
+		var err error
+		dur, err = quota.Migrate(ctx, rawKey, legacyQuotaKeyName(scope, key))
+		if err != nil {
+			logger.WithError(err).Error("error migrating quota key, blocking")
+			return true
+		}
 	}
 
 	// The command returns -2 if the key does not exist.
@@ -495,28 +557,20 @@ func (l *SessionLimiter) RedisQuotaExceeded(
 	})
 
 	increment := func() bool {
-		var res *redis.IntCmd
-		// todo: change to lua script, it does not works like author suppose
-		_, err := conn.Pipelined(ctx, func(pipe redis.Pipeliner) error {
-			res = pipe.Incr(ctx, rawKey)
-			if res.Val() == 1 && quotaRenewalRate > 0 {
-				pipe.Expire(ctx, rawKey, quotaRenewalRate)
-			}
-			return nil
-		})
+		used, err := quota.Increment(ctx, rawKey, cost, limit.QuotaMax, quotaRenewalRate)
 		if err != nil {
 			logger.WithError(err).Error("error incrementing quota key")
 			return true
 		}
 
-		quota := res.Val()
-		blocked := quota-1 >= limit.QuotaMax
-		remaining := limit.QuotaMax - quota
+		blocked := used > limit.QuotaMax
+		remaining := limit.QuotaMax - used
 		if blocked {
-			remaining = 0
+			// the blocked request isn't counted, the quota left may still fit lighter requests
+			remaining = max(limit.QuotaMax-(used-cost), 0)
 		}
 
-		logger = logger.WithField("quota", quota-1)
+		logger = logger.WithField("quota", used-cost)
 		logger = logger.WithField("blocked", blocked)
 		logger = logger.WithField("remaining", remaining)
 		logger.Debug("[QUOTA] Update quota key")
@@ -560,6 +614,22 @@ func (l *SessionLimiter) RedisQuotaExceeded(
 	return increment()
 }
 
+// requestRateCost returns the rate_cost entry matching the request, weighing it as a single request by default.
+func (l *SessionLimiter) requestRateCost(r *http.Request, api *APISpec) apidef.RateCostMeta {
+	rateCost := apidef.RateCostMeta{Cost: 1}
+
+	versionInfo, _ := api.Version(r)
+	if versionInfo == nil {
+		return rateCost
+	}
+
+	if spec, ok := api.FindSpecMatchesStatus(r, api.RxPaths[versionInfo.Name], RateCost); ok {
+		rateCost = spec.RateCost
+	}
+
+	return rateCost
+}
+
 func GetAccessDefinitionByAPIIDOrSession(session *user.SessionState, api *APISpec) (accessDef *user.AccessDefinition, allowanceScope string, err error) {
 	accessDef = &user.AccessDefinition{}
 	if len(session.AccessRights) > 0 {
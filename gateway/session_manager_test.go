@@ -491,7 +491,7 @@ func TestSessionLimiter(t *testing.T) {
 
 		cfg := tc.Gw.GetConfig()
 		drlManager := &drl.DRL{}
-		return NewSessionLimiter(tc.Gw.ctx, &cfg, drlManager, &cfg.ExternalServices)
+		return NewSessionLimiter(tc.Gw.ctx, &cfg, drlManager, &cfg.ExternalServices, nil)
 	}
 
 	limiter := newSessionLimiter(t)
@@ -590,6 +590,7 @@ func TestSessionLimiter(t *testing.T) {
 				&user.SessionState{},
 				key,
 				&user.APILimit{RateLimit: user.RateLimit{Rate: 60, Per: 60}},
+				1,
 				false,
 			)
 
@@ -611,6 +612,7 @@ func TestSessionLimiter(t *testing.T) {
 				&user.SessionState{},
 				key,
 				&user.APILimit{RateLimit: user.RateLimit{Rate: 60, Per: 60}},
+				1,
 				false,
 			)
 
@@ -689,15 +691,15 @@ func TestSessionLimiter(t *testing.T) {
 		session := &user.SessionState{}
 		apiLimit := &user.APILimit{RateLimit: user.RateLimit{Rate: 2, Per: 60}}
 
-		state, block := limiter.limitRedis(r, session, redisKey, apiLimit, false)
+		state, block := limiter.limitRedis(r, session, redisKey, apiLimit, 1, false)
 		assert.True(t, state.Reset == 0, "first cal is not blocked reset")
 		assert.False(t, block, "first cal is not blocked block")
 
-		state, block = limiter.limitRedis(r, session, redisKey, apiLimit, false)
+		state, block = limiter.limitRedis(r, session, redisKey, apiLimit, 1, false)
 		assert.InDelta(t, 60.0, state.Reset.Seconds(), 0.1, "second cal is blocked for all")
 		assert.False(t, block, "second cal is not blocked block")
 
-		state, block = limiter.limitRedis(r, session, redisKey, apiLimit, false)
+		state, block = limiter.limitRedis(r, session, redisKey, apiLimit, 1, false)
 		assert.InDelta(t, 60.0, state.Reset.Seconds(), 0.1, "third call is blocked for all window size")
 		assert.True(t, block, "third call is blocked")
 	})
@@ -712,11 +714,11 @@ func TestSessionLimiter(t *testing.T) {
 		apiLimit := &user.APILimit{RateLimit: user.RateLimit{Rate: 2, Per: 60}}
 		bucketKey := "test-drl-dryrun-key"
 
-		state, blocked := limiter.limitDRL(bucketKey, apiLimit, false)
+		state, blocked := limiter.limitDRL(bucketKey, apiLimit, 1, false)
 		require.False(t, blocked)
 		require.Equal(t, uint(1), state.Remaining)
 
-		_, blocked = limiter.limitDRL(bucketKey, apiLimit, true)
+		_, blocked = limiter.limitDRL(bucketKey, apiLimit, 1, true)
 		require.True(t, blocked, fmt.Sprintf(
 			"Dry run should return blocked=true when available tokens (%d) are less than cost (%d)",
 			state.Remaining,
@@ -0,0 +1,122 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+)
+
+// sseKeepaliveComment is an SSE comment line, ignored by EventSource clients.
+var sseKeepaliveComment = []byte(": ping\n\n")
+
+// sseKeepaliveWriter writes SSE comments to the client whenever the upstream
+// has been quiet for the keepalive interval, so that idle streams aren't
+// dropped by load balancers in between.
+//
+// Keepalives are only written between events: a keepalive written in the
+// middle of a partially received event would dispatch it early on the client.
+type sseKeepaliveWriter struct {
+	dst      writeFlusher
+	interval time.Duration
+
+	mu         sync.Mutex // protects tail, stopped, t and writes to dst
+	tail       []byte
+	stopped    bool
+	t          *time.Timer
+	stopOnDone func() bool
+}
+
+func newSSEKeepaliveWriter(ctx context.Context, dst writeFlusher, interval time.Duration) *sseKeepaliveWriter {
+	w := &sseKeepaliveWriter{
+		dst:      dst,
+		interval: interval,
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.t = time.AfterFunc(interval, w.keepalive)
+	// stop as soon as the client goes away
+	w.stopOnDone = context.AfterFunc(ctx, w.stop)
+
+	return w
+}
+
+func (w *sseKeepaliveWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.stopped {
+		w.t.Reset(w.interval)
+	}
+
+	n, err := w.dst.Write(p)
+	w.track(p[:n])
+
+	return n, err
+}
+
+func (w *sseKeepaliveWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.dst.Flush()
+}
+
+func (w *sseKeepaliveWriter) keepalive() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stopped { // if stop was called but AfterFunc already started this goroutine
+		return
+	}
+
+	if w.betweenEvents() {
+		if _, err := w.dst.Write(sseKeepaliveComment); err != nil {
+			w.stopLocked()
+			return
+		}
+		w.dst.Flush()
+	}
+
+	w.t.Reset(w.interval)
+}
+
+// stop prevents any further keepalive from being written. It is safe to call more than once.
+func (w *sseKeepaliveWriter) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stopLocked()
+}
+
+func (w *sseKeepaliveWriter) stopLocked() {
+	if w.stopped {
+		return
+	}
+
+	w.stopped = true
+	w.t.Stop()
+	if w.stopOnDone != nil {
+		w.stopOnDone()
+	}
+}
+
+// track keeps the last bytes written, enough to tell whether the stream ends with a blank line.
+func (w *sseKeepaliveWriter) track(p []byte) {
+	const keep = 3
+
+	w.tail = append(w.tail, p...)
+	if len(w.tail) > keep {
+		w.tail = append(w.tail[:0], w.tail[len(w.tail)-keep:]...)
+	}
+}
+
+// betweenEvents returns true if nothing was written yet or the last event was terminated by a blank line.
+func (w *sseKeepaliveWriter) betweenEvents() bool {
+	return len(w.tail) == 0 ||
+		bytes.HasSuffix(w.tail, []byte("\n\n")) ||
+		bytes.HasSuffix(w.tail, []byte("\r\r")) ||
+		bytes.HasSuffix(w.tail, []byte("\n\r\n"))
+}
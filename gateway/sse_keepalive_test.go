@@ -0,0 +1,71 @@
+package gateway
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lockedRecorder guards the recorder body, which the keepalive timer writes to concurrently.
+type lockedRecorder struct {
+	mu sync.Mutex
+	*httptest.ResponseRecorder
+}
+
+func (r *lockedRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ResponseRecorder.Write(p)
+}
+
+func (r *lockedRecorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.Body.String()
+}
+
+func TestSSEKeepaliveWriter(t *testing.T) {
+	const interval = 20 * time.Millisecond
+
+	t.Run("keepalive is written between events only", func(t *testing.T) {
+		rec := &lockedRecorder{ResponseRecorder: httptest.NewRecorder()}
+		w := newSSEKeepaliveWriter(context.Background(), rec, interval)
+		defer w.stop()
+
+		_, _ = w.Write([]byte("data: partial"))
+		time.Sleep(5 * interval)
+		assert.Equal(t, "data: partial", rec.String())
+
+		_, _ = w.Write([]byte("\n\n"))
+		assert.Eventually(t, func() bool {
+			return strings.HasPrefix(rec.String(), "data: partial\n\n: ping\n\n")
+		}, time.Second, interval/2)
+	})
+
+	t.Run("keepalive stops with the stream", func(t *testing.T) {
+		rec := &lockedRecorder{ResponseRecorder: httptest.NewRecorder()}
+		w := newSSEKeepaliveWriter(context.Background(), rec, interval)
+
+		w.stop()
+		w.stop()
+		time.Sleep(5 * interval)
+		assert.Empty(t, rec.String())
+	})
+
+	t.Run("keepalive stops when the client disconnects", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		rec := &lockedRecorder{ResponseRecorder: httptest.NewRecorder()}
+		w := newSSEKeepaliveWriter(ctx, rec, interval)
+		defer w.stop()
+
+		cancel()
+		time.Sleep(5 * interval)
+		assert.Empty(t, rec.String())
+	})
+}
@@ -9,12 +9,16 @@ import (
 	"time"
 )
 
-// prepareSSEStreaming configures the write deadline for SSE streams.
+// prepareSSEStreaming configures the write deadline and keepalives for SSE streams.
 //
 // Default behaviour: regular APIs keep the server's write_timeout.
 // For MCP APIs the deadline is cleared so streams live indefinitely,
 // mirroring how hijacked WebSocket connections bypass WriteTimeout.
-func (p *ReverseProxy) prepareSSEStreaming(rw http.ResponseWriter, _ io.Closer) (io.Writer, func()) {
+//
+// When sse_keepalive_interval is set, the returned writer injects SSE
+// comments while the upstream is quiet. The returned func stops them and
+// must be called once the upstream body is copied.
+func (p *ReverseProxy) prepareSSEStreaming(rw http.ResponseWriter, res *http.Response) (io.Writer, func()) {
 	if p.TykAPISpec.IsMCP() {
 		rc := http.NewResponseController(rw)
 		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
@@ -22,7 +26,23 @@ func (p *ReverseProxy) prepareSSEStreaming(rw http.ResponseWriter, _ io.Closer)
 		}
 	}
 
-	return nil, nil
+	interval := p.Gw.GetConfig().HttpServerOptions.SSEKeepaliveInterval
+	if interval <= 0 {
+		return nil, nil
+	}
+
+	wf, ok := rw.(writeFlusher)
+	if !ok {
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	if res.Request != nil {
+		ctx = res.Request.Context()
+	}
+
+	w := newSSEKeepaliveWriter(ctx, wf, time.Duration(interval)*time.Second)
+	return w, w.stop
 }
 
 // handleCopyError sends an SSE error event to the client when the upstream
@@ -18,8 +18,11 @@ import (
 
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/apidef/oas"
+	"github.com/TykTechnologies/tyk/ctx"
+	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/internal/httputil"
 	"github.com/TykTechnologies/tyk/internal/model"
+	"github.com/TykTechnologies/tyk/internal/reflect"
 	"github.com/TykTechnologies/tyk/user"
 )
 
@@ -42,14 +45,21 @@ type traceRequest struct {
 	Request *traceHttpRequest     `json:"request"`
 	Spec    *apidef.APIDefinition `json:"spec"`
 	OAS     *oas.OAS              `json:"oas"`
+	// APIID traces the request through the loaded API with this ID, instead of Spec or OAS.
+	APIID string `json:"api_id"`
+	// Key is the key the request is authenticated with, set in the auth header of the API.
+	Key string `json:"key"`
+	// DryRun stops the request before it's sent to the upstream.
+	DryRun bool `json:"dry_run"`
 }
 
 // TraceResponse is for tracing an HTTP response
 // swagger:model TraceResponse
 type traceResponse struct {
-	Message  string `json:"message"`
-	Response string `json:"response"`
-	Logs     string `json:"logs"`
+	Message  string      `json:"message"`
+	Response string      `json:"response"`
+	Logs     string      `json:"logs"`
+	Trace    *chainTrace `json:"trace,omitempty"`
 }
 
 type traceLogEntry struct {
@@ -130,6 +140,17 @@ func (tr *traceRequest) toRequest(
 		addCustomHeader(r.Header, key, values, ignoreCanonicalMIMEHeaderKey)
 	}
 
+	if tr.Key != "" {
+		authHeaderName := tr.Spec.AuthConfigs[apidef.AuthTokenType].AuthHeaderName
+		if authHeaderName == "" {
+			authHeaderName = header.Authorization
+		}
+
+		if r.Header.Get(authHeaderName) == "" {
+			r.Header.Set(authHeaderName, tr.Key)
+		}
+	}
+
 	// inject fake session
 	ctxSetSession(r, &user.SessionState{}, false, false)
 
@@ -178,7 +199,23 @@ func (gw *Gateway) traceHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if traceReq.OAS != nil {
+	if traceReq.APIID != "" {
+		loaded := gw.getApiSpec(traceReq.APIID)
+		if loaded == nil {
+			doJSONWrite(w, http.StatusNotFound, apiError("API not found"))
+			return
+		}
+
+		traceReq.Spec = reflect.Clone(loaded.APIDefinition)
+		traceReq.OAS = nil
+		if loaded.IsOAS {
+			var err error
+			if traceReq.OAS, err = loaded.OAS.Clone(); err != nil {
+				doJSONWrite(w, http.StatusInternalServerError, apiError("Unexpected failure: "+err.Error()))
+				return
+			}
+		}
+	} else if traceReq.OAS != nil {
 		var newDef apidef.APIDefinition
 		traceReq.OAS.ExtractTo(&newDef)
 		traceReq.Spec = &newDef
@@ -205,7 +242,9 @@ func (gw *Gateway) traceHandler(w http.ResponseWriter, r *http.Request) {
 	gs := gw.prepareStorage()
 
 	loader := &APIDefinitionLoader{Gw: gw}
-	traceReq.Spec.IsOAS = true
+	if traceReq.APIID == "" {
+		traceReq.Spec.IsOAS = true
+	}
 
 	spec, err := loader.MakeSpec(
 		&model.MergedAPI{APIDefinition: traceReq.Spec, OAS: traceReq.OAS},
@@ -239,7 +278,14 @@ func (gw *Gateway) traceHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	nopCloseRequestBody(tr)
+
+	trace := &chainTrace{APIID: spec.APIID, DryRun: traceReq.DryRun}
+	trace.matchPaths(spec, tr)
+	setCtxValue(tr, ctx.ChainTrace, trace)
+
+	startTime := time.Now()
 	chainObj.ThisHandler.ServeHTTP(wr, tr)
+	trace.DurationNs = time.Since(startTime).Nanoseconds()
 
 	var response string
 	if dump, err := httputil.DumpResponse(wr.Result(), true); err == nil {
@@ -259,6 +305,7 @@ func (gw *Gateway) traceHandler(w http.ResponseWriter, r *http.Request) {
 		Message:  "ok",
 		Response: makeTraceDump(request, response),
 		Logs:     logStorage.String(),
+		Trace:    trace,
 	})
 }
 
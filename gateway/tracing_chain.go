@@ -0,0 +1,282 @@
+package gateway
+
+import (
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TykTechnologies/tyk/ctx"
+	"github.com/TykTechnologies/tyk/internal/middleware"
+)
+
+// Decisions a middleware took on a traced request.
+const (
+	traceDecisionPass      = "pass"
+	traceDecisionTransform = "transform"
+	traceDecisionBlock     = "block"
+	traceDecisionRespond   = "respond"
+)
+
+// Parts of the request a traced mutation applies to.
+const (
+	traceTargetMethod = "method"
+	traceTargetURL    = "url"
+	traceTargetHeader = "header"
+	traceTargetBody   = "body"
+)
+
+// traceURLStatusNames names the extended path entries reported in a chain trace.
+var traceURLStatusNames = map[URLStatus]string{
+	Ignored:                "ignored",
+	WhiteList:              "white_list",
+	BlackList:              "black_list",
+	MockResponse:           "mock_response",
+	Cached:                 "cache",
+	Transformed:            "transform",
+	TransformedJQ:          "transform_jq",
+	HeaderInjected:         "transform_headers",
+	HeaderInjectedResponse: "transform_response_headers",
+	TransformedResponse:    "transform_response",
+	TransformedJQResponse:  "transform_jq_response",
+	HardTimeout:            "hard_timeouts",
+	CircuitBreaker:         "circuit_breakers",
+	URLRewrite:             "url_rewrites",
+	VirtualPath:            "virtual",
+	RequestSizeLimit:       "size_limits",
+	MethodTransformed:      "method_transforms",
+	RequestTracked:         "track_endpoints",
+	RequestNotTracked:      "do_not_track_endpoints",
+	ValidateJSONRequest:    "validate_json",
+	OASValidateRequest:     "validate_request",
+	Internal:               "internal",
+	GoPlugin:               "go_plugin",
+	PersistGraphQL:         "persist_graphql",
+	RateLimit:              "rate_limit",
+	OASMockResponse:        "oas_mock_response",
+	UpstreamHostHeader:     "upstream_host",
+}
+
+// chainTrace is the structured trace of a request passing through the middleware chain of an API.
+// swagger:model ChainTrace
+type chainTrace struct {
+	APIID           string                `json:"api_id"`
+	DryRun          bool                  `json:"dry_run"`
+	MatchedPaths    []traceMatchedPath    `json:"matched_paths"`
+	Middlewares     []*traceMiddleware    `json:"middlewares"`
+	UpstreamRequest *traceUpstreamRequest `json:"upstream_request,omitempty"`
+	DurationNs      int64                 `json:"duration_ns"`
+}
+
+// traceMatchedPath is an extended path entry the traced request matched.
+type traceMatchedPath struct {
+	Type    string `json:"type"`
+	Pattern string `json:"pattern"`
+}
+
+// traceMiddleware is the outcome of a middleware on the traced request.
+type traceMiddleware struct {
+	Name       string          `json:"name"`
+	Decision   string          `json:"decision"`
+	Code       int             `json:"code,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Mutations  []traceMutation `json:"mutations,omitempty"`
+	DurationNs int64           `json:"duration_ns"`
+
+	before    traceSnapshot
+	startTime time.Time
+}
+
+// traceMutation is a change a middleware made to the traced request.
+type traceMutation struct {
+	Target string `json:"target"`
+	Name   string `json:"name,omitempty"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// traceUpstreamRequest is the request the gateway sent, or would have sent, to the upstream.
+type traceUpstreamRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers"`
+	Body    string      `json:"body"`
+}
+
+// traceSnapshot is the state of the traced request the mutations are computed from.
+type traceSnapshot struct {
+	method  string
+	url     string
+	headers http.Header
+	body    string
+}
+
+func ctxGetChainTrace(r *http.Request) *chainTrace {
+	trace, _ := r.Context().Value(ctx.ChainTrace).(*chainTrace)
+	return trace
+}
+
+// begin records the start of the named middleware. It's a no-op for untraced requests.
+func (t *chainTrace) begin(name string, r *http.Request) *traceMiddleware {
+	if t == nil {
+		return nil
+	}
+
+	mw := &traceMiddleware{
+		Name:      name,
+		before:    snapshotRequest(r),
+		startTime: time.Now(),
+	}
+	t.Middlewares = append(t.Middlewares, mw)
+
+	return mw
+}
+
+// end records the outcome of the middleware. It's a no-op for untraced requests.
+func (mw *traceMiddleware) end(r *http.Request, err error, errCode int) {
+	if mw == nil {
+		return
+	}
+
+	mw.DurationNs = time.Since(mw.startTime).Nanoseconds()
+	mw.Mutations = mw.before.diff(snapshotRequest(r))
+
+	switch {
+	case err != nil:
+		mw.Decision = traceDecisionBlock
+		mw.Code = errCode
+		mw.Error = err.Error()
+	case errCode == middleware.StatusRespond:
+		mw.Decision = traceDecisionRespond
+	case len(mw.Mutations) > 0:
+		mw.Decision = traceDecisionTransform
+	default:
+		mw.Decision = traceDecisionPass
+	}
+}
+
+// upstream records the request sent to the upstream. It's a no-op for untraced requests.
+func (t *chainTrace) upstream(outreq *http.Request) {
+	if t == nil {
+		return
+	}
+
+	t.UpstreamRequest = &traceUpstreamRequest{
+		Method:  outreq.Method,
+		URL:     outreq.URL.String(),
+		Headers: outreq.Header.Clone(),
+		Body:    traceRequestBody(outreq),
+	}
+}
+
+// matchPaths records the extended path entries of the API version serving r.
+func (t *chainTrace) matchPaths(spec *APISpec, r *http.Request) {
+	versionInfo, _ := spec.Version(r.Clone(r.Context()))
+	if versionInfo == nil {
+		return
+	}
+
+	for _, rxPath := range spec.RxPaths[versionInfo.Name] {
+		name, ok := traceURLStatusNames[rxPath.Status]
+		if !ok || rxPath.spec == nil {
+			continue
+		}
+
+		matchPath, method := spec.getMatchPathAndMethod(r, rxPath.Status)
+		if !rxPath.matchesMethod(method) || !rxPath.matchesPath(matchPath, spec) {
+			continue
+		}
+
+		t.MatchedPaths = append(t.MatchedPaths, traceMatchedPath{Type: name, Pattern: rxPath.spec.String()})
+	}
+}
+
+// dryRunResponse is the empty response standing in for the upstream one in dry run traces.
+func dryRunResponse(outreq *http.Request) *http.Response {
+	return &http.Response{
+		Status:     http.StatusText(http.StatusOK),
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    outreq,
+	}
+}
+
+func snapshotRequest(r *http.Request) traceSnapshot {
+	// The URL rewrites and method transforms are applied to the request once it leaves the chain.
+	target := r.URL
+	if rewritten := ctxGetURLRewriteTarget(r); rewritten != nil {
+		target = rewritten
+	}
+
+	return traceSnapshot{
+		method:  ctxGetTransformRequestMethod(r),
+		url:     target.String(),
+		headers: r.Header.Clone(),
+		body:    traceRequestBody(r),
+	}
+}
+
+// diff returns the changes between s and the later snapshot.
+func (s traceSnapshot) diff(after traceSnapshot) []traceMutation {
+	var mutations []traceMutation
+
+	if s.method != after.method {
+		mutations = append(mutations, traceMutation{Target: traceTargetMethod, Before: s.method, After: after.method})
+	}
+
+	if s.url != after.url {
+		mutations = append(mutations, traceMutation{Target: traceTargetURL, Before: s.url, After: after.url})
+	}
+
+	names := map[string]struct{}{}
+	for name := range s.headers {
+		names[name] = struct{}{}
+	}
+	for name := range after.headers {
+		names[name] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		before, current := strings.Join(s.headers[name], ", "), strings.Join(after.headers[name], ", ")
+		if before != current {
+			mutations = append(mutations, traceMutation{Target: traceTargetHeader, Name: name, Before: before, After: current})
+		}
+	}
+
+	if s.body != after.body {
+		mutations = append(mutations, traceMutation{Target: traceTargetBody, Before: s.body, After: after.body})
+	}
+
+	return mutations
+}
+
+// traceRequestBody reads the body of r, leaving it ready to be read again.
+func traceRequestBody(r *http.Request) string {
+	if r.Body == nil || r.Body == http.NoBody {
+		return ""
+	}
+
+	body, err := copyBody(r.Body, true)
+	if err != nil {
+		return ""
+	}
+	r.Body = body
+
+	data, _ := io.ReadAll(body)
+	if seeker, ok := body.(io.Seeker); ok {
+		_, _ = seeker.Seek(0, io.SeekStart)
+	}
+
+	return string(data)
+}
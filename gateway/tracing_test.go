@@ -469,3 +469,120 @@ func and[T any](predicates ...cntPredicate[T]) cntPredicate[T] {
 		return true
 	}
 }
+
+func TestTraceChain(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	var upstreamHits int
+	upstream := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		upstreamHits++
+	}))
+	defer upstream.Close()
+
+	const apiID = "trace-chain"
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = apiID
+		spec.UseKeylessAccess = true
+		spec.Proxy.ListenPath = "/trace/"
+		spec.Proxy.StripListenPath = true
+		spec.Proxy.TargetURL = upstream.URL
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.UseExtendedPaths = true
+			v.ExtendedPaths.URLRewrite = []apidef.URLRewriteMeta{{
+				Path:         "/old",
+				Method:       http.MethodGet,
+				MatchPattern: "/old",
+				RewriteTo:    "/new",
+			}}
+			v.ExtendedPaths.TransformHeader = []apidef.HeaderInjectionMeta{{
+				Path:          "/old",
+				Method:        http.MethodGet,
+				AddHeaders:    map[string]string{"X-Added": "yes"},
+				DeleteHeaders: []string{"X-Removed"},
+			}}
+		})
+	})
+
+	trace := func(t *testing.T, traceReq traceRequest) (int, traceResponse) {
+		t.Helper()
+
+		reqBody, err := json.Marshal(traceReq)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		ts.Gw.traceHandler(w, httptest.NewRequest(http.MethodPost, "/tyk/debug", bytes.NewReader(reqBody)))
+
+		var traceResp traceResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &traceResp)
+		return w.Code, traceResp
+	}
+
+	headers := http.Header{}
+	headers.Set("X-Removed", "gone")
+
+	t.Run("dry run reports the mutations", func(t *testing.T) {
+		code, traceResp := trace(t, traceRequest{
+			APIID:   apiID,
+			DryRun:  true,
+			Request: &traceHttpRequest{Method: http.MethodGet, Path: "/old", Headers: headers},
+		})
+		require.Equal(t, http.StatusOK, code)
+		require.NotNil(t, traceResp.Trace)
+
+		chain := traceResp.Trace
+		assert.Equal(t, apiID, chain.APIID)
+		assert.True(t, chain.DryRun)
+		assert.Zero(t, upstreamHits)
+
+		assert.ElementsMatch(t, []string{"url_rewrites", "transform_headers"}, lo.Map(chain.MatchedPaths, func(p traceMatchedPath, _ int) string {
+			return p.Type
+		}))
+
+		byName := lo.KeyBy(chain.Middlewares, func(mw *traceMiddleware) string { return mw.Name })
+
+		rewrite := byName[new(URLRewriteMiddleware).Name()]
+		require.NotNil(t, rewrite)
+		assert.Equal(t, traceDecisionTransform, rewrite.Decision)
+		assert.Contains(t, rewrite.Mutations, traceMutation{Target: traceTargetURL, Before: "/trace/old", After: "/new"})
+
+		transform := byName[new(TransformHeaders).Name()]
+		require.NotNil(t, transform)
+		assert.Equal(t, traceDecisionTransform, transform.Decision)
+		assert.Equal(t, []traceMutation{
+			{Target: traceTargetHeader, Name: "X-Added", Before: "", After: "yes"},
+			{Target: traceTargetHeader, Name: "X-Removed", Before: "gone", After: ""},
+		}, transform.Mutations)
+
+		for _, mw := range chain.Middlewares {
+			if mw != rewrite && mw != transform {
+				assert.Equal(t, traceDecisionPass, mw.Decision, mw.Name)
+			}
+		}
+
+		require.NotNil(t, chain.UpstreamRequest)
+		assert.Equal(t, http.MethodGet, chain.UpstreamRequest.Method)
+		assert.Equal(t, upstream.URL+"/new", chain.UpstreamRequest.URL)
+		assert.Equal(t, "yes", chain.UpstreamRequest.Headers.Get("X-Added"))
+		assert.Empty(t, chain.UpstreamRequest.Headers.Get("X-Removed"))
+	})
+
+	t.Run("the upstream is called without dry run", func(t *testing.T) {
+		code, traceResp := trace(t, traceRequest{
+			APIID:   apiID,
+			Request: &traceHttpRequest{Method: http.MethodGet, Path: "/old"},
+		})
+		require.Equal(t, http.StatusOK, code)
+		require.NotNil(t, traceResp.Trace)
+		assert.NotNil(t, traceResp.Trace.UpstreamRequest)
+		assert.Equal(t, 1, upstreamHits)
+	})
+
+	t.Run("unknown API", func(t *testing.T) {
+		code, _ := trace(t, traceRequest{
+			APIID:   "unknown",
+			Request: &traceHttpRequest{Method: http.MethodGet, Path: "/old"},
+		})
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+}
@@ -0,0 +1,152 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/itchyny/gojq"
+)
+
+const (
+	defaultTransformJQTimeout       = time.Second
+	defaultTransformJQMaxOutputSize = 10 << 20
+	defaultTransformJQMaxInputSize  = 10 << 20
+)
+
+var (
+	// errTransformJQInput is wrapped by the errors of JQ transforms caused by a body not matching the expression.
+	errTransformJQInput = errors.New("body does not match the JQ transform")
+
+	errTransformJQTimeout        = errors.New("JQ transform timed out")
+	errTransformJQInputTooLarge  = errors.New("JQ transform input exceeded the allowed size")
+	errTransformJQOutputTooLarge = errors.New("JQ transform output exceeded the allowed size")
+)
+
+// transformJQInputReader limits body to one byte over the size a JQ transform accepts, so that
+// larger bodies are detected without being read in full.
+func (gw *Gateway) transformJQInputReader(body io.Reader) io.Reader {
+	return io.LimitReader(body, gw.transformJQMaxInputSize()+1)
+}
+
+func (gw *Gateway) transformJQMaxInputSize() int64 {
+	if maxSize := gw.GetConfig().HttpServerOptions.TransformJQMaxInputSize; maxSize > 0 {
+		return maxSize
+	}
+	return defaultTransformJQMaxInputSize
+}
+
+// transformJQ runs the JQ expression of a body transform against a JSON body and returns the first value
+// it produces, encoded as JSON. The context and session metadata are exposed as $tyk_context and $tyk_meta.
+// The body should be read through transformJQInputReader.
+func (gw *Gateway) transformJQ(r *http.Request, spec *APISpec, tmeta *TransformSpec, body []byte) ([]byte, error) {
+	if int64(len(body)) > gw.transformJQMaxInputSize() {
+		return nil, errTransformJQInputTooLarge
+	}
+
+	var input interface{}
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := decodeTransformJQValue(body, &input); err != nil {
+			return nil, fmt.Errorf("%w: error unmarshalling JSON: %v", errTransformJQInput, err)
+		}
+	}
+
+	tykContext := map[string]interface{}{}
+	if spec.EnableContextVars {
+		if err := normaliseTransformJQVariable(ctxGetData(r), &tykContext); err != nil {
+			return nil, err
+		}
+	}
+
+	tykMeta := map[string]interface{}{}
+	if tmeta.TemplateData.EnableSession {
+		if session := ctxGetSession(r); session != nil {
+			if err := normaliseTransformJQVariable(session.MetaData, &tykMeta); err != nil {
+				return nil, err
+			}
+		} else {
+			log.Error("Session context was enabled but not found.")
+		}
+	}
+
+	conf := gw.GetConfig().HttpServerOptions
+
+	timeout := defaultTransformJQTimeout
+	if conf.TransformJQTimeout > 0 {
+		timeout = time.Duration(conf.TransformJQTimeout) * time.Millisecond
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	value, ok := tmeta.JQ.RunWithContext(ctx, input, tykContext, tykMeta).Next()
+	if !ok {
+		return nil, fmt.Errorf("%w: no output", errTransformJQInput)
+	}
+
+	if err, ok := value.(error); ok {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, errTransformJQTimeout
+		}
+		return nil, fmt.Errorf("%w: %v", errTransformJQInput, err)
+	}
+
+	out, err := gojq.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSize := conf.TransformJQMaxOutputSize
+	if maxSize <= 0 {
+		maxSize = defaultTransformJQMaxOutputSize
+	}
+
+	if int64(len(out)) > maxSize {
+		return nil, errTransformJQOutputTooLarge
+	}
+
+	return out, nil
+}
+
+// decodeTransformJQValue decodes a single JSON value, keeping numbers exact.
+func decodeTransformJQValue(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after top-level value")
+	}
+
+	return nil
+}
+
+// normaliseTransformJQVariable converts a variable to the JSON types JQ expressions operate on.
+func normaliseTransformJQVariable(in interface{}, out *map[string]interface{}) error {
+	if in == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	if err := decodeTransformJQValue(data, out); err != nil {
+		return err
+	}
+
+	if *out == nil {
+		*out = map[string]interface{}{}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+const testTransformJQOrders = `{"orders":[{"id":1,"items":[{"sku":"a","qty":2},{"sku":"b","qty":0}]},{"id":2,"items":[{"sku":"c","qty":0}]}]}`
+
+func testTransformJQMeta(path, expression string) []apidef.TemplateMeta {
+	return []apidef.TemplateMeta{
+		{
+			Path:   path,
+			Method: http.MethodPost,
+			Format: apidef.TransformFormatJQ,
+			TemplateData: apidef.TemplateData{
+				Input:          apidef.RequestJSON,
+				Mode:           apidef.UseBlob,
+				TemplateSource: base64.StdEncoding.EncodeToString([]byte(expression)),
+			},
+		},
+	}
+}
+
+func TestTransformJQ_Request(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.EnableContextVars = true
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.Transform = testTransformJQMeta("/orders",
+				`{tenant: $tyk_context.headers_X_Tenant, orders: [.orders[] | {id, items: [.items[] | select(.qty > 0) | .sku]} | select(.items | length > 0)]}`)
+		})
+	})
+
+	t.Run("nested array filtering", func(t *testing.T) {
+		res, err := ts.Run(t, test.TestCase{
+			Method:  http.MethodPost,
+			Path:    "/orders",
+			Data:    testTransformJQOrders,
+			Headers: map[string]string{"X-Tenant": "acme"},
+			Code:    http.StatusOK,
+		})
+		require.NoError(t, err)
+
+		var upstream TestHttpResponse
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&upstream))
+
+		expected := `{"orders":[{"id":1,"items":["a"]}],"tenant":"acme"}`
+		assert.JSONEq(t, expected, upstream.Body)
+		assert.Equal(t, strconv.Itoa(len(upstream.Body)), upstream.Headers["Content-Length"])
+	})
+
+	t.Run("mismatched input", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Method: http.MethodPost, Path: "/orders", Data: `{"orders":"none"}`, Code: http.StatusBadRequest, BodyMatch: "Request body could not be transformed"},
+			{Method: http.MethodPost, Path: "/orders", Data: `not json`, Code: http.StatusBadRequest},
+		}...)
+	})
+}
+
+func TestTransformJQ_Response(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.Copy(w, r.Body)
+	}))
+	defer upstream.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.TransformResponse = testTransformJQMeta("/orders", `[.orders[].items[] | select(.qty > 0)]`)
+		})
+	})
+
+	res, err := ts.Run(t, test.TestCase{Method: http.MethodPost, Path: "/orders", Data: testTransformJQOrders, Code: http.StatusOK})
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"sku":"a","qty":2}]`, string(body))
+	assert.Equal(t, strconv.Itoa(len(body)), res.Header.Get("Content-Length"))
+
+	_, _ = ts.Run(t, test.TestCase{
+		Method:    http.MethodPost,
+		Path:      "/orders",
+		Data:      `{"orders":[{"items":"none"}]}`,
+		Code:      http.StatusInternalServerError,
+		BodyMatch: "Response body could not be transformed",
+	})
+}
+
+func TestTransformJQ_Limits(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.HttpServerOptions.TransformJQTimeout = 50
+		globalConf.HttpServerOptions.TransformJQMaxOutputSize = 64
+		globalConf.HttpServerOptions.TransformJQMaxInputSize = 32
+	})
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.Transform = append(
+				testTransformJQMeta("/loop", `last(range(infinite))`),
+				testTransformJQMeta("/large", `[range(100)]`)...,
+			)
+			v.ExtendedPaths.TransformResponse = testTransformJQMeta("/echo", `.`)
+		})
+	})
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Method: http.MethodPost, Path: "/loop", Data: `{}`, Code: http.StatusInternalServerError},
+		{Method: http.MethodPost, Path: "/large", Data: `{}`, Code: http.StatusInternalServerError},
+		{Method: http.MethodPost, Path: "/large", Data: `{"padding":"` + strings.Repeat("a", 32) + `"}`, Code: http.StatusBadRequest, BodyMatch: "Request is too large"},
+		{Method: http.MethodPost, Path: "/echo", Data: `{}`, Code: http.StatusInternalServerError, BodyMatch: "Response body too large"},
+	}...)
+}
+
+func TestTransformJQ_InvalidExpression(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	specs := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.Transform = testTransformJQMeta("/orders", `.orders[] | select(.id ==`)
+		})
+	})
+
+	require.Len(t, specs, 1)
+	assert.Nil(t, specs[0], "API with an invalid JQ expression should not be loaded")
+}
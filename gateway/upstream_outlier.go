@@ -0,0 +1,241 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+const (
+	defaultOutlierConsecutiveFailures = 5
+	defaultOutlierEjectionTime        = 30 * time.Second
+	defaultOutlierMaxEjectionTime     = 5 * time.Minute
+)
+
+// upstreamOutlierDetector tracks the consecutive failures of load balanced targets and ejects
+// the failing ones from rotation for an exponentially growing period of time.
+type upstreamOutlierDetector struct {
+	consecutiveFailures int
+	ejectionTime        time.Duration
+	maxEjectionTime     time.Duration
+
+	now func() time.Time
+
+	mu    sync.Mutex
+	hosts map[string]*upstreamHostState
+}
+
+type upstreamHostState struct {
+	failures     int
+	ejections    int
+	ejectedUntil time.Time
+}
+
+// EventHostEjectionMeta is the metadata structure for load balanced targets being ejected or readmitted.
+type EventHostEjectionMeta struct {
+	EventMetaDefault
+	Host         string
+	EjectedUntil time.Time
+}
+
+func newUpstreamOutlierDetector(conf apidef.ProxyOutlierDetection) *upstreamOutlierDetector {
+	if !conf.Enabled {
+		return nil
+	}
+
+	d := &upstreamOutlierDetector{
+		consecutiveFailures: conf.ConsecutiveFailures,
+		ejectionTime:        time.Duration(conf.EjectionTime) * time.Second,
+		maxEjectionTime:     time.Duration(conf.MaxEjectionTime) * time.Second,
+		now:                 time.Now,
+		hosts:               map[string]*upstreamHostState{},
+	}
+
+	if d.consecutiveFailures <= 0 {
+		d.consecutiveFailures = defaultOutlierConsecutiveFailures
+	}
+
+	if d.ejectionTime <= 0 {
+		d.ejectionTime = defaultOutlierEjectionTime
+	}
+
+	if d.maxEjectionTime <= 0 {
+		d.maxEjectionTime = defaultOutlierMaxEjectionTime
+	}
+
+	if d.maxEjectionTime < d.ejectionTime {
+		d.maxEjectionTime = d.ejectionTime
+	}
+
+	return d
+}
+
+// isEjected reports whether a host is ejected. A host whose ejection time elapsed is readmitted.
+func (d *upstreamOutlierDetector) isEjected(host string) (ejected, readmitted bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	state, ok := d.hosts[host]
+	if !ok || state.ejectedUntil.IsZero() {
+		return false, false
+	}
+
+	if d.now().Before(state.ejectedUntil) {
+		return true, false
+	}
+
+	state.ejectedUntil = time.Time{}
+	state.failures = 0
+	return false, true
+}
+
+// record records the outcome of a request to a host. It returns the time the host is ejected until
+// if the failure ejected it, and whether a success readmitted an ejected host.
+func (d *upstreamOutlierDetector) record(host string, failed bool) (ejectedUntil time.Time, readmitted bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	state, ok := d.hosts[host]
+	if !failed {
+		if ok {
+			// a success resets the backoff of the host too
+			delete(d.hosts, host)
+			readmitted = !state.ejectedUntil.IsZero()
+		}
+		return time.Time{}, readmitted
+	}
+
+	if !ok {
+		state = &upstreamHostState{}
+		d.hosts[host] = state
+	}
+
+	// ejected hosts only receive traffic when every target is ejected, don't extend their ejection
+	if !state.ejectedUntil.IsZero() {
+		return time.Time{}, false
+	}
+
+	state.failures++
+	if state.failures < d.consecutiveFailures {
+		return time.Time{}, false
+	}
+
+	ejectionTime := d.ejectionTime
+	for i := 0; i < state.ejections && ejectionTime < d.maxEjectionTime; i++ {
+		ejectionTime *= 2
+	}
+
+	if ejectionTime > d.maxEjectionTime {
+		ejectionTime = d.maxEjectionTime
+	}
+
+	state.failures = 0
+	state.ejections++
+	state.ejectedUntil = d.now().Add(ejectionTime)
+
+	return state.ejectedUntil, false
+}
+
+// readmit puts an ejected host back into rotation, keeping its backoff.
+func (d *upstreamOutlierDetector) readmit(host string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	state, ok := d.hosts[host]
+	if !ok || state.ejectedUntil.IsZero() {
+		return false
+	}
+
+	state.ejectedUntil = time.Time{}
+	state.failures = 0
+	return true
+}
+
+// outlierHostKey returns the key outlier detection tracks a target URL with.
+func outlierHostKey(target string) string {
+	if !strings.Contains(target, "://") {
+		return target
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+
+	return u.Host
+}
+
+// upstreamHostEjected reports whether a load balanced target is ejected from rotation by outlier detection.
+func (s *APISpec) upstreamHostEjected(target string) bool {
+	if s.outlierDetector == nil {
+		return false
+	}
+
+	host := outlierHostKey(target)
+	ejected, readmitted := s.outlierDetector.isEjected(host)
+	if readmitted {
+		s.fireHostReadmitted(host, "Ejection time elapsed")
+	}
+
+	return ejected
+}
+
+// recordUpstreamResult records the outcome of a proxied request for outlier detection.
+// 5xx responses and errors other than cancelled requests count as failures.
+func (s *APISpec) recordUpstreamResult(target string, res *http.Response, err error) {
+	if s.outlierDetector == nil || !s.Proxy.EnableLoadBalancing {
+		return
+	}
+
+	failed := res != nil && res.StatusCode >= http.StatusInternalServerError
+	if err != nil {
+		failed = !errors.Is(err, context.Canceled) && !strings.HasPrefix(err.Error(), "mock:")
+	}
+
+	host := outlierHostKey(target)
+	ejectedUntil, readmitted := s.outlierDetector.record(host, failed)
+
+	if readmitted {
+		s.fireHostReadmitted(host, "Request succeeded")
+	}
+
+	if !ejectedUntil.IsZero() {
+		log.WithField("prefix", "proxy").Warningf("[PROXY] [LOAD BALANCING] Host %s ejected until %s", host, ejectedUntil.Format(time.RFC3339))
+
+		s.FireEvent(EventHostEjected, EventHostEjectionMeta{
+			EventMetaDefault: EventMetaDefault{
+				Message: fmt.Sprintf("Host ejected after %d consecutive failures", s.outlierDetector.consecutiveFailures),
+			},
+			Host:         host,
+			EjectedUntil: ejectedUntil,
+		})
+	}
+}
+
+// readmitUpstreamHost puts an ejected target back into rotation after a successful uptime test.
+func (s *APISpec) readmitUpstreamHost(target string) {
+	if s.outlierDetector == nil {
+		return
+	}
+
+	host := outlierHostKey(target)
+	if s.outlierDetector.readmit(host) {
+		s.fireHostReadmitted(host, "Uptime test succeeded")
+	}
+}
+
+func (s *APISpec) fireHostReadmitted(host, reason string) {
+	log.WithField("prefix", "proxy").Infof("[PROXY] [LOAD BALANCING] Host %s readmitted: %s", host, reason)
+
+	s.FireEvent(EventHostReadmitted, EventHostEjectionMeta{
+		EventMetaDefault: EventMetaDefault{Message: reason},
+		Host:             host,
+	})
+}
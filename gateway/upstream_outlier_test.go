@@ -0,0 +1,169 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestUpstreamOutlierDetector(t *testing.T) {
+	now := time.Now()
+	d := newUpstreamOutlierDetector(apidef.ProxyOutlierDetection{
+		Enabled:             true,
+		ConsecutiveFailures: 2,
+		EjectionTime:        10,
+		MaxEjectionTime:     30,
+	})
+	d.now = func() time.Time { return now }
+
+	eject := func() time.Time {
+		until, _ := d.record("a:80", true)
+		assert.True(t, until.IsZero())
+		until, _ = d.record("a:80", true)
+		return until
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.Nil(t, newUpstreamOutlierDetector(apidef.ProxyOutlierDetection{}))
+	})
+
+	t.Run("success resets consecutive failures", func(t *testing.T) {
+		d.record("a:80", true)
+		d.record("a:80", false)
+		until, _ := d.record("a:80", true)
+		assert.True(t, until.IsZero())
+		d.record("a:80", false)
+	})
+
+	t.Run("ejection time doubles up to the maximum", func(t *testing.T) {
+		for _, ejectionTime := range []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second} {
+			assert.Equal(t, now.Add(ejectionTime), eject())
+
+			ejected, _ := d.isEjected("a:80")
+			assert.True(t, ejected)
+
+			now = now.Add(ejectionTime)
+			ejected, readmitted := d.isEjected("a:80")
+			assert.False(t, ejected)
+			assert.True(t, readmitted)
+		}
+
+		_, readmitted := d.record("a:80", false)
+		assert.False(t, readmitted)
+		assert.Equal(t, now.Add(10*time.Second), eject())
+	})
+
+	t.Run("readmitted after a success", func(t *testing.T) {
+		_, readmitted := d.record("a:80", false)
+		assert.True(t, readmitted)
+
+		ejected, _ := d.isEjected("a:80")
+		assert.False(t, ejected)
+	})
+
+	t.Run("readmitted after a successful uptime test", func(t *testing.T) {
+		eject()
+		assert.True(t, d.readmit("a:80"))
+		assert.False(t, d.readmit("a:80"))
+
+		ejected, _ := d.isEjected("a:80")
+		assert.False(t, ejected)
+	})
+}
+
+func TestOutlierDetection_LoadBalancing(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	var healthyHits, failingHits int64
+	var failing int32 = 1
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt64(&healthyHits, 1)
+	}))
+	defer healthy.Close()
+
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt64(&failingHits, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer flaky.Close()
+
+	api := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.EnableLoadBalancing = true
+		spec.Proxy.Targets = []string{healthy.URL, flaky.URL}
+		spec.Proxy.OutlierDetection = apidef.ProxyOutlierDetection{
+			Enabled:             true,
+			ConsecutiveFailures: 2,
+			EjectionTime:        30,
+		}
+	})[0]
+
+	now := time.Now()
+	detector := ts.Gw.getApiSpec(api.APIID).outlierDetector
+	detector.now = func() time.Time { return now }
+
+	request := func(n int) {
+		for i := 0; i < n; i++ {
+			_, _ = ts.Run(t, test.TestCase{Path: "/"})
+		}
+	}
+
+	// round robin sends every other request to the failing upstream until it gets ejected
+	request(4)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&failingHits))
+
+	request(6)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&failingHits), "ejected upstream shouldn't receive traffic")
+	assert.Equal(t, int64(8), atomic.LoadInt64(&healthyHits))
+
+	atomic.StoreInt32(&failing, 0)
+	now = now.Add(30 * time.Second)
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Path: "/", Code: http.StatusOK},
+		{Path: "/", Code: http.StatusOK},
+		{Path: "/", Code: http.StatusOK},
+		{Path: "/", Code: http.StatusOK},
+	}...)
+	assert.Equal(t, int64(4), atomic.LoadInt64(&failingHits), "recovered upstream should be back in rotation")
+}
+
+func TestOutlierDetection_AllHostsEjected(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	var hits int64
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt64(&hits, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer upstream.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.EnableLoadBalancing = true
+		spec.Proxy.Targets = []string{upstream.URL}
+		spec.Proxy.OutlierDetection = apidef.ProxyOutlierDetection{
+			Enabled:             true,
+			ConsecutiveFailures: 1,
+		}
+	})
+
+	// an ejected host still receives traffic when there is no other one
+	_, _ = ts.Run(t, []test.TestCase{
+		{Path: "/", Code: http.StatusBadGateway},
+		{Path: "/", Code: http.StatusBadGateway},
+	}...)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&hits))
+}
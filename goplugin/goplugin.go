@@ -9,6 +9,9 @@ import (
 	"plugin"
 )
 
+// Enabled reports whether the binary was built with go plugin support.
+const Enabled = true
+
 func GetSymbol(modulePath string, symbol string) (interface{}, error) {
 	// try to load plugin
 	loadedPlugin, err := plugin.Open(modulePath)
@@ -54,3 +57,20 @@ func GetResponseHandler(modulePath string, symbol string) (func(rw http.Response
 
 	return respPluginHandler, nil
 }
+
+// GetResponseHandlerWithSession loads a response plugin of either the
+// ResponseHandlerWithSession or the GetResponseHandler signature.
+func GetResponseHandlerWithSession(modulePath string, symbol string) (ResponseHandlerWithSession, error) {
+	funcSymbol, err := GetSymbol(modulePath, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	// try to cast symbol to real func
+	respPluginHandler, ok := responseHandlerFromSymbol(funcSymbol)
+	if !ok {
+		return nil, errors.New("could not cast function symbol to TykResponseHandler")
+	}
+
+	return respPluginHandler, nil
+}
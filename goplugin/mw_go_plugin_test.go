@@ -4,9 +4,14 @@
 package goplugin_test
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/base64"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -20,6 +25,7 @@ import (
 	"github.com/TykTechnologies/tyk/apidef/oas"
 	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/gateway"
+	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/test"
 	"github.com/TykTechnologies/tyk/user"
 )
@@ -327,6 +333,92 @@ func TestGoPluginResponseHook(t *testing.T) {
 	})
 }
 
+func TestGoPluginResponseHook_RewritesBodyWithSession(t *testing.T) {
+	const upstreamBody = `{"message":"from upstream"}`
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(header.ContentType, header.ApplicationJSON)
+		if r.Header.Get(header.AcceptEncoding) != "gzip" {
+			_, _ = w.Write([]byte(upstreamBody))
+			return
+		}
+
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write([]byte(upstreamBody))
+		_ = zw.Close()
+
+		w.Header().Set(header.ContentEncoding, "gzip")
+		w.Header().Set(header.ContentLength, strconv.Itoa(buf.Len()))
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer upstream.Close()
+
+	ts := gateway.StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *gateway.APISpec) {
+		spec.APIID = "plugin_api"
+		spec.Proxy.ListenPath = "/goplugin"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.UseKeylessAccess = false
+		spec.CustomMiddleware = apidef.MiddlewareSection{
+			Driver: apidef.GoPluginDriver,
+			Response: []apidef.MiddlewareDefinition{
+				{
+					Name: "MyResponsePluginRewritingBody",
+					Path: goPluginFilename(),
+				},
+			},
+		}
+	})
+
+	_, key := ts.CreateSession(func(s *user.SessionState) {
+		s.Alias = "plugin-alias"
+		s.AccessRights = map[string]user.AccessDefinition{"plugin_api": {APIID: "plugin_api"}}
+	})
+
+	const wantBody = `{"message":"from upstream","session_alias":"plugin-alias"}`
+
+	t.Run("gzip encoded", func(t *testing.T) {
+		resp, err := ts.Run(t, test.TestCase{
+			Path:    "/goplugin/get",
+			Headers: map[string]string{header.Authorization: key, header.AcceptEncoding: "gzip"},
+			Code:    http.StatusOK,
+		})
+		require.NoError(t, err)
+
+		raw, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, "gzip", resp.Header.Get(header.ContentEncoding))
+		assert.Equal(t, strconv.Itoa(len(raw)), resp.Header.Get(header.ContentLength))
+
+		zr, err := gzip.NewReader(bytes.NewReader(raw))
+		require.NoError(t, err)
+		body, err := io.ReadAll(zr)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, wantBody, string(body))
+	})
+
+	t.Run("not encoded", func(t *testing.T) {
+		resp, err := ts.Run(t, test.TestCase{
+			Path:    "/goplugin/get",
+			Headers: map[string]string{header.Authorization: key, header.AcceptEncoding: "identity"},
+			Code:    http.StatusOK,
+		})
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		assert.Empty(t, resp.Header.Get(header.ContentEncoding))
+		assert.Equal(t, strconv.Itoa(len(body)), resp.Header.Get(header.ContentLength))
+		assert.JSONEq(t, wantBody, string(body))
+	})
+}
+
 func TestGoPluginPerPathSingleFile(t *testing.T) {
 
 	ts := gateway.StartTest(nil)
@@ -12,6 +12,9 @@ const (
 	errNotImplemented = "goplugin.%s is disabled, use -tags=goplugin to enable"
 )
 
+// Enabled reports whether the binary was built with go plugin support.
+const Enabled = false
+
 func GetSymbol(modulePath string, symbol string) (interface{}, error) {
 	return nil, fmt.Errorf(errNotImplemented, "GetSymbol")
 }
@@ -23,3 +26,7 @@ func GetHandler(path string, symbol string) (http.HandlerFunc, error) {
 func GetResponseHandler(path string, symbol string) (func(rw http.ResponseWriter, res *http.Response, req *http.Request), error) {
 	return nil, fmt.Errorf(errNotImplemented, "GetResponseHandler")
 }
+
+func GetResponseHandlerWithSession(path string, symbol string) (ResponseHandlerWithSession, error) {
+	return nil, fmt.Errorf(errNotImplemented, "GetResponseHandlerWithSession")
+}
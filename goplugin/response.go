@@ -0,0 +1,39 @@
+package goplugin
+
+import (
+	"net/http"
+
+	"github.com/TykTechnologies/tyk/internal/httputil"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// ResponseHandlerWithSession is the signature of a response plugin receiving the session of the request.
+// Response plugins with the `func(http.ResponseWriter, *http.Response, *http.Request)` signature load too,
+// they are called without the session.
+type ResponseHandlerWithSession func(rw http.ResponseWriter, res *http.Response, req *http.Request, session *user.SessionState)
+
+// responseHandlerFromSymbol casts a plugin symbol to a response handler of either signature.
+func responseHandlerFromSymbol(funcSymbol interface{}) (ResponseHandlerWithSession, bool) {
+	switch fn := funcSymbol.(type) {
+	case func(http.ResponseWriter, *http.Response, *http.Request, *user.SessionState):
+		return fn, true
+	case func(http.ResponseWriter, *http.Response, *http.Request):
+		return func(rw http.ResponseWriter, res *http.Response, req *http.Request, _ *user.SessionState) {
+			fn(rw, res, req)
+		}, true
+	}
+
+	return nil, false
+}
+
+// ReadResponseBody reads the body of the response, decompressing it when it is gzip encoded.
+// The response body stays readable, so a plugin may read the body and leave it unchanged.
+func ReadResponseBody(res *http.Response) ([]byte, error) {
+	return httputil.ReadResponseBody(res)
+}
+
+// ReplaceResponseBody replaces the body of the response, gzip encoding it when the response is
+// gzip encoded, and sets the Content-Length of the response to the length of the new body.
+func ReplaceResponseBody(res *http.Response, body []byte) error {
+	return httputil.ReplaceResponseBody(res, body)
+}
@@ -18,6 +18,16 @@ const (
 	Cookie                  = "Cookie"
 	TransferEncoding        = "Transfer-Encoding"
 	Host                    = "Host"
+	RetryAfter              = "Retry-After"
+	Sunset                  = "Sunset"
+	Deprecation             = "Deprecation"
+	Warning                 = "Warning"
+	Link                    = "Link"
+	Vary                    = "Vary"
+	ETag                    = "ETag"
+	Date                    = "Date"
+	Digest                  = "Digest"
+	SOAPAction              = "SOAPAction"
 )
 
 const (
@@ -45,16 +55,20 @@ const (
 	XTykHostname          = "x-tyk-hostname"
 	XGenerator            = "X-Generator"
 	XTykAuthorization     = "X-Tyk-Authorization"
+	XTykOrgID             = "X-Tyk-Org-Id"
 	XTykAcceptExampleName = "X-Tyk-Accept-Example-Name"
 	XTykAcceptExampleCode = "X-Tyk-Accept-Example-Code"
+	XRequestID            = "X-Request-ID"
 )
 
 // upgrade and websocket
 const (
-	Upgrade              = "Upgrade"
-	SecWebSocketProtocol = "Sec-WebSocket-Protocol"
-	SecWebSocketVersion  = "Sec-WebSocket-Version"
-	SecWebSocketKey      = "Sec-WebSocket-Key"
+	Upgrade                = "Upgrade"
+	SecWebSocketProtocol   = "Sec-WebSocket-Protocol"
+	SecWebSocketVersion    = "Sec-WebSocket-Version"
+	SecWebSocketKey        = "Sec-WebSocket-Key"
+	SecWebSocketAccept     = "Sec-WebSocket-Accept"
+	SecWebSocketExtensions = "Sec-WebSocket-Extensions"
 )
 
 // Gateway's custom response headers
@@ -67,4 +81,10 @@ const (
 
 	// XRateLimitReset The number of seconds until the rate limit resets.
 	XRateLimitReset = "X-RateLimit-Reset"
+
+	// XTykLimitScope Whether the key or the organisation limit rejected the request, either key or org.
+	XTykLimitScope = "X-Tyk-Limit-Scope"
+
+	// XTykLatencyBreakdown The time spent in each stage of the request in milliseconds, e.g. auth=2,proxy=35.
+	XTykLatencyBreakdown = "X-Tyk-Latency-Breakdown"
 )
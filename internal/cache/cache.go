@@ -2,6 +2,7 @@ package cache
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,6 +17,9 @@ type Cache struct {
 	// cache items and protecting mutex
 	mu    sync.RWMutex
 	items map[string]Item
+
+	// stats counts the accesses once enabled, nil until then
+	stats atomic.Pointer[Stats]
 }
 
 // NewCache creates a new *Cache for storing items with a TTL.
@@ -72,20 +76,42 @@ func (c *Cache) Get(k string) (any, bool) {
 	item, found := c.items[k]
 	if !found {
 		c.mu.RUnlock()
+		c.recordMiss()
 		return nil, false
 	}
 
 	if item.Expiration > 0 {
 		if time.Now().UnixNano() > item.Expiration {
 			c.mu.RUnlock()
+			c.recordMiss()
 			return nil, false
 		}
 	}
 
 	c.mu.RUnlock()
+	if stats := c.stats.Load(); stats != nil {
+		stats.hits.Add(1)
+	}
 	return item.Object, true
 }
 
+func (c *Cache) recordMiss() {
+	if stats := c.stats.Load(); stats != nil {
+		stats.misses.Add(1)
+	}
+}
+
+// EnableStats starts counting the hits, misses and evictions of the cache.
+// Until then the accesses aren't counted.
+func (c *Cache) EnableStats() {
+	c.stats.CompareAndSwap(nil, &Stats{})
+}
+
+// Stats returns the counters of the cache, nil if they aren't enabled.
+func (c *Cache) Stats() *Stats {
+	return c.stats.Load()
+}
+
 // Items copies all unexpired items in the cache into a new map and returns it.
 func (c *Cache) Items() map[string]Item {
 	c.mu.RLock()
@@ -115,13 +141,20 @@ func (c *Cache) Delete(k string) {
 func (c *Cache) Cleanup() {
 	now := time.Now().UnixNano()
 
+	var evicted int64
+
 	c.mu.Lock()
 	for k, v := range c.items {
 		if v.Expiration > 0 && now > v.Expiration {
 			delete(c.items, k)
+			evicted++
 		}
 	}
 	c.mu.Unlock()
+
+	if stats := c.stats.Load(); stats != nil && evicted > 0 {
+		stats.evictions.Add(evicted)
+	}
 }
 
 // Count returns the number of items in cache, including expired items.
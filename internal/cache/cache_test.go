@@ -50,3 +50,30 @@ func TestCache_Expired(t *testing.T) {
 		assert.Equal(t, 2, cache.Count())
 	})
 }
+
+func TestCache_Stats(t *testing.T) {
+	cache := &Cache{
+		items: map[string]Item{
+			"expired": {
+				Expiration: 1,
+			},
+			"live": {
+				Expiration: 0,
+			},
+		},
+	}
+	assert.Nil(t, cache.Stats())
+
+	cache.EnableStats()
+	stats := cache.Stats()
+	assert.NotNil(t, stats)
+
+	cache.EnableStats()
+	assert.Same(t, stats, cache.Stats(), "enabling the stats again keeps the counters")
+
+	cache.Get("live")
+	cache.Get("expired")
+	cache.Cleanup()
+
+	assert.Equal(t, StatsSnapshot{Hits: 1, Misses: 1, Evictions: 1, HitRatio: 0.5}, stats.Snapshot())
+}
@@ -79,6 +79,25 @@ func (r *MemRepository) Close() {
 	r.cache.Close()
 }
 
+// EnableStats starts counting the hits, misses and evictions of the cache.
+func (r *MemRepository) EnableStats() {
+	r.cache.EnableStats()
+}
+
+// Stats returns a snapshot of the counters and the size of the cache. It
+// reports false when the counters aren't enabled, the snapshot only has the size then.
+func (r *MemRepository) Stats() (StatsSnapshot, bool) {
+	var snapshot StatsSnapshot
+
+	stats := r.cache.Stats()
+	if stats != nil {
+		snapshot = stats.Snapshot()
+	}
+	snapshot.Size = r.cache.Count()
+
+	return snapshot, stats != nil
+}
+
 // DefaultExpiration returns default expiration in seconds
 func (r *MemRepository) DefaultExpiration() int64 {
 	return r.defaultExpiration
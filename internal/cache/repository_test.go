@@ -43,3 +43,29 @@ func TestRepository(t *testing.T) {
 	store.Close()
 	assert.Equal(t, 0, store.Count())
 }
+
+func TestRepository_Stats(t *testing.T) {
+	store := cache.New(60, 0)
+	defer store.Close()
+
+	store.Set("key", "value", 0)
+	store.Get("key")
+
+	stats, enabled := store.Stats()
+	assert.False(t, enabled)
+	assert.Equal(t, cache.StatsSnapshot{Size: 1}, stats, "accesses aren't counted until stats are enabled")
+
+	store.EnableStats()
+
+	// 3 hits and a miss
+	for _, key := range []string{"key", "key", "missing", "key"} {
+		store.Get(key)
+	}
+
+	stats, enabled = store.Stats()
+	assert.True(t, enabled)
+	assert.Equal(t, int64(3), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, 1, stats.Size)
+	assert.Equal(t, 0.75, stats.HitRatio)
+}
@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"sync/atomic"
+)
+
+// Stats counts the hits, misses and evictions of a cache. The counters are
+// updated atomically and are safe for concurrent use.
+type Stats struct {
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+// StatsSnapshot is a point in time copy of the counters of a cache.
+type StatsSnapshot struct {
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	Evictions int64   `json:"evictions"`
+	Size      int     `json:"size"`
+	HitRatio  float64 `json:"hit_ratio"`
+}
+
+// Snapshot returns the current values of the counters. Size isn't known to
+// the counters and is left to the caller.
+func (s *Stats) Snapshot() StatsSnapshot {
+	snapshot := StatsSnapshot{
+		Hits:      s.hits.Load(),
+		Misses:    s.misses.Load(),
+		Evictions: s.evictions.Load(),
+	}
+
+	if lookups := snapshot.Hits + snapshot.Misses; lookups > 0 {
+		snapshot.HitRatio = float64(snapshot.Hits) / float64(lookups)
+	}
+
+	return snapshot
+}
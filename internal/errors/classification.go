@@ -111,6 +111,7 @@ const (
 	// Rate limit error types
 	ErrTypeSessionRateLimit = "session_rate_limit"
 	ErrTypeAPIRateLimit     = "api_rate_limit"
+	ErrTypeOrgRateLimit     = "org_rate_limit"
 	ErrTypeOtherRateLimit   = "generic_rate_limit_error"
 
 	// JSON validation error types
@@ -138,7 +139,9 @@ const (
 	// Rate limit details
 	detailSessionRateLimited = "session_rate_limited"
 	detailAPIRateLimited     = "api_rate_limited"
+	detailOrgRateLimited     = "org_rate_limited"
 	detailQuotaExceeded      = "quota_exceeded"
+	detailOrgQuotaExceeded   = "org_quota_exceeded"
 	detailGenericRateLimit   = "generic_rate_limit_error"
 
 	// JWT details
@@ -365,6 +365,8 @@ func ClassifyRateLimitError(errorType string, source string) *ErrorClassificatio
 		return NewErrorClassification(RLT, detailSessionRateLimited).WithSource(source)
 	case ErrTypeAPIRateLimit:
 		return NewErrorClassification(RLT, detailAPIRateLimited).WithSource(source)
+	case ErrTypeOrgRateLimit:
+		return NewErrorClassification(RLT, detailOrgRateLimited).WithSource(source)
 	case ErrTypeOtherRateLimit:
 		return NewErrorClassification(RLT, detailGenericRateLimit).WithSource(source)
 	default:
@@ -377,6 +379,11 @@ func ClassifyQuotaExceededError(source string) *ErrorClassification {
 	return NewErrorClassification(QEX, detailQuotaExceeded).WithSource(source)
 }
 
+// ClassifyOrgQuotaExceededError creates an error classification for organisation quota exceeded events.
+func ClassifyOrgQuotaExceededError(source string) *ErrorClassification {
+	return NewErrorClassification(QEX, detailOrgQuotaExceeded).WithSource(source)
+}
+
 // ClassifyJWTError maps JWT-specific error types to ErrorClassification.
 // Returns nil for unknown error types.
 func ClassifyJWTError(errorType string, source string) *ErrorClassification {
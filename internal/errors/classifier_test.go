@@ -844,6 +844,13 @@ func TestClassifyRateLimitError(t *testing.T) {
 			expectedFlag: RLT,
 			expectedDet:  "api_rate_limited",
 		},
+		{
+			name:         "org_rate_limit",
+			errorType:    ErrTypeOrgRateLimit,
+			source:       "OrganizationMonitor",
+			expectedFlag: RLT,
+			expectedDet:  "org_rate_limited",
+		},
 		{
 			name:         "other_rate_limit",
 			errorType:    ErrTypeOtherRateLimit,
@@ -878,6 +885,15 @@ func TestClassifyQuotaExceededError(t *testing.T) {
 	assert.Equal(t, "RateLimitAndQuotaCheck", ec.Source)
 }
 
+func TestClassifyOrgQuotaExceededError(t *testing.T) {
+	ec := ClassifyOrgQuotaExceededError("OrganizationMonitor")
+
+	assert.NotNil(t, ec)
+	assert.Equal(t, QEX, ec.Flag)
+	assert.Equal(t, "org_quota_exceeded", ec.Details)
+	assert.Equal(t, "OrganizationMonitor", ec.Source)
+}
+
 func TestClassifyJWTError(t *testing.T) {
 	testCases := []struct {
 		name         string
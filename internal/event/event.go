@@ -38,6 +38,10 @@ const (
 	HostDown Event = "HostDown"
 	// HostUp is the event triggered when hostchecker finds a host is back being available after being offline.
 	HostUp Event = "HostUp"
+	// HostEjected is the event triggered when a load balanced target is ejected from rotation after consecutive failures.
+	HostEjected Event = "HostEjected"
+	// HostReadmitted is the event triggered when an ejected load balanced target is put back into rotation.
+	HostReadmitted Event = "HostReadmitted"
 	// TokenCreated is the event triggered when a token is created.
 	TokenCreated Event = "TokenCreated"
 	// TokenUpdated is the event triggered when a token is updated.
@@ -49,9 +53,40 @@ const (
 	// CertificateExpired is the event triggered when a certificate is expired.
 	CertificateExpired Event = "CertificateExpired"
 
+	// RefreshTokenReused is the event triggered when an already exchanged OAuth refresh token is presented again
+	// and all tokens of the client have been revoked.
+	RefreshTokenReused Event = "RefreshTokenReused"
+
+	// KeyIPNotAllowed is the event triggered when a key is used from an IP address outside its allowed IPs.
+	KeyIPNotAllowed Event = "KeyIPNotAllowed"
+
 	// OAuth2ScopeCheckFailed fires when an OAS-native scope check
 	// rejects a request (insufficient_scope per RFC 6750 §3.1).
 	OAuth2ScopeCheckFailed Event = "OAuth2ScopeCheckFailed"
+
+	// ControlAPIAuthLockout is the event triggered when a source IP is locked out of the Control API
+	// after too many authentication failures.
+	ControlAPIAuthLockout Event = "ControlAPIAuthLockout"
+
+	// KVSecretsRotated is the event triggered when secrets of the Gateway configuration
+	// stored in Consul or Vault changed and were applied without a restart.
+	KVSecretsRotated Event = "KVSecretsRotated"
+
+	// GeoRestrictionBlocked is the event triggered when a request is blocked by the country restrictions of an API.
+	GeoRestrictionBlocked Event = "GeoRestrictionBlocked"
+
+	// PayloadSizeThreshold is the event triggered when the body of a proxied request or response exceeds the size configured for an API.
+	PayloadSizeThreshold Event = "PayloadSizeThreshold"
+
+	// VersionDeprecated is the event triggered by the first request to an API version once its deprecation date passed.
+	VersionDeprecated Event = "VersionDeprecated"
+
+	// VersionSunset is the event triggered by the first request to an API version once it's retired.
+	VersionSunset Event = "VersionSunset"
+
+	// AuthFailureLockout is the event triggered when a client IP or a token prefix is locked out of an API
+	// after too many authentication failures.
+	AuthFailureLockout Event = "AuthFailureLockout"
 )
 
 // Rate limiter events
@@ -95,6 +130,8 @@ const (
 	JSVMHandler HandlerName = "eh_dynamic_handler"
 	// CoProcessHandler is the HandlerName used in classic API definition for coprocess event handler.
 	CoProcessHandler HandlerName = "cp_dynamic_handler"
+	// ChatWebhookHandler is the HandlerName used in classic API definition for Slack and Microsoft Teams event handler.
+	ChatWebhookHandler HandlerName = "eh_chat_webhook_handler"
 )
 
 // Kind is the action to be performed when an event is triggered, to be used in OAS API definition.
@@ -6,6 +6,8 @@ const (
 	ComplexityFailReasonNone ComplexityFailReason = iota
 	ComplexityFailReasonInternalError
 	ComplexityFailReasonDepthLimitExceeded
+	ComplexityFailReasonNodeLimitExceeded
+	ComplexityFailReasonAliasLimitExceeded
 )
 
 type GranularAccessFailReason int
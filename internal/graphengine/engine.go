@@ -42,6 +42,8 @@ type ComplexityAccessDefinition struct {
 
 type ComplexityLimit struct {
 	MaxQueryDepth int
+	MaxQueryNodes int
+	MaxAliases    int
 }
 
 type ComplexityFieldAccessDefinition struct {
@@ -55,6 +57,7 @@ type ComplexityFieldLimits struct {
 }
 
 type ComplexityChecker interface {
+	// DepthLimitExceeded checks the request against the depth, node and alias limits.
 	DepthLimitExceeded(r *http.Request, accessDefinition *ComplexityAccessDefinition) ComplexityFailReason
 }
 
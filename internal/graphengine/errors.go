@@ -8,6 +8,8 @@ var (
 	ProxyingRequestFailedErr     = errors.New("there was a problem proxying the request")
 	errCustomBodyResponse        = errors.New("errCustomBodyResponse")
 	GraphQLDepthLimitExceededErr = errors.New("depth limit exceeded")
+	GraphQLNodeLimitExceededErr  = errors.New("query node limit exceeded")
+	GraphQLAliasLimitExceededErr = errors.New("query alias limit exceeded")
 	ErrIntrospectionDisabled     = errors.New("introspection is disabled")
 	ErrUnknownReverseProxyType   = errors.New("unknown reverse proxy type")
 )
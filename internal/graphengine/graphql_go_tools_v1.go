@@ -290,7 +290,7 @@ type complexityCheckerV1 struct {
 }
 
 func (c *complexityCheckerV1) DepthLimitExceeded(r *http.Request, accessDefinition *ComplexityAccessDefinition) ComplexityFailReason {
-	if !c.depthLimitEnabled(accessDefinition) {
+	if !c.depthLimitEnabled(accessDefinition) && !sizeLimitsEnabled(accessDefinition) {
 		return ComplexityFailReasonNone
 	}
 
@@ -320,6 +320,42 @@ func (c *complexityCheckerV1) DepthLimitExceeded(r *http.Request, accessDefiniti
 		return ComplexityFailReasonInternalError
 	}
 
+	if failReason := c.depthLimitExceeded(complexityRes, accessDefinition); failReason != ComplexityFailReasonNone {
+		return failReason
+	}
+
+	if accessDefinition.Limit.MaxQueryNodes > 0 && complexityRes.NodeCount > accessDefinition.Limit.MaxQueryNodes {
+		c.logger.Debug("node count of the request is higher than the allowed limit",
+			abstractlogger.Int("nodeCount", complexityRes.NodeCount),
+			abstractlogger.Int("maxQueryNodes", accessDefinition.Limit.MaxQueryNodes),
+		)
+		return ComplexityFailReasonNodeLimitExceeded
+	}
+
+	if accessDefinition.Limit.MaxAliases > 0 {
+		aliases, err := countAliases(gqlRequest.Query)
+		if err != nil {
+			c.logger.Error("error while counting aliases of GraphQL request", abstractlogger.Error(err))
+			return ComplexityFailReasonInternalError
+		}
+
+		if aliases > accessDefinition.Limit.MaxAliases {
+			c.logger.Debug("alias count of the request is higher than the allowed limit",
+				abstractlogger.Int("aliases", aliases),
+				abstractlogger.Int("maxAliases", accessDefinition.Limit.MaxAliases),
+			)
+			return ComplexityFailReasonAliasLimitExceeded
+		}
+	}
+
+	return ComplexityFailReasonNone
+}
+
+func (c *complexityCheckerV1) depthLimitExceeded(complexityRes graphql.ComplexityResult, accessDefinition *ComplexityAccessDefinition) ComplexityFailReason {
+	if !c.depthLimitEnabled(accessDefinition) {
+		return ComplexityFailReasonNone
+	}
+
 	// do per query depth check
 	if len(accessDefinition.FieldAccessRights) == 0 {
 		if accessDefinition.Limit.MaxQueryDepth > 0 && complexityRes.Depth > accessDefinition.Limit.MaxQueryDepth {
@@ -415,6 +415,46 @@ func TestComplexityCheckerV1_DepthLimitExceeded(t *testing.T) {
 		})
 	})
 
+	t.Run("query node and alias limits", func(t *testing.T) {
+		const (
+			nestedOperation  = `{ countries { continent { countries { continent } } } }`                    // 3 nodes
+			aliasedOperation = `{ a: countries { continent } b: countries { continent } }`                  // 2 nodes, 2 aliases
+			fragmentAliases  = `{ countries { ...f } } fragment f on Country { a: continent b: continent }` // 2 aliases
+		)
+
+		testCases := []struct {
+			name      string
+			operation string
+			limit     ComplexityLimit
+			expected  ComplexityFailReason
+		}{
+			{name: "node limit exceeded", operation: nestedOperation, limit: ComplexityLimit{MaxQueryNodes: 2}, expected: ComplexityFailReasonNodeLimitExceeded},
+			{name: "node limit not exceeded", operation: nestedOperation, limit: ComplexityLimit{MaxQueryNodes: 3}, expected: ComplexityFailReasonNone},
+			{name: "alias limit exceeded", operation: aliasedOperation, limit: ComplexityLimit{MaxAliases: 1}, expected: ComplexityFailReasonAliasLimitExceeded},
+			{name: "alias limit not exceeded", operation: aliasedOperation, limit: ComplexityLimit{MaxAliases: 2}, expected: ComplexityFailReasonNone},
+			{name: "aliases in fragments are counted", operation: fragmentAliases, limit: ComplexityLimit{MaxAliases: 1}, expected: ComplexityFailReasonAliasLimitExceeded},
+			{name: "depth limit is checked first", operation: nestedOperation, limit: ComplexityLimit{MaxQueryDepth: 2, MaxQueryNodes: 1}, expected: ComplexityFailReasonDepthLimitExceeded},
+			{name: "unlimited depth with node limit", operation: nestedOperation, limit: ComplexityLimit{MaxQueryDepth: -1, MaxQueryNodes: 1}, expected: ComplexityFailReasonNodeLimitExceeded},
+			{name: "introspection query is exempt", operation: testIntrospectionQuery, limit: ComplexityLimit{MaxQueryNodes: 1, MaxAliases: 1}, expected: ComplexityFailReasonNone},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				request, err := http.NewRequest(http.MethodPost, "http://example.com", nil)
+				require.NoError(t, err)
+
+				complexityChecker := newTestComplexityCheckerV1(t, withTestComplexityCheckerV1Schema(testSchemaNestedEngineV1))
+				complexityChecker.ctxRetrieveRequest = func(r *http.Request) *graphql.Request {
+					return &graphql.Request{
+						Query: tc.operation,
+					}
+				}
+
+				result := complexityChecker.DepthLimitExceeded(request, &ComplexityAccessDefinition{Limit: tc.limit})
+				assert.Equal(t, tc.expected, result)
+			})
+		}
+	})
 }
 
 func TestComplexityCheckerV1_DepthLimitEnabled(t *testing.T) {
@@ -9,6 +9,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astparser"
 	graphqlv2 "github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
 
 	"github.com/TykTechnologies/tyk/apidef"
@@ -60,12 +61,33 @@ func writeGraphQLError(logger abstractlogger.Logger, w http.ResponseWriter, erro
 	return errCustomBodyResponse, http.StatusBadRequest
 }
 
+// countAliases returns the number of aliased fields in the query, including
+// the ones in fragments. Only the query is parsed, so the engine versions share it.
+func countAliases(query string) (int, error) {
+	doc, report := astparser.ParseGraphqlDocumentString(query)
+	if report.HasErrors() {
+		return 0, report
+	}
+
+	var count int
+	for _, field := range doc.Fields {
+		if field.Alias.IsDefined {
+			count++
+		}
+	}
+	return count, nil
+}
+
 func complexityFailReasonAsHttpStatusCode(failReason ComplexityFailReason) (error, int) {
 	switch failReason {
 	case ComplexityFailReasonInternalError:
 		return ProxyingRequestFailedErr, http.StatusInternalServerError
 	case ComplexityFailReasonDepthLimitExceeded:
 		return GraphQLDepthLimitExceededErr, http.StatusForbidden
+	case ComplexityFailReasonNodeLimitExceeded:
+		return GraphQLNodeLimitExceededErr, http.StatusBadRequest
+	case ComplexityFailReasonAliasLimitExceeded:
+		return GraphQLAliasLimitExceededErr, http.StatusBadRequest
 	}
 
 	return nil, http.StatusOK
@@ -98,6 +120,15 @@ func granularAccessFailReasonAsHttpStatusCode(logger abstractlogger.Logger, resu
 	return nil, http.StatusOK
 }
 
+// sizeLimitsEnabled reports whether a node or alias limit is set.
+func sizeLimitsEnabled(accessDefinition *ComplexityAccessDefinition) bool {
+	if accessDefinition == nil {
+		return false
+	}
+
+	return accessDefinition.Limit.MaxQueryNodes > 0 || accessDefinition.Limit.MaxAliases > 0
+}
+
 func greaterThanIntConsideringUnlimited(first, second int) bool {
 	if first == -1 {
 		return true
@@ -121,6 +152,12 @@ func isProxyOnly(apiDefinition *apidef.APIDefinition) bool {
 
 func additionalUpstreamHeaders(logger abstractlogger.Logger, outreq *http.Request, apiDefinition *apidef.APIDefinition) http.Header {
 	upstreamHeaders := http.Header{}
+
+	// propagate the request ID to the subgraphs and data sources, so the calls can be correlated
+	if requestID := ctx.GetRequestID(outreq); requestID != nil {
+		upstreamHeaders.Set(requestID.Header, requestID.ID)
+	}
+
 	switch apiDefinition.GraphQL.ExecutionMode {
 	case apidef.GraphQLExecutionModeSupergraph:
 		// if this context vars are enabled and this is a supergraph, inject the sub request id header
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/ctx"
 	"github.com/TykTechnologies/tyk/header"
 )
 
@@ -414,3 +415,32 @@ func TestAdditionalUpstreamHeaders_NoAuthHeaderLeakAcrossConnections(t *testing.
 			"unauthenticated connection must not inherit any previous connection's auth token")
 	})
 }
+
+func TestAdditionalUpstreamHeaders_PropagateRequestID(t *testing.T) {
+	for _, mode := range []apidef.GraphQLExecutionMode{apidef.GraphQLExecutionModeSupergraph, apidef.GraphQLExecutionModeExecutionEngine} {
+		t.Run(string(mode), func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "http://example.com", nil)
+			require.NoError(t, err)
+			ctx.SetRequestID(req, &ctx.RequestIDData{Header: "X-Correlation-Id", ID: "request-1"})
+
+			apiDef := &apidef.APIDefinition{StripAuthData: true}
+			apiDef.GraphQL.Enabled = true
+			apiDef.GraphQL.ExecutionMode = mode
+
+			result := additionalUpstreamHeaders(testLogger(), req, apiDef)
+			assert.Equal(t, "request-1", result.Get("X-Correlation-Id"))
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "http://example.com", nil)
+		require.NoError(t, err)
+
+		apiDef := &apidef.APIDefinition{StripAuthData: true}
+		apiDef.GraphQL.Enabled = true
+		apiDef.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeSupergraph
+
+		result := additionalUpstreamHeaders(testLogger(), req, apiDef)
+		assert.Empty(t, result.Get(header.XRequestID))
+	})
+}
@@ -118,9 +118,16 @@ func (f *ExternalHTTPClientFactory) CreateHealthCheckClient() (*http.Client, err
 
 // GetJWKWithClient fetches JWK using the provided HTTP client for proxy and mTLS support
 func GetJWKWithClient(jwlUrl string, client *http.Client, parseJWK func([]byte) (*jose.JSONWebKeySet, error)) (*jose.JSONWebKeySet, error) {
+	jwkSet, _, err := GetJWKWithClientAndHeaders(jwlUrl, client, parseJWK)
+	return jwkSet, err
+}
+
+// GetJWKWithClientAndHeaders fetches JWK like GetJWKWithClient and also returns the response headers,
+// so callers can honour the caching directives of the JWKS endpoint.
+func GetJWKWithClientAndHeaders(jwlUrl string, client *http.Client, parseJWK func([]byte) (*jose.JSONWebKeySet, error)) (*jose.JSONWebKeySet, http.Header, error) {
 	resp, err := client.Get(jwlUrl)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	defer func() {
@@ -129,15 +136,15 @@ func GetJWKWithClient(jwlUrl string, client *http.Client, parseJWK func([]byte)
 
 	buf, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	jwkSet, err := parseJWK(buf)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return jwkSet, nil
+	return jwkSet, resp.Header, nil
 }
 
 // getServiceConfig returns the merged configuration for a specific service type.
@@ -3,12 +3,17 @@ package httputil
 import (
 	"net"
 	"net/http"
+	"sync"
 	"sync/atomic"
 )
 
-// ConnectionWatcher counts http server connections.
+// ConnectionWatcher counts http server connections,
+// and the websocket connections proxied for each API.
 type ConnectionWatcher struct {
 	n int64
+
+	mu         sync.RWMutex
+	websockets map[string]int64
 }
 
 // NewConnectionWatcher returns a new *ConnectionWatcher.
@@ -28,7 +33,7 @@ func (cw *ConnectionWatcher) OnStateChange(_ net.Conn, state http.ConnState) {
 	}
 }
 
-// Count returns the number of connections at the time the call.
+// Count returns the number of connections at the time of the call.
 func (cw *ConnectionWatcher) Count() int {
 	return int(atomic.LoadInt64(&cw.n))
 }
@@ -37,3 +42,39 @@ func (cw *ConnectionWatcher) Count() int {
 func (cw *ConnectionWatcher) Add(c int64) {
 	atomic.AddInt64(&cw.n, c)
 }
+
+// AddWebSocket adds c to the number of active websocket connections for an API.
+func (cw *ConnectionWatcher) AddWebSocket(apiID string, c int64) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	if cw.websockets == nil {
+		cw.websockets = make(map[string]int64)
+	}
+
+	cw.websockets[apiID] += c
+	if cw.websockets[apiID] == 0 {
+		delete(cw.websockets, apiID)
+	}
+}
+
+// WebSocketCount returns the number of active websocket connections for an API at the time of the call.
+func (cw *ConnectionWatcher) WebSocketCount(apiID string) int {
+	cw.mu.RLock()
+	defer cw.mu.RUnlock()
+
+	return int(cw.websockets[apiID])
+}
+
+// WebSocketCounts returns the number of active websocket connections for each API at the time of the call.
+func (cw *ConnectionWatcher) WebSocketCounts() map[string]int {
+	cw.mu.RLock()
+	defer cw.mu.RUnlock()
+
+	counts := make(map[string]int, len(cw.websockets))
+	for apiID, n := range cw.websockets {
+		counts[apiID] = int(n)
+	}
+
+	return counts
+}
@@ -26,3 +26,22 @@ func TestConnectionWatcher(t *testing.T) {
 	assert.Equal(t, -1, w.Count())
 
 }
+
+func TestConnectionWatcher_WebSockets(t *testing.T) {
+	w := httputil.NewConnectionWatcher()
+	assert.Equal(t, 0, w.WebSocketCount("api1"))
+	assert.Empty(t, w.WebSocketCounts())
+
+	w.AddWebSocket("api1", 1)
+	w.AddWebSocket("api1", 1)
+	w.AddWebSocket("api2", 1)
+	assert.Equal(t, 2, w.WebSocketCount("api1"))
+	assert.Equal(t, map[string]int{"api1": 2, "api2": 1}, w.WebSocketCounts())
+
+	w.AddWebSocket("api1", -2)
+	assert.Equal(t, 0, w.WebSocketCount("api1"))
+	assert.Equal(t, map[string]int{"api2": 1}, w.WebSocketCounts())
+
+	// websocket connections aren't counted as http server connections
+	assert.Equal(t, 0, w.Count())
+}
@@ -1,7 +1,13 @@
 package httputil
 
 import (
+	"bytes"
+	"compress/gzip"
+	"io"
 	"net/http"
+	"strconv"
+
+	"github.com/TykTechnologies/tyk/header"
 )
 
 // EntityTooLarge responds with HTTP 413 Request Entity Too Large.
@@ -32,3 +38,61 @@ func RemoveResponseTransferEncoding(response *http.Response, victim string) {
 		}
 	}
 }
+
+// ReadResponseBody reads the body of the response, decompressing it when it is gzip encoded.
+// The response body stays readable, so the body may be read and left unchanged.
+func ReadResponseBody(res *http.Response) ([]byte, error) {
+	if res.Body == nil || res.Body == http.NoBody {
+		return nil, nil
+	}
+
+	raw, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	if !isGzipEncoded(res) {
+		return raw, nil
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// ReplaceResponseBody replaces the body of the response, gzip encoding it when the response is
+// gzip encoded, and sets the Content-Length of the response to the length of the new body.
+func ReplaceResponseBody(res *http.Response, body []byte) error {
+	if isGzipEncoded(res) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		body = buf.Bytes()
+	}
+
+	if res.Body != nil {
+		res.Body.Close()
+	}
+
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	res.ContentLength = int64(len(body))
+	res.TransferEncoding = nil
+	res.Header.Set(header.ContentLength, strconv.Itoa(len(body)))
+
+	return nil
+}
+
+func isGzipEncoded(res *http.Response) bool {
+	return res.Header.Get(header.ContentEncoding) == "gzip"
+}
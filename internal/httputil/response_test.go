@@ -1,11 +1,18 @@
 package httputil
 
 import (
+	"bytes"
+	"compress/gzip"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/header"
 )
 
 func TestRequestUtilities(t *testing.T) {
@@ -70,3 +77,70 @@ func TestRemoveResponseTransferEncoding(t *testing.T) {
 		})
 	}
 }
+
+func TestReadAndReplaceResponseBody(t *testing.T) {
+	gzipped := func(t *testing.T, body string) []byte {
+		t.Helper()
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err := zw.Write([]byte(body))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		return buf.Bytes()
+	}
+
+	t.Run("plain body", func(t *testing.T) {
+		res := &http.Response{
+			Header: http.Header{},
+			Body:   io.NopCloser(strings.NewReader("original")),
+		}
+
+		body, err := ReadResponseBody(res)
+		require.NoError(t, err)
+		assert.Equal(t, "original", string(body))
+
+		unchanged, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "original", string(unchanged))
+
+		require.NoError(t, ReplaceResponseBody(res, []byte("replaced body")))
+		assert.Equal(t, int64(13), res.ContentLength)
+		assert.Equal(t, "13", res.Header.Get(header.ContentLength))
+
+		replaced, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "replaced body", string(replaced))
+	})
+
+	t.Run("gzip encoded body", func(t *testing.T) {
+		res := &http.Response{
+			Header:           http.Header{header.ContentEncoding: []string{"gzip"}},
+			Body:             io.NopCloser(bytes.NewReader(gzipped(t, "original"))),
+			TransferEncoding: []string{"chunked"},
+		}
+
+		body, err := ReadResponseBody(res)
+		require.NoError(t, err)
+		assert.Equal(t, "original", string(body))
+
+		require.NoError(t, ReplaceResponseBody(res, []byte("replaced body")))
+		assert.Empty(t, res.TransferEncoding)
+		assert.Equal(t, "gzip", res.Header.Get(header.ContentEncoding))
+
+		raw, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(raw)), res.ContentLength)
+
+		zr, err := gzip.NewReader(bytes.NewReader(raw))
+		require.NoError(t, err)
+		replaced, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		assert.Equal(t, "replaced body", string(replaced))
+	})
+
+	t.Run("no body", func(t *testing.T) {
+		body, err := ReadResponseBody(&http.Response{Header: http.Header{}, Body: http.NoBody})
+		require.NoError(t, err)
+		assert.Nil(t, body)
+	})
+}
@@ -35,4 +35,5 @@ const (
 	Fail      = apidef.Fail
 	System    = apidef.System
 	Datastore = apidef.Datastore
+	Component = apidef.Component
 )
@@ -7,6 +7,20 @@ type UpstreamAuthProvider interface {
 	Fill(r *http.Request)
 }
 
+// UpstreamAuthRefresher is implemented by the upstream auth providers which can renew
+// the authentication details rejected by the upstream.
+type UpstreamAuthRefresher interface {
+	// Refresh fills in new authentication details and reports whether the request can be sent again.
+	Refresh(r *http.Request) bool
+}
+
+// UpstreamAuthSigner is implemented by the upstream auth providers which sign the request.
+// It's called in place of Fill on the final outbound request, so the signature covers its body and headers.
+type UpstreamAuthSigner interface {
+	// Sign signs the request or reports why it can't be signed.
+	Sign(r *http.Request) error
+}
+
 // MockUpstreamAuthProvider is a mock implementation of UpstreamAuthProvider.
 type MockUpstreamAuthProvider struct{}
 
@@ -101,6 +101,9 @@ var metadataExtractors = map[string]func(rc *RequestContext) string{
 	"response_code": func(rc *RequestContext) string {
 		return strconv.Itoa(rc.StatusCode)
 	},
+	"status_class": func(rc *RequestContext) string {
+		return StatusClass(rc.StatusCode)
+	},
 	"listen_path": func(rc *RequestContext) string {
 		return rc.ListenPath
 	},
@@ -206,3 +209,12 @@ func truncateKey(token string) string {
 	}
 	return token
 }
+
+// StatusClass returns the class of an HTTP status code, e.g. `2xx` for 204.
+// Codes outside of the 100-599 range are reported as `unknown`.
+func StatusClass(code int) string {
+	if code < 100 || code > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(code/100) + "xx"
+}
@@ -48,6 +48,24 @@ func TestCompileExtractor_MetadataResponseCode(t *testing.T) {
 	assert.Equal(t, "404", ext.Extract(rc))
 }
 
+func TestCompileExtractor_MetadataStatusClass(t *testing.T) {
+	ext, err := CompileExtractor(DimensionDefinition{Source: "metadata", Key: "status_class"})
+	require.NoError(t, err)
+
+	rc := makeRequestContext()
+	rc.StatusCode = 404
+	assert.Equal(t, "4xx", ext.Extract(rc))
+}
+
+func TestStatusClass(t *testing.T) {
+	assert.Equal(t, "1xx", StatusClass(101))
+	assert.Equal(t, "2xx", StatusClass(200))
+	assert.Equal(t, "3xx", StatusClass(302))
+	assert.Equal(t, "5xx", StatusClass(599))
+	assert.Equal(t, "unknown", StatusClass(0))
+	assert.Equal(t, "unknown", StatusClass(600))
+}
+
 func TestCompileExtractor_MetadataListenPath(t *testing.T) {
 	ext, err := CompileExtractor(DimensionDefinition{Source: "metadata", Key: "listen_path"})
 	require.NoError(t, err)
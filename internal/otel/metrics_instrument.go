@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 
 	tykmetric "github.com/TykTechnologies/opentelemetry/metric"
 
@@ -30,6 +31,13 @@ type MetricInstruments struct {
 	// Reload event metrics.
 	reloadCounter  *tykmetric.Counter
 	reloadDuration *tykmetric.Histogram
+
+	// Proxy outcome metrics. These are dimensioned by API ID and listen
+	// path only to keep their cardinality bounded.
+	proxyRequests       *tykmetric.Counter
+	upstreamLatency     *tykmetric.Histogram
+	circuitBreakerTrips *tykmetric.Counter
+	rateLimitRejections *tykmetric.Counter
 }
 
 // NewMetricInstruments creates gateway metric instruments from an existing provider.
@@ -80,13 +88,54 @@ func NewMetricInstruments(provider tykmetric.Provider, logger *logrus.Logger) *M
 		logger.Errorf("Creating reload duration histogram: %s", err)
 	}
 
+	proxyRequests, err := provider.NewCounter(
+		"tyk.proxy.requests",
+		"Total requests proxied per API and response status class",
+		"{request}",
+	)
+	if err != nil {
+		logger.Errorf("Creating proxy requests counter: %s", err)
+	}
+
+	upstreamLatency, err := provider.NewHistogram(
+		"tyk.proxy.upstream.latency",
+		"Upstream response time per API",
+		"s",
+		tykmetric.DefaultLatencyBucketsSeconds,
+	)
+	if err != nil {
+		logger.Errorf("Creating upstream latency histogram: %s", err)
+	}
+
+	circuitBreakerTrips, err := provider.NewCounter(
+		"tyk.proxy.circuit_breaker.trips",
+		"Total number of circuit breaker trips per API",
+		"{trip}",
+	)
+	if err != nil {
+		logger.Errorf("Creating circuit breaker trips counter: %s", err)
+	}
+
+	rateLimitRejections, err := provider.NewCounter(
+		"tyk.proxy.rate_limit.rejections",
+		"Total number of requests rejected by rate limiting per API",
+		"{request}",
+	)
+	if err != nil {
+		logger.Errorf("Creating rate limit rejections counter: %s", err)
+	}
+
 	return &MetricInstruments{
-		provider:       provider,
-		requestCounter: requestCounter,
-		apisLoaded:     apisLoaded,
-		policiesLoaded: policiesLoaded,
-		reloadCounter:  reloadCounter,
-		reloadDuration: reloadDuration,
+		provider:            provider,
+		requestCounter:      requestCounter,
+		apisLoaded:          apisLoaded,
+		policiesLoaded:      policiesLoaded,
+		reloadCounter:       reloadCounter,
+		reloadDuration:      reloadDuration,
+		proxyRequests:       proxyRequests,
+		upstreamLatency:     upstreamLatency,
+		circuitBreakerTrips: circuitBreakerTrips,
+		rateLimitRejections: rateLimitRejections,
 	}
 }
 
@@ -151,6 +200,35 @@ func (i *MetricInstruments) RecordReload(ctx context.Context, duration time.Dura
 	i.reloadDuration.Record(ctx, duration.Seconds())
 }
 
+// RecordProxyRequest counts a proxied request by API and response status class.
+func (i *MetricInstruments) RecordProxyRequest(ctx context.Context, apiID, listenPath string, statusCode int) {
+	attrs := append(apiAttributes(apiID, listenPath),
+		attribute.String("http.response.status_class", apimetrics.StatusClass(statusCode)))
+	i.proxyRequests.Add(ctx, 1, attrs...)
+}
+
+// RecordUpstreamLatency records the time the upstream took to respond.
+func (i *MetricInstruments) RecordUpstreamLatency(ctx context.Context, apiID, listenPath string, latency time.Duration) {
+	i.upstreamLatency.Record(ctx, latency.Seconds(), apiAttributes(apiID, listenPath)...)
+}
+
+// RecordCircuitBreakerTrip increments the circuit breaker trips counter.
+func (i *MetricInstruments) RecordCircuitBreakerTrip(ctx context.Context, apiID, listenPath string) {
+	i.circuitBreakerTrips.Add(ctx, 1, apiAttributes(apiID, listenPath)...)
+}
+
+// RecordRateLimitRejection increments the rate limit rejections counter.
+func (i *MetricInstruments) RecordRateLimitRejection(ctx context.Context, apiID, listenPath string) {
+	i.rateLimitRejections.Add(ctx, 1, apiAttributes(apiID, listenPath)...)
+}
+
+func apiAttributes(apiID, listenPath string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("tyk.api.id", apiID),
+		attribute.String("tyk.api.listen_path", listenPath),
+	}
+}
+
 // Shutdown flushes pending metrics and shuts down the provider.
 func (i *MetricInstruments) Shutdown(ctx context.Context) error {
 	if err := i.provider.ForceFlush(ctx); err != nil {
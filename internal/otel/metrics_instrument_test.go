@@ -10,6 +10,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/TykTechnologies/opentelemetry/metric/metrictest"
 
@@ -151,6 +152,35 @@ func TestRecordRequest_CountsCorrectly(t *testing.T) {
 	metrictest.AssertSum(t, m, int64(7))
 }
 
+func TestRecordProxyOutcomes(t *testing.T) {
+	inst, tp := activeProvider(t)
+	ctx := context.Background()
+
+	inst.RecordProxyRequest(ctx, "api-1", "/one/", 200)
+	inst.RecordProxyRequest(ctx, "api-1", "/one/", 204)
+	inst.RecordProxyRequest(ctx, "api-1", "/one/", 502)
+	inst.RecordUpstreamLatency(ctx, "api-1", "/one/", 250*time.Millisecond)
+	inst.RecordCircuitBreakerTrip(ctx, "api-1", "/one/")
+	inst.RecordRateLimitRejection(ctx, "api-1", "/one/")
+	inst.RecordRateLimitRejection(ctx, "api-1", "/one/")
+
+	apiAttrs := []attribute.KeyValue{
+		attribute.String("tyk.api.id", "api-1"),
+		attribute.String("tyk.api.listen_path", "/one/"),
+	}
+
+	requests := tp.FindMetric(t, "tyk.proxy.requests")
+	metrictest.AssertSumWithAttrs(t, requests, int64(2), append(apiAttrs, attribute.String("http.response.status_class", "2xx"))...)
+	metrictest.AssertSumWithAttrs(t, requests, int64(1), append(apiAttrs, attribute.String("http.response.status_class", "5xx"))...)
+
+	latency := tp.FindMetric(t, "tyk.proxy.upstream.latency")
+	metrictest.AssertHistogramCount(t, latency, 1)
+	metrictest.AssertHistogramSum(t, latency, 0.25)
+
+	metrictest.AssertSumWithAttrs(t, tp.FindMetric(t, "tyk.proxy.circuit_breaker.trips"), int64(1), apiAttrs...)
+	metrictest.AssertSumWithAttrs(t, tp.FindMetric(t, "tyk.proxy.rate_limit.rejections"), int64(2), apiAttrs...)
+}
+
 func TestRecordConfigState_SetsGauges(t *testing.T) {
 	inst, tp := activeProvider(t)
 	ctx := context.Background()
@@ -1,6 +1,7 @@
 package policy
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 
@@ -23,6 +24,9 @@ type Service struct {
 
 	// used for validation if not empty
 	orgID *string
+
+	conflictResolution ConflictResolution
+	limitSources       LimitSources
 }
 
 func New(orgID *string, storage model.PolicyProvider, logger *logrus.Logger) *Service {
@@ -33,6 +37,17 @@ func New(orgID *string, storage model.PolicyProvider, logger *logrus.Logger) *Se
 	}
 }
 
+// WithConflictResolution sets the rule resolving the conflicting limits of the applied policies, MostPermissive by default.
+func (t *Service) WithConflictResolution(resolution ConflictResolution) *Service {
+	t.conflictResolution = resolution
+	return t
+}
+
+// LimitSources returns the policies which set the limits of the key the policies were last applied to.
+func (t *Service) LimitSources() LimitSources {
+	return t.limitSources
+}
+
 // ClearSession clears the quota, rate limit and complexity values so that partitioned policies can apply their values.
 // Otherwise, if the session has already a higher value, an applied policy will not win, and its values will be ignored.
 func (t *Service) ClearSession(session *user.SessionState) error {
@@ -55,12 +70,16 @@ func (t *Service) ClearSession(session *user.SessionState) error {
 			session.Rate = 0
 			session.Per = 0
 			session.Smoothing = nil
+			session.Burst = 0
 			session.ThrottleRetryLimit = 0
 			session.ThrottleInterval = 0
+			session.MaxConcurrentRequests = 0
 		}
 
 		if policy.Partitions.Complexity || all {
 			session.MaxQueryDepth = 0
+			session.MaxQueryNodes = 0
+			session.MaxAliases = 0
 		}
 	}
 
@@ -74,6 +93,7 @@ type applyStatus struct {
 	didComplexity map[string]bool
 	didPerAPI     bool
 	didPartition  bool
+	claims        *limitClaims
 }
 
 // Apply will check if any policies are loaded. If any are, it
@@ -84,6 +104,7 @@ func (t *Service) Apply(session *user.SessionState) error {
 	if session.MetaData == nil {
 		session.MetaData = make(map[string]interface{})
 	}
+	delete(session.MetaData, PartitionConflictsMetaKey)
 
 	if err := t.ClearSession(session); err != nil {
 		t.logger.WithError(err).Warn("error clearing session")
@@ -94,10 +115,13 @@ func (t *Service) Apply(session *user.SessionState) error {
 		didRateLimit:  make(map[string]bool),
 		didAcl:        make(map[string]bool),
 		didComplexity: make(map[string]bool),
+		claims:        newLimitClaims(t.conflictResolution),
 	}
 
 	var (
-		policyIDs []model.PolicyID
+		policyIDs   []model.PolicyID
+		allowedIPs  []string
+		idleTimeout int64
 	)
 
 	storage := t.storage
@@ -158,6 +182,13 @@ func (t *Service) Apply(session *user.SessionState) error {
 			tags[tag] = true
 		}
 
+		allowedIPs = appendIfMissing(allowedIPs, policy.AllowedIPs...)
+
+		// the longest idle timeout wins
+		if policy.IdleTimeout > idleTimeout {
+			idleTimeout = policy.IdleTimeout
+		}
+
 		for k, v := range policy.MetaData {
 			session.MetaData[k] = v
 		}
@@ -176,6 +207,19 @@ func (t *Service) Apply(session *user.SessionState) error {
 
 	session.IsInactive = sessionInactiveState
 
+	t.limitSources = applyState.claims.sources
+	t.recordPartitionConflicts(session, applyState.claims.partitionConflicts())
+
+	// IPs allowed by the policies replace the ones set on the key
+	if len(allowedIPs) > 0 {
+		session.AllowedIPs = allowedIPs
+	}
+
+	// Idle timeouts set by the policies replace the one set on the key
+	if idleTimeout > 0 {
+		session.IdleTimeout = idleTimeout
+	}
+
 	for _, tag := range session.Tags {
 		tags[tag] = true
 	}
@@ -215,13 +259,17 @@ func (t *Service) Apply(session *user.SessionState) error {
 			v.Limit.Rate = session.Rate
 			v.Limit.Per = session.Per
 			v.Limit.Smoothing = session.Smoothing
+			v.Limit.Burst = session.Burst
 			v.Limit.ThrottleInterval = session.ThrottleInterval
 			v.Limit.ThrottleRetryLimit = session.ThrottleRetryLimit
+			v.Limit.MaxConcurrentRequests = session.MaxConcurrentRequests
 			v.Endpoints = nil
 		}
 
 		if !applyState.didComplexity[k] {
 			v.Limit.MaxQueryDepth = session.MaxQueryDepth
+			v.Limit.MaxQueryNodes = session.MaxQueryNodes
+			v.Limit.MaxAliases = session.MaxAliases
 		}
 
 		if !applyState.didQuota[k] {
@@ -262,40 +310,25 @@ func (t *Service) Logger() *logrus.Entry {
 	return logrus.NewEntry(t.logger)
 }
 
-// ApplyRateLimits will write policy limits to session and apiLimits.
-// The limits get written if either are empty.
-// The limits get written if filled and policyLimits allows a higher request rate.
-func (t *Service) ApplyRateLimits(session *user.SessionState, policy user.Policy, apiLimits *user.APILimit) {
+// applyRateLimits writes the rate limit of the policy to apiLimits and to the session when the policy claims them.
+func (t *Service) applyRateLimits(claims *limitClaims, apiID string, session *user.SessionState, policy user.Policy, apiLimits *user.APILimit) {
 	policyLimits := policy.APILimit()
 	if t.emptyRateLimit(policyLimits) {
 		return
 	}
 
-	// duration is time between requests, e.g.:
-	//
-	// apiLimits: 500ms for 2 requests / second
-	// policyLimits: 100ms for 10 requests / second
-	//
-	// if apiLimits > policyLimits (500ms > 100ms) then
-	// we apply the higher rate from the policy.
-	//
-	// the policy-defined rate limits are enforced as
-	// a minimum possible api rate limit setting,
-	// raising apiLimits.
-
-	if t.emptyRateLimit(*apiLimits) || apiLimits.Duration() > policyLimits.Duration() {
+	if claims.claimAPI(apiID, partitionRateLimit, limitRate, policy.ID, compareRateLimits(policyLimits.RateLimit, apiLimits.RateLimit)) {
 		apiLimits.Rate = policyLimits.Rate
 		apiLimits.Per = policyLimits.Per
 		apiLimits.Smoothing = policyLimits.Smoothing
+		apiLimits.Burst = policyLimits.Burst
 	}
 
-	// sessionLimits, similar to apiLimits, get policy
-	// rate applied if the policy allows more requests.
-	sessionLimits := session.APILimit()
-	if t.emptyRateLimit(sessionLimits) || sessionLimits.Duration() > policyLimits.Duration() {
+	if claims.claimSession(partitionRateLimit, limitRate, policy.ID, compareRateLimits(policyLimits.RateLimit, session.APILimit().RateLimit), false) {
 		session.Rate = policyLimits.Rate
 		session.Per = policyLimits.Per
 		session.Smoothing = policyLimits.Smoothing
+		session.Burst = policyLimits.Burst
 	}
 }
 
@@ -502,25 +535,14 @@ func (t *Service) applyPartitions(policy user.Policy, session *user.SessionState
 		if !usePartitions || policy.Partitions.Quota {
 			applyState.didQuota[k] = true
 
-			if greaterThanInt64(policy.QuotaMax, ar.Limit.QuotaMax) {
-				ar.Limit.QuotaMax = policy.QuotaMax
-				if greaterThanInt64(policy.QuotaMax, session.QuotaMax) {
-					session.QuotaMax = policy.QuotaMax
-				}
-			}
-
-			if policy.QuotaRenewalRate > ar.Limit.QuotaRenewalRate {
-				ar.Limit.QuotaRenewalRate = policy.QuotaRenewalRate
-				if policy.QuotaRenewalRate > session.QuotaRenewalRate {
-					session.QuotaRenewalRate = policy.QuotaRenewalRate
-				}
-			}
+			claimLimit(applyState.claims, k, partitionQuota, limitQuotaMax, policy.ID, policy.QuotaMax, &ar.Limit.QuotaMax, &session.QuotaMax, compareLimits)
+			claimLimit(applyState.claims, k, partitionQuota, limitQuotaRenewalRate, policy.ID, policy.QuotaRenewalRate, &ar.Limit.QuotaRenewalRate, &session.QuotaRenewalRate, cmp.Compare)
 		}
 
 		if !usePartitions || policy.Partitions.RateLimit {
 			applyState.didRateLimit[k] = true
 
-			t.ApplyRateLimits(session, policy, &ar.Limit)
+			t.applyRateLimits(applyState.claims, k, session, policy, &ar.Limit)
 
 			if rightsAR, ok := rights[k]; ok {
 				ar.Endpoints = t.ApplyEndpointLevelLimits(v.Endpoints, rightsAR.Endpoints)
@@ -528,30 +550,17 @@ func (t *Service) applyPartitions(policy user.Policy, session *user.SessionState
 				ar.MCPPrimitives = t.ApplyMCPPrimitiveLimits(v.MCPPrimitives, rightsAR.MCPPrimitives)
 			}
 
-			if policy.ThrottleRetryLimit > ar.Limit.ThrottleRetryLimit {
-				ar.Limit.ThrottleRetryLimit = policy.ThrottleRetryLimit
-				if policy.ThrottleRetryLimit > session.ThrottleRetryLimit {
-					session.ThrottleRetryLimit = policy.ThrottleRetryLimit
-				}
-			}
-
-			if policy.ThrottleInterval > ar.Limit.ThrottleInterval {
-				ar.Limit.ThrottleInterval = policy.ThrottleInterval
-				if policy.ThrottleInterval > session.ThrottleInterval {
-					session.ThrottleInterval = policy.ThrottleInterval
-				}
-			}
+			claimLimit(applyState.claims, k, partitionRateLimit, limitThrottleRetryLimit, policy.ID, policy.ThrottleRetryLimit, &ar.Limit.ThrottleRetryLimit, &session.ThrottleRetryLimit, cmp.Compare)
+			claimLimit(applyState.claims, k, partitionRateLimit, limitThrottleInterval, policy.ID, policy.ThrottleInterval, &ar.Limit.ThrottleInterval, &session.ThrottleInterval, cmp.Compare)
+			claimLimit(applyState.claims, k, partitionRateLimit, limitMaxConcurrentRequests, policy.ID, policy.MaxConcurrentRequests, &ar.Limit.MaxConcurrentRequests, &session.MaxConcurrentRequests, compareLimits)
 		}
 
 		if !usePartitions || policy.Partitions.Complexity {
 			applyState.didComplexity[k] = true
 
-			if greaterThanInt(policy.MaxQueryDepth, ar.Limit.MaxQueryDepth) {
-				ar.Limit.MaxQueryDepth = policy.MaxQueryDepth
-				if greaterThanInt(policy.MaxQueryDepth, session.MaxQueryDepth) {
-					session.MaxQueryDepth = policy.MaxQueryDepth
-				}
-			}
+			claimLimit(applyState.claims, k, partitionComplexity, limitMaxQueryDepth, policy.ID, policy.MaxQueryDepth, &ar.Limit.MaxQueryDepth, &session.MaxQueryDepth, compareLimits)
+			claimLimit(applyState.claims, k, partitionComplexity, limitMaxQueryNodes, policy.ID, policy.MaxQueryNodes, &ar.Limit.MaxQueryNodes, &session.MaxQueryNodes, compareLimits)
+			claimLimit(applyState.claims, k, partitionComplexity, limitMaxAliases, policy.ID, policy.MaxAliases, &ar.Limit.MaxAliases, &session.MaxAliases, compareLimits)
 		}
 
 		// Respect existing QuotaRenews
@@ -564,21 +573,31 @@ func (t *Service) applyPartitions(policy user.Policy, session *user.SessionState
 
 	// Master policy case
 	if len(policy.AccessRights) == 0 {
+		claims := applyState.claims
+
 		if !usePartitions || policy.Partitions.RateLimit {
-			session.Rate = policy.Rate
-			session.Per = policy.Per
-			session.Smoothing = policy.Smoothing
-			session.ThrottleInterval = policy.ThrottleInterval
-			session.ThrottleRetryLimit = policy.ThrottleRetryLimit
+			policyLimits := policy.APILimit()
+			if claims.claimSession(partitionRateLimit, limitRate, policy.ID, compareRateLimits(policyLimits.RateLimit, session.APILimit().RateLimit), true) {
+				session.Rate = policy.Rate
+				session.Per = policy.Per
+				session.Smoothing = policy.Smoothing
+				session.Burst = policy.Burst
+			}
+
+			claimSessionLimit(claims, partitionRateLimit, limitThrottleInterval, policy.ID, policy.ThrottleInterval, &session.ThrottleInterval, cmp.Compare)
+			claimSessionLimit(claims, partitionRateLimit, limitThrottleRetryLimit, policy.ID, policy.ThrottleRetryLimit, &session.ThrottleRetryLimit, cmp.Compare)
+			claimSessionLimit(claims, partitionRateLimit, limitMaxConcurrentRequests, policy.ID, policy.MaxConcurrentRequests, &session.MaxConcurrentRequests, compareLimits)
 		}
 
 		if !usePartitions || policy.Partitions.Complexity {
-			session.MaxQueryDepth = policy.MaxQueryDepth
+			claimSessionLimit(claims, partitionComplexity, limitMaxQueryDepth, policy.ID, policy.MaxQueryDepth, &session.MaxQueryDepth, compareLimits)
+			claimSessionLimit(claims, partitionComplexity, limitMaxQueryNodes, policy.ID, policy.MaxQueryNodes, &session.MaxQueryNodes, compareLimits)
+			claimSessionLimit(claims, partitionComplexity, limitMaxAliases, policy.ID, policy.MaxAliases, &session.MaxAliases, compareLimits)
 		}
 
 		if !usePartitions || policy.Partitions.Quota {
-			session.QuotaMax = policy.QuotaMax
-			session.QuotaRenewalRate = policy.QuotaRenewalRate
+			claimSessionLimit(claims, partitionQuota, limitQuotaMax, policy.ID, policy.QuotaMax, &session.QuotaMax, compareLimits)
+			claimSessionLimit(claims, partitionQuota, limitQuotaRenewalRate, policy.ID, policy.QuotaRenewalRate, &session.QuotaRenewalRate, cmp.Compare)
 		}
 	}
 
@@ -602,6 +621,8 @@ func (t *Service) updateSessionRootVars(session *user.SessionState, rights map[s
 				session.Rate = v.Limit.Rate
 				session.Per = v.Limit.Per
 				session.Smoothing = v.Limit.Smoothing
+				session.Burst = v.Limit.Burst
+				session.MaxConcurrentRequests = v.Limit.MaxConcurrentRequests
 			}
 
 			if len(applyState.didQuota) == 1 {
@@ -612,6 +633,8 @@ func (t *Service) updateSessionRootVars(session *user.SessionState, rights map[s
 
 			if len(applyState.didComplexity) == 1 {
 				session.MaxQueryDepth = v.Limit.MaxQueryDepth
+				session.MaxQueryNodes = v.Limit.MaxQueryNodes
+				session.MaxAliases = v.Limit.MaxAliases
 			}
 		}
 	}
@@ -623,6 +646,7 @@ func (t *Service) applyAPILevelLimits(policyAD user.AccessDefinition, currAD use
 		policyAD.Limit.Per = currAD.Limit.Per
 		policyAD.Limit.Rate = currAD.Limit.Rate
 		policyAD.Limit.Smoothing = currAD.Limit.Smoothing
+		policyAD.Limit.Burst = currAD.Limit.Burst
 		updated = true
 	}
 
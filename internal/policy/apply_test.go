@@ -22,101 +22,80 @@ import (
 var testDataFS embed.FS
 
 func TestApplyRateLimits_PolicyLimits(t *testing.T) {
-	t.Run("policy limits unset", func(t *testing.T) {
-		svc := &policy.Service{}
+	svc := &policy.Service{}
 
+	rateLimit := func(rate float64) user.APILimit {
+		return user.APILimit{RateLimit: user.RateLimit{Rate: rate, Per: 10}}
+	}
+	ratePolicy := func(id string, rate float64) user.Policy {
+		return user.Policy{ID: id, Rate: rate, Per: 10}
+	}
+
+	t.Run("policy limits unset", func(t *testing.T) {
 		session := &user.SessionState{
 			Rate: 5,
 			Per:  10,
 		}
-		apiLimits := user.APILimit{
-			RateLimit: user.RateLimit{
-				Rate: 10,
-				Per:  10,
-			},
-		}
-		policy := user.Policy{}
+		apiLimits := rateLimit(10)
 
-		svc.ApplyRateLimits(session, policy, &apiLimits)
+		svc.ApplyRateLimits(policy.NewLimitClaims(policy.MostPermissive), "a", session, user.Policy{}, &apiLimits)
 
 		assert.Equal(t, 10, int(apiLimits.Rate))
 		assert.Equal(t, 5, int(session.Rate))
 	})
 
-	t.Run("policy limits apply all", func(t *testing.T) {
-		svc := &policy.Service{}
-
+	// The first policy claims the limits, whatever the limits were before the policies were applied.
+	t.Run("first policy applies all", func(t *testing.T) {
 		session := &user.SessionState{
-			Rate: 5,
-			Per:  10,
-		}
-		apiLimits := user.APILimit{
-			RateLimit: user.RateLimit{
-				Rate: 5,
-				Per:  10,
-			},
-		}
-		policy := user.Policy{
-			Rate: 10,
+			Rate: 15,
 			Per:  10,
 		}
+		apiLimits := rateLimit(5)
 
-		svc.ApplyRateLimits(session, policy, &apiLimits)
+		svc.ApplyRateLimits(policy.NewLimitClaims(policy.MostPermissive), "a", session, ratePolicy("pol1", 10), &apiLimits)
 
 		assert.Equal(t, 10, int(apiLimits.Rate))
 		assert.Equal(t, 10, int(session.Rate))
 	})
 
-	// As the policy defined a higher rate than apiLimits,
-	// changes are applied to api limits, but skipped on
-	// the session as the session has a higher allowance.
-	t.Run("policy limits apply per-api", func(t *testing.T) {
-		svc := &policy.Service{}
-
-		session := &user.SessionState{
-			Rate: 15,
-			Per:  10,
-		}
-		apiLimits := user.APILimit{
-			RateLimit: user.RateLimit{
-				Rate: 5,
-				Per:  10,
-			},
-		}
-		policy := user.Policy{
-			Rate: 10,
-			Per:  10,
-		}
+	t.Run("most permissive policy wins", func(t *testing.T) {
+		claims := policy.NewLimitClaims(policy.MostPermissive)
+		session := &user.SessionState{}
+		apiLimits := user.APILimit{}
 
-		svc.ApplyRateLimits(session, policy, &apiLimits)
+		svc.ApplyRateLimits(claims, "a", session, ratePolicy("pol1", 10), &apiLimits)
+		svc.ApplyRateLimits(claims, "a", session, ratePolicy("pol2", 15), &apiLimits)
+		svc.ApplyRateLimits(claims, "a", session, ratePolicy("pol3", 5), &apiLimits)
 
-		assert.Equal(t, 10, int(apiLimits.Rate))
+		assert.Equal(t, 15, int(apiLimits.Rate))
 		assert.Equal(t, 15, int(session.Rate))
 	})
 
-	// As the policy defined a lower rate than apiLimits,
-	// no changes to api limits are applied.
-	t.Run("policy limits skip", func(t *testing.T) {
-		svc := &policy.Service{}
+	t.Run("most restrictive policy wins", func(t *testing.T) {
+		claims := policy.NewLimitClaims(policy.MostRestrictive)
+		session := &user.SessionState{}
+		apiLimits := user.APILimit{}
 
-		session := &user.SessionState{
-			Rate: 5,
-			Per:  10,
-		}
-		apiLimits := user.APILimit{
-			RateLimit: user.RateLimit{Rate: 15,
-				Per: 10,
-			},
-		}
-		policy := user.Policy{
-			Rate: 10,
-			Per:  10,
-		}
+		svc.ApplyRateLimits(claims, "a", session, ratePolicy("pol1", 10), &apiLimits)
+		svc.ApplyRateLimits(claims, "a", session, ratePolicy("pol2", 15), &apiLimits)
+		svc.ApplyRateLimits(claims, "a", session, ratePolicy("pol3", 5), &apiLimits)
 
-		svc.ApplyRateLimits(session, policy, &apiLimits)
+		assert.Equal(t, 5, int(apiLimits.Rate))
+		assert.Equal(t, 5, int(session.Rate))
+	})
 
-		assert.Equal(t, 15, int(apiLimits.Rate))
-		assert.Equal(t, 10, int(session.Rate))
+	// The limits of the APIs are claimed separately, the session limits are claimed once for all of them.
+	t.Run("per-api claims", func(t *testing.T) {
+		claims := policy.NewLimitClaims(policy.MostPermissive)
+		session := &user.SessionState{}
+		limitsA, limitsB := user.APILimit{}, user.APILimit{}
+
+		svc.ApplyRateLimits(claims, "a", session, ratePolicy("pol1", 15), &limitsA)
+		svc.ApplyRateLimits(claims, "b", session, ratePolicy("pol2", 10), &limitsB)
+
+		assert.Equal(t, 15, int(limitsA.Rate))
+		assert.Equal(t, 10, int(limitsB.Rate))
+		assert.Equal(t, 15, int(session.Rate))
 	})
 }
 
@@ -387,6 +366,7 @@ func testPrepareApplyPolicies(tb testing.TB) (*policy.Service, []testApplyPolici
 				if s.Rate != 3 {
 					t.Fatalf("want Rate to be 3")
 				}
+				assert.Equal(t, 10, s.MaxConcurrentRequests)
 			}, nil, false,
 		},
 		{
@@ -396,6 +376,8 @@ func testPrepareApplyPolicies(tb testing.TB) (*policy.Service, []testApplyPolici
 				if s.Rate != 4 {
 					t.Fatalf("Should pick bigger value")
 				}
+				assert.Equal(t, 10, s.MaxConcurrentRequests)
+				assert.Equal(t, 10, s.AccessRights["a"].Limit.MaxConcurrentRequests)
 			}, nil, false,
 		},
 		{
@@ -439,6 +421,8 @@ func testPrepareApplyPolicies(tb testing.TB) (*policy.Service, []testApplyPolici
 				if s.MaxQueryDepth != 2 {
 					t.Fatalf("want MaxQueryDepth to be 2")
 				}
+				assert.Equal(t, 50, s.MaxQueryNodes)
+				assert.Equal(t, 5, s.MaxAliases)
 			}, nil, false,
 		},
 		{
@@ -448,6 +432,10 @@ func testPrepareApplyPolicies(tb testing.TB) (*policy.Service, []testApplyPolici
 				if s.MaxQueryDepth != 3 {
 					t.Fatalf("Should pick bigger value")
 				}
+				assert.Equal(t, 50, s.MaxQueryNodes)
+				assert.Equal(t, 10, s.MaxAliases)
+				assert.Equal(t, 50, s.AccessRights["a"].Limit.MaxQueryNodes)
+				assert.Equal(t, 10, s.AccessRights["a"].Limit.MaxAliases)
 			}, nil, false,
 		},
 	}
@@ -889,6 +877,58 @@ func testPrepareApplyPolicies(tb testing.TB) (*policy.Service, []testApplyPolici
 	}
 	tests = append(tests, tagsTCs...)
 
+	allowedIPsTCs := []testApplyPoliciesData{
+		{
+			name:     "Merge allowed IPs from policies",
+			policies: []string{"allowed-ips1", "allowed-ips2"},
+			sessMatch: func(t *testing.T, s *user.SessionState) {
+				t.Helper()
+				assert.Equal(t, []string{"10.0.0.0/8", "2001:db8::/32", "192.168.1.10"}, s.AllowedIPs)
+			},
+			session: &user.SessionState{
+				AllowedIPs: []string{"172.16.0.1"},
+			},
+		},
+		{
+			name:     "Keep key allowed IPs when policies don't set any",
+			policies: []string{"tags1"},
+			sessMatch: func(t *testing.T, s *user.SessionState) {
+				t.Helper()
+				assert.Equal(t, []string{"172.16.0.1"}, s.AllowedIPs)
+			},
+			session: &user.SessionState{
+				AllowedIPs: []string{"172.16.0.1"},
+			},
+		},
+	}
+	tests = append(tests, allowedIPsTCs...)
+
+	idleTimeoutTCs := []testApplyPoliciesData{
+		{
+			name:     "Longest idle timeout of the policies wins",
+			policies: []string{"idle-timeout1", "idle-timeout2"},
+			sessMatch: func(t *testing.T, s *user.SessionState) {
+				t.Helper()
+				assert.Equal(t, int64(2592000), s.IdleTimeout)
+			},
+			session: &user.SessionState{
+				IdleTimeout: 60,
+			},
+		},
+		{
+			name:     "Keep key idle timeout when policies don't set any",
+			policies: []string{"tags1"},
+			sessMatch: func(t *testing.T, s *user.SessionState) {
+				t.Helper()
+				assert.Equal(t, int64(60), s.IdleTimeout)
+			},
+			session: &user.SessionState{
+				IdleTimeout: 60,
+			},
+		},
+	}
+	tests = append(tests, idleTimeoutTCs...)
+
 	partitionTCs := []testApplyPoliciesData{
 		{
 			"NonpartAndPart", []string{"nonpart1", "quota1"},
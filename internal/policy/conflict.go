@@ -0,0 +1,225 @@
+package policy
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// ConflictResolution is the rule resolving the conflicts between the policies applied to a key,
+// when they claim the same partition of an API with different limits.
+//
+// Each limit is resolved on its own, -1 being unlimited. The rate limits are compared by the
+// duration between two allowed requests, then by their rate and burst. The limits set to equal
+// values by several policies are attributed to the policy with the lowest ID.
+type ConflictResolution string
+
+const (
+	// MostPermissive keeps the highest of the conflicting limits. It's the default.
+	MostPermissive ConflictResolution = "most_permissive"
+	// MostRestrictive keeps the lowest of the conflicting limits.
+	MostRestrictive ConflictResolution = "most_restrictive"
+)
+
+// PartitionConflictsMetaKey is the session metadata key the partition conflicts are recorded under.
+const PartitionConflictsMetaKey = "tyk_policy_partition_conflicts"
+
+// The partitions of the limits.
+const (
+	partitionQuota      = "quota"
+	partitionRateLimit  = "rate_limit"
+	partitionComplexity = "complexity"
+)
+
+// The names of the limits, as encoded in user.APILimit. The rate limit is named after its rate.
+const (
+	limitRate                  = "rate"
+	limitThrottleInterval      = "throttle_interval"
+	limitThrottleRetryLimit    = "throttle_retry_limit"
+	limitMaxConcurrentRequests = "max_concurrent_requests"
+	limitQuotaMax              = "quota_max"
+	limitQuotaRenewalRate      = "quota_renewal_rate"
+	limitMaxQueryDepth         = "max_query_depth"
+	limitMaxQueryNodes         = "max_query_nodes"
+	limitMaxAliases            = "max_aliases"
+)
+
+// PartitionConflict is a limit claimed with different values by several policies applied to a key.
+type PartitionConflict struct {
+	// APIID is the API of the limit, empty for the limits of the session.
+	APIID     string `json:"api_id,omitempty"`
+	Partition string `json:"partition"`
+	Limit     string `json:"limit"`
+	// Policies are the IDs of the conflicting policies.
+	Policies   []string           `json:"policies"`
+	Resolution ConflictResolution `json:"resolution"`
+	// AppliedPolicy is the ID of the policy whose limit is applied.
+	AppliedPolicy string `json:"applied_policy"`
+}
+
+// LimitSources records the policies which set the effective limits of a key.
+type LimitSources struct {
+	// Session maps the limits of the session to the IDs of the policies which set them.
+	Session map[string]string `json:"session,omitempty"`
+	// APIs maps the IDs of the APIs to their limits and the IDs of the policies which set them.
+	APIs map[string]map[string]string `json:"apis,omitempty"`
+}
+
+// limitClaims tracks the policies claiming the limits of a key while they're applied.
+type limitClaims struct {
+	resolution ConflictResolution
+	sources    LimitSources
+	conflicts  map[[2]string]*PartitionConflict
+}
+
+func newLimitClaims(resolution ConflictResolution) *limitClaims {
+	if resolution != MostRestrictive {
+		resolution = MostPermissive
+	}
+
+	return &limitClaims{
+		resolution: resolution,
+		sources: LimitSources{
+			Session: make(map[string]string),
+			APIs:    make(map[string]map[string]string),
+		},
+		conflicts: make(map[[2]string]*PartitionConflict),
+	}
+}
+
+// claimAPI reports whether the policy sets the limit of the API. order compares the limit of the
+// policy to the current one: positive if it's more permissive, negative if it's more restrictive.
+// A policy claiming a limit already set by another policy with a different value is a conflict.
+func (c *limitClaims) claimAPI(apiID, partition, limit, policyID string, order int) bool {
+	sources, ok := c.sources.APIs[apiID]
+	if !ok {
+		sources = make(map[string]string)
+		c.sources.APIs[apiID] = sources
+	}
+
+	return c.claim(sources, apiID, partition, limit, policyID, order, true)
+}
+
+// claimSession reports whether the policy sets the limit of the session. The limits set by policies without
+// access rights conflict, the session limits aggregated from the access rights of the policies don't.
+func (c *limitClaims) claimSession(partition, limit, policyID string, order int, conflicts bool) bool {
+	return c.claim(c.sources.Session, "", partition, limit, policyID, order, conflicts)
+}
+
+func (c *limitClaims) claim(sources map[string]string, apiID, partition, limit, policyID string, order int, conflicts bool) bool {
+	current, ok := sources[limit]
+	if !ok {
+		sources[limit] = policyID
+		return true
+	}
+
+	if order == 0 {
+		if policyID < current {
+			sources[limit] = policyID
+		}
+		return false
+	}
+
+	wins := order > 0
+	if c.resolution == MostRestrictive {
+		wins = order < 0
+	}
+
+	if wins {
+		sources[limit] = policyID
+	}
+
+	if conflicts && current != policyID {
+		conflict, ok := c.conflicts[[2]string{apiID, limit}]
+		if !ok {
+			conflict = &PartitionConflict{
+				APIID:      apiID,
+				Partition:  partition,
+				Limit:      limit,
+				Policies:   []string{current},
+				Resolution: c.resolution,
+			}
+			c.conflicts[[2]string{apiID, limit}] = conflict
+		}
+
+		if !slices.Contains(conflict.Policies, policyID) {
+			conflict.Policies = append(conflict.Policies, policyID)
+			slices.Sort(conflict.Policies)
+		}
+		conflict.AppliedPolicy = sources[limit]
+	}
+
+	return wins
+}
+
+// partitionConflicts returns the conflicts sorted by API and limit.
+func (c *limitClaims) partitionConflicts() []PartitionConflict {
+	conflicts := make([]PartitionConflict, 0, len(c.conflicts))
+	for _, conflict := range c.conflicts {
+		conflicts = append(conflicts, *conflict)
+	}
+
+	slices.SortFunc(conflicts, func(a, b PartitionConflict) int {
+		return cmp.Or(cmp.Compare(a.APIID, b.APIID), cmp.Compare(a.Limit, b.Limit))
+	})
+
+	return conflicts
+}
+
+// recordPartitionConflicts records the conflicts in the session metadata and logs them.
+func (t *Service) recordPartitionConflicts(session *user.SessionState, conflicts []PartitionConflict) {
+	if len(conflicts) == 0 {
+		return
+	}
+
+	session.MetaData[PartitionConflictsMetaKey] = conflicts
+
+	for _, conflict := range conflicts {
+		t.Logger().WithFields(logrus.Fields{
+			"api_id":         conflict.APIID,
+			"partition":      conflict.Partition,
+			"limit":          conflict.Limit,
+			"policies":       conflict.Policies,
+			"resolution":     conflict.Resolution,
+			"applied_policy": conflict.AppliedPolicy,
+		}).Warning("Policies applied to the key set conflicting limits")
+	}
+}
+
+// claimLimit sets the limit of the API and the limit of the session to the value of the policy when it claims them.
+func claimLimit[T any](claims *limitClaims, apiID, partition, limit, policyID string, value T, apiLimit, sessionLimit *T, compare func(T, T) int) {
+	if claims.claimAPI(apiID, partition, limit, policyID, compare(value, *apiLimit)) {
+		*apiLimit = value
+	}
+
+	if claims.claimSession(partition, limit, policyID, compare(value, *sessionLimit), false) {
+		*sessionLimit = value
+	}
+}
+
+// claimSessionLimit sets the limit of the session to the value of a policy without access rights when it claims it.
+func claimSessionLimit[T any](claims *limitClaims, partition, limit, policyID string, value T, sessionLimit *T, compare func(T, T) int) {
+	if claims.claimSession(partition, limit, policyID, compare(value, *sessionLimit), true) {
+		*sessionLimit = value
+	}
+}
+
+// compareLimits compares two limits where -1 is unlimited.
+func compareLimits[T int | int64](a, b T) int {
+	switch {
+	case a == b:
+		return 0
+	case greaterThanInt64(int64(a), int64(b)):
+		return 1
+	default:
+		return -1
+	}
+}
+
+// compareRateLimits compares two rate limits, the rate limit allowing requests more often being the more permissive.
+func compareRateLimits(a, b user.RateLimit) int {
+	return cmp.Or(cmp.Compare(b.Duration(), a.Duration()), cmp.Compare(a.Rate, b.Rate), cmp.Compare(a.Burst, b.Burst))
+}
@@ -0,0 +1,145 @@
+package policy_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/internal/policy"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestApply_PartitionConflicts(t *testing.T) {
+	api1 := map[string]user.AccessDefinition{"api1": {APIID: "api1", Versions: []string{"Default"}}}
+
+	policies := map[string]user.Policy{
+		"acl": {ID: "acl", Partitions: user.PolicyPartitions{Acl: true}, AccessRights: api1},
+		"rate-high": {
+			ID: "rate-high", Partitions: user.PolicyPartitions{RateLimit: true}, AccessRights: api1,
+			Rate: 100, Per: 60, ThrottleRetryLimit: 5, ThrottleInterval: 1,
+		},
+		"rate-low": {
+			ID: "rate-low", Partitions: user.PolicyPartitions{RateLimit: true}, AccessRights: api1,
+			Rate: 10, Per: 60, ThrottleRetryLimit: 5, ThrottleInterval: 1,
+		},
+		"quota-limited": {
+			ID: "quota-limited", Partitions: user.PolicyPartitions{Quota: true}, AccessRights: api1,
+			QuotaMax: 1000, QuotaRenewalRate: 3600,
+		},
+		"quota-unlimited": {
+			ID: "quota-unlimited", Partitions: user.PolicyPartitions{Quota: true}, AccessRights: api1,
+			QuotaMax: -1, QuotaRenewalRate: 3600,
+		},
+		"master-high": {ID: "master-high", Partitions: user.PolicyPartitions{Complexity: true}, MaxQueryDepth: 10},
+		"master-low":  {ID: "master-low", Partitions: user.PolicyPartitions{Complexity: true}, MaxQueryDepth: 2},
+	}
+	ids := []string{"acl", "rate-high", "rate-low", "quota-limited", "quota-unlimited", "master-high", "master-low"}
+
+	apply := func(t *testing.T, resolution policy.ConflictResolution, ids []string) (*user.SessionState, policy.LimitSources) {
+		t.Helper()
+
+		svc := policy.New(nil, policy.NewStoreMap(policies), logrus.StandardLogger()).WithConflictResolution(resolution)
+
+		session := &user.SessionState{}
+		session.SetPolicies(ids...)
+		require.NoError(t, svc.Apply(session))
+
+		return session, svc.LimitSources()
+	}
+
+	conflict := func(apiID, partition, limit string, resolution policy.ConflictResolution, applied string, policies ...string) policy.PartitionConflict {
+		return policy.PartitionConflict{
+			APIID:         apiID,
+			Partition:     partition,
+			Limit:         limit,
+			Policies:      policies,
+			Resolution:    resolution,
+			AppliedPolicy: applied,
+		}
+	}
+
+	testCases := []struct {
+		resolution    policy.ConflictResolution
+		rate          float64
+		quotaMax      int64
+		maxQueryDepth int
+		conflicts     []policy.PartitionConflict
+		sources       map[string]string
+	}{
+		{
+			resolution:    policy.MostPermissive,
+			rate:          100,
+			quotaMax:      -1,
+			maxQueryDepth: 10,
+			conflicts: []policy.PartitionConflict{
+				conflict("", "complexity", "max_query_depth", policy.MostPermissive, "master-high", "master-high", "master-low"),
+				conflict("api1", "quota", "quota_max", policy.MostPermissive, "quota-unlimited", "quota-limited", "quota-unlimited"),
+				conflict("api1", "rate_limit", "rate", policy.MostPermissive, "rate-high", "rate-high", "rate-low"),
+			},
+			sources: map[string]string{
+				"rate": "rate-high", "throttle_retry_limit": "rate-high", "throttle_interval": "rate-high", "max_concurrent_requests": "rate-high",
+				"quota_max": "quota-unlimited", "quota_renewal_rate": "quota-limited",
+			},
+		},
+		{
+			resolution:    policy.MostRestrictive,
+			rate:          10,
+			quotaMax:      1000,
+			maxQueryDepth: 2,
+			conflicts: []policy.PartitionConflict{
+				conflict("", "complexity", "max_query_depth", policy.MostRestrictive, "master-low", "master-high", "master-low"),
+				conflict("api1", "quota", "quota_max", policy.MostRestrictive, "quota-limited", "quota-limited", "quota-unlimited"),
+				conflict("api1", "rate_limit", "rate", policy.MostRestrictive, "rate-low", "rate-high", "rate-low"),
+			},
+			sources: map[string]string{
+				"rate": "rate-low", "throttle_retry_limit": "rate-high", "throttle_interval": "rate-high", "max_concurrent_requests": "rate-high",
+				"quota_max": "quota-limited", "quota_renewal_rate": "quota-limited",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.resolution), func(t *testing.T) {
+			session, sources := apply(t, tc.resolution, ids)
+
+			limit := session.AccessRights["api1"].Limit
+			assert.Equal(t, tc.rate, limit.Rate)
+			assert.Equal(t, float64(60), limit.Per)
+			assert.Equal(t, tc.quotaMax, limit.QuotaMax)
+			assert.Equal(t, tc.maxQueryDepth, session.MaxQueryDepth)
+			assert.Equal(t, tc.conflicts, session.MetaData[policy.PartitionConflictsMetaKey])
+			assert.Equal(t, tc.sources, sources.APIs["api1"])
+
+			// The policies applied in the reverse order give the same limits.
+			reversed := slices.Clone(ids)
+			slices.Reverse(reversed)
+			reversedSession, reversedSources := apply(t, tc.resolution, reversed)
+
+			assert.Equal(t, session.AccessRights, reversedSession.AccessRights)
+			assert.Equal(t, session.APILimit(), reversedSession.APILimit())
+			assert.Equal(t, session.MetaData, reversedSession.MetaData)
+			assert.Equal(t, sources, reversedSources)
+		})
+	}
+
+	t.Run("policies without conflicts", func(t *testing.T) {
+		session, sources := apply(t, policy.MostRestrictive, []string{"acl", "rate-low", "quota-limited"})
+
+		assert.NotContains(t, session.MetaData, policy.PartitionConflictsMetaKey)
+		assert.Equal(t, "rate-low", sources.APIs["api1"]["rate"])
+		assert.Equal(t, "quota-limited", sources.APIs["api1"]["quota_max"])
+	})
+
+	t.Run("conflicts are recorded again on every application", func(t *testing.T) {
+		session, _ := apply(t, policy.MostPermissive, ids)
+
+		svc := policy.New(nil, policy.NewStoreMap(policies), logrus.StandardLogger())
+		session.SetPolicies("acl", "rate-low")
+		require.NoError(t, svc.Apply(session))
+
+		assert.NotContains(t, session.MetaData, policy.PartitionConflictsMetaKey)
+	})
+}
@@ -0,0 +1,16 @@
+package policy
+
+import "github.com/TykTechnologies/tyk/user"
+
+// LimitClaims exposes the limit claims to the tests.
+type LimitClaims = limitClaims
+
+// NewLimitClaims returns the claims of a single application of policies, resolved by resolution.
+func NewLimitClaims(resolution ConflictResolution) *LimitClaims {
+	return newLimitClaims(resolution)
+}
+
+// ApplyRateLimits applies the rate limit of the policy to the API and the session with the claims.
+func (t *Service) ApplyRateLimits(claims *LimitClaims, apiID string, session *user.SessionState, policy user.Policy, apiLimits *user.APILimit) {
+	t.applyRateLimits(claims, apiID, session, policy, apiLimits)
+}
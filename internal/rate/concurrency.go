@@ -0,0 +1,115 @@
+package rate
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/TykTechnologies/tyk/internal/redis"
+	"github.com/TykTechnologies/tyk/internal/uuid"
+)
+
+var concurrencyAcquire = mustScript("scripts/concurrency_acquire.lua")
+
+// Concurrency limits the number of requests in flight for each key, counting
+// the requests of a single gateway in memory.
+type Concurrency struct {
+	mu       sync.Mutex
+	inFlight map[string]int
+}
+
+// NewConcurrency creates a new in memory Concurrency limiter.
+func NewConcurrency() *Concurrency {
+	return &Concurrency{
+		inFlight: make(map[string]int),
+	}
+}
+
+// Acquire takes a slot for key if less than limit requests are in flight and reports
+// if it did. The returned function releases the slot, calling it again has no effect.
+func (c *Concurrency) Acquire(key string, limit int) (func(), bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.inFlight[key] >= limit {
+		return nil, false
+	}
+
+	c.inFlight[key]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.release(key)
+		})
+	}, true
+}
+
+// InFlight returns the number of requests in flight for key.
+func (c *Concurrency) InFlight(key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.inFlight[key]
+}
+
+func (c *Concurrency) release(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inFlight[key]--
+	if c.inFlight[key] <= 0 {
+		delete(c.inFlight, key)
+	}
+}
+
+// ConcurrencyRedis limits the number of requests in flight for each key across
+// gateways. Each request in flight holds a lease in a sorted set stored in redis,
+// updated atomically by a lua script.
+type ConcurrencyRedis struct {
+	conn     redis.UniversalClient
+	leaseTTL time.Duration
+}
+
+// NewConcurrencyRedis creates a new ConcurrencyRedis instance with a redis.UniversalClient.
+// Leases which aren't released, e.g. when a gateway stops while serving requests,
+// expire after leaseTTL so their slots aren't held forever.
+func NewConcurrencyRedis(conn redis.UniversalClient, leaseTTL time.Duration) *ConcurrencyRedis {
+	return &ConcurrencyRedis{
+		conn:     conn,
+		leaseTTL: leaseTTL,
+	}
+}
+
+// Acquire takes a lease for key if less than limit requests are in flight and reports
+// if it did. The returned function releases the lease, calling it again has no effect.
+// The lease is released ignoring the cancellation of ctx, so cancelled requests release their lease too.
+// In case an error occurs, no lease is taken and the caller decides whether to allow the request.
+func (c *ConcurrencyRedis) Acquire(ctx context.Context, now time.Time, key string, limit int) (func(), bool, error) {
+	lease := uuid.New()
+
+	res, err := concurrencyAcquire.Run(
+		ctx, c.conn, []string{key},
+		now.UnixMilli(),
+		c.leaseTTL.Milliseconds(),
+		limit,
+		lease,
+	).Int64()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if res != 1 {
+		return nil, false, nil
+	}
+
+	releaseCtx := context.WithoutCancel(ctx)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			// A lease which failed to be removed expires with its TTL.
+			_ = c.conn.ZRem(releaseCtx, key, lease).Err()
+		})
+	}, true, nil
+}
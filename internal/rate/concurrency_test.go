@@ -0,0 +1,100 @@
+package rate_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/internal/rate"
+	"github.com/TykTechnologies/tyk/internal/uuid"
+)
+
+func TestConcurrency_Acquire(t *testing.T) {
+	limiter := rate.NewConcurrency()
+
+	release1, ok := limiter.Acquire("key", 2)
+	require.True(t, ok)
+	release2, ok := limiter.Acquire("key", 2)
+	require.True(t, ok)
+
+	_, ok = limiter.Acquire("key", 2)
+	assert.False(t, ok, "third request should be rejected")
+
+	_, ok = limiter.Acquire("other", 2)
+	assert.True(t, ok, "keys should be limited independently")
+
+	// Releasing twice frees a single slot.
+	release1()
+	release1()
+	assert.Equal(t, 1, limiter.InFlight("key"))
+
+	_, ok = limiter.Acquire("key", 2)
+	assert.True(t, ok)
+
+	release2()
+	assert.Equal(t, 1, limiter.InFlight("key"))
+}
+
+func TestConcurrency_Parallel(t *testing.T) {
+	limiter := rate.NewConcurrency()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if release, ok := limiter.Acquire("key", 10); ok {
+				release()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 0, limiter.InFlight("key"))
+}
+
+func TestConcurrencyRedis_Acquire(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	conn := newTokenBucketConn(t)
+	key := "concurrency-" + uuid.New()
+	now := time.Now()
+
+	// Two gateways sharing the limit.
+	gateways := []*rate.ConcurrencyRedis{
+		rate.NewConcurrencyRedis(conn, time.Minute),
+		rate.NewConcurrencyRedis(conn, time.Minute),
+	}
+
+	release1, ok, err := gateways[0].Acquire(ctx, now, key, 2)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, ok, err = gateways[1].Acquire(ctx, now, key, 2)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, ok, err = gateways[1].Acquire(ctx, now, key, 2)
+	require.NoError(t, err)
+	assert.False(t, ok, "limit should be shared between gateways")
+
+	// Leases are released after the request context is cancelled.
+	cancel()
+	release1()
+	release1()
+
+	release, ok, err := gateways[1].Acquire(context.Background(), now, key, 2)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	release()
+
+	// Leases which weren't released expire.
+	_, ok, err = gateways[0].Acquire(context.Background(), now.Add(time.Minute+time.Second), key, 1)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
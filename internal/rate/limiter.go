@@ -30,6 +30,12 @@ func Limiter(gwConfig *config.Config, rClient redis.UniversalClient) limiter.Fun
 	return nil
 }
 
+// LimiterKind returns the kind of rate limiter enabled by config, or an empty string if none is.
+func LimiterKind(gwConfig *config.Config) string {
+	name, _ := limiterKind(gwConfig)
+	return name
+}
+
 // limiterKind returns the kind of rate limiter enabled by config.
 // This function is used for release builds.
 func limiterKind(c *config.Config) (string, bool) {
@@ -42,9 +48,19 @@ func limiterKind(c *config.Config) (string, bool) {
 // LimiterKey returns a redis key name based on passed parameters.
 // The key should be post-fixed if multiple keys are required (sentinel).
 func LimiterKey(currentSession *user.SessionState, rateScope string, key string, useCustomKey bool) string {
+	return Prefix(LimiterKeyPrefix, rateScope, limiterKeyID(currentSession, key, useCustomKey))
+}
+
+// ClusterLimiterKey is like LimiterKey, with the session identifier in a hash tag. The keys
+// post-fixed to it are kept in the same Redis Cluster hash slot as the quota keys of the session.
+func ClusterLimiterKey(currentSession *user.SessionState, rateScope string, key string, useCustomKey bool) string {
+	return Prefix(LimiterKeyPrefix, rateScope, HashTag(limiterKeyID(currentSession, key, useCustomKey)))
+}
+
+func limiterKeyID(currentSession *user.SessionState, key string, useCustomKey bool) string {
 	if !useCustomKey && !currentSession.KeyHashEmpty() {
-		return Prefix(LimiterKeyPrefix, rateScope, currentSession.KeyHash())
+		return currentSession.KeyHash()
 	}
 
-	return Prefix(LimiterKeyPrefix, rateScope, key)
+	return key
 }
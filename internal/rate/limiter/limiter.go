@@ -19,7 +19,8 @@ type Limiter struct {
 	clock  limiters.Clock
 }
 
-type Func func(ctx context.Context, key string, rate float64, per float64) (ttl time.Duration, err error)
+// Func counts a request as cost requests of the rate limit of key.
+type Func func(ctx context.Context, key string, rate float64, per float64, cost int64) (ttl time.Duration, err error)
 
 // NewLimiter creates a new limiter object. It holds the redis client and the
 // default non-distributed locks, logger, and a clock for supporting tests.
@@ -2,27 +2,100 @@ package limiter
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
-	"github.com/TykTechnologies/exp/pkg/limiters"
+	"github.com/TykTechnologies/tyk/internal/redis"
 )
 
-func (l *Limiter) FixedWindow(ctx context.Context, key string, rate float64, per float64) (time.Duration, error) {
+// FixedWindow counts a request as cost requests of the window. A request which would overflow the
+// window isn't counted, so a blocked weighted request doesn't use up the allowance of the others.
+func (l *Limiter) FixedWindow(ctx context.Context, key string, rate float64, per float64, cost int64) (time.Duration, error) {
 	var (
-		storage limiters.FixedWindowIncrementer
+		storage fixedWindowCounter
 
 		capacity = int64(rate)
 		ttl      = time.Duration(per * float64(time.Second))
 	)
 
 	if l.redis != nil {
-		storage = limiters.NewFixedWindowRedis(l.redis, key)
+		storage = &fixedWindowRedis{cli: l.redis, prefix: key}
 	} else {
-		storage = limiters.LocalFixedWindow(key)
+		storage = localFixedWindow(key)
 	}
 
-	limiter := limiters.NewFixedWindow(capacity, ttl, storage, l.clock)
+	now := l.clock.Now()
+	window := now.Truncate(ttl)
+	wait := ttl - now.Sub(window)
 
-	// Rate limiter returns a zero duration and a possible ErrLimitExhausted when no tokens are available.
-	return limiter.Limit(ctx)
+	count, err := storage.Add(ctx, window, wait, cost)
+	if err != nil {
+		return 0, err
+	}
+
+	if count > capacity {
+		if _, err := storage.Add(ctx, window, wait, -cost); err != nil {
+			l.logger.Log(err)
+		}
+
+		// Rate limiter returns the time left in the window and ErrLimitExhausted when the request doesn't fit.
+		return wait, ErrLimitExhausted
+	}
+
+	return 0, nil
+}
+
+// fixedWindowCounter adds to the counter of a window and returns its value.
+type fixedWindowCounter interface {
+	Add(ctx context.Context, window time.Time, ttl time.Duration, n int64) (int64, error)
+}
+
+// fixedWindowRedis holds the window counters in redis, under the keys of limiters.FixedWindowRedis.
+type fixedWindowRedis struct {
+	cli    redis.UniversalClient
+	prefix string
+}
+
+func (f *fixedWindowRedis) Add(ctx context.Context, window time.Time, ttl time.Duration, n int64) (int64, error) {
+	key := fmt.Sprintf("%s/%d", f.prefix, window.UnixNano())
+
+	var incr *redis.IntCmd
+	_, err := f.cli.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.IncrBy(ctx, key, n)
+		pipe.PExpire(ctx, key, ttl)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("redis transaction failed: %w", err)
+	}
+
+	return incr.Val(), nil
+}
+
+// fixedWindowInMemory is the counter of the current window of a key.
+type fixedWindowInMemory struct {
+	mu     sync.Mutex
+	window time.Time
+	count  int64
+}
+
+var localFixedWindows sync.Map
+
+func localFixedWindow(key string) *fixedWindowInMemory {
+	counter, _ := localFixedWindows.LoadOrStore(key, &fixedWindowInMemory{})
+	return counter.(*fixedWindowInMemory)
+}
+
+func (f *fixedWindowInMemory) Add(ctx context.Context, window time.Time, _ time.Duration, n int64) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if window != f.window {
+		f.window = window
+		f.count = 0
+	}
+	f.count += n
+
+	return f.count, ctx.Err()
 }
@@ -7,7 +7,9 @@ import (
 	"github.com/TykTechnologies/exp/pkg/limiters"
 )
 
-func (l *Limiter) LeakyBucket(ctx context.Context, key string, rate float64, per float64) (time.Duration, error) {
+// LeakyBucket queues the request cost times. The leaky bucket can't take a request back, so the
+// part of a weighted request queued before the bucket overflows stays queued.
+func (l *Limiter) LeakyBucket(ctx context.Context, key string, rate float64, per float64, cost int64) (time.Duration, error) {
 	var (
 		storage limiters.LeakyBucketStateBackend
 		locker  limiters.DistLocker
@@ -28,7 +30,13 @@ func (l *Limiter) LeakyBucket(ctx context.Context, key string, rate float64, per
 	limiter := limiters.NewLeakyBucket(capacity, outputRate, locker, storage, l.clock, l.logger)
 
 	// Rate limiter returns ErrLimitExhausted, or queues the request.
-	res, err := limiter.Limit(ctx)
+	var (
+		res time.Duration
+		err error
+	)
+	for i := int64(0); i < cost && err == nil; i++ {
+		res, err = limiter.Limit(ctx)
+	}
 	if err == nil {
 		time.Sleep(res)
 	}
@@ -7,7 +7,9 @@ import (
 	"github.com/TykTechnologies/exp/pkg/limiters"
 )
 
-func (l *Limiter) SlidingWindow(ctx context.Context, key string, rate float64, per float64) (time.Duration, error) {
+// SlidingWindow counts the request cost times. The sliding window can't take a request back, so
+// the part of a weighted request counted before the window overflows stays counted.
+func (l *Limiter) SlidingWindow(ctx context.Context, key string, rate float64, per float64, cost int64) (time.Duration, error) {
 	var (
 		storage limiters.SlidingWindowIncrementer
 
@@ -30,5 +32,13 @@ func (l *Limiter) SlidingWindow(ctx context.Context, key string, rate float64, p
 	limiter := limiters.NewSlidingWindow(capacity, ttl, storage, l.clock, 0)
 
 	// Rate limiter returns a zero duration and a possible ErrLimitExhausted when no tokens are available.
-	return limiter.Limit(ctx)
+	var (
+		res time.Duration
+		err error
+	)
+	for i := int64(0); i < cost && err == nil && res == 0; i++ {
+		res, err = limiter.Limit(ctx)
+	}
+
+	return res, err
 }
@@ -7,7 +7,8 @@ import (
 	"github.com/TykTechnologies/exp/pkg/limiters"
 )
 
-func (l *Limiter) TokenBucket(ctx context.Context, key string, rate float64, per float64) (time.Duration, error) {
+// TokenBucket takes cost tokens from the bucket, none when it holds less.
+func (l *Limiter) TokenBucket(ctx context.Context, key string, rate float64, per float64, cost int64) (time.Duration, error) {
 	var (
 		storage limiters.TokenBucketStateBackend
 		locker  limiters.DistLocker
@@ -27,5 +28,5 @@ func (l *Limiter) TokenBucket(ctx context.Context, key string, rate float64, per
 	limiter := limiters.NewTokenBucket(capacity, ttl, locker, storage, l.clock, l.logger)
 
 	// Rate limiter returns a zero duration and a possible ErrLimitExhausted when no tokens are available.
-	return limiter.Limit(ctx)
+	return limiter.Take(ctx, cost)
 }
@@ -0,0 +1,132 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/TykTechnologies/tyk/internal/redis"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+// ErrCrossSlot is returned for the operations Redis Cluster would reject with a CROSSSLOT error.
+var ErrCrossSlot = errors.New("CROSSSLOT Keys in request don't hash to the same slot")
+
+// Cluster is a redis hook emulating the key constraints of Redis Cluster on a single node.
+// Commands, scripts and transactions using keys of more than one hash slot are rejected and
+// recorded, so tests can assert no cross-slot operation is issued.
+type Cluster struct {
+	mu        sync.Mutex
+	crossSlot []string
+}
+
+// CrossSlot returns the rejected operations, with the keys they used.
+func (c *Cluster) CrossSlot() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.crossSlot...)
+}
+
+func (c *Cluster) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (c *Cluster) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := c.check(cmd.Name(), commandKeys(cmd)); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+
+		return next(ctx, cmd)
+	}
+}
+
+func (c *Cluster) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		// the commands of a transaction all run on the node of their slot
+		transaction := len(cmds) > 0 && cmds[0].Name() == "multi"
+
+		var keys []string
+		for _, cmd := range cmds {
+			if !transaction {
+				keys = nil
+			}
+			keys = append(keys, commandKeys(cmd)...)
+
+			name := cmd.Name()
+			if transaction {
+				name = "multi"
+			}
+
+			if err := c.check(name, keys); err != nil {
+				for _, cmd := range cmds {
+					cmd.SetErr(err)
+				}
+				return err
+			}
+		}
+
+		return next(ctx, cmds)
+	}
+}
+
+func (c *Cluster) check(name string, keys []string) error {
+	for _, key := range keys {
+		if storage.KeyHashSlot(key) == storage.KeyHashSlot(keys[0]) {
+			continue
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.crossSlot = append(c.crossSlot, fmt.Sprintf("%s %s", name, strings.Join(keys, " ")))
+		return ErrCrossSlot
+	}
+
+	return nil
+}
+
+// commandKeys returns the keys used by a command.
+func commandKeys(cmd redis.Cmder) []string {
+	args := cmd.Args()
+
+	var keys []interface{}
+	switch cmd.Name() {
+	case "multi", "exec", "discard", "ping", "hello", "client", "select", "script", "info", "flushdb", "flushall",
+		"publish", "subscribe", "unsubscribe", "psubscribe", "punsubscribe", "scan", "keys", "dbsize":
+		return nil
+	case "del", "unlink", "exists", "mget", "touch", "watch", "sinterstore", "sunionstore", "rename", "renamenx":
+		keys = args[1:]
+	case "mset", "msetnx":
+		for i := 1; i < len(args); i += 2 {
+			keys = append(keys, args[i])
+		}
+	case "eval", "evalsha", "eval_ro", "evalsha_ro":
+		if len(args) < 3 {
+			return nil
+		}
+
+		numKeys, err := strconv.Atoi(fmt.Sprint(args[2]))
+		if err != nil || 3+numKeys > len(args) {
+			return nil
+		}
+		keys = args[3 : 3+numKeys]
+	default:
+		if len(args) < 2 {
+			return nil
+		}
+		keys = args[1:2]
+	}
+
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		names = append(names, fmt.Sprint(key))
+	}
+
+	return names
+}
@@ -0,0 +1,76 @@
+package rate
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/TykTechnologies/tyk/internal/redis"
+)
+
+var quotaIncrement = mustScript("scripts/quota_increment.lua")
+
+// Quota implements the quota counters in redis. Every operation touches a single key,
+// so the counters are safe to use with Redis Cluster.
+type Quota struct {
+	conn redis.UniversalClient
+}
+
+// NewQuotaRedis creates a new Quota instance with a redis.UniversalClient.
+func NewQuotaRedis(conn redis.UniversalClient) *Quota {
+	return &Quota{
+		conn: conn,
+	}
+}
+
+// Increment counts cost requests in the quota counter stored at key and returns the
+// updated counter. A request taking the counter over max is blocked and isn't counted,
+// the returned counter includes it though. A new counter expires after renewal, or never
+// when renewal isn't positive. The counter is updated atomically by a lua script.
+func (q *Quota) Increment(ctx context.Context, key string, cost, max int64, renewal time.Duration) (int64, error) {
+	return quotaIncrement.Run(ctx, q.conn, []string{key}, cost, renewal.Milliseconds(), max).Int64()
+}
+
+// Migrate copies the counter stored at legacyKey, with its expiry, to key when key doesn't
+// exist yet. The legacy counter is left to expire, so it's only read for the remainder of
+// its quota period. It returns the TTL of key, following the PTTL conventions: -2 if the
+// key doesn't exist and -1 if it doesn't expire.
+//
+// The keys are read and written with separate commands, as they may be stored in
+// different Redis Cluster hash slots.
+func (q *Quota) Migrate(ctx context.Context, key, legacyKey string) (time.Duration, error) {
+	value, err := q.conn.Get(ctx, legacyKey).Result()
+	if errors.Is(err, redis.Nil) {
+		return -2, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	ttl, err := q.conn.PTTL(ctx, legacyKey).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// the legacy counter expired since it was read
+	if ttl == -2 {
+		return -2, nil
+	}
+
+	expiration := ttl
+	if expiration < 0 {
+		expiration = 0
+	}
+
+	set, err := q.conn.SetNX(ctx, key, value, expiration).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	if set {
+		return ttl, nil
+	}
+
+	// another gateway created or migrated the counter first
+	return q.conn.PTTL(ctx, key).Result()
+}
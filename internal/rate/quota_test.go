@@ -0,0 +1,148 @@
+package rate_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/internal/rate"
+	"github.com/TykTechnologies/tyk/internal/rate/mock"
+	"github.com/TykTechnologies/tyk/internal/redis"
+	"github.com/TykTechnologies/tyk/internal/uuid"
+)
+
+func TestQuota_Increment(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := newTokenBucketConn(t)
+	cluster := &mock.Cluster{}
+	db.AddHook(cluster)
+
+	quota := rate.NewQuotaRedis(db)
+	key := rate.Prefix("quota", rate.HashTag(uuid.New()))
+
+	count, err := quota.Increment(ctx, key, 1, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	ttl := db.PTTL(ctx, key).Val()
+	assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+	// the quota period isn't extended by the following requests
+	require.NoError(t, db.PExpire(ctx, key, 30*time.Second).Err())
+	count, err = quota.Increment(ctx, key, 5, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), count)
+	assert.LessOrEqual(t, db.PTTL(ctx, key).Val(), 30*time.Second)
+
+	// a request over the quota isn't counted
+	count, err = quota.Increment(ctx, key, 5, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), count)
+	assert.Equal(t, "6", db.Get(ctx, key).Val())
+
+	count, err = quota.Increment(ctx, key, 4, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), count)
+
+	t.Run("without renewal", func(t *testing.T) {
+		key := rate.Prefix("quota", rate.HashTag(uuid.New()))
+
+		count, err := quota.Increment(ctx, key, 2, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+		assert.Equal(t, time.Duration(-1), db.PTTL(ctx, key).Val())
+	})
+
+	assert.Empty(t, cluster.CrossSlot())
+}
+
+func TestQuota_Migrate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := newTokenBucketConn(t)
+	cluster := &mock.Cluster{}
+	db.AddHook(cluster)
+
+	quota := rate.NewQuotaRedis(db)
+
+	newKeys := func() (string, string) {
+		id := uuid.New()
+		return rate.Prefix("quota", rate.HashTag(id)), rate.Prefix("quota", id)
+	}
+
+	t.Run("legacy counter", func(t *testing.T) {
+		key, legacyKey := newKeys()
+		require.NoError(t, db.Set(ctx, legacyKey, 7, time.Minute).Err())
+
+		ttl, err := quota.Migrate(ctx, key, legacyKey)
+		require.NoError(t, err)
+		assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+		assert.Equal(t, "7", db.Get(ctx, key).Val())
+		assert.InDelta(t, time.Minute, db.PTTL(ctx, key).Val(), float64(time.Second))
+
+		count, err := quota.Increment(ctx, key, 1, 10, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, int64(8), count)
+	})
+
+	t.Run("legacy counter without expiry", func(t *testing.T) {
+		key, legacyKey := newKeys()
+		require.NoError(t, db.Set(ctx, legacyKey, 3, 0).Err())
+
+		ttl, err := quota.Migrate(ctx, key, legacyKey)
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(-1), ttl)
+		assert.Equal(t, "3", db.Get(ctx, key).Val())
+	})
+
+	t.Run("no legacy counter", func(t *testing.T) {
+		key, legacyKey := newKeys()
+
+		ttl, err := quota.Migrate(ctx, key, legacyKey)
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(-2), ttl)
+		assert.Zero(t, db.Exists(ctx, key).Val())
+	})
+
+	t.Run("migrated by another gateway", func(t *testing.T) {
+		key, legacyKey := newKeys()
+		require.NoError(t, db.Set(ctx, legacyKey, 3, time.Minute).Err())
+		require.NoError(t, db.Set(ctx, key, 5, 30*time.Second).Err())
+
+		ttl, err := quota.Migrate(ctx, key, legacyKey)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, ttl, 30*time.Second)
+		assert.Equal(t, "5", db.Get(ctx, key).Val())
+	})
+
+	assert.Empty(t, cluster.CrossSlot())
+}
+
+func TestCluster_RejectsCrossSlot(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := newTokenBucketConn(t)
+	cluster := &mock.Cluster{}
+	db.AddHook(cluster)
+
+	id := uuid.New()
+	assert.NoError(t, db.Del(ctx, "quota-{"+id+"}", "rate-limit-{"+id+"}").Err())
+	assert.ErrorIs(t, db.Del(ctx, "quota-"+id, "rate-limit-"+id).Err(), mock.ErrCrossSlot)
+
+	_, err := db.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, "quota-"+id)
+		pipe.Incr(ctx, "rate-limit-"+id)
+		return nil
+	})
+	assert.ErrorIs(t, err, mock.ErrCrossSlot)
+
+	assert.Len(t, cluster.CrossSlot(), 2)
+}
@@ -17,6 +17,9 @@ const (
 	LimitTokenBucket   string = "token-bucket"
 	LimitFixedWindow   string = "fixed-window"
 	LimitSlidingWindow string = "sliding-window"
+	LimitRedisRolling  string = "redis-rolling"
+	LimitSentinel      string = "sentinel"
+	LimitDRL           string = "drl"
 )
 
 const (
@@ -46,3 +49,9 @@ func Prefix(params ...string) string {
 	}
 	return res.String()
 }
+
+// HashTag wraps key in a Redis Cluster hash tag. Keys containing the same hash tag are
+// stored in the same hash slot, so they can be used together in scripts and transactions.
+func HashTag(key string) string {
+	return "{" + key + "}"
+}
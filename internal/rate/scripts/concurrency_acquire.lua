@@ -0,0 +1,18 @@
+local key = KEYS[1]
+
+local now = tonumber(ARGV[1])
+local ttl = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local lease = ARGV[4]
+
+-- Leases which weren't released, e.g. by a gateway which stopped, expire
+redis.call("ZREMRANGEBYSCORE", key, "-inf", now)
+
+if redis.call("ZCARD", key) >= limit then
+	return 0
+end
+
+redis.call("ZADD", key, now + ttl, lease)
+redis.call("PEXPIRE", key, ttl)
+
+return 1
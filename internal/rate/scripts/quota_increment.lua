@@ -0,0 +1,19 @@
+local key = KEYS[1]
+
+local cost = tonumber(ARGV[1])
+local renewal_ms = tonumber(ARGV[2])
+local max = tonumber(ARGV[3])
+
+local quota = redis.call("INCRBY", key, cost)
+
+-- A new counter starts the quota period, an existing one keeps its expiry
+if quota == cost and renewal_ms > 0 then
+	redis.call("PEXPIRE", key, renewal_ms)
+end
+
+-- A request over the quota isn't counted, so a blocked weighted request doesn't use up the quota left
+if quota > max then
+	redis.call("DECRBY", key, cost)
+end
+
+return quota
@@ -4,21 +4,26 @@ local now_str = ARGV[1]
 local window_start_str = ARGV[2]
 local max_allowed = tonumber(ARGV[3])
 local per_seconds = tonumber(ARGV[4])
+local cost = tonumber(ARGV[5]) or 1
 
 redis.call("ZREMRANGEBYSCORE", key, "-inf", window_start_str)
 
 local count = redis.call("ZCARD", key)
 
+-- A weighted request is logged as cost entries with the same score
 redis.call("ZADD", key, now_str, now_str)
+for i = 2, cost do
+	redis.call("ZADD", key, now_str, now_str .. ":" .. i)
+end
 redis.call("EXPIRE", key, per_seconds)
 
-local remaining = max_allowed - count - 1
+local remaining = max_allowed - count - cost
 if remaining < 0 then
 	remaining = 0
 end
 
 local earliest_next = now_str
-if count >= max_allowed then
+if count + cost > max_allowed then
 	local oldest = redis.call("ZRANGE", key, -max_allowed, -max_allowed)
 	if #oldest > 0 then
 		earliest_next = oldest[1]
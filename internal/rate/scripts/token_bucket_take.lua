@@ -0,0 +1,40 @@
+local key = KEYS[1]
+
+local now = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local refill_per_ms = tonumber(ARGV[3])
+local take = tonumber(ARGV[4])
+local need = tonumber(ARGV[5]) or 1
+
+local state = redis.call("HMGET", key, "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+
+-- A gateway with a clock behind the last update doesn't refill the bucket
+if now > ts then
+	tokens = math.min(capacity, tokens + (now - ts) * refill_per_ms)
+	ts = now
+end
+
+local allowed = 0
+if tokens >= need then
+	allowed = 1
+	tokens = tokens - take
+end
+
+local full_in = math.ceil((capacity - tokens) / refill_per_ms)
+
+redis.call("HSET", key, "tokens", tostring(tokens), "ts", tostring(ts))
+redis.call("PEXPIRE", key, full_in + 1000)
+
+local reset = full_in
+if allowed == 0 then
+	reset = math.ceil((need - tokens) / refill_per_ms)
+end
+
+return { allowed, math.floor(tokens), reset }
@@ -184,13 +184,25 @@ func (r *SlidingLog) Get(ctx context.Context, now time.Time, keyName string, per
 // If there are issues with storage availability for example, requests will be blocked rather
 // than let through, as no rate limit can be enforced without storage.
 func (r *SlidingLog) Do(ctx context.Context, now time.Time, key string, maxAllowed, per int64) (Stats, bool, error) {
-	stats, err := r.SetCountScript(ctx, now, key, maxAllowed, per)
+	return r.DoCost(ctx, now, key, maxAllowed, per, 1)
+}
+
+// DoCost is like Do, logging the request as cost requests. It's blocked when the
+// requests in the window and its cost exceed maxAllowed.
+func (r *SlidingLog) DoCost(ctx context.Context, now time.Time, key string, maxAllowed, per, cost int64) (Stats, bool, error) {
+	if cost < 1 {
+		cost = 1
+	}
+
+	stats, err := r.setCountScript(ctx, now, key, maxAllowed, per, cost)
 
 	if err != nil {
 		return NewEmptyStats(), true, err
 	}
 
-	return stats, r.smoothingFn(ctx, key, int64(stats.Count), maxAllowed), err
+	// The smoothing function blocks from the count of the requests before this one,
+	// so the extra cost of a weighted request is added to it.
+	return stats, r.smoothingFn(ctx, key, int64(stats.Count)+cost-1, maxAllowed), err
 }
 
 // SetCountScript get current window occupation
@@ -201,7 +213,10 @@ func (r *SlidingLog) SetCountScript(
 	key string,
 	maxAllowed, per int64,
 ) (Stats, error) {
+	return r.setCountScript(ctx, now, key, maxAllowed, per, 1)
+}
 
+func (r *SlidingLog) setCountScript(ctx context.Context, now time.Time, key string, maxAllowed, per, cost int64) (Stats, error) {
 	now = now.Local()
 	windowStart := now.Add(time.Second * time.Duration(-1*per))
 
@@ -211,6 +226,7 @@ func (r *SlidingLog) SetCountScript(
 		strconv.FormatInt(windowStart.UnixNano(), 10),
 		maxAllowed,
 		per,
+		cost,
 	)
 
 	if cmd.Err() != nil {
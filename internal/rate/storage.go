@@ -13,6 +13,13 @@ import (
 
 // NewStorage provides a redis v9 client for rate limiter use.
 func NewStorage(cfg *config.StorageOptionsConf, externalServicesConfig *config.ExternalServiceConfig) redis.UniversalClient {
+	return NewStorageWithCredentials(cfg, externalServicesConfig, nil)
+}
+
+// NewStorageWithCredentials provides a redis v9 client for rate limiter use. The connections are
+// authenticated with the username and password returned by credentials when it's set, so the client
+// keeps connecting after the password is rotated.
+func NewStorageWithCredentials(cfg *config.StorageOptionsConf, externalServicesConfig *config.ExternalServiceConfig, credentials func() (string, string)) redis.UniversalClient {
 	logrus.Debugf("[ExternalServices] Creating Redis client for rate limiter")
 	// poolSize applies per cluster node and not for the whole cluster.
 	poolSize := 500
@@ -46,6 +53,8 @@ func NewStorage(cfg *config.StorageOptionsConf, externalServicesConfig *config.E
 		//		IdleTimeout:      240 * timeout,
 		PoolSize:  poolSize,
 		TLSConfig: tlsConfig,
+
+		CredentialsProvider: credentials,
 	}
 
 	if opts.MasterName != "" {
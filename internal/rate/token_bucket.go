@@ -0,0 +1,84 @@
+package rate
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/TykTechnologies/tyk/internal/redis"
+)
+
+var tokenBucketTake = mustScript("scripts/token_bucket_take.lua")
+
+// ErrInvalidTokenBucket is returned when the token bucket rate or interval isn't positive.
+var ErrInvalidTokenBucket = errors.New("token bucket rate and per must be positive")
+
+// TokenBucket implements a token bucket rate limiter storing the bucket in redis.
+// The bucket is updated atomically by a lua script, so it's shared between
+// gateways and a burst can't be multiplied by the number of gateways.
+type TokenBucket struct {
+	conn redis.UniversalClient
+}
+
+// NewTokenBucketRedis creates a new TokenBucket instance with a redis.UniversalClient.
+func NewTokenBucketRedis(conn redis.UniversalClient) *TokenBucket {
+	return &TokenBucket{
+		conn: conn,
+	}
+}
+
+// Do takes a token from the bucket stored at key and reports if the request should
+// be blocked. The bucket holds up to burst tokens, defaulting to the rate, and refills
+// at rate tokens every per seconds. With dryRun set the bucket is checked without
+// taking a token. In case an error occurs, the request should be blocked.
+//
+// The returned stats hold the burst as the limit and the tokens left as remaining.
+// Reset is the time until the bucket is full, or until the next token when it's empty.
+func (t *TokenBucket) Do(ctx context.Context, now time.Time, key string, rate, per float64, burst int64, dryRun bool) (Stats, bool, error) {
+	return t.DoCost(ctx, now, key, rate, per, burst, 1, dryRun)
+}
+
+// DoCost is like Do, taking cost tokens from the bucket. The request is blocked
+// when fewer than cost tokens are left.
+func (t *TokenBucket) DoCost(ctx context.Context, now time.Time, key string, rate, per float64, burst, cost int64, dryRun bool) (Stats, bool, error) {
+	if rate <= 0 || per <= 0 {
+		return NewEmptyStats(), true, ErrInvalidTokenBucket
+	}
+
+	if burst <= 0 {
+		burst = int64(math.Max(1, math.Ceil(rate)))
+	}
+
+	if cost < 1 {
+		cost = 1
+	}
+
+	take := cost
+	if dryRun {
+		take = 0
+	}
+
+	refillPerMs := rate / (per * 1000)
+
+	res, err := tokenBucketTake.Run(
+		ctx, t.conn, []string{key},
+		now.UnixMilli(),
+		burst,
+		strconv.FormatFloat(refillPerMs, 'f', -1, 64),
+		take,
+		cost,
+	).Int64Slice()
+	if err != nil {
+		return NewEmptyStats(), true, err
+	}
+
+	allowed, remaining, resetMs := res[0] == 1, res[1], res[2]
+
+	return Stats{
+		Limit:     int(burst),
+		Remaining: int(remaining),
+		Reset:     time.Duration(resetMs) * time.Millisecond,
+	}, !allowed, nil
+}
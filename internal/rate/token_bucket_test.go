@@ -0,0 +1,108 @@
+package rate_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/rate"
+	"github.com/TykTechnologies/tyk/internal/redis"
+	"github.com/TykTechnologies/tyk/internal/uuid"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+func newTokenBucketConn(t *testing.T) redis.UniversalClient {
+	t.Helper()
+
+	conf, err := config.New()
+	require.NoError(t, err)
+
+	conn, err := storage.NewConnector(storage.DefaultConn, *conf)
+	require.NoError(t, err)
+
+	var db redis.UniversalClient
+	require.True(t, conn.As(&db))
+	return db
+}
+
+// TestTokenBucket_Do drives a precise request pattern against a bucket of
+// 10 requests per second with a burst of 5.
+func TestTokenBucket_Do(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := newTokenBucketConn(t)
+	key := "token-bucket-" + uuid.New()
+
+	// Two gateways sharing the bucket.
+	gateways := []*rate.TokenBucket{rate.NewTokenBucketRedis(db), rate.NewTokenBucketRedis(db)}
+
+	start := time.Now()
+	steps := []struct {
+		offset    time.Duration
+		dryRun    bool
+		blocked   bool
+		remaining int
+		reset     time.Duration
+	}{
+		// The burst is available straight away, whichever gateway handles the request.
+		{offset: 0, remaining: 4, reset: 100 * time.Millisecond},
+		{offset: 0, remaining: 3, reset: 200 * time.Millisecond},
+		{offset: 0, remaining: 2, reset: 300 * time.Millisecond},
+		{offset: 0, remaining: 1, reset: 400 * time.Millisecond},
+		{offset: 0, remaining: 0, reset: 500 * time.Millisecond},
+		{offset: 0, blocked: true, remaining: 0, reset: 100 * time.Millisecond},
+		{offset: 50 * time.Millisecond, blocked: true, remaining: 0, reset: 50 * time.Millisecond},
+		// One token is added every 100ms.
+		{offset: 100 * time.Millisecond, remaining: 0, reset: 500 * time.Millisecond},
+		{offset: 100 * time.Millisecond, blocked: true, remaining: 0, reset: 100 * time.Millisecond},
+		{offset: 300 * time.Millisecond, dryRun: true, remaining: 2, reset: 300 * time.Millisecond},
+		{offset: 300 * time.Millisecond, remaining: 1, reset: 400 * time.Millisecond},
+		// The bucket never holds more than the burst.
+		{offset: 5 * time.Second, remaining: 4, reset: 100 * time.Millisecond},
+	}
+
+	for i, step := range steps {
+		gw := gateways[i%len(gateways)]
+		stats, blocked, err := gw.Do(ctx, start.Add(step.offset), key, 10, 1, 5, step.dryRun)
+		require.NoError(t, err)
+
+		assert.Equal(t, step.blocked, blocked, "step %d", i)
+		assert.Equal(t, 5, stats.Limit, "step %d", i)
+		assert.Equal(t, step.remaining, stats.Remaining, "step %d", i)
+		assert.Equal(t, step.reset, stats.Reset, "step %d", i)
+	}
+}
+
+func TestTokenBucket_DefaultBurst(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bucket := rate.NewTokenBucketRedis(newTokenBucketConn(t))
+	key := "token-bucket-" + uuid.New()
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		stats, blocked, err := bucket.Do(ctx, now, key, 3, 60, 0, false)
+		require.NoError(t, err)
+		assert.False(t, blocked)
+		assert.Equal(t, 3, stats.Limit)
+	}
+
+	stats, blocked, err := bucket.Do(ctx, now, key, 3, 60, 0, false)
+	require.NoError(t, err)
+	assert.True(t, blocked)
+	assert.Equal(t, 20*time.Second, stats.Reset)
+}
+
+func TestTokenBucket_Errors(t *testing.T) {
+	bucket := rate.NewTokenBucketRedis(nil)
+
+	_, blocked, err := bucket.Do(context.Background(), time.Now(), "key", 10, 0, 5, false)
+	assert.ErrorIs(t, err, rate.ErrInvalidTokenBucket)
+	assert.True(t, blocked)
+}
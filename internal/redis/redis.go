@@ -40,5 +40,13 @@ type (
 	ZSliceCmd      = redis.ZSliceCmd
 	StringCmd      = redis.StringCmd
 	StringSliceCmd = redis.StringSliceCmd
+	SliceCmd       = redis.SliceCmd
+	DurationCmd    = redis.DurationCmd
+	Cmder          = redis.Cmder
 	Script         = redis.Script
+
+	Hook                = redis.Hook
+	DialHook            = redis.DialHook
+	ProcessHook         = redis.ProcessHook
+	ProcessPipelineHook = redis.ProcessPipelineHook
 )
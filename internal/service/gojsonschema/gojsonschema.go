@@ -8,6 +8,7 @@ type (
 	JSONLoader              = gojsonschema.JSONLoader
 	ResultError             = gojsonschema.ResultError
 	Result                  = gojsonschema.Result
+	Schema                  = gojsonschema.Schema
 	FormatCheckerChain      = gojsonschema.FormatCheckerChain
 	DoesNotMatchFormatError = gojsonschema.DoesNotMatchFormatError
 )
@@ -15,6 +16,7 @@ type (
 var (
 	NewBytesLoader = gojsonschema.NewBytesLoader
 	NewGoLoader    = gojsonschema.NewGoLoader
+	NewSchema      = gojsonschema.NewSchema
 	FormatCheckers = gojsonschema.FormatCheckers
 	Validate       = gojsonschema.Validate
 )
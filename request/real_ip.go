@@ -3,7 +3,9 @@ package request
 import (
 	"net"
 	"net/http"
+	"slices"
 	"strings"
+	"sync/atomic"
 
 	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/header"
@@ -11,13 +13,28 @@ import (
 
 var Global func() config.Config
 
+// trustedProxies caches the parsed trusted_proxies list of the configuration it was parsed from.
+type trustedProxies struct {
+	entries []string
+	nets    []*net.IPNet
+}
+
+var trustedProxiesCache atomic.Pointer[trustedProxies]
+
 // RealIP takes a request object, and returns the real Client IP address.
+//
+// When trusted proxies are configured, forwarded headers are only honoured for requests
+// coming from a trusted proxy, and X-Forwarded-For is walked from the right skipping trusted hops.
 func RealIP(r *http.Request) string {
 
 	if contextIp := r.Context().Value("remote_addr"); contextIp != nil {
 		return contextIp.(string)
 	}
 
+	if nets, ok := configuredTrustedProxies(); ok {
+		return realIPBehindTrustedProxies(r, nets)
+	}
+
 	if realIPVal := r.Header.Get(header.XRealIP); realIPVal != "" {
 		if realIP := net.ParseIP(realIPVal); realIP != nil {
 			return realIP.String()
@@ -58,6 +75,106 @@ func RealIP(r *http.Request) string {
 		// If IP is invalid, fall through to use RemoteAddr
 	}
 
+	return remoteHost(r)
+}
+
+// FromTrustedProxy reports whether the forwarded headers of the request can be honoured,
+// which is when no trusted proxies are configured or the request comes from one of them.
+func FromTrustedProxy(r *http.Request) bool {
+	nets, ok := configuredTrustedProxies()
+	if !ok {
+		return true
+	}
+	return isTrusted(net.ParseIP(remoteHost(r)), nets)
+}
+
+func realIPBehindTrustedProxies(r *http.Request, nets []*net.IPNet) string {
+	host := remoteHost(r)
+	if !isTrusted(net.ParseIP(host), nets) {
+		return host
+	}
+
+	if realIP := net.ParseIP(r.Header.Get(header.XRealIP)); realIP != nil {
+		return realIP.String()
+	}
+
+	var hops []string
+	for _, fw := range r.Header.Values(header.XForwardFor) {
+		hops = append(hops, strings.Split(fw, ",")...)
+	}
+
+	// walk the chain from the closest hop, the first one not added by a trusted proxy is the client
+	client := host
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			break
+		}
+		client = ip.String()
+		if !isTrusted(ip, nets) {
+			break
+		}
+	}
+	return client
+}
+
+// configuredTrustedProxies returns the parsed trusted proxies, and false when none are configured.
+// Invalid entries are skipped, so a list without valid entries trusts no proxy.
+func configuredTrustedProxies() ([]*net.IPNet, bool) {
+	if Global == nil {
+		return nil, false
+	}
+
+	entries := Global().HttpServerOptions.TrustedProxies
+	if len(entries) == 0 {
+		return nil, false
+	}
+
+	if cached := trustedProxiesCache.Load(); cached != nil && slices.Equal(cached.entries, entries) {
+		return cached.nets, true
+	}
+
+	nets := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		if ipNet := parseTrustedProxy(entry); ipNet != nil {
+			nets = append(nets, ipNet)
+		}
+	}
+	trustedProxiesCache.Store(&trustedProxies{entries: entries, nets: nets})
+
+	return nets, true
+}
+
+// parseTrustedProxy parses a CIDR range or a single IP address.
+func parseTrustedProxy(entry string) *net.IPNet {
+	entry = strings.TrimSpace(entry)
+	if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+		return ipNet
+	}
+
+	ip := net.ParseIP(entry)
+	if ip == nil {
+		return nil
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+func isTrusted(ip net.IP, nets []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func remoteHost(r *http.Request) string {
 	// From net/http.Request.RemoteAddr:
 	//   The HTTP server in this package sets RemoteAddr to an
 	//   "IP:port" address before invoking a handler.
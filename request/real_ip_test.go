@@ -9,11 +9,12 @@ import (
 )
 
 var ipHeaderTests = []struct {
-	remoteAddr string
-	key        string
-	value      string
-	expected   string
-	comment    string
+	remoteAddr     string
+	key            string
+	value          string
+	trustedProxies []string
+	expected       string
+	comment        string
 }{
 	{remoteAddr: "10.0.1.4:8080", key: "X-Real-IP", value: "10.0.0.1", expected: "10.0.0.1", comment: "X-Real-IP"},
 	{remoteAddr: "10.0.1.4:8080", key: "X-Forwarded-For", value: "10.0.0.2", expected: "10.0.0.2", comment: "X-Forwarded-For (single)"},
@@ -21,6 +22,16 @@ var ipHeaderTests = []struct {
 	{remoteAddr: "10.0.1.4:8080", expected: "10.0.1.4", comment: "RemoteAddr"},
 	{remoteAddr: "10.0.1.4:8080", key: "X-Forwarded-For", value: "bob", expected: "10.0.1.4", comment: "invalid X-Forwarded-For"},
 	{remoteAddr: "10.0.1.4:8080", key: "X-Real-IP", value: "bob", expected: "10.0.1.4", comment: "invalid X-Real-IP"},
+	{remoteAddr: "10.0.1.4:8080", key: "X-Real-IP", value: "10.0.0.1", trustedProxies: []string{"10.0.1.0/24"}, expected: "10.0.0.1", comment: "X-Real-IP from trusted proxy"},
+	{remoteAddr: "192.168.0.9:8080", key: "X-Real-IP", value: "10.0.0.1", trustedProxies: []string{"10.0.1.0/24"}, expected: "192.168.0.9", comment: "X-Real-IP from untrusted RemoteAddr"},
+	{remoteAddr: "192.168.0.9:8080", key: "X-Forwarded-For", value: "10.0.0.2", trustedProxies: []string{"10.0.1.0/24"}, expected: "192.168.0.9", comment: "X-Forwarded-For from untrusted RemoteAddr"},
+	{remoteAddr: "10.0.1.4:8080", key: "X-Forwarded-For", value: "1.1.1.1, 10.0.0.3, 10.0.1.7", trustedProxies: []string{"10.0.1.0/24"}, expected: "10.0.0.3", comment: "X-Forwarded-For skips trusted hops"},
+	{remoteAddr: "10.0.1.4:8080", key: "X-Forwarded-For", value: "1.1.1.1, 10.0.0.3, 10.0.1.7", trustedProxies: []string{"10.0.0.3", "10.0.1.0/24"}, expected: "1.1.1.1", comment: "X-Forwarded-For skips trusted single IP hops"},
+	{remoteAddr: "10.0.1.4:8080", key: "X-Forwarded-For", value: "10.0.1.8, 10.0.1.7", trustedProxies: []string{"10.0.1.0/24"}, expected: "10.0.1.8", comment: "X-Forwarded-For with only trusted hops"},
+	{remoteAddr: "10.0.1.4:8080", key: "X-Forwarded-For", value: "1.1.1.1, bob, 10.0.1.7", trustedProxies: []string{"10.0.1.0/24"}, expected: "10.0.1.7", comment: "X-Forwarded-For stops at invalid hop"},
+	{remoteAddr: "10.0.1.4:8080", expected: "10.0.1.4", trustedProxies: []string{"10.0.1.0/24"}, comment: "RemoteAddr from trusted proxy without headers"},
+	{remoteAddr: "10.0.1.4:8080", key: "X-Forwarded-For", value: "10.0.0.2", trustedProxies: []string{"bob"}, expected: "10.0.1.4", comment: "invalid trusted proxies trust nobody"},
+	{remoteAddr: "[2001:db8::1]:8080", key: "X-Forwarded-For", value: "10.0.0.2", trustedProxies: []string{"2001:db8::/32"}, expected: "10.0.0.2", comment: "X-Forwarded-For from trusted IPv6 proxy"},
 }
 
 func TestRealIP(t *testing.T) {
@@ -33,6 +44,7 @@ func TestRealIP(t *testing.T) {
 
 	for _, test := range ipHeaderTests {
 		t.Log(test.comment)
+		mockConfig.HttpServerOptions.TrustedProxies = test.trustedProxies
 
 		r, err := http.NewRequest(http.MethodGet, "http://abc.com:8080", nil)
 		if err != nil {
@@ -48,6 +60,8 @@ func TestRealIP(t *testing.T) {
 		}
 	}
 
+	mockConfig.HttpServerOptions.TrustedProxies = nil
+
 	t.Log("Context")
 	r, err := http.NewRequest(http.MethodGet, "http://abc.com:8080", nil)
 	if err != nil {
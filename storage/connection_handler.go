@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -20,6 +21,8 @@ import (
 type ConnectionHandler struct {
 	connections   map[string]model.Connector
 	connectionsMu *sync.RWMutex
+	// readReplicas serve the reads of the stores reading from replicas, nil when none are configured.
+	readReplicas *readReplicas
 
 	storageUp      atomic.Value
 	disableStorage atomic.Value
@@ -37,6 +40,12 @@ const (
 	AnalyticsConn = "analytics"
 )
 
+// reconnectDrainTimeout is how long the connections replaced by Reconnect are kept open.
+const reconnectDrainTimeout = 5 * time.Second
+
+// reconnectPingTimeout is how long Reconnect waits for the new connections to answer a ping.
+const reconnectPingTimeout = 5 * time.Second
+
 // NewConnectionHandler creates a new connection handler not connected
 func NewConnectionHandler(ctx context.Context) *ConnectionHandler {
 	return &ConnectionHandler{
@@ -151,6 +160,7 @@ func (rc *ConnectionHandler) Connect(ctx context.Context, onConnect func(), conf
 
 	// We need the ticker to constantly checking the connection status of Redis. If Redis gets down and up again, we should be able to recover.
 	go rc.statusCheck(ctx)
+	go rc.readReplicaCheck(ctx)
 }
 
 // initConnection initializes the connection singletons.
@@ -172,11 +182,79 @@ func (rc *ConnectionHandler) initConnection(conf config.Config) (err error) {
 		rc.connections[connType] = conn
 	}
 
+	rc.readReplicas, err = newReadReplicas(conf.Storage)
+	return err
+}
+
+// Reconnect replaces the storage connections with new ones created from conf, e.g. when the
+// Redis password is rotated. The new connections must answer a ping, otherwise they're closed
+// and the current ones are kept. The previous connections are closed after reconnectDrainTimeout,
+// so the commands already running on them complete.
+func (rc *ConnectionHandler) Reconnect(conf config.Config) error {
+	connections := make(map[string]model.Connector)
+	for _, connType := range []string{DefaultConn, CacheConn, AnalyticsConn} {
+		conn, err := NewConnector(connType, conf)
+		if err != nil {
+			disconnectAll(connections)
+			return err
+		}
+		connections[connType] = conn
+
+		ctx, cancel := context.WithTimeout(rc.ctx, reconnectPingTimeout)
+		err = conn.Ping(ctx)
+		cancel()
+		if err != nil {
+			disconnectAll(connections)
+			return fmt.Errorf("could not ping the new %s Redis connection: %w", connType, err)
+		}
+	}
+
+	replicas, err := newReadReplicas(conf.Storage)
+	if err != nil {
+		disconnectAll(connections)
+		return err
+	}
+	if replicas != nil {
+		// the new replicas are read from once they pass a health check
+		replicas.check(rc.ctx)
+	}
+
+	rc.connectionsMu.Lock()
+	previous := rc.connections
+	previousReplicas := rc.readReplicas
+	rc.connections = connections
+	rc.readReplicas = replicas
+	rc.connectionsMu.Unlock()
+
+	time.AfterFunc(reconnectDrainTimeout, func() {
+		if previousReplicas != nil {
+			previousReplicas.disconnect()
+		}
+
+		disconnectAll(previous)
+	})
+
 	return nil
 }
 
+// disconnectAll closes the connections, e.g. the ones replaced by Reconnect.
+func disconnectAll(connections map[string]model.Connector) {
+	for connType, conn := range connections {
+		if conn == nil {
+			continue
+		}
+		if err := conn.Disconnect(context.Background()); err != nil {
+			log.WithError(err).Warningf("Could not close the %s Redis connection", connType)
+		}
+	}
+}
+
 func (rc *ConnectionHandler) isConnected(ctx context.Context, connType string) bool {
-	if conn, ok := rc.connections[connType]; ok && conn != nil {
+	rc.connectionsMu.RLock()
+	conn, ok := rc.connections[connType]
+	rc.connectionsMu.RUnlock()
+
+	if ok && conn != nil {
 		err := conn.Ping(ctx)
 		return err == nil
 	}
@@ -238,6 +316,11 @@ func NewConnector(connType string, conf config.Config) (model.Connector, error)
 	}
 	log.Debug("Creating new " + connType + " Storage connection")
 
+	return newConnector(cfg)
+}
+
+// newConnector creates a storage connection configured by cfg.
+func newConnector(cfg config.StorageOptionsConf) (model.Connector, error) {
 	// poolSize applies per cluster node and not for the whole cluster.
 	poolSize := 500
 	if cfg.MaxActive > 0 {
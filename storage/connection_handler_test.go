@@ -147,6 +147,52 @@ func TestConnectionHandler_Disconnect(t *testing.T) {
 	mockConn.AssertExpectations(t)
 }
 
+// TestConnectionHandler_Reconnect tests that the connections are replaced with the new configuration.
+func TestConnectionHandler_Reconnect(t *testing.T) {
+	ctx := context.Background()
+	rc := NewConnectionHandler(ctx)
+
+	// The previous connection is closed once drained, after the test.
+	previous := tempmocks.NewConnector(t)
+	previous.On("Disconnect", mock.Anything).Return(nil).Maybe()
+	rc.connections[DefaultConn] = previous
+
+	conf, err := config.New()
+	assert.NoError(t, err)
+
+	assert.NoError(t, rc.Reconnect(*conf))
+
+	conn := rc.getConnection(false, false)
+	assert.NotSame(t, previous, conn)
+	assert.NoError(t, conn.Ping(ctx))
+	assert.NotNil(t, rc.getConnection(true, false))
+	assert.NotNil(t, rc.getConnection(false, true))
+
+	t.Run("cached storage is created again", func(t *testing.T) {
+		rc.storageUp.Store(true)
+		store := &RedisCluster{ConnectionHandler: rc}
+
+		kv, err := store.kv()
+		assert.NoError(t, err)
+
+		assert.NoError(t, rc.Reconnect(*conf))
+
+		reconnected, err := store.kv()
+		assert.NoError(t, err)
+		assert.NotSame(t, kv, reconnected)
+	})
+
+	t.Run("connections are kept when the new ones don't answer", func(t *testing.T) {
+		current := rc.getConnection(false, false)
+
+		unreachable := *conf
+		unreachable.Storage.Port = 1
+
+		assert.Error(t, rc.Reconnect(unreachable))
+		assert.Same(t, current, rc.getConnection(false, false))
+	})
+}
+
 // TestConnectionHandler_statusCheck tests the status check routine of the connection handler.
 func TestConnectionHandler_statusCheck(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
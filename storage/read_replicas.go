@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	tempkv "github.com/TykTechnologies/storage/temporal/keyvalue"
+	"github.com/TykTechnologies/storage/temporal/model"
+	"github.com/TykTechnologies/storage/temporal/temperr"
+
+	"github.com/TykTechnologies/tyk/config"
+	redis "github.com/TykTechnologies/tyk/internal/redis"
+)
+
+// defaultReadReplicaHealthCheckInterval is how often the replicas are checked when the configuration doesn't set it.
+const defaultReadReplicaHealthCheckInterval = 5 * time.Second
+
+// readReplica is a Redis replica the keys are read from.
+type readReplica struct {
+	addr string
+	conn model.Connector
+	kv   model.KeyValue
+
+	healthy atomic.Bool
+	// latency is the duration of the last successful health check.
+	latency atomic.Int64
+}
+
+// failed takes the replica out of the reads until its next successful health check, unless err is a missing key.
+func (rr *readReplica) failed(err error) {
+	if isKeyNotFound(err) {
+		return
+	}
+
+	if rr.healthy.CompareAndSwap(true, false) {
+		log.WithError(err).WithField("replica", rr.addr).Warning("Reading from the Redis replica failed, reading from the primary")
+	}
+}
+
+// readReplicas routes the reads to the healthy replica with the lowest latency, the nearest one.
+type readReplicas struct {
+	replicas []*readReplica
+	interval time.Duration
+}
+
+// newReadReplicas connects to the read replicas configured for the storage, it returns nil if there are none.
+// The replicas aren't read from until they passed a health check.
+func newReadReplicas(conf config.StorageOptionsConf) (*readReplicas, error) {
+	replicasConf := conf.ReadReplicas
+	if !replicasConf.Enabled || len(replicasConf.Addrs) == 0 {
+		return nil, nil
+	}
+
+	rs := &readReplicas{interval: time.Duration(replicasConf.HealthCheckInterval) * time.Second}
+	if rs.interval <= 0 {
+		rs.interval = defaultReadReplicaHealthCheckInterval
+	}
+
+	for _, addr := range replicasConf.Addrs {
+		// the replicas are standalone nodes sharing the credentials and TLS settings of the primary
+		replicaConf := conf
+		replicaConf.Host = ""
+		replicaConf.Port = 0
+		replicaConf.Hosts = nil
+		replicaConf.Addrs = []string{addr}
+		replicaConf.MasterName = ""
+		replicaConf.EnableCluster = false
+
+		conn, err := newConnector(replicaConf)
+		if err != nil {
+			rs.disconnect()
+			return nil, err
+		}
+
+		kv, err := tempkv.NewKeyValue(conn)
+		if err != nil {
+			rs.disconnect()
+			return nil, err
+		}
+
+		rs.replicas = append(rs.replicas, &readReplica{addr: addr, conn: conn, kv: kv})
+	}
+
+	return rs, nil
+}
+
+// nearest returns the healthy replica with the lowest latency, or nil when no replica is healthy.
+func (rs *readReplicas) nearest() *readReplica {
+	var nearest *readReplica
+	for _, replica := range rs.replicas {
+		if !replica.healthy.Load() {
+			continue
+		}
+
+		if nearest == nil || replica.latency.Load() < nearest.latency.Load() {
+			nearest = replica
+		}
+	}
+
+	return nearest
+}
+
+// check pings the replicas, recording their availability and latency.
+func (rs *readReplicas) check(ctx context.Context) {
+	for _, replica := range rs.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, rs.interval)
+		start := time.Now()
+		err := replica.conn.Ping(pingCtx)
+		cancel()
+
+		if err != nil {
+			if replica.healthy.Swap(false) {
+				log.WithError(err).WithField("replica", replica.addr).Warning("Redis replica is down, reading from the other replicas or the primary")
+			}
+			continue
+		}
+
+		replica.latency.Store(int64(time.Since(start)))
+		if !replica.healthy.Swap(true) {
+			log.WithField("replica", replica.addr).Info("Reading from the Redis replica")
+		}
+	}
+}
+
+// disconnect closes the connections to the replicas.
+func (rs *readReplicas) disconnect() {
+	for _, replica := range rs.replicas {
+		if err := replica.conn.Disconnect(context.Background()); err != nil {
+			log.WithError(err).WithField("replica", replica.addr).Warning("Could not close the Redis replica connection")
+		}
+	}
+}
+
+// readReplicaCheck checks the read replicas of the handler until ctx is done.
+func (rc *ConnectionHandler) readReplicaCheck(ctx context.Context) {
+	replicas := rc.getReadReplicas()
+	if replicas == nil {
+		return
+	}
+
+	replicas.check(ctx)
+
+	tick := time.NewTicker(replicas.interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			// the replicas are replaced on Reconnect
+			if replicas := rc.getReadReplicas(); replicas != nil {
+				replicas.check(ctx)
+			}
+		}
+	}
+}
+
+func (rc *ConnectionHandler) getReadReplicas() *readReplicas {
+	rc.connectionsMu.RLock()
+	defer rc.connectionsMu.RUnlock()
+
+	return rc.readReplicas
+}
+
+// getReadReplica returns the replica to read from, or nil to read from the primary.
+func (rc *ConnectionHandler) getReadReplica() *readReplica {
+	replicas := rc.getReadReplicas()
+	if replicas == nil {
+		return nil
+	}
+
+	return replicas.nearest()
+}
+
+func isKeyNotFound(err error) bool {
+	return errors.Is(err, temperr.KeyNotFound) || errors.Is(err, redis.Nil) || errors.Is(err, ErrKeyNotFound)
+}
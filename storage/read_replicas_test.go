@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/TykTechnologies/storage/temporal/temperr"
+	tempmocks "github.com/TykTechnologies/storage/temporal/tempmocks"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+func TestRedisCluster_ReadFromReplica(t *testing.T) {
+	// newStore returns a store reading from a replica, both mocked, so the calls not expected fail the test.
+	newStore := func(t *testing.T) (*RedisCluster, *tempmocks.KeyValue, *readReplica) {
+		t.Helper()
+
+		primary := tempmocks.NewKeyValue(t)
+		replica := &readReplica{addr: "replica:6379", kv: tempmocks.NewKeyValue(t)}
+		replica.healthy.Store(true)
+
+		handler := NewConnectionHandler(context.Background())
+		handler.storageUp.Store(true)
+		handler.readReplicas = &readReplicas{replicas: []*readReplica{replica}}
+
+		store := &RedisCluster{KeyPrefix: "apikey-", ReadFromReplica: true, ConnectionHandler: handler, kvStorage: primary}
+		return store, primary, replica
+	}
+
+	replicaKV := func(replica *readReplica) *tempmocks.KeyValue {
+		return replica.kv.(*tempmocks.KeyValue)
+	}
+
+	t.Run("reads from the replica, writes to the primary", func(t *testing.T) {
+		store, primary, replica := newStore(t)
+		replicaKV(replica).On("Get", mock.Anything, "apikey-key").Return("session", nil)
+		replicaKV(replica).On("GetMulti", mock.Anything, []string{"apikey-key"}).Return([]interface{}{"session"}, nil)
+		replicaKV(replica).On("Get", mock.Anything, "raw").Return("value", nil)
+		primary.On("Set", mock.Anything, "apikey-key", "updated", time.Minute).Return(nil)
+		primary.On("Increment", mock.Anything, "quota").Return(int64(1), nil)
+		primary.On("Expire", mock.Anything, "quota", time.Minute).Return(nil)
+		primary.On("Exists", mock.Anything, "apikey-key").Return(true, nil)
+		primary.On("Delete", mock.Anything, "apikey-key").Return(nil)
+
+		assert.True(t, store.ReadsFromReplica())
+
+		value, err := store.GetKey("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "session", value)
+
+		values, err := store.GetMultiKey([]string{"key"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"session"}, values)
+
+		value, err = store.GetRawKey("raw")
+		assert.NoError(t, err)
+		assert.Equal(t, "value", value)
+
+		assert.NoError(t, store.SetKey("key", "updated", 60))
+		assert.Equal(t, int64(1), store.IncrememntWithExpire("quota", 60))
+		assert.True(t, store.DeleteKey("key"))
+	})
+
+	t.Run("a key missing on the replica is read from the primary", func(t *testing.T) {
+		store, primary, replica := newStore(t)
+		replicaKV(replica).On("Get", mock.Anything, "apikey-key").Return("", temperr.KeyNotFound)
+		primary.On("Get", mock.Anything, "apikey-key").Return("session", nil)
+
+		value, err := store.GetKey("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "session", value)
+		assert.True(t, replica.healthy.Load(), "a missing key doesn't take the replica out of the reads")
+	})
+
+	t.Run("a failing replica fails over to the primary", func(t *testing.T) {
+		store, primary, replica := newStore(t)
+		replicaKV(replica).On("Get", mock.Anything, "apikey-key").Return("", errors.New("connection refused")).Once()
+		primary.On("Get", mock.Anything, "apikey-key").Return("session", nil).Twice()
+
+		for i := 0; i < 2; i++ {
+			value, err := store.GetKey("key")
+			assert.NoError(t, err)
+			assert.Equal(t, "session", value)
+		}
+
+		assert.False(t, replica.healthy.Load())
+		assert.False(t, store.ReadsFromReplica())
+	})
+
+	t.Run("the stores not reading from replicas read from the primary", func(t *testing.T) {
+		store, primary, _ := newStore(t)
+		store.ReadFromReplica = false
+		primary.On("Get", mock.Anything, "apikey-key").Return("session", nil)
+
+		value, err := store.GetKey("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "session", value)
+	})
+}
+
+func TestReadReplicas(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		replicas, err := newReadReplicas(config.StorageOptionsConf{
+			ReadReplicas: config.ReadReplicasConf{Addrs: []string{"localhost:6379"}},
+		})
+		assert.NoError(t, err)
+		assert.Nil(t, replicas)
+	})
+
+	t.Run("the nearest healthy replica is read from", func(t *testing.T) {
+		newReplica := func(addr string, latency time.Duration, pingErr error) *readReplica {
+			conn := tempmocks.NewConnector(t)
+			conn.On("Ping", mock.Anything).Run(func(mock.Arguments) { time.Sleep(latency) }).Return(pingErr)
+			return &readReplica{addr: addr, conn: conn}
+		}
+
+		far := newReplica("far:6379", 20*time.Millisecond, nil)
+		near := newReplica("near:6379", 0, nil)
+		down := newReplica("down:6379", 0, errors.New("connection refused"))
+		replicas := &readReplicas{replicas: []*readReplica{far, down, near}, interval: time.Second}
+
+		assert.Nil(t, replicas.nearest(), "the replicas are read from once healthy")
+
+		replicas.check(context.Background())
+		assert.Same(t, near, replicas.nearest())
+
+		near.failed(errors.New("connection refused"))
+		assert.Same(t, far, replicas.nearest())
+	})
+}
@@ -44,11 +44,23 @@ type RedisCluster struct {
 	IsCache     bool
 	IsAnalytics bool
 
+	// HashKeyFunction is the algorithm the keys are hashed with. If empty, a key is hashed with
+	// the algorithm recorded in its token.
+	HashKeyFunction string
+	// HashKeyFallback looks up the keys which aren't found under their HashKeyFunction hash under
+	// the hash of the algorithm recorded in their token, and moves them to the HashKeyFunction hash
+	// when they're set.
+	HashKeyFallback bool
+	// ReadFromReplica reads the keys with GetKey, GetMultiKey and GetRawKey from the nearest healthy read
+	// replica configured for the storage, falling back to the primary when the key isn't found on it.
+	ReadFromReplica bool
+
 	ConnectionHandler *ConnectionHandler
 	// RedisController must remain for compatibility with goplugins
 	RedisController *RedisController
 
 	storageMu        sync.Mutex
+	storageConn      model.Connector
 	kvStorage        model.KeyValue
 	flusherStorage   model.Flusher
 	queueStorage     model.Queue
@@ -59,7 +71,23 @@ type RedisCluster struct {
 
 // SetKeyEx will update a key value in the store if value already exist.
 func (r *RedisCluster) SetKeyEx(keyName, session string, timeout int64) error {
-	return r.SetRawKeyEx(r.fixKey(keyName), session, timeout)
+	fallbackKey, hasFallback := r.fallbackKey(keyName)
+	if !hasFallback {
+		return r.SetRawKeyEx(r.fixKey(keyName), session, timeout)
+	}
+
+	storage, err := r.kv()
+	if err != nil {
+		return err
+	}
+
+	updated, err := storage.SetIfExist(context.Background(), r.fixKey(keyName), session, time.Duration(timeout)*time.Second)
+	if err != nil || updated {
+		return err
+	}
+
+	_, err = storage.SetIfExist(context.Background(), fallbackKey, session, time.Duration(timeout)*time.Second)
+	return err
 }
 
 // SetRawKeyEx will update a raw key value in the store if value already exist.
@@ -119,6 +147,7 @@ func (r *RedisCluster) kv() (model.KeyValue, error) {
 
 	r.storageMu.Lock()
 	defer r.storageMu.Unlock()
+	r.dropStaleStorage()
 
 	if r.kvStorage != nil {
 		return r.kvStorage, nil
@@ -134,6 +163,7 @@ func (r *RedisCluster) kv() (model.KeyValue, error) {
 		return nil, err
 	}
 	r.kvStorage = kvStorage
+	r.storageConn = conn
 
 	return kvStorage, nil
 }
@@ -145,6 +175,8 @@ func (r *RedisCluster) flusher() (model.Flusher, error) {
 
 	r.storageMu.Lock()
 	defer r.storageMu.Unlock()
+	r.dropStaleStorage()
+
 	if r.flusherStorage != nil {
 		return r.flusherStorage, nil
 	}
@@ -159,6 +191,7 @@ func (r *RedisCluster) flusher() (model.Flusher, error) {
 		return nil, err
 	}
 	r.flusherStorage = flusherStorage
+	r.storageConn = conn
 
 	return flusherStorage, nil
 }
@@ -170,6 +203,7 @@ func (r *RedisCluster) queue() (model.Queue, error) {
 
 	r.storageMu.Lock()
 	defer r.storageMu.Unlock()
+	r.dropStaleStorage()
 
 	if r.queueStorage != nil {
 		return r.queueStorage, nil
@@ -185,6 +219,7 @@ func (r *RedisCluster) queue() (model.Queue, error) {
 		return nil, err
 	}
 	r.queueStorage = queueStorage
+	r.storageConn = conn
 
 	return queueStorage, nil
 }
@@ -196,6 +231,8 @@ func (r *RedisCluster) list() (model.List, error) {
 
 	r.storageMu.Lock()
 	defer r.storageMu.Unlock()
+	r.dropStaleStorage()
+
 	if r.listStorage != nil {
 		return r.listStorage, nil
 	}
@@ -210,6 +247,7 @@ func (r *RedisCluster) list() (model.List, error) {
 		return nil, err
 	}
 	r.listStorage = listStorage
+	r.storageConn = conn
 
 	return listStorage, nil
 }
@@ -220,6 +258,8 @@ func (r *RedisCluster) set() (model.Set, error) {
 	}
 	r.storageMu.Lock()
 	defer r.storageMu.Unlock()
+	r.dropStaleStorage()
+
 	if r.setStorage != nil {
 		return r.setStorage, nil
 	}
@@ -234,6 +274,7 @@ func (r *RedisCluster) set() (model.Set, error) {
 		return nil, err
 	}
 	r.setStorage = setStorage
+	r.storageConn = conn
 
 	return setStorage, nil
 }
@@ -244,6 +285,8 @@ func (r *RedisCluster) sortedSet() (model.SortedSet, error) {
 	}
 	r.storageMu.Lock()
 	defer r.storageMu.Unlock()
+	r.dropStaleStorage()
+
 	if r.sortedSetStorage != nil {
 		return r.sortedSetStorage, nil
 	}
@@ -258,22 +301,80 @@ func (r *RedisCluster) sortedSet() (model.SortedSet, error) {
 		return nil, err
 	}
 	r.sortedSetStorage = sortedSetStorage
+	r.storageConn = conn
 
 	return sortedSetStorage, nil
 }
 
+// dropStaleStorage drops the cached storages when the connection handler reconnected, e.g. after a
+// Redis password rotation, so they're created again on the new connection. storageMu must be held.
+func (r *RedisCluster) dropStaleStorage() {
+	conn := r.getConnectionHandler().getConnection(r.IsCache, r.IsAnalytics)
+	if r.storageConn == nil || conn == nil || conn == r.storageConn {
+		return
+	}
+
+	r.storageConn = nil
+	r.kvStorage = nil
+	r.flusherStorage = nil
+	r.queueStorage = nil
+	r.listStorage = nil
+	r.setStorage = nil
+	r.sortedSetStorage = nil
+}
+
 func (r *RedisCluster) hashKey(in string) string {
 	if !r.HashKeys {
 		// Not hashing? Return the raw key
 		return in
 	}
-	return HashStr(in)
+	return HashStr(in, r.HashKeyFunction)
 }
 
 func (r *RedisCluster) fixKey(keyName string) string {
 	return r.KeyPrefix + r.hashKey(keyName)
 }
 
+// fallbackKey returns the prefixed key hashed with the algorithm recorded in its token, which
+// the key is looked up under when HashKeyFallback is set. It reports false when there's no
+// such key to look up, the key isn't hashed or the algorithms are the same.
+func (r *RedisCluster) fallbackKey(keyName string) (string, bool) {
+	if !r.HashKeys || !r.HashKeyFallback || r.HashKeyFunction == "" {
+		return "", false
+	}
+
+	fallbackKey := r.KeyPrefix + HashStr(keyName)
+	return fallbackKey, fallbackKey != r.fixKey(keyName)
+}
+
+// withFallbackKeys appends the fallback keys of keys to keyNames. The returned slice holds
+// the index of the fallback of each key in keyNames, -1 if the key has none.
+func (r *RedisCluster) withFallbackKeys(keys, keyNames []string) ([]string, []int) {
+	fallbacks := make([]int, len(keys))
+	for index, val := range keys {
+		fallbacks[index] = -1
+		if fallbackKey, ok := r.fallbackKey(val); ok {
+			fallbacks[index] = len(keyNames)
+			keyNames = append(keyNames, fallbackKey)
+		}
+	}
+
+	return keyNames, fallbacks
+}
+
+// mergeFallbackValues returns the values of the keys, using the value of the fallback key
+// when a key has no value. See withFallbackKeys.
+func mergeFallbackValues(values []string, fallbacks []int) []string {
+	merged := values[:len(fallbacks)]
+	for index, fallback := range fallbacks {
+		if merged[index] == "" && fallback >= 0 {
+			merged[index] = values[fallback]
+		}
+	}
+
+	return merged
+}
+
 func (r *RedisCluster) cleanKey(keyName string) string {
 	return strings.Replace(keyName, r.KeyPrefix, "", 1)
 }
@@ -285,15 +386,39 @@ func (r *RedisCluster) up() error {
 	return nil
 }
 
+// ReadsFromReplica reports whether the keys are read from a Redis read replica, so they may be stale.
+func (r *RedisCluster) ReadsFromReplica() bool {
+	return r.readReplica() != nil
+}
+
+// readReplica returns the replica GetKey, GetMultiKey and GetRawKey read from, or nil to read from the primary.
+// The other reads, e.g. of the rate limits and quotas, and all the writes go to the primary.
+func (r *RedisCluster) readReplica() *readReplica {
+	if !r.ReadFromReplica || r.IsCache || r.IsAnalytics || r.up() != nil {
+		return nil
+	}
+
+	return r.getConnectionHandler().getReadReplica()
+}
+
 // GetKey will retrieve a key from the database
 func (r *RedisCluster) GetKey(keyName string) (string, error) {
+	if replica := r.readReplica(); replica != nil {
+		value, err := r.getKey(replica.kv, keyName)
+		if err == nil {
+			return value, nil
+		}
+		// the key may not be replicated yet
+		replica.failed(err)
+	}
+
 	storage, err := r.kv()
 	if err != nil {
 		log.Error(err)
 		return "", err
 	}
 
-	value, err := storage.Get(context.Background(), r.fixKey(keyName))
+	value, err := r.getKey(storage, keyName)
 	if err != nil {
 		if !errors.Is(err, redis.Nil) {
 			log.Debug("Error trying to get value:", err)
@@ -304,24 +429,56 @@ func (r *RedisCluster) GetKey(keyName string) (string, error) {
 	return value, nil
 }
 
+func (r *RedisCluster) getKey(storage model.KeyValue, keyName string) (string, error) {
+	value, err := storage.Get(context.Background(), r.fixKey(keyName))
+	if err != nil {
+		if fallbackKey, ok := r.fallbackKey(keyName); ok {
+			value, err = storage.Get(context.Background(), fallbackKey)
+		}
+	}
+
+	return value, err
+}
+
 // GetMultiKey gets multiple keys from the database
 func (r *RedisCluster) GetMultiKey(keys []string) ([]string, error) {
+	if replica := r.readReplica(); replica != nil {
+		values, err := r.getMultiKey(replica.kv, keys)
+		if err == nil {
+			return values, nil
+		}
+		replica.failed(err)
+	}
+
 	storage, err := r.kv()
 	if err != nil {
 		log.Error(err)
 		return nil, err
 	}
 
+	values, err := r.getMultiKey(storage, keys)
+	if err != nil {
+		if !errors.Is(err, ErrKeyNotFound) {
+			log.WithError(err).Debug("Error trying to get value")
+		}
+		return nil, ErrKeyNotFound
+	}
+
+	return values, nil
+}
+
+func (r *RedisCluster) getMultiKey(storage model.KeyValue, keys []string) ([]string, error) {
 	keyNames := make([]string, len(keys))
 	copy(keyNames, keys)
 	for index, val := range keyNames {
 		keyNames[index] = r.fixKey(val)
 	}
 
+	keyNames, fallbacks := r.withFallbackKeys(keys, keyNames)
+
 	values, err := storage.GetMulti(context.Background(), keyNames)
 	if err != nil {
-		log.WithError(err).Debug("Error trying to get value")
-		return nil, ErrKeyNotFound
+		return nil, err
 	}
 	result := make([]string, 0)
 	for _, val := range values {
@@ -331,6 +488,7 @@ func (r *RedisCluster) GetMultiKey(keys []string) ([]string, error) {
 		}
 		result = append(result, strVal)
 	}
+	result = mergeFallbackValues(result, fallbacks)
 
 	for _, val := range result {
 		if val != "" {
@@ -341,6 +499,85 @@ func (r *RedisCluster) GetMultiKey(keys []string) ([]string, error) {
 	return nil, ErrKeyNotFound
 }
 
+// GetMultiKeyAndTTL fetches the values of keys, prefixed and hashed like GetMultiKey does, together with the
+// remaining TTLs of the raw ttlKeys, in a single round trip. On Redis Cluster the keys are fetched with one MGET
+// per hash slot, all in the same pipeline. A missing key has an empty value, the TTLs follow PTTL: -2 if the key
+// doesn't exist and -1 if it has no expiry.
+func (r *RedisCluster) GetMultiKeyAndTTL(keys, ttlKeys []string) ([]string, []time.Duration, error) {
+	client, err := r.Client()
+	if err != nil {
+		log.Error(err)
+		return nil, nil, err
+	}
+
+	keyNames := make([]string, len(keys))
+	for index, val := range keys {
+		keyNames[index] = r.fixKey(val)
+	}
+
+	keyNames, fallbacks := r.withFallbackKeys(keys, keyNames)
+
+	// a single MGET can only read keys living on the same hash slot of a cluster
+	var groups [][]int
+	if _, isCluster := client.(*redis.ClusterClient); isCluster {
+		groups = groupKeysBySlot(keyNames)
+	} else {
+		group := make([]int, len(keyNames))
+		for index := range group {
+			group[index] = index
+		}
+		groups = [][]int{group}
+	}
+
+	ctx := context.Background()
+	mgets := make([]*redis.SliceCmd, len(groups))
+	pttls := make([]*redis.DurationCmd, len(ttlKeys))
+
+	_, err = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for index, group := range groups {
+			if len(group) == 0 {
+				continue
+			}
+
+			groupKeys := make([]string, len(group))
+			for i, keyIndex := range group {
+				groupKeys[i] = keyNames[keyIndex]
+			}
+			mgets[index] = pipe.MGet(ctx, groupKeys...)
+		}
+
+		for index, key := range ttlKeys {
+			pttls[index] = pipe.PTTL(ctx, key)
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.WithError(err).Debug("Error trying to get values and TTLs")
+		return nil, nil, err
+	}
+
+	values := make([]string, len(keyNames))
+	for index, group := range groups {
+		if mgets[index] == nil {
+			continue
+		}
+
+		for i, val := range mgets[index].Val() {
+			if strVal, ok := val.(string); ok {
+				values[group[i]] = strVal
+			}
+		}
+	}
+
+	ttls := make([]time.Duration, len(ttlKeys))
+	for index, cmd := range pttls {
+		ttls[index] = cmd.Val()
+	}
+
+	return mergeFallbackValues(values, fallbacks), ttls, nil
+}
+
 func (r *RedisCluster) GetKeyTTL(keyName string) (ttl int64, err error) {
 	storage, err := r.kv()
 	if err != nil {
@@ -348,10 +585,25 @@ func (r *RedisCluster) GetKeyTTL(keyName string) (ttl int64, err error) {
 		return 0, err
 	}
 
-	return storage.TTL(context.Background(), r.fixKey(keyName))
+	ttl, err = storage.TTL(context.Background(), r.fixKey(keyName))
+	if err == nil && ttl == -2 {
+		if fallbackKey, ok := r.fallbackKey(keyName); ok {
+			return storage.TTL(context.Background(), fallbackKey)
+		}
+	}
+
+	return ttl, err
 }
 
 func (r *RedisCluster) GetRawKey(keyName string) (string, error) {
+	if replica := r.readReplica(); replica != nil {
+		value, err := replica.kv.Get(context.Background(), keyName)
+		if err == nil {
+			return value, nil
+		}
+		replica.failed(err)
+	}
+
 	storage, err := r.kv()
 	if err != nil {
 		log.Error(err)
@@ -380,12 +632,27 @@ func (r *RedisCluster) SetExp(keyName string, timeout int64) error {
 		return err
 	}
 
+	if fallbackKey, ok := r.fallbackKey(keyName); ok {
+		if err := storage.Expire(context.Background(), fallbackKey, time.Duration(timeout)*time.Second); err != nil {
+			return err
+		}
+	}
+
 	return storage.Expire(context.Background(), r.fixKey(keyName), time.Duration(timeout)*time.Second)
 }
 
 // SetKey will create (or update) a key value in the store
 func (r *RedisCluster) SetKey(keyName, session string, timeout int64) error {
-	return r.SetRawKey(r.fixKey(keyName), session, timeout)
+	if err := r.SetRawKey(r.fixKey(keyName), session, timeout); err != nil {
+		return err
+	}
+
+	// the key is moved to its HashKeyFunction hash
+	if fallbackKey, ok := r.fallbackKey(keyName); ok {
+		r.DeleteRawKey(fallbackKey)
+	}
+
+	return nil
 }
 
 func (r *RedisCluster) SetRawKey(keyName, session string, timeout int64) error {
@@ -521,6 +788,15 @@ func (r *RedisCluster) DeleteKey(keyName string) bool {
 		return false
 	}
 
+	if fallbackKey, ok := r.fallbackKey(keyName); ok {
+		deleted, err := storage.DeleteKeys(context.Background(), []string{r.fixKey(keyName), fallbackKey})
+		if err != nil {
+			log.WithError(err).Error("Error trying to delete key")
+			return false
+		}
+		return deleted > 0
+	}
+
 	exist, err := storage.Exists(context.Background(), r.fixKey(keyName))
 	if err != nil || !exist {
 		return false
@@ -585,6 +861,56 @@ func (r *RedisCluster) DeleteScanMatch(pattern string) bool {
 	return true
 }
 
+// DeleteScanMatchFunc deletes the keys matching the pattern for which match returns true.
+// Each SCAN iteration checks about count keys and the scan stops once about limit keys were checked.
+// It returns the number of deleted keys and whether the scan stopped at the limit.
+func (r *RedisCluster) DeleteScanMatchFunc(pattern string, count, limit int64, match func(key string) bool) (int64, bool, error) {
+	storage, err := r.kv()
+	if err != nil {
+		log.Error(err)
+		return 0, false, err
+	}
+
+	var (
+		ctx     = context.Background()
+		cursors map[string]uint64
+		scanned int64
+		deleted int64
+		keys    []string
+		more    = true
+	)
+
+	for more {
+		if scanned >= limit {
+			return deleted, true, nil
+		}
+
+		keys, cursors, more, err = storage.GetKeysWithOpts(ctx, pattern, cursors, count)
+		if err != nil {
+			return deleted, false, err
+		}
+		scanned += count
+
+		var victims []string
+		for _, key := range keys {
+			if match(key) {
+				victims = append(victims, key)
+			}
+		}
+		if len(victims) == 0 {
+			continue
+		}
+
+		n, err := storage.DeleteKeys(ctx, victims)
+		deleted += n
+		if err != nil {
+			return deleted, false, err
+		}
+	}
+
+	return deleted, false, nil
+}
+
 func (r *RedisCluster) DeleteRawKeys(keys []string) bool {
 	storage, err := r.kv()
 	if err != nil {
@@ -608,11 +934,13 @@ func (r *RedisCluster) DeleteKeys(keys []string) bool {
 		return false
 	}
 
+	keyNames := make([]string, len(keys))
 	for i, v := range keys {
-		keys[i] = r.fixKey(v)
+		keyNames[i] = r.fixKey(v)
 	}
+	keyNames, _ = r.withFallbackKeys(keys, keyNames)
 
-	deleted, err := storage.DeleteKeys(context.Background(), keys)
+	deleted, err := storage.DeleteKeys(context.Background(), keyNames)
 	if err != nil {
 		log.WithError(err).Error("Error trying to delete keys ")
 		return false
@@ -731,6 +1059,42 @@ func (r *RedisCluster) AppendToSet(keyName, value string) {
 	}
 }
 
+// AppendToCappedList appends the value to the list identified by keyName and removes
+// the oldest elements, so the list holds at most maxLen elements.
+func (r *RedisCluster) AppendToCappedList(keyName, value string, maxLen int64) error {
+	fixedKey := r.fixKey(keyName)
+	log.WithField("fixedKey", fixedKey).Debug("Appending to capped list")
+
+	storage, err := r.list()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	ctx := context.Background()
+	if err := storage.Append(ctx, false, fixedKey, []byte(value)); err != nil {
+		log.WithError(err).Error("Error trying to append to capped list")
+		return err
+	}
+
+	length, err := storage.Length(ctx, fixedKey)
+	if err != nil {
+		log.WithError(err).Error("LLEN command failed")
+		return err
+	}
+
+	if length <= maxLen {
+		return nil
+	}
+
+	if _, err := storage.Pop(ctx, fixedKey, length-maxLen); err != nil {
+		log.WithError(err).Error("Error trying to trim capped list")
+		return err
+	}
+
+	return nil
+}
+
 // Exists check if keyName exists
 func (r *RedisCluster) Exists(keyName string) (bool, error) {
 	fixedKey := r.fixKey(keyName)
@@ -743,6 +1107,11 @@ func (r *RedisCluster) Exists(keyName string) (bool, error) {
 	}
 
 	exists, err := storage.Exists(context.Background(), fixedKey)
+	if err == nil && !exists {
+		if fallbackKey, ok := r.fallbackKey(keyName); ok {
+			exists, err = storage.Exists(context.Background(), fallbackKey)
+		}
+	}
 	if err != nil {
 		log.Error("Error trying to check if key exists: ", err)
 		return false, err
@@ -1067,6 +1436,34 @@ func (r *RedisCluster) GetSortedSetRange(keyName, scoreFrom, scoreTo string) ([]
 	return elements, scores, nil
 }
 
+// RemoveFromSortedSet removes the values from the sorted set identified by keyName in a single command
+func (r *RedisCluster) RemoveFromSortedSet(keyName string, values ...string) error {
+	fixedKey := r.fixKey(keyName)
+	logEntry := logrus.Fields{
+		"keyName":  keyName,
+		"fixedKey": fixedKey,
+	}
+	log.WithFields(logEntry).Debug("Removing values from sorted set")
+
+	singleton, err := r.Client()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	members := make([]interface{}, len(values))
+	for i, v := range values {
+		members[i] = v
+	}
+
+	if err := singleton.ZRem(context.Background(), fixedKey, members...).Err(); err != nil {
+		log.WithFields(logEntry).WithError(err).Error("ZREM command failed")
+		return err
+	}
+
+	return nil
+}
+
 // RemoveSortedSetRange removes range of elements from sorted set identified by keyName
 func (r *RedisCluster) RemoveSortedSetRange(keyName, scoreFrom, scoreTo string) error {
 	fixedKey := r.fixKey(keyName)
@@ -3,7 +3,11 @@ package storage
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -1174,6 +1178,71 @@ func TestDeleteScanMatch(t *testing.T) {
 	})
 }
 
+func TestDeleteScanMatchFunc(t *testing.T) {
+	isProduct := func(key string) bool {
+		return strings.HasPrefix(key, "product")
+	}
+
+	t.Run("storage disconnected", func(t *testing.T) {
+		storage := &RedisCluster{ConnectionHandler: rc}
+		storage.ConnectionHandler.storageUp.Store(false)
+		mockKv := tempmocks.NewKeyValue(t)
+		storage.kvStorage = mockKv
+		defer storage.ConnectionHandler.storageUp.Store(true)
+
+		_, _, err := storage.DeleteScanMatchFunc("*", 10, 100, isProduct)
+		assert.Error(t, err)
+
+		mockKv.AssertExpectations(t)
+	})
+	t.Run("deletes matching keys of all iterations", func(t *testing.T) {
+		storage := &RedisCluster{ConnectionHandler: rc}
+		mockKv := tempmocks.NewKeyValue(t)
+		storage.kvStorage = mockKv
+
+		cursors := map[string]uint64{"node": 5}
+		mockKv.On("GetKeysWithOpts", mock.Anything, "*", map[string]uint64(nil), int64(10)).
+			Return([]string{"product-1", "order-1"}, cursors, true, nil).Once()
+		mockKv.On("GetKeysWithOpts", mock.Anything, "*", cursors, int64(10)).
+			Return([]string{"order-2", "product-2", "product-3"}, map[string]uint64{"node": 0}, false, nil).Once()
+		mockKv.On("DeleteKeys", mock.Anything, []string{"product-1"}).Return(int64(1), nil).Once()
+		mockKv.On("DeleteKeys", mock.Anything, []string{"product-2", "product-3"}).Return(int64(2), nil).Once()
+
+		deleted, truncated, err := storage.DeleteScanMatchFunc("*", 10, 100, isProduct)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), deleted)
+		assert.False(t, truncated)
+		mockKv.AssertExpectations(t)
+	})
+	t.Run("stops at the limit", func(t *testing.T) {
+		storage := &RedisCluster{ConnectionHandler: rc}
+		mockKv := tempmocks.NewKeyValue(t)
+		storage.kvStorage = mockKv
+
+		mockKv.On("GetKeysWithOpts", mock.Anything, "*", map[string]uint64(nil), int64(10)).
+			Return([]string{"product-1"}, map[string]uint64{"node": 5}, true, nil).Once()
+		mockKv.On("DeleteKeys", mock.Anything, []string{"product-1"}).Return(int64(1), nil).Once()
+
+		deleted, truncated, err := storage.DeleteScanMatchFunc("*", 10, 10, isProduct)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+		assert.True(t, truncated)
+		mockKv.AssertExpectations(t)
+	})
+	t.Run("scan error", func(t *testing.T) {
+		storage := &RedisCluster{ConnectionHandler: rc}
+		mockKv := tempmocks.NewKeyValue(t)
+		storage.kvStorage = mockKv
+
+		mockKv.On("GetKeysWithOpts", mock.Anything, "*", map[string]uint64(nil), int64(10)).
+			Return(nil, nil, false, errors.New("scan failed")).Once()
+
+		_, _, err := storage.DeleteScanMatchFunc("*", 10, 100, isProduct)
+		assert.Error(t, err)
+		mockKv.AssertExpectations(t)
+	})
+}
+
 func TestDeleteRawKeys(t *testing.T) {
 	t.Run("storage disconnected", func(t *testing.T) {
 		storage := &RedisCluster{ConnectionHandler: rc}
@@ -1399,6 +1468,52 @@ func TestAppendToSet(t *testing.T) {
 	})
 }
 
+func TestAppendToCappedList(t *testing.T) {
+	t.Run("under the cap", func(t *testing.T) {
+		storage := &RedisCluster{ConnectionHandler: rc}
+		mockList := tempmocks.NewList(t)
+		storage.listStorage = mockList
+		fixedKey := storage.fixKey("key")
+		mockList.On("Append", mock.Anything, false, fixedKey, []byte("value")).Return(nil)
+		mockList.On("Length", mock.Anything, fixedKey).Return(int64(3), nil)
+
+		assert.NoError(t, storage.AppendToCappedList("key", "value", 3))
+		mockList.AssertExpectations(t)
+	})
+	t.Run("over the cap", func(t *testing.T) {
+		storage := &RedisCluster{ConnectionHandler: rc}
+		mockList := tempmocks.NewList(t)
+		storage.listStorage = mockList
+		fixedKey := storage.fixKey("key")
+		mockList.On("Append", mock.Anything, false, fixedKey, []byte("value")).Return(nil)
+		mockList.On("Length", mock.Anything, fixedKey).Return(int64(5), nil)
+		mockList.On("Pop", mock.Anything, fixedKey, int64(2)).Return([]string{"a", "b"}, nil)
+
+		assert.NoError(t, storage.AppendToCappedList("key", "value", 3))
+		mockList.AssertExpectations(t)
+	})
+	t.Run("error appending", func(t *testing.T) {
+		storage := &RedisCluster{ConnectionHandler: rc}
+		mockList := tempmocks.NewList(t)
+		storage.listStorage = mockList
+		fixedKey := storage.fixKey("key")
+		mockList.On("Append", mock.Anything, false, fixedKey, []byte("value")).Return(errors.New("error appending"))
+
+		assert.Error(t, storage.AppendToCappedList("key", "value", 3))
+		mockList.AssertExpectations(t)
+	})
+	t.Run("storage disconnected", func(t *testing.T) {
+		storage := &RedisCluster{ConnectionHandler: rc}
+		storage.ConnectionHandler.storageUp.Store(false)
+		defer storage.ConnectionHandler.storageUp.Store(true)
+		mockList := tempmocks.NewList(t)
+		storage.listStorage = mockList
+
+		assert.Error(t, storage.AppendToCappedList("key", "value", 3))
+		mockList.AssertExpectations(t)
+	})
+}
+
 func TestExists(t *testing.T) {
 	t.Run("exists true", func(t *testing.T) {
 		storage := &RedisCluster{ConnectionHandler: rc}
@@ -1962,6 +2077,23 @@ func TestRemoveSortedSetRange(t *testing.T) {
 	})
 }
 
+func TestRemoveFromSortedSet(t *testing.T) {
+	storage := &RedisCluster{KeyPrefix: "test-", ConnectionHandler: rc}
+	keyName := "remove-from-sorted-set"
+
+	// the members share the score, only the listed ones are removed
+	storage.AddToSortedSet(keyName, "a", 10)
+	storage.AddToSortedSet(keyName, "b", 10)
+	storage.AddToSortedSet(keyName, "c", 10)
+
+	assert.NoError(t, storage.RemoveFromSortedSet(keyName, "a", "c", "missing"))
+
+	values, scores, err := storage.GetSortedSetRange(keyName, "-inf", "+inf")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b"}, values)
+	assert.Equal(t, []float64{10}, scores)
+}
+
 func TestScanKeys(t *testing.T) {
 	t.Run("scan keys success", func(t *testing.T) {
 		storage := &RedisCluster{ConnectionHandler: rc}
@@ -2155,3 +2287,213 @@ func TestPublish(t *testing.T) {
 		assert.Equal(t, expectedErr, err)
 	})
 }
+
+func TestGetMultiKeyAndTTL(t *testing.T) {
+	for _, hashKeys := range []bool{false, true} {
+		t.Run(fmt.Sprintf("hash keys %v", hashKeys), func(t *testing.T) {
+			r := &RedisCluster{KeyPrefix: "multi-ttl-", HashKeys: hashKeys, ConnectionHandler: rc}
+			client, err := r.Client()
+			assert.NoError(t, err)
+
+			ctx := context.Background()
+			assert.NoError(t, r.SetKey("session", "value", 0))
+			assert.NoError(t, client.Set(ctx, "quota-expiring", "1", time.Minute).Err())
+			assert.NoError(t, client.Set(ctx, "quota-persistent", "1", 0).Err())
+			defer func() {
+				r.DeleteKey("session")
+				client.Del(ctx, "quota-expiring", "quota-persistent")
+			}()
+
+			values, ttls, err := r.GetMultiKeyAndTTL(
+				[]string{"session", "missing"},
+				[]string{"quota-expiring", "quota-persistent", "quota-missing"},
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"value", ""}, values)
+			assert.Len(t, ttls, 3)
+			assert.InDelta(t, time.Minute, ttls[0], float64(5*time.Second))
+			assert.Equal(t, time.Duration(-1), ttls[1])
+			assert.Equal(t, time.Duration(-2), ttls[2])
+		})
+	}
+
+	t.Run("storage disconnected", func(t *testing.T) {
+		r := &RedisCluster{ConnectionHandler: rc}
+		r.ConnectionHandler.storageUp.Store(false)
+		defer r.ConnectionHandler.storageUp.Store(true)
+
+		_, _, err := r.GetMultiKeyAndTTL([]string{"key"}, nil)
+		assert.Equal(t, ErrRedisIsDown, err)
+	})
+}
+
+func TestRedisCluster_HashKeyFallback(t *testing.T) {
+	const prefix = "hash-fallback-"
+
+	legacy := &RedisCluster{KeyPrefix: prefix, HashKeys: true, ConnectionHandler: rc}
+	migrating := &RedisCluster{KeyPrefix: prefix, HashKeys: true, HashKeyFunction: HashSha256, HashKeyFallback: true, ConnectionHandler: rc}
+	migrated := &RedisCluster{KeyPrefix: prefix, HashKeys: true, HashKeyFunction: HashSha256, ConnectionHandler: rc}
+
+	legacyKey := prefix + HashStr("key")
+	sha256Key := prefix + HashStr("key", HashSha256)
+
+	t.Run("keys are looked up under both hashes", func(t *testing.T) {
+		assert.NoError(t, legacy.SetKey("key", "legacy", 0))
+		defer legacy.DeleteKey("key")
+
+		value, err := migrating.GetKey("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "legacy", value)
+
+		values, err := migrating.GetMultiKey([]string{"missing", "key"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"", "legacy"}, values)
+
+		values, _, err = migrating.GetMultiKeyAndTTL([]string{"key"}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"legacy"}, values)
+
+		exists, err := migrating.Exists("key")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+
+		_, err = migrated.GetKey("key")
+		assert.ErrorIs(t, err, ErrKeyNotFound, "no fallback once the migration is complete")
+	})
+
+	t.Run("the new hash is read first", func(t *testing.T) {
+		assert.NoError(t, legacy.SetKey("key", "legacy", 0))
+		assert.NoError(t, migrated.SetKey("key", "migrated", 0))
+		defer migrating.DeleteKey("key")
+
+		value, err := migrating.GetKey("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "migrated", value)
+	})
+
+	t.Run("set moves the key to the new hash", func(t *testing.T) {
+		assert.NoError(t, legacy.SetKey("key", "legacy", 0))
+		defer migrating.DeleteKey("key")
+
+		assert.NoError(t, migrating.SetKeyEx("key", "updated", 0))
+		value, err := legacy.GetRawKey(legacyKey)
+		assert.NoError(t, err)
+		assert.Equal(t, "updated", value, "a key is updated in place")
+
+		assert.NoError(t, migrating.SetKey("key", "saved", 0))
+
+		_, err = legacy.GetRawKey(legacyKey)
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+		value, err = legacy.GetRawKey(sha256Key)
+		assert.NoError(t, err)
+		assert.Equal(t, "saved", value)
+	})
+
+	t.Run("delete removes both hashes", func(t *testing.T) {
+		assert.NoError(t, legacy.SetKey("key", "legacy", 0))
+		assert.NoError(t, migrated.SetKey("key", "migrated", 0))
+
+		assert.True(t, migrating.DeleteKey("key"))
+		assert.False(t, migrating.DeleteKey("key"))
+
+		exists, err := migrating.Exists("key")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+// roundTripCounter counts the commands sent to Redis and the round trips needed to send them.
+type roundTripCounter struct {
+	enabled    atomic.Bool
+	commands   atomic.Int64
+	roundTrips atomic.Int64
+}
+
+func (c *roundTripCounter) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (c *roundTripCounter) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if c.enabled.Load() {
+			c.commands.Add(1)
+			c.roundTrips.Add(1)
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (c *roundTripCounter) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if c.enabled.Load() {
+			c.commands.Add(int64(len(cmds)))
+			c.roundTrips.Add(1)
+		}
+		return next(ctx, cmds)
+	}
+}
+
+func (c *roundTripCounter) start() {
+	c.commands.Store(0)
+	c.roundTrips.Store(0)
+	c.enabled.Store(true)
+}
+
+func (c *roundTripCounter) report(b *testing.B) {
+	b.Helper()
+	c.enabled.Store(false)
+	b.ReportMetric(float64(c.commands.Load())/float64(b.N), "commands/op")
+	b.ReportMetric(float64(c.roundTrips.Load())/float64(b.N), "roundtrips/op")
+}
+
+var (
+	benchRoundTripCounter     = &roundTripCounter{}
+	benchRoundTripCounterOnce sync.Once
+)
+
+// BenchmarkGetMultiKeyAndTTL compares reading a session and the TTL of its quota key one by one,
+// as the auth and quota checks used to, with reading both in a single round trip.
+func BenchmarkGetMultiKeyAndTTL(b *testing.B) {
+	r := &RedisCluster{KeyPrefix: "bench-apikey-", HashKeys: true, ConnectionHandler: rc}
+	client, err := r.Client()
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchRoundTripCounterOnce.Do(func() {
+		client.AddHook(benchRoundTripCounter)
+	})
+
+	ctx := context.Background()
+	quotaKey := "quota-" + HashStr("session")
+	if err := r.SetKey("session", `{"quota_max":100}`, 0); err != nil {
+		b.Fatal(err)
+	}
+	client.Set(ctx, quotaKey, "1", time.Minute)
+	defer func() {
+		r.DeleteKey("session")
+		client.Del(ctx, quotaKey)
+	}()
+
+	b.Run("sequential", func(b *testing.B) {
+		benchRoundTripCounter.start()
+		for i := 0; i < b.N; i++ {
+			if _, err := r.GetKey("session"); err != nil {
+				b.Fatal(err)
+			}
+			if err := client.PTTL(ctx, quotaKey).Err(); err != nil {
+				b.Fatal(err)
+			}
+		}
+		benchRoundTripCounter.report(b)
+	})
+
+	b.Run("pipelined", func(b *testing.B) {
+		benchRoundTripCounter.start()
+		for i := 0; i < b.N; i++ {
+			if _, _, err := r.GetMultiKeyAndTTL([]string{"session"}, []string{quotaKey}); err != nil {
+				b.Fatal(err)
+			}
+		}
+		benchRoundTripCounter.report(b)
+	})
+}
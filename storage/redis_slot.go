@@ -0,0 +1,58 @@
+package storage
+
+import "strings"
+
+// redisClusterSlots is the number of hash slots of a Redis Cluster.
+const redisClusterSlots = 16384
+
+// KeyHashSlot returns the Redis Cluster hash slot of a key. When the key has a non-empty
+// hash tag, the part between the first `{` and the next `}`, only the tag is hashed.
+func KeyHashSlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	return int(crc16(key)) % redisClusterSlots
+}
+
+// crc16 implements CRC16-CCITT (XMODEM), the checksum Redis Cluster uses to map keys to slots.
+func crc16(key string) uint16 {
+	var crc uint16
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
+
+// groupKeysBySlot splits keys into groups of keys sharing a hash slot, so every group can be
+// fetched with a single MGET on Redis Cluster. The groups hold the indexes of the keys and are
+// ordered by the first appearance of their slot.
+func groupKeysBySlot(keys []string) [][]int {
+	groups := make([][]int, 0, 1)
+	groupBySlot := make(map[int]int, len(keys))
+
+	for i, key := range keys {
+		slot := KeyHashSlot(key)
+
+		group, ok := groupBySlot[slot]
+		if !ok {
+			group = len(groups)
+			groupBySlot[slot] = group
+			groups = append(groups, nil)
+		}
+
+		groups[group] = append(groups[group], i)
+	}
+
+	return groups
+}
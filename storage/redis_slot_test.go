@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyHashSlot(t *testing.T) {
+	tests := []struct {
+		key  string
+		slot int
+	}{
+		{key: "123456789", slot: 0x31C3},
+		{key: "foo", slot: 12182},
+		{key: "bar", slot: 5061},
+		{key: "{user1000}.following", slot: KeyHashSlot("user1000")},
+		{key: "foo{bar}{zap}", slot: KeyHashSlot("bar")},
+		{key: "foo{{bar}}zap", slot: KeyHashSlot("{bar")},
+		// an empty hash tag hashes the whole key
+		{key: "foo{}{bar}", slot: int(crc16("foo{}{bar}")) % redisClusterSlots},
+		{key: "foo{bar", slot: int(crc16("foo{bar")) % redisClusterSlots},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.key, func(t *testing.T) {
+			assert.Equal(t, tc.slot, KeyHashSlot(tc.key))
+		})
+	}
+}
+
+func TestGroupKeysBySlot(t *testing.T) {
+	t.Run("keys on different slots", func(t *testing.T) {
+		groups := groupKeysBySlot([]string{"foo", "bar", "{foo}.quota", "{bar}.quota", "baz"})
+		assert.Equal(t, [][]int{{0, 2}, {1, 3}, {4}}, groups)
+	})
+
+	t.Run("keys on the same slot", func(t *testing.T) {
+		groups := groupKeysBySlot([]string{"{user}.session", "{user}.quota"})
+		assert.Equal(t, [][]int{{0, 1}}, groups)
+	})
+
+	t.Run("no keys", func(t *testing.T) {
+		assert.Empty(t, groupKeysBySlot(nil))
+	})
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"errors"
+	"time"
 
 	logger "github.com/TykTechnologies/tyk/log"
 )
@@ -71,6 +72,16 @@ type GetMultiKeyHandler interface {
 	GetMultiKey([]string) ([]string, error)
 }
 
+// GetMultiKeyAndTTLHandler is implemented by the storages able to read keys and TTLs in a single round trip.
+type GetMultiKeyAndTTLHandler interface {
+	GetMultiKeyAndTTL([]string, []string) ([]string, []time.Duration, error)
+}
+
+// ReadReplicaHandler is implemented by the storages able to read keys from a read replica.
+type ReadReplicaHandler interface {
+	ReadsFromReplica() bool
+}
+
 type GetRawKeyHandler interface {
 	GetRawKey(string) (string, error)
 }
@@ -55,6 +55,60 @@ func (s *Stat) Flush() Stat {
 	return v
 }
 
+// ConnectionStats is a snapshot of the connections handled by a Proxy.
+type ConnectionStats struct {
+	// Active is the number of connections currently being proxied.
+	Active int64 `json:"active"`
+	// Accepted is the total number of accepted connections.
+	Accepted int64 `json:"accepted"`
+	// Rejected is the total number of connections rejected because MaxConnections was reached.
+	Rejected int64 `json:"rejected"`
+	// BytesIn is the total number of bytes read from clients.
+	BytesIn int64 `json:"bytes_in"`
+	// BytesOut is the total number of bytes read from upstreams.
+	BytesOut int64 `json:"bytes_out"`
+}
+
+// connectionCounter tracks connection counts of a Proxy. It survives Swap so
+// counters are kept across reloads.
+type connectionCounter struct {
+	active   int64
+	accepted int64
+	rejected int64
+	bytesIn  int64
+	bytesOut int64
+}
+
+// acquire reserves a slot for a new connection, it returns false if limit is reached.
+// A limit of zero or less means no limit.
+func (c *connectionCounter) acquire(limit int64) bool {
+	for {
+		active := atomic.LoadInt64(&c.active)
+		if limit > 0 && active >= limit {
+			atomic.AddInt64(&c.rejected, 1)
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&c.active, active, active+1) {
+			atomic.AddInt64(&c.accepted, 1)
+			return true
+		}
+	}
+}
+
+func (c *connectionCounter) release() {
+	atomic.AddInt64(&c.active, -1)
+}
+
+func (c *connectionCounter) snapshot() ConnectionStats {
+	return ConnectionStats{
+		Active:   atomic.LoadInt64(&c.active),
+		Accepted: atomic.LoadInt64(&c.accepted),
+		Rejected: atomic.LoadInt64(&c.rejected),
+		BytesIn:  atomic.LoadInt64(&c.bytesIn),
+		BytesOut: atomic.LoadInt64(&c.bytesOut),
+	}
+}
+
 type Proxy struct {
 	sync.RWMutex
 
@@ -71,6 +125,12 @@ type Proxy struct {
 	// Duration in which connection stats will be flushed. Defaults to one second.
 	StatsSyncInterval time.Duration
 
+	// MaxConnections limits the number of concurrently proxied connections.
+	// New connections exceeding the limit are closed right away. Zero means no limit.
+	MaxConnections int
+
+	counter connectionCounter
+
 	// Connection tracking for graceful shutdown
 	activeConns sync.WaitGroup
 	shutdownCtx context.Context
@@ -101,6 +161,18 @@ func (p *Proxy) Swap(new *Proxy) {
 
 	p.muxer = new.muxer
 	p.TLSConfigTarget = new.TLSConfigTarget
+	p.MaxConnections = new.MaxConnections
+}
+
+// Stats returns a snapshot of the connection counters.
+func (p *Proxy) Stats() ConnectionStats {
+	return p.counter.snapshot()
+}
+
+func (p *Proxy) maxConnections() int64 {
+	p.RLock()
+	defer p.RUnlock()
+	return int64(p.MaxConnections)
 }
 
 func (p *Proxy) RemoveDomainHandler(domain string) {
@@ -161,10 +233,17 @@ func (p *Proxy) Serve(l net.Listener) error {
 			return err
 		}
 
+		if !p.counter.acquire(p.maxConnections()) {
+			log.WithField("conn", clientConn(conn)).Warning("Maximum number of connections reached, rejecting connection")
+			conn.Close()
+			continue
+		}
+
 		p.activeConns.Add(1)
 		go func() {
 			// Track this connection only when we actually start handling it
 			defer p.activeConns.Done()
+			defer p.counter.release()
 			if err := p.handleConn(conn); err != nil {
 				log.WithError(err).Warning("Can't handle connection")
 			}
@@ -300,6 +379,7 @@ func (p *Proxy) handleConn(conn net.Conn) error {
 	r := pipeOpts{
 		modifier: func(src, dst net.Conn, data []byte) ([]byte, error) {
 			atomic.AddInt64(&stat.BytesIn, int64(len(data)))
+			atomic.AddInt64(&p.counter.bytesIn, int64(len(data)))
 			h := config.modifier.ModifyRequest
 			if h != nil {
 				return h(src, dst, data)
@@ -328,6 +408,7 @@ func (p *Proxy) handleConn(conn net.Conn) error {
 	w := pipeOpts{
 		modifier: func(src, dst net.Conn, data []byte) ([]byte, error) {
 			atomic.AddInt64(&stat.BytesOut, int64(len(data)))
+			atomic.AddInt64(&p.counter.bytesOut, int64(len(data)))
 			h := config.modifier.ModifyResponse
 			if h != nil {
 				return h(src, dst, data)
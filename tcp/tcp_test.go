@@ -4,12 +4,16 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"io"
 	"net"
 	"reflect"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/TykTechnologies/tyk/test"
 )
 
@@ -650,3 +654,83 @@ func TestProxy_ShutdownIntegration(t *testing.T) {
 		t.Error("Serve did not exit after listener was closed")
 	}
 }
+
+func TestProxy_MaxConnections(t *testing.T) {
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer upstream.Close()
+
+	go func() {
+		for {
+			conn, err := upstream.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	proxy := &Proxy{MaxConnections: 2}
+	proxy.AddDomainHandler("", upstream.Addr().String(), nil)
+
+	proxyLn, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer proxyLn.Close()
+
+	go proxy.Serve(proxyLn)
+
+	ping := func(t *testing.T, conn net.Conn) error {
+		t.Helper()
+		if _, err := conn.Write([]byte("ping")); err != nil {
+			return err
+		}
+		buf := make([]byte, 4)
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return err
+		}
+		assert.Equal(t, "ping", string(buf))
+		return nil
+	}
+
+	var conns []net.Conn
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", proxyLn.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+		require.NoError(t, ping(t, conn))
+		conns = append(conns, conn)
+	}
+
+	for i := 0; i < 3; i++ {
+		conn, err := net.Dial("tcp", proxyLn.Addr().String())
+		require.NoError(t, err)
+		assert.Error(t, ping(t, conn), "connection above the limit must be rejected")
+		conn.Close()
+	}
+
+	stats := proxy.Stats()
+	assert.Equal(t, int64(2), stats.Active)
+	assert.Equal(t, int64(2), stats.Accepted)
+	assert.Equal(t, int64(3), stats.Rejected)
+	assert.Equal(t, int64(8), stats.BytesIn)
+	assert.Equal(t, int64(8), stats.BytesOut)
+
+	conns[0].Close()
+	assert.Eventually(t, func() bool {
+		return proxy.Stats().Active == 1
+	}, time.Second, 10*time.Millisecond)
+
+	conn, err := net.Dial("tcp", proxyLn.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, ping(t, conn))
+
+	stats = proxy.Stats()
+	assert.Equal(t, int64(2), stats.Active)
+	assert.Equal(t, int64(3), stats.Accepted)
+	assert.Equal(t, int64(3), stats.Rejected)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/TykTechnologies/tyk-pump/analytics"
 	"github.com/TykTechnologies/tyk/ctx"
 	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/httputil"
 	"github.com/TykTechnologies/tyk/user"
 )
 
@@ -118,6 +119,38 @@ func MyPluginResponse(rw http.ResponseWriter, res *http.Response, req *http.Requ
 	res.Header.Add("X-Plugin-Data", pluginConfig)
 }
 
+// MyResponsePluginRewritingBody uses the response plugin signature receiving the session,
+// it adds the alias of the session to the JSON response body, which may be gzip encoded.
+// It uses the helpers behind goplugin.ReadResponseBody and goplugin.ReplaceResponseBody,
+// as the goplugin package tests load this plugin.
+func MyResponsePluginRewritingBody(rw http.ResponseWriter, res *http.Response, _ *http.Request, session *user.SessionState) {
+	body, err := httputil.ReadResponseBody(res)
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if session != nil {
+		data["session_alias"] = session.Alias
+	}
+
+	body, err = json.Marshal(data)
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if err := httputil.ReplaceResponseBody(res, body); err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
 func MyPluginPerPathFoo(rw http.ResponseWriter, r *http.Request) {
 
 	rw.Header().Add("X-foo", "foo")
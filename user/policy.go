@@ -17,20 +17,26 @@ type Policy struct {
 	OrgID                         string                           `bson:"org_id" json:"org_id"`
 	Rate                          float64                          `bson:"rate" json:"rate"`
 	Per                           float64                          `bson:"per" json:"per"`
+	Burst                         int64                            `bson:"burst" json:"burst,omitempty"`
 	QuotaMax                      int64                            `bson:"quota_max" json:"quota_max"`
 	QuotaRenewalRate              int64                            `bson:"quota_renewal_rate" json:"quota_renewal_rate"`
 	ThrottleInterval              float64                          `bson:"throttle_interval" json:"throttle_interval"`
 	ThrottleRetryLimit            int                              `bson:"throttle_retry_limit" json:"throttle_retry_limit"`
 	MaxQueryDepth                 int                              `bson:"max_query_depth" json:"max_query_depth"`
+	MaxQueryNodes                 int                              `bson:"max_query_nodes" json:"max_query_nodes,omitempty"`
+	MaxAliases                    int                              `bson:"max_aliases" json:"max_aliases,omitempty"`
+	MaxConcurrentRequests         int                              `bson:"max_concurrent_requests" json:"max_concurrent_requests,omitempty"`
 	AccessRights                  map[string]AccessDefinition      `bson:"access_rights" json:"access_rights"`
 	HMACEnabled                   bool                             `bson:"hmac_enabled" json:"hmac_enabled"`
 	EnableHTTPSignatureValidation bool                             `json:"enable_http_signature_validation" msg:"enable_http_signature_validation"`
 	Active                        bool                             `bson:"active" json:"active"`
 	IsInactive                    bool                             `bson:"is_inactive" json:"is_inactive"`
 	Tags                          []string                         `bson:"tags" json:"tags"`
+	AllowedIPs                    []string                         `bson:"allowed_ips" json:"allowed_ips,omitempty"`
 	KeyExpiresIn                  int64                            `bson:"key_expires_in" json:"key_expires_in"`
 	PostExpiryAction              PostExpiryAction                 `bson:"post_expiry_action" json:"post_expiry_action,omitzero"`
 	PostExpiryGracePeriod         int64                            `bson:"post_expiry_grace_period" json:"post_expiry_grace_period"`
+	IdleTimeout                   int64                            `bson:"idle_timeout" json:"idle_timeout,omitempty"`
 	Partitions                    PolicyPartitions                 `bson:"partitions" json:"partitions"`
 	LastUpdated                   string                           `bson:"last_updated" json:"last_updated"`
 	MetaData                      map[string]interface{}           `bson:"meta_data" json:"meta_data"`
@@ -38,23 +44,45 @@ type Policy struct {
 
 	// Smoothing contains rate limit smoothing settings.
 	Smoothing *apidef.RateLimitSmoothing `json:"smoothing" bson:"smoothing"`
+
+	// Monitor contains the quota trigger monitor settings of keys with the policy.
+	Monitor PolicyMonitor `bson:"monitor" json:"monitor,omitzero"`
 }
 
 func (p *Policy) APILimit() APILimit {
 	return APILimit{
-		QuotaMax:           p.QuotaMax,
-		QuotaRenewalRate:   p.QuotaRenewalRate,
-		ThrottleInterval:   p.ThrottleInterval,
-		ThrottleRetryLimit: p.ThrottleRetryLimit,
-		MaxQueryDepth:      p.MaxQueryDepth,
+		QuotaMax:              p.QuotaMax,
+		QuotaRenewalRate:      p.QuotaRenewalRate,
+		ThrottleInterval:      p.ThrottleInterval,
+		ThrottleRetryLimit:    p.ThrottleRetryLimit,
+		MaxQueryDepth:         p.MaxQueryDepth,
+		MaxQueryNodes:         p.MaxQueryNodes,
+		MaxAliases:            p.MaxAliases,
+		MaxConcurrentRequests: p.MaxConcurrentRequests,
 		RateLimit: RateLimit{
 			Rate:      p.Rate,
 			Per:       p.Per,
+			Burst:     p.Burst,
 			Smoothing: p.Smoothing,
 		},
 	}
 }
 
+// PolicyMonitor holds the quota trigger monitor settings of a policy.
+type PolicyMonitor struct {
+	// TriggerLimits are the quota usage percentages firing a trigger event, in addition to the global ones.
+	TriggerLimits []float64 `bson:"trigger_limits" json:"trigger_limits,omitempty"`
+	// TargetPath overrides the webhook target the trigger events are sent to.
+	TargetPath string `bson:"target_path" json:"target_path,omitempty"`
+	// TemplatePath overrides the template of the webhook request.
+	TemplatePath string `bson:"template_path" json:"template_path,omitempty"`
+}
+
+// IsZero returns true if PolicyMonitor is empty (for omitzero support).
+func (m PolicyMonitor) IsZero() bool {
+	return len(m.TriggerLimits) == 0 && m.TargetPath == "" && m.TemplatePath == ""
+}
+
 type PolicyPartitions struct {
 	Quota      bool `bson:"quota" json:"quota"`
 	RateLimit  bool `bson:"rate_limit" json:"rate_limit"`
@@ -56,6 +56,10 @@ type RateLimit struct {
 	Rate float64 `json:"rate,omitzero" msg:"rate"`
 	// Per is the interval at which rate limit is enforced.
 	Per float64 `json:"per,omitzero" msg:"per"`
+	// Burst is the number of requests the token bucket rate limiter allows at once,
+	// refilling at Rate requests per Per seconds. Setting it enables the token bucket
+	// rate limiter for this limit.
+	Burst int64 `json:"burst,omitzero" msg:"burst"`
 
 	// Smoothing contains rate limit smoothing settings.
 	Smoothing *apidef.RateLimitSmoothing `json:"smoothing,omitzero" bson:"smoothing,omitempty"`
@@ -64,14 +68,17 @@ type RateLimit struct {
 // APILimit stores quota and rate limit on ACL level (per API)
 type APILimit struct {
 	RateLimit
-	ThrottleInterval   float64 `json:"throttle_interval,omitzero" msg:"throttle_interval"`
-	ThrottleRetryLimit int     `json:"throttle_retry_limit,omitzero" msg:"throttle_retry_limit"`
-	MaxQueryDepth      int     `json:"max_query_depth,omitzero" msg:"max_query_depth"`
-	QuotaMax           int64   `json:"quota_max,omitzero" msg:"quota_max"`
-	QuotaRenews        int64   `json:"quota_renews,omitzero" msg:"quota_renews"`
-	QuotaRemaining     int64   `json:"quota_remaining,omitzero" msg:"quota_remaining"`
-	QuotaRenewalRate   int64   `json:"quota_renewal_rate,omitzero" msg:"quota_renewal_rate"`
-	SetBy              string  `json:"-" msg:"-"`
+	ThrottleInterval      float64 `json:"throttle_interval,omitzero" msg:"throttle_interval"`
+	ThrottleRetryLimit    int     `json:"throttle_retry_limit,omitzero" msg:"throttle_retry_limit"`
+	MaxQueryDepth         int     `json:"max_query_depth,omitzero" msg:"max_query_depth"`
+	MaxQueryNodes         int     `json:"max_query_nodes,omitzero" msg:"max_query_nodes"`
+	MaxAliases            int     `json:"max_aliases,omitzero" msg:"max_aliases"`
+	MaxConcurrentRequests int     `json:"max_concurrent_requests,omitzero" msg:"max_concurrent_requests"`
+	QuotaMax              int64   `json:"quota_max,omitzero" msg:"quota_max"`
+	QuotaRenews           int64   `json:"quota_renews,omitzero" msg:"quota_renews"`
+	QuotaRemaining        int64   `json:"quota_remaining,omitzero" msg:"quota_remaining"`
+	QuotaRenewalRate      int64   `json:"quota_renewal_rate,omitzero" msg:"quota_renewal_rate"`
+	SetBy                 string  `json:"-" msg:"-"`
 }
 
 // Clone does a deepcopy of APILimit.
@@ -86,16 +93,20 @@ func (a APILimit) Clone() *APILimit {
 		RateLimit: RateLimit{
 			Rate:      a.Rate,
 			Per:       a.Per,
+			Burst:     a.Burst,
 			Smoothing: smoothingRef,
 		},
-		ThrottleInterval:   a.ThrottleInterval,
-		ThrottleRetryLimit: a.ThrottleRetryLimit,
-		MaxQueryDepth:      a.MaxQueryDepth,
-		QuotaMax:           a.QuotaMax,
-		QuotaRenews:        a.QuotaRenews,
-		QuotaRemaining:     a.QuotaRemaining,
-		QuotaRenewalRate:   a.QuotaRenewalRate,
-		SetBy:              a.SetBy,
+		ThrottleInterval:      a.ThrottleInterval,
+		ThrottleRetryLimit:    a.ThrottleRetryLimit,
+		MaxQueryDepth:         a.MaxQueryDepth,
+		MaxQueryNodes:         a.MaxQueryNodes,
+		MaxAliases:            a.MaxAliases,
+		MaxConcurrentRequests: a.MaxConcurrentRequests,
+		QuotaMax:              a.QuotaMax,
+		QuotaRenews:           a.QuotaRenews,
+		QuotaRemaining:        a.QuotaRemaining,
+		QuotaRenewalRate:      a.QuotaRenewalRate,
+		SetBy:                 a.SetBy,
 	}
 }
 
@@ -143,6 +154,10 @@ func (a APILimit) IsEmpty() bool {
 		return false
 	}
 
+	if a.Burst != 0 {
+		return false
+	}
+
 	if a.ThrottleInterval != 0 {
 		return false
 	}
@@ -155,6 +170,18 @@ func (a APILimit) IsEmpty() bool {
 		return false
 	}
 
+	if a.MaxQueryNodes != 0 {
+		return false
+	}
+
+	if a.MaxAliases != 0 {
+		return false
+	}
+
+	if a.MaxConcurrentRequests != 0 {
+		return false
+	}
+
 	if a.QuotaMax != 0 {
 		return false
 	}
@@ -186,7 +213,7 @@ func (a APILimit) IsZero() bool {
 
 // IsZero returns true if RateLimit is empty (for omitzero support).
 func (r RateLimit) IsZero() bool {
-	return r.Rate == 0 && r.Per == 0 && r.Smoothing == nil
+	return r.Rate == 0 && r.Per == 0 && r.Burst == 0 && r.Smoothing == nil
 }
 
 // IsZero returns true if FieldLimits is empty (for omitzero support).
@@ -299,9 +326,13 @@ type SessionState struct {
 	Allowance                     float64                     `json:"allowance,omitzero" msg:"allowance"`
 	Rate                          float64                     `json:"rate,omitzero" msg:"rate"`
 	Per                           float64                     `json:"per,omitzero" msg:"per"`
+	Burst                         int64                       `json:"burst,omitzero" msg:"burst"`
 	ThrottleInterval              float64                     `json:"throttle_interval,omitzero" msg:"throttle_interval"`
 	ThrottleRetryLimit            int                         `json:"throttle_retry_limit,omitzero" msg:"throttle_retry_limit"`
 	MaxQueryDepth                 int                         `json:"max_query_depth,omitzero" msg:"max_query_depth"`
+	MaxQueryNodes                 int                         `json:"max_query_nodes,omitzero" msg:"max_query_nodes"`
+	MaxAliases                    int                         `json:"max_aliases,omitzero" msg:"max_aliases"`
+	MaxConcurrentRequests         int                         `json:"max_concurrent_requests,omitzero" msg:"max_concurrent_requests"`
 	DateCreated                   time.Time                   `json:"date_created,omitzero" msg:"date_created"`
 	Expires                       int64                       `json:"expires,omitzero" msg:"expires"`
 	QuotaMax                      int64                       `json:"quota_max,omitzero" msg:"quota_max"`
@@ -314,6 +345,7 @@ type SessionState struct {
 	OauthKeys                     map[string]string           `json:"oauth_keys,omitempty" msg:"oauth_keys"`
 	Certificate                   string                      `json:"certificate,omitzero" msg:"certificate"`
 	MtlsStaticCertificateBindings []string                    `json:"mtls_static_certificate_bindings,omitempty" msg:"mtls_static_certificate_bindings"`
+	AllowedIPs                    []string                    `json:"allowed_ips,omitempty" msg:"allowed_ips"` // IPs or CIDR ranges the key can be used from, empty allows any
 	BasicAuthData                 BasicAuthData               `json:"basic_auth_data,omitzero" msg:"basic_auth_data"`
 	JWTData                       JWTData                     `json:"jwt_data,omitzero" msg:"jwt_data"`
 	HMACEnabled                   bool                        `json:"hmac_enabled,omitzero" msg:"hmac_enabled"`
@@ -338,6 +370,19 @@ type SessionState struct {
 	PostExpiryAction        PostExpiryAction       `json:"post_expiry_action,omitzero" msg:"post_expiry_action"`
 	PostExpiryGracePeriod   int64                  `json:"post_expiry_grace_period,omitzero" msg:"post_expiry_grace_period"`
 
+	// RateLimitDebugUntil logs every rate limit decision of the key until the Unix time.
+	// Set it to -1 to log them until it's reset to 0, which turns the debug mode off.
+	RateLimitDebugUntil int64 `json:"rate_limit_debug_until,omitzero" msg:"rate_limit_debug_until"`
+
+	// IdleTimeout expires the key once it wasn't used for this number of seconds. 0 disables it.
+	IdleTimeout int64 `json:"idle_timeout,omitzero" msg:"idle_timeout"`
+	// LastSeen is the Unix time the key was last used, only tracked for keys with an idle timeout.
+	LastSeen int64 `json:"last_seen,omitzero" msg:"last_seen"`
+
+	// OrgAdminTokenHash is the SHA-256 hex digest of the token allowed to manage the keys of the organisation
+	// through the Control API, see the enable_org_scoped_admin_tokens option. Only used on org sessions.
+	OrgAdminTokenHash string `json:"org_admin_token_hash,omitzero" msg:"org_admin_token_hash"`
+
 	// Used to store token hash
 	keyHash string
 	KeyID   string `json:"-"`
@@ -365,14 +410,18 @@ func (s *SessionState) APILimit() APILimit {
 		RateLimit: RateLimit{
 			Rate:      s.Rate,
 			Per:       s.Per,
+			Burst:     s.Burst,
 			Smoothing: s.Smoothing,
 		},
-		QuotaMax:           s.QuotaMax,
-		QuotaRenewalRate:   s.QuotaRenewalRate,
-		QuotaRenews:        s.QuotaRenews,
-		ThrottleInterval:   s.ThrottleInterval,
-		ThrottleRetryLimit: s.ThrottleRetryLimit,
-		MaxQueryDepth:      s.MaxQueryDepth,
+		QuotaMax:              s.QuotaMax,
+		QuotaRenewalRate:      s.QuotaRenewalRate,
+		QuotaRenews:           s.QuotaRenews,
+		ThrottleInterval:      s.ThrottleInterval,
+		ThrottleRetryLimit:    s.ThrottleRetryLimit,
+		MaxQueryDepth:         s.MaxQueryDepth,
+		MaxQueryNodes:         s.MaxQueryNodes,
+		MaxAliases:            s.MaxAliases,
+		MaxConcurrentRequests: s.MaxConcurrentRequests,
 	}
 }
 
@@ -415,6 +464,7 @@ func (s SessionState) Clone() SessionState {
 	newSession.ApplyPolicies = slices.Clone(s.ApplyPolicies)
 	newSession.MetaData = maps.Clone(s.MetaData)
 	newSession.Tags = slices.Clone(s.Tags)
+	newSession.AllowedIPs = slices.Clone(s.AllowedIPs)
 
 	return newSession
 }
@@ -439,6 +489,25 @@ func (s *SessionState) KeyHashEmpty() bool {
 	return s.keyHash == ""
 }
 
+// RateLimitDebugEnabled returns true if the rate limit decisions of the key are logged at the given time.
+func (s *SessionState) RateLimitDebugEnabled(now time.Time) bool {
+	return s.RateLimitDebugUntil == -1 || s.RateLimitDebugUntil > now.Unix()
+}
+
+// SeedLastSeen sets the last seen time of a key with an idle timeout which has none, so the key
+// is idle from the time its idle timeout is set even if it's never used.
+func (s *SessionState) SeedLastSeen(now time.Time) {
+	if s.IdleTimeout > 0 && s.LastSeen == 0 {
+		s.LastSeen = now.Unix()
+	}
+}
+
+// IdleExpired returns true when the key has an idle timeout and wasn't used for longer than it.
+// A key without a last seen time, see SeedLastSeen, isn't idle, its idleness is counted from its next use.
+func (s *SessionState) IdleExpired(now time.Time) bool {
+	return s.IdleTimeout > 0 && s.LastSeen > 0 && now.Unix()-s.LastSeen > s.IdleTimeout
+}
+
 // hasNewExpiryBehaviour returns true when the new post-expiry fields are explicitly
 // configured, indicating that the new TTL calculation logic should be used instead
 // of the legacy behavior.
@@ -584,6 +653,8 @@ type EndpointRateLimitInfo struct {
 	Rate float64
 	// Per is the rate limiting interval.
 	Per float64
+	// Burst is the token bucket capacity.
+	Burst int64
 }
 
 // Map returns EndpointsMap of Endpoints using the key format [method:path].
@@ -256,6 +256,47 @@ func Test_calculateLifetime(t *testing.T) {
 	})
 }
 
+func TestSessionState_IdleExpired(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name    string
+		session SessionState
+		want    bool
+	}{
+		{"no idle timeout", SessionState{LastSeen: now.Unix() - 3600}, false},
+		{"never seen nor created", SessionState{IdleTimeout: 60}, false},
+		{"seen recently", SessionState{IdleTimeout: 60, LastSeen: now.Unix() - 30}, false},
+		{"seen exactly at timeout", SessionState{IdleTimeout: 60, LastSeen: now.Unix() - 60}, false},
+		{"idle", SessionState{IdleTimeout: 60, LastSeen: now.Unix() - 61}, true},
+		{"never seen, created recently", SessionState{IdleTimeout: 60, DateCreated: now.Add(-time.Second)}, false},
+		{"never seen, created long ago", SessionState{IdleTimeout: 60, DateCreated: now.Add(-time.Hour)}, false},
+		{"seen after creation", SessionState{IdleTimeout: 60, LastSeen: now.Unix() - 30, DateCreated: now.Add(-time.Hour)}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.session.IdleExpired(now))
+		})
+	}
+}
+
+func TestSessionState_SeedLastSeen(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	session := SessionState{IdleTimeout: 60}
+	session.SeedLastSeen(now)
+	assert.Equal(t, now.Unix(), session.LastSeen)
+	assert.True(t, session.IdleExpired(now.Add(61*time.Second)), "a key never used expires")
+
+	session.SeedLastSeen(now.Add(time.Hour))
+	assert.Equal(t, now.Unix(), session.LastSeen, "the last seen time is kept")
+
+	session = SessionState{}
+	session.SeedLastSeen(now)
+	assert.Zero(t, session.LastSeen, "keys without idle timeout aren't tracked")
+}
+
 func TestAPILimit_Duration(t *testing.T) {
 	t.Run("valid limit", func(t *testing.T) {
 		limit := APILimit{
@@ -984,6 +1025,9 @@ func TestAPILimit_IsZero_NonEmpty(t *testing.T) {
 		{"QuotaRemaining set", APILimit{QuotaRemaining: 500}},
 		{"ThrottleInterval set", APILimit{ThrottleInterval: 10}},
 		{"MaxQueryDepth set", APILimit{MaxQueryDepth: 5}},
+		{"MaxQueryNodes set", APILimit{MaxQueryNodes: 100}},
+		{"MaxAliases set", APILimit{MaxAliases: 10}},
+		{"MaxConcurrentRequests set", APILimit{MaxConcurrentRequests: 10}},
 	}
 
 	for _, tt := range tests {
@@ -1723,3 +1767,25 @@ func TestSessionState_Lifetime_PostExpiry_BypassesRespectKeyExpiration(t *testin
 		})
 	}
 }
+
+func TestSessionState_RateLimitDebugEnabled(t *testing.T) {
+	now := time.Now()
+
+	tcs := []struct {
+		name  string
+		until int64
+		want  bool
+	}{
+		{name: "disabled", until: 0, want: false},
+		{name: "until disabled", until: -1, want: true},
+		{name: "active", until: now.Add(time.Minute).Unix(), want: true},
+		{name: "expired", until: now.Add(-time.Minute).Unix(), want: false},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &SessionState{RateLimitDebugUntil: tc.until}
+			assert.Equal(t, tc.want, s.RateLimitDebugEnabled(now))
+		})
+	}
+}